@@ -0,0 +1,137 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: access grant list
+// path: /access-grants
+// method: GET
+// produce: application/json
+// responses:
+//   200: List active access grants
+//   204: No content
+//   401: Unauthorized
+func accessGrantList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermAccessGrantRead) {
+		return permission.ErrUnauthorized
+	}
+	grants, err := auth.ListActiveAccessGrants()
+	if err != nil {
+		return err
+	}
+	if len(grants) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(grants)
+}
+
+// title: access grant create
+// path: /access-grants
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   201: Access granted
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+func accessGrantCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermAccessGrantCreate) {
+		return permission.ErrUnauthorized
+	}
+	grant := auth.AccessGrant{
+		User:         InputValue(r, "user"),
+		Role:         InputValue(r, "role"),
+		ContextValue: InputValue(r, "context"),
+		Reason:       InputValue(r, "reason"),
+		GrantedBy:    t.GetUserName(),
+	}
+	duration, err := time.ParseDuration(InputValue(r, "duration"))
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "invalid duration: " + err.Error()}
+	}
+	role, err := permission.FindRole(grant.Role)
+	if err == permTypes.ErrRoleNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	err = canUseRole(t, role, grant.ContextValue)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:      userTarget(grant.User),
+		Kind:        permission.PermAccessGrantCreate,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  event.FormToCustomData(InputFields(r)),
+		Allowed:     event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, grant.User)),
+		DisableLock: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = auth.GrantAccess(&grant, duration)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(grant)
+}
+
+// title: access grant revoke
+// path: /access-grants/{id}
+// method: DELETE
+// responses:
+//   200: Access grant revoked
+//   401: Unauthorized
+//   404: Active access grant not found
+func accessGrantRevoke(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermAccessGrantDelete) {
+		return permission.ErrUnauthorized
+	}
+	grant, err := auth.FindAccessGrant(r.URL.Query().Get(":id"))
+	if err == auth.ErrAccessGrantNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:      userTarget(grant.User),
+		Kind:        permission.PermAccessGrantDelete,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  event.FormToCustomData(InputFields(r)),
+		Allowed:     event.Allowed(permission.PermUserReadEvents, permission.Context(permTypes.CtxUser, grant.User)),
+		DisableLock: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = auth.RevokeAccessGrant(grant.ID.Hex(), t.GetUserName())
+	if err == auth.ErrAccessGrantNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAccessGrantCreateListRevoke(c *check.C) {
+	role, err := permission.NewRole("app-admin", "app", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions("app.update")
+	c.Assert(err, check.IsNil)
+	_, userToken := permissiontest.CustomUserWithPermission(c, nativeScheme, "oncall")
+	body := strings.NewReader("user=" + userToken.GetUserName() + "&role=app-admin&context=myapp&duration=2h&reason=incident")
+	request, err := http.NewRequest("POST", "/1.13/access-grants", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", recorder.Body.String()))
+	var grant auth.AccessGrant
+	err = json.NewDecoder(recorder.Body).Decode(&grant)
+	c.Assert(err, check.IsNil)
+	c.Assert(grant.GrantedBy, check.Equals, s.token.GetUserName())
+	c.Assert(permission.Check(userToken, permission.PermAppUpdate, permission.Context(permTypes.CtxApp, "myapp")), check.Equals, true)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeUser, Value: userToken.GetUserName()},
+		Owner:  s.token.GetUserName(),
+		Kind:   "access-grant.create",
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("GET", "/1.13/access-grants", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var grants []auth.AccessGrant
+	err = json.NewDecoder(recorder.Body).Decode(&grants)
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.HasLen, 1)
+	request, err = http.NewRequest("DELETE", "/1.13/access-grants/"+grant.ID.Hex(), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(permission.Check(userToken, permission.PermAppUpdate, permission.Context(permTypes.CtxApp, "myapp")), check.Equals, false)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestAccessGrantCreateInvalidDuration(c *check.C) {
+	_, err := permission.NewRole("app-admin", "app", "")
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("user=" + s.token.GetUserName() + "&role=app-admin&context=myapp&duration=forever&reason=incident")
+	request, err := http.NewRequest("POST", "/1.13/access-grants", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestAccessGrantCreateRoleNotUsable(c *check.C) {
+	role, err := permission.NewRole("app-admin", "app", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions("app.update")
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAccessGrantCreate,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	body := strings.NewReader("user=" + s.token.GetUserName() + "&role=app-admin&context=myapp&duration=1h&reason=incident")
+	request, err := http.NewRequest("POST", "/1.13/access-grants", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
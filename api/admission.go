@@ -0,0 +1,52 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"net/http"
+
+	pkgErrors "github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/admission"
+	"github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// appCreateAdmission is the object sent to admission webhooks on app
+// creation. The name is informative only, mutations to it are ignored.
+type appCreateAdmission struct {
+	Name        string            `json:"name"`
+	TeamOwner   string            `json:"teamOwner"`
+	Platform    string            `json:"platform"`
+	Plan        string            `json:"plan"`
+	Pool        string            `json:"pool"`
+	Router      string            `json:"router"`
+	RouterOpts  map[string]string `json:"routerOpts"`
+	Description string            `json:"description"`
+	Tags        []string          `json:"tags"`
+	Metadata    appTypes.Metadata `json:"metadata"`
+}
+
+// appDeployAdmission is the object sent to admission webhooks on app
+// deploys. Only the image and the message may be mutated.
+type appDeployAdmission struct {
+	Kind       string `json:"kind"`
+	Origin     string `json:"origin"`
+	Image      string `json:"image"`
+	ArchiveURL string `json:"archiveURL"`
+	Commit     string `json:"commit"`
+	Message    string `json:"message"`
+}
+
+func reviewAdmission(ctx context.Context, operation, appName, user string, object interface{}) error {
+	err := admission.Review(ctx, operation, appName, user, object)
+	if err == nil {
+		return nil
+	}
+	if denied, ok := pkgErrors.Cause(err).(*admission.DeniedError); ok {
+		return &errors.HTTP{Code: http.StatusForbidden, Message: denied.Error()}
+	}
+	return err
+}
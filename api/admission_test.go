@@ -0,0 +1,136 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/ajg/form"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/admission"
+	"github.com/tsuru/tsuru/permission"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func setAdmissionWebhook(url string, operations ...interface{}) {
+	config.Set("admission:webhooks", []interface{}{
+		map[interface{}]interface{}{
+			"name":       "policy",
+			"url":        url,
+			"operations": operations,
+		},
+	})
+}
+
+func (s *S) TestCreateAppAdmissionMutation(c *check.C) {
+	s.setupMockForCreateApp(c, "zend")
+	var received admission.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		fmt.Fprintf(w, `{"allowed": true, "object": {"name": "someapp", "teamOwner": %q, "platform": "zend", "tags": ["cost-center=42"]}}`, s.team.Name)
+	}))
+	defer srv.Close()
+	setAdmissionWebhook(srv.URL, admission.OperationAppCreate)
+	defer config.Unset("admission")
+	request, err := http.NewRequest("POST", "/apps", strings.NewReader("name=someapp&platform=zend"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppCreate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	c.Assert(received.Operation, check.Equals, admission.OperationAppCreate)
+	c.Assert(received.App, check.Equals, "someapp")
+	c.Assert(received.User, check.Equals, token.GetUserName())
+	var gotApp app.App
+	err = s.conn.Apps().Find(bson.M{"name": "someapp"}).One(&gotApp)
+	c.Assert(err, check.IsNil)
+	c.Assert(gotApp.Tags, check.DeepEquals, []string{"cost-center=42"})
+}
+
+func (s *S) TestCreateAppAdmissionDenied(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"allowed": false, "message": "apps must have a cost center tag"}`)
+	}))
+	defer srv.Close()
+	setAdmissionWebhook(srv.URL, admission.OperationAppCreate)
+	defer config.Unset("admission")
+	request, err := http.NewRequest("POST", "/apps", strings.NewReader("name=someapp&platform=zend"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Body.String(), check.Equals, `operation denied by admission webhook "policy": apps must have a cost center tag`+"\n")
+	count, err := s.conn.Apps().Find(bson.M{"name": "someapp"}).Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(count, check.Equals, 0)
+}
+
+func (s *S) TestSetEnvAdmissionDenied(c *check.C) {
+	a := app.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"allowed": false, "message": "secrets must be private"}`)
+	}))
+	defer srv.Close()
+	setAdmissionWebhook(srv.URL, admission.OperationAppEnvSet)
+	defer config.Unset("admission")
+	d := apiTypes.Envs{
+		Envs: []apiTypes.Env{{Name: "DATABASE_PASSWORD", Value: "secret"}},
+	}
+	v, err := form.EncodeToValues(&d)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/black-dog/env", strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	gotApp, err := app.GetByName(context.TODO(), "black-dog")
+	c.Assert(err, check.IsNil)
+	_, ok := gotApp.Env["DATABASE_PASSWORD"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestSetEnvAdmissionMutationInternalEnv(c *check.C) {
+	a := app.App{Name: "black-dog", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"allowed": true, "object": {"Envs": [{"Name": "TSURU_APPNAME", "Value": "other"}]}}`)
+	}))
+	defer srv.Close()
+	setAdmissionWebhook(srv.URL)
+	defer config.Unset("admission")
+	d := apiTypes.Envs{
+		Envs: []apiTypes.Env{{Name: "DATABASE_HOST", Value: "localhost"}},
+	}
+	v, err := form.EncodeToValues(&d)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/black-dog/env", strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
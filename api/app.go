@@ -8,6 +8,7 @@ import (
 	stdContext "context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -19,9 +20,13 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tsuru/tsuru/api/context"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/admission"
 	"github.com/tsuru/tsuru/app/bind"
 	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/app/project"
+	"github.com/tsuru/tsuru/app/template"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/db"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
@@ -134,6 +139,206 @@ func appVersionDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 	return a.DeleteVersion(ctx, evt, versionString)
 }
 
+// title: app version artifact
+// path: /apps/{app}/versions/{version}/artifact
+// method: GET
+// produce: application/octet-stream
+// responses:
+//   200: Ok
+//   400: Not supported
+//   401: Unauthorized
+//   404: Not found
+func appVersionArtifact(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppReadDeployArtifact,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	artifact, err := a.VersionArtifact(ctx, r.URL.Query().Get(":version"))
+	if err != nil {
+		if appTypes.IsInvalidVersionError(err) || err == builder.ErrArtifactNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		if _, ok := err.(provision.ProvisionerNotSupported); ok {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	defer artifact.Close()
+	w.Header().Set("Content-Type", artifact.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", artifact.Name))
+	_, err = io.Copy(w, artifact)
+	return err
+}
+
+// title: app version list
+// path: /apps/{app}/versions
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: Not found
+func appVersionList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppRead,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	versions, err := a.VersionUnits(ctx)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(versions)
+}
+
+func appVersionFromPath(r *http.Request) (int, error) {
+	version, err := strconv.Atoi(r.URL.Query().Get(":version"))
+	if err != nil || version <= 0 {
+		return 0, &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid version"}
+	}
+	return version, nil
+}
+
+// title: app version traffic shift
+// path: /apps/{app}/versions/{version}/traffic
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+func appVersionTraffic(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	version, err := appVersionFromPath(r)
+	if err != nil {
+		return err
+	}
+	weight, err := strconv.Atoi(InputValue(r, "weight"))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid weight"}
+	}
+	process := InputValue(r, "process")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateRoutable,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:        appTarget(appName),
+		Kind:          permission.PermAppUpdateRoutable,
+		Owner:         t,
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    event.FormToCustomData(InputFields(r)),
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(&a)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	ctx, cancel := evt.CancelableContext(a.Context())
+	defer cancel()
+	a.ReplaceContext(ctx)
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	return a.ShiftTraffic(ctx, evt, process, version, weight)
+}
+
+// title: app version retire
+// path: /apps/{app}/versions/{version}/retire
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+func appVersionRetire(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	version, err := appVersionFromPath(r)
+	if err != nil {
+		return err
+	}
+	step := 1
+	if value := InputValue(r, "step"); value != "" {
+		step, err = strconv.Atoi(value)
+		if err != nil || step <= 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid step"}
+		}
+	}
+	interval := 30 * time.Second
+	if value := InputValue(r, "interval"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval < 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid interval"}
+		}
+	}
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateUnitRemove,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:        appTarget(appName),
+		Kind:          permission.PermAppUpdateUnitRemove,
+		Owner:         t,
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    event.FormToCustomData(InputFields(r)),
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(&a)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	ctx, cancel := evt.CancelableContext(a.Context())
+	defer cancel()
+	a.ReplaceContext(ctx)
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	return a.RetireVersion(ctx, evt, version, step, interval)
+}
+
 // title: remove app
 // path: /apps/{name}
 // method: DELETE
@@ -142,6 +347,8 @@ func appVersionDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err
 //   200: App removed
 //   401: Unauthorized
 //   404: Not found
+//   409: App is protected
+//   412: App still in use
 func appDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
 	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
@@ -154,6 +361,22 @@ func appDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	if !canDelete {
 		return permission.ErrUnauthorized
 	}
+	if err = a.CheckRemovalProtection(); err != nil {
+		return protectionError(err)
+	}
+	force, _ := strconv.ParseBool(InputValue(r, "force"))
+	if !force && app.RemovalSafetyChecksEnabled() {
+		var report *app.RemovalReport
+		report, err = app.CheckRemoval(ctx, &a)
+		if err != nil {
+			return err
+		}
+		if report.Blocked() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return json.NewEncoder(w).Encode(report)
+		}
+	}
 	evt, err := event.New(&event.Opts{
 		Target:     appTarget(a.Name),
 		Kind:       permission.PermAppDelete,
@@ -255,6 +478,10 @@ contextsLoop:
 //   401: Unauthorized
 func appList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	ctx := r.Context()
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return err
+	}
 	filter := &app.Filter{}
 	if name := r.URL.Query().Get("name"); name != "" {
 		filter.NameMatches = name
@@ -271,6 +498,9 @@ func appList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if pool := r.URL.Query().Get("pool"); pool != "" {
 		filter.Pool = pool
 	}
+	if projectName := r.URL.Query().Get("project"); projectName != "" {
+		filter.Project = projectName
+	}
 	locked, _ := strconv.ParseBool(r.URL.Query().Get("locked"))
 	if locked {
 		filter.Locked = true
@@ -295,9 +525,16 @@ func appList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		w.WriteHeader(http.StatusNoContent)
 		return nil
 	}
+	var next int
+	if opts.sort == "" && opts.paginated() {
+		// units are only loaded for the apps in the page when the apps are
+		// kept in the storage order.
+		var start, end int
+		start, end, next = opts.bounds(len(apps))
+		apps = apps[start:end]
+	}
 	simple, _ := strconv.ParseBool(r.URL.Query().Get("simplified"))
 	extended, _ := strconv.ParseBool(r.URL.Query().Get("extended"))
-	w.Header().Set("Content-Type", "application/json")
 	miniApps := make([]miniApp, len(apps))
 	if simple {
 		for i, ap := range apps {
@@ -307,20 +544,22 @@ func appList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 				return err
 			}
 		}
-		return json.NewEncoder(w).Encode(miniApps)
-	}
-	appUnits, err := app.Units(ctx, apps)
-	if err != nil {
-		return err
-	}
-
-	for i, app := range apps {
-		miniApps[i], err = minifyApp(app, appUnits[app.Name], extended)
+	} else {
+		appUnits, err := app.Units(ctx, apps)
 		if err != nil {
 			return err
 		}
+		for i, app := range apps {
+			miniApps[i], err = minifyApp(app, appUnits[app.Name], extended)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if opts.sort == "" {
+		return writePage(w, r, opts, miniApps, next)
 	}
-	return json.NewEncoder(w).Encode(miniApps)
+	return writeList(w, r, opts, miniApps)
 }
 
 // title: app info
@@ -362,6 +601,8 @@ type inputApp struct {
 	Tags         []string
 	PlanOverride appTypes.PlanOverride
 	Metadata     appTypes.Metadata
+	Template     string
+	Visibility   string
 }
 
 func autoTeamOwner(ctx stdContext.Context, t auth.Token, perm *permission.PermissionScheme) (string, error) {
@@ -411,16 +652,53 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		Router:      ia.Router,
 		Tags:        ia.Tags,
 		Metadata:    ia.Metadata,
+		Visibility:  ia.Visibility,
 		Quota:       quota.UnlimitedQuota,
 	}
 	tags, _ := InputValues(r, "tag")
 	a.Tags = append(a.Tags, tags...) // for compatibility
+	var tpl *template.Template
+	if ia.Template != "" {
+		tpl, err = template.Get(ia.Template)
+		if err == template.ErrTemplateNotFound {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		if err != nil {
+			return err
+		}
+		tpl.ApplyDefaults(&a)
+	}
 	if a.TeamOwner == "" {
 		a.TeamOwner, err = autoTeamOwner(ctx, t, permission.PermAppCreate)
 		if err != nil {
 			return err
 		}
 	}
+	review := appCreateAdmission{
+		Name:        a.Name,
+		TeamOwner:   a.TeamOwner,
+		Platform:    a.Platform,
+		Plan:        a.Plan.Name,
+		Pool:        a.Pool,
+		Router:      a.Router,
+		RouterOpts:  a.RouterOpts,
+		Description: a.Description,
+		Tags:        a.Tags,
+		Metadata:    a.Metadata,
+	}
+	err = reviewAdmission(ctx, admission.OperationAppCreate, a.Name, t.GetUserName(), &review)
+	if err != nil {
+		return err
+	}
+	a.TeamOwner = review.TeamOwner
+	a.Platform = review.Platform
+	a.Plan = appTypes.Plan{Name: review.Plan}
+	a.Pool = review.Pool
+	a.Router = review.Router
+	a.RouterOpts = review.RouterOpts
+	a.Description = review.Description
+	a.Tags = review.Tags
+	a.Metadata = review.Metadata
 	canCreate := permission.Check(t, permission.PermAppCreate,
 		permission.Context(permTypes.CtxTeam, a.TeamOwner),
 	)
@@ -482,6 +760,11 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 		}
 		return err
 	}
+	if tpl != nil {
+		if _, err = tpl.Sync(ctx, &a, evt); err != nil {
+			return err
+		}
+	}
 	msg := map[string]interface{}{
 		"status": "success",
 	}
@@ -512,6 +795,7 @@ func createApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 //   400: Invalid new pool
 //   401: Unauthorized
 //   404: Not found
+//   409: App is protected
 func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
 	var ia inputApp
@@ -599,6 +883,9 @@ func updateApp(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 			return permission.ErrUnauthorized
 		}
 	}
+	if err = a.CheckPoolChangeProtection(updateData.Pool); err != nil {
+		return protectionError(err)
+	}
 	evt, err := event.New(&event.Opts{
 		Target:        appTarget(appName),
 		Kind:          permission.PermAppUpdate,
@@ -703,6 +990,16 @@ func addUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	defer keepAliveWriter.Stop()
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
 	evt.SetLogWriter(writer)
+	if a.Project != "" {
+		p, errProject := project.Get(a.Project)
+		if errProject != nil {
+			return errProject
+		}
+		err = p.CheckUnits(ctx, int(n))
+		if err != nil {
+			return err
+		}
+	}
 	return a.AddUnits(n, processName, version, evt)
 }
 
@@ -715,11 +1012,16 @@ func addUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 //   400: Invalid data
 //   401: Unauthorized
 //   403: Not enough reserved units
-//   404: App not found
+//   404: App or unit not found
+//   409: App is protected
 func removeUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
-	n, err := numberOfUnits(r)
-	if err != nil {
-		return err
+	unitIDs, _ := InputValues(r, "unit")
+	var n uint
+	if len(unitIDs) == 0 {
+		n, err = numberOfUnits(r)
+		if err != nil {
+			return err
+		}
 	}
 	version := InputValue(r, "version")
 	processName := InputValue(r, "process")
@@ -734,6 +1036,16 @@ func removeUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
+	if err = checkPoolFreeze(r, t, &a, permission.PermAppUpdateUnitRemove); err != nil {
+		return err
+	}
+	toRemove := int(n)
+	if len(unitIDs) > 0 {
+		toRemove = len(unitIDs)
+	}
+	if err = a.CheckUnitsProtection(toRemove); err != nil {
+		return protectionError(err)
+	}
 	evt, err := event.New(&event.Opts{
 		Target:        appTarget(appName),
 		Kind:          permission.PermAppUpdateUnitRemove,
@@ -756,7 +1068,17 @@ func removeUnits(w http.ResponseWriter, r *http.Request, t auth.Token) (err erro
 	defer keepAliveWriter.Stop()
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
 	evt.SetLogWriter(writer)
-	return a.RemoveUnits(ctx, n, processName, version, evt)
+	if len(unitIDs) == 0 {
+		return a.RemoveUnits(ctx, n, processName, version, evt)
+	}
+	err = a.RemoveUnitsByID(ctx, unitIDs, evt)
+	if _, ok := err.(*provision.UnitNotFoundError); ok {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err == app.ErrRemoveUnitsByIDProvisioner {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
 }
 
 // title: set unit status
@@ -860,6 +1182,58 @@ func killUnit(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return err
 }
 
+// title: restart a single unit
+// path: /apps/{app}/units/{unit}/restart
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: Ok
+//   400: Unit restart not supported
+//   401: Unauthorized
+//   404: App or unit not found
+func restartUnit(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	unitName := r.URL.Query().Get(":unit")
+	appName := r.URL.Query().Get(":app")
+	a, err := app.GetByName(ctx, appName)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateUnitRestart,
+		contextsForApp(a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateUnitRestart,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: []map[string]interface{}{
+			{"unit": unitName},
+		},
+		Allowed: event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	err = a.RestartUnit(unitName, evt)
+	if _, ok := err.(*provision.UnitNotFoundError); ok {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err == app.ErrRestartUnitProvisioner {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
 // title: set node status
 // path: /node/status
 // method: POST
@@ -1111,10 +1485,9 @@ func setEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: msg}
 	}
 
-	for _, result := range e.Envs {
-		if isInternalEnv(result.Name) {
-			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("Can't change the following environment variables (write protected): %s", internalEnvs())}
-		}
+	err = checkInternalEnvs(e.Envs)
+	if err != nil {
+		return err
 	}
 	appName := r.URL.Query().Get(":app")
 	a, err := getAppFromContext(appName, r)
@@ -1127,6 +1500,14 @@ func setEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
+	err = reviewAdmission(r.Context(), admission.OperationAppEnvSet, appName, t.GetUserName(), &e)
+	if err != nil {
+		return err
+	}
+	err = checkInternalEnvs(e.Envs)
+	if err != nil {
+		return err
+	}
 
 	var toExclude []string
 	for i := 0; i < len(e.Envs); i++ {
@@ -1175,15 +1556,27 @@ func setEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	err = a.SetEnvs(bind.SetEnvArgs{
 		Envs:          variables,
 		ManagedBy:     e.ManagedBy,
-		PruneUnused:   e.PruneUnused,
-		ShouldRestart: !e.NoRestart,
-		Writer:        evt,
+		PruneUnused:    e.PruneUnused,
+		ShouldRestart:  !e.NoRestart,
+		RollingRestart: e.RollingRestart,
+		BatchSize:      e.BatchSize,
+		Writer:         evt,
 	})
 	if v, ok := err.(*errors.ValidationError); ok {
 		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
 	}
 	return err
 }
+
+func checkInternalEnvs(envs []apiTypes.Env) error {
+	for _, env := range envs {
+		if isInternalEnv(env.Name) {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("Can't change the following environment variables (write protected): %s", internalEnvs())}
+		}
+	}
+	return nil
+}
+
 func isInternalEnv(envKey string) bool {
 	for _, internalEnv := range internalEnvs() {
 		if internalEnv == envKey {
@@ -1245,10 +1638,14 @@ func unsetEnv(w http.ResponseWriter, r *http.Request, t auth.Token) (err error)
 	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
 	evt.SetLogWriter(writer)
 	noRestart, _ := strconv.ParseBool(InputValue(r, "noRestart"))
+	rollingRestart, _ := strconv.ParseBool(InputValue(r, "rollingRestart"))
+	batchSize, _ := strconv.Atoi(InputValue(r, "batchSize"))
 	return a.UnsetEnvs(bind.UnsetEnvArgs{
-		VariableNames: variables,
-		ShouldRestart: !noRestart,
-		Writer:        evt,
+		VariableNames:  variables,
+		ShouldRestart:  !noRestart,
+		RollingRestart: rollingRestart,
+		BatchSize:      batchSize,
+		Writer:         evt,
 	})
 }
 
@@ -1397,6 +1794,7 @@ func appLog(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		Source:       source,
 		InvertSource: invert,
 		Units:        units,
+		RequestID:    urlValues.Get("request-id"),
 		Token:        t,
 	}
 	logs, err := a.LastLogs(ctx, logService, listArgs)
@@ -1671,6 +2069,9 @@ func restart(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	if !allowed {
 		return permission.ErrUnauthorized
 	}
+	if err = checkPoolFreeze(r, t, &a, permission.PermAppUpdateRestart); err != nil {
+		return err
+	}
 	evt, err := event.New(&event.Opts{
 		Target:        appTarget(appName),
 		Kind:          permission.PermAppUpdateRestart,
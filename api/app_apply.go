@@ -0,0 +1,329 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/reconcile"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/service"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+var manifestFieldPermissions = map[string]*permission.PermissionScheme{
+	"description": permission.PermAppUpdateDescription,
+	"platform":    permission.PermAppUpdatePlatform,
+	"pool":        permission.PermAppUpdatePool,
+	"plan":        permission.PermAppUpdatePlan,
+	"team_owner":  permission.PermAppUpdateTeamowner,
+	"tags":        permission.PermAppUpdateTags,
+}
+
+// title: app apply
+// path: /apps/apply
+// method: POST
+// consume: application/x-yaml
+// produce: application/x-json-stream
+// responses:
+//   200: Manifest applied
+//   400: Invalid manifest
+//   401: Unauthorized
+func appApply(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	data, err := context.GetBody(r)
+	if err != nil {
+		return err
+	}
+	m, err := manifest.Parse(data)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry-run"))
+	prune, _ := strconv.ParseBool(r.URL.Query().Get("prune"))
+	keepSpec, _ := strconv.ParseBool(r.URL.Query().Get("reconcile"))
+	var current *manifest.Manifest
+	a, err := app.GetByName(ctx, m.Name)
+	switch err {
+	case nil:
+		if !permission.Check(t, permission.PermAppRead, contextsForApp(a)...) {
+			return permission.ErrUnauthorized
+		}
+		if keepSpec && !permission.Check(t, permission.PermAppUpdateReconcile, contextsForApp(a)...) {
+			return permission.ErrUnauthorized
+		}
+		current, err = reconcile.State(a)
+		if err != nil {
+			return err
+		}
+	case appTypes.ErrAppNotFound:
+		a = nil
+	default:
+		return err
+	}
+	changes := manifest.Diff(current, *m, prune)
+	if dryRun {
+		w.Header().Set("Content-Type", "application/json")
+		if changes == nil {
+			changes = []manifest.Change{}
+		}
+		return json.NewEncoder(w).Encode(changes)
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	if len(changes) == 0 {
+		fmt.Fprintf(writer, "App %q is up to date.\n", m.Name)
+	} else {
+		for _, change := range changes {
+			if (change.Kind == manifest.ChangeUnitsAdd || change.Kind == manifest.ChangeUnitsRemove) && (a == nil || a.Deploys == 0) {
+				fmt.Fprintf(writer, "---- skipping %s: app has not been deployed yet ----\n", change)
+				continue
+			}
+			fmt.Fprintf(writer, "---- %s ----\n", change)
+			err = applyManifestChange(r, t, a, m, change, writer)
+			if err != nil {
+				return err
+			}
+			a, err = app.GetByName(ctx, m.Name)
+			if err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(writer, "App %q converged to manifest.\n", m.Name)
+	}
+	if !keepSpec {
+		return nil
+	}
+	if !permission.Check(t, permission.PermAppUpdateReconcile, contextsForApp(a)...) {
+		return permission.ErrUnauthorized
+	}
+	err = reconcile.SaveSpec(*m, prune)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "Manifest stored, app %q will be continuously reconciled.\n", m.Name)
+	return nil
+}
+
+func newApplyEvent(r *http.Request, t auth.Token, a *app.App, kind *permission.PermissionScheme, change manifest.Change, extraTargets ...event.ExtraTarget) (*event.Event, error) {
+	return event.New(&event.Opts{
+		Target:       appTarget(a.Name),
+		ExtraTargets: extraTargets,
+		Kind:         kind,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   change,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+}
+
+func applyManifestChange(r *http.Request, t auth.Token, a *app.App, m *manifest.Manifest, change manifest.Change, w io.Writer) (err error) {
+	switch change.Kind {
+	case manifest.ChangeCreate:
+		return applyManifestCreate(r, t, m, change)
+	case manifest.ChangeUpdate:
+		return applyManifestUpdate(r, t, a, m, change, w)
+	case manifest.ChangeEnvSet, manifest.ChangeEnvUnset:
+		return applyManifestEnvs(r, t, a, m, change, w)
+	case manifest.ChangeBind, manifest.ChangeUnbind:
+		return applyManifestBinding(r, t, a, change, w)
+	case manifest.ChangeCNameAdd, manifest.ChangeCNameRemove:
+		return applyManifestCNames(r, t, a, change)
+	case manifest.ChangeUnitsAdd, manifest.ChangeUnitsRemove:
+		return applyManifestUnits(r, t, a, change, w)
+	}
+	return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("unknown change %q", change.Kind)}
+}
+
+func applyManifestCreate(r *http.Request, t auth.Token, m *manifest.Manifest, change manifest.Change) (err error) {
+	ctx := r.Context()
+	a := app.App{
+		Name:        m.Name,
+		Description: m.Description,
+		Platform:    m.Platform,
+		Pool:        m.Pool,
+		Plan:        appTypes.Plan{Name: m.Plan},
+		TeamOwner:   m.TeamOwner,
+		Tags:        m.Tags,
+	}
+	if a.TeamOwner == "" {
+		a.TeamOwner, err = autoTeamOwner(ctx, t, permission.PermAppCreate)
+		if err != nil {
+			return err
+		}
+	}
+	if !permission.Check(t, permission.PermAppCreate, permission.Context(permTypes.CtxTeam, a.TeamOwner)) {
+		return permission.ErrUnauthorized
+	}
+	u, err := auth.ConvertNewUser(t.User())
+	if err != nil {
+		return err
+	}
+	evt, err := newApplyEvent(r, t, &a, permission.PermAppCreate, change)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = app.CreateApp(ctx, &a, u)
+	if err != nil {
+		if e, ok := err.(*appTypes.AppCreationError); ok && e.Err == app.ErrAppAlreadyExists {
+			return &errors.HTTP{Code: http.StatusConflict, Message: e.Error()}
+		}
+		if _, ok := err.(appTypes.NoTeamsError); ok {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "Cannot create app without teams."}
+		}
+	}
+	return err
+}
+
+func applyManifestUpdate(r *http.Request, t auth.Token, a *app.App, m *manifest.Manifest, change manifest.Change, w io.Writer) (err error) {
+	for _, field := range change.Fields {
+		if !permission.Check(t, manifestFieldPermissions[field], contextsForApp(a)...) {
+			return permission.ErrUnauthorized
+		}
+	}
+	evt, err := newApplyEvent(r, t, a, permission.PermAppUpdate, change)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.SetLogWriter(w)
+	err = a.Update(app.UpdateAppArgs{
+		UpdateData:    reconcile.UpdateData(m, change.Fields),
+		Writer:        evt,
+		ShouldRestart: true,
+	})
+	if err == appTypes.ErrPlanNotFound {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+func applyManifestEnvs(r *http.Request, t auth.Token, a *app.App, m *manifest.Manifest, change manifest.Change, w io.Writer) (err error) {
+	perm := permission.PermAppUpdateEnvSet
+	if change.Kind == manifest.ChangeEnvUnset {
+		perm = permission.PermAppUpdateEnvUnset
+	}
+	if !permission.Check(t, perm, contextsForApp(a)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := newApplyEvent(r, t, a, perm, change)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.SetLogWriter(w)
+	if change.Kind == manifest.ChangeEnvUnset {
+		return a.UnsetEnvs(bind.UnsetEnvArgs{
+			VariableNames: change.Names,
+			ShouldRestart: true,
+			Writer:        evt,
+		})
+	}
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs:          reconcile.EnvVars(a, m, change.Names),
+		ShouldRestart: true,
+		Writer:        evt,
+	})
+	if v, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+func applyManifestBinding(r *http.Request, t auth.Token, a *app.App, change manifest.Change, w io.Writer) (err error) {
+	serviceName, instanceName := change.Binding.Service, change.Binding.Instance
+	instance, err := getServiceInstanceOrError(r.Context(), serviceName, instanceName)
+	if err != nil {
+		return err
+	}
+	appPerm, instancePerm := permission.PermAppUpdateBind, permission.PermServiceInstanceUpdateBind
+	if change.Kind == manifest.ChangeUnbind {
+		appPerm, instancePerm = permission.PermAppUpdateUnbind, permission.PermServiceInstanceUpdateUnbind
+	}
+	allowed := permission.Check(t, instancePerm,
+		append(permission.Contexts(permTypes.CtxTeam, instance.Teams),
+			permission.Context(permTypes.CtxTeam, instance.TeamOwner),
+			permission.Context(permTypes.CtxServiceInstance, instance.Name),
+		)...,
+	)
+	if !allowed || !permission.Check(t, appPerm, contextsForApp(a)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := newApplyEvent(r, t, a, appPerm, change, event.ExtraTarget{Target: serviceInstanceTarget(serviceName, instanceName)})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.SetLogWriter(w)
+	if change.Kind == manifest.ChangeUnbind {
+		return instance.UnbindApp(service.UnbindAppArgs{
+			App:       a,
+			Restart:   true,
+			Event:     evt,
+			RequestID: requestIDHeader(r),
+		})
+	}
+	return instance.BindApp(a, nil, true, evt, evt, requestIDHeader(r))
+}
+
+func applyManifestCNames(r *http.Request, t auth.Token, a *app.App, change manifest.Change) (err error) {
+	perm := permission.PermAppUpdateCnameAdd
+	if change.Kind == manifest.ChangeCNameRemove {
+		perm = permission.PermAppUpdateCnameRemove
+	}
+	if !permission.Check(t, perm, contextsForApp(a)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := newApplyEvent(r, t, a, perm, change)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	if change.Kind == manifest.ChangeCNameRemove {
+		return a.RemoveCName(change.Names...)
+	}
+	err = a.AddCName(change.Names...)
+	if err != nil && err.Error() == "Invalid cname" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+func applyManifestUnits(r *http.Request, t auth.Token, a *app.App, change manifest.Change, w io.Writer) (err error) {
+	perm := permission.PermAppUpdateUnitAdd
+	if change.Kind == manifest.ChangeUnitsRemove {
+		perm = permission.PermAppUpdateUnitRemove
+	}
+	if !permission.Check(t, perm, contextsForApp(a)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := newApplyEvent(r, t, a, perm, change)
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.SetLogWriter(w)
+	if change.Kind == manifest.ChangeUnitsRemove {
+		return a.RemoveUnits(r.Context(), change.Units, change.Process, "", evt)
+	}
+	return a.AddUnits(change.Units, change.Process, "", evt)
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/manifest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppApplyDryRunNewApp(c *check.C) {
+	body := strings.NewReader("name: myapp\nplatform: zend\nenv:\n  DEBUG: \"1\"\n")
+	request, err := http.NewRequest(http.MethodPost, "/apps/apply?dry-run=true", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-yaml")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var changes []manifest.Change
+	err = json.Unmarshal(recorder.Body.Bytes(), &changes)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []manifest.Change{
+		{Kind: manifest.ChangeCreate},
+		{Kind: manifest.ChangeEnvSet, Names: []string{"DEBUG"}},
+	})
+	_, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.NotNil)
+}
+
+func (s *S) TestAppApplyExistingApp(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{{Name: "OLD", Value: "x", Public: true}}})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"name": "myapp", "description": "managed by git", "env": {"DEBUG": "1"}}`)
+	request, err := http.NewRequest(http.MethodPost, "/apps/apply?prune=true", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*converged to manifest.*`)
+	updated, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Description, check.Equals, "managed by git")
+	c.Assert(updated.Env["DEBUG"].Value, check.Equals, "1")
+	_, ok := updated.Env["OLD"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestAppApplyInvalidManifest(c *check.C) {
+	body := strings.NewReader("platform: zend\n")
+	request, err := http.NewRequest(http.MethodPost, "/apps/apply", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-yaml")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, manifest.ErrNameRequired.Error()+"\n")
+}
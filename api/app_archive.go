@@ -0,0 +1,110 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/snapshot"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: app archive
+// path: /apps/{app}/archive
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App not found
+//   409: App already archived
+func appArchive(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateArchive, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	if a.Archived != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: app.ErrAppArchived.Error()}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateArchive,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	// the snapshot keeps the app configuration, including env and metadata,
+	// as it was when archived, allowing it to be restored later.
+	if _, err = snapshot.Take(&a); err != nil {
+		return errors.Wrap(err, "unable to snapshot app configuration")
+	}
+	err = a.Archive(r.Context(), t.GetUserName(), evt)
+	if err != nil {
+		return err
+	}
+	evt.SetOtherCustomData(a.Archived)
+	return nil
+}
+
+// title: app unarchive
+// path: /apps/{app}/unarchive
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App not found
+//   409: App not archived
+func appUnarchive(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateUnarchive, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	if a.Archived == nil {
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: app.ErrAppNotArchived.Error()}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateUnarchive,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	return a.Unarchive(r.Context(), evt)
+}
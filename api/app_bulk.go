@@ -0,0 +1,230 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/admission"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+)
+
+const (
+	bulkActionRestart = "restart"
+	bulkActionStop    = "stop"
+	bulkActionStart   = "start"
+	bulkActionEnvSet  = "env-set"
+	bulkActionDeploy  = "deploy"
+)
+
+var bulkActionPerms = map[string]*permission.PermissionScheme{
+	bulkActionRestart: permission.PermAppUpdateRestart,
+	bulkActionStop:    permission.PermAppUpdateStop,
+	bulkActionStart:   permission.PermAppUpdateStart,
+	bulkActionEnvSet:  permission.PermAppUpdateEnvSet,
+	bulkActionDeploy:  permission.PermAppDeployImage,
+}
+
+type bulkInput struct {
+	Tags      []string
+	Pool      string
+	TeamOwner string
+	Action    string
+	Process   string
+	Envs      []apiTypes.Env
+	Private   bool
+	NoRestart bool
+	Image     string
+	Message   string
+	Preview   bool
+}
+
+// title: app bulk action
+// path: /apps/bulk
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   204: No apps matched
+//   400: Invalid data
+//   401: Unauthorized
+func appBulk(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var input bulkInput
+	err = ParseInput(r, &input)
+	if err != nil {
+		return err
+	}
+	tags, _ := InputValues(r, "tag")
+	input.Tags = append(input.Tags, tags...)
+	if len(input.Tags) == 0 && input.Pool == "" && input.TeamOwner == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "you must select apps by tag, pool or team owner"}
+	}
+	perm, ok := bulkActionPerms[input.Action]
+	if !ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid action %q", input.Action)}
+	}
+	switch input.Action {
+	case bulkActionEnvSet:
+		if len(input.Envs) == 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "You must provide the list of environment variables"}
+		}
+		if err = checkInternalEnvs(input.Envs); err != nil {
+			return err
+		}
+	case bulkActionDeploy:
+		if input.Image == "" {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "you must specify the image to deploy"}
+		}
+	}
+	contexts := permission.ContextsForPermission(t, perm)
+	if len(contexts) == 0 {
+		return permission.ErrUnauthorized
+	}
+	filter := &app.Filter{Tags: input.Tags, Pool: input.Pool, TeamOwner: input.TeamOwner}
+	apps, err := app.List(ctx, appFilterByContext(contexts, filter))
+	if err != nil {
+		return err
+	}
+	if len(apps) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+	if input.Preview {
+		names := make([]string, len(apps))
+		for i := range apps {
+			names[i] = apps[i].Name
+		}
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(names)
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	var failed []string
+	for i := range apps {
+		a := &apps[i]
+		fmt.Fprintf(writer, "---- [%d/%d] %s app %q ----\n", i+1, len(apps), input.Action, a.Name)
+		if errApp := appBulkRun(r, t, a, input, writer); errApp != nil {
+			fmt.Fprintf(writer, "Unable to %s app %q: %s\n", input.Action, a.Name, errApp)
+			failed = append(failed, a.Name)
+		}
+	}
+	fmt.Fprintf(writer, "\n---- %d of %d apps succeeded ----\n", len(apps)-len(failed), len(apps))
+	if len(failed) > 0 {
+		return fmt.Errorf("%s failed for apps: %s", input.Action, strings.Join(failed, ", "))
+	}
+	fmt.Fprintln(writer, "\nOK")
+	return nil
+}
+
+func appBulkRun(r *http.Request, t auth.Token, a *app.App, input bulkInput, w io.Writer) (err error) {
+	if input.Action == bulkActionRestart {
+		if err = checkPoolFreeze(r, t, a, bulkActionPerms[input.Action]); err != nil {
+			return err
+		}
+	}
+	if input.Action == bulkActionDeploy {
+		opts := app.DeployOptions{
+			App:     a,
+			Image:   input.Image,
+			Origin:  "image",
+			User:    t.GetUserName(),
+			Message: input.Message,
+		}
+		review := appDeployAdmission{
+			Kind:    string(opts.GetKind()),
+			Origin:  opts.Origin,
+			Image:   opts.Image,
+			Message: opts.Message,
+		}
+		err = reviewAdmission(r.Context(), admission.OperationAppDeploy, a.Name, t.GetUserName(), &review)
+		if err != nil {
+			return err
+		}
+		opts.Image = review.Image
+		opts.Message = review.Message
+		if err = checkDeployAllowed(r, t, opts, false); err != nil {
+			return err
+		}
+		return deployGroupApp(r, opts, w)
+	}
+	envs := apiTypes.Envs{Envs: input.Envs, Private: input.Private, NoRestart: input.NoRestart}
+	var toExclude []string
+	if input.Action == bulkActionEnvSet {
+		err = reviewAdmission(r.Context(), admission.OperationAppEnvSet, a.Name, t.GetUserName(), &envs)
+		if err != nil {
+			return err
+		}
+		if err = checkInternalEnvs(envs.Envs); err != nil {
+			return err
+		}
+		for i, env := range input.Envs {
+			if (env.Private != nil && *env.Private) || input.Private {
+				toExclude = append(toExclude, fmt.Sprintf("Envs.%d.Value", i))
+			}
+		}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:        appTarget(a.Name),
+		Kind:          bulkActionPerms[input.Action],
+		Owner:         t,
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    event.FormToCustomData(InputFields(r, toExclude...)),
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(a)...),
+		Cancelable:    input.Action != bulkActionEnvSet,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.SetLogWriter(w)
+	ctx, cancel := evt.CancelableContext(a.Context())
+	defer cancel()
+	a.ReplaceContext(ctx)
+	switch input.Action {
+	case bulkActionRestart:
+		return a.Restart(ctx, input.Process, "", evt)
+	case bulkActionStop:
+		return a.Stop(ctx, evt, input.Process, "")
+	case bulkActionStart:
+		return a.Start(ctx, evt, input.Process, "")
+	}
+	variables := make([]bind.EnvVar, 0, len(envs.Envs))
+	for _, v := range envs.Envs {
+		private := envs.Private
+		if v.Private != nil && *v.Private {
+			private = true
+		}
+		variables = append(variables, bind.EnvVar{
+			Name:   v.Name,
+			Value:  v.Value,
+			Public: !private,
+			Alias:  v.Alias,
+		})
+	}
+	return a.SetEnvs(bind.SetEnvArgs{
+		Envs:          variables,
+		ShouldRestart: !envs.NoRestart,
+		Writer:        evt,
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createBulkApps(c *check.C) {
+	for _, a := range []app.App{
+		{Name: "front1", Platform: "zend", TeamOwner: s.team.Name, Tags: []string{"tier:front"}},
+		{Name: "front2", Platform: "zend", TeamOwner: s.team.Name, Tags: []string{"tier:front", "critical"}},
+		{Name: "back", Platform: "zend", TeamOwner: s.team.Name, Tags: []string{"tier:back"}},
+	} {
+		a := a
+		err := app.CreateApp(context.TODO(), &a, s.user)
+		c.Assert(err, check.IsNil)
+		newSuccessfulAppVersion(c, &a)
+	}
+}
+
+func (s *S) doBulkRequest(c *check.C, body string, token string) *httptest.ResponseRecorder {
+	request, err := http.NewRequest("POST", "/apps/bulk", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+token)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *S) TestAppBulkRequiresSelector(c *check.C) {
+	recorder := s.doBulkRequest(c, "action=restart", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "you must select apps by tag, pool or team owner\n")
+}
+
+func (s *S) TestAppBulkInvalidAction(c *check.C) {
+	recorder := s.doBulkRequest(c, "tag=tier:front&action=destroy", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid action \"destroy\"\n")
+}
+
+func (s *S) TestAppBulkPreview(c *check.C) {
+	s.createBulkApps(c)
+	recorder := s.doBulkRequest(c, "tag=tier:front&action=restart&preview=true", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	var names []string
+	err := json.Unmarshal(recorder.Body.Bytes(), &names)
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.DeepEquals, []string{"front1", "front2"})
+}
+
+func (s *S) TestAppBulkNoMatch(c *check.C) {
+	s.createBulkApps(c)
+	recorder := s.doBulkRequest(c, "tag=tier:none&action=restart", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestAppBulkRestart(c *check.C) {
+	config.Set("docker:router", "fake")
+	defer config.Unset("docker:router")
+	s.createBulkApps(c)
+	recorder := s.doBulkRequest(c, "tag=tier:front&action=restart", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	body := recorder.Body.String()
+	c.Assert(body, check.Matches, `(?s).*\[1/2\] restart app \\"front1\\".*\[2/2\] restart app \\"front2\\".*2 of 2 apps succeeded.*`)
+	c.Assert(strings.Contains(body, `\"back\"`), check.Equals, false)
+	for _, name := range []string{"front1", "front2"} {
+		c.Assert(eventtest.EventDesc{
+			Target: appTarget(name),
+			Owner:  s.token.GetUserName(),
+			Kind:   "app.update.restart",
+		}, eventtest.HasEvent)
+	}
+}
+
+func (s *S) TestAppBulkEnvSet(c *check.C) {
+	s.createBulkApps(c)
+	recorder := s.doBulkRequest(c, "tag=critical&action=env-set&noRestart=true&envs.0.name=LOG_LEVEL&envs.0.value=debug", s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	a, err := app.GetByName(context.TODO(), "front2")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Env["LOG_LEVEL"].Value, check.Equals, "debug")
+	a, err = app.GetByName(context.TODO(), "front1")
+	c.Assert(err, check.IsNil)
+	_, ok := a.Env["LOG_LEVEL"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestAppBulkOnlyAllowedApps(c *check.C) {
+	s.createBulkApps(c)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppUpdateRestart,
+		Context: permission.Context(permTypes.CtxApp, "front2"),
+	})
+	recorder := s.doBulkRequest(c, "tag=tier:front&action=restart&preview=true", token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var names []string
+	err := json.Unmarshal(recorder.Body.Bytes(), &names)
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.DeepEquals, []string{"front2"})
+}
+
+func (s *S) TestAppBulkUnauthorized(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	recorder := s.doBulkRequest(c, "tag=tier:front&action=restart", token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
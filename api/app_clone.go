@@ -0,0 +1,153 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: app clone
+// path: /apps/{app}/clone
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: App cloned
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+//   409: App already exists
+func appClone(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	src, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	opts := app.CloneOptions{
+		Name:        InputValue(r, "name"),
+		TeamOwner:   InputValue(r, "team-owner"),
+		Description: InputValue(r, "description"),
+		RequestID:   requestIDHeader(r),
+	}
+	if opts.Name == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "name is required"}
+	}
+	var deploy bool
+	for field, value := range map[string]*bool{"copy-secrets": &opts.CopySecrets, "bind": &opts.Bind, "deploy": &deploy} {
+		if v := InputValue(r, field); v != "" {
+			*value, err = strconv.ParseBool(v)
+			if err != nil {
+				return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid %s: %s", field, err)}
+			}
+		}
+	}
+	teamOwner := opts.TeamOwner
+	if teamOwner == "" {
+		teamOwner = src.TeamOwner
+	}
+	allowed := permission.Check(t, permission.PermAppReadEnv, contextsForApp(&src)...) &&
+		permission.Check(t, permission.PermAppCreate, permission.Context(permTypes.CtxTeam, teamOwner))
+	if opts.Bind {
+		allowed = allowed && permission.Check(t, permission.PermAppUpdateBind, contextsForApp(&src)...)
+	}
+	if t.IsAppToken() || !allowed {
+		return permission.ErrUnauthorized
+	}
+	_, err = app.GetByName(ctx, opts.Name)
+	if err == nil {
+		return &errors.HTTP{Code: http.StatusConflict, Message: app.ErrAppAlreadyExists.Error()}
+	}
+	if err != appTypes.ErrAppNotFound {
+		return err
+	}
+	var image string
+	if deploy {
+		version, versionErr := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, &src)
+		if versionErr != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("unable to find a deployed image for app %q: %v", src.Name, versionErr)}
+		}
+		image = version.VersionInfo().DeployImage
+	}
+	opts.User, err = auth.ConvertNewUser(t.User())
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(opts.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: appTarget(src.Name)}},
+		Kind:         permission.PermAppCreate,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(&src)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	opts.Writer = evt
+	opts.Event = evt
+	clone, err := app.Clone(ctx, &src, opts)
+	if err != nil {
+		return err
+	}
+	if image == "" {
+		fmt.Fprintf(writer, "\nApp %s cloned from %s.\n", clone.Name, src.Name)
+		return nil
+	}
+	deployOpts := app.DeployOptions{
+		App:          clone,
+		Image:        image,
+		Origin:       "image",
+		User:         t.GetUserName(),
+		Message:      fmt.Sprintf("clone of app %s", src.Name),
+		OutputStream: writer,
+	}
+	deployOpts.GetKind()
+	if err = checkDeployAllowed(r, t, deployOpts, false); err != nil {
+		return err
+	}
+	var imageID string
+	deployEvt, err := event.New(&event.Opts{
+		Target:        appTarget(clone.Name),
+		Kind:          permission.PermAppDeploy,
+		Owner:         t,
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    deployOpts,
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(clone)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(clone)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { deployEvt.DoneCustomData(err, app.NewDeployEndData(deployEvt, clone, imageID)) }()
+	deployOpts.Event = deployEvt
+	imageID, err = app.Deploy(ctx, deployOpts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "\nApp %s cloned from %s and deployed.\n", clone.Name, src.Name)
+	return nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/admission"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// title: app dependency add
+// path: /apps/{app}/dependencies
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Dependency added
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+//   409: Dependency cycle or deploy blocked by pool freeze, approval or gate
+func appDependencyAdd(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateDependency, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	dependency := InputValue(r, "dependency")
+	if dependency == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "the dependency parameter is required"}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(a.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: appTarget(dependency)}},
+		Kind:         permission.PermAppUpdateDependency,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = a.AddDependency(r.Context(), dependency)
+	if cycleErr, ok := err.(*app.DependencyCycleError); ok {
+		return &errors.HTTP{Code: http.StatusConflict, Message: cycleErr.Error()}
+	}
+	if validationErr, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: validationErr.Message}
+	}
+	return err
+}
+
+// title: app dependency remove
+// path: /apps/{app}/dependencies/{dependency}
+// method: DELETE
+// responses:
+//   200: Dependency removed
+//   401: Unauthorized
+//   404: App or dependency not found
+func appDependencyRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateDependency, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	dependency := r.URL.Query().Get(":dependency")
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(a.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: appTarget(dependency)}},
+		Kind:         permission.PermAppUpdateDependency,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = a.RemoveDependency(dependency)
+	if err == app.ErrDependencyNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+type groupDeployApp struct {
+	Name       string
+	Image      string
+	ArchiveURL string `json:"archive-url" form:"archive-url"`
+}
+
+type groupDeployInput struct {
+	Apps    []groupDeployApp
+	Message string
+}
+
+// title: group deploy
+// path: /deploy/group
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: OK
+//   400: Invalid data
+//   403: Forbidden
+//   404: App not found
+//   409: Dependency cycle or deploy blocked by pool freeze, approval or gate
+func deployGroup(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	var input groupDeployInput
+	err = ParseInput(r, &input)
+	if err != nil {
+		return err
+	}
+	if len(input.Apps) == 0 {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "no apps to deploy"}
+	}
+	apps := make([]*app.App, 0, len(input.Apps))
+	opts := make(map[string]app.DeployOptions, len(input.Apps))
+	for _, item := range input.Apps {
+		if _, ok := opts[item.Name]; ok {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("app %q is listed more than once", item.Name)}
+		}
+		if (item.Image == "") == (item.ArchiveURL == "") {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("you must specify either the archive-url or the image of app %q", item.Name)}
+		}
+		instance, errGet := app.GetByName(ctx, item.Name)
+		if errGet == appTypes.ErrAppNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("app %q not found", item.Name)}
+		}
+		if errGet != nil {
+			return errGet
+		}
+		deployOpts := app.DeployOptions{
+			App:        instance,
+			Image:      item.Image,
+			ArchiveURL: item.ArchiveURL,
+			User:       t.GetUserName(),
+			Message:    input.Message,
+		}
+		if deployOpts.Image != "" {
+			deployOpts.Origin = "image"
+		}
+		if !permission.Check(t, permSchemeForDeploy(deployOpts), contextsForApp(instance)...) {
+			return &errors.HTTP{Code: http.StatusForbidden, Message: fmt.Sprintf("User does not have permission to deploy app %q", item.Name)}
+		}
+		review := appDeployAdmission{
+			Kind:       string(deployOpts.GetKind()),
+			Origin:     deployOpts.Origin,
+			Image:      deployOpts.Image,
+			ArchiveURL: deployOpts.ArchiveURL,
+			Message:    deployOpts.Message,
+		}
+		err = reviewAdmission(ctx, admission.OperationAppDeploy, item.Name, t.GetUserName(), &review)
+		if err != nil {
+			return err
+		}
+		if deployOpts.Image != "" {
+			deployOpts.Image = review.Image
+		}
+		deployOpts.Message = review.Message
+		if err = checkDeployAllowed(r, t, deployOpts, false); err != nil {
+			return err
+		}
+		opts[item.Name] = deployOpts
+		apps = append(apps, instance)
+	}
+	if _, err = app.DeployOrder(apps); err != nil {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	w.Header().Set("Content-Type", "text")
+	writer := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "please wait...")
+	defer writer.Stop()
+	results, err := app.DeployGroup(apps, func(instance *app.App) error {
+		fmt.Fprintf(writer, "---- Deploying app %q ----\n", instance.Name)
+		return deployGroupApp(r, opts[instance.Name], writer)
+	})
+	if err != nil {
+		return err
+	}
+	var failed []string
+	fmt.Fprintln(writer, "\n---- Group deploy summary ----")
+	for _, result := range results {
+		if result.Error != "" {
+			fmt.Fprintf(writer, "%s: %s (%s)\n", result.App, result.Status, result.Error)
+		} else {
+			fmt.Fprintf(writer, "%s: %s\n", result.App, result.Status)
+		}
+		if result.Status != app.GroupDeploySucceeded {
+			failed = append(failed, result.App)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("apps not deployed: %s", strings.Join(failed, ", "))
+	}
+	fmt.Fprintln(writer, "\nOK")
+	return nil
+}
+
+func deployGroupApp(r *http.Request, opts app.DeployOptions, w io.Writer) (err error) {
+	instance := opts.App
+	var imageID string
+	evt, err := event.New(&event.Opts{
+		Target:        appTarget(instance.Name),
+		Kind:          permission.PermAppDeploy,
+		RawOwner:      event.Owner{Type: event.OwnerTypeUser, Name: opts.User},
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    opts,
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(instance)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.DoneCustomData(err, app.NewDeployEndData(evt, instance, imageID)) }()
+	ctx, cancel := evt.CancelableContext(instance.Context())
+	defer cancel()
+	instance.ReplaceContext(ctx)
+	opts.Event = evt
+	opts.OutputStream = w
+	imageID, err = app.Deploy(ctx, opts)
+	return err
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/builder"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppDependencyAdd(c *check.C) {
+	api := app.App{Name: "api", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &api, s.user)
+	c.Assert(err, check.IsNil)
+	worker := app.App{Name: "worker", Platform: "zend", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &worker, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/worker/dependencies", strings.NewReader("dependency=api"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), "worker")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Dependencies, check.DeepEquals, []string{"api"})
+	request, err = http.NewRequest("POST", "/apps/api/dependencies", strings.NewReader("dependency=worker"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Equals, "dependency cycle found: api -> worker -> api\n")
+}
+
+func (s *S) TestAppDependencyRemove(c *check.C) {
+	api := app.App{Name: "api", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &api, s.user)
+	c.Assert(err, check.IsNil)
+	worker := app.App{Name: "worker", Platform: "zend", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &worker, s.user)
+	c.Assert(err, check.IsNil)
+	err = worker.AddDependency(context.TODO(), "api")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/apps/worker/dependencies/api", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	dbApp, err := app.GetByName(context.TODO(), "worker")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Dependencies, check.HasLen, 0)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) TestDeployGroup(c *check.C) {
+	var deployed []string
+	s.builder.OnBuild = func(p provision.BuilderDeploy, a provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
+		deployed = append(deployed, a.GetName())
+		return newAppVersion(c, a), nil
+	}
+	api := app.App{Name: "api", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &api, s.user)
+	c.Assert(err, check.IsNil)
+	worker := app.App{Name: "worker", Platform: "python", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &worker, s.user)
+	c.Assert(err, check.IsNil)
+	err = api.AddDependency(context.TODO(), "worker")
+	c.Assert(err, check.IsNil)
+	body := `{"apps": [{"name": "api", "image": "tsuru/api"}, {"name": "worker", "image": "tsuru/worker"}]}`
+	request, err := http.NewRequest("POST", "/deploy/group", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(deployed, check.DeepEquals, []string{"worker", "api"})
+	c.Assert(recorder.Body.String(), check.Matches, `(?s)---- Deploying app "worker" ----.*---- Deploying app "api" ----.*worker: succeeded\napi: succeeded\n\nOK\n`)
+}
+
+func (s *DeploySuite) TestDeployGroupAbortsDownstream(c *check.C) {
+	var deployed []string
+	s.builder.OnBuild = func(p provision.BuilderDeploy, a provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
+		deployed = append(deployed, a.GetName())
+		if a.GetName() == "worker" {
+			return nil, errors.New("healthcheck failed")
+		}
+		return newAppVersion(c, a), nil
+	}
+	for _, name := range []string{"api", "docs", "worker"} {
+		a := app.App{Name: name, Platform: "python", TeamOwner: s.team.Name}
+		err := app.CreateApp(context.TODO(), &a, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	api, err := app.GetByName(context.TODO(), "api")
+	c.Assert(err, check.IsNil)
+	err = api.AddDependency(context.TODO(), "worker")
+	c.Assert(err, check.IsNil)
+	body := `{"apps": [{"name": "api", "image": "tsuru/api"}, {"name": "docs", "image": "tsuru/docs"}, {"name": "worker", "image": "tsuru/worker"}]}`
+	request, err := http.NewRequest("POST", "/deploy/group", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(deployed, check.DeepEquals, []string{"worker", "docs"})
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*worker: failed \(.*healthcheck failed.*\)\napi: aborted \(dependency "worker" was not deployed\)\ndocs: succeeded\n.*apps not deployed: worker, api\n`)
+}
+
+func (s *DeploySuite) TestDeployGroupRequiresApproval(c *check.C) {
+	var deployed []string
+	s.builder.OnBuild = func(p provision.BuilderDeploy, a provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
+		deployed = append(deployed, a.GetName())
+		return newAppVersion(c, a), nil
+	}
+	api := app.App{Name: "api", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &api, s.user)
+	c.Assert(err, check.IsNil)
+	worker := app.App{Name: "worker", Platform: "python", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &worker, s.user)
+	c.Assert(err, check.IsNil)
+	err = worker.SetDeployApproval(true)
+	c.Assert(err, check.IsNil)
+	body := `{"apps": [{"name": "api", "image": "tsuru/api"}, {"name": "worker", "image": "tsuru/worker"}]}`
+	request, err := http.NewRequest("POST", "/deploy/group", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Equals, errDeployNeedsApproval.Message+"\n")
+	c.Assert(deployed, check.HasLen, 0)
+}
+
+func (s *DeploySuite) TestDeployGroupBlockedByGate(c *check.C) {
+	gate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("error budget exhausted"))
+	}))
+	defer gate.Close()
+	var deployed []string
+	s.builder.OnBuild = func(p provision.BuilderDeploy, a provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
+		deployed = append(deployed, a.GetName())
+		return newAppVersion(c, a), nil
+	}
+	worker := app.App{Name: "worker", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &worker, s.user)
+	c.Assert(err, check.IsNil)
+	err = worker.SetDeployGates([]app.DeployGate{{Name: "budget", URL: gate.URL}})
+	c.Assert(err, check.IsNil)
+	body := `{"apps": [{"name": "worker", "image": "tsuru/worker"}]}`
+	request, err := http.NewRequest("POST", "/deploy/group", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Equals, "deploy blocked by gate \"budget\": error budget exhausted\n")
+	c.Assert(deployed, check.HasLen, 0)
+}
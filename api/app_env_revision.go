@@ -0,0 +1,167 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: app env revisions
+// path: /apps/{app}/env/revisions
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func appEnvRevisionList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppReadEnv, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	versions, err := a.ConfigVersions()
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	for i := range versions {
+		versions[i].SuppressSensitiveEnvs()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(versions)
+}
+
+// title: app configuration versions
+// path: /apps/{app}/env/versions
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func appConfigVersionList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	// Configuration versions are the env revisions, the endpoint is kept
+	// for clients using the rolling restart API.
+	return appEnvRevisionList(w, r, t)
+}
+
+// title: app env revision diff
+// path: /apps/{app}/env/revisions/{revision}/diff
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App or revision not found
+func appEnvRevisionDiff(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppReadEnv, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	to, err := strconv.Atoi(r.URL.Query().Get(":revision"))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid revision: " + err.Error()}
+	}
+	var from int
+	if value := InputValue(r, "from"); value != "" {
+		from, err = strconv.Atoi(value)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid from: " + err.Error()}
+		}
+	}
+	diff, err := a.DiffConfigVersions(from, to)
+	if err == app.ErrConfigVersionNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(diff)
+}
+
+// title: app env rollback
+// path: /apps/{app}/env/rollback
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App or configuration version not found
+func appEnvRollback(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateEnvRollback, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	var version, batchSize int
+	// version is the name of the parameter in the rolling restart API,
+	// accepted as an alias of revision.
+	for _, param := range []string{"revision", "version"} {
+		value := InputValue(r, param)
+		if value == "" {
+			continue
+		}
+		version, err = strconv.Atoi(value)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid " + param + ": " + err.Error()}
+		}
+		break
+	}
+	if value := InputValue(r, "batchSize"); value != "" {
+		batchSize, err = strconv.Atoi(value)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid batchSize: " + err.Error()}
+		}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateEnvRollback,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	_, err = a.RollbackConfigVersion(version, batchSize, evt)
+	if err == app.ErrConfigVersionNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
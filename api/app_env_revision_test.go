@@ -0,0 +1,135 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppEnvRevisionListAndRollback(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs:           []bind.EnvVar{{Name: "PASSWORD", Value: "secret"}, {Name: "DEBUG", Value: "1", Public: true}},
+		RollingRestart: true,
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps/myapp/env/revisions", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var versions []app.ConfigVersion
+	err = json.Unmarshal(recorder.Body.Bytes(), &versions)
+	c.Assert(err, check.IsNil)
+	c.Assert(versions, check.HasLen, 3)
+	c.Assert(versions[0].Env["PASSWORD"].Value, check.Equals, app.SuppressedEnv)
+	c.Assert(versions[0].Env["DEBUG"].Value, check.Equals, "1")
+	request, err = http.NewRequest("POST", "/apps/myapp/env/rollback", strings.NewReader("batchSize=2"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*Rolling back to configuration version 2.*`)
+	dbApp, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	_, ok := dbApp.Env["DEBUG"]
+	c.Assert(ok, check.Equals, false)
+	c.Assert(dbApp.Env["TSURU_APPNAME"].Value, check.Equals, "myapp")
+}
+
+func (s *S) TestAppEnvRollbackNotFound(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/myapp/env/rollback?revision=42", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestAppEnvRevisionDiff(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs: []bind.EnvVar{{Name: "DEBUG", Value: "1", Public: true}},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps/myapp/env/revisions/3/diff", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var diff app.ConfigVersionDiff
+	err = json.Unmarshal(recorder.Body.Bytes(), &diff)
+	c.Assert(err, check.IsNil)
+	c.Assert(diff, check.DeepEquals, app.ConfigVersionDiff{
+		From:    2,
+		To:      3,
+		Added:   []app.EnvChange{{Name: "DEBUG", NewValue: "1"}},
+		Removed: []app.EnvChange{},
+		Changed: []app.EnvChange{},
+	})
+	request, err = http.NewRequest("GET", "/apps/myapp/env/revisions/9/diff", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestAppConfigVersionListAndRollback(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs:           []bind.EnvVar{{Name: "DEBUG", Value: "1", Public: true}},
+		RollingRestart: true,
+	})
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs:           []bind.EnvVar{{Name: "DEBUG", Value: "2", Public: true}},
+		RollingRestart: true,
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps/myapp/env/versions", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var versions []app.ConfigVersion
+	err = json.Unmarshal(recorder.Body.Bytes(), &versions)
+	c.Assert(err, check.IsNil)
+	c.Assert(versions, check.HasLen, 4)
+	c.Assert(versions[0].Env["DEBUG"].Value, check.Equals, "2")
+	request, err = http.NewRequest("POST", "/apps/myapp/env/rollback", strings.NewReader("version=3"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*Rolling back to configuration version 3.*`)
+	dbApp, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["DEBUG"].Value, check.Equals, "1")
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/maintenance"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// title: app maintenance schedule
+// path: /apps/{app}/maintenance
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   201: Maintenance window scheduled
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+//   409: Maintenance window overlaps another one
+func appMaintenanceSchedule(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateMaintenance, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	window := maintenance.Window{
+		App:        a.Name,
+		BackendApp: InputValue(r, "backend-app"),
+		Owner:      t.GetUserName(),
+	}
+	window.Start, err = time.Parse(time.RFC3339, InputValue(r, "start"))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid start: " + err.Error()}
+	}
+	window.End, err = time.Parse(time.RFC3339, InputValue(r, "end"))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid end: " + err.Error()}
+	}
+	if stopUnits := InputValue(r, "stop-units"); stopUnits != "" {
+		window.StopUnits, err = strconv.ParseBool(stopUnits)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid stop-units: " + err.Error()}
+		}
+	}
+	if window.BackendApp != "" {
+		_, err = app.GetByName(r.Context(), window.BackendApp)
+		if err == appTypes.ErrAppNotFound {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "backend app not found"}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateMaintenance,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = maintenance.Schedule(&window)
+	switch err {
+	case nil:
+	case maintenance.ErrInvalidWindow:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	case maintenance.ErrWindowOverlaps:
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	default:
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(window)
+}
+
+// title: app maintenance list
+// path: /apps/{app}/maintenance
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func appMaintenanceList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppRead, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	windows, err := maintenance.List(a.Name)
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(windows)
+}
+
+// title: app maintenance cancel
+// path: /apps/{app}/maintenance/{id}
+// method: DELETE
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App or maintenance window not found
+func appMaintenanceCancel(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateMaintenance, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	id := r.URL.Query().Get(":id")
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateMaintenance,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]string{"canceled": id},
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = maintenance.Cancel(a.Name, id)
+	if err == maintenance.ErrWindowNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
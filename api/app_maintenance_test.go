@@ -0,0 +1,101 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/maintenance"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppMaintenanceSchedule(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	start := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	body := url.Values{
+		"start":      []string{start.Format(time.RFC3339)},
+		"end":        []string{start.Add(time.Hour).Format(time.RFC3339)},
+		"stop-units": []string{"true"},
+	}
+	request, err := http.NewRequest(http.MethodPost, "/apps/myapp/maintenance", strings.NewReader(body.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated)
+	var window maintenance.Window
+	err = json.Unmarshal(recorder.Body.Bytes(), &window)
+	c.Assert(err, check.IsNil)
+	c.Assert(window.Start.Equal(start), check.Equals, true)
+	c.Assert(window.StopUnits, check.Equals, true)
+	c.Assert(window.Status, check.Equals, maintenance.StatusScheduled)
+	request, err = http.NewRequest(http.MethodGet, "/apps/myapp/maintenance", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var windows []maintenance.Window
+	err = json.Unmarshal(recorder.Body.Bytes(), &windows)
+	c.Assert(err, check.IsNil)
+	c.Assert(windows, check.HasLen, 1)
+	c.Assert(windows[0].ID, check.Equals, window.ID)
+	request, err = http.NewRequest(http.MethodDelete, "/apps/myapp/maintenance/"+window.ID.Hex(), nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	windows, err = maintenance.List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(windows[0].Status, check.Equals, maintenance.StatusCanceled)
+}
+
+func (s *S) TestAppMaintenanceScheduleInvalid(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	start := time.Now().Add(time.Hour)
+	tests := []struct {
+		body     url.Values
+		expected string
+	}{
+		{url.Values{"start": {"tomorrow"}}, "invalid start.*"},
+		{url.Values{"start": {start.Format(time.RFC3339)}, "end": {start.Add(-time.Minute).Format(time.RFC3339)}}, "maintenance window must end after it starts.*"},
+		{url.Values{"start": {start.Format(time.RFC3339)}, "end": {start.Add(time.Hour).Format(time.RFC3339)}, "backend-app": {"unknown"}}, "backend app not found\n"},
+	}
+	for _, tt := range tests {
+		request, err := http.NewRequest(http.MethodPost, "/apps/myapp/maintenance", strings.NewReader(tt.body.Encode()))
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+		c.Assert(recorder.Body.String(), check.Matches, tt.expected)
+	}
+}
+
+func (s *S) TestAppMaintenanceCancelNotFound(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodDelete, "/apps/myapp/maintenance/5f1e5ff5e9a3a7b4d8b4c0a1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/deployhook"
+	"github.com/tsuru/tsuru/app/preview"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const previewCloseKind = "preview close"
+
+// title: app preview list
+// path: /apps/{app}/previews
+// method: GET
+// produce: application/json
+// responses:
+//   200: List previews
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func appPreviewList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppRead, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	previews, err := preview.List(a.Name)
+	if err != nil {
+		return err
+	}
+	if len(previews) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(previews)
+}
+
+// title: app preview create
+// path: /apps/{app}/previews
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: Preview created and deployed
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appPreviewCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	base, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if t.IsAppToken() ||
+		!permission.Check(t, permission.PermAppPreviewCreate, contextsForApp(&base)...) ||
+		!permission.Check(t, permission.PermAppCreate, permission.Context(permTypes.CtxTeam, base.TeamOwner)) {
+		return permission.ErrUnauthorized
+	}
+	opts := preview.CreateOptions{
+		Base:      &base,
+		PR:        InputValue(r, "pr"),
+		Image:     InputValue(r, "image"),
+		RequestID: requestIDHeader(r),
+	}
+	if ttl := InputValue(r, "ttl"); ttl != "" {
+		opts.TTL, err = time.ParseDuration(ttl)
+		if err != nil || opts.TTL <= 0 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid ttl %q", ttl)}
+		}
+	}
+	if shared := InputValue(r, "shared-bindings"); shared != "" {
+		opts.SharedBindings, err = strconv.ParseBool(shared)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid shared-bindings: " + err.Error()}
+		}
+	}
+	previewName, err := preview.Name(base.Name, opts.PR)
+	if err != nil {
+		return err
+	}
+	opts.User, err = auth.ConvertNewUser(t.User())
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(base.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: appTarget(previewName)}},
+		Kind:         permission.PermAppPreviewCreate,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(&base)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	opts.Event = evt
+	p, err := preview.Create(ctx, opts)
+	if err != nil {
+		return err
+	}
+	evt.SetOtherCustomData(p)
+	previewApp, err := app.GetByName(ctx, p.App)
+	if err != nil {
+		return err
+	}
+	deployOpts := app.DeployOptions{
+		App:          previewApp,
+		Image:        p.Image,
+		Origin:       "image",
+		User:         t.GetUserName(),
+		Message:      fmt.Sprintf("preview of pull request %s", p.PR),
+		OutputStream: writer,
+	}
+	deployOpts.GetKind()
+	if err = checkDeployAllowed(r, t, deployOpts, false); err != nil {
+		return err
+	}
+	var imageID string
+	deployEvt, err := event.New(&event.Opts{
+		Target:        appTarget(previewApp.Name),
+		Kind:          permission.PermAppDeploy,
+		Owner:         t,
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    deployOpts,
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(previewApp)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(previewApp)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { deployEvt.DoneCustomData(err, app.NewDeployEndData(deployEvt, previewApp, imageID)) }()
+	deployOpts.Event = deployEvt
+	imageID, err = app.Deploy(ctx, deployOpts)
+	if err != nil {
+		return err
+	}
+	if p.Hostname != "" {
+		fmt.Fprintf(writer, "\nPreview app %s is available at %s, until %s.\n", p.App, p.Hostname, p.ExpiresAt.Format(time.RFC3339))
+	} else {
+		fmt.Fprintf(writer, "\nPreview app %s deployed, it expires at %s.\n", p.App, p.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// title: app preview remove
+// path: /apps/{app}/previews/{pr}
+// method: DELETE
+// produce: application/x-json-stream
+// responses:
+//   200: Preview removed
+//   401: Unauthorized
+//   404: App or preview not found
+func appPreviewRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	base, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppPreviewDelete, contextsForApp(&base)...) {
+		return permission.ErrUnauthorized
+	}
+	p, err := preview.Get(base.Name, r.URL.Query().Get(":pr"))
+	if err == preview.ErrPreviewNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(base.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: appTarget(p.App)}},
+		Kind:         permission.PermAppPreviewDelete,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   p,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(&base)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	evt.SetLogWriter(&tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)})
+	return preview.Remove(r.Context(), p, evt, requestIDHeader(r))
+}
+
+// title: app preview pull request hook
+// path: /hooks/preview/{app}
+// method: POST
+// consume: application/json
+// responses:
+//   200: Event handled or ignored
+//   400: Invalid payload
+//   401: Invalid secret
+//   404: Deploy hook not found
+func appPreviewHookReceive(w http.ResponseWriter, r *http.Request) error {
+	appName := r.URL.Query().Get(":app")
+	hook, err := deployhook.Get(appName)
+	if err == deployhook.ErrHookNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxDeployHookPayload))
+	if err != nil {
+		return err
+	}
+	err = hook.Verify(r.Header, body)
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusUnauthorized, Message: err.Error()}
+	}
+	pr, err := deployhook.ParsePullRequest(r.Header, body)
+	if err == deployhook.ErrUnsupportedEvent {
+		fmt.Fprintln(w, "Event ignored.")
+		return nil
+	}
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if pr.Ping {
+		fmt.Fprintln(w, "pong")
+		return nil
+	}
+	if !pr.Closed {
+		fmt.Fprintf(w, "Pull request %s is not closed, event ignored.\n", pr.Number)
+		return nil
+	}
+	p, err := preview.Get(appName, pr.Number)
+	if err == preview.ErrPreviewNotFound {
+		fmt.Fprintf(w, "No preview for pull request %s.\n", pr.Number)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       appTarget(appName),
+		ExtraTargets: []event.ExtraTarget{{Target: appTarget(p.App)}},
+		InternalKind: previewCloseKind,
+		CustomData:   p,
+		Allowed: event.Allowed(permission.PermAppReadEvents,
+			permission.Context(permTypes.CtxApp, appName),
+			permission.Context(permTypes.CtxApp, p.App),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	go func() {
+		removeErr := preview.Remove(context.Background(), p, evt, "")
+		if removeErr != nil {
+			log.Errorf("[previews] unable to remove preview %q: %v", p.App, removeErr)
+		}
+		evt.Done(removeErr)
+	}()
+	fmt.Fprintf(w, "Removing preview app %s (event %s).\n", p.App, evt.UniqueID.Hex())
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/deployhook"
+	"github.com/tsuru/tsuru/app/preview"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createPreviewBaseApp(c *check.C) *app.App {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestAppPreviewCreate(c *check.C) {
+	s.createPreviewBaseApp(c)
+	body := strings.NewReader("pr=42&image=registry.example.com/myapp:pr-42&ttl=2h")
+	request, err := http.NewRequest("POST", "/1.13/apps/myapp/previews", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*Preview app myapp-pr-42 deployed.*`)
+	p, err := preview.Get("myapp", "42")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.App, check.Equals, "myapp-pr-42")
+	c.Assert(p.Image, check.Equals, "registry.example.com/myapp:pr-42")
+	c.Assert(p.ExpiresAt.After(time.Now().Add(time.Hour)), check.Equals, true)
+	previewApp, err := app.GetByName(context.TODO(), "myapp-pr-42")
+	c.Assert(err, check.IsNil)
+	c.Assert(previewApp.TeamOwner, check.Equals, s.team.Name)
+}
+
+func (s *S) TestAppPreviewCreateInvalidTTL(c *check.C) {
+	s.createPreviewBaseApp(c)
+	body := strings.NewReader("pr=42&image=myapp:v1&ttl=forever")
+	request, err := http.NewRequest("POST", "/1.13/apps/myapp/previews", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestAppPreviewCreateUnauthorized(c *check.C) {
+	s.createPreviewBaseApp(c)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppPreviewCreate,
+		Context: permission.Context(permTypes.CtxApp, "myapp"),
+	})
+	body := strings.NewReader("pr=42&image=myapp:v1")
+	request, err := http.NewRequest("POST", "/1.13/apps/myapp/previews", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppPreviewListEmpty(c *check.C) {
+	s.createPreviewBaseApp(c)
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/previews", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestAppPreviewList(c *check.C) {
+	base := s.createPreviewBaseApp(c)
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       appTarget("myapp"),
+		InternalKind: "preview-test",
+		Allowed:      event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = preview.Create(context.TODO(), preview.CreateOptions{Base: base, PR: "1", Image: "myapp:v1", User: s.user, Event: evt})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/previews", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var previews []preview.Preview
+	err = json.Unmarshal(recorder.Body.Bytes(), &previews)
+	c.Assert(err, check.IsNil)
+	c.Assert(previews, check.HasLen, 1)
+	c.Assert(previews[0].App, check.Equals, "myapp-pr-1")
+}
+
+func (s *S) TestAppPreviewRemoveNotFound(c *check.C) {
+	s.createPreviewBaseApp(c)
+	request, err := http.NewRequest("DELETE", "/1.13/apps/myapp/previews/42", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestAppPreviewHookIgnoresOpenPullRequest(c *check.C) {
+	s.createPreviewBaseApp(c)
+	err := deployhook.Set(&deployhook.Hook{App: "myapp", User: s.user.Email, Secret: "s3cr3t", Branches: []string{"main"}})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.13/hooks/preview/myapp", strings.NewReader(`{"object_attributes":{"iid":3,"action":"open"}}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	request.Header.Set("X-Gitlab-Token", "s3cr3t")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, "Pull request 3 is not closed, event ignored.\n")
+}
+
+func (s *S) TestAppPreviewHookInvalidSecret(c *check.C) {
+	s.createPreviewBaseApp(c)
+	err := deployhook.Set(&deployhook.Hook{App: "myapp", User: s.user.Email, Secret: "s3cr3t", Branches: []string{"main"}})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.13/hooks/preview/myapp", strings.NewReader(`{}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	request.Header.Set("X-Gitlab-Token", "wrong")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusUnauthorized)
+}
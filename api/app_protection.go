@@ -0,0 +1,67 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: app protection set
+// path: /apps/{app}/protection
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Protection updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appProtectionSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	// only admins of the team owning the app may change its protection.
+	if !permission.Check(t, permission.PermTeamUpdateAppProtection, permission.Context(permTypes.CtxTeam, a.TeamOwner)) {
+		return permission.ErrUnauthorized
+	}
+	var p app.Protection
+	err = ParseInput(r, &p)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermTeamUpdateAppProtection,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = a.SetProtection(p)
+	if v, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+// protectionError maps errors of the app protection to a conflict, so that
+// they're reported before any streaming starts.
+func protectionError(err error) error {
+	if _, ok := err.(app.ErrAppProtected); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
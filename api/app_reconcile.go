@@ -0,0 +1,125 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app/reconcile"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: app reconcile info
+// path: /apps/{app}/reconcile
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App or spec not found
+func appReconcileInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppRead, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	spec, err := reconcile.GetSpec(a.Name)
+	if err == reconcile.ErrSpecNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(spec)
+}
+
+// title: app reconcile pause
+// path: /apps/{app}/reconcile/pause
+// method: POST
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App or spec not found
+func appReconcilePause(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return setAppReconcilePaused(r, t, true)
+}
+
+// title: app reconcile resume
+// path: /apps/{app}/reconcile/resume
+// method: POST
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App or spec not found
+func appReconcileResume(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return setAppReconcilePaused(r, t, false)
+}
+
+func setAppReconcilePaused(r *http.Request, t auth.Token, paused bool) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateReconcile, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateReconcile,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]bool{"paused": paused},
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = reconcile.SetPaused(a.Name, paused)
+	if err == reconcile.ErrSpecNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: app reconcile remove
+// path: /apps/{app}/reconcile
+// method: DELETE
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App or spec not found
+func appReconcileRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateReconcile, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateReconcile,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = reconcile.RemoveSpec(a.Name)
+	if err == reconcile.ErrSpecNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
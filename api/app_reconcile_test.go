@@ -0,0 +1,99 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/reconcile"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppApplyStoresSpec(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"name": "myapp", "description": "managed"}`)
+	request, err := http.NewRequest(http.MethodPost, "/apps/apply?reconcile=true", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*continuously reconciled.*`)
+	spec, err := reconcile.GetSpec("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(spec.Manifest, check.DeepEquals, manifest.Manifest{Name: "myapp", Description: "managed"})
+	c.Assert(spec.Paused, check.Equals, false)
+}
+
+func (s *S) TestAppReconcilePauseResume(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = reconcile.SaveSpec(manifest.Manifest{Name: "myapp"}, false)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPost, "/apps/myapp/reconcile/pause", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	request, err = http.NewRequest(http.MethodGet, "/apps/myapp/reconcile", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var spec reconcile.Spec
+	err = json.Unmarshal(recorder.Body.Bytes(), &spec)
+	c.Assert(err, check.IsNil)
+	c.Assert(spec.App, check.Equals, "myapp")
+	c.Assert(spec.Paused, check.Equals, true)
+	request, err = http.NewRequest(http.MethodPost, "/apps/myapp/reconcile/resume", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	stored, err := reconcile.GetSpec("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Paused, check.Equals, false)
+}
+
+func (s *S) TestAppReconcileRemove(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = reconcile.SaveSpec(manifest.Manifest{Name: "myapp"}, false)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodDelete, "/apps/myapp/reconcile", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = reconcile.GetSpec("myapp")
+	c.Assert(err, check.Equals, reconcile.ErrSpecNotFound)
+}
+
+func (s *S) TestAppReconcileInfoNotManaged(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/apps/myapp/reconcile", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app/snapshot"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: app snapshot list
+// path: /apps/{app}/snapshots
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func appSnapshotList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppRead, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	snapshots, err := snapshot.List(a.Name)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(snapshots)
+}
+
+// title: app restore
+// path: /apps/{app}/restore
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   400: Invalid time
+//   401: Unauthorized
+//   404: App or snapshot not found
+func appRestore(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateRestore, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	at, err := parseRestoreTime(InputValue(r, "at"))
+	if err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	preview, _ := strconv.ParseBool(InputValue(r, "preview"))
+	if preview {
+		restore, errPreview := snapshot.Preview(&a, at)
+		if errPreview == snapshot.ErrSnapshotNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: errPreview.Error()}
+		}
+		if errPreview != nil {
+			return errPreview
+		}
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(restore)
+	}
+	if _, err = snapshot.At(a.Name, at); err != nil {
+		if err == snapshot.ErrSnapshotNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateRestore,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	restore, err := snapshot.RestoreApp(r.Context(), &a, at, evt)
+	if err != nil {
+		return err
+	}
+	evt.SetOtherCustomData(restore)
+	return nil
+}
+
+// parseRestoreTime accepts times in RFC 3339 format or as unix timestamps.
+func parseRestoreTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, &errors.ValidationError{Message: "the at parameter is required"}
+	}
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, &errors.ValidationError{Message: "invalid time, expected RFC 3339 or unix timestamp"}
+	}
+	return at, nil
+}
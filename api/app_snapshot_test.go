@@ -0,0 +1,85 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/snapshot"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestParseRestoreTime(c *check.C) {
+	at, err := parseRestoreTime("1700000000")
+	c.Assert(err, check.IsNil)
+	c.Assert(at.Equal(time.Unix(1700000000, 0)), check.Equals, true)
+	at, err = parseRestoreTime("2023-11-14T22:13:20Z")
+	c.Assert(err, check.IsNil)
+	c.Assert(at.Equal(time.Unix(1700000000, 0)), check.Equals, true)
+	_, err = parseRestoreTime("")
+	c.Assert(err, check.ErrorMatches, "the at parameter is required")
+	_, err = parseRestoreTime("yesterday")
+	c.Assert(err, check.ErrorMatches, "invalid time, expected RFC 3339 or unix timestamp")
+}
+
+func (s *S) TestAppRestorePreview(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = snapshot.Take(&a)
+	c.Assert(err, check.IsNil)
+	at := time.Now()
+	err = a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{{Name: "DEBUG", Value: "1", Public: true}}})
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("/apps/%s/restore?preview=true&at=%d", a.Name, at.Unix()+1)
+	request, err := http.NewRequest("POST", url, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var restore snapshot.Restore
+	err = json.Unmarshal(recorder.Body.Bytes(), &restore)
+	c.Assert(err, check.IsNil)
+	c.Assert(restore.Changes, check.DeepEquals, []manifest.Change{
+		{Kind: manifest.ChangeEnvUnset, Names: []string{"DEBUG"}},
+	})
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["DEBUG"].Value, check.Equals, "1")
+}
+
+func (s *S) TestAppRestoreSnapshotNotFound(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/myapp/restore?at=1700000000", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestAppRestoreInvalidTime(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps/myapp/restore?at=yesterday", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
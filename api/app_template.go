@@ -0,0 +1,281 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/template"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// title: app template list
+// path: /app-templates
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+func appTemplateList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	templates, err := template.List()
+	if err != nil {
+		return err
+	}
+	if len(templates) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(templates)
+}
+
+// title: app template info
+// path: /app-templates/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   404: Not found
+func appTemplateInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	tpl, err := template.Get(r.URL.Query().Get(":name"))
+	if err == template.ErrTemplateNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(tpl)
+}
+
+// title: app template create
+// path: /app-templates
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   201: Template created
+//   400: Invalid data
+//   401: Unauthorized
+//   409: Template already exists
+func appTemplateCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermAppTemplateCreate) {
+		return permission.ErrUnauthorized
+	}
+	var tpl template.Template
+	err = ParseInput(r, &tpl)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeAppTemplate, Value: tpl.Name},
+		Kind:       permission.PermAppTemplateCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppTemplateReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = template.Create(r.Context(), tpl)
+	if err == template.ErrTemplateAlreadyExists {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// title: app template update
+// path: /app-templates/{name}
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Template updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Template not found
+func appTemplateUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermAppTemplateUpdate) {
+		return permission.ErrUnauthorized
+	}
+	var tpl template.Template
+	err = ParseInput(r, &tpl)
+	if err != nil {
+		return err
+	}
+	tpl.Name = r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeAppTemplate, Value: tpl.Name},
+		Kind:       permission.PermAppTemplateUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppTemplateReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = template.Update(r.Context(), tpl)
+	if err == template.ErrTemplateNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: app template delete
+// path: /app-templates/{name}
+// method: DELETE
+// responses:
+//   200: Template removed
+//   401: Unauthorized
+//   404: Template not found
+//   409: Template in use
+func appTemplateDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermAppTemplateDelete) {
+		return permission.ErrUnauthorized
+	}
+	name := r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeAppTemplate, Value: name},
+		Kind:       permission.PermAppTemplateDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppTemplateReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = template.Remove(name)
+	switch err {
+	case template.ErrTemplateNotFound:
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	case template.ErrTemplateInUse:
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
+
+// title: app template sync
+// path: /app-templates/{name}/sync
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Template not found
+func appTemplateSync(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	if !permission.Check(t, permission.PermAppTemplateUpdateSync) {
+		return permission.ErrUnauthorized
+	}
+	tpl, err := template.Get(r.URL.Query().Get(":name"))
+	if err == template.ErrTemplateNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	names, err := template.Apps(tpl.Name)
+	if err != nil {
+		return err
+	}
+	if only, _ := InputValues(r, "app"); len(only) > 0 {
+		children := make(map[string]struct{}, len(names))
+		for _, name := range names {
+			children[name] = struct{}{}
+		}
+		for _, name := range only {
+			if _, ok := children[name]; !ok {
+				return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("app %q was not created from template %q", name, tpl.Name)}
+			}
+		}
+		names = only
+	}
+	apps := make([]*app.App, 0, len(names))
+	for _, name := range names {
+		a, errGet := app.GetByName(ctx, name)
+		if errGet == appTypes.ErrAppNotFound {
+			continue
+		}
+		if errGet != nil {
+			return errGet
+		}
+		apps = append(apps, a)
+	}
+	preview, _ := strconv.ParseBool(InputValue(r, "preview"))
+	if preview {
+		results := make([]template.SyncResult, 0, len(apps))
+		for _, a := range apps {
+			result := template.SyncResult{App: a.Name}
+			result.Changes, err = tpl.Preview(a)
+			if err != nil {
+				result.Error = err.Error()
+			}
+			results = append(results, result)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(results)
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	var failed []string
+	for _, a := range apps {
+		fmt.Fprintf(writer, "---- Syncing app %q ----\n", a.Name)
+		if errSync := appTemplateSyncApp(r, t, tpl, a, writer); errSync != nil {
+			fmt.Fprintf(writer, "Unable to sync app %q: %s\n", a.Name, errSync)
+			failed = append(failed, a.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("apps not synced: %s", strings.Join(failed, ", "))
+	}
+	fmt.Fprintln(writer, "\nOK")
+	return nil
+}
+
+func appTemplateSyncApp(r *http.Request, t auth.Token, tpl *template.Template, a *app.App, w *tsuruIo.SimpleJsonMessageEncoderWriter) (err error) {
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(a.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: event.Target{Type: event.TargetTypeAppTemplate, Value: tpl.Name}}},
+		Kind:         permission.PermAppTemplateUpdateSync,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.SetLogWriter(w)
+	changes, err := tpl.Sync(r.Context(), a, evt)
+	if err != nil {
+		return err
+	}
+	evt.SetOtherCustomData(changes)
+	return nil
+}
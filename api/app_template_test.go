@@ -0,0 +1,179 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/template"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppTemplateCreate(c *check.C) {
+	body := strings.NewReader("name=web&plan=default&env.LOG_LEVEL=info&tags.0=tier:front")
+	request, err := http.NewRequest("POST", "/app-templates", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", recorder.Body.String()))
+	tpl, err := template.Get("web")
+	c.Assert(err, check.IsNil)
+	c.Assert(tpl, check.DeepEquals, &template.Template{
+		Name: "web",
+		Plan: "default",
+		Tags: []string{"tier:front"},
+		Env:  map[string]string{"LOG_LEVEL": "info"},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeAppTemplate, Value: "web"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "app-template.create",
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("POST", "/app-templates", strings.NewReader("name=web"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestAppTemplateCreateUnauthorized(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppCreate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("POST", "/app-templates", strings.NewReader("name=web"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppTemplateDeleteInUse(c *check.C) {
+	err := template.Create(context.TODO(), template.Template{Name: "web"})
+	c.Assert(err, check.IsNil)
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Template: "web"}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/app-templates/web", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestCreateAppWithTemplate(c *check.C) {
+	s.setupMockForCreateApp(c, "zend")
+	err := template.Create(context.TODO(), template.Template{
+		Name: "web",
+		Tags: []string{"tier:front"},
+		Env:  map[string]string{"LOG_LEVEL": "info"},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/apps", strings.NewReader("name=someapp&platform=zend&template=web"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", recorder.Body.String()))
+	var gotApp app.App
+	err = s.conn.Apps().Find(bson.M{"name": "someapp"}).One(&gotApp)
+	c.Assert(err, check.IsNil)
+	c.Assert(gotApp.Template, check.Equals, "web")
+	c.Assert(gotApp.Tags, check.DeepEquals, []string{"tier:front"})
+	c.Assert(gotApp.Env["LOG_LEVEL"].Value, check.Equals, "info")
+}
+
+func (s *S) TestCreateAppWithTemplateNotFound(c *check.C) {
+	s.setupMockForCreateApp(c, "zend")
+	request, err := http.NewRequest("POST", "/apps", strings.NewReader("name=someapp&platform=zend&template=web"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, template.ErrTemplateNotFound.Error()+"\n")
+}
+
+func (s *S) TestAppTemplateSyncPreview(c *check.C) {
+	tpl := template.Template{Name: "web"}
+	err := template.Create(context.TODO(), tpl)
+	c.Assert(err, check.IsNil)
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Template: "web"}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	tpl.Env = map[string]string{"LOG_LEVEL": "debug"}
+	err = template.Update(context.TODO(), tpl)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/app-templates/web/sync?preview=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	var results []template.SyncResult
+	err = json.Unmarshal(recorder.Body.Bytes(), &results)
+	c.Assert(err, check.IsNil)
+	c.Assert(results, check.DeepEquals, []template.SyncResult{
+		{App: "myapp", Changes: []manifest.Change{{Kind: manifest.ChangeEnvSet, Names: []string{"LOG_LEVEL"}}}},
+	})
+}
+
+func (s *S) TestAppTemplateSync(c *check.C) {
+	tpl := template.Template{Name: "web"}
+	err := template.Create(context.TODO(), tpl)
+	c.Assert(err, check.IsNil)
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Template: "web"}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	tpl.Env = map[string]string{"LOG_LEVEL": "debug"}
+	err = template.Update(context.TODO(), tpl)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/app-templates/web/sync", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*env-set LOG_LEVEL.*OK.*`)
+	dbApp, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["LOG_LEVEL"].Value, check.Equals, "debug")
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app-template.update.sync",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestAppTemplateSyncAppNotFromTemplate(c *check.C) {
+	err := template.Create(context.TODO(), template.Template{Name: "web"})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/app-templates/web/sync?app=otherapp", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
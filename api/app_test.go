@@ -799,6 +799,43 @@ func (s *S) TestDelete(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestDeleteBlockedBySafetyChecks(c *check.C) {
+	config.Set("app-removal:safety-checks", true)
+	defer config.Unset("app-removal:safety-checks")
+	config.Set("routers:mytraffic:type", "fake-traffic")
+	defer config.Unset("routers:mytraffic")
+	defer routertest.TrafficRouter.Reset()
+	myApp := &app.App{Name: "myapptodelete", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), myApp, s.user)
+	c.Assert(err, check.IsNil)
+	err = myApp.AddRouter(appTypes.AppRouter{Name: "mytraffic"})
+	c.Assert(err, check.IsNil)
+	routertest.TrafficRouter.Requests[myApp.Name] = 10
+	request, err := http.NewRequest("DELETE", "/apps/"+myApp.Name, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusPreconditionFailed)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var report app.RemovalReport
+	err = json.Unmarshal(recorder.Body.Bytes(), &report)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.App, check.Equals, myApp.Name)
+	c.Assert(report.Blockers, check.HasLen, 1)
+	c.Assert(report.Blockers[0].Kind, check.Equals, app.RemovalBlockerTraffic)
+	_, err = app.GetByName(context.TODO(), myApp.Name)
+	c.Assert(err, check.IsNil)
+	request, err = http.NewRequest("DELETE", "/apps/"+myApp.Name+"?force=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = app.GetByName(context.TODO(), myApp.Name)
+	c.Assert(err, check.Equals, appTypes.ErrAppNotFound)
+}
+
 func (s *S) TestDeleteVersion(c *check.C) {
 	myApp := &app.App{
 		Name:      "myversiontodelete",
@@ -836,6 +873,41 @@ func (s *S) TestDeleteVersion(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestAppVersionList(c *check.C) {
+	a := app.App{Name: "myversions", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 3, "web", nil, nil)
+	request, err := http.NewRequest("GET", "/apps/myversions/versions", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var versions []app.VersionUnits
+	err = json.Unmarshal(recorder.Body.Bytes(), &versions)
+	c.Assert(err, check.IsNil)
+	c.Assert(versions, check.DeepEquals, []app.VersionUnits{
+		{Version: 0, Units: map[string]int{"web": 3}},
+	})
+}
+
+func (s *S) TestAppVersionTrafficInvalidWeight(c *check.C) {
+	a := app.App{Name: "myversions", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("process=web&weight=abc")
+	request, err := http.NewRequest("POST", "/apps/myversions/versions/2/traffic", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid weight\n")
+}
+
 func (s *S) TestDeleteShouldReturnForbiddenIfTheGivenUserDoesNotHaveAccessToTheApp(c *check.C) {
 	myApp := app.App{Name: "app-to-delete", Platform: "zend"}
 	err := s.conn.Apps().Insert(myApp)
@@ -2479,6 +2551,52 @@ func (s *S) TestRemoveUnits(c *check.C) {
 	c.Assert(recorder.Body.String(), check.Matches, `{"Message":".*removing 2 units","Timestamp":".*"}`+"\n")
 }
 
+func (s *S) TestRemoveUnitsByID(c *check.C) {
+	a := app.App{Name: "velha", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	s.provisioner.AddUnits(context.TODO(), &a, 3, "web", nil, nil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 3)
+	request, err := http.NewRequest("DELETE", "/apps/velha/units?unit="+units[0].ID+"&unit="+units[2].ID, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-type"), check.Equals, "application/x-json-stream")
+	remaining := s.provisioner.GetUnits(&a)
+	c.Assert(remaining, check.HasLen, 1)
+	c.Assert(remaining[0].ID, check.Equals, units[1].ID)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("velha"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.unit.remove",
+		StartCustomData: []map[string]interface{}{
+			{"name": "unit", "value": []interface{}{units[0].ID, units[2].ID}},
+			{"name": ":app", "value": "velha"},
+		},
+	}, eventtest.HasEvent)
+	c.Assert(recorder.Body.String(), check.Matches, `{"Message":".*removing 2 units","Timestamp":".*"}`+"\n")
+}
+
+func (s *S) TestRemoveUnitsByIDUnitNotFound(c *check.C) {
+	a := app.App{Name: "velha", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", nil, nil)
+	request, err := http.NewRequest("DELETE", "/apps/velha/units?unit=unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(s.provisioner.GetUnits(&a), check.HasLen, 1)
+}
+
 func (s *S) TestRemoveUnitsReturns404IfAppDoesNotExist(c *check.C) {
 	request, err := http.NewRequest("DELETE", "/apps/fetisha/units?:app=fetisha&units=1&process=web", nil)
 	c.Assert(err, check.IsNil)
@@ -6850,3 +6968,66 @@ func (s *S) TestFollowLogs(c *check.C) {
 	c.Assert(msgSlice, check.HasLen, 1)
 	c.Assert(msgSlice[0].Message, check.Equals, "xyz")
 }
+
+func (s *S) TestRestartUnit(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 2, "web", nil, nil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 2)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppUpdateUnitRestart,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("POST", "/1.13/apps/myapp/units/"+units[1].ID+"/restart", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(s.provisioner.UnitRestarts(&a), check.DeepEquals, []string{units[1].ID})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  token.GetUserName(),
+		Kind:   "app.update.unit.restart",
+		StartCustomData: []map[string]interface{}{
+			{"unit": units[1].ID},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestRestartUnitNotFound(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.13/apps/myapp/units/unknown/restart", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(s.provisioner.UnitRestarts(&a), check.IsNil)
+}
+
+func (s *S) TestRestartUnitUnauthorized(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.provisioner.AddUnits(context.TODO(), &a, 1, "web", nil, nil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppUpdateUnitKill,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("POST", "/1.13/apps/myapp/units/"+units[0].ID+"/restart", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(s.provisioner.UnitRestarts(&a), check.IsNil)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/dirsync"
 	"github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
@@ -133,6 +134,9 @@ func login(w http.ResponseWriter, r *http.Request) (err error) {
 	if err != nil {
 		return handleAuthError(err)
 	}
+	if syncErr := dirsync.SyncUser(ctx, token.GetUserName()); syncErr != nil && syncErr != dirsync.ErrNotConfigured {
+		log.Errorf("[directory-sync] unable to sync user %q on login: %v", token.GetUserName(), syncErr)
+	}
 	return json.NewEncoder(w).Encode(map[string]string{"token": token.GetValue()})
 }
 
@@ -0,0 +1,88 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const defaultAvailabilityPeriod = 30 * 24 * time.Hour
+
+// title: app availability report
+// path: /apps/{app}/availability
+// method: GET
+// produce: application/json, text/csv
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func appAvailability(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppRead, contextsForApp(&a)...)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	period, err := parsePeriod(r.URL.Query().Get("period"), defaultAvailabilityPeriod)
+	if err != nil {
+		return err
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" && r.Header.Get("Accept") == "text/csv" {
+		format = "csv"
+	}
+	if format != "" && format != "json" && format != "csv" {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format, possible values are 'json' or 'csv'",
+		}
+	}
+	until := time.Now().UTC()
+	report, err := a.Availability(until.Add(-period), until)
+	if err != nil {
+		return err
+	}
+	if format == "csv" {
+		return writeAvailabilityCSV(w, report)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(report)
+}
+
+func writeAvailabilityCSV(w http.ResponseWriter, report *app.AvailabilityReport) error {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+report.App+`-availability.csv"`)
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"app", "process", "since", "until", "availability", "downtime_seconds", "incidents", "mttr_seconds", "deploys", "failed_deploys", "deploys_per_day"})
+	for _, p := range report.Processes {
+		writer.Write([]string{
+			report.App,
+			p.Process,
+			report.Since.Format(time.RFC3339),
+			report.Until.Format(time.RFC3339),
+			strconv.FormatFloat(p.Availability, 'f', 3, 64),
+			strconv.FormatFloat(p.Downtime.Seconds(), 'f', 0, 64),
+			strconv.Itoa(p.Incidents),
+			strconv.FormatFloat(p.MTTR.Seconds(), 'f', 0, 64),
+			strconv.Itoa(report.Deploys),
+			strconv.Itoa(report.FailedDeploys),
+			strconv.FormatFloat(report.DeploysPerDay, 'f', 3, 64),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
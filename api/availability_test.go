@@ -0,0 +1,113 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision/unithistory"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) recordAvailabilityTransitions(c *check.C) {
+	now := time.Now().UTC()
+	transitions := []unithistory.Transition{
+		{App: "myapp", Unit: "u1", Process: "web", From: "started", Status: "error", Time: now.Add(-2 * time.Hour)},
+		{App: "myapp", Unit: "u1", Process: "web", From: "error", Status: "started", Time: now.Add(-time.Hour)},
+	}
+	for _, t := range transitions {
+		err := unithistory.Record(t)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *S) TestAppAvailability(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.recordAvailabilityTransitions(c)
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/availability?period=1d", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var report app.AvailabilityReport
+	err = json.Unmarshal(recorder.Body.Bytes(), &report)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.App, check.Equals, "myapp")
+	c.Assert(report.Until.Sub(report.Since), check.Equals, 24*time.Hour)
+	c.Assert(report.Processes, check.HasLen, 1)
+	c.Assert(report.Processes[0].Process, check.Equals, "web")
+	c.Assert(report.Processes[0].Incidents, check.Equals, 1)
+	c.Assert(report.Processes[0].MTTR, check.Equals, time.Hour)
+}
+
+func (s *S) TestAppAvailabilityCSV(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.recordAvailabilityTransitions(c)
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/availability?period=1d&format=csv", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "text/csv")
+	records, err := csv.NewReader(recorder.Body).ReadAll()
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.HasLen, 2)
+	c.Assert(records[0][:3], check.DeepEquals, []string{"app", "process", "since"})
+	c.Assert(records[1][0], check.Equals, "myapp")
+	c.Assert(records[1][1], check.Equals, "web")
+	c.Assert(records[1][6], check.Equals, "1")
+	c.Assert(records[1][7], check.Equals, "3600")
+}
+
+func (s *S) TestAppAvailabilityInvalidFormat(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/availability?format=xml", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestParsePeriod(c *check.C) {
+	tests := []struct {
+		raw      string
+		expected time.Duration
+		valid    bool
+	}{
+		{raw: "", expected: time.Hour, valid: true},
+		{raw: "30d", expected: 30 * 24 * time.Hour, valid: true},
+		{raw: "6h", expected: 6 * time.Hour, valid: true},
+		{raw: "31d"},
+		{raw: "0d"},
+		{raw: "-1h"},
+		{raw: "xd"},
+		{raw: "1y"},
+	}
+	for _, tt := range tests {
+		period, err := parsePeriod(tt.raw, time.Hour)
+		if tt.valid {
+			c.Check(err, check.IsNil, check.Commentf("period %q", tt.raw))
+			c.Check(period, check.Equals, tt.expected, check.Commentf("period %q", tt.raw))
+		} else {
+			c.Check(err, check.NotNil, check.Commentf("period %q", tt.raw))
+		}
+	}
+}
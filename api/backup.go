@@ -0,0 +1,74 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db/backup"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: export control plane state
+// path: /backup
+// method: GET
+// produce: application/gzip
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Forbidden
+func exportBackup(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermBackupRead) {
+		return permission.ErrUnauthorized
+	}
+	var credentials bool
+	if value := InputValue(r, "credentials"); value != "" {
+		var err error
+		credentials, err = strconv.ParseBool(value)
+		if err != nil {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "invalid value for credentials"}
+		}
+	}
+	if credentials && !permission.Check(t, permission.PermBackupReadCredentials) {
+		return permission.ErrUnauthorized
+	}
+	tmp, err := ioutil.TempFile("", "tsuru-export-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	manifest, err := backup.Export(tmp, backup.ExportOptions{
+		TsuruVersion: Version,
+		Databases:    backup.Databases(),
+		Credentials:  credentials,
+	})
+	if err != nil {
+		return err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	filename := fmt.Sprintf("tsuru-%s.tar.gz", manifest.CreatedAt.Format(time.RFC3339))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", fmt.Sprint(size))
+	_, err = io.Copy(w, tmp)
+	return err
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/db/backup"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestExportBackup(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.13/backup", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/gzip")
+	c.Assert(recorder.Header().Get("Content-Disposition"), check.Matches, `attachment; filename="tsuru-.*\.tar\.gz"`)
+	gzr, err := gzip.NewReader(recorder.Body)
+	c.Assert(err, check.IsNil)
+	tr := tar.NewReader(gzr)
+	var manifest backup.Manifest
+	for {
+		header, err := tr.Next()
+		c.Assert(err, check.IsNil)
+		if header.Name == "manifest.json" {
+			err = json.NewDecoder(tr).Decode(&manifest)
+			c.Assert(err, check.IsNil)
+			break
+		}
+	}
+	c.Assert(manifest.TsuruVersion, check.Equals, Version)
+	c.Assert(manifest.Databases, check.Not(check.HasLen), 0)
+	c.Assert(manifest.Databases[0].Name, check.Equals, "tsuru")
+}
+
+func (s *S) TestExportBackupForbidden(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request, err := http.NewRequest("GET", "/1.13/backup", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestExportBackupCredentialsForbidden(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermBackupRead,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request, err := http.NewRequest("GET", "/1.13/backup?credentials=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestExportBackupRedactsCredentials(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermBackupRead,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request, err := http.NewRequest("GET", "/1.13/backup", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	gzr, err := gzip.NewReader(recorder.Body)
+	c.Assert(err, check.IsNil)
+	tr := tar.NewReader(gzr)
+	var manifest backup.Manifest
+	for {
+		header, err := tr.Next()
+		c.Assert(err, check.IsNil)
+		c.Assert(header.Name, check.Not(check.Equals), "tsuru/tokens.bson")
+		if header.Name == "manifest.json" {
+			err = json.NewDecoder(tr).Decode(&manifest)
+			c.Assert(err, check.IsNil)
+			break
+		}
+	}
+	c.Assert(manifest.Redacted, check.Equals, true)
+}
@@ -86,7 +86,7 @@ func build(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 		return err
 	}
 	var imageID string
-	defer func() { evt.DoneCustomData(err, map[string]string{"image": imageID}) }()
+	defer func() { evt.DoneCustomData(err, app.NewDeployEndData(evt, instance, imageID)) }()
 	ctx, cancel := evt.CancelableContext(opts.App.Context())
 	defer cancel()
 	opts.App.ReplaceContext(ctx)
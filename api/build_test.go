@@ -196,7 +196,9 @@ func (s *BuildSuite) TestBuildHandler(c *check.C) {
 			"rollback":   false,
 		},
 		EndCustomData: map[string]interface{}{
-			"image": "tsuruteam/app-otherapp:mytag",
+			"image":    "tsuruteam/app-otherapp:mytag",
+			"pool":     "pool1",
+			"platform": "python",
 		},
 	}, eventtest.HasEvent)
 }
@@ -0,0 +1,434 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/chatops"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const (
+	chatResponseEphemeral = "ephemeral"
+	chatResponseInChannel = "in_channel"
+)
+
+type chatRequest struct {
+	user        chatops.ChatUser
+	text        string
+	responseURL string
+	remoteAddr  string
+	httpReq     *http.Request
+}
+
+type chatResponse struct {
+	ResponseType string        `json:"response_type"`
+	Text         string        `json:"text"`
+	Blocks       []interface{} `json:"blocks,omitempty"`
+}
+
+type chatInteraction struct {
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	ResponseURL string `json:"response_url"`
+}
+
+func ephemeral(format string, args ...interface{}) *chatResponse {
+	return &chatResponse{ResponseType: chatResponseEphemeral, Text: fmt.Sprintf(format, args...)}
+}
+
+func parseChatRequest(values url.Values) (*chatRequest, error) {
+	payload := values.Get("payload")
+	if payload == "" {
+		return &chatRequest{
+			user:        chatops.ChatUser{TeamID: values.Get("team_id"), UserID: values.Get("user_id")},
+			text:        values.Get("text"),
+			responseURL: values.Get("response_url"),
+		}, nil
+	}
+	var interaction chatInteraction
+	err := json.Unmarshal([]byte(payload), &interaction)
+	if err != nil {
+		return nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "invalid interaction payload"}
+	}
+	if len(interaction.Actions) == 0 {
+		return nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "no actions in interaction payload"}
+	}
+	action := interaction.Actions[0]
+	return &chatRequest{
+		user:        chatops.ChatUser{TeamID: interaction.Team.ID, UserID: interaction.User.ID},
+		text:        fmt.Sprintf("%s %s", action.ActionID, action.Value),
+		responseURL: interaction.ResponseURL,
+	}, nil
+}
+
+// title: chatops command
+// path: /chatops/command
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   200: Command handled
+//   400: Invalid data
+//   401: Invalid signature
+func chatOpsCommand(w http.ResponseWriter, r *http.Request) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	err = chatops.VerifyRequest(r.Header, body, values.Get("token"))
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusUnauthorized, Message: err.Error()}
+	}
+	req, err := parseChatRequest(values)
+	if err != nil {
+		return err
+	}
+	req.remoteAddr = r.RemoteAddr
+	req.httpReq = r
+	rsp := handleChatCommand(r.Context(), req)
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(rsp)
+}
+
+// title: chatops link user
+// path: /chatops/link
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: User linked
+//   400: Invalid code
+//   401: Unauthorized
+func chatOpsLink(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if t.IsAppToken() {
+		return permission.ErrUnauthorized
+	}
+	code := InputValue(r, "code")
+	if code == "" {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "code is required"}
+	}
+	_, err := chatops.Link(code, t.GetUserName())
+	if err == chatops.ErrInvalidLinkCode {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+// title: chatops unlink user
+// path: /chatops/link
+// method: DELETE
+// responses:
+//   200: User unlinked
+//   401: Unauthorized
+func chatOpsUnlink(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if t.IsAppToken() {
+		return permission.ErrUnauthorized
+	}
+	return chatops.Unlink(t.GetUserName())
+}
+
+func handleChatCommand(ctx context.Context, req *chatRequest) *chatResponse {
+	cmd, err := chatops.ParseCommand(req.text)
+	if err != nil {
+		return ephemeral("%v\n%s", err, chatops.Usage())
+	}
+	switch cmd.Name {
+	case chatops.CommandHelp:
+		return ephemeral("%s", chatops.Usage())
+	case chatops.CommandLink:
+		var code string
+		code, err = chatops.NewLinkCode(req.user)
+		if err != nil {
+			return ephemeral("Unable to generate link code: %v", err)
+		}
+		return ephemeral("To link your chat user with your tsuru user, send the code %s to POST /chatops/link using your tsuru token. The code expires in a few minutes.", code)
+	}
+	email, err := chatops.UserEmail(req.user)
+	if err != nil {
+		if err == chatops.ErrUserNotLinked {
+			return ephemeral("Your chat user is not linked to a tsuru user, use the link command first.")
+		}
+		return ephemeral("Unable to find linked user: %v", err)
+	}
+	if _, err = auth.GetUserByEmail(email); err != nil {
+		return ephemeral("Unable to find tsuru user %q: %v", email, err)
+	}
+	t := &auth.APIToken{UserEmail: email}
+	switch cmd.Name {
+	case chatops.CommandCancel:
+		if _, err = chatops.PopConfirmation(req.user, cmd.Code); err != nil {
+			return ephemeral("%v", err)
+		}
+		return ephemeral("Command cancelled.")
+	case chatops.CommandConfirm:
+		cmd, err = chatops.PopConfirmation(req.user, cmd.Code)
+		if err != nil {
+			return ephemeral("%v", err)
+		}
+		return runChatCommand(ctx, req, t, cmd, true)
+	}
+	return runChatCommand(ctx, req, t, cmd, false)
+}
+
+func runChatCommand(ctx context.Context, req *chatRequest, t auth.Token, cmd *chatops.Command, confirmed bool) *chatResponse {
+	a, err := app.GetByName(ctx, cmd.App)
+	if err != nil {
+		if err == appTypes.ErrAppNotFound {
+			return ephemeral("App %q not found.", cmd.App)
+		}
+		return ephemeral("Unable to find app %q: %v", cmd.App, err)
+	}
+	switch cmd.Name {
+	case chatops.CommandStatus:
+		return chatAppStatus(t, a)
+	case chatops.CommandDeploy:
+		return chatDeploy(req, t, a, cmd)
+	case chatops.CommandRollback:
+		if !confirmed {
+			return chatConfirm(req, t, a, cmd, permission.PermAppDeployRollback)
+		}
+		return chatDeploy(req, t, a, cmd)
+	case chatops.CommandScale:
+		return chatScale(req, t, a, cmd, confirmed)
+	}
+	return ephemeral("%v", chatops.ErrUnknownCommand)
+}
+
+func chatConfirm(req *chatRequest, t auth.Token, a *app.App, cmd *chatops.Command, perm *permission.PermissionScheme) *chatResponse {
+	if !permission.Check(t, perm, contextsForApp(a)...) {
+		return ephemeral("%v", permission.ErrUnauthorized)
+	}
+	confirmation, err := chatops.NewConfirmation(req.user, cmd)
+	if err != nil {
+		return ephemeral("Unable to request confirmation: %v", err)
+	}
+	text := fmt.Sprintf("Are you sure you want to run %q? Reply with \"confirm %s\" or \"cancel %s\".", cmd.String(), confirmation.Code, confirmation.Code)
+	rsp := ephemeral("%s", text)
+	rsp.Blocks = []interface{}{
+		map[string]interface{}{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": text},
+		},
+		map[string]interface{}{
+			"type": "actions",
+			"elements": []interface{}{
+				map[string]interface{}{
+					"type":      "button",
+					"style":     "danger",
+					"action_id": string(chatops.CommandConfirm),
+					"value":     confirmation.Code,
+					"text":      map[string]string{"type": "plain_text", "text": "Confirm"},
+				},
+				map[string]interface{}{
+					"type":      "button",
+					"action_id": string(chatops.CommandCancel),
+					"value":     confirmation.Code,
+					"text":      map[string]string{"type": "plain_text", "text": "Cancel"},
+				},
+			},
+		},
+	}
+	return rsp
+}
+
+func chatAppStatus(t auth.Token, a *app.App) *chatResponse {
+	if !permission.Check(t, permission.PermAppRead, contextsForApp(a)...) {
+		return ephemeral("%v", permission.ErrUnauthorized)
+	}
+	units, err := a.Units()
+	if err != nil {
+		return ephemeral("Unable to list units for app %q: %v", a.Name, err)
+	}
+	byProcess := map[string]map[provision.Status]int{}
+	for _, u := range units {
+		if byProcess[u.ProcessName] == nil {
+			byProcess[u.ProcessName] = map[provision.Status]int{}
+		}
+		byProcess[u.ProcessName][u.Status]++
+	}
+	processes := make([]string, 0, len(byProcess))
+	for p := range byProcess {
+		processes = append(processes, p)
+	}
+	sort.Strings(processes)
+	lines := []string{fmt.Sprintf("App %s (pool %s, %d deploys):", a.Name, a.Pool, a.Deploys)}
+	if len(units) == 0 {
+		lines = append(lines, "  no units")
+	}
+	for _, p := range processes {
+		var statuses []string
+		for status, count := range byProcess[p] {
+			statuses = append(statuses, fmt.Sprintf("%d %s", count, status))
+		}
+		sort.Strings(statuses)
+		lines = append(lines, fmt.Sprintf("  %s: %s", p, strings.Join(statuses, ", ")))
+	}
+	return &chatResponse{ResponseType: chatResponseInChannel, Text: strings.Join(lines, "\n")}
+}
+
+func chatDeploy(req *chatRequest, t auth.Token, a *app.App, cmd *chatops.Command) *chatResponse {
+	opts := app.DeployOptions{
+		App:  a,
+		User: t.GetUserName(),
+	}
+	if cmd.Name == chatops.CommandRollback {
+		opts.Image = cmd.Version
+		opts.Origin = "rollback"
+		opts.Rollback = true
+	} else {
+		opts.Image = cmd.Image
+		opts.Origin = "image"
+	}
+	opts.GetKind()
+	if !permission.Check(t, permSchemeForDeploy(opts), contextsForApp(a)...) {
+		return ephemeral("%v", permission.ErrUnauthorized)
+	}
+	if err := checkDeployAllowed(req.httpReq, t, opts, false); err != nil {
+		return ephemeral("Unable to start %s of app %s: %v", cmd.Name, a.Name, err)
+	}
+	evt, err := event.New(&event.Opts{
+		Target:        appTarget(a.Name),
+		Kind:          permission.PermAppDeploy,
+		Owner:         t,
+		RemoteAddr:    req.remoteAddr,
+		CustomData:    opts,
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(a)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return ephemeral("Unable to start %s: %v", cmd.Name, err)
+	}
+	opts.Event = evt
+	opts.OutputStream = ioutil.Discard
+	go func() {
+		var imageID string
+		var deployErr error
+		defer func() { evt.DoneCustomData(deployErr, app.NewDeployEndData(evt, a, imageID)) }()
+		deployCtx, cancel := evt.CancelableContext(context.Background())
+		defer cancel()
+		a.ReplaceContext(deployCtx)
+		imageID, deployErr = app.Deploy(deployCtx, opts)
+		text := fmt.Sprintf("%s of app %s finished successfully.", cmd.Name, a.Name)
+		if deployErr != nil {
+			text = fmt.Sprintf("%s of app %s failed: %v", cmd.Name, a.Name, deployErr)
+		}
+		postChatResponse(req.responseURL, &chatResponse{ResponseType: chatResponseInChannel, Text: text})
+	}()
+	return &chatResponse{
+		ResponseType: chatResponseInChannel,
+		Text:         fmt.Sprintf("%s of app %s started by %s (event %s).", cmd.Name, a.Name, t.GetUserName(), evt.UniqueID.Hex()),
+	}
+}
+
+func chatScale(req *chatRequest, t auth.Token, a *app.App, cmd *chatops.Command, confirmed bool) *chatResponse {
+	units, err := a.Units()
+	if err != nil {
+		return ephemeral("Unable to list units for app %q: %v", a.Name, err)
+	}
+	var current int
+	for _, u := range units {
+		if u.ProcessName == cmd.Process {
+			current++
+		}
+	}
+	delta := cmd.Units - current
+	if delta == 0 {
+		return ephemeral("App %s already has %d units for process %s.", a.Name, current, cmd.Process)
+	}
+	perm := permission.PermAppUpdateUnitAdd
+	if delta < 0 {
+		perm = permission.PermAppUpdateUnitRemove
+		if !confirmed {
+			return chatConfirm(req, t, a, cmd, perm)
+		}
+	}
+	if !permission.Check(t, perm, contextsForApp(a)...) {
+		return ephemeral("%v", permission.ErrUnauthorized)
+	}
+	evt, err := event.New(&event.Opts{
+		Target:        appTarget(a.Name),
+		Kind:          perm,
+		Owner:         t,
+		RemoteAddr:    req.remoteAddr,
+		CustomData:    cmd,
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(a)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return ephemeral("Unable to scale app %s: %v", a.Name, err)
+	}
+	go func() {
+		var scaleErr error
+		defer func() { evt.Done(scaleErr) }()
+		scaleCtx, cancel := evt.CancelableContext(context.Background())
+		defer cancel()
+		a.ReplaceContext(scaleCtx)
+		if delta > 0 {
+			scaleErr = a.AddUnits(uint(delta), cmd.Process, "", evt)
+		} else {
+			scaleErr = a.RemoveUnits(scaleCtx, uint(-delta), cmd.Process, "", evt)
+		}
+		text := fmt.Sprintf("App %s process %s scaled to %d units.", a.Name, cmd.Process, cmd.Units)
+		if scaleErr != nil {
+			text = fmt.Sprintf("Unable to scale app %s process %s: %v", a.Name, cmd.Process, scaleErr)
+		}
+		postChatResponse(req.responseURL, &chatResponse{ResponseType: chatResponseInChannel, Text: text})
+	}()
+	return &chatResponse{
+		ResponseType: chatResponseInChannel,
+		Text:         fmt.Sprintf("Scaling app %s process %s from %d to %d units (event %s).", a.Name, cmd.Process, current, cmd.Units, evt.UniqueID.Hex()),
+	}
+}
+
+func postChatResponse(responseURL string, rsp *chatResponse) {
+	if responseURL == "" {
+		return
+	}
+	data, err := json.Marshal(rsp)
+	if err != nil {
+		log.Errorf("[chatops] unable to encode response: %v", err)
+		return
+	}
+	httpRsp, err := tsuruNet.Dial15Full60ClientNoKeepAlive.Post(responseURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		log.Errorf("[chatops] unable to send response: %v", err)
+		return
+	}
+	httpRsp.Body.Close()
+	if httpRsp.StatusCode < 200 || httpRsp.StatusCode >= 300 {
+		log.Errorf("[chatops] invalid status code sending response: %d", httpRsp.StatusCode)
+	}
+}
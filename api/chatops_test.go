@@ -0,0 +1,142 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/chatops"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) chatOpsRequest(c *check.C, values url.Values) *httptest.ResponseRecorder {
+	values.Set("token", "chat-token")
+	request, err := http.NewRequest("POST", "/1.13/chatops/command", strings.NewReader(values.Encode()))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *S) linkChatUser(c *check.C, user chatops.ChatUser) {
+	code, err := chatops.NewLinkCode(user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.13/chatops/link", strings.NewReader("code="+code))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+}
+
+func (s *S) TestChatOpsCommandInvalidToken(c *check.C) {
+	config.Set("chatops:token", "other-token")
+	defer config.Unset("chatops")
+	recorder := s.chatOpsRequest(c, url.Values{"text": {"help"}})
+	c.Assert(recorder.Code, check.Equals, http.StatusUnauthorized)
+}
+
+func (s *S) TestChatOpsCommandNotLinked(c *check.C) {
+	config.Set("chatops:token", "chat-token")
+	defer config.Unset("chatops")
+	recorder := s.chatOpsRequest(c, url.Values{"team_id": {"T1"}, "user_id": {"U1"}, "text": {"status myapp"}})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var rsp chatResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	c.Assert(err, check.IsNil)
+	c.Assert(rsp.ResponseType, check.Equals, "ephemeral")
+	c.Assert(rsp.Text, check.Matches, "Your chat user is not linked.*")
+}
+
+func (s *S) TestChatOpsCommandStatus(c *check.C) {
+	config.Set("chatops:token", "chat-token")
+	defer config.Unset("chatops")
+	a := app.App{Name: "chatapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	user := chatops.ChatUser{TeamID: "T1", UserID: "U1"}
+	s.linkChatUser(c, user)
+	recorder := s.chatOpsRequest(c, url.Values{"team_id": {"T1"}, "user_id": {"U1"}, "text": {"status chatapp"}})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var rsp chatResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	c.Assert(err, check.IsNil)
+	c.Assert(rsp.ResponseType, check.Equals, "in_channel")
+	c.Assert(rsp.Text, check.Equals, "App chatapp (pool test1, 0 deploys):\n  no units")
+}
+
+func (s *S) TestChatOpsCommandRollbackRequiresConfirmation(c *check.C) {
+	config.Set("chatops:token", "chat-token")
+	defer config.Unset("chatops")
+	a := app.App{Name: "chatapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	user := chatops.ChatUser{TeamID: "T1", UserID: "U1"}
+	s.linkChatUser(c, user)
+	recorder := s.chatOpsRequest(c, url.Values{"team_id": {"T1"}, "user_id": {"U1"}, "text": {"rollback chatapp v1"}})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var rsp chatResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	c.Assert(err, check.IsNil)
+	c.Assert(rsp.Text, check.Matches, `Are you sure you want to run "rollback chatapp v1"\? Reply with "confirm \w+" or "cancel \w+".`)
+	c.Assert(rsp.Blocks, check.HasLen, 2)
+	code := strings.Fields(rsp.Text)[len(strings.Fields(rsp.Text))-1]
+	code = strings.Trim(code, `".`)
+	recorder = s.chatOpsRequest(c, url.Values{"team_id": {"T1"}, "user_id": {"U2"}, "text": {"cancel " + code}})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	c.Assert(err, check.IsNil)
+	c.Assert(rsp.Text, check.Matches, "Your chat user is not linked.*")
+	recorder = s.chatOpsRequest(c, url.Values{"team_id": {"T1"}, "user_id": {"U1"}, "text": {"cancel " + code}})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	c.Assert(err, check.IsNil)
+	c.Assert(rsp.Text, check.Equals, "Command cancelled.")
+}
+
+func (s *S) TestChatOpsCommandDeployRequiresApproval(c *check.C) {
+	config.Set("chatops:token", "chat-token")
+	defer config.Unset("chatops")
+	a := app.App{Name: "chatapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetDeployApproval(true)
+	c.Assert(err, check.IsNil)
+	user := chatops.ChatUser{TeamID: "T1", UserID: "U1"}
+	s.linkChatUser(c, user)
+	recorder := s.chatOpsRequest(c, url.Values{"team_id": {"T1"}, "user_id": {"U1"}, "text": {"deploy chatapp tsuru/chatapp:v2"}})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var rsp chatResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	c.Assert(err, check.IsNil)
+	c.Assert(rsp.ResponseType, check.Equals, "ephemeral")
+	c.Assert(rsp.Text, check.Equals, "Unable to start deploy of app chatapp: "+errDeployNeedsApproval.Message)
+	evts, err := event.All()
+	c.Assert(err, check.IsNil)
+	for _, evt := range evts {
+		c.Assert(evt.Kind.Name, check.Not(check.Equals), permission.PermAppDeploy.FullName())
+	}
+}
+
+func (s *S) TestChatOpsLinkInvalidCode(c *check.C) {
+	request, err := http.NewRequest("POST", "/1.13/chatops/link", strings.NewReader("code=invalid"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
@@ -5,11 +5,14 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"runtime/pprof"
 
+	"github.com/tsuru/tsuru/api/tracker"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
 )
 
 // title: dump goroutines
@@ -23,3 +26,23 @@ func dumpGoroutines(w http.ResponseWriter, r *http.Request, t auth.Token) error
 	}
 	return pprof.Lookup("goroutine").WriteTo(w, 2)
 }
+
+// title: node status
+// path: /node-status
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   403: Forbidden
+func nodeStatus(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermDebug) {
+		return permission.ErrUnauthorized
+	}
+	status, err := tracker.NodeStatus(r.Context(), servicemanager.InstanceTracker)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(status)
+}
@@ -5,9 +5,12 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/types/tracker"
 	check "gopkg.in/check.v1"
 )
 
@@ -20,3 +23,40 @@ func (s *S) TestDumpGoroutines(c *check.C) {
 	c.Assert(recorder.Code, check.Equals, http.StatusOK)
 	c.Assert(recorder.Body.String(), check.Matches, `(?s)goroutine \d+ \[running\]:.*`)
 }
+
+func (s *S) TestNodeStatus(c *check.C) {
+	oldTracker := servicemanager.InstanceTracker
+	defer func() { servicemanager.InstanceTracker = oldTracker }()
+	servicemanager.InstanceTracker = &tracker.MockInstanceService{
+		OnLiveInstances: func() ([]tracker.TrackedInstance, error) {
+			return []tracker.TrackedInstance{{Name: "host1", Addresses: []string{"10.0.0.1"}}}, nil
+		},
+		OnCurrentInstance: func() (tracker.TrackedInstance, error) {
+			return tracker.TrackedInstance{Name: "host1"}, nil
+		},
+	}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.13/node-status", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var status tracker.NodeStatus
+	err = json.Unmarshal(recorder.Body.Bytes(), &status)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.LeaderElection, check.Equals, false)
+	c.Assert(status.Instances, check.DeepEquals, []tracker.InstanceStatus{
+		{Name: "host1", Addresses: []string{"10.0.0.1"}, Current: true, Role: "follower"},
+	})
+}
+
+func (s *S) TestNodeStatusUnauthorized(c *check.C) {
+	token := userWithPermission(c)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/1.13/node-status", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
@@ -5,6 +5,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,11 +13,17 @@ import (
 	"time"
 
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/admission"
+	"github.com/tsuru/tsuru/app/delta"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/builder"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	tsuruIo "github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
 )
 
 const eventIDHeader = "X-Tsuru-Eventid"
@@ -27,9 +34,11 @@ const eventIDHeader = "X-Tsuru-Eventid"
 // consume: application/x-www-form-urlencoded
 // responses:
 //   200: OK
+//   202: Deploy waiting for approval
 //   400: Invalid data
 //   403: Forbidden
 //   404: Not found
+//   409: Deploy blocked by gate or delta base mismatch
 func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	ctx := r.Context()
 	opts, err := prepareToBuild(r)
@@ -79,6 +88,12 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	opts.Message = message
 	opts.NewVersion, _ = strconv.ParseBool(InputValue(r, "new-version"))
 	opts.OverrideVersions, _ = strconv.ParseBool(InputValue(r, "override-versions"))
+	if shadow := InputValue(r, "shadow"); shadow != "" {
+		opts.Shadow, err = strconv.Atoi(shadow)
+		if err != nil || opts.Shadow <= 0 || opts.Shadow > 100 {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "shadow must be a percentage between 1 and 100"}
+		}
+	}
 	opts.GetKind()
 	if t.GetAppName() != app.InternalAppName {
 		canDeploy := permission.Check(t, permSchemeForDeploy(opts), contextsForApp(instance)...)
@@ -86,6 +101,56 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 			return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: "User does not have permission to do this action in this app"}
 		}
 	}
+	if planOnly, _ := strconv.ParseBool(InputValue(r, "plan-only")); planOnly {
+		plan, planErr := app.PlanDeploy(ctx, opts)
+		if planErr != nil {
+			if _, ok := planErr.(provision.ProvisionerNotSupported); ok {
+				return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: planErr.Error()}
+			}
+			return planErr
+		}
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(plan)
+	}
+	review := appDeployAdmission{
+		Kind:       string(opts.GetKind()),
+		Origin:     opts.Origin,
+		Image:      opts.Image,
+		ArchiveURL: opts.ArchiveURL,
+		Commit:     opts.Commit,
+		Message:    opts.Message,
+	}
+	err = reviewAdmission(ctx, admission.OperationAppDeploy, appName, userName, &review)
+	if err != nil {
+		return err
+	}
+	if opts.Image != "" {
+		opts.Image = review.Image
+	}
+	opts.Message = review.Message
+	if deltaManifest := InputValue(r, "delta-manifest"); deltaManifest != "" {
+		err = expandDeployDelta(ctx, instance, &opts, deltaManifest)
+		if err != nil {
+			return err
+		}
+		defer opts.File.Close()
+	}
+	err = checkDeployAllowed(r, t, opts, false)
+	if err == errDeployNeedsApproval {
+		req, errReq := app.CreateDeployRequest(opts)
+		if errReq != nil {
+			if v, ok := errReq.(*tsuruErrors.ValidationError); ok {
+				return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+			}
+			return errReq
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "Deploy request %s created. It must be approved by another user before %s to run.\n", req.ID.Hex(), req.ExpiresAt.Format(time.RFC3339))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 	var imageID string
 	evt, err := event.New(&event.Opts{
 		Target:        appTarget(appName),
@@ -100,7 +165,7 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	if err != nil {
 		return err
 	}
-	defer func() { evt.DoneCustomData(err, map[string]string{"image": imageID}) }()
+	defer func() { evt.DoneCustomData(err, app.NewDeployEndData(evt, instance, imageID)) }()
 	ctx, cancel := evt.CancelableContext(opts.App.Context())
 	defer cancel()
 	opts.App.ReplaceContext(ctx)
@@ -116,6 +181,93 @@ func deploy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	return err
 }
 
+// expandDeployDelta replaces the patch uploaded in a delta deploy with the
+// full archive, rebuilt from the archive of the last deploy of the app.
+func expandDeployDelta(ctx context.Context, instance *app.App, opts *app.DeployOptions, rawManifest string) error {
+	if opts.File == nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "delta deploys require the patch file"}
+	}
+	var manifest delta.Manifest
+	err := json.Unmarshal([]byte(rawManifest), &manifest)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid delta manifest: %v", err)}
+	}
+	archive, size, err := instance.ApplyDelta(ctx, manifest, opts.File)
+	if err != nil {
+		if err == delta.ErrBaseMismatch || err == builder.ErrArtifactNotFound || err == appTypes.ErrNoVersionsAvailable {
+			return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+		}
+		if _, ok := err.(provision.ProvisionerNotSupported); ok {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	opts.File = archive
+	opts.FileSize = size
+	return nil
+}
+
+// title: deploy delta base
+// path: /apps/{app}/deploy/delta-base
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   400: Not supported
+//   401: Unauthorized
+//   404: Not found
+func deployDeltaBase(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	instance, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canDeploy := permission.Check(t, permission.PermAppDeployUpload, contextsForApp(&instance)...)
+	if !canDeploy {
+		return permission.ErrUnauthorized
+	}
+	base, err := instance.DeltaBase(ctx)
+	if err != nil {
+		if err == appTypes.ErrNoVersionsAvailable || err == builder.ErrArtifactNotFound {
+			return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		if _, ok := err.(provision.ProvisionerNotSupported); ok {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(base)
+}
+
+// errDeployNeedsApproval is returned by checkDeployAllowed when deploys to the
+// app must be approved by a second user.
+var errDeployNeedsApproval = &tsuruErrors.HTTP{
+	Code:    http.StatusConflict,
+	Message: "deploys to this app must be approved by another user, request the deploy using the app deploy endpoint",
+}
+
+// checkDeployAllowed runs the checks shared by every path starting a deploy,
+// it must be called before app.Deploy: pool freeze windows, deploy approval
+// and deploy gates. The approval check is skipped for deploys replaying an
+// approved deploy request. Gates are only called once the deploy is allowed
+// to start, so they're not called for deploys waiting for approval.
+func checkDeployAllowed(r *http.Request, t auth.Token, opts app.DeployOptions, approved bool) error {
+	if err := checkPoolFreeze(r, t, opts.App, permission.PermAppDeploy); err != nil {
+		return err
+	}
+	if !approved {
+		requiresApproval, err := opts.App.RequiresDeployApproval(r.Context())
+		if err != nil {
+			return err
+		}
+		if requiresApproval {
+			return errDeployNeedsApproval
+		}
+	}
+	return checkDeployGates(r, t, opts)
+}
+
 func permSchemeForDeploy(opts app.DeployOptions) *permission.PermissionScheme {
 	switch opts.GetKind() {
 	case app.DeployGit:
@@ -155,6 +307,7 @@ func diffDeploy(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 //   400: Invalid data
 //   403: Forbidden
 //   404: Not found
+//   409: Deploy blocked by pool freeze, approval or gate
 func deployRollback(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	ctx := r.Context()
 	appName := r.URL.Query().Get(":app")
@@ -197,6 +350,9 @@ func deployRollback(w http.ResponseWriter, r *http.Request, t auth.Token) error
 	if !canRollback {
 		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: permission.ErrUnauthorized.Error()}
 	}
+	if err = checkDeployAllowed(r, t, opts, false); err != nil {
+		return err
+	}
 	var imageID string
 	evt, err := event.New(&event.Opts{
 		Target:        appTarget(appName),
@@ -211,7 +367,7 @@ func deployRollback(w http.ResponseWriter, r *http.Request, t auth.Token) error
 	if err != nil {
 		return err
 	}
-	defer func() { evt.DoneCustomData(err, map[string]string{"image": imageID}) }()
+	defer func() { evt.DoneCustomData(err, app.NewDeployEndData(evt, instance, imageID)) }()
 	ctx, cancel := evt.CancelableContext(opts.App.Context())
 	defer cancel()
 	opts.App.ReplaceContext(ctx)
@@ -255,6 +411,47 @@ func deploysList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	return json.NewEncoder(w).Encode(deploys)
 }
 
+// title: deploy stats
+// path: /deploys/stats
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   400: Invalid data
+//   401: Unauthorized
+func deployStats(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	filter := app.DeployStatsFilter{
+		Pool:     r.URL.Query().Get("pool"),
+		Platform: r.URL.Query().Get("platform"),
+		Since:    time.Now().Add(-7 * 24 * time.Hour),
+	}
+	if rawSince := r.URL.Query().Get("since"); rawSince != "" {
+		since, err := time.ParseDuration(rawSince)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid since duration: %v", err)}
+		}
+		filter.Since = time.Now().Add(-since)
+	}
+	var ctxs []permTypes.PermissionContext
+	if filter.Pool != "" {
+		ctxs = append(ctxs, permission.Context(permTypes.CtxPool, filter.Pool))
+	}
+	if !permission.Check(t, permission.PermAppReadDeploy, ctxs...) {
+		return permission.ErrUnauthorized
+	}
+	stats, err := app.DeployStats(filter)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Add("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(stats)
+}
+
 // title: deploy info
 // path: /deploys/{deploy}
 // method: GET
@@ -295,6 +492,7 @@ func deployInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 //   400: Invalid data
 //   403: Forbidden
 //   404: Not found
+//   409: Deploy blocked by pool freeze, approval or gate
 func deployRebuild(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	ctx := r.Context()
 	appName := r.URL.Query().Get(":app")
@@ -326,6 +524,9 @@ func deployRebuild(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if !canDeploy {
 		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: permission.ErrUnauthorized.Error()}
 	}
+	if err = checkDeployAllowed(r, t, opts, false); err != nil {
+		return err
+	}
 	var imageID string
 	evt, err := event.New(&event.Opts{
 		Target:        appTarget(appName),
@@ -340,7 +541,7 @@ func deployRebuild(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if err != nil {
 		return err
 	}
-	defer func() { evt.DoneCustomData(err, map[string]string{"image": imageID}) }()
+	defer func() { evt.DoneCustomData(err, app.NewDeployEndData(evt, instance, imageID)) }()
 	ctx, cancel := evt.CancelableContext(opts.App.Context())
 	defer cancel()
 	opts.App.ReplaceContext(ctx)
@@ -422,3 +623,227 @@ func deployRollbackUpdate(w http.ResponseWriter, r *http.Request, t auth.Token)
 	}
 	return err
 }
+
+// title: auto rollback update
+// path: /apps/{app}/deploy/auto-rollback
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Auto rollback updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func deployAutoRollbackUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	instance, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateDeployAutoRollback, contextsForApp(&instance)...) {
+		return permission.ErrUnauthorized
+	}
+	enabled := InputValue(r, "enabled")
+	var policy app.AutoRollback
+	policy.Enabled, err = strconv.ParseBool(enabled)
+	if err != nil {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Cannot set 'enabled' status to: '%s', instead of 'true' or 'false'", enabled),
+		}
+	}
+	if window := InputValue(r, "window"); window != "" {
+		policy.Window, err = time.ParseDuration(window)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid stabilization window: %s", window)}
+		}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(instance.Name),
+		Kind:       permission.PermAppUpdateDeployAutoRollback,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&instance)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = instance.SetAutoRollback(policy)
+	if v, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+// title: deploy request list
+// path: /apps/{app}/deploy/requests
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func deployRequestList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	instance, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppReadDeploy, contextsForApp(&instance)...) {
+		return permission.ErrUnauthorized
+	}
+	requests, err := app.ListDeployRequests(instance.Name)
+	if err != nil {
+		return err
+	}
+	if len(requests) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(requests)
+}
+
+func getDeployRequest(r *http.Request, t auth.Token) (*app.App, *app.DeployRequest, error) {
+	instance, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !permission.Check(t, permission.PermAppDeployApprove, contextsForApp(&instance)...) {
+		return nil, nil, permission.ErrUnauthorized
+	}
+	req, err := app.GetDeployRequest(instance.Name, r.URL.Query().Get(":id"))
+	if err == app.ErrDeployRequestNotFound {
+		return nil, nil, &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &instance, req, nil
+}
+
+func deployRequestReviewError(err error) error {
+	switch err {
+	case app.ErrDeployRequestSelfReview:
+		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: err.Error()}
+	case app.ErrDeployRequestNotPending:
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
+
+// title: deploy request approve
+// path: /apps/{app}/deploy/requests/{id}/approve
+// method: POST
+// produce: text
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   403: Requester can't approve its own deploy
+//   404: Not found
+//   409: Deploy request is not pending
+func deployRequestApprove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	instance, req, err := getDeployRequest(r, t)
+	if err != nil {
+		return err
+	}
+	if err = checkDeployAllowed(r, t, req.DeployOptions(instance), true); err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(instance.Name),
+		Kind:       permission.PermAppDeployApprove,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: req,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+	})
+	if err != nil {
+		return err
+	}
+	err = req.Approve(t.GetUserName())
+	evt.Done(err)
+	if err != nil {
+		return deployRequestReviewError(err)
+	}
+	w.Header().Set("Content-Type", "text")
+	writer := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "please wait...")
+	defer writer.Stop()
+	fmt.Fprintf(writer, "Deploy request %s approved by %s, deploying...\n", req.ID.Hex(), t.GetUserName())
+	err = deployGroupApp(r, req.DeployOptions(instance), writer)
+	if err == nil {
+		fmt.Fprintln(writer, "\nOK")
+	}
+	return err
+}
+
+// title: deploy request reject
+// path: /apps/{app}/deploy/requests/{id}/reject
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   403: Requester can't reject its own deploy
+//   404: Not found
+//   409: Deploy request is not pending
+func deployRequestReject(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	instance, req, err := getDeployRequest(r, t)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(instance.Name),
+		Kind:       permission.PermAppDeployApprove,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = req.Reject(t.GetUserName(), InputValue(r, "reason"))
+	return deployRequestReviewError(err)
+}
+
+// title: deploy approval update
+// path: /apps/{app}/deploy/approval
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Deploy approval updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func deployApprovalUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	instance, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppAdminDeployApproval, contextsForApp(&instance)...) {
+		return permission.ErrUnauthorized
+	}
+	required := InputValue(r, "required")
+	requiredValue, err := strconv.ParseBool(required)
+	if err != nil {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Cannot set 'required' status to: '%s', instead of 'true' or 'false'", required),
+		}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(instance.Name),
+		Kind:       permission.PermAppAdminDeployApproval,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&instance)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return instance.SetDeployApproval(requiredValue)
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+)
+
+type deployGatesInput struct {
+	Gates []app.DeployGate
+}
+
+// title: deploy gates update
+// path: /apps/{app}/deploy/gates
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Deploy gates updated
+//   400: Invalid deploy gates
+//   401: Unauthorized
+//   404: App not found
+func deployGatesUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	instance, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateDeployGates, contextsForApp(&instance)...) {
+		return permission.ErrUnauthorized
+	}
+	var input deployGatesInput
+	err = ParseInput(r, &input)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(instance.Name),
+		Kind:       permission.PermAppUpdateDeployGates,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&instance)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = instance.SetDeployGates(input.Gates)
+	if v, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+// checkDeployGates calls the deploy gates of the app, unless the user asked
+// to override them with the override-gates flag, which is only allowed to
+// users with the app.admin.deploy-gate-override permission.
+func checkDeployGates(r *http.Request, t auth.Token, opts app.DeployOptions) error {
+	if override, _ := strconv.ParseBool(InputValue(r, "override-gates")); override {
+		if !permission.Check(t, permission.PermAppAdminDeployGateOverride, contextsForApp(opts.App)...) {
+			return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: "User does not have permission to override deploy gates"}
+		}
+		return nil
+	}
+	err := app.CheckDeployGates(r.Context(), opts)
+	if _, ok := err.(*app.ErrDeployBlocked); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
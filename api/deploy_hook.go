@@ -0,0 +1,265 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/deployhook"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const maxDeployHookPayload = 5 * 1024 * 1024
+
+// title: deploy hook info
+// path: /apps/{app}/deploy-hook
+// method: GET
+// produce: application/json
+// responses:
+//   200: Deploy hook
+//   401: Unauthorized
+//   404: App or deploy hook not found
+func deployHookInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateDeployHook, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	hook, err := deployhook.Get(a.Name)
+	if err == deployhook.ErrHookNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(hook)
+}
+
+// title: deploy hook set
+// path: /apps/{app}/deploy-hook
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   200: Deploy hook set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func deployHookSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if t.IsAppToken() || !permission.Check(t, permission.PermAppUpdateDeployHook, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	var hook deployhook.Hook
+	err = ParseInput(r, &hook)
+	if err != nil {
+		return err
+	}
+	hook.App = a.Name
+	hook.User = t.GetUserName()
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateDeployHook,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r, "secret")),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = deployhook.Set(&hook)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(hook)
+}
+
+// title: deploy hook remove
+// path: /apps/{app}/deploy-hook
+// method: DELETE
+// responses:
+//   200: Deploy hook removed
+//   401: Unauthorized
+//   404: App or deploy hook not found
+func deployHookRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateDeployHook, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateDeployHook,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = deployhook.Remove(a.Name)
+	if err == deployhook.ErrHookNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: deploy hook receive
+// path: /hooks/deploy/{app}
+// method: POST
+// consume: application/json
+// responses:
+//   200: Push ignored
+//   202: Deploy started or waiting for approval
+//   400: Invalid payload
+//   401: Invalid secret
+//   403: Forbidden
+//   404: Deploy hook not found
+//   409: Pool frozen
+func deployHookReceive(w http.ResponseWriter, r *http.Request) error {
+	appName := r.URL.Query().Get(":app")
+	hook, err := deployhook.Get(appName)
+	if err == deployhook.ErrHookNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxDeployHookPayload))
+	if err != nil {
+		return err
+	}
+	err = hook.Verify(r.Header, body)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusUnauthorized, Message: err.Error()}
+	}
+	push, err := deployhook.ParsePush(r.Header, body)
+	if err == deployhook.ErrUnsupportedEvent {
+		fmt.Fprintln(w, "Event ignored.")
+		return nil
+	}
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if push.Ping {
+		fmt.Fprintln(w, "pong")
+		return nil
+	}
+	if !hook.Match(push) {
+		fmt.Fprintf(w, "Push to %s ignored, it doesn't match the deploy hook rules.\n", pushRef(push))
+		return nil
+	}
+	if _, err = auth.GetUserByEmail(hook.User); err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: fmt.Sprintf("deploy hook user %q not found", hook.User)}
+	}
+	t := &auth.APIToken{UserEmail: hook.User}
+	ctx := r.Context()
+	instance, err := app.GetByName(ctx, appName)
+	if err == appTypes.ErrAppNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	opts := app.DeployOptions{
+		App:     instance,
+		User:    hook.User,
+		Message: push.Message,
+	}
+	if push.Image != "" {
+		opts.Image = push.Image
+		opts.Origin = "image"
+	} else {
+		opts.ArchiveURL = push.ArchiveURL
+		opts.Commit = push.Commit
+		opts.Origin = "git"
+	}
+	opts.GetKind()
+	if !permission.Check(t, permSchemeForDeploy(opts), contextsForApp(instance)...) {
+		return &tsuruErrors.HTTP{Code: http.StatusForbidden, Message: "Deploy hook user does not have permission to deploy this app"}
+	}
+	err = checkDeployAllowed(r, t, opts, false)
+	if err == errDeployNeedsApproval {
+		// Deploy requests are replayed later from the archive, so they
+		// can't carry the commit of git deploys.
+		opts.Commit = ""
+		req, errReq := app.CreateDeployRequest(opts)
+		if errReq != nil {
+			return errReq
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "Deploy request %s created. It must be approved by another user before %s to run.\n", req.ID.Hex(), req.ExpiresAt.Format(time.RFC3339))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:        appTarget(appName),
+		Kind:          permission.PermAppDeploy,
+		RawOwner:      event.Owner{Type: event.OwnerTypeUser, Name: hook.User},
+		RemoteAddr:    r.RemoteAddr,
+		CustomData:    opts,
+		Allowed:       event.Allowed(permission.PermAppReadEvents, contextsForApp(instance)...),
+		AllowedCancel: event.Allowed(permission.PermAppUpdateEvents, contextsForApp(instance)...),
+		Cancelable:    true,
+	})
+	if err != nil {
+		return err
+	}
+	opts.Event = evt
+	opts.OutputStream = ioutil.Discard
+	go func() {
+		var imageID string
+		var deployErr error
+		defer func() { evt.DoneCustomData(deployErr, app.NewDeployEndData(evt, instance, imageID)) }()
+		deployCtx, cancel := evt.CancelableContext(context.Background())
+		defer cancel()
+		instance.ReplaceContext(deployCtx)
+		imageID, deployErr = app.Deploy(deployCtx, opts)
+		if deployErr != nil {
+			log.Errorf("[deploy hook] deploy of app %s from %s failed: %v", appName, pushRef(push), deployErr)
+		}
+	}()
+	w.Header().Set(eventIDHeader, evt.UniqueID.Hex())
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintf(w, "Deploy of app %s from %s started (event %s).\n", appName, pushRef(push), evt.UniqueID.Hex())
+	return nil
+}
+
+func pushRef(p *deployhook.Push) string {
+	if p.Image != "" {
+		return "image " + p.Image
+	}
+	if p.Branch == "" {
+		return "a non-branch ref"
+	}
+	return "branch " + p.Branch
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/deployhook"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createDeployHookApp(c *check.C) *app.App {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestDeployHookSet(c *check.C) {
+	s.createDeployHookApp(c)
+	body := strings.NewReader("branches.0=main&branches.1=release-*&tags.0=v*")
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/deploy-hook", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	var result deployhook.Hook
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Secret, check.Not(check.Equals), "")
+	hook, err := deployhook.Get("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(hook.Secret, check.Equals, result.Secret)
+	c.Assert(hook.User, check.Equals, s.user.Email)
+	c.Assert(hook.Branches, check.DeepEquals, []string{"main", "release-*"})
+	c.Assert(hook.Tags, check.DeepEquals, []string{"v*"})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget("myapp"),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.deploy-hook",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestDeployHookSetInvalid(c *check.C) {
+	s.createDeployHookApp(c)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/deploy-hook", strings.NewReader(""))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestDeployHookSetUnauthorized(c *check.C) {
+	s.createDeployHookApp(c)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/deploy-hook", strings.NewReader("branches.0=main"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestDeployHookRemove(c *check.C) {
+	s.createDeployHookApp(c)
+	err := deployhook.Set(&deployhook.Hook{App: "myapp", User: s.user.Email, Branches: []string{"main"}})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.13/apps/myapp/deploy-hook", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = deployhook.Get("myapp")
+	c.Assert(err, check.Equals, deployhook.ErrHookNotFound)
+}
+
+func (s *S) TestDeployHookReceiveNotFound(c *check.C) {
+	request, err := http.NewRequest("POST", "/1.13/hooks/deploy/myapp", strings.NewReader("{}"))
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestDeployHookReceiveInvalidSignature(c *check.C) {
+	s.createDeployHookApp(c)
+	err := deployhook.Set(&deployhook.Hook{App: "myapp", User: s.user.Email, Secret: "s3cr3t", Branches: []string{"main"}})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.13/hooks/deploy/myapp", strings.NewReader(`{"ref":"refs/heads/main"}`))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("X-GitHub-Event", "push")
+	request.Header.Set("X-Hub-Signature-256", "sha256=abc")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusUnauthorized)
+}
+
+func (s *S) TestDeployHookReceiveIgnoredBranch(c *check.C) {
+	s.createDeployHookApp(c)
+	err := deployhook.Set(&deployhook.Hook{App: "myapp", User: s.user.Email, Secret: "s3cr3t", Branches: []string{"main"}})
+	c.Assert(err, check.IsNil)
+	body := `{"ref":"refs/heads/feature","after":"abc123","repository":{"html_url":"https://github.com/org/repo"}}`
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte(body))
+	request, err := http.NewRequest("POST", "/1.13/hooks/deploy/myapp", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("X-GitHub-Event", "push")
+	request.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Equals, "Push to branch feature ignored, it doesn't match the deploy hook rules.\n")
+}
+
+func (s *S) TestDeployHookReceiveSecretInQuery(c *check.C) {
+	s.createDeployHookApp(c)
+	err := deployhook.Set(&deployhook.Hook{App: "myapp", User: s.user.Email, Secret: "s3cr3t", Tags: []string{"v*"}})
+	c.Assert(err, check.IsNil)
+	body := `{"push_data":{"tag":"v1"},"repository":{"repo_name":"org/myapp"}}`
+	request, err := http.NewRequest("POST", "/1.13/hooks/deploy/myapp?secret=s3cr3t", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusUnauthorized)
+}
+
+func (s *S) TestDeployHookReceiveRegistryPush(c *check.C) {
+	s.createDeployHookApp(c)
+	err := deployhook.Set(&deployhook.Hook{App: "myapp", User: s.user.Email, Secret: "s3cr3t", Tags: []string{"v*"}})
+	c.Assert(err, check.IsNil)
+	body := `{"push_data":{"tag":"v1"},"repository":{"repo_name":"org/myapp"}}`
+	request, err := http.NewRequest("POST", "/1.13/hooks/deploy/myapp", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set(deployhook.SecretHeader, "s3cr3t")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusAccepted, check.Commentf("body: %s", recorder.Body.String()))
+	evtID := recorder.Header().Get(eventIDHeader)
+	c.Assert(evtID, check.Not(check.Equals), "")
+	evt, err := event.GetByHexID(evtID)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Target, check.DeepEquals, appTarget("myapp"))
+	c.Assert(evt.Owner.Name, check.Equals, s.user.Email)
+	c.Assert(evt.Kind.Name, check.Equals, "app.deploy")
+}
@@ -5,21 +5,27 @@
 package api
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/globalsign/mgo/bson"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/delta"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/db"
@@ -357,6 +363,35 @@ func (s *DeploySuite) TestDeployArchiveURL(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *DeploySuite) TestDeployPlanOnlyNotSupported(c *check.C) {
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
+		c.Fatal("build should not be called")
+		return nil, nil
+	}
+	a := app.App{
+		Name:      "otherapp",
+		Platform:  "python",
+		TeamOwner: s.team.Name,
+		Router:    "fake",
+	}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("/apps/%s/deploy", a.Name)
+	request, err := http.NewRequest("POST", url, strings.NewReader("archive-url=http://something.tar.gz&plan-only=true"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "provisioner \"fake\" does not support deploy plan\n")
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Kind:   "app.deploy",
+	}, check.Not(eventtest.HasEvent))
+}
+
 func (s *DeploySuite) TestDeployUploadFile(c *check.C) {
 	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
 		return newAppVersion(c, app), nil
@@ -1384,6 +1419,230 @@ func (s *DeploySuite) TestRollbackUpdate(c *check.C) {
 	c.Assert(disabledVersion.DisabledReason, check.Equals, "because of reasons")
 }
 
+func (s *DeploySuite) TestDeployAutoRollbackUpdate(c *check.C) {
+	fakeApp := app.App{Name: "otherapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &fakeApp, s.user)
+	c.Assert(err, check.IsNil)
+	v := url.Values{}
+	v.Set("enabled", "true")
+	v.Set("window", "2m")
+	url := fmt.Sprintf("/apps/%s/deploy/auto-rollback", fakeApp.Name)
+	request, err := http.NewRequest(http.MethodPut, url, strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myadmin", permission.Permission{
+		Scheme:  permission.PermAppUpdateDeployAutoRollback,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	server := RunServer(true)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), fakeApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.AutoRollback, check.DeepEquals, app.AutoRollback{Enabled: true, Window: 2 * time.Minute})
+}
+
+func (s *DeploySuite) TestDeployAutoRollbackUpdateInvalidWindow(c *check.C) {
+	fakeApp := app.App{Name: "otherapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &fakeApp, s.user)
+	c.Assert(err, check.IsNil)
+	url := fmt.Sprintf("/apps/%s/deploy/auto-rollback", fakeApp.Name)
+	request, err := http.NewRequest(http.MethodPut, url, strings.NewReader("enabled=true&window=2h"))
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myadmin", permission.Permission{
+		Scheme:  permission.PermAppUpdateDeployAutoRollback,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	server := RunServer(true)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "stabilization window must be between 0 and 30m0s\n")
+}
+
+func (s *DeploySuite) TestDeployGatesUpdate(c *check.C) {
+	fakeApp := app.App{Name: "otherapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &fakeApp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("gates.0.name=budget&gates.0.url=http://budget/check")
+	request, err := http.NewRequest(http.MethodPut, "/apps/otherapp/deploy/gates", body)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myadmin", permission.Permission{
+		Scheme:  permission.PermAppUpdateDeployGates,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	server := RunServer(true)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), fakeApp.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeployGates, check.DeepEquals, []app.DeployGate{{Name: "budget", URL: "http://budget/check"}})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(fakeApp.Name),
+		Owner:  token.GetUserName(),
+		Kind:   "app.update.deploy-gates",
+	}, eventtest.HasEvent)
+}
+
+func (s *DeploySuite) TestDeployBlockedByGate(c *check.C) {
+	gate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("change freeze until monday"))
+	}))
+	defer gate.Close()
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
+		return newAppVersion(c, app), nil
+	}
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetDeployGates([]app.DeployGate{{Name: "freeze", URL: gate.URL}})
+	c.Assert(err, check.IsNil)
+	server := RunServer(true)
+	request, err := http.NewRequest(http.MethodPost, "/apps/myapp/deploy", strings.NewReader("image=127.0.0.1:5000/tsuru/otherapp"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Equals, "deploy blocked by gate \"freeze\": change freeze until monday\n")
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "deployer", permission.Permission{
+		Scheme:  permission.PermAppDeploy,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request, err = http.NewRequest(http.MethodPost, "/apps/myapp/deploy", strings.NewReader("image=127.0.0.1:5000/tsuru/otherapp&override-gates=true"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	request, err = http.NewRequest(http.MethodPost, "/apps/myapp/deploy", strings.NewReader("image=127.0.0.1:5000/tsuru/otherapp&override-gates=true"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+}
+
+func (s *DeploySuite) TestDeployRequiresApproval(c *check.C) {
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
+		return newAppVersion(c, app), nil
+	}
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetDeployApproval(true)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPost, "/apps/myapp/deploy", strings.NewReader("image=127.0.0.1:5000/tsuru/otherapp"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusAccepted, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Body.String(), check.Matches, "Deploy request [0-9a-f]{24} created.*\n")
+	requests, err := app.ListDeployRequests(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(requests, check.HasLen, 1)
+	c.Assert(requests[0].Status, check.Equals, app.DeployRequestPending)
+	c.Assert(requests[0].User, check.Equals, s.token.GetUserName())
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Deploys, check.Equals, uint(0))
+	approveURL := fmt.Sprintf("/apps/myapp/deploy/requests/%s/approve", requests[0].ID.Hex())
+	request, err = http.NewRequest(http.MethodPost, approveURL, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "approver", permission.Permission{
+		Scheme:  permission.PermAppDeployApprove,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request, err = http.NewRequest(http.MethodPost, approveURL, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Body.String(), check.Matches, "(?s).*Builder deploy called\nOK\n")
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  token.GetUserName(),
+		Kind:   "app.deploy.approve",
+	}, eventtest.HasEvent)
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.deploy",
+		StartCustomData: map[string]interface{}{
+			"app.name": a.Name,
+			"image":    "127.0.0.1:5000/tsuru/otherapp",
+			"user":     s.token.GetUserName(),
+		},
+	}, eventtest.HasEvent)
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *DeploySuite) TestDeployRequestReject(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	req, err := app.CreateDeployRequest(app.DeployOptions{App: &a, Image: "tsuru/otherapp", User: s.token.GetUserName()})
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "approver", permission.Permission{
+		Scheme:  permission.PermAppDeployApprove,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	url := fmt.Sprintf("/apps/myapp/deploy/requests/%s/reject", req.ID.Hex())
+	request, err := http.NewRequest(http.MethodPost, url, strings.NewReader("reason=not+now"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	RunServer(true).ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbReq, err := app.GetDeployRequest(a.Name, req.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(dbReq.Status, check.Equals, app.DeployRequestRejected)
+	c.Assert(dbReq.ReviewedBy, check.Equals, token.GetUserName())
+	c.Assert(dbReq.Reason, check.Equals, "not now")
+}
+
+func (s *DeploySuite) TestDeployApprovalUpdate(c *check.C) {
+	a := app.App{Name: "otherapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodPut, "/apps/otherapp/deploy/approval", strings.NewReader("required=true"))
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "myadmin", permission.Permission{
+		Scheme:  permission.PermAppAdminDeployApproval,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	RunServer(true).ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeployApproval, check.Equals, true)
+}
+
 func (s *DeploySuite) TestRollbackUpdateInvalidImage(c *check.C) {
 	fakeApp := app.App{Name: "otherapp", TeamOwner: s.team.Name}
 	err := app.CreateApp(context.TODO(), &fakeApp, s.user)
@@ -1502,3 +1761,261 @@ func (s *DeploySuite) TestRollbackUpdateErrNoPerms(c *check.C) {
 	c.Assert(recorder.Body.String(), check.Equals, "User does not have permission to do this action in this app\n")
 	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
 }
+
+func (s *DeploySuite) TestDeployStats(c *check.C) {
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: "app", Value: "g1"},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: "me@tsuru.io"},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.DoneCustomData(nil, app.DeployEndData{
+		Pool:     "pool1",
+		Platform: "python",
+		Stages:   map[string]time.Duration{"build": time.Minute},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/deploys/stats?pool=pool1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var stats []app.DeployStageStats
+	err = json.Unmarshal(recorder.Body.Bytes(), &stats)
+	c.Assert(err, check.IsNil)
+	c.Assert(stats, check.DeepEquals, []app.DeployStageStats{
+		{Pool: "pool1", Platform: "python", Stage: "build", Count: 1, P50: time.Minute, P90: time.Minute, P99: time.Minute},
+	})
+}
+
+func (s *DeploySuite) TestDeployStatsInvalidSince(c *check.C) {
+	request, err := http.NewRequest("GET", "/deploys/stats?since=abc", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *DeploySuite) TestDeployStatsUnauthorized(c *check.C) {
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "poolonly", permission.Permission{
+		Scheme:  permission.PermAppReadDeploy,
+		Context: permission.Context(permTypes.CtxPool, "pool1"),
+	})
+	request, err := http.NewRequest("GET", "/deploys/stats?pool=pool2", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *DeploySuite) TestAppVersionArtifact(c *check.C) {
+	a := app.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	s.builder.OnArtifact = func(p provision.BuilderDeploy, app provision.App, version appTypes.AppVersion) (*builder.Artifact, error) {
+		c.Assert(app.GetName(), check.Equals, "otherapp")
+		c.Assert(version.Version(), check.Equals, 1)
+		return &builder.Artifact{
+			ReadCloser:  ioutil.NopCloser(strings.NewReader("archive data")),
+			Name:        "archive.tar.gz",
+			ContentType: "application/gzip",
+		}, nil
+	}
+	request, err := http.NewRequest("GET", "/apps/otherapp/versions/1/artifact", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/gzip")
+	c.Assert(recorder.Header().Get("Content-Disposition"), check.Equals, `attachment; filename="archive.tar.gz"`)
+	c.Assert(recorder.Body.String(), check.Equals, "archive data")
+}
+
+func (s *DeploySuite) TestAppVersionArtifactNotFound(c *check.C) {
+	a := app.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	for _, version := range []string{"1", "9"} {
+		request, err := http.NewRequest("GET", "/apps/otherapp/versions/"+version+"/artifact", nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		server := RunServer(true)
+		server.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	}
+}
+
+func (s *DeploySuite) TestAppVersionArtifactUnauthorized(c *check.C) {
+	a := app.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "reader", permission.Permission{
+		Scheme:  permission.PermAppReadDeploy,
+		Context: permission.Context(permTypes.CtxApp, a.Name),
+	})
+	request, err := http.NewRequest("GET", "/apps/otherapp/versions/1/artifact", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func deltaArchive(c *check.C, files map[string]string) []byte {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range names {
+		err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg})
+		c.Assert(err, check.IsNil)
+		_, err = tarWriter.Write([]byte(files[name]))
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(tarWriter.Close(), check.IsNil)
+	c.Assert(gzipWriter.Close(), check.IsNil)
+	return buf.Bytes()
+}
+
+func (s *DeploySuite) setDeltaBase(c *check.C, archive []byte) {
+	s.builder.OnArtifact = func(p provision.BuilderDeploy, app provision.App, version appTypes.AppVersion) (*builder.Artifact, error) {
+		return &builder.Artifact{
+			ReadCloser:  ioutil.NopCloser(bytes.NewReader(archive)),
+			Name:        "archive.tar.gz",
+			ContentType: "application/gzip",
+		}, nil
+	}
+}
+
+func (s *DeploySuite) TestDeployDeltaBase(c *check.C) {
+	a := app.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	archive := deltaArchive(c, map[string]string{"Procfile": "web: ./app"})
+	s.setDeltaBase(c, archive)
+	request, err := http.NewRequest("GET", "/apps/otherapp/deploy/delta-base", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var base delta.Base
+	err = json.Unmarshal(recorder.Body.Bytes(), &base)
+	c.Assert(err, check.IsNil)
+	c.Assert(base, check.DeepEquals, delta.Base{
+		Digest: fmt.Sprintf("%x", sha256.Sum256(archive)),
+		Files:  []delta.File{{Path: "Procfile", Digest: fmt.Sprintf("%x", sha256.Sum256([]byte("web: ./app")))}},
+	})
+}
+
+func (s *DeploySuite) TestDeployDeltaBaseNotFound(c *check.C) {
+	a := app.App{Name: "otherapp", Platform: "python", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/apps/otherapp/deploy/delta-base", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *DeploySuite) deltaDeployRequest(c *check.C, appName string, manifest delta.Manifest, patch []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	rawManifest, err := json.Marshal(manifest)
+	c.Assert(err, check.IsNil)
+	err = writer.WriteField("delta-manifest", string(rawManifest))
+	c.Assert(err, check.IsNil)
+	file, err := writer.CreateFormFile("file", "archive.tar.gz")
+	c.Assert(err, check.IsNil)
+	file.Write(patch)
+	writer.Close()
+	request, err := http.NewRequest("POST", fmt.Sprintf("/apps/%s/deploy", appName), &body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "multipart/form-data; boundary="+writer.Boundary())
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *DeploySuite) TestDeployDelta(c *check.C) {
+	a := app.App{Name: "otherapp", Platform: "python", Router: "fake", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	base := deltaArchive(c, map[string]string{"Procfile": "web: ./app", "main.go": "package main"})
+	s.setDeltaBase(c, base)
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
+		data, err := ioutil.ReadAll(opts.ArchiveFile)
+		c.Assert(err, check.IsNil)
+		c.Assert(opts.ArchiveSize, check.Equals, int64(len(data)))
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		c.Assert(err, check.IsNil)
+		tarReader := tar.NewReader(gzipReader)
+		files := map[string]string{}
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			c.Assert(err, check.IsNil)
+			content, err := ioutil.ReadAll(tarReader)
+			c.Assert(err, check.IsNil)
+			files[header.Name] = string(content)
+		}
+		c.Assert(files, check.DeepEquals, map[string]string{"Procfile": "web: ./app", "main.go": "package main // changed"})
+		return newAppVersion(c, app), nil
+	}
+	manifest := delta.Manifest{
+		Base: fmt.Sprintf("%x", sha256.Sum256(base)),
+		Files: []delta.File{
+			{Path: "Procfile", Digest: fmt.Sprintf("%x", sha256.Sum256([]byte("web: ./app")))},
+			{Path: "main.go", Digest: fmt.Sprintf("%x", sha256.Sum256([]byte("package main // changed")))},
+		},
+	}
+	patch := deltaArchive(c, map[string]string{"main.go": "package main // changed"})
+	recorder := s.deltaDeployRequest(c, a.Name, manifest, patch)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), check.Matches, ".*Builder deploy called\nOK\n")
+}
+
+func (s *DeploySuite) TestDeployDeltaBaseMismatch(c *check.C) {
+	a := app.App{Name: "otherapp", Platform: "python", Router: "fake", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	s.setDeltaBase(c, deltaArchive(c, map[string]string{"Procfile": "web: ./app"}))
+	manifest := delta.Manifest{
+		Base:  "somethingelse",
+		Files: []delta.File{{Path: "Procfile", Digest: fmt.Sprintf("%x", sha256.Sum256([]byte("web: ./app")))}},
+	}
+	recorder := s.deltaDeployRequest(c, a.Name, manifest, deltaArchive(c, nil))
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Equals, delta.ErrBaseMismatch.Error()+"\n")
+}
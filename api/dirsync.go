@@ -0,0 +1,152 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/dirsync"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: directory sync mapping list
+// path: /directory-sync/mappings
+// method: GET
+// produce: application/json
+// responses:
+//   200: List directory sync mappings
+//   204: No content
+//   401: Unauthorized
+func dirSyncMappingList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermDirectorySyncRead) {
+		return permission.ErrUnauthorized
+	}
+	mappings, err := dirsync.List()
+	if err != nil {
+		return err
+	}
+	if len(mappings) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(mappings)
+}
+
+// title: directory sync mapping create
+// path: /directory-sync/mappings
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   201: Directory sync mapping created
+//   400: Invalid mapping
+//   401: Unauthorized
+//   409: Directory sync mapping already exists
+func dirSyncMappingCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermDirectorySyncCreate) {
+		return permission.ErrUnauthorized
+	}
+	var mapping dirsync.Mapping
+	err = ParseInput(r, &mapping)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeDirectorySync, Value: mapping.Group},
+		Kind:       permission.PermDirectorySyncCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermDirectorySyncRead),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = dirsync.Create(r.Context(), &mapping)
+	if err == dirsync.ErrMappingAlreadyExists {
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(mapping)
+}
+
+// title: directory sync mapping delete
+// path: /directory-sync/mappings/{id}
+// method: DELETE
+// responses:
+//   200: Directory sync mapping removed
+//   401: Unauthorized
+//   404: Directory sync mapping not found
+func dirSyncMappingDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermDirectorySyncDelete) {
+		return permission.ErrUnauthorized
+	}
+	id := r.URL.Query().Get(":id")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeDirectorySync, Value: id},
+		Kind:       permission.PermDirectorySyncDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermDirectorySyncRead),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = dirsync.Remove(id)
+	if err == dirsync.ErrMappingNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: directory sync run
+// path: /directory-sync/run
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   200: Sync result
+//   400: Directory sync not configured
+//   401: Unauthorized
+func dirSyncRun(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermDirectorySyncRun) {
+		return permission.ErrUnauthorized
+	}
+	dryRun, _ := strconv.ParseBool(InputValue(r, "dry"))
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeDirectorySync, Value: "run"},
+		Kind:       permission.PermDirectorySyncRun,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermDirectorySyncRead),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	result, err := dirsync.Sync(r.Context(), dryRun)
+	if err == dirsync.ErrNotConfigured {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/discovery"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestListAppDiscoveryEntries(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = discovery.Update(a.Name, map[string][]string{"web": {"10.0.0.1:8888"}, "worker": {"10.0.0.2:8888"}})
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/discovery", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var entries []discovery.Entry
+	err = json.Unmarshal(recorder.Body.Bytes(), &entries)
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 2)
+	c.Assert(entries[0].Name, check.Equals, "myapp.web.tsuru.internal")
+	c.Assert(entries[0].Addresses, check.DeepEquals, []string{"10.0.0.1:8888"})
+	c.Assert(entries[1].Name, check.Equals, "myapp.worker.tsuru.internal")
+}
+
+func (s *S) TestResolveDiscoveryName(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = discovery.Update(a.Name, map[string][]string{"web": {"10.0.0.2:8888", "10.0.0.1:8888"}})
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("GET", "/1.13/discovery/myapp.web.tsuru.internal", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var entry discovery.Entry
+	err = json.Unmarshal(recorder.Body.Bytes(), &entry)
+	c.Assert(err, check.IsNil)
+	c.Assert(entry.App, check.Equals, "myapp")
+	c.Assert(entry.Process, check.Equals, "web")
+	c.Assert(entry.Addresses, check.DeepEquals, []string{"10.0.0.1:8888", "10.0.0.2:8888"})
+}
+
+func (s *S) TestResolveDiscoveryNameNotFound(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("GET", "/1.13/discovery/unknown.web.tsuru.internal", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestResolveDiscoveryNameUnauthorized(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = discovery.Update(a.Name, map[string][]string{"web": {"10.0.0.1:8888"}})
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, "other-team"),
+	})
+	request, err := http.NewRequest("GET", "/1.13/discovery/myapp.web.tsuru.internal", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+type appDisruptionInfo struct {
+	App       appTypes.DisruptionSettings           `json:"app"`
+	Effective provision.EffectiveDisruptionSettings `json:"effective"`
+}
+
+// title: app disruption settings info
+// path: /apps/{app}/disruption
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   400: Provisioner doesn't support disruption settings
+//   401: Unauthorized
+//   404: App not found
+func disruptionInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppRead, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	effective, err := a.EffectiveDisruptionSettings()
+	if err != nil {
+		if _, ok := err.(provision.ProvisionerNotSupported); ok {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(appDisruptionInfo{
+		App:       a.Disruption,
+		Effective: effective,
+	})
+}
+
+// title: app disruption settings update
+// path: /apps/{app}/disruption
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Disruption settings updated
+//   400: Invalid disruption settings
+//   401: Unauthorized
+//   404: App not found
+func disruptionUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateDisruption, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	var settings appTypes.DisruptionSettings
+	err = ParseInput(r, &settings)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdateDisruption,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = a.SetDisruptionSettings(settings)
+	if v, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
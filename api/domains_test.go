@@ -0,0 +1,121 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/dns"
+	"github.com/tsuru/tsuru/dns/dnstest"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAddAppDomain(c *check.C) {
+	config.Set("dns:providers:fake-dns:driver", "fake")
+	config.Set("dns:providers:fake-dns:zones", []interface{}{"mycompany.com"})
+	defer func() {
+		config.Unset("dns")
+		dns.Reset()
+		dnstest.Provider.Reset()
+	}()
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppUpdateCnameAdd,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader("domain=www.mycompany.com")
+	request, err := http.NewRequest("POST", "/1.13/apps/myapp/domains", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	var d app.Domain
+	err = json.Unmarshal(recorder.Body.Bytes(), &d)
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Status, check.Equals, app.DomainStatusActive)
+	c.Assert(d.Provider, check.Equals, "fake-dns")
+	_, ok := dnstest.Provider.Record("mycompany.com", "www.mycompany.com", dns.RecordTypeCNAME)
+	c.Assert(ok, check.Equals, true)
+
+	request, err = http.NewRequest("GET", "/1.13/apps/myapp/domains", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var domains []app.Domain
+	err = json.Unmarshal(recorder.Body.Bytes(), &domains)
+	c.Assert(err, check.IsNil)
+	c.Assert(domains, check.HasLen, 1)
+	c.Assert(domains[0].Name, check.Equals, "www.mycompany.com")
+
+	request, err = http.NewRequest("DELETE", "/1.13/apps/myapp/domains/www.mycompany.com", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, ok = dnstest.Provider.Record("mycompany.com", "www.mycompany.com", dns.RecordTypeCNAME)
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestAddAppDomainInUse(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	other := app.App{Name: "otherapp", Platform: "go", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &other, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = other.AddDomain(context.TODO(), "www.example.com")
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("domain=www.example.com")
+	request, err := http.NewRequest("POST", "/1.13/apps/myapp/domains", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrDomainAlreadyInUse.Error()+"\n")
+}
+
+func (s *S) TestVerifyAppDomainNotVerified(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = a.AddDomain(context.TODO(), "www.invalid")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.13/apps/myapp/domains/www.invalid/verify", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, app.ErrDomainNotVerified.Error()+"\n")
+}
+
+func (s *S) TestRemoveAppDomainNotFound(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.13/apps/myapp/domains/www.example.com", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
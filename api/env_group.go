@@ -0,0 +1,326 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/envgroup"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+type envGroupInfoResponse struct {
+	*envgroup.Group
+	Apps []string `json:"apps"`
+}
+
+func envGroupTarget(name string) event.Target {
+	return event.Target{Type: event.TargetTypeEnvGroup, Value: name}
+}
+
+// title: env group list
+// path: /env-groups
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+func envGroupList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	groups, err := envgroup.List()
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(groups)
+}
+
+// title: env group info
+// path: /env-groups/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   404: Not found
+func envGroupInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	g, err := envgroup.Get(r.URL.Query().Get(":name"))
+	if err == envgroup.ErrGroupNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	apps, err := envgroup.Apps(g.Name)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(envGroupInfoResponse{Group: g, Apps: apps})
+}
+
+// title: env group create
+// path: /env-groups
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   201: Env group created
+//   400: Invalid data
+//   401: Unauthorized
+//   409: Env group already exists
+func envGroupCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermEnvGroupCreate) {
+		return permission.ErrUnauthorized
+	}
+	var g envgroup.Group
+	err = ParseInput(r, &g)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     envGroupTarget(g.Name),
+		Kind:       permission.PermEnvGroupCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermEnvGroupReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = envgroup.Create(g)
+	if err == envgroup.ErrGroupAlreadyExists {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// title: env group update
+// path: /env-groups/{name}
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: Env group updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Env group not found
+func envGroupUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermEnvGroupUpdate) {
+		return permission.ErrUnauthorized
+	}
+	var g envgroup.Group
+	err = ParseInput(r, &g)
+	if err != nil {
+		return err
+	}
+	g.Name = r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     envGroupTarget(g.Name),
+		Kind:       permission.PermEnvGroupUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermEnvGroupReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = envgroup.Update(g)
+	if err == envgroup.ErrGroupNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	names, err := envgroup.Apps(g.Name)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	var failed []string
+	for _, name := range names {
+		a, errGet := app.GetByName(r.Context(), name)
+		if errGet == appTypes.ErrAppNotFound {
+			continue
+		}
+		if errGet != nil {
+			return errGet
+		}
+		fmt.Fprintf(evt, "---- Syncing app %q ----\n", a.Name)
+		if errSync := envGroupSyncApp(r, t, &g, a, writer); errSync != nil {
+			fmt.Fprintf(evt, "Unable to sync app %q: %s\n", a.Name, errSync)
+			failed = append(failed, a.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("apps not synced: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func envGroupSyncApp(r *http.Request, t auth.Token, g *envgroup.Group, a *app.App, w *tsuruIo.SimpleJsonMessageEncoderWriter) (err error) {
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(a.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: envGroupTarget(g.Name)}},
+		Kind:         permission.PermEnvGroupUpdate,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.SetLogWriter(w)
+	return g.SyncApp(r.Context(), a, evt)
+}
+
+// title: env group delete
+// path: /env-groups/{name}
+// method: DELETE
+// responses:
+//   200: Env group removed
+//   401: Unauthorized
+//   404: Env group not found
+//   409: Env group is attached to apps
+func envGroupDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermEnvGroupDelete) {
+		return permission.ErrUnauthorized
+	}
+	name := r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     envGroupTarget(name),
+		Kind:       permission.PermEnvGroupDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermEnvGroupReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = envgroup.Remove(name)
+	switch err {
+	case envgroup.ErrGroupNotFound:
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	case envgroup.ErrGroupInUse:
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
+
+// title: app env group attach
+// path: /apps/{app}/env-groups/{group}
+// method: PUT
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App or env group not found
+func appEnvGroupAttach(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateEnvGroup, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	g, err := envgroup.Get(r.URL.Query().Get(":group"))
+	if err == envgroup.ErrGroupNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(a.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: envGroupTarget(g.Name)}},
+		Kind:         permission.PermAppUpdateEnvGroup,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	return g.Attach(r.Context(), &a, evt)
+}
+
+// title: app env group detach
+// path: /apps/{app}/env-groups/{group}
+// method: DELETE
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App not found or env group not attached
+func appEnvGroupDetach(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateEnvGroup, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	name := r.URL.Query().Get(":group")
+	var attached bool
+	for _, g := range a.EnvGroups {
+		attached = attached || g == name
+	}
+	if !attached {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: envgroup.ErrNotAttached.Error()}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(a.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: envGroupTarget(name)}},
+		Kind:         permission.PermAppUpdateEnvGroup,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	return envgroup.Detach(r.Context(), &a, name, evt)
+}
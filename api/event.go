@@ -32,8 +32,15 @@ func eventList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if err != nil {
 		return err
 	}
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return err
+	}
 	filter.LoadKindNames(r.Form)
 	filter.PruneUserValues()
+	if opts.offset > 0 {
+		filter.Skip = opts.offset
+	}
 	filter.Permissions, err = t.Permissions()
 	if err != nil {
 		return err
@@ -42,18 +49,17 @@ func eventList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	if err != nil {
 		return err
 	}
-	if len(events) == 0 {
-		w.WriteHeader(http.StatusNoContent)
-		return nil
-	}
 	for _, event := range events {
 		err = suppressSensitiveEnvs(event)
 		if err != nil {
 			return err
 		}
 	}
-	w.Header().Add("Content-Type", "application/json")
-	return json.NewEncoder(w).Encode(events)
+	var next int
+	if len(events) == filter.Limit {
+		next = filter.Skip + filter.Limit
+	}
+	return writePage(w, r, opts, events, next)
 }
 
 // title: kind list
@@ -0,0 +1,167 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/gitops"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: gitops status
+// path: /gitops
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No sync yet
+//   401: Unauthorized
+func gitopsStatus(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermGitopsRead) {
+		return permission.ErrUnauthorized
+	}
+	st, err := gitops.GetStatus()
+	if err != nil {
+		return err
+	}
+	if st == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(st)
+}
+
+// title: gitops plan
+// path: /gitops/plan
+// method: POST
+// consume: application/gzip
+// produce: application/json
+// responses:
+//   200: OK
+//   400: Invalid bundle
+//   401: Unauthorized
+func gitopsPlan(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermGitopsRead) {
+		return permission.ErrUnauthorized
+	}
+	ctx := r.Context()
+	var b *gitops.Bundle
+	var err error
+	if r.ContentLength != 0 {
+		b, err = gitops.ReadArchive(r.Body)
+	} else {
+		b, err = gitops.Fetch(ctx)
+	}
+	if err == gitops.ErrNoSource {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	plan, err := gitops.NewPlan(ctx, b, gitops.Prune())
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(plan)
+}
+
+// title: gitops bundle upload
+// path: /gitops/bundle
+// method: PUT
+// consume: application/gzip
+// responses:
+//   200: Bundle stored
+//   400: Invalid bundle
+//   401: Unauthorized
+func gitopsBundleUpload(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermGitopsUpdate) {
+		return permission.ErrUnauthorized
+	}
+	if gitops.Source() != gitops.SourceUpload {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "bundles are read from the gitops repository, uploads are not allowed"}
+	}
+	b, err := gitops.ReadArchive(r.Body)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     gitops.Target(),
+		Kind:       permission.PermGitopsUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]string{"revision": b.Revision},
+		Allowed:    event.Allowed(permission.PermGitopsRead),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = gitops.SaveBundle(b)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Bundle revision %s stored, with %d pools, %d service instances and %d apps.\n", b.Revision, len(b.Pools), len(b.ServiceInstances), len(b.Apps))
+	return nil
+}
+
+// title: gitops sync
+// path: /gitops/sync
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: Synced
+//   401: Unauthorized
+func gitopsSync(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermGitopsUpdate) {
+		return permission.ErrUnauthorized
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry-run"))
+	user, err := auth.ConvertNewUser(t.User())
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     gitops.Target(),
+		Kind:       permission.PermGitopsUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: map[string]bool{"dry-run": dryRun},
+		Allowed:    event.Allowed(permission.PermGitopsRead),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	st, err := gitops.Sync(r.Context(), gitops.SyncOptions{DryRun: dryRun, User: user, Event: evt})
+	if err != nil {
+		return err
+	}
+	switch {
+	case st.Drift == nil:
+		fmt.Fprintf(writer, "Revision %s is in sync, nothing to do.\n", st.Revision)
+	case dryRun:
+		data, _ := json.MarshalIndent(st.Drift, "", "  ")
+		fmt.Fprintf(writer, "Changes needed to sync revision %s:\n%s\n", st.Revision, data)
+	default:
+		fmt.Fprintf(writer, "Revision %s synced.\n", st.Revision)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/gitops"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func gitopsBundle(c *check.C, content string) *bytes.Buffer {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	err := tw.WriteHeader(&tar.Header{Name: "specs.yaml", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	c.Assert(err, check.IsNil)
+	_, err = tw.Write([]byte(content))
+	c.Assert(err, check.IsNil)
+	c.Assert(tw.Close(), check.IsNil)
+	c.Assert(gzw.Close(), check.IsNil)
+	return &buf
+}
+
+func (s *S) TestGitopsStatusNoSync(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.13/gitops", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestGitopsPlanUploadedBundle(c *check.C) {
+	body := gitopsBundle(c, "kind: app\nname: newapp\nplatform: python\n---\nkind: pool\nname: gitops-pool\n")
+	request, err := http.NewRequest("POST", "/1.13/gitops/plan", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/gzip")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	var plan gitops.Plan
+	err = json.Unmarshal(recorder.Body.Bytes(), &plan)
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Pools, check.DeepEquals, []gitops.ResourceChange{{Kind: manifest.ChangeCreate, Name: "gitops-pool"}})
+	c.Assert(plan.Apps, check.HasLen, 1)
+	c.Assert(plan.Apps[0].App, check.Equals, "newapp")
+	c.Assert(plan.Apps[0].Changes[0].Kind, check.Equals, manifest.ChangeCreate)
+}
+
+func (s *S) TestGitopsPlanNoSource(c *check.C) {
+	request, err := http.NewRequest("POST", "/1.13/gitops/plan", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestGitopsBundleUploadAndSyncDryRun(c *check.C) {
+	request, err := http.NewRequest("PUT", "/1.13/gitops/bundle", gitopsBundle(c, "kind: app\nname: newapp\nplatform: python\n"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/gzip")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	request, err = http.NewRequest("POST", "/1.13/gitops/sync?dry-run=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Body.String(), check.Matches, `(?s).*Changes needed to sync revision.*`)
+	st, err := gitops.GetStatus()
+	c.Assert(err, check.IsNil)
+	c.Assert(st.DryRun, check.Equals, true)
+	c.Assert(st.Drift.Apps, check.HasLen, 1)
+}
+
+func (s *S) TestGitopsSyncUnauthorized(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermGitopsRead,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request, err := http.NewRequest("POST", "/1.13/gitops/sync", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
@@ -0,0 +1,528 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const graphqlMaxLimit = 100
+
+type graphqlTokenKey struct{}
+
+var (
+	graphqlSchemaOnce sync.Once
+	graphqlSchema     graphql.Schema
+	graphqlSchemaErr  error
+)
+
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// title: graphql query
+// path: /graphql
+// method: POST
+// consume: application/json
+// produce: application/json
+// responses:
+//   200: Query result
+//   400: Invalid query
+//   401: Unauthorized
+//   404: GraphQL disabled
+func graphqlHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if enabled, _ := config.GetBool("graphql:enabled"); !enabled {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: "graphql endpoint is disabled"}
+	}
+	var req graphqlRequest
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+	} else {
+		err := ParseJSON(r, &req)
+		if err != nil {
+			return err
+		}
+	}
+	if req.Query == "" {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "query is required"}
+	}
+	graphqlSchemaOnce.Do(func() {
+		graphqlSchema, graphqlSchemaErr = newGraphqlSchema()
+	})
+	if graphqlSchemaErr != nil {
+		return graphqlSchemaErr
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         graphqlSchema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        context.WithValue(r.Context(), graphqlTokenKey{}, t),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	if result.Data == nil && result.HasErrors() {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	return json.NewEncoder(w).Encode(result)
+}
+
+func graphqlToken(p graphql.ResolveParams) auth.Token {
+	t, _ := p.Context.Value(graphqlTokenKey{}).(auth.Token)
+	return t
+}
+
+func graphqlLimit(p graphql.ResolveParams) int {
+	limit, _ := p.Args["limit"].(int)
+	if limit <= 0 || limit > graphqlMaxLimit {
+		return graphqlMaxLimit
+	}
+	return limit
+}
+
+func graphqlApps(p graphql.ResolveParams, filter *app.Filter) ([]app.App, error) {
+	t := graphqlToken(p)
+	contexts := permission.ContextsForPermission(t, permission.PermAppRead)
+	contexts = append(contexts, permission.ContextsForPermission(t, permission.PermAppReadInfo)...)
+	if len(contexts) == 0 {
+		return nil, nil
+	}
+	return app.List(p.Context, appFilterByContext(contexts, filter))
+}
+
+func graphqlApp(p graphql.ResolveParams, name string) (*app.App, error) {
+	a, err := app.GetByName(p.Context, name)
+	if err != nil {
+		return nil, err
+	}
+	if !permission.Check(graphqlToken(p), permission.PermAppRead, contextsForApp(a)...) {
+		return nil, permission.ErrUnauthorized
+	}
+	return a, nil
+}
+
+func graphqlPool(p graphql.ResolveParams, name string) (*pool.Pool, error) {
+	pools, err := readablePools(p.Context, graphqlToken(p))
+	if err != nil {
+		return nil, err
+	}
+	for i := range pools {
+		if pools[i].Name == name {
+			return &pools[i], nil
+		}
+	}
+	return nil, pool.ErrPoolNotFound
+}
+
+func graphqlEvents(p graphql.ResolveParams, filter *event.Filter) ([]*event.Event, error) {
+	perms, err := graphqlToken(p).Permissions()
+	if err != nil {
+		return nil, err
+	}
+	// a nil list of permissions in the filter would return every event.
+	if len(perms) == 0 {
+		return nil, nil
+	}
+	filter.Permissions = perms
+	filter.Limit = graphqlLimit(p)
+	return event.List(filter)
+}
+
+// graphqlNodes returns the nodes the token is allowed to read, in the pool
+// when it's not empty.
+func graphqlNodes(p graphql.ResolveParams, poolName string) ([]provision.Node, error) {
+	t := graphqlToken(p)
+	provs, err := provision.Registry()
+	if err != nil {
+		return nil, err
+	}
+	var nodes []provision.Node
+	for _, prov := range provs {
+		nodeProv, ok := prov.(provision.NodeProvisioner)
+		if !ok {
+			continue
+		}
+		provNodes, err := nodeProv.ListNodes(p.Context, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range provNodes {
+			if poolName != "" && n.Pool() != poolName {
+				continue
+			}
+			if permission.Check(t, permission.PermNodeRead, permission.Context(permTypes.CtxPool, n.Pool())) {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	return nodes, nil
+}
+
+func newGraphqlSchema() (graphql.Schema, error) {
+	var appType, poolType, nodeType *graphql.Object
+	limitArg := &graphql.ArgumentConfig{Type: graphql.Int, Description: "Maximum number of items, up to 100."}
+	unitType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Unit",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":           &graphql.Field{Type: graphql.String},
+				"name":         &graphql.Field{Type: graphql.String},
+				"processName":  &graphql.Field{Type: graphql.String},
+				"type":         &graphql.Field{Type: graphql.String},
+				"ip":           &graphql.Field{Type: graphql.String},
+				"status":       &graphql.Field{Type: graphql.String},
+				"statusReason": &graphql.Field{Type: graphql.String},
+				"version":      &graphql.Field{Type: graphql.Int},
+				"ready":        &graphql.Field{Type: graphql.Boolean},
+				"address": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						u := p.Source.(provision.Unit)
+						if u.Address == nil {
+							return nil, nil
+						}
+						return u.Address.String(), nil
+					},
+				},
+				"app": &graphql.Field{
+					Type: appType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlApp(p, p.Source.(provision.Unit).AppName)
+					},
+				},
+			}
+		}),
+	})
+	deployType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Deploy",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(app.DeployData).ID.Hex(), nil
+				},
+			},
+			"image":     &graphql.Field{Type: graphql.String},
+			"version":   &graphql.Field{Type: graphql.Int},
+			"commit":    &graphql.Field{Type: graphql.String},
+			"origin":    &graphql.Field{Type: graphql.String},
+			"user":      &graphql.Field{Type: graphql.String},
+			"message":   &graphql.Field{Type: graphql.String},
+			"error":     &graphql.Field{Type: graphql.String},
+			"timestamp": &graphql.Field{Type: graphql.DateTime},
+			"duration": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "Duration of the deploy in seconds.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(app.DeployData).Duration.Seconds(), nil
+				},
+			},
+		},
+	})
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Event",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*event.Event).UniqueID.Hex(), nil
+				},
+			},
+			"kind": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*event.Event).Kind.Name, nil
+				},
+			},
+			"targetType": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return string(p.Source.(*event.Event).Target.Type), nil
+				},
+			},
+			"targetValue": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*event.Event).Target.Value, nil
+				},
+			},
+			"owner": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*event.Event).Owner.Name, nil
+				},
+			},
+			"startTime": &graphql.Field{
+				Type: graphql.DateTime,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*event.Event).StartTime, nil
+				},
+			},
+			"endTime": &graphql.Field{
+				Type: graphql.DateTime,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					evt := p.Source.(*event.Event)
+					if evt.EndTime.IsZero() {
+						return nil, nil
+					}
+					return evt.EndTime, nil
+				},
+			},
+			"running": &graphql.Field{
+				Type: graphql.Boolean,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*event.Event).Running, nil
+				},
+			},
+			"error": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*event.Event).Error, nil
+				},
+			},
+		},
+	})
+	appType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "App",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name":        &graphql.Field{Type: graphql.String},
+				"description": &graphql.Field{Type: graphql.String},
+				"platform":    &graphql.Field{Type: graphql.String},
+				"teamOwner":   &graphql.Field{Type: graphql.String},
+				"owner":       &graphql.Field{Type: graphql.String},
+				"teams":       &graphql.Field{Type: graphql.NewList(graphql.String)},
+				"tags":        &graphql.Field{Type: graphql.NewList(graphql.String)},
+				"cname":       &graphql.Field{Type: graphql.NewList(graphql.String)},
+				"plan": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*app.App).Plan.Name, nil
+					},
+				},
+				"pool": &graphql.Field{
+					Type: poolType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return graphqlPool(p, p.Source.(*app.App).Pool)
+					},
+				},
+				"units": &graphql.Field{
+					Type: graphql.NewList(unitType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*app.App).Units()
+					},
+				},
+				"deploys": &graphql.Field{
+					Type: graphql.NewList(deployType),
+					Args: graphql.FieldConfigArgument{"limit": limitArg},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						a := p.Source.(*app.App)
+						if !permission.Check(graphqlToken(p), permission.PermAppReadDeploy, contextsForApp(a)...) {
+							return nil, permission.ErrUnauthorized
+						}
+						return app.ListDeploys(p.Context, &app.Filter{Name: a.Name}, 0, graphqlLimit(p))
+					},
+				},
+				"events": &graphql.Field{
+					Type: graphql.NewList(eventType),
+					Args: graphql.FieldConfigArgument{"limit": limitArg},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						a := p.Source.(*app.App)
+						return graphqlEvents(p, &event.Filter{
+							Target: event.Target{Type: event.TargetTypeApp, Value: a.Name},
+						})
+					},
+				},
+			}
+		}),
+	})
+	nodeType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Node",
+		Fields: graphql.Fields{
+			"address": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(provision.Node).Address(), nil
+				},
+			},
+			"pool": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(provision.Node).Pool(), nil
+				},
+			},
+			"status": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(provision.Node).Status(), nil
+				},
+			},
+			"iaasID": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(provision.Node).IaaSID(), nil
+				},
+			},
+			"units": &graphql.Field{
+				Type: graphql.NewList(unitType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(provision.Node).Units()
+				},
+			},
+		},
+	})
+	poolType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Pool",
+		Fields: graphql.Fields{
+			"name":        &graphql.Field{Type: graphql.String},
+			"provisioner": &graphql.Field{Type: graphql.String},
+			"default":     &graphql.Field{Type: graphql.Boolean},
+			"teams": &graphql.Field{
+				Type: graphql.NewList(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*pool.Pool).GetTeams()
+				},
+			},
+			"apps": &graphql.Field{
+				Type: graphql.NewList(appType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphqlAppPointers(graphqlApps(p, &app.Filter{Pool: p.Source.(*pool.Pool).Name}))
+				},
+			},
+			"nodes": &graphql.Field{
+				Type: graphql.NewList(nodeType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphqlNodes(p, p.Source.(*pool.Pool).Name)
+				},
+			},
+		},
+	})
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"apps": &graphql.Field{
+				Type: graphql.NewList(appType),
+				Args: graphql.FieldConfigArgument{
+					"name":      &graphql.ArgumentConfig{Type: graphql.String},
+					"pool":      &graphql.ArgumentConfig{Type: graphql.String},
+					"platform":  &graphql.ArgumentConfig{Type: graphql.String},
+					"teamOwner": &graphql.ArgumentConfig{Type: graphql.String},
+					"tag":       &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					filter := &app.Filter{}
+					filter.NameMatches, _ = p.Args["name"].(string)
+					filter.Pool, _ = p.Args["pool"].(string)
+					filter.Platform, _ = p.Args["platform"].(string)
+					filter.TeamOwner, _ = p.Args["teamOwner"].(string)
+					if tag, _ := p.Args["tag"].(string); tag != "" {
+						filter.Tags = []string{tag}
+					}
+					return graphqlAppPointers(graphqlApps(p, filter))
+				},
+			},
+			"app": &graphql.Field{
+				Type: appType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphqlApp(p, p.Args["name"].(string))
+				},
+			},
+			"pools": &graphql.Field{
+				Type: graphql.NewList(poolType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					pools, err := readablePools(p.Context, graphqlToken(p))
+					if err != nil {
+						return nil, err
+					}
+					result := make([]*pool.Pool, len(pools))
+					for i := range pools {
+						result[i] = &pools[i]
+					}
+					return result, nil
+				},
+			},
+			"pool": &graphql.Field{
+				Type: poolType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphqlPool(p, p.Args["name"].(string))
+				},
+			},
+			"events": &graphql.Field{
+				Type: graphql.NewList(eventType),
+				Args: graphql.FieldConfigArgument{
+					"targetType":  &graphql.ArgumentConfig{Type: graphql.String},
+					"targetValue": &graphql.ArgumentConfig{Type: graphql.String},
+					"kind":        &graphql.ArgumentConfig{Type: graphql.String},
+					"running":     &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"limit":       limitArg,
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					filter := &event.Filter{}
+					if targetType, _ := p.Args["targetType"].(string); targetType != "" {
+						tt, err := event.GetTargetType(targetType)
+						if err != nil {
+							return nil, err
+						}
+						filter.Target.Type = tt
+					}
+					filter.Target.Value, _ = p.Args["targetValue"].(string)
+					if kind, _ := p.Args["kind"].(string); kind != "" {
+						filter.KindNames = []string{kind}
+					}
+					if running, ok := p.Args["running"].(bool); ok {
+						filter.Running = &running
+					}
+					return graphqlEvents(p, filter)
+				},
+			},
+			"nodes": &graphql.Field{
+				Type: graphql.NewList(nodeType),
+				Args: graphql.FieldConfigArgument{
+					"pool": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					poolName, _ := p.Args["pool"].(string)
+					return graphqlNodes(p, poolName)
+				},
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// graphqlAppPointers converts apps to pointers, the type expected by the
+// resolvers of the App fields.
+func graphqlAppPointers(apps []app.App, err error) ([]*app.App, error) {
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*app.App, len(apps))
+	for i := range apps {
+		result[i] = &apps[i]
+	}
+	return result, nil
+}
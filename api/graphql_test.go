@@ -0,0 +1,86 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) doGraphqlQuery(c *check.C, token, query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	body, err := json.Marshal(map[string]string{"query": query})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.13/graphql", strings.NewReader(string(body)))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	var result map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &result)
+	return recorder, result
+}
+
+func (s *S) TestGraphqlDisabled(c *check.C) {
+	recorder, _ := s.doGraphqlQuery(c, s.token.GetValue(), "{ apps { name } }")
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestGraphqlApps(c *check.C) {
+	config.Set("graphql:enabled", true)
+	defer config.Unset("graphql")
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	recorder, result := s.doGraphqlQuery(c, s.token.GetValue(), `{ apps { name platform pool { name } units { id } } }`)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(result["errors"], check.IsNil)
+	c.Assert(result["data"], check.DeepEquals, map[string]interface{}{
+		"apps": []interface{}{
+			map[string]interface{}{
+				"name":     "myapp",
+				"platform": "zend",
+				"pool":     map[string]interface{}{"name": "test1"},
+				"units":    []interface{}{},
+			},
+		},
+	})
+}
+
+func (s *S) TestGraphqlAppUnauthorized(c *check.C) {
+	config.Set("graphql:enabled", true)
+	defer config.Unset("graphql")
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxApp, "otherapp"),
+	})
+	recorder, result := s.doGraphqlQuery(c, token.GetValue(), `{ app(name: "myapp") { name } apps { name } }`)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(result["data"], check.DeepEquals, map[string]interface{}{
+		"app":  nil,
+		"apps": []interface{}{},
+	})
+	c.Assert(result["errors"], check.HasLen, 1)
+}
+
+func (s *S) TestGraphqlInvalidQuery(c *check.C) {
+	config.Set("graphql:enabled", true)
+	defer config.Unset("graphql")
+	recorder, result := s.doGraphqlQuery(c, s.token.GetValue(), `{ apps { invalid } }`)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(result["errors"], check.HasLen, 1)
+}
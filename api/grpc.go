@@ -0,0 +1,468 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/ratelimit"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/api/tsurupb"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var grpcWatchInterval = 2 * time.Second
+
+type grpcTokenKey struct{}
+
+// grpcServer implements the gRPC API, delegating to the same checks of the
+// HTTP API.
+type grpcServer struct {
+	tsurupb.UnimplementedTsuruServer
+}
+
+type grpcShutdown struct {
+	srv *grpc.Server
+}
+
+func (s *grpcShutdown) Shutdown(ctx stdContext.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.srv.Stop()
+	}
+	return nil
+}
+
+// startGRPCServer starts the gRPC API in grpc:listen, it's disabled when the
+// address is empty. Calls are rate limited by the same limiter of the HTTP
+// API, when it's enabled.
+func startGRPCServer(limiter *ratelimit.Limiter) error {
+	listen, _ := config.GetString("grpc:listen")
+	if listen == "" {
+		return nil
+	}
+	opts := grpcInterceptors(limiter)
+	if useTLS, _ := config.GetBool("use-tls"); useTLS {
+		certFile, _ := config.GetString("tls:cert-file")
+		keyFile, _ := config.GetString("tls:key-file")
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	srv := newGRPCServer(opts...)
+	shutdown.Register(&grpcShutdown{srv: srv})
+	fmt.Printf("gRPC API listening on %s.\n", listen)
+	go srv.Serve(listener)
+	return nil
+}
+
+// grpcInterceptors authenticates the calls and then applies the rate limiter,
+// when it's not nil.
+func grpcInterceptors(limiter *ratelimit.Limiter) []grpc.ServerOption {
+	unary := []grpc.UnaryServerInterceptor{grpcUnaryAuth}
+	stream := []grpc.StreamServerInterceptor{grpcStreamAuth}
+	if limiter != nil {
+		unary = append(unary, grpcUnaryRateLimit(limiter))
+		stream = append(stream, grpcStreamRateLimit(limiter))
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
+
+func newGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	tsurupb.RegisterTsuruServer(srv, &grpcServer{})
+	return srv
+}
+
+func grpcAuthenticate(ctx stdContext.Context) (stdContext.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+	t, err := authenticate(ctx, values[0])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return stdContext.WithValue(ctx, grpcTokenKey{}, t), nil
+}
+
+func grpcUnaryAuth(ctx stdContext.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := grpcAuthenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+type grpcAuthStream struct {
+	grpc.ServerStream
+	ctx stdContext.Context
+}
+
+func (s *grpcAuthStream) Context() stdContext.Context {
+	return s.ctx
+}
+
+func grpcStreamAuth(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := grpcAuthenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &grpcAuthStream{ServerStream: stream, ctx: ctx})
+}
+
+// grpcRateLimit consumes a token of the bucket of the caller, it must run
+// after the authentication interceptor.
+func grpcRateLimit(ctx stdContext.Context, limiter *ratelimit.Limiter) (metadata.MD, error) {
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+	allowed, remaining, reset, class := limiter.Allow(remoteAddr, grpcToken(ctx))
+	md := metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(class.Burst),
+		"ratelimit-remaining", strconv.Itoa(remaining),
+		"ratelimit-reset", strconv.Itoa(int(math.Ceil(reset.Seconds()))),
+	)
+	if !allowed {
+		return md, status.Error(codes.ResourceExhausted, "rate limit exceeded, try again later")
+	}
+	return md, nil
+}
+
+func grpcUnaryRateLimit(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx stdContext.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, err := grpcRateLimit(ctx, limiter)
+		grpc.SetHeader(ctx, md)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func grpcStreamRateLimit(limiter *ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, err := grpcRateLimit(stream.Context(), limiter)
+		stream.SetHeader(md)
+		if err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+func grpcToken(ctx stdContext.Context) auth.Token {
+	t, _ := ctx.Value(grpcTokenKey{}).(auth.Token)
+	return t
+}
+
+// grpcError converts the errors of the HTTP API to gRPC status errors.
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch e := err.(type) {
+	case *tsuruErrors.HTTP:
+		code := codes.Unknown
+		switch e.Code {
+		case http.StatusBadRequest:
+			code = codes.InvalidArgument
+		case http.StatusUnauthorized:
+			code = codes.Unauthenticated
+		case http.StatusForbidden:
+			code = codes.PermissionDenied
+		case http.StatusNotFound:
+			code = codes.NotFound
+		case http.StatusConflict:
+			code = codes.AlreadyExists
+		case http.StatusPreconditionFailed:
+			code = codes.FailedPrecondition
+		case http.StatusTooManyRequests:
+			code = codes.ResourceExhausted
+		}
+		return status.Error(code, e.Message)
+	case *tsuruErrors.ValidationError:
+		return status.Error(codes.InvalidArgument, e.Message)
+	}
+	switch err {
+	case permission.ErrUnauthorized:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case appTypes.ErrAppNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func grpcApp(ctx stdContext.Context, name string, perm *permission.PermissionScheme) (*app.App, error) {
+	t := grpcToken(ctx)
+	if t.IsAppToken() && t.GetAppName() != name {
+		return nil, status.Errorf(codes.PermissionDenied, "app token mismatch, token for %q, request for %q", t.GetAppName(), name)
+	}
+	a, err := app.GetByName(ctx, name)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if !permission.Check(t, perm, contextsForApp(a)...) {
+		return nil, grpcError(permission.ErrUnauthorized)
+	}
+	return a, nil
+}
+
+func (s *grpcServer) AppInfo(ctx stdContext.Context, req *tsurupb.AppInfoRequest) (*tsurupb.App, error) {
+	a, err := grpcApp(ctx, req.App, permission.PermAppReadInfo)
+	if err != nil {
+		return nil, err
+	}
+	return &tsurupb.App{
+		Name:        a.Name,
+		Platform:    a.Platform,
+		TeamOwner:   a.TeamOwner,
+		Owner:       a.Owner,
+		Pool:        a.Pool,
+		Plan:        a.Plan.Name,
+		Description: a.Description,
+		Teams:       a.Teams,
+		Tags:        a.Tags,
+		Cnames:      a.CName,
+		Deploys:     uint64(a.Deploys),
+		Locked:      a.Lock.Locked,
+	}, nil
+}
+
+func (s *grpcServer) UnitList(ctx stdContext.Context, req *tsurupb.UnitListRequest) (*tsurupb.UnitListResponse, error) {
+	a, err := grpcApp(ctx, req.App, permission.PermAppRead)
+	if err != nil {
+		return nil, err
+	}
+	units, err := a.Units()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &tsurupb.UnitListResponse{}
+	for _, u := range units {
+		resp.Units = append(resp.Units, grpcUnit(u))
+	}
+	return resp, nil
+}
+
+func grpcUnit(u provision.Unit) *tsurupb.Unit {
+	unit := &tsurupb.Unit{
+		Id:           u.ID,
+		Name:         u.Name,
+		ProcessName:  u.ProcessName,
+		Type:         u.Type,
+		Ip:           u.IP,
+		Status:       u.Status.String(),
+		StatusReason: u.StatusReason,
+		Version:      int32(u.Version),
+	}
+	if u.Address != nil {
+		unit.Address = u.Address.String()
+	}
+	if u.Ready != nil {
+		unit.Ready = *u.Ready
+	}
+	return unit
+}
+
+// grpcDeployWriter streams the output of the deploy handler.
+type grpcDeployWriter struct {
+	stream  tsurupb.Tsuru_DeployServer
+	header  http.Header
+	eventID string
+}
+
+func (w *grpcDeployWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *grpcDeployWriter) WriteHeader(int) {}
+
+func (w *grpcDeployWriter) Write(data []byte) (int, error) {
+	msg := &tsurupb.DeployOutput{Data: data}
+	if w.eventID == "" {
+		w.eventID = w.header.Get(eventIDHeader)
+		msg.EventId = w.eventID
+	}
+	if err := w.stream.Send(msg); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Deploy runs the deploy handler of the HTTP API, keeping its checks, like
+// permissions, pool freezes, admission and deploy gates.
+func (s *grpcServer) Deploy(req *tsurupb.DeployRequest, stream tsurupb.Tsuru_DeployServer) error {
+	ctx := stream.Context()
+	form := url.Values{}
+	for key, value := range map[string]string{
+		"image":             req.Image,
+		"archive-url":       req.ArchiveUrl,
+		"message":           req.Message,
+		"origin":            req.Origin,
+		"commit":            req.Commit,
+		"user":              req.User,
+		"new-version":       strconv.FormatBool(req.NewVersion),
+		"override-versions": strconv.FormatBool(req.OverrideVersions),
+	} {
+		if value != "" {
+			form.Set(key, value)
+		}
+	}
+	path := fmt.Sprintf("/apps/%s/deploy?:appname=%s", url.PathEscape(req.App), url.QueryEscape(req.App))
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return grpcError(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+	w := &grpcDeployWriter{stream: stream, header: http.Header{}}
+	return grpcError(deploy(w, r, grpcToken(ctx)))
+}
+
+func grpcEvent(evt *event.Event) *tsurupb.Event {
+	e := &tsurupb.Event{
+		Id:          evt.UniqueID.Hex(),
+		Kind:        evt.Kind.Name,
+		TargetType:  string(evt.Target.Type),
+		TargetValue: evt.Target.Value,
+		Owner:       evt.Owner.Name,
+		StartTime:   timestamppb.New(evt.StartTime),
+		Running:     evt.Running,
+		Error:       evt.Error,
+	}
+	if !evt.EndTime.IsZero() {
+		e.EndTime = timestamppb.New(evt.EndTime)
+	}
+	return e
+}
+
+// WatchEvents polls the events readable by the token, sending them when
+// they start and again when they finish.
+func (s *grpcServer) WatchEvents(req *tsurupb.WatchEventsRequest, stream tsurupb.Tsuru_WatchEventsServer) error {
+	ctx := stream.Context()
+	t := grpcToken(ctx)
+	filter := event.Filter{
+		Target:    event.Target{Value: req.TargetValue},
+		KindNames: req.Kinds,
+		Sort:      "starttime",
+	}
+	if req.TargetType != "" {
+		targetType, err := event.GetTargetType(req.TargetType)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		filter.Target.Type = targetType
+	}
+	since := time.Now().UTC()
+	if req.Since != nil {
+		since = req.Since.AsTime()
+	}
+	seen := map[string]time.Time{}
+	running := map[string]struct{}{}
+	ticker := time.NewTicker(grpcWatchInterval)
+	defer ticker.Stop()
+	for {
+		// permissions are loaded on every poll, revoked permissions stop
+		// the watch.
+		perms, err := t.Permissions()
+		if err != nil {
+			return grpcError(err)
+		}
+		if len(perms) == 0 {
+			return grpcError(permission.ErrUnauthorized)
+		}
+		f := filter
+		f.Permissions = perms
+		f.Since = since
+		events, err := event.List(&f)
+		if err != nil {
+			return grpcError(err)
+		}
+		for _, evt := range events {
+			id := evt.UniqueID.Hex()
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = evt.StartTime
+			if evt.StartTime.After(since) {
+				since = evt.StartTime
+			}
+			if evt.Running {
+				running[id] = struct{}{}
+			}
+			if err = stream.Send(grpcEvent(evt)); err != nil {
+				return err
+			}
+		}
+		for id := range running {
+			evt, err := event.GetByHexID(id)
+			if err != nil {
+				return grpcError(err)
+			}
+			if evt.Running {
+				continue
+			}
+			delete(running, id)
+			if err = stream.Send(grpcEvent(evt)); err != nil {
+				return err
+			}
+		}
+		for id, startTime := range seen {
+			if startTime.Before(since) {
+				delete(seen, id)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/tsuru/tsuru/api/ratelimit"
+	"github.com/tsuru/tsuru/api/tsurupb"
+	"github.com/tsuru/tsuru/app"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) grpcClient(c *check.C) (tsurupb.TsuruClient, func()) {
+	return s.grpcClientWithLimiter(c, nil)
+}
+
+func (s *S) grpcClientWithLimiter(c *check.C, limiter *ratelimit.Limiter) (tsurupb.TsuruClient, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+	srv := newGRPCServer(grpcInterceptors(limiter)...)
+	go srv.Serve(listener)
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	c.Assert(err, check.IsNil)
+	return tsurupb.NewTsuruClient(conn), func() {
+		conn.Close()
+		srv.Stop()
+	}
+}
+
+func grpcContext(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "bearer "+token)
+}
+
+func (s *S) TestGRPCAppInfo(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Tags: []string{"tag1"}}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	client, stop := s.grpcClient(c)
+	defer stop()
+	info, err := client.AppInfo(grpcContext(s.token.GetValue()), &tsurupb.AppInfoRequest{App: "myapp"})
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Name, check.Equals, "myapp")
+	c.Assert(info.Platform, check.Equals, "zend")
+	c.Assert(info.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(info.Pool, check.Equals, "test1")
+	c.Assert(info.Tags, check.DeepEquals, []string{"tag1"})
+	units, err := client.UnitList(grpcContext(s.token.GetValue()), &tsurupb.UnitListRequest{App: "myapp"})
+	c.Assert(err, check.IsNil)
+	c.Assert(units.Units, check.HasLen, 0)
+	_, err = client.AppInfo(grpcContext(s.token.GetValue()), &tsurupb.AppInfoRequest{App: "unknown"})
+	c.Assert(status.Code(err), check.Equals, codes.NotFound)
+}
+
+func (s *S) TestGRPCUnauthenticated(c *check.C) {
+	client, stop := s.grpcClient(c)
+	defer stop()
+	_, err := client.AppInfo(context.Background(), &tsurupb.AppInfoRequest{App: "myapp"})
+	c.Assert(status.Code(err), check.Equals, codes.Unauthenticated)
+	_, err = client.AppInfo(grpcContext("invalid"), &tsurupb.AppInfoRequest{App: "myapp"})
+	c.Assert(status.Code(err), check.Equals, codes.Unauthenticated)
+}
+
+func (s *S) TestGRPCRateLimit(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	limiter := ratelimit.NewLimiter(&ratelimit.Class{Name: "default", RequestsPerSecond: 0.001, Burst: 1})
+	client, stop := s.grpcClientWithLimiter(c, limiter)
+	defer stop()
+	var header metadata.MD
+	_, err = client.AppInfo(grpcContext(s.token.GetValue()), &tsurupb.AppInfoRequest{App: "myapp"}, grpc.Header(&header))
+	c.Assert(err, check.IsNil)
+	c.Assert(header.Get("ratelimit-limit"), check.DeepEquals, []string{"1"})
+	c.Assert(header.Get("ratelimit-remaining"), check.DeepEquals, []string{"0"})
+	_, err = client.AppInfo(grpcContext(s.token.GetValue()), &tsurupb.AppInfoRequest{App: "myapp"})
+	c.Assert(status.Code(err), check.Equals, codes.ResourceExhausted)
+}
+
+func (s *S) TestGRPCAppInfoForbidden(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppReadInfo,
+		Context: permission.Context(permTypes.CtxApp, "otherapp"),
+	})
+	client, stop := s.grpcClient(c)
+	defer stop()
+	_, err = client.AppInfo(grpcContext(token.GetValue()), &tsurupb.AppInfoRequest{App: "myapp"})
+	c.Assert(status.Code(err), check.Equals, codes.PermissionDenied)
+}
+
+func (s *S) TestGRPCError(c *check.C) {
+	err := grpcError(&tsuruErrors.HTTP{Code: http.StatusConflict, Message: "conflict"})
+	c.Assert(status.Code(err), check.Equals, codes.AlreadyExists)
+	c.Assert(status.Convert(err).Message(), check.Equals, "conflict")
+	err = grpcError(permission.ErrUnauthorized)
+	c.Assert(status.Code(err), check.Equals, codes.PermissionDenied)
+	err = grpcError(&tsuruErrors.ValidationError{Message: "invalid"})
+	c.Assert(status.Code(err), check.Equals, codes.InvalidArgument)
+	c.Assert(grpcError(nil), check.IsNil)
+}
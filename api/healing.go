@@ -12,6 +12,7 @@ import (
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision/docker/healer"
+	"github.com/tsuru/tsuru/provision/docker/types"
 )
 
 // title: docker healing history
@@ -45,3 +46,39 @@ func healingHistoryHandler(w http.ResponseWriter, r *http.Request, t auth.Token)
 	w.Header().Set("Content-Type", "application/json")
 	return json.NewEncoder(w).Encode(history)
 }
+
+// title: unit healing history
+// path: /apps/{app}/units/{unit}/healing-history
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func unitHealingHistory(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadEvents, contextsForApp(&a)...)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	history, err := healer.UnitHealingHistory(r.URL.Query().Get(":unit"))
+	if err != nil {
+		return err
+	}
+	var appHistory []types.HealingEvent
+	for _, evt := range history {
+		if evt.FailingContainer.AppName == a.Name {
+			appHistory = append(appHistory, evt)
+		}
+	}
+	if len(appHistory) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(appHistory)
+}
@@ -5,6 +5,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision/docker/container"
@@ -180,3 +182,45 @@ func (s *S) TestHealingHistoryHandlerFilterNode(c *check.C) {
 	c.Assert(healings[1].ID, check.Equals, evt1.UniqueID.Hex())
 	c.Assert(healings[1].FailingNode.Address, check.Equals, "addr1")
 }
+
+func (s *S) TestUnitHealingHistory(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	evt, err := event.NewInternal(&event.Opts{
+		Target: event.Target{Type: event.TargetTypeContainer, Value: "1234"},
+		ExtraTargets: []event.ExtraTarget{
+			{Target: event.Target{Type: event.TargetTypeContainer, Value: "9876"}},
+		},
+		InternalKind: "healer",
+		CustomData:   container.Container{Container: types.Container{ID: "1234", AppName: "myapp"}},
+		Allowed:      event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	evt.DoneCustomData(nil, container.Container{Container: types.Container{ID: "9876", AppName: "myapp"}})
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/apps/myapp/units/9876/healing-history", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var healings []types.HealingEvent
+	err = json.Unmarshal(recorder.Body.Bytes(), &healings)
+	c.Assert(err, check.IsNil)
+	c.Assert(healings, check.HasLen, 1)
+	c.Assert(healings[0].FailingContainer.ID, check.Equals, "1234")
+	c.Assert(healings[0].CreatedContainer.ID, check.Equals, "9876")
+}
+
+func (s *S) TestUnitHealingHistoryNoContent(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/apps/myapp/units/9876/healing-history", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
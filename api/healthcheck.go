@@ -9,17 +9,27 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/tsuru/tsuru/hc"
 )
 
+// shuttingDown is set when tsuru starts shutting down, failing the
+// healthcheck so the instance is removed from load balancers.
+var shuttingDown int32
+
 // title: healthcheck
 // path: /healthcheck
 // method: GET
 // responses:
 //   200: OK
 //   500: Internal server error
+//   503: Shutting down
 func healthcheck(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&shuttingDown) == 1 {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
 	var checks []string
 	values := r.URL.Query()
 	if values != nil {
@@ -8,6 +8,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 
 	"github.com/tsuru/tsuru/hc"
 	check "gopkg.in/check.v1"
@@ -26,6 +27,17 @@ func (s *HealthCheckSuite) TestHealthCheck(c *check.C) {
 	c.Assert(recorder.Body.String(), check.Equals, "WORKING")
 }
 
+func (s *HealthCheckSuite) TestHealthCheckShuttingDown(c *check.C) {
+	atomic.StoreInt32(&shuttingDown, 1)
+	defer atomic.StoreInt32(&shuttingDown, 0)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/healthcheck", nil)
+	c.Assert(err, check.IsNil)
+	healthcheck(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusServiceUnavailable)
+	c.Assert(recorder.Body.String(), check.Equals, "shutting down\n")
+}
+
 func (s *HealthCheckSuite) TestHealthCheckWithChecks(c *check.C) {
 	hc.AddChecker("mychecker", func(ctx context.Context) error {
 		return nil
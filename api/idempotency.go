@@ -0,0 +1,166 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db"
+	dbStorage "github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/errors"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+
+	idempotencyKeyTTL = 24 * time.Hour
+	// idempotencyPendingTimeout is how long a request holding a key may run
+	// before a retry with the same key is allowed to take it over.
+	idempotencyPendingTimeout = 10 * time.Minute
+)
+
+var idempotencyNow = time.Now
+
+type idempotencyRecord struct {
+	ID          string    `bson:"_id"`
+	RequestHash string    `bson:"requesthash"`
+	Pending     bool      `bson:"pending"`
+	Status      int       `bson:"status"`
+	ContentType string    `bson:"contenttype"`
+	ETag        string    `bson:"etag"`
+	Body        []byte    `bson:"body"`
+	CreatedAt   time.Time `bson:"createdat"`
+	ExpireAt    time.Time `bson:"expireat"`
+}
+
+func idempotencyCollection(conn *db.Storage) *dbStorage.Collection {
+	coll := conn.Collection("idempotency_keys")
+	coll.EnsureIndex(mgo.Index{Key: []string{"expireat"}, ExpireAfter: time.Second})
+	return coll
+}
+
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *idempotencyRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *idempotencyRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func idempotencyRequestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	for _, part := range []string{r.Method, r.URL.Path, r.Header.Get("If-Match"), r.Header.Get("If-None-Match")} {
+		fmt.Fprintf(h, "%s\n", part)
+	}
+	h.Write(body)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// idempotentHandler makes retries of fn safe when the client sends the
+// Idempotency-Key header: the first successful response is stored for 24
+// hours and replayed to later requests with the same key and payload,
+// without running fn again.
+func idempotentHandler(fn AuthorizationRequiredHandler) AuthorizationRequiredHandler {
+	return func(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			return fn(w, r, t)
+		}
+		body, err := context.GetBody(r)
+		if err != nil {
+			return err
+		}
+		conn, err := db.Conn()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		coll := idempotencyCollection(conn)
+		now := idempotencyNow().UTC()
+		record := idempotencyRecord{
+			ID:          fmt.Sprintf("%x", sha256.Sum256([]byte(t.GetUserName()+"\n"+key))),
+			RequestHash: idempotencyRequestHash(r, body),
+			Pending:     true,
+			CreatedAt:   now,
+			ExpireAt:    now.Add(idempotencyKeyTTL),
+		}
+		err = coll.Insert(record)
+		if mgo.IsDup(err) {
+			var existing idempotencyRecord
+			err = coll.FindId(record.ID).One(&existing)
+			if err != nil {
+				return err
+			}
+			if existing.RequestHash != record.RequestHash {
+				return &errors.HTTP{Code: http.StatusUnprocessableEntity, Message: "idempotency key already used with a different request"}
+			}
+			if !existing.Pending {
+				replayIdempotentResponse(w, &existing)
+				return nil
+			}
+			if now.Sub(existing.CreatedAt) < idempotencyPendingTimeout {
+				return &errors.HTTP{Code: http.StatusConflict, Message: "a request with the same idempotency key is in progress"}
+			}
+			err = coll.Update(bson.M{"_id": record.ID, "createdat": existing.CreatedAt}, record)
+			if err == mgo.ErrNotFound {
+				return &errors.HTTP{Code: http.StatusConflict, Message: "a request with the same idempotency key is in progress"}
+			}
+		}
+		if err != nil {
+			return err
+		}
+		recorder := &idempotencyRecorder{ResponseWriter: w}
+		defer func() {
+			if err != nil || recorder.status >= http.StatusInternalServerError {
+				coll.RemoveId(record.ID)
+				return
+			}
+			record.Pending = false
+			record.Status = recorder.status
+			if record.Status == 0 {
+				record.Status = http.StatusOK
+			}
+			record.ContentType = w.Header().Get("Content-Type")
+			record.ETag = w.Header().Get("ETag")
+			record.Body = recorder.body.Bytes()
+			coll.UpdateId(record.ID, record)
+		}()
+		return fn(recorder, r, t)
+	}
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, record *idempotencyRecord) {
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	if record.ETag != "" {
+		w.Header().Set("ETag", record.ETag)
+	}
+	w.Header().Set(idempotencyReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	w.Write(record.Body)
+}
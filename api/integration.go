@@ -0,0 +1,200 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app/commitstatus"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+func integrationTarget(name string) event.Target {
+	return event.Target{Type: event.TargetTypeIntegration, Value: name}
+}
+
+func getIntegration(w http.ResponseWriter, name string) (*commitstatus.Integration, error) {
+	i, err := commitstatus.GetIntegration(name)
+	if err == commitstatus.ErrIntegrationNotFound {
+		w.WriteHeader(http.StatusNotFound)
+	}
+	return i, err
+}
+
+// title: integration list
+// path: /integrations
+// method: GET
+// produce: application/json
+// responses:
+//   200: List integrations
+//   204: No content
+func integrationList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctxs := permission.ContextsForPermission(t, permission.PermIntegrationRead, permTypes.CtxTeam)
+	var teams []string
+	for _, c := range ctxs {
+		if c.CtxType == permTypes.CtxGlobal {
+			teams = nil
+			break
+		}
+		teams = append(teams, c.Value)
+	}
+	integrations, err := commitstatus.ListIntegrations(teams)
+	if err != nil {
+		return err
+	}
+	if len(integrations) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	for i := range integrations {
+		integrations[i].Token = ""
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(integrations)
+}
+
+// title: integration info
+// path: /integrations/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: Get integration
+//   401: Unauthorized
+//   404: Not found
+func integrationInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	i, err := getIntegration(w, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermIntegrationRead, permission.Context(permTypes.CtxTeam, i.TeamOwner)) {
+		return permission.ErrUnauthorized
+	}
+	i.Token = ""
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(i)
+}
+
+// title: integration create
+// path: /integrations
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Integration created
+//   400: Invalid integration
+//   401: Unauthorized
+//   409: Integration already exists
+func integrationCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var i commitstatus.Integration
+	err = ParseInput(r, &i)
+	if err != nil {
+		return err
+	}
+	if i.TeamOwner == "" {
+		i.TeamOwner, err = autoTeamOwner(r.Context(), t, permission.PermIntegrationCreate)
+		if err != nil {
+			return err
+		}
+	}
+	permCtx := permission.Context(permTypes.CtxTeam, i.TeamOwner)
+	if !permission.Check(t, permission.PermIntegrationCreate, permCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     integrationTarget(i.Name),
+		Kind:       permission.PermIntegrationCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r, "token")),
+		Allowed:    event.Allowed(permission.PermIntegrationReadEvents, permCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = commitstatus.CreateIntegration(i)
+	if err == commitstatus.ErrIntegrationAlreadyExists {
+		w.WriteHeader(http.StatusConflict)
+	}
+	return err
+}
+
+// title: integration update
+// path: /integrations/{name}
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Integration updated
+//   400: Invalid integration
+//   401: Unauthorized
+//   404: Not found
+func integrationUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	current, err := getIntegration(w, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	var i commitstatus.Integration
+	err = ParseInput(r, &i)
+	if err != nil {
+		return err
+	}
+	i.Name = current.Name
+	if i.TeamOwner == "" {
+		i.TeamOwner = current.TeamOwner
+	}
+	currentCtx := permission.Context(permTypes.CtxTeam, current.TeamOwner)
+	newCtx := permission.Context(permTypes.CtxTeam, i.TeamOwner)
+	if !permission.Check(t, permission.PermIntegrationUpdate, currentCtx) ||
+		!permission.Check(t, permission.PermIntegrationUpdate, newCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     integrationTarget(i.Name),
+		Kind:       permission.PermIntegrationUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r, "token")),
+		Allowed:    event.Allowed(permission.PermIntegrationReadEvents, currentCtx, newCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return commitstatus.UpdateIntegration(i)
+}
+
+// title: integration delete
+// path: /integrations/{name}
+// method: DELETE
+// responses:
+//   200: Integration removed
+//   401: Unauthorized
+//   404: Not found
+func integrationDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	i, err := getIntegration(w, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	permCtx := permission.Context(permTypes.CtxTeam, i.TeamOwner)
+	if !permission.Check(t, permission.PermIntegrationDelete, permCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     integrationTarget(i.Name),
+		Kind:       permission.PermIntegrationDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermIntegrationReadEvents, permCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return commitstatus.RemoveIntegration(i.Name)
+}
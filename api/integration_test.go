@@ -0,0 +1,189 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/ajg/form"
+	"github.com/tsuru/tsuru/app/commitstatus"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) integrationRequest(c *check.C, method, path string, i commitstatus.Integration, token string) *httptest.ResponseRecorder {
+	bodyData, err := form.EncodeToString(i)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(method, path, strings.NewReader(bodyData))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *S) TestIntegrationList(c *check.C) {
+	err := commitstatus.CreateIntegration(commitstatus.Integration{
+		Name: "gh1", TeamOwner: s.team.Name, Type: "github", Token: "secret",
+		Repositories: []commitstatus.RepositoryMapping{{App: "myapp", Repository: "org/myapp"}},
+	})
+	c.Assert(err, check.IsNil)
+	err = commitstatus.CreateIntegration(commitstatus.Integration{
+		Name: "gl1", TeamOwner: "t2", Type: "gitlab", Token: "secret",
+		Repositories: []commitstatus.RepositoryMapping{{App: "other", Repository: "group/other"}},
+	})
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermIntegrationRead,
+		Context: permission.Context(permTypes.CtxTeam, "t2"),
+	})
+	request, err := http.NewRequest("GET", "/1.13/integrations", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result []commitstatus.Integration
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 1)
+	c.Assert(result[0].Name, check.Equals, "gl1")
+	c.Assert(result[0].Token, check.Equals, "")
+}
+
+func (s *S) TestIntegrationListEmpty(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.13/integrations", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestIntegrationCreate(c *check.C) {
+	i := commitstatus.Integration{
+		Name:         "gh",
+		Type:         "github",
+		Token:        "secret",
+		Repositories: []commitstatus.RepositoryMapping{{App: "myapp", Repository: "org/myapp"}},
+	}
+	recorder := s.integrationRequest(c, "POST", "/1.13/integrations", i, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbIntegration, err := commitstatus.GetIntegration("gh")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbIntegration.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(dbIntegration.Token, check.Equals, "secret")
+	c.Assert(dbIntegration.Repositories, check.DeepEquals, i.Repositories)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeIntegration, Value: "gh"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "integration.create",
+		StartCustomData: []map[string]interface{}{
+			{"name": "name", "value": "gh"},
+			{"name": "type", "value": "github"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestIntegrationCreateInvalid(c *check.C) {
+	i := commitstatus.Integration{Name: "bb", Type: "bitbucket", Token: "secret"}
+	recorder := s.integrationRequest(c, "POST", "/1.13/integrations", i, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, `invalid integration type "bitbucket".*\n`)
+}
+
+func (s *S) TestIntegrationCreateConflict(c *check.C) {
+	i := commitstatus.Integration{
+		Name: "gh", TeamOwner: s.team.Name, Type: "github", Token: "secret",
+		Repositories: []commitstatus.RepositoryMapping{{App: "myapp", Repository: "org/myapp"}},
+	}
+	err := commitstatus.CreateIntegration(i)
+	c.Assert(err, check.IsNil)
+	recorder := s.integrationRequest(c, "POST", "/1.13/integrations", i, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestIntegrationInfo(c *check.C) {
+	err := commitstatus.CreateIntegration(commitstatus.Integration{
+		Name: "gh", TeamOwner: s.team.Name, Type: "github", Token: "secret",
+		Repositories: []commitstatus.RepositoryMapping{{App: "myapp", Repository: "org/myapp"}},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.13/integrations/gh", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result commitstatus.Integration
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Name, check.Equals, "gh")
+	c.Assert(result.Token, check.Equals, "")
+}
+
+func (s *S) TestIntegrationInfoNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.13/integrations/unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestIntegrationUpdateKeepsToken(c *check.C) {
+	err := commitstatus.CreateIntegration(commitstatus.Integration{
+		Name: "gh", TeamOwner: s.team.Name, Type: "github", Token: "secret",
+		Repositories: []commitstatus.RepositoryMapping{{App: "myapp", Repository: "org/myapp"}},
+	})
+	c.Assert(err, check.IsNil)
+	i := commitstatus.Integration{
+		Type:         "github",
+		URL:          "https://github.example.com/api/v3",
+		Repositories: []commitstatus.RepositoryMapping{{App: "myapp", Repository: "org/renamed"}},
+	}
+	recorder := s.integrationRequest(c, "PUT", "/1.13/integrations/gh", i, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbIntegration, err := commitstatus.GetIntegration("gh")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbIntegration.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(dbIntegration.Token, check.Equals, "secret")
+	c.Assert(dbIntegration.URL, check.Equals, "https://github.example.com/api/v3")
+	c.Assert(dbIntegration.Repositories, check.DeepEquals, i.Repositories)
+}
+
+func (s *S) TestIntegrationUpdateNotFound(c *check.C) {
+	i := commitstatus.Integration{Type: "github", Token: "secret"}
+	recorder := s.integrationRequest(c, "PUT", "/1.13/integrations/unknown", i, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestIntegrationDelete(c *check.C) {
+	err := commitstatus.CreateIntegration(commitstatus.Integration{
+		Name: "gh", TeamOwner: s.team.Name, Type: "github", Token: "secret",
+		Repositories: []commitstatus.RepositoryMapping{{App: "myapp", Repository: "org/myapp"}},
+	})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.13/integrations/gh", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = commitstatus.GetIntegration("gh")
+	c.Assert(err, check.Equals, commitstatus.ErrIntegrationNotFound)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeIntegration, Value: "gh"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "integration.delete",
+	}, eventtest.HasEvent)
+}
@@ -6,12 +6,20 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"net/http"
+	"sort"
+	"strconv"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/context"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	"golang.org/x/net/websocket"
@@ -69,3 +77,77 @@ func scanLogs(stream io.Reader) error {
 type errMsg struct {
 	Error string `json:"error"`
 }
+
+const (
+	defaultTraceLines   = 100
+	defaultTraceMaxApps = 50
+)
+
+// title: log trace
+// path: /logs/trace/{id}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   400: Invalid data
+//   401: Unauthorized
+func logTrace(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	query := r.URL.Query()
+	requestID := query.Get(":id")
+	lines := defaultTraceLines
+	if l := query.Get("lines"); l != "" {
+		var err error
+		lines, err = strconv.Atoi(l)
+		if err != nil || lines <= 0 {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: `Parameter "lines" must be a positive integer.`}
+		}
+	}
+	filter := &app.Filter{
+		TeamOwner: query.Get("teamOwner"),
+		Pool:      query.Get("pool"),
+		Tags:      query["tag"],
+	}
+	contexts := permission.ContextsForPermission(t, permission.PermAppReadLog)
+	if len(contexts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	apps, err := app.List(ctx, appFilterByContext(contexts, filter))
+	if err != nil {
+		return err
+	}
+	maxApps, _ := config.GetInt("log:trace-max-apps")
+	if maxApps <= 0 {
+		maxApps = defaultTraceMaxApps
+	}
+	if len(apps) > maxApps {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Trace would scan %d apps, the limit is %d. Narrow it with the teamOwner, pool or tag parameters.", len(apps), maxApps),
+		}
+	}
+	var logs []appTypes.Applog
+	for i := range apps {
+		appLogs, err := apps[i].LastLogs(ctx, servicemanager.AppLog, appTypes.ListLogArgs{
+			Limit:     lines,
+			RequestID: requestID,
+			Token:     t,
+		})
+		if err != nil {
+			log.Debugf("[log trace] unable to list logs of app %q: %v", apps[i].Name, err)
+			continue
+		}
+		logs = append(logs, appLogs...)
+	}
+	if len(logs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Date.Before(logs[j].Date)
+	})
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(logs)
+}
@@ -6,8 +6,10 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"sort"
@@ -15,6 +17,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/shutdown"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/applog"
@@ -207,6 +210,62 @@ func (s *S) TestAddLogsHandlerInvalidToken(c *check.C) {
 	c.Assert(string(buffer[:n]), check.Equals, `{"error":"wslogs: invalid token app name: \"\""}`)
 }
 
+func (s *S) TestLogTrace(c *check.C) {
+	config.Set("log:request-id-pattern", `request_id=(\S+)`)
+	defer config.Unset("log:request-id-pattern")
+	for _, name := range []string{"frontend", "backend", "other"} {
+		a := app.App{Name: name, Platform: "zend", TeamOwner: s.team.Name, Tags: []string{"project=shop"}}
+		if name == "other" {
+			a.Tags = nil
+		}
+		err := app.CreateApp(context.TODO(), &a, s.user)
+		c.Assert(err, check.IsNil)
+		err = servicemanager.AppLog.Add(name, "GET / request_id=abc-123", "web", "unit1")
+		c.Assert(err, check.IsNil)
+		err = servicemanager.AppLog.Add(name, "GET / request_id=def-456", "web", "unit1")
+		c.Assert(err, check.IsNil)
+	}
+	request, err := http.NewRequest(http.MethodGet, "/logs/trace/abc-123?tag=project%3Dshop", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var logs []appTypes.Applog
+	err = json.Unmarshal(recorder.Body.Bytes(), &logs)
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 2)
+	apps := []string{logs[0].AppName, logs[1].AppName}
+	sort.Strings(apps)
+	c.Assert(apps, check.DeepEquals, []string{"backend", "frontend"})
+	for _, l := range logs {
+		c.Assert(l.RequestID, check.Equals, "abc-123")
+	}
+	request, err = http.NewRequest(http.MethodGet, "/logs/trace/unknown", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestLogTraceTooManyApps(c *check.C) {
+	config.Set("log:trace-max-apps", 1)
+	defer config.Unset("log:trace-max-apps")
+	for _, name := range []string{"frontend", "backend"} {
+		a := app.App{Name: name, Platform: "zend", TeamOwner: s.team.Name}
+		err := app.CreateApp(context.TODO(), &a, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	request, err := http.NewRequest(http.MethodGet, "/logs/trace/abc-123", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, "Trace would scan 2 apps, the limit is 1.*\n")
+}
+
 func (s *S) BenchmarkScanLogs(c *check.C) {
 	c.StopTimer()
 	var apps []app.App
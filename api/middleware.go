@@ -6,6 +6,7 @@ package api
 
 import (
 	"bytes"
+	stdContext "context"
 	"encoding/json"
 	"fmt"
 	stdIO "io"
@@ -25,6 +26,7 @@ import (
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/cmd"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/log"
 	tsuruNet "github.com/tsuru/tsuru/net"
@@ -41,18 +43,27 @@ const (
 	defaultMaxMemory = 32 << 20 // 32 MB
 )
 
-func validate(token string, r *http.Request) (auth.Token, error) {
-	var t auth.Token
-	t, err := app.AuthScheme.Auth(r.Context(), token)
+// authenticate returns the user, app or team token matching the value of
+// the Authorization header.
+func authenticate(ctx stdContext.Context, token string) (auth.Token, error) {
+	t, err := app.AuthScheme.Auth(ctx, token)
 	if err != nil {
 		t, err = auth.APIAuth(token)
 		if err != nil {
-			t, err = servicemanager.TeamToken.Authenticate(r.Context(), token)
+			t, err = servicemanager.TeamToken.Authenticate(ctx, token)
 			if err != nil {
 				return nil, err
 			}
 		}
 	}
+	return t, nil
+}
+
+func validate(token string, r *http.Request) (auth.Token, error) {
+	t, err := authenticate(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
 	span := opentracing.SpanFromContext(r.Context())
 
 	if t.IsAppToken() {
@@ -156,6 +167,9 @@ func errorHandlingMiddleware(w http.ResponseWriter, r *http.Request, next http.H
 		if errors.Cause(err) == appTypes.ErrAppNotFound {
 			code = http.StatusNotFound
 		}
+		if errors.Cause(err) == event.ErrDraining {
+			code = http.StatusServiceUnavailable
+		}
 		if verbosity == 0 {
 			err = fmt.Errorf("%s", err)
 		} else {
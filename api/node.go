@@ -224,8 +224,12 @@ func removeNodeHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 //   204: No content
 func listNodesHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	ctx := r.Context()
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return err
+	}
 	filter := &provTypes.NodeFilter{}
-	err := ParseInput(r, &filter)
+	err = ParseInput(r, &filter)
 	if err != nil {
 		return err
 	}
@@ -298,8 +302,22 @@ func listNodesHandler(w http.ResponseWriter, r *http.Request, t auth.Token) erro
 		w.WriteHeader(http.StatusNoContent)
 		return nil
 	}
-	result := apiTypes.ListNodeResponse{
-		Nodes:    allNodes,
+	nodes, next, err := opts.page(allNodes)
+	if err != nil {
+		return err
+	}
+	nodes, err = opts.selectFields(nodes)
+	if err != nil {
+		return err
+	}
+	if next > 0 {
+		setNextLink(w, r, next)
+	}
+	result := struct {
+		Nodes    interface{}    `json:"nodes"`
+		Machines []iaas.Machine `json:"machines"`
+	}{
+		Nodes:    nodes,
 		Machines: machines,
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -658,6 +676,134 @@ func rebalanceNodesHandler(w http.ResponseWriter, r *http.Request, t auth.Token)
 	return nil
 }
 
+// title: rebalance plan for nodes
+// path: /node/rebalance/plan
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   400: Invalid data
+//   401: Unauthorized
+func rebalanceNodesPlanHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	var params provision.RebalanceNodesOptions
+	err := ParseInput(r, &params)
+	if err != nil {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	}
+	params.Force = true
+	var permContexts []permTypes.PermissionContext
+	var ok bool
+	params.Pool, ok = params.MetadataFilter[provision.PoolMetadataName]
+	if ok {
+		delete(params.MetadataFilter, provision.PoolMetadataName)
+		permContexts = append(permContexts, permission.Context(permTypes.CtxPool, params.Pool))
+	}
+	if !permission.Check(t, permission.PermNodeUpdateRebalance, permContexts...) {
+		return permission.ErrUnauthorized
+	}
+	var provs []provision.Provisioner
+	if params.Pool != "" {
+		p, err := pool.GetPoolByName(ctx, params.Pool)
+		if err != nil {
+			return err
+		}
+		prov, err := p.GetProvisioner()
+		if err != nil {
+			return err
+		}
+		if _, ok := prov.(provision.NodeRebalancePlanProvisioner); !ok {
+			return provision.ProvisionerNotSupported{Prov: prov, Action: "node rebalance plans"}
+		}
+		provs = append(provs, prov)
+	} else {
+		provs, err = provision.Registry()
+		if err != nil {
+			return err
+		}
+	}
+	result := provision.RebalancePlan{Moves: []provision.RebalanceMove{}, Nodes: []provision.RebalanceNodePlan{}}
+	for _, prov := range provs {
+		planProv, ok := prov.(provision.NodeRebalancePlanProvisioner)
+		if !ok {
+			continue
+		}
+		plan, err := planProv.RebalancePlan(ctx, params)
+		if err != nil {
+			return errors.Wrap(err, "Error trying to plan the rebalance of units in nodes")
+		}
+		result.Moves = append(result.Moves, plan.Moves...)
+		result.Nodes = append(result.Nodes, plan.Nodes...)
+		result.EstimatedDuration += plan.EstimatedDuration
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
+
+// title: evict node
+// path: /node/{address}/eviction
+// method: POST
+// produce: application/x-json-stream
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Not found
+func nodeEvictionHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	address := r.URL.Query().Get(":address")
+	if address == "" {
+		return errors.Errorf("Node address is required.")
+	}
+	prov, n, err := node.FindNode(ctx, address)
+	if err != nil {
+		if err == provision.ErrNodeNotFound {
+			return &tsuruErrors.HTTP{
+				Code:    http.StatusNotFound,
+				Message: err.Error(),
+			}
+		}
+		return err
+	}
+	poolContext := permission.Context(permTypes.CtxPool, n.Pool())
+	if !permission.Check(t, permission.PermNodeUpdateEviction, poolContext) {
+		return permission.ErrUnauthorized
+	}
+	evictionProv, ok := prov.(provision.NodeEvictionProvisioner)
+	if !ok {
+		return provision.ProvisionerNotSupported{Prov: prov, Action: "node eviction"}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:      event.Target{Type: event.TargetTypeNode, Value: n.Address()},
+		Kind:        permission.PermNodeUpdateEviction,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  event.FormToCustomData(InputFields(r)),
+		DisableLock: true,
+		Allowed:     event.Allowed(permission.PermPoolReadEvents, poolContext),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 15*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	err = evictionProv.EvictNode(ctx, provision.EvictNodeOptions{
+		Address: n.Address(),
+		Writer:  evt,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Error trying to evict node")
+	}
+	fmt.Fprintf(writer, "Node %s successfully evicted!\n", n.Address())
+	return nil
+}
+
 // title: node info
 // path: /node/{address}
 // method: GET
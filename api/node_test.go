@@ -337,6 +337,45 @@ func (s *S) TestRemoveNodeHandler(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestNodeEvictionHandler(c *check.C) {
+	err := s.provisioner.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address: "host.com:2375",
+	})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest("POST", "/node/host.com:2375/eviction", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	c.Assert(rec.Body.String(), check.Matches, `(?s).*evicting\.\.\..*Node host.com:2375 successfully evicted!.*`)
+	nodes, err := s.provisioner.ListNodes(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.HasLen, 1)
+	c.Assert(nodes[0].Status(), check.Equals, "disabled")
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeNode, Value: "host.com:2375"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "node.update.eviction",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":address", "value": "host.com:2375"},
+		},
+		LogMatches: []string{`evicting\.\.\.`},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestNodeEvictionHandlerNodeNotFound(c *check.C) {
+	req, err := http.NewRequest("POST", "/node/host.com:2375/eviction", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	server := RunServer(true)
+	server.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
 func (s *S) TestRemoveNodeHandlerNoRebalance(c *check.C) {
 	err := s.provisioner.AddNode(context.TODO(), provision.AddNodeOptions{
 		Address: "host.com:2375",
@@ -896,27 +935,27 @@ func (s *S) TestNodeHealingUpdateRead(c *check.C) {
 		}},
 		{"pool=p1&Enabled=false", map[string]healer.NodeHealerConfig{
 			"":   {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxUnresponsiveTime: intPtr(20)},
-			"p1": {Enabled: boolPtr(false), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(20), MaxUnresponsiveTimeInherited: true},
+			"p1": {Enabled: boolPtr(false), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(20), MaxUnresponsiveTimeInherited: true, ReplaceNodeInherited: true},
 		}},
 		{"pool=p1&Enabled=true", map[string]healer.NodeHealerConfig{
 			"":   {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxUnresponsiveTime: intPtr(20)},
-			"p1": {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(20), MaxUnresponsiveTimeInherited: true},
+			"p1": {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(20), MaxUnresponsiveTimeInherited: true, ReplaceNodeInherited: true},
 		}},
 		{"pool=p1", map[string]healer.NodeHealerConfig{
 			"":   {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxUnresponsiveTime: intPtr(20)},
-			"p1": {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(20), MaxUnresponsiveTimeInherited: true},
+			"p1": {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(20), MaxUnresponsiveTimeInherited: true, ReplaceNodeInherited: true},
 		}},
 		{"pool=p1&MaxUnresponsiveTime=30", map[string]healer.NodeHealerConfig{
 			"":   {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxUnresponsiveTime: intPtr(20)},
-			"p1": {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(30), MaxUnresponsiveTimeInherited: false},
+			"p1": {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(30), MaxUnresponsiveTimeInherited: false, ReplaceNodeInherited: true},
 		}},
 		{"pool=p1&MaxUnresponsiveTime=0", map[string]healer.NodeHealerConfig{
 			"":   {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxUnresponsiveTime: intPtr(20)},
-			"p1": {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(0), MaxUnresponsiveTimeInherited: false},
+			"p1": {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(0), MaxUnresponsiveTimeInherited: false, ReplaceNodeInherited: true},
 		}},
 		{"pool=p1&Enabled=false", map[string]healer.NodeHealerConfig{
 			"":   {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxUnresponsiveTime: intPtr(20)},
-			"p1": {Enabled: boolPtr(false), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(0), MaxUnresponsiveTimeInherited: false},
+			"p1": {Enabled: boolPtr(false), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(0), MaxUnresponsiveTimeInherited: false, ReplaceNodeInherited: true},
 		}},
 	}
 	for i, t := range tests {
@@ -950,7 +989,7 @@ func (s *S) TestNodeHealingUpdateRead(c *check.C) {
 	configMap := doRequest("")
 	c.Assert(configMap, check.DeepEquals, map[string]healer.NodeHealerConfig{
 		"":   {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60), MaxUnresponsiveTime: intPtr(20)},
-		"p1": {Enabled: boolPtr(false), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(20), MaxUnresponsiveTimeInherited: true},
+		"p1": {Enabled: boolPtr(false), MaxTimeSinceSuccess: intPtr(60), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTime: intPtr(20), MaxUnresponsiveTimeInherited: true, ReplaceNodeInherited: true},
 	})
 	request, err = http.NewRequest("DELETE", "/docker/healing/node", nil)
 	c.Assert(err, check.IsNil)
@@ -961,7 +1000,7 @@ func (s *S) TestNodeHealingUpdateRead(c *check.C) {
 	configMap = doRequest("")
 	c.Assert(configMap, check.DeepEquals, map[string]healer.NodeHealerConfig{
 		"":   {},
-		"p1": {Enabled: boolPtr(false), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTimeInherited: true},
+		"p1": {Enabled: boolPtr(false), MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTimeInherited: true, ReplaceNodeInherited: true},
 	})
 	request, err = http.NewRequest("DELETE", "/docker/healing/node?pool=p1&name=Enabled", nil)
 	c.Assert(err, check.IsNil)
@@ -972,7 +1011,7 @@ func (s *S) TestNodeHealingUpdateRead(c *check.C) {
 	configMap = doRequest("")
 	c.Assert(configMap, check.DeepEquals, map[string]healer.NodeHealerConfig{
 		"":   {},
-		"p1": {EnabledInherited: true, MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTimeInherited: true},
+		"p1": {EnabledInherited: true, MaxTimeSinceSuccessInherited: true, MaxUnresponsiveTimeInherited: true, ReplaceNodeInherited: true},
 	})
 }
 
@@ -1019,7 +1058,7 @@ func (s *S) TestNodeHealingConfigUpdateReadLimited(c *check.C) {
 	data = doRequest(t, http.StatusOK, "pool=p2&Enabled=true&MaxTimeSinceSuccess=20")
 	c.Assert(data, check.DeepEquals, map[string]healer.NodeHealerConfig{
 		"":   {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(60)},
-		"p2": {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(20), MaxUnresponsiveTimeInherited: true},
+		"p2": {Enabled: boolPtr(true), MaxTimeSinceSuccess: intPtr(20), MaxUnresponsiveTimeInherited: true, ReplaceNodeInherited: true},
 	})
 }
 
@@ -1121,6 +1160,64 @@ func (s *S) TestNodeRebalanceFilters(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestNodeRebalancePlan(c *check.C) {
+	poolOpts := pool.AddPoolOptions{Name: "pool1"}
+	err := pool.AddPool(context.TODO(), poolOpts)
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address: "n1",
+		Pool:    "pool1",
+	})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address: "n2",
+		Pool:    "pool1",
+	})
+	c.Assert(err, check.IsNil)
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = s.provisioner.AddUnitsToNode(&a, 3, "web", nil, "n1", nil)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/node/rebalance/plan?MetadataFilter.pool=pool1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var plan provision.RebalancePlan
+	err = json.Unmarshal(recorder.Body.Bytes(), &plan)
+	c.Assert(err, check.IsNil)
+	c.Assert(plan, check.DeepEquals, provision.RebalancePlan{
+		Moves: []provision.RebalanceMove{},
+		Nodes: []provision.RebalanceNodePlan{
+			{Address: "n1", UnitsBefore: 3, UnitsAfter: 3},
+			{Address: "n2"},
+		},
+	})
+	units, err := s.provisioner.Units(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	for _, u := range units {
+		c.Assert(u.IP, check.Equals, "n1")
+	}
+}
+
+func (s *S) TestNodeRebalancePlanUnauthorized(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermNodeUpdateRebalance,
+		Context: permission.Context(permTypes.CtxPool, "other"),
+	})
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/node/rebalance/plan?MetadataFilter.pool=pool1", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	server := RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
 func (s *S) TestInfoNodeHandlerNotFound(c *check.C) {
 	nodeAddr := "http://host1.com:2375"
 	req, err := http.NewRequest("GET", "/node/"+nodeAddr, nil)
@@ -0,0 +1,247 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	stdContext "context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/servicemanager"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// checkNotificationSubscriptions ensures the user is allowed to read the
+// events of every app and pool the channel is subscribed to, so teams can't
+// be notified about apps they don't have access to.
+func checkNotificationSubscriptions(ctx stdContext.Context, t auth.Token, ch eventTypes.NotificationChannel) error {
+	for _, appName := range ch.Apps {
+		a, err := getApp(ctx, appName)
+		if err != nil {
+			return err
+		}
+		if !permission.Check(t, permission.PermAppReadEvents, contextsForApp(a)...) {
+			return permission.ErrUnauthorized
+		}
+	}
+	for _, poolName := range ch.Pools {
+		_, err := pool.GetPoolByName(ctx, poolName)
+		if err != nil {
+			if err == pool.ErrPoolNotFound {
+				return &errors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("Pool %s not found.", poolName)}
+			}
+			return err
+		}
+		if !permission.Check(t, permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)) {
+			return permission.ErrUnauthorized
+		}
+	}
+	return nil
+}
+
+// title: notification channel list
+// path: /notifications/channels
+// method: GET
+// produce: application/json
+// responses:
+//   200: List notification channels
+//   204: No content
+func notificationChannelList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctxs := permission.ContextsForPermission(t, permission.PermNotificationRead, permTypes.CtxTeam)
+	var teams []string
+	for _, c := range ctxs {
+		if c.CtxType == permTypes.CtxGlobal {
+			teams = nil
+			break
+		}
+		teams = append(teams, c.Value)
+	}
+	channels, err := servicemanager.Notification.List(teams)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(channels)
+}
+
+// title: notification channel info
+// path: /notifications/channels/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: Get notification channel
+//   404: Not found
+//   401: Unauthorized
+func notificationChannelInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	name := r.URL.Query().Get(":name")
+	ch, err := servicemanager.Notification.Find(name)
+	if err != nil {
+		if err == eventTypes.ErrNotificationChannelNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	ctx := permission.Context(permTypes.CtxTeam, ch.TeamOwner)
+	if !permission.Check(t, permission.PermNotificationRead, ctx) {
+		return permission.ErrUnauthorized
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(ch)
+}
+
+// title: notification channel create
+// path: /notifications/channels
+// method: POST
+// responses:
+//   200: Notification channel created
+//   400: Invalid notification channel
+//   401: Unauthorized
+//   404: App or pool not found
+//   409: Notification channel already exists
+func notificationChannelCreate(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	var ch eventTypes.NotificationChannel
+	err := ParseInput(r, &ch)
+	if err != nil {
+		return err
+	}
+	if ch.TeamOwner == "" {
+		ch.TeamOwner, err = autoTeamOwner(ctx, t, permission.PermNotificationCreate)
+		if err != nil {
+			return err
+		}
+	}
+	permCtx := permission.Context(permTypes.CtxTeam, ch.TeamOwner)
+	if !permission.Check(t, permission.PermNotificationCreate, permCtx) {
+		return permission.ErrUnauthorized
+	}
+	err = checkNotificationSubscriptions(ctx, t, ch)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeNotification, Value: ch.Name},
+		Kind:       permission.PermNotificationCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r, "token")),
+		Allowed:    event.Allowed(permission.PermNotificationReadEvents, permCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		evt.Done(err)
+	}()
+	err = servicemanager.Notification.Create(ch)
+	if err == eventTypes.ErrNotificationChannelAlreadyExists {
+		w.WriteHeader(http.StatusConflict)
+	}
+	return err
+}
+
+// title: notification channel update
+// path: /notifications/channels/{name}
+// method: PUT
+// responses:
+//   200: Notification channel updated
+//   400: Invalid notification channel
+//   401: Unauthorized
+//   404: Notification channel, app or pool not found
+func notificationChannelUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	var ch eventTypes.NotificationChannel
+	err := ParseInput(r, &ch)
+	if err != nil {
+		return err
+	}
+	ch.Name = r.URL.Query().Get(":name")
+	current, err := servicemanager.Notification.Find(ch.Name)
+	if err != nil {
+		if err == eventTypes.ErrNotificationChannelNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	if ch.TeamOwner == "" {
+		ch.TeamOwner = current.TeamOwner
+	}
+	currentCtx := permission.Context(permTypes.CtxTeam, current.TeamOwner)
+	ctx := permission.Context(permTypes.CtxTeam, ch.TeamOwner)
+	if !permission.Check(t, permission.PermNotificationUpdate, currentCtx) ||
+		!permission.Check(t, permission.PermNotificationUpdate, ctx) {
+		return permission.ErrUnauthorized
+	}
+	err = checkNotificationSubscriptions(r.Context(), t, ch)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeNotification, Value: ch.Name},
+		Kind:       permission.PermNotificationUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r, "token")),
+		Allowed:    event.Allowed(permission.PermNotificationReadEvents, currentCtx, ctx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		evt.Done(err)
+	}()
+	err = servicemanager.Notification.Update(ch)
+	if err == eventTypes.ErrNotificationChannelNotFound {
+		w.WriteHeader(http.StatusNotFound)
+	}
+	return err
+}
+
+// title: notification channel delete
+// path: /notifications/channels/{name}
+// method: DELETE
+// responses:
+//   200: Notification channel deleted
+//   401: Unauthorized
+//   404: Notification channel not found
+func notificationChannelDelete(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	name := r.URL.Query().Get(":name")
+	ch, err := servicemanager.Notification.Find(name)
+	if err != nil {
+		if err == eventTypes.ErrNotificationChannelNotFound {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return err
+	}
+	ctx := permission.Context(permTypes.CtxTeam, ch.TeamOwner)
+	if !permission.Check(t, permission.PermNotificationDelete, ctx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeNotification, Value: ch.Name},
+		Kind:       permission.PermNotificationDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermNotificationReadEvents, ctx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		evt.Done(err)
+	}()
+	return servicemanager.Notification.Delete(name)
+}
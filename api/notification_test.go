@@ -0,0 +1,209 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/ajg/form"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) notificationRequest(c *check.C, method, path string, ch eventTypes.NotificationChannel, token string) *httptest.ResponseRecorder {
+	bodyData, err := form.EncodeToString(ch)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(method, path, strings.NewReader(bodyData))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *S) TestNotificationChannelList(c *check.C) {
+	ch1 := eventTypes.NotificationChannel{TeamOwner: s.team.Name, Name: "ch1", Driver: "slack", URL: "https://hooks.slack.com/x", Pools: []string{s.Pool}}
+	ch2 := eventTypes.NotificationChannel{TeamOwner: "t2", Name: "ch2", Driver: "teams", URL: "https://outlook.office.com/x", Pools: []string{s.Pool}}
+	err := servicemanager.Notification.Create(ch1)
+	c.Assert(err, check.IsNil)
+	err = servicemanager.Notification.Create(ch2)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermNotificationRead,
+		Context: permission.Context(permTypes.CtxTeam, "t2"),
+	})
+	request, err := http.NewRequest("GET", "/1.13/notifications/channels", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result []eventTypes.NotificationChannel
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 1)
+	c.Assert(result[0].Name, check.Equals, "ch2")
+}
+
+func (s *S) TestNotificationChannelListEmpty(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.13/notifications/channels", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestNotificationChannelCreate(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	ch := eventTypes.NotificationChannel{
+		Name:   "deploys",
+		Driver: "matrix",
+		URL:    "https://matrix.example.com",
+		Room:   "!room:example.com",
+		Token:  "secret",
+		Apps:   []string{"myapp"},
+		Events: []string{"deploy-failure", "healing"},
+	}
+	recorder := s.notificationRequest(c, "POST", "/1.13/notifications/channels", ch, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbCh, err := servicemanager.Notification.Find("deploys")
+	c.Assert(err, check.IsNil)
+	ch.TeamOwner = s.team.Name
+	ch.Pools = []string{}
+	c.Assert(dbCh, check.DeepEquals, ch)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeNotification, Value: "deploys"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "notification.create",
+		StartCustomData: []map[string]interface{}{
+			{"name": "name", "value": "deploys"},
+			{"name": "driver", "value": "matrix"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestNotificationChannelCreateInvalid(c *check.C) {
+	ch := eventTypes.NotificationChannel{Name: "deploys", Driver: "irc", URL: "https://irc.example.com", Pools: []string{s.Pool}}
+	recorder := s.notificationRequest(c, "POST", "/1.13/notifications/channels", ch, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, `invalid notification driver "irc".*\n`)
+}
+
+func (s *S) TestNotificationChannelCreateConflict(c *check.C) {
+	ch := eventTypes.NotificationChannel{TeamOwner: s.team.Name, Name: "deploys", Driver: "slack", URL: "https://hooks.slack.com/x", Pools: []string{s.Pool}}
+	err := servicemanager.Notification.Create(ch)
+	c.Assert(err, check.IsNil)
+	recorder := s.notificationRequest(c, "POST", "/1.13/notifications/channels", ch, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestNotificationChannelCreateAppNotAllowed(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermNotificationCreate,
+		Context: permission.Context(permTypes.CtxTeam, "t2"),
+	})
+	ch := eventTypes.NotificationChannel{TeamOwner: "t2", Name: "deploys", Driver: "slack", URL: "https://hooks.slack.com/x", Apps: []string{"myapp"}}
+	recorder := s.notificationRequest(c, "POST", "/1.13/notifications/channels", ch, token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	_, err = servicemanager.Notification.Find("deploys")
+	c.Assert(err, check.Equals, eventTypes.ErrNotificationChannelNotFound)
+}
+
+func (s *S) TestNotificationChannelCreatePoolNotFound(c *check.C) {
+	ch := eventTypes.NotificationChannel{Name: "deploys", Driver: "slack", URL: "https://hooks.slack.com/x", Pools: []string{"unknown"}}
+	recorder := s.notificationRequest(c, "POST", "/1.13/notifications/channels", ch, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, "Pool unknown not found.\n")
+}
+
+func (s *S) TestNotificationChannelInfo(c *check.C) {
+	ch := eventTypes.NotificationChannel{TeamOwner: s.team.Name, Name: "deploys", Driver: "slack", URL: "https://hooks.slack.com/x", Pools: []string{s.Pool}}
+	err := servicemanager.Notification.Create(ch)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.13/notifications/channels/deploys", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result eventTypes.NotificationChannel
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Name, check.Equals, "deploys")
+	c.Assert(result.Pools, check.DeepEquals, []string{s.Pool})
+}
+
+func (s *S) TestNotificationChannelInfoNotFound(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.13/notifications/channels/deploys", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestNotificationChannelUpdate(c *check.C) {
+	ch := eventTypes.NotificationChannel{TeamOwner: s.team.Name, Name: "deploys", Driver: "slack", URL: "https://hooks.slack.com/x", Pools: []string{s.Pool}}
+	err := servicemanager.Notification.Create(ch)
+	c.Assert(err, check.IsNil)
+	ch.TeamOwner = ""
+	ch.Driver = "teams"
+	ch.URL = "https://outlook.office.com/x"
+	ch.Events = []string{"autoscale"}
+	recorder := s.notificationRequest(c, "PUT", "/1.13/notifications/channels/deploys", ch, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbCh, err := servicemanager.Notification.Find("deploys")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbCh.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(dbCh.Driver, check.Equals, "teams")
+	c.Assert(dbCh.Events, check.DeepEquals, []string{"autoscale"})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeNotification, Value: "deploys"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "notification.update",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestNotificationChannelUpdateNotFound(c *check.C) {
+	ch := eventTypes.NotificationChannel{Driver: "slack", URL: "https://hooks.slack.com/x", Pools: []string{s.Pool}}
+	recorder := s.notificationRequest(c, "PUT", "/1.13/notifications/channels/deploys", ch, s.token.GetValue())
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestNotificationChannelDelete(c *check.C) {
+	ch := eventTypes.NotificationChannel{TeamOwner: s.team.Name, Name: "deploys", Driver: "slack", URL: "https://hooks.slack.com/x", Pools: []string{s.Pool}}
+	err := servicemanager.Notification.Create(ch)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.13/notifications/channels/deploys", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = servicemanager.Notification.Find("deploys")
+	c.Assert(err, check.Equals, eventTypes.ErrNotificationChannelNotFound)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeNotification, Value: "deploys"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "notification.delete",
+	}, eventtest.HasEvent)
+}
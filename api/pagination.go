@@ -0,0 +1,220 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+const maxListLimit = 1000
+
+// listOptions holds the parameters accepted by list endpoints: limit is the
+// size of the page, cursor is returned in the Link header of the previous
+// page, sort is the name of a field, prefixed with - for descending order,
+// and fields restricts the fields of each item in the response.
+type listOptions struct {
+	limit  int
+	offset int
+	sort   string
+	desc   bool
+	fields []string
+}
+
+func parseListOptions(r *http.Request) (*listOptions, error) {
+	query := r.URL.Query()
+	opts := &listOptions{}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		opts.limit, err = strconv.Atoi(limit)
+		if err != nil || opts.limit < 0 {
+			return nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "limit must be a positive integer"}
+		}
+		if opts.limit > maxListLimit {
+			opts.limit = maxListLimit
+		}
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		offset, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "invalid cursor"}
+		}
+		opts.offset = offset
+	}
+	opts.sort = query.Get("sort")
+	if strings.HasPrefix(opts.sort, "-") {
+		opts.sort = opts.sort[1:]
+		opts.desc = true
+	}
+	for _, fields := range query["fields"] {
+		for _, f := range strings.Split(fields, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				opts.fields = append(opts.fields, f)
+			}
+		}
+	}
+	return opts, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(data))
+	if err == nil && offset < 0 {
+		err = fmt.Errorf("negative offset")
+	}
+	return offset, err
+}
+
+// paginated returns whether the page is a subset of the list.
+func (o *listOptions) paginated() bool {
+	return o.limit > 0 || o.offset > 0
+}
+
+// bounds returns the page of a list with total items and the offset of the
+// next page, which is zero in the last page.
+func (o *listOptions) bounds(total int) (start, end, next int) {
+	start, end = o.offset, total
+	if start > total {
+		start = total
+	}
+	if o.limit > 0 && start+o.limit < total {
+		end = start + o.limit
+		next = end
+	}
+	return start, end, next
+}
+
+// page sorts and paginates items, a slice, by their JSON fields, returning
+// the page and the offset of the next page.
+func (o *listOptions) page(items interface{}) (interface{}, int, error) {
+	if o.sort == "" && !o.paginated() {
+		return items, 0, nil
+	}
+	list, err := toMaps(items)
+	if err != nil {
+		return nil, 0, err
+	}
+	if o.sort != "" {
+		sort.SliceStable(list, func(i, j int) bool {
+			if o.desc {
+				return lessValue(list[j][o.sort], list[i][o.sort])
+			}
+			return lessValue(list[i][o.sort], list[j][o.sort])
+		})
+	}
+	start, end, next := o.bounds(len(list))
+	return list[start:end], next, nil
+}
+
+// selectFields returns the items in page with only the fields selected.
+func (o *listOptions) selectFields(page interface{}) (interface{}, error) {
+	if len(o.fields) == 0 {
+		return page, nil
+	}
+	list, err := toMaps(page)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]interface{}, len(list))
+	for i, item := range list {
+		result[i] = make(map[string]interface{}, len(o.fields))
+		for _, f := range o.fields {
+			if v, ok := item[f]; ok {
+				result[i][f] = v
+			}
+		}
+	}
+	return result, nil
+}
+
+// writeList writes the page of items, a slice, selected by opts as JSON.
+func writeList(w http.ResponseWriter, r *http.Request, opts *listOptions, items interface{}) error {
+	page, next, err := opts.page(items)
+	if err != nil {
+		return err
+	}
+	return writePage(w, r, opts, page, next)
+}
+
+// writePage writes a page already sorted and paginated, with the fields
+// selected in opts and a Link header to the next page.
+func writePage(w http.ResponseWriter, r *http.Request, opts *listOptions, page interface{}, next int) error {
+	if reflect.ValueOf(page).Len() == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	if next > 0 {
+		setNextLink(w, r, next)
+	}
+	page, err := opts.selectFields(page)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(page)
+}
+
+func setNextLink(w http.ResponseWriter, r *http.Request, next int) {
+	query := r.URL.Query()
+	for k := range query {
+		if strings.HasPrefix(k, ":") {
+			query.Del(k)
+		}
+	}
+	query.Set("cursor", encodeCursor(next))
+	w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, query.Encode()))
+}
+
+func toMaps(items interface{}) ([]map[string]interface{}, error) {
+	if list, ok := items.([]map[string]interface{}); ok {
+		return list, nil
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var list []map[string]interface{}
+	err = json.Unmarshal(data, &list)
+	return list, err
+}
+
+// lessValue compares values decoded from JSON, missing values come first.
+func lessValue(a, b interface{}) bool {
+	switch va := a.(type) {
+	case nil:
+		return b != nil
+	case float64:
+		if vb, ok := b.(float64); ok {
+			return va < vb
+		}
+	case string:
+		if vb, ok := b.(string); ok {
+			return va < vb
+		}
+	case bool:
+		if vb, ok := b.(bool); ok {
+			return !va && vb
+		}
+	}
+	if b == nil {
+		return false
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestParseListOptions(c *check.C) {
+	request, err := http.NewRequest("GET", "/apps?limit=5&sort=-name&fields=name,pool&fields=tags&cursor="+encodeCursor(10), nil)
+	c.Assert(err, check.IsNil)
+	opts, err := parseListOptions(request)
+	c.Assert(err, check.IsNil)
+	c.Assert(opts, check.DeepEquals, &listOptions{
+		limit:  5,
+		offset: 10,
+		sort:   "name",
+		desc:   true,
+		fields: []string{"name", "pool", "tags"},
+	})
+	request, err = http.NewRequest("GET", "/apps?cursor=invalid", nil)
+	c.Assert(err, check.IsNil)
+	_, err = parseListOptions(request)
+	c.Assert(err, check.ErrorMatches, "invalid cursor")
+	request, err = http.NewRequest("GET", "/apps?limit=-1", nil)
+	c.Assert(err, check.IsNil)
+	_, err = parseListOptions(request)
+	c.Assert(err, check.ErrorMatches, "limit must be a positive integer")
+}
+
+func (s *S) TestWriteList(c *check.C) {
+	type item struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	items := []item{{"b", 2}, {"c", 1}, {"a", 3}}
+	request, err := http.NewRequest("GET", "/items?limit=2&sort=-count&fields=name", nil)
+	c.Assert(err, check.IsNil)
+	opts, err := parseListOptions(request)
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	err = writeList(recorder, request, opts, items)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorder.Body.String(), check.Equals, `[{"name":"a"},{"name":"b"}]`+"\n")
+	link := recorder.Header().Get("Link")
+	c.Assert(link, check.Matches, `<.*>; rel="next"`)
+	next, err := url.Parse(strings.TrimPrefix(strings.Split(link, ">")[0], "<"))
+	c.Assert(err, check.IsNil)
+	c.Assert(next.Path, check.Equals, "/items")
+	request, err = http.NewRequest("GET", next.String(), nil)
+	c.Assert(err, check.IsNil)
+	opts, err = parseListOptions(request)
+	c.Assert(err, check.IsNil)
+	recorder = httptest.NewRecorder()
+	err = writeList(recorder, request, opts, items)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorder.Body.String(), check.Equals, `[{"name":"c"}]`+"\n")
+	c.Assert(recorder.Header().Get("Link"), check.Equals, "")
+}
+
+func (s *S) TestAppListPaginated(c *check.C) {
+	for _, name := range []string{"app1", "app2", "app3"} {
+		err := app.CreateApp(context.TODO(), &app.App{Name: name, Platform: "zend", TeamOwner: s.team.Name}, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	request, err := http.NewRequest("GET", "/apps?limit=2&sort=-name&fields=name,pool", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var apps []map[string]interface{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &apps)
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.DeepEquals, []map[string]interface{}{
+		{"name": "app3", "pool": "test1"},
+		{"name": "app2", "pool": "test1"},
+	})
+	c.Assert(recorder.Header().Get("Link"), check.Matches, `</apps\?.*cursor=.*>; rel="next"`)
+}
@@ -0,0 +1,300 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+func permTemplateError(err error) error {
+	switch err.(type) {
+	case *permTypes.ErrPermissionNotFound:
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	case *permTypes.ErrPermissionNotAllowed:
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	switch err {
+	case permTypes.ErrInvalidTemplateName, permTypes.ErrInvalidPermissionName:
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	case permTypes.ErrTemplateNotFound, permTypes.ErrRoleNotFound:
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	case permTypes.ErrTemplateAlreadyExists:
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	case permTypes.ErrTemplateInUse:
+		return &tsuruErrors.HTTP{Code: http.StatusPreconditionFailed, Message: err.Error()}
+	}
+	return err
+}
+
+// title: permission template list
+// path: /permission-templates
+// method: GET
+// produce: application/json
+// responses:
+//   200: List permission templates
+//   204: No content
+//   401: Unauthorized
+func permTemplateList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermPermissionTemplateRead) {
+		return permission.ErrUnauthorized
+	}
+	templates, err := permission.ListTemplates()
+	if err != nil {
+		return err
+	}
+	if len(templates) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(templates)
+}
+
+// title: permission template info
+// path: /permission-templates/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: Permission template
+//   401: Unauthorized
+//   404: Permission template not found
+func permTemplateInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermPermissionTemplateRead) {
+		return permission.ErrUnauthorized
+	}
+	template, err := permission.FindTemplate(r.URL.Query().Get(":name"))
+	if err != nil {
+		return permTemplateError(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(template)
+}
+
+// title: permission template create
+// path: /permission-templates
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   201: Permission template created
+//   400: Invalid permission template
+//   401: Unauthorized
+//   409: Permission template already exists
+func permTemplateCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermPermissionTemplateCreate) {
+		return permission.ErrUnauthorized
+	}
+	var template permission.Template
+	err = ParseInput(r, &template)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePermTemplate, Value: template.Name},
+		Kind:       permission.PermPermissionTemplateCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermRoleReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = permission.AddTemplate(&template)
+	if err != nil {
+		return permTemplateError(err)
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// title: permission template update
+// path: /permission-templates/{name}
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Permission template updated
+//   400: Invalid permission template
+//   401: Unauthorized
+//   404: Permission template not found
+func permTemplateUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermPermissionTemplateUpdate) {
+		return permission.ErrUnauthorized
+	}
+	var template permission.Template
+	err = ParseInput(r, &template)
+	if err != nil {
+		return err
+	}
+	template.Name = r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePermTemplate, Value: template.Name},
+		Kind:       permission.PermPermissionTemplateUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermRoleReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return permTemplateError(permission.UpdateTemplate(&template))
+}
+
+// title: permission template delete
+// path: /permission-templates/{name}
+// method: DELETE
+// responses:
+//   200: Permission template removed
+//   401: Unauthorized
+//   404: Permission template not found
+//   412: Permission template used by roles
+func permTemplateDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermPermissionTemplateDelete) {
+		return permission.ErrUnauthorized
+	}
+	name := r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePermTemplate, Value: name},
+		Kind:       permission.PermPermissionTemplateDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermRoleReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return permTemplateError(permission.DestroyTemplate(name))
+}
+
+// title: add templates to role
+// path: /roles/{name}/templates
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Role or permission template not found
+//   409: Permission not allowed
+func roleTemplateAdd(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermRoleUpdateTemplateAdd) {
+		return permission.ErrUnauthorized
+	}
+	roleName := r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeRole, Value: roleName},
+		Kind:       permission.PermRoleUpdateTemplateAdd,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermRoleReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	role, err := permission.FindRole(roleName)
+	if err != nil {
+		return permTemplateError(err)
+	}
+	templates, _ := InputValues(r, "template")
+	if len(templates) == 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: permTypes.ErrInvalidTemplateName.Error()}
+	}
+	return permTemplateError(role.AddTemplates(templates...))
+}
+
+// title: remove template from role
+// path: /roles/{name}/templates/{template}
+// method: DELETE
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Role not found
+func roleTemplateRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermRoleUpdateTemplateRemove) {
+		return permission.ErrUnauthorized
+	}
+	roleName := r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeRole, Value: roleName},
+		Kind:       permission.PermRoleUpdateTemplateRemove,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermRoleReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	role, err := permission.FindRole(roleName)
+	if err != nil {
+		return permTemplateError(err)
+	}
+	return role.RemoveTemplates(r.URL.Query().Get(":template"))
+}
+
+// title: export roles and permission templates
+// path: /permissions/bundle
+// method: GET
+// produce: application/json
+// responses:
+//   200: Roles and permission templates
+//   401: Unauthorized
+func permBundleExport(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermRoleExport) {
+		return permission.ErrUnauthorized
+	}
+	bundle, err := permission.Export()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(bundle)
+}
+
+// title: import roles and permission templates
+// path: /permissions/bundle
+// method: POST
+// consume: application/json
+// responses:
+//   200: Roles and permission templates imported
+//   400: Invalid bundle
+//   401: Unauthorized
+func permBundleImport(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermRoleImport) {
+		return permission.ErrUnauthorized
+	}
+	var bundle permission.Bundle
+	err = ParseJSON(r, &bundle)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:      event.Target{Type: event.TargetTypeGlobal},
+		Kind:        permission.PermRoleImport,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  bundle,
+		Allowed:     event.Allowed(permission.PermRoleReadEvents),
+		DisableLock: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return permission.Import(&bundle)
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPermTemplateCreate(c *check.C) {
+	body := strings.NewReader("name=deployer&description=deploys&permissions.0=app.deploy&permissions.1=app.read")
+	request, err := http.NewRequest("POST", "/1.13/permission-templates", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", recorder.Body.String()))
+	template, err := permission.FindTemplate("deployer")
+	c.Assert(err, check.IsNil)
+	c.Assert(template.Description, check.Equals, "deploys")
+	c.Assert(template.Permissions, check.DeepEquals, []string{"app.deploy", "app.read"})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypePermTemplate, Value: "deployer"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "permission-template.create",
+	}, eventtest.HasEvent)
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("POST", "/1.13/permission-templates", strings.NewReader("name=deployer"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestPermTemplateCreateInvalidPermission(c *check.C) {
+	request, err := http.NewRequest("POST", "/1.13/permission-templates", strings.NewReader("name=deployer&permissions.0=app.invalid"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestPermTemplateDeleteInUse(c *check.C) {
+	err := permission.AddTemplate(&permission.Template{Name: "deployer", Permissions: []string{"app.deploy"}})
+	c.Assert(err, check.IsNil)
+	role, err := permission.NewRole("deployers", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddTemplates("deployer")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.13/permission-templates/deployer", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusPreconditionFailed)
+}
+
+func (s *S) TestRoleTemplateAdd(c *check.C) {
+	err := permission.AddTemplate(&permission.Template{Name: "deployer", Permissions: []string{"app.deploy"}})
+	c.Assert(err, check.IsNil)
+	_, err = permission.NewRole("deployers", "team", "")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.13/roles/deployers/templates", strings.NewReader("template=deployer"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	role, err := permission.FindRole("deployers")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.Templates, check.DeepEquals, []string{"deployer"})
+	perms := role.PermissionsFor("myteam")
+	c.Assert(perms, check.HasLen, 1)
+	c.Assert(perms[0].Scheme, check.Equals, permission.PermAppDeploy)
+	request, err = http.NewRequest("DELETE", "/1.13/roles/deployers/templates/deployer", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	role, err = permission.FindRole("deployers")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.Templates, check.HasLen, 0)
+}
+
+func (s *S) TestRoleTemplateAddNotAllowed(c *check.C) {
+	err := permission.AddTemplate(&permission.Template{Name: "admin", Permissions: []string{"pool.create"}})
+	c.Assert(err, check.IsNil)
+	_, err = permission.NewRole("admins", "team", "")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("POST", "/1.13/roles/admins/templates", strings.NewReader("template=admin"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestPermBundleExportImport(c *check.C) {
+	err := permission.AddTemplate(&permission.Template{Name: "observer", Permissions: []string{"app.read"}})
+	c.Assert(err, check.IsNil)
+	role, err := permission.NewRole("observers", "pool", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddTemplates("observer")
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.13/permissions/bundle", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var bundle permission.Bundle
+	err = json.NewDecoder(recorder.Body).Decode(&bundle)
+	c.Assert(err, check.IsNil)
+	c.Assert(bundle.Templates, check.HasLen, 1)
+	var found bool
+	for _, r := range bundle.Roles {
+		if r.Name == "observers" {
+			found = true
+			c.Assert(r.Templates, check.DeepEquals, []string{"observer"})
+		}
+	}
+	c.Assert(found, check.Equals, true)
+	err = permission.DestroyRole("observers")
+	c.Assert(err, check.IsNil)
+	data, err := json.Marshal(bundle)
+	c.Assert(err, check.IsNil)
+	request, err = http.NewRequest("POST", "/1.13/permissions/bundle", strings.NewReader(string(data)))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	role, err = permission.FindRole("observers")
+	c.Assert(err, check.IsNil)
+	c.Assert(role.ContextType, check.Equals, permTypes.CtxPool)
+	c.Assert(role.Templates, check.DeepEquals, []string{"observer"})
+}
+
+func (s *S) TestPermBundleImportInvalid(c *check.C) {
+	body := `{"roles": [{"name": "r1", "context": "team", "templates": ["unknown"]}]}`
+	request, err := http.NewRequest("POST", "/1.13/permissions/bundle", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "permission template not found\n")
+}
+
+func (s *S) TestPermBundleImportUnauthorized(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermRoleExport,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request, err := http.NewRequest("POST", "/1.13/permissions/bundle", strings.NewReader("{}"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
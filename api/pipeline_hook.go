@@ -0,0 +1,173 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app/pipelinehook"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// title: pipeline hook list
+// path: /pipeline-hooks
+// method: GET
+// produce: application/json
+// responses:
+//   200: List pipeline hooks
+//   204: No content
+//   401: Unauthorized
+func pipelineHookList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermPipelineHookRead) {
+		return permission.ErrUnauthorized
+	}
+	hooks, err := pipelinehook.List()
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(hooks)
+}
+
+// title: pipeline hook info
+// path: /pipeline-hooks/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: Pipeline hook
+//   401: Unauthorized
+//   404: Pipeline hook not found
+func pipelineHookInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermPipelineHookRead) {
+		return permission.ErrUnauthorized
+	}
+	hook, err := pipelinehook.Get(r.URL.Query().Get(":name"))
+	if err == pipelinehook.ErrHookNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(hook)
+}
+
+// title: pipeline hook create
+// path: /pipeline-hooks
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   201: Pipeline hook created
+//   400: Invalid pipeline hook
+//   401: Unauthorized
+//   409: Pipeline hook already exists
+func pipelineHookCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermPipelineHookCreate) {
+		return permission.ErrUnauthorized
+	}
+	var hook pipelinehook.Hook
+	err = ParseInput(r, &hook)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePipelineHook, Value: hook.Name},
+		Kind:       permission.PermPipelineHookCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPipelineHookReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pipelinehook.Create(&hook)
+	if err == pipelinehook.ErrHookAlreadyExists {
+		return &tsuruErrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// title: pipeline hook update
+// path: /pipeline-hooks/{name}
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Pipeline hook updated
+//   400: Invalid pipeline hook
+//   401: Unauthorized
+//   404: Pipeline hook not found
+func pipelineHookUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermPipelineHookUpdate) {
+		return permission.ErrUnauthorized
+	}
+	var hook pipelinehook.Hook
+	err = ParseInput(r, &hook)
+	if err != nil {
+		return err
+	}
+	hook.Name = r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePipelineHook, Value: hook.Name},
+		Kind:       permission.PermPipelineHookUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPipelineHookReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pipelinehook.Update(&hook)
+	if err == pipelinehook.ErrHookNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: pipeline hook delete
+// path: /pipeline-hooks/{name}
+// method: DELETE
+// responses:
+//   200: Pipeline hook removed
+//   401: Unauthorized
+//   404: Pipeline hook not found
+func pipelineHookDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermPipelineHookDelete) {
+		return permission.ErrUnauthorized
+	}
+	name := r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePipelineHook, Value: name},
+		Kind:       permission.PermPipelineHookDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPipelineHookReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pipelinehook.Remove(name)
+	if err == pipelinehook.ErrHookNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
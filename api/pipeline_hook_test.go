@@ -0,0 +1,143 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app/pipelinehook"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPipelineHookCreate(c *check.C) {
+	body := strings.NewReader("name=scan&kind=http&url=http://scanner/check&stages.0=post-build&pools.0=prod&failurePolicy=ignore")
+	request, err := http.NewRequest("POST", "/1.13/pipeline-hooks", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", recorder.Body.String()))
+	hook, err := pipelinehook.Get("scan")
+	c.Assert(err, check.IsNil)
+	c.Assert(hook.Kind, check.Equals, pipelinehook.KindHTTP)
+	c.Assert(hook.URL, check.Equals, "http://scanner/check")
+	c.Assert(hook.Stages, check.DeepEquals, []string{pipelinehook.StagePostBuild})
+	c.Assert(hook.Pools, check.DeepEquals, []string{"prod"})
+	c.Assert(hook.FailurePolicy, check.Equals, pipelinehook.FailurePolicyIgnore)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypePipelineHook, Value: "scan"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pipeline-hook.create",
+	}, eventtest.HasEvent)
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("POST", "/1.13/pipeline-hooks", strings.NewReader("name=scan&kind=http&url=http://other&stages.0=pre-build"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestPipelineHookCreateInvalid(c *check.C) {
+	request, err := http.NewRequest("POST", "/1.13/pipeline-hooks", strings.NewReader("name=scan&kind=http&url=http://scanner&stages.0=pre-deploy"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, `invalid stage "pre-deploy".*\n`)
+}
+
+func (s *S) TestPipelineHookCreateUnauthorized(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermPipelineHookRead,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	request, err := http.NewRequest("POST", "/1.13/pipeline-hooks", strings.NewReader("name=scan&kind=http&url=http://scanner&stages.0=pre-build"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestPipelineHookListAndInfo(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.13/pipeline-hooks", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+	err = pipelinehook.Create(&pipelinehook.Hook{Name: "scan", Kind: pipelinehook.KindExec, Command: "scan", Stages: []string{pipelinehook.StagePreBuild}})
+	c.Assert(err, check.IsNil)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var hooks []pipelinehook.Hook
+	err = json.Unmarshal(recorder.Body.Bytes(), &hooks)
+	c.Assert(err, check.IsNil)
+	c.Assert(hooks, check.HasLen, 1)
+	c.Assert(hooks[0].Command, check.Equals, "scan")
+	request, err = http.NewRequest("GET", "/1.13/pipeline-hooks/scan", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	request, err = http.NewRequest("GET", "/1.13/pipeline-hooks/other", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestPipelineHookUpdate(c *check.C) {
+	err := pipelinehook.Create(&pipelinehook.Hook{Name: "scan", Kind: pipelinehook.KindExec, Command: "scan", Stages: []string{pipelinehook.StagePreBuild}})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("kind=exec&command=scan2&stages.0=post-build")
+	request, err := http.NewRequest("PUT", "/1.13/pipeline-hooks/scan", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	hook, err := pipelinehook.Get("scan")
+	c.Assert(err, check.IsNil)
+	c.Assert(hook.Command, check.Equals, "scan2")
+	c.Assert(hook.Stages, check.DeepEquals, []string{pipelinehook.StagePostBuild})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypePipelineHook, Value: "scan"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pipeline-hook.update",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestPipelineHookDelete(c *check.C) {
+	err := pipelinehook.Create(&pipelinehook.Hook{Name: "scan", Kind: pipelinehook.KindExec, Command: "scan", Stages: []string{pipelinehook.StagePreBuild}})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.13/pipeline-hooks/scan", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	_, err = pipelinehook.Get("scan")
+	c.Assert(err, check.Equals, pipelinehook.ErrHookNotFound)
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+func placementConstraintsFromRequest(r *http.Request) ([]appTypes.PlacementConstraint, error) {
+	exprs, _ := InputValues(r, "constraint")
+	var constraints []appTypes.PlacementConstraint
+	for _, expr := range exprs {
+		if expr == "" {
+			continue
+		}
+		c, err := appTypes.ParsePlacementConstraint(expr)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints, nil
+}
+
+// title: app placement constraints update
+// path: /apps/{app}/placement
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Placement constraints updated
+//   400: Invalid placement constraints
+//   401: Unauthorized
+//   404: App not found
+func placementUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdatePlacement, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	constraints, err := placementConstraintsFromRequest(r)
+	if v, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(a.Name),
+		Kind:       permission.PermAppUpdatePlacement,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = a.SetPlacementConstraints(constraints)
+	if v, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+// title: app placement nodes preview
+// path: /apps/{app}/placement/nodes
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No matching nodes
+//   400: Invalid placement constraints
+//   401: Unauthorized
+//   404: App not found
+func placementNodes(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppRead, contextsForApp(&a)...) &&
+		permission.Check(t, permission.PermNodeRead, permission.Context(permTypes.CtxPool, a.Pool))
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	constraints, err := placementConstraintsFromRequest(r)
+	if v, ok := err.(*tsuruErrors.ValidationError); ok {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	if len(constraints) == 0 {
+		constraints = a.Placement
+	}
+	nodes, err := a.PlacementNodes(constraints)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	specs := make([]provision.NodeSpec, len(nodes))
+	for i, n := range nodes {
+		specs[i] = provision.NodeToSpec(n)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(specs)
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) addPlacementNodes(c *check.C, pool string) {
+	err := s.provisioner.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address:  "http://n1:2375",
+		Pool:     pool,
+		Metadata: map[string]string{"disk": "ssd", "zone": "a"},
+	})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address:  "http://n2:2375",
+		Pool:     pool,
+		Metadata: map[string]string{"disk": "ssd", "zone": "c"},
+	})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestPlacementUpdate(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.addPlacementNodes(c, a.Pool)
+	body := strings.NewReader("constraint=disk%3Dssd&constraint=zone+in+%5Ba%2Cb%5D")
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/placement", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Placement, check.DeepEquals, []appTypes.PlacementConstraint{
+		{Key: "disk", Operator: appTypes.PlacementOpIn, Values: []string{"ssd"}},
+		{Key: "zone", Operator: appTypes.PlacementOpIn, Values: []string{"a", "b"}},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: appTarget(a.Name),
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.placement",
+		StartCustomData: []map[string]interface{}{
+			{"name": "constraint", "value": []interface{}{"disk=ssd", "zone in [a,b]"}},
+			{"name": ":app", "value": "myapp"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestPlacementUpdateInvalid(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.addPlacementNodes(c, a.Pool)
+	for _, expr := range []string{"disk%3D", "disk%3Dhdd"} {
+		body := strings.NewReader("constraint=" + expr)
+		request, err := http.NewRequest("PUT", "/1.13/apps/myapp/placement", body)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Assert(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("expr: %s", expr))
+	}
+}
+
+func (s *S) TestPlacementUpdateUnauthorized(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader("constraint=disk%3Dssd")
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/placement", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestPlacementNodes(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	s.addPlacementNodes(c, a.Pool)
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/placement/nodes?constraint=zone+notin+%5Bc%5D", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var nodes []provision.NodeSpec
+	err = json.Unmarshal(recorder.Body.Bytes(), &nodes)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.HasLen, 1)
+	c.Assert(nodes[0].Address, check.Equals, "http://n1:2375")
+	request, err = http.NewRequest("GET", "/1.13/apps/myapp/placement/nodes?constraint=gpu", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
@@ -36,13 +36,21 @@ func addPlan(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	isDefault, _ := strconv.ParseBool(InputValue(r, "default"))
 	memory := getSize(InputValue(r, "memory"))
 	swap := getSize(InputValue(r, "swap"))
+	cpuQuota, _ := strconv.ParseInt(InputValue(r, "cpuquota"), 10, 64)
+	cpuPeriod, _ := strconv.ParseInt(InputValue(r, "cpuperiod"), 10, 64)
+	blkioWeight, _ := strconv.ParseInt(InputValue(r, "blkioweight"), 10, 64)
+	pidsLimit, _ := strconv.ParseInt(InputValue(r, "pidslimit"), 10, 64)
 	plan := appTypes.Plan{
-		Name:     InputValue(r, "name"),
-		Memory:   memory,
-		Swap:     swap,
-		CpuShare: cpuShare,
-		CPUMilli: cpuMilli,
-		Default:  isDefault,
+		Name:        InputValue(r, "name"),
+		Memory:      memory,
+		Swap:        swap,
+		CpuShare:    cpuShare,
+		CPUMilli:    cpuMilli,
+		CPUQuota:    cpuQuota,
+		CPUPeriod:   cpuPeriod,
+		BlkioWeight: blkioWeight,
+		PidsLimit:   pidsLimit,
+		Default:     isDefault,
 	}
 	allowed := permission.Check(t, permission.PermPlanCreate)
 	if !allowed {
@@ -67,7 +75,7 @@ func addPlan(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 			Message: err.Error(),
 		}
 	}
-	if err == appTypes.ErrLimitOfMemory || err == appTypes.ErrLimitOfCpuShare {
+	if isPlanValidationError(err) {
 		return &errors.HTTP{
 			Code:    http.StatusBadRequest,
 			Message: err.Error(),
@@ -80,6 +88,91 @@ func addPlan(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	return err
 }
 
+// title: plan update
+// path: /plans/{planname}
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   200: Plan updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Plan not found
+func updatePlan(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	if !permission.Check(t, permission.PermPlanUpdate) {
+		return permission.ErrUnauthorized
+	}
+	planName := r.URL.Query().Get(":planname")
+	plan, err := servicemanager.Plan.FindByName(ctx, planName)
+	if err == appTypes.ErrPlanNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	for _, field := range []struct {
+		name  string
+		value *int64
+	}{
+		{"memory", &plan.Memory},
+		{"swap", &plan.Swap},
+		{"cpuquota", &plan.CPUQuota},
+		{"cpuperiod", &plan.CPUPeriod},
+		{"blkioweight", &plan.BlkioWeight},
+		{"pidslimit", &plan.PidsLimit},
+	} {
+		if values, ok := InputValues(r, field.name); ok && len(values) > 0 {
+			*field.value = getSize(values[0])
+		}
+	}
+	for _, field := range []struct {
+		name  string
+		value *int
+	}{
+		{"cpushare", &plan.CpuShare},
+		{"cpumilli", &plan.CPUMilli},
+	} {
+		if values, ok := InputValues(r, field.name); ok && len(values) > 0 {
+			*field.value, _ = strconv.Atoi(values[0])
+		}
+	}
+	if values, ok := InputValues(r, "default"); ok && len(values) > 0 {
+		plan.Default, _ = strconv.ParseBool(values[0])
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePlan, Value: planName},
+		Kind:       permission.PermPlanUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPlanReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = servicemanager.Plan.Update(ctx, *plan)
+	if isPlanValidationError(err) {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err == appTypes.ErrPlanNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(plan)
+}
+
+func isPlanValidationError(err error) bool {
+	if _, ok := err.(appTypes.PlanValidationError); ok {
+		return true
+	}
+	return err == appTypes.ErrLimitOfMemory || err == appTypes.ErrLimitOfCpuShare
+}
+
 // title: plan list
 // path: /plans
 // method: GET
@@ -233,6 +233,64 @@ func (s *S) TestPlanList(c *check.C) {
 	c.Assert(plans, check.DeepEquals, expected)
 }
 
+func (s *S) TestPlanUpdate(c *check.C) {
+	s.plan = appTypes.Plan{Name: "plan1", Memory: 1024, CPUMilli: 500}
+	var updated appTypes.Plan
+	s.mockService.Plan.OnUpdate = func(p appTypes.Plan) error {
+		updated = p
+		return nil
+	}
+	body := strings.NewReader("cpuquota=50000&cpuperiod=100000&blkioweight=500&pidslimit=256")
+	request, err := http.NewRequest("PUT", "/1.13/plans/plan1", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(updated, check.DeepEquals, appTypes.Plan{
+		Name:        "plan1",
+		Memory:      1024,
+		CPUMilli:    500,
+		CPUQuota:    50000,
+		CPUPeriod:   100000,
+		BlkioWeight: 500,
+		PidsLimit:   256,
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypePlan, Value: "plan1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "plan.update",
+		StartCustomData: []map[string]interface{}{
+			{"name": "cpuquota", "value": "50000"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestPlanUpdateInvalid(c *check.C) {
+	s.plan = appTypes.Plan{Name: "plan1"}
+	s.mockService.Plan.OnUpdate = func(p appTypes.Plan) error {
+		return appTypes.PlanValidationError{Field: "blkioweight"}
+	}
+	request, err := http.NewRequest("PUT", "/1.13/plans/plan1", strings.NewReader("blkioweight=1"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestPlanUpdateNotFound(c *check.C) {
+	request, err := http.NewRequest("PUT", "/1.13/plans/unknown", strings.NewReader("pidslimit=10"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
+
 func (s *S) TestPlanRemove(c *check.C) {
 	recorder := httptest.NewRecorder()
 	s.mockService.Plan.OnRemove = func(name string) error {
@@ -16,6 +16,7 @@ import (
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/registry"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 )
 
@@ -55,6 +56,21 @@ func getPoolHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error
 //   204: No content
 //   401: Unauthorized
 func poolList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	poolList, err := readablePools(r.Context(), t)
+	if err != nil {
+		return err
+	}
+	if len(poolList) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(poolList)
+}
+
+// readablePools returns the pools where the token is allowed to create apps
+// or read the pool.
+func readablePools(ctx context.Context, t auth.Token) ([]pool.Pool, error) {
 	var teams, poolNames []string
 	isGlobal := false
 	contexts := permission.ContextsForPermission(t, permission.PermAppCreate)
@@ -74,19 +90,19 @@ func poolList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	var pools []pool.Pool
 	var err error
 	if isGlobal {
-		pools, err = pool.ListAllPools(context.TODO())
+		pools, err = pool.ListAllPools(ctx)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	} else {
-		pools, err = pool.ListPossiblePools(context.TODO(), teams)
+		pools, err = pool.ListPossiblePools(ctx, teams)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if len(poolNames) > 0 {
-			namedPools, err := pool.ListPools(context.TODO(), poolNames...)
+			namedPools, err := pool.ListPools(ctx, poolNames...)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			pools = append(pools, namedPools...)
 		}
@@ -100,12 +116,7 @@ func poolList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 		poolList = append(poolList, p)
 		poolsMap[p.Name] = struct{}{}
 	}
-	if len(poolList) == 0 {
-		w.WriteHeader(http.StatusNoContent)
-		return nil
-	}
-	w.Header().Set("Content-Type", "application/json")
-	return json.NewEncoder(w).Encode(poolList)
+	return poolList, nil
 }
 
 // title: pool create
@@ -204,6 +215,13 @@ func removePoolHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 	if err == pool.ErrPoolNotFound {
 		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
 	}
+	if err != nil {
+		return err
+	}
+	err = registry.RemovePoolRegistry(poolName)
+	if err == registry.ErrPoolRegistryNotFound {
+		return nil
+	}
 	return err
 }
 
@@ -359,6 +377,7 @@ func poolConstraintList(w http.ResponseWriter, r *http.Request, t auth.Token) er
 // consume: application/x-www-form-urlencoded
 // responses:
 //   200: OK
+//   400: Invalid constraint
 //   401: Unauthorized
 func poolConstraintSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
 	if !permission.Check(t, permission.PermPoolUpdateConstraintsSet) {
@@ -392,7 +411,46 @@ func poolConstraintSet(w http.ResponseWriter, r *http.Request, t auth.Token) (er
 		append, _ = strconv.ParseBool(appendStr)
 	}
 	if append {
-		return pool.AppendPoolConstraint(&poolConstraint)
+		err = pool.AppendPoolConstraint(&poolConstraint)
+	} else {
+		err = pool.SetPoolConstraint(&poolConstraint)
+	}
+	if v, ok := err.(*terrors.ValidationError); ok {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+// title: test an app spec against pool constraints
+// path: /pools/{name}/constraints/test
+// method: POST
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Pool not found
+func poolConstraintTest(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(t, permission.PermPoolReadConstraints, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
 	}
-	return pool.SetPoolConstraint(&poolConstraint)
+	var spec pool.ConstraintSpec
+	err := ParseInput(r, &spec)
+	if err != nil {
+		return err
+	}
+	p, err := pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	results, err := p.TestConstraints(spec)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(results)
 }
@@ -0,0 +1,169 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	terrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+type poolFreezeResponse struct {
+	pool.PoolFreeze
+	Status pool.FreezeStatus `json:"status"`
+}
+
+// title: pool freeze info
+// path: /pools/{name}/freeze
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Pool not found
+func poolFreezeInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(t, permission.PermPoolReadFreeze, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	_, err := pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	freeze, err := pool.GetPoolFreeze(poolName)
+	if err == pool.ErrPoolFreezeNotFound {
+		freeze, err = &pool.PoolFreeze{Pool: poolName, Windows: []pool.FreezeWindow{}}, nil
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(poolFreezeResponse{PoolFreeze: *freeze, Status: freeze.Status(time.Now())})
+}
+
+// title: pool freeze set
+// path: /pools/{name}/freeze
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Freeze windows set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Pool not found
+func poolFreezeSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	poolName := r.URL.Query().Get(":name")
+	poolCtx := permission.Context(permTypes.CtxPool, poolName)
+	if !permission.Check(t, permission.PermPoolUpdateFreezeSet, poolCtx) {
+		return permission.ErrUnauthorized
+	}
+	freeze := pool.PoolFreeze{
+		Pool:     poolName,
+		Timezone: InputValue(r, "timezone"),
+		Reason:   InputValue(r, "reason"),
+	}
+	windows, _ := InputValues(r, "window")
+	for _, window := range windows {
+		freeze.Windows = append(freeze.Windows, pool.FreezeWindow(window))
+	}
+	_, err = pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateFreezeSet,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, poolCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pool.SetPoolFreeze(freeze)
+	if v, ok := err.(*terrors.ValidationError); ok {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: v.Message}
+	}
+	return err
+}
+
+// title: pool freeze remove
+// path: /pools/{name}/freeze
+// method: DELETE
+// responses:
+//   200: Freeze windows removed
+//   401: Unauthorized
+//   404: Freeze windows not found
+func poolFreezeRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	poolName := r.URL.Query().Get(":name")
+	poolCtx := permission.Context(permTypes.CtxPool, poolName)
+	if !permission.Check(t, permission.PermPoolUpdateFreezeRemove, poolCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateFreezeRemove,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, poolCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pool.RemovePoolFreeze(poolName)
+	if err == pool.ErrPoolFreezeNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// checkPoolFreeze rejects operations on apps whose pool is inside a freeze
+// window, unless the user is allowed to override it. Rejected attempts are
+// recorded as failed events of the given kind.
+func checkPoolFreeze(r *http.Request, t auth.Token, a *app.App, kind *permission.PermissionScheme) error {
+	errFreeze := pool.CheckFreeze(a.Pool, time.Now())
+	if errFreeze == nil {
+		return nil
+	}
+	if _, ok := errFreeze.(pool.ErrPoolFrozen); !ok {
+		return errFreeze
+	}
+	if permission.Check(t, permission.PermAppAdminFreezeOverride, contextsForApp(a)...) {
+		return nil
+	}
+	evt, err := event.New(&event.Opts{
+		Target: appTarget(a.Name),
+		ExtraTargets: []event.ExtraTarget{
+			{Target: event.Target{Type: event.TargetTypePool, Value: a.Pool}},
+		},
+		Kind:        kind,
+		Owner:       t,
+		RemoteAddr:  r.RemoteAddr,
+		CustomData:  event.FormToCustomData(InputFields(r)),
+		Allowed:     event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+		DisableLock: true,
+	})
+	if err == nil {
+		evt.Done(errFreeze)
+	}
+	return &terrors.HTTP{Code: http.StatusConflict, Message: errFreeze.Error()}
+}
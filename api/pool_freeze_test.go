@@ -0,0 +1,140 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision/pool"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+var alwaysFrozen = []pool.FreezeWindow{"Sun 00:00-Wed 12:00", "Wed 12:00-Sun 00:00"}
+
+func (s *S) TestPoolFreezeSet(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("window=Fri+18:00-Mon+06:00&timezone=America/Sao_Paulo&reason=weekend")
+	req, err := http.NewRequest(http.MethodPut, "/pools/pool1/freeze", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	freeze, err := pool.GetPoolFreeze("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(freeze, check.DeepEquals, &pool.PoolFreeze{
+		Pool:     "pool1",
+		Windows:  []pool.FreezeWindow{"Fri 18:00-Mon 06:00"},
+		Timezone: "America/Sao_Paulo",
+		Reason:   "weekend",
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypePool, Value: "pool1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.freeze.set",
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestPoolFreezeSetInvalidWindow(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodPut, "/pools/pool1/freeze", strings.NewReader("window=Fri+18:00"))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestPoolFreezeInfo(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolFreeze(pool.PoolFreeze{Pool: "pool1", Windows: alwaysFrozen, Reason: "migration"})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodGet, "/pools/pool1/freeze", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var info poolFreezeResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &info)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Windows, check.DeepEquals, alwaysFrozen)
+	c.Assert(info.Status.Frozen, check.Equals, true)
+	c.Assert(info.Status.Reason, check.Equals, "migration")
+	c.Assert(info.Status.Until, check.NotNil)
+}
+
+func (s *S) TestRestartHandlerPoolFrozen(c *check.C) {
+	a := app.App{Name: "stress", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolFreeze(pool.PoolFreeze{Pool: a.Pool, Windows: alwaysFrozen})
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "dev", permission.Permission{
+		Scheme:  permission.PermAppUpdateRestart,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req, err := http.NewRequest(http.MethodPost, "/apps/stress/restart", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusConflict)
+	c.Assert(rec.Body.String(), check.Matches, `pool ".*" is frozen until .*\n`)
+	c.Assert(eventtest.EventDesc{
+		Target:       appTarget(a.Name),
+		Owner:        token.GetUserName(),
+		Kind:         "app.update.restart",
+		ErrorMatches: `pool ".*" is frozen until .*`,
+	}, eventtest.HasEvent)
+	req, err = http.NewRequest(http.MethodPost, "/apps/stress/restart", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+}
+
+func (s *S) TestDeployGroupPoolFrozen(c *check.C) {
+	a := app.App{Name: "stress", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolFreeze(pool.PoolFreeze{Pool: a.Pool, Windows: alwaysFrozen})
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "dev", permission.Permission{
+		Scheme:  permission.PermAppDeploy,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	body := `{"apps": [{"name": "stress", "image": "tsuru/stress"}]}`
+	req, err := http.NewRequest(http.MethodPost, "/deploy/group", strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusConflict)
+	c.Assert(rec.Body.String(), check.Matches, `pool ".*" is frozen until .*\n`)
+	c.Assert(eventtest.EventDesc{
+		Target:       appTarget(a.Name),
+		Owner:        token.GetUserName(),
+		Kind:         "app.deploy",
+		ErrorMatches: `pool ".*" is frozen until .*`,
+	}, eventtest.HasEvent)
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	terrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: pool plans info
+// path: /pools/{name}/plans
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Pool not found
+func poolPlansInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	if !permission.Check(t, permission.PermPoolReadConstraints) {
+		return permission.ErrUnauthorized
+	}
+	plans, err := pool.GetPoolPlans(r.Context(), r.URL.Query().Get(":name"))
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(plans)
+}
+
+// title: pool plans set
+// path: /pools/{name}/plans
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Plans set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Pool not found
+func poolPlansSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermPoolUpdateConstraintsSet) {
+		return permission.ErrUnauthorized
+	}
+	poolName := r.URL.Query().Get(":name")
+	plans, _ := InputValues(r, "plans")
+	defaultPlan := InputValue(r, "default")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateConstraintsSet,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pool.SetPoolPlans(r.Context(), poolName, plans, defaultPlan)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// title: pool plans remove
+// path: /pools/{name}/plans
+// method: DELETE
+// responses:
+//   200: Plans restrictions removed
+//   401: Unauthorized
+//   404: Pool not found
+func poolPlansRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermPoolUpdateConstraintsSet) {
+		return permission.ErrUnauthorized
+	}
+	poolName := r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateConstraintsSet,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pool.RemovePoolPlans(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPoolPlansSet(c *check.C) {
+	s.plan = appTypes.Plan{Name: "large", Memory: 4194304}
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("plans=default-plan&plans=large&default=large")
+	req, err := http.NewRequest(http.MethodPut, "/1.13/pools/pool1/plans", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypePool, Value: "pool1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.constraints.set",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": "pool1"},
+			{"name": "plans", "value": []interface{}{"default-plan", "large"}},
+			{"name": "default", "value": "large"},
+		},
+	}, eventtest.HasEvent)
+	req, err = http.NewRequest(http.MethodGet, "/1.13/pools/pool1/plans", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var plans pool.PoolPlans
+	err = json.Unmarshal(rec.Body.Bytes(), &plans)
+	c.Assert(err, check.IsNil)
+	c.Assert(plans, check.DeepEquals, pool.PoolPlans{Pool: "pool1", Plans: []string{"default-plan", "large"}, Default: "large"})
+}
+
+func (s *S) TestPoolPlansSetInvalidDefault(c *check.C) {
+	s.plan = appTypes.Plan{Name: "large", Memory: 4194304}
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("plans=default-plan&default=large")
+	req, err := http.NewRequest(http.MethodPut, "/1.13/pools/pool1/plans", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Equals, "default plan \"large\" must be one of the allowed plans: default-plan\n")
+}
+
+func (s *S) TestPoolPlansRemove(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = pool.SetPoolPlans(context.TODO(), "pool1", []string{"default-plan"}, "default-plan")
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodDelete, "/1.13/pools/pool1/plans", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	p, err := pool.GetPoolByName(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.DefaultPlan, check.Equals, "")
+}
+
+func (s *S) TestPoolPlansSetUnauthorized(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermPoolReadConstraints,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	req, err := http.NewRequest(http.MethodPut, "/1.13/pools/pool1/plans", strings.NewReader("plans=default-plan"))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusForbidden)
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	terrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/registry"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: pool registry info
+// path: /pools/{name}/registry
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Registry not found
+func poolRegistryInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(t, permission.PermPoolReadRegistry, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	reg, err := registry.GetPoolRegistry(poolName)
+	if err == registry.ErrPoolRegistryNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(reg)
+}
+
+// title: pool registry set
+// path: /pools/{name}/registry
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Registry set
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Pool not found
+//   409: Registry conflicts with another registry
+func poolRegistrySet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	poolName := r.URL.Query().Get(":name")
+	poolCtx := permission.Context(permTypes.CtxPool, poolName)
+	if !permission.Check(t, permission.PermPoolUpdateRegistrySet, poolCtx) {
+		return permission.ErrUnauthorized
+	}
+	var reg registry.PoolRegistry
+	err = ParseInput(r, &reg)
+	if err != nil {
+		return err
+	}
+	_, err = pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateRegistrySet,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r, "password")),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, poolCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = registry.SetPoolRegistry(poolName, reg)
+	if err == registry.ErrPoolRegistryAddressMissing {
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if _, ok := err.(*registry.PoolRegistryConflictError); ok {
+		return &terrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
+
+// title: pool registry remove
+// path: /pools/{name}/registry
+// method: DELETE
+// responses:
+//   200: Registry removed
+//   401: Unauthorized
+//   404: Registry not found
+func poolRegistryRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	poolName := r.URL.Query().Get(":name")
+	poolCtx := permission.Context(permTypes.CtxPool, poolName)
+	if !permission.Check(t, permission.PermPoolUpdateRegistryRemove, poolCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateRegistryRemove,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, poolCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = registry.RemovePoolRegistry(poolName)
+	if err == registry.ErrPoolRegistryNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
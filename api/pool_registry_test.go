@@ -0,0 +1,150 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/registry"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPoolRegistrySet(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("address=registry.example.com&namespace=team-a&username=user&password=secret")
+	req, err := http.NewRequest(http.MethodPut, "/pools/pool1/registry", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	reg, err := registry.GetPoolRegistry("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(reg, check.DeepEquals, &registry.PoolRegistry{
+		Pool:      "pool1",
+		Address:   "registry.example.com",
+		Namespace: "team-a",
+		Username:  "user",
+		Password:  "secret",
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypePool, Value: "pool1"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "pool.update.registry.set",
+		StartCustomData: []map[string]interface{}{
+			{"name": ":name", "value": "pool1"},
+			{"name": "address", "value": "registry.example.com"},
+			{"name": "namespace", "value": "team-a"},
+			{"name": "username", "value": "user"},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *S) TestPoolRegistrySetKeepsPassword(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = registry.SetPoolRegistry("pool1", registry.PoolRegistry{Address: "registry.example.com", Username: "user", Password: "secret"})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("address=registry.example.com&namespace=team-b&username=user")
+	req, err := http.NewRequest(http.MethodPut, "/pools/pool1/registry", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	reg, err := registry.GetPoolRegistry("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(reg.Namespace, check.Equals, "team-b")
+	c.Assert(reg.Password, check.Equals, "secret")
+}
+
+func (s *S) TestPoolRegistrySetPoolNotFound(c *check.C) {
+	body := strings.NewReader("address=registry.example.com")
+	req, err := http.NewRequest(http.MethodPut, "/pools/unknown/registry", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
+func (s *S) TestPoolRegistrySetMissingAddress(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("username=user")
+	req, err := http.NewRequest(http.MethodPut, "/pools/pool1/registry", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Equals, registry.ErrPoolRegistryAddressMissing.Error()+"\n")
+}
+
+func (s *S) TestPoolRegistrySetConflict(c *check.C) {
+	for _, name := range []string{"pool1", "pool2"} {
+		err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: name})
+		c.Assert(err, check.IsNil)
+	}
+	err := registry.SetPoolRegistry("pool1", registry.PoolRegistry{Address: "registry.example.com", Namespace: "team-a"})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("address=registry.example.com")
+	req, err := http.NewRequest(http.MethodPut, "/pools/pool2/registry", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusConflict)
+	c.Assert(rec.Body.String(), check.Equals, "registry address and namespace overlap with the images of the registry of pool \"pool1\"\n")
+}
+
+func (s *S) TestPoolRegistryInfoHidesPassword(c *check.C) {
+	err := registry.SetPoolRegistry("pool1", registry.PoolRegistry{Address: "registry.example.com", Username: "user", Password: "secret"})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodGet, "/pools/pool1/registry", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Body.String(), check.Not(check.Matches), "(?s).*secret.*")
+	var result map[string]string
+	err = json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, map[string]string{
+		"pool":     "pool1",
+		"address":  "registry.example.com",
+		"username": "user",
+	})
+}
+
+func (s *S) TestPoolRegistryRemove(c *check.C) {
+	err := registry.SetPoolRegistry("pool1", registry.PoolRegistry{Address: "registry.example.com"})
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodDelete, "/pools/pool1/registry", nil)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	_, err = registry.GetPoolRegistry("pool1")
+	c.Assert(err, check.Equals, registry.ErrPoolRegistryNotFound)
+	rec = httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
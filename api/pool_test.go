@@ -769,6 +769,58 @@ func (s *S) TestPoolConstraintSetAppend(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestPoolConstraintSetInvalidExpression(c *check.C) {
+	params := pool.PoolConstraint{
+		PoolExpr: "*",
+		Field:    pool.ConstraintTypeExpression,
+		Values:   []string{"team in (a"},
+	}
+	v, err := form.EncodeToValues(&params)
+	c.Assert(err, check.IsNil)
+	req, err := http.NewRequest(http.MethodPut, "/1.3/constraints", strings.NewReader(v.Encode()))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Equals, "invalid constraint expression \"team in (a\": unexpected end of expression\n")
+}
+
+func (s *S) TestPoolConstraintTest(c *check.C) {
+	err := pool.SetPoolConstraint(&pool.PoolConstraint{PoolExpr: "test1", Field: pool.ConstraintTypeExpression, Values: []string{
+		"app != legacy-*",
+		"tag in (critical, standard)",
+	}})
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("name=legacy-billing&tags.0=standard")
+	req, err := http.NewRequest(http.MethodPost, "/1.13/pools/test1/constraints/test", body)
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), check.Equals, "application/json")
+	var results []pool.ConstraintResult
+	err = json.NewDecoder(rec.Body).Decode(&results)
+	c.Assert(err, check.IsNil)
+	c.Assert(results, check.DeepEquals, []pool.ConstraintResult{
+		{Field: pool.ConstraintTypeExpression, Expression: "app != legacy-*", Satisfied: false},
+		{Field: pool.ConstraintTypeExpression, Expression: "tag in (critical, standard)", Satisfied: true},
+	})
+}
+
+func (s *S) TestPoolConstraintTestPoolNotFound(c *check.C) {
+	req, err := http.NewRequest(http.MethodPost, "/1.13/pools/unknown/constraints/test", strings.NewReader("name=myapp"))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	c.Assert(rec.Code, check.Equals, http.StatusNotFound)
+}
+
 func (s *S) TestPoolConstraintSetRequiresPoolExpr(c *check.C) {
 	req, err := http.NewRequest(http.MethodPut, "/constraints", bytes.NewBufferString(""))
 	c.Assert(err, check.IsNil)
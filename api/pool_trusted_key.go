@@ -0,0 +1,135 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app/imagepolicy"
+	"github.com/tsuru/tsuru/auth"
+	terrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/registry"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// title: pool trusted key list
+// path: /pools/{name}/trusted-keys
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: Pool not found
+func poolTrustedKeyList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(t, permission.PermPoolReadTrustedKeys, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	_, err := pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	keys, err := imagepolicy.TrustedKeys(poolName)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(keys)
+}
+
+// title: pool trusted key add
+// path: /pools/{name}/trusted-keys
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: Trusted key added
+//   400: Invalid key
+//   401: Unauthorized
+//   404: Pool not found
+//   409: Trusted key already exists
+func poolTrustedKeyAdd(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	poolName := r.URL.Query().Get(":name")
+	poolCtx := permission.Context(permTypes.CtxPool, poolName)
+	if !permission.Check(t, permission.PermPoolUpdateTrustedKeysAdd, poolCtx) {
+		return permission.ErrUnauthorized
+	}
+	var key imagepolicy.TrustedKey
+	err = ParseInput(r, &key)
+	if err != nil {
+		return err
+	}
+	_, err = pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateTrustedKeysAdd,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, poolCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = imagepolicy.AddTrustedKey(poolName, key)
+	switch err {
+	case imagepolicy.ErrTrustedKeyAlreadyExists:
+		return &terrors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	case imagepolicy.ErrTrustedKeyNameRequired, registry.ErrInvalidPublicKeyBlock, registry.ErrUnsupportedPublicKey:
+		return &terrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return err
+}
+
+// title: pool trusted key remove
+// path: /pools/{name}/trusted-keys/{key}
+// method: DELETE
+// responses:
+//   200: Trusted key removed
+//   401: Unauthorized
+//   404: Trusted key not found
+func poolTrustedKeyRemove(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	poolName := r.URL.Query().Get(":name")
+	keyName := r.URL.Query().Get(":key")
+	poolCtx := permission.Context(permTypes.CtxPool, poolName)
+	if !permission.Check(t, permission.PermPoolUpdateTrustedKeysRemove, poolCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdateTrustedKeysRemove,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, poolCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = imagepolicy.RemoveTrustedKey(poolName, keyName)
+	if err == imagepolicy.ErrTrustedKeyNotFound {
+		return &terrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	return err
+}
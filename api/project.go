@@ -0,0 +1,304 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/project"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/permission"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"github.com/tsuru/tsuru/types/quota"
+)
+
+type projectInfoResponse struct {
+	*project.Project
+	Apps       []string `json:"apps"`
+	UnitsInUse int      `json:"unitsInUse"`
+}
+
+// title: project list
+// path: /projects
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+func projectList(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	projects, err := project.List(&project.Filter{Team: r.URL.Query().Get("team")})
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(projects)
+}
+
+// title: project info
+// path: /projects/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   404: Not found
+func projectInfo(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	p, err := project.Get(r.URL.Query().Get(":name"))
+	if err == project.ErrProjectNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	apps, err := project.Apps(p.Name)
+	if err != nil {
+		return err
+	}
+	inUse, err := p.UnitsInUse(r.Context())
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(projectInfoResponse{Project: p, Apps: apps, UnitsInUse: inUse})
+}
+
+// title: project create
+// path: /projects
+// method: POST
+// consume: application/x-www-form-urlencoded
+// responses:
+//   201: Project created
+//   400: Invalid data
+//   401: Unauthorized
+//   409: Project already exists
+func projectCreate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermProjectCreate) {
+		return permission.ErrUnauthorized
+	}
+	var p project.Project
+	err = ParseInput(r, &p)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeProject, Value: p.Name},
+		Kind:       permission.PermProjectCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermProjectReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = project.Create(r.Context(), p)
+	if err == project.ErrProjectAlreadyExists {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// title: project update
+// path: /projects/{name}
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: Project updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Project not found
+func projectUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermProjectUpdate) {
+		return permission.ErrUnauthorized
+	}
+	var p project.Project
+	err = ParseInput(r, &p)
+	if err != nil {
+		return err
+	}
+	p.Name = r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeProject, Value: p.Name},
+		Kind:       permission.PermProjectUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermProjectReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = project.Update(r.Context(), p)
+	if err == project.ErrProjectNotFound {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	names, err := project.Apps(p.Name)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	var failed []string
+	for _, name := range names {
+		a, errGet := app.GetByName(r.Context(), name)
+		if errGet == appTypes.ErrAppNotFound {
+			continue
+		}
+		if errGet != nil {
+			return errGet
+		}
+		fmt.Fprintf(evt, "---- Syncing app %q ----\n", a.Name)
+		if errSync := projectSyncApp(r, t, &p, a, writer); errSync != nil {
+			fmt.Fprintf(evt, "Unable to sync app %q: %s\n", a.Name, errSync)
+			failed = append(failed, a.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("apps not synced: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func projectSyncApp(r *http.Request, t auth.Token, p *project.Project, a *app.App, w *tsuruIo.SimpleJsonMessageEncoderWriter) (err error) {
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(a.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: event.Target{Type: event.TargetTypeProject, Value: p.Name}}},
+		Kind:         permission.PermProjectUpdate,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	evt.SetLogWriter(w)
+	return p.SyncApp(r.Context(), a, evt)
+}
+
+// title: project delete
+// path: /projects/{name}
+// method: DELETE
+// responses:
+//   200: Project removed
+//   401: Unauthorized
+//   404: Project not found
+//   409: Project has apps
+func projectDelete(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	if !permission.Check(t, permission.PermProjectDelete) {
+		return permission.ErrUnauthorized
+	}
+	name := r.URL.Query().Get(":name")
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeProject, Value: name},
+		Kind:       permission.PermProjectDelete,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermProjectReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = project.Remove(name)
+	switch err {
+	case project.ErrProjectNotFound:
+		return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	case project.ErrProjectInUse:
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	return err
+}
+
+// title: app project set
+// path: /apps/{app}/project
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: OK
+//   400: Invalid data
+//   401: Unauthorized
+//   403: Project quota exceeded
+//   404: App or project not found
+func appProjectSet(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermAppUpdateProject, contextsForApp(&a)...) {
+		return permission.ErrUnauthorized
+	}
+	name := InputValue(r, "project")
+	var p *project.Project
+	if name != "" {
+		p, err = project.Get(name)
+		if err == project.ErrProjectNotFound {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	var extraTargets []event.ExtraTarget
+	for _, target := range []string{a.Project, name} {
+		if target != "" {
+			extraTargets = append(extraTargets, event.ExtraTarget{Target: event.Target{Type: event.TargetTypeProject, Value: target}})
+		}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:       appTarget(a.Name),
+		ExtraTargets: extraTargets,
+		Kind:         permission.PermAppUpdateProject,
+		Owner:        t,
+		RemoteAddr:   r.RemoteAddr,
+		CustomData:   event.FormToCustomData(InputFields(r)),
+		Allowed:      event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	if p == nil {
+		return project.RemoveApp(ctx, &a, evt)
+	}
+	err = p.AddApp(ctx, &a, evt)
+	if _, ok := err.(*quota.QuotaExceededError); ok {
+		return &errors.HTTP{Code: http.StatusForbidden, Message: fmt.Sprintf("project %q: %s", p.Name, err)}
+	}
+	return err
+}
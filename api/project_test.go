@@ -0,0 +1,153 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/project"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/eventtest"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestProjectCreate(c *check.C) {
+	body := strings.NewReader("name=checkout&teams.0=" + s.team.Name + "&env.LOG_LEVEL=info&quota.apps=3")
+	request, err := http.NewRequest("POST", "/1.13/projects", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", recorder.Body.String()))
+	p, err := project.Get("checkout")
+	c.Assert(err, check.IsNil)
+	c.Assert(p, check.DeepEquals, &project.Project{
+		Name:  "checkout",
+		Teams: []string{s.team.Name},
+		Env:   map[string]string{"LOG_LEVEL": "info"},
+		Quota: project.Quota{Apps: 3},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeProject, Value: "checkout"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "project.create",
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("POST", "/1.13/projects", strings.NewReader("name=checkout"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
+
+func (s *S) TestProjectCreateUnauthorized(c *check.C) {
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppCreate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("POST", "/1.13/projects", strings.NewReader("name=checkout"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestAppProjectSet(c *check.C) {
+	err := project.Create(context.TODO(), project.Project{Name: "checkout", Env: map[string]string{"LOG_LEVEL": "info"}})
+	c.Assert(err, check.IsNil)
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/project", strings.NewReader("project=checkout"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	dbApp, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Project, check.Equals, "checkout")
+	c.Assert(dbApp.Env["LOG_LEVEL"].Value, check.Equals, "info")
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: event.TargetTypeProject, Value: "checkout"},
+		Owner:  s.token.GetUserName(),
+		Kind:   "app.update.project",
+	}, eventtest.HasEvent)
+	request, err = http.NewRequest("GET", "/1.13/projects/checkout", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var info struct {
+		Name string
+		Apps []string
+	}
+	err = json.NewDecoder(recorder.Body).Decode(&info)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Name, check.Equals, "checkout")
+	c.Assert(info.Apps, check.DeepEquals, []string{"myapp"})
+	request, err = http.NewRequest("GET", "/apps?project=checkout", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var apps []app.App
+	err = json.NewDecoder(recorder.Body).Decode(&apps)
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.HasLen, 1)
+	c.Assert(apps[0].Name, check.Equals, "myapp")
+}
+
+func (s *S) TestAppProjectSetQuotaExceeded(c *check.C) {
+	err := project.Create(context.TODO(), project.Project{Name: "checkout", Quota: project.Quota{Apps: 1}})
+	c.Assert(err, check.IsNil)
+	for _, name := range []string{"app1", "app2"} {
+		a := app.App{Name: name, Platform: "zend", TeamOwner: s.team.Name}
+		err = app.CreateApp(context.TODO(), &a, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	p, err := project.Get("checkout")
+	c.Assert(err, check.IsNil)
+	a, err := app.GetByName(context.TODO(), "app1")
+	c.Assert(err, check.IsNil)
+	err = p.AddApp(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("PUT", "/1.13/apps/app2/project", strings.NewReader("project=checkout"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+	c.Assert(recorder.Body.String(), check.Equals, "project \"checkout\": Quota exceeded. Available: 0, Requested: 1.\n")
+}
+
+func (s *S) TestProjectDeleteInUse(c *check.C) {
+	err := project.Create(context.TODO(), project.Project{Name: "checkout"})
+	c.Assert(err, check.IsNil)
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name, Project: "checkout"}
+	err = app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("DELETE", "/1.13/projects/checkout", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "b "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusConflict)
+}
@@ -0,0 +1,262 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ratelimit limits the rate of API requests with token buckets keyed
+// by the caller: team tokens, app tokens and users are limited by their names
+// and unauthenticated requests by the client IP. Callers holding a permission
+// in the global context may get a different limit, configured as a class
+// under rate-limit:classes.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+)
+
+const (
+	defaultClass             = "default"
+	defaultRequestsPerSecond = 10
+	defaultBurst             = 50
+	pruneInterval            = time.Minute
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tsuru",
+		Subsystem: "rate_limit",
+		Name:      "requests_total",
+		Help:      "Number of API requests checked by the rate limiter",
+	}, []string{"class", "result"})
+
+	trackedKeys = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "tsuru",
+		Subsystem: "rate_limit",
+		Name:      "tracked_keys",
+		Help:      "Number of callers tracked by the rate limiter",
+	})
+)
+
+// Class is a limit applied to the callers holding Permission in the global
+// context, the default class has no permission.
+type Class struct {
+	Name              string
+	Permission        *permission.PermissionScheme
+	RequestsPerSecond float64
+	Burst             int
+}
+
+type bucket struct {
+	class  *Class
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket and consumes a token when available, returning the
+// remaining tokens and the time until the bucket is full again.
+func (b *bucket) take(now time.Time) (bool, int, time.Duration) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(b.class.Burst), b.tokens+elapsed*b.class.RequestsPerSecond)
+	b.last = now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	missing := float64(b.class.Burst) - b.tokens
+	reset := time.Duration(missing / b.class.RequestsPerSecond * float64(time.Second))
+	return allowed, int(b.tokens), reset
+}
+
+func (b *bucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.class.RequestsPerSecond >= float64(b.class.Burst)
+}
+
+type Limiter struct {
+	mu        sync.Mutex
+	classes   []*Class
+	buckets   map[string]*bucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// NewLimiter returns a limiter using the first class for callers not
+// matching the permission of any other class.
+func NewLimiter(classes ...*Class) *Limiter {
+	return &Limiter{
+		classes: classes,
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// FromConfig returns the limiter configured under rate-limit, or nil when
+// rate-limit:enabled isn't set.
+func FromConfig() (*Limiter, error) {
+	if enabled, _ := config.GetBool("rate-limit:enabled"); !enabled {
+		return nil, nil
+	}
+	def, err := classFromConfig(defaultClass, "rate-limit")
+	if err != nil {
+		return nil, err
+	}
+	classes := []*Class{def}
+	classesConfig, err := config.Get("rate-limit:classes")
+	if err != nil {
+		return NewLimiter(classes...), nil
+	}
+	classesMap, ok := classesConfig.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.Errorf("invalid rate limit classes configuration: %v", classesConfig)
+	}
+	names := make([]string, 0, len(classesMap))
+	for name := range classesMap {
+		names = append(names, fmt.Sprint(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prefix := "rate-limit:classes:" + name
+		class, err := classFromConfig(name, prefix)
+		if err != nil {
+			return nil, err
+		}
+		permName, _ := config.GetString(prefix + ":permission")
+		if permName == "*" {
+			permName = ""
+		}
+		class.Permission, err = permission.SafeGet(permName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid permission for rate limit class %q", name)
+		}
+		classes = append(classes, class)
+	}
+	return NewLimiter(classes...), nil
+}
+
+func classFromConfig(name, prefix string) (*Class, error) {
+	class := &Class{Name: name, RequestsPerSecond: defaultRequestsPerSecond, Burst: defaultBurst}
+	if rps, err := config.GetFloat(prefix + ":requests-per-second"); err == nil {
+		class.RequestsPerSecond = rps
+	}
+	if burst, err := config.GetInt(prefix + ":burst"); err == nil {
+		class.Burst = burst
+	}
+	if class.RequestsPerSecond <= 0 || class.Burst < 1 {
+		return nil, errors.Errorf("invalid rate limit class %q, requests-per-second and burst must be positive", name)
+	}
+	return class, nil
+}
+
+// classFor returns the class with the highest rate among the classes whose
+// permission is held by the token.
+func (l *Limiter) classFor(t auth.Token) *Class {
+	class := l.classes[0]
+	if t == nil || len(l.classes) == 1 {
+		return class
+	}
+	perms, err := t.Permissions()
+	if err != nil {
+		return class
+	}
+	for _, c := range l.classes[1:] {
+		if c.RequestsPerSecond > class.RequestsPerSecond && permission.CheckFromPermList(perms, c.Permission) {
+			class = c
+		}
+	}
+	return class
+}
+
+func requestKey(remoteAddr string, t auth.Token) string {
+	if t == nil {
+		ip, _, err := net.SplitHostPort(remoteAddr)
+		if err != nil {
+			ip = remoteAddr
+		}
+		return "ip:" + ip
+	}
+	if named, ok := t.(authTypes.NamedToken); ok {
+		return "token:" + named.GetTokenName()
+	}
+	if t.IsAppToken() {
+		return "app:" + t.GetAppName()
+	}
+	return "user:" + t.GetUserName()
+}
+
+// Allow consumes a token of the bucket of the caller, identified by the token
+// or by the remote address when the request isn't authenticated.
+func (l *Limiter) Allow(remoteAddr string, t auth.Token) (bool, int, time.Duration, *Class) {
+	key := requestKey(remoteAddr, t)
+	l.mu.Lock()
+	b := l.buckets[key]
+	l.mu.Unlock()
+	if b == nil {
+		// Looking up the permissions of the token may be slow, it's done
+		// without holding the lock and only for callers not tracked.
+		class := l.classFor(t)
+		l.mu.Lock()
+		if b = l.buckets[key]; b == nil {
+			b = &bucket{class: class, tokens: float64(class.Burst), last: l.now()}
+			l.buckets[key] = b
+		}
+		l.mu.Unlock()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	allowed, remaining, reset := b.take(now)
+	l.prune(now)
+	if allowed {
+		requestsTotal.WithLabelValues(b.class.Name, "allowed").Inc()
+	} else {
+		requestsTotal.WithLabelValues(b.class.Name, "limited").Inc()
+	}
+	return allowed, remaining, reset, b.class
+}
+
+// prune forgets callers whose buckets are full, they start again with a full
+// bucket when they come back. Callers are also classified again, picking up
+// permission changes.
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < pruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		if b.full(now) {
+			delete(l.buckets, key)
+		}
+	}
+	trackedKeys.Set(float64(len(l.buckets)))
+}
+
+// ServeHTTP implements negroni.Handler, it must run after the authentication
+// middleware.
+func (l *Limiter) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	allowed, remaining, reset, class := l.Allow(r.RemoteAddr, context.GetAuthToken(r))
+	resetSeconds := strconv.Itoa(int(math.Ceil(reset.Seconds())))
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(class.Burst))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("RateLimit-Reset", resetSeconds)
+	if !allowed {
+		retryAfter := int(math.Ceil(1 / class.RequestsPerSecond))
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		http.Error(w, "rate limit exceeded, try again later", http.StatusTooManyRequests)
+		return
+	}
+	next(w, r)
+}
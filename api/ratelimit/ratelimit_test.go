@@ -0,0 +1,128 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("rate-limit")
+}
+
+type fakeToken struct {
+	user  string
+	perms []permission.Permission
+}
+
+func (t *fakeToken) GetValue() string                              { return "" }
+func (t *fakeToken) GetAppName() string                            { return "" }
+func (t *fakeToken) GetUserName() string                           { return t.user }
+func (t *fakeToken) IsAppToken() bool                              { return false }
+func (t *fakeToken) User() (*authTypes.User, error)                { return nil, nil }
+func (t *fakeToken) Permissions() ([]permission.Permission, error) { return t.perms, nil }
+
+func doRequest(l *Limiter, token *fakeToken) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/apps", nil)
+	request.RemoteAddr = "10.0.0.1:5555"
+	if token != nil {
+		context.SetAuthToken(request, token)
+	}
+	l.ServeHTTP(recorder, request, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	context.Clear(request)
+	return recorder
+}
+
+func (s *S) TestLimiterBurstAndRefill(c *check.C) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter(&Class{Name: "default", RequestsPerSecond: 1, Burst: 2})
+	l.now = func() time.Time { return now }
+	token := &fakeToken{user: "me@tsuru.io"}
+	recorder := doRequest(l, token)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("RateLimit-Limit"), check.Equals, "2")
+	c.Assert(recorder.Header().Get("RateLimit-Remaining"), check.Equals, "1")
+	c.Assert(recorder.Header().Get("RateLimit-Reset"), check.Equals, "1")
+	recorder = doRequest(l, token)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	recorder = doRequest(l, token)
+	c.Assert(recorder.Code, check.Equals, http.StatusTooManyRequests)
+	c.Assert(recorder.Header().Get("RateLimit-Remaining"), check.Equals, "0")
+	c.Assert(recorder.Header().Get("Retry-After"), check.Equals, "1")
+	recorder = doRequest(l, &fakeToken{user: "other@tsuru.io"})
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	recorder = doRequest(l, nil)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	now = now.Add(time.Second)
+	recorder = doRequest(l, token)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+}
+
+func (s *S) TestLimiterClassByPermission(c *check.C) {
+	now := time.Now()
+	l := NewLimiter(
+		&Class{Name: "default", RequestsPerSecond: 1, Burst: 1},
+		&Class{Name: "admin", Permission: permission.PermAll, RequestsPerSecond: 100, Burst: 3},
+	)
+	l.now = func() time.Time { return now }
+	admin := &fakeToken{user: "admin@tsuru.io", perms: []permission.Permission{
+		{Scheme: permission.PermAll, Context: permission.Context(permTypes.CtxGlobal, "")},
+	}}
+	for i := 0; i < 3; i++ {
+		recorder := doRequest(l, admin)
+		c.Assert(recorder.Code, check.Equals, http.StatusOK)
+		c.Assert(recorder.Header().Get("RateLimit-Limit"), check.Equals, "3")
+	}
+	c.Assert(doRequest(l, admin).Code, check.Equals, http.StatusTooManyRequests)
+	user := &fakeToken{user: "user@tsuru.io", perms: []permission.Permission{
+		{Scheme: permission.PermAll, Context: permission.Context(permTypes.CtxTeam, "myteam")},
+	}}
+	c.Assert(doRequest(l, user).Code, check.Equals, http.StatusOK)
+	c.Assert(doRequest(l, user).Code, check.Equals, http.StatusTooManyRequests)
+}
+
+func (s *S) TestFromConfig(c *check.C) {
+	l, err := FromConfig()
+	c.Assert(err, check.IsNil)
+	c.Assert(l, check.IsNil)
+	config.Set("rate-limit:enabled", true)
+	config.Set("rate-limit:burst", 20)
+	config.Set("rate-limit:classes:admin:permission", "*")
+	config.Set("rate-limit:classes:admin:requests-per-second", 50.0)
+	config.Set("rate-limit:classes:deployers:permission", "app.deploy")
+	l, err = FromConfig()
+	c.Assert(err, check.IsNil)
+	c.Assert(l.classes, check.DeepEquals, []*Class{
+		{Name: "default", RequestsPerSecond: 10, Burst: 20},
+		{Name: "admin", Permission: permission.PermAll, RequestsPerSecond: 50, Burst: 50},
+		{Name: "deployers", Permission: permission.PermAppDeploy, RequestsPerSecond: 10, Burst: 50},
+	})
+	config.Set("rate-limit:classes:deployers:permission", "app.invalid")
+	_, err = FromConfig()
+	c.Assert(err, check.ErrorMatches, `invalid permission for rate limit class "deployers".*`)
+	config.Set("rate-limit:classes:deployers:permission", "app.deploy")
+	config.Set("rate-limit:requests-per-second", 0)
+	_, err = FromConfig()
+	c.Assert(err, check.ErrorMatches, `invalid rate limit class "default".*`)
+}
@@ -0,0 +1,1255 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/service"
+	"github.com/tsuru/tsuru/servicemanager"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+func sortedCopy(values []string) []string {
+	result := make([]string, len(values))
+	copy(result, values)
+	sort.Strings(result)
+	return result
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = sortedCopy(a), sortedCopy(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// resourceRevision returns a token identifying the current state of a
+// resource. It must be called before the Revision field of the resource is
+// set.
+func resourceRevision(resource interface{}) (string, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+func writeResource(w http.ResponseWriter, revision string, resource interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", `"`+revision+`"`)
+	return json.NewEncoder(w).Encode(resource)
+}
+
+// checkRevision ensures conditional updates are applied only to the expected
+// state of the resource. The expected revision is read from the If-Match
+// header, falling back to the revision field sent in the request body.
+func checkRevision(r *http.Request, bodyRevision, current string) error {
+	expected := strings.Trim(r.Header.Get("If-Match"), `"`)
+	if expected == "" {
+		expected = bodyRevision
+	}
+	if expected == "" {
+		return &errors.HTTP{Code: http.StatusPreconditionRequired, Message: "the resource revision must be sent in the If-Match header"}
+	}
+	if expected != current {
+		return &errors.HTTP{Code: http.StatusPreconditionFailed, Message: fmt.Sprintf("resource revision %q does not match current revision %q", expected, current)}
+	}
+	return nil
+}
+
+// wantsCreate returns whether the PUT request asks for the resource to be
+// created, which requires the If-None-Match: * header. Creating an existing
+// resource fails with 412, so retried creates never overwrite it.
+func wantsCreate(r *http.Request) bool {
+	return r.Header.Get("If-None-Match") == "*"
+}
+
+func errResourceExists(resType, id string) error {
+	return &errors.HTTP{Code: http.StatusPreconditionFailed, Message: fmt.Sprintf("%s %q already exists", resType, id)}
+}
+
+func checkResourceName(pathName, bodyName string) error {
+	if bodyName != "" && bodyName != pathName {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("resource name %q does not match %q", bodyName, pathName)}
+	}
+	return nil
+}
+
+func writeCreatedResource(w http.ResponseWriter, revision string, resource interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", `"`+revision+`"`)
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(resource)
+}
+
+func appResource(r *http.Request, t auth.Token, name string) (*app.App, *apiTypes.AppResource, error) {
+	a, err := getApp(r.Context(), name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !permission.Check(t, permission.PermAppRead, contextsForApp(a)...) {
+		return nil, nil, permission.ErrUnauthorized
+	}
+	res := apiTypes.AppResource{
+		Name:        a.Name,
+		Description: a.Description,
+		Platform:    a.Platform,
+		Pool:        a.Pool,
+		Plan:        a.Plan.Name,
+		TeamOwner:   a.TeamOwner,
+		Teams:       sortedCopy(a.Teams),
+		Tags:        sortedCopy(a.Tags),
+		CNames:      sortedCopy(a.CName),
+	}
+	res.Revision, err = resourceRevision(res)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, &res, nil
+}
+
+// title: app resource
+// path: /resources/apps/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Not found
+func appResourceGet(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	_, res, err := appResource(r, t, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	return writeResource(w, res.Revision, res)
+}
+
+// title: app resource update
+// path: /resources/apps/{name}
+// method: PUT
+// consume: application/json
+// produce: application/json
+// responses:
+//   200: App updated
+//   201: App created
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+//   409: App already exists
+//   412: Revision mismatch
+//   428: Revision required
+func appResourceUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var wanted apiTypes.AppResource
+	err = ParseInput(r, &wanted)
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":name")
+	if err = checkResourceName(appName, wanted.Name); err != nil {
+		return err
+	}
+	if wantsCreate(r) {
+		return appResourceCreate(w, r, t, appName, wanted)
+	}
+	a, current, err := appResource(r, t, appName)
+	if err != nil {
+		return err
+	}
+	err = checkRevision(r, wanted.Revision, current.Revision)
+	if err != nil {
+		return err
+	}
+	var updateData app.App
+	var wantedPerms []*permission.PermissionScheme
+	if wanted.Description != current.Description {
+		updateData.Description = wanted.Description
+		wantedPerms = append(wantedPerms, permission.PermAppUpdateDescription)
+	}
+	if wanted.Platform != "" && wanted.Platform != current.Platform {
+		updateData.Platform = wanted.Platform
+		wantedPerms = append(wantedPerms, permission.PermAppUpdatePlatform)
+	}
+	if wanted.Pool != "" && wanted.Pool != current.Pool {
+		updateData.Pool = wanted.Pool
+		wantedPerms = append(wantedPerms, permission.PermAppUpdatePool)
+	}
+	if wanted.Plan != "" && wanted.Plan != current.Plan {
+		updateData.Plan = appTypes.Plan{Name: wanted.Plan}
+		wantedPerms = append(wantedPerms, permission.PermAppUpdatePlan)
+	}
+	if wanted.TeamOwner != "" && wanted.TeamOwner != current.TeamOwner {
+		updateData.TeamOwner = wanted.TeamOwner
+		wantedPerms = append(wantedPerms, permission.PermAppUpdateTeamowner)
+	}
+	if !sameStrings(wanted.Tags, current.Tags) {
+		updateData.Tags = append([]string{}, wanted.Tags...)
+		wantedPerms = append(wantedPerms, permission.PermAppUpdateTags)
+	}
+	if len(wantedPerms) == 0 {
+		return writeResource(w, current.Revision, current)
+	}
+	for _, perm := range wantedPerms {
+		if !permission.Check(t, perm, contextsForApp(a)...) {
+			return permission.ErrUnauthorized
+		}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: wanted,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = a.Update(app.UpdateAppArgs{
+		UpdateData:    updateData,
+		Writer:        evt,
+		ShouldRestart: true,
+	})
+	if err == appTypes.ErrPlanNotFound {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if _, ok := err.(*router.ErrRouterNotFound); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	_, updated, err := appResource(r, t, appName)
+	if err != nil {
+		return err
+	}
+	return writeResource(w, updated.Revision, updated)
+}
+
+func appResourceCreate(w http.ResponseWriter, r *http.Request, t auth.Token, appName string, wanted apiTypes.AppResource) (err error) {
+	ctx := r.Context()
+	_, err = app.GetByName(ctx, appName)
+	if err == nil {
+		return errResourceExists(apiTypes.ResourceTypeApp, appName)
+	}
+	if err != appTypes.ErrAppNotFound {
+		return err
+	}
+	a := app.App{
+		Name:        appName,
+		Description: wanted.Description,
+		Platform:    wanted.Platform,
+		Pool:        wanted.Pool,
+		Plan:        appTypes.Plan{Name: wanted.Plan},
+		TeamOwner:   wanted.TeamOwner,
+		Tags:        wanted.Tags,
+	}
+	if a.TeamOwner == "" {
+		a.TeamOwner, err = autoTeamOwner(ctx, t, permission.PermAppCreate)
+		if err != nil {
+			return err
+		}
+	}
+	if !permission.Check(t, permission.PermAppCreate, permission.Context(permTypes.CtxTeam, a.TeamOwner)) {
+		return permission.ErrUnauthorized
+	}
+	u, err := auth.ConvertNewUser(t.User())
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: wanted,
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = app.CreateApp(ctx, &a, u)
+	if err != nil {
+		if e, ok := err.(*appTypes.AppCreationError); ok && e.Err == app.ErrAppAlreadyExists {
+			return &errors.HTTP{Code: http.StatusConflict, Message: e.Error()}
+		}
+		if _, ok := err.(appTypes.NoTeamsError); ok {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "Cannot create app without teams."}
+		}
+		if err == appTypes.ErrInvalidPlatform || err == appTypes.ErrPlanNotFound {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	_, created, err := appResource(r, t, appName)
+	if err != nil {
+		return err
+	}
+	return writeCreatedResource(w, created.Revision, created)
+}
+
+func poolResource(r *http.Request, t auth.Token, name string) (*apiTypes.PoolResource, error) {
+	if !permission.Check(t, permission.PermPoolRead, permission.Context(permTypes.CtxPool, name)) {
+		return nil, permission.ErrUnauthorized
+	}
+	p, err := pool.GetPoolByName(r.Context(), name)
+	if err == pool.ErrPoolNotFound {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var res apiTypes.PoolResource
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+	res.Teams = sortedCopy(res.Teams)
+	if res.Labels == nil {
+		res.Labels = map[string]string{}
+	}
+	res.Revision, err = resourceRevision(res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// title: pool resource
+// path: /resources/pools/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Not found
+func poolResourceGet(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	res, err := poolResource(r, t, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	return writeResource(w, res.Revision, res)
+}
+
+// title: pool resource update
+// path: /resources/pools/{name}
+// method: PUT
+// consume: application/json
+// produce: application/json
+// responses:
+//   200: Pool updated
+//   201: Pool created
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+//   409: Default pool already exists
+//   412: Revision mismatch
+//   428: Revision required
+func poolResourceUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var wanted apiTypes.PoolResource
+	err = ParseInput(r, &wanted)
+	if err != nil {
+		return err
+	}
+	poolName := r.URL.Query().Get(":name")
+	if err = checkResourceName(poolName, wanted.Name); err != nil {
+		return err
+	}
+	if wantsCreate(r) {
+		return poolResourceCreate(w, r, t, poolName, wanted)
+	}
+	poolCtx := permission.Context(permTypes.CtxPool, poolName)
+	current, err := poolResource(r, t, poolName)
+	if err != nil {
+		return err
+	}
+	err = checkRevision(r, wanted.Revision, current.Revision)
+	if err != nil {
+		return err
+	}
+	var opts pool.UpdatePoolOptions
+	changed := false
+	if wanted.Default != current.Default {
+		opts.Default = &wanted.Default
+		changed = true
+	}
+	if wanted.Public != current.Public {
+		opts.Public = &wanted.Public
+		changed = true
+	}
+	if wanted.Labels == nil {
+		wanted.Labels = map[string]string{}
+	}
+	if fmt.Sprint(wanted.Labels) != fmt.Sprint(current.Labels) {
+		opts.Labels = wanted.Labels
+		changed = true
+	}
+	if !changed {
+		return writeResource(w, current.Revision, current)
+	}
+	if !permission.Check(t, permission.PermPoolUpdate, poolCtx) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: wanted,
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, poolCtx),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pool.PoolUpdate(r.Context(), poolName, opts)
+	if err == pool.ErrDefaultPoolAlreadyExists {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	updated, err := poolResource(r, t, poolName)
+	if err != nil {
+		return err
+	}
+	return writeResource(w, updated.Revision, updated)
+}
+
+func poolResourceCreate(w http.ResponseWriter, r *http.Request, t auth.Token, poolName string, wanted apiTypes.PoolResource) (err error) {
+	if !permission.Check(t, permission.PermPoolCreate) {
+		return permission.ErrUnauthorized
+	}
+	_, err = pool.GetPoolByName(r.Context(), poolName)
+	if err == nil {
+		return errResourceExists(apiTypes.ResourceTypePool, poolName)
+	}
+	if err != pool.ErrPoolNotFound {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePool, Value: poolName},
+		Kind:       permission.PermPoolCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: wanted,
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, poolName)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = pool.AddPool(r.Context(), pool.AddPoolOptions{
+		Name:        poolName,
+		Public:      wanted.Public,
+		Default:     wanted.Default,
+		Provisioner: wanted.Provisioner,
+		Labels:      wanted.Labels,
+	})
+	if err == pool.ErrDefaultPoolAlreadyExists || err == pool.ErrPoolAlreadyExists {
+		return &errors.HTTP{Code: http.StatusConflict, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	created, err := poolResource(r, t, poolName)
+	if err != nil {
+		return err
+	}
+	return writeCreatedResource(w, created.Revision, created)
+}
+
+func roleResource(t auth.Token, name string) (*permission.Role, *apiTypes.RoleResource, error) {
+	if !permission.Check(t, permission.PermRoleRead) {
+		return nil, nil, permission.ErrUnauthorized
+	}
+	role, err := getRoleReturnNotFound(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	res := apiTypes.RoleResource{
+		Name:        role.Name,
+		Context:     string(role.ContextType),
+		Description: role.Description,
+		Permissions: sortedCopy(role.SchemeNames),
+		Events:      sortedCopy(role.Events),
+	}
+	res.Revision, err = resourceRevision(res)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &role, &res, nil
+}
+
+// title: role resource
+// path: /resources/roles/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Not found
+func roleResourceGet(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	_, res, err := roleResource(t, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	return writeResource(w, res.Revision, res)
+}
+
+// title: role resource update
+// path: /resources/roles/{name}
+// method: PUT
+// consume: application/json
+// produce: application/json
+// responses:
+//   200: Role updated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+//   412: Revision mismatch
+//   428: Revision required
+func roleResourceUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var wanted apiTypes.RoleResource
+	err = ParseInput(r, &wanted)
+	if err != nil {
+		return err
+	}
+	roleName := r.URL.Query().Get(":name")
+	role, current, err := roleResource(t, roleName)
+	if err != nil {
+		return err
+	}
+	err = checkRevision(r, wanted.Revision, current.Revision)
+	if err != nil {
+		return err
+	}
+	if wanted.Context != "" && wanted.Context != current.Context {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "the role context cannot be changed, the role must be recreated"}
+	}
+	currentPerms := make(map[string]struct{}, len(current.Permissions))
+	for _, p := range current.Permissions {
+		currentPerms[p] = struct{}{}
+	}
+	wantedPerms := make(map[string]struct{}, len(wanted.Permissions))
+	var toAdd, toRemove []string
+	for _, p := range wanted.Permissions {
+		wantedPerms[p] = struct{}{}
+		if _, ok := currentPerms[p]; !ok {
+			toAdd = append(toAdd, p)
+		}
+	}
+	for _, p := range current.Permissions {
+		if _, ok := wantedPerms[p]; !ok {
+			toRemove = append(toRemove, p)
+		}
+	}
+	updateDescription := wanted.Description != current.Description
+	if !updateDescription && len(toAdd) == 0 && len(toRemove) == 0 {
+		return writeResource(w, current.Revision, current)
+	}
+	if updateDescription && !permission.Check(t, permission.PermRoleUpdateDescription) {
+		return permission.ErrUnauthorized
+	}
+	if len(toAdd) > 0 && !permission.Check(t, permission.PermRoleUpdatePermissionAdd) {
+		return permission.ErrUnauthorized
+	}
+	if len(toRemove) > 0 && !permission.Check(t, permission.PermRoleUpdatePermissionRemove) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeRole, Value: roleName},
+		Kind:       permission.PermRoleUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: wanted,
+		Allowed:    event.Allowed(permission.PermRoleReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	if updateDescription {
+		role.Description = wanted.Description
+		err = role.Update()
+		if err != nil {
+			return err
+		}
+	}
+	if len(toAdd) > 0 {
+		err = role.AddPermissions(toAdd...)
+		if err == permTypes.ErrInvalidPermissionName {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		switch perr := err.(type) {
+		case *permTypes.ErrPermissionNotFound:
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: perr.Error()}
+		case *permTypes.ErrPermissionNotAllowed:
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: perr.Error()}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(toRemove) > 0 {
+		err = role.RemovePermissions(toRemove...)
+		if err != nil {
+			return err
+		}
+	}
+	_, updated, err := roleResource(t, roleName)
+	if err != nil {
+		return err
+	}
+	return writeResource(w, updated.Revision, updated)
+}
+
+func serviceBindingResource(r *http.Request, t auth.Token, serviceName, instanceName, appName string) (*service.ServiceInstance, *app.App, *apiTypes.ServiceBindingResource, error) {
+	instance, a, err := getServiceInstance(r.Context(), serviceName, instanceName, appName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !permission.Check(t, permission.PermServiceInstanceRead, contextsForServiceInstance(instance, serviceName)...) {
+		return nil, nil, nil, permission.ErrUnauthorized
+	}
+	if !permission.Check(t, permission.PermAppRead, contextsForApp(a)...) {
+		return nil, nil, nil, permission.ErrUnauthorized
+	}
+	bound := false
+	for _, name := range instance.Apps {
+		if name == appName {
+			bound = true
+			break
+		}
+	}
+	if !bound {
+		return instance, a, nil, nil
+	}
+	envs := []string{}
+	for name := range a.InstanceEnvs(serviceName, instanceName) {
+		envs = append(envs, name)
+	}
+	sort.Strings(envs)
+	res := apiTypes.ServiceBindingResource{
+		Service:  serviceName,
+		Instance: instanceName,
+		App:      appName,
+		Envs:     envs,
+	}
+	res.Revision, err = resourceRevision(res)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return instance, a, &res, nil
+}
+
+func errServiceBindingNotFound(instanceName, appName string) error {
+	return &errors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("instance %q is not bound to the app %q", instanceName, appName)}
+}
+
+// title: service binding resource
+// path: /resources/service-bindings/{service}/{instance}/{app}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Not found
+func serviceBindingResourceGet(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	serviceName := r.URL.Query().Get(":service")
+	instanceName := r.URL.Query().Get(":instance")
+	appName := r.URL.Query().Get(":app")
+	_, _, res, err := serviceBindingResource(r, t, serviceName, instanceName, appName)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		return errServiceBindingNotFound(instanceName, appName)
+	}
+	return writeResource(w, res.Revision, res)
+}
+
+// title: service binding resource create
+// path: /resources/service-bindings/{service}/{instance}/{app}
+// method: PUT
+// produce: application/json
+// responses:
+//   200: Binding created or unchanged
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+//   412: Revision mismatch
+//   428: Revision required
+func serviceBindingResourceUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	serviceName := r.URL.Query().Get(":service")
+	instanceName := r.URL.Query().Get(":instance")
+	appName := r.URL.Query().Get(":app")
+	instance, a, current, err := serviceBindingResource(r, t, serviceName, instanceName, appName)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		if r.Header.Get("If-None-Match") == "*" {
+			return &errors.HTTP{Code: http.StatusPreconditionFailed, Message: fmt.Sprintf("instance %q is already bound to the app %q", instanceName, appName)}
+		}
+		err = checkRevision(r, "", current.Revision)
+		if err != nil {
+			return err
+		}
+		return writeResource(w, current.Revision, current)
+	}
+	if r.Header.Get("If-None-Match") != "*" {
+		if r.Header.Get("If-Match") != "" {
+			return errServiceBindingNotFound(instanceName, appName)
+		}
+		return &errors.HTTP{Code: http.StatusPreconditionRequired, Message: "creating a binding requires the If-None-Match: * header"}
+	}
+	allowed := permission.Check(t, permission.PermServiceInstanceUpdateBind,
+		append(permission.Contexts(permTypes.CtxTeam, instance.Teams),
+			permission.Context(permTypes.CtxTeam, instance.TeamOwner),
+			permission.Context(permTypes.CtxServiceInstance, instance.Name),
+		)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	if !permission.Check(t, permission.PermAppUpdateBind, contextsForApp(a)...) {
+		return permission.ErrUnauthorized
+	}
+	err = a.ValidateService(serviceName)
+	if err == pool.ErrPoolHasNoService {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target: appTarget(appName),
+		ExtraTargets: []event.ExtraTarget{
+			{Target: serviceInstanceTarget(serviceName, instanceName)},
+		},
+		Kind:       permission.PermAppUpdateBind,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = instance.BindApp(a, nil, true, evt, evt, requestIDHeader(r))
+	if err != nil {
+		return err
+	}
+	_, _, created, err := serviceBindingResource(r, t, serviceName, instanceName, appName)
+	if err != nil {
+		return err
+	}
+	if created == nil {
+		return errServiceBindingNotFound(instanceName, appName)
+	}
+	return writeResource(w, created.Revision, created)
+}
+
+func planResource(r *http.Request, name string) (*apiTypes.PlanResource, error) {
+	plan, err := servicemanager.Plan.FindByName(r.Context(), name)
+	if err == appTypes.ErrPlanNotFound {
+		return nil, &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := apiTypes.PlanResource{
+		Name:        plan.Name,
+		Memory:      plan.Memory,
+		Swap:        plan.Swap,
+		CPUMilli:    plan.CPUMilli,
+		CPUQuota:    plan.CPUQuota,
+		CPUPeriod:   plan.CPUPeriod,
+		BlkioWeight: plan.BlkioWeight,
+		PidsLimit:   plan.PidsLimit,
+		Default:     plan.Default,
+	}
+	res.Revision, err = resourceRevision(res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// title: plan resource
+// path: /resources/plans/{name}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Not found
+func planResourceGet(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	res, err := planResource(r, r.URL.Query().Get(":name"))
+	if err != nil {
+		return err
+	}
+	return writeResource(w, res.Revision, res)
+}
+
+// title: plan resource update
+// path: /resources/plans/{name}
+// method: PUT
+// consume: application/json
+// produce: application/json
+// responses:
+//   200: Plan updated
+//   201: Plan created
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+//   412: Revision mismatch
+//   428: Revision required
+func planResourceUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var wanted apiTypes.PlanResource
+	err = ParseInput(r, &wanted)
+	if err != nil {
+		return err
+	}
+	planName := r.URL.Query().Get(":name")
+	if err = checkResourceName(planName, wanted.Name); err != nil {
+		return err
+	}
+	ctx := r.Context()
+	create := wantsCreate(r)
+	plan := &appTypes.Plan{Name: planName}
+	if !create {
+		current, err := planResource(r, planName)
+		if err != nil {
+			return err
+		}
+		err = checkRevision(r, wanted.Revision, current.Revision)
+		if err != nil {
+			return err
+		}
+		wanted.Name, wanted.Revision = current.Name, current.Revision
+		if wanted == *current {
+			return writeResource(w, current.Revision, current)
+		}
+		plan, err = servicemanager.Plan.FindByName(ctx, planName)
+		if err != nil {
+			return err
+		}
+	}
+	kind := permission.PermPlanUpdate
+	if create {
+		kind = permission.PermPlanCreate
+	}
+	if !permission.Check(t, kind) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypePlan, Value: planName},
+		Kind:       kind,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: wanted,
+		Allowed:    event.Allowed(permission.PermPlanReadEvents),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	plan.Memory = wanted.Memory
+	plan.Swap = wanted.Swap
+	plan.CPUMilli = wanted.CPUMilli
+	plan.CPUQuota = wanted.CPUQuota
+	plan.CPUPeriod = wanted.CPUPeriod
+	plan.BlkioWeight = wanted.BlkioWeight
+	plan.PidsLimit = wanted.PidsLimit
+	plan.Default = wanted.Default
+	if create {
+		err = servicemanager.Plan.Create(ctx, *plan)
+	} else {
+		err = servicemanager.Plan.Update(ctx, *plan)
+	}
+	if err == appTypes.ErrPlanAlreadyExists {
+		return errResourceExists(apiTypes.ResourceTypePlan, planName)
+	}
+	if isPlanValidationError(err) {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	res, err := planResource(r, planName)
+	if err != nil {
+		return err
+	}
+	if create {
+		return writeCreatedResource(w, res.Revision, res)
+	}
+	return writeResource(w, res.Revision, res)
+}
+
+func serviceInstanceResource(r *http.Request, t auth.Token, serviceName, instanceName string) (*service.ServiceInstance, *apiTypes.ServiceInstanceResource, error) {
+	instance, err := getServiceInstanceOrError(r.Context(), serviceName, instanceName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !permission.Check(t, permission.PermServiceInstanceRead, contextsForServiceInstance(instance, serviceName)...) {
+		return nil, nil, permission.ErrUnauthorized
+	}
+	res := apiTypes.ServiceInstanceResource{
+		Service:     serviceName,
+		Name:        instance.Name,
+		Plan:        instance.PlanName,
+		TeamOwner:   instance.TeamOwner,
+		Teams:       sortedCopy(instance.Teams),
+		Description: instance.Description,
+		Tags:        sortedCopy(instance.Tags),
+		Pool:        instance.Pool,
+		Parameters:  instance.Parameters,
+	}
+	if res.Parameters == nil {
+		res.Parameters = map[string]interface{}{}
+	}
+	res.Revision, err = resourceRevision(res)
+	if err != nil {
+		return nil, nil, err
+	}
+	return instance, &res, nil
+}
+
+// title: service instance resource
+// path: /resources/service-instances/{service}/{instance}
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: Not found
+func serviceInstanceResourceGet(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	_, res, err := serviceInstanceResource(r, t, r.URL.Query().Get(":service"), r.URL.Query().Get(":instance"))
+	if err != nil {
+		return err
+	}
+	return writeResource(w, res.Revision, res)
+}
+
+// title: service instance resource update
+// path: /resources/service-instances/{service}/{instance}
+// method: PUT
+// consume: application/json
+// produce: application/json
+// responses:
+//   200: Service instance updated
+//   201: Service instance created
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+//   412: Revision mismatch
+//   428: Revision required
+func serviceInstanceResourceUpdate(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var wanted apiTypes.ServiceInstanceResource
+	err = ParseInput(r, &wanted)
+	if err != nil {
+		return err
+	}
+	serviceName := r.URL.Query().Get(":service")
+	instanceName := r.URL.Query().Get(":instance")
+	if err = checkResourceName(instanceName, wanted.Name); err != nil {
+		return err
+	}
+	if err = checkResourceName(serviceName, wanted.Service); err != nil {
+		return err
+	}
+	srv, err := getService(r.Context(), serviceName)
+	if err != nil {
+		return err
+	}
+	if wantsCreate(r) {
+		return serviceInstanceResourceCreate(w, r, t, &srv, instanceName, wanted)
+	}
+	si, current, err := serviceInstanceResource(r, t, serviceName, instanceName)
+	if err != nil {
+		return err
+	}
+	err = checkRevision(r, wanted.Revision, current.Revision)
+	if err != nil {
+		return err
+	}
+	if wanted.Pool != "" && wanted.Pool != current.Pool {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "the service instance pool cannot be changed, the instance must be recreated"}
+	}
+	if wanted.Parameters == nil {
+		wanted.Parameters = map[string]interface{}{}
+	}
+	updateData := *si
+	var wantedPerms []*permission.PermissionScheme
+	if wanted.Description != current.Description {
+		updateData.Description = wanted.Description
+		wantedPerms = append(wantedPerms, permission.PermServiceInstanceUpdateDescription)
+	}
+	if wanted.TeamOwner != "" && wanted.TeamOwner != current.TeamOwner {
+		updateData.TeamOwner = wanted.TeamOwner
+		wantedPerms = append(wantedPerms, permission.PermServiceInstanceUpdateTeamowner)
+	}
+	if wanted.Plan != "" && wanted.Plan != current.Plan {
+		updateData.PlanName = wanted.Plan
+		wantedPerms = append(wantedPerms, permission.PermServiceInstanceUpdatePlan)
+	}
+	if !sameStrings(wanted.Tags, current.Tags) {
+		updateData.Tags = append([]string{}, wanted.Tags...)
+		wantedPerms = append(wantedPerms, permission.PermServiceInstanceUpdateTags)
+	}
+	if wanted.Teams != nil && !sameStrings(wanted.Teams, current.Teams) {
+		var teamPerms []*permission.PermissionScheme
+		updateData.Teams, teamPerms, err = serviceInstanceTeamsUpdate(r, current, updateData.TeamOwner, wanted.Teams)
+		if err != nil {
+			return err
+		}
+		wantedPerms = append(wantedPerms, teamPerms...)
+	}
+	if !reflect.DeepEqual(wanted.Parameters, current.Parameters) {
+		updateData.Parameters = wanted.Parameters
+		wantedPerms = append(wantedPerms, permission.PermServiceInstanceUpdateParameters)
+	}
+	if len(wantedPerms) == 0 {
+		return writeResource(w, current.Revision, current)
+	}
+	for _, perm := range wantedPerms {
+		if !permission.Check(t, perm, contextsForServiceInstance(si, serviceName)...) {
+			return permission.ErrUnauthorized
+		}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     serviceInstanceTarget(serviceName, instanceName),
+		Kind:       permission.PermServiceInstanceUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: wanted,
+		Allowed:    event.Allowed(permission.PermServiceInstanceReadEvents, contextsForServiceInstance(si, serviceName)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = si.Update(srv, updateData, evt, requestIDHeader(r))
+	if err == service.ErrServiceInstanceChanged {
+		return &errors.HTTP{Code: http.StatusPreconditionFailed, Message: fmt.Sprintf("resource revision %q does not match current revision, the service instance was changed by another request", current.Revision)}
+	}
+	if err != nil {
+		return err
+	}
+	_, updated, err := serviceInstanceResource(r, t, serviceName, instanceName)
+	if err != nil {
+		return err
+	}
+	return writeResource(w, updated.Revision, updated)
+}
+
+// serviceInstanceTeamsUpdate returns the teams of the service instance
+// after the update, which always include the team owner, along with the
+// permissions required to grant and revoke the changed teams.
+func serviceInstanceTeamsUpdate(r *http.Request, current *apiTypes.ServiceInstanceResource, teamOwner string, wanted []string) ([]string, []*permission.PermissionScheme, error) {
+	currentTeams := map[string]bool{}
+	for _, team := range current.Teams {
+		currentTeams[team] = true
+	}
+	teams := []string{teamOwner}
+	kept := map[string]bool{teamOwner: true}
+	var grant bool
+	for _, team := range append(teams, wanted...) {
+		if team != teamOwner {
+			if kept[team] {
+				continue
+			}
+			kept[team] = true
+			teams = append(teams, team)
+		}
+		if currentTeams[team] {
+			continue
+		}
+		_, err := servicemanager.Team.FindByName(r.Context(), team)
+		if err == authTypes.ErrTeamNotFound {
+			return nil, nil, &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("team %q not found", team)}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		grant = true
+	}
+	var perms []*permission.PermissionScheme
+	if grant {
+		perms = append(perms, permission.PermServiceInstanceUpdateGrant)
+	}
+	for _, team := range current.Teams {
+		if !kept[team] {
+			perms = append(perms, permission.PermServiceInstanceUpdateRevoke)
+			break
+		}
+	}
+	return teams, perms, nil
+}
+
+func serviceInstanceResourceCreate(w http.ResponseWriter, r *http.Request, t auth.Token, srv *service.Service, instanceName string, wanted apiTypes.ServiceInstanceResource) (err error) {
+	ctx := r.Context()
+	_, err = service.GetServiceInstance(ctx, srv.Name, instanceName)
+	if err == nil {
+		return errResourceExists(apiTypes.ResourceTypeServiceInstance, srv.Name+"/"+instanceName)
+	}
+	if err != service.ErrServiceInstanceNotFound {
+		return err
+	}
+	instance := service.ServiceInstance{
+		Name:        instanceName,
+		ServiceName: srv.Name,
+		PlanName:    wanted.Plan,
+		TeamOwner:   wanted.TeamOwner,
+		Description: wanted.Description,
+		Tags:        wanted.Tags,
+		Pool:        wanted.Pool,
+		Parameters:  wanted.Parameters,
+	}
+	if instance.TeamOwner == "" {
+		instance.TeamOwner, err = permission.TeamForPermission(t, permission.PermServiceInstanceCreate)
+		if err != nil {
+			return err
+		}
+	}
+	if !permission.Check(t, permission.PermServiceInstanceCreate, permission.Context(permTypes.CtxTeam, instance.TeamOwner)) {
+		return permission.ErrUnauthorized
+	}
+	if srv.IsRestricted && !permission.Check(t, permission.PermServiceRead, contextsForService(srv)...) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     serviceInstanceTarget(srv.Name, instanceName),
+		Kind:       permission.PermServiceInstanceCreate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: wanted,
+		Allowed:    event.Allowed(permission.PermServiceInstanceReadEvents, contextsForServiceInstance(&instance, srv.Name)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	err = service.CreateServiceInstance(ctx, instance, srv, evt, requestIDHeader(r))
+	switch err {
+	case nil:
+	case service.ErrMultiClusterViolatingConstraint:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("Service %q is not available in pool %q", srv.Name, instance.Pool)}
+	case service.ErrInstanceNameAlreadyExists:
+		return errResourceExists(apiTypes.ResourceTypeServiceInstance, srv.Name+"/"+instanceName)
+	case service.ErrInvalidInstanceName:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	default:
+		return err
+	}
+	_, created, err := serviceInstanceResource(r, t, srv.Name, instanceName)
+	if err != nil {
+		return err
+	}
+	return writeCreatedResource(w, created.Revision, created)
+}
+
+// title: resource import
+// path: /resources/import
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   400: Invalid resource type or id
+//   401: Unauthorized
+//   404: Not found
+func resourceImport(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	resType := r.URL.Query().Get("type")
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "resource id is required"}
+	}
+	result := apiTypes.ImportResource{Type: resType, ID: id}
+	switch resType {
+	case apiTypes.ResourceTypeApp:
+		_, res, err := appResource(r, t, id)
+		if err != nil {
+			return err
+		}
+		result.Revision, result.Resource = res.Revision, res
+	case apiTypes.ResourceTypePool:
+		res, err := poolResource(r, t, id)
+		if err != nil {
+			return err
+		}
+		result.Revision, result.Resource = res.Revision, res
+	case apiTypes.ResourceTypeRole:
+		_, res, err := roleResource(t, id)
+		if err != nil {
+			return err
+		}
+		result.Revision, result.Resource = res.Revision, res
+	case apiTypes.ResourceTypePlan:
+		res, err := planResource(r, id)
+		if err != nil {
+			return err
+		}
+		result.Revision, result.Resource = res.Revision, res
+	case apiTypes.ResourceTypeServiceInstance:
+		parts := strings.Split(id, "/")
+		if len(parts) != 2 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "service instance id must be in the form <service>/<instance>"}
+		}
+		_, res, err := serviceInstanceResource(r, t, parts[0], parts[1])
+		if err != nil {
+			return err
+		}
+		result.Revision, result.Resource = res.Revision, res
+	case apiTypes.ResourceTypeServiceBinding:
+		parts := strings.Split(id, "/")
+		if len(parts) != 3 {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: "service binding id must be in the form <service>/<instance>/<app>"}
+		}
+		_, _, res, err := serviceBindingResource(r, t, parts[0], parts[1], parts[2])
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			return errServiceBindingNotFound(parts[1], parts[2])
+		}
+		result.Revision, result.Resource = res.Revision, res
+	default:
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid resource type %q", resType)}
+	}
+	return writeResource(w, result.Revision, result)
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/service"
+	apiTypes "github.com/tsuru/tsuru/types/api"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) doResourceRequest(c *check.C, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	c.Assert(err, check.IsNil)
+	req.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.testServer.ServeHTTP(rec, req)
+	return rec
+}
+
+func (s *S) TestRoleResourceGet(c *check.C) {
+	role, err := permission.NewRole("test", "team", "my role")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions("app.update", "app.deploy")
+	c.Assert(err, check.IsNil)
+	rec := s.doResourceRequest(c, http.MethodGet, "/resources/roles/test", "", nil)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var res apiTypes.RoleResource
+	err = json.Unmarshal(rec.Body.Bytes(), &res)
+	c.Assert(err, check.IsNil)
+	c.Assert(res.Revision, check.Not(check.Equals), "")
+	c.Assert(rec.Header().Get("ETag"), check.Equals, `"`+res.Revision+`"`)
+	c.Assert(res, check.DeepEquals, apiTypes.RoleResource{
+		Name:        "test",
+		Context:     "team",
+		Description: "my role",
+		Permissions: []string{"app.deploy", "app.update"},
+		Events:      []string{},
+		Revision:    res.Revision,
+	})
+	again := s.doResourceRequest(c, http.MethodGet, "/resources/roles/test", "", nil)
+	c.Assert(again.Body.String(), check.Equals, rec.Body.String())
+}
+
+func (s *S) TestRoleResourceUpdate(c *check.C) {
+	role, err := permission.NewRole("test", "team", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions("app.update")
+	c.Assert(err, check.IsNil)
+	rec := s.doResourceRequest(c, http.MethodGet, "/resources/roles/test", "", nil)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	etag := rec.Header().Get("ETag")
+	body := `{"name":"test","context":"team","description":"new desc","permissions":["app.deploy"]}`
+	rec = s.doResourceRequest(c, http.MethodPut, "/resources/roles/test", body, map[string]string{"If-Match": etag})
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("ETag"), check.Not(check.Equals), etag)
+	r, err := permission.FindRole("test")
+	c.Assert(err, check.IsNil)
+	sort.Strings(r.SchemeNames)
+	c.Assert(r.SchemeNames, check.DeepEquals, []string{"app.deploy"})
+	c.Assert(r.Description, check.Equals, "new desc")
+	rec = s.doResourceRequest(c, http.MethodPut, "/resources/roles/test", body, map[string]string{"If-Match": etag})
+	c.Assert(rec.Code, check.Equals, http.StatusPreconditionFailed)
+}
+
+func (s *S) TestRoleResourceUpdateRequiresRevision(c *check.C) {
+	_, err := permission.NewRole("test", "team", "")
+	c.Assert(err, check.IsNil)
+	rec := s.doResourceRequest(c, http.MethodPut, "/resources/roles/test", `{"description":"x"}`, nil)
+	c.Assert(rec.Code, check.Equals, http.StatusPreconditionRequired)
+}
+
+func (s *S) TestPoolResourceUpdate(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	rec := s.doResourceRequest(c, http.MethodGet, "/resources/pools/pool1", "", nil)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var res apiTypes.PoolResource
+	err = json.Unmarshal(rec.Body.Bytes(), &res)
+	c.Assert(err, check.IsNil)
+	c.Assert(res.Public, check.Equals, false)
+	c.Assert(res.Labels, check.DeepEquals, map[string]string{})
+	res.Public = true
+	res.Labels = map[string]string{"env": "prod"}
+	data, err := json.Marshal(res)
+	c.Assert(err, check.IsNil)
+	rec = s.doResourceRequest(c, http.MethodPut, "/resources/pools/pool1", string(data), nil)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var updated apiTypes.PoolResource
+	err = json.Unmarshal(rec.Body.Bytes(), &updated)
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Public, check.Equals, true)
+	c.Assert(updated.Labels, check.DeepEquals, map[string]string{"env": "prod"})
+	c.Assert(updated.Revision, check.Not(check.Equals), res.Revision)
+}
+
+func (s *S) TestResourceImport(c *check.C) {
+	_, err := permission.NewRole("test", "global", "")
+	c.Assert(err, check.IsNil)
+	rec := s.doResourceRequest(c, http.MethodGet, "/resources/import?type=role&id=test", "", nil)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var result struct {
+		apiTypes.ImportResource
+		Resource apiTypes.RoleResource `json:"resource"`
+	}
+	err = json.Unmarshal(rec.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Type, check.Equals, "role")
+	c.Assert(result.ID, check.Equals, "test")
+	c.Assert(result.Resource.Name, check.Equals, "test")
+	c.Assert(result.Revision, check.Equals, result.Resource.Revision)
+}
+
+func (s *S) TestResourceImportInvalid(c *check.C) {
+	rec := s.doResourceRequest(c, http.MethodGet, "/resources/import?type=unknown&id=x", "", nil)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	rec = s.doResourceRequest(c, http.MethodGet, "/resources/import?type=service-binding&id=x", "", nil)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestPoolResourceCreate(c *check.C) {
+	body := `{"name": "newpool", "public": true, "labels": {"env": "dev"}}`
+	rec := s.doResourceRequest(c, http.MethodPut, "/resources/pools/newpool", body, map[string]string{"If-None-Match": "*"})
+	c.Assert(rec.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", rec.Body.String()))
+	var res apiTypes.PoolResource
+	err := json.Unmarshal(rec.Body.Bytes(), &res)
+	c.Assert(err, check.IsNil)
+	c.Assert(res.Public, check.Equals, true)
+	c.Assert(res.Labels, check.DeepEquals, map[string]string{"env": "dev"})
+	c.Assert(rec.Header().Get("ETag"), check.Equals, `"`+res.Revision+`"`)
+	p, err := pool.GetPoolByName(context.TODO(), "newpool")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Name, check.Equals, "newpool")
+	rec = s.doResourceRequest(c, http.MethodPut, "/resources/pools/newpool", body, map[string]string{"If-None-Match": "*"})
+	c.Assert(rec.Code, check.Equals, http.StatusPreconditionFailed)
+}
+
+func (s *S) TestResourceUpdateNameMismatch(c *check.C) {
+	rec := s.doResourceRequest(c, http.MethodPut, "/resources/pools/pool1", `{"name": "other"}`, map[string]string{"If-None-Match": "*"})
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestPlanResourceCreate(c *check.C) {
+	var created appTypes.Plan
+	s.mockService.Plan.OnCreate = func(p appTypes.Plan) error {
+		created = p
+		s.plan = p
+		return nil
+	}
+	body := `{"name": "large", "memory": 1024, "cpumilli": 500}`
+	rec := s.doResourceRequest(c, http.MethodPut, "/resources/plans/large", body, map[string]string{"If-None-Match": "*"})
+	c.Assert(rec.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", rec.Body.String()))
+	c.Assert(created, check.DeepEquals, appTypes.Plan{Name: "large", Memory: 1024, CPUMilli: 500})
+	var res apiTypes.PlanResource
+	err := json.Unmarshal(rec.Body.Bytes(), &res)
+	c.Assert(err, check.IsNil)
+	s.mockService.Plan.OnUpdate = func(p appTypes.Plan) error {
+		s.plan = p
+		return nil
+	}
+	res.PidsLimit = 512
+	data, err := json.Marshal(res)
+	c.Assert(err, check.IsNil)
+	rec = s.doResourceRequest(c, http.MethodPut, "/resources/plans/large", string(data), nil)
+	c.Assert(rec.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", rec.Body.String()))
+	c.Assert(s.plan.PidsLimit, check.Equals, int64(512))
+	c.Assert(s.plan.Memory, check.Equals, int64(1024))
+}
+
+func (s *S) TestResourceUpdateIdempotencyKey(c *check.C) {
+	headers := map[string]string{"If-None-Match": "*", "Idempotency-Key": "k1"}
+	body := `{"name": "idempotent-pool"}`
+	rec := s.doResourceRequest(c, http.MethodPut, "/resources/pools/idempotent-pool", body, headers)
+	c.Assert(rec.Code, check.Equals, http.StatusCreated, check.Commentf("body: %s", rec.Body.String()))
+	first := rec.Body.String()
+	rec = s.doResourceRequest(c, http.MethodPut, "/resources/pools/idempotent-pool", body, headers)
+	c.Assert(rec.Code, check.Equals, http.StatusCreated)
+	c.Assert(rec.Header().Get("Idempotent-Replayed"), check.Equals, "true")
+	c.Assert(rec.Body.String(), check.Equals, first)
+	rec = s.doResourceRequest(c, http.MethodPut, "/resources/pools/idempotent-pool", `{"name": "idempotent-pool", "public": true}`, headers)
+	c.Assert(rec.Code, check.Equals, http.StatusUnprocessableEntity)
+}
+
+func (s *S) createResourceServiceInstance(c *check.C) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	srvc := service.Service{Name: "mysql", Endpoint: map[string]string{"production": ts.URL}, Password: "abcde", OwnerTeams: []string{s.team.Name}}
+	err := service.Create(srvc)
+	c.Assert(err, check.IsNil)
+	instance := service.ServiceInstance{Name: "my-mysql", ServiceName: "mysql", TeamOwner: s.team.Name, Teams: []string{s.team.Name}}
+	err = s.conn.ServiceInstances().Insert(instance)
+	c.Assert(err, check.IsNil)
+	return ts
+}
+
+func (s *S) getServiceInstanceResource(c *check.C) apiTypes.ServiceInstanceResource {
+	rec := s.doResourceRequest(c, http.MethodGet, "/resources/service-instances/mysql/my-mysql", "", nil)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var res apiTypes.ServiceInstanceResource
+	err := json.Unmarshal(rec.Body.Bytes(), &res)
+	c.Assert(err, check.IsNil)
+	return res
+}
+
+func (s *S) TestServiceInstanceResourceUpdateTeams(c *check.C) {
+	ts := s.createResourceServiceInstance(c)
+	defer ts.Close()
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		if name == "unknown" {
+			return nil, authTypes.ErrTeamNotFound
+		}
+		return &authTypes.Team{Name: name}, nil
+	}
+	res := s.getServiceInstanceResource(c)
+	c.Assert(res.Teams, check.DeepEquals, []string{s.team.Name})
+	res.Teams = []string{"other-team"}
+	data, err := json.Marshal(res)
+	c.Assert(err, check.IsNil)
+	rec := s.doResourceRequest(c, http.MethodPut, "/resources/service-instances/mysql/my-mysql", string(data), nil)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	var updated apiTypes.ServiceInstanceResource
+	err = json.Unmarshal(rec.Body.Bytes(), &updated)
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Teams, check.DeepEquals, sortedCopy([]string{"other-team", s.team.Name}))
+	instance, err := service.GetServiceInstance(context.TODO(), "mysql", "my-mysql")
+	c.Assert(err, check.IsNil)
+	c.Assert(sortedCopy(instance.Teams), check.DeepEquals, sortedCopy([]string{"other-team", s.team.Name}))
+	updated.Teams = []string{"unknown"}
+	data, err = json.Marshal(updated)
+	c.Assert(err, check.IsNil)
+	rec = s.doResourceRequest(c, http.MethodPut, "/resources/service-instances/mysql/my-mysql", string(data), nil)
+	c.Assert(rec.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(rec.Body.String(), check.Equals, "team \"unknown\" not found\n")
+}
+
+func (s *S) TestServiceInstanceResourceUpdateConflict(c *check.C) {
+	ts := s.createResourceServiceInstance(c)
+	defer ts.Close()
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	res := s.getServiceInstanceResource(c)
+	instance, err := service.GetServiceInstance(context.TODO(), "mysql", "my-mysql")
+	c.Assert(err, check.IsNil)
+	err = instance.Grant("other-team")
+	c.Assert(err, check.IsNil)
+	res.Description = "new desc"
+	data, err := json.Marshal(res)
+	c.Assert(err, check.IsNil)
+	rec := s.doResourceRequest(c, http.MethodPut, "/resources/service-instances/mysql/my-mysql", string(data), nil)
+	c.Assert(rec.Code, check.Equals, http.StatusPreconditionFailed)
+	instance, err = service.GetServiceInstance(context.TODO(), "mysql", "my-mysql")
+	c.Assert(err, check.IsNil)
+	c.Assert(instance.Description, check.Equals, "")
+	res = s.getServiceInstanceResource(c)
+	res.Description = "new desc"
+	data, err = json.Marshal(res)
+	c.Assert(err, check.IsNil)
+	rec = s.doResourceRequest(c, http.MethodPut, "/resources/service-instances/mysql/my-mysql", string(data), nil)
+	c.Assert(rec.Code, check.Equals, http.StatusOK)
+	rec = s.doResourceRequest(c, http.MethodPut, "/resources/service-instances/mysql/my-mysql", string(data), nil)
+	c.Assert(rec.Code, check.Equals, http.StatusPreconditionFailed)
+}
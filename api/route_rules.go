@@ -0,0 +1,167 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+)
+
+// title: list app route rules
+// path: /apps/{app}/routes/rules
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func listAppRouteRules(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadRouter,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	routers := a.GetRouters()
+	if len(routers) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	result := make([]routerTypes.AppRouteRules, len(routers))
+	for i, appRouter := range routers {
+		result[i] = routerTypes.AppRouteRules{Router: appRouter.Name, Rules: appRouter.Rules}
+		if result[i].Rules == nil {
+			result[i].Rules = []routerTypes.RouteRule{}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(result)
+}
+
+// title: set app route rules
+// path: /apps/{app}/routes/rules
+// method: PUT
+// consume: application/json
+// responses:
+//   200: OK
+//   400: Invalid rules
+//   401: Unauthorized
+//   404: App or router not found
+func setAppRouteRules(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var req routerTypes.AppRouteRules
+	err = ParseInput(r, &req)
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateRouterUpdate,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	appRouter, err := routeRulesRouter(&a, req.Router)
+	if err != nil {
+		return err
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateRouterUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.SetRouteRules(appRouter.Name, req.Rules)
+}
+
+// title: remove app route rule
+// path: /apps/{app}/routes/rules/{rule}
+// method: DELETE
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App, router or rule not found
+func removeAppRouteRule(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	appName := r.URL.Query().Get(":app")
+	ruleName := r.URL.Query().Get(":rule")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateRouterUpdate,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	appRouter, err := routeRulesRouter(&a, InputValue(r, "router"))
+	if err != nil {
+		return err
+	}
+	var rules []routerTypes.RouteRule
+	for _, rule := range appRouter.Rules {
+		if rule.Name != ruleName {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == len(appRouter.Rules) {
+		return &errors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("rule %q not found in router %q", ruleName, appRouter.Name)}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateRouterUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.SetRouteRules(appRouter.Name, rules)
+}
+
+// routeRulesRouter returns the app router named routerName, which may be
+// omitted when the app has a single router.
+func routeRulesRouter(a *app.App, routerName string) (*appTypes.AppRouter, error) {
+	routers := a.GetRouters()
+	if routerName == "" {
+		if len(routers) != 1 {
+			return nil, &errors.HTTP{Code: http.StatusBadRequest, Message: "router is required when the app has more than one router"}
+		}
+		return &routers[0], nil
+	}
+	for i := range routers {
+		if routers[i].Name == routerName {
+			return &routers[i], nil
+		}
+	}
+	return nil, &errors.HTTP{Code: http.StatusNotFound, Message: (&router.ErrRouterNotFound{Name: routerName}).Error()}
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createRouteRulesApp(c *check.C) *app.App {
+	config.Set("routers:fake-rules:type", "fake-rules")
+	myapp := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	err = myapp.AddRouter(appTypes.AppRouter{Name: "fake-rules"})
+	c.Assert(err, check.IsNil)
+	return &myapp
+}
+
+func (s *S) TestSetAppRouteRules(c *check.C) {
+	defer config.Unset("routers:fake-rules")
+	defer routertest.RulesRouter.Reset()
+	s.createRouteRulesApp(c)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppUpdateRouterUpdate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader(`{"router":"fake-rules","rules":[{"name":"beta","match":{"headers":{"X-Beta":"1"}},"backends":[{"version":0,"weight":100}]}]}`)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/routes/rules", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	expected := []routerTypes.RouteRule{{
+		Name:     "beta",
+		Match:    routerTypes.RouteMatch{Headers: map[string]string{"X-Beta": "1"}},
+		Backends: []routerTypes.WeightedBackend{{Version: 0, Weight: 100}},
+	}}
+	c.Assert(routertest.RulesRouter.Rules["myapp"], check.DeepEquals, expected)
+	dbApp, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	routers := dbApp.GetRouters()
+	c.Assert(routers, check.HasLen, 2)
+	c.Assert(routers[1].Rules, check.DeepEquals, expected)
+
+	request, err = http.NewRequest("GET", "/1.13/apps/myapp/routes/rules", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result []routerTypes.AppRouteRules
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.DeepEquals, []routerTypes.AppRouteRules{
+		{Router: "fake", Rules: []routerTypes.RouteRule{}},
+		{Router: "fake-rules", Rules: expected},
+	})
+
+	request, err = http.NewRequest("DELETE", "/1.13/apps/myapp/routes/rules/beta?router=fake-rules", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(routertest.RulesRouter.Rules["myapp"], check.HasLen, 0)
+}
+
+func (s *S) TestSetAppRouteRulesInvalidWeights(c *check.C) {
+	defer config.Unset("routers:fake-rules")
+	s.createRouteRulesApp(c)
+	body := strings.NewReader(`{"router":"fake-rules","rules":[{"name":"beta","match":{"pathPrefix":"/beta"},"backends":[{"version":0,"weight":50}]}]}`)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/routes/rules", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "rule \"beta\" backend weights must sum 100, got 50\n")
+}
+
+func (s *S) TestSetAppRouteRulesRouterRequired(c *check.C) {
+	defer config.Unset("routers:fake-rules")
+	s.createRouteRulesApp(c)
+	body := strings.NewReader(`{"rules":[]}`)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/routes/rules", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "router is required when the app has more than one router\n")
+}
+
+func (s *S) TestSetAppRouteRulesNotSupported(c *check.C) {
+	myapp := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"rules":[{"name":"beta","match":{"pathPrefix":"/beta"},"backends":[{"version":0,"weight":100}]}]}`)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/routes/rules", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "route rules are not supported by router \"fake\"\n")
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+)
+
+// title: get app router policy
+// path: /apps/{app}/router-policies
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func getAppRouterPolicy(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadRouter,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	policy, err := a.GetRouterPolicy()
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(policy)
+}
+
+// title: set app router policy
+// path: /apps/{app}/router-policies
+// method: PUT
+// consume: application/json
+// responses:
+//   200: OK
+//   400: Invalid policy
+//   401: Unauthorized
+//   404: App not found
+func setAppRouterPolicy(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var policy routerTypes.Policy
+	err = ParseInput(r, &policy)
+	if err != nil {
+		return err
+	}
+	return updateAppRouterPolicy(r, t, &policy)
+}
+
+// title: remove app router policy
+// path: /apps/{app}/router-policies
+// method: DELETE
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App not found
+func removeAppRouterPolicy(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return updateAppRouterPolicy(r, t, nil)
+}
+
+func updateAppRouterPolicy(r *http.Request, t auth.Token, policy *routerTypes.Policy) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateRouterUpdate,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateRouterUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.SetRouterPolicy(policy)
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router/routertest"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetAppRouterPolicy(c *check.C) {
+	config.Set("routers:fake-policy:type", "fake-policy")
+	defer config.Unset("routers:fake-policy")
+	defer routertest.PolicyRouter.Reset()
+	myapp := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "fake-policy"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppUpdateRouterUpdate,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	body := strings.NewReader(`{"rateLimit":{"requestsPerSecond":50,"burst":10},"allowCIDRs":["10.0.0.0/8"],"maxBodySize":1048576}`)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/router-policies", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	expected := &routerTypes.Policy{
+		RateLimit:   &routerTypes.RateLimit{RequestsPerSecond: 50, Burst: 10},
+		AllowCIDRs:  []string{"10.0.0.0/8"},
+		MaxBodySize: 1048576,
+	}
+	c.Assert(routertest.PolicyRouter.Policies["myapp"], check.DeepEquals, expected)
+
+	request, err = http.NewRequest("GET", "/1.13/apps/myapp/router-policies", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var result routerTypes.Policy
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(&result, check.DeepEquals, expected)
+
+	request, err = http.NewRequest("DELETE", "/1.13/apps/myapp/router-policies", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(routertest.PolicyRouter.Policies["myapp"], check.IsNil)
+
+	request, err = http.NewRequest("GET", "/1.13/apps/myapp/router-policies", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestSetAppRouterPolicyInvalid(c *check.C) {
+	config.Set("routers:fake-policy:type", "fake-policy")
+	defer config.Unset("routers:fake-policy")
+	myapp := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "fake-policy"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"denyCIDRs":["invalid"]}`)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/router-policies", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "invalid CIDR \"invalid\"\n")
+}
+
+func (s *S) TestSetAppRouterPolicyNotSupported(c *check.C) {
+	myapp := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader(`{"maxBodySize":1024}`)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/router-policies", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "router policies are not supported by the app routers\n")
+}
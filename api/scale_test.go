@@ -57,6 +57,43 @@ func (s *S) TestAutoScaleUnitsInfo(c *check.C) {
 	c.Assert(autoscales[0], check.DeepEquals, autoscaleSpec)
 }
 
+func (s *S) TestAutoScaleStatus(c *check.C) {
+	provision.DefaultProvisioner = "autoscaleProv"
+	provision.Register("autoscaleProv", func() (provision.Provisioner, error) {
+		return &provisiontest.AutoScaleProvisioner{FakeProvisioner: provisiontest.ProvisionerInstance}, nil
+	})
+	defer provision.Unregister("autoscaleProv")
+
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+
+	autoscaleSpec := provision.AutoScaleSpec{
+		Process:  "p1",
+		MaxUnits: 10,
+		MinUnits: 2,
+		Metrics: []provision.AutoScaleMetric{
+			{Type: provision.AutoScaleMetricExternal, Name: "queue_depth", Target: "30"},
+		},
+	}
+	err = a.AutoScale(autoscaleSpec)
+	c.Assert(err, check.IsNil)
+
+	request, err := http.NewRequest("GET", "/apps/myapp/autoscale", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+
+	var autoscales []provision.AutoScaleSpec
+	err = json.Unmarshal(recorder.Body.Bytes(), &autoscales)
+	c.Assert(err, check.IsNil)
+	autoscaleSpec.Status = &provision.AutoScaleStatus{CurrentUnits: 2, DesiredUnits: 2}
+	c.Assert(autoscales, check.DeepEquals, []provision.AutoScaleSpec{autoscaleSpec})
+}
+
 func (s *S) TestAddAutoScaleUnits(c *check.C) {
 	s.mockService.AppQuota.OnGet = func(item quota.QuotaItem) (*quota.Quota, error) {
 		c.Assert(item.GetName(), check.Equals, "myapp")
@@ -16,6 +16,7 @@ import (
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -27,23 +28,38 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/observability"
+	"github.com/tsuru/tsuru/api/ratelimit"
 	apiRouter "github.com/tsuru/tsuru/api/router"
 	"github.com/tsuru/tsuru/api/shutdown"
 	"github.com/tsuru/tsuru/api/tracker"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/acme"
 	"github.com/tsuru/tsuru/app/bind"
 	"github.com/tsuru/tsuru/app/image"
 	"github.com/tsuru/tsuru/app/image/gc"
+	"github.com/tsuru/tsuru/app/maintenance"
+	"github.com/tsuru/tsuru/app/metering"
+	"github.com/tsuru/tsuru/app/preview"
+	"github.com/tsuru/tsuru/app/reconcile"
+	"github.com/tsuru/tsuru/app/snapshot"
 	"github.com/tsuru/tsuru/app/version"
 	"github.com/tsuru/tsuru/applog"
 	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/dirsync"
 	_ "github.com/tsuru/tsuru/auth/native"
 	_ "github.com/tsuru/tsuru/auth/oauth"
 	_ "github.com/tsuru/tsuru/auth/saml"
 	"github.com/tsuru/tsuru/autoscale"
 	"github.com/tsuru/tsuru/db"
+	_ "github.com/tsuru/tsuru/dns/clouddns"
+	_ "github.com/tsuru/tsuru/dns/rfc2136"
+	_ "github.com/tsuru/tsuru/dns/route53"
 	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/event/audit"
+	"github.com/tsuru/tsuru/event/bus"
+	"github.com/tsuru/tsuru/event/notification"
 	"github.com/tsuru/tsuru/event/webhook"
+	"github.com/tsuru/tsuru/gitops"
 	"github.com/tsuru/tsuru/hc"
 	"github.com/tsuru/tsuru/healer"
 	"github.com/tsuru/tsuru/log"
@@ -51,6 +67,7 @@ import (
 	"github.com/tsuru/tsuru/provision/cluster"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
 	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/readcache"
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/router/rebuild"
 	"github.com/tsuru/tsuru/service"
@@ -148,6 +165,18 @@ func setupServices() error {
 	if err != nil {
 		return err
 	}
+	servicemanager.Notification, err = notification.NotificationService()
+	if err != nil {
+		return err
+	}
+	servicemanager.Audit, err = audit.AuditService()
+	if err != nil {
+		return err
+	}
+	servicemanager.EventPublisher, err = bus.PublisherService()
+	if err != nil {
+		return err
+	}
 	servicemanager.Cluster, err = cluster.ClusterService()
 	if err != nil {
 		return err
@@ -233,6 +262,7 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodGet, "/services/{service}/instances/{instance}/status", AuthorizationRequiredHandler(serviceInstanceStatus))
 	m.Add("1.0", http.MethodPut, "/services/{service}/instances/{instance}/{app}", AuthorizationRequiredHandler(bindServiceInstance))
 	m.Add("1.0", http.MethodDelete, "/services/{service}/instances/{instance}/{app}", AuthorizationRequiredHandler(unbindServiceInstance))
+	m.Add("1.13", http.MethodPut, "/services/{service}/instances/{instance}/{app}/credentials", AuthorizationRequiredHandler(serviceInstanceRotateCredentials))
 	m.Add("1.0", http.MethodPut, "/services/{service}/instances/permission/{instance}/{team}", AuthorizationRequiredHandler(serviceInstanceGrantTeam))
 	m.Add("1.0", http.MethodDelete, "/services/{service}/instances/permission/{instance}/{team}", AuthorizationRequiredHandler(serviceInstanceRevokeTeam))
 
@@ -255,6 +285,22 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodGet, "/apps/{app}", AuthorizationRequiredHandler(appInfo))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}", AuthorizationRequiredHandler(appDelete))
 	m.Add("1.0", http.MethodPut, "/apps/{app}", AuthorizationRequiredHandler(updateApp))
+	m.Add("1.13", http.MethodPost, "/apps/apply", AuthorizationRequiredHandler(appApply))
+	m.Add("1.13", http.MethodPost, "/apps/bulk", AuthorizationRequiredHandler(appBulk))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/reconcile", AuthorizationRequiredHandler(appReconcileInfo))
+	m.Add("1.13", http.MethodDelete, "/apps/{app}/reconcile", AuthorizationRequiredHandler(appReconcileRemove))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/reconcile/pause", AuthorizationRequiredHandler(appReconcilePause))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/reconcile/resume", AuthorizationRequiredHandler(appReconcileResume))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/maintenance", AuthorizationRequiredHandler(appMaintenanceList))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/maintenance", AuthorizationRequiredHandler(appMaintenanceSchedule))
+	m.Add("1.13", http.MethodDelete, "/apps/{app}/maintenance/{id}", AuthorizationRequiredHandler(appMaintenanceCancel))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/snapshots", AuthorizationRequiredHandler(appSnapshotList))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/restore", AuthorizationRequiredHandler(appRestore))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/archive", AuthorizationRequiredHandler(appArchive))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/unarchive", AuthorizationRequiredHandler(appUnarchive))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/protection", AuthorizationRequiredHandler(appProtectionSet))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/dependencies", AuthorizationRequiredHandler(appDependencyAdd))
+	m.Add("1.13", http.MethodDelete, "/apps/{app}/dependencies/{dependency}", AuthorizationRequiredHandler(appDependencyRemove))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/cname", AuthorizationRequiredHandler(setCName))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}/cname", AuthorizationRequiredHandler(unsetCName))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/run", AuthorizationRequiredHandler(runCommand))
@@ -263,43 +309,118 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/apps/{app}/stop", AuthorizationRequiredHandler(stop))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/sleep", AuthorizationRequiredHandler(sleep))
 	m.Add("1.10", http.MethodDelete, "/apps/{app}/versions/{version}", AuthorizationRequiredHandler(appVersionDelete))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/versions/{version}/artifact", AuthorizationRequiredHandler(appVersionArtifact))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/versions", AuthorizationRequiredHandler(appVersionList))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/versions/{version}/traffic", AuthorizationRequiredHandler(appVersionTraffic))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/versions/{version}/retire", AuthorizationRequiredHandler(appVersionRetire))
 	m.Add("1.0", http.MethodGet, "/apps/{app}/quota", AuthorizationRequiredHandler(getAppQuota))
 	m.Add("1.0", http.MethodPut, "/apps/{app}/quota", AuthorizationRequiredHandler(changeAppQuota))
 	m.Add("1.0", http.MethodGet, "/apps/{app}/env", AuthorizationRequiredHandler(getEnv))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/env", AuthorizationRequiredHandler(setEnv))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}/env", AuthorizationRequiredHandler(unsetEnv))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/env/versions", AuthorizationRequiredHandler(appConfigVersionList))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/env/revisions", AuthorizationRequiredHandler(appEnvRevisionList))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/env/revisions/{revision}/diff", AuthorizationRequiredHandler(appEnvRevisionDiff))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/env/rollback", AuthorizationRequiredHandler(appEnvRollback))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}/lock", AuthorizationRequiredHandler(forceDeleteLock))
 	m.Add("1.0", http.MethodPut, "/apps/{app}/units", AuthorizationRequiredHandler(addUnits))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}/units", AuthorizationRequiredHandler(removeUnits))
 	m.Add("1.9", http.MethodGet, "/apps/{app}/units/autoscale", AuthorizationRequiredHandler(autoScaleUnitsInfo))
 	m.Add("1.9", http.MethodPost, "/apps/{app}/units/autoscale", AuthorizationRequiredHandler(addAutoScaleUnits))
 	m.Add("1.9", http.MethodDelete, "/apps/{app}/units/autoscale", AuthorizationRequiredHandler(removeAutoScaleUnits))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/autoscale", AuthorizationRequiredHandler(autoScaleStatus))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/units/register", AuthorizationRequiredHandler(registerUnit))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/units/{unit}", AuthorizationRequiredHandler(setUnitStatus))
 	m.Add("1.12", http.MethodDelete, "/apps/{app}/units/{unit}", AuthorizationRequiredHandler(killUnit))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/units/{unit}/restart", AuthorizationRequiredHandler(restartUnit))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/units/{unit}/healing-history", AuthorizationRequiredHandler(unitHealingHistory))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/units/{unit}/history", AuthorizationRequiredHandler(unitStatusHistory))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/units/{unit}/metrics", AuthorizationRequiredHandler(unitMetrics))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/uptime", AuthorizationRequiredHandler(appUptime))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/availability", AuthorizationRequiredHandler(appAvailability))
+	m.Add("1.13", http.MethodGet, "/usage", AuthorizationRequiredHandler(resourceUsage))
+	m.Add("1.13", http.MethodGet, "/backup", AuthorizationRequiredHandler(exportBackup))
 	m.Add("1.0", http.MethodPut, "/apps/{app}/teams/{team}", AuthorizationRequiredHandler(grantAppAccess))
 	m.Add("1.0", http.MethodDelete, "/apps/{app}/teams/{team}", AuthorizationRequiredHandler(revokeAppAccess))
 	m.AddNamed("log-get", "1.0", http.MethodGet, "/apps/{app}/log", AuthorizationRequiredHandler(appLog))
 	m.AddNamed("log-get-instance", "1.8", http.MethodGet, "/apps/{app}/log-instance", AuthorizationRequiredHandler(appLog))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/log", AuthorizationRequiredHandler(addLog))
+	m.Add("1.13", http.MethodGet, "/logs/trace/{id}", AuthorizationRequiredHandler(logTrace))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/deploy/rollback", AuthorizationRequiredHandler(deployRollback))
 	m.Add("1.4", http.MethodPut, "/apps/{app}/deploy/rollback/update", AuthorizationRequiredHandler(deployRollbackUpdate))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/deploy/auto-rollback", AuthorizationRequiredHandler(deployAutoRollbackUpdate))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/deploy/approval", AuthorizationRequiredHandler(deployApprovalUpdate))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/deploy/gates", AuthorizationRequiredHandler(deployGatesUpdate))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/disruption", AuthorizationRequiredHandler(disruptionInfo))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/disruption", AuthorizationRequiredHandler(disruptionUpdate))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/placement", AuthorizationRequiredHandler(placementUpdate))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/placement/nodes", AuthorizationRequiredHandler(placementNodes))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/project", AuthorizationRequiredHandler(appProjectSet))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/env-groups/{group}", AuthorizationRequiredHandler(appEnvGroupAttach))
+	m.Add("1.13", http.MethodDelete, "/apps/{app}/env-groups/{group}", AuthorizationRequiredHandler(appEnvGroupDetach))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/deploy/delta-base", AuthorizationRequiredHandler(deployDeltaBase))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/deploy/requests", AuthorizationRequiredHandler(deployRequestList))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/deploy/requests/{id}/approve", AuthorizationRequiredHandler(deployRequestApprove))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/deploy/requests/{id}/reject", AuthorizationRequiredHandler(deployRequestReject))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/deploy-hook", AuthorizationRequiredHandler(deployHookInfo))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/deploy-hook", AuthorizationRequiredHandler(deployHookSet))
+	m.Add("1.13", http.MethodDelete, "/apps/{app}/deploy-hook", AuthorizationRequiredHandler(deployHookRemove))
+	m.Add("1.13", http.MethodPost, "/hooks/deploy/{app}", Handler(deployHookReceive))
+	m.Add("1.13", http.MethodGet, "/pipeline-hooks", AuthorizationRequiredHandler(pipelineHookList))
+	m.Add("1.13", http.MethodPost, "/pipeline-hooks", AuthorizationRequiredHandler(pipelineHookCreate))
+	m.Add("1.13", http.MethodGet, "/pipeline-hooks/{name}", AuthorizationRequiredHandler(pipelineHookInfo))
+	m.Add("1.13", http.MethodPut, "/pipeline-hooks/{name}", AuthorizationRequiredHandler(pipelineHookUpdate))
+	m.Add("1.13", http.MethodDelete, "/pipeline-hooks/{name}", AuthorizationRequiredHandler(pipelineHookDelete))
+
+	m.Add("1.13", http.MethodGet, "/directory-sync/mappings", AuthorizationRequiredHandler(dirSyncMappingList))
+	m.Add("1.13", http.MethodPost, "/directory-sync/mappings", AuthorizationRequiredHandler(dirSyncMappingCreate))
+	m.Add("1.13", http.MethodDelete, "/directory-sync/mappings/{id}", AuthorizationRequiredHandler(dirSyncMappingDelete))
+	m.Add("1.13", http.MethodPost, "/directory-sync/run", AuthorizationRequiredHandler(dirSyncRun))
+
+	m.Add("1.13", http.MethodGet, "/graphql", AuthorizationRequiredHandler(graphqlHandler))
+	m.Add("1.13", http.MethodPost, "/graphql", AuthorizationRequiredHandler(graphqlHandler))
+
+	m.Add("1.13", http.MethodGet, "/apps/{app}/previews", AuthorizationRequiredHandler(appPreviewList))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/previews", AuthorizationRequiredHandler(appPreviewCreate))
+	m.Add("1.13", http.MethodDelete, "/apps/{app}/previews/{pr}", AuthorizationRequiredHandler(appPreviewRemove))
+	m.Add("1.13", http.MethodPost, "/hooks/preview/{app}", Handler(appPreviewHookReceive))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/clone", AuthorizationRequiredHandler(appClone))
 	m.Add("1.3", http.MethodPost, "/apps/{app}/deploy/rebuild", AuthorizationRequiredHandler(deployRebuild))
 	m.Add("1.0", http.MethodGet, "/apps/{app}/metric/envs", AuthorizationRequiredHandler(appMetricEnvs))
 	m.Add("1.0", http.MethodPost, "/apps/{app}/routes", AuthorizationRequiredHandler(appRebuildRoutes))
 	m.Add("1.2", http.MethodGet, "/apps/{app}/certificate", AuthorizationRequiredHandler(listCertificates))
 	m.Add("1.2", http.MethodPut, "/apps/{app}/certificate", AuthorizationRequiredHandler(setCertificate))
 	m.Add("1.2", http.MethodDelete, "/apps/{app}/certificate", AuthorizationRequiredHandler(unsetCertificate))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/certificates", AuthorizationRequiredHandler(appCertificatesStatus))
 
 	m.Add("1.5", http.MethodPost, "/apps/{app}/routers", AuthorizationRequiredHandler(addAppRouter))
 	m.Add("1.5", http.MethodPut, "/apps/{app}/routers/{router}", AuthorizationRequiredHandler(updateAppRouter))
 	m.Add("1.5", http.MethodDelete, "/apps/{app}/routers/{router}", AuthorizationRequiredHandler(removeAppRouter))
 	m.Add("1.5", http.MethodGet, "/apps/{app}/routers", AuthorizationRequiredHandler(listAppRouters))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/routes/rules", AuthorizationRequiredHandler(listAppRouteRules))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/routes/rules", AuthorizationRequiredHandler(setAppRouteRules))
+	m.Add("1.13", http.MethodDelete, "/apps/{app}/routes/rules/{rule}", AuthorizationRequiredHandler(removeAppRouteRule))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/router-policies", AuthorizationRequiredHandler(getAppRouterPolicy))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/router-policies", AuthorizationRequiredHandler(setAppRouterPolicy))
+	m.Add("1.13", http.MethodDelete, "/apps/{app}/router-policies", AuthorizationRequiredHandler(removeAppRouterPolicy))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/visibility", AuthorizationRequiredHandler(setAppVisibility))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/shadow", AuthorizationRequiredHandler(appShadowReport))
+	m.Add("1.13", http.MethodPut, "/apps/{app}/shadow", AuthorizationRequiredHandler(setAppShadow))
+	m.Add("1.13", http.MethodDelete, "/apps/{app}/shadow", AuthorizationRequiredHandler(removeAppShadow))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/exposed-endpoints", AuthorizationRequiredHandler(listAppExposedEndpoints))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/discovery", AuthorizationRequiredHandler(listAppDiscoveryEntries))
+	m.Add("1.13", http.MethodGet, "/discovery/{name}", AuthorizationRequiredHandler(resolveDiscoveryName))
+	m.Add("1.13", http.MethodGet, "/apps/{app}/domains", AuthorizationRequiredHandler(listAppDomains))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/domains", AuthorizationRequiredHandler(addAppDomain))
+	m.Add("1.13", http.MethodPost, "/apps/{app}/domains/{domain}/verify", AuthorizationRequiredHandler(verifyAppDomain))
+	m.Add("1.13", http.MethodDelete, "/apps/{app}/domains/{domain}", AuthorizationRequiredHandler(removeAppDomain))
 	m.Add("1.8", http.MethodPost, "/apps/{app}/routable", AuthorizationRequiredHandler(appSetRoutable))
 
 	m.Add("1.0", http.MethodPost, "/node/status", AuthorizationRequiredHandler(setNodeStatus))
 
+	m.Add("1.13", http.MethodPost, "/deploy/group", AuthorizationRequiredHandler(deployGroup))
 	m.Add("1.0", http.MethodGet, "/deploys", AuthorizationRequiredHandler(deploysList))
+	m.Add("1.13", http.MethodGet, "/deploys/stats", AuthorizationRequiredHandler(deployStats))
 	m.Add("1.0", http.MethodGet, "/deploys/{deploy}", AuthorizationRequiredHandler(deployInfo))
 
 	m.Add("1.1", http.MethodGet, "/events", AuthorizationRequiredHandler(eventList))
@@ -316,6 +437,27 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.6", http.MethodPut, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookUpdate))
 	m.Add("1.6", http.MethodDelete, "/events/webhooks/{name}", AuthorizationRequiredHandler(webhookDelete))
 
+	m.Add("1.13", http.MethodGet, "/notifications/channels", AuthorizationRequiredHandler(notificationChannelList))
+	m.Add("1.13", http.MethodPost, "/notifications/channels", AuthorizationRequiredHandler(notificationChannelCreate))
+	m.Add("1.13", http.MethodGet, "/notifications/channels/{name}", AuthorizationRequiredHandler(notificationChannelInfo))
+	m.Add("1.13", http.MethodPut, "/notifications/channels/{name}", AuthorizationRequiredHandler(notificationChannelUpdate))
+	m.Add("1.13", http.MethodDelete, "/notifications/channels/{name}", AuthorizationRequiredHandler(notificationChannelDelete))
+
+	m.Add("1.13", http.MethodGet, "/integrations", AuthorizationRequiredHandler(integrationList))
+	m.Add("1.13", http.MethodPost, "/integrations", AuthorizationRequiredHandler(integrationCreate))
+	m.Add("1.13", http.MethodGet, "/integrations/{name}", AuthorizationRequiredHandler(integrationInfo))
+	m.Add("1.13", http.MethodPut, "/integrations/{name}", AuthorizationRequiredHandler(integrationUpdate))
+	m.Add("1.13", http.MethodDelete, "/integrations/{name}", AuthorizationRequiredHandler(integrationDelete))
+
+	m.Add("1.13", http.MethodGet, "/gitops", AuthorizationRequiredHandler(gitopsStatus))
+	m.Add("1.13", http.MethodPost, "/gitops/plan", AuthorizationRequiredHandler(gitopsPlan))
+	m.Add("1.13", http.MethodPut, "/gitops/bundle", AuthorizationRequiredHandler(gitopsBundleUpload))
+	m.Add("1.13", http.MethodPost, "/gitops/sync", AuthorizationRequiredHandler(gitopsSync))
+
+	m.Add("1.13", http.MethodPost, "/chatops/command", Handler(chatOpsCommand))
+	m.Add("1.13", http.MethodPost, "/chatops/link", AuthorizationRequiredHandler(chatOpsLink))
+	m.Add("1.13", http.MethodDelete, "/chatops/link", AuthorizationRequiredHandler(chatOpsUnlink))
+
 	m.Add("1.0", http.MethodGet, "/platforms", AuthorizationRequiredHandler(platformList))
 	m.Add("1.0", http.MethodPost, "/platforms", AuthorizationRequiredHandler(platformAdd))
 	m.Add("1.0", http.MethodPut, "/platforms/{name}", AuthorizationRequiredHandler(platformUpdate))
@@ -368,6 +510,7 @@ func RunServer(dry bool) http.Handler {
 
 	m.Add("1.0", http.MethodGet, "/healthcheck/", http.HandlerFunc(healthcheck))
 	m.Add("1.0", http.MethodGet, "/healthcheck", http.HandlerFunc(healthcheck))
+	m.Add("1.13", http.MethodGet, "/.well-known/acme-challenge/{token}", http.HandlerFunc(acmeChallenge))
 
 	m.Add("1.0", http.MethodGet, "/iaas/machines", AuthorizationRequiredHandler(machinesList))
 	m.Add("1.0", http.MethodDelete, "/iaas/machines/{machine_id}", AuthorizationRequiredHandler(machineDestroy))
@@ -379,6 +522,26 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodGet, "/plans", AuthorizationRequiredHandler(listPlans))
 	m.Add("1.0", http.MethodPost, "/plans", AuthorizationRequiredHandler(addPlan))
 	m.Add("1.0", http.MethodDelete, "/plans/{planname}", AuthorizationRequiredHandler(removePlan))
+	m.Add("1.13", http.MethodPut, "/plans/{planname}", AuthorizationRequiredHandler(updatePlan))
+
+	m.Add("1.13", http.MethodGet, "/app-templates", AuthorizationRequiredHandler(appTemplateList))
+	m.Add("1.13", http.MethodPost, "/app-templates", AuthorizationRequiredHandler(appTemplateCreate))
+	m.Add("1.13", http.MethodGet, "/app-templates/{name}", AuthorizationRequiredHandler(appTemplateInfo))
+	m.Add("1.13", http.MethodPut, "/app-templates/{name}", AuthorizationRequiredHandler(appTemplateUpdate))
+	m.Add("1.13", http.MethodDelete, "/app-templates/{name}", AuthorizationRequiredHandler(appTemplateDelete))
+	m.Add("1.13", http.MethodPost, "/app-templates/{name}/sync", AuthorizationRequiredHandler(appTemplateSync))
+
+	m.Add("1.13", http.MethodGet, "/projects", AuthorizationRequiredHandler(projectList))
+	m.Add("1.13", http.MethodPost, "/projects", AuthorizationRequiredHandler(projectCreate))
+	m.Add("1.13", http.MethodGet, "/projects/{name}", AuthorizationRequiredHandler(projectInfo))
+	m.Add("1.13", http.MethodPut, "/projects/{name}", AuthorizationRequiredHandler(projectUpdate))
+	m.Add("1.13", http.MethodDelete, "/projects/{name}", AuthorizationRequiredHandler(projectDelete))
+
+	m.Add("1.13", http.MethodGet, "/env-groups", AuthorizationRequiredHandler(envGroupList))
+	m.Add("1.13", http.MethodPost, "/env-groups", AuthorizationRequiredHandler(envGroupCreate))
+	m.Add("1.13", http.MethodGet, "/env-groups/{name}", AuthorizationRequiredHandler(envGroupInfo))
+	m.Add("1.13", http.MethodPut, "/env-groups/{name}", AuthorizationRequiredHandler(envGroupUpdate))
+	m.Add("1.13", http.MethodDelete, "/env-groups/{name}", AuthorizationRequiredHandler(envGroupDelete))
 
 	m.Add("1.0", http.MethodGet, "/pools", AuthorizationRequiredHandler(poolList))
 	m.Add("1.0", http.MethodPost, "/pools", AuthorizationRequiredHandler(addPoolHandler))
@@ -387,6 +550,33 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.0", http.MethodPost, "/pools/{name}/team", AuthorizationRequiredHandler(addTeamToPoolHandler))
 	m.Add("1.0", http.MethodDelete, "/pools/{name}/team", AuthorizationRequiredHandler(removeTeamToPoolHandler))
 	m.Add("1.8", http.MethodGet, "/pools/{name}", AuthorizationRequiredHandler(getPoolHandler))
+	m.Add("1.13", http.MethodGet, "/pools/{name}/trusted-keys", AuthorizationRequiredHandler(poolTrustedKeyList))
+	m.Add("1.13", http.MethodPost, "/pools/{name}/trusted-keys", AuthorizationRequiredHandler(poolTrustedKeyAdd))
+	m.Add("1.13", http.MethodDelete, "/pools/{name}/trusted-keys/{key}", AuthorizationRequiredHandler(poolTrustedKeyRemove))
+	m.Add("1.13", http.MethodGet, "/pools/{name}/registry", AuthorizationRequiredHandler(poolRegistryInfo))
+	m.Add("1.13", http.MethodPut, "/pools/{name}/registry", AuthorizationRequiredHandler(poolRegistrySet))
+	m.Add("1.13", http.MethodDelete, "/pools/{name}/registry", AuthorizationRequiredHandler(poolRegistryRemove))
+	m.Add("1.13", http.MethodGet, "/pools/{name}/freeze", AuthorizationRequiredHandler(poolFreezeInfo))
+	m.Add("1.13", http.MethodPut, "/pools/{name}/freeze", AuthorizationRequiredHandler(poolFreezeSet))
+	m.Add("1.13", http.MethodDelete, "/pools/{name}/freeze", AuthorizationRequiredHandler(poolFreezeRemove))
+	m.Add("1.13", http.MethodGet, "/pools/{name}/plans", AuthorizationRequiredHandler(poolPlansInfo))
+	m.Add("1.13", http.MethodPut, "/pools/{name}/plans", AuthorizationRequiredHandler(poolPlansSet))
+	m.Add("1.13", http.MethodDelete, "/pools/{name}/plans", AuthorizationRequiredHandler(poolPlansRemove))
+	m.Add("1.13", http.MethodPost, "/pools/{name}/constraints/test", AuthorizationRequiredHandler(poolConstraintTest))
+
+	m.Add("1.13", http.MethodGet, "/resources/import", AuthorizationRequiredHandler(resourceImport))
+	m.Add("1.13", http.MethodGet, "/resources/apps/{name}", AuthorizationRequiredHandler(appResourceGet))
+	m.Add("1.13", http.MethodPut, "/resources/apps/{name}", AuthorizationRequiredHandler(idempotentHandler(appResourceUpdate)))
+	m.Add("1.13", http.MethodGet, "/resources/pools/{name}", AuthorizationRequiredHandler(poolResourceGet))
+	m.Add("1.13", http.MethodPut, "/resources/pools/{name}", AuthorizationRequiredHandler(idempotentHandler(poolResourceUpdate)))
+	m.Add("1.13", http.MethodGet, "/resources/roles/{name}", AuthorizationRequiredHandler(roleResourceGet))
+	m.Add("1.13", http.MethodPut, "/resources/roles/{name}", AuthorizationRequiredHandler(idempotentHandler(roleResourceUpdate)))
+	m.Add("1.13", http.MethodGet, "/resources/plans/{name}", AuthorizationRequiredHandler(planResourceGet))
+	m.Add("1.13", http.MethodPut, "/resources/plans/{name}", AuthorizationRequiredHandler(idempotentHandler(planResourceUpdate)))
+	m.Add("1.13", http.MethodGet, "/resources/service-instances/{service}/{instance}", AuthorizationRequiredHandler(serviceInstanceResourceGet))
+	m.Add("1.13", http.MethodPut, "/resources/service-instances/{service}/{instance}", AuthorizationRequiredHandler(idempotentHandler(serviceInstanceResourceUpdate)))
+	m.Add("1.13", http.MethodGet, "/resources/service-bindings/{service}/{instance}/{app}", AuthorizationRequiredHandler(serviceBindingResourceGet))
+	m.Add("1.13", http.MethodPut, "/resources/service-bindings/{service}/{instance}/{app}", AuthorizationRequiredHandler(idempotentHandler(serviceBindingResourceUpdate)))
 
 	m.Add("1.3", http.MethodGet, "/constraints", AuthorizationRequiredHandler(poolConstraintList))
 	m.Add("1.3", http.MethodPut, "/constraints", AuthorizationRequiredHandler(poolConstraintSet))
@@ -408,8 +598,21 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.6", http.MethodDelete, "/roles/{name}/token/{token_id}", AuthorizationRequiredHandler(dissociateRoleFromToken))
 	m.Add("1.9", http.MethodPost, "/roles/{name}/group", AuthorizationRequiredHandler(assignRoleToGroup))
 	m.Add("1.9", http.MethodDelete, "/roles/{name}/group/{group_name}", AuthorizationRequiredHandler(dissociateRoleFromGroup))
+	m.Add("1.13", http.MethodPost, "/roles/{name}/templates", AuthorizationRequiredHandler(roleTemplateAdd))
+	m.Add("1.13", http.MethodDelete, "/roles/{name}/templates/{template}", AuthorizationRequiredHandler(roleTemplateRemove))
+	m.Add("1.13", http.MethodGet, "/permission-templates", AuthorizationRequiredHandler(permTemplateList))
+	m.Add("1.13", http.MethodPost, "/permission-templates", AuthorizationRequiredHandler(permTemplateCreate))
+	m.Add("1.13", http.MethodGet, "/permission-templates/{name}", AuthorizationRequiredHandler(permTemplateInfo))
+	m.Add("1.13", http.MethodPut, "/permission-templates/{name}", AuthorizationRequiredHandler(permTemplateUpdate))
+	m.Add("1.13", http.MethodDelete, "/permission-templates/{name}", AuthorizationRequiredHandler(permTemplateDelete))
+	m.Add("1.13", http.MethodGet, "/permissions/bundle", AuthorizationRequiredHandler(permBundleExport))
+	m.Add("1.13", http.MethodPost, "/permissions/bundle", AuthorizationRequiredHandler(permBundleImport))
+	m.Add("1.13", http.MethodGet, "/access-grants", AuthorizationRequiredHandler(accessGrantList))
+	m.Add("1.13", http.MethodPost, "/access-grants", AuthorizationRequiredHandler(accessGrantCreate))
+	m.Add("1.13", http.MethodDelete, "/access-grants/{id}", AuthorizationRequiredHandler(accessGrantRevoke))
 
 	m.Add("1.0", http.MethodGet, "/debug/goroutines", AuthorizationRequiredHandler(dumpGoroutines))
+	m.Add("1.13", http.MethodGet, "/node-status", AuthorizationRequiredHandler(nodeStatus))
 	m.Add("1.0", http.MethodGet, "/debug/pprof/", AuthorizationRequiredHandler(debugHandler(pprof.Index)))
 	m.Add("1.0", http.MethodGet, "/debug/pprof/cmdline", AuthorizationRequiredHandler(debugHandler(pprof.Cmdline)))
 	m.Add("1.0", http.MethodGet, "/debug/pprof/profile", AuthorizationRequiredHandler(debugHandler(pprof.Profile)))
@@ -436,6 +639,8 @@ func RunServer(dry bool) http.Handler {
 	m.Add("1.2", http.MethodPut, "/node", AuthorizationRequiredHandler(updateNodeHandler))
 	m.Add("1.2", http.MethodDelete, "/node/{address:.*}", AuthorizationRequiredHandler(removeNodeHandler))
 	m.Add("1.3", http.MethodPost, "/node/rebalance", AuthorizationRequiredHandler(rebalanceNodesHandler))
+	m.Add("1.13", http.MethodGet, "/node/rebalance/plan", AuthorizationRequiredHandler(rebalanceNodesPlanHandler))
+	m.Add("1.13", http.MethodPost, "/node/{address:.*}/eviction", AuthorizationRequiredHandler(nodeEvictionHandler))
 	m.Add("1.6", http.MethodGet, "/node/{address:.*}", AuthorizationRequiredHandler(infoNodeHandler))
 
 	m.Add("1.2", http.MethodGet, "/nodecontainers", AuthorizationRequiredHandler(nodeContainerList))
@@ -537,12 +742,19 @@ func RunServer(dry bool) http.Handler {
 	n.Use(negroni.HandlerFunc(errorHandlingMiddleware))
 	n.Use(negroni.HandlerFunc(setVersionHeadersMiddleware))
 	n.Use(negroni.HandlerFunc(authTokenMiddleware))
+	limiter, err := ratelimit.FromConfig()
+	if err != nil {
+		fatal(err)
+	}
+	if limiter != nil {
+		n.Use(limiter)
+	}
 	n.UseHandler(http.HandlerFunc(runDelayedHandler))
 
 	form.DefaultEncoder = form.DefaultEncoder.UseJSONTags(false)
 
 	if !dry {
-		err := startServer(n)
+		err := startServer(n, limiter)
 		if err != nil {
 			fatal(err)
 		}
@@ -598,7 +810,7 @@ func bindAppsLister() ([]bind.App, error) {
 	return bindApps, nil
 }
 
-func startServer(handler http.Handler) error {
+func startServer(handler http.Handler, limiter *ratelimit.Limiter) error {
 	span, ctx := opentracing.StartSpanFromContext(
 		context.Background(), "StartServer")
 	defer span.Finish()
@@ -612,6 +824,14 @@ func startServer(handler http.Handler) error {
 	if shutdownTimeoutInt != 0 {
 		srvConf.shutdownTimeout = time.Duration(shutdownTimeoutInt) * time.Second
 	}
+	srvConf.drainTimeout = 5 * time.Minute
+	drainTimeoutInt, _ := config.GetInt("shutdown-drain-timeout")
+	if drainTimeoutInt != 0 {
+		srvConf.drainTimeout = time.Duration(drainTimeoutInt) * time.Second
+	}
+	if srvConf.drainTimeout > srvConf.shutdownTimeout {
+		srvConf.drainTimeout = srvConf.shutdownTimeout
+	}
 	go srvConf.handleSignals(srvConf.shutdownTimeout)
 
 	defer srvConf.shutdown(srvConf.shutdownTimeout)
@@ -660,6 +880,10 @@ func startServer(handler http.Handler) error {
 	if err != nil {
 		return err
 	}
+	err = tracker.InitializeLeaderElection(servicemanager.InstanceTracker)
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize leader election")
+	}
 	err = provision.InitializeAll()
 	if err != nil {
 		return err
@@ -684,6 +908,46 @@ func startServer(handler http.Handler) error {
 	if err != nil {
 		return err
 	}
+	err = reconcile.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize app reconciliation")
+	}
+	err = maintenance.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize app maintenance windows")
+	}
+	err = snapshot.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize app snapshots")
+	}
+	err = preview.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize app previews")
+	}
+	err = gitops.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize gitops")
+	}
+	err = acme.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize acme certificates")
+	}
+	err = metering.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize usage metering")
+	}
+	err = readcache.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize read cache")
+	}
+	err = dirsync.Initialize()
+	if err != nil {
+		return errors.Wrap(err, "unable to initialize directory sync")
+	}
+	err = startGRPCServer(limiter)
+	if err != nil {
+		return errors.Wrap(err, "unable to start grpc server")
+	}
 	fmt.Println("Checking components status:")
 	results := hc.Check(ctx, "all")
 	for _, result := range results {
@@ -939,6 +1203,9 @@ type srvConfig struct {
 	httpsSrv        *http.Server
 	certificate     *tls.Certificate
 	shutdownTimeout time.Duration
+	// drainTimeout is how long running events are waited for before being
+	// canceled on shutdown.
+	drainTimeout time.Duration
 	// roots holds a set of trusted certificates that are used by certificate
 	// validator to check a given certificate. If roots is nil, the system
 	// certificates are used instead.
@@ -995,7 +1262,29 @@ func (conf *srvConfig) shutdown(shutdownTimeout time.Duration) {
 	conf.shutdownCalled = true
 }
 
+// drain fails the healthcheck and waits for the events running in this
+// instance, like deploys, to finish before connections are closed. Events
+// still running after the drain timeout are canceled. Then the app log
+// writers are flushed.
+func (conf *srvConfig) drain() {
+	atomic.StoreInt32(&shuttingDown, 1)
+	fmt.Printf("[shutdown] tsuru is waiting up to %v for running events to finish.\n", conf.drainTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), conf.drainTimeout)
+	err := event.Drain(ctx, "tsuru API is shutting down")
+	cancel()
+	if err != nil {
+		fmt.Printf("[shutdown] error while draining events: %v\n", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+	err = app.FlushLogWriters(ctx)
+	cancel()
+	if err != nil {
+		fmt.Printf("[shutdown] error while flushing log writers: %v\n", err)
+	}
+}
+
 func (conf *srvConfig) onceShutdown(shutdownTimeout time.Duration) {
+	conf.drain()
 	var wg sync.WaitGroup
 	defer wg.Wait()
 	shutdownSrv := func(srv *http.Server) {
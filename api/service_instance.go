@@ -18,6 +18,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/context"
+	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
@@ -353,6 +354,10 @@ func readableServices(ctx stdContext.Context, t auth.Token, contexts []permTypes
 //   401: Unauthorized
 func serviceInstances(w http.ResponseWriter, r *http.Request, t auth.Token) error {
 	ctx := r.Context()
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return err
+	}
 	appName := r.URL.Query().Get("app")
 	contexts := permission.ContextsForPermission(t, permission.PermServiceInstanceRead)
 	instances, err := readableInstances(t, contexts, appName, "")
@@ -387,12 +392,7 @@ func serviceInstances(w http.ResponseWriter, r *http.Request, t auth.Token) erro
 		entry := servicesMap[name]
 		result = append(result, *entry)
 	}
-	if len(result) == 0 {
-		w.WriteHeader(http.StatusNoContent)
-		return nil
-	}
-	w.Header().Set("Content-Type", "application/json")
-	return json.NewEncoder(w).Encode(result)
+	return writeList(w, r, opts, result)
 }
 
 // title: service instance status
@@ -690,6 +690,86 @@ func serviceInstanceProxy(w http.ResponseWriter, r *http.Request, t auth.Token)
 	return service.ProxyInstance(ctx, serviceInstance, path, evt, requestIDHeader(r), w, r)
 }
 
+// title: rotate service instance credentials
+// path: /services/{service}/instances/{instance}/{app}/credentials
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// produce: application/x-json-stream
+// responses:
+//   200: Credentials rotated
+//   400: Invalid data
+//   401: Unauthorized
+//   404: Not found
+func serviceInstanceRotateCredentials(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	ctx := r.Context()
+	serviceName := r.URL.Query().Get(":service")
+	instanceName := r.URL.Query().Get(":instance")
+	appName := r.URL.Query().Get(":app")
+	var req struct {
+		Envs map[string]string
+	}
+	err = ParseInput(r, &req)
+	if err != nil {
+		return err
+	}
+	if len(req.Envs) == 0 {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "no environment variables to rotate"}
+	}
+	srv, err := getService(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermServiceUpdateCredentials,
+		contextsForServiceProvision(&srv)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	instance, a, err := getServiceInstance(ctx, serviceName, instanceName, appName)
+	if err != nil {
+		return err
+	}
+	if len(a.InstanceEnvs(serviceName, instanceName)) == 0 {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("instance %q has no environment variables bound to the app %q", instance.Name, a.Name),
+		}
+	}
+	envNames := make([]string, 0, len(req.Envs))
+	for name := range req.Envs {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	evt, err := event.New(&event.Opts{
+		Target: appTarget(appName),
+		ExtraTargets: []event.ExtraTarget{
+			{Target: serviceInstanceTarget(serviceName, instanceName)},
+		},
+		Kind:       permission.PermServiceUpdateCredentials,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: []map[string]interface{}{
+			{"name": "envs", "value": envNames},
+		},
+		Allowed: event.Allowed(permission.PermAppReadEvents, contextsForApp(a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	w.Header().Set("Content-Type", "application/x-json-stream")
+	keepAliveWriter := tsuruIo.NewKeepAliveWriter(w, 30*time.Second, "")
+	defer keepAliveWriter.Stop()
+	writer := &tsuruIo.SimpleJsonMessageEncoderWriter{Encoder: json.NewEncoder(keepAliveWriter)}
+	evt.SetLogWriter(writer)
+	return a.RotateInstanceEnvs(app.RotateInstanceEnvsArgs{
+		ServiceName:  serviceName,
+		InstanceName: instanceName,
+		Envs:         req.Envs,
+		Writer:       evt,
+	})
+}
+
 // title: grant access to service instance
 // path: /services/{service}/instances/permission/{instance}/{team}
 // consume: application/x-www-form-urlencoded
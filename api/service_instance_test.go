@@ -21,10 +21,12 @@ import (
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/context"
 	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
 	"github.com/tsuru/tsuru/auth"
 	"github.com/tsuru/tsuru/db"
 	"github.com/tsuru/tsuru/db/dbtest"
 	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/event/eventtest"
 	"github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/permission"
@@ -2541,3 +2543,84 @@ func (s *ServiceInstanceSuite) TestGrantRevokeServiceToTeamWithManyInstanceName(
 	c.Assert(err, check.IsNil)
 	c.Assert(sinst.Teams, check.DeepEquals, []string{s.team.Name})
 }
+
+func (s *ServiceInstanceSuite) TestServiceInstanceRotateCredentials(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(stdContext.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddInstance(bind.AddInstanceArgs{
+		Envs: []bind.ServiceEnvVar{
+			{EnvVar: bind.EnvVar{Name: "DB_PASSWORD", Value: "old"}, InstanceName: "mydb", ServiceName: "mysql"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	si := service.ServiceInstance{Name: "mydb", ServiceName: "mysql", Teams: []string{s.team.Name}, Apps: []string{a.Name}}
+	err = s.conn.ServiceInstances().Insert(si)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "rotator", permission.Permission{
+		Scheme:  permission.PermServiceUpdateCredentials,
+		Context: permission.Context(permTypes.CtxService, "mysql"),
+	})
+	body := strings.NewReader("envs.DB_PASSWORD=new")
+	request, err := http.NewRequest("PUT", "/services/mysql/instances/mydb/myapp/credentials", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/x-json-stream")
+	dbApp, err := app.GetByName(stdContext.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.InstanceEnvs("mysql", "mydb"), check.DeepEquals, map[string]bind.EnvVar{
+		"DB_PASSWORD": {Name: "DB_PASSWORD", Value: "new"},
+	})
+	c.Assert(eventtest.EventDesc{
+		Target:       appTarget(a.Name),
+		ExtraTargets: []event.ExtraTarget{{Target: serviceInstanceTarget("mysql", "mydb")}},
+		Owner:        token.GetUserName(),
+		Kind:         "service.update.credentials",
+		StartCustomData: []map[string]interface{}{
+			{"name": "envs", "value": []interface{}{"DB_PASSWORD"}},
+		},
+	}, eventtest.HasEvent)
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceRotateCredentialsUnauthorized(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(stdContext.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	si := service.ServiceInstance{Name: "mydb", ServiceName: "mysql", Teams: []string{s.team.Name}, Apps: []string{a.Name}}
+	err = s.conn.ServiceInstances().Insert(si)
+	c.Assert(err, check.IsNil)
+	body := strings.NewReader("envs.DB_PASSWORD=new")
+	request, err := http.NewRequest("PUT", "/services/mysql/instances/mydb/myapp/credentials", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusForbidden)
+}
+
+func (s *ServiceInstanceSuite) TestServiceInstanceRotateCredentialsNotBound(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "zend", TeamOwner: s.team.Name}
+	err := app.CreateApp(stdContext.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	si := service.ServiceInstance{Name: "mydb", ServiceName: "mysql", Teams: []string{s.team.Name}}
+	err = s.conn.ServiceInstances().Insert(si)
+	c.Assert(err, check.IsNil)
+	_, token := permissiontest.CustomUserWithPermission(c, nativeScheme, "rotator", permission.Permission{
+		Scheme:  permission.PermServiceUpdateCredentials,
+		Context: permission.Context(permTypes.CtxService, "mysql"),
+	})
+	body := strings.NewReader("envs.DB_PASSWORD=new")
+	request, err := http.NewRequest("PUT", "/services/mysql/instances/mydb/myapp/credentials", body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "instance \"mydb\" has no environment variables bound to the app \"myapp\"\n")
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+)
+
+// title: app shadow report
+// path: /apps/{app}/shadow
+// method: GET
+// produce: application/json
+// responses:
+//   200: OK
+//   204: No version shadowed
+//   401: Unauthorized
+//   404: App not found
+func appShadowReport(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppReadRouter,
+		contextsForApp(&a)...,
+	)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	comparisons, err := a.ShadowReport(r.Context())
+	if err != nil {
+		return err
+	}
+	if a.Shadow == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(comparisons)
+}
+
+type setShadowRequest struct {
+	Version string `json:"version"`
+	Percent int    `json:"percent"`
+}
+
+// title: set app shadow
+// path: /apps/{app}/shadow
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// responses:
+//   200: OK
+//   400: Invalid data
+//   401: Unauthorized
+//   404: App not found
+func setAppShadow(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	var args setShadowRequest
+	err = ParseInput(r, &args)
+	if err != nil {
+		return err
+	}
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	version, err := servicemanager.AppVersion.VersionByImageOrVersion(r.Context(), &a, args.Version)
+	if err != nil {
+		if appTypes.IsInvalidVersionError(err) {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	return updateAppShadow(r, t, &routerTypes.Shadow{Version: version.Version(), Percent: args.Percent})
+}
+
+// title: remove app shadow
+// path: /apps/{app}/shadow
+// method: DELETE
+// responses:
+//   200: OK
+//   401: Unauthorized
+//   404: App not found
+func removeAppShadow(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	return updateAppShadow(r, t, nil)
+}
+
+func updateAppShadow(r *http.Request, t auth.Token, shadow *routerTypes.Shadow) (err error) {
+	appName := r.URL.Query().Get(":app")
+	a, err := getAppFromContext(appName, r)
+	if err != nil {
+		return err
+	}
+	allowed := permission.Check(t, permission.PermAppUpdateRoutable,
+		contextsForApp(&a)...,
+	)
+	if !allowed {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     appTarget(appName),
+		Kind:       permission.PermAppUpdateRoutable,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(InputFields(r)),
+		Allowed:    event.Allowed(permission.PermAppReadEvents, contextsForApp(&a)...),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return a.SetShadow(shadow)
+}
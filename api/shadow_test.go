@@ -0,0 +1,60 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/router/routertest"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppShadowReport(c *check.C) {
+	config.Set("routers:fake-shadow:type", "fake-shadow")
+	defer config.Unset("routers:fake-shadow")
+	defer routertest.ShadowRouter.Reset()
+	myapp := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "fake-shadow"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/shadow", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+
+	err = myapp.SetShadow(&routerTypes.Shadow{Version: 2, Percent: 50})
+	c.Assert(err, check.IsNil)
+	routertest.ShadowRouter.Reports["myapp"] = &routerTypes.ShadowReport{
+		Requests: 2,
+		Primary:  routerTypes.ShadowBackendStats{StatusCodes: map[int]int64{200: 2}, LatencyP50: 10},
+		Shadow:   routerTypes.ShadowBackendStats{StatusCodes: map[int]int64{200: 1, 500: 1}, LatencyP50: 30},
+	}
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	var result []app.ShadowComparison
+	err = json.Unmarshal(recorder.Body.Bytes(), &result)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.HasLen, 1)
+	c.Assert(result[0].Version, check.Equals, 2)
+	c.Assert(result[0].Mismatches, check.Equals, int64(1))
+	c.Assert(result[0].ShadowErrorRate, check.Equals, float64(50))
+	c.Assert(result[0].LatencyP50Delta, check.Equals, float64(20))
+
+	request, err = http.NewRequest("DELETE", "/1.13/apps/myapp/shadow", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(routertest.ShadowRouter.Shadows["myapp"], check.IsNil)
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tracker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/storage"
+	trackerTypes "github.com/tsuru/tsuru/types/tracker"
+)
+
+const (
+	defaultLeaseDuration = 30 * time.Second
+
+	RoleLeader   = "leader"
+	RoleFollower = "follower"
+)
+
+var (
+	electorMu       sync.RWMutex
+	electorInstance *elector
+)
+
+// InitializeLeaderElection starts electing which API instance runs each
+// background worker, so workers aren't duplicated when more than one
+// instance is running. Each worker is owned by the instance holding its
+// lease, renewed while the instance is alive. Leader election may be
+// disabled by tracker:disable-leader-election, in which case every instance
+// runs all workers.
+func InitializeLeaderElection(instances trackerTypes.InstanceService) error {
+	disabled, _ := config.GetBool("tracker:disable-leader-election")
+	if disabled {
+		return nil
+	}
+	leaseStorage, err := leaseStorage()
+	if err != nil {
+		return err
+	}
+	instance, err := instances.CurrentInstance(context.Background())
+	if err != nil {
+		return err
+	}
+	duration := defaultLeaseDuration
+	durationSeconds, _ := config.GetFloat("tracker:lease-duration")
+	if durationSeconds > 0 {
+		duration = time.Duration(durationSeconds * float64(time.Second))
+	}
+	e := newElector(leaseStorage, instance.Name, duration)
+	go e.start()
+	shutdown.Register(e)
+	electorMu.Lock()
+	electorInstance = e
+	electorMu.Unlock()
+	return nil
+}
+
+func leaseStorage() (trackerTypes.LeaseStorage, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dbDriver.LeaseStorage, nil
+}
+
+func currentElector() *elector {
+	electorMu.RLock()
+	defer electorMu.RUnlock()
+	return electorInstance
+}
+
+// IsLeader reports whether the current instance owns the worker, trying to
+// acquire it on the first call. Workers are owned by every instance when
+// leader election is disabled.
+func IsLeader(worker string) bool {
+	e := currentElector()
+	if e == nil {
+		return true
+	}
+	return e.isLeader(worker)
+}
+
+// NodeStatus lists the live API instances along with the workers owned by
+// each one of them.
+func NodeStatus(ctx context.Context, instances trackerTypes.InstanceService) (*trackerTypes.NodeStatus, error) {
+	live, err := instances.LiveInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, err := instances.CurrentInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e := currentElector()
+	status := trackerTypes.NodeStatus{
+		LeaderElection: e != nil,
+		Instances:      []trackerTypes.InstanceStatus{},
+		Leases:         []trackerTypes.Lease{},
+	}
+	workers := map[string][]string{}
+	if e != nil {
+		status.Leases, err = e.storage.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		for _, lease := range status.Leases {
+			if lease.ExpiresAt.After(now) {
+				workers[lease.Owner] = append(workers[lease.Owner], lease.Name)
+			}
+		}
+	}
+	for _, instance := range live {
+		role := RoleFollower
+		if len(workers[instance.Name]) > 0 {
+			role = RoleLeader
+		}
+		status.Instances = append(status.Instances, trackerTypes.InstanceStatus{
+			Name:       instance.Name,
+			Addresses:  instance.Addresses,
+			Port:       instance.Port,
+			TLSPort:    instance.TLSPort,
+			LastUpdate: instance.LastUpdate,
+			Current:    instance.Name == current.Name,
+			Role:       role,
+			Workers:    workers[instance.Name],
+		})
+	}
+	sort.Slice(status.Instances, func(i, j int) bool {
+		return status.Instances[i].Name < status.Instances[j].Name
+	})
+	return &status, nil
+}
+
+type elector struct {
+	storage  trackerTypes.LeaseStorage
+	owner    string
+	duration time.Duration
+	mu       sync.Mutex
+	// held maps each known worker to the time its lease is considered
+	// expired by this instance, zero when held by another instance.
+	held map[string]time.Time
+	quit chan struct{}
+	done chan struct{}
+}
+
+func newElector(leaseStorage trackerTypes.LeaseStorage, owner string, duration time.Duration) *elector {
+	return &elector{
+		storage:  leaseStorage,
+		owner:    owner,
+		duration: duration,
+		held:     map[string]time.Time{},
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (e *elector) start() {
+	defer close(e.done)
+	for {
+		select {
+		case <-e.quit:
+			return
+		case <-time.After(e.duration / 3):
+		}
+		for _, worker := range e.workers() {
+			e.acquire(worker)
+		}
+	}
+}
+
+func (e *elector) workers() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	workers := make([]string, 0, len(e.held))
+	for worker := range e.held {
+		workers = append(workers, worker)
+	}
+	return workers
+}
+
+func (e *elector) isLeader(worker string) bool {
+	select {
+	case <-e.quit:
+		return false
+	default:
+	}
+	e.mu.Lock()
+	expires, known := e.held[worker]
+	e.mu.Unlock()
+	if !known {
+		expires = e.acquire(worker)
+	}
+	return time.Now().Before(expires)
+}
+
+// acquire takes or renews the lease of the worker. The lease is considered
+// expired by this instance before it expires in the storage, so the worker
+// stops before being acquired by another instance if renewals fail.
+func (e *elector) acquire(worker string) time.Time {
+	start := time.Now()
+	err := e.storage.Acquire(context.Background(), worker, e.owner, e.duration)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	previous, known := e.held[worker]
+	if err == trackerTypes.ErrLeaseHeld {
+		if previous.After(start) {
+			log.Errorf("[leader-election] lost the lease of %q", worker)
+		}
+		e.held[worker] = time.Time{}
+		return time.Time{}
+	}
+	if err != nil {
+		log.Errorf("[leader-election] unable to acquire the lease of %q: %v", worker, err)
+		if !known {
+			e.held[worker] = time.Time{}
+		}
+		return e.held[worker]
+	}
+	if !previous.After(start) {
+		log.Debugf("[leader-election] acquired the lease of %q", worker)
+	}
+	expires := start.Add(e.duration * 2 / 3)
+	e.held[worker] = expires
+	return expires
+}
+
+// Shutdown stops renewing leases. Leases aren't released, as shutdowns run
+// concurrently and workers may still be running, they expire after the lease
+// duration instead.
+func (e *elector) Shutdown(ctx context.Context) error {
+	close(e.quit)
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tracker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tsuru/tsuru/types/tracker"
+	check "gopkg.in/check.v1"
+)
+
+type LeaderSuite struct{}
+
+var _ = check.Suite(&LeaderSuite{})
+
+func (s *LeaderSuite) TearDownTest(c *check.C) {
+	electorMu.Lock()
+	electorInstance = nil
+	electorMu.Unlock()
+}
+
+type fakeLeaseStorage struct {
+	mu     sync.Mutex
+	leases map[string]tracker.Lease
+}
+
+func newFakeLeaseStorage() *fakeLeaseStorage {
+	return &fakeLeaseStorage{leases: map[string]tracker.Lease{}}
+}
+
+func (f *fakeLeaseStorage) Acquire(ctx context.Context, name, owner string, duration time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if lease, ok := f.leases[name]; ok && lease.Owner != owner && lease.ExpiresAt.After(now) {
+		return tracker.ErrLeaseHeld
+	}
+	f.leases[name] = tracker.Lease{Name: name, Owner: owner, RenewedAt: now, ExpiresAt: now.Add(duration)}
+	return nil
+}
+
+func (f *fakeLeaseStorage) List(ctx context.Context) ([]tracker.Lease, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	leases := []tracker.Lease{}
+	for _, lease := range f.leases {
+		leases = append(leases, lease)
+	}
+	sort.Slice(leases, func(i, j int) bool { return leases[i].Name < leases[j].Name })
+	return leases, nil
+}
+
+func (s *LeaderSuite) TestIsLeaderWithoutElection(c *check.C) {
+	c.Assert(IsLeader("gc"), check.Equals, true)
+}
+
+func (s *LeaderSuite) TestElectorIsLeader(c *check.C) {
+	storage := newFakeLeaseStorage()
+	e1 := newElector(storage, "host1", time.Minute)
+	e2 := newElector(storage, "host2", time.Minute)
+	c.Assert(e1.isLeader("gc"), check.Equals, true)
+	c.Assert(e2.isLeader("gc"), check.Equals, false)
+	c.Assert(e2.isLeader("autoscale"), check.Equals, true)
+	c.Assert(e1.isLeader("autoscale"), check.Equals, false)
+	c.Assert(e1.isLeader("gc"), check.Equals, true)
+}
+
+func (s *LeaderSuite) TestElectorTakesOverExpiredLease(c *check.C) {
+	storage := newFakeLeaseStorage()
+	e1 := newElector(storage, "host1", 150*time.Millisecond)
+	e2 := newElector(storage, "host2", 150*time.Millisecond)
+	go e2.start()
+	defer e2.Shutdown(context.Background())
+	c.Assert(e1.isLeader("gc"), check.Equals, true)
+	c.Assert(e2.isLeader("gc"), check.Equals, false)
+	time.Sleep(300 * time.Millisecond)
+	c.Assert(e1.isLeader("gc"), check.Equals, false)
+	c.Assert(e2.isLeader("gc"), check.Equals, true)
+}
+
+func (s *LeaderSuite) TestElectorRenewsLeases(c *check.C) {
+	storage := newFakeLeaseStorage()
+	e1 := newElector(storage, "host1", 150*time.Millisecond)
+	e2 := newElector(storage, "host2", 150*time.Millisecond)
+	go e1.start()
+	c.Assert(e1.isLeader("gc"), check.Equals, true)
+	time.Sleep(300 * time.Millisecond)
+	c.Assert(e1.isLeader("gc"), check.Equals, true)
+	c.Assert(e2.isLeader("gc"), check.Equals, false)
+	err := e1.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Assert(e1.isLeader("gc"), check.Equals, false)
+}
+
+func (s *LeaderSuite) TestNodeStatus(c *check.C) {
+	storage := newFakeLeaseStorage()
+	e := newElector(storage, "host1", time.Minute)
+	electorMu.Lock()
+	electorInstance = e
+	electorMu.Unlock()
+	err := storage.Acquire(context.TODO(), "gc", "host1", time.Minute)
+	c.Assert(err, check.IsNil)
+	err = storage.Acquire(context.TODO(), "acme", "host1", time.Minute)
+	c.Assert(err, check.IsNil)
+	err = storage.Acquire(context.TODO(), "metering", "host3", -time.Minute)
+	c.Assert(err, check.IsNil)
+	instances := &tracker.MockInstanceService{
+		OnLiveInstances: func() ([]tracker.TrackedInstance, error) {
+			return []tracker.TrackedInstance{
+				{Name: "host2", Addresses: []string{"10.0.0.2"}},
+				{Name: "host1", Addresses: []string{"10.0.0.1"}},
+			}, nil
+		},
+		OnCurrentInstance: func() (tracker.TrackedInstance, error) {
+			return tracker.TrackedInstance{Name: "host2"}, nil
+		},
+	}
+	status, err := NodeStatus(context.TODO(), instances)
+	c.Assert(err, check.IsNil)
+	c.Assert(status.LeaderElection, check.Equals, true)
+	c.Assert(status.Leases, check.HasLen, 3)
+	c.Assert(status.Instances, check.DeepEquals, []tracker.InstanceStatus{
+		{Name: "host1", Addresses: []string{"10.0.0.1"}, Role: RoleLeader, Workers: []string{"acme", "gc"}},
+		{Name: "host2", Addresses: []string{"10.0.0.2"}, Role: RoleFollower, Current: true},
+	})
+}
@@ -0,0 +1,9 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tsurupb contains the protocol buffers and gRPC service of the tsuru
+// gRPC API.
+package tsurupb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative tsuru.proto
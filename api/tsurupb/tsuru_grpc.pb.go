@@ -0,0 +1,268 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package tsurupb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// TsuruClient is the client API for Tsuru service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TsuruClient interface {
+	AppInfo(ctx context.Context, in *AppInfoRequest, opts ...grpc.CallOption) (*App, error)
+	UnitList(ctx context.Context, in *UnitListRequest, opts ...grpc.CallOption) (*UnitListResponse, error)
+	// Deploy triggers a deploy, streaming its output.
+	Deploy(ctx context.Context, in *DeployRequest, opts ...grpc.CallOption) (Tsuru_DeployClient, error)
+	// WatchEvents streams the events as they start and finish.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (Tsuru_WatchEventsClient, error)
+}
+
+type tsuruClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTsuruClient(cc grpc.ClientConnInterface) TsuruClient {
+	return &tsuruClient{cc}
+}
+
+func (c *tsuruClient) AppInfo(ctx context.Context, in *AppInfoRequest, opts ...grpc.CallOption) (*App, error) {
+	out := new(App)
+	err := c.cc.Invoke(ctx, "/tsuru.v1.Tsuru/AppInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tsuruClient) UnitList(ctx context.Context, in *UnitListRequest, opts ...grpc.CallOption) (*UnitListResponse, error) {
+	out := new(UnitListResponse)
+	err := c.cc.Invoke(ctx, "/tsuru.v1.Tsuru/UnitList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tsuruClient) Deploy(ctx context.Context, in *DeployRequest, opts ...grpc.CallOption) (Tsuru_DeployClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Tsuru_ServiceDesc.Streams[0], "/tsuru.v1.Tsuru/Deploy", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tsuruDeployClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Tsuru_DeployClient interface {
+	Recv() (*DeployOutput, error)
+	grpc.ClientStream
+}
+
+type tsuruDeployClient struct {
+	grpc.ClientStream
+}
+
+func (x *tsuruDeployClient) Recv() (*DeployOutput, error) {
+	m := new(DeployOutput)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tsuruClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (Tsuru_WatchEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Tsuru_ServiceDesc.Streams[1], "/tsuru.v1.Tsuru/WatchEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tsuruWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Tsuru_WatchEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type tsuruWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *tsuruWatchEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TsuruServer is the server API for Tsuru service.
+// All implementations must embed UnimplementedTsuruServer
+// for forward compatibility
+type TsuruServer interface {
+	AppInfo(context.Context, *AppInfoRequest) (*App, error)
+	UnitList(context.Context, *UnitListRequest) (*UnitListResponse, error)
+	// Deploy triggers a deploy, streaming its output.
+	Deploy(*DeployRequest, Tsuru_DeployServer) error
+	// WatchEvents streams the events as they start and finish.
+	WatchEvents(*WatchEventsRequest, Tsuru_WatchEventsServer) error
+	mustEmbedUnimplementedTsuruServer()
+}
+
+// UnimplementedTsuruServer must be embedded to have forward compatible implementations.
+type UnimplementedTsuruServer struct {
+}
+
+func (UnimplementedTsuruServer) AppInfo(context.Context, *AppInfoRequest) (*App, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppInfo not implemented")
+}
+func (UnimplementedTsuruServer) UnitList(context.Context, *UnitListRequest) (*UnitListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnitList not implemented")
+}
+func (UnimplementedTsuruServer) Deploy(*DeployRequest, Tsuru_DeployServer) error {
+	return status.Errorf(codes.Unimplemented, "method Deploy not implemented")
+}
+func (UnimplementedTsuruServer) WatchEvents(*WatchEventsRequest, Tsuru_WatchEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedTsuruServer) mustEmbedUnimplementedTsuruServer() {}
+
+// UnsafeTsuruServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TsuruServer will
+// result in compilation errors.
+type UnsafeTsuruServer interface {
+	mustEmbedUnimplementedTsuruServer()
+}
+
+func RegisterTsuruServer(s grpc.ServiceRegistrar, srv TsuruServer) {
+	s.RegisterService(&Tsuru_ServiceDesc, srv)
+}
+
+func _Tsuru_AppInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TsuruServer).AppInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tsuru.v1.Tsuru/AppInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TsuruServer).AppInfo(ctx, req.(*AppInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tsuru_UnitList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnitListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TsuruServer).UnitList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tsuru.v1.Tsuru/UnitList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TsuruServer).UnitList(ctx, req.(*UnitListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Tsuru_Deploy_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeployRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TsuruServer).Deploy(m, &tsuruDeployServer{stream})
+}
+
+type Tsuru_DeployServer interface {
+	Send(*DeployOutput) error
+	grpc.ServerStream
+}
+
+type tsuruDeployServer struct {
+	grpc.ServerStream
+}
+
+func (x *tsuruDeployServer) Send(m *DeployOutput) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Tsuru_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TsuruServer).WatchEvents(m, &tsuruWatchEventsServer{stream})
+}
+
+type Tsuru_WatchEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type tsuruWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *tsuruWatchEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Tsuru_ServiceDesc is the grpc.ServiceDesc for Tsuru service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Tsuru_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tsuru.v1.Tsuru",
+	HandlerType: (*TsuruServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AppInfo",
+			Handler:    _Tsuru_AppInfo_Handler,
+		},
+		{
+			MethodName: "UnitList",
+			Handler:    _Tsuru_UnitList_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Deploy",
+			Handler:       _Tsuru_Deploy_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Tsuru_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tsuru.proto",
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/unithistory"
+)
+
+const defaultUptimePeriod = 24 * time.Hour
+
+// parsePeriod parses periods of the units status history, accepting days as
+// in 30d besides the units of time.ParseDuration.
+func parsePeriod(rawPeriod string, defaultPeriod time.Duration) (time.Duration, error) {
+	if rawPeriod == "" {
+		return defaultPeriod, nil
+	}
+	var period time.Duration
+	var err error
+	if days := strings.TrimSuffix(rawPeriod, "d"); days != rawPeriod {
+		var n int
+		n, err = strconv.Atoi(days)
+		period = time.Duration(n) * 24 * time.Hour
+	} else {
+		period, err = time.ParseDuration(rawPeriod)
+	}
+	if err != nil || period <= 0 || period > unithistory.Retention {
+		return 0, &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: "invalid period, must be a positive duration up to 30d",
+		}
+	}
+	return period, nil
+}
+
+// title: unit status history
+// path: /apps/{app}/units/{unit}/history
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   204: No content
+//   401: Unauthorized
+//   404: App not found
+func unitStatusHistory(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppRead, contextsForApp(&a)...)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	history, err := unithistory.UnitHistory(a.Name, r.URL.Query().Get(":unit"))
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(history)
+}
+
+// title: app uptime
+// path: /apps/{app}/uptime
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   400: Invalid period
+//   401: Unauthorized
+//   404: App not found
+func appUptime(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppRead, contextsForApp(&a)...)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	period, err := parsePeriod(r.URL.Query().Get("period"), defaultUptimePeriod)
+	if err != nil {
+		return err
+	}
+	uptime, err := unithistory.Uptime(a.Name, time.Now().UTC().Add(-period))
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(uptime)
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/unithistory"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestUnitStatusHistory(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC()
+	err = unithistory.Record(unithistory.Transition{App: "myapp", Unit: "u1", Process: "web", Status: "starting", Time: now.Add(-time.Minute)})
+	c.Assert(err, check.IsNil)
+	err = unithistory.Record(unithistory.Transition{App: "myapp", Unit: "u1", Process: "web", From: "starting", Status: "started", Time: now})
+	c.Assert(err, check.IsNil)
+	err = unithistory.Record(unithistory.Transition{App: "otherapp", Unit: "u1", Process: "web", Status: "error", Time: now})
+	c.Assert(err, check.IsNil)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/units/u1/history", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var history []unithistory.Transition
+	err = json.Unmarshal(recorder.Body.Bytes(), &history)
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 2)
+	c.Assert(history[0].Status, check.Equals, "starting")
+	c.Assert(history[1].From, check.Equals, "starting")
+	c.Assert(history[1].Status, check.Equals, "started")
+}
+
+func (s *S) TestUnitStatusHistoryEmpty(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/units/u1/history", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestAppUptime(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC()
+	transitions := []unithistory.Transition{
+		{App: "myapp", Unit: "u1", Process: "web", From: "started", Status: "error", Time: now.Add(-2 * time.Hour)},
+		{App: "myapp", Unit: "u1", Process: "web", From: "error", Status: "starting", Time: now.Add(-time.Hour)},
+	}
+	for _, t := range transitions {
+		err = unithistory.Record(t)
+		c.Assert(err, check.IsNil)
+	}
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/uptime?period=4h", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var uptime unithistory.AppUptime
+	err = json.Unmarshal(recorder.Body.Bytes(), &uptime)
+	c.Assert(err, check.IsNil)
+	c.Assert(uptime.App, check.Equals, "myapp")
+	c.Assert(uptime.Restarts, check.Equals, 1)
+	c.Assert(uptime.Units, check.HasLen, 1)
+	c.Assert(uptime.Units[0].Status, check.Equals, "starting")
+	c.Assert(uptime.Uptime > 49 && uptime.Uptime < 51, check.Equals, true)
+}
+
+func (s *S) TestAppUptimeInvalidPeriod(c *check.C) {
+	a := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("GET", "/1.13/apps/myapp/uptime?period=1y", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Matches, "invalid period.*\n")
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+)
+
+// title: unit metrics
+// path: /apps/{app}/units/{unit}/metrics
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   400: Unit metrics not supported
+//   401: Unauthorized
+//   404: App or unit not found
+func unitMetrics(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	a, err := getAppFromContext(r.URL.Query().Get(":app"), r)
+	if err != nil {
+		return err
+	}
+	canRead := permission.Check(t, permission.PermAppRead, contextsForApp(&a)...)
+	if !canRead {
+		return permission.ErrUnauthorized
+	}
+	stats, err := a.UnitStats(r.URL.Query().Get(":unit"))
+	if err != nil {
+		if _, ok := err.(*provision.UnitNotFoundError); ok {
+			return &errors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+		}
+		if err == app.ErrUnitStatsUnavailable {
+			return &errors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(stats)
+}
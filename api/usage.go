@@ -0,0 +1,121 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/metering"
+	"github.com/tsuru/tsuru/auth"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const defaultUsagePeriod = 30 * 24 * time.Hour
+
+// title: resource usage
+// path: /usage
+// method: GET
+// produce: application/json, text/csv
+// responses:
+//   200: Ok
+//   204: No content
+//   400: Invalid data
+//   401: Unauthorized
+func resourceUsage(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	ctx := r.Context()
+	contexts := permission.ContextsForPermission(t, permission.PermAppRead)
+	if len(contexts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	query := r.URL.Query()
+	until := time.Now().UTC()
+	since := until.Add(-defaultUsagePeriod)
+	var err error
+	if raw := query.Get("since"); raw != "" {
+		since, err = time.Parse(metering.DateFormat, raw)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "invalid since date, must be in the YYYY-MM-DD format"}
+		}
+	}
+	if raw := query.Get("until"); raw != "" {
+		until, err = time.Parse(metering.DateFormat, raw)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "invalid until date, must be in the YYYY-MM-DD format"}
+		}
+	}
+	if until.Before(since) {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "since date must not be after until date"}
+	}
+	format := query.Get("format")
+	if format == "" && r.Header.Get("Accept") == "text/csv" {
+		format = "csv"
+	}
+	if format != "" && format != "json" && format != "csv" {
+		return &tsuruErrors.HTTP{
+			Code:    http.StatusBadRequest,
+			Message: "invalid format, possible values are 'json' or 'csv'",
+		}
+	}
+	daily, _ := strconv.ParseBool(query.Get("daily"))
+	filter := metering.Filter{
+		Since:   since.Format(metering.DateFormat),
+		Until:   until.Format(metering.DateFormat),
+		GroupBy: query.Get("groupBy"),
+		Daily:   daily,
+	}
+	appFilter := appFilterByContext(contexts, nil)
+	if appFilter.Extra != nil {
+		apps, listErr := app.List(ctx, appFilter)
+		if listErr != nil {
+			return listErr
+		}
+		filter.Apps = make([]string, len(apps))
+		for i, a := range apps {
+			filter.Apps[i] = a.Name
+		}
+	}
+	records, err := metering.Usage(filter)
+	if err == metering.ErrInvalidGroupBy {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	if format == "csv" {
+		return writeUsageCSV(w, records)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(records)
+}
+
+func writeUsageCSV(w http.ResponseWriter, records []metering.UsageRecord) error {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="usage.csv"`)
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"group", "date", "cpu_seconds", "memory_gb_hours", "network_rx_bytes", "network_tx_bytes"})
+	for _, r := range records {
+		writer.Write([]string{
+			r.Group,
+			r.Date,
+			strconv.FormatFloat(r.CPUSeconds, 'f', 3, 64),
+			strconv.FormatFloat(r.MemoryGBHours, 'f', 6, 64),
+			strconv.FormatInt(r.NetworkRxBytes, 10),
+			strconv.FormatInt(r.NetworkTxBytes, 10),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
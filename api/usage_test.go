@@ -0,0 +1,114 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/metering"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) recordUsage(c *check.C, apps ...*app.App) time.Time {
+	window := time.Now().UTC().Truncate(time.Hour)
+	for _, a := range apps {
+		err := metering.Record(a, window, time.Hour, []provision.UnitUsage{{ID: a.Name + "-u1", CPU: 1, Memory: 1e9}})
+		c.Assert(err, check.IsNil)
+	}
+	return window
+}
+
+func (s *S) TestResourceUsage(c *check.C) {
+	a1 := app.App{Name: "app1", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a1, s.user)
+	c.Assert(err, check.IsNil)
+	a2 := app.App{Name: "app2", Platform: "go", TeamOwner: "otherteam", Pool: "pool2"}
+	s.recordUsage(c, &a1, &a2)
+	request, err := http.NewRequest("GET", "/1.13/usage?groupBy=team", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var records []metering.UsageRecord
+	err = json.Unmarshal(recorder.Body.Bytes(), &records)
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.DeepEquals, []metering.UsageRecord{
+		{Group: "otherteam", CPUSeconds: 3600, MemoryGBHours: 1},
+		{Group: s.team.Name, CPUSeconds: 3600, MemoryGBHours: 1},
+	})
+}
+
+func (s *S) TestResourceUsageOnlyVisibleApps(c *check.C) {
+	a1 := app.App{Name: "app1", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &a1, s.user)
+	c.Assert(err, check.IsNil)
+	a2 := app.App{Name: "app2", Platform: "go", TeamOwner: "otherteam"}
+	s.recordUsage(c, &a1, &a2)
+	token := userWithPermission(c, permission.Permission{
+		Scheme:  permission.PermAppRead,
+		Context: permission.Context(permTypes.CtxTeam, s.team.Name),
+	})
+	request, err := http.NewRequest("GET", "/1.13/usage", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var records []metering.UsageRecord
+	err = json.Unmarshal(recorder.Body.Bytes(), &records)
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.HasLen, 1)
+	c.Assert(records[0].Group, check.Equals, "app1")
+}
+
+func (s *S) TestResourceUsageCSV(c *check.C) {
+	a1 := app.App{Name: "app1", Platform: "go", TeamOwner: s.team.Name}
+	window := s.recordUsage(c, &a1)
+	request, err := http.NewRequest("GET", "/1.13/usage?daily=true", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Accept", "text/csv")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "text/csv")
+	records, err := csv.NewReader(recorder.Body).ReadAll()
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.DeepEquals, [][]string{
+		{"group", "date", "cpu_seconds", "memory_gb_hours", "network_rx_bytes", "network_tx_bytes"},
+		{"app1", window.Format(metering.DateFormat), "3600.000", "1.000000", "0", "0"},
+	})
+}
+
+func (s *S) TestResourceUsageNoContent(c *check.C) {
+	request, err := http.NewRequest("GET", "/1.13/usage", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestResourceUsageInvalidParameters(c *check.C) {
+	for _, query := range []string{"groupBy=platform", "since=yesterday", "until=2020-13-01", "since=2026-03-10&until=2026-03-01", "format=xml"} {
+		request, err := http.NewRequest("GET", "/1.13/usage?"+query, nil)
+		c.Assert(err, check.IsNil)
+		request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+		recorder := httptest.NewRecorder()
+		s.testServer.ServeHTTP(recorder, request)
+		c.Check(recorder.Code, check.Equals, http.StatusBadRequest, check.Commentf("query %q", query))
+	}
+}
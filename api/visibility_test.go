@@ -0,0 +1,52 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/router/routertest"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetAppVisibility(c *check.C) {
+	config.Set("routers:fake-visibility:type", "fake-visibility")
+	defer config.Unset("routers:fake-visibility")
+	defer routertest.VisibilityRouter.Reset()
+	myapp := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "fake-visibility"}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/visibility", strings.NewReader("visibility=internal"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(routertest.VisibilityRouter.Visibilities["myapp"], check.Equals, routerTypes.VisibilityInternal)
+	dbApp, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Visibility, check.Equals, routerTypes.VisibilityInternal)
+}
+
+func (s *S) TestSetAppVisibilityNotSupported(c *check.C) {
+	myapp := app.App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), &myapp, s.user)
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest("PUT", "/1.13/apps/myapp/visibility", strings.NewReader("visibility=none"))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	s.testServer.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+	c.Assert(recorder.Body.String(), check.Equals, "visibility \"none\" is not supported by router \"fake\"\n")
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package acme issues and renews certificates for app CNAMEs using the ACME
+// protocol, storing them encrypted and pushing them to the TLS capable
+// routers of the apps.
+package acme
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"sort"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+)
+
+const (
+	StatusPending = "pending"
+	StatusIssued  = "issued"
+	StatusFailed  = "failed"
+	// StatusManual is reported for CNAMEs whose certificate was set by
+	// users instead of being managed by tsuru.
+	StatusManual = "manual"
+	// StatusMissing is reported for CNAMEs without any certificate.
+	StatusMissing = "missing"
+)
+
+// Certificate is an ACME managed certificate for an app CNAME. The private
+// key is stored encrypted.
+type Certificate struct {
+	ID          string `bson:"_id"`
+	App         string
+	CName       string
+	Status      string
+	Error       string
+	Challenge   string
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+	LastAttempt time.Time
+	Certificate string
+	Key         []byte
+}
+
+// CertificateStatus is the state of the certificate of a CNAME of an app.
+type CertificateStatus struct {
+	CName       string     `json:"cname"`
+	Managed     bool       `json:"managed"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	Challenge   string     `json:"challenge,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	LastAttempt *time.Time `json:"lastAttempt,omitempty"`
+}
+
+func certificateID(appName, cname string) string {
+	return appName + "/" + cname
+}
+
+func certificatesCollection(conn *db.Storage) *storage.Collection {
+	coll := conn.Collection("acme_certificates")
+	coll.EnsureIndex(mgo.Index{Key: []string{"app"}})
+	return coll
+}
+
+func listCertificates(query bson.M) ([]Certificate, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var certs []Certificate
+	err = certificatesCollection(conn).Find(query).Sort("app", "cname").All(&certs)
+	return certs, err
+}
+
+func getCertificate(appName, cname string) (*Certificate, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var cert Certificate
+	err = certificatesCollection(conn).FindId(certificateID(appName, cname)).One(&cert)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+func saveCertificate(cert *Certificate) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	cert.ID = certificateID(cert.App, cert.CName)
+	_, err = certificatesCollection(conn).UpsertId(cert.ID, cert)
+	return err
+}
+
+func removeCertificate(appName, cname string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = certificatesCollection(conn).RemoveId(certificateID(appName, cname))
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// AppCertificates returns the state of the certificate of each CNAME of the
+// app, whether managed by tsuru or set by users.
+func AppCertificates(a *app.App) ([]CertificateStatus, error) {
+	managed, err := listCertificates(bson.M{"app": a.Name})
+	if err != nil {
+		return nil, err
+	}
+	managedByCName := map[string]Certificate{}
+	for _, cert := range managed {
+		managedByCName[cert.CName] = cert
+	}
+	var routerCerts map[string]map[string]string
+	result := make([]CertificateStatus, 0, len(a.CName))
+	for _, cname := range a.CName {
+		if cert, ok := managedByCName[cname]; ok {
+			result = append(result, managedStatus(cert))
+			continue
+		}
+		if routerCerts == nil {
+			routerCerts, err = a.GetCertificates()
+			if err != nil {
+				return nil, err
+			}
+		}
+		status := CertificateStatus{CName: cname, Status: StatusMissing}
+		for _, certs := range routerCerts {
+			if certs[cname] == "" {
+				continue
+			}
+			status.Status = StatusManual
+			if expiresAt, err := certificateExpiration([]byte(certs[cname])); err == nil {
+				status.ExpiresAt = &expiresAt
+			}
+			break
+		}
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CName < result[j].CName })
+	return result, nil
+}
+
+func managedStatus(cert Certificate) CertificateStatus {
+	status := CertificateStatus{
+		CName:     cert.CName,
+		Managed:   true,
+		Status:    cert.Status,
+		Error:     cert.Error,
+		Challenge: cert.Challenge,
+	}
+	if !cert.ExpiresAt.IsZero() {
+		expiresAt := cert.ExpiresAt
+		status.ExpiresAt = &expiresAt
+	}
+	if !cert.LastAttempt.IsZero() {
+		lastAttempt := cert.LastAttempt
+		status.LastAttempt = &lastAttempt
+	}
+	return status
+}
+
+func certificateExpiration(certPEM []byte) (time.Time, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return time.Time{}, errors.New("invalid certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
@@ -0,0 +1,340 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/version"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	acmeAPI "golang.org/x/crypto/acme"
+	"golang.org/x/crypto/bcrypt"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage     *db.Storage
+	user        *auth.User
+	mockService servicemock.MockService
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_acme_tests")
+	config.Set("routers:fake-tls:type", "fake-tls")
+	config.Set("auth:hash-cost", bcrypt.MinCost)
+	config.Set("acme:encryption-key", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+	provision.DefaultProvisioner = "fake"
+	app.AuthScheme = auth.ManagedScheme(native.NativeScheme{})
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	provisiontest.ProvisionerInstance.Reset()
+	routertest.TLSRouter.Reset()
+	routertest.TLSRouter.Certs = map[string]string{}
+	routertest.TLSRouter.Keys = map[string]string{}
+	s.user, _ = permissiontest.CustomUserWithPermission(c, app.AuthScheme, "majortom", permission.Permission{
+		Scheme:  permission.PermAll,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "p1", Default: true})
+	c.Assert(err, check.IsNil)
+	servicemock.SetMockService(&s.mockService)
+	plan := appTypes.Plan{Name: "default", Default: true, CpuShare: 100}
+	s.mockService.Plan.OnList = func() ([]appTypes.Plan, error) {
+		return []appTypes.Plan{plan}, nil
+	}
+	s.mockService.Plan.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &plan, nil
+	}
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		if name == plan.Name {
+			return &plan, nil
+		}
+		return nil, appTypes.ErrPlanNotFound
+	}
+	servicemanager.AppVersion, err = version.AppVersionService()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+	s.storage.Close()
+}
+
+func (s *S) createApp(c *check.C, name string, cnames ...string) *app.App {
+	a := app.App{Name: name, Platform: "python", TeamOwner: "myteam", Routers: []appTypes.AppRouter{{Name: "fake-tls"}}}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	if len(cnames) > 0 {
+		err = a.AddCName(cnames...)
+		c.Assert(err, check.IsNil)
+	}
+	return &a
+}
+
+type fakeIssuer struct {
+	issued []string
+	err    error
+}
+
+func (i *fakeIssuer) Challenge() string {
+	return ChallengeHTTP01
+}
+
+func (i *fakeIssuer) Issue(ctx context.Context, domain string) (*Issued, error) {
+	i.issued = append(i.issued, domain)
+	if i.err != nil {
+		return nil, i.err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	notBefore := time.Now().Add(-time.Hour).Truncate(time.Second)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(90 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	keyData, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Issued{
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:         pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData}),
+		NotBefore:   template.NotBefore,
+		NotAfter:    template.NotAfter,
+	}, nil
+}
+
+func (s *S) newController(issuer Issuer) *controller {
+	return &controller{
+		issuer:        issuer,
+		renewBefore:   defaultRenewBefore,
+		retryInterval: defaultRetryInterval,
+		shutdown:      make(chan struct{}),
+	}
+}
+
+func (s *S) TestEncryptDecrypt(c *check.C) {
+	data, err := encrypt([]byte("my secret key"))
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Not(check.Matches), ".*my secret key.*")
+	plain, err := decrypt(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(plain), check.Equals, "my secret key")
+	data[len(data)-1]++
+	_, err = decrypt(data)
+	c.Assert(err, check.Equals, errInvalidCiphertext)
+}
+
+func (s *S) TestEncryptionKeyInvalidSize(c *check.C) {
+	config.Set("acme:encryption-key", "c2hvcnQ=")
+	defer config.Set("acme:encryption-key", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	_, err := encrypt([]byte("data"))
+	c.Assert(err, check.ErrorMatches, "acme:encryption-key must have 32 bytes, got 5")
+}
+
+func (s *S) TestControllerIssuesCertificates(c *check.C) {
+	a := s.createApp(c, "myapp", "myapp.example.com")
+	issuer := &fakeIssuer{}
+	ctrl := s.newController(issuer)
+	ctrl.run()
+	c.Assert(issuer.issued, check.DeepEquals, []string{"myapp.example.com"})
+	c.Assert(routertest.TLSRouter.Certs["myapp.example.com"], check.Not(check.Equals), "")
+	cert, err := getCertificate("myapp", "myapp.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(cert.Status, check.Equals, StatusIssued)
+	c.Assert(cert.Certificate, check.Equals, routertest.TLSRouter.Certs["myapp.example.com"])
+	c.Assert(string(cert.Key), check.Not(check.Equals), routertest.TLSRouter.Keys["myapp.example.com"])
+	key, err := decrypt(cert.Key)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(key), check.Equals, routertest.TLSRouter.Keys["myapp.example.com"])
+	ctrl.run()
+	c.Assert(issuer.issued, check.HasLen, 1)
+	statuses, err := AppCertificates(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 1)
+	c.Assert(statuses[0].Status, check.Equals, StatusIssued)
+	c.Assert(statuses[0].Managed, check.Equals, true)
+	c.Assert(statuses[0].ExpiresAt.Equal(cert.ExpiresAt), check.Equals, true)
+}
+
+func (s *S) TestControllerRenewsExpiringCertificates(c *check.C) {
+	s.createApp(c, "myapp", "myapp.example.com")
+	issuer := &fakeIssuer{}
+	ctrl := s.newController(issuer)
+	ctrl.run()
+	ctrl.renewBefore = 100 * 24 * time.Hour
+	ctrl.run()
+	c.Assert(issuer.issued, check.DeepEquals, []string{"myapp.example.com", "myapp.example.com"})
+}
+
+func (s *S) TestControllerPushesCertificateMissingInRouter(c *check.C) {
+	s.createApp(c, "myapp", "myapp.example.com")
+	issuer := &fakeIssuer{}
+	ctrl := s.newController(issuer)
+	ctrl.run()
+	certPEM := routertest.TLSRouter.Certs["myapp.example.com"]
+	delete(routertest.TLSRouter.Certs, "myapp.example.com")
+	ctrl.run()
+	c.Assert(issuer.issued, check.HasLen, 1)
+	c.Assert(routertest.TLSRouter.Certs["myapp.example.com"], check.Equals, certPEM)
+}
+
+func (s *S) TestControllerSkipsManualCertificates(c *check.C) {
+	a := s.createApp(c, "myapp", "myapp.example.com")
+	issued, err := (&fakeIssuer{}).Issue(context.TODO(), "myapp.example.com")
+	c.Assert(err, check.IsNil)
+	err = a.SetCertificate("myapp.example.com", string(issued.Certificate), string(issued.Key))
+	c.Assert(err, check.IsNil)
+	issuer := &fakeIssuer{}
+	s.newController(issuer).run()
+	c.Assert(issuer.issued, check.HasLen, 0)
+	statuses, err := AppCertificates(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 1)
+	c.Assert(statuses[0].Status, check.Equals, StatusManual)
+	c.Assert(statuses[0].Managed, check.Equals, false)
+	c.Assert(statuses[0].ExpiresAt.Equal(issued.NotAfter), check.Equals, true)
+}
+
+func (s *S) TestControllerFailureWaitsRetryInterval(c *check.C) {
+	a := s.createApp(c, "myapp", "myapp.example.com")
+	issuer := &fakeIssuer{err: errors.New("rate limited")}
+	ctrl := s.newController(issuer)
+	ctrl.run()
+	ctrl.run()
+	c.Assert(issuer.issued, check.HasLen, 1)
+	statuses, err := AppCertificates(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(statuses, check.HasLen, 1)
+	c.Assert(statuses[0].Status, check.Equals, StatusFailed)
+	c.Assert(statuses[0].Error, check.Equals, "rate limited")
+	c.Assert(statuses[0].LastAttempt, check.NotNil)
+	ctrl.retryInterval = time.Nanosecond
+	issuer.err = nil
+	ctrl.run()
+	c.Assert(issuer.issued, check.HasLen, 2)
+	cert, err := getCertificate("myapp", "myapp.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(cert.Status, check.Equals, StatusIssued)
+	c.Assert(cert.Error, check.Equals, "")
+}
+
+func (s *S) TestControllerRemovesUnusedCertificates(c *check.C) {
+	a := s.createApp(c, "myapp", "myapp.example.com")
+	ctrl := s.newController(&fakeIssuer{})
+	ctrl.run()
+	err := a.RemoveCName("myapp.example.com")
+	c.Assert(err, check.IsNil)
+	ctrl.run()
+	cert, err := getCertificate("myapp", "myapp.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(cert, check.IsNil)
+}
+
+func (s *S) TestHTTP01Challenge(c *check.C) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	client := &acmeAPI.Client{Key: key}
+	chal := &acmeAPI.Challenge{Type: ChallengeHTTP01, Token: "mytoken"}
+	err = http01Solver{}.present(context.TODO(), client, "myapp.example.com", chal)
+	c.Assert(err, check.IsNil)
+	expected, err := client.HTTP01ChallengeResponse("mytoken")
+	c.Assert(err, check.IsNil)
+	response, err := HTTP01Response("mytoken")
+	c.Assert(err, check.IsNil)
+	c.Assert(response, check.Equals, expected)
+	err = http01Solver{}.cleanUp(context.TODO(), client, "myapp.example.com", chal)
+	c.Assert(err, check.IsNil)
+	_, err = HTTP01Response("mytoken")
+	c.Assert(err, check.Equals, ErrChallengeNotFound)
+}
+
+func (s *S) TestWebhookDNSProvider(c *check.C) {
+	var records []webhookDNSRecord
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Header.Get("Authorization"), check.Equals, "Bearer mytoken")
+		var record webhookDNSRecord
+		json.NewDecoder(r.Body).Decode(&record)
+		records = append(records, record)
+		methods = append(methods, r.Method)
+	}))
+	defer server.Close()
+	config.Set("acme:dns:driver", "webhook")
+	config.Set("acme:dns:webhook:url", server.URL)
+	config.Set("acme:dns:webhook:token", "mytoken")
+	defer config.Unset("acme:dns")
+	provider, err := getDNSProvider()
+	c.Assert(err, check.IsNil)
+	err = provider.Present(context.TODO(), "_acme-challenge.myapp.example.com.", "value")
+	c.Assert(err, check.IsNil)
+	err = provider.CleanUp(context.TODO(), "_acme-challenge.myapp.example.com.", "value")
+	c.Assert(err, check.IsNil)
+	c.Assert(methods, check.DeepEquals, []string{http.MethodPost, http.MethodDelete})
+	c.Assert(records, check.DeepEquals, []webhookDNSRecord{
+		{FQDN: "_acme-challenge.myapp.example.com.", Value: "value"},
+		{FQDN: "_acme-challenge.myapp.example.com.", Value: "value"},
+	})
+}
+
+func (s *S) TestGetDNSProviderUnknown(c *check.C) {
+	config.Set("acme:dns:driver", "unknown")
+	defer config.Unset("acme:dns")
+	_, err := getDNSProvider()
+	c.Assert(err, check.ErrorMatches, `unknown acme dns driver "unknown"`)
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package acme
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/api/tracker"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	defaultInterval      = time.Hour
+	defaultRenewBefore   = 30 * 24 * time.Hour
+	defaultRetryInterval = 6 * time.Hour
+)
+
+var newIssuerFunc = newIssuer
+
+// Initialize starts the controller issuing and renewing certificates for
+// app CNAMEs, when enabled by the acme:enabled config entry.
+func Initialize() error {
+	enabled, _ := config.GetBool("acme:enabled")
+	if !enabled {
+		return nil
+	}
+	_, err := encryptionKey()
+	if err != nil {
+		return err
+	}
+	issuer, err := newIssuerFunc()
+	if err != nil {
+		return err
+	}
+	c := &controller{
+		issuer:        issuer,
+		interval:      configDuration("acme:interval", defaultInterval),
+		renewBefore:   configDuration("acme:renew-before", defaultRenewBefore),
+		retryInterval: configDuration("acme:retry-interval", defaultRetryInterval),
+	}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+func configDuration(key string, defaultValue time.Duration) time.Duration {
+	value, _ := config.GetDuration(key)
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+type controller struct {
+	issuer        Issuer
+	interval      time.Duration
+	renewBefore   time.Duration
+	retryInterval time.Duration
+	shutdown      chan struct{}
+	done          chan struct{}
+}
+
+func (c *controller) start() {
+	c.shutdown = make(chan struct{})
+	c.done = make(chan struct{})
+	log.Debugf("[acme] starting. Running every %s.", c.interval)
+	go func() {
+		defer close(c.done)
+		for {
+			c.run()
+			select {
+			case <-time.After(c.interval):
+			case <-c.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (c *controller) run() {
+	if !tracker.IsLeader("acme") {
+		return
+	}
+	apps, err := app.List(context.Background(), nil)
+	if err != nil {
+		log.Errorf("[acme] unable to list apps: %v", err)
+		return
+	}
+	existing, err := listCertificates(nil)
+	if err != nil {
+		log.Errorf("[acme] unable to list certificates: %v", err)
+		return
+	}
+	inUse := map[string]struct{}{}
+	for i := range apps {
+		for _, cname := range apps[i].CName {
+			select {
+			case <-c.shutdown:
+				return
+			default:
+			}
+			inUse[certificateID(apps[i].Name, cname)] = struct{}{}
+			err = c.ensure(&apps[i], cname)
+			if err != nil {
+				log.Errorf("[acme] unable to ensure certificate for %q in app %q: %v", cname, apps[i].Name, err)
+			}
+		}
+	}
+	for _, cert := range existing {
+		if _, ok := inUse[cert.ID]; ok {
+			continue
+		}
+		err = removeCertificate(cert.App, cert.CName)
+		if err != nil {
+			log.Errorf("[acme] unable to remove certificate for %q in app %q: %v", cert.CName, cert.App, err)
+		}
+	}
+}
+
+// ensure issues a certificate for the CNAME when it has none, renews it when
+// close to expiring and pushes it again to routers missing it. CNAMEs with
+// certificates set by users are left alone.
+func (c *controller) ensure(a *app.App, cname string) error {
+	cert, err := getCertificate(a.Name, cname)
+	if err != nil {
+		return err
+	}
+	routerCerts, err := a.GetCertificates()
+	if err != nil {
+		return err
+	}
+	inRouters := true
+	for _, certs := range routerCerts {
+		if certs[cname] == "" {
+			inRouters = false
+			break
+		}
+	}
+	if cert == nil {
+		if len(routerCerts) > 0 && inRouters {
+			return nil
+		}
+		cert = &Certificate{ID: certificateID(a.Name, cname), App: a.Name, CName: cname, Status: StatusPending}
+	}
+	now := time.Now().UTC()
+	if cert.Certificate != "" && cert.ExpiresAt.Sub(now) > c.renewBefore {
+		if inRouters {
+			return nil
+		}
+		var key []byte
+		key, err = decrypt(cert.Key)
+		if err != nil {
+			return err
+		}
+		return a.SetCertificate(cname, cert.Certificate, string(key))
+	}
+	if cert.Status == StatusFailed && now.Sub(cert.LastAttempt) < c.retryInterval {
+		return nil
+	}
+	return c.issue(a, cert)
+}
+
+func (c *controller) issue(a *app.App, cert *Certificate) (err error) {
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeACMECertificate, Value: cert.ID},
+		ExtraTargets: []event.ExtraTarget{{Target: event.Target{Type: event.TargetTypeApp, Value: a.Name}}},
+		InternalKind: "acme certificate issue",
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permTypes.CtxTeam, a.Teams),
+			permission.Context(permTypes.CtxApp, a.Name),
+			permission.Context(permTypes.CtxPool, a.Pool),
+		)...),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	cert.Challenge = c.issuer.Challenge()
+	cert.LastAttempt = time.Now().UTC()
+	if cert.Certificate == "" {
+		cert.Status = StatusPending
+		err = saveCertificate(cert)
+		if err != nil {
+			return err
+		}
+	}
+	issued, err := c.issuer.Issue(context.Background(), cert.CName)
+	if err == nil {
+		err = a.SetCertificate(cert.CName, string(issued.Certificate), string(issued.Key))
+	}
+	if err != nil {
+		cert.Status = StatusFailed
+		cert.Error = err.Error()
+		if saveErr := saveCertificate(cert); saveErr != nil {
+			log.Errorf("[acme] unable to store failure for %q in app %q: %v", cert.CName, cert.App, saveErr)
+		}
+		return errors.Wrapf(err, "unable to issue certificate for %q", cert.CName)
+	}
+	key, err := encrypt(issued.Key)
+	if err != nil {
+		return err
+	}
+	cert.Status = StatusIssued
+	cert.Error = ""
+	cert.Certificate = string(issued.Certificate)
+	cert.Key = key
+	cert.IssuedAt = issued.NotBefore
+	cert.ExpiresAt = issued.NotAfter
+	return saveCertificate(cert)
+}
+
+// Shutdown stops the controller, waiting for the current certificate to be
+// handled.
+func (c *controller) Shutdown(ctx context.Context) error {
+	close(c.shutdown)
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
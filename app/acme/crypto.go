@@ -0,0 +1,76 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package acme
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+var errInvalidCiphertext = errors.New("invalid encrypted data")
+
+// encryptionKey returns the AES-256 key, base64 encoded in the
+// acme:encryption-key config entry, used to encrypt private keys at rest.
+func encryptionKey() ([]byte, error) {
+	encoded, err := config.GetString("acme:encryption-key")
+	if err != nil {
+		return nil, errors.New("acme:encryption-key is required to store certificates")
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid acme:encryption-key")
+	}
+	if len(key) != 32 {
+		return nil, errors.Errorf("acme:encryption-key must have 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	key, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encrypt(data []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func decrypt(data []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errInvalidCiphertext
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errInvalidCiphertext
+	}
+	return plain, nil
+}
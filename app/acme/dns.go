@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package acme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/net"
+)
+
+// DNSProvider manages the TXT records used to solve DNS-01 challenges.
+type DNSProvider interface {
+	Present(ctx context.Context, fqdn, value string) error
+	CleanUp(ctx context.Context, fqdn, value string) error
+}
+
+// DNSProviderFactory creates a DNS provider reading its settings from the
+// config entries under configPrefix.
+type DNSProviderFactory func(configPrefix string) (DNSProvider, error)
+
+var dnsProviders = struct {
+	sync.Mutex
+	factories map[string]DNSProviderFactory
+}{factories: map[string]DNSProviderFactory{}}
+
+// RegisterDNSProvider registers a DNS provider driver, available to be
+// selected through the acme:dns:driver config entry.
+func RegisterDNSProvider(name string, factory DNSProviderFactory) {
+	dnsProviders.Lock()
+	defer dnsProviders.Unlock()
+	dnsProviders.factories[name] = factory
+}
+
+func getDNSProvider() (DNSProvider, error) {
+	name, err := config.GetString("acme:dns:driver")
+	if err != nil {
+		return nil, errors.New("acme:dns:driver is required to solve dns-01 challenges")
+	}
+	dnsProviders.Lock()
+	factory, ok := dnsProviders.factories[name]
+	dnsProviders.Unlock()
+	if !ok {
+		return nil, errors.Errorf("unknown acme dns driver %q", name)
+	}
+	return factory("acme:dns:" + name)
+}
+
+func init() {
+	RegisterDNSProvider("webhook", newWebhookDNSProvider)
+}
+
+// webhookDNSProvider delegates the management of TXT records to an external
+// HTTP endpoint, which receives a POST to create and a DELETE to remove a
+// record, both with a JSON body holding the fqdn and value of the record.
+type webhookDNSProvider struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type webhookDNSRecord struct {
+	FQDN  string `json:"fqdn"`
+	Value string `json:"value"`
+}
+
+func newWebhookDNSProvider(configPrefix string) (DNSProvider, error) {
+	url, err := config.GetString(configPrefix + ":url")
+	if err != nil {
+		return nil, errors.Errorf("%s:url is required", configPrefix)
+	}
+	token, _ := config.GetString(configPrefix + ":token")
+	return &webhookDNSProvider{url: url, token: token, client: net.Dial15Full60ClientNoKeepAlive}, nil
+}
+
+func (p *webhookDNSProvider) Present(ctx context.Context, fqdn, value string) error {
+	return p.do(ctx, http.MethodPost, fqdn, value)
+}
+
+func (p *webhookDNSProvider) CleanUp(ctx context.Context, fqdn, value string) error {
+	return p.do(ctx, http.MethodDelete, fqdn, value)
+}
+
+func (p *webhookDNSProvider) do(ctx context.Context, method, fqdn, value string) error {
+	body, err := json.Marshal(webhookDNSRecord{FQDN: fqdn, Value: value})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	rsp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		data, _ := ioutil.ReadAll(rsp.Body)
+		return errors.Errorf("%s %s: invalid response code: %d: %s", method, p.url, rsp.StatusCode, string(data))
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	acmeAPI "golang.org/x/crypto/acme"
+)
+
+const (
+	ChallengeHTTP01 = "http-01"
+	ChallengeDNS01  = "dns-01"
+
+	challengeTTL = time.Hour
+)
+
+var ErrChallengeNotFound = errors.New("acme challenge not found")
+
+// Issued is a certificate issued by an ACME server.
+type Issued struct {
+	Certificate []byte
+	Key         []byte
+	NotBefore   time.Time
+	NotAfter    time.Time
+}
+
+// Issuer obtains certificates for domains.
+type Issuer interface {
+	Issue(ctx context.Context, domain string) (*Issued, error)
+	Challenge() string
+}
+
+type challengeSolver interface {
+	present(ctx context.Context, client *acmeAPI.Client, domain string, chal *acmeAPI.Challenge) error
+	cleanUp(ctx context.Context, client *acmeAPI.Client, domain string, chal *acmeAPI.Challenge) error
+}
+
+type acmeIssuer struct {
+	directoryURL string
+	email        string
+	challenge    string
+	solver       challengeSolver
+}
+
+type account struct {
+	ID  string `bson:"_id"`
+	Key []byte
+}
+
+func accountsCollection(conn *db.Storage) *storage.Collection {
+	return conn.Collection("acme_accounts")
+}
+
+func newIssuer() (Issuer, error) {
+	directoryURL, _ := config.GetString("acme:directory-url")
+	if directoryURL == "" {
+		directoryURL = acmeAPI.LetsEncryptURL
+	}
+	email, _ := config.GetString("acme:email")
+	challenge, _ := config.GetString("acme:challenge")
+	if challenge == "" {
+		challenge = ChallengeHTTP01
+	}
+	issuer := &acmeIssuer{directoryURL: directoryURL, email: email, challenge: challenge}
+	switch challenge {
+	case ChallengeHTTP01:
+		issuer.solver = http01Solver{}
+	case ChallengeDNS01:
+		provider, err := getDNSProvider()
+		if err != nil {
+			return nil, err
+		}
+		issuer.solver = dns01Solver{provider: provider}
+	default:
+		return nil, errors.Errorf("invalid acme:challenge %q, must be %s or %s", challenge, ChallengeHTTP01, ChallengeDNS01)
+	}
+	return issuer, nil
+}
+
+func (i *acmeIssuer) Challenge() string {
+	return i.challenge
+}
+
+// client returns a client registered in the ACME server, with the account
+// key kept encrypted in the database.
+func (i *acmeIssuer) client(ctx context.Context) (*acmeAPI.Client, error) {
+	key, err := i.accountKey()
+	if err != nil {
+		return nil, err
+	}
+	client := &acmeAPI.Client{Key: key, DirectoryURL: i.directoryURL}
+	acct := &acmeAPI.Account{}
+	if i.email != "" {
+		acct.Contact = []string{"mailto:" + i.email}
+	}
+	_, err = client.Register(ctx, acct, acmeAPI.AcceptTOS)
+	if err != nil && err != acmeAPI.ErrAccountAlreadyExists {
+		return nil, errors.Wrap(err, "unable to register acme account")
+	}
+	return client, nil
+}
+
+func (i *acmeIssuer) accountKey() (crypto.Signer, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	coll := accountsCollection(conn)
+	id := i.directoryURL + "/" + i.email
+	var acct account
+	err = coll.FindId(id).One(&acct)
+	if err == nil {
+		var data []byte
+		data, err = decrypt(acct.Key)
+		if err != nil {
+			return nil, err
+		}
+		return x509.ParseECPrivateKey(data)
+	}
+	if err != mgo.ErrNotFound {
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	data, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	acct = account{ID: id}
+	acct.Key, err = encrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	err = coll.Insert(acct)
+	if mgo.IsDup(err) {
+		// Another API instance registered the account first.
+		return i.accountKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (i *acmeIssuer) Issue(ctx context.Context, domain string) (*Issued, error) {
+	client, err := i.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order, err := client.AuthorizeOrder(ctx, acmeAPI.DomainIDs(domain))
+	if err != nil {
+		return nil, err
+	}
+	for _, authzURL := range order.AuthzURLs {
+		err = i.authorize(ctx, client, domain, authzURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, err
+	}
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{domain}}, certKey)
+	if err != nil {
+		return nil, err
+	}
+	der, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(der) == 0 {
+		return nil, errors.New("acme server returned no certificate")
+	}
+	leaf, err := x509.ParseCertificate(der[0])
+	if err != nil {
+		return nil, err
+	}
+	var chain []byte
+	for _, b := range der {
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: b})...)
+	}
+	keyData, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Issued{
+		Certificate: chain,
+		Key:         pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData}),
+		NotBefore:   leaf.NotBefore,
+		NotAfter:    leaf.NotAfter,
+	}, nil
+}
+
+func (i *acmeIssuer) authorize(ctx context.Context, client *acmeAPI.Client, domain, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return err
+	}
+	if authz.Status == acmeAPI.StatusValid {
+		return nil
+	}
+	var chal *acmeAPI.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == i.challenge {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return errors.Errorf("acme server offered no %s challenge for %q", i.challenge, domain)
+	}
+	err = i.solver.present(ctx, client, domain, chal)
+	if err != nil {
+		return err
+	}
+	defer i.solver.cleanUp(ctx, client, domain, chal)
+	_, err = client.Accept(ctx, chal)
+	if err != nil {
+		return err
+	}
+	_, err = client.WaitAuthorization(ctx, authz.URI)
+	return err
+}
+
+type dns01Solver struct {
+	provider DNSProvider
+}
+
+func (s dns01Solver) record(client *acmeAPI.Client, domain string, chal *acmeAPI.Challenge) (string, string, error) {
+	value, err := client.DNS01ChallengeRecord(chal.Token)
+	return "_acme-challenge." + domain + ".", value, err
+}
+
+func (s dns01Solver) present(ctx context.Context, client *acmeAPI.Client, domain string, chal *acmeAPI.Challenge) error {
+	fqdn, value, err := s.record(client, domain, chal)
+	if err != nil {
+		return err
+	}
+	return s.provider.Present(ctx, fqdn, value)
+}
+
+func (s dns01Solver) cleanUp(ctx context.Context, client *acmeAPI.Client, domain string, chal *acmeAPI.Challenge) error {
+	fqdn, value, err := s.record(client, domain, chal)
+	if err != nil {
+		return err
+	}
+	return s.provider.CleanUp(ctx, fqdn, value)
+}
+
+// http01Solver stores challenge responses in the database, so any API
+// instance can answer them. Routers must forward requests to
+// /.well-known/acme-challenge/{token} on app CNAMEs to the tsuru API.
+type http01Solver struct{}
+
+type http01Challenge struct {
+	Token    string `bson:"_id"`
+	Response string
+	ExpireAt time.Time
+}
+
+func challengesCollection(conn *db.Storage) *storage.Collection {
+	coll := conn.Collection("acme_challenges")
+	coll.EnsureIndex(mgo.Index{Key: []string{"expireat"}, ExpireAfter: time.Second})
+	return coll
+}
+
+func (http01Solver) present(ctx context.Context, client *acmeAPI.Client, domain string, chal *acmeAPI.Challenge) error {
+	response, err := client.HTTP01ChallengeResponse(chal.Token)
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = challengesCollection(conn).UpsertId(chal.Token, http01Challenge{
+		Token:    chal.Token,
+		Response: response,
+		ExpireAt: time.Now().UTC().Add(challengeTTL),
+	})
+	return err
+}
+
+func (http01Solver) cleanUp(ctx context.Context, client *acmeAPI.Client, domain string, chal *acmeAPI.Challenge) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return challengesCollection(conn).RemoveId(chal.Token)
+}
+
+// HTTP01Response returns the response expected by the ACME server for the
+// HTTP-01 challenge with the given token.
+func HTTP01Response(token string) (string, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	var chal http01Challenge
+	err = challengesCollection(conn).FindId(token).One(&chal)
+	if err == mgo.ErrNotFound {
+		return "", ErrChallengeNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return chal.Response, nil
+}
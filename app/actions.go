@@ -265,6 +265,26 @@ func removeAllRoutersBackend(ctx context.Context, app *App) error {
 	return multi.ToError()
 }
 
+func addAllRoutersBackend(ctx context.Context, app *App) error {
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(ctx, appRouter.Name)
+		if err != nil {
+			return err
+		}
+		if _, ok := r.(router.RouterV2); ok {
+			err = router.Store(app.GetName(), app.GetName(), r.GetType())
+		} else if optsRouter, ok := r.(router.OptsRouter); ok {
+			err = optsRouter.AddBackendOpts(ctx, app, appRouter.Opts)
+		} else {
+			err = r.AddBackend(ctx, app)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 var addRouterBackend = action.Action{
 	Name: "add-router-backend",
 	Forward: func(ctx action.FWContext) (result action.Result, err error) {
@@ -281,21 +301,9 @@ var addRouterBackend = action.Action{
 				removeAllRoutersBackend(stdCtx, app)
 			}
 		}()
-		for _, appRouter := range app.GetRouters() {
-			r, err := router.Get(stdCtx, appRouter.Name)
-			if err != nil {
-				return nil, err
-			}
-			if _, ok := r.(router.RouterV2); ok {
-				err = router.Store(app.GetName(), app.GetName(), r.GetType())
-			} else if optsRouter, ok := r.(router.OptsRouter); ok {
-				err = optsRouter.AddBackendOpts(ctx.Context, app, appRouter.Opts)
-			} else {
-				err = r.AddBackend(ctx.Context, app)
-			}
-			if err != nil {
-				return nil, err
-			}
+		err = addAllRoutersBackend(stdCtx, app)
+		if err != nil {
+			return nil, err
 		}
 		return app, nil
 	},
@@ -598,11 +606,12 @@ var updateAppProvisioner = action.Action{
 	},
 }
 
+var cnameRegexp = regexp.MustCompile(`^(\*\.)?[a-zA-Z0-9][\w-.]+$`)
+
 var validateNewCNames = action.Action{
 	Name: "validate-new-cnames",
 	Forward: func(ctx action.FWContext) (action.Result, error) {
 		app := ctx.Params[0].(*App)
-		cnameRegexp := regexp.MustCompile(`^(\*\.)?[a-zA-Z0-9][\w-.]+$`)
 		cnames := ctx.Params[1].([]string)
 		conn, err := db.Conn()
 		if err != nil {
@@ -0,0 +1,185 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package admission calls external webhooks before app operations are
+// executed, allowing company specific policy systems to deny or mutate them.
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	internalConfig "github.com/tsuru/tsuru/config"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/net"
+)
+
+const (
+	OperationAppCreate = "app.create"
+	OperationAppDeploy = "app.deploy"
+	OperationAppEnvSet = "app.update.env.set"
+
+	FailurePolicyFail   = "fail"
+	FailurePolicyIgnore = "ignore"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Webhook is an admission webhook, as declared in admission:webhooks.
+type Webhook struct {
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	Operations    []string          `json:"operations"`
+	Timeout       string            `json:"timeout"`
+	FailurePolicy string            `json:"failure-policy"`
+	Headers       map[string]string `json:"headers"`
+}
+
+// Request is the payload sent to the webhooks.
+type Request struct {
+	UID       string      `json:"uid"`
+	Operation string      `json:"operation"`
+	App       string      `json:"app"`
+	User      string      `json:"user"`
+	Object    interface{} `json:"object"`
+}
+
+// Response is the payload expected from the webhooks. When Object is set,
+// it replaces the object of the operation.
+type Response struct {
+	Allowed bool            `json:"allowed"`
+	Message string          `json:"message,omitempty"`
+	Object  json.RawMessage `json:"object,omitempty"`
+}
+
+// DeniedError is returned when a webhook denies an operation.
+type DeniedError struct {
+	Webhook string
+	Message string
+}
+
+func (e *DeniedError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("operation denied by admission webhook %q", e.Webhook)
+	}
+	return fmt.Sprintf("operation denied by admission webhook %q: %s", e.Webhook, e.Message)
+}
+
+func (w *Webhook) handles(operation string) bool {
+	if len(w.Operations) == 0 {
+		return true
+	}
+	for _, op := range w.Operations {
+		if op == operation {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhook) timeout() time.Duration {
+	timeout, err := time.ParseDuration(w.Timeout)
+	if err != nil || timeout <= 0 {
+		return defaultTimeout
+	}
+	return timeout
+}
+
+func webhooks() ([]Webhook, error) {
+	var hooks []Webhook
+	err := internalConfig.UnmarshalConfig("admission:webhooks", &hooks)
+	if err != nil {
+		if _, ok := errors.Cause(err).(config.ErrKeyNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// Review sends the object of the operation to the webhooks configured for
+// it, in order. Mutations returned by a webhook are decoded into object and
+// sent to the next webhooks. A *DeniedError is returned if any webhook
+// denies the operation. Webhooks that can't be reached deny the operation,
+// unless their failure policy is "ignore".
+func Review(ctx context.Context, operation, appName, user string, object interface{}) error {
+	hooks, err := webhooks()
+	if err != nil {
+		return errors.Wrap(err, "unable to load admission webhooks")
+	}
+	for _, hook := range hooks {
+		if !hook.handles(operation) {
+			continue
+		}
+		req := Request{
+			UID:       bson.NewObjectId().Hex(),
+			Operation: operation,
+			App:       appName,
+			User:      user,
+			Object:    object,
+		}
+		resp, err := hook.call(ctx, req)
+		if err != nil {
+			if hook.FailurePolicy == FailurePolicyIgnore {
+				log.Errorf("[admission] ignoring failure calling webhook %q: %v", hook.Name, err)
+				continue
+			}
+			return &DeniedError{Webhook: hook.Name, Message: err.Error()}
+		}
+		if !resp.Allowed {
+			return &DeniedError{Webhook: hook.Name, Message: resp.Message}
+		}
+		if len(resp.Object) > 0 {
+			err = json.Unmarshal(resp.Object, object)
+			if err != nil {
+				return &DeniedError{Webhook: hook.Name, Message: fmt.Sprintf("invalid mutated object: %v", err)}
+			}
+		}
+	}
+	return nil
+}
+
+func (w *Webhook) call(ctx context.Context, admissionReq Request) (*Response, error) {
+	body, err := json.Marshal(admissionReq)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, w.timeout())
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+	httpResp, err := net.Dial15Full60ClientNoKeepAlive.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	data, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d: %s", httpResp.StatusCode, data)
+	}
+	var resp Response
+	err = json.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid admission response")
+	}
+	return &resp, nil
+}
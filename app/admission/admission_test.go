@@ -0,0 +1,159 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+type payload struct {
+	Image string            `json:"image"`
+	Envs  map[string]string `json:"envs"`
+}
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("admission")
+}
+
+func setWebhooks(hooks ...map[string]interface{}) {
+	var entries []interface{}
+	for _, h := range hooks {
+		entry := map[interface{}]interface{}{}
+		for k, v := range h {
+			entry[k] = v
+		}
+		entries = append(entries, entry)
+	}
+	config.Set("admission:webhooks", entries)
+}
+
+func (s *S) TestReviewNoWebhooks(c *check.C) {
+	obj := payload{Image: "myimg"}
+	err := Review(context.TODO(), OperationAppDeploy, "myapp", "me@tsuru.io", &obj)
+	c.Assert(err, check.IsNil)
+	c.Assert(obj.Image, check.Equals, "myimg")
+}
+
+func (s *S) TestReviewAllowed(c *check.C) {
+	var received Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Header.Get("X-Token"), check.Equals, "secret")
+		err := json.NewDecoder(r.Body).Decode(&received)
+		c.Check(err, check.IsNil)
+		fmt.Fprint(w, `{"allowed": true}`)
+	}))
+	defer srv.Close()
+	setWebhooks(map[string]interface{}{
+		"name":       "policy",
+		"url":        srv.URL,
+		"operations": []interface{}{OperationAppDeploy},
+		"headers":    map[interface{}]interface{}{"X-Token": "secret"},
+	})
+	obj := payload{Image: "myimg"}
+	err := Review(context.TODO(), OperationAppDeploy, "myapp", "me@tsuru.io", &obj)
+	c.Assert(err, check.IsNil)
+	c.Assert(received.UID, check.Not(check.Equals), "")
+	c.Assert(received.Operation, check.Equals, OperationAppDeploy)
+	c.Assert(received.App, check.Equals, "myapp")
+	c.Assert(received.User, check.Equals, "me@tsuru.io")
+	c.Assert(received.Object, check.DeepEquals, map[string]interface{}{"image": "myimg", "envs": nil})
+}
+
+func (s *S) TestReviewSkipsOtherOperations(c *check.C) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		fmt.Fprint(w, `{"allowed": false}`)
+	}))
+	defer srv.Close()
+	setWebhooks(map[string]interface{}{
+		"name":       "policy",
+		"url":        srv.URL,
+		"operations": []interface{}{OperationAppCreate},
+	})
+	err := Review(context.TODO(), OperationAppEnvSet, "myapp", "me@tsuru.io", &payload{})
+	c.Assert(err, check.IsNil)
+	c.Assert(called, check.Equals, false)
+}
+
+func (s *S) TestReviewDenied(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"allowed": false, "message": "images must come from the internal registry"}`)
+	}))
+	defer srv.Close()
+	setWebhooks(map[string]interface{}{"name": "policy", "url": srv.URL})
+	err := Review(context.TODO(), OperationAppDeploy, "myapp", "me@tsuru.io", &payload{Image: "myimg"})
+	c.Assert(err, check.DeepEquals, &DeniedError{Webhook: "policy", Message: "images must come from the internal registry"})
+	c.Assert(err, check.ErrorMatches, `operation denied by admission webhook "policy": images must come from the internal registry`)
+}
+
+func (s *S) TestReviewMutation(c *check.C) {
+	var secondReceived Request
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"allowed": true, "object": {"image": "registry.internal/myimg", "envs": {"TEAM": "a"}}}`)
+	}))
+	defer first.Close()
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&secondReceived)
+		fmt.Fprint(w, `{"allowed": true}`)
+	}))
+	defer second.Close()
+	setWebhooks(
+		map[string]interface{}{"name": "first", "url": first.URL},
+		map[string]interface{}{"name": "second", "url": second.URL},
+	)
+	obj := payload{Image: "myimg"}
+	err := Review(context.TODO(), OperationAppDeploy, "myapp", "me@tsuru.io", &obj)
+	c.Assert(err, check.IsNil)
+	c.Assert(obj, check.DeepEquals, payload{Image: "registry.internal/myimg", Envs: map[string]string{"TEAM": "a"}})
+	c.Assert(secondReceived.Object, check.DeepEquals, map[string]interface{}{
+		"image": "registry.internal/myimg",
+		"envs":  map[string]interface{}{"TEAM": "a"},
+	})
+}
+
+func (s *S) TestReviewFailurePolicy(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprint(w, `{"allowed": false}`)
+	}))
+	defer srv.Close()
+	setWebhooks(map[string]interface{}{"name": "slow", "url": srv.URL, "timeout": "50ms"})
+	err := Review(context.TODO(), OperationAppCreate, "myapp", "me@tsuru.io", &payload{})
+	c.Assert(err, check.FitsTypeOf, &DeniedError{})
+	c.Assert(err.(*DeniedError).Webhook, check.Equals, "slow")
+	setWebhooks(map[string]interface{}{"name": "slow", "url": srv.URL, "timeout": "50ms", "failure-policy": FailurePolicyIgnore})
+	err = Review(context.TODO(), OperationAppCreate, "myapp", "me@tsuru.io", &payload{})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestReviewInvalidStatus(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	setWebhooks(map[string]interface{}{"name": "broken", "url": srv.URL})
+	err := Review(context.TODO(), OperationAppCreate, "myapp", "me@tsuru.io", &payload{})
+	c.Assert(err, check.ErrorMatches, `operation denied by admission webhook "broken": unexpected status code 500: `)
+}
@@ -38,6 +38,8 @@ import (
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/nodecontainer"
 	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/unithistory"
+	"github.com/tsuru/tsuru/readcache"
 	"github.com/tsuru/tsuru/registry"
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/router/rebuild"
@@ -66,13 +68,16 @@ var (
 
 	ErrRouterAlreadyLinked = errors.New("router already linked to this app")
 
-	ErrNoVersionProvisioner = errors.New("The current app provisioner does not support multiple versions handling")
-	ErrKillUnitProvisioner  = errors.New("The current app provisioner does not support killing a unit")
-	ErrSwapMultipleVersions = errors.New("swapping apps with multiple versions is not allowed")
-	ErrSwapMultipleRouters  = errors.New("swapping apps with multiple routers is not supported")
-	ErrSwapDifferentRouters = errors.New("swapping apps with different routers is not supported")
-	ErrSwapNoCNames         = errors.New("no cnames to swap")
-	ErrSwapDeprecated       = errors.New("swapping using router api v2 will work only with cnameOnly")
+	ErrNoVersionProvisioner       = errors.New("The current app provisioner does not support multiple versions handling")
+	ErrKillUnitProvisioner        = errors.New("The current app provisioner does not support killing a unit")
+	ErrRemoveUnitsByIDProvisioner = errors.New("The current app provisioner does not support removing units by ID")
+	ErrRestartUnitProvisioner     = errors.New("The current app provisioner does not support restarting a single unit")
+	ErrUnitStatsUnavailable       = errors.New("The current app provisioner does not support unit stats")
+	ErrSwapMultipleVersions       = errors.New("swapping apps with multiple versions is not allowed")
+	ErrSwapMultipleRouters        = errors.New("swapping apps with multiple routers is not supported")
+	ErrSwapDifferentRouters       = errors.New("swapping apps with different routers is not supported")
+	ErrSwapNoCNames               = errors.New("no cnames to swap")
+	ErrSwapDeprecated             = errors.New("swapping using router api v2 will work only with cnameOnly")
 )
 
 var (
@@ -123,7 +128,22 @@ type App struct {
 	Tags            []string
 	Error           string
 	Routers         []appTypes.AppRouter
+	RouterPolicy    *routerTypes.Policy `bson:",omitempty"`
+	Shadow          *routerTypes.Shadow `bson:",omitempty"`
+	Visibility      string              `bson:",omitempty"`
 	Metadata        appTypes.Metadata
+	Dependencies    []string
+	Template        string
+	Project         string
+	EnvGroups       []string
+	AutoRollback    AutoRollback
+	DeployApproval  bool
+	DeployGates     []DeployGate
+	Disruption      appTypes.DisruptionSettings
+	Placement       []appTypes.PlacementConstraint `bson:",omitempty"`
+	Archived        *ArchiveState                  `bson:",omitempty"`
+	Protection      Protection                     `bson:",omitempty"`
+	Maintenance     *MaintenanceState              `bson:",omitempty"`
 
 	// UUID is a v4 UUID lazily generated on the first call to GetUUID()
 	UUID string
@@ -278,6 +298,45 @@ func (app *App) MarshalJSON() ([]byte, error) {
 	result["tags"] = app.Tags
 	result["routers"] = routers
 	result["metadata"] = app.Metadata
+	if app.Visibility != "" {
+		result["visibility"] = app.Visibility
+	}
+	if len(app.Dependencies) > 0 {
+		result["dependencies"] = app.Dependencies
+	}
+	if app.Template != "" {
+		result["template"] = app.Template
+	}
+	if app.Project != "" {
+		result["project"] = app.Project
+	}
+	if len(app.EnvGroups) > 0 {
+		result["envGroups"] = app.EnvGroups
+	}
+	if app.Archived != nil {
+		result["archived"] = app.Archived
+	}
+	if app.Protection.Enabled {
+		result["protection"] = app.Protection
+	}
+	if app.AutoRollback.Enabled {
+		result["autoRollback"] = map[string]interface{}{
+			"enabled": true,
+			"window":  app.AutoRollback.window().String(),
+		}
+	}
+	if app.DeployApproval {
+		result["deployApproval"] = true
+	}
+	if len(app.DeployGates) > 0 {
+		result["deployGates"] = app.DeployGates
+	}
+	if !app.Disruption.IsEmpty() {
+		result["disruption"] = app.Disruption
+	}
+	if len(app.Placement) > 0 {
+		result["placement"] = app.Placement
+	}
 	q, err := app.GetQuota()
 	if err != nil {
 		errMsgs = append(errMsgs, fmt.Sprintf("unable to get app quota: %+v", err))
@@ -338,12 +397,14 @@ func (app *App) MarshalJSON() ([]byte, error) {
 // name.
 func GetByName(ctx context.Context, name string) (*App, error) {
 	var app App
-	conn, err := db.Conn()
-	if err != nil {
-		return nil, err
-	}
-	defer conn.Close()
-	err = conn.Apps().Find(bson.M{"name": name}).One(&app)
+	err := readcache.Fetch(readcache.Apps, name, &app, func() error {
+		conn, err := db.Conn()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return conn.Apps().Find(bson.M{"name": name}).One(&app)
+	})
 	if err == mgo.ErrNotFound {
 		return nil, appTypes.ErrAppNotFound
 	}
@@ -355,8 +416,8 @@ func GetByName(ctx context.Context, name string) (*App, error) {
 //
 // Creating a new app is a process composed of the following steps:
 //
-//       1. Save the app in the database
-//       2. Provision the app using the provisioner
+//  1. Save the app in the database
+//  2. Provision the app using the provisioner
 func CreateApp(ctx context.Context, app *App, user *auth.User) error {
 	if app.ctx == nil {
 		app.ctx = ctx
@@ -404,6 +465,15 @@ func CreateApp(ctx context.Context, app *App, user *auth.User) error {
 	if err != nil {
 		return err
 	}
+	if app.Visibility != "" {
+		err = app.validateVisibility(app.Visibility, app.GetRouters())
+		if err != nil {
+			return err
+		}
+		if app.Visibility == routerTypes.VisibilityPublic {
+			app.Visibility = ""
+		}
+	}
 	actions := []*action.Action{
 		&reserveTeamApp,
 		&reserveUserApp,
@@ -472,6 +542,10 @@ func (app *App) Update(args UpdateAppArgs) (err error) {
 		app.Description = description
 	}
 	if poolName != "" {
+		err = app.CheckPoolChangeProtection(poolName)
+		if err != nil {
+			return err
+		}
 		app.Pool = poolName
 		app.provisioner = nil
 		_, err = app.getPoolForApp(app.Pool)
@@ -670,6 +744,9 @@ func (app *App) unbindVolumes() error {
 
 // Delete deletes an app.
 func Delete(ctx context.Context, app *App, evt *event.Event, requestID string) error {
+	if err := app.CheckRemovalProtection(); err != nil {
+		return err
+	}
 	w := evt
 	isSwapped, swappedWith, err := router.IsSwapped(app.GetName())
 	if err != nil {
@@ -747,10 +824,18 @@ func Delete(ctx context.Context, app *App, evt *event.Event, requestID string) e
 	if err != nil {
 		logErr("Failed to remove router backend from database", err)
 	}
+	err = app.removeDomains(ctx)
+	if err != nil {
+		logErr("Unable to remove domains", err)
+	}
 	err = app.unbindVolumes()
 	if err != nil {
 		logErr("Unable to unbind volumes", err)
 	}
+	err = unithistory.RemoveApp(app.Name)
+	if err != nil {
+		logErr("Unable to remove units status history", err)
+	}
 	token := app.Env["TSURU_APP_TOKEN"].Value
 	err = AuthScheme.AppLogout(ctx, token)
 	if err != nil {
@@ -775,6 +860,10 @@ func Delete(ctx context.Context, app *App, evt *event.Event, requestID string) e
 		}
 	}
 
+	err = app.removeConfigVersions()
+	if err != nil {
+		logErr("Unable to remove configuration versions", err)
+	}
 	conn, err := db.Conn()
 	if err == nil {
 		defer conn.Close()
@@ -783,6 +872,7 @@ func Delete(ctx context.Context, app *App, evt *event.Event, requestID string) e
 	if err != nil {
 		logErr("Unable to remove app from db", err)
 	}
+	readcache.Invalidate(readcache.Apps, appName)
 	// NOTE: some provisioners hold apps' info on their own (e.g. apps.tsuru.io
 	// CustomResource on Kubernetes). Deleting the app on provisioner as the last
 	// step of removal, we may give time enough to external components
@@ -829,10 +919,44 @@ func (app *App) DeleteVersion(ctx context.Context, w io.Writer, versionStr strin
 	if err != nil {
 		logErr("Unable to destroy app in provisioner", err)
 	}
+	err = app.stopShadowOf(version.Version())
+	if err != nil {
+		logErr("Unable to stop shadowing version", err)
+	}
 
 	return nil
 }
 
+// VersionArtifact returns the artifact used to build the given version of
+// the app, as kept by the builder of its provisioner.
+func (app *App) VersionArtifact(ctx context.Context, versionStr string) (*builder.Artifact, error) {
+	version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, versionStr)
+	if err != nil {
+		return nil, err
+	}
+	return app.versionArtifact(ctx, version)
+}
+
+func (app *App) versionArtifact(ctx context.Context, version appTypes.AppVersion) (*builder.Artifact, error) {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	deployProv, ok := prov.(provision.BuilderDeploy)
+	if !ok {
+		return nil, provision.ProvisionerNotSupported{Prov: prov, Action: "version artifacts"}
+	}
+	b, err := app.getBuilder()
+	if err != nil {
+		return nil, err
+	}
+	artifactBuilder, ok := b.(builder.ArtifactBuilder)
+	if !ok {
+		return nil, provision.ProvisionerNotSupported{Prov: prov, Action: "version artifacts"}
+	}
+	return artifactBuilder.Artifact(ctx, deployProv, app, version)
+}
+
 func (app *App) BindUnit(unit *provision.Unit) error {
 	instances, err := service.GetServiceInstancesBoundToApp(app.Name)
 	if err != nil {
@@ -876,6 +1000,9 @@ func (app *App) UnbindUnit(unit *provision.Unit) error {
 // AddUnits creates n new units within the provisioner, saves new units in the
 // database and enqueues the apprc serialization.
 func (app *App) AddUnits(n uint, process, versionStr string, w io.Writer) error {
+	if app.Archived != nil {
+		return ErrAppArchived
+	}
 	if n == 0 {
 		return errors.New("Cannot add zero units.")
 	}
@@ -934,13 +1061,17 @@ func (app *App) ensureNoAutoscaler(process string) error {
 // RemoveUnits removes n units from the app. It's a process composed of
 // multiple steps:
 //
-//     1. Remove units from the provisioner
-//     2. Update quota
+//  1. Remove units from the provisioner
+//  2. Update quota
 func (app *App) RemoveUnits(ctx context.Context, n uint, process, versionStr string, w io.Writer) error {
 	err := app.ensureNoAutoscaler(process)
 	if err != nil {
 		return err
 	}
+	err = app.CheckUnitsProtection(int(n))
+	if err != nil {
+		return err
+	}
 	prov, err := app.getProvisioner()
 	if err != nil {
 		return err
@@ -958,6 +1089,55 @@ func (app *App) RemoveUnits(ctx context.Context, n uint, process, versionStr str
 	return nil
 }
 
+// RemoveUnitsByID removes the units of the app with the given IDs, updating
+// routes and binds like RemoveUnits.
+func (app *App) RemoveUnitsByID(ctx context.Context, unitIDs []string, w io.Writer) error {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return err
+	}
+	unitsProv, ok := prov.(provision.RemoveUnitsByIDProvisioner)
+	if !ok {
+		return ErrRemoveUnitsByIDProvisioner
+	}
+	err = app.CheckUnitsProtection(len(unitIDs))
+	if err != nil {
+		return err
+	}
+	units, err := app.Units()
+	if err != nil {
+		return err
+	}
+	unitsByID := make(map[string]provision.Unit, len(units))
+	for _, u := range units {
+		unitsByID[u.ID] = u
+	}
+	toRemove := make([]string, 0, len(unitIDs))
+	seen := map[string]bool{}
+	for _, id := range unitIDs {
+		u, ok := unitsByID[id]
+		if !ok {
+			return &provision.UnitNotFoundError{ID: id}
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		err = app.ensureNoAutoscaler(u.ProcessName)
+		if err != nil {
+			return err
+		}
+		toRemove = append(toRemove, id)
+	}
+	w = app.withLogWriter(w)
+	err = unitsProv.RemoveUnitsByID(ctx, app, toRemove, w)
+	rebuild.RoutesRebuildOrEnqueueWithProgress(app.Name, w)
+	if err != nil {
+		return newErrorWithLog(err, app, "remove units")
+	}
+	return nil
+}
+
 // SetUnitStatus changes the status of the given unit.
 func (app *App) SetUnitStatus(unitName string, status provision.Status) error {
 	units, err := app.Units()
@@ -992,6 +1172,20 @@ func (app *App) KillUnit(unitName string, force bool) error {
 	return unitProv.KillUnit(app.ctx, app, unitName, force)
 }
 
+// RestartUnit replaces a single unit of the app, leaving the other units of
+// its process untouched.
+func (app *App) RestartUnit(unitName string, w io.Writer) error {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return err
+	}
+	unitProv, ok := prov.(provision.RestartUnitProvisioner)
+	if !ok {
+		return ErrRestartUnitProvisioner
+	}
+	return unitProv.RestartUnit(app.ctx, app, unitName, app.withLogWriter(w))
+}
+
 type UpdateUnitsResult struct {
 	ID    string
 	Found bool
@@ -1236,7 +1430,64 @@ func (app *App) validate() error {
 	if err != nil {
 		return err
 	}
-	return app.validatePlan()
+	err = app.validatePlan()
+	if err != nil {
+		return err
+	}
+	return app.validatePoolExpressions(nil, nil)
+}
+
+// validatePoolExpressions checks the app, along with services and routers
+// about to be added to it, against the expression constraints of its pool.
+func (app *App) validatePoolExpressions(services, routers []string) error {
+	p, err := pool.GetPoolByName(app.ctx, app.Pool)
+	if err != nil {
+		return err
+	}
+	spec, err := app.poolConstraintSpec()
+	if err != nil {
+		return err
+	}
+	spec.Services = appendMissing(spec.Services, services...)
+	spec.Routers = appendMissing(spec.Routers, routers...)
+	return p.CheckConstraintExpressions(spec)
+}
+
+func (app *App) poolConstraintSpec() (pool.ConstraintSpec, error) {
+	spec := pool.ConstraintSpec{
+		Name:     app.Name,
+		Team:     app.TeamOwner,
+		Plan:     app.Plan.Name,
+		Platform: app.Platform,
+		Tags:     app.Tags,
+	}
+	for _, r := range app.GetRouters() {
+		spec.Routers = appendMissing(spec.Routers, r.Name)
+	}
+	instances, err := service.GetServiceInstancesBoundToApp(app.Name)
+	if err != nil {
+		return spec, err
+	}
+	for _, instance := range instances {
+		spec.Services = appendMissing(spec.Services, instance.ServiceName)
+	}
+	return spec, nil
+}
+
+func appendMissing(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
 }
 
 func (app *App) validatePlan() error {
@@ -1250,7 +1501,7 @@ func (app *App) validatePlan() error {
 	}
 	planSet := set.FromSlice(plans)
 	if !planSet.Includes(app.Plan.Name) {
-		msg := fmt.Sprintf("App plan %q is not allowed on pool %q", app.Plan.Name, pool.Name)
+		msg := fmt.Sprintf("App plan %q is not allowed on pool %q. Allowed plans: %s", app.Plan.Name, pool.Name, strings.Join(plans, ", "))
 		return &tsuruErrors.ValidationError{Message: msg}
 	}
 	return nil
@@ -1329,7 +1580,7 @@ func (app *App) ValidateService(services ...string) error {
 			return &tsuruErrors.ValidationError{Message: msg}
 		}
 	}
-	return nil
+	return app.validatePoolExpressions(services, nil)
 }
 
 // InstanceEnvs returns a map of environment variables that belongs to the
@@ -1394,6 +1645,9 @@ func (app *App) run(cmd string, w io.Writer, args provision.RunArgs) error {
 
 // Restart runs the restart hook for the app, writing its output to w.
 func (app *App) Restart(ctx context.Context, process, versionStr string, w io.Writer) error {
+	if app.Archived != nil {
+		return ErrAppArchived
+	}
 	w = app.withLogWriter(w)
 	msg := fmt.Sprintf("---- Restarting process %q ----", process)
 	if process == "" {
@@ -1660,6 +1914,27 @@ func (app *App) GetCpuShare() int {
 	return app.Plan.CpuShare
 }
 
+// GetCPUQuota returns the hard limit of CPU time, in microseconds, each unit
+// of the app may use every GetCPUPeriod microseconds.
+func (app *App) GetCPUQuota() int64 {
+	return app.Plan.CPUQuota
+}
+
+// GetCPUPeriod returns the period, in microseconds, of the CPU quota.
+func (app *App) GetCPUPeriod() int64 {
+	return app.Plan.CPUPeriod
+}
+
+// GetBlkioWeight returns the relative block IO weight for the app.
+func (app *App) GetBlkioWeight() int64 {
+	return app.Plan.BlkioWeight
+}
+
+// GetPidsLimit returns the maximum number of processes in each unit.
+func (app *App) GetPidsLimit() int64 {
+	return app.Plan.PidsLimit
+}
+
 func (app *App) GetAddresses() ([]string, error) {
 	routers, err := app.GetRoutersWithAddr()
 	if err != nil {
@@ -1696,6 +1971,21 @@ func (app *App) GetInternalBindableAddresses() ([]string, error) {
 	return addresses, nil
 }
 
+// ExposedEndpoints returns the addresses where the TCP and UDP ports exposed
+// by the app processes are reachable, it's empty when the app provisioner
+// doesn't support exposing ports.
+func (app *App) ExposedEndpoints() ([]provision.AppExposedEndpoint, error) {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	portsProv, ok := prov.(provision.ExposedPortsProvisioner)
+	if !ok {
+		return nil, nil
+	}
+	return portsProv.ExposedEndpoints(app.ctx, app)
+}
+
 func (app *App) GetQuotaInUse() (int, error) {
 	units, err := app.Units()
 	if err != nil {
@@ -1779,7 +2069,8 @@ func (app *App) Envs() map[string]bind.EnvVar {
 	return mergedEnvs
 }
 
-// SetEnvs saves a list of environment variables in the app.
+// SetEnvs saves a list of environment variables in the app. Each change is
+// recorded as a new configuration version of the app.
 func (app *App) SetEnvs(setEnvs bind.SetEnvArgs) error {
 	if setEnvs.ManagedBy == "" && len(setEnvs.Envs) == 0 {
 		return nil
@@ -1792,6 +2083,11 @@ func (app *App) SetEnvs(setEnvs bind.SetEnvArgs) error {
 		}
 	}
 
+	previous, err := app.currentConfigVersion()
+	if err != nil {
+		return err
+	}
+
 	if setEnvs.Writer != nil && len(setEnvs.Envs) > 0 {
 		fmt.Fprintf(setEnvs.Writer, "---- Setting %d new environment variables ----\n", len(setEnvs.Envs))
 	}
@@ -1824,11 +2120,12 @@ func (app *App) SetEnvs(setEnvs bind.SetEnvArgs) error {
 		return err
 	}
 
-	if setEnvs.ShouldRestart {
-		return app.restartIfUnits(setEnvs.Writer)
-	}
-
-	return nil
+	return app.applyConfigVersion(previous, applyConfigArgs{
+		restart:   setEnvs.ShouldRestart,
+		rolling:   setEnvs.RollingRestart,
+		batchSize: setEnvs.BatchSize,
+		w:         setEnvs.Writer,
+	})
 }
 
 // UnsetEnvs removes environment variables from an app, serializing the
@@ -1837,6 +2134,10 @@ func (app *App) UnsetEnvs(unsetEnvs bind.UnsetEnvArgs) error {
 	if len(unsetEnvs.VariableNames) == 0 {
 		return nil
 	}
+	previous, err := app.currentConfigVersion()
+	if err != nil {
+		return err
+	}
 	if unsetEnvs.Writer != nil {
 		fmt.Fprintf(unsetEnvs.Writer, "---- Unsetting %d environment variables ----\n", len(unsetEnvs.VariableNames))
 	}
@@ -1852,10 +2153,54 @@ func (app *App) UnsetEnvs(unsetEnvs bind.UnsetEnvArgs) error {
 	if err != nil {
 		return err
 	}
-	if unsetEnvs.ShouldRestart {
-		return app.restartIfUnits(unsetEnvs.Writer)
+	return app.applyConfigVersion(previous, applyConfigArgs{
+		restart:   unsetEnvs.ShouldRestart,
+		rolling:   unsetEnvs.RollingRestart,
+		batchSize: unsetEnvs.BatchSize,
+		w:         unsetEnvs.Writer,
+	})
+}
+
+// SetManagedEnvs makes envs the set of public environment variables of the
+// app managed by managedBy, pruning the other variables it manages.
+// Variables set directly on the app, or managed by someone else, take
+// precedence and are kept. The Envs, ManagedBy and PruneUnused fields of
+// args are ignored. It returns whether the environment of the app changed.
+func (app *App) SetManagedEnvs(managedBy string, envs map[string]string, args bind.SetEnvArgs) (bool, error) {
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var toSet []bind.EnvVar
+	changed := false
+	for _, name := range names {
+		current, ok := app.Env[name]
+		if ok && current.ManagedBy != managedBy {
+			continue
+		}
+		if !ok || current.Value != envs[name] {
+			changed = true
+		}
+		toSet = append(toSet, bind.EnvVar{
+			Name:      name,
+			Value:     envs[name],
+			Public:    true,
+			ManagedBy: managedBy,
+		})
 	}
-	return nil
+	for name, env := range app.Env {
+		if env.ManagedBy == managedBy && !envInSet(name, toSet) {
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	args.Envs = toSet
+	args.ManagedBy = managedBy
+	args.PruneUnused = true
+	return true, app.SetEnvs(args)
 }
 
 func (app *App) restartIfUnits(w io.Writer) error {
@@ -2024,6 +2369,7 @@ type Filter struct {
 	UserOwner   string
 	Pool        string
 	Pools       []string
+	Project     string
 	Statuses    []string
 	Locked      bool
 	Tags        []string
@@ -2096,6 +2442,9 @@ func (f *Filter) Query() bson.M {
 	if len(f.Pools) > 0 {
 		query["pool"] = bson.M{"$in": f.Pools}
 	}
+	if f.Project != "" {
+		query["project"] = f.Project
+	}
 	tags := processTags(f.Tags)
 	if len(tags) > 0 {
 		query["tags"] = bson.M{"$all": tags}
@@ -2326,6 +2675,9 @@ func Swap(ctx context.Context, app1, app2 *App, cnameOnly bool) error {
 // Start starts the app calling the provisioner.Start method and
 // changing the units state to StatusStarted.
 func (app *App) Start(ctx context.Context, w io.Writer, process, versionStr string) error {
+	if app.Archived != nil {
+		return ErrAppArchived
+	}
 	w = app.withLogWriter(w)
 	msg := fmt.Sprintf("\n ---> Starting the process %q", process)
 	if process == "" {
@@ -2393,6 +2745,14 @@ func (app *App) AddRouter(appRouter appTypes.AppRouter) error {
 			return ErrRouterAlreadyLinked
 		}
 	}
+	err := app.validateVisibility(app.GetVisibility(), []appTypes.AppRouter{appRouter})
+	if err != nil {
+		return err
+	}
+	err = app.validatePoolExpressions(nil, []string{appRouter.Name})
+	if err != nil {
+		return err
+	}
 	cnames := app.GetCname()
 	appCName := App{}
 	conn, err := db.Conn()
@@ -2537,6 +2897,148 @@ func (app *App) RemoveRouter(name string) error {
 	return nil
 }
 
+// SetRouteRules replaces the route rules of the app in the given router,
+// validating that every backend points to an existing app version.
+func (app *App) SetRouteRules(routerName string, rules []routerTypes.RouteRule) error {
+	var existing *appTypes.AppRouter
+	routers := app.GetRouters()
+	for i, r := range routers {
+		if r.Name == routerName {
+			existing = &routers[i]
+			break
+		}
+	}
+	if existing == nil {
+		return &router.ErrRouterNotFound{Name: routerName}
+	}
+	err := routerTypes.ValidateRouteRules(rules)
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: err.Error()}
+	}
+	err = app.validateRouteRulesVersions(rules)
+	if err != nil {
+		return err
+	}
+	r, err := router.Get(app.ctx, routerName)
+	if err != nil {
+		return err
+	}
+	rulesRouter, ok := router.AsRouteRulesRouter(r)
+	if !ok {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("route rules are not supported by router %q", routerName)}
+	}
+	err = rulesRouter.SetRouteRules(app.ctx, app, rules)
+	if err != nil {
+		return err
+	}
+	existing.Rules = rules
+	return app.updateRoutersDB(routers)
+}
+
+// GetRouterPolicy returns the router policy of the app, either set through
+// SetRouterPolicy or declared in the tsuru.yaml of the latest deployed
+// version.
+func (app *App) GetRouterPolicy() (*routerTypes.Policy, error) {
+	if app.RouterPolicy != nil {
+		return app.RouterPolicy, nil
+	}
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(app.ctx, app)
+	if err != nil {
+		if err == appTypes.ErrNoVersionsAvailable {
+			err = nil
+		}
+		return nil, err
+	}
+	yamlData, err := version.TsuruYamlData()
+	if err != nil {
+		return nil, err
+	}
+	if yamlData.RouterPolicy == nil || yamlData.RouterPolicy.IsEmpty() {
+		return nil, nil
+	}
+	err = yamlData.RouterPolicy.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid router policy in tsuru.yaml")
+	}
+	return yamlData.RouterPolicy, nil
+}
+
+// SetRouterPolicy sets the router policy of the app, overriding the policy
+// declared in tsuru.yaml, and applies it to the app routers. A nil policy
+// removes the policy previously set.
+func (app *App) SetRouterPolicy(policy *routerTypes.Policy) error {
+	if policy != nil && policy.IsEmpty() {
+		policy = nil
+	}
+	if policy != nil {
+		err := policy.Validate()
+		if err != nil {
+			return &tsuruErrors.ValidationError{Message: err.Error()}
+		}
+		supported, err := app.supportsRouterPolicy()
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return &tsuruErrors.ValidationError{Message: "router policies are not supported by the app routers"}
+		}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"$set": bson.M{"routerpolicy": policy}}
+	if policy == nil {
+		update = bson.M{"$unset": bson.M{"routerpolicy": ""}}
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.RouterPolicy = policy
+	rebuild.RoutesRebuildOrEnqueue(app.Name)
+	return nil
+}
+
+func (app *App) supportsRouterPolicy() (bool, error) {
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(app.ctx, appRouter.Name)
+		if err != nil {
+			return false, err
+		}
+		if _, ok := router.AsPolicyRouter(r); ok {
+			return true, nil
+		}
+		if _, ok := r.(router.RouterV2); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (app *App) validateRouteRulesVersions(rules []routerTypes.RouteRule) error {
+	var versions *appTypes.AppVersions
+	for _, rule := range rules {
+		for _, b := range rule.Backends {
+			if b.Version == 0 {
+				continue
+			}
+			if versions == nil {
+				appVersions, err := servicemanager.AppVersion.AppVersions(app.ctx, app)
+				if err != nil && err != appTypes.ErrNoVersionsAvailable {
+					return err
+				}
+				versions = &appVersions
+			}
+			if _, ok := versions.Versions[b.Version]; !ok {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("rule %q points to version %d which does not exist", rule.Name, b.Version)}
+			}
+		}
+	}
+	return nil
+}
+
 func (app *App) updateRoutersDB(routers []appTypes.AppRouter) error {
 	conn, err := db.Conn()
 	if err != nil {
@@ -2766,7 +3268,11 @@ func (app *App) RoutableAddresses(ctx context.Context) ([]appTypes.RoutableAddre
 	if err != nil {
 		return nil, err
 	}
-	return prov.RoutableAddresses(ctx, app)
+	routable, err := prov.RoutableAddresses(ctx, app)
+	if err != nil || app.Maintenance == nil {
+		return routable, err
+	}
+	return app.maintenanceAddresses(routable)
 }
 
 func (app *App) withLogWriter(w io.Writer) io.Writer {
@@ -2862,7 +3368,14 @@ func (app *App) SetRoutable(ctx context.Context, version appTypes.AppVersion, is
 	if !ok {
 		return errors.Errorf("provisioner %v does not support setting versions routable", prov.GetName())
 	}
-	return rprov.ToggleRoutable(ctx, app, version, isRoutable)
+	err = rprov.ToggleRoutable(ctx, app, version, isRoutable)
+	if err != nil {
+		return err
+	}
+	if isRoutable {
+		return app.stopShadowOf(version.Version())
+	}
+	return nil
 }
 
 func (app *App) DeployedVersions() ([]int, error) {
@@ -2944,6 +3457,14 @@ func (app *App) GetMetadata() appTypes.Metadata {
 	return app.Metadata
 }
 
+func (app *App) GetDisruptionSettings() appTypes.DisruptionSettings {
+	return app.Disruption
+}
+
+func (app *App) GetPlacementConstraints() []appTypes.PlacementConstraint {
+	return app.Placement
+}
+
 func (app *App) AutoScaleInfo() ([]provision.AutoScaleSpec, error) {
 	prov, err := app.getProvisioner()
 	if err != nil {
@@ -2956,6 +3477,19 @@ func (app *App) AutoScaleInfo() ([]provision.AutoScaleSpec, error) {
 	return autoscaleProv.GetAutoScale(app.ctx, app)
 }
 
+// AutoScaleStatus returns the autoscale specs of the app along with the
+// current state of each autoscaler, when reported by the provisioner.
+func (app *App) AutoScaleStatus() ([]provision.AutoScaleSpec, error) {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	if statusProv, ok := prov.(provision.AutoScaleStatusProvisioner); ok {
+		return statusProv.GetAutoScaleStatus(app.ctx, app)
+	}
+	return app.AutoScaleInfo()
+}
+
 func (app *App) VerticalAutoScaleRecommendations() ([]provision.RecommendedResources, error) {
 	prov, err := app.getProvisioner()
 	if err != nil {
@@ -2980,6 +3514,33 @@ func (app *App) UnitsMetrics() ([]provision.UnitMetric, error) {
 	return metricsProv.UnitsMetrics(app.ctx, app)
 }
 
+// UnitsUsage samples the resources used by the app units, returning nil when
+// the provisioner is unable to sample them.
+func (app *App) UnitsUsage() ([]provision.UnitUsage, error) {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	usageProv, ok := prov.(provision.UsageProvisioner)
+	if !ok {
+		return nil, nil
+	}
+	return usageProv.UnitsUsage(app.ctx, app)
+}
+
+// UnitStats returns the last samples of the resources used by the unit.
+func (app *App) UnitStats(unitID string) ([]provision.UnitStats, error) {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	statsProv, ok := prov.(provision.UnitStatsProvisioner)
+	if !ok {
+		return nil, ErrUnitStatsUnavailable
+	}
+	return statsProv.UnitStats(app.ctx, app, unitID)
+}
+
 func (app *App) AutoScale(spec provision.AutoScaleSpec) error {
 	prov, err := app.getProvisioner()
 	if err != nil {
@@ -3014,6 +3575,13 @@ func envInSet(envName string, envs []bind.EnvVar) bool {
 }
 
 func (app *App) GetRegistry() (imgTypes.ImageRegistry, error) {
+	reg, err := registry.GetPoolRegistry(app.Pool)
+	if err == nil {
+		return reg.ImageRegistry(), nil
+	}
+	if err != registry.ErrPoolRegistryNotFound {
+		return "", err
+	}
 	prov, err := app.getProvisioner()
 	if err != nil {
 		return "", err
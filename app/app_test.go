@@ -594,7 +594,7 @@ func (s *S) TestCreateAppWithExplicitPlanConstraint(c *check.C) {
 		TeamOwner: s.team.Name,
 	}
 	err = CreateApp(context.TODO(), &a, s.user)
-	c.Assert(err, check.ErrorMatches, `App plan "myplan" is not allowed on pool "pool1"`)
+	c.Assert(err, check.ErrorMatches, `App plan "myplan" is not allowed on pool "pool1". Allowed plans: .*`)
 }
 
 func (s *S) TestCreateAppUserQuotaExceeded(c *check.C) {
@@ -664,6 +664,18 @@ func (s *S) TestCreateAppTeamOwnerTeamNotFound(c *check.C) {
 	c.Assert(err.Error(), check.Equals, "team not found")
 }
 
+func (s *S) TestCreateAppPoolExpressionConstraint(c *check.C) {
+	err := pool.SetPoolConstraint(&pool.PoolConstraint{PoolExpr: "*", Field: pool.ConstraintTypeExpression, Values: []string{"app != legacy-*"}})
+	c.Assert(err, check.IsNil)
+	a := App{Name: "legacy-billing", Platform: "python", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.FitsTypeOf, &errors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `app doesn't satisfy the constraint "app != legacy-\*" of pool "pool1"`)
+	a = App{Name: "billing", Platform: "python", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+}
+
 func (s *S) TestCannotCreateAppWithoutTeamOwner(c *check.C) {
 	u := auth.User{Email: "perpetual@yes.com"}
 	err := u.Create()
@@ -2590,7 +2602,7 @@ func (s *S) TestIsValid(c *check.C) {
 		{"myapp", s.team.Name, "pool1", "faketls", "default-plan", "router \"faketls\" is not available for pool \"pool1\". Available routers are: \"fake, fake-hc, fake-tls, fake-v2\""},
 		{"myapp", "noaccessteam", "pool1", "fake", "default-plan", "App team owner \"noaccessteam\" has no access to pool \"pool1\""},
 		{"myApp", s.team.Name, "pool1", "fake", "default-plan", errMsg},
-		{"myapp", s.team.Name, "pool1", "fake", "plan1", "App plan \"plan1\" is not allowed on pool \"pool1\""},
+		{"myapp", s.team.Name, "pool1", "fake", "plan1", "App plan \"plan1\" is not allowed on pool \"pool1\". Allowed plans: default-plan, plan2"},
 		{"myapp", s.team.Name, "pool1", "fake", "plan2", ""},
 	}
 	for _, d := range data {
@@ -5347,7 +5359,7 @@ func (s *S) TestUpdatePlanWithConstraint(c *check.C) {
 	c.Assert(err, check.IsNil)
 	updateData := App{Name: "my-test-app", Plan: appTypes.Plan{Name: "something"}}
 	err = a.Update(UpdateAppArgs{UpdateData: updateData, Writer: new(bytes.Buffer)})
-	c.Assert(err, check.ErrorMatches, `App plan "something" is not allowed on pool "pool1"`)
+	c.Assert(err, check.ErrorMatches, `App plan "something" is not allowed on pool "pool1". Allowed plans: .*`)
 }
 
 func (s *S) TestUpdatePlanNoRouteChange(c *check.C) {
@@ -5756,6 +5768,82 @@ func (s *S) TestUpdateRouter(c *check.C) {
 	})
 }
 
+func (s *S) TestSetRouteRules(c *check.C) {
+	config.Set("routers:fake-rules:type", "fake-rules")
+	defer config.Unset("routers:fake-rules:type")
+	defer routertest.RulesRouter.Reset()
+	app := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.AddRouter(appTypes.AppRouter{Name: "fake-rules"})
+	c.Assert(err, check.IsNil)
+	rules := []routerTypes.RouteRule{{
+		Name:     "beta",
+		Match:    routerTypes.RouteMatch{Cookies: map[string]string{"beta": "true"}},
+		Backends: []routerTypes.WeightedBackend{{Version: 0, Weight: 100}},
+	}}
+	err = app.SetRouteRules("fake-rules", rules)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.RulesRouter.Rules["myapp"], check.DeepEquals, rules)
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.GetRouters()[1].Rules, check.DeepEquals, rules)
+	rules[0].Backends = []routerTypes.WeightedBackend{{Version: 0, Weight: 90}, {Version: 3, Weight: 10}}
+	err = app.SetRouteRules("fake-rules", rules)
+	c.Assert(err, check.ErrorMatches, `rule "beta" points to version 3 which does not exist`)
+	err = app.SetRouteRules("fake", nil)
+	c.Assert(err, check.ErrorMatches, `route rules are not supported by router "fake"`)
+}
+
+func (s *S) TestSetRouterPolicy(c *check.C) {
+	config.Set("routers:fake-policy:type", "fake-policy")
+	defer config.Unset("routers:fake-policy:type")
+	defer routertest.PolicyRouter.Reset()
+	app := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "fake-policy"}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	policy := &routerTypes.Policy{
+		RateLimit: &routerTypes.RateLimit{RequestsPerSecond: 100, Burst: 10},
+		DenyCIDRs: []string{"192.168.0.0/16"},
+	}
+	err = app.SetRouterPolicy(policy)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.PolicyRouter.Policies["myapp"], check.DeepEquals, policy)
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.RouterPolicy, check.DeepEquals, policy)
+	current, err := dbApp.GetRouterPolicy()
+	c.Assert(err, check.IsNil)
+	c.Assert(current, check.DeepEquals, policy)
+	err = app.SetRouterPolicy(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.PolicyRouter.Policies["myapp"], check.IsNil)
+	dbApp, err = GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.RouterPolicy, check.IsNil)
+}
+
+func (s *S) TestSetRouterPolicyInvalid(c *check.C) {
+	config.Set("routers:fake-policy:type", "fake-policy")
+	defer config.Unset("routers:fake-policy:type")
+	defer routertest.PolicyRouter.Reset()
+	app := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "fake-policy"}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetRouterPolicy(&routerTypes.Policy{AllowCIDRs: []string{"10.0.0.1"}})
+	c.Assert(err, check.ErrorMatches, `invalid CIDR "10.0.0.1"`)
+	err = app.SetRouterPolicy(&routerTypes.Policy{RateLimit: &routerTypes.RateLimit{}})
+	c.Assert(err, check.ErrorMatches, `rate limit requests per second must be greater than 0`)
+}
+
+func (s *S) TestSetRouterPolicyNotSupported(c *check.C) {
+	app := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetRouterPolicy(&routerTypes.Policy{MaxBodySize: 1024})
+	c.Assert(err, check.ErrorMatches, `router policies are not supported by the app routers`)
+}
+
 func (s *S) TestUpdateRouterV2(c *check.C) {
 	config.Set("routers:fake-v2:type", "fake-opts")
 	defer config.Unset("routers:fake-v2:type")
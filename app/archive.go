@@ -0,0 +1,159 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/router/rebuild"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+var (
+	ErrAppArchived    = errors.New("app is archived, unarchive it first")
+	ErrAppNotArchived = errors.New("app is not archived")
+)
+
+// ArchivedProcess is a group of units removed when the app was archived.
+type ArchivedProcess struct {
+	Process string `json:"process"`
+	Version int    `json:"version"`
+	Image   string `json:"image,omitempty"`
+	Units   int    `json:"units"`
+}
+
+func (p ArchivedProcess) versionString() string {
+	if p.Version == 0 {
+		return ""
+	}
+	return strconv.Itoa(p.Version)
+}
+
+// ArchiveState is what an archived app needs to be reactivated. The rest of
+// the app configuration, like env and metadata, is kept in the app itself.
+type ArchiveState struct {
+	ArchivedAt time.Time         `json:"archivedAt"`
+	Owner      string            `json:"owner"`
+	Processes  []ArchivedProcess `json:"processes"`
+}
+
+// Archive removes every unit of the app and releases its router backends,
+// recording the units and images in use so that Unarchive can bring the app
+// back. Archived apps have no units, so they don't count against unit quotas.
+func (app *App) Archive(ctx context.Context, owner string, w io.Writer) error {
+	if app.Archived != nil {
+		return ErrAppArchived
+	}
+	w = app.withLogWriter(w)
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return err
+	}
+	units, err := app.Units()
+	if err != nil {
+		return err
+	}
+	if len(units) > 0 {
+		err = app.CheckUnitsProtection(len(units))
+		if err != nil {
+			return err
+		}
+	}
+	state := ArchiveState{ArchivedAt: time.Now().UTC(), Owner: owner, Processes: []ArchivedProcess{}}
+	counts := map[ArchivedProcess]int{}
+	for _, u := range units {
+		counts[ArchivedProcess{Process: u.ProcessName, Version: u.Version}]++
+	}
+	for p, n := range counts {
+		p.Units = n
+		if p.Version > 0 {
+			var version appTypes.AppVersion
+			version, err = servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, strconv.Itoa(p.Version))
+			if err != nil {
+				return err
+			}
+			p.Image = version.VersionInfo().DeployImage
+		}
+		state.Processes = append(state.Processes, p)
+	}
+	sort.Slice(state.Processes, func(i, j int) bool {
+		if state.Processes[i].Process == state.Processes[j].Process {
+			return state.Processes[i].Version < state.Processes[j].Version
+		}
+		return state.Processes[i].Process < state.Processes[j].Process
+	})
+	err = app.setArchived(&state)
+	if err != nil {
+		return err
+	}
+	for _, p := range state.Processes {
+		fmt.Fprintf(w, "---- Removing %d units of process %q ----\n", p.Units, p.Process)
+		version, err := app.getVersionAllowNil(p.versionString())
+		if err != nil {
+			return err
+		}
+		err = prov.RemoveUnits(ctx, app, uint(p.Units), p.Process, version, w)
+		if err != nil {
+			return newErrorWithLog(err, app, "archive")
+		}
+	}
+	fmt.Fprintf(w, "---- Releasing router backends ----\n")
+	return removeAllRoutersBackend(ctx, app)
+}
+
+// Unarchive reactivates an archived app, adding back its router backends
+// and the units it had when archived.
+func (app *App) Unarchive(ctx context.Context, w io.Writer) error {
+	if app.Archived == nil {
+		return ErrAppNotArchived
+	}
+	w = app.withLogWriter(w)
+	state := app.Archived
+	fmt.Fprintf(w, "---- Adding router backends ----\n")
+	err := addAllRoutersBackend(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = app.setArchived(nil)
+	if err != nil {
+		return err
+	}
+	for _, p := range state.Processes {
+		fmt.Fprintf(w, "---- Adding %d units to process %q ----\n", p.Units, p.Process)
+		err = app.AddUnits(uint(p.Units), p.Process, p.versionString(), w)
+		if err != nil {
+			return err
+		}
+	}
+	rebuild.RoutesRebuildOrEnqueueWithProgress(app.Name, w)
+	return nil
+}
+
+func (app *App) setArchived(state *ArchiveState) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"$set": bson.M{"archived": state}}
+	if state == nil {
+		update = bson.M{"$unset": bson.M{"archived": ""}}
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.Archived = state
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestArchiveAndUnarchive(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	err = a.AddUnits(3, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(1, "worker", "", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.HasBackend("myapp"), check.Equals, true)
+	err = a.Archive(context.TODO(), "majortom", nil)
+	c.Assert(err, check.IsNil)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 0)
+	c.Assert(routertest.FakeRouter.HasBackend("myapp"), check.Equals, false)
+	dbApp, err := GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Archived, check.NotNil)
+	c.Assert(dbApp.Archived.Owner, check.Equals, "majortom")
+	c.Assert(dbApp.Archived.Processes, check.DeepEquals, []ArchivedProcess{
+		{Process: "web", Version: version.Version(), Image: version.VersionInfo().DeployImage, Units: 3},
+		{Process: "worker", Version: version.Version(), Image: version.VersionInfo().DeployImage, Units: 1},
+	})
+	inUse, err := dbApp.GetQuotaInUse()
+	c.Assert(err, check.IsNil)
+	c.Assert(inUse, check.Equals, 0)
+	c.Assert(dbApp.AddUnits(1, "web", "", nil), check.Equals, ErrAppArchived)
+	c.Assert(dbApp.Archive(context.TODO(), "majortom", nil), check.Equals, ErrAppArchived)
+	err = dbApp.Unarchive(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.HasBackend("myapp"), check.Equals, true)
+	units, err = dbApp.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 4)
+	dbApp, err = GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Archived, check.IsNil)
+	c.Assert(dbApp.Unarchive(context.TODO(), nil), check.Equals, ErrAppNotArchived)
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+const (
+	defaultAutoRollbackWindow = time.Minute
+	maxAutoRollbackWindow     = 30 * time.Minute
+)
+
+// autoRollbackCheckInterval is how often units are checked during the
+// stabilization window.
+var autoRollbackCheckInterval = 5 * time.Second
+
+// AutoRollback is the policy of an app for rolling back deploys whose units
+// fail healthchecks or crash within the stabilization window.
+type AutoRollback struct {
+	Enabled bool
+	Window  time.Duration
+}
+
+func (p AutoRollback) window() time.Duration {
+	if p.Window <= 0 {
+		return defaultAutoRollbackWindow
+	}
+	return p.Window
+}
+
+// SetAutoRollback stores the automatic rollback policy of the app.
+func (app *App) SetAutoRollback(policy AutoRollback) error {
+	if policy.Window < 0 || policy.Window > maxAutoRollbackWindow {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("stabilization window must be between 0 and %s", maxAutoRollbackWindow)}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, bson.M{"$set": bson.M{"autorollback": policy}})
+	if err != nil {
+		return err
+	}
+	app.AutoRollback = policy
+	return nil
+}
+
+// autoRollbackTarget returns the version the app must be rolled back to if
+// the deploy fails, or nil when the policy is disabled or there's nothing to
+// roll back to.
+func autoRollbackTarget(ctx context.Context, opts *DeployOptions) appTypes.AppVersion {
+	if !opts.App.AutoRollback.Enabled || opts.Kind == DeployRollback {
+		return nil
+	}
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, opts.App)
+	if err != nil {
+		return nil
+	}
+	return version
+}
+
+// ErrDeployUnstable is returned when units of the deployed version fail or
+// crash during the stabilization window, being the only deploy failure that
+// triggers automatic rollbacks. Failures before the units are replaced, like
+// build errors and cancellations, leave the previous version running.
+type ErrDeployUnstable struct {
+	Unit         string
+	Crashed      bool
+	StatusReason string
+}
+
+func (e *ErrDeployUnstable) Error() string {
+	if e.Crashed {
+		return fmt.Sprintf("unit %s crashed during the stabilization window", e.Unit)
+	}
+	return fmt.Sprintf("unit %s failed during the stabilization window: %s", e.Unit, e.StatusReason)
+}
+
+// waitStabilization watches the units of the app during the stabilization
+// window, returning an ErrDeployUnstable as soon as any of them fails or
+// crashes. When
+// the deploy kept units from other versions, only the units of the deployed
+// version are watched.
+func waitStabilization(ctx context.Context, opts *DeployOptions, imageID string, evt *event.Event) error {
+	var versionNum int
+	if opts.NewVersion {
+		version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, opts.App, imageID)
+		if err != nil {
+			return err
+		}
+		versionNum = version.Version()
+	}
+	window := opts.App.AutoRollback.window()
+	fmt.Fprintf(evt, "\n---- Watching units for %s before considering the deploy stable ----\n", window)
+	restarts := map[string]int32{}
+	deadline := time.After(window)
+	ticker := time.NewTicker(autoRollbackCheckInterval)
+	defer ticker.Stop()
+	for {
+		units, err := opts.App.Units()
+		if err != nil {
+			return err
+		}
+		for _, u := range units {
+			if versionNum != 0 && u.Version != versionNum {
+				continue
+			}
+			if u.Status == provision.StatusError {
+				return &ErrDeployUnstable{Unit: u.ID, StatusReason: u.StatusReason}
+			}
+			if u.Restarts == nil {
+				continue
+			}
+			if initial, ok := restarts[u.ID]; !ok {
+				restarts[u.ID] = *u.Restarts
+			} else if *u.Restarts > initial {
+				return &ErrDeployUnstable{Unit: u.ID, Crashed: true}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Fprintf(evt, "---- Stopped watching units: %s ----\n", ctx.Err())
+			return nil
+		case <-deadline:
+			fmt.Fprintf(evt, "---- Deploy is stable ----\n")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// autoRollback deploys the previous version of the app after the deployed
+// version failed to stabilize, disabling it for future rollbacks. The returned error
+// always describes the deploy failure, and the rollback failure if any.
+func autoRollback(ctx context.Context, opts *DeployOptions, previous appTypes.AppVersion, imageID string, deployErr error, evt *event.Event) error {
+	fmt.Fprintf(evt, "\n---- Deploy failed, rolling back to version %d ----\n", previous.Version())
+	prov, err := opts.App.getProvisioner()
+	if err != nil {
+		return errors.Wrapf(deployErr, "unable to roll back: %v", err)
+	}
+	deployer, ok := prov.(provision.BuilderDeploy)
+	if !ok {
+		return errors.Wrapf(deployErr, "unable to roll back: provisioner %q does not support deploys", prov.GetName())
+	}
+	_, err = deployer.Deploy(ctx, provision.DeployArgs{
+		App:     opts.App,
+		Version: previous,
+		Event:   evt,
+	})
+	if err != nil {
+		return errors.Wrapf(deployErr, "unable to roll back to version %d: %v", previous.Version(), err)
+	}
+	reason := fmt.Sprintf("automatically rolled back: %v", errors.Cause(deployErr))
+	if errToggle := RollbackUpdate(ctx, opts.App, imageID, reason, true); errToggle != nil {
+		fmt.Fprintf(evt, "Unable to disable failed version for rollback: %v\n", errToggle)
+	}
+	return errors.Wrapf(deployErr, "app rolled back to version %d", previous.Version())
+}
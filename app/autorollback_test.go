@@ -0,0 +1,134 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tsuru/tsuru/builder"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) deployImageWithEvent(c *check.C, a *App, image string) (string, string, error) {
+	writer := &bytes.Buffer{}
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	imageID, err := Deploy(context.TODO(), DeployOptions{
+		App:          a,
+		Image:        image,
+		OutputStream: writer,
+		Event:        evt,
+	})
+	evt.Done(err)
+	return imageID, writer.String(), err
+}
+
+func (s *S) createAutoRollbackApp(c *check.C, window time.Duration) *App {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, Router: "fake"}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	_, _, err = s.deployImageWithEvent(c, &a, "myimage:v1")
+	c.Assert(err, check.IsNil)
+	err = a.AddUnits(1, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = a.SetAutoRollback(AutoRollback{Enabled: true, Window: window})
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestSetAutoRollback(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetAutoRollback(AutoRollback{Enabled: true, Window: time.Hour})
+	c.Assert(err, check.ErrorMatches, "stabilization window must be between 0 and 30m0s")
+	err = a.SetAutoRollback(AutoRollback{Enabled: true, Window: 2 * time.Minute})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.AutoRollback, check.DeepEquals, AutoRollback{Enabled: true, Window: 2 * time.Minute})
+}
+
+func (s *S) TestDeployAutoRollbackNotOnDeployFailure(c *check.C) {
+	a := s.createAutoRollbackApp(c, time.Millisecond)
+	s.provisioner.PrepareFailure("Deploy", errors.New("healthcheck failed"))
+	_, output, err := s.deployImageWithEvent(c, a, "myimage:v2")
+	c.Assert(err, check.ErrorMatches, `(?s).*healthcheck failed.*`)
+	c.Assert(err, check.Not(check.ErrorMatches), `(?s).*rolled back.*`)
+	c.Assert(output, check.Not(check.Matches), `(?s).*rolling back.*`)
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(version.Version(), check.Equals, 1)
+}
+
+func (s *S) TestDeployAutoRollbackNotOnBuildFailure(c *check.C) {
+	a := s.createAutoRollbackApp(c, time.Millisecond)
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
+		return nil, errors.New("build failed")
+	}
+	_, output, err := s.deployImageWithEvent(c, a, "myimage:v2")
+	c.Assert(err, check.ErrorMatches, `(?s).*build failed.*`)
+	c.Assert(output, check.Not(check.Matches), `(?s).*rolling back.*`)
+	c.Assert(s.provisioner.Restarts(a, ""), check.Equals, 0)
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(version.Version(), check.Equals, 1)
+}
+
+func (s *S) TestDeployAutoRollbackNotOnCancel(c *check.C) {
+	a := s.createAutoRollbackApp(c, time.Millisecond)
+	s.builder.OnBuild = func(p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *builder.BuildOpts) (appTypes.AppVersion, error) {
+		return nil, context.Canceled
+	}
+	_, output, err := s.deployImageWithEvent(c, a, "myimage:v2")
+	c.Assert(err, check.ErrorMatches, `(?s).*context canceled.*`)
+	c.Assert(output, check.Not(check.Matches), `(?s).*rolling back.*`)
+}
+
+func (s *S) TestDeployAutoRollbackOnUnitFailure(c *check.C) {
+	defer func(d time.Duration) { autoRollbackCheckInterval = d }(autoRollbackCheckInterval)
+	autoRollbackCheckInterval = 10 * time.Millisecond
+	a := s.createAutoRollbackApp(c, time.Second)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	c.Assert(units, check.HasLen, 1)
+	err = s.provisioner.SetUnitStatus(units[0], provision.StatusError)
+	c.Assert(err, check.IsNil)
+	_, output, err := s.deployImageWithEvent(c, a, "myimage:v2")
+	c.Assert(err, check.ErrorMatches, `(?s)app rolled back to version 1: .*failed during the stabilization window.*`)
+	c.Assert(output, check.Matches, `(?s).*Watching units for 1s.*Deploy failed, rolling back to version 1.*`)
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(version.Version(), check.Equals, 1)
+	failed, err := servicemanager.AppVersion.VersionByImageOrVersion(context.TODO(), a, "2")
+	c.Assert(err, check.IsNil)
+	c.Assert(failed.VersionInfo().Disabled, check.Equals, true)
+	c.Assert(failed.VersionInfo().DisabledReason, check.Matches, "automatically rolled back: .*")
+}
+
+func (s *S) TestDeployAutoRollbackStable(c *check.C) {
+	defer func(d time.Duration) { autoRollbackCheckInterval = d }(autoRollbackCheckInterval)
+	autoRollbackCheckInterval = 10 * time.Millisecond
+	a := s.createAutoRollbackApp(c, 50*time.Millisecond)
+	_, output, err := s.deployImageWithEvent(c, a, "myimage:v2")
+	c.Assert(err, check.IsNil)
+	c.Assert(output, check.Matches, `(?s).*Deploy is stable.*`)
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(version.Version(), check.Equals, 2)
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/unithistory"
+)
+
+// AvailabilityReport summarizes the availability of the app processes and
+// its deploys in a period.
+type AvailabilityReport struct {
+	App           string                            `json:"app"`
+	Since         time.Time                         `json:"since"`
+	Until         time.Time                         `json:"until"`
+	Processes     []unithistory.ProcessAvailability `json:"processes"`
+	Deploys       int                               `json:"deploys"`
+	FailedDeploys int                               `json:"failedDeploys"`
+	DeploysPerDay float64                           `json:"deploysPerDay"`
+}
+
+// Availability builds the availability report of the app between since and
+// until, based on the status history of its units and its deploy events.
+func (app *App) Availability(since, until time.Time) (*AvailabilityReport, error) {
+	processes, err := unithistory.Availability(app.Name, since, until)
+	if err != nil {
+		return nil, err
+	}
+	report := &AvailabilityReport{
+		App:       app.Name,
+		Since:     since,
+		Until:     until,
+		Processes: processes,
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var evts []struct {
+		Error string `bson:"error"`
+	}
+	query := bson.M{
+		"target.type":  event.TargetTypeApp,
+		"target.value": app.Name,
+		"kind.name":    permission.PermAppDeploy.FullName(),
+		"running":      false,
+		"starttime":    bson.M{"$gte": since, "$lte": until},
+	}
+	err = conn.Events().Find(query).Select(bson.M{"error": 1}).All(&evts)
+	if err != nil {
+		return nil, err
+	}
+	for _, evt := range evts {
+		report.Deploys++
+		if evt.Error != "" {
+			report.FailedDeploys++
+		}
+	}
+	if days := until.Sub(since).Hours() / 24; days > 0 {
+		report.DeploysPerDay = float64(report.Deploys) / days
+	}
+	return report, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/provision/unithistory"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAppAvailability(c *check.C) {
+	now := time.Now().UTC()
+	since := now.Add(-4 * time.Hour)
+	transitions := []unithistory.Transition{
+		{App: "myapp", Unit: "u1", Process: "web", From: "started", Status: "error", Time: now.Add(-2 * time.Hour)},
+		{App: "myapp", Unit: "u1", Process: "web", From: "error", Status: "started", Time: now.Add(-time.Hour)},
+		{App: "otherapp", Unit: "u2", Process: "web", From: "started", Status: "error", Time: now.Add(-time.Hour)},
+	}
+	for _, t := range transitions {
+		err := unithistory.Record(t)
+		c.Assert(err, check.IsNil)
+	}
+	insertDeployWithStages(c, "myapp", DeployEndData{}, nil)
+	insertDeployWithStages(c, "myapp", DeployEndData{}, errors.New("deploy failed"))
+	insertDeployWithStages(c, "otherapp", DeployEndData{}, nil)
+	a := App{Name: "myapp"}
+	report, err := a.Availability(since, now.Add(time.Minute))
+	c.Assert(err, check.IsNil)
+	c.Assert(report.App, check.Equals, "myapp")
+	c.Assert(report.Deploys, check.Equals, 2)
+	c.Assert(report.FailedDeploys, check.Equals, 1)
+	c.Assert(report.Processes, check.HasLen, 1)
+	c.Assert(report.Processes[0].Process, check.Equals, "web")
+	c.Assert(report.Processes[0].Incidents, check.Equals, 1)
+	c.Assert(report.Processes[0].MTTR, check.Equals, time.Hour)
+	c.Assert(report.Processes[0].Downtime, check.Equals, time.Hour)
+}
@@ -60,12 +60,18 @@ type SetEnvArgs struct {
 	ManagedBy     string
 	PruneUnused   bool
 	ShouldRestart bool
+	// RollingRestart records the change as a new configuration version of
+	// the app and replaces its units in batches of BatchSize units.
+	RollingRestart bool
+	BatchSize      int
 }
 
 type UnsetEnvArgs struct {
-	VariableNames []string
-	Writer        io.Writer
-	ShouldRestart bool
+	VariableNames  []string
+	Writer         io.Writer
+	ShouldRestart  bool
+	RollingRestart bool
+	BatchSize      int
 }
 
 type AddInstanceArgs struct {
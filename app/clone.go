@@ -0,0 +1,119 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/service"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+)
+
+// CloneOptions holds the parameters used to clone an app. Empty TeamOwner,
+// Description and Tags are copied from the source app. Private environment
+// variables are only copied when CopySecrets is true, and the clone is only
+// bound to the service instances of the source app when Bind is true.
+type CloneOptions struct {
+	Name        string
+	TeamOwner   string
+	Description string
+	Tags        []string
+	CopySecrets bool
+	Bind        bool
+	User        *auth.User
+	Writer      io.Writer
+	Event       *event.Event
+	RequestID   string
+}
+
+// Clone creates a new app with the platform, plan, pool, routers, teams and
+// environment variables of the source app. When the new app is created but
+// a later step fails, both the app and the error are returned, so callers
+// may clean it up.
+func Clone(ctx context.Context, src *App, opts CloneOptions) (*App, error) {
+	w := opts.Writer
+	if w == nil {
+		w = ioutil.Discard
+	}
+	platform := src.Platform
+	if src.PlatformVersion != "" && src.PlatformVersion != "latest" {
+		platform += ":" + src.PlatformVersion
+	}
+	clone := App{
+		Name:        opts.Name,
+		Platform:    platform,
+		Plan:        appTypes.Plan{Name: src.Plan.Name},
+		Pool:        src.Pool,
+		TeamOwner:   opts.TeamOwner,
+		Description: opts.Description,
+		Tags:        opts.Tags,
+		Routers:     append([]appTypes.AppRouter{}, src.Routers...),
+		Metadata:    src.Metadata,
+	}
+	if clone.TeamOwner == "" {
+		clone.TeamOwner = src.TeamOwner
+	}
+	if clone.Description == "" {
+		clone.Description = src.Description
+	}
+	if clone.Tags == nil {
+		clone.Tags = append([]string{}, src.Tags...)
+	}
+	fmt.Fprintf(w, "---- Cloning app %q into %q ----\n", src.Name, clone.Name)
+	err := CreateApp(ctx, &clone, opts.User)
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range src.Teams {
+		if team == clone.TeamOwner {
+			continue
+		}
+		err = clone.Grant(&authTypes.Team{Name: team})
+		if err != nil && err != ErrAlreadyHaveAccess {
+			return &clone, errors.Wrapf(err, "unable to grant access to team %q", team)
+		}
+	}
+	var envs []bind.EnvVar
+	for name, env := range src.Env {
+		if env.ManagedBy != "" || strings.HasPrefix(name, "TSURU_") {
+			continue
+		}
+		if !env.Public && !opts.CopySecrets {
+			continue
+		}
+		envs = append(envs, bind.EnvVar{Name: name, Value: env.Value, Alias: env.Alias, Public: env.Public})
+	}
+	if len(envs) > 0 {
+		fmt.Fprintf(w, "---- Setting %d environment variables ----\n", len(envs))
+		err = clone.SetEnvs(bind.SetEnvArgs{Envs: envs, Writer: w})
+		if err != nil {
+			return &clone, err
+		}
+	}
+	if !opts.Bind {
+		return &clone, nil
+	}
+	instances, err := service.GetServiceInstancesBoundToApp(src.Name)
+	if err != nil {
+		return &clone, err
+	}
+	for i := range instances {
+		fmt.Fprintf(w, "---- Binding service instance %s/%s ----\n", instances[i].ServiceName, instances[i].Name)
+		err = instances[i].BindApp(&clone, nil, false, w, opts.Event, opts.RequestID)
+		if err != nil {
+			return &clone, err
+		}
+	}
+	return &clone, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createCloneSource(c *check.C) *App {
+	a := App{Name: "source", Platform: "django", TeamOwner: s.team.Name, Tags: []string{"web"}}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{
+		{Name: "LOG_LEVEL", Value: "debug", Public: true},
+		{Name: "DATABASE_PASSWORD", Value: "s3cr3t"},
+	}})
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestClone(c *check.C) {
+	src := s.createCloneSource(c)
+	clone, err := Clone(context.TODO(), src, CloneOptions{Name: "staging", User: s.user})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), "staging")
+	c.Assert(err, check.IsNil)
+	c.Assert(clone.Name, check.Equals, dbApp.Name)
+	c.Assert(dbApp.Platform, check.Equals, src.Platform)
+	c.Assert(dbApp.Plan.Name, check.Equals, src.Plan.Name)
+	c.Assert(dbApp.Pool, check.Equals, src.Pool)
+	c.Assert(dbApp.TeamOwner, check.Equals, s.team.Name)
+	c.Assert(dbApp.Tags, check.DeepEquals, []string{"web"})
+	c.Assert(dbApp.Env["LOG_LEVEL"].Value, check.Equals, "debug")
+	_, ok := dbApp.Env["DATABASE_PASSWORD"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestCloneCopySecrets(c *check.C) {
+	src := s.createCloneSource(c)
+	_, err := Clone(context.TODO(), src, CloneOptions{Name: "staging", CopySecrets: true, User: s.user})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), "staging")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["DATABASE_PASSWORD"].Value, check.Equals, "s3cr3t")
+	c.Assert(dbApp.Env["DATABASE_PASSWORD"].Public, check.Equals, false)
+}
+
+func (s *S) TestCloneAppAlreadyExists(c *check.C) {
+	src := s.createCloneSource(c)
+	clone, err := Clone(context.TODO(), src, CloneOptions{Name: "source", User: s.user})
+	c.Assert(err, check.NotNil)
+	c.Assert(clone, check.IsNil)
+}
@@ -0,0 +1,276 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package commitstatus reports the progress of app deploys back to the source
+// code hosting provider, as commit status checks.
+package commitstatus
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	internalConfig "github.com/tsuru/tsuru/config"
+	"github.com/tsuru/tsuru/log"
+)
+
+type State string
+
+const (
+	StatePending State = "pending"
+	StateSuccess State = "success"
+	StateFailure State = "failure"
+)
+
+const (
+	defaultContext = "tsuru/deploy"
+	notifyTimeout  = 30 * time.Second
+)
+
+var ErrProviderNotFound = errors.New("commit status provider not found")
+
+// Status is a single status check reported for a commit.
+type Status struct {
+	Repository  string
+	Commit      string
+	State       State
+	Description string
+	TargetURL   string
+	Context     string
+}
+
+// Provider is implemented by drivers able to publish commit status checks in
+// a source code hosting provider.
+type Provider interface {
+	SetStatus(ctx context.Context, status Status) error
+}
+
+// ProviderConfig holds the configuration of a provider, as declared in
+// commit-status:providers:<name>.
+type ProviderConfig struct {
+	Name  string `json:"-"`
+	Type  string `json:"type"`
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// RepositoryMapping associates an app with the repository whose commits will
+// receive status checks for its deploys.
+type RepositoryMapping struct {
+	App        string `json:"app" form:"app"`
+	Provider   string `json:"provider,omitempty" form:"provider"`
+	Repository string `json:"repository" form:"repository"`
+}
+
+// Deploy describes the deploy being reported.
+type Deploy struct {
+	App     string
+	Teams   []string
+	Commit  string
+	Origin  string
+	EventID string
+}
+
+type providerFactory func(ProviderConfig) (Provider, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]providerFactory{}
+)
+
+// Register registers a new provider driver.
+func Register(driver string, factory providerFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[driver] = factory
+}
+
+func getFactory(driver string) (providerFactory, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	factory, ok := providers[driver]
+	return factory, ok
+}
+
+// Match returns whether the mapping applies to the app name. The app field
+// in the mapping accepts shell patterns, e.g. "myapp-*".
+func (m RepositoryMapping) Match(appName string) bool {
+	if m.App == appName {
+		return true
+	}
+	matched, _ := path.Match(m.App, appName)
+	return matched
+}
+
+func repositoryMappings() ([]RepositoryMapping, error) {
+	var mappings []RepositoryMapping
+	err := internalConfig.UnmarshalConfig("commit-status:repositories", &mappings)
+	if err != nil {
+		if _, ok := errors.Cause(err).(config.ErrKeyNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return mappings, nil
+}
+
+func findMapping(appName string) (*RepositoryMapping, error) {
+	mappings, err := repositoryMappings()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mappings {
+		if m.Match(appName) {
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+func getProvider(name string) (Provider, error) {
+	var providerConfig ProviderConfig
+	err := internalConfig.UnmarshalConfig("commit-status:providers:"+name, &providerConfig)
+	if err != nil {
+		if _, ok := errors.Cause(err).(config.ErrKeyNotFound); ok {
+			return nil, ErrProviderNotFound
+		}
+		return nil, err
+	}
+	providerConfig.Name = name
+	if providerConfig.Type == "" {
+		providerConfig.Type = name
+	}
+	factory, ok := getFactory(providerConfig.Type)
+	if !ok {
+		return nil, errors.Errorf("unknown commit status provider type %q", providerConfig.Type)
+	}
+	return factory(providerConfig)
+}
+
+// EventURL returns the URL where the event with the given ID can be
+// inspected, based on the commit-status:event-url template or on the tsuru
+// API host.
+func EventURL(eventID string) string {
+	if tpl, _ := config.GetString("commit-status:event-url"); tpl != "" {
+		return strings.Replace(tpl, "{event}", eventID, -1)
+	}
+	host, _ := config.GetString("host")
+	if host == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/events/%s", strings.TrimRight(host, "/"), eventID)
+}
+
+func description(state State) string {
+	switch state {
+	case StatePending:
+		return "Deploy in progress"
+	case StateSuccess:
+		return "Deploy succeeded"
+	default:
+		return "Deploy failed"
+	}
+}
+
+// Notify reports the state of a deploy for its commit. For deploys coming
+// from git, the repository and credentials come from the first integration of
+// the app teams with a repository mapped to the app. Other deploys carrying a
+// commit, and git deploys without a matching integration, use
+// commit-status:repositories. It does nothing if no repository is mapped to
+// the app.
+func Notify(ctx context.Context, d Deploy, state State) error {
+	if d.Commit == "" {
+		return nil
+	}
+	var (
+		integration *Integration
+		mapping     *RepositoryMapping
+		err         error
+	)
+	if d.Origin == "git" {
+		integration, mapping, err = findIntegration(d.App, d.Teams)
+		if err != nil {
+			return err
+		}
+	}
+	var provider Provider
+	if integration != nil {
+		provider, err = integration.provider()
+	} else {
+		mapping, err = findMapping(d.App)
+		if err != nil || mapping == nil {
+			return err
+		}
+		provider, err = getProvider(mapping.Provider)
+	}
+	if err != nil {
+		return err
+	}
+	statusContext, _ := config.GetString("commit-status:context")
+	if statusContext == "" {
+		statusContext = defaultContext
+	}
+	return provider.SetStatus(ctx, Status{
+		Repository:  mapping.Repository,
+		Commit:      d.Commit,
+		State:       state,
+		Description: description(state),
+		TargetURL:   EventURL(d.EventID),
+		Context:     statusContext,
+	})
+}
+
+var (
+	queuesMu sync.Mutex
+	queues   = map[string][]func(){}
+)
+
+// enqueue runs fn in background after the functions previously enqueued
+// with the same key.
+func enqueue(key string, fn func()) {
+	queuesMu.Lock()
+	defer queuesMu.Unlock()
+	pending, running := queues[key]
+	queues[key] = append(pending, fn)
+	if !running {
+		go runQueue(key)
+	}
+}
+
+func runQueue(key string) {
+	for {
+		queuesMu.Lock()
+		pending := queues[key]
+		if len(pending) == 0 {
+			delete(queues, key)
+			queuesMu.Unlock()
+			return
+		}
+		queues[key] = pending[1:]
+		queuesMu.Unlock()
+		pending[0]()
+	}
+}
+
+// NotifyAsync calls Notify in background, logging any error. Notifications
+// for the same app and commit are sent in the order NotifyAsync is called, so
+// that a slow pending status never overrides the final one.
+func NotifyAsync(d Deploy, state State) {
+	if d.Commit == "" {
+		return
+	}
+	enqueue(d.App+"/"+d.Commit, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		err := Notify(ctx, d, state)
+		if err != nil {
+			log.Errorf("[commit-status] unable to set %s status for app %q commit %q: %v", state, d.App, d.Commit, err)
+		}
+	})
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commitstatus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpTest(c *check.C) {
+	config.Unset("commit-status")
+	config.Set("host", "http://tsuru.example.com")
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("commit-status")
+	config.Unset("host")
+}
+
+type receivedRequest struct {
+	path   string
+	header http.Header
+	body   map[string]string
+}
+
+func fakeServer(c *check.C, reqs *[]receivedRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		err := json.NewDecoder(r.Body).Decode(&body)
+		c.Assert(err, check.IsNil)
+		*reqs = append(*reqs, receivedRequest{path: r.URL.EscapedPath(), header: r.Header, body: body})
+		w.WriteHeader(http.StatusCreated)
+	}))
+}
+
+func (s *S) TestNotifyGitHub(c *check.C) {
+	var reqs []receivedRequest
+	srv := fakeServer(c, &reqs)
+	defer srv.Close()
+	config.Set("commit-status:providers:gh:type", "github")
+	config.Set("commit-status:providers:gh:url", srv.URL)
+	config.Set("commit-status:providers:gh:token", "abc")
+	config.Set("commit-status:repositories", []interface{}{
+		map[interface{}]interface{}{"app": "myapp", "provider": "gh", "repository": "org/repo"},
+	})
+	err := Notify(context.Background(), Deploy{App: "myapp", Commit: "a1b2c3", Origin: "git", EventID: "evt1"}, StatePending)
+	c.Assert(err, check.IsNil)
+	c.Assert(reqs, check.HasLen, 1)
+	c.Assert(reqs[0].path, check.Equals, "/repos/org/repo/statuses/a1b2c3")
+	c.Assert(reqs[0].header.Get("Authorization"), check.Equals, "token abc")
+	c.Assert(reqs[0].body, check.DeepEquals, map[string]string{
+		"state":       "pending",
+		"target_url":  "http://tsuru.example.com/events/evt1",
+		"description": "Deploy in progress",
+		"context":     "tsuru/deploy",
+	})
+}
+
+func (s *S) TestNotifyGitLab(c *check.C) {
+	var reqs []receivedRequest
+	srv := fakeServer(c, &reqs)
+	defer srv.Close()
+	config.Set("commit-status:context", "ci/tsuru")
+	config.Set("commit-status:event-url", "https://dashboard.example.com/events/{event}")
+	config.Set("commit-status:providers:gitlab:url", srv.URL)
+	config.Set("commit-status:providers:gitlab:token", "xyz")
+	config.Set("commit-status:repositories", []interface{}{
+		map[interface{}]interface{}{"app": "myapp-*", "provider": "gitlab", "repository": "group/project"},
+	})
+	err := Notify(context.Background(), Deploy{App: "myapp-dev", Commit: "a1b2c3", Origin: "git", EventID: "evt1"}, StateFailure)
+	c.Assert(err, check.IsNil)
+	c.Assert(reqs, check.HasLen, 1)
+	c.Assert(reqs[0].path, check.Equals, "/api/v4/projects/group%2Fproject/statuses/a1b2c3")
+	c.Assert(reqs[0].header.Get("Private-Token"), check.Equals, "xyz")
+	c.Assert(reqs[0].body, check.DeepEquals, map[string]string{
+		"state":       "failed",
+		"target_url":  "https://dashboard.example.com/events/evt1",
+		"description": "Deploy failed",
+		"name":        "ci/tsuru",
+	})
+}
+
+func (s *S) TestNotifyNoMapping(c *check.C) {
+	var reqs []receivedRequest
+	srv := fakeServer(c, &reqs)
+	defer srv.Close()
+	config.Set("commit-status:providers:gh:type", "github")
+	config.Set("commit-status:providers:gh:url", srv.URL)
+	config.Set("commit-status:repositories", []interface{}{
+		map[interface{}]interface{}{"app": "otherapp", "provider": "gh", "repository": "org/repo"},
+	})
+	err := Notify(context.Background(), Deploy{App: "myapp", Commit: "a1b2c3", Origin: "git", EventID: "evt1"}, StateSuccess)
+	c.Assert(err, check.IsNil)
+	c.Assert(reqs, check.HasLen, 0)
+}
+
+func (s *S) TestNotifyNoCommit(c *check.C) {
+	err := Notify(context.Background(), Deploy{App: "myapp", Commit: "", Origin: "git", EventID: "evt1"}, StateSuccess)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestNotifyProviderNotFound(c *check.C) {
+	config.Set("commit-status:repositories", []interface{}{
+		map[interface{}]interface{}{"app": "myapp", "provider": "gh", "repository": "org/repo"},
+	})
+	err := Notify(context.Background(), Deploy{App: "myapp", Commit: "a1b2c3", Origin: "git", EventID: "evt1"}, StateSuccess)
+	c.Assert(err, check.Equals, ErrProviderNotFound)
+}
+
+func (s *S) TestNotifyProviderError(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	config.Set("commit-status:providers:github:url", srv.URL)
+	config.Set("commit-status:repositories", []interface{}{
+		map[interface{}]interface{}{"app": "myapp", "provider": "github", "repository": "org/repo"},
+	})
+	err := Notify(context.Background(), Deploy{App: "myapp", Commit: "a1b2c3", Origin: "git", EventID: "evt1"}, StateSuccess)
+	c.Assert(err, check.ErrorMatches, "invalid status code setting commit status: 401: bad credentials\n")
+}
+
+func (s *S) TestNotifyNotGitOrigin(c *check.C) {
+	var reqs []receivedRequest
+	srv := fakeServer(c, &reqs)
+	defer srv.Close()
+	config.Set("commit-status:providers:github:url", srv.URL)
+	config.Set("commit-status:repositories", []interface{}{
+		map[interface{}]interface{}{"app": "myapp", "provider": "github", "repository": "org/repo"},
+	})
+	err := Notify(context.Background(), Deploy{App: "myapp", Commit: "a1b2c3", Origin: "image", EventID: "evt1"}, StateSuccess)
+	c.Assert(err, check.IsNil)
+	c.Assert(reqs, check.HasLen, 1)
+	c.Assert(reqs[0].path, check.Equals, "/repos/org/repo/statuses/a1b2c3")
+	c.Assert(reqs[0].body["state"], check.Equals, "success")
+}
+
+func (s *S) TestNotifyAsyncNotGitOrigin(c *check.C) {
+	var reqs []receivedRequest
+	srv := fakeServer(c, &reqs)
+	defer srv.Close()
+	config.Set("commit-status:providers:github:url", srv.URL)
+	config.Set("commit-status:repositories", []interface{}{
+		map[interface{}]interface{}{"app": "myapp", "provider": "github", "repository": "org/repo"},
+	})
+	done := make(chan struct{})
+	NotifyAsync(Deploy{App: "myapp", Commit: "d4e5f6", Origin: "rollback", EventID: "evt1"}, StatePending)
+	enqueue("myapp/d4e5f6", func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for notification")
+	}
+	c.Assert(reqs, check.HasLen, 1)
+	c.Assert(reqs[0].path, check.Equals, "/repos/org/repo/statuses/d4e5f6")
+}
+
+func (s *S) TestNotifyAsyncKeepsOrder(c *check.C) {
+	var mu sync.Mutex
+	var states []string
+	received := make(chan struct{}, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["state"] == "pending" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		mu.Lock()
+		states = append(states, body["state"])
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		received <- struct{}{}
+	}))
+	defer srv.Close()
+	config.Set("commit-status:providers:gh:type", "github")
+	config.Set("commit-status:providers:gh:url", srv.URL)
+	config.Set("commit-status:repositories", []interface{}{
+		map[interface{}]interface{}{"app": "myapp", "provider": "gh", "repository": "org/repo"},
+	})
+	d := Deploy{App: "myapp", Commit: "a1b2c3", Origin: "git", EventID: "evt1"}
+	NotifyAsync(d, StatePending)
+	NotifyAsync(d, StateSuccess)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			c.Fatal("timeout waiting for commit statuses")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	c.Assert(states, check.DeepEquals, []string{"pending", "success"})
+}
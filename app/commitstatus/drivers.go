@@ -0,0 +1,117 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commitstatus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	tsuruNet "github.com/tsuru/tsuru/net"
+)
+
+const (
+	defaultGitHubURL = "https://api.github.com"
+	defaultGitLabURL = "https://gitlab.com"
+)
+
+func init() {
+	Register("github", newGitHubProvider)
+	Register("gitlab", newGitLabProvider)
+}
+
+type gitHubProvider struct {
+	baseURL string
+	token   string
+}
+
+func newGitHubProvider(cfg ProviderConfig) (Provider, error) {
+	baseURL := cfg.URL
+	if baseURL == "" {
+		baseURL = defaultGitHubURL
+	}
+	return &gitHubProvider{baseURL: strings.TrimRight(baseURL, "/"), token: cfg.Token}, nil
+}
+
+func (p *gitHubProvider) SetStatus(ctx context.Context, status Status) error {
+	body := map[string]string{
+		"state":       string(status.State),
+		"target_url":  status.TargetURL,
+		"description": status.Description,
+		"context":     status.Context,
+	}
+	u := fmt.Sprintf("%s/repos/%s/statuses/%s", p.baseURL, status.Repository, status.Commit)
+	headers := http.Header{}
+	headers.Set("Accept", "application/vnd.github.v3+json")
+	if p.token != "" {
+		headers.Set("Authorization", "token "+p.token)
+	}
+	return doJSONRequest(ctx, u, headers, body)
+}
+
+type gitLabProvider struct {
+	baseURL string
+	token   string
+}
+
+func newGitLabProvider(cfg ProviderConfig) (Provider, error) {
+	baseURL := cfg.URL
+	if baseURL == "" {
+		baseURL = defaultGitLabURL
+	}
+	return &gitLabProvider{baseURL: strings.TrimRight(baseURL, "/"), token: cfg.Token}, nil
+}
+
+func gitLabState(state State) string {
+	if state == StateFailure {
+		return "failed"
+	}
+	return string(state)
+}
+
+func (p *gitLabProvider) SetStatus(ctx context.Context, status Status) error {
+	body := map[string]string{
+		"state":       gitLabState(status.State),
+		"target_url":  status.TargetURL,
+		"description": status.Description,
+		"name":        status.Context,
+	}
+	u := fmt.Sprintf("%s/api/v4/projects/%s/statuses/%s", p.baseURL, url.PathEscape(status.Repository), status.Commit)
+	headers := http.Header{}
+	if p.token != "" {
+		headers.Set("Private-Token", p.token)
+	}
+	return doJSONRequest(ctx, u, headers, body)
+}
+
+func doJSONRequest(ctx context.Context, u string, headers http.Header, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header = headers
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := tsuruNet.Dial15Full60ClientNoKeepAlive.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		respData, _ := ioutil.ReadAll(rsp.Body)
+		return errors.Errorf("invalid status code setting commit status: %d: %s", rsp.StatusCode, string(respData))
+	}
+	return nil
+}
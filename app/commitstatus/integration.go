@@ -0,0 +1,191 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commitstatus
+
+import (
+	"fmt"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/validation"
+)
+
+var (
+	ErrIntegrationNotFound      = errors.New("integration not found")
+	ErrIntegrationAlreadyExists = errors.New("integration already exists with the same name")
+)
+
+// Integration holds the credentials a team uses to report the deploys of its
+// apps to a source code hosting provider. Integrations take precedence over
+// the repositories declared in commit-status:repositories.
+type Integration struct {
+	Name         string              `json:"name" bson:"_id" form:"name"`
+	TeamOwner    string              `json:"team_owner" form:"team_owner"`
+	Type         string              `json:"type" form:"type"`
+	URL          string              `json:"url,omitempty" form:"url"`
+	Token        string              `json:"token,omitempty" form:"token"`
+	Repositories []RepositoryMapping `json:"repositories" form:"repositories"`
+}
+
+func integrationsCollection(conn *db.Storage) *storage.Collection {
+	coll := conn.Collection("commit_status_integrations")
+	coll.EnsureIndex(mgo.Index{Key: []string{"teamowner"}})
+	return coll
+}
+
+func (i *Integration) validate() error {
+	if _, ok := getFactory(i.Type); !ok {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid integration type %q, must be github or gitlab", i.Type)}
+	}
+	if i.Token == "" {
+		return &tsuruErrors.ValidationError{Message: "integration token must not be empty"}
+	}
+	if len(i.Repositories) == 0 {
+		return &tsuruErrors.ValidationError{Message: "integration must have at least one repository"}
+	}
+	for _, r := range i.Repositories {
+		if r.App == "" || r.Repository == "" {
+			return &tsuruErrors.ValidationError{Message: "integration repositories must have an app and a repository"}
+		}
+	}
+	return nil
+}
+
+func (i *Integration) provider() (Provider, error) {
+	factory, ok := getFactory(i.Type)
+	if !ok {
+		return nil, errors.Errorf("unknown commit status provider type %q", i.Type)
+	}
+	return factory(ProviderConfig{Name: i.Name, Type: i.Type, URL: i.URL, Token: i.Token})
+}
+
+// CreateIntegration stores a new integration.
+func CreateIntegration(i Integration) error {
+	if !validation.ValidateName(i.Name) {
+		return &tsuruErrors.ValidationError{Message: "Invalid integration name, integration name should have at most 40 " +
+			"characters, containing only lower case letters, numbers or dashes, " +
+			"starting with a letter."}
+	}
+	if err := i.validate(); err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = integrationsCollection(conn).Insert(i)
+	if mgo.IsDup(err) {
+		return ErrIntegrationAlreadyExists
+	}
+	return err
+}
+
+// UpdateIntegration replaces an existing integration. An empty token keeps
+// the current one, so clients don't need to send credentials again.
+func UpdateIntegration(i Integration) error {
+	if i.Token == "" {
+		current, err := GetIntegration(i.Name)
+		if err != nil {
+			return err
+		}
+		i.Token = current.Token
+	}
+	if err := i.validate(); err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = integrationsCollection(conn).UpdateId(i.Name, i)
+	if err == mgo.ErrNotFound {
+		return ErrIntegrationNotFound
+	}
+	return err
+}
+
+// GetIntegration returns the integration with the given name.
+func GetIntegration(name string) (*Integration, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var i Integration
+	err = integrationsCollection(conn).FindId(name).One(&i)
+	if err == mgo.ErrNotFound {
+		return nil, ErrIntegrationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// ListIntegrations returns the integrations owned by the given teams, or all
+// integrations when teams is nil.
+func ListIntegrations(teams []string) ([]Integration, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var query bson.M
+	if teams != nil {
+		query = bson.M{"teamowner": bson.M{"$in": teams}}
+	}
+	var integrations []Integration
+	err = integrationsCollection(conn).Find(query).Sort("_id").All(&integrations)
+	if err != nil {
+		return nil, err
+	}
+	return integrations, nil
+}
+
+// RemoveIntegration removes the integration with the given name.
+func RemoveIntegration(name string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = integrationsCollection(conn).RemoveId(name)
+	if err == mgo.ErrNotFound {
+		return ErrIntegrationNotFound
+	}
+	return err
+}
+
+// findIntegration returns the integration of the first team, in the given
+// order, with a repository mapped to the app.
+func findIntegration(appName string, teams []string) (*Integration, *RepositoryMapping, error) {
+	if len(teams) == 0 {
+		return nil, nil, nil
+	}
+	integrations, err := ListIntegrations(teams)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, team := range teams {
+		for i := range integrations {
+			if integrations[i].TeamOwner != team {
+				continue
+			}
+			for _, m := range integrations[i].Repositories {
+				if m.Match(appName) {
+					m.Provider = integrations[i].Name
+					return &integrations[i], &m, nil
+				}
+			}
+		}
+	}
+	return nil, nil, nil
+}
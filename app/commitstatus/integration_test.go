@@ -0,0 +1,161 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package commitstatus
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	check "gopkg.in/check.v1"
+)
+
+type IntegrationSuite struct{}
+
+var _ = check.Suite(&IntegrationSuite{})
+
+func (s *IntegrationSuite) SetUpTest(c *check.C) {
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "tsuru_commitstatus_tests")
+	config.Set("host", "http://tsuru.example.com")
+	conn, err := db.Conn()
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+	err = dbtest.ClearAllCollections(conn.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IntegrationSuite) TearDownTest(c *check.C) {
+	config.Unset("commit-status")
+	config.Unset("host")
+}
+
+func (s *IntegrationSuite) TestCreateIntegration(c *check.C) {
+	i := Integration{
+		Name:         "team1-github",
+		TeamOwner:    "team1",
+		Type:         "github",
+		Token:        "abc",
+		Repositories: []RepositoryMapping{{App: "myapp", Repository: "org/repo"}},
+	}
+	err := CreateIntegration(i)
+	c.Assert(err, check.IsNil)
+	dbI, err := GetIntegration("team1-github")
+	c.Assert(err, check.IsNil)
+	c.Assert(*dbI, check.DeepEquals, i)
+	err = CreateIntegration(i)
+	c.Assert(err, check.Equals, ErrIntegrationAlreadyExists)
+}
+
+func (s *IntegrationSuite) TestCreateIntegrationInvalid(c *check.C) {
+	tests := []struct {
+		integration Integration
+		expected    string
+	}{
+		{Integration{Name: "_x", Type: "github", Token: "abc"}, "Invalid integration name.*"},
+		{Integration{Name: "x", Type: "bitbucket", Token: "abc"}, `invalid integration type "bitbucket", must be github or gitlab`},
+		{Integration{Name: "x", Type: "github"}, "integration token must not be empty"},
+		{Integration{Name: "x", Type: "github", Token: "abc"}, "integration must have at least one repository"},
+		{Integration{Name: "x", Type: "github", Token: "abc", Repositories: []RepositoryMapping{{App: "myapp"}}}, "integration repositories must have an app and a repository"},
+	}
+	for i, tt := range tests {
+		err := CreateIntegration(tt.integration)
+		c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{}, check.Commentf("failed test %d", i))
+		c.Assert(err, check.ErrorMatches, tt.expected, check.Commentf("failed test %d", i))
+	}
+}
+
+func (s *IntegrationSuite) TestUpdateIntegrationKeepsToken(c *check.C) {
+	i := Integration{
+		Name:         "team1-github",
+		TeamOwner:    "team1",
+		Type:         "github",
+		Token:        "abc",
+		Repositories: []RepositoryMapping{{App: "myapp", Repository: "org/repo"}},
+	}
+	err := CreateIntegration(i)
+	c.Assert(err, check.IsNil)
+	i.Token = ""
+	i.Repositories = []RepositoryMapping{{App: "myapp-*", Repository: "org/other"}}
+	err = UpdateIntegration(i)
+	c.Assert(err, check.IsNil)
+	dbI, err := GetIntegration("team1-github")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbI.Token, check.Equals, "abc")
+	c.Assert(dbI.Repositories, check.DeepEquals, []RepositoryMapping{{App: "myapp-*", Repository: "org/other"}})
+	err = UpdateIntegration(Integration{Name: "unknown"})
+	c.Assert(err, check.Equals, ErrIntegrationNotFound)
+}
+
+func (s *IntegrationSuite) TestListAndRemoveIntegrations(c *check.C) {
+	for _, team := range []string{"team1", "team2"} {
+		err := CreateIntegration(Integration{
+			Name:         team + "-gitlab",
+			TeamOwner:    team,
+			Type:         "gitlab",
+			Token:        "abc",
+			Repositories: []RepositoryMapping{{App: "*", Repository: "group/project"}},
+		})
+		c.Assert(err, check.IsNil)
+	}
+	integrations, err := ListIntegrations(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(integrations, check.HasLen, 2)
+	integrations, err = ListIntegrations([]string{"team2"})
+	c.Assert(err, check.IsNil)
+	c.Assert(integrations, check.HasLen, 1)
+	c.Assert(integrations[0].Name, check.Equals, "team2-gitlab")
+	err = RemoveIntegration("team2-gitlab")
+	c.Assert(err, check.IsNil)
+	err = RemoveIntegration("team2-gitlab")
+	c.Assert(err, check.Equals, ErrIntegrationNotFound)
+}
+
+func (s *IntegrationSuite) TestNotifyUsesTeamIntegration(c *check.C) {
+	var reqs []receivedRequest
+	srv := fakeServer(c, &reqs)
+	defer srv.Close()
+	config.Set("commit-status:providers:github:url", srv.URL)
+	config.Set("commit-status:providers:github:token", "global")
+	config.Set("commit-status:repositories", []interface{}{
+		map[interface{}]interface{}{"app": "myapp", "provider": "github", "repository": "org/global"},
+	})
+	for _, i := range []Integration{
+		{Name: "other-team", TeamOwner: "team2", Type: "github", URL: srv.URL, Token: "team2", Repositories: []RepositoryMapping{{App: "myapp", Repository: "org/team2"}}},
+		{Name: "owner-team", TeamOwner: "team1", Type: "github", URL: srv.URL, Token: "team1", Repositories: []RepositoryMapping{{App: "my*", Repository: "org/team1"}}},
+	} {
+		err := CreateIntegration(i)
+		c.Assert(err, check.IsNil)
+	}
+	err := Notify(context.Background(), Deploy{App: "myapp", Teams: []string{"team1", "team2"}, Commit: "a1b2c3", Origin: "git", EventID: "evt1"}, StateSuccess)
+	c.Assert(err, check.IsNil)
+	err = Notify(context.Background(), Deploy{App: "myapp", Teams: []string{"team3"}, Commit: "a1b2c3", Origin: "git", EventID: "evt1"}, StateSuccess)
+	c.Assert(err, check.IsNil)
+	c.Assert(reqs, check.HasLen, 2)
+	c.Assert(reqs[0].path, check.Equals, "/repos/org/team1/statuses/a1b2c3")
+	c.Assert(reqs[0].header.Get("Authorization"), check.Equals, "token team1")
+	c.Assert(reqs[1].path, check.Equals, "/repos/org/global/statuses/a1b2c3")
+	c.Assert(reqs[1].header.Get("Authorization"), check.Equals, "token global")
+}
+
+func (s *IntegrationSuite) TestNotifyNotGitOriginSkipsTeamIntegration(c *check.C) {
+	var reqs []receivedRequest
+	srv := fakeServer(c, &reqs)
+	defer srv.Close()
+	config.Set("commit-status:providers:github:url", srv.URL)
+	config.Set("commit-status:providers:github:token", "global")
+	config.Set("commit-status:repositories", []interface{}{
+		map[interface{}]interface{}{"app": "myapp", "provider": "github", "repository": "org/global"},
+	})
+	err := CreateIntegration(Integration{Name: "owner-team", TeamOwner: "team1", Type: "github", URL: srv.URL, Token: "team1", Repositories: []RepositoryMapping{{App: "myapp", Repository: "org/team1"}}})
+	c.Assert(err, check.IsNil)
+	err = Notify(context.Background(), Deploy{App: "myapp", Teams: []string{"team1"}, Commit: "a1b2c3", Origin: "image", EventID: "evt1"}, StateSuccess)
+	c.Assert(err, check.IsNil)
+	c.Assert(reqs, check.HasLen, 1)
+	c.Assert(reqs[0].path, check.Equals, "/repos/org/global/statuses/a1b2c3")
+	c.Assert(reqs[0].header.Get("Authorization"), check.Equals, "token global")
+}
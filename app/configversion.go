@@ -0,0 +1,364 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+)
+
+const defaultRollingBatchSize = 1
+
+var ErrConfigVersionNotFound = errors.New("configuration version not found")
+
+// ConfigVersion is a revision of the environment variables of an app. A new
+// version is recorded each time the environment variables are changed,
+// allowing the changes to be audited and the app to be rolled back to a
+// previous version.
+type ConfigVersion struct {
+	App       string                 `json:"app"`
+	Version   int                    `json:"version"`
+	Env       map[string]bind.EnvVar `json:"env"`
+	CreatedAt time.Time              `json:"createdAt"`
+	// EventID is the event that changed the environment variables, if any.
+	EventID string `json:"eventID,omitempty"`
+}
+
+// EnvChange is a change to an environment variable between two
+// configuration versions. Values of private variables are suppressed.
+type EnvChange struct {
+	Name     string `json:"name"`
+	OldValue string `json:"oldValue,omitempty"`
+	NewValue string `json:"newValue,omitempty"`
+}
+
+// ConfigVersionDiff holds the changes to the environment variables of an
+// app between two configuration versions.
+type ConfigVersionDiff struct {
+	From    int         `json:"from"`
+	To      int         `json:"to"`
+	Added   []EnvChange `json:"added"`
+	Removed []EnvChange `json:"removed"`
+	Changed []EnvChange `json:"changed"`
+}
+
+type applyConfigArgs struct {
+	restart   bool
+	rolling   bool
+	batchSize int
+	w         io.Writer
+}
+
+// SuppressSensitiveEnvs hides the values of private environment variables.
+func (v *ConfigVersion) SuppressSensitiveEnvs() {
+	newEnv := map[string]bind.EnvVar{}
+	for key, env := range v.Env {
+		if !env.Public {
+			env.Value = SuppressedEnv
+		}
+		newEnv[key] = env
+	}
+	v.Env = newEnv
+}
+
+func configVersionsCollection(conn *db.Storage) *storage.Collection {
+	coll := conn.Collection("app_config_versions")
+	coll.EnsureIndex(mgo.Index{Key: []string{"app", "version"}, Unique: true})
+	return coll
+}
+
+// ConfigVersions returns the configuration versions of the app, newest first.
+func (app *App) ConfigVersions() ([]ConfigVersion, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var versions []ConfigVersion
+	err = configVersionsCollection(conn).Find(bson.M{"app": app.Name}).Sort("-version").All(&versions)
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (app *App) configVersion(version int) (*ConfigVersion, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	filter := bson.M{"app": app.Name}
+	if version > 0 {
+		filter["version"] = version
+	}
+	var cv ConfigVersion
+	err = configVersionsCollection(conn).Find(filter).Sort("-version").One(&cv)
+	if err == mgo.ErrNotFound {
+		return nil, ErrConfigVersionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cv, nil
+}
+
+// currentConfigVersion returns the latest configuration version of the app,
+// recording the current environment variables as the first version if there
+// is none yet.
+func (app *App) currentConfigVersion() (*ConfigVersion, error) {
+	cv, err := app.configVersion(0)
+	if err == ErrConfigVersionNotFound {
+		return app.saveConfigVersion(nil)
+	}
+	return cv, err
+}
+
+// ConfigVersion returns the given configuration version of the app.
+func (app *App) ConfigVersion(version int) (*ConfigVersion, error) {
+	if version <= 0 {
+		return nil, ErrConfigVersionNotFound
+	}
+	return app.configVersion(version)
+}
+
+// DiffConfigVersions returns the changes to the environment variables from
+// one configuration version to another. A from version of zero stands for the
+// version right before the to version.
+func (app *App) DiffConfigVersions(from, to int) (*ConfigVersionDiff, error) {
+	target, err := app.ConfigVersion(to)
+	if err != nil {
+		return nil, err
+	}
+	if from <= 0 {
+		from = to - 1
+	}
+	base := &ConfigVersion{Version: from}
+	if from > 0 {
+		base, err = app.ConfigVersion(from)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return diffConfigVersions(base, target), nil
+}
+
+func diffConfigVersions(from, to *ConfigVersion) *ConfigVersionDiff {
+	diff := ConfigVersionDiff{
+		From:    from.Version,
+		To:      to.Version,
+		Added:   []EnvChange{},
+		Removed: []EnvChange{},
+		Changed: []EnvChange{},
+	}
+	value := func(env bind.EnvVar) string {
+		if !env.Public {
+			return SuppressedEnv
+		}
+		return env.Value
+	}
+	for _, name := range sortedEnvNames(to.Env) {
+		newEnv := to.Env[name]
+		oldEnv, ok := from.Env[name]
+		if !ok {
+			diff.Added = append(diff.Added, EnvChange{Name: name, NewValue: value(newEnv)})
+			continue
+		}
+		if oldEnv.Value != newEnv.Value || oldEnv.Public != newEnv.Public || oldEnv.Alias != newEnv.Alias {
+			diff.Changed = append(diff.Changed, EnvChange{Name: name, OldValue: value(oldEnv), NewValue: value(newEnv)})
+		}
+	}
+	for _, name := range sortedEnvNames(from.Env) {
+		if _, ok := to.Env[name]; !ok {
+			diff.Removed = append(diff.Removed, EnvChange{Name: name, OldValue: value(from.Env[name])})
+		}
+	}
+	return &diff
+}
+
+func sortedEnvNames(envs map[string]bind.EnvVar) []string {
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// saveConfigVersion records the current environment variables as a new
+// configuration version, unless they're unchanged since the latest one.
+func (app *App) saveConfigVersion(w io.Writer) (*ConfigVersion, error) {
+	latest, err := app.configVersion(0)
+	if err != nil && err != ErrConfigVersionNotFound {
+		return nil, err
+	}
+	if latest != nil && (len(latest.Env) == 0 && len(app.Env) == 0 || reflect.DeepEqual(latest.Env, app.Env)) {
+		return latest, nil
+	}
+	cv := ConfigVersion{
+		App:       app.Name,
+		Version:   1,
+		Env:       make(map[string]bind.EnvVar, len(app.Env)),
+		CreatedAt: time.Now().UTC(),
+	}
+	for name, env := range app.Env {
+		cv.Env[name] = env
+	}
+	if latest != nil {
+		cv.Version = latest.Version + 1
+	}
+	if evt, ok := w.(*event.Event); ok {
+		cv.EventID = evt.UniqueID.Hex()
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	err = configVersionsCollection(conn).Insert(cv)
+	if err != nil {
+		return nil, err
+	}
+	return &cv, nil
+}
+
+// applyConfigVersion records the current environment variables as a new
+// configuration version and restarts the app. With a rolling restart, units
+// are replaced in batches and, if the replacement fails, the app is rolled
+// back to the previous version.
+func (app *App) applyConfigVersion(previous *ConfigVersion, args applyConfigArgs) error {
+	cv, err := app.saveConfigVersion(args.w)
+	if err != nil {
+		return err
+	}
+	if !args.rolling {
+		if args.restart {
+			return app.restartIfUnits(args.w)
+		}
+		return nil
+	}
+	w := args.w
+	if w == nil {
+		w = ioutil.Discard
+	}
+	fmt.Fprintf(w, "---- Configuration version %d ----\n", cv.Version)
+	if !args.restart {
+		return nil
+	}
+	err = app.rollingRestartIfUnits(args.batchSize, w)
+	if err == nil {
+		return nil
+	}
+	fmt.Fprintf(w, "---- Rolling restart failed, rolling back to configuration version %d ----\n", previous.Version)
+	if _, rollbackErr := app.restoreConfigVersion(previous, args.batchSize, w); rollbackErr != nil {
+		return errors.Wrapf(err, "unable to roll back to configuration version %d: %v", previous.Version, rollbackErr)
+	}
+	return errors.Wrapf(err, "rolled back to configuration version %d", previous.Version)
+}
+
+// RollbackConfigVersion restores the environment variables of the app to the
+// given configuration version, or to the one before the latest version when
+// version is zero, replacing the units in batches. The restore is recorded as
+// a new configuration version.
+func (app *App) RollbackConfigVersion(version, batchSize int, w io.Writer) (*ConfigVersion, error) {
+	if version <= 0 {
+		latest, err := app.configVersion(0)
+		if err != nil {
+			return nil, err
+		}
+		version = latest.Version - 1
+		if version <= 0 {
+			return nil, ErrConfigVersionNotFound
+		}
+	}
+	target, err := app.configVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		w = ioutil.Discard
+	}
+	fmt.Fprintf(w, "---- Rolling back to configuration version %d ----\n", target.Version)
+	return app.restoreConfigVersion(target, batchSize, w)
+}
+
+func (app *App) restoreConfigVersion(target *ConfigVersion, batchSize int, w io.Writer) (*ConfigVersion, error) {
+	app.Env = make(map[string]bind.EnvVar, len(target.Env))
+	for name, env := range target.Env {
+		app.Env[name] = env
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, bson.M{"$set": bson.M{"env": app.Env}})
+	if err != nil {
+		return nil, err
+	}
+	cv, err := app.saveConfigVersion(w)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w, "---- Configuration version %d ----\n", cv.Version)
+	return cv, app.rollingRestartIfUnits(batchSize, w)
+}
+
+// rollingRestartIfUnits replaces the units of the app in batches, falling
+// back to a regular restart when the provisioner can't do it.
+func (app *App) rollingRestartIfUnits(batchSize int, w io.Writer) error {
+	units, err := app.GetUnits()
+	if err != nil {
+		return err
+	}
+	if len(units) == 0 {
+		return nil
+	}
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return err
+	}
+	roller, ok := prov.(provision.RollingRestarter)
+	if !ok {
+		return app.restartIfUnits(w)
+	}
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(app.ctx, app)
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		batchSize = defaultRollingBatchSize
+	}
+	fmt.Fprintf(w, "---- Replacing %d units, %d at a time ----\n", len(units), batchSize)
+	err = roller.RollingRestart(app.ctx, app, version, batchSize, w)
+	if err != nil {
+		return newErrorWithLog(err, app, "restart")
+	}
+	return nil
+}
+
+func (app *App) removeConfigVersions() error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = configVersionsCollection(conn).RemoveAll(bson.M{"app": app.Name})
+	return err
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tsuru/tsuru/app/bind"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createAppWithUnits(c *check.C, name string) *App {
+	a := App{Name: name, Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	err = s.provisioner.AddUnits(context.TODO(), &a, 3, "web", version, nil)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestSetEnvsRollingRestart(c *check.C) {
+	a := s.createAppWithUnits(c, "myapp")
+	err := a.SetEnvs(bind.SetEnvArgs{
+		Envs:          []bind.EnvVar{{Name: "DEBUG", Value: "0", Public: true}},
+		ShouldRestart: false,
+	})
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs:           []bind.EnvVar{{Name: "DEBUG", Value: "1", Public: true}},
+		ShouldRestart:  true,
+		RollingRestart: true,
+		BatchSize:      2,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.provisioner.Restarts(a, ""), check.Equals, 0)
+	c.Assert(s.provisioner.RollingRestarts(a), check.DeepEquals, []int{2})
+	versions, err := a.ConfigVersions()
+	c.Assert(err, check.IsNil)
+	c.Assert(versions, check.HasLen, 4)
+	c.Assert(versions[0].Version, check.Equals, 4)
+	c.Assert(versions[0].Env["DEBUG"].Value, check.Equals, "1")
+	c.Assert(versions[1].Version, check.Equals, 3)
+	c.Assert(versions[1].Env["DEBUG"].Value, check.Equals, "0")
+}
+
+func (s *S) TestSetEnvsRollingRestartFailureRollsBack(c *check.C) {
+	a := s.createAppWithUnits(c, "myapp")
+	err := a.SetEnvs(bind.SetEnvArgs{
+		Envs: []bind.EnvVar{{Name: "DEBUG", Value: "0", Public: true}},
+	})
+	c.Assert(err, check.IsNil)
+	s.provisioner.PrepareFailure("RollingRestart", errors.New("unit not healthy"))
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs:           []bind.EnvVar{{Name: "DEBUG", Value: "1", Public: true}},
+		ShouldRestart:  true,
+		RollingRestart: true,
+	})
+	c.Assert(err, check.ErrorMatches, `(?s)rolled back to configuration version 3: .*unit not healthy.*`)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["DEBUG"].Value, check.Equals, "0")
+	c.Assert(s.provisioner.RollingRestarts(a), check.DeepEquals, []int{1})
+	versions, err := a.ConfigVersions()
+	c.Assert(err, check.IsNil)
+	c.Assert(versions, check.HasLen, 5)
+	c.Assert(versions[0].Env["DEBUG"].Value, check.Equals, "0")
+}
+
+func (s *S) TestUnsetEnvsRollingRestart(c *check.C) {
+	a := s.createAppWithUnits(c, "myapp")
+	err := a.SetEnvs(bind.SetEnvArgs{
+		Envs: []bind.EnvVar{{Name: "DEBUG", Value: "0", Public: true}},
+	})
+	c.Assert(err, check.IsNil)
+	err = a.UnsetEnvs(bind.UnsetEnvArgs{
+		VariableNames:  []string{"DEBUG"},
+		ShouldRestart:  true,
+		RollingRestart: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.provisioner.RollingRestarts(a), check.DeepEquals, []int{1})
+	versions, err := a.ConfigVersions()
+	c.Assert(err, check.IsNil)
+	c.Assert(versions, check.HasLen, 4)
+	_, ok := versions[0].Env["DEBUG"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestRollbackConfigVersion(c *check.C) {
+	a := s.createAppWithUnits(c, "myapp")
+	for _, value := range []string{"0", "1", "2"} {
+		err := a.SetEnvs(bind.SetEnvArgs{
+			Envs:           []bind.EnvVar{{Name: "DEBUG", Value: value, Public: true}},
+			RollingRestart: true,
+		})
+		c.Assert(err, check.IsNil)
+	}
+	cv, err := a.RollbackConfigVersion(0, 3, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(cv.Version, check.Equals, 6)
+	c.Assert(cv.Env["DEBUG"].Value, check.Equals, "1")
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Env["DEBUG"].Value, check.Equals, "1")
+	c.Assert(s.provisioner.RollingRestarts(a), check.DeepEquals, []int{3})
+	cv, err = a.RollbackConfigVersion(3, 0, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(cv.Version, check.Equals, 7)
+	c.Assert(cv.Env["DEBUG"].Value, check.Equals, "0")
+	_, err = a.RollbackConfigVersion(42, 0, nil)
+	c.Assert(err, check.Equals, ErrConfigVersionNotFound)
+}
+
+func (s *S) TestSetEnvsRecordsConfigVersions(c *check.C) {
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	versions, err := a.ConfigVersions()
+	c.Assert(err, check.IsNil)
+	c.Assert(versions, check.HasLen, 2)
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs: []bind.EnvVar{{Name: "DEBUG", Value: "1", Public: true}},
+	})
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs: []bind.EnvVar{{Name: "DEBUG", Value: "1", Public: true}},
+	})
+	c.Assert(err, check.IsNil)
+	err = a.UnsetEnvs(bind.UnsetEnvArgs{VariableNames: []string{"DEBUG"}})
+	c.Assert(err, check.IsNil)
+	versions, err = a.ConfigVersions()
+	c.Assert(err, check.IsNil)
+	c.Assert(versions, check.HasLen, 4)
+	c.Assert(versions[0].Version, check.Equals, 4)
+	c.Assert(versions[1].Env["DEBUG"].Value, check.Equals, "1")
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 0)
+}
+
+func (s *S) TestDiffConfigVersions(c *check.C) {
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs: []bind.EnvVar{
+			{Name: "DEBUG", Value: "0", Public: true},
+			{Name: "PASSWORD", Value: "old"},
+			{Name: "REMOVED", Value: "x", Public: true},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = a.UnsetEnvs(bind.UnsetEnvArgs{VariableNames: []string{"REMOVED"}})
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{
+		Envs: []bind.EnvVar{
+			{Name: "DEBUG", Value: "1", Public: true},
+			{Name: "PASSWORD", Value: "new"},
+			{Name: "NEW", Value: "y", Public: true},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	diff, err := a.DiffConfigVersions(0, 5)
+	c.Assert(err, check.IsNil)
+	c.Assert(diff, check.DeepEquals, &ConfigVersionDiff{
+		From:    4,
+		To:      5,
+		Added:   []EnvChange{{Name: "NEW", NewValue: "y"}},
+		Removed: []EnvChange{},
+		Changed: []EnvChange{
+			{Name: "DEBUG", OldValue: "0", NewValue: "1"},
+			{Name: "PASSWORD", OldValue: SuppressedEnv, NewValue: SuppressedEnv},
+		},
+	})
+	diff, err = a.DiffConfigVersions(3, 5)
+	c.Assert(err, check.IsNil)
+	c.Assert(diff.Removed, check.DeepEquals, []EnvChange{{Name: "REMOVED", OldValue: "x"}})
+	_, err = a.DiffConfigVersions(0, 6)
+	c.Assert(err, check.Equals, ErrConfigVersionNotFound)
+}
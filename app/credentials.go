@@ -0,0 +1,159 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+)
+
+const apprcPath = "/home/application/apprc"
+
+// shellEnvNameRegexp matches the names that can be exported by the shell.
+var shellEnvNameRegexp = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+// RotateInstanceEnvsArgs holds the credentials pushed by a service for one of
+// its instances bound to an app.
+type RotateInstanceEnvsArgs struct {
+	ServiceName  string
+	InstanceName string
+	Envs         map[string]string
+	Writer       io.Writer
+}
+
+// RotateInstanceEnvs replaces the values of the environment variables set by
+// a service instance bound to the app and reloads them in the running units,
+// without restarting them. Units get the new values in the apprc file and then
+// run the reload hooks of the app, if any. Units of provisioners unable to
+// execute commands are restarted instead.
+func (app *App) RotateInstanceEnvs(args RotateInstanceEnvsArgs) error {
+	if len(args.Envs) == 0 {
+		return errors.New("no environment variables to rotate")
+	}
+	if len(app.InstanceEnvs(args.ServiceName, args.InstanceName)) == 0 {
+		return errors.Errorf("app %q has no environment variables from instance %q of service %q", app.Name, args.InstanceName, args.ServiceName)
+	}
+	w := args.Writer
+	if w == nil {
+		w = ioutil.Discard
+	}
+	names := make([]string, 0, len(args.Envs))
+	for name := range args.Envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		found := false
+		for i, env := range app.ServiceEnvs {
+			if env.ServiceName == args.ServiceName && env.InstanceName == args.InstanceName && env.Name == name {
+				app.ServiceEnvs[i].Value = args.Envs[name]
+				found = true
+			}
+		}
+		if !found {
+			app.ServiceEnvs = append(app.ServiceEnvs, bind.ServiceEnvVar{
+				EnvVar:       bind.EnvVar{Name: name, Value: args.Envs[name]},
+				ServiceName:  args.ServiceName,
+				InstanceName: args.InstanceName,
+			})
+		}
+	}
+	fmt.Fprintf(w, "---- Rotating %d environment variables ----\n", len(names))
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, bson.M{"$set": bson.M{"serviceenvs": app.ServiceEnvs}})
+	if err != nil {
+		return err
+	}
+	return app.reloadUnitEnvs(w)
+}
+
+// reloadUnitEnvs writes the current environment variables of the app to the
+// apprc file of every unit and runs the reload hooks of the app.
+func (app *App) reloadUnitEnvs(w io.Writer) error {
+	units, err := app.Units()
+	if err != nil {
+		return err
+	}
+	if len(units) == 0 {
+		return nil
+	}
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return err
+	}
+	execProv, ok := prov.(provision.ExecutableProvisioner)
+	if !ok {
+		fmt.Fprintf(w, "---- Provisioner unable to reload units, restarting them ----\n")
+		return app.restartIfUnits(w)
+	}
+	var hooks []string
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(app.ctx, app)
+	if err == nil {
+		yamlData, errYaml := version.TsuruYamlData()
+		if errYaml != nil {
+			return errYaml
+		}
+		if yamlData.Hooks != nil {
+			hooks = yamlData.Hooks.Reload
+		}
+	}
+	opts := provision.ExecOptions{
+		App:    app,
+		Stdout: w,
+		Stderr: w,
+		Cmds:   []string{"/bin/sh", "-c", reloadEnvsScript(app.Envs(), hooks)},
+	}
+	for _, u := range units {
+		opts.Units = append(opts.Units, u.ID)
+	}
+	fmt.Fprintf(w, "---- Reloading environment variables in %d units ----\n", len(units))
+	return execProv.ExecuteCommand(app.ctx, opts)
+}
+
+// reloadEnvsScript returns a shell script that rewrites the apprc file with
+// the environment variables and runs the reload hooks with them loaded. The
+// file content is passed to printf as a single quoted argument, so values
+// can't break out of it, and variables with invalid names are skipped.
+func reloadEnvsScript(envs map[string]bind.EnvVar, hooks []string) string {
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		if shellEnvNameRegexp.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var apprc strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&apprc, "export %s=%s\n", name, shellQuote(envs[name].Value))
+	}
+	var script strings.Builder
+	fmt.Fprintf(&script, "printf '%%s' %s > %s.new", shellQuote(apprc.String()), apprcPath)
+	fmt.Fprintf(&script, " && mv %[1]s.new %[1]s", apprcPath)
+	if len(hooks) > 0 {
+		fmt.Fprintf(&script, " && . %s", apprcPath)
+		fmt.Fprintf(&script, " && { [ ! -d %[1]s ] || cd %[1]s; }", defaultAppDir)
+		fmt.Fprintf(&script, " && %s", strings.Join(hooks, " && "))
+	}
+	return script.String()
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/tsuru/tsuru/app/bind"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestReloadEnvsScript(c *check.C) {
+	envs := map[string]bind.EnvVar{
+		"DB_USER":     {Name: "DB_USER", Value: "root"},
+		"DB_PASSWORD": {Name: "DB_PASSWORD", Value: "it's secret"},
+	}
+	script := reloadEnvsScript(envs, nil)
+	c.Assert(script, check.Equals, `printf '%s' 'export DB_PASSWORD='\''it'\''\'\'''\''s secret'\''
+export DB_USER='\''root'\''
+' > /home/application/apprc.new && mv /home/application/apprc.new /home/application/apprc`)
+	script = reloadEnvsScript(envs, []string{"kill -HUP 1", "echo reloaded"})
+	c.Assert(script, check.Matches, `(?s).*mv /home/application/apprc.new /home/application/apprc && \. /home/application/apprc && \{ \[ ! -d /home/application/current \] \|\| cd /home/application/current; \} && kill -HUP 1 && echo reloaded$`)
+}
+
+func (s *S) TestReloadEnvsScriptQuotesValues(c *check.C) {
+	envs := map[string]bind.EnvVar{
+		"TOKEN":   {Name: "TOKEN", Value: "a'b\nTSURU_APPRC_EOF\n$(touch pwned) `touch pwned`"},
+		"EMPTY":   {Name: "EMPTY", Value: ""},
+		"BAD;env": {Name: "BAD;env", Value: "x"},
+	}
+	dir := c.MkDir()
+	script := strings.Replace(reloadEnvsScript(envs, nil), apprcPath, filepath.Join(dir, "apprc"), -1)
+	c.Assert(script, check.Not(check.Matches), ".*BAD.*")
+	out, err := exec.Command("/bin/sh", "-c", "cd "+dir+" && "+script+` && . ./apprc && printf '%s|%s' "$TOKEN" "$EMPTY"`).CombinedOutput()
+	c.Assert(err, check.IsNil, check.Commentf("output: %s", out))
+	c.Assert(string(out), check.Equals, envs["TOKEN"].Value+"|")
+	_, err = os.Stat(filepath.Join(dir, "pwned"))
+	c.Assert(os.IsNotExist(err), check.Equals, true)
+}
+
+func (s *S) TestRotateInstanceEnvs(c *check.C) {
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddInstance(bind.AddInstanceArgs{
+		Envs: []bind.ServiceEnvVar{
+			{EnvVar: bind.EnvVar{Name: "DB_USER", Value: "root"}, InstanceName: "mydb", ServiceName: "mysql"},
+			{EnvVar: bind.EnvVar{Name: "DB_PASSWORD", Value: "old"}, InstanceName: "mydb", ServiceName: "mysql"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	version := newSuccessfulAppVersion(c, &a)
+	err = version.AddData(appTypes.AddVersionDataArgs{
+		CustomData: map[string]interface{}{
+			"hooks": map[string]interface{}{"reload": []string{"kill -HUP 1"}},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddUnits(context.TODO(), &a, 2, "web", version, nil)
+	c.Assert(err, check.IsNil)
+	s.provisioner.PrepareOutput([]byte("reloaded"))
+	s.provisioner.PrepareOutput([]byte("reloaded"))
+	err = a.RotateInstanceEnvs(RotateInstanceEnvsArgs{
+		ServiceName:  "mysql",
+		InstanceName: "mydb",
+		Envs:         map[string]string{"DB_PASSWORD": "new"},
+	})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.InstanceEnvs("mysql", "mydb"), check.DeepEquals, map[string]bind.EnvVar{
+		"DB_USER":     {Name: "DB_USER", Value: "root"},
+		"DB_PASSWORD": {Name: "DB_PASSWORD", Value: "new"},
+	})
+	c.Assert(s.provisioner.Restarts(&a, ""), check.Equals, 0)
+	allExecs := s.provisioner.AllExecs()
+	c.Assert(allExecs, check.HasLen, 2)
+	for _, execs := range allExecs {
+		c.Assert(execs, check.HasLen, 1)
+		c.Assert(execs[0].Cmds, check.HasLen, 3)
+		c.Assert(execs[0].Cmds[2], check.Matches, `(?s).*export DB_PASSWORD='\\''new'\\''\n.*&& kill -HUP 1$`)
+	}
+}
+
+func (s *S) TestRotateInstanceEnvsNotBound(c *check.C) {
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.RotateInstanceEnvs(RotateInstanceEnvsArgs{
+		ServiceName:  "mysql",
+		InstanceName: "mydb",
+		Envs:         map[string]string{"DB_PASSWORD": "new"},
+	})
+	c.Assert(err, check.ErrorMatches, `app "myapp" has no environment variables from instance "mydb" of service "mysql"`)
+}
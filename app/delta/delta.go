@@ -0,0 +1,232 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package delta rebuilds deploy archives from the archive used in the last
+// deploy of an app and a patch holding only the files changed since then.
+//
+// The client asks for the Base of the app, compares its files against the
+// local ones and sends a Manifest listing every regular file of the new
+// archive along with a patch archive, a gzipped tar like the ones used in
+// regular uploads, holding the new and changed files. Directories, symlinks
+// and other non-regular entries are always sent in the patch.
+package delta
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"path"
+
+	"github.com/pkg/errors"
+)
+
+var ErrBaseMismatch = errors.New("delta base doesn't match the last deployed archive")
+
+// File is a regular file in an archive, identified by the sha256 of its
+// contents.
+type File struct {
+	Path   string `json:"path"`
+	Digest string `json:"digest"`
+}
+
+// Base describes the archive used as base for delta deploys. Digest is the
+// sha256 of the archive itself.
+type Base struct {
+	Digest string `json:"digest"`
+	Files  []File `json:"files"`
+}
+
+// Manifest describes the archive to be rebuilt from Base, which must match
+// the digest of the base archive, and the patch.
+type Manifest struct {
+	Base  string `json:"base"`
+	Files []File `json:"files"`
+}
+
+func (m *Manifest) validate() error {
+	if m.Base == "" {
+		return errors.New("delta manifest must have a base digest")
+	}
+	for _, f := range m.Files {
+		if f.Path == "" || f.Digest == "" {
+			return errors.New("delta manifest files must have path and digest")
+		}
+	}
+	return nil
+}
+
+// Describe reads the gzipped tar archive, returning its digest and the
+// digest of each regular file in it.
+func Describe(archive io.Reader) (*Base, error) {
+	archiveHash := sha256.New()
+	tarReader, gzipReader, err := openArchive(io.TeeReader(archive, archiveHash))
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+	base := Base{Files: []File{}}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read base archive")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		h := sha256.New()
+		if _, err = io.Copy(h, tarReader); err != nil {
+			return nil, errors.Wrap(err, "unable to read base archive")
+		}
+		base.Files = append(base.Files, File{Path: cleanPath(header.Name), Digest: hexDigest(h)})
+	}
+	if _, err = io.Copy(io.Discard, archive); err != nil {
+		return nil, err
+	}
+	base.Digest = hexDigest(archiveHash)
+	return &base, nil
+}
+
+// Apply writes to w a gzipped tar archive with every file in the manifest,
+// taking the files present in the patch from it and the remaining ones from
+// the base archive. It returns ErrBaseMismatch when the base archive isn't
+// the one the manifest was generated against.
+func Apply(base io.Reader, manifest Manifest, patch io.Reader, w io.Writer) error {
+	if err := manifest.validate(); err != nil {
+		return err
+	}
+	wanted := make(map[string]string, len(manifest.Files))
+	for _, f := range manifest.Files {
+		wanted[cleanPath(f.Path)] = f.Digest
+	}
+	patchFiles, err := patchedPaths(patch)
+	if err != nil {
+		return err
+	}
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	written := map[string]bool{}
+	baseHash := sha256.New()
+	err = copyEntries(io.TeeReader(base, baseHash), tarWriter, func(name string, header *tar.Header) bool {
+		_, inPatch := patchFiles.entries[name]
+		return header.Typeflag == tar.TypeReg && !inPatch && wanted[name] != ""
+	}, wanted, written)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(io.Discard, base); err != nil {
+		return err
+	}
+	if hexDigest(baseHash) != manifest.Base {
+		return ErrBaseMismatch
+	}
+	if _, err = patchFiles.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	err = copyEntries(patchFiles.file, tarWriter, func(name string, header *tar.Header) bool {
+		return header.Typeflag != tar.TypeReg || wanted[name] != ""
+	}, wanted, written)
+	if err != nil {
+		return err
+	}
+	for name := range wanted {
+		if !written[name] {
+			return errors.Errorf("file %q not found in delta base or patch", name)
+		}
+	}
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// copyEntries copies the entries accepted by include from the gzipped tar
+// archive in r to tarWriter, checking regular files against their digests in
+// wanted.
+func copyEntries(r io.Reader, tarWriter *tar.Writer, include func(string, *tar.Header) bool, wanted map[string]string, written map[string]bool) error {
+	tarReader, gzipReader, err := openArchive(r)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "unable to read archive")
+		}
+		name := cleanPath(header.Name)
+		if !include(name, header) {
+			continue
+		}
+		if err = tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		h := sha256.New()
+		if _, err = io.Copy(io.MultiWriter(tarWriter, h), tarReader); err != nil {
+			return err
+		}
+		if hexDigest(h) != wanted[name] {
+			return errors.Errorf("digest mismatch for file %q", name)
+		}
+		written[name] = true
+	}
+}
+
+type patchIndex struct {
+	file    io.ReadSeeker
+	entries map[string]struct{}
+}
+
+// patchedPaths lists the entries in the patch, which must be seekable as it
+// is read twice.
+func patchedPaths(patch io.Reader) (*patchIndex, error) {
+	seeker, ok := patch.(io.ReadSeeker)
+	if !ok {
+		return nil, errors.New("delta patch must be seekable")
+	}
+	tarReader, gzipReader, err := openArchive(seeker)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid delta patch")
+	}
+	defer gzipReader.Close()
+	idx := &patchIndex{file: seeker, entries: map[string]struct{}{}}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return idx, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid delta patch")
+		}
+		idx.entries[cleanPath(header.Name)] = struct{}{}
+	}
+}
+
+func openArchive(r io.Reader) (*tar.Reader, *gzip.Reader, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "archive must be a gzipped tar")
+	}
+	return tar.NewReader(gzipReader), gzipReader, nil
+}
+
+func cleanPath(name string) string {
+	cleaned := path.Clean("/" + name)
+	return cleaned[1:]
+}
+
+func hexDigest(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
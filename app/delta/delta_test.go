@@ -0,0 +1,152 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package delta
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+type entry struct {
+	name    string
+	content string
+	dir     bool
+}
+
+func buildArchive(c *check.C, entries ...entry) []byte {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		if e.dir {
+			header = &tar.Header{Name: e.name, Mode: 0755, Typeflag: tar.TypeDir}
+		}
+		err := tarWriter.WriteHeader(header)
+		c.Assert(err, check.IsNil)
+		_, err = tarWriter.Write([]byte(e.content))
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(tarWriter.Close(), check.IsNil)
+	c.Assert(gzipWriter.Close(), check.IsNil)
+	return buf.Bytes()
+}
+
+func readArchive(c *check.C, data []byte) map[string]string {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	c.Assert(err, check.IsNil)
+	tarReader := tar.NewReader(gzipReader)
+	files := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files
+		}
+		c.Assert(err, check.IsNil)
+		content, err := io.ReadAll(tarReader)
+		c.Assert(err, check.IsNil)
+		files[header.Name] = string(content)
+	}
+}
+
+func digest(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *S) TestDescribe(c *check.C) {
+	archive := buildArchive(c, entry{name: "app/", dir: true}, entry{name: "./app/main.go", content: "package main"}, entry{name: "Procfile", content: "web: ./app"})
+	base, err := Describe(bytes.NewReader(archive))
+	c.Assert(err, check.IsNil)
+	c.Assert(base, check.DeepEquals, &Base{
+		Digest: digest(string(archive)),
+		Files: []File{
+			{Path: "app/main.go", Digest: digest("package main")},
+			{Path: "Procfile", Digest: digest("web: ./app")},
+		},
+	})
+}
+
+func (s *S) TestApply(c *check.C) {
+	base := buildArchive(c,
+		entry{name: "app/", dir: true},
+		entry{name: "app/main.go", content: "package main"},
+		entry{name: "app/removed.go", content: "package old"},
+		entry{name: "Procfile", content: "web: ./app"},
+	)
+	patch := buildArchive(c,
+		entry{name: "app/", dir: true},
+		entry{name: "app/main.go", content: "package main // changed"},
+		entry{name: "app/new.go", content: "package new"},
+	)
+	manifest := Manifest{
+		Base: digest(string(base)),
+		Files: []File{
+			{Path: "app/main.go", Digest: digest("package main // changed")},
+			{Path: "app/new.go", Digest: digest("package new")},
+			{Path: "./Procfile", Digest: digest("web: ./app")},
+		},
+	}
+	var out bytes.Buffer
+	err := Apply(bytes.NewReader(base), manifest, bytes.NewReader(patch), &out)
+	c.Assert(err, check.IsNil)
+	c.Assert(readArchive(c, out.Bytes()), check.DeepEquals, map[string]string{
+		"app/":        "",
+		"app/main.go": "package main // changed",
+		"app/new.go":  "package new",
+		"Procfile":    "web: ./app",
+	})
+}
+
+func (s *S) TestApplyBaseMismatch(c *check.C) {
+	base := buildArchive(c, entry{name: "Procfile", content: "web: ./app"})
+	patch := buildArchive(c)
+	manifest := Manifest{
+		Base:  digest("other archive"),
+		Files: []File{{Path: "Procfile", Digest: digest("web: ./app")}},
+	}
+	err := Apply(bytes.NewReader(base), manifest, bytes.NewReader(patch), io.Discard)
+	c.Assert(err, check.Equals, ErrBaseMismatch)
+}
+
+func (s *S) TestApplyFileDigestMismatch(c *check.C) {
+	base := buildArchive(c, entry{name: "Procfile", content: "web: ./app"})
+	patch := buildArchive(c)
+	manifest := Manifest{
+		Base:  digest(string(base)),
+		Files: []File{{Path: "Procfile", Digest: digest("web: ./other")}},
+	}
+	err := Apply(bytes.NewReader(base), manifest, bytes.NewReader(patch), io.Discard)
+	c.Assert(err, check.ErrorMatches, `digest mismatch for file "Procfile"`)
+}
+
+func (s *S) TestApplyMissingFile(c *check.C) {
+	base := buildArchive(c, entry{name: "Procfile", content: "web: ./app"})
+	patch := buildArchive(c)
+	manifest := Manifest{
+		Base:  digest(string(base)),
+		Files: []File{{Path: "main.go", Digest: digest("package main")}},
+	}
+	err := Apply(bytes.NewReader(base), manifest, bytes.NewReader(patch), io.Discard)
+	c.Assert(err, check.ErrorMatches, `file "main.go" not found in delta base or patch`)
+}
+
+func (s *S) TestApplyInvalidManifest(c *check.C) {
+	err := Apply(bytes.NewReader(nil), Manifest{}, bytes.NewReader(nil), io.Discard)
+	c.Assert(err, check.ErrorMatches, "delta manifest must have a base digest")
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// Status of each app in a group deploy.
+const (
+	GroupDeploySucceeded = "succeeded"
+	GroupDeployFailed    = "failed"
+	GroupDeployAborted   = "aborted"
+)
+
+var ErrDependencyNotFound = errors.New("dependency not found")
+
+// DependencyCycleError is returned when a dependency would make apps depend on
+// themselves.
+type DependencyCycleError struct {
+	Cycle []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	return fmt.Sprintf("dependency cycle found: %s", strings.Join(e.Cycle, " -> "))
+}
+
+// GroupDeployResult is the outcome of the deploy of an app in a group deploy.
+type GroupDeployResult struct {
+	App    string `json:"app"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AddDependency declares that the app depends on another app, which must be
+// deployed first when both are deployed together.
+func (app *App) AddDependency(ctx context.Context, name string) error {
+	if name == app.Name {
+		return &tsuruErrors.ValidationError{Message: "an app cannot depend on itself"}
+	}
+	for _, dep := range app.Dependencies {
+		if dep == name {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("app %q already depends on %q", app.Name, name)}
+		}
+	}
+	if _, err := GetByName(ctx, name); err != nil {
+		if err == appTypes.ErrAppNotFound {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("app %q not found", name)}
+		}
+		return err
+	}
+	cycle, err := dependencyPath(ctx, name, app.Name)
+	if err != nil {
+		return err
+	}
+	if cycle != nil {
+		return &DependencyCycleError{Cycle: append([]string{app.Name}, cycle...)}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, bson.M{"$addToSet": bson.M{"dependencies": name}})
+	if err != nil {
+		return err
+	}
+	app.Dependencies = append(app.Dependencies, name)
+	return nil
+}
+
+// RemoveDependency removes a dependency previously added to the app.
+func (app *App) RemoveDependency(name string) error {
+	var deps []string
+	for _, dep := range app.Dependencies {
+		if dep != name {
+			deps = append(deps, dep)
+		}
+	}
+	if len(deps) == len(app.Dependencies) {
+		return ErrDependencyNotFound
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, bson.M{"$pull": bson.M{"dependencies": name}})
+	if err != nil {
+		return err
+	}
+	app.Dependencies = deps
+	return nil
+}
+
+// dependencyPath returns the chain of dependencies leading from one app to
+// another, or nil if there is none.
+func dependencyPath(ctx context.Context, from, to string) ([]string, error) {
+	visited := map[string]bool{}
+	var visit func(name string) ([]string, error)
+	visit = func(name string) ([]string, error) {
+		if name == to {
+			return []string{name}, nil
+		}
+		if visited[name] {
+			return nil, nil
+		}
+		visited[name] = true
+		a, err := GetByName(ctx, name)
+		if err == appTypes.ErrAppNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range a.Dependencies {
+			path, err := visit(dep)
+			if err != nil {
+				return nil, err
+			}
+			if path != nil {
+				return append([]string{name}, path...), nil
+			}
+		}
+		return nil, nil
+	}
+	return visit(from)
+}
+
+// DeployOrder sorts the apps so that each app comes after the apps it depends
+// on. Dependencies on apps outside the given list are ignored. Apps without
+// dependencies between them are sorted by name.
+func DeployOrder(apps []*App) ([]*App, error) {
+	byName := make(map[string]*App, len(apps))
+	for _, a := range apps {
+		byName[a.Name] = a
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var path []string
+	var ordered []*App
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			var start int
+			for i := range path {
+				if path[i] == name {
+					start = i
+				}
+			}
+			return &DependencyCycleError{Cycle: append(append([]string{}, path[start:]...), name)}
+		}
+		state[name] = visiting
+		path = append(path, name)
+		deps := append([]string{}, byName[name].Dependencies...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if _, ok := byName[dep]; !ok {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		ordered = append(ordered, byName[name])
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// DeployGroup calls deploy for each app respecting the dependencies between
+// them. When the deploy of an app fails, the deploys of the apps depending on
+// it, directly or not, are aborted; other apps are still deployed.
+func DeployGroup(apps []*App, deploy func(*App) error) ([]GroupDeployResult, error) {
+	ordered, err := DeployOrder(apps)
+	if err != nil {
+		return nil, err
+	}
+	failed := map[string]string{}
+	results := make([]GroupDeployResult, 0, len(ordered))
+	for _, a := range ordered {
+		result := GroupDeployResult{App: a.Name, Status: GroupDeploySucceeded}
+		for _, dep := range a.Dependencies {
+			if _, ok := failed[dep]; ok {
+				result.Status = GroupDeployAborted
+				result.Error = fmt.Sprintf("dependency %q was not deployed", dep)
+				break
+			}
+		}
+		if result.Status == GroupDeploySucceeded {
+			if err = deploy(a); err != nil {
+				result.Status = GroupDeployFailed
+				result.Error = err.Error()
+			}
+		}
+		if result.Status != GroupDeploySucceeded {
+			failed[a.Name] = result.Status
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
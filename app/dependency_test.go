@@ -0,0 +1,131 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"errors"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	check "gopkg.in/check.v1"
+)
+
+func appNames(apps []*App) []string {
+	names := make([]string, len(apps))
+	for i, a := range apps {
+		names[i] = a.Name
+	}
+	return names
+}
+
+func (s *S) TestAddDependency(c *check.C) {
+	api := App{Name: "api", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &api, s.user)
+	c.Assert(err, check.IsNil)
+	worker := App{Name: "worker", Platform: "go", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &worker, s.user)
+	c.Assert(err, check.IsNil)
+	err = worker.AddDependency(context.TODO(), "api")
+	c.Assert(err, check.IsNil)
+	c.Assert(worker.Dependencies, check.DeepEquals, []string{"api"})
+	dbApp, err := GetByName(context.TODO(), "worker")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Dependencies, check.DeepEquals, []string{"api"})
+	err = worker.AddDependency(context.TODO(), "api")
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = worker.AddDependency(context.TODO(), "worker")
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = worker.AddDependency(context.TODO(), "unknown")
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+}
+
+func (s *S) TestAddDependencyCycle(c *check.C) {
+	for _, name := range []string{"a", "b", "c"} {
+		a := App{Name: name, Platform: "go", TeamOwner: s.team.Name}
+		err := CreateApp(context.TODO(), &a, s.user)
+		c.Assert(err, check.IsNil)
+	}
+	a, err := GetByName(context.TODO(), "a")
+	c.Assert(err, check.IsNil)
+	err = a.AddDependency(context.TODO(), "b")
+	c.Assert(err, check.IsNil)
+	b, err := GetByName(context.TODO(), "b")
+	c.Assert(err, check.IsNil)
+	err = b.AddDependency(context.TODO(), "c")
+	c.Assert(err, check.IsNil)
+	cApp, err := GetByName(context.TODO(), "c")
+	c.Assert(err, check.IsNil)
+	err = cApp.AddDependency(context.TODO(), "a")
+	c.Assert(err, check.DeepEquals, &DependencyCycleError{Cycle: []string{"c", "a", "b", "c"}})
+	c.Assert(err, check.ErrorMatches, "dependency cycle found: c -> a -> b -> c")
+}
+
+func (s *S) TestRemoveDependency(c *check.C) {
+	api := App{Name: "api", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &api, s.user)
+	c.Assert(err, check.IsNil)
+	worker := App{Name: "worker", Platform: "go", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &worker, s.user)
+	c.Assert(err, check.IsNil)
+	err = worker.AddDependency(context.TODO(), "api")
+	c.Assert(err, check.IsNil)
+	err = worker.RemoveDependency("api")
+	c.Assert(err, check.IsNil)
+	c.Assert(worker.Dependencies, check.HasLen, 0)
+	dbApp, err := GetByName(context.TODO(), "worker")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Dependencies, check.HasLen, 0)
+	err = worker.RemoveDependency("api")
+	c.Assert(err, check.Equals, ErrDependencyNotFound)
+}
+
+func (s *S) TestDeployOrder(c *check.C) {
+	apps := []*App{
+		{Name: "worker", Dependencies: []string{"api", "db-migrate"}},
+		{Name: "api", Dependencies: []string{"db-migrate", "not-in-group"}},
+		{Name: "db-migrate"},
+		{Name: "frontend", Dependencies: []string{"api"}},
+		{Name: "docs"},
+	}
+	ordered, err := DeployOrder(apps)
+	c.Assert(err, check.IsNil)
+	c.Assert(appNames(ordered), check.DeepEquals, []string{"db-migrate", "api", "docs", "frontend", "worker"})
+}
+
+func (s *S) TestDeployOrderCycle(c *check.C) {
+	apps := []*App{
+		{Name: "a", Dependencies: []string{"b"}},
+		{Name: "b", Dependencies: []string{"a"}},
+	}
+	_, err := DeployOrder(apps)
+	c.Assert(err, check.DeepEquals, &DependencyCycleError{Cycle: []string{"a", "b", "a"}})
+}
+
+func (s *S) TestDeployGroup(c *check.C) {
+	apps := []*App{
+		{Name: "worker", Dependencies: []string{"api"}},
+		{Name: "api", Dependencies: []string{"db-migrate"}},
+		{Name: "db-migrate"},
+		{Name: "docs"},
+		{Name: "frontend", Dependencies: []string{"worker"}},
+	}
+	var deployed []string
+	results, err := DeployGroup(apps, func(a *App) error {
+		deployed = append(deployed, a.Name)
+		if a.Name == "api" {
+			return errors.New("healthcheck failed")
+		}
+		return nil
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(deployed, check.DeepEquals, []string{"db-migrate", "api", "docs"})
+	c.Assert(results, check.DeepEquals, []GroupDeployResult{
+		{App: "db-migrate", Status: GroupDeploySucceeded},
+		{App: "api", Status: GroupDeployFailed, Error: "healthcheck failed"},
+		{App: "docs", Status: GroupDeploySucceeded},
+		{App: "worker", Status: GroupDeployAborted, Error: `dependency "api" was not deployed`},
+		{App: "frontend", Status: GroupDeployAborted, Error: `dependency "worker" was not deployed`},
+	})
+}
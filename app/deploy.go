@@ -16,6 +16,10 @@ import (
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/commitstatus"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/app/imagepolicy"
+	"github.com/tsuru/tsuru/app/pipelinehook"
 	"github.com/tsuru/tsuru/builder"
 	"github.com/tsuru/tsuru/db"
 	"github.com/tsuru/tsuru/event"
@@ -60,6 +64,7 @@ type DeployData struct {
 	CanRollback bool
 	Diff        string
 	Message     string
+	Stages      map[string]time.Duration `json:",omitempty"`
 }
 
 func findValidImages(ctx context.Context, appNames []string) (set.Set, error) {
@@ -162,9 +167,10 @@ func eventToDeployData(evt *event.Event, validImages set.Set, full bool) *Deploy
 			log.Errorf("cannot decode the event's other custom data value: event %s - %v", evt.UniqueID, err)
 		}
 	}
-	var endData map[string]string
+	var endData DeployEndData
 	if err = evt.EndData(&endData); err == nil {
-		data.Image = endData["image"]
+		data.Image = endData.Image
+		data.Stages = endData.Stages
 		if reImageVersion.MatchString(data.Image) {
 			parts := reImageVersion.FindStringSubmatch(data.Image)
 			data.Version, _ = strconv.Atoi(parts[1])
@@ -196,6 +202,7 @@ type DeployOptions struct {
 	Build            bool
 	NewVersion       bool
 	OverrideVersions bool
+	Shadow           int
 }
 
 func (o *DeployOptions) GetOrigin() string {
@@ -316,6 +323,18 @@ func validateVersions(ctx context.Context, opts DeployOptions) error {
 	if opts.NewVersion && opts.OverrideVersions {
 		return errors.New("conflicting deploy flags, new-version and override-old-versions")
 	}
+	if opts.Shadow != 0 {
+		if opts.Shadow < 0 || opts.Shadow > 100 {
+			return errors.New("shadow percent must be between 1 and 100")
+		}
+		supported, err := opts.App.supportsShadow()
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return errShadowNotSupported
+		}
+	}
 	if opts.NewVersion || opts.OverrideVersions {
 		return nil
 	}
@@ -329,14 +348,78 @@ func validateVersions(ctx context.Context, opts DeployOptions) error {
 	return nil
 }
 
+// PlanDeploy returns the changes a deploy with the given options would make
+// to the units and routes of the app, without building images or changing
+// anything in the provisioner.
+func PlanDeploy(ctx context.Context, opts DeployOptions) (*provision.DeployPlan, error) {
+	prov, err := opts.App.getProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	planner, ok := prov.(provision.DeployPlanner)
+	if !ok {
+		return nil, provision.ProvisionerNotSupported{Prov: prov, Action: "deploy plan"}
+	}
+	if opts.Kind == "" {
+		opts.GetKind()
+	}
+	var imageName string
+	if opts.Kind == DeployRollback {
+		version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, opts.App, opts.Image)
+		if err != nil {
+			return nil, err
+		}
+		imageName = version.VersionInfo().DeployImage
+	} else {
+		versions, err := servicemanager.AppVersion.AppVersions(ctx, opts.App)
+		if err != nil && err != appTypes.ErrNoVersionsAvailable {
+			return nil, err
+		}
+		reg, err := opts.App.GetRegistry()
+		if err != nil {
+			return nil, err
+		}
+		baseName, err := image.AppBasicImageName(reg, opts.App.Name)
+		if err != nil {
+			return nil, err
+		}
+		imageName = fmt.Sprintf("%s:v%d", baseName, versions.Count+1)
+	}
+	return planner.PlanDeploy(ctx, opts.App, imageName)
+}
+
 // Deploy runs a deployment of an application. It will first try to run an
 // archive based deploy (if opts.ArchiveURL is not empty), and then fallback to
 // the Git based deployment.
-func Deploy(ctx context.Context, opts DeployOptions) (string, error) {
+func Deploy(ctx context.Context, opts DeployOptions) (imageID string, err error) {
 	if opts.Event == nil {
 		return "", errors.Errorf("missing event in deploy opts")
 	}
-	err := validateVersions(ctx, opts)
+	if opts.App.Archived != nil {
+		return "", ErrAppArchived
+	}
+	statusDeploy := commitstatus.Deploy{
+		App:     opts.App.Name,
+		Teams:   append([]string{opts.App.TeamOwner}, opts.App.Teams...),
+		Commit:  opts.Commit,
+		Origin:  opts.GetOrigin(),
+		EventID: opts.Event.UniqueID.Hex(),
+	}
+	commitstatus.NotifyAsync(statusDeploy, commitstatus.StatePending)
+	defer func() {
+		state := commitstatus.StateSuccess
+		if err != nil {
+			state = commitstatus.StateFailure
+		}
+		commitstatus.NotifyAsync(statusDeploy, state)
+	}()
+	if opts.Shadow > 0 {
+		if opts.OverrideVersions {
+			return "", errors.New("conflicting deploy flags, shadow and override-versions")
+		}
+		opts.NewVersion = true
+	}
+	err = validateVersions(ctx, opts)
 	if err != nil {
 		return "", err
 	}
@@ -344,10 +427,30 @@ func Deploy(ctx context.Context, opts DeployOptions) (string, error) {
 	logWriter.Async()
 	defer logWriter.Close()
 	opts.Event.SetLogWriter(io.MultiWriter(&tsuruIo.NoErrorWriter{Writer: opts.OutputStream}, &logWriter))
-	imageID, err := deployToProvisioner(ctx, &opts, opts.Event)
+	if opts.Kind == "" {
+		opts.GetKind()
+	}
+	previous := autoRollbackTarget(ctx, &opts)
+	imageID, err = deployToProvisioner(ctx, &opts, opts.Event)
+	var unstable bool
+	if err == nil && previous != nil {
+		err = waitStabilization(ctx, &opts, imageID, opts.Event)
+		_, unstable = err.(*ErrDeployUnstable)
+	}
+	if err == nil && opts.Shadow > 0 {
+		err = opts.App.startShadow(ctx, imageID, opts.Shadow, opts.Event)
+	}
+	if err != nil {
+		err = newErrorWithLog(err, opts.App, "deploy")
+		if unstable {
+			// The rollback must run even if the deploy is canceled
+			// meanwhile, so it doesn't use the deploy context.
+			err = autoRollback(context.Background(), &opts, previous, imageID, err, opts.Event)
+		}
+	}
 	rebuild.RoutesRebuildOrEnqueueWithProgress(opts.App.Name, opts.Event)
 	if err != nil {
-		return "", newErrorWithLog(err, opts.App, "deploy")
+		return "", err
 	}
 	err = incrementDeploy(opts.App)
 	if err != nil {
@@ -401,18 +504,59 @@ func deployToProvisioner(ctx context.Context, opts *DeployOptions, evt *event.Ev
 			return "", errors.Errorf("the selected version is disabled for rollback: %s", version.VersionInfo().DisabledReason)
 		}
 	} else {
+		if opts.Kind == DeployImage {
+			opts.Image, err = imagepolicy.VerifyImage(ctx, opts.App.Pool, opts.Image)
+			if err != nil {
+				return "", err
+			}
+		}
+		err = runPipelineHooks(ctx, pipelinehook.StagePreBuild, opts, evt, nil)
+		if err != nil {
+			return "", err
+		}
 		version, err = builderDeploy(ctx, deployer, opts, evt)
 		if err != nil {
 			return "", err
 		}
+		err = runPipelineHooks(ctx, pipelinehook.StagePostBuild, opts, evt, version)
+		if err != nil {
+			return "", err
+		}
 	}
-	return deployer.Deploy(ctx, provision.DeployArgs{
+	imageID, err := deployer.Deploy(ctx, provision.DeployArgs{
 		App:              opts.App,
 		Version:          version,
 		Event:            evt,
 		PreserveVersions: opts.NewVersion,
 		OverrideVersions: opts.OverrideVersions,
 	})
+	if err != nil {
+		return "", err
+	}
+	err = runPipelineHooks(ctx, pipelinehook.StagePostRouteSwap, opts, evt, version)
+	if err != nil {
+		return "", err
+	}
+	return imageID, nil
+}
+
+func runPipelineHooks(ctx context.Context, stage string, opts *DeployOptions, evt *event.Event, version appTypes.AppVersion) error {
+	req := pipelinehook.Request{
+		Stage:   stage,
+		App:     opts.App.Name,
+		Pool:    opts.App.Pool,
+		User:    opts.User,
+		Kind:    string(opts.Kind),
+		EventID: evt.UniqueID.Hex(),
+		Image:   opts.Image,
+	}
+	if version != nil {
+		req.Version = version.Version()
+		if deployImage := version.VersionInfo().DeployImage; deployImage != "" {
+			req.Image = deployImage
+		}
+	}
+	return pipelinehook.Run(ctx, req, evt)
 }
 
 func builderDeploy(ctx context.Context, prov provision.BuilderDeploy, opts *DeployOptions, evt *event.Event) (appTypes.AppVersion, error) {
@@ -493,7 +637,7 @@ func deployDataToEvent(data *DeployData) error {
 	if data.Diff != "" {
 		otherData = map[string]string{"diff": data.Diff}
 	}
-	endData := map[string]string{"image": data.Image}
+	endData := NewDeployEndData(&evt, a, data.Image)
 	err = evt.RawInsert(startOpts, otherData, endData)
 	if mgo.IsDup(err) {
 		return nil
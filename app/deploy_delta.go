@@ -0,0 +1,90 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/tsuru/tsuru/app/delta"
+	"github.com/tsuru/tsuru/builder"
+	"github.com/tsuru/tsuru/servicemanager"
+)
+
+// uploadedArchiveContentType is the content type of artifacts holding the
+// archive uploaded by the client, artifacts from image deploys can't be
+// used as delta base.
+const uploadedArchiveContentType = "application/gzip"
+
+type tempArchive struct {
+	*os.File
+}
+
+func (f *tempArchive) Close() error {
+	f.File.Close()
+	return os.Remove(f.Name())
+}
+
+// deltaBaseArchive returns the archive uploaded in the last successful
+// deploy of the app, it returns builder.ErrArtifactNotFound when the last
+// version wasn't deployed from an uploaded archive.
+func (app *App) deltaBaseArchive(ctx context.Context) (*builder.Artifact, error) {
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	artifact, err := app.versionArtifact(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	if artifact.ContentType != uploadedArchiveContentType {
+		artifact.Close()
+		return nil, builder.ErrArtifactNotFound
+	}
+	return artifact, nil
+}
+
+// DeltaBase describes the archive used by delta deploys of the app, clients
+// send only the files which differ from it.
+func (app *App) DeltaBase(ctx context.Context) (*delta.Base, error) {
+	artifact, err := app.deltaBaseArchive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer artifact.Close()
+	return delta.Describe(artifact)
+}
+
+// ApplyDelta rebuilds the full deploy archive from the delta base of the app
+// and the patch sent by the client. The returned archive is kept in a
+// temporary file, removed when it's closed.
+func (app *App) ApplyDelta(ctx context.Context, manifest delta.Manifest, patch io.Reader) (io.ReadCloser, int64, error) {
+	artifact, err := app.deltaBaseArchive(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer artifact.Close()
+	tmp, err := ioutil.TempFile("", "tsuru-delta-")
+	if err != nil {
+		return nil, 0, err
+	}
+	archive := &tempArchive{File: tmp}
+	err = delta.Apply(artifact, manifest, patch, archive)
+	if err != nil {
+		archive.Close()
+		return nil, 0, err
+	}
+	size, err := archive.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = archive.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		archive.Close()
+		return nil, 0, err
+	}
+	return archive, size, nil
+}
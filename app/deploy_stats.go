@@ -0,0 +1,134 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+)
+
+// DeployEndData is stored as the end custom data of deploy events.
+type DeployEndData struct {
+	Image    string                   `bson:"image"`
+	Pool     string                   `bson:"pool,omitempty"`
+	Platform string                   `bson:"platform,omitempty"`
+	Stages   map[string]time.Duration `bson:"stages,omitempty"`
+}
+
+// NewDeployEndData returns the end custom data for a deploy event, including
+// the durations of the deploy stages recorded in the event.
+func NewDeployEndData(evt *event.Event, app *App, image string) DeployEndData {
+	data := DeployEndData{
+		Image:  image,
+		Stages: evt.StageDurations(),
+	}
+	if app != nil {
+		data.Pool = app.GetPool()
+		data.Platform = app.GetPlatform()
+	}
+	return data
+}
+
+type DeployStatsFilter struct {
+	Pool     string
+	Platform string
+	Since    time.Time
+}
+
+// DeployStageStats holds the percentiles of the duration of a deploy stage
+// in successful deploys of a pool and platform.
+type DeployStageStats struct {
+	Pool     string        `json:"pool"`
+	Platform string        `json:"platform"`
+	Stage    string        `json:"stage"`
+	Count    int           `json:"count"`
+	P50      time.Duration `json:"p50"`
+	P90      time.Duration `json:"p90"`
+	P99      time.Duration `json:"p99"`
+}
+
+// DeployStats aggregates the stage durations of successful deploys matching
+// the filter.
+func DeployStats(filter DeployStatsFilter) ([]DeployStageStats, error) {
+	query := bson.M{
+		"kind.name":            permission.PermAppDeploy.FullName(),
+		"running":              false,
+		"error":                "",
+		"endcustomdata.stages": bson.M{"$exists": true},
+	}
+	if filter.Pool != "" {
+		query["endcustomdata.pool"] = filter.Pool
+	}
+	if filter.Platform != "" {
+		query["endcustomdata.platform"] = filter.Platform
+	}
+	if !filter.Since.IsZero() {
+		query["starttime"] = bson.M{"$gte": filter.Since}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var evts []struct {
+		EndCustomData DeployEndData `bson:"endcustomdata"`
+	}
+	err = conn.Events().Find(query).Select(bson.M{"endcustomdata": 1}).All(&evts)
+	if err != nil {
+		return nil, err
+	}
+	type statsKey struct {
+		pool, platform, stage string
+	}
+	samples := map[statsKey][]time.Duration{}
+	for _, evt := range evts {
+		data := evt.EndCustomData
+		for stage, duration := range data.Stages {
+			key := statsKey{pool: data.Pool, platform: data.Platform, stage: stage}
+			samples[key] = append(samples[key], duration)
+		}
+	}
+	stats := make([]DeployStageStats, 0, len(samples))
+	for key, durations := range samples {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		stats = append(stats, DeployStageStats{
+			Pool:     key.pool,
+			Platform: key.platform,
+			Stage:    key.stage,
+			Count:    len(durations),
+			P50:      percentile(durations, 50),
+			P90:      percentile(durations, 90),
+			P99:      percentile(durations, 99),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Pool != stats[j].Pool {
+			return stats[i].Pool < stats[j].Pool
+		}
+		if stats[i].Platform != stats[j].Platform {
+			return stats[i].Platform < stats[j].Platform
+		}
+		return stats[i].Stage < stats[j].Stage
+	})
+	return stats, nil
+}
+
+// percentile returns the nearest-rank percentile p of the sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
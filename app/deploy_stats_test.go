@@ -0,0 +1,76 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	check "gopkg.in/check.v1"
+)
+
+func insertDeployWithStages(c *check.C, appName string, data DeployEndData, deployErr error) {
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: "app", Value: appName},
+		Kind:     permission.PermAppDeploy,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: "me@tsuru.io"},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.DoneCustomData(deployErr, data)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestDeployStats(c *check.C) {
+	for i := 1; i <= 10; i++ {
+		insertDeployWithStages(c, "myapp", DeployEndData{
+			Pool:     "pool1",
+			Platform: "python",
+			Stages: map[string]time.Duration{
+				"build": time.Duration(i) * time.Second,
+				"start": time.Second,
+			},
+		}, nil)
+	}
+	insertDeployWithStages(c, "otherapp", DeployEndData{
+		Pool:     "pool2",
+		Platform: "go",
+		Stages:   map[string]time.Duration{"pull": 3 * time.Second},
+	}, nil)
+	insertDeployWithStages(c, "otherapp", DeployEndData{
+		Pool:     "pool2",
+		Platform: "go",
+		Stages:   map[string]time.Duration{"pull": time.Hour},
+	}, errors.New("deploy failed"))
+	stats, err := DeployStats(DeployStatsFilter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(stats, check.DeepEquals, []DeployStageStats{
+		{Pool: "pool1", Platform: "python", Stage: "build", Count: 10, P50: 5 * time.Second, P90: 9 * time.Second, P99: 10 * time.Second},
+		{Pool: "pool1", Platform: "python", Stage: "start", Count: 10, P50: time.Second, P90: time.Second, P99: time.Second},
+		{Pool: "pool2", Platform: "go", Stage: "pull", Count: 1, P50: 3 * time.Second, P90: 3 * time.Second, P99: 3 * time.Second},
+	})
+	stats, err = DeployStats(DeployStatsFilter{Platform: "go"})
+	c.Assert(err, check.IsNil)
+	c.Assert(stats, check.HasLen, 1)
+	c.Assert(stats[0].Stage, check.Equals, "pull")
+	stats, err = DeployStats(DeployStatsFilter{Since: time.Now().Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+	c.Assert(stats, check.HasLen, 0)
+}
+
+func (s *S) TestNewDeployEndData(c *check.C) {
+	evt := &event.Event{}
+	evt.StartStage("build")()
+	a := App{Name: "myapp", Pool: "pool1", Platform: "python"}
+	data := NewDeployEndData(evt, &a, "myimg:v1")
+	c.Assert(data.Image, check.Equals, "myimg:v1")
+	c.Assert(data.Pool, check.Equals, "pool1")
+	c.Assert(data.Platform, check.Equals, "python")
+	c.Assert(data.Stages, check.HasLen, 1)
+	data = NewDeployEndData(nil, nil, "myimg:v1")
+	c.Assert(data, check.DeepEquals, DeployEndData{Image: "myimg:v1"})
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision/pool"
+)
+
+const (
+	DeployRequestPending  = "pending"
+	DeployRequestApproved = "approved"
+	DeployRequestRejected = "rejected"
+	DeployRequestExpired  = "expired"
+
+	defaultDeployRequestExpiration = 24 * time.Hour
+)
+
+var (
+	ErrDeployRequestNotFound   = errors.New("deploy request not found")
+	ErrDeployRequestNotPending = errors.New("deploy request is not pending")
+	ErrDeployRequestSelfReview = errors.New("deploy requests must be reviewed by a user other than the requester")
+)
+
+// DeployRequest is a deploy waiting for a second user to approve it before
+// running. Only deploys that can be replayed later, i.e. image, archive URL
+// and rollback deploys, may be requested.
+type DeployRequest struct {
+	ID               bson.ObjectId `bson:"_id" json:"id"`
+	App              string        `json:"app"`
+	User             string        `json:"user"`
+	Kind             DeployKind    `json:"kind"`
+	Image            string        `json:"image,omitempty"`
+	ArchiveURL       string        `json:"archiveURL,omitempty"`
+	Origin           string        `json:"origin,omitempty"`
+	Message          string        `json:"message,omitempty"`
+	NewVersion       bool          `json:"newVersion,omitempty"`
+	OverrideVersions bool          `json:"overrideVersions,omitempty"`
+	Status           string        `json:"status"`
+	CreatedAt        time.Time     `json:"createdAt"`
+	ExpiresAt        time.Time     `json:"expiresAt"`
+	ReviewedBy       string        `json:"reviewedBy,omitempty"`
+	ReviewedAt       time.Time     `json:"reviewedAt,omitempty"`
+	Reason           string        `json:"reason,omitempty"`
+}
+
+func deployRequestsCollection(conn *db.Storage) *storage.Collection {
+	return conn.Collection("deploy_requests")
+}
+
+// RequiresDeployApproval returns whether deploys to the app must be approved
+// by a second user, either because of the app policy or because its pool
+// requires it.
+func (app *App) RequiresDeployApproval(ctx context.Context) (bool, error) {
+	if app.DeployApproval {
+		return true, nil
+	}
+	p, err := pool.GetPoolByName(ctx, app.Pool)
+	if err != nil {
+		if err == pool.ErrPoolNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return p.RequiresDeployApproval(), nil
+}
+
+// SetDeployApproval stores whether deploys to the app must be approved by a
+// second user, regardless of its pool policy.
+func (app *App) SetDeployApproval(required bool) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, bson.M{"$set": bson.M{"deployapproval": required}})
+	if err != nil {
+		return err
+	}
+	app.DeployApproval = required
+	return nil
+}
+
+func deployRequestExpiration() time.Duration {
+	if value, err := config.GetDuration("deploy-approval:expiration"); err == nil && value > 0 {
+		return value
+	}
+	return defaultDeployRequestExpiration
+}
+
+// CreateDeployRequest stores a pending request for the deploy described by
+// opts.
+func CreateDeployRequest(opts DeployOptions) (*DeployRequest, error) {
+	kind := opts.GetKind()
+	switch kind {
+	case DeployImage, DeployArchiveURL, DeployRollback:
+	default:
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("deploys of kind %q cannot be approved, deploy an image or an archive URL instead", kind)}
+	}
+	if kind == DeployArchiveURL && opts.ArchiveURL == "" {
+		return nil, &tsuruErrors.ValidationError{Message: "you must provide the archive URL"}
+	}
+	now := time.Now().UTC()
+	req := DeployRequest{
+		ID:               bson.NewObjectId(),
+		App:              opts.App.Name,
+		User:             opts.User,
+		Kind:             kind,
+		Image:            opts.Image,
+		ArchiveURL:       opts.ArchiveURL,
+		Origin:           opts.Origin,
+		Message:          opts.Message,
+		NewVersion:       opts.NewVersion,
+		OverrideVersions: opts.OverrideVersions,
+		Status:           DeployRequestPending,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(deployRequestExpiration()),
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	err = deployRequestsCollection(conn).Insert(req)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// expireDeployRequests marks as expired the pending requests of the app that
+// were not reviewed in time.
+func expireDeployRequests(conn *db.Storage, appName string) error {
+	_, err := deployRequestsCollection(conn).UpdateAll(bson.M{
+		"app":       appName,
+		"status":    DeployRequestPending,
+		"expiresat": bson.M{"$lte": time.Now().UTC()},
+	}, bson.M{"$set": bson.M{"status": DeployRequestExpired}})
+	return err
+}
+
+// ListDeployRequests returns the deploy requests of the app, newest first.
+func ListDeployRequests(appName string) ([]DeployRequest, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = expireDeployRequests(conn, appName); err != nil {
+		return nil, err
+	}
+	var requests []DeployRequest
+	err = deployRequestsCollection(conn).Find(bson.M{"app": appName}).Sort("-createdat").All(&requests)
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// GetDeployRequest returns a deploy request of the app by its ID.
+func GetDeployRequest(appName, id string) (*DeployRequest, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, ErrDeployRequestNotFound
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = expireDeployRequests(conn, appName); err != nil {
+		return nil, err
+	}
+	var req DeployRequest
+	err = deployRequestsCollection(conn).Find(bson.M{"_id": bson.ObjectIdHex(id), "app": appName}).One(&req)
+	if err == mgo.ErrNotFound {
+		return nil, ErrDeployRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// Approve marks the request as approved by the given user. Requests can't be
+// approved by their own requester, nor after they expire.
+func (r *DeployRequest) Approve(user string) error {
+	return r.review(user, DeployRequestApproved, "")
+}
+
+// Reject marks the request as rejected by the given user.
+func (r *DeployRequest) Reject(user, reason string) error {
+	return r.review(user, DeployRequestRejected, reason)
+}
+
+func (r *DeployRequest) review(user, status, reason string) error {
+	if user == r.User {
+		return ErrDeployRequestSelfReview
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	now := time.Now().UTC()
+	err = deployRequestsCollection(conn).Update(bson.M{
+		"_id":       r.ID,
+		"status":    DeployRequestPending,
+		"expiresat": bson.M{"$gt": now},
+	}, bson.M{"$set": bson.M{
+		"status":     status,
+		"reviewedby": user,
+		"reviewedat": now,
+		"reason":     reason,
+	}})
+	if err == mgo.ErrNotFound {
+		return ErrDeployRequestNotPending
+	}
+	if err != nil {
+		return err
+	}
+	r.Status = status
+	r.ReviewedBy = user
+	r.ReviewedAt = now
+	r.Reason = reason
+	return nil
+}
+
+// DeployOptions returns the options for running the requested deploy.
+func (r *DeployRequest) DeployOptions(a *App) DeployOptions {
+	return DeployOptions{
+		App:              a,
+		User:             r.User,
+		Image:            r.Image,
+		ArchiveURL:       r.ArchiveURL,
+		Origin:           r.Origin,
+		Message:          r.Message,
+		Rollback:         r.Kind == DeployRollback,
+		NewVersion:       r.NewVersion,
+		OverrideVersions: r.OverrideVersions,
+	}
+}
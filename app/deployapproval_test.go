@@ -0,0 +1,86 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/provision/pool"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestRequiresDeployApproval(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{
+		Name:   "prod",
+		Public: true,
+		Labels: map[string]string{"deploy-approval": "true"},
+	})
+	c.Assert(err, check.IsNil)
+	a := App{Name: "myapp", Pool: s.Pool}
+	required, err := a.RequiresDeployApproval(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(required, check.Equals, false)
+	a.DeployApproval = true
+	required, err = a.RequiresDeployApproval(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(required, check.Equals, true)
+	a = App{Name: "myapp", Pool: "prod"}
+	required, err = a.RequiresDeployApproval(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(required, check.Equals, true)
+}
+
+func (s *S) TestCreateDeployRequestInvalidKind(c *check.C) {
+	a := App{Name: "myapp"}
+	_, err := CreateDeployRequest(DeployOptions{App: &a, Commit: "abc123", User: "someone"})
+	c.Assert(err, check.ErrorMatches, `deploys of kind "git" cannot be approved.*`)
+}
+
+func (s *S) TestDeployRequestApprove(c *check.C) {
+	a := App{Name: "myapp"}
+	req, err := CreateDeployRequest(DeployOptions{App: &a, Image: "tsuru/myapp", User: "requester", Message: "release"})
+	c.Assert(err, check.IsNil)
+	c.Assert(req.Kind, check.Equals, DeployImage)
+	c.Assert(req.Status, check.Equals, DeployRequestPending)
+	err = req.Approve("requester")
+	c.Assert(err, check.Equals, ErrDeployRequestSelfReview)
+	err = req.Approve("reviewer")
+	c.Assert(err, check.IsNil)
+	dbReq, err := GetDeployRequest(a.Name, req.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(dbReq.Status, check.Equals, DeployRequestApproved)
+	c.Assert(dbReq.ReviewedBy, check.Equals, "reviewer")
+	err = dbReq.Reject("other", "too late")
+	c.Assert(err, check.Equals, ErrDeployRequestNotPending)
+	opts := dbReq.DeployOptions(&a)
+	c.Assert(opts.Image, check.Equals, "tsuru/myapp")
+	c.Assert(opts.User, check.Equals, "requester")
+	c.Assert(opts.Message, check.Equals, "release")
+}
+
+func (s *S) TestDeployRequestExpiration(c *check.C) {
+	config.Set("deploy-approval:expiration", time.Millisecond)
+	defer config.Unset("deploy-approval:expiration")
+	a := App{Name: "myapp"}
+	req, err := CreateDeployRequest(DeployOptions{App: &a, Image: "tsuru/myapp", User: "requester"})
+	c.Assert(err, check.IsNil)
+	time.Sleep(10 * time.Millisecond)
+	err = req.Approve("reviewer")
+	c.Assert(err, check.Equals, ErrDeployRequestNotPending)
+	requests, err := ListDeployRequests(a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(requests, check.HasLen, 1)
+	c.Assert(requests[0].Status, check.Equals, DeployRequestExpired)
+}
+
+func (s *S) TestGetDeployRequestNotFound(c *check.C) {
+	_, err := GetDeployRequest("myapp", "invalid")
+	c.Assert(err, check.Equals, ErrDeployRequestNotFound)
+	_, err = GetDeployRequest("myapp", bson.NewObjectId().Hex())
+	c.Assert(err, check.Equals, ErrDeployRequestNotFound)
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/net"
+)
+
+const (
+	maxDeployGates          = 10
+	maxDeployGateReasonSize = 4096
+)
+
+// deployGateTimeout is the time limit of each gate check.
+var deployGateTimeout = 10 * time.Second
+
+// DeployGate is an external check called before deploys of the app start,
+// like an error budget service or a change-freeze calendar. Deploys are
+// blocked unless the check answers with 200.
+type DeployGate struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// DeployGateCheck is the payload posted to deploy gates.
+type DeployGateCheck struct {
+	App    string `json:"app"`
+	Pool   string `json:"pool"`
+	User   string `json:"user"`
+	Kind   string `json:"kind"`
+	Origin string `json:"origin,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	Image  string `json:"image,omitempty"`
+}
+
+// ErrDeployBlocked is returned when a deploy gate blocks the deploy.
+type ErrDeployBlocked struct {
+	Gate   string
+	Reason string
+}
+
+func (e *ErrDeployBlocked) Error() string {
+	return fmt.Sprintf("deploy blocked by gate %q: %s", e.Gate, e.Reason)
+}
+
+// SetDeployGates replaces the deploy gates of the app.
+func (app *App) SetDeployGates(gates []DeployGate) error {
+	if len(gates) > maxDeployGates {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("apps can have at most %d deploy gates", maxDeployGates)}
+	}
+	names := map[string]struct{}{}
+	for _, g := range gates {
+		if g.Name == "" {
+			return &tsuruErrors.ValidationError{Message: "deploy gate name is required"}
+		}
+		if _, ok := names[g.Name]; ok {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("duplicated deploy gate %q", g.Name)}
+		}
+		names[g.Name] = struct{}{}
+		if !strings.HasPrefix(g.URL, "http://") && !strings.HasPrefix(g.URL, "https://") {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid url for deploy gate %q", g.Name)}
+		}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": app.Name}, bson.M{"$set": bson.M{"deploygates": gates}})
+	if err != nil {
+		return err
+	}
+	app.DeployGates = gates
+	return nil
+}
+
+// CheckDeployGates calls the deploy gates of the app, in order, returning an
+// *ErrDeployBlocked for the first gate not answering with 200. Gates which
+// can't be reached also block the deploy.
+func CheckDeployGates(ctx context.Context, opts DeployOptions) error {
+	if len(opts.App.DeployGates) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(DeployGateCheck{
+		App:    opts.App.Name,
+		Pool:   opts.App.Pool,
+		User:   opts.User,
+		Kind:   string(opts.GetKind()),
+		Origin: opts.GetOrigin(),
+		Commit: opts.Commit,
+		Image:  opts.Image,
+	})
+	if err != nil {
+		return err
+	}
+	for _, gate := range opts.App.DeployGates {
+		reason, err := callDeployGate(ctx, gate, body)
+		if err != nil {
+			return &ErrDeployBlocked{Gate: gate.Name, Reason: fmt.Sprintf("unable to check gate: %v", err)}
+		}
+		if reason != "" {
+			return &ErrDeployBlocked{Gate: gate.Name, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// callDeployGate returns the reason returned by the gate when it blocks the
+// deploy, or an empty string when the deploy is allowed.
+func callDeployGate(ctx context.Context, gate DeployGate, body []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, deployGateTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, gate.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := net.Dial15Full60ClientNoKeepAlive.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	data, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, maxDeployGateReasonSize))
+	if rsp.StatusCode == http.StatusOK {
+		return "", nil
+	}
+	reason := strings.TrimSpace(string(data))
+	if reason == "" {
+		reason = fmt.Sprintf("status code %d", rsp.StatusCode)
+	}
+	return reason, nil
+}
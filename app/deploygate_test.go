@@ -0,0 +1,65 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetDeployGates(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetDeployGates([]DeployGate{{Name: "budget", URL: "ftp://budget"}})
+	c.Assert(err, check.ErrorMatches, `invalid url for deploy gate "budget"`)
+	err = a.SetDeployGates([]DeployGate{{Name: "budget", URL: "http://a"}, {Name: "budget", URL: "http://b"}})
+	c.Assert(err, check.ErrorMatches, `duplicated deploy gate "budget"`)
+	gates := []DeployGate{{Name: "budget", URL: "http://budget/check"}, {Name: "freeze", URL: "https://calendar/freeze"}}
+	err = a.SetDeployGates(gates)
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeployGates, check.DeepEquals, gates)
+	err = a.SetDeployGates(nil)
+	c.Assert(err, check.IsNil)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.DeployGates, check.HasLen, 0)
+}
+
+func (s *S) TestCheckDeployGates(c *check.C) {
+	var checks []DeployGateCheck
+	allow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload DeployGateCheck
+		json.NewDecoder(r.Body).Decode(&payload)
+		checks = append(checks, payload)
+	}))
+	defer allow.Close()
+	deny := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusLocked)
+		w.Write([]byte("error budget exhausted\n"))
+	}))
+	defer deny.Close()
+	a := App{Name: "myapp", Pool: "pool1", DeployGates: []DeployGate{{Name: "freeze", URL: allow.URL}}}
+	opts := DeployOptions{App: &a, User: "me@example.com", Image: "myimage:v2"}
+	err := CheckDeployGates(context.TODO(), opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(checks, check.DeepEquals, []DeployGateCheck{
+		{App: "myapp", Pool: "pool1", User: "me@example.com", Kind: "image", Image: "myimage:v2"},
+	})
+	a.DeployGates = append(a.DeployGates, DeployGate{Name: "budget", URL: deny.URL})
+	err = CheckDeployGates(context.TODO(), opts)
+	c.Assert(err, check.DeepEquals, &ErrDeployBlocked{Gate: "budget", Reason: "error budget exhausted"})
+	c.Assert(err, check.ErrorMatches, `deploy blocked by gate "budget": error budget exhausted`)
+	a.DeployGates = []DeployGate{{Name: "down", URL: "http://127.0.0.1:1"}}
+	err = CheckDeployGates(context.TODO(), opts)
+	c.Assert(err, check.FitsTypeOf, &ErrDeployBlocked{})
+	c.Assert(err, check.ErrorMatches, `deploy blocked by gate "down": unable to check gate: .*`)
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package deployhook implements deploy hooks, which allow source code
+// hosting providers and image registries to trigger app deploys directly
+// through webhooks, without an external CI runner calling the tsuru API.
+package deployhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+var ErrHookNotFound = errors.New("deploy hook not found")
+
+// Hook is the deploy hook of an app. Pushes to branches matching Branches
+// trigger archive deploys of the pushed commit, and images pushed with tags
+// matching Tags trigger image deploys. Deploys run on behalf of User, so the
+// hook stops working when the user loses permission to deploy the app.
+type Hook struct {
+	App       string    `json:"app" bson:"_id" form:"-"`
+	Secret    string    `json:"secret,omitempty" form:"secret"`
+	User      string    `json:"user" form:"-"`
+	Branches  []string  `json:"branches,omitempty" form:"branches"`
+	Tags      []string  `json:"tags,omitempty" form:"tags"`
+	UpdatedAt time.Time `json:"updatedAt" form:"-"`
+}
+
+func hooksCollection(conn *db.Storage) *storage.Collection {
+	return conn.Collection("app_deploy_hooks")
+}
+
+func (h *Hook) validate() error {
+	if len(h.Branches) == 0 && len(h.Tags) == 0 {
+		return &tsuruErrors.ValidationError{Message: "deploy hook must have at least one branch or tag pattern"}
+	}
+	for _, p := range append(append([]string{}, h.Branches...), h.Tags...) {
+		if _, err := path.Match(p, ""); err != nil || p == "" {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid pattern %q", p)}
+		}
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	data := make([]byte, 24)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// Set creates or replaces the deploy hook of the app. An empty secret keeps
+// the current one, or generates a new one when the app has no hook yet.
+func Set(h *Hook) error {
+	if err := h.validate(); err != nil {
+		return err
+	}
+	if h.Secret == "" {
+		current, err := Get(h.App)
+		switch err {
+		case nil:
+			h.Secret = current.Secret
+		case ErrHookNotFound:
+			h.Secret, err = generateSecret()
+			if err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+	h.UpdatedAt = time.Now().UTC()
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = hooksCollection(conn).UpsertId(h.App, h)
+	return err
+}
+
+// Get returns the deploy hook of the app.
+func Get(appName string) (*Hook, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var h Hook
+	err = hooksCollection(conn).FindId(appName).One(&h)
+	if err == mgo.ErrNotFound {
+		return nil, ErrHookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// Remove removes the deploy hook of the app.
+func Remove(appName string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = hooksCollection(conn).RemoveId(appName)
+	if err == mgo.ErrNotFound {
+		return ErrHookNotFound
+	}
+	return err
+}
+
+// Match returns whether the push must trigger a deploy.
+func (h *Hook) Match(p *Push) bool {
+	patterns, value := h.Branches, p.Branch
+	if p.Image != "" {
+		patterns, value = h.Tags, p.Tag
+	}
+	if value == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package deployhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+type StorageSuite struct{}
+
+var _ = check.Suite(&StorageSuite{})
+
+func (s *StorageSuite) SetUpTest(c *check.C) {
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "tsuru_deployhook_tests")
+	conn, err := db.Conn()
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+	err = dbtest.ClearAllCollections(conn.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *StorageSuite) TestSetGeneratesAndKeepsSecret(c *check.C) {
+	h := Hook{App: "myapp", User: "me@example.com", Branches: []string{"main"}}
+	err := Set(&h)
+	c.Assert(err, check.IsNil)
+	c.Assert(h.Secret, check.HasLen, 48)
+	secret := h.Secret
+	h = Hook{App: "myapp", User: "other@example.com", Tags: []string{"v*"}}
+	err = Set(&h)
+	c.Assert(err, check.IsNil)
+	dbHook, err := Get("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbHook.Secret, check.Equals, secret)
+	c.Assert(dbHook.User, check.Equals, "other@example.com")
+	c.Assert(dbHook.Branches, check.IsNil)
+	c.Assert(dbHook.Tags, check.DeepEquals, []string{"v*"})
+}
+
+func (s *StorageSuite) TestSetInvalid(c *check.C) {
+	err := Set(&Hook{App: "myapp"})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = Set(&Hook{App: "myapp", Branches: []string{"[main"}})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+}
+
+func (s *StorageSuite) TestRemove(c *check.C) {
+	err := Set(&Hook{App: "myapp", Branches: []string{"main"}})
+	c.Assert(err, check.IsNil)
+	err = Remove("myapp")
+	c.Assert(err, check.IsNil)
+	_, err = Get("myapp")
+	c.Assert(err, check.Equals, ErrHookNotFound)
+	err = Remove("myapp")
+	c.Assert(err, check.Equals, ErrHookNotFound)
+}
+
+func (s *S) TestVerifyGitHubSignature(c *check.C) {
+	h := Hook{Secret: "s3cr3t"}
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write(body)
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	c.Assert(h.Verify(header, body), check.IsNil)
+	c.Assert(h.Verify(header, []byte(`{}`)), check.Equals, ErrInvalidSecret)
+}
+
+func (s *S) TestVerifySecret(c *check.C) {
+	h := Hook{Secret: "s3cr3t"}
+	header := http.Header{}
+	header.Set("X-Gitlab-Token", "s3cr3t")
+	c.Assert(h.Verify(header, nil), check.IsNil)
+	header = http.Header{}
+	header.Set(SecretHeader, "s3cr3t")
+	c.Assert(h.Verify(header, nil), check.IsNil)
+	header.Set(SecretHeader, "wrong")
+	c.Assert(h.Verify(header, nil), check.Equals, ErrInvalidSecret)
+	c.Assert(h.Verify(http.Header{}, nil), check.Equals, ErrInvalidSecret)
+}
+
+func (s *S) TestParseGitHubPush(c *check.C) {
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	body := `{"ref":"refs/heads/main","after":"abc123","head_commit":{"message":"fix"},"repository":{"html_url":"https://github.com/org/repo"}}`
+	p, err := ParsePush(header, []byte(body))
+	c.Assert(err, check.IsNil)
+	c.Assert(p, check.DeepEquals, &Push{
+		Source:     SourceGitHub,
+		Branch:     "main",
+		Commit:     "abc123",
+		Message:    "fix",
+		ArchiveURL: "https://github.com/org/repo/archive/abc123.tar.gz",
+	})
+	header.Set("X-GitHub-Event", "ping")
+	p, err = ParsePush(header, []byte(`{}`))
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Ping, check.Equals, true)
+	header.Set("X-GitHub-Event", "issues")
+	_, err = ParsePush(header, []byte(`{}`))
+	c.Assert(err, check.Equals, ErrUnsupportedEvent)
+}
+
+func (s *S) TestParseGitHubPushDeletedBranch(c *check.C) {
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "push")
+	body := `{"ref":"refs/heads/main","after":"0000000000000000000000000000000000000000","deleted":true}`
+	p, err := ParsePush(header, []byte(body))
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Branch, check.Equals, "")
+	c.Assert(p.ArchiveURL, check.Equals, "")
+}
+
+func (s *S) TestParseGitLabPush(c *check.C) {
+	header := http.Header{}
+	header.Set("X-Gitlab-Event", "Push Hook")
+	body := `{"ref":"refs/heads/develop","checkout_sha":"def456","project":{"web_url":"https://gitlab.com/group/repo"},"commits":[{"id":"aaa","message":"old"},{"id":"def456","message":"new"}]}`
+	p, err := ParsePush(header, []byte(body))
+	c.Assert(err, check.IsNil)
+	c.Assert(p, check.DeepEquals, &Push{
+		Source:     SourceGitLab,
+		Branch:     "develop",
+		Commit:     "def456",
+		Message:    "new",
+		ArchiveURL: "https://gitlab.com/group/repo/-/archive/def456/repo-def456.tar.gz",
+	})
+}
+
+func (s *S) TestParseRegistryPush(c *check.C) {
+	p, err := ParsePush(http.Header{}, []byte(`{"push_data":{"tag":"v1.2"},"repository":{"repo_name":"org/app"}}`))
+	c.Assert(err, check.IsNil)
+	c.Assert(p, check.DeepEquals, &Push{Source: SourceRegistry, Image: "org/app:v1.2", Tag: "v1.2"})
+	p, err = ParsePush(http.Header{}, []byte(`{"type":"PUSH_ARTIFACT","event_data":{"resources":[{"tag":"v2","resource_url":"harbor.example.com/lib/app:v2"}]}}`))
+	c.Assert(err, check.IsNil)
+	c.Assert(p, check.DeepEquals, &Push{Source: SourceRegistry, Image: "harbor.example.com/lib/app:v2", Tag: "v2"})
+	_, err = ParsePush(http.Header{}, []byte(`{}`))
+	c.Assert(err, check.Equals, ErrUnsupportedEvent)
+}
+
+func (s *S) TestMatch(c *check.C) {
+	h := Hook{Branches: []string{"main", "release-*"}, Tags: []string{"v*"}}
+	c.Assert(h.Match(&Push{Branch: "main"}), check.Equals, true)
+	c.Assert(h.Match(&Push{Branch: "release-1.0"}), check.Equals, true)
+	c.Assert(h.Match(&Push{Branch: "feature"}), check.Equals, false)
+	c.Assert(h.Match(&Push{}), check.Equals, false)
+	c.Assert(h.Match(&Push{Image: "org/app:v1", Tag: "v1"}), check.Equals, true)
+	c.Assert(h.Match(&Push{Image: "org/app:latest", Tag: "latest"}), check.Equals, false)
+}
+
+func (s *S) TestParsePullRequest(c *check.C) {
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "pull_request")
+	pr, err := ParsePullRequest(header, []byte(`{"action":"closed","number":42}`))
+	c.Assert(err, check.IsNil)
+	c.Assert(pr, check.DeepEquals, &PullRequest{Source: SourceGitHub, Number: "42", Closed: true})
+	pr, err = ParsePullRequest(header, []byte(`{"action":"synchronize","number":42}`))
+	c.Assert(err, check.IsNil)
+	c.Assert(pr.Closed, check.Equals, false)
+	header = http.Header{}
+	header.Set("X-Gitlab-Event", "Merge Request Hook")
+	pr, err = ParsePullRequest(header, []byte(`{"object_attributes":{"iid":7,"action":"merge"}}`))
+	c.Assert(err, check.IsNil)
+	c.Assert(pr, check.DeepEquals, &PullRequest{Source: SourceGitLab, Number: "7", Closed: true})
+	header.Set("X-Gitlab-Event", "Push Hook")
+	_, err = ParsePullRequest(header, []byte(`{}`))
+	c.Assert(err, check.Equals, ErrUnsupportedEvent)
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package deployhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// PullRequest is a pull request (or GitLab merge request) notification.
+type PullRequest struct {
+	Source string
+	Ping   bool
+	Number string
+	Closed bool
+}
+
+// ParsePullRequest parses GitHub pull_request and GitLab merge request
+// webhook payloads. Closed is true when the pull request was closed or
+// merged.
+func ParsePullRequest(header http.Header, body []byte) (*PullRequest, error) {
+	switch header.Get("X-GitHub-Event") {
+	case "ping":
+		return &PullRequest{Source: SourceGitHub, Ping: true}, nil
+	case "pull_request":
+		var payload struct {
+			Action string `json:"action"`
+			Number int    `json:"number"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, errors.Wrap(err, "invalid github pull request payload")
+		}
+		return &PullRequest{
+			Source: SourceGitHub,
+			Number: strconv.Itoa(payload.Number),
+			Closed: payload.Action == "closed",
+		}, nil
+	}
+	if header.Get("X-Gitlab-Event") == "Merge Request Hook" {
+		var payload struct {
+			ObjectAttributes struct {
+				IID    int    `json:"iid"`
+				Action string `json:"action"`
+			} `json:"object_attributes"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, errors.Wrap(err, "invalid gitlab merge request payload")
+		}
+		action := payload.ObjectAttributes.Action
+		return &PullRequest{
+			Source: SourceGitLab,
+			Number: strconv.Itoa(payload.ObjectAttributes.IID),
+			Closed: action == "close" || action == "merge",
+		}, nil
+	}
+	return nil, ErrUnsupportedEvent
+}
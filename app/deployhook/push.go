@@ -0,0 +1,196 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package deployhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SourceGitHub   = "github"
+	SourceGitLab   = "gitlab"
+	SourceRegistry = "registry"
+
+	SecretHeader = "X-Tsuru-Hook-Secret"
+
+	branchRefPrefix = "refs/heads/"
+	emptyCommit     = "0000000000000000000000000000000000000000"
+)
+
+var (
+	ErrInvalidSecret    = errors.New("invalid deploy hook secret or signature")
+	ErrUnsupportedEvent = errors.New("unsupported webhook event")
+)
+
+// Push is a push notification received from a source code hosting provider
+// or an image registry. Git pushes carry Branch, Commit and ArchiveURL,
+// while registry pushes carry Image and Tag.
+type Push struct {
+	Source     string
+	Ping       bool
+	Branch     string
+	Commit     string
+	Message    string
+	ArchiveURL string
+	Image      string
+	Tag        string
+}
+
+// Verify checks that the request was sent by someone who knows the hook
+// secret. GitHub requests are signed with HMAC-SHA256, GitLab sends the
+// secret in the X-Gitlab-Token header and registries must send it in the
+// X-Tsuru-Hook-Secret header. The secret is never accepted in the URL, which
+// ends up in access and proxy logs.
+func (h *Hook) Verify(header http.Header, body []byte) error {
+	if h.Secret == "" {
+		return ErrInvalidSecret
+	}
+	if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+		mac := hmac.New(sha256.New, []byte(h.Secret))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if !hmac.Equal([]byte(signature), []byte(expected)) {
+			return ErrInvalidSecret
+		}
+		return nil
+	}
+	secret := header.Get("X-Gitlab-Token")
+	if secret == "" {
+		secret = header.Get(SecretHeader)
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(h.Secret)) != 1 {
+		return ErrInvalidSecret
+	}
+	return nil
+}
+
+// ParsePush parses the webhook payload sent by GitHub, GitLab, Docker Hub
+// or Harbor.
+func ParsePush(header http.Header, body []byte) (*Push, error) {
+	if evt := header.Get("X-GitHub-Event"); evt != "" {
+		switch evt {
+		case "ping":
+			return &Push{Source: SourceGitHub, Ping: true}, nil
+		case "push":
+			return parseGitHubPush(body)
+		}
+		return nil, ErrUnsupportedEvent
+	}
+	if evt := header.Get("X-Gitlab-Event"); evt != "" {
+		if evt == "Push Hook" {
+			return parseGitLabPush(body)
+		}
+		return nil, ErrUnsupportedEvent
+	}
+	return parseRegistryPush(body)
+}
+
+func branchFromRef(ref string) string {
+	if !strings.HasPrefix(ref, branchRefPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(ref, branchRefPrefix)
+}
+
+func parseGitHubPush(body []byte) (*Push, error) {
+	var payload struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Deleted    bool   `json:"deleted"`
+		HeadCommit struct {
+			Message string `json:"message"`
+		} `json:"head_commit"`
+		Repository struct {
+			HTMLURL string `json:"html_url"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.Wrap(err, "invalid github push payload")
+	}
+	p := &Push{Source: SourceGitHub}
+	if payload.Deleted || payload.After == "" || payload.After == emptyCommit {
+		return p, nil
+	}
+	p.Branch = branchFromRef(payload.Ref)
+	p.Commit = payload.After
+	p.Message = payload.HeadCommit.Message
+	p.ArchiveURL = strings.TrimSuffix(payload.Repository.HTMLURL, "/") + "/archive/" + payload.After + ".tar.gz"
+	return p, nil
+}
+
+func parseGitLabPush(body []byte) (*Push, error) {
+	var payload struct {
+		Ref         string `json:"ref"`
+		CheckoutSHA string `json:"checkout_sha"`
+		Project     struct {
+			WebURL string `json:"web_url"`
+		} `json:"project"`
+		Commits []struct {
+			ID      string `json:"id"`
+			Message string `json:"message"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.Wrap(err, "invalid gitlab push payload")
+	}
+	p := &Push{Source: SourceGitLab}
+	if payload.CheckoutSHA == "" || payload.CheckoutSHA == emptyCommit {
+		return p, nil
+	}
+	for _, c := range payload.Commits {
+		if c.ID == payload.CheckoutSHA {
+			p.Message = c.Message
+		}
+	}
+	webURL := strings.TrimSuffix(payload.Project.WebURL, "/")
+	p.Branch = branchFromRef(payload.Ref)
+	p.Commit = payload.CheckoutSHA
+	p.ArchiveURL = webURL + "/-/archive/" + p.Commit + "/" + path.Base(webURL) + "-" + p.Commit + ".tar.gz"
+	return p, nil
+}
+
+func parseRegistryPush(body []byte) (*Push, error) {
+	var payload struct {
+		PushData struct {
+			Tag string `json:"tag"`
+		} `json:"push_data"`
+		Repository struct {
+			RepoName string `json:"repo_name"`
+		} `json:"repository"`
+		Type      string `json:"type"`
+		EventData struct {
+			Resources []struct {
+				Tag         string `json:"tag"`
+				ResourceURL string `json:"resource_url"`
+			} `json:"resources"`
+		} `json:"event_data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.Wrap(err, "invalid registry push payload")
+	}
+	if payload.Repository.RepoName != "" && payload.PushData.Tag != "" {
+		return &Push{
+			Source: SourceRegistry,
+			Image:  payload.Repository.RepoName + ":" + payload.PushData.Tag,
+			Tag:    payload.PushData.Tag,
+		}, nil
+	}
+	if payload.Type == "PUSH_ARTIFACT" && len(payload.EventData.Resources) > 0 {
+		resource := payload.EventData.Resources[0]
+		if resource.ResourceURL != "" && resource.Tag != "" {
+			return &Push{Source: SourceRegistry, Image: resource.ResourceURL, Tag: resource.Tag}, nil
+		}
+	}
+	return nil, ErrUnsupportedEvent
+}
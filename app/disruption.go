@@ -0,0 +1,51 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// SetDisruptionSettings replaces the rollout and disruption budget settings
+// of the app. They're applied by the provisioner on the next deploy or
+// restart of the app.
+func (app *App) SetDisruptionSettings(settings appTypes.DisruptionSettings) error {
+	err := settings.Validate()
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"$set": bson.M{"disruption": settings}}
+	if settings.IsEmpty() {
+		update = bson.M{"$unset": bson.M{"disruption": ""}}
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.Disruption = settings
+	return nil
+}
+
+// EffectiveDisruptionSettings returns the rollout and disruption budget
+// settings in effect for the app units.
+func (app *App) EffectiveDisruptionSettings() (provision.EffectiveDisruptionSettings, error) {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return provision.EffectiveDisruptionSettings{}, err
+	}
+	disruptionProv, ok := prov.(provision.DisruptionProvisioner)
+	if !ok {
+		return provision.EffectiveDisruptionSettings{}, provision.ProvisionerNotSupported{Prov: prov, Action: "disruption settings"}
+	}
+	return disruptionProv.DisruptionSettings(app.Context(), app)
+}
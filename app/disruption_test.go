@@ -0,0 +1,40 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetDisruptionSettings(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetDisruptionSettings(appTypes.DisruptionSettings{MaxSurge: "0", MaxUnavailable: "0%"})
+	c.Assert(err, check.ErrorMatches, "maxSurge and maxUnavailable can't be both zero")
+	settings := appTypes.DisruptionSettings{MaxSurge: "1", PDBMinAvailable: "50%"}
+	err = a.SetDisruptionSettings(settings)
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.GetDisruptionSettings(), check.DeepEquals, settings)
+	effective, err := dbApp.EffectiveDisruptionSettings()
+	c.Assert(err, check.IsNil)
+	c.Assert(effective, check.DeepEquals, provision.EffectiveDisruptionSettings{
+		MaxSurge:        "1",
+		MaxUnavailable:  "0",
+		PDBEnabled:      true,
+		PDBMinAvailable: "50%",
+	})
+	err = a.SetDisruptionSettings(appTypes.DisruptionSettings{})
+	c.Assert(err, check.IsNil)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Disruption.IsEmpty(), check.Equals, true)
+}
@@ -0,0 +1,346 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/dns"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	DomainStatusPending = "pending"
+	DomainStatusActive  = "active"
+
+	domainVerificationPrefix = "_tsuru-verification."
+)
+
+var (
+	ErrDomainNotFound        = errors.New("domain not found")
+	ErrDomainAlreadyInUse    = errors.New("domain is already in use by another app")
+	ErrDomainNotVerified     = errors.New("domain ownership could not be verified, check the verification TXT record")
+	ErrDomainNoRouterAddress = errors.New("app has no router address to point the domain to")
+
+	lookupTXT = net.DefaultResolver.LookupTXT
+)
+
+// Domain is a custom domain of an app. Domains in zones managed by a
+// configured DNS provider have their records managed by tsuru and are
+// active right away, other domains remain pending until their ownership is
+// verified through a TXT record.
+type Domain struct {
+	Name         string              `json:"domain" bson:"_id"`
+	App          string              `json:"app"`
+	Status       string              `json:"status"`
+	Provider     string              `json:"provider,omitempty"`
+	Zone         string              `json:"zone,omitempty"`
+	Record       *DomainRecord       `json:"record,omitempty"`
+	Verification *DomainVerification `json:"verification,omitempty"`
+	CreatedAt    time.Time           `json:"createdAt"`
+	VerifiedAt   *time.Time          `json:"verifiedAt,omitempty"`
+}
+
+// DomainRecord is the DNS record pointing the domain to the app router.
+type DomainRecord struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// DomainVerification is the TXT record that must be created to prove the
+// ownership of a domain not managed by tsuru.
+type DomainVerification struct {
+	Record string `json:"record"`
+	Value  string `json:"value"`
+}
+
+func domainsCollection(conn *db.Storage) *storage.Collection {
+	coll := conn.Collection("app_domains")
+	coll.EnsureIndex(mgo.Index{Key: []string{"app"}})
+	return coll
+}
+
+func getDomain(name string) (*Domain, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var d Domain
+	err = domainsCollection(conn).FindId(name).One(&d)
+	if err == mgo.ErrNotFound {
+		return nil, ErrDomainNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func saveDomain(d *Domain) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = domainsCollection(conn).UpsertId(d.Name, d)
+	return err
+}
+
+func removeDomain(name string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = domainsCollection(conn).RemoveId(name)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// Domains returns the custom domains of the app.
+func (app *App) Domains() ([]Domain, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var domains []Domain
+	err = domainsCollection(conn).Find(bson.M{"app": app.Name}).Sort("_id").All(&domains)
+	if err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+// AddDomain adds a custom domain to the app. When a DNS provider manages the
+// domain zone, the domain is set as CName in the app routers and a record
+// pointing to the app is created in the provider. Otherwise the domain is
+// kept pending until VerifyDomain confirms its ownership. Adding an active
+// domain again updates its DNS record.
+func (app *App) AddDomain(ctx context.Context, name string) (*Domain, error) {
+	name = dns.Canonical(name)
+	if !cnameRegexp.MatchString(name) {
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid domain %q", name)}
+	}
+	d, err := getDomain(name)
+	if err != nil && err != ErrDomainNotFound {
+		return nil, err
+	}
+	if d != nil && d.App != app.Name {
+		return nil, ErrDomainAlreadyInUse
+	}
+	zone, err := dns.ForDomain(name)
+	if err != nil && err != dns.ErrNoProvider {
+		return nil, err
+	}
+	if d == nil {
+		d = &Domain{Name: name, App: app.Name, Status: DomainStatusPending, CreatedAt: time.Now().UTC()}
+	}
+	if zone == nil {
+		if d.Status == DomainStatusPending && d.Verification == nil {
+			d.Verification, err = newDomainVerification(name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return d, saveDomain(d)
+	}
+	d.Provider = zone.Provider
+	d.Zone = zone.Name
+	d.Verification = nil
+	wasActive := d.Status == DomainStatusActive
+	if !wasActive {
+		err = app.AddCName(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = app.setDomainRecord(ctx, d)
+	if err != nil {
+		if !wasActive {
+			if rollbackErr := app.RemoveCName(name); rollbackErr != nil {
+				log.Errorf("[domains] unable to remove cname %q from app %q: %v", name, app.Name, rollbackErr)
+			}
+		}
+		return nil, err
+	}
+	if !wasActive {
+		now := time.Now().UTC()
+		d.Status = DomainStatusActive
+		d.VerifiedAt = &now
+	}
+	return d, saveDomain(d)
+}
+
+// VerifyDomain checks the verification TXT record of a pending domain,
+// setting the domain as CName in the app routers once its ownership is
+// confirmed.
+func (app *App) VerifyDomain(ctx context.Context, name string) (*Domain, error) {
+	d, err := app.getAppDomain(name)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == DomainStatusActive {
+		return d, nil
+	}
+	records, err := lookupTXT(ctx, d.Verification.Record)
+	if err != nil {
+		log.Debugf("[domains] unable to lookup verification record for %q: %v", d.Name, err)
+		return nil, ErrDomainNotVerified
+	}
+	verified := false
+	for _, r := range records {
+		if r == d.Verification.Value {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrDomainNotVerified
+	}
+	err = app.AddCName(d.Name)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	d.Status = DomainStatusActive
+	d.VerifiedAt = &now
+	return d, saveDomain(d)
+}
+
+// RemoveDomain removes a custom domain from the app, along with its CName in
+// the app routers and its record in the DNS provider.
+func (app *App) RemoveDomain(ctx context.Context, name string) error {
+	d, err := app.getAppDomain(name)
+	if err != nil {
+		return err
+	}
+	if d.Status == DomainStatusActive {
+		err = app.RemoveCName(d.Name)
+		if err != nil {
+			return err
+		}
+	}
+	err = app.removeDomainRecord(ctx, d)
+	if err != nil {
+		return err
+	}
+	return removeDomain(d.Name)
+}
+
+// removeDomains removes the DNS records of every domain of the app, called
+// when the app is removed.
+func (app *App) removeDomains(ctx context.Context) error {
+	domains, err := app.Domains()
+	if err != nil {
+		return err
+	}
+	multi := tsuruErrors.NewMultiError()
+	for i := range domains {
+		err = app.removeDomainRecord(ctx, &domains[i])
+		if err == nil {
+			err = removeDomain(domains[i].Name)
+		}
+		if err != nil {
+			multi.Add(errors.Wrapf(err, "unable to remove domain %q", domains[i].Name))
+		}
+	}
+	return multi.ToError()
+}
+
+func (app *App) getAppDomain(name string) (*Domain, error) {
+	d, err := getDomain(dns.Canonical(name))
+	if err != nil {
+		return nil, err
+	}
+	if d.App != app.Name {
+		return nil, ErrDomainNotFound
+	}
+	return d, nil
+}
+
+func (app *App) setDomainRecord(ctx context.Context, d *Domain) error {
+	addresses, err := app.GetAddresses()
+	if err != nil {
+		return err
+	}
+	var target string
+	for _, addr := range addresses {
+		if addr != "" {
+			target = addr
+			break
+		}
+	}
+	if target == "" {
+		return ErrDomainNoRouterAddress
+	}
+	target = strings.TrimPrefix(strings.TrimPrefix(target, "http://"), "https://")
+	if host, _, splitErr := net.SplitHostPort(target); splitErr == nil {
+		target = host
+	}
+	record := DomainRecord{Type: dns.RecordTypeCNAME, Value: target}
+	if ip := net.ParseIP(target); ip != nil {
+		record.Type = dns.RecordTypeA
+	}
+	p, err := dns.Get(d.Provider)
+	if err != nil {
+		return err
+	}
+	if d.Record != nil && d.Record.Type != record.Type {
+		err = p.RemoveRecord(ctx, d.Zone, dns.Record{Name: d.Name, Type: d.Record.Type})
+		if err != nil {
+			return err
+		}
+	}
+	err = p.SetRecord(ctx, d.Zone, dns.Record{
+		Name:   d.Name,
+		Type:   record.Type,
+		Values: []string{record.Value},
+		TTL:    dns.DefaultTTL,
+	})
+	if err != nil {
+		return err
+	}
+	d.Record = &record
+	return nil
+}
+
+func (app *App) removeDomainRecord(ctx context.Context, d *Domain) error {
+	if d.Provider == "" || d.Record == nil {
+		return nil
+	}
+	p, err := dns.Get(d.Provider)
+	if err != nil {
+		return err
+	}
+	return p.RemoveRecord(ctx, d.Zone, dns.Record{Name: d.Name, Type: d.Record.Type})
+}
+
+func newDomainVerification(name string) (*DomainVerification, error) {
+	token := make([]byte, 16)
+	_, err := rand.Read(token)
+	if err != nil {
+		return nil, err
+	}
+	return &DomainVerification{
+		Record: domainVerificationPrefix + strings.TrimPrefix(name, "*."),
+		Value:  "tsuru-verification=" + hex.EncodeToString(token),
+	}, nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/dns"
+	"github.com/tsuru/tsuru/dns/dnstest"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router/routertest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) setupDNSProvider(c *check.C) func() {
+	config.Set("dns:providers:fake-dns:driver", "fake")
+	config.Set("dns:providers:fake-dns:zones", []interface{}{"mycompany.com"})
+	return func() {
+		config.Unset("dns")
+		dns.Reset()
+		dnstest.Provider.Reset()
+	}
+}
+
+func (s *S) TestAddDomainManagedZone(c *check.C) {
+	defer s.setupDNSProvider(c)()
+	a := &App{Name: "ktulu", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	d, err := a.AddDomain(context.TODO(), "WWW.mycompany.com.")
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Name, check.Equals, "www.mycompany.com")
+	c.Assert(d.Status, check.Equals, DomainStatusActive)
+	c.Assert(d.Provider, check.Equals, "fake-dns")
+	c.Assert(d.Zone, check.Equals, "mycompany.com")
+	c.Assert(d.Record, check.DeepEquals, &DomainRecord{Type: dns.RecordTypeCNAME, Value: "ktulu.fakerouter.com"})
+	c.Assert(d.Verification, check.IsNil)
+	record, ok := dnstest.Provider.Record("mycompany.com", "www.mycompany.com", dns.RecordTypeCNAME)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(record.Values, check.DeepEquals, []string{"ktulu.fakerouter.com"})
+	c.Assert(routertest.FakeRouter.HasCName("www.mycompany.com"), check.Equals, true)
+	a, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(a.CName, check.DeepEquals, []string{"www.mycompany.com"})
+	domains, err := a.Domains()
+	c.Assert(err, check.IsNil)
+	c.Assert(domains, check.HasLen, 1)
+	c.Assert(domains[0].Name, check.Equals, "www.mycompany.com")
+}
+
+func (s *S) TestAddDomainManagedZoneProviderFailure(c *check.C) {
+	defer s.setupDNSProvider(c)()
+	dnstest.Provider.FailSet = true
+	a := &App{Name: "ktulu", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = a.AddDomain(context.TODO(), "www.mycompany.com")
+	c.Assert(err, check.ErrorMatches, "fake provider failure")
+	a, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(a.CName, check.HasLen, 0)
+	domains, err := a.Domains()
+	c.Assert(err, check.IsNil)
+	c.Assert(domains, check.HasLen, 0)
+}
+
+func (s *S) TestAddDomainPendingVerification(c *check.C) {
+	a := &App{Name: "ktulu", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	d, err := a.AddDomain(context.TODO(), "www.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Status, check.Equals, DomainStatusPending)
+	c.Assert(d.Provider, check.Equals, "")
+	c.Assert(d.Verification.Record, check.Equals, "_tsuru-verification.www.example.com")
+	c.Assert(d.Verification.Value, check.Matches, "tsuru-verification=[0-9a-f]{32}")
+	again, err := a.AddDomain(context.TODO(), "www.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(again.Verification, check.DeepEquals, d.Verification)
+	a, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(a.CName, check.HasLen, 0)
+}
+
+func (s *S) TestVerifyDomain(c *check.C) {
+	a := &App{Name: "ktulu", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	d, err := a.AddDomain(context.TODO(), "*.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Verification.Record, check.Equals, "_tsuru-verification.example.com")
+	var txt []string
+	defer func(orig func(context.Context, string) ([]string, error)) { lookupTXT = orig }(lookupTXT)
+	lookupTXT = func(ctx context.Context, name string) ([]string, error) {
+		c.Assert(name, check.Equals, "_tsuru-verification.example.com")
+		return txt, nil
+	}
+	_, err = a.VerifyDomain(context.TODO(), "*.example.com")
+	c.Assert(err, check.Equals, ErrDomainNotVerified)
+	txt = []string{"other", d.Verification.Value}
+	d, err = a.VerifyDomain(context.TODO(), "*.example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Status, check.Equals, DomainStatusActive)
+	c.Assert(d.VerifiedAt, check.NotNil)
+	a, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(a.CName, check.DeepEquals, []string{"*.example.com"})
+}
+
+func (s *S) TestAddDomainInUseByAnotherApp(c *check.C) {
+	a := &App{Name: "ktulu", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	other := &App{Name: "other", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), other, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = a.AddDomain(context.TODO(), "www.example.com")
+	c.Assert(err, check.IsNil)
+	_, err = other.AddDomain(context.TODO(), "www.example.com")
+	c.Assert(err, check.Equals, ErrDomainAlreadyInUse)
+	err = other.RemoveDomain(context.TODO(), "www.example.com")
+	c.Assert(err, check.Equals, ErrDomainNotFound)
+}
+
+func (s *S) TestAddDomainInvalid(c *check.C) {
+	a := &App{Name: "ktulu", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = a.AddDomain(context.TODO(), "-invalid/domain")
+	c.Assert(err, check.ErrorMatches, `invalid domain "-invalid/domain"`)
+}
+
+func (s *S) TestRemoveDomain(c *check.C) {
+	defer s.setupDNSProvider(c)()
+	a := &App{Name: "ktulu", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = a.AddDomain(context.TODO(), "www.mycompany.com")
+	c.Assert(err, check.IsNil)
+	err = a.RemoveDomain(context.TODO(), "www.mycompany.com")
+	c.Assert(err, check.IsNil)
+	_, ok := dnstest.Provider.Record("mycompany.com", "www.mycompany.com", dns.RecordTypeCNAME)
+	c.Assert(ok, check.Equals, false)
+	c.Assert(routertest.FakeRouter.HasCName("www.mycompany.com"), check.Equals, false)
+	domains, err := a.Domains()
+	c.Assert(err, check.IsNil)
+	c.Assert(domains, check.HasLen, 0)
+}
+
+func (s *S) TestDeleteAppRemovesDomains(c *check.C) {
+	defer s.setupDNSProvider(c)()
+	a := &App{Name: "ktulu", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	_, err = a.AddDomain(context.TODO(), "www.mycompany.com")
+	c.Assert(err, check.IsNil)
+	_, err = a.AddDomain(context.TODO(), "www.example.com")
+	c.Assert(err, check.IsNil)
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: "app", Value: a.Name},
+		Kind:     permission.PermAppDelete,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = Delete(context.TODO(), a, evt, "")
+	c.Assert(err, check.IsNil)
+	_, ok := dnstest.Provider.Record("mycompany.com", "www.mycompany.com", dns.RecordTypeCNAME)
+	c.Assert(ok, check.Equals, false)
+	_, err = getDomain("www.example.com")
+	c.Assert(err, check.Equals, ErrDomainNotFound)
+}
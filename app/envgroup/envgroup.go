@@ -0,0 +1,251 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package envgroup manages env groups: named sets of environment variables
+// attached to many apps. Attached apps get a rolling restart whenever the
+// group changes, unless they're under maintenance, in which case the restart
+// happens when the maintenance window ends.
+package envgroup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/maintenance"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/validation"
+)
+
+var (
+	ErrGroupNotFound      = errors.New("env group not found")
+	ErrGroupAlreadyExists = errors.New("env group already exists")
+	ErrGroupInUse         = errors.New("env group is attached to apps")
+	ErrNotAttached        = errors.New("env group is not attached to the app")
+)
+
+// Group is a named set of environment variables shared by apps.
+type Group struct {
+	Name        string            `bson:"_id" json:"name"`
+	Description string            `json:"description,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+}
+
+func groupsCollection(conn *db.Storage) *storage.Collection {
+	return conn.Collection("env_groups")
+}
+
+// managedBy identifies the environment variables set on apps by the group.
+func managedBy(name string) string {
+	return "env-group/" + name
+}
+
+func (g *Group) validate() error {
+	if !validation.ValidateName(g.Name) {
+		msg := "Invalid env group name, env group name should have at most 40 " +
+			"characters, containing only lower case letters, numbers or dashes, " +
+			"starting with a letter."
+		return &tsuruErrors.ValidationError{Message: msg}
+	}
+	for name := range g.Env {
+		if name == "" {
+			return &tsuruErrors.ValidationError{Message: "environment variable name is required"}
+		}
+	}
+	return nil
+}
+
+// Create stores a new group.
+func Create(g Group) error {
+	if err := g.validate(); err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = groupsCollection(conn).Insert(g)
+	if mgo.IsDup(err) {
+		return ErrGroupAlreadyExists
+	}
+	return err
+}
+
+// Update replaces an existing group. Attached apps are only changed when
+// the group is synced.
+func Update(g Group) error {
+	if err := g.validate(); err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = groupsCollection(conn).UpdateId(g.Name, g)
+	if err == mgo.ErrNotFound {
+		return ErrGroupNotFound
+	}
+	return err
+}
+
+// Remove removes a group, as long as it's not attached to any app.
+func Remove(name string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	n, err := conn.Apps().Find(bson.M{"envgroups": name}).Count()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrGroupInUse
+	}
+	err = groupsCollection(conn).RemoveId(name)
+	if err == mgo.ErrNotFound {
+		return ErrGroupNotFound
+	}
+	return err
+}
+
+// Get returns the group with the given name.
+func Get(name string) (*Group, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var g Group
+	err = groupsCollection(conn).FindId(name).One(&g)
+	if err == mgo.ErrNotFound {
+		return nil, ErrGroupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// List returns all groups, sorted by name.
+func List() ([]Group, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var groups []Group
+	err = groupsCollection(conn).Find(nil).Sort("_id").All(&groups)
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Apps returns the names of the apps the group is attached to.
+func Apps(name string) ([]string, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var apps []struct{ Name string }
+	err = conn.Apps().Find(bson.M{"envgroups": name}).Select(bson.M{"name": 1}).Sort("name").All(&apps)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(apps))
+	for i, a := range apps {
+		names[i] = a.Name
+	}
+	return names, nil
+}
+
+// Attach attaches the group to the app and sets its environment variables.
+func (g *Group) Attach(ctx context.Context, a *app.App, w io.Writer) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": a.Name}, bson.M{"$addToSet": bson.M{"envgroups": g.Name}})
+	if err != nil {
+		return err
+	}
+	if !attached(a, g.Name) {
+		a.EnvGroups = append(a.EnvGroups, g.Name)
+	}
+	return g.SyncApp(ctx, a, w)
+}
+
+// Detach detaches the group from the app, unsetting its environment
+// variables.
+func Detach(ctx context.Context, a *app.App, name string, w io.Writer) error {
+	if !attached(a, name) {
+		return ErrNotAttached
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = conn.Apps().Update(bson.M{"name": a.Name}, bson.M{"$pull": bson.M{"envgroups": name}})
+	if err != nil {
+		return err
+	}
+	var groups []string
+	for _, n := range a.EnvGroups {
+		if n != name {
+			groups = append(groups, n)
+		}
+	}
+	a.EnvGroups = groups
+	return setEnvs(a, name, nil, w)
+}
+
+// SyncApp sets the group environment variables on the app, pruning the ones
+// removed from the group. Variables set directly on the app, or by another
+// group, take precedence.
+func (g *Group) SyncApp(ctx context.Context, a *app.App, w io.Writer) error {
+	return setEnvs(a, g.Name, g.Env, w)
+}
+
+func setEnvs(a *app.App, name string, envs map[string]string, w io.Writer) error {
+	if w == nil {
+		w = ioutil.Discard
+	}
+	window, err := maintenance.Active(a.Name)
+	if err != nil {
+		return err
+	}
+	changed, err := a.SetManagedEnvs(managedBy(name), envs, bind.SetEnvArgs{
+		ShouldRestart:  window == nil,
+		RollingRestart: true,
+		Writer:         w,
+	})
+	if err != nil || !changed || window == nil {
+		return err
+	}
+	fmt.Fprintf(w, "---- App %q is under maintenance, restart deferred until the window ends ----\n", a.Name)
+	return maintenance.DeferRestart(window)
+}
+
+func attached(a *app.App, name string) bool {
+	for _, n := range a.EnvGroups {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
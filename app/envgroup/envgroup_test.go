@@ -0,0 +1,168 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envgroup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/maintenance"
+	"github.com/tsuru/tsuru/app/version"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"golang.org/x/crypto/bcrypt"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage     *db.Storage
+	user        *auth.User
+	mockService servicemock.MockService
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_envgroup_tests")
+	config.Set("routers:fake:type", "fake")
+	config.Set("auth:hash-cost", bcrypt.MinCost)
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+	provision.DefaultProvisioner = "fake"
+	app.AuthScheme = auth.ManagedScheme(native.NativeScheme{})
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	provisiontest.ProvisionerInstance.Reset()
+	routertest.FakeRouter.Reset()
+	s.user, _ = permissiontest.CustomUserWithPermission(c, app.AuthScheme, "majortom", permission.Permission{
+		Scheme:  permission.PermAll,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "p1", Default: true})
+	c.Assert(err, check.IsNil)
+	servicemock.SetMockService(&s.mockService)
+	plan := appTypes.Plan{Name: "default", Default: true, CpuShare: 100}
+	s.mockService.Plan.OnList = func() ([]appTypes.Plan, error) {
+		return []appTypes.Plan{plan}, nil
+	}
+	s.mockService.Plan.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &plan, nil
+	}
+	servicemanager.AppVersion, err = version.AppVersionService()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+	s.storage.Close()
+}
+
+func (s *S) createApp(c *check.C, name string) *app.App {
+	a := app.App{Name: name, Platform: "python", TeamOwner: "myteam"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestCreateAndGet(c *check.C) {
+	g := Group{Name: "observability", Description: "shared tracing config", Env: map[string]string{"OTEL_ENDPOINT": "collector:4317"}}
+	err := Create(g)
+	c.Assert(err, check.IsNil)
+	stored, err := Get("observability")
+	c.Assert(err, check.IsNil)
+	c.Assert(*stored, check.DeepEquals, g)
+	err = Create(g)
+	c.Assert(err, check.Equals, ErrGroupAlreadyExists)
+	err = Create(Group{Name: "Invalid Name"})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	groups, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(groups, check.HasLen, 1)
+	err = Update(Group{Name: "other"})
+	c.Assert(err, check.Equals, ErrGroupNotFound)
+}
+
+func (s *S) TestAttachAndDetach(c *check.C) {
+	g := Group{Name: "observability", Env: map[string]string{"OTEL_ENDPOINT": "collector:4317", "LOG_LEVEL": "info"}}
+	err := Create(g)
+	c.Assert(err, check.IsNil)
+	a := s.createApp(c, "myapp")
+	err = a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{{Name: "LOG_LEVEL", Value: "debug", Public: true}}})
+	c.Assert(err, check.IsNil)
+	err = g.Attach(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.EnvGroups, check.DeepEquals, []string{"observability"})
+	c.Assert(a.Env["OTEL_ENDPOINT"].Value, check.Equals, "collector:4317")
+	c.Assert(a.Env["OTEL_ENDPOINT"].ManagedBy, check.Equals, "env-group/observability")
+	c.Assert(a.Env["LOG_LEVEL"].Value, check.Equals, "debug")
+	apps, err := Apps("observability")
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.DeepEquals, []string{"myapp"})
+	c.Assert(Remove("observability"), check.Equals, ErrGroupInUse)
+	err = Detach(context.TODO(), a, "observability", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(Detach(context.TODO(), a, "observability", nil), check.Equals, ErrNotAttached)
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.EnvGroups, check.HasLen, 0)
+	_, ok := a.Env["OTEL_ENDPOINT"]
+	c.Assert(ok, check.Equals, false)
+	c.Assert(a.Env["LOG_LEVEL"].Value, check.Equals, "debug")
+	err = Remove("observability")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestSyncAppDefersRestartDuringMaintenance(c *check.C) {
+	g := Group{Name: "observability", Env: map[string]string{"OTEL_ENDPOINT": "collector:4317"}}
+	err := Create(g)
+	c.Assert(err, check.IsNil)
+	a := s.createApp(c, "myapp")
+	now := time.Now()
+	w := maintenance.Window{App: "myapp", Start: now.Add(-time.Minute), End: now.Add(time.Hour)}
+	err = maintenance.Schedule(&w)
+	c.Assert(err, check.IsNil)
+	err = s.storage.Collection("app_maintenance_windows").UpdateId(w.ID, bson.M{"$set": bson.M{"status": maintenance.StatusActive}})
+	c.Assert(err, check.IsNil)
+	err = g.Attach(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Env["OTEL_ENDPOINT"].Value, check.Equals, "collector:4317")
+	active, err := maintenance.Active("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(active.RestartOnEnd, check.Equals, true)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/api/tracker"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/app/image"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
@@ -181,6 +182,9 @@ func (g *imgGC) spin() {
 }
 
 func runPeriodicGC() (err error) {
+	if !tracker.IsLeader("image-gc") {
+		return nil
+	}
 	evt, err := event.NewInternal(&event.Opts{
 		Target:       event.Target{Type: event.TargetTypeGC, Value: "global"},
 		InternalKind: "gc",
@@ -0,0 +1,153 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package imagepolicy manages the image signature policies of pools. Pools
+// with at least one trusted key only accept image deploys whose images carry
+// a valid cosign signature made by one of these keys.
+package imagepolicy
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"strings"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	dbStorage "github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/registry"
+)
+
+var (
+	ErrTrustedKeyAlreadyExists = errors.New("trusted key already exists with the same name")
+	ErrTrustedKeyNotFound      = errors.New("trusted key not found")
+	ErrTrustedKeyNameRequired  = errors.New("trusted key name is required")
+)
+
+// TrustedKey is a PEM encoded public key allowed to sign images deployed in a
+// pool.
+type TrustedKey struct {
+	Name      string `json:"name" form:"name"`
+	PublicKey string `json:"public_key" form:"public_key" bson:"publickey"`
+}
+
+type poolPolicy struct {
+	Pool        string `bson:"_id"`
+	TrustedKeys []TrustedKey
+}
+
+// ErrUnsignedImage is returned when the image being deployed does not comply
+// with the pool policy.
+type ErrUnsignedImage struct {
+	Image  string
+	Pool   string
+	Reason error
+}
+
+func (e *ErrUnsignedImage) Error() string {
+	return fmt.Sprintf("image %q is not allowed in pool %q: %v", e.Image, e.Pool, e.Reason)
+}
+
+func collection(conn *db.Storage) *dbStorage.Collection {
+	return conn.Collection("pool_image_policies")
+}
+
+// TrustedKeys returns the keys trusted to sign images deployed in the pool.
+func TrustedKeys(pool string) ([]TrustedKey, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var policy poolPolicy
+	err = collection(conn).FindId(pool).One(&policy)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	return policy.TrustedKeys, nil
+}
+
+// AddTrustedKey adds a new trusted key to the pool, enabling signature
+// verification for image deploys in it.
+func AddTrustedKey(pool string, key TrustedKey) error {
+	if key.Name == "" {
+		return ErrTrustedKeyNameRequired
+	}
+	if _, err := registry.ParsePublicKey([]byte(key.PublicKey)); err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = collection(conn).Upsert(
+		bson.M{"_id": pool, "trustedkeys.name": bson.M{"$ne": key.Name}},
+		bson.M{"$push": bson.M{"trustedkeys": key}},
+	)
+	if mgo.IsDup(err) {
+		return ErrTrustedKeyAlreadyExists
+	}
+	return err
+}
+
+// RemoveTrustedKey removes a trusted key from the pool. Signature
+// verification is disabled in the pool after its last key is removed.
+func RemoveTrustedKey(pool, name string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = collection(conn).Update(
+		bson.M{"_id": pool, "trustedkeys.name": name},
+		bson.M{"$pull": bson.M{"trustedkeys": bson.M{"name": name}}},
+	)
+	if err == mgo.ErrNotFound {
+		return ErrTrustedKeyNotFound
+	}
+	return err
+}
+
+// VerifyImage checks whether the image may be deployed in the pool. Pools
+// without trusted keys accept any image. It returns the reference to be
+// deployed: images verified against trusted keys are pinned to the digest of
+// the verified manifest, so that moving their tags after the verification
+// doesn't change what is deployed.
+func VerifyImage(ctx context.Context, pool, image string) (string, error) {
+	trustedKeys, err := TrustedKeys(pool)
+	if err != nil {
+		return "", err
+	}
+	if len(trustedKeys) == 0 {
+		return image, nil
+	}
+	keys := make([]crypto.PublicKey, 0, len(trustedKeys))
+	for _, k := range trustedKeys {
+		key, err := registry.ParsePublicKey([]byte(k.PublicKey))
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid trusted key %q in pool %q", k.Name, pool)
+		}
+		keys = append(keys, key)
+	}
+	digest, err := registry.VerifyCosignSignature(ctx, pool, image, keys)
+	if err != nil {
+		return "", &ErrUnsignedImage{Image: image, Pool: pool, Reason: err}
+	}
+	return pinDigest(image, digest), nil
+}
+
+// pinDigest replaces the tag or digest of the image reference with the given
+// digest.
+func pinDigest(image, digest string) string {
+	repository := image
+	if idx := strings.Index(repository, "@"); idx != -1 {
+		repository = repository[:idx]
+	} else if idx := strings.LastIndex(repository, ":"); idx > strings.LastIndex(repository, "/") {
+		repository = repository[:idx]
+	}
+	return repository + "@" + digest
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagepolicy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	"github.com/tsuru/tsuru/registry"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	conn *db.Storage
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "tsuru_imagepolicy_tests")
+	var err error
+	s.conn, err = db.Conn()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	dbtest.ClearAllCollections(s.conn.Apps().Database)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	s.conn.Close()
+}
+
+func publicKeyPEM(c *check.C) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	c.Assert(err, check.IsNil)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func (s *S) TestAddTrustedKey(c *check.C) {
+	key1 := TrustedKey{Name: "k1", PublicKey: publicKeyPEM(c)}
+	key2 := TrustedKey{Name: "k2", PublicKey: publicKeyPEM(c)}
+	err := AddTrustedKey("pool1", key1)
+	c.Assert(err, check.IsNil)
+	err = AddTrustedKey("pool1", key2)
+	c.Assert(err, check.IsNil)
+	keys, err := TrustedKeys("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.DeepEquals, []TrustedKey{key1, key2})
+	keys, err = TrustedKeys("pool2")
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.HasLen, 0)
+}
+
+func (s *S) TestAddTrustedKeyDuplicated(c *check.C) {
+	key := TrustedKey{Name: "k1", PublicKey: publicKeyPEM(c)}
+	err := AddTrustedKey("pool1", key)
+	c.Assert(err, check.IsNil)
+	err = AddTrustedKey("pool1", key)
+	c.Assert(err, check.Equals, ErrTrustedKeyAlreadyExists)
+}
+
+func (s *S) TestAddTrustedKeyInvalid(c *check.C) {
+	err := AddTrustedKey("pool1", TrustedKey{Name: "k1", PublicKey: "invalid"})
+	c.Assert(err, check.Equals, registry.ErrInvalidPublicKeyBlock)
+	err = AddTrustedKey("pool1", TrustedKey{PublicKey: publicKeyPEM(c)})
+	c.Assert(err, check.Equals, ErrTrustedKeyNameRequired)
+}
+
+func (s *S) TestRemoveTrustedKey(c *check.C) {
+	key1 := TrustedKey{Name: "k1", PublicKey: publicKeyPEM(c)}
+	key2 := TrustedKey{Name: "k2", PublicKey: publicKeyPEM(c)}
+	c.Assert(AddTrustedKey("pool1", key1), check.IsNil)
+	c.Assert(AddTrustedKey("pool1", key2), check.IsNil)
+	err := RemoveTrustedKey("pool1", "k1")
+	c.Assert(err, check.IsNil)
+	keys, err := TrustedKeys("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.DeepEquals, []TrustedKey{key2})
+	err = RemoveTrustedKey("pool1", "k1")
+	c.Assert(err, check.Equals, ErrTrustedKeyNotFound)
+}
+
+func (s *S) TestVerifyImageNoTrustedKeys(c *check.C) {
+	image, err := VerifyImage(context.TODO(), "pool1", "tsuru/app:v1")
+	c.Assert(err, check.IsNil)
+	c.Assert(image, check.Equals, "tsuru/app:v1")
+}
+
+func (s *S) TestVerifyImageUnsigned(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/tsuru/app/manifests/v1" {
+			w.Header().Set("Docker-Content-Digest", "sha256:abcdef")
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	c.Assert(AddTrustedKey("pool1", TrustedKey{Name: "k1", PublicKey: publicKeyPEM(c)}), check.IsNil)
+	imageName := strings.TrimPrefix(srv.URL, "http://") + "/tsuru/app:v1"
+	_, err := VerifyImage(context.TODO(), "pool1", imageName)
+	c.Assert(err, check.FitsTypeOf, &ErrUnsignedImage{})
+	c.Assert(err.(*ErrUnsignedImage).Reason, check.Equals, registry.ErrSignatureNotFound)
+}
+
+func (s *S) TestVerifyImagePoolRegistry(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "pool-user" || pass != "pool-pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/v2/team-a/app/manifests/v1" {
+			w.Header().Set("Docker-Content-Digest", "sha256:abcdef")
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	address := strings.TrimPrefix(srv.URL, "http://")
+	err := registry.SetPoolRegistry("pool1", registry.PoolRegistry{Address: address, Namespace: "team-a", Username: "pool-user", Password: "pool-pass"})
+	c.Assert(err, check.IsNil)
+	c.Assert(AddTrustedKey("pool1", TrustedKey{Name: "k1", PublicKey: publicKeyPEM(c)}), check.IsNil)
+	_, err = VerifyImage(context.TODO(), "pool1", address+"/team-a/app:v1")
+	c.Assert(err, check.FitsTypeOf, &ErrUnsignedImage{})
+	c.Assert(err.(*ErrUnsignedImage).Reason, check.Equals, registry.ErrSignatureNotFound)
+}
+
+func (s *S) TestPinDigest(c *check.C) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{"tsuru/app:v1", "tsuru/app@sha256:abc"},
+		{"tsuru/app", "tsuru/app@sha256:abc"},
+		{"localhost:5000/tsuru/app:v1", "localhost:5000/tsuru/app@sha256:abc"},
+		{"localhost:5000/tsuru/app", "localhost:5000/tsuru/app@sha256:abc"},
+		{"registry.io/tsuru/app@sha256:old", "registry.io/tsuru/app@sha256:abc"},
+	}
+	for _, tt := range tests {
+		c.Check(pinDigest(tt.image, "sha256:abc"), check.Equals, tt.expected, check.Commentf("image %s", tt.image))
+	}
+}
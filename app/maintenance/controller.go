@@ -0,0 +1,115 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package maintenance
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/log"
+)
+
+const defaultPage = `<html><head><title>Under maintenance</title></head><body><h1>This application is under maintenance. Please try again later.</h1></body></html>`
+
+// Initialize starts the controller that starts and ends maintenance windows
+// and, when maintenance:responder:listen is set, the built-in maintenance
+// responder.
+func Initialize() error {
+	interval, _ := config.GetDuration("maintenance:interval")
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	c := &controller{interval: interval}
+	c.start()
+	shutdown.Register(c)
+	listen, _ := config.GetString("maintenance:responder:listen")
+	if listen == "" {
+		return nil
+	}
+	handler, err := NewResponder()
+	if err != nil {
+		return err
+	}
+	srv := &responderServer{srv: &http.Server{Addr: listen, Handler: handler}}
+	go func() {
+		log.Debugf("[maintenance] responder listening on %s", listen)
+		if err := srv.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Errorf("[maintenance] responder stopped: %v", err)
+		}
+	}()
+	shutdown.Register(srv)
+	return nil
+}
+
+type controller struct {
+	interval time.Duration
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+func (c *controller) start() {
+	c.shutdown = make(chan struct{})
+	c.done = make(chan struct{})
+	log.Debugf("[maintenance] starting. Running every %s.", c.interval)
+	go func() {
+		defer close(c.done)
+		for {
+			select {
+			case <-time.After(c.interval):
+				runOnce()
+			case <-c.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops the controller, waiting for the current windows to be
+// handled.
+func (c *controller) Shutdown(ctx context.Context) error {
+	close(c.shutdown)
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
+
+type responderServer struct {
+	srv *http.Server
+}
+
+func (s *responderServer) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// NewResponder returns the handler of the built-in maintenance responder. It
+// answers every request with 503 and the page set in
+// maintenance:responder:page, or a default page.
+func NewResponder() (http.Handler, error) {
+	page := []byte(defaultPage)
+	pagePath, _ := config.GetString("maintenance:responder:page")
+	if pagePath != "" {
+		var err error
+		page, err = os.ReadFile(pagePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read maintenance page")
+		}
+	}
+	retryAfter, _ := config.GetString("maintenance:responder:retry-after")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write(page)
+	}), nil
+}
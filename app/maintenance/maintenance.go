@@ -0,0 +1,411 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package maintenance implements scheduled maintenance windows for apps.
+// During a window the app routes point to a maintenance backend, either
+// another app serving a static page or the built-in responder, and its
+// units may be stopped. Traffic is restored when the window ends.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/rebuild"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	StatusScheduled = "scheduled"
+	StatusActive    = "active"
+	StatusFinished  = "finished"
+	StatusCanceled  = "canceled"
+	StatusFailed    = "failed"
+
+	eventKindStart = "maintenance start"
+	eventKindEnd   = "maintenance end"
+)
+
+var (
+	ErrWindowNotFound   = errors.New("maintenance window not found")
+	ErrInvalidWindow    = errors.New("maintenance window must end after it starts and in the future")
+	ErrWindowOverlaps   = errors.New("maintenance window overlaps another window of the app")
+	ErrNoBackendAddress = errors.New("no maintenance backend available: set a backend app or configure maintenance:responder:address")
+)
+
+// Window is a maintenance window scheduled for an app.
+type Window struct {
+	ID         bson.ObjectId `bson:"_id" json:"id"`
+	App        string        `json:"app"`
+	Start      time.Time     `json:"start"`
+	End        time.Time     `json:"end"`
+	BackendApp string        `json:"backendApp,omitempty"`
+	StopUnits  bool          `json:"stopUnits"`
+	Status     string        `json:"status"`
+	Error      string        `json:"error,omitempty"`
+	Owner      string        `json:"owner"`
+	// RestartOnEnd is set when changes to the app, like env group updates,
+	// had their restart deferred until the end of the window.
+	RestartOnEnd bool `json:"restartOnEnd,omitempty"`
+}
+
+func windowsCollection(conn *db.Storage) *storage.Collection {
+	return conn.Collection("app_maintenance_windows")
+}
+
+// Schedule stores a new maintenance window for the app.
+func Schedule(w *Window) error {
+	if !w.End.After(w.Start) || !w.End.After(time.Now()) {
+		return ErrInvalidWindow
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	coll := windowsCollection(conn)
+	overlapping, err := coll.Find(bson.M{
+		"app":    w.App,
+		"status": bson.M{"$in": []string{StatusScheduled, StatusActive}},
+		"start":  bson.M{"$lt": w.End},
+		"end":    bson.M{"$gt": w.Start},
+	}).Count()
+	if err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrWindowOverlaps
+	}
+	w.ID = bson.NewObjectId()
+	w.Status = StatusScheduled
+	w.Start = w.Start.UTC()
+	w.End = w.End.UTC()
+	return coll.Insert(w)
+}
+
+// List returns the maintenance windows of the app, most recent first.
+func List(appName string) ([]Window, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var windows []Window
+	err = windowsCollection(conn).Find(bson.M{"app": appName}).Sort("-start").All(&windows)
+	if err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// Active returns the window in progress for the app, or nil if the app is not
+// under maintenance.
+func Active(appName string) (*Window, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var w Window
+	err = windowsCollection(conn).Find(bson.M{"app": appName, "status": StatusActive}).One(&w)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// DeferRestart makes the app be restarted when the window ends.
+func DeferRestart(w *Window) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = windowsCollection(conn).UpdateId(w.ID, bson.M{"$set": bson.M{"restartonend": true}})
+	if err != nil {
+		return err
+	}
+	w.RestartOnEnd = true
+	return nil
+}
+
+// Cancel cancels a scheduled window. Active windows are ended in the next
+// controller run, restoring the app traffic.
+func Cancel(appName, id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return ErrWindowNotFound
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	coll := windowsCollection(conn)
+	query := bson.M{"_id": bson.ObjectIdHex(id), "app": appName}
+	err = coll.Update(mergeQuery(query, bson.M{"status": StatusScheduled}), bson.M{"$set": bson.M{"status": StatusCanceled}})
+	if err != mgo.ErrNotFound {
+		return err
+	}
+	err = coll.Update(mergeQuery(query, bson.M{"status": StatusActive}), bson.M{"$set": bson.M{"end": time.Now().UTC()}})
+	if err == mgo.ErrNotFound {
+		return ErrWindowNotFound
+	}
+	return err
+}
+
+func mergeQuery(base, extra bson.M) bson.M {
+	query := bson.M{}
+	for k, v := range base {
+		query[k] = v
+	}
+	for k, v := range extra {
+		query[k] = v
+	}
+	return query
+}
+
+// claim atomically moves one window matching the query to the new status,
+// so that only one tsuru API instance handles each transition.
+func claim(query bson.M, status string) (*Window, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var w Window
+	_, err = windowsCollection(conn).Find(query).Sort("start").Apply(mgo.Change{
+		Update:    bson.M{"$set": bson.M{"status": status}},
+		ReturnNew: true,
+	}, &w)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func setStatus(w *Window, status string, windowErr error) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"status": status}
+	if windowErr != nil {
+		update["error"] = windowErr.Error()
+	}
+	return windowsCollection(conn).UpdateId(w.ID, bson.M{"$set": update})
+}
+
+// runOnce starts every window due to start and ends every window due to
+// end.
+func runOnce() {
+	now := time.Now().UTC()
+	for {
+		w, err := claim(bson.M{"status": StatusScheduled, "start": bson.M{"$lte": now}}, StatusActive)
+		if err != nil {
+			log.Errorf("[maintenance] unable to get windows to start: %v", err)
+			break
+		}
+		if w == nil {
+			break
+		}
+		if !w.End.After(now) {
+			setStatus(w, StatusFinished, nil)
+			continue
+		}
+		err = startWindow(w)
+		if err != nil {
+			log.Errorf("[maintenance] unable to start window %s of app %q: %v", w.ID.Hex(), w.App, err)
+		}
+	}
+	for {
+		w, err := claim(bson.M{"status": StatusActive, "end": bson.M{"$lte": now}}, StatusFinished)
+		if err != nil {
+			log.Errorf("[maintenance] unable to get windows to end: %v", err)
+			break
+		}
+		if w == nil {
+			break
+		}
+		err = endWindow(w)
+		if err != nil {
+			log.Errorf("[maintenance] unable to end window %s of app %q: %v", w.ID.Hex(), w.App, err)
+		}
+	}
+}
+
+func newWindowEvent(a *app.App, w *Window, kind string) (*event.Event, error) {
+	return event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		InternalKind: kind,
+		CustomData:   w,
+		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permTypes.CtxTeam, a.Teams),
+			permission.Context(permTypes.CtxApp, a.Name),
+			permission.Context(permTypes.CtxPool, a.Pool),
+		)...),
+	})
+}
+
+func startWindow(w *Window) (err error) {
+	ctx := context.Background()
+	a, err := app.GetByName(ctx, w.App)
+	if err != nil {
+		setStatus(w, StatusFailed, err)
+		return err
+	}
+	evt, err := newWindowEvent(a, w, eventKindStart)
+	if err != nil {
+		// The app is probably locked by another operation, the window is
+		// started again in the next run.
+		setStatus(w, StatusScheduled, nil)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			setStatus(w, StatusFailed, err)
+			if a.UnderMaintenance() {
+				if clearErr := a.SetMaintenance(nil); clearErr != nil {
+					log.Errorf("[maintenance] unable to clear maintenance state of app %q: %v", a.Name, clearErr)
+				}
+			}
+			rebuild.RoutesRebuildOrEnqueueWithProgress(a.Name, evt)
+		}
+		evt.Done(err)
+	}()
+	addresses, err := backendAddresses(ctx, w)
+	if err != nil {
+		return err
+	}
+	state := app.MaintenanceState{Window: w.ID.Hex()}
+	for _, addr := range addresses {
+		state.Addresses = append(state.Addresses, addr.String())
+	}
+	err = a.SetMaintenance(&state)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(evt, " ---> Routing app %q to maintenance backend %v\n", a.Name, addresses)
+	err = routeTo(ctx, a, addresses)
+	if err != nil {
+		return err
+	}
+	if w.StopUnits {
+		err = a.Stop(ctx, evt, "", "")
+	}
+	return err
+}
+
+func endWindow(w *Window) (err error) {
+	ctx := context.Background()
+	a, err := app.GetByName(ctx, w.App)
+	if err != nil {
+		setStatus(w, StatusFailed, err)
+		return err
+	}
+	evt, err := newWindowEvent(a, w, eventKindEnd)
+	if err != nil {
+		setStatus(w, StatusActive, nil)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			setStatus(w, StatusFailed, err)
+		}
+		evt.Done(err)
+	}()
+	err = a.SetMaintenance(nil)
+	if err != nil {
+		return err
+	}
+	if w.StopUnits {
+		err = a.Start(ctx, evt, "", "")
+	} else {
+		fmt.Fprintf(evt, " ---> Restoring routes of app %q\n", a.Name)
+		err = rebuild.RunRoutesRebuildOnce(a.Name, false, evt)
+	}
+	if err != nil || !w.RestartOnEnd {
+		return err
+	}
+	fmt.Fprintf(evt, " ---> Restarting app %q to apply changes deferred during maintenance\n", a.Name)
+	return a.Restart(ctx, "", "", evt)
+}
+
+func backendAddresses(ctx context.Context, w *Window) ([]*url.URL, error) {
+	if w.BackendApp == "" {
+		responderAddr, _ := config.GetString("maintenance:responder:address")
+		if responderAddr == "" {
+			return nil, ErrNoBackendAddress
+		}
+		addr, err := url.Parse(responderAddr)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid maintenance:responder:address")
+		}
+		return []*url.URL{addr}, nil
+	}
+	backend, err := app.GetByName(ctx, w.BackendApp)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get maintenance backend app %q", w.BackendApp)
+	}
+	units, err := backend.Units()
+	if err != nil {
+		return nil, err
+	}
+	var addresses []*url.URL
+	for _, u := range units {
+		if u.Address != nil {
+			addresses = append(addresses, u.Address)
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, errors.Errorf("maintenance backend app %q has no units", w.BackendApp)
+	}
+	return addresses, nil
+}
+
+// routeTo replaces the routes of the app in all its routers by addresses.
+// Later route rebuilds keep the addresses while the maintenance state is set
+// in the app.
+func routeTo(ctx context.Context, a *app.App, addresses []*url.URL) error {
+	for _, appRouter := range a.GetRouters() {
+		r, err := router.Get(ctx, appRouter.Name)
+		if err != nil {
+			return err
+		}
+		if _, isRouterV2 := r.(router.RouterV2); isRouterV2 {
+			return errors.Errorf("router %s does not support maintenance windows", appRouter.Name)
+		}
+		oldRoutes, err := r.Routes(ctx, a)
+		if err != nil {
+			return err
+		}
+		err = r.RemoveRoutes(ctx, a, oldRoutes)
+		if err != nil {
+			return err
+		}
+		err = r.AddRoutes(ctx, a, addresses)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package maintenance
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/version"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/rebuild"
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"golang.org/x/crypto/bcrypt"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage     *db.Storage
+	user        *auth.User
+	mockService servicemock.MockService
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_maintenance_tests")
+	config.Set("routers:fake:type", "fake")
+	config.Set("auth:hash-cost", bcrypt.MinCost)
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+	provision.DefaultProvisioner = "fake"
+	app.AuthScheme = auth.ManagedScheme(native.NativeScheme{})
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	provisiontest.ProvisionerInstance.Reset()
+	routertest.FakeRouter.Reset()
+	s.user, _ = permissiontest.CustomUserWithPermission(c, app.AuthScheme, "majortom", permission.Permission{
+		Scheme:  permission.PermAll,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "p1", Default: true})
+	c.Assert(err, check.IsNil)
+	servicemock.SetMockService(&s.mockService)
+	plan := appTypes.Plan{Name: "default", Default: true, CpuShare: 100}
+	s.mockService.Plan.OnList = func() ([]appTypes.Plan, error) {
+		return []appTypes.Plan{plan}, nil
+	}
+	s.mockService.Plan.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &plan, nil
+	}
+	servicemanager.AppVersion, err = version.AppVersionService()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("maintenance:responder")
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+	s.storage.Close()
+}
+
+func (s *S) createApp(c *check.C, name string) *app.App {
+	a := app.App{Name: name, Platform: "python", TeamOwner: "myteam"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestScheduleInvalidWindow(c *check.C) {
+	now := time.Now()
+	err := Schedule(&Window{App: "myapp", Start: now, End: now.Add(-time.Minute)})
+	c.Assert(err, check.Equals, ErrInvalidWindow)
+	err = Schedule(&Window{App: "myapp", Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)})
+	c.Assert(err, check.Equals, ErrInvalidWindow)
+}
+
+func (s *S) TestScheduleOverlapping(c *check.C) {
+	now := time.Now()
+	err := Schedule(&Window{App: "myapp", Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)})
+	c.Assert(err, check.IsNil)
+	err = Schedule(&Window{App: "myapp", Start: now.Add(90 * time.Minute), End: now.Add(3 * time.Hour)})
+	c.Assert(err, check.Equals, ErrWindowOverlaps)
+	err = Schedule(&Window{App: "otherapp", Start: now.Add(90 * time.Minute), End: now.Add(3 * time.Hour)})
+	c.Assert(err, check.IsNil)
+	windows, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(windows, check.HasLen, 1)
+	c.Assert(windows[0].Status, check.Equals, StatusScheduled)
+}
+
+func (s *S) TestCancel(c *check.C) {
+	now := time.Now()
+	w := Window{App: "myapp", Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)}
+	err := Schedule(&w)
+	c.Assert(err, check.IsNil)
+	c.Assert(Cancel("otherapp", w.ID.Hex()), check.Equals, ErrWindowNotFound)
+	c.Assert(Cancel("myapp", "invalid"), check.Equals, ErrWindowNotFound)
+	err = Cancel("myapp", w.ID.Hex())
+	c.Assert(err, check.IsNil)
+	windows, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(windows, check.HasLen, 1)
+	c.Assert(windows[0].Status, check.Equals, StatusCanceled)
+	c.Assert(Cancel("myapp", w.ID.Hex()), check.Equals, ErrWindowNotFound)
+}
+
+func (s *S) TestRunOnceStartsAndEndsWindow(c *check.C) {
+	config.Set("maintenance:responder:address", "http://maintenance.tsuru:8080")
+	s.createApp(c, "myapp")
+	now := time.Now()
+	w := Window{App: "myapp", Start: now.Add(-time.Minute), End: now.Add(time.Hour)}
+	err := Schedule(&w)
+	c.Assert(err, check.IsNil)
+	runOnce()
+	c.Assert(routertest.FakeRouter.HasRoute("myapp", "http://maintenance.tsuru:8080"), check.Equals, true)
+	windows, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(windows[0].Status, check.Equals, StatusActive)
+	err = Cancel("myapp", w.ID.Hex())
+	c.Assert(err, check.IsNil)
+	runOnce()
+	c.Assert(routertest.FakeRouter.HasRoute("myapp", "http://maintenance.tsuru:8080"), check.Equals, false)
+	windows, err = List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(windows[0].Status, check.Equals, StatusFinished)
+	evts, err := event.List(&event.Filter{Target: event.Target{Type: event.TargetTypeApp, Value: "myapp"}})
+	c.Assert(err, check.IsNil)
+	var kinds []string
+	for _, evt := range evts {
+		kinds = append(kinds, evt.Kind.Name)
+	}
+	c.Assert(kinds, check.DeepEquals, []string{eventKindEnd, eventKindStart})
+}
+
+func (s *S) TestRouteRebuildKeepsMaintenanceBackend(c *check.C) {
+	config.Set("maintenance:responder:address", "http://maintenance.tsuru:8080")
+	a := s.createApp(c, "myapp")
+	err := provisiontest.ProvisionerInstance.AddUnits(context.TODO(), a, 1, "web", nil, nil)
+	c.Assert(err, check.IsNil)
+	now := time.Now()
+	w := Window{App: "myapp", Start: now.Add(-time.Minute), End: now.Add(time.Hour)}
+	err = Schedule(&w)
+	c.Assert(err, check.IsNil)
+	runOnce()
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.UnderMaintenance(), check.Equals, true)
+	units, err := a.Units()
+	c.Assert(err, check.IsNil)
+	_, err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{App: a})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.FakeRouter.HasRoute("myapp", "http://maintenance.tsuru:8080"), check.Equals, true)
+	c.Assert(routertest.FakeRouter.HasRoute("myapp", units[0].Address.String()), check.Equals, false)
+	err = Cancel("myapp", w.ID.Hex())
+	c.Assert(err, check.IsNil)
+	runOnce()
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.UnderMaintenance(), check.Equals, false)
+}
+
+func (s *S) TestRunOnceWithoutBackend(c *check.C) {
+	s.createApp(c, "myapp")
+	now := time.Now()
+	err := Schedule(&Window{App: "myapp", Start: now.Add(-time.Minute), End: now.Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+	runOnce()
+	windows, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(windows[0].Status, check.Equals, StatusFailed)
+	c.Assert(windows[0].Error, check.Equals, ErrNoBackendAddress.Error())
+}
+
+func (s *S) TestResponder(c *check.C) {
+	config.Set("maintenance:responder:retry-after", "120")
+	handler, err := NewResponder()
+	c.Assert(err, check.IsNil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Assert(recorder.Code, check.Equals, http.StatusServiceUnavailable)
+	c.Assert(recorder.Header().Get("Retry-After"), check.Equals, "120")
+	body, err := io.ReadAll(recorder.Body)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(body), check.Equals, defaultPage)
+}
+
+func (s *S) TestActiveAndDeferRestart(c *check.C) {
+	config.Set("maintenance:responder:address", "http://maintenance.tsuru:8080")
+	s.createApp(c, "myapp")
+	active, err := Active("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(active, check.IsNil)
+	now := time.Now()
+	err = Schedule(&Window{App: "myapp", Start: now.Add(-time.Minute), End: now.Add(time.Hour)})
+	c.Assert(err, check.IsNil)
+	runOnce()
+	active, err = Active("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(active, check.NotNil)
+	c.Assert(active.RestartOnEnd, check.Equals, false)
+	err = DeferRestart(active)
+	c.Assert(err, check.IsNil)
+	windows, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(windows[0].RestartOnEnd, check.Equals, true)
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"net/url"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// MaintenanceState is kept in the app while a maintenance window is open, so
+// that route rebuilds, deploys and unit changes route traffic to the
+// maintenance backend instead of the app units.
+type MaintenanceState struct {
+	Window    string   `json:"window"`
+	Addresses []string `json:"addresses"`
+}
+
+// UnderMaintenance returns whether the app routes point to a maintenance
+// backend.
+func (app *App) UnderMaintenance() bool {
+	return app.Maintenance != nil
+}
+
+// SetMaintenance stores the maintenance state of the app, a nil state
+// meaning the app is not under maintenance. Routes are not rebuilt, it's up
+// to the caller to do so.
+func (app *App) SetMaintenance(state *MaintenanceState) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"$set": bson.M{"maintenance": state}}
+	if state == nil {
+		update = bson.M{"$unset": bson.M{"maintenance": ""}}
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.Maintenance = state
+	return nil
+}
+
+// maintenanceAddresses replaces the addresses of every prefix by the
+// maintenance backend addresses.
+func (app *App) maintenanceAddresses(routable []appTypes.RoutableAddresses) ([]appTypes.RoutableAddresses, error) {
+	backend := make([]*url.URL, 0, len(app.Maintenance.Addresses))
+	for _, addr := range app.Maintenance.Addresses {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, err
+		}
+		backend = append(backend, u)
+	}
+	if len(routable) == 0 {
+		routable = []appTypes.RoutableAddresses{{}}
+	}
+	result := make([]appTypes.RoutableAddresses, len(routable))
+	for i, r := range routable {
+		r.Addresses = backend
+		result[i] = r
+	}
+	return result, nil
+}
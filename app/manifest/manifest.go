@@ -0,0 +1,251 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package manifest computes the changes needed to converge an app to a
+// declarative manifest, allowing apps to be managed by GitOps pipelines.
+package manifest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+var ErrNameRequired = errors.New("app name is required in the manifest")
+
+// Manifest is the declarative description of an app. Empty fields are left
+// untouched when applied to an existing app.
+type Manifest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Platform    string            `json:"platform,omitempty"`
+	Pool        string            `json:"pool,omitempty"`
+	Plan        string            `json:"plan,omitempty"`
+	TeamOwner   string            `json:"team_owner,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	PrivateEnv  []string          `json:"private_env,omitempty"`
+	Units       map[string]uint   `json:"units,omitempty"`
+	Bindings    []Binding         `json:"bindings,omitempty"`
+	CNames      []string          `json:"cnames,omitempty"`
+}
+
+type Binding struct {
+	Service  string `json:"service"`
+	Instance string `json:"instance"`
+}
+
+func (b Binding) String() string {
+	return b.Service + "/" + b.Instance
+}
+
+type ChangeKind string
+
+const (
+	ChangeCreate      = ChangeKind("create")
+	ChangeUpdate      = ChangeKind("update")
+	ChangeEnvSet      = ChangeKind("env-set")
+	ChangeEnvUnset    = ChangeKind("env-unset")
+	ChangeBind        = ChangeKind("bind")
+	ChangeUnbind      = ChangeKind("unbind")
+	ChangeCNameAdd    = ChangeKind("cname-add")
+	ChangeCNameRemove = ChangeKind("cname-remove")
+	ChangeUnitsAdd    = ChangeKind("units-add")
+	ChangeUnitsRemove = ChangeKind("units-remove")
+)
+
+// Change is a single step needed to converge an app to its manifest.
+// Environment variable values are never part of a change, only their names.
+type Change struct {
+	Kind    ChangeKind `json:"kind"`
+	Fields  []string   `json:"fields,omitempty"`
+	Names   []string   `json:"names,omitempty"`
+	Binding *Binding   `json:"binding,omitempty"`
+	Process string     `json:"process,omitempty"`
+	Units   uint       `json:"units,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeCreate:
+		return "create app"
+	case ChangeUpdate:
+		return fmt.Sprintf("update %s", strings.Join(c.Fields, ", "))
+	case ChangeEnvSet, ChangeEnvUnset, ChangeCNameAdd, ChangeCNameRemove:
+		return fmt.Sprintf("%s %s", c.Kind, strings.Join(c.Names, ", "))
+	case ChangeBind, ChangeUnbind:
+		return fmt.Sprintf("%s %s", c.Kind, c.Binding)
+	case ChangeUnitsAdd, ChangeUnitsRemove:
+		return fmt.Sprintf("%s %d to process %q", c.Kind, c.Units, c.Process)
+	}
+	return string(c.Kind)
+}
+
+// IsPrivateEnv returns whether the manifest marks the environment variable
+// as private. Variables not marked keep their current visibility when
+// updated, and new ones are public.
+func (m *Manifest) IsPrivateEnv(name string) bool {
+	for _, n := range m.PrivateEnv {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse reads a manifest in either YAML or JSON format.
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	err := yaml.Unmarshal(data, &m)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid manifest")
+	}
+	if m.Name == "" {
+		return nil, ErrNameRequired
+	}
+	return &m, nil
+}
+
+// Diff returns the changes needed to converge current into wanted. A nil
+// current means the app does not exist yet. Environment variables, bindings
+// and cnames not present in wanted are only removed when prune is true.
+func Diff(current *Manifest, wanted Manifest, prune bool) []Change {
+	var changes []Change
+	if current == nil {
+		changes = append(changes, Change{Kind: ChangeCreate})
+		current = &Manifest{
+			Name:        wanted.Name,
+			Description: wanted.Description,
+			Platform:    wanted.Platform,
+			Pool:        wanted.Pool,
+			Plan:        wanted.Plan,
+			TeamOwner:   wanted.TeamOwner,
+			Tags:        wanted.Tags,
+		}
+	}
+	var fields []string
+	if wanted.Description != "" && wanted.Description != current.Description {
+		fields = append(fields, "description")
+	}
+	if wanted.Platform != "" && wanted.Platform != current.Platform {
+		fields = append(fields, "platform")
+	}
+	if wanted.Pool != "" && wanted.Pool != current.Pool {
+		fields = append(fields, "pool")
+	}
+	if wanted.Plan != "" && wanted.Plan != current.Plan {
+		fields = append(fields, "plan")
+	}
+	if wanted.TeamOwner != "" && wanted.TeamOwner != current.TeamOwner {
+		fields = append(fields, "team_owner")
+	}
+	if wanted.Tags != nil && !sameSet(wanted.Tags, current.Tags) {
+		fields = append(fields, "tags")
+	}
+	if len(fields) > 0 {
+		changes = append(changes, Change{Kind: ChangeUpdate, Fields: fields})
+	}
+	var envSet, envUnset []string
+	for name, value := range wanted.Env {
+		currentValue, ok := current.Env[name]
+		if !ok || currentValue != value || (wanted.IsPrivateEnv(name) && !current.IsPrivateEnv(name)) {
+			envSet = append(envSet, name)
+		}
+	}
+	if prune {
+		for name := range current.Env {
+			if _, ok := wanted.Env[name]; !ok {
+				envUnset = append(envUnset, name)
+			}
+		}
+	}
+	if len(envSet) > 0 {
+		sort.Strings(envSet)
+		changes = append(changes, Change{Kind: ChangeEnvSet, Names: envSet})
+	}
+	if len(envUnset) > 0 {
+		sort.Strings(envUnset)
+		changes = append(changes, Change{Kind: ChangeEnvUnset, Names: envUnset})
+	}
+	currentBindings := make(map[Binding]struct{}, len(current.Bindings))
+	for _, b := range current.Bindings {
+		currentBindings[b] = struct{}{}
+	}
+	wantedBindings := make(map[Binding]struct{}, len(wanted.Bindings))
+	for _, b := range wanted.Bindings {
+		wantedBindings[b] = struct{}{}
+	}
+	for _, b := range sortedBindings(wantedBindings) {
+		if _, ok := currentBindings[b]; !ok {
+			b := b
+			changes = append(changes, Change{Kind: ChangeBind, Binding: &b})
+		}
+	}
+	if prune {
+		for _, b := range sortedBindings(currentBindings) {
+			if _, ok := wantedBindings[b]; !ok {
+				b := b
+				changes = append(changes, Change{Kind: ChangeUnbind, Binding: &b})
+			}
+		}
+	}
+	if cnames := difference(wanted.CNames, current.CNames); len(cnames) > 0 {
+		changes = append(changes, Change{Kind: ChangeCNameAdd, Names: cnames})
+	}
+	if prune {
+		if cnames := difference(current.CNames, wanted.CNames); len(cnames) > 0 {
+			changes = append(changes, Change{Kind: ChangeCNameRemove, Names: cnames})
+		}
+	}
+	processes := make([]string, 0, len(wanted.Units))
+	for process := range wanted.Units {
+		processes = append(processes, process)
+	}
+	sort.Strings(processes)
+	for _, process := range processes {
+		wantedUnits, currentUnits := wanted.Units[process], current.Units[process]
+		switch {
+		case wantedUnits > currentUnits:
+			changes = append(changes, Change{Kind: ChangeUnitsAdd, Process: process, Units: wantedUnits - currentUnits})
+		case wantedUnits < currentUnits:
+			changes = append(changes, Change{Kind: ChangeUnitsRemove, Process: process, Units: currentUnits - wantedUnits})
+		}
+	}
+	return changes
+}
+
+func sameSet(a, b []string) bool {
+	return len(difference(a, b)) == 0 && len(difference(b, a)) == 0
+}
+
+// difference returns the sorted values in a that are not in b.
+func difference(a, b []string) []string {
+	inB := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		inB[v] = struct{}{}
+	}
+	var result []string
+	for _, v := range a {
+		if _, ok := inB[v]; !ok {
+			result = append(result, v)
+			inB[v] = struct{}{}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func sortedBindings(bindings map[Binding]struct{}) []Binding {
+	result := make([]Binding, 0, len(bindings))
+	for b := range bindings {
+		result = append(result, b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
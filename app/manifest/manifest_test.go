@@ -0,0 +1,155 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest
+
+import (
+	"testing"
+
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TestParseYAML(c *check.C) {
+	m, err := Parse([]byte(`
+name: myapp
+platform: python
+pool: pool1
+env:
+  DEBUG: "1"
+units:
+  web: 2
+bindings:
+  - service: mysql
+    instance: db
+cnames:
+  - myapp.example.com
+`))
+	c.Assert(err, check.IsNil)
+	c.Assert(m, check.DeepEquals, &Manifest{
+		Name:     "myapp",
+		Platform: "python",
+		Pool:     "pool1",
+		Env:      map[string]string{"DEBUG": "1"},
+		Units:    map[string]uint{"web": 2},
+		Bindings: []Binding{{Service: "mysql", Instance: "db"}},
+		CNames:   []string{"myapp.example.com"},
+	})
+}
+
+func (s *S) TestParseJSON(c *check.C) {
+	m, err := Parse([]byte(`{"name": "myapp", "units": {"worker": 1}}`))
+	c.Assert(err, check.IsNil)
+	c.Assert(m, check.DeepEquals, &Manifest{Name: "myapp", Units: map[string]uint{"worker": 1}})
+}
+
+func (s *S) TestParseWithoutName(c *check.C) {
+	_, err := Parse([]byte(`platform: python`))
+	c.Assert(err, check.Equals, ErrNameRequired)
+}
+
+func (s *S) TestDiffNewApp(c *check.C) {
+	wanted := Manifest{
+		Name:     "myapp",
+		Platform: "python",
+		Env:      map[string]string{"B": "2", "A": "1"},
+		Units:    map[string]uint{"web": 2},
+		Bindings: []Binding{{Service: "mysql", Instance: "db"}},
+		CNames:   []string{"myapp.example.com"},
+	}
+	changes := Diff(nil, wanted, false)
+	c.Assert(changes, check.DeepEquals, []Change{
+		{Kind: ChangeCreate},
+		{Kind: ChangeEnvSet, Names: []string{"A", "B"}},
+		{Kind: ChangeBind, Binding: &Binding{Service: "mysql", Instance: "db"}},
+		{Kind: ChangeCNameAdd, Names: []string{"myapp.example.com"}},
+		{Kind: ChangeUnitsAdd, Process: "web", Units: 2},
+	})
+}
+
+func (s *S) TestDiffExistingApp(c *check.C) {
+	current := &Manifest{
+		Name:        "myapp",
+		Description: "old",
+		Platform:    "python",
+		Pool:        "pool1",
+		Tags:        []string{"a"},
+		Env:         map[string]string{"A": "1", "B": "2", "C": "3"},
+		Units:       map[string]uint{"web": 3, "worker": 1},
+		Bindings:    []Binding{{Service: "mysql", Instance: "db"}, {Service: "redis", Instance: "cache"}},
+		CNames:      []string{"a.example.com", "b.example.com"},
+	}
+	wanted := Manifest{
+		Name:        "myapp",
+		Description: "new",
+		Pool:        "pool1",
+		Tags:        []string{"a", "b"},
+		Env:         map[string]string{"A": "1", "B": "changed"},
+		Units:       map[string]uint{"web": 1, "worker": 1},
+		Bindings:    []Binding{{Service: "mysql", Instance: "db"}},
+		CNames:      []string{"a.example.com"},
+	}
+	changes := Diff(current, wanted, false)
+	c.Assert(changes, check.DeepEquals, []Change{
+		{Kind: ChangeUpdate, Fields: []string{"description", "tags"}},
+		{Kind: ChangeEnvSet, Names: []string{"B"}},
+		{Kind: ChangeUnitsRemove, Process: "web", Units: 2},
+	})
+	changes = Diff(current, wanted, true)
+	c.Assert(changes, check.DeepEquals, []Change{
+		{Kind: ChangeUpdate, Fields: []string{"description", "tags"}},
+		{Kind: ChangeEnvSet, Names: []string{"B"}},
+		{Kind: ChangeEnvUnset, Names: []string{"C"}},
+		{Kind: ChangeUnbind, Binding: &Binding{Service: "redis", Instance: "cache"}},
+		{Kind: ChangeCNameRemove, Names: []string{"b.example.com"}},
+		{Kind: ChangeUnitsRemove, Process: "web", Units: 2},
+	})
+}
+
+func (s *S) TestDiffPrivateEnv(c *check.C) {
+	current := &Manifest{
+		Name:       "myapp",
+		Env:        map[string]string{"A": "1", "B": "2"},
+		PrivateEnv: []string{"B"},
+	}
+	wanted := Manifest{
+		Name:       "myapp",
+		Env:        map[string]string{"A": "1", "B": "2"},
+		PrivateEnv: []string{"A"},
+	}
+	c.Assert(Diff(current, wanted, false), check.DeepEquals, []Change{
+		{Kind: ChangeEnvSet, Names: []string{"A"}},
+	})
+	wanted.PrivateEnv = nil
+	c.Assert(Diff(current, wanted, false), check.HasLen, 0)
+}
+
+func (s *S) TestDiffNoChanges(c *check.C) {
+	current := &Manifest{
+		Name:  "myapp",
+		Tags:  []string{"b", "a"},
+		Env:   map[string]string{"A": "1"},
+		Units: map[string]uint{"web": 1},
+	}
+	wanted := Manifest{
+		Name:  "myapp",
+		Tags:  []string{"a", "b"},
+		Env:   map[string]string{"A": "1"},
+		Units: map[string]uint{"web": 1},
+	}
+	c.Assert(Diff(current, wanted, true), check.HasLen, 0)
+}
+
+func (s *S) TestChangeString(c *check.C) {
+	c.Assert(Change{Kind: ChangeCreate}.String(), check.Equals, "create app")
+	c.Assert(Change{Kind: ChangeUpdate, Fields: []string{"pool", "plan"}}.String(), check.Equals, "update pool, plan")
+	c.Assert(Change{Kind: ChangeEnvSet, Names: []string{"A", "B"}}.String(), check.Equals, "env-set A, B")
+	c.Assert(Change{Kind: ChangeBind, Binding: &Binding{Service: "mysql", Instance: "db"}}.String(), check.Equals, "bind mysql/db")
+	c.Assert(Change{Kind: ChangeUnitsAdd, Process: "web", Units: 2}.String(), check.Equals, `units-add 2 to process "web"`)
+}
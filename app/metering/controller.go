@@ -0,0 +1,96 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metering
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/api/tracker"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/log"
+)
+
+const defaultInterval = time.Minute
+
+// Initialize starts the controller sampling the usage of all apps, when
+// enabled by the metering:enabled config entry.
+func Initialize() error {
+	enabled, _ := config.GetBool("metering:enabled")
+	if !enabled {
+		return nil
+	}
+	interval, _ := config.GetDuration("metering:interval")
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	c := &controller{interval: interval}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type controller struct {
+	interval time.Duration
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+func (c *controller) start() {
+	c.shutdown = make(chan struct{})
+	c.done = make(chan struct{})
+	log.Debugf("[metering] starting. Running every %s.", c.interval)
+	go func() {
+		defer close(c.done)
+		for {
+			c.run(time.Now().UTC().Truncate(c.interval))
+			select {
+			case <-time.After(c.interval):
+			case <-c.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (c *controller) run(window time.Time) {
+	if !tracker.IsLeader("metering") {
+		return
+	}
+	apps, err := app.List(context.Background(), nil)
+	if err != nil {
+		log.Errorf("[metering] unable to list apps: %v", err)
+		return
+	}
+	for i := range apps {
+		select {
+		case <-c.shutdown:
+			return
+		default:
+		}
+		usage, err := apps[i].UnitsUsage()
+		if err != nil {
+			log.Errorf("[metering] unable to sample usage of app %q: %v", apps[i].Name, err)
+			continue
+		}
+		err = Record(&apps[i], window, c.interval, usage)
+		if err != nil {
+			log.Errorf("[metering] unable to record usage of app %q: %v", apps[i].Name, err)
+		}
+	}
+}
+
+// Shutdown stops the controller, waiting for the current app to be
+// handled.
+func (c *controller) Shutdown(ctx context.Context) error {
+	close(c.shutdown)
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,261 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package metering samples the resources used by app units and aggregates
+// them daily per app, allowing platform teams to allocate the costs of the
+// cluster to the teams and pools running the apps.
+package metering
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/provision"
+)
+
+const (
+	samplesCollectionName = "usage_samples"
+	dailyCollectionName   = "usage_daily"
+
+	samplesRetention = 48 * time.Hour
+
+	DateFormat = "2006-01-02"
+
+	GroupByApp  = "app"
+	GroupByTeam = "team"
+	GroupByPool = "pool"
+)
+
+var ErrInvalidGroupBy = errors.New("invalid group, possible values are app, team or pool")
+
+// sample is the last seen network counters of an unit in a sampling window.
+// Its id includes the window, so only one API instance meters each window.
+type sample struct {
+	ID             string `bson:"_id"`
+	App            string
+	Unit           string
+	Time           time.Time
+	NetworkRxBytes int64
+	NetworkTxBytes int64
+}
+
+// DailyUsage is the resources used by an app in a day.
+type DailyUsage struct {
+	ID             string  `bson:"_id" json:"-"`
+	App            string  `json:"app"`
+	Team           string  `json:"team"`
+	Pool           string  `json:"pool"`
+	Date           string  `json:"date"`
+	CPUSeconds     float64 `json:"cpuSeconds"`
+	MemoryGBHours  float64 `json:"memoryGBHours"`
+	NetworkRxBytes int64   `json:"networkRxBytes"`
+	NetworkTxBytes int64   `json:"networkTxBytes"`
+}
+
+// UsageRecord is the resources used by a group of apps, in a day or in the
+// whole requested period.
+type UsageRecord struct {
+	Group          string  `json:"group"`
+	Date           string  `json:"date,omitempty"`
+	CPUSeconds     float64 `json:"cpuSeconds"`
+	MemoryGBHours  float64 `json:"memoryGBHours"`
+	NetworkRxBytes int64   `json:"networkRxBytes"`
+	NetworkTxBytes int64   `json:"networkTxBytes"`
+}
+
+// Filter selects the daily usage aggregated by Usage. Dates are in the
+// DateFormat and are inclusive. A nil Apps selects all apps.
+type Filter struct {
+	Since   string
+	Until   string
+	Apps    []string
+	GroupBy string
+	Daily   bool
+}
+
+func samplesCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection(samplesCollectionName)
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"unit", "-time"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"time"}, ExpireAfter: samplesRetention})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func dailyCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection(dailyCollectionName)
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"date", "app"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+// Record meters the usage sampled from the app units in the sampling window
+// starting at window, with the given duration. CPU and memory are assumed
+// constant in the window, while network usage is the difference from the
+// last sample of each unit. Units already metered in the window, by another
+// API instance, are ignored.
+func Record(a *app.App, window time.Time, duration time.Duration, usage []provision.UnitUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+	samples, err := samplesCollection()
+	if err != nil {
+		return err
+	}
+	defer samples.Close()
+	daily, err := dailyCollection()
+	if err != nil {
+		return err
+	}
+	defer daily.Close()
+	window = window.UTC()
+	var cpuSeconds, memoryGBHours float64
+	var rxBytes, txBytes int64
+	for _, u := range usage {
+		current := sample{
+			ID:             fmt.Sprintf("%s/%d", u.ID, window.Unix()),
+			App:            a.Name,
+			Unit:           u.ID,
+			Time:           window,
+			NetworkRxBytes: int64(u.NetworkRxBytes),
+			NetworkTxBytes: int64(u.NetworkTxBytes),
+		}
+		var previous sample
+		err = samples.Find(bson.M{"unit": u.ID, "time": bson.M{"$lt": window}}).Sort("-time").One(&previous)
+		if err != nil && err != mgo.ErrNotFound {
+			return err
+		}
+		hasPrevious := err == nil
+		err = samples.Insert(current)
+		if mgo.IsDup(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		cpuSeconds += u.CPU * duration.Seconds()
+		memoryGBHours += float64(u.Memory) / 1e9 * duration.Hours()
+		if hasPrevious {
+			rxBytes += counterDelta(previous.NetworkRxBytes, current.NetworkRxBytes)
+			txBytes += counterDelta(previous.NetworkTxBytes, current.NetworkTxBytes)
+		}
+	}
+	date := window.Format(DateFormat)
+	_, err = daily.UpsertId(a.Name+"/"+date, bson.M{
+		"$set": bson.M{"app": a.Name, "team": a.TeamOwner, "pool": a.Pool, "date": date},
+		"$inc": bson.M{
+			"cpuseconds":     cpuSeconds,
+			"memorygbhours":  memoryGBHours,
+			"networkrxbytes": rxBytes,
+			"networktxbytes": txBytes,
+		},
+	})
+	return err
+}
+
+// counterDelta returns the increase of a cumulative counter, which is reset
+// when the unit restarts.
+func counterDelta(previous, current int64) int64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+// Usage aggregates the daily usage matching the filter by app, team or pool,
+// and also by day when filter.Daily is set.
+func Usage(filter Filter) ([]UsageRecord, error) {
+	switch filter.GroupBy {
+	case "":
+		filter.GroupBy = GroupByApp
+	case GroupByApp, GroupByTeam, GroupByPool:
+	default:
+		return nil, ErrInvalidGroupBy
+	}
+	match := bson.M{}
+	dateQuery := bson.M{}
+	if filter.Since != "" {
+		dateQuery["$gte"] = filter.Since
+	}
+	if filter.Until != "" {
+		dateQuery["$lte"] = filter.Until
+	}
+	if len(dateQuery) > 0 {
+		match["date"] = dateQuery
+	}
+	if filter.Apps != nil {
+		match["app"] = bson.M{"$in": filter.Apps}
+	}
+	groupID := bson.M{"group": "$" + filter.GroupBy}
+	sortBy := bson.D{{Name: "_id.group", Value: 1}}
+	if filter.Daily {
+		groupID["date"] = "$date"
+		sortBy = bson.D{{Name: "_id.date", Value: 1}, {Name: "_id.group", Value: 1}}
+	}
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id":            groupID,
+			"cpuseconds":     bson.M{"$sum": "$cpuseconds"},
+			"memorygbhours":  bson.M{"$sum": "$memorygbhours"},
+			"networkrxbytes": bson.M{"$sum": "$networkrxbytes"},
+			"networktxbytes": bson.M{"$sum": "$networktxbytes"},
+		}},
+		{"$sort": sortBy},
+	}
+	coll, err := dailyCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var results []struct {
+		ID struct {
+			Group string
+			Date  string
+		} `bson:"_id"`
+		CPUSeconds     float64
+		MemoryGBHours  float64
+		NetworkRxBytes int64
+		NetworkTxBytes int64
+	}
+	err = coll.Pipe(pipeline).All(&results)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]UsageRecord, len(results))
+	for i, r := range results {
+		records[i] = UsageRecord{
+			Group:          r.ID.Group,
+			Date:           r.ID.Date,
+			CPUSeconds:     r.CPUSeconds,
+			MemoryGBHours:  r.MemoryGBHours,
+			NetworkRxBytes: r.NetworkRxBytes,
+			NetworkTxBytes: r.NetworkTxBytes,
+		}
+	}
+	return records, nil
+}
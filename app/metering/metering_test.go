@@ -0,0 +1,127 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metering
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	"github.com/tsuru/tsuru/provision"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage *db.Storage
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_metering_tests")
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	s.storage.Close()
+}
+
+func (s *S) TestRecord(c *check.C) {
+	a := &app.App{Name: "myapp", TeamOwner: "team1", Pool: "pool1"}
+	window := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	usage := []provision.UnitUsage{
+		{ID: "u1", Process: "web", CPU: 0.5, Memory: 2e9, NetworkRxBytes: 100, NetworkTxBytes: 200},
+		{ID: "u2", Process: "web", CPU: 1, Memory: 1e9, NetworkRxBytes: 1000, NetworkTxBytes: 2000},
+	}
+	err := Record(a, window, time.Hour, usage)
+	c.Assert(err, check.IsNil)
+	usage[0].NetworkRxBytes, usage[0].NetworkTxBytes = 150, 300
+	usage[1].NetworkRxBytes, usage[1].NetworkTxBytes = 10, 20
+	err = Record(a, window.Add(time.Hour), time.Hour, usage)
+	c.Assert(err, check.IsNil)
+	var daily []DailyUsage
+	err = s.storage.Collection(dailyCollectionName).Find(nil).All(&daily)
+	c.Assert(err, check.IsNil)
+	c.Assert(daily, check.DeepEquals, []DailyUsage{{
+		ID:             "myapp/2026-03-10",
+		App:            "myapp",
+		Team:           "team1",
+		Pool:           "pool1",
+		Date:           "2026-03-10",
+		CPUSeconds:     10800,
+		MemoryGBHours:  6,
+		NetworkRxBytes: 60,
+		NetworkTxBytes: 120,
+	}})
+}
+
+func (s *S) TestRecordAlreadyMeteredWindow(c *check.C) {
+	a := &app.App{Name: "myapp", TeamOwner: "team1", Pool: "pool1"}
+	window := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	usage := []provision.UnitUsage{{ID: "u1", CPU: 1, Memory: 1e9}}
+	err := Record(a, window, time.Minute, usage)
+	c.Assert(err, check.IsNil)
+	err = Record(a, window, time.Minute, usage)
+	c.Assert(err, check.IsNil)
+	records, err := Usage(Filter{})
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.HasLen, 1)
+	c.Assert(records[0].CPUSeconds, check.Equals, float64(60))
+}
+
+func (s *S) TestUsage(c *check.C) {
+	apps := []*app.App{
+		{Name: "app1", TeamOwner: "team1", Pool: "pool1"},
+		{Name: "app2", TeamOwner: "team1", Pool: "pool2"},
+		{Name: "app3", TeamOwner: "team2", Pool: "pool2"},
+	}
+	day1 := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	for _, window := range []time.Time{day1, day2} {
+		for i, a := range apps {
+			err := Record(a, window, time.Hour, []provision.UnitUsage{{ID: a.Name + "-u1", CPU: float64(i + 1)}})
+			c.Assert(err, check.IsNil)
+		}
+	}
+	records, err := Usage(Filter{GroupBy: GroupByTeam})
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.DeepEquals, []UsageRecord{
+		{Group: "team1", CPUSeconds: 21600},
+		{Group: "team2", CPUSeconds: 21600},
+	})
+	records, err = Usage(Filter{GroupBy: GroupByPool, Since: "2026-03-11", Daily: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.DeepEquals, []UsageRecord{
+		{Group: "pool1", Date: "2026-03-11", CPUSeconds: 3600},
+		{Group: "pool2", Date: "2026-03-11", CPUSeconds: 18000},
+	})
+	records, err = Usage(Filter{Apps: []string{"app2"}, Until: "2026-03-10", Daily: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.DeepEquals, []UsageRecord{
+		{Group: "app2", Date: "2026-03-10", CPUSeconds: 7200},
+	})
+	_, err = Usage(Filter{GroupBy: "platform"})
+	c.Assert(err, check.Equals, ErrInvalidGroupBy)
+}
+
+func (s *S) TestCounterDelta(c *check.C) {
+	c.Assert(counterDelta(100, 150), check.Equals, int64(50))
+	c.Assert(counterDelta(100, 100), check.Equals, int64(0))
+	c.Assert(counterDelta(100, 30), check.Equals, int64(30))
+}
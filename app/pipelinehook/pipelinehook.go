@@ -0,0 +1,361 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pipelinehook implements admin registered plugins called at fixed
+// stages of the deploy pipeline. Plugins are either HTTP endpoints or
+// binaries executed by tsuru, and are able to abort the deploy.
+package pipelinehook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/net"
+)
+
+const (
+	StagePreBuild      = "pre-build"
+	StagePostBuild     = "post-build"
+	StagePostRouteSwap = "post-route-swap"
+
+	KindHTTP = "http"
+	KindExec = "exec"
+
+	FailurePolicyFail   = "fail"
+	FailurePolicyIgnore = "ignore"
+
+	defaultTimeout = 30 * time.Second
+)
+
+var (
+	ErrHookNotFound      = errors.New("pipeline hook not found")
+	ErrHookAlreadyExists = errors.New("pipeline hook already exists")
+
+	stages = []string{StagePreBuild, StagePostBuild, StagePostRouteSwap}
+)
+
+// Hook is a plugin called in the given stages of the deploys of apps in
+// Pools, or of every app when Pools is empty. HTTP hooks receive the Request
+// in a POST to URL and must answer with a Response. Exec hooks run Command,
+// which must be a binary in the pipeline-hooks:exec-dir directory, with the
+// Request in the standard input, aborting the deploy when exiting with a
+// non-zero status.
+type Hook struct {
+	Name          string    `json:"name" bson:"_id" form:"name"`
+	Kind          string    `json:"kind" form:"kind"`
+	URL           string    `json:"url,omitempty" form:"url"`
+	Command       string    `json:"command,omitempty" form:"command"`
+	Args          []string  `json:"args,omitempty" form:"args"`
+	Stages        []string  `json:"stages" form:"stages"`
+	Pools         []string  `json:"pools,omitempty" form:"pools"`
+	Timeout       string    `json:"timeout,omitempty" form:"timeout"`
+	FailurePolicy string    `json:"failurePolicy,omitempty" form:"failurePolicy"`
+	UpdatedAt     time.Time `json:"updatedAt" form:"-"`
+}
+
+// Request is the payload sent to hooks.
+type Request struct {
+	Stage   string `json:"stage"`
+	App     string `json:"app"`
+	Pool    string `json:"pool"`
+	User    string `json:"user"`
+	Kind    string `json:"kind"`
+	EventID string `json:"eventID"`
+	Image   string `json:"image,omitempty"`
+	Version int    `json:"version,omitempty"`
+}
+
+// Response is the payload expected from HTTP hooks. Message is written to
+// the deploy output, and used as the abort reason when Allowed is false.
+type Response struct {
+	Allowed bool   `json:"allowed"`
+	Message string `json:"message,omitempty"`
+}
+
+// AbortedError is returned when a hook aborts the deploy.
+type AbortedError struct {
+	Hook    string
+	Stage   string
+	Message string
+}
+
+func (e *AbortedError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("deploy aborted by pipeline hook %q at %s", e.Hook, e.Stage)
+	}
+	return fmt.Sprintf("deploy aborted by pipeline hook %q at %s: %s", e.Hook, e.Stage, e.Message)
+}
+
+func hooksCollection(conn *db.Storage) *storage.Collection {
+	return conn.Collection("pipeline_hooks")
+}
+
+func validStage(stage string) bool {
+	for _, s := range stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Hook) validate() error {
+	if h.Name == "" {
+		return &tsuruErrors.ValidationError{Message: "pipeline hook name is required"}
+	}
+	if len(h.Stages) == 0 {
+		return &tsuruErrors.ValidationError{Message: "pipeline hook must have at least one stage"}
+	}
+	for _, s := range h.Stages {
+		if !validStage(s) {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid stage %q, must be one of %s", s, strings.Join(stages, ", "))}
+		}
+	}
+	switch h.Kind {
+	case KindHTTP:
+		if !strings.HasPrefix(h.URL, "http://") && !strings.HasPrefix(h.URL, "https://") {
+			return &tsuruErrors.ValidationError{Message: "http pipeline hooks require a valid url"}
+		}
+	case KindExec:
+		if h.Command == "" || strings.ContainsRune(h.Command, '/') || h.Command == "." || h.Command == ".." {
+			return &tsuruErrors.ValidationError{Message: "exec pipeline hooks require the name of a binary in the hooks directory"}
+		}
+	default:
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid kind %q, must be %s or %s", h.Kind, KindHTTP, KindExec)}
+	}
+	if h.Timeout != "" {
+		if d, err := time.ParseDuration(h.Timeout); err != nil || d <= 0 {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid timeout %q", h.Timeout)}
+		}
+	}
+	if h.FailurePolicy != "" && h.FailurePolicy != FailurePolicyFail && h.FailurePolicy != FailurePolicyIgnore {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid failure policy %q", h.FailurePolicy)}
+	}
+	return nil
+}
+
+func (h *Hook) timeout() time.Duration {
+	timeout, err := time.ParseDuration(h.Timeout)
+	if err != nil || timeout <= 0 {
+		return defaultTimeout
+	}
+	return timeout
+}
+
+func (h *Hook) handles(stage, pool string) bool {
+	if !contains(h.Stages, stage) {
+		return false
+	}
+	return len(h.Pools) == 0 || contains(h.Pools, pool)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Create registers a new pipeline hook.
+func Create(h *Hook) error {
+	if err := h.validate(); err != nil {
+		return err
+	}
+	h.UpdatedAt = time.Now().UTC()
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = hooksCollection(conn).Insert(h)
+	if mgo.IsDup(err) {
+		return ErrHookAlreadyExists
+	}
+	return err
+}
+
+// Update replaces the pipeline hook with the same name.
+func Update(h *Hook) error {
+	if err := h.validate(); err != nil {
+		return err
+	}
+	h.UpdatedAt = time.Now().UTC()
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = hooksCollection(conn).UpdateId(h.Name, h)
+	if err == mgo.ErrNotFound {
+		return ErrHookNotFound
+	}
+	return err
+}
+
+// Get returns the pipeline hook with the given name.
+func Get(name string) (*Hook, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var h Hook
+	err = hooksCollection(conn).FindId(name).One(&h)
+	if err == mgo.ErrNotFound {
+		return nil, ErrHookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// List returns every pipeline hook, sorted by name, which is also the order
+// they run in each stage.
+func List() ([]Hook, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var hooks []Hook
+	err = hooksCollection(conn).Find(nil).Sort("_id").All(&hooks)
+	if err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// Remove removes the pipeline hook with the given name.
+func Remove(name string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = hooksCollection(conn).RemoveId(name)
+	if err == mgo.ErrNotFound {
+		return ErrHookNotFound
+	}
+	return err
+}
+
+// Run calls the hooks registered for the stage of the request, in order,
+// writing their messages to w. An *AbortedError is returned when a hook
+// aborts the deploy. Hooks failing to run also abort the deploy, unless
+// their failure policy is "ignore".
+func Run(ctx context.Context, req Request, w io.Writer) error {
+	hooks, err := List()
+	if err != nil {
+		return errors.Wrap(err, "unable to load pipeline hooks")
+	}
+	if w == nil {
+		w = ioutil.Discard
+	}
+	for _, hook := range hooks {
+		if !hook.handles(req.Stage, req.Pool) {
+			continue
+		}
+		fmt.Fprintf(w, "---- Running pipeline hook %q (%s) ----\n", hook.Name, req.Stage)
+		resp, err := hook.call(ctx, req, w)
+		if err != nil {
+			if hook.FailurePolicy == FailurePolicyIgnore {
+				log.Errorf("[pipeline-hook] ignoring failure calling hook %q: %v", hook.Name, err)
+				fmt.Fprintf(w, " ---> Ignoring failure: %v\n", err)
+				continue
+			}
+			return &AbortedError{Hook: hook.Name, Stage: req.Stage, Message: err.Error()}
+		}
+		if !resp.Allowed {
+			return &AbortedError{Hook: hook.Name, Stage: req.Stage, Message: resp.Message}
+		}
+		if resp.Message != "" {
+			fmt.Fprintf(w, " ---> %s\n", resp.Message)
+		}
+	}
+	return nil
+}
+
+func (h *Hook) call(ctx context.Context, req Request, w io.Writer) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout())
+	defer cancel()
+	if h.Kind == KindExec {
+		return h.exec(ctx, body, w)
+	}
+	return h.post(ctx, body)
+}
+
+func (h *Hook) post(ctx context.Context, body []byte) (*Response, error) {
+	httpReq, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := net.Dial15Full60ClientNoKeepAlive.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	data, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d: %s", httpResp.StatusCode, data)
+	}
+	var resp Response
+	err = json.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid pipeline hook response")
+	}
+	return &resp, nil
+}
+
+// exec runs the hook binary, streaming its standard output to w. A non-zero
+// exit status aborts the deploy, using the standard error as message.
+func (h *Hook) exec(ctx context.Context, body []byte, w io.Writer) (*Response, error) {
+	dir, _ := config.GetString("pipeline-hooks:exec-dir")
+	if dir == "" {
+		return nil, errors.New("pipeline-hooks:exec-dir is not configured")
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, filepath.Join(dir, h.Command), h.Args...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, errors.Wrap(ctx.Err(), "pipeline hook interrupted")
+	}
+	if _, ok := err.(*exec.ExitError); ok {
+		return &Response{Allowed: false, Message: strings.TrimSpace(stderr.String())}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Allowed: true}, nil
+}
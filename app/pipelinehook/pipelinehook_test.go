@@ -0,0 +1,175 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pipelinehook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+type StorageSuite struct{}
+
+var _ = check.Suite(&StorageSuite{})
+
+func (s *StorageSuite) SetUpTest(c *check.C) {
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "tsuru_pipelinehook_tests")
+	conn, err := db.Conn()
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+	err = dbtest.ClearAllCollections(conn.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *StorageSuite) TearDownTest(c *check.C) {
+	config.Unset("pipeline-hooks:exec-dir")
+}
+
+func (s *S) TestValidate(c *check.C) {
+	tests := []struct {
+		hook Hook
+		msg  string
+	}{
+		{Hook{Kind: KindHTTP, URL: "http://a", Stages: []string{StagePreBuild}}, "pipeline hook name is required"},
+		{Hook{Name: "h", Kind: KindHTTP, URL: "http://a"}, "pipeline hook must have at least one stage"},
+		{Hook{Name: "h", Kind: KindHTTP, URL: "http://a", Stages: []string{"pre-deploy"}}, `invalid stage "pre-deploy", must be one of pre-build, post-build, post-route-swap`},
+		{Hook{Name: "h", Kind: KindHTTP, URL: "ftp://a", Stages: []string{StagePreBuild}}, "http pipeline hooks require a valid url"},
+		{Hook{Name: "h", Kind: KindExec, Command: "../bin/sh", Stages: []string{StagePreBuild}}, "exec pipeline hooks require the name of a binary in the hooks directory"},
+		{Hook{Name: "h", Kind: "grpc", Stages: []string{StagePreBuild}}, `invalid kind "grpc", must be http or exec`},
+		{Hook{Name: "h", Kind: KindHTTP, URL: "http://a", Stages: []string{StagePreBuild}, Timeout: "-1s"}, `invalid timeout "-1s"`},
+		{Hook{Name: "h", Kind: KindHTTP, URL: "http://a", Stages: []string{StagePreBuild}, FailurePolicy: "retry"}, `invalid failure policy "retry"`},
+		{Hook{Name: "h", Kind: KindExec, Command: "scan", Stages: []string{StagePostBuild}, Timeout: "1m"}, ""},
+	}
+	for _, tt := range tests {
+		err := tt.hook.validate()
+		if tt.msg == "" {
+			c.Check(err, check.IsNil)
+			continue
+		}
+		c.Check(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+		c.Check(err, check.ErrorMatches, tt.msg)
+	}
+}
+
+func (s *S) TestHandles(c *check.C) {
+	h := Hook{Stages: []string{StagePreBuild, StagePostBuild}}
+	c.Assert(h.handles(StagePreBuild, "pool1"), check.Equals, true)
+	c.Assert(h.handles(StagePostRouteSwap, "pool1"), check.Equals, false)
+	h.Pools = []string{"pool2"}
+	c.Assert(h.handles(StagePreBuild, "pool1"), check.Equals, false)
+	c.Assert(h.handles(StagePreBuild, "pool2"), check.Equals, true)
+}
+
+func (s *StorageSuite) TestCreateGetListRemove(c *check.C) {
+	h := Hook{Name: "scan", Kind: KindHTTP, URL: "http://scanner", Stages: []string{StagePostBuild}}
+	err := Create(&h)
+	c.Assert(err, check.IsNil)
+	err = Create(&h)
+	c.Assert(err, check.Equals, ErrHookAlreadyExists)
+	err = Create(&Hook{Name: "audit", Kind: KindExec, Command: "audit", Stages: []string{StagePreBuild}})
+	c.Assert(err, check.IsNil)
+	hooks, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(hooks, check.HasLen, 2)
+	c.Assert(hooks[0].Name, check.Equals, "audit")
+	c.Assert(hooks[1].Name, check.Equals, "scan")
+	h.Pools = []string{"prod"}
+	err = Update(&h)
+	c.Assert(err, check.IsNil)
+	dbHook, err := Get("scan")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbHook.Pools, check.DeepEquals, []string{"prod"})
+	err = Update(&Hook{Name: "other", Kind: KindHTTP, URL: "http://a", Stages: []string{StagePreBuild}})
+	c.Assert(err, check.Equals, ErrHookNotFound)
+	err = Remove("scan")
+	c.Assert(err, check.IsNil)
+	_, err = Get("scan")
+	c.Assert(err, check.Equals, ErrHookNotFound)
+	err = Remove("scan")
+	c.Assert(err, check.Equals, ErrHookNotFound)
+}
+
+func (s *StorageSuite) TestRunHTTP(c *check.C) {
+	var received []Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		received = append(received, req)
+		json.NewEncoder(w).Encode(Response{Allowed: req.Image != "bad", Message: "image " + req.Image + " checked"})
+	}))
+	defer srv.Close()
+	err := Create(&Hook{Name: "scan", Kind: KindHTTP, URL: srv.URL, Stages: []string{StagePostBuild}})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = Run(context.TODO(), Request{Stage: StagePreBuild, App: "myapp"}, &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(received, check.HasLen, 0)
+	err = Run(context.TODO(), Request{Stage: StagePostBuild, App: "myapp", Image: "good"}, &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s).*Running pipeline hook "scan" \(post-build\).*---> image good checked.*`)
+	err = Run(context.TODO(), Request{Stage: StagePostBuild, App: "myapp", Image: "bad"}, &buf)
+	c.Assert(err, check.DeepEquals, &AbortedError{Hook: "scan", Stage: StagePostBuild, Message: "image bad checked"})
+	c.Assert(received, check.HasLen, 2)
+	c.Assert(received[0].App, check.Equals, "myapp")
+}
+
+func (s *StorageSuite) TestRunHTTPFailurePolicy(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	h := Hook{Name: "scan", Kind: KindHTTP, URL: srv.URL, Stages: []string{StagePreBuild}}
+	err := Create(&h)
+	c.Assert(err, check.IsNil)
+	err = Run(context.TODO(), Request{Stage: StagePreBuild}, nil)
+	c.Assert(err, check.FitsTypeOf, &AbortedError{})
+	c.Assert(err, check.ErrorMatches, `deploy aborted by pipeline hook "scan" at pre-build: unexpected status code 500.*`)
+	h.FailurePolicy = FailurePolicyIgnore
+	err = Update(&h)
+	c.Assert(err, check.IsNil)
+	err = Run(context.TODO(), Request{Stage: StagePreBuild}, nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *StorageSuite) TestRunExec(c *check.C) {
+	dir := c.MkDir()
+	script := "#!/bin/sh\ngrep -q '\"app\":\"myapp\"' && echo checked && exit 0\necho denied for $1 >&2\nexit 1\n"
+	err := ioutil.WriteFile(filepath.Join(dir, "check"), []byte(script), 0755)
+	c.Assert(err, check.IsNil)
+	config.Set("pipeline-hooks:exec-dir", dir)
+	err = Create(&Hook{Name: "check", Kind: KindExec, Command: "check", Args: []string{"policy"}, Stages: []string{StagePreBuild}})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = Run(context.TODO(), Request{Stage: StagePreBuild, App: "myapp"}, &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s).*checked\n`)
+	err = Run(context.TODO(), Request{Stage: StagePreBuild, App: "otherapp"}, &buf)
+	c.Assert(err, check.DeepEquals, &AbortedError{Hook: "check", Stage: StagePreBuild, Message: "denied for policy"})
+}
+
+func (s *StorageSuite) TestRunExecWithoutDir(c *check.C) {
+	err := Create(&Hook{Name: "check", Kind: KindExec, Command: "check", Stages: []string{StagePreBuild}})
+	c.Assert(err, check.IsNil)
+	err = Run(context.TODO(), Request{Stage: StagePreBuild}, nil)
+	c.Assert(err, check.ErrorMatches, `.*pipeline-hooks:exec-dir is not configured`)
+}
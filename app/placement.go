@@ -0,0 +1,98 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// SetPlacementConstraints replaces the requirements on the metadata of the
+// nodes running the units of the app. At least one node of the app pool must
+// satisfy them. They're applied by the provisioner on the next deploy or
+// restart of the app.
+func (app *App) SetPlacementConstraints(constraints []appTypes.PlacementConstraint) error {
+	for _, c := range constraints {
+		err := c.Validate()
+		if err != nil {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid placement constraint %q: %s", c, err)}
+		}
+	}
+	if len(constraints) > 0 {
+		poolNodes, err := app.poolNodes()
+		if err != nil {
+			return err
+		}
+		matching := filterPlacementNodes(poolNodes, constraints)
+		if len(poolNodes) > 0 && len(matching) == 0 {
+			return &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("no node in pool %q satisfies the placement constraints", app.Pool),
+			}
+		}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"$set": bson.M{"placement": constraints}}
+	if len(constraints) == 0 {
+		update = bson.M{"$unset": bson.M{"placement": ""}}
+		constraints = nil
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.Placement = constraints
+	return nil
+}
+
+// PlacementNodes returns the nodes of the app pool satisfying the given
+// placement constraints.
+func (app *App) PlacementNodes(constraints []appTypes.PlacementConstraint) ([]provision.Node, error) {
+	poolNodes, err := app.poolNodes()
+	if err != nil {
+		return nil, err
+	}
+	return filterPlacementNodes(poolNodes, constraints), nil
+}
+
+func (app *App) poolNodes() ([]provision.Node, error) {
+	prov, err := app.getProvisioner()
+	if err != nil {
+		return nil, err
+	}
+	nodeProv, ok := prov.(provision.NodeProvisioner)
+	if !ok {
+		return nil, nil
+	}
+	nodes, err := nodeProv.ListNodes(app.Context(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var poolNodes []provision.Node
+	for _, n := range nodes {
+		if n.Pool() == app.Pool {
+			poolNodes = append(poolNodes, n)
+		}
+	}
+	return poolNodes, nil
+}
+
+func filterPlacementNodes(nodes []provision.Node, constraints []appTypes.PlacementConstraint) []provision.Node {
+	var matching []provision.Node
+	for _, n := range nodes {
+		if appTypes.PlacementMatches(constraints, n.MetadataNoPrefix()) {
+			matching = append(matching, n)
+		}
+	}
+	return matching
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetPlacementConstraints(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address:  "http://n1:2375",
+		Pool:     a.Pool,
+		Metadata: map[string]string{"disk": "ssd", "zone": "a"},
+	})
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address:  "http://n2:2375",
+		Pool:     a.Pool,
+		Metadata: map[string]string{"disk": "hdd", "zone": "b"},
+	})
+	c.Assert(err, check.IsNil)
+	constraints := []appTypes.PlacementConstraint{
+		{Key: "disk", Operator: appTypes.PlacementOpIn, Values: []string{"ssd"}},
+	}
+	nodes, err := a.PlacementNodes(constraints)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.HasLen, 1)
+	c.Assert(nodes[0].Address(), check.Equals, "http://n1:2375")
+	err = a.SetPlacementConstraints(constraints)
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.GetPlacementConstraints(), check.DeepEquals, constraints)
+	err = a.SetPlacementConstraints(nil)
+	c.Assert(err, check.IsNil)
+	dbApp, err = GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.GetPlacementConstraints(), check.IsNil)
+}
+
+func (s *S) TestSetPlacementConstraintsNoMatchingNode(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = s.provisioner.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address:  "http://n1:2375",
+		Pool:     a.Pool,
+		Metadata: map[string]string{"disk": "hdd"},
+	})
+	c.Assert(err, check.IsNil)
+	err = a.SetPlacementConstraints([]appTypes.PlacementConstraint{
+		{Key: "disk", Operator: appTypes.PlacementOpIn, Values: []string{"ssd"}},
+	})
+	c.Assert(err, check.ErrorMatches, `no node in pool ".*" satisfies the placement constraints`)
+	err = a.SetPlacementConstraints([]appTypes.PlacementConstraint{
+		{Key: "disk", Operator: "Gt", Values: []string{"1"}},
+	})
+	c.Assert(err, check.ErrorMatches, `invalid placement constraint "disk": unknown operator "Gt"`)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Placement, check.IsNil)
+}
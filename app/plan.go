@@ -7,7 +7,9 @@ package app
 import (
 	"context"
 
+	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/storage"
 	appTypes "github.com/tsuru/tsuru/types/app"
@@ -156,8 +158,7 @@ func PlanService() (appTypes.PlanService, error) {
 	return svc, nil
 }
 
-// Create implements Create method of PlanService interface
-func (s *planService) Create(ctx context.Context, plan appTypes.Plan) error {
+func validatePlan(plan appTypes.Plan) error {
 	if plan.Name == "" {
 		return appTypes.PlanValidationError{Field: "name"}
 	}
@@ -167,9 +168,58 @@ func (s *planService) Create(ctx context.Context, plan appTypes.Plan) error {
 	if plan.Memory > 0 && plan.Memory < 4194304 {
 		return appTypes.ErrLimitOfMemory
 	}
+	if plan.CPUQuota < 0 || (plan.CPUQuota > 0 && plan.CPUQuota < 1000) {
+		return appTypes.PlanValidationError{Field: "cpuquota"}
+	}
+	if plan.CPUPeriod < 0 || (plan.CPUPeriod > 0 && (plan.CPUPeriod < 1000 || plan.CPUPeriod > 1000000)) {
+		return appTypes.PlanValidationError{Field: "cpuperiod"}
+	}
+	if plan.BlkioWeight < 0 || (plan.BlkioWeight > 0 && (plan.BlkioWeight < 10 || plan.BlkioWeight > 1000)) {
+		return appTypes.PlanValidationError{Field: "blkioweight"}
+	}
+	if plan.PidsLimit < 0 {
+		return appTypes.PlanValidationError{Field: "pidslimit"}
+	}
+	return nil
+}
+
+// Create implements Create method of PlanService interface
+func (s *planService) Create(ctx context.Context, plan appTypes.Plan) error {
+	if err := validatePlan(plan); err != nil {
+		return err
+	}
 	return s.storage.Insert(ctx, plan)
 }
 
+// Update implements Update method of PlanService interface. The new limits
+// are copied to the apps using the plan, keeping their overrides, and are
+// applied to their units on the next restart.
+func (s *planService) Update(ctx context.Context, plan appTypes.Plan) error {
+	if err := validatePlan(plan); err != nil {
+		return err
+	}
+	err := s.storage.Update(ctx, plan)
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Apps().UpdateAll(bson.M{"plan.name": plan.Name}, bson.M{"$set": bson.M{
+		"plan.memory":      plan.Memory,
+		"plan.swap":        plan.Swap,
+		"plan.cpushare":    plan.CpuShare,
+		"plan.cpumilli":    plan.CPUMilli,
+		"plan.cpuquota":    plan.CPUQuota,
+		"plan.cpuperiod":   plan.CPUPeriod,
+		"plan.blkioweight": plan.BlkioWeight,
+		"plan.pidslimit":   plan.PidsLimit,
+	}})
+	return err
+}
+
 // List implements List method of PlanService interface
 func (s *planService) List(ctx context.Context) ([]appTypes.Plan, error) {
 	return s.storage.FindAll(ctx)
@@ -66,6 +66,63 @@ func (s *S) TestPlanAddInvalid(c *check.C) {
 	}
 }
 
+func (s *S) TestPlanAddInvalidLimits(c *check.C) {
+	invalidPlans := map[string]appTypes.Plan{
+		"cpuquota":    {Name: "plan1", CPUQuota: 999},
+		"cpuperiod":   {Name: "plan1", CPUPeriod: 2000000},
+		"blkioweight": {Name: "plan1", BlkioWeight: 5},
+		"pidslimit":   {Name: "plan1", PidsLimit: -1},
+	}
+	ps := &planService{
+		storage: &appTypes.MockPlanStorage{
+			OnInsert: func(appTypes.Plan) error {
+				c.Error("storage.Insert should not be called")
+				return nil
+			},
+		},
+	}
+	for field, p := range invalidPlans {
+		err := ps.Create(context.TODO(), p)
+		c.Assert(err, check.Equals, appTypes.PlanValidationError{Field: field})
+	}
+}
+
+func (s *S) TestPlanUpdate(c *check.C) {
+	a := App{Name: "app-with-plan", TeamOwner: s.team.Name, Plan: appTypes.Plan{Name: "plan1", Memory: 1024 * 1024 * 1024}}
+	a.Plan.MergeOverride(appTypes.PlanOverride{CPUMilli: func(i int) *int { return &i }(300)})
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	p := appTypes.Plan{
+		Name:        "plan1",
+		Memory:      2 * 1024 * 1024 * 1024,
+		CPUMilli:    1000,
+		CPUQuota:    50000,
+		CPUPeriod:   100000,
+		BlkioWeight: 500,
+		PidsLimit:   1024,
+	}
+	var updated appTypes.Plan
+	ps := &planService{
+		storage: &appTypes.MockPlanStorage{
+			OnUpdate: func(plan appTypes.Plan) error {
+				updated = plan
+				return nil
+			},
+		},
+	}
+	err = ps.Update(context.TODO(), p)
+	c.Assert(err, check.IsNil)
+	c.Assert(updated, check.DeepEquals, p)
+	dbApp, err := GetByName(context.TODO(), a.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.GetMemory(), check.Equals, p.Memory)
+	c.Assert(dbApp.GetMilliCPU(), check.Equals, 300)
+	c.Assert(dbApp.GetCPUQuota(), check.Equals, int64(50000))
+	c.Assert(dbApp.GetCPUPeriod(), check.Equals, int64(100000))
+	c.Assert(dbApp.GetBlkioWeight(), check.Equals, int64(500))
+	c.Assert(dbApp.GetPidsLimit(), check.Equals, int64(1024))
+}
+
 func (s *S) TestPlansList(c *check.C) {
 	ps := &planService{
 		storage: &appTypes.MockPlanStorage{
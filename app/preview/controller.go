@@ -0,0 +1,112 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package preview
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/api/tracker"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	defaultInterval = 10 * time.Minute
+	internalKind    = "preview expire"
+)
+
+// Initialize starts the controller removing expired previews, running
+// every previews:gc-interval.
+func Initialize() error {
+	interval, _ := config.GetDuration("previews:gc-interval")
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	c := &controller{interval: interval}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type controller struct {
+	interval time.Duration
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+func (c *controller) start() {
+	c.shutdown = make(chan struct{})
+	c.done = make(chan struct{})
+	log.Debugf("[previews] starting. Running every %s.", c.interval)
+	go func() {
+		defer close(c.done)
+		for {
+			c.run()
+			select {
+			case <-time.After(c.interval):
+			case <-c.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (c *controller) run() {
+	if !tracker.IsLeader("app-preview") {
+		return
+	}
+	previews, err := Expired(time.Now())
+	if err != nil {
+		log.Errorf("[previews] unable to list expired previews: %v", err)
+		return
+	}
+	for i := range previews {
+		select {
+		case <-c.shutdown:
+			return
+		default:
+		}
+		err = expire(&previews[i])
+		if err != nil {
+			log.Errorf("[previews] unable to remove expired preview %q: %v", previews[i].App, err)
+		}
+	}
+}
+
+func expire(p *Preview) (err error) {
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: p.App},
+		InternalKind: internalKind,
+		CustomData:   p,
+		Allowed: event.Allowed(permission.PermAppReadEvents,
+			permission.Context(permTypes.CtxApp, p.App),
+			permission.Context(permTypes.CtxApp, p.BaseApp),
+		),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	return Remove(context.Background(), p, evt, "")
+}
+
+// Shutdown stops the controller, waiting for the current preview to be
+// removed.
+func (c *controller) Shutdown(ctx context.Context) error {
+	close(c.shutdown)
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
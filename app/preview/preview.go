@@ -0,0 +1,339 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package preview implements ephemeral preview apps for pull requests. A
+// preview is a clone of a base app, with the same plan, pool, env and
+// services, running the image built for the pull request. Previews are
+// removed when they expire or when the pull request is closed.
+package preview
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/service"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	"github.com/tsuru/tsuru/validation"
+)
+
+const (
+	defaultTTL = 72 * time.Hour
+	maxNameLen = 40
+	nameInfix  = "-pr-"
+)
+
+var (
+	ErrPreviewNotFound = errors.New("preview not found")
+
+	invalidPRChars = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+// Preview is an ephemeral app cloned from a base app to run the image built
+// for a pull request. Instances are the service instances created for the
+// preview, which are removed along with it.
+type Preview struct {
+	App            string             `json:"app" bson:"_id"`
+	BaseApp        string             `json:"baseApp"`
+	PR             string             `json:"pr"`
+	Image          string             `json:"image"`
+	Hostname       string             `json:"hostname,omitempty"`
+	SharedBindings bool               `json:"sharedBindings"`
+	Instances      []manifest.Binding `json:"instances,omitempty"`
+	Owner          string             `json:"owner"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	ExpiresAt      time.Time          `json:"expiresAt"`
+}
+
+// CreateOptions holds the parameters used to create or update a preview.
+// When SharedBindings is true the preview is bound to the same service
+// instances as the base app, otherwise new instances with the same plans
+// are created for it.
+type CreateOptions struct {
+	Base           *app.App
+	PR             string
+	Image          string
+	TTL            time.Duration
+	SharedBindings bool
+	User           *auth.User
+	Event          *event.Event
+	RequestID      string
+}
+
+func previewsCollection(conn *db.Storage) *storage.Collection {
+	coll := conn.Collection("app_previews")
+	coll.EnsureIndex(mgo.Index{Key: []string{"baseapp", "pr"}, Unique: true})
+	coll.EnsureIndex(mgo.Index{Key: []string{"expiresat"}})
+	return coll
+}
+
+// DefaultTTL returns how long previews live unless a TTL is given when
+// they're created, as set by the previews:ttl config entry.
+func DefaultTTL() time.Duration {
+	ttl, _ := config.GetDuration("previews:ttl")
+	if ttl <= 0 {
+		return defaultTTL
+	}
+	return ttl
+}
+
+// Name returns the name of the preview app of the base app for the given
+// pull request, truncating the base app name when needed.
+func Name(baseApp, pr string) (string, error) {
+	id := strings.Trim(invalidPRChars.ReplaceAllString(strings.ToLower(pr), "-"), "-")
+	if id == "" {
+		return "", &tsuruErrors.ValidationError{Message: "invalid pull request identifier"}
+	}
+	suffix := nameInfix + id
+	if len(suffix) >= maxNameLen {
+		return "", &tsuruErrors.ValidationError{Message: "pull request identifier is too long"}
+	}
+	if len(baseApp)+len(suffix) > maxNameLen {
+		baseApp = strings.TrimRight(baseApp[:maxNameLen-len(suffix)], "-")
+	}
+	name := baseApp + suffix
+	if !validation.ValidateName(name) {
+		return "", &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid preview app name %q", name)}
+	}
+	return name, nil
+}
+
+func hostname(name string) string {
+	domain, _ := config.GetString("previews:domain")
+	if domain == "" {
+		return ""
+	}
+	return name + "." + strings.TrimPrefix(domain, ".")
+}
+
+// Create clones the base app into the preview app of the pull request, or
+// refreshes the expiration and image of the existing preview. The image is
+// not deployed, callers are expected to deploy it to the returned preview.
+func Create(ctx context.Context, opts CreateOptions) (*Preview, error) {
+	if opts.Image == "" {
+		return nil, &tsuruErrors.ValidationError{Message: "image is required"}
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL()
+	}
+	name, err := Name(opts.Base.Name, opts.PR)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	p, err := Get(opts.Base.Name, opts.PR)
+	if err == nil {
+		fmt.Fprintf(opts.Event, "---- Updating preview %q ----\n", p.App)
+		p.Image = opts.Image
+		p.ExpiresAt = now.Add(opts.TTL)
+		return p, save(p)
+	}
+	if err != ErrPreviewNotFound {
+		return nil, err
+	}
+	p = &Preview{
+		App:            name,
+		BaseApp:        opts.Base.Name,
+		PR:             opts.PR,
+		Image:          opts.Image,
+		Hostname:       hostname(name),
+		SharedBindings: opts.SharedBindings,
+		Owner:          opts.User.Email,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(opts.TTL),
+	}
+	fmt.Fprintf(opts.Event, "---- Creating preview app %q from %q ----\n", p.App, p.BaseApp)
+	previewApp, err := app.Clone(ctx, opts.Base, app.CloneOptions{
+		Name:        p.App,
+		Description: fmt.Sprintf("Preview of app %s for pull request %s", p.BaseApp, p.PR),
+		Tags:        append(append([]string{}, opts.Base.Tags...), "preview"),
+		CopySecrets: true,
+		User:        opts.User,
+		Writer:      opts.Event,
+		Event:       opts.Event,
+		RequestID:   opts.RequestID,
+	})
+	if previewApp == nil {
+		return nil, err
+	}
+	// The preview is stored as soon as its app exists, so that it's garbage
+	// collected even if the rest of the cloning fails.
+	if saveErr := save(p); saveErr != nil {
+		return nil, saveErr
+	}
+	if err == nil {
+		err = clone(ctx, opts, p, previewApp)
+	}
+	if err != nil {
+		return p, errors.Wrapf(err, "unable to clone app %q", p.BaseApp)
+	}
+	return p, nil
+}
+
+func clone(ctx context.Context, opts CreateOptions, p *Preview, previewApp *app.App) error {
+	if p.Hostname != "" {
+		err := previewApp.AddCName(p.Hostname)
+		if err != nil {
+			return err
+		}
+	}
+	instances, err := service.GetServiceInstancesBoundToApp(opts.Base.Name)
+	if err != nil {
+		return err
+	}
+	for _, si := range instances {
+		instance := si
+		if !p.SharedBindings {
+			instance, err = cloneInstance(ctx, opts, p, si)
+			if err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(opts.Event, "---- Binding service instance %s/%s ----\n", instance.ServiceName, instance.Name)
+		err = instance.BindApp(previewApp, nil, false, opts.Event, opts.Event, opts.RequestID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cloneInstance(ctx context.Context, opts CreateOptions, p *Preview, si service.ServiceInstance) (service.ServiceInstance, error) {
+	srv, err := service.Get(ctx, si.ServiceName)
+	if err != nil {
+		return si, err
+	}
+	name, err := Name(si.Name, p.PR)
+	if err != nil {
+		return si, err
+	}
+	instance := service.ServiceInstance{
+		Name:        name,
+		ServiceName: si.ServiceName,
+		PlanName:    si.PlanName,
+		TeamOwner:   opts.Base.TeamOwner,
+		Description: fmt.Sprintf("Preview of service instance %s for pull request %s", si.Name, p.PR),
+		Pool:        si.Pool,
+		Parameters:  si.Parameters,
+	}
+	fmt.Fprintf(opts.Event, "---- Creating service instance %s/%s ----\n", instance.ServiceName, instance.Name)
+	err = service.CreateServiceInstance(ctx, instance, &srv, opts.Event, opts.RequestID)
+	if err != nil {
+		return si, err
+	}
+	p.Instances = append(p.Instances, manifest.Binding{Service: instance.ServiceName, Instance: instance.Name})
+	if err = save(p); err != nil {
+		return si, err
+	}
+	created, err := service.GetServiceInstance(ctx, instance.ServiceName, instance.Name)
+	if err != nil {
+		return si, err
+	}
+	return *created, nil
+}
+
+func save(p *Preview) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = previewsCollection(conn).UpsertId(p.App, p)
+	return err
+}
+
+// Get returns the preview of the base app for the pull request.
+func Get(baseApp, pr string) (*Preview, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var p Preview
+	err = previewsCollection(conn).Find(bson.M{"baseapp": baseApp, "pr": pr}).One(&p)
+	if err == mgo.ErrNotFound {
+		return nil, ErrPreviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List returns the previews of the base app.
+func List(baseApp string) ([]Preview, error) {
+	return find(bson.M{"baseapp": baseApp})
+}
+
+// Expired returns the previews that expired before the given time.
+func Expired(now time.Time) ([]Preview, error) {
+	return find(bson.M{"expiresat": bson.M{"$lt": now}})
+}
+
+func find(query bson.M) ([]Preview, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var previews []Preview
+	err = previewsCollection(conn).Find(query).Sort("createdat").All(&previews)
+	if err != nil {
+		return nil, err
+	}
+	return previews, nil
+}
+
+// Remove deletes the preview app and the service instances created for it.
+func Remove(ctx context.Context, p *Preview, evt *event.Event, requestID string) error {
+	a, err := app.GetByName(ctx, p.App)
+	switch err {
+	case nil:
+		err = app.Delete(ctx, a, evt, requestID)
+		if err != nil {
+			return err
+		}
+	case appTypes.ErrAppNotFound:
+	default:
+		return err
+	}
+	for _, b := range p.Instances {
+		var si *service.ServiceInstance
+		si, err = service.GetServiceInstance(ctx, b.Service, b.Instance)
+		if err == service.ErrServiceInstanceNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(evt, "---- Removing service instance %s ----\n", b)
+		err = service.DeleteInstance(ctx, si, evt, requestID)
+		if err != nil {
+			return err
+		}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = previewsCollection(conn).RemoveId(p.App)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package preview
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/version"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"golang.org/x/crypto/bcrypt"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type NameSuite struct{}
+
+var _ = check.Suite(&NameSuite{})
+
+func (s *NameSuite) TestName(c *check.C) {
+	name, err := Name("myapp", "42")
+	c.Assert(err, check.IsNil)
+	c.Assert(name, check.Equals, "myapp-pr-42")
+	name, err = Name("myapp", "Feature/Login")
+	c.Assert(err, check.IsNil)
+	c.Assert(name, check.Equals, "myapp-pr-feature-login")
+	name, err = Name("a-very-long-application-name-for-tests", "1234")
+	c.Assert(err, check.IsNil)
+	c.Assert(name, check.Equals, "a-very-long-application-name-for-pr-1234")
+	c.Assert(len(name) <= maxNameLen, check.Equals, true)
+	_, err = Name("myapp", "!!!")
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+}
+
+type S struct {
+	storage     *db.Storage
+	user        *auth.User
+	mockService servicemock.MockService
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_preview_tests")
+	config.Set("routers:fake:type", "fake")
+	config.Set("auth:hash-cost", bcrypt.MinCost)
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+	provision.DefaultProvisioner = "fake"
+	app.AuthScheme = auth.ManagedScheme(native.NativeScheme{})
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	provisiontest.ProvisionerInstance.Reset()
+	routertest.FakeRouter.Reset()
+	s.user, _ = permissiontest.CustomUserWithPermission(c, app.AuthScheme, "majortom", permission.Permission{
+		Scheme:  permission.PermAll,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "p1", Default: true})
+	c.Assert(err, check.IsNil)
+	servicemock.SetMockService(&s.mockService)
+	plan := appTypes.Plan{Name: "default", Default: true, CpuShare: 100}
+	s.mockService.Plan.OnList = func() ([]appTypes.Plan, error) {
+		return []appTypes.Plan{plan}, nil
+	}
+	s.mockService.Plan.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &plan, nil
+	}
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		if name == plan.Name {
+			return &plan, nil
+		}
+		return nil, appTypes.ErrPlanNotFound
+	}
+	servicemanager.AppVersion, err = version.AppVersionService()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("previews")
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+	s.storage.Close()
+}
+
+func (s *S) createApp(c *check.C, name string) *app.App {
+	a := app.App{Name: name, Platform: "python", TeamOwner: "myteam", Tags: []string{"web"}}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) newEvent(c *check.C, appName string) *event.Event {
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: appName},
+		InternalKind: "preview-test",
+		Allowed:      event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *S) TestCreateClonesApp(c *check.C) {
+	config.Set("previews:domain", "preview.example.com")
+	base := s.createApp(c, "myapp")
+	err := base.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{
+		{Name: "DEBUG", Value: "1", Public: true},
+		{Name: "SECRET", Value: "s3cr3t"},
+	}})
+	c.Assert(err, check.IsNil)
+	base, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	p, err := Create(context.TODO(), CreateOptions{
+		Base:  base,
+		PR:    "42",
+		Image: "registry.example.com/myapp:pr-42",
+		TTL:   time.Hour,
+		User:  s.user,
+		Event: s.newEvent(c, "myapp"),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(p.App, check.Equals, "myapp-pr-42")
+	c.Assert(p.Hostname, check.Equals, "myapp-pr-42.preview.example.com")
+	c.Assert(p.ExpiresAt.After(time.Now().Add(50*time.Minute)), check.Equals, true)
+	previewApp, err := app.GetByName(context.TODO(), "myapp-pr-42")
+	c.Assert(err, check.IsNil)
+	c.Assert(previewApp.TeamOwner, check.Equals, "myteam")
+	c.Assert(previewApp.Pool, check.Equals, base.Pool)
+	c.Assert(previewApp.Plan.Name, check.Equals, base.Plan.Name)
+	c.Assert(previewApp.Tags, check.DeepEquals, []string{"web", "preview"})
+	c.Assert(previewApp.CName, check.DeepEquals, []string{"myapp-pr-42.preview.example.com"})
+	c.Assert(previewApp.Env["DEBUG"].Value, check.Equals, "1")
+	c.Assert(previewApp.Env["SECRET"].Value, check.Equals, "s3cr3t")
+	c.Assert(previewApp.Env["SECRET"].Public, check.Equals, false)
+	stored, err := Get("myapp", "42")
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.App, check.Equals, "myapp-pr-42")
+	c.Assert(stored.Owner, check.Equals, s.user.Email)
+}
+
+func (s *S) TestCreateExistingUpdatesImage(c *check.C) {
+	base := s.createApp(c, "myapp")
+	opts := CreateOptions{Base: base, PR: "42", Image: "myapp:v1", User: s.user, Event: s.newEvent(c, "myapp")}
+	_, err := Create(context.TODO(), opts)
+	c.Assert(err, check.IsNil)
+	opts.Image = "myapp:v2"
+	opts.TTL = 2 * time.Hour
+	p, err := Create(context.TODO(), opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Image, check.Equals, "myapp:v2")
+	previews, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(previews, check.HasLen, 1)
+	c.Assert(previews[0].Image, check.Equals, "myapp:v2")
+}
+
+func (s *S) TestRemoveAndExpired(c *check.C) {
+	base := s.createApp(c, "myapp")
+	p, err := Create(context.TODO(), CreateOptions{Base: base, PR: "7", Image: "myapp:v1", TTL: time.Minute, User: s.user, Event: s.newEvent(c, "myapp")})
+	c.Assert(err, check.IsNil)
+	expired, err := Expired(time.Now())
+	c.Assert(err, check.IsNil)
+	c.Assert(expired, check.HasLen, 0)
+	expired, err = Expired(time.Now().Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	c.Assert(expired, check.HasLen, 1)
+	err = Remove(context.TODO(), p, s.newEvent(c, p.App), "")
+	c.Assert(err, check.IsNil)
+	_, err = app.GetByName(context.TODO(), p.App)
+	c.Assert(err, check.Equals, appTypes.ErrAppNotFound)
+	_, err = Get("myapp", "7")
+	c.Assert(err, check.Equals, ErrPreviewNotFound)
+}
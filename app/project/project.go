@@ -0,0 +1,338 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package project manages projects: groups of apps sharing teams, a set of
+// environment variables and quotas.
+package project
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	"github.com/tsuru/tsuru/types/quota"
+	"github.com/tsuru/tsuru/validation"
+)
+
+var (
+	ErrProjectNotFound      = errors.New("project not found")
+	ErrProjectAlreadyExists = errors.New("project already exists")
+	ErrProjectInUse         = errors.New("project still has apps")
+)
+
+// Quota limits the apps of a project. Zero means unlimited.
+type Quota struct {
+	Apps  int `json:"apps,omitempty"`
+	Units int `json:"units,omitempty"`
+}
+
+// Project groups apps that share teams, environment variables and quotas.
+type Project struct {
+	Name        string            `bson:"_id" json:"name"`
+	Description string            `json:"description,omitempty"`
+	Teams       []string          `json:"teams,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	Quota       Quota             `json:"quota"`
+}
+
+// Filter selects projects in List.
+type Filter struct {
+	Team string
+}
+
+func projectsCollection(conn *db.Storage) *storage.Collection {
+	return conn.Collection("projects")
+}
+
+// managedBy identifies the environment variables set on apps by the project.
+func managedBy(name string) string {
+	return "project/" + name
+}
+
+func (p *Project) validate(ctx context.Context) error {
+	if !validation.ValidateName(p.Name) {
+		msg := "Invalid project name, project name should have at most 40 " +
+			"characters, containing only lower case letters, numbers or dashes, " +
+			"starting with a letter."
+		return &tsuruErrors.ValidationError{Message: msg}
+	}
+	for _, name := range p.Teams {
+		if _, err := servicemanager.Team.FindByName(ctx, name); err != nil {
+			if err == authTypes.ErrTeamNotFound {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("team %q not found", name)}
+			}
+			return err
+		}
+	}
+	for name := range p.Env {
+		if name == "" {
+			return &tsuruErrors.ValidationError{Message: "environment variable name is required"}
+		}
+	}
+	if p.Quota.Apps < 0 || p.Quota.Units < 0 {
+		return &tsuruErrors.ValidationError{Message: "project quota can't be negative"}
+	}
+	return nil
+}
+
+// Create stores a new project.
+func Create(ctx context.Context, p Project) error {
+	if err := p.validate(ctx); err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = projectsCollection(conn).Insert(p)
+	if mgo.IsDup(err) {
+		return ErrProjectAlreadyExists
+	}
+	return err
+}
+
+// Update replaces an existing project. Apps in the project are only changed
+// when the project is synced.
+func Update(ctx context.Context, p Project) error {
+	if err := p.validate(ctx); err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = projectsCollection(conn).UpdateId(p.Name, p)
+	if err == mgo.ErrNotFound {
+		return ErrProjectNotFound
+	}
+	return err
+}
+
+// Remove removes a project, as long as it has no apps.
+func Remove(name string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	n, err := conn.Apps().Find(bson.M{"project": name}).Count()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrProjectInUse
+	}
+	err = projectsCollection(conn).RemoveId(name)
+	if err == mgo.ErrNotFound {
+		return ErrProjectNotFound
+	}
+	return err
+}
+
+// Get returns the project with the given name.
+func Get(name string) (*Project, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var p Project
+	err = projectsCollection(conn).FindId(name).One(&p)
+	if err == mgo.ErrNotFound {
+		return nil, ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List returns the projects matching the filter, sorted by name.
+func List(filter *Filter) ([]Project, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	query := bson.M{}
+	if filter != nil && filter.Team != "" {
+		query["teams"] = filter.Team
+	}
+	var projects []Project
+	err = projectsCollection(conn).Find(query).Sort("_id").All(&projects)
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// Apps returns the names of the apps in the project.
+func Apps(name string) ([]string, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var apps []struct{ Name string }
+	err = conn.Apps().Find(bson.M{"project": name}).Select(bson.M{"name": 1}).Sort("name").All(&apps)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(apps))
+	for i, a := range apps {
+		names[i] = a.Name
+	}
+	return names, nil
+}
+
+// UnitsInUse returns the number of units counted against the quota of the
+// apps in the project.
+func (p *Project) UnitsInUse(ctx context.Context) (int, error) {
+	names, err := Apps(p.Name)
+	if err != nil {
+		return 0, err
+	}
+	var total int
+	for _, name := range names {
+		q, err := servicemanager.AppQuota.Get(ctx, &app.App{Name: name})
+		if err != nil {
+			return 0, err
+		}
+		total += q.InUse
+	}
+	return total, nil
+}
+
+// CheckUnits returns a quota error if adding n units to apps of the project
+// would exceed its units quota.
+func (p *Project) CheckUnits(ctx context.Context, n int) error {
+	if p.Quota.Units == 0 {
+		return nil
+	}
+	inUse, err := p.UnitsInUse(ctx)
+	if err != nil {
+		return err
+	}
+	if inUse+n > p.Quota.Units {
+		available := p.Quota.Units - inUse
+		if available < 0 {
+			available = 0
+		}
+		return &quota.QuotaExceededError{Requested: uint(n), Available: uint(available)}
+	}
+	return nil
+}
+
+// AddApp moves the app into the project, leaving its previous project if
+// any, and syncs the project teams and environment variables to it.
+func (p *Project) AddApp(ctx context.Context, a *app.App, w io.Writer) error {
+	if a.Project == p.Name {
+		return p.SyncApp(ctx, a, w)
+	}
+	if p.Quota.Apps > 0 {
+		names, err := Apps(p.Name)
+		if err != nil {
+			return err
+		}
+		if len(names) >= p.Quota.Apps {
+			return &quota.QuotaExceededError{Requested: 1, Available: 0}
+		}
+	}
+	q, err := a.GetQuota()
+	if err != nil {
+		return err
+	}
+	err = p.CheckUnits(ctx, q.InUse)
+	if err != nil {
+		return err
+	}
+	if a.Project != "" {
+		err = RemoveApp(ctx, a, w)
+		if err != nil {
+			return err
+		}
+	}
+	err = setAppProject(a, p.Name)
+	if err != nil {
+		return err
+	}
+	return p.SyncApp(ctx, a, w)
+}
+
+// RemoveApp takes the app out of its project, unsetting the environment
+// variables set by the project. Teams granted by the project keep their
+// access to the app.
+func RemoveApp(ctx context.Context, a *app.App, w io.Writer) error {
+	if a.Project == "" {
+		return nil
+	}
+	name := a.Project
+	err := setAppProject(a, "")
+	if err != nil {
+		return err
+	}
+	_, err = a.SetManagedEnvs(managedBy(name), nil, bind.SetEnvArgs{
+		ShouldRestart: true,
+		Writer:        w,
+	})
+	return err
+}
+
+func setAppProject(a *app.App, name string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"$set": bson.M{"project": name}}
+	if name == "" {
+		update = bson.M{"$unset": bson.M{"project": ""}}
+	}
+	err = conn.Apps().Update(bson.M{"name": a.Name}, update)
+	if err != nil {
+		return err
+	}
+	a.Project = name
+	return nil
+}
+
+// SyncApp grants the project teams access to the app and sets the project
+// environment variables on it, pruning the ones removed from the project.
+// Variables set directly on the app take precedence over the project ones.
+// The app is restarted if its environment changes.
+func (p *Project) SyncApp(ctx context.Context, a *app.App, w io.Writer) error {
+	for _, name := range p.Teams {
+		team, err := servicemanager.Team.FindByName(ctx, name)
+		if err != nil {
+			return err
+		}
+		err = a.Grant(team)
+		if err == app.ErrAlreadyHaveAccess {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if w != nil {
+			fmt.Fprintf(w, "---- project %q: grant access to team %s ----\n", p.Name, name)
+		}
+	}
+	_, err := a.SetManagedEnvs(managedBy(p.Name), p.Env, bind.SetEnvArgs{
+		ShouldRestart: true,
+		Writer:        w,
+	})
+	return err
+}
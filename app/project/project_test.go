@@ -0,0 +1,256 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/version"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"github.com/tsuru/tsuru/types/quota"
+	"golang.org/x/crypto/bcrypt"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage     *db.Storage
+	user        *auth.User
+	mockService servicemock.MockService
+	unitsInUse  map[string]int
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_project_tests")
+	config.Set("routers:fake:type", "fake")
+	config.Set("auth:hash-cost", bcrypt.MinCost)
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+	provision.DefaultProvisioner = "fake"
+	app.AuthScheme = auth.ManagedScheme(native.NativeScheme{})
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	provisiontest.ProvisionerInstance.Reset()
+	routertest.FakeRouter.Reset()
+	s.user, _ = permissiontest.CustomUserWithPermission(c, app.AuthScheme, "majortom", permission.Permission{
+		Scheme:  permission.PermAll,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "p1", Default: true})
+	c.Assert(err, check.IsNil)
+	err = pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "p2", Public: true})
+	c.Assert(err, check.IsNil)
+	servicemock.SetMockService(&s.mockService)
+	plans := []appTypes.Plan{
+		{Name: "default", Default: true, CpuShare: 100},
+		{Name: "large", CpuShare: 200},
+	}
+	s.mockService.Plan.OnList = func() ([]appTypes.Plan, error) {
+		return plans, nil
+	}
+	s.mockService.Plan.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &plans[0], nil
+	}
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		for i := range plans {
+			if plans[i].Name == name {
+				return &plans[i], nil
+			}
+		}
+		return nil, appTypes.ErrPlanNotFound
+	}
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		if name == "myteam" || name == "ops" {
+			return &authTypes.Team{Name: name}, nil
+		}
+		return nil, authTypes.ErrTeamNotFound
+	}
+	s.mockService.AppQuota.OnGet = func(item quota.QuotaItem) (*quota.Quota, error) {
+		return &quota.Quota{Limit: -1, InUse: s.unitsInUse[item.GetName()]}, nil
+	}
+	s.unitsInUse = map[string]int{}
+	servicemanager.AppVersion, err = version.AppVersionService()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+	s.storage.Close()
+}
+
+func (s *S) createApp(c *check.C, name string) *app.App {
+	a := app.App{Name: name, Platform: "python", TeamOwner: "myteam"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestCreateAndGet(c *check.C) {
+	p := Project{Name: "checkout", Description: "checkout services", Teams: []string{"ops"}, Env: map[string]string{"LOG_LEVEL": "info"}, Quota: Quota{Apps: 5}}
+	err := Create(context.TODO(), p)
+	c.Assert(err, check.IsNil)
+	stored, err := Get("checkout")
+	c.Assert(err, check.IsNil)
+	c.Assert(*stored, check.DeepEquals, p)
+	err = Create(context.TODO(), p)
+	c.Assert(err, check.Equals, ErrProjectAlreadyExists)
+	err = Create(context.TODO(), Project{Name: "payments"})
+	c.Assert(err, check.IsNil)
+	projects, err := List(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(projects, check.HasLen, 2)
+	projects, err = List(&Filter{Team: "ops"})
+	c.Assert(err, check.IsNil)
+	c.Assert(projects, check.HasLen, 1)
+	c.Assert(projects[0].Name, check.Equals, "checkout")
+}
+
+func (s *S) TestCreateValidation(c *check.C) {
+	tests := []struct {
+		project Project
+		message string
+	}{
+		{Project{Name: "Invalid Name"}, "Invalid project name, project name should have at most 40 characters, containing only lower case letters, numbers or dashes, starting with a letter."},
+		{Project{Name: "checkout", Teams: []string{"nobody"}}, `team "nobody" not found`},
+		{Project{Name: "checkout", Quota: Quota{Units: -1}}, "project quota can't be negative"},
+	}
+	for _, tt := range tests {
+		err := Create(context.TODO(), tt.project)
+		c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: tt.message})
+	}
+}
+
+func (s *S) TestUpdateNotFound(c *check.C) {
+	err := Update(context.TODO(), Project{Name: "checkout"})
+	c.Assert(err, check.Equals, ErrProjectNotFound)
+}
+
+func (s *S) TestRemove(c *check.C) {
+	p := Project{Name: "checkout"}
+	err := Create(context.TODO(), p)
+	c.Assert(err, check.IsNil)
+	a := s.createApp(c, "myapp")
+	err = p.AddApp(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	err = Remove("checkout")
+	c.Assert(err, check.Equals, ErrProjectInUse)
+	err = RemoveApp(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	err = Remove("checkout")
+	c.Assert(err, check.IsNil)
+	_, err = Get("checkout")
+	c.Assert(err, check.Equals, ErrProjectNotFound)
+	err = Remove("checkout")
+	c.Assert(err, check.Equals, ErrProjectNotFound)
+}
+
+func (s *S) TestAddApp(c *check.C) {
+	p := Project{Name: "checkout", Teams: []string{"ops"}, Env: map[string]string{"LOG_LEVEL": "info", "REGION": "us"}}
+	err := Create(context.TODO(), p)
+	c.Assert(err, check.IsNil)
+	a := s.createApp(c, "myapp")
+	err = a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{{Name: "REGION", Value: "eu", Public: true}}})
+	c.Assert(err, check.IsNil)
+	err = p.AddApp(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Project, check.Equals, "checkout")
+	c.Assert(a.Teams, check.DeepEquals, []string{"myteam", "ops"})
+	c.Assert(a.Env["LOG_LEVEL"].Value, check.Equals, "info")
+	c.Assert(a.Env["LOG_LEVEL"].ManagedBy, check.Equals, "project/checkout")
+	c.Assert(a.Env["REGION"].Value, check.Equals, "eu")
+	apps, err := Apps("checkout")
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.DeepEquals, []string{"myapp"})
+	filtered, err := app.List(context.TODO(), &app.Filter{Project: "checkout"})
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.HasLen, 1)
+}
+
+func (s *S) TestSyncAppPrunesRemovedEnvs(c *check.C) {
+	p := Project{Name: "checkout", Env: map[string]string{"LOG_LEVEL": "info", "REGION": "us"}}
+	err := Create(context.TODO(), p)
+	c.Assert(err, check.IsNil)
+	a := s.createApp(c, "myapp")
+	err = p.AddApp(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	p.Env = map[string]string{"LOG_LEVEL": "debug"}
+	err = Update(context.TODO(), p)
+	c.Assert(err, check.IsNil)
+	err = p.SyncApp(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Env["LOG_LEVEL"].Value, check.Equals, "debug")
+	_, ok := a.Env["REGION"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestRemoveApp(c *check.C) {
+	p := Project{Name: "checkout", Teams: []string{"ops"}, Env: map[string]string{"LOG_LEVEL": "info"}}
+	err := Create(context.TODO(), p)
+	c.Assert(err, check.IsNil)
+	a := s.createApp(c, "myapp")
+	err = p.AddApp(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	err = RemoveApp(context.TODO(), a, nil)
+	c.Assert(err, check.IsNil)
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Project, check.Equals, "")
+	c.Assert(a.Teams, check.DeepEquals, []string{"myteam", "ops"})
+	_, ok := a.Env["LOG_LEVEL"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestAddAppQuota(c *check.C) {
+	p := Project{Name: "checkout", Quota: Quota{Apps: 1, Units: 4}}
+	err := Create(context.TODO(), p)
+	c.Assert(err, check.IsNil)
+	a1 := s.createApp(c, "app1")
+	s.unitsInUse["app1"] = 3
+	err = p.AddApp(context.TODO(), a1, nil)
+	c.Assert(err, check.IsNil)
+	a2 := s.createApp(c, "app2")
+	err = p.AddApp(context.TODO(), a2, nil)
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{Requested: 1, Available: 0})
+	err = p.CheckUnits(context.TODO(), 1)
+	c.Assert(err, check.IsNil)
+	err = p.CheckUnits(context.TODO(), 2)
+	c.Assert(err, check.DeepEquals, &quota.QuotaExceededError{Requested: 2, Available: 1})
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+// Protection guards an app against destructive operations: protected apps
+// can't be removed, moved to another pool or scaled down below MinUnits
+// until the protection is removed.
+type Protection struct {
+	Enabled bool `json:"enabled"`
+	// MinUnits is the floor for unit removals, it defaults to a single unit.
+	MinUnits int `json:"minUnits,omitempty"`
+}
+
+func (p Protection) unitsFloor() int {
+	if p.MinUnits > 0 {
+		return p.MinUnits
+	}
+	return 1
+}
+
+// ErrAppProtected is returned when an operation is rejected by the
+// protection of the app.
+type ErrAppProtected struct {
+	App       string
+	Operation string
+}
+
+func (e ErrAppProtected) Error() string {
+	return fmt.Sprintf("app %q is protected against %s, remove the protection first", e.App, e.Operation)
+}
+
+// SetProtection enables or removes the protection of the app.
+func (app *App) SetProtection(p Protection) error {
+	if p.MinUnits < 0 {
+		return &tsuruErrors.ValidationError{Message: "minimum units can't be negative"}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"$set": bson.M{"protection": p}}
+	if !p.Enabled {
+		p = Protection{}
+		update = bson.M{"$unset": bson.M{"protection": ""}}
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.Protection = p
+	return nil
+}
+
+// CheckRemovalProtection returns ErrAppProtected if the app is protected.
+func (app *App) CheckRemovalProtection() error {
+	if app.Protection.Enabled {
+		return ErrAppProtected{App: app.Name, Operation: "removal"}
+	}
+	return nil
+}
+
+// CheckPoolChangeProtection returns ErrAppProtected if the app is protected
+// and pool is not its current pool.
+func (app *App) CheckPoolChangeProtection(pool string) error {
+	if app.Protection.Enabled && pool != "" && pool != app.Pool {
+		return ErrAppProtected{App: app.Name, Operation: "pool changes"}
+	}
+	return nil
+}
+
+// CheckUnitsProtection returns ErrAppProtected if the app is protected and
+// removing n units would leave it with less units than its floor.
+func (app *App) CheckUnitsProtection(n int) error {
+	if !app.Protection.Enabled {
+		return nil
+	}
+	units, err := app.Units()
+	if err != nil {
+		return err
+	}
+	floor := app.Protection.unitsFloor()
+	if len(units)-n < floor {
+		return ErrAppProtected{App: app.Name, Operation: fmt.Sprintf("removing units below %d", floor)}
+	}
+	return nil
+}
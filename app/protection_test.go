@@ -0,0 +1,61 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetProtection(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetProtection(Protection{Enabled: true, MinUnits: -1})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = a.SetProtection(Protection{Enabled: true, MinUnits: 2})
+	c.Assert(err, check.IsNil)
+	dbApp, err := GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Protection, check.DeepEquals, Protection{Enabled: true, MinUnits: 2})
+	err = dbApp.SetProtection(Protection{MinUnits: 2})
+	c.Assert(err, check.IsNil)
+	dbApp, err = GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Protection, check.DeepEquals, Protection{})
+}
+
+func (s *S) TestProtectedAppRejectsDestructiveOperations(c *check.C) {
+	a := App{Name: "myapp", Platform: "python", TeamOwner: s.team.Name, Quota: quota.UnlimitedQuota}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	newSuccessfulAppVersion(c, &a)
+	err = a.AddUnits(3, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	err = a.SetProtection(Protection{Enabled: true, MinUnits: 2})
+	c.Assert(err, check.IsNil)
+	err = a.RemoveUnits(context.TODO(), 2, "web", "", nil)
+	c.Assert(err, check.DeepEquals, ErrAppProtected{App: "myapp", Operation: "removing units below 2"})
+	err = a.RemoveUnits(context.TODO(), 1, "web", "", nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(a.CheckPoolChangeProtection(a.Pool), check.IsNil)
+	c.Assert(a.CheckPoolChangeProtection("other"), check.DeepEquals, ErrAppProtected{App: "myapp", Operation: "pool changes"})
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		Kind:     permission.PermAppDelete,
+		RawOwner: event.Owner{Type: event.OwnerTypeUser, Name: s.user.Email},
+		Allowed:  event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	err = Delete(context.TODO(), &a, evt, "")
+	c.Assert(err, check.DeepEquals, ErrAppProtected{App: "myapp", Operation: "removal"})
+	_, err = GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+}
@@ -0,0 +1,308 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reconcile keeps apps managed by declarative specs converged to
+// them, periodically re-applying the stored manifests and reverting changes
+// made out of band.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/api/tracker"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/service"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const internalKind = "reconcile"
+
+// internalEnvs are set by tsuru itself on every app, so they're never part of
+// manifests and must not be pruned.
+var internalEnvs = map[string]bool{
+	"TSURU_APPNAME":   true,
+	"TSURU_APPDIR":    true,
+	"TSURU_APP_TOKEN": true,
+}
+
+var (
+	driftsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_reconcile_drifts_total",
+		Help: "The total number of apps found drifted from their declarative specs.",
+	})
+
+	reconcileErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_reconcile_errors_total",
+		Help: "The total number of errors reconciling apps.",
+	})
+)
+
+// State describes the current state of the app using the same
+// representation as the manifests applied to it.
+func State(a *app.App) (*manifest.Manifest, error) {
+	state := manifest.Manifest{
+		Name:        a.Name,
+		Description: a.Description,
+		Platform:    a.Platform,
+		Pool:        a.Pool,
+		Plan:        a.Plan.Name,
+		TeamOwner:   a.TeamOwner,
+		Tags:        a.Tags,
+		CNames:      a.CName,
+		Env:         map[string]string{},
+		Units:       map[string]uint{},
+	}
+	for name, env := range a.Env {
+		if env.ManagedBy == "" && !internalEnvs[name] {
+			state.Env[name] = env.Value
+			if !env.Public {
+				state.PrivateEnv = append(state.PrivateEnv, name)
+			}
+		}
+	}
+	sort.Strings(state.PrivateEnv)
+	units, err := a.Units()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range units {
+		state.Units[u.ProcessName]++
+	}
+	instances, err := service.GetServiceInstancesBoundToApp(a.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, si := range instances {
+		state.Bindings = append(state.Bindings, manifest.Binding{Service: si.ServiceName, Instance: si.Name})
+	}
+	return &state, nil
+}
+
+// Initialize starts the reconciliation controller when enabled by the
+// reconcile:enabled config entry.
+func Initialize() error {
+	enabled, _ := config.GetBool("reconcile:enabled")
+	if !enabled {
+		return nil
+	}
+	interval, _ := config.GetDuration("reconcile:interval")
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	c := &controller{interval: interval}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type controller struct {
+	interval time.Duration
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+func (c *controller) start() {
+	c.shutdown = make(chan struct{})
+	c.done = make(chan struct{})
+	log.Debugf("[reconcile] starting. Running every %s.", c.interval)
+	go func() {
+		defer close(c.done)
+		for {
+			select {
+			case <-time.After(c.interval):
+				c.run()
+			case <-c.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (c *controller) run() {
+	if !tracker.IsLeader("app-reconcile") {
+		return
+	}
+	specs, err := ListSpecs()
+	if err != nil {
+		log.Errorf("[reconcile] unable to list specs: %v", err)
+		return
+	}
+	for i := range specs {
+		select {
+		case <-c.shutdown:
+			return
+		default:
+		}
+		if specs[i].Paused {
+			continue
+		}
+		err = Reconcile(context.Background(), &specs[i])
+		if err != nil {
+			reconcileErrors.Inc()
+			log.Errorf("[reconcile] unable to reconcile app %q: %v", specs[i].App, err)
+		}
+	}
+}
+
+// Shutdown stops the controller, waiting for the current app to be
+// reconciled.
+func (c *controller) Shutdown(ctx context.Context) error {
+	close(c.shutdown)
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
+
+// Reconcile re-applies the spec to its app. Any drift found is recorded in
+// an event with the changes needed to revert it.
+func Reconcile(ctx context.Context, spec *Spec) (err error) {
+	a, err := app.GetByName(ctx, spec.App)
+	if err == appTypes.ErrAppNotFound {
+		log.Debugf("[reconcile] app %q not found, removing its spec", spec.App)
+		return RemoveSpec(spec.App)
+	}
+	if err != nil {
+		return err
+	}
+	current, err := State(a)
+	if err != nil {
+		return err
+	}
+	var changes []manifest.Change
+	for _, change := range manifest.Diff(current, spec.Manifest, spec.Prune) {
+		if (change.Kind == manifest.ChangeUnitsAdd || change.Kind == manifest.ChangeUnitsRemove) && a.Deploys == 0 {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	err = updateSpec(spec.App, bson.M{"lastcheck": time.Now().UTC(), "lastdrift": changes})
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	driftsTotal.Inc()
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: a.Name},
+		InternalKind: internalKind,
+		CustomData:   changes,
+		Allowed:      event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, a.Name)),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			log.Debugf("[reconcile] skipping app %q: event locked", a.Name)
+			return nil
+		}
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	for _, change := range changes {
+		fmt.Fprintf(evt, "---- reverting drift: %s ----\n", change)
+		err = ApplyChange(ctx, a, &spec.Manifest, change, true, evt)
+		if err != nil {
+			return errors.Wrapf(err, "unable to %s", change)
+		}
+		a, err = app.GetByName(ctx, spec.App)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyChange applies a single change to the app, converging it to the
+// manifest. When restart is false, changes that would restart the app units
+// are applied without restarting them.
+func ApplyChange(ctx context.Context, a *app.App, m *manifest.Manifest, change manifest.Change, restart bool, evt *event.Event) error {
+	switch change.Kind {
+	case manifest.ChangeUpdate:
+		return a.Update(app.UpdateAppArgs{
+			UpdateData:    UpdateData(m, change.Fields),
+			Writer:        evt,
+			ShouldRestart: restart,
+		})
+	case manifest.ChangeEnvSet:
+		return a.SetEnvs(bind.SetEnvArgs{Envs: EnvVars(a, m, change.Names), ShouldRestart: restart, Writer: evt})
+	case manifest.ChangeEnvUnset:
+		return a.UnsetEnvs(bind.UnsetEnvArgs{VariableNames: change.Names, ShouldRestart: restart, Writer: evt})
+	case manifest.ChangeBind, manifest.ChangeUnbind:
+		instance, err := service.GetServiceInstance(ctx, change.Binding.Service, change.Binding.Instance)
+		if err != nil {
+			return err
+		}
+		if change.Kind == manifest.ChangeUnbind {
+			return instance.UnbindApp(service.UnbindAppArgs{App: a, Restart: restart, Event: evt})
+		}
+		return instance.BindApp(a, nil, restart, evt, evt, "")
+	case manifest.ChangeCNameAdd:
+		return a.AddCName(change.Names...)
+	case manifest.ChangeCNameRemove:
+		return a.RemoveCName(change.Names...)
+	case manifest.ChangeUnitsAdd:
+		return a.AddUnits(change.Units, change.Process, "", evt)
+	case manifest.ChangeUnitsRemove:
+		return a.RemoveUnits(ctx, change.Units, change.Process, "", evt)
+	}
+	return errors.Errorf("unsupported change %q", change.Kind)
+}
+
+// EnvVars returns the environment variables with the given names to be set
+// in the app from the manifest. Variables marked as private in the manifest
+// are set as private, the others keep the visibility they have in the app,
+// being public when they're new.
+func EnvVars(a *app.App, m *manifest.Manifest, names []string) []bind.EnvVar {
+	envs := make([]bind.EnvVar, 0, len(names))
+	for _, name := range names {
+		public := true
+		if current, ok := a.Env[name]; ok {
+			public = current.Public
+		}
+		if m.IsPrivateEnv(name) {
+			public = false
+		}
+		envs = append(envs, bind.EnvVar{Name: name, Value: m.Env[name], Public: public})
+	}
+	return envs
+}
+
+// UpdateData returns the app fields to be updated for the given manifest
+// fields, as reported by an update change.
+func UpdateData(m *manifest.Manifest, fields []string) app.App {
+	var updateData app.App
+	for _, field := range fields {
+		switch field {
+		case "description":
+			updateData.Description = m.Description
+		case "platform":
+			updateData.Platform = m.Platform
+		case "pool":
+			updateData.Pool = m.Pool
+		case "plan":
+			updateData.Plan = appTypes.Plan{Name: m.Plan}
+		case "team_owner":
+			updateData.TeamOwner = m.TeamOwner
+		case "tags":
+			updateData.Tags = append([]string{}, m.Tags...)
+		}
+	}
+	return updateData
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/version"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"golang.org/x/crypto/bcrypt"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage     *db.Storage
+	user        *auth.User
+	mockService servicemock.MockService
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_reconcile_tests")
+	config.Set("routers:fake:type", "fake")
+	config.Set("auth:hash-cost", bcrypt.MinCost)
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+	provision.DefaultProvisioner = "fake"
+	app.AuthScheme = auth.ManagedScheme(native.NativeScheme{})
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	provisiontest.ProvisionerInstance.Reset()
+	routertest.FakeRouter.Reset()
+	s.user, _ = permissiontest.CustomUserWithPermission(c, app.AuthScheme, "majortom", permission.Permission{
+		Scheme:  permission.PermAll,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "p1", Default: true})
+	c.Assert(err, check.IsNil)
+	servicemock.SetMockService(&s.mockService)
+	plan := appTypes.Plan{Name: "default", Default: true, CpuShare: 100}
+	s.mockService.Plan.OnList = func() ([]appTypes.Plan, error) {
+		return []appTypes.Plan{plan}, nil
+	}
+	s.mockService.Plan.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &plan, nil
+	}
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		if name == plan.Name {
+			return &plan, nil
+		}
+		return nil, appTypes.ErrPlanNotFound
+	}
+	servicemanager.AppVersion, err = version.AppVersionService()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+	s.storage.Close()
+}
+
+func (s *S) createApp(c *check.C, name string) *app.App {
+	a := app.App{Name: name, Platform: "python", TeamOwner: "myteam"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) TestSaveSpecKeepsPauseState(c *check.C) {
+	err := SaveSpec(manifest.Manifest{Name: "myapp"}, false)
+	c.Assert(err, check.IsNil)
+	err = SetPaused("myapp", true)
+	c.Assert(err, check.IsNil)
+	err = SaveSpec(manifest.Manifest{Name: "myapp", Description: "new"}, true)
+	c.Assert(err, check.IsNil)
+	spec, err := GetSpec("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(spec.Manifest, check.DeepEquals, manifest.Manifest{Name: "myapp", Description: "new"})
+	c.Assert(spec.Prune, check.Equals, true)
+	c.Assert(spec.Paused, check.Equals, true)
+}
+
+func (s *S) TestSpecNotFound(c *check.C) {
+	_, err := GetSpec("myapp")
+	c.Assert(err, check.Equals, ErrSpecNotFound)
+	c.Assert(SetPaused("myapp", true), check.Equals, ErrSpecNotFound)
+	c.Assert(RemoveSpec("myapp"), check.Equals, ErrSpecNotFound)
+}
+
+func (s *S) TestReconcileRevertsDrift(c *check.C) {
+	a := s.createApp(c, "myapp")
+	err := a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{
+		{Name: "DEBUG", Value: "0", Public: true},
+		{Name: "MANUAL", Value: "x", Public: true},
+	}})
+	c.Assert(err, check.IsNil)
+	err = SaveSpec(manifest.Manifest{
+		Name:        "myapp",
+		Description: "managed",
+		Env:         map[string]string{"DEBUG": "1"},
+	}, true)
+	c.Assert(err, check.IsNil)
+	spec, err := GetSpec("myapp")
+	c.Assert(err, check.IsNil)
+	err = Reconcile(context.TODO(), spec)
+	c.Assert(err, check.IsNil)
+	updated, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Description, check.Equals, "managed")
+	c.Assert(updated.Env["DEBUG"].Value, check.Equals, "1")
+	_, ok := updated.Env["MANUAL"]
+	c.Assert(ok, check.Equals, false)
+	c.Assert(updated.Env["TSURU_APPNAME"].Value, check.Equals, "myapp")
+	spec, err = GetSpec("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(spec.LastDrift, check.DeepEquals, []manifest.Change{
+		{Kind: manifest.ChangeUpdate, Fields: []string{"description"}},
+		{Kind: manifest.ChangeEnvSet, Names: []string{"DEBUG"}},
+		{Kind: manifest.ChangeEnvUnset, Names: []string{"MANUAL"}},
+	})
+	evts, err := event.All()
+	c.Assert(err, check.IsNil)
+	var found bool
+	for _, evt := range evts {
+		if evt.Kind.Name == internalKind {
+			found = true
+			c.Assert(evt.Target, check.Equals, event.Target{Type: event.TargetTypeApp, Value: "myapp"})
+		}
+	}
+	c.Assert(found, check.Equals, true)
+}
+
+func (s *S) TestStateSkipsInternalEnvs(c *check.C) {
+	a := s.createApp(c, "myapp")
+	state, err := State(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(state.Env, check.DeepEquals, map[string]string{})
+}
+
+func (s *S) TestStatePrivateEnvs(c *check.C) {
+	a := s.createApp(c, "myapp")
+	err := a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{
+		{Name: "DEBUG", Value: "0", Public: true},
+		{Name: "TOKEN", Value: "secret"},
+	}})
+	c.Assert(err, check.IsNil)
+	state, err := State(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(state.Env, check.DeepEquals, map[string]string{"DEBUG": "0", "TOKEN": "secret"})
+	c.Assert(state.PrivateEnv, check.DeepEquals, []string{"TOKEN"})
+}
+
+func (s *S) TestApplyChangeKeepsPrivateEnvs(c *check.C) {
+	a := s.createApp(c, "myapp")
+	err := a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{
+		{Name: "DEBUG", Value: "0", Public: true},
+		{Name: "TOKEN", Value: "secret"},
+	}})
+	c.Assert(err, check.IsNil)
+	m := &manifest.Manifest{
+		Name:       "myapp",
+		Env:        map[string]string{"DEBUG": "1", "TOKEN": "other", "NEW": "x", "KEY": "y"},
+		PrivateEnv: []string{"KEY"},
+	}
+	change := manifest.Change{Kind: manifest.ChangeEnvSet, Names: []string{"DEBUG", "KEY", "NEW", "TOKEN"}}
+	err = ApplyChange(context.TODO(), a, m, change, false, &event.Event{})
+	c.Assert(err, check.IsNil)
+	updated, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Env["DEBUG"].Public, check.Equals, true)
+	c.Assert(updated.Env["NEW"].Public, check.Equals, true)
+	c.Assert(updated.Env["TOKEN"].Public, check.Equals, false)
+	c.Assert(updated.Env["TOKEN"].Value, check.Equals, "other")
+	c.Assert(updated.Env["KEY"].Public, check.Equals, false)
+}
+
+func (s *S) TestReconcileNoDrift(c *check.C) {
+	s.createApp(c, "myapp")
+	err := SaveSpec(manifest.Manifest{Name: "myapp", Platform: "python"}, false)
+	c.Assert(err, check.IsNil)
+	spec, err := GetSpec("myapp")
+	c.Assert(err, check.IsNil)
+	err = Reconcile(context.TODO(), spec)
+	c.Assert(err, check.IsNil)
+	spec, err = GetSpec("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(spec.LastDrift, check.HasLen, 0)
+	c.Assert(spec.LastCheck.IsZero(), check.Equals, false)
+}
+
+func (s *S) TestReconcileRemovedApp(c *check.C) {
+	err := SaveSpec(manifest.Manifest{Name: "myapp"}, false)
+	c.Assert(err, check.IsNil)
+	spec, err := GetSpec("myapp")
+	c.Assert(err, check.IsNil)
+	err = Reconcile(context.TODO(), spec)
+	c.Assert(err, check.IsNil)
+	_, err = GetSpec("myapp")
+	c.Assert(err, check.Equals, ErrSpecNotFound)
+}
+
+func (s *S) TestControllerRunSkipsPaused(c *check.C) {
+	s.createApp(c, "myapp")
+	err := SaveSpec(manifest.Manifest{Name: "myapp", Description: "managed"}, false)
+	c.Assert(err, check.IsNil)
+	err = SetPaused("myapp", true)
+	c.Assert(err, check.IsNil)
+	ctrl := &controller{shutdown: make(chan struct{})}
+	ctrl.run()
+	a, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Description, check.Equals, "")
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reconcile
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+)
+
+var ErrSpecNotFound = errors.New("app is not managed by a declarative spec")
+
+// Spec is the manifest stored for an app managed declaratively, along with
+// the state of its reconciliation.
+type Spec struct {
+	App       string            `bson:"_id" json:"app"`
+	Manifest  manifest.Manifest `json:"-"`
+	Prune     bool              `json:"prune"`
+	Paused    bool              `json:"paused"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	LastCheck time.Time         `json:"lastCheck"`
+	LastDrift []manifest.Change `json:"lastDrift"`
+}
+
+func specsCollection(conn *db.Storage) *storage.Collection {
+	return conn.Collection("app_specs")
+}
+
+// SaveSpec stores the manifest applied to an app, which will be re-applied
+// periodically by the controller. Pause state is kept for existing specs.
+func SaveSpec(m manifest.Manifest, prune bool) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = specsCollection(conn).UpsertId(m.Name, bson.M{"$set": bson.M{
+		"manifest":  m,
+		"prune":     prune,
+		"updatedat": time.Now().UTC(),
+	}})
+	return err
+}
+
+func GetSpec(appName string) (*Spec, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var spec Spec
+	err = specsCollection(conn).FindId(appName).One(&spec)
+	if err == mgo.ErrNotFound {
+		return nil, ErrSpecNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+func ListSpecs() ([]Spec, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var specs []Spec
+	err = specsCollection(conn).Find(nil).Sort("_id").All(&specs)
+	if err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// SetPaused pauses or resumes the reconciliation of an app.
+func SetPaused(appName string, paused bool) error {
+	return updateSpec(appName, bson.M{"paused": paused})
+}
+
+func RemoveSpec(appName string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = specsCollection(conn).RemoveId(appName)
+	if err == mgo.ErrNotFound {
+		return ErrSpecNotFound
+	}
+	return err
+}
+
+func updateSpec(appName string, fields bson.M) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = specsCollection(conn).UpdateId(appName, bson.M{"$set": fields})
+	if err == mgo.ErrNotFound {
+		return ErrSpecNotFound
+	}
+	return err
+}
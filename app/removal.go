@@ -0,0 +1,241 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/router"
+)
+
+const defaultRemovalTrafficWindow = time.Hour
+
+// Kinds of blockers found while checking whether an app can be removed.
+const (
+	RemovalBlockerTraffic = "traffic"
+	RemovalBlockerBinding = "binding"
+	RemovalBlockerCName   = "cname"
+)
+
+var (
+	lookupCNAME = net.LookupCNAME
+	lookupHost  = net.LookupHost
+)
+
+// RemovalBlocker is a reason to keep an app from being removed.
+type RemovalBlocker struct {
+	Kind    string `json:"kind"`
+	Target  string `json:"target"`
+	Message string `json:"message"`
+}
+
+// RemovalReport is the result of the checks run before removing an app.
+type RemovalReport struct {
+	App      string           `json:"app"`
+	Blockers []RemovalBlocker `json:"blockers"`
+	// Errors holds the checks that could not be completed, they don't block
+	// the removal.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Blocked returns whether any blocker was found.
+func (r *RemovalReport) Blocked() bool {
+	return len(r.Blockers) > 0
+}
+
+func (r *RemovalReport) Error() string {
+	msgs := make([]string, len(r.Blockers))
+	for i, b := range r.Blockers {
+		msgs[i] = b.Message
+	}
+	return fmt.Sprintf("unable to remove app %q: %s", r.App, strings.Join(msgs, "; "))
+}
+
+// RemovalSafetyChecksEnabled returns whether apps must pass the removal checks
+// before being removed, which is set by the app-removal:safety-checks config.
+func RemovalSafetyChecksEnabled() bool {
+	enabled, _ := config.GetBool("app-removal:safety-checks")
+	return enabled
+}
+
+// CheckRemoval looks for signs that the app is still in use: requests received
+// by its routers recently, other apps with environment variables pointing to
+// its addresses and cnames whose DNS records still point to its routers.
+func CheckRemoval(ctx context.Context, app *App) (*RemovalReport, error) {
+	report := RemovalReport{App: app.Name, Blockers: []RemovalBlocker{}}
+	routers, err := app.GetRoutersWithAddr()
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("unable to get router addresses: %s", err))
+	}
+	window := defaultRemovalTrafficWindow
+	if value, errConf := config.GetDuration("app-removal:traffic-window"); errConf == nil && value > 0 {
+		window = value
+	}
+	var addresses []string
+	for _, appRouter := range routers {
+		if appRouter.Address != "" {
+			addresses = append(addresses, addressHost(appRouter.Address))
+		}
+		r, errGet := router.Get(ctx, appRouter.Name)
+		if errGet != nil {
+			report.Errors = append(report.Errors, errGet.Error())
+			continue
+		}
+		trafficRouter, ok := router.AsTrafficRouter(r)
+		if !ok {
+			continue
+		}
+		count, errCount := trafficRouter.RequestCount(ctx, app, time.Now().Add(-window))
+		if errCount != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("unable to get traffic from router %q: %s", appRouter.Name, errCount))
+			continue
+		}
+		if count > 0 {
+			report.Blockers = append(report.Blockers, RemovalBlocker{
+				Kind:    RemovalBlockerTraffic,
+				Target:  appRouter.Name,
+				Message: fmt.Sprintf("router %q received %d requests in the last %s", appRouter.Name, count, window),
+			})
+		}
+	}
+	bindings, err := appsReferencing(app.Name, append(addresses, app.CName...))
+	if err != nil {
+		return nil, err
+	}
+	report.Blockers = append(report.Blockers, bindings...)
+	for _, cname := range app.CName {
+		target, errLookup := cnamePointsTo(cname, addresses)
+		if errLookup != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("unable to resolve cname %q: %s", cname, errLookup))
+			continue
+		}
+		if target != "" {
+			report.Blockers = append(report.Blockers, RemovalBlocker{
+				Kind:    RemovalBlockerCName,
+				Target:  cname,
+				Message: fmt.Sprintf("cname %q still points to %q", cname, target),
+			})
+		}
+	}
+	return &report, nil
+}
+
+// appsReferencing returns a blocker for each environment variable of other
+// apps whose value references one of the hosts. Values are split in host
+// names, so that a host is not found inside longer host names.
+func appsReferencing(appName string, hosts []string) ([]RemovalBlocker, error) {
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var apps []struct {
+		Name string
+		Env  map[string]bind.EnvVar
+	}
+	err = conn.Apps().Find(bson.M{"name": bson.M{"$ne": appName}}).Select(bson.M{"name": 1, "env": 1}).All(&apps)
+	if err != nil {
+		return nil, err
+	}
+	var blockers []RemovalBlocker
+	for _, a := range apps {
+		names := make([]string, 0, len(a.Env))
+		for name := range a.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			valueHosts := hostTokens(a.Env[name].Value)
+			for _, host := range hosts {
+				if valueHosts[strings.ToLower(host)] {
+					blockers = append(blockers, RemovalBlocker{
+						Kind:    RemovalBlockerBinding,
+						Target:  a.Name,
+						Message: fmt.Sprintf("app %q references %q in env %s", a.Name, host, name),
+					})
+					break
+				}
+			}
+		}
+	}
+	return blockers, nil
+}
+
+// hostTokens splits the value in the sequences of characters allowed in
+// host names, like the host in an URL or in a host:port pair.
+func hostTokens(value string) map[string]bool {
+	tokens := strings.FieldsFunc(value, func(r rune) bool {
+		return !(r == '.' || r == '-' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'))
+	})
+	result := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		result[strings.ToLower(strings.Trim(token, "."))] = true
+	}
+	return result
+}
+
+// cnamePointsTo returns the router address the cname resolves to, or an empty
+// string if it doesn't resolve to any of the addresses.
+func cnamePointsTo(cname string, addresses []string) (string, error) {
+	if len(addresses) == 0 {
+		return "", nil
+	}
+	canonical, err := lookupCNAME(cname)
+	if err == nil {
+		canonical = strings.TrimSuffix(canonical, ".")
+		for _, addr := range addresses {
+			if strings.EqualFold(canonical, addr) {
+				return addr, nil
+			}
+		}
+	}
+	cnameIPs, err := lookupHost(cname)
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, addr := range addresses {
+		addrIPs := []string{addr}
+		if net.ParseIP(addr) == nil {
+			addrIPs, err = lookupHost(addr)
+			if err != nil {
+				continue
+			}
+		}
+		for _, ip := range cnameIPs {
+			for _, addrIP := range addrIPs {
+				if ip == addrIP {
+					return addr, nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+func addressHost(address string) string {
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		address = u.Host
+	}
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	return address
+}
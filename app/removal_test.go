@@ -0,0 +1,92 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"net"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestCheckRemovalNoBlockers(c *check.C) {
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	report, err := CheckRemoval(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Blocked(), check.Equals, false)
+	c.Assert(report.Blockers, check.DeepEquals, []RemovalBlocker{})
+}
+
+func (s *S) TestCheckRemovalTraffic(c *check.C) {
+	config.Set("routers:mytraffic:type", "fake-traffic")
+	defer config.Unset("routers:mytraffic")
+	defer routertest.TrafficRouter.Reset()
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.AddRouter(appTypes.AppRouter{Name: "mytraffic"})
+	c.Assert(err, check.IsNil)
+	routertest.TrafficRouter.Requests["myapp"] = 42
+	report, err := CheckRemoval(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Blocked(), check.Equals, true)
+	c.Assert(report.Blockers, check.DeepEquals, []RemovalBlocker{
+		{Kind: RemovalBlockerTraffic, Target: "mytraffic", Message: `router "mytraffic" received 42 requests in the last 1h0m0s`},
+	})
+}
+
+func (s *S) TestCheckRemovalBindings(c *check.C) {
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	other := App{Name: "otherapp", Platform: "go", TeamOwner: s.team.Name}
+	err = CreateApp(context.TODO(), &other, s.user)
+	c.Assert(err, check.IsNil)
+	err = s.conn.Apps().Update(map[string]string{"name": "otherapp"}, map[string]interface{}{
+		"$set": map[string]interface{}{"env": map[string]bind.EnvVar{
+			"API_URL": {Name: "API_URL", Value: "http://myapp.fakerouter.com/v1"},
+			"DB_HOST": {Name: "DB_HOST", Value: "db.example.com"},
+			"OLD_URL": {Name: "OLD_URL", Value: "https://notmyapp.fakerouter.com:443"},
+			"PROXY":   {Name: "PROXY", Value: "myapp.fakerouter.com.example.net"},
+		}},
+	})
+	c.Assert(err, check.IsNil)
+	report, err := CheckRemoval(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Blockers, check.DeepEquals, []RemovalBlocker{
+		{Kind: RemovalBlockerBinding, Target: "otherapp", Message: `app "otherapp" references "myapp.fakerouter.com" in env API_URL`},
+	})
+}
+
+func (s *S) TestCheckRemovalCName(c *check.C) {
+	defer func() {
+		lookupCNAME = net.LookupCNAME
+		lookupHost = net.LookupHost
+	}()
+	lookupCNAME = func(host string) (string, error) {
+		if host == "www.example.com" {
+			return "myapp.fakerouter.com.", nil
+		}
+		return host + ".", nil
+	}
+	lookupHost = func(host string) ([]string, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	a := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, CName: []string{"www.example.com", "old.example.com"}}
+	err := CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	report, err := CheckRemoval(context.TODO(), &a)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Blockers, check.DeepEquals, []RemovalBlocker{
+		{Kind: RemovalBlockerCName, Target: "www.example.com", Message: `cname "www.example.com" still points to "myapp.fakerouter.com"`},
+	})
+	c.Assert(report.Errors, check.IsNil)
+}
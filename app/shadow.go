@@ -0,0 +1,194 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/rebuild"
+	"github.com/tsuru/tsuru/servicemanager"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+)
+
+var errShadowNotSupported = &tsuruErrors.ValidationError{Message: "traffic shadowing is not supported by the app routers"}
+
+// ShadowComparison compares the responses of the requests mirrored to the
+// shadow version of the app in a router with the responses served by the
+// routable versions. Latency deltas are in milliseconds, positive when the
+// shadow version is slower.
+type ShadowComparison struct {
+	Router           string                    `json:"router"`
+	Version          int                       `json:"version"`
+	Percent          int                       `json:"percent"`
+	Requests         int64                     `json:"requests"`
+	StatusCodes      map[int]ShadowStatusCount `json:"statusCodes"`
+	Mismatches       int64                     `json:"mismatches"`
+	PrimaryErrorRate float64                   `json:"primaryErrorRate"`
+	ShadowErrorRate  float64                   `json:"shadowErrorRate"`
+	LatencyP50Delta  float64                   `json:"latencyP50Delta"`
+	LatencyP99Delta  float64                   `json:"latencyP99Delta"`
+	Report           routerTypes.ShadowReport  `json:"report"`
+}
+
+// ShadowStatusCount holds the responses with a status code answered by the
+// routable versions and by the shadow version.
+type ShadowStatusCount struct {
+	Primary int64 `json:"primary"`
+	Shadow  int64 `json:"shadow"`
+}
+
+// GetShadow returns the version receiving mirrored requests of the app, if
+// any.
+func (app *App) GetShadow() *routerTypes.Shadow {
+	return app.Shadow
+}
+
+// SetShadow starts mirroring requests of the app to the units of a version,
+// replacing the version previously shadowed. A nil shadow stops the
+// mirroring.
+func (app *App) SetShadow(shadow *routerTypes.Shadow) error {
+	if shadow != nil {
+		err := shadow.Validate()
+		if err != nil {
+			return &tsuruErrors.ValidationError{Message: err.Error()}
+		}
+		supported, err := app.supportsShadow()
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return errShadowNotSupported
+		}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"$set": bson.M{"shadow": shadow}}
+	if shadow == nil {
+		update = bson.M{"$unset": bson.M{"shadow": ""}}
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.Shadow = shadow
+	rebuild.RoutesRebuildOrEnqueue(app.Name)
+	return nil
+}
+
+func (app *App) supportsShadow() (bool, error) {
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(app.ctx, appRouter.Name)
+		if err != nil {
+			return false, err
+		}
+		if _, ok := router.AsShadowRouter(r); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// startShadow mirrors percent percent of the requests of the app to the
+// version deployed with imageID.
+func (app *App) startShadow(ctx context.Context, imageID string, percent int, w io.Writer) error {
+	version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, imageID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n---- Mirroring %d%% of the requests to version %d ----\n", percent, version.Version())
+	return app.SetShadow(&routerTypes.Shadow{Version: version.Version(), Percent: percent})
+}
+
+// stopShadowOf stops the mirroring of requests when version is the shadowed
+// version, it's called when the version is promoted or removed.
+func (app *App) stopShadowOf(version int) error {
+	if app.Shadow == nil || app.Shadow.Version != version {
+		return nil
+	}
+	return app.SetShadow(nil)
+}
+
+// ShadowReport compares, in each router able to report it, the responses of
+// the requests mirrored to the shadow version with the responses served to
+// the clients. It returns nil when no version is shadowed.
+func (app *App) ShadowReport(ctx context.Context) ([]ShadowComparison, error) {
+	if app.Shadow == nil {
+		return nil, nil
+	}
+	var result []ShadowComparison
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(ctx, appRouter.Name)
+		if err != nil {
+			return nil, err
+		}
+		shadowRouter, ok := router.AsShadowRouter(r)
+		if !ok {
+			continue
+		}
+		report, err := shadowRouter.ShadowReport(ctx, app)
+		if err != nil {
+			return nil, err
+		}
+		comparison := compareShadow(*report)
+		comparison.Router = appRouter.Name
+		comparison.Version = app.Shadow.Version
+		comparison.Percent = app.Shadow.Percent
+		result = append(result, comparison)
+	}
+	return result, nil
+}
+
+func compareShadow(report routerTypes.ShadowReport) ShadowComparison {
+	comparison := ShadowComparison{
+		Requests:        report.Requests,
+		StatusCodes:     map[int]ShadowStatusCount{},
+		LatencyP50Delta: report.Shadow.LatencyP50 - report.Primary.LatencyP50,
+		LatencyP99Delta: report.Shadow.LatencyP99 - report.Primary.LatencyP99,
+		Report:          report,
+	}
+	for code, n := range report.Primary.StatusCodes {
+		count := comparison.StatusCodes[code]
+		count.Primary = n
+		comparison.StatusCodes[code] = count
+	}
+	for code, n := range report.Shadow.StatusCodes {
+		count := comparison.StatusCodes[code]
+		count.Shadow = n
+		comparison.StatusCodes[code] = count
+	}
+	for _, count := range comparison.StatusCodes {
+		if count.Shadow > count.Primary {
+			comparison.Mismatches += count.Shadow - count.Primary
+		}
+	}
+	comparison.PrimaryErrorRate = errorRate(report.Primary.StatusCodes)
+	comparison.ShadowErrorRate = errorRate(report.Shadow.StatusCodes)
+	return comparison
+}
+
+// errorRate returns the percentage of 5xx responses.
+func errorRate(statusCodes map[int]int64) float64 {
+	var total, errors int64
+	for code, n := range statusCodes {
+		total += n
+		if code >= 500 {
+			errors += n
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(errors)*10000/float64(total)) / 100
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/router/routertest"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestSetShadow(c *check.C) {
+	config.Set("routers:fake-shadow:type", "fake-shadow")
+	defer config.Unset("routers:fake-shadow:type")
+	defer routertest.ShadowRouter.Reset()
+	app := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "fake-shadow"}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	shadow := &routerTypes.Shadow{Version: 2, Percent: 10}
+	err = app.SetShadow(shadow)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.ShadowRouter.Shadows["myapp"], check.DeepEquals, shadow)
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Shadow, check.DeepEquals, shadow)
+	err = dbApp.stopShadowOf(1)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.ShadowRouter.Shadows["myapp"], check.DeepEquals, shadow)
+	err = dbApp.stopShadowOf(2)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.ShadowRouter.Shadows["myapp"], check.IsNil)
+	dbApp, err = GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Shadow, check.IsNil)
+}
+
+func (s *S) TestSetShadowInvalid(c *check.C) {
+	app := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	err = app.SetShadow(&routerTypes.Shadow{Version: 2, Percent: 101})
+	c.Assert(err, check.ErrorMatches, "shadow percent must be between 1 and 100")
+	err = app.SetShadow(&routerTypes.Shadow{Version: 2, Percent: 10})
+	c.Assert(err, check.Equals, errShadowNotSupported)
+}
+
+func (s *S) TestShadowReport(c *check.C) {
+	config.Set("routers:fake-shadow:type", "fake-shadow")
+	defer config.Unset("routers:fake-shadow:type")
+	defer routertest.ShadowRouter.Reset()
+	app := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "fake-shadow"}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	report, err := app.ShadowReport(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.IsNil)
+	err = app.SetShadow(&routerTypes.Shadow{Version: 2, Percent: 10})
+	c.Assert(err, check.IsNil)
+	routertest.ShadowRouter.Reports["myapp"] = &routerTypes.ShadowReport{
+		Requests: 4,
+		Primary:  routerTypes.ShadowBackendStats{StatusCodes: map[int]int64{200: 4}},
+		Shadow:   routerTypes.ShadowBackendStats{StatusCodes: map[int]int64{200: 4}},
+	}
+	report, err = app.ShadowReport(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.HasLen, 1)
+	c.Assert(report[0].Router, check.Equals, "fake-shadow")
+	c.Assert(report[0].Version, check.Equals, 2)
+	c.Assert(report[0].Percent, check.Equals, 10)
+	c.Assert(report[0].StatusCodes, check.DeepEquals, map[int]ShadowStatusCount{200: {Primary: 4, Shadow: 4}})
+}
+
+func (s *S) TestCompareShadow(c *check.C) {
+	report := routerTypes.ShadowReport{
+		Requests: 200,
+		Primary: routerTypes.ShadowBackendStats{
+			StatusCodes: map[int]int64{200: 190, 404: 8, 500: 2},
+			LatencyP50:  10,
+			LatencyP99:  100,
+		},
+		Shadow: routerTypes.ShadowBackendStats{
+			StatusCodes: map[int]int64{200: 180, 404: 8, 502: 12},
+			LatencyP50:  12.5,
+			LatencyP99:  90,
+		},
+	}
+	comparison := compareShadow(report)
+	c.Assert(comparison, check.DeepEquals, ShadowComparison{
+		Requests: 200,
+		StatusCodes: map[int]ShadowStatusCount{
+			200: {Primary: 190, Shadow: 180},
+			404: {Primary: 8, Shadow: 8},
+			500: {Primary: 2},
+			502: {Shadow: 12},
+		},
+		Mismatches:       12,
+		PrimaryErrorRate: 1,
+		ShadowErrorRate:  6,
+		LatencyP50Delta:  2.5,
+		LatencyP99Delta:  -10,
+		Report:           report,
+	})
+	c.Assert(compareShadow(routerTypes.ShadowReport{}).ShadowErrorRate, check.Equals, float64(0))
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/api/tracker"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	defaultInterval  = time.Hour
+	defaultRetention = 30 * 24 * time.Hour
+)
+
+// Initialize starts the controller taking periodic snapshots of all apps,
+// unless disabled by the app-snapshots:disabled config entry.
+func Initialize() error {
+	disabled, _ := config.GetBool("app-snapshots:disabled")
+	if disabled {
+		return nil
+	}
+	interval, _ := config.GetDuration("app-snapshots:interval")
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	retention, _ := config.GetDuration("app-snapshots:retention")
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	c := &controller{interval: interval, retention: retention}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type controller struct {
+	interval  time.Duration
+	retention time.Duration
+	shutdown  chan struct{}
+	done      chan struct{}
+}
+
+func (c *controller) start() {
+	c.shutdown = make(chan struct{})
+	c.done = make(chan struct{})
+	log.Debugf("[app-snapshots] starting. Running every %s.", c.interval)
+	go func() {
+		defer close(c.done)
+		for {
+			c.run()
+			select {
+			case <-time.After(c.interval):
+			case <-c.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (c *controller) run() {
+	if !tracker.IsLeader("app-snapshot") {
+		return
+	}
+	apps, err := app.List(context.Background(), nil)
+	if err != nil {
+		log.Errorf("[app-snapshots] unable to list apps: %v", err)
+		return
+	}
+	for i := range apps {
+		select {
+		case <-c.shutdown:
+			return
+		default:
+		}
+		_, err = Take(&apps[i])
+		if err != nil {
+			log.Errorf("[app-snapshots] unable to take snapshot of app %q: %v", apps[i].Name, err)
+			continue
+		}
+		err = RemoveOlderThan(apps[i].Name, time.Now().Add(-c.retention))
+		if err != nil {
+			log.Errorf("[app-snapshots] unable to remove old snapshots of app %q: %v", apps[i].Name, err)
+		}
+	}
+}
+
+// Shutdown stops the controller, waiting for the current app to be
+// handled.
+func (c *controller) Shutdown(ctx context.Context) error {
+	close(c.shutdown)
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,344 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package snapshot keeps periodic snapshots of the configuration of apps,
+// allowing apps to be restored to the configuration they had at a point in
+// time.
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/reconcile"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/secret"
+)
+
+var ErrSnapshotNotFound = errors.New("no snapshot found for the app at the given time")
+
+// Snapshot is the configuration of an app at a point in time, using the
+// same representation as declarative manifests.
+//
+// Values of private environment variables are never stored in the manifest,
+// they're encrypted in EncryptedEnv when the secrets:encryption-key config
+// entry is set, or not stored at all otherwise, being listed in RedactedEnv.
+type Snapshot struct {
+	ID           bson.ObjectId     `bson:"_id" json:"id"`
+	App          string            `json:"app"`
+	Timestamp    time.Time         `json:"timestamp"`
+	Manifest     manifest.Manifest `json:"-"`
+	EncryptedEnv map[string][]byte `json:"-"`
+	RedactedEnv  []string          `json:"redactedEnv,omitempty"`
+}
+
+// protectPrivateEnvs moves the values of private environment variables out
+// of the manifest, encrypting them when possible.
+func (s *Snapshot) protectPrivateEnvs() error {
+	if len(s.Manifest.PrivateEnv) == 0 {
+		return nil
+	}
+	env := make(map[string]string, len(s.Manifest.Env))
+	for name, value := range s.Manifest.Env {
+		env[name] = value
+	}
+	for _, name := range s.Manifest.PrivateEnv {
+		value, ok := env[name]
+		if !ok {
+			continue
+		}
+		delete(env, name)
+		if !secret.Enabled() {
+			s.RedactedEnv = append(s.RedactedEnv, name)
+			continue
+		}
+		encrypted, err := secret.Encrypt([]byte(value))
+		if err != nil {
+			return err
+		}
+		if s.EncryptedEnv == nil {
+			s.EncryptedEnv = map[string][]byte{}
+		}
+		s.EncryptedEnv[name] = encrypted
+	}
+	s.Manifest.Env = env
+	return nil
+}
+
+// revealPrivateEnvs puts the values of private environment variables back
+// in the manifest. Variables whose values were not stored, or can't be
+// decrypted, take the values they have in current, being left untouched by
+// restores.
+func (s *Snapshot) revealPrivateEnvs(current *manifest.Manifest) {
+	if len(s.EncryptedEnv) == 0 && len(s.RedactedEnv) == 0 {
+		return
+	}
+	env := make(map[string]string, len(s.Manifest.Env))
+	for name, value := range s.Manifest.Env {
+		env[name] = value
+	}
+	missing := s.RedactedEnv
+	for name, encrypted := range s.EncryptedEnv {
+		value, err := secret.Decrypt(encrypted)
+		if err != nil {
+			log.Errorf("[app snapshots] unable to decrypt env %s of app %s: %v", name, s.App, err)
+			missing = append(missing, name)
+			continue
+		}
+		env[name] = string(value)
+	}
+	for _, name := range missing {
+		if value, ok := current.Env[name]; ok {
+			env[name] = value
+		}
+	}
+	s.Manifest.Env = env
+}
+
+// Restore is the result of restoring an app to a snapshot, or of previewing
+// the restore.
+type Restore struct {
+	Snapshot Snapshot          `json:"snapshot"`
+	Changes  []manifest.Change `json:"changes"`
+}
+
+func snapshotsCollection(conn *db.Storage) *storage.Collection {
+	coll := conn.Collection("app_snapshots")
+	coll.EnsureIndex(mgo.Index{Key: []string{"app", "-timestamp"}})
+	return coll
+}
+
+// Take stores a snapshot with the current configuration of the app, unless
+// it's unchanged since the latest snapshot. It returns whether a new snapshot
+// was stored.
+func Take(a *app.App) (bool, error) {
+	state, err := reconcile.State(a)
+	if err != nil {
+		return false, err
+	}
+	latest, err := At(a.Name, time.Now())
+	if err != nil && err != ErrSnapshotNotFound {
+		return false, err
+	}
+	if latest != nil {
+		latest.revealPrivateEnvs(state)
+		if len(manifest.Diff(&latest.Manifest, *state, true)) == 0 && len(manifest.Diff(state, latest.Manifest, true)) == 0 {
+			return false, nil
+		}
+	}
+	snapshot := Snapshot{
+		ID:        bson.NewObjectId(),
+		App:       a.Name,
+		Timestamp: time.Now().UTC(),
+		Manifest:  *state,
+	}
+	err = snapshot.protectPrivateEnvs()
+	if err != nil {
+		return false, err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	err = snapshotsCollection(conn).Insert(snapshot)
+	return err == nil, err
+}
+
+// List returns the snapshots of the app, newest first.
+func List(appName string) ([]Snapshot, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var snapshots []Snapshot
+	err = snapshotsCollection(conn).Find(bson.M{"app": appName}).Sort("-timestamp").All(&snapshots)
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// At returns the latest snapshot of the app taken until the given time.
+func At(appName string, at time.Time) (*Snapshot, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var snapshot Snapshot
+	err = snapshotsCollection(conn).Find(bson.M{
+		"app":       appName,
+		"timestamp": bson.M{"$lte": at.UTC()},
+	}).Sort("-timestamp").One(&snapshot)
+	if err == mgo.ErrNotFound {
+		return nil, ErrSnapshotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// RemoveOlderThan removes the snapshots taken before the given time, always
+// keeping the latest snapshot of each app.
+func RemoveOlderThan(appName string, before time.Time) error {
+	latest, err := At(appName, time.Now())
+	if err == ErrSnapshotNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = snapshotsCollection(conn).RemoveAll(bson.M{
+		"app":       appName,
+		"timestamp": bson.M{"$lt": before.UTC()},
+		"_id":       bson.M{"$ne": latest.ID},
+	})
+	return err
+}
+
+// Preview returns the changes needed to restore the app to the configuration
+// it had at the given time.
+func Preview(a *app.App, at time.Time) (*Restore, error) {
+	snapshot, err := At(a.Name, at)
+	if err != nil {
+		return nil, err
+	}
+	current, err := reconcile.State(a)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.revealPrivateEnvs(current)
+	restore := Restore{Snapshot: *snapshot, Changes: []manifest.Change{}}
+	changes := manifest.Diff(current, snapshot.Manifest, true)
+	if names := privacyChanges(current, &snapshot.Manifest); len(names) > 0 {
+		changes = mergeEnvSet(changes, names)
+	}
+	for _, change := range changes {
+		if (change.Kind == manifest.ChangeUnitsAdd || change.Kind == manifest.ChangeUnitsRemove) && a.Deploys == 0 {
+			continue
+		}
+		restore.Changes = append(restore.Changes, change)
+	}
+	return &restore, nil
+}
+
+// privacyChanges returns the environment variables set with the same value
+// in both manifests whose visibility differs.
+func privacyChanges(current, wanted *manifest.Manifest) []string {
+	var names []string
+	for name, value := range wanted.Env {
+		if currentValue, ok := current.Env[name]; ok && currentValue == value && current.IsPrivateEnv(name) != wanted.IsPrivateEnv(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func mergeEnvSet(changes []manifest.Change, names []string) []manifest.Change {
+	for i, change := range changes {
+		if change.Kind == manifest.ChangeEnvSet {
+			merged := append([]string{}, change.Names...)
+			for _, name := range names {
+				if !contains(merged, name) {
+					merged = append(merged, name)
+				}
+			}
+			sort.Strings(merged)
+			changes[i].Names = merged
+			return changes
+		}
+	}
+	envSet := manifest.Change{Kind: manifest.ChangeEnvSet, Names: names}
+	for i, change := range changes {
+		if change.Kind != manifest.ChangeCreate && change.Kind != manifest.ChangeUpdate {
+			return append(changes[:i], append([]manifest.Change{envSet}, changes[i:]...)...)
+		}
+	}
+	return append(changes, envSet)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreEnvs sets the environment variables to the values and visibility
+// they have in the snapshot.
+func restoreEnvs(a *app.App, m *manifest.Manifest, names []string, evt *event.Event) error {
+	envs := make([]bind.EnvVar, 0, len(names))
+	for _, name := range names {
+		envs = append(envs, bind.EnvVar{Name: name, Value: m.Env[name], Public: !m.IsPrivateEnv(name)})
+	}
+	return a.SetEnvs(bind.SetEnvArgs{Envs: envs, Writer: evt})
+}
+
+// RestoreApp reverts the configuration of the app to the one it had at the
+// given time. The app is restarted once, after all changes are applied, if
+// any of them requires it. A snapshot of the current configuration is taken
+// first, so that the restore itself may be reverted.
+func RestoreApp(ctx context.Context, a *app.App, at time.Time, evt *event.Event) (*Restore, error) {
+	restore, err := Preview(a, at)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = Take(a); err != nil {
+		return nil, errors.Wrap(err, "unable to snapshot current configuration")
+	}
+	var needsRestart bool
+	for _, change := range restore.Changes {
+		fmt.Fprintf(evt, "---- restoring: %s ----\n", change)
+		if change.Kind == manifest.ChangeEnvSet {
+			err = restoreEnvs(a, &restore.Snapshot.Manifest, change.Names, evt)
+		} else {
+			err = reconcile.ApplyChange(ctx, a, &restore.Snapshot.Manifest, change, false, evt)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to %s", change)
+		}
+		switch change.Kind {
+		case manifest.ChangeUpdate, manifest.ChangeEnvSet, manifest.ChangeEnvUnset, manifest.ChangeBind, manifest.ChangeUnbind:
+			needsRestart = true
+		}
+		a, err = app.GetByName(ctx, a.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !needsRestart {
+		return restore, nil
+	}
+	units, err := a.Units()
+	if err != nil {
+		return nil, err
+	}
+	if len(units) == 0 {
+		return restore, nil
+	}
+	fmt.Fprintf(evt, "---- restarting app ----\n")
+	return restore, a.Restart(ctx, "", "", evt)
+}
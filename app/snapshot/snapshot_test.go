@@ -0,0 +1,271 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/bind"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/version"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"golang.org/x/crypto/bcrypt"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage     *db.Storage
+	user        *auth.User
+	mockService servicemock.MockService
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_snapshot_tests")
+	config.Set("routers:fake:type", "fake")
+	config.Set("auth:hash-cost", bcrypt.MinCost)
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+	provision.DefaultProvisioner = "fake"
+	app.AuthScheme = auth.ManagedScheme(native.NativeScheme{})
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	provisiontest.ProvisionerInstance.Reset()
+	routertest.FakeRouter.Reset()
+	s.user, _ = permissiontest.CustomUserWithPermission(c, app.AuthScheme, "majortom", permission.Permission{
+		Scheme:  permission.PermAll,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "p1", Default: true})
+	c.Assert(err, check.IsNil)
+	servicemock.SetMockService(&s.mockService)
+	plan := appTypes.Plan{Name: "default", Default: true, CpuShare: 100}
+	s.mockService.Plan.OnList = func() ([]appTypes.Plan, error) {
+		return []appTypes.Plan{plan}, nil
+	}
+	s.mockService.Plan.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &plan, nil
+	}
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		if name == plan.Name {
+			return &plan, nil
+		}
+		return nil, appTypes.ErrPlanNotFound
+	}
+	servicemanager.AppVersion, err = version.AppVersionService()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+	s.storage.Close()
+}
+
+func (s *S) createApp(c *check.C, name string) *app.App {
+	a := app.App{Name: name, Platform: "python", TeamOwner: "myteam"}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) newEvent(c *check.C, appName string) *event.Event {
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: appName},
+		InternalKind: "restore-test",
+		Allowed:      event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *S) TestTakeSkipsUnchanged(c *check.C) {
+	a := s.createApp(c, "myapp")
+	taken, err := Take(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(taken, check.Equals, true)
+	taken, err = Take(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(taken, check.Equals, false)
+	err = a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{{Name: "DEBUG", Value: "1", Public: true}}})
+	c.Assert(err, check.IsNil)
+	taken, err = Take(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(taken, check.Equals, true)
+	snapshots, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshots, check.HasLen, 2)
+	c.Assert(snapshots[0].Manifest.Env, check.DeepEquals, map[string]string{"DEBUG": "1"})
+	c.Assert(snapshots[1].Manifest.Env, check.DeepEquals, map[string]string{})
+}
+
+func (s *S) TestAtNotFound(c *check.C) {
+	a := s.createApp(c, "myapp")
+	_, err := Take(a)
+	c.Assert(err, check.IsNil)
+	_, err = At("myapp", time.Now().Add(-time.Hour))
+	c.Assert(err, check.Equals, ErrSnapshotNotFound)
+	_, err = At("otherapp", time.Now())
+	c.Assert(err, check.Equals, ErrSnapshotNotFound)
+}
+
+func (s *S) TestRemoveOlderThanKeepsLatest(c *check.C) {
+	a := s.createApp(c, "myapp")
+	_, err := Take(a)
+	c.Assert(err, check.IsNil)
+	err = a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{{Name: "DEBUG", Value: "1", Public: true}}})
+	c.Assert(err, check.IsNil)
+	_, err = Take(a)
+	c.Assert(err, check.IsNil)
+	err = RemoveOlderThan("myapp", time.Now().Add(time.Hour))
+	c.Assert(err, check.IsNil)
+	snapshots, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshots, check.HasLen, 1)
+	c.Assert(snapshots[0].Manifest.Env, check.DeepEquals, map[string]string{"DEBUG": "1"})
+}
+
+func (s *S) TestRestoreApp(c *check.C) {
+	a := s.createApp(c, "myapp")
+	err := a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{{Name: "DEBUG", Value: "0", Public: true}}})
+	c.Assert(err, check.IsNil)
+	_, err = Take(a)
+	c.Assert(err, check.IsNil)
+	at := time.Now()
+	err = a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{
+		{Name: "DEBUG", Value: "1", Public: true},
+		{Name: "NEW", Value: "x", Public: true},
+	}})
+	c.Assert(err, check.IsNil)
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	preview, err := Preview(a, at)
+	c.Assert(err, check.IsNil)
+	c.Assert(preview.Changes, check.DeepEquals, []manifest.Change{
+		{Kind: manifest.ChangeEnvSet, Names: []string{"DEBUG"}},
+		{Kind: manifest.ChangeEnvUnset, Names: []string{"NEW"}},
+	})
+	updated, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Env["DEBUG"].Value, check.Equals, "1")
+	evt := s.newEvent(c, "myapp")
+	restore, err := RestoreApp(context.TODO(), a, at, evt)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Done(nil), check.IsNil)
+	c.Assert(restore.Changes, check.DeepEquals, preview.Changes)
+	updated, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Env["DEBUG"].Value, check.Equals, "0")
+	_, ok := updated.Env["NEW"]
+	c.Assert(ok, check.Equals, false)
+	snapshots, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshots, check.HasLen, 2)
+	c.Assert(snapshots[0].Manifest.Env, check.DeepEquals, map[string]string{"DEBUG": "1", "NEW": "x"})
+}
+
+func (s *S) TestRestoreAppPrivateEnvs(c *check.C) {
+	config.Set("secrets:encryption-key", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	defer config.Unset("secrets")
+	a := s.createApp(c, "myapp")
+	err := a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{{Name: "TOKEN", Value: "secret"}}})
+	c.Assert(err, check.IsNil)
+	_, err = Take(a)
+	c.Assert(err, check.IsNil)
+	var raw []bson.M
+	err = s.storage.Collection("app_snapshots").Find(nil).All(&raw)
+	c.Assert(err, check.IsNil)
+	c.Assert(raw, check.HasLen, 1)
+	data, err := bson.Marshal(raw[0])
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Not(check.Matches), "(?s).*secret.*")
+	at := time.Now()
+	err = a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{{Name: "TOKEN", Value: "leaked", Public: true}}})
+	c.Assert(err, check.IsNil)
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	evt := s.newEvent(c, "myapp")
+	restore, err := RestoreApp(context.TODO(), a, at, evt)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.Done(nil), check.IsNil)
+	c.Assert(restore.Changes, check.DeepEquals, []manifest.Change{
+		{Kind: manifest.ChangeEnvSet, Names: []string{"TOKEN"}},
+	})
+	updated, err := app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(updated.Env["TOKEN"].Value, check.Equals, "secret")
+	c.Assert(updated.Env["TOKEN"].Public, check.Equals, false)
+}
+
+func (s *S) TestRestoreAppRedactedEnvs(c *check.C) {
+	a := s.createApp(c, "myapp")
+	err := a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{
+		{Name: "DEBUG", Value: "0", Public: true},
+		{Name: "TOKEN", Value: "secret"},
+	}})
+	c.Assert(err, check.IsNil)
+	_, err = Take(a)
+	c.Assert(err, check.IsNil)
+	snapshots, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(snapshots, check.HasLen, 1)
+	c.Assert(snapshots[0].Manifest.Env, check.DeepEquals, map[string]string{"DEBUG": "0"})
+	c.Assert(snapshots[0].RedactedEnv, check.DeepEquals, []string{"TOKEN"})
+	changed, err := Take(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(changed, check.Equals, false)
+	at := time.Now()
+	err = a.SetEnvs(bind.SetEnvArgs{Envs: []bind.EnvVar{
+		{Name: "DEBUG", Value: "1", Public: true},
+		{Name: "TOKEN", Value: "rotated"},
+	}})
+	c.Assert(err, check.IsNil)
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	preview, err := Preview(a, at)
+	c.Assert(err, check.IsNil)
+	c.Assert(preview.Changes, check.DeepEquals, []manifest.Change{
+		{Kind: manifest.ChangeEnvSet, Names: []string{"DEBUG"}},
+	})
+}
+
+func (s *S) TestRestoreAppNoSnapshot(c *check.C) {
+	a := s.createApp(c, "myapp")
+	evt := s.newEvent(c, "myapp")
+	defer evt.Done(nil)
+	_, err := RestoreApp(context.TODO(), a, time.Now(), evt)
+	c.Assert(err, check.Equals, ErrSnapshotNotFound)
+}
@@ -0,0 +1,329 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package template manages app templates: sets of defaults, such as plan,
+// pool, teams, tags, environment variables and autoscale rules, that are
+// applied to apps created from them and may later be synced to those apps.
+package template
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/reconcile"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+)
+
+var (
+	ErrTemplateNotFound      = errors.New("app template not found")
+	ErrTemplateAlreadyExists = errors.New("app template already exists")
+	ErrTemplateInUse         = errors.New("app template is in use by apps")
+)
+
+// Template holds the defaults applied to apps created from it.
+type Template struct {
+	Name      string                    `bson:"_id" json:"name"`
+	Plan      string                    `json:"plan,omitempty"`
+	Pool      string                    `json:"pool,omitempty"`
+	Teams     []string                  `json:"teams,omitempty"`
+	Tags      []string                  `json:"tags,omitempty"`
+	Env       map[string]string         `json:"env,omitempty"`
+	AutoScale []provision.AutoScaleSpec `json:"autoscale,omitempty"`
+}
+
+// SyncResult is the outcome of syncing a template to one of its apps.
+type SyncResult struct {
+	App     string            `json:"app"`
+	Changes []manifest.Change `json:"changes"`
+	Error   string            `json:"error,omitempty"`
+}
+
+func templatesCollection(conn *db.Storage) *storage.Collection {
+	return conn.Collection("app_templates")
+}
+
+func (t *Template) validate(ctx context.Context) error {
+	if t.Name == "" {
+		return &tsuruErrors.ValidationError{Message: "app template name is required"}
+	}
+	if t.Pool != "" {
+		if _, err := pool.GetPoolByName(ctx, t.Pool); err != nil {
+			if err == pool.ErrPoolNotFound {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("pool %q not found", t.Pool)}
+			}
+			return err
+		}
+	}
+	if t.Plan != "" {
+		if _, err := servicemanager.Plan.FindByName(ctx, t.Plan); err != nil {
+			if err == appTypes.ErrPlanNotFound {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("plan %q not found", t.Plan)}
+			}
+			return err
+		}
+	}
+	for _, name := range t.Teams {
+		if _, err := servicemanager.Team.FindByName(ctx, name); err != nil {
+			if err == authTypes.ErrTeamNotFound {
+				return &tsuruErrors.ValidationError{Message: fmt.Sprintf("team %q not found", name)}
+			}
+			return err
+		}
+	}
+	for _, spec := range t.AutoScale {
+		if spec.Process == "" {
+			return &tsuruErrors.ValidationError{Message: "autoscale process is required"}
+		}
+		if spec.MaxUnits < spec.MinUnits {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("autoscale max units must not be lower than min units for process %q", spec.Process)}
+		}
+	}
+	return nil
+}
+
+// Create stores a new template.
+func Create(ctx context.Context, t Template) error {
+	if err := t.validate(ctx); err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = templatesCollection(conn).Insert(t)
+	if mgo.IsDup(err) {
+		return ErrTemplateAlreadyExists
+	}
+	return err
+}
+
+// Update replaces an existing template. Apps created from it are only changed
+// when the template is synced.
+func Update(ctx context.Context, t Template) error {
+	if err := t.validate(ctx); err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = templatesCollection(conn).UpdateId(t.Name, t)
+	if err == mgo.ErrNotFound {
+		return ErrTemplateNotFound
+	}
+	return err
+}
+
+// Remove removes a template, as long as no app was created from it.
+func Remove(name string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	n, err := conn.Apps().Find(bson.M{"template": name}).Count()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrTemplateInUse
+	}
+	err = templatesCollection(conn).RemoveId(name)
+	if err == mgo.ErrNotFound {
+		return ErrTemplateNotFound
+	}
+	return err
+}
+
+// Get returns the template with the given name.
+func Get(name string) (*Template, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var t Template
+	err = templatesCollection(conn).FindId(name).One(&t)
+	if err == mgo.ErrNotFound {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List returns all templates, sorted by name.
+func List() ([]Template, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var templates []Template
+	err = templatesCollection(conn).Find(nil).Sort("_id").All(&templates)
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Apps returns the names of the apps created from the template.
+func Apps(name string) ([]string, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var apps []struct{ Name string }
+	err = conn.Apps().Find(bson.M{"template": name}).Select(bson.M{"name": 1}).Sort("name").All(&apps)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(apps))
+	for i, a := range apps {
+		names[i] = a.Name
+	}
+	return names, nil
+}
+
+// ApplyDefaults fills the app with the template defaults that must be known
+// before the app is created: plan and pool, unless explicitly set, and tags.
+func (t *Template) ApplyDefaults(a *app.App) {
+	a.Template = t.Name
+	if a.Plan.Name == "" {
+		a.Plan = appTypes.Plan{Name: t.Plan}
+	}
+	if a.Pool == "" {
+		a.Pool = t.Pool
+	}
+	a.Tags = union(a.Tags, t.Tags)
+}
+
+// Preview returns the changes needed to sync the app to the template. Plan
+// and pool are overwritten, while tags and environment variables set
+// directly on the app are kept.
+func (t *Template) Preview(a *app.App) ([]manifest.Change, error) {
+	current, err := reconcile.State(a)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Diff(current, t.manifest(a), false), nil
+}
+
+func (t *Template) manifest(a *app.App) manifest.Manifest {
+	return manifest.Manifest{
+		Name: a.Name,
+		Plan: t.Plan,
+		Pool: t.Pool,
+		Tags: union(a.Tags, t.Tags),
+		Env:  t.Env,
+	}
+}
+
+// Sync converges the app to the template, granting the template teams access
+// to it and, for apps already deployed, setting the template autoscale
+// rules. The app is restarted once, after all changes are applied, if any of
+// them requires it.
+func (t *Template) Sync(ctx context.Context, a *app.App, evt *event.Event) ([]manifest.Change, error) {
+	changes, err := t.Preview(a)
+	if err != nil {
+		return nil, err
+	}
+	wanted := t.manifest(a)
+	for _, change := range changes {
+		fmt.Fprintf(evt, "---- applying template %q: %s ----\n", t.Name, change)
+		err = reconcile.ApplyChange(ctx, a, &wanted, change, false, evt)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to %s", change)
+		}
+		a, err = app.GetByName(ctx, a.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range t.Teams {
+		team, err := servicemanager.Team.FindByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		err = a.Grant(team)
+		if err == app.ErrAlreadyHaveAccess {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(evt, "---- applying template %q: grant access to team %s ----\n", t.Name, name)
+	}
+	if a.Deploys > 0 && len(t.AutoScale) > 0 {
+		if err = t.syncAutoScale(a, evt); err != nil {
+			return nil, err
+		}
+	}
+	if len(changes) == 0 {
+		return changes, nil
+	}
+	units, err := a.Units()
+	if err != nil {
+		return nil, err
+	}
+	if len(units) == 0 {
+		return changes, nil
+	}
+	fmt.Fprintf(evt, "---- restarting app ----\n")
+	return changes, a.Restart(ctx, "", "", evt)
+}
+
+func (t *Template) syncAutoScale(a *app.App, evt *event.Event) error {
+	current, err := a.AutoScaleInfo()
+	if err != nil {
+		return err
+	}
+	byProcess := make(map[string]provision.AutoScaleSpec, len(current))
+	for _, spec := range current {
+		byProcess[spec.Process] = spec
+	}
+	for _, spec := range t.AutoScale {
+		existing, ok := byProcess[spec.Process]
+		if ok && existing.MinUnits == spec.MinUnits && existing.MaxUnits == spec.MaxUnits && existing.AverageCPU == spec.AverageCPU {
+			continue
+		}
+		fmt.Fprintf(evt, "---- applying template %q: set autoscale for process %s ----\n", t.Name, spec.Process)
+		if err = a.AutoScale(spec); err != nil {
+			return errors.Wrapf(err, "unable to set autoscale for process %q", spec.Process)
+		}
+	}
+	return nil
+}
+
+func union(a, b []string) []string {
+	result := append([]string{}, a...)
+	seen := make(map[string]struct{}, len(a)+len(b))
+	for _, s := range a {
+		seen[s] = struct{}{}
+	}
+	for _, s := range b {
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
+			result = append(result, s)
+		}
+	}
+	return result
+}
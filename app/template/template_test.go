@@ -0,0 +1,236 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/version"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/auth/native"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/permission/permissiontest"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/router/routertest"
+	"github.com/tsuru/tsuru/servicemanager"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	"golang.org/x/crypto/bcrypt"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage     *db.Storage
+	user        *auth.User
+	mockService servicemock.MockService
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "app_template_tests")
+	config.Set("routers:fake:type", "fake")
+	config.Set("auth:hash-cost", bcrypt.MinCost)
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+	provision.DefaultProvisioner = "fake"
+	app.AuthScheme = auth.ManagedScheme(native.NativeScheme{})
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	provisiontest.ProvisionerInstance.Reset()
+	routertest.FakeRouter.Reset()
+	s.user, _ = permissiontest.CustomUserWithPermission(c, app.AuthScheme, "majortom", permission.Permission{
+		Scheme:  permission.PermAll,
+		Context: permission.Context(permTypes.CtxGlobal, ""),
+	})
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "p1", Default: true})
+	c.Assert(err, check.IsNil)
+	err = pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "p2", Public: true})
+	c.Assert(err, check.IsNil)
+	servicemock.SetMockService(&s.mockService)
+	plans := []appTypes.Plan{
+		{Name: "default", Default: true, CpuShare: 100},
+		{Name: "large", CpuShare: 200},
+	}
+	s.mockService.Plan.OnList = func() ([]appTypes.Plan, error) {
+		return plans, nil
+	}
+	s.mockService.Plan.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &plans[0], nil
+	}
+	s.mockService.Plan.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		for i := range plans {
+			if plans[i].Name == name {
+				return &plans[i], nil
+			}
+		}
+		return nil, appTypes.ErrPlanNotFound
+	}
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		if name == "myteam" || name == "ops" {
+			return &authTypes.Team{Name: name}, nil
+		}
+		return nil, authTypes.ErrTeamNotFound
+	}
+	servicemanager.AppVersion, err = version.AppVersionService()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+	s.storage.Close()
+}
+
+func (s *S) createApp(c *check.C, name string, t *Template) *app.App {
+	a := app.App{Name: name, Platform: "python", TeamOwner: "myteam"}
+	t.ApplyDefaults(&a)
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	return &a
+}
+
+func (s *S) newEvent(c *check.C, appName string) *event.Event {
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeApp, Value: appName},
+		InternalKind: "template-test",
+		Allowed:      event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *S) TestCreateAndGet(c *check.C) {
+	t := Template{Name: "web", Plan: "large", Pool: "p2", Teams: []string{"ops"}, Env: map[string]string{"LOG_LEVEL": "info"}}
+	err := Create(context.TODO(), t)
+	c.Assert(err, check.IsNil)
+	stored, err := Get("web")
+	c.Assert(err, check.IsNil)
+	c.Assert(*stored, check.DeepEquals, t)
+	err = Create(context.TODO(), t)
+	c.Assert(err, check.Equals, ErrTemplateAlreadyExists)
+	templates, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(templates, check.HasLen, 1)
+}
+
+func (s *S) TestCreateValidation(c *check.C) {
+	tests := []struct {
+		template Template
+		message  string
+	}{
+		{Template{}, "app template name is required"},
+		{Template{Name: "web", Pool: "nopool"}, `pool "nopool" not found`},
+		{Template{Name: "web", Plan: "huge"}, `plan "huge" not found`},
+		{Template{Name: "web", Teams: []string{"nobody"}}, `team "nobody" not found`},
+		{Template{Name: "web", AutoScale: []provision.AutoScaleSpec{{MinUnits: 1, MaxUnits: 2}}}, "autoscale process is required"},
+		{Template{Name: "web", AutoScale: []provision.AutoScaleSpec{{Process: "web", MinUnits: 3, MaxUnits: 2}}}, `autoscale max units must not be lower than min units for process "web"`},
+	}
+	for _, tt := range tests {
+		err := Create(context.TODO(), tt.template)
+		c.Assert(err, check.DeepEquals, &tsuruErrors.ValidationError{Message: tt.message})
+	}
+}
+
+func (s *S) TestUpdateNotFound(c *check.C) {
+	err := Update(context.TODO(), Template{Name: "web"})
+	c.Assert(err, check.Equals, ErrTemplateNotFound)
+}
+
+func (s *S) TestRemove(c *check.C) {
+	t := Template{Name: "web"}
+	err := Create(context.TODO(), t)
+	c.Assert(err, check.IsNil)
+	s.createApp(c, "myapp", &t)
+	err = Remove("web")
+	c.Assert(err, check.Equals, ErrTemplateInUse)
+	err = Create(context.TODO(), Template{Name: "worker"})
+	c.Assert(err, check.IsNil)
+	err = Remove("worker")
+	c.Assert(err, check.IsNil)
+	_, err = Get("worker")
+	c.Assert(err, check.Equals, ErrTemplateNotFound)
+	err = Remove("worker")
+	c.Assert(err, check.Equals, ErrTemplateNotFound)
+}
+
+func (s *S) TestApplyDefaults(c *check.C) {
+	t := Template{Name: "web", Plan: "large", Pool: "p2", Tags: []string{"team:web", "tier:front"}}
+	a := app.App{Name: "myapp", Pool: "p1", Tags: []string{"tier:front", "custom"}}
+	t.ApplyDefaults(&a)
+	c.Assert(a.Template, check.Equals, "web")
+	c.Assert(a.Plan.Name, check.Equals, "large")
+	c.Assert(a.Pool, check.Equals, "p1")
+	c.Assert(a.Tags, check.DeepEquals, []string{"tier:front", "custom", "team:web"})
+}
+
+func (s *S) TestSync(c *check.C) {
+	t := Template{Name: "web", Plan: "large", Teams: []string{"ops"}, Tags: []string{"tier:front"}, Env: map[string]string{"LOG_LEVEL": "info"}}
+	err := Create(context.TODO(), t)
+	c.Assert(err, check.IsNil)
+	a := s.createApp(c, "myapp", &t)
+	evt := s.newEvent(c, a.Name)
+	changes, err := t.Sync(context.TODO(), a, evt)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []manifest.Change{{Kind: manifest.ChangeEnvSet, Names: []string{"LOG_LEVEL"}}})
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Template, check.Equals, "web")
+	c.Assert(a.Plan.Name, check.Equals, "large")
+	c.Assert(a.Env["LOG_LEVEL"].Value, check.Equals, "info")
+	c.Assert(a.Teams, check.DeepEquals, []string{"myteam", "ops"})
+	t.Pool = "p2"
+	t.Env["LOG_LEVEL"] = "debug"
+	err = Update(context.TODO(), t)
+	c.Assert(err, check.IsNil)
+	changes, err = t.Preview(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.DeepEquals, []manifest.Change{
+		{Kind: manifest.ChangeUpdate, Fields: []string{"pool"}},
+		{Kind: manifest.ChangeEnvSet, Names: []string{"LOG_LEVEL"}},
+	})
+	_, err = t.Sync(context.TODO(), a, evt)
+	c.Assert(err, check.IsNil)
+	a, err = app.GetByName(context.TODO(), "myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Pool, check.Equals, "p2")
+	c.Assert(a.Env["LOG_LEVEL"].Value, check.Equals, "debug")
+	changes, err = t.Preview(a)
+	c.Assert(err, check.IsNil)
+	c.Assert(changes, check.HasLen, 0)
+}
+
+func (s *S) TestApps(c *check.C) {
+	t := Template{Name: "web"}
+	s.createApp(c, "app2", &t)
+	s.createApp(c, "app1", &t)
+	s.createApp(c, "other", &Template{Name: "worker"})
+	names, err := Apps("web")
+	c.Assert(err, check.IsNil)
+	c.Assert(names, check.DeepEquals, []string{"app1", "app2"})
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/pkg/errors"
 	provTypes "github.com/tsuru/tsuru/types/provision"
+	routerTypes "github.com/tsuru/tsuru/types/router"
 )
 
 var (
@@ -19,9 +20,26 @@ var (
 )
 
 type customData struct {
-	Hooks       *provTypes.TsuruYamlHooks
-	Healthcheck *provTypes.TsuruYamlHealthcheck
-	Kubernetes  *tsuruYamlKubernetesConfig
+	Hooks             *provTypes.TsuruYamlHooks
+	Healthcheck       *provTypes.TsuruYamlHealthcheck
+	Readiness         *provTypes.TsuruYamlHealthcheck
+	Liveness          *provTypes.TsuruYamlHealthcheck
+	Kubernetes        *tsuruYamlKubernetesConfig
+	RouterPolicy      *tsuruYamlRouterPolicy           `json:"router_policy"`
+	ExposedPorts      []provTypes.TsuruYamlExposedPort `json:"exposed_ports"`
+	Ports             map[string][]provTypes.TsuruYamlProcessPort
+	RoutableProcesses []provTypes.TsuruYamlRoutableProcess `json:"routable_processes"`
+	Startup           map[string]provTypes.TsuruYamlStartup
+}
+
+type tsuruYamlRouterPolicy struct {
+	RateLimit *struct {
+		RequestsPerSecond int `json:"requests_per_second"`
+		Burst             int `json:"burst"`
+	} `json:"rate_limit"`
+	AllowCIDRs  []string `json:"allow_cidrs"`
+	DenyCIDRs   []string `json:"deny_cidrs"`
+	MaxBodySize int64    `json:"max_body_size"`
 }
 
 type tsuruYamlKubernetesConfig struct {
@@ -60,8 +78,28 @@ func unmarshalYamlData(data map[string]interface{}) (provTypes.TsuruYamlData, er
 	}
 
 	result := provTypes.TsuruYamlData{
-		Hooks:       custom.Hooks,
-		Healthcheck: custom.Healthcheck,
+		Hooks:        custom.Hooks,
+		Healthcheck:  custom.Healthcheck,
+		Readiness:    custom.Readiness,
+		Liveness:     custom.Liveness,
+		ExposedPorts: custom.ExposedPorts,
+		Ports:        custom.Ports,
+
+		RoutableProcesses: custom.RoutableProcesses,
+		Startup:           custom.Startup,
+	}
+	if policy := custom.RouterPolicy; policy != nil {
+		result.RouterPolicy = &routerTypes.Policy{
+			AllowCIDRs:  policy.AllowCIDRs,
+			DenyCIDRs:   policy.DenyCIDRs,
+			MaxBodySize: policy.MaxBodySize,
+		}
+		if policy.RateLimit != nil {
+			result.RouterPolicy.RateLimit = &routerTypes.RateLimit{
+				RequestsPerSecond: policy.RateLimit.RequestsPerSecond,
+				Burst:             policy.RateLimit.Burst,
+			}
+		}
 	}
 	if custom.Kubernetes == nil {
 		return result, nil
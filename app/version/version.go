@@ -149,6 +149,9 @@ func (v *appVersionImpl) AddData(args appTypes.AddVersionDataArgs) error {
 	if args.ExposedPorts != nil {
 		v.versionInfo.ExposedPorts = args.ExposedPorts
 	}
+	if args.Architectures != nil {
+		v.versionInfo.Architectures = args.Architectures
+	}
 	return v.storage.UpdateVersion(v.ctx, v.app.GetName(), v.versionInfo)
 }
 
@@ -10,6 +10,7 @@ import (
 	"github.com/tsuru/config"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	provTypes "github.com/tsuru/tsuru/types/provision"
+	routerTypes "github.com/tsuru/tsuru/types/router"
 	"gopkg.in/check.v1"
 )
 
@@ -93,6 +94,14 @@ func (s *S) TestAppVersionImpl_AddData(c *check.C) {
 			expectedProcesses: map[string][]string{},
 			expectedPorts:     []string{"80/tcp", "22/tcp"},
 		},
+		{
+			name: "only architectures",
+			addData: appTypes.AddVersionDataArgs{
+				Architectures: []string{"amd64", "arm64"},
+			},
+			expectedProcesses: map[string][]string{},
+			expectedPorts:     []string{},
+		},
 		{
 			name: "explicit processes",
 			addData: appTypes.AddVersionDataArgs{
@@ -166,6 +175,129 @@ func (s *S) TestAppVersionImpl_AddData(c *check.C) {
 			expectedProcesses: map[string][]string{},
 			expectedPorts:     []string{},
 		},
+		{
+			name: "parse router policy",
+			addData: appTypes.AddVersionDataArgs{
+				CustomData: map[string]interface{}{
+					"router_policy": map[string]interface{}{
+						"rate_limit": map[string]interface{}{
+							"requests_per_second": 10,
+							"burst":               20,
+						},
+						"deny_cidrs":    []string{"10.0.0.0/8"},
+						"max_body_size": 1048576,
+					},
+				},
+			},
+			expectedProcesses: map[string][]string{},
+			expectedPorts:     []string{},
+			expectedYamlData: provTypes.TsuruYamlData{
+				RouterPolicy: &routerTypes.Policy{
+					RateLimit:   &routerTypes.RateLimit{RequestsPerSecond: 10, Burst: 20},
+					DenyCIDRs:   []string{"10.0.0.0/8"},
+					MaxBodySize: 1048576,
+				},
+			},
+		},
+		{
+			name: "parse exposed ports",
+			addData: appTypes.AddVersionDataArgs{
+				CustomData: map[string]interface{}{
+					"exposed_ports": []map[string]interface{}{
+						{"process": "db", "port": 5432},
+						{"process": "dns", "port": 53, "protocol": "udp"},
+					},
+				},
+			},
+			expectedProcesses: map[string][]string{},
+			expectedPorts:     []string{},
+			expectedYamlData: provTypes.TsuruYamlData{
+				ExposedPorts: []provTypes.TsuruYamlExposedPort{
+					{Process: "db", Port: 5432},
+					{Process: "dns", Port: 53, Protocol: "udp"},
+				},
+			},
+		},
+		{
+			name: "parse process ports",
+			addData: appTypes.AddVersionDataArgs{
+				CustomData: map[string]interface{}{
+					"ports": map[string]interface{}{
+						"web": []map[string]interface{}{
+							{"name": "http", "port": 8888},
+							{"name": "grpc", "port": 9000},
+						},
+					},
+				},
+			},
+			expectedProcesses: map[string][]string{},
+			expectedPorts:     []string{},
+			expectedYamlData: provTypes.TsuruYamlData{
+				Ports: map[string][]provTypes.TsuruYamlProcessPort{
+					"web": {{Name: "http", Port: 8888}, {Name: "grpc", Port: 9000}},
+				},
+			},
+		},
+		{
+			name: "parse routable processes",
+			addData: appTypes.AddVersionDataArgs{
+				CustomData: map[string]interface{}{
+					"routable_processes": []map[string]interface{}{
+						{"process": "api", "hostnames": []string{"api.example.com"}, "port": 9000},
+						{"process": "admin"},
+					},
+				},
+			},
+			expectedProcesses: map[string][]string{},
+			expectedPorts:     []string{},
+			expectedYamlData: provTypes.TsuruYamlData{
+				RoutableProcesses: []provTypes.TsuruYamlRoutableProcess{
+					{Process: "api", Hostnames: []string{"api.example.com"}, Port: 9000},
+					{Process: "admin"},
+				},
+			},
+		},
+		{
+			name: "parse readiness and liveness",
+			addData: appTypes.AddVersionDataArgs{
+				CustomData: map[string]interface{}{
+					"readiness": map[string]interface{}{
+						"path":             "/ready",
+						"allowed_failures": 5,
+						"interval_seconds": 2,
+					},
+					"liveness": map[string]interface{}{
+						"path": "/alive",
+					},
+				},
+			},
+			expectedProcesses: map[string][]string{},
+			expectedPorts:     []string{},
+			expectedYamlData: provTypes.TsuruYamlData{
+				Readiness: &provTypes.TsuruYamlHealthcheck{Path: "/ready", AllowedFailures: 5, IntervalSeconds: 2},
+				Liveness:  &provTypes.TsuruYamlHealthcheck{Path: "/alive"},
+			},
+		},
+		{
+			name: "parse startup",
+			addData: appTypes.AddVersionDataArgs{
+				CustomData: map[string]interface{}{
+					"startup": map[string]interface{}{
+						"web": map[string]interface{}{
+							"grace_period_seconds": 30,
+							"max_boot_seconds":     300,
+						},
+					},
+				},
+			},
+			expectedProcesses: map[string][]string{},
+			expectedPorts:     []string{},
+			expectedYamlData: provTypes.TsuruYamlData{
+				Startup: map[string]provTypes.TsuruYamlStartup{
+					"web": {GracePeriodSeconds: 30, MaxBootSeconds: 300},
+				},
+			},
+		},
 		{
 			name: "parse and recover hooks and complex kubernetes",
 			addData: appTypes.AddVersionDataArgs{
@@ -258,6 +390,7 @@ func (s *S) TestAppVersionImpl_AddData(c *check.C) {
 			c.Assert(err, check.IsNil)
 			c.Check(yamlData, check.DeepEquals, tt.expectedYamlData)
 			c.Check(version.VersionInfo().ExposedPorts, check.DeepEquals, tt.expectedPorts)
+			c.Check(version.VersionInfo().Architectures, check.DeepEquals, tt.addData.Architectures)
 			if tt.expectedRaw != nil {
 				c.Check(version.VersionInfo().CustomData, check.DeepEquals, tt.expectedRaw)
 			}
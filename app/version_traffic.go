@@ -0,0 +1,294 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/provision"
+)
+
+// VersionUnits summarizes the units running a version of the app. Routers
+// balance requests between the units of every routable version, so Weight,
+// the percentage of the traffic received by the version, is its share of the
+// routable units.
+type VersionUnits struct {
+	Version  int            `json:"version"`
+	Routable bool           `json:"routable"`
+	Units    map[string]int `json:"units"`
+	Weight   int            `json:"weight"`
+}
+
+// VersionUnits returns the versions of the app with deployed or running
+// units, sorted by version.
+func (app *App) VersionUnits(ctx context.Context) ([]VersionUnits, error) {
+	units, err := app.Units()
+	if err != nil {
+		return nil, err
+	}
+	deployed, err := app.DeployedVersions()
+	if err != nil && err != ErrNoVersionProvisioner {
+		return nil, err
+	}
+	return versionUnits(units, deployed), nil
+}
+
+func versionUnits(units []provision.Unit, deployed []int) []VersionUnits {
+	byVersion := map[int]*VersionUnits{}
+	get := func(version int) *VersionUnits {
+		v := byVersion[version]
+		if v == nil {
+			v = &VersionUnits{Version: version, Units: map[string]int{}}
+			byVersion[version] = v
+		}
+		return v
+	}
+	for _, version := range deployed {
+		get(version)
+	}
+	routableUnits := map[int]int{}
+	totalRoutable := 0
+	for _, u := range units {
+		v := get(u.Version)
+		v.Units[u.ProcessName]++
+		if u.Routable {
+			v.Routable = true
+			routableUnits[u.Version]++
+			totalRoutable++
+		}
+	}
+	result := make([]VersionUnits, 0, len(byVersion))
+	for _, v := range byVersion {
+		if totalRoutable > 0 {
+			v.Weight = int(math.Round(float64(routableUnits[v.Version]) * 100 / float64(totalRoutable)))
+		}
+		result = append(result, *v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result
+}
+
+// planTrafficShift returns the units of each version of a process needed for
+// version to receive weight percent of the traffic, keeping the total of
+// units. current holds the units of the routable versions, the units left
+// are split between the other versions in proportion to their units.
+func planTrafficShift(current map[int]int, version, weight int) (map[int]int, error) {
+	if weight < 0 || weight > 100 {
+		return nil, &tsuruErrors.ValidationError{Message: "weight must be between 0 and 100"}
+	}
+	total := 0
+	var others []int
+	for v, n := range current {
+		total += n
+		if v != version && n > 0 {
+			others = append(others, v)
+		}
+	}
+	if total == 0 {
+		return nil, &tsuruErrors.ValidationError{Message: "process has no routable units"}
+	}
+	sort.Ints(others)
+	target := int(math.Round(float64(total) * float64(weight) / 100))
+	if weight > 0 && target == 0 {
+		target = 1
+	}
+	rest := total - target
+	if rest > 0 && len(others) == 0 {
+		return nil, &tsuruErrors.ValidationError{Message: "no other routable version to receive the remaining traffic"}
+	}
+	plan := map[int]int{version: target}
+	othersTotal := total - current[version]
+	assigned := 0
+	type remainder struct {
+		version int
+		value   float64
+	}
+	var remainders []remainder
+	for _, v := range others {
+		share := float64(rest) * float64(current[v]) / float64(othersTotal)
+		plan[v] = int(math.Floor(share))
+		assigned += plan[v]
+		remainders = append(remainders, remainder{version: v, value: share - math.Floor(share)})
+	}
+	sort.SliceStable(remainders, func(i, j int) bool {
+		return remainders[i].value > remainders[j].value
+	})
+	for i := 0; assigned < rest; i++ {
+		plan[remainders[i%len(remainders)].version]++
+		assigned++
+	}
+	return plan, nil
+}
+
+// ShiftTraffic scales the units of the routable versions of the process so
+// version receives weight percent of its traffic, an empty process shifts the
+// traffic of every process. Units are added before being removed, keeping the
+// capacity of the process during the shift.
+func (app *App) ShiftTraffic(ctx context.Context, w io.Writer, process string, version, weight int) error {
+	versionProv, v, err := app.explicitVersion(strconv.Itoa(version))
+	if err != nil {
+		return err
+	}
+	if versionProv == nil {
+		return ErrNoVersionProvisioner
+	}
+	units, err := app.Units()
+	if err != nil {
+		return err
+	}
+	currentByProcess := map[string]map[int]int{}
+	routable := false
+	for _, u := range units {
+		if process != "" && u.ProcessName != process {
+			continue
+		}
+		if u.Version != version && !u.Routable {
+			continue
+		}
+		current := currentByProcess[u.ProcessName]
+		if current == nil {
+			current = map[int]int{version: 0}
+			currentByProcess[u.ProcessName] = current
+		}
+		current[u.Version]++
+		if u.Version == version {
+			routable = routable || u.Routable
+		}
+	}
+	if process != "" && currentByProcess[process] == nil {
+		currentByProcess[process] = map[int]int{version: 0}
+	}
+	processes := make([]string, 0, len(currentByProcess))
+	for p := range currentByProcess {
+		processes = append(processes, p)
+	}
+	if len(processes) == 0 {
+		return &tsuruErrors.ValidationError{Message: "app has no routable units"}
+	}
+	sort.Strings(processes)
+	plans := map[string]map[int]int{}
+	needsRoutable := false
+	for _, p := range processes {
+		plan, err := planTrafficShift(currentByProcess[p], version, weight)
+		if err != nil {
+			return err
+		}
+		plans[p] = plan
+		needsRoutable = needsRoutable || plan[version] > 0
+	}
+	if !routable && needsRoutable {
+		fmt.Fprintf(app.withLogWriter(w), "---- Setting version %d as routable ----\n", version)
+		err = versionProv.ToggleRoutable(ctx, app, v, true)
+		if err != nil {
+			return err
+		}
+		err = app.stopShadowOf(version)
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range processes {
+		err = app.applyTrafficShift(ctx, w, p, currentByProcess[p], plans[p])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (app *App) applyTrafficShift(ctx context.Context, w io.Writer, process string, current, plan map[int]int) error {
+	versions := make([]int, 0, len(plan))
+	for planVersion := range plan {
+		versions = append(versions, planVersion)
+	}
+	sort.Ints(versions)
+	for _, planVersion := range versions {
+		if diff := plan[planVersion] - current[planVersion]; diff > 0 {
+			err := app.AddUnits(uint(diff), process, strconv.Itoa(planVersion), w)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, planVersion := range versions {
+		if diff := current[planVersion] - plan[planVersion]; diff > 0 {
+			err := app.RemoveUnits(ctx, uint(diff), process, strconv.Itoa(planVersion), w)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RetireVersion removes the units of the version, step units of each
+// process at a time waiting interval between the steps, and then destroys
+// the version. Another routable version must have units to receive the
+// traffic.
+func (app *App) RetireVersion(ctx context.Context, w io.Writer, version, step int, interval time.Duration) error {
+	if step <= 0 {
+		return &tsuruErrors.ValidationError{Message: "step must be greater than zero"}
+	}
+	versionProv, _, err := app.explicitVersion(strconv.Itoa(version))
+	if err != nil {
+		return err
+	}
+	if versionProv == nil {
+		return ErrNoVersionProvisioner
+	}
+	logWriter := app.withLogWriter(w)
+	for {
+		units, err := app.Units()
+		if err != nil {
+			return err
+		}
+		remaining := map[string]int{}
+		otherRoutable := false
+		for _, u := range units {
+			if u.Version == version {
+				remaining[u.ProcessName]++
+			} else if u.Routable {
+				otherRoutable = true
+			}
+		}
+		if len(remaining) == 0 {
+			return app.DeleteVersion(ctx, w, strconv.Itoa(version))
+		}
+		if !otherRoutable {
+			return errors.Errorf("version %d is the only routable version, shift its traffic to another version first", version)
+		}
+		processes := make([]string, 0, len(remaining))
+		for process := range remaining {
+			processes = append(processes, process)
+		}
+		sort.Strings(processes)
+		for _, process := range processes {
+			n := step
+			if remaining[process] < n {
+				n = remaining[process]
+			}
+			fmt.Fprintf(logWriter, "---- Retiring %d units of process %q of version %d ----\n", n, process, version)
+			err = app.RemoveUnits(ctx, uint(n), process, strconv.Itoa(version), w)
+			if err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
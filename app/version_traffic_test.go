@@ -0,0 +1,52 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/tsuru/tsuru/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestVersionUnits(c *check.C) {
+	units := []provision.Unit{
+		{ID: "u1", ProcessName: "web", Version: 1, Routable: true},
+		{ID: "u2", ProcessName: "web", Version: 1, Routable: true},
+		{ID: "u3", ProcessName: "worker", Version: 1, Routable: true},
+		{ID: "u4", ProcessName: "web", Version: 2, Routable: true},
+		{ID: "u5", ProcessName: "web", Version: 3},
+	}
+	result := versionUnits(units, []int{1, 2, 3, 4})
+	c.Assert(result, check.DeepEquals, []VersionUnits{
+		{Version: 1, Routable: true, Units: map[string]int{"web": 2, "worker": 1}, Weight: 75},
+		{Version: 2, Routable: true, Units: map[string]int{"web": 1}, Weight: 25},
+		{Version: 3, Units: map[string]int{"web": 1}},
+		{Version: 4, Units: map[string]int{}},
+	})
+	c.Assert(versionUnits(nil, nil), check.HasLen, 0)
+}
+
+func (s *S) TestPlanTrafficShift(c *check.C) {
+	plan, err := planTrafficShift(map[int]int{1: 4, 2: 0}, 2, 25)
+	c.Assert(err, check.IsNil)
+	c.Assert(plan, check.DeepEquals, map[int]int{1: 3, 2: 1})
+	plan, err = planTrafficShift(map[int]int{1: 4, 2: 0}, 2, 100)
+	c.Assert(err, check.IsNil)
+	c.Assert(plan, check.DeepEquals, map[int]int{1: 0, 2: 4})
+	plan, err = planTrafficShift(map[int]int{1: 2, 2: 1, 3: 1}, 3, 0)
+	c.Assert(err, check.IsNil)
+	c.Assert(plan, check.DeepEquals, map[int]int{1: 3, 2: 1, 3: 0})
+	plan, err = planTrafficShift(map[int]int{1: 10, 2: 0}, 2, 1)
+	c.Assert(err, check.IsNil)
+	c.Assert(plan, check.DeepEquals, map[int]int{1: 9, 2: 1})
+}
+
+func (s *S) TestPlanTrafficShiftInvalid(c *check.C) {
+	_, err := planTrafficShift(map[int]int{1: 2}, 1, 101)
+	c.Assert(err, check.ErrorMatches, "weight must be between 0 and 100")
+	_, err = planTrafficShift(map[int]int{1: 0}, 1, 50)
+	c.Assert(err, check.ErrorMatches, "process has no routable units")
+	_, err = planTrafficShift(map[int]int{1: 2}, 1, 50)
+	c.Assert(err, check.ErrorMatches, "no other routable version to receive the remaining traffic")
+}
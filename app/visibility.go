@@ -0,0 +1,109 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/router"
+	"github.com/tsuru/tsuru/router/rebuild"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+)
+
+// GetVisibility returns where the routers of the app expose it, apps are
+// public unless set otherwise.
+func (app *App) GetVisibility() string {
+	if app.Visibility == "" {
+		return routerTypes.VisibilityPublic
+	}
+	return app.Visibility
+}
+
+// SetVisibility changes where the routers of the app expose it, updating the
+// backends of the app in every router.
+func (app *App) SetVisibility(visibility string) error {
+	if visibility == "" {
+		visibility = routerTypes.VisibilityPublic
+	}
+	err := app.validateVisibility(visibility, app.GetRouters())
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"$set": bson.M{"visibility": visibility}}
+	if visibility == routerTypes.VisibilityPublic {
+		update = bson.M{"$unset": bson.M{"visibility": ""}}
+		visibility = ""
+	}
+	err = conn.Apps().Update(bson.M{"name": app.Name}, update)
+	if err != nil {
+		return err
+	}
+	app.Visibility = visibility
+	for _, appRouter := range app.GetRouters() {
+		r, err := router.Get(app.ctx, appRouter.Name)
+		if err != nil {
+			return err
+		}
+		// routers v2 ensure the backend with the app visibility in the
+		// routes rebuild below.
+		if _, ok := r.(router.RouterV2); ok {
+			continue
+		}
+		if optsRouter, ok := r.(router.OptsRouter); ok {
+			err = optsRouter.UpdateBackendOpts(app.ctx, app, appRouter.Opts)
+			if err != nil && err != router.ErrBackendNotFound {
+				return err
+			}
+		}
+	}
+	rebuild.RoutesRebuildOrEnqueue(app.Name)
+	return nil
+}
+
+// validateVisibility checks that the visibility is valid and supported by
+// every router in routers.
+func (app *App) validateVisibility(visibility string, routers []appTypes.AppRouter) error {
+	err := routerTypes.ValidateVisibility(visibility)
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: err.Error()}
+	}
+	if visibility == routerTypes.VisibilityPublic {
+		return nil
+	}
+	for _, appRouter := range routers {
+		supported, err := routerSupportsVisibility(app.ctx, appRouter.Name, visibility)
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("visibility %q is not supported by router %q", visibility, appRouter.Name),
+			}
+		}
+	}
+	return nil
+}
+
+func routerSupportsVisibility(ctx context.Context, routerName, visibility string) (bool, error) {
+	r, err := router.Get(ctx, routerName)
+	if err != nil {
+		return false, err
+	}
+	visibilityRouter, ok := r.(router.VisibilityRouter)
+	if !ok {
+		return false, nil
+	}
+	return visibilityRouter.SupportsVisibility(ctx, visibility)
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/router/routertest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	routerTypes "github.com/tsuru/tsuru/types/router"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestCreateAppWithVisibility(c *check.C) {
+	config.Set("routers:fake-visibility:type", "fake-visibility")
+	defer config.Unset("routers:fake-visibility:type")
+	defer routertest.VisibilityRouter.Reset()
+	app := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "fake-visibility", Visibility: routerTypes.VisibilityInternal}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.VisibilityRouter.Visibilities["myapp"], check.Equals, routerTypes.VisibilityInternal)
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.GetVisibility(), check.Equals, routerTypes.VisibilityInternal)
+	err = dbApp.AddRouter(appTypes.AppRouter{Name: "fake"})
+	c.Assert(err, check.ErrorMatches, `visibility "internal" is not supported by router "fake"`)
+}
+
+func (s *S) TestCreateAppWithVisibilityNotSupported(c *check.C) {
+	app := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Visibility: routerTypes.VisibilityNone}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.ErrorMatches, `visibility "none" is not supported by router "fake"`)
+	app = App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Visibility: "secret"}
+	err = CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.ErrorMatches, `invalid visibility "secret".*`)
+}
+
+func (s *S) TestSetVisibility(c *check.C) {
+	config.Set("routers:fake-visibility:type", "fake-visibility")
+	defer config.Unset("routers:fake-visibility:type")
+	defer routertest.VisibilityRouter.Reset()
+	app := App{Name: "myapp", Platform: "go", TeamOwner: s.team.Name, Router: "fake-visibility"}
+	err := CreateApp(context.TODO(), &app, s.user)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.VisibilityRouter.Visibilities["myapp"], check.Equals, routerTypes.VisibilityPublic)
+	err = app.SetVisibility(routerTypes.VisibilityNone)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.VisibilityRouter.Visibilities["myapp"], check.Equals, routerTypes.VisibilityNone)
+	dbApp, err := GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Visibility, check.Equals, routerTypes.VisibilityNone)
+	err = dbApp.SetVisibility(routerTypes.VisibilityPublic)
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.VisibilityRouter.Visibilities["myapp"], check.Equals, routerTypes.VisibilityPublic)
+	dbApp, err = GetByName(context.TODO(), app.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbApp.Visibility, check.Equals, "")
+}
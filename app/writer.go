@@ -5,6 +5,7 @@
 package app
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -17,6 +18,11 @@ import (
 // different test cases interacting.
 var TestLogWriterWaitOnClose = false
 
+var asyncWriters = struct {
+	sync.Mutex
+	writers map[*LogWriter]struct{}
+}{writers: map[*LogWriter]struct{}{}}
+
 type LogWriter struct {
 	AppName string
 	Source  string
@@ -29,8 +35,16 @@ type LogWriter struct {
 func (w *LogWriter) Async() {
 	w.msgCh = make(chan []byte, 1000)
 	w.doneCh = make(chan bool)
+	asyncWriters.Lock()
+	asyncWriters.writers[w] = struct{}{}
+	asyncWriters.Unlock()
 	go func() {
-		defer close(w.doneCh)
+		defer func() {
+			asyncWriters.Lock()
+			delete(asyncWriters.writers, w)
+			asyncWriters.Unlock()
+			close(w.doneCh)
+		}()
 		for msg := range w.msgCh {
 			err := w.write(msg)
 			if err != nil {
@@ -43,6 +57,9 @@ func (w *LogWriter) Async() {
 func (w *LogWriter) Close() {
 	w.finLk.Lock()
 	defer w.finLk.Unlock()
+	if w.closed {
+		return
+	}
 	w.closed = true
 	if w.msgCh != nil {
 		close(w.msgCh)
@@ -64,6 +81,28 @@ func (w *LogWriter) Wait(timeout time.Duration) error {
 	return nil
 }
 
+// FlushLogWriters closes the async log writers still open, waiting until
+// their pending messages are written or ctx is done.
+func FlushLogWriters(ctx context.Context) error {
+	asyncWriters.Lock()
+	writers := make([]*LogWriter, 0, len(asyncWriters.writers))
+	for w := range asyncWriters.writers {
+		writers = append(writers, w)
+	}
+	asyncWriters.Unlock()
+	for _, w := range writers {
+		w.Close()
+	}
+	for _, w := range writers {
+		select {
+		case <-w.doneCh:
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "timeout flushing log writers")
+		}
+	}
+	return nil
+}
+
 // Write writes and logs the data.
 func (w *LogWriter) Write(data []byte) (int, error) {
 	w.finLk.RLock()
@@ -202,3 +202,25 @@ func (s *S) TestLogWriterWriteClosed(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(logs, check.HasLen, 0)
 }
+
+func (s *S) TestFlushLogWriters(c *check.C) {
+	a := App{Name: "down"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	writer := LogWriter{AppName: a.Name}
+	writer.Async()
+	_, err = writer.Write([]byte("flushed"))
+	c.Assert(err, check.IsNil)
+	err = FlushLogWriters(context.Background())
+	c.Assert(err, check.IsNil)
+	logs, err := a.LastLogs(context.TODO(), servicemanager.AppLog, appTypes.ListLogArgs{
+		Limit: 1,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(logs, check.HasLen, 1)
+	c.Assert(logs[0].Message, check.Equals, "flushed")
+	_, err = writer.Write([]byte("dropped"))
+	c.Assert(err, check.IsNil)
+	writer.Close()
+}
@@ -219,6 +219,9 @@ func buildInstanceRequests(ctx context.Context, args appTypes.ListLogArgs, follo
 			urlValues.Add("unit", u)
 		}
 		urlValues.Add("invert-source", strconv.FormatBool(args.InvertSource))
+		if args.RequestID != "" {
+			urlValues.Add("request-id", args.RequestID)
+		}
 		if follow {
 			urlValues.Add("follow", "1")
 		}
@@ -66,13 +66,17 @@ var (
 
 type memoryLogService struct {
 	bufferMap sync.Map
+	requestID *requestIDMatcher
 }
 
 func memoryAppLogService() (appTypes.AppLogService, error) {
-	return &memoryLogService{}, nil
+	return &memoryLogService{requestID: newRequestIDMatcher()}, nil
 }
 
 func (s *memoryLogService) Enqueue(entry *appTypes.Applog) error {
+	if entry.RequestID == "" {
+		entry.RequestID = s.requestID.fromMessage(entry.Message)
+	}
 	buffer := s.getAppBuffer(entry.AppName)
 	buffer.add(entry)
 	return nil
@@ -193,7 +197,8 @@ func (b *appLogBuffer) list(args appTypes.ListLogArgs) []appTypes.Applog {
 	unitsSet := set.FromSlice(args.Units)
 	for current := b.end; count < args.Limit; {
 		if (args.Source == "" || (args.Source == current.log.Source) != args.InvertSource) &&
-			(len(args.Units) == 0 || unitsSet.Includes(current.log.Unit)) {
+			(len(args.Units) == 0 || unitsSet.Includes(current.log.Unit)) &&
+			(args.RequestID == "" || args.RequestID == current.log.RequestID) {
 
 			logs[len(logs)-count-1] = *current.log
 			count++
@@ -269,6 +274,7 @@ func entrySize(entry *appTypes.Applog) uint {
 		len(entry.MongoID) +
 		len(entry.Source) +
 		len(entry.Unit) +
+		len(entry.RequestID) +
 		int(baseLogSize))
 }
 
@@ -289,6 +295,9 @@ func (w *memoryWatcher) notify(entry *appTypes.Applog, dropCounter prometheus.Co
 	if len(w.filter.Units) > 0 && !w.unitsSet.Includes(entry.Unit) {
 		return
 	}
+	if w.filter.RequestID != "" && w.filter.RequestID != entry.RequestID {
+		return
+	}
 	select {
 	case w.ch <- *entry:
 	default:
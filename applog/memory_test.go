@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/tsuru/config"
 	appTypes "github.com/tsuru/tsuru/types/app"
 	"gopkg.in/check.v1"
 )
@@ -80,3 +81,20 @@ func (s *S) Test_MemoryLogService_MessagExactLimit(c *check.C) {
 		{Message: newMessage, AppName: "myapp", Source: "tsuru", Unit: "avranakern2"},
 	})
 }
+
+func (s *S) Test_MemoryLogService_ListByRequestID(c *check.C) {
+	config.Set("log:request-id-pattern", `request_id=([0-9a-f-]+)`)
+	defer config.Unset("log:request-id-pattern")
+	svc := memoryLogService{requestID: newRequestIDMatcher()}
+	err := svc.Add("myapp", "GET /a request_id=abc-123\nGET /b request_id=def-456\nno id here", "app", "u1")
+	c.Assert(err, check.IsNil)
+	msgs, err := svc.List(context.TODO(), appTypes.ListLogArgs{AppName: "myapp", RequestID: "abc-123"})
+	c.Assert(err, check.IsNil)
+	compareLogsNoDate(c, msgs, []appTypes.Applog{
+		{Message: "GET /a request_id=abc-123", AppName: "myapp", Source: "app", Unit: "u1", RequestID: "abc-123"},
+	})
+	msgs, err = svc.List(context.TODO(), appTypes.ListLogArgs{AppName: "myapp"})
+	c.Assert(err, check.IsNil)
+	c.Assert(msgs, check.HasLen, 3)
+	c.Assert(msgs[2].RequestID, check.Equals, "")
+}
@@ -26,12 +26,14 @@ var (
 type provisionerWrapper struct {
 	logService        appTypes.AppLogService
 	provisionerGetter logsProvisionerGetter
+	requestID         *requestIDMatcher
 }
 
 func newProvisionerWrapper(logService appTypes.AppLogService) appTypes.AppLogService {
 	return &provisionerWrapper{
 		logService:        logService,
 		provisionerGetter: defaultLogsProvisionerGetter,
+		requestID:         newRequestIDMatcher(),
 	}
 }
 
@@ -62,17 +64,29 @@ func (k *provisionerWrapper) List(ctx context.Context, args appTypes.ListLogArgs
 		return nil, err
 	}
 
-	logs, err := logsProvisioner.ListLogs(ctx, a, args)
+	provisionerArgs := args
+	if args.RequestID != "" {
+		// the provisioner doesn't know about request ids, so the filter must
+		// run over a larger window before the line limit is applied.
+		provisionerArgs.Limit = requestIDScanLines(args.Limit)
+	}
+	logs, err := logsProvisioner.ListLogs(ctx, a, provisionerArgs)
 	if err == provision.ErrLogsUnavailable {
 		return tsuruLogs, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	if args.RequestID != "" {
+		logs = k.filterByRequestID(logs, args.RequestID)
+	}
 	logs = append(logs, tsuruLogs...)
 	sort.SliceStable(logs, func(i, j int) bool {
 		return logs[i].Date.Before(logs[j].Date)
 	})
+	if args.RequestID != "" && args.Limit > 0 && len(logs) > args.Limit {
+		logs = logs[len(logs)-args.Limit:]
+	}
 	return logs, err
 }
 
@@ -190,3 +204,18 @@ func (m *multiWatcher) Close() {
 	m.wg.Wait()
 	close(m.ch)
 }
+
+// filterByRequestID keeps the provisioner logs with the request id, which
+// are not indexed by tsuru.
+func (k *provisionerWrapper) filterByRequestID(logs []appTypes.Applog, requestID string) []appTypes.Applog {
+	var filtered []appTypes.Applog
+	for _, l := range logs {
+		if l.RequestID == "" {
+			l.RequestID = k.requestID.fromMessage(l.Message)
+		}
+		if l.RequestID == requestID {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
+}
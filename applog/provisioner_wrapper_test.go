@@ -6,9 +6,11 @@ package applog
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"time"
 
+	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/provisiontest"
 	"github.com/tsuru/tsuru/servicemanager"
@@ -62,6 +64,59 @@ func (s *ProvisionerWrapperSuite) Test_List(c *check.C) {
 	c.Check(logs[1].Message, check.Equals, "Fake message from tsuru logs")
 }
 
+type scanLogsProvisioner struct {
+	logs  []appTypes.Applog
+	limit int
+}
+
+func (p *scanLogsProvisioner) ListLogs(ctx context.Context, app appTypes.App, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
+	p.limit = args.Limit
+	logs := p.logs
+	if args.Limit > 0 && len(logs) > args.Limit {
+		logs = logs[len(logs)-args.Limit:]
+	}
+	return logs, nil
+}
+
+func (p *scanLogsProvisioner) WatchLogs(ctx context.Context, app appTypes.App, args appTypes.ListLogArgs) (appTypes.LogWatcher, error) {
+	return nil, provision.ErrLogsUnavailable
+}
+
+func (s *ProvisionerWrapperSuite) Test_ListByRequestIDBeforeLimit(c *check.C) {
+	config.Set("log:request-id-pattern", `request_id=([0-9a-f-]+)`)
+	defer config.Unset("log:request-id-pattern")
+	config.Set("log:request-id-scan-lines", 50)
+	defer config.Unset("log:request-id-scan-lines")
+	now := time.Now()
+	provisioner := &scanLogsProvisioner{}
+	for i := 0; i < 20; i++ {
+		msg := "noise"
+		if i < 3 {
+			msg = fmt.Sprintf("line %d request_id=abc-123", i)
+		}
+		provisioner.logs = append(provisioner.logs, appTypes.Applog{Message: msg, Date: now.Add(time.Duration(i) * time.Second)})
+	}
+	memoryService, err := memoryAppLogService()
+	c.Assert(err, check.IsNil)
+	pw := &provisionerWrapper{
+		logService: memoryService,
+		provisionerGetter: func(ctx context.Context, a appTypes.App) (provision.LogsProvisioner, error) {
+			return provisioner, nil
+		},
+		requestID: newRequestIDMatcher(),
+	}
+	logs, err := pw.List(context.TODO(), appTypes.ListLogArgs{
+		AppName:   "myapp",
+		RequestID: "abc-123",
+		Limit:     2,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(provisioner.limit, check.Equals, 50)
+	c.Assert(logs, check.HasLen, 2)
+	c.Check(logs[0].Message, check.Equals, "line 1 request_id=abc-123")
+	c.Check(logs[1].Message, check.Equals, "line 2 request_id=abc-123")
+}
+
 func (s *ProvisionerWrapperSuite) Test_Watch(c *check.C) {
 	watcher, err := s.provisionerWrapper.Watch(context.TODO(), appTypes.ListLogArgs{
 		AppName: "myapp",
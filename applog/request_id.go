@@ -0,0 +1,64 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package applog
+
+import (
+	"regexp"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/log"
+)
+
+const defaultRequestIDScanLines = 10000
+
+// requestIDMatcher extracts request ids from log messages. It's built once
+// when the log service is created, as it runs for every log line.
+type requestIDMatcher struct {
+	re *regexp.Regexp
+}
+
+// newRequestIDMatcher compiles the regular expression set in
+// log:request-id-pattern. An invalid pattern is logged and disables the
+// extraction.
+func newRequestIDMatcher() *requestIDMatcher {
+	pattern, _ := config.GetString("log:request-id-pattern")
+	if pattern == "" {
+		return &requestIDMatcher{}
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		log.Errorf("[log] invalid log:request-id-pattern %q, request ids won't be extracted: %v", pattern, err)
+		return &requestIDMatcher{}
+	}
+	return &requestIDMatcher{re: re}
+}
+
+// fromMessage extracts the request id from a log message using the first
+// capturing group of the pattern. It returns an empty string when the pattern
+// isn't set or doesn't match.
+func (m *requestIDMatcher) fromMessage(message string) string {
+	if m == nil || m.re == nil {
+		return ""
+	}
+	matches := m.re.FindStringSubmatch(message)
+	if len(matches) < 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// requestIDScanLines returns how many provisioner log lines are scanned when
+// filtering by request id, as set in log:request-id-scan-lines. It's never
+// less than the requested limit.
+func requestIDScanLines(limit int) int {
+	lines, err := config.GetInt("log:request-id-scan-lines")
+	if err != nil || lines <= 0 {
+		lines = defaultRequestIDScanLines
+	}
+	if lines < limit {
+		return limit
+	}
+	return lines
+}
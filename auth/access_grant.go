@@ -0,0 +1,179 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const defaultAccessGrantMaxDuration = 24 * time.Hour
+
+var ErrAccessGrantNotFound = errors.New("access grant not found")
+
+// AccessGrant is a temporary assignment of a role to a user, used for
+// break-glass access during incidents. The role is granted in addition to the
+// roles of the user until ExpiresAt, and every event started by the user
+// while the grant is active is flagged with its ID.
+type AccessGrant struct {
+	ID           bson.ObjectId `bson:"_id" json:"id"`
+	User         string        `json:"user"`
+	Role         string        `json:"role"`
+	ContextValue string        `json:"contextValue"`
+	Reason       string        `json:"reason"`
+	GrantedBy    string        `json:"grantedBy"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	ExpiresAt    time.Time     `json:"expiresAt"`
+	RevokedBy    string        `json:"revokedBy,omitempty"`
+}
+
+func accessGrantsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection("access_grants")
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"user", "expiresat"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func accessGrantMaxDuration() time.Duration {
+	maxDuration, _ := config.GetDuration("access-grants:max-duration")
+	if maxDuration <= 0 {
+		return defaultAccessGrantMaxDuration
+	}
+	return maxDuration
+}
+
+// GrantAccess assigns the role to the user for the duration, up to
+// access-grants:max-duration.
+func GrantAccess(g *AccessGrant, duration time.Duration) error {
+	if g.Reason == "" {
+		return &tsuruErrors.ValidationError{Message: "reason is required"}
+	}
+	if maxDuration := accessGrantMaxDuration(); duration <= 0 || duration > maxDuration {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("duration must be between 0 and %s", maxDuration)}
+	}
+	_, err := GetUserByEmail(g.User)
+	if err != nil {
+		if err == authTypes.ErrUserNotFound {
+			return &tsuruErrors.ValidationError{Message: err.Error()}
+		}
+		return err
+	}
+	role, err := permission.FindRole(g.Role)
+	if err != nil {
+		if err == permTypes.ErrRoleNotFound {
+			return &tsuruErrors.ValidationError{Message: err.Error()}
+		}
+		return err
+	}
+	if role.ContextType == permTypes.CtxGlobal {
+		g.ContextValue = ""
+	} else if g.ContextValue == "" {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("a context value is required for roles with the %s context", role.ContextType)}
+	}
+	coll, err := accessGrantsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	g.ID = bson.NewObjectId()
+	g.CreatedAt = time.Now().UTC()
+	g.ExpiresAt = g.CreatedAt.Add(duration)
+	g.RevokedBy = ""
+	return coll.Insert(g)
+}
+
+// ListActiveAccessGrants returns the grants not yet expired, sorted by
+// expiration.
+func ListActiveAccessGrants() ([]AccessGrant, error) {
+	return findAccessGrants(bson.M{"expiresat": bson.M{"$gt": time.Now().UTC()}})
+}
+
+// ActiveAccessGrants returns the grants of the user not yet expired.
+func ActiveAccessGrants(email string) ([]AccessGrant, error) {
+	return findAccessGrants(bson.M{"user": email, "expiresat": bson.M{"$gt": time.Now().UTC()}})
+}
+
+func findAccessGrants(query bson.M) ([]AccessGrant, error) {
+	coll, err := accessGrantsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var grants []AccessGrant
+	err = coll.Find(query).Sort("expiresat").All(&grants)
+	return grants, err
+}
+
+func FindAccessGrant(id string) (*AccessGrant, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, ErrAccessGrantNotFound
+	}
+	coll, err := accessGrantsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var g AccessGrant
+	err = coll.FindId(bson.ObjectIdHex(id)).One(&g)
+	if err == mgo.ErrNotFound {
+		return nil, ErrAccessGrantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// RevokeAccessGrant expires an active grant immediately, the grant is kept
+// for auditing.
+func RevokeAccessGrant(id, revokedBy string) error {
+	if !bson.IsObjectIdHex(id) {
+		return ErrAccessGrantNotFound
+	}
+	coll, err := accessGrantsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	now := time.Now().UTC()
+	err = coll.Update(
+		bson.M{"_id": bson.ObjectIdHex(id), "expiresat": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"expiresat": now, "revokedby": revokedBy}},
+	)
+	if err == mgo.ErrNotFound {
+		return ErrAccessGrantNotFound
+	}
+	return err
+}
+
+func accessGrantRoles(email string) ([]authTypes.RoleInstance, error) {
+	grants, err := ActiveAccessGrants(email)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]authTypes.RoleInstance, len(grants))
+	for i, g := range grants {
+		roles[i] = authTypes.RoleInstance{Name: g.Role, ContextValue: g.ContextValue}
+	}
+	return roles, nil
+}
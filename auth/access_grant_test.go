@@ -0,0 +1,87 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"time"
+
+	"github.com/tsuru/config"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestGrantAccess(c *check.C) {
+	role, err := permission.NewRole("app-admin", "app", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions("app.update")
+	c.Assert(err, check.IsNil)
+	perms, err := s.user.Permissions()
+	c.Assert(err, check.IsNil)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppUpdate, permission.Context(permTypes.CtxApp, "myapp")), check.Equals, false)
+	grant := AccessGrant{User: s.user.Email, Role: "app-admin", ContextValue: "myapp", Reason: "incident", GrantedBy: "admin@example.com"}
+	err = GrantAccess(&grant, 2*time.Hour)
+	c.Assert(err, check.IsNil)
+	c.Assert(grant.ID.Valid(), check.Equals, true)
+	c.Assert(grant.ExpiresAt.Sub(grant.CreatedAt), check.Equals, 2*time.Hour)
+	perms, err = s.user.Permissions()
+	c.Assert(err, check.IsNil)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppUpdate, permission.Context(permTypes.CtxApp, "myapp")), check.Equals, true)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppUpdate, permission.Context(permTypes.CtxApp, "otherapp")), check.Equals, false)
+	grants, err := ListActiveAccessGrants()
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.HasLen, 1)
+	c.Assert(grants[0].Reason, check.Equals, "incident")
+}
+
+func (s *S) TestGrantAccessInvalid(c *check.C) {
+	_, err := permission.NewRole("app-admin", "app", "")
+	c.Assert(err, check.IsNil)
+	config.Set("access-grants:max-duration", "4h")
+	defer config.Unset("access-grants:max-duration")
+	tests := []struct {
+		grant    AccessGrant
+		duration time.Duration
+		msg      string
+	}{
+		{AccessGrant{User: s.user.Email, Role: "app-admin", ContextValue: "myapp"}, time.Hour, "reason is required"},
+		{AccessGrant{User: s.user.Email, Role: "app-admin", ContextValue: "myapp", Reason: "r"}, 5 * time.Hour, "duration must be between 0 and 4h0m0s"},
+		{AccessGrant{User: s.user.Email, Role: "app-admin", ContextValue: "myapp", Reason: "r"}, 0, "duration must be between 0 and 4h0m0s"},
+		{AccessGrant{User: "nobody@example.com", Role: "app-admin", ContextValue: "myapp", Reason: "r"}, time.Hour, "user not found"},
+		{AccessGrant{User: s.user.Email, Role: "unknown", ContextValue: "myapp", Reason: "r"}, time.Hour, "role not found"},
+		{AccessGrant{User: s.user.Email, Role: "app-admin", Reason: "r"}, time.Hour, "a context value is required for roles with the app context"},
+	}
+	for _, tt := range tests {
+		err = GrantAccess(&tt.grant, tt.duration)
+		c.Check(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+		c.Check(err, check.ErrorMatches, tt.msg)
+	}
+}
+
+func (s *S) TestRevokeAccessGrant(c *check.C) {
+	role, err := permission.NewRole("app-admin", "app", "")
+	c.Assert(err, check.IsNil)
+	err = role.AddPermissions("app.update")
+	c.Assert(err, check.IsNil)
+	grant := AccessGrant{User: s.user.Email, Role: "app-admin", ContextValue: "myapp", Reason: "incident"}
+	err = GrantAccess(&grant, time.Hour)
+	c.Assert(err, check.IsNil)
+	err = RevokeAccessGrant(grant.ID.Hex(), "admin@example.com")
+	c.Assert(err, check.IsNil)
+	perms, err := s.user.Permissions()
+	c.Assert(err, check.IsNil)
+	c.Assert(permission.CheckFromPermList(perms, permission.PermAppUpdate, permission.Context(permTypes.CtxApp, "myapp")), check.Equals, false)
+	grants, err := ListActiveAccessGrants()
+	c.Assert(err, check.IsNil)
+	c.Assert(grants, check.HasLen, 0)
+	revoked, err := FindAccessGrant(grant.ID.Hex())
+	c.Assert(err, check.IsNil)
+	c.Assert(revoked.RevokedBy, check.Equals, "admin@example.com")
+	err = RevokeAccessGrant(grant.ID.Hex(), "admin@example.com")
+	c.Assert(err, check.Equals, ErrAccessGrantNotFound)
+	err = RevokeAccessGrant("invalid", "admin@example.com")
+	c.Assert(err, check.Equals, ErrAccessGrantNotFound)
+}
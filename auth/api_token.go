@@ -5,9 +5,8 @@
 package auth
 
 import (
-	"github.com/globalsign/mgo"
-	"github.com/globalsign/mgo/bson"
-	"github.com/tsuru/tsuru/db"
+	"context"
+
 	"github.com/tsuru/tsuru/permission"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 )
@@ -42,24 +41,22 @@ func (t *APIToken) Permissions() ([]permission.Permission, error) {
 }
 
 func getAPIToken(header string) (*APIToken, error) {
-	conn, err := db.Conn()
+	s, err := userStorage()
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
-	var t APIToken
 	token, err := ParseToken(header)
 	if err != nil {
 		return nil, err
 	}
-	err = conn.Users().Find(bson.M{"apikey": token}).One(&t)
+	u, err := s.FindByAPIKey(context.TODO(), token)
 	if err != nil {
-		if err == mgo.ErrNotFound {
+		if err == authTypes.ErrUserNotFound {
 			return nil, ErrInvalidToken
 		}
 		return nil, err
 	}
-	return &t, nil
+	return &APIToken{Token: u.APIKey, UserEmail: u.Email}, nil
 }
 
 func APIAuth(token string) (*APIToken, error) {
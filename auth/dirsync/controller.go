@@ -0,0 +1,84 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dirsync
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/log"
+)
+
+// Initialize starts the controller syncing the mappings every
+// directory-sync:interval, when directory-sync:ldap:url is set. With
+// directory-sync:dry-run enabled the controller only logs the changes.
+func Initialize() error {
+	if url, _ := config.GetString("directory-sync:ldap:url"); url == "" {
+		return nil
+	}
+	interval, _ := config.GetDuration("directory-sync:interval")
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	dryRun, _ := config.GetBool("directory-sync:dry-run")
+	c := &controller{interval: interval, dryRun: dryRun}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type controller struct {
+	interval time.Duration
+	dryRun   bool
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+func (c *controller) start() {
+	c.shutdown = make(chan struct{})
+	c.done = make(chan struct{})
+	log.Debugf("[directory-sync] starting. Running every %s.", c.interval)
+	go func() {
+		defer close(c.done)
+		for {
+			select {
+			case <-time.After(c.interval):
+				c.runOnce()
+			case <-c.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (c *controller) runOnce() {
+	result, err := Sync(context.Background(), c.dryRun)
+	if err != nil {
+		log.Errorf("[directory-sync] unable to sync: %v", err)
+		return
+	}
+	for _, change := range result.Changes {
+		prefix := ""
+		if result.DryRun {
+			prefix = "(dry-run) "
+		}
+		log.Debugf("[directory-sync] %s%s role %q in team %q for %s, group %q", prefix, change.Action, change.Role, change.Team, change.User, change.Group)
+	}
+	for _, msg := range result.Errors {
+		log.Errorf("[directory-sync] %s", msg)
+	}
+}
+
+// Shutdown stops the controller, waiting for the current sync to finish.
+func (c *controller) Shutdown(ctx context.Context) error {
+	close(c.shutdown)
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
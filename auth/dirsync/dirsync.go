@@ -0,0 +1,343 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dirsync synchronizes team memberships with the groups of an LDAP
+// or Active Directory server. Each mapping assigns a team role to the members
+// of a directory group, removing it when they leave the group. Roles assigned
+// manually are never removed by the sync.
+package dirsync
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	ActionAdd    = "add"
+	ActionRemove = "remove"
+)
+
+var (
+	ErrMappingNotFound      = errors.New("directory sync mapping not found")
+	ErrMappingAlreadyExists = errors.New("directory sync mapping already exists")
+	ErrNotConfigured        = errors.New("directory sync is not configured, set directory-sync:ldap:url")
+)
+
+// Mapping assigns a role in a team to the members of a directory group.
+type Mapping struct {
+	ID    bson.ObjectId `bson:"_id" json:"id"`
+	Group string        `json:"group"`
+	Team  string        `json:"team"`
+	Role  string        `json:"role"`
+	// Members are the users the role was assigned to by the sync, only them
+	// have the role removed when they leave the group.
+	Members   []string  `json:"members"`
+	LastSync  time.Time `json:"lastSync"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Change is a role assignment or removal made, or planned in dry runs, by the
+// sync.
+type Change struct {
+	Group  string `json:"group"`
+	Team   string `json:"team"`
+	Role   string `json:"role"`
+	User   string `json:"user"`
+	Action string `json:"action"`
+}
+
+// Result is the outcome of a sync of all mappings.
+type Result struct {
+	DryRun  bool     `json:"dryRun"`
+	Changes []Change `json:"changes"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+func mappingsCollection(conn *db.Storage) (*storage.Collection, error) {
+	coll := conn.Collection("directory_sync_mappings")
+	err := coll.EnsureIndex(mgo.Index{Key: []string{"group", "team", "role"}, Unique: true})
+	return coll, err
+}
+
+// Create stores a new mapping, it's applied in the next sync.
+func Create(ctx context.Context, m *Mapping) error {
+	if m.Group == "" || m.Team == "" || m.Role == "" {
+		return &tsuruErrors.ValidationError{Message: "group, team and role are required"}
+	}
+	role, err := permission.FindRole(m.Role)
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: err.Error()}
+	}
+	if role.ContextType != permTypes.CtxTeam {
+		return &tsuruErrors.ValidationError{Message: "role " + m.Role + " must have the team context"}
+	}
+	_, err = servicemanager.Team.FindByName(ctx, m.Team)
+	if err != nil {
+		if err == authTypes.ErrTeamNotFound {
+			return &tsuruErrors.ValidationError{Message: err.Error()}
+		}
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	coll, err := mappingsCollection(conn)
+	if err != nil {
+		return err
+	}
+	m.ID = bson.NewObjectId()
+	m.Members = nil
+	err = coll.Insert(m)
+	if mgo.IsDup(err) {
+		return ErrMappingAlreadyExists
+	}
+	return err
+}
+
+// List returns all mappings, sorted by group and team.
+func List() ([]Mapping, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	coll, err := mappingsCollection(conn)
+	if err != nil {
+		return nil, err
+	}
+	var mappings []Mapping
+	err = coll.Find(nil).Sort("group", "team", "role").All(&mappings)
+	return mappings, err
+}
+
+// Remove deletes a mapping. The roles it assigned are kept.
+func Remove(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return ErrMappingNotFound
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	coll, err := mappingsCollection(conn)
+	if err != nil {
+		return err
+	}
+	err = coll.RemoveId(bson.ObjectIdHex(id))
+	if err == mgo.ErrNotFound {
+		return ErrMappingNotFound
+	}
+	return err
+}
+
+// Sync applies all mappings, assigning their roles to the members of the
+// groups and removing them from the users that left the groups. Dry runs
+// only report the changes.
+func Sync(ctx context.Context, dryRun bool) (*Result, error) {
+	dir, err := NewDirectory()
+	if err != nil {
+		return nil, err
+	}
+	mappings, err := List()
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{DryRun: dryRun, Changes: []Change{}}
+	for i := range mappings {
+		err = syncMapping(ctx, dir, &mappings[i], dryRun, result)
+		if err != nil {
+			result.Errors = append(result.Errors, errors.Wrapf(err, "group %q to team %q", mappings[i].Group, mappings[i].Team).Error())
+		}
+	}
+	return result, nil
+}
+
+func syncMapping(ctx context.Context, dir Directory, m *Mapping, dryRun bool, result *Result) error {
+	emails, err := dir.GroupMembers(ctx, m.Group)
+	if err != nil {
+		if !dryRun {
+			updateErr := updateMapping(m.ID, bson.M{"$set": bson.M{"lastsync": time.Now().UTC(), "lasterror": err.Error()}})
+			if updateErr != nil {
+				log.Errorf("[directory-sync] unable to update mapping %s: %v", m.ID.Hex(), updateErr)
+			}
+		}
+		return err
+	}
+	sort.Strings(emails)
+	inGroup := make(map[string]struct{}, len(emails))
+	for _, email := range emails {
+		inGroup[strings.ToLower(email)] = struct{}{}
+	}
+	synced := make(map[string]struct{}, len(m.Members))
+	for _, email := range m.Members {
+		synced[email] = struct{}{}
+	}
+	var members []string
+	multiErr := tsuruErrors.NewMultiError()
+	for _, email := range emails {
+		email = strings.ToLower(email)
+		_, wasSynced := synced[email]
+		user, err := auth.GetUserByEmail(email)
+		if err != nil {
+			if err != authTypes.ErrUserNotFound {
+				multiErr.Add(err)
+			}
+			if wasSynced {
+				members = append(members, email)
+			}
+			// Users are synced on their first login.
+			continue
+		}
+		if hasRole(user, m.Role, m.Team) {
+			// Roles assigned manually are not taken over by the sync.
+			if wasSynced {
+				members = append(members, email)
+			}
+			continue
+		}
+		result.Changes = append(result.Changes, Change{Group: m.Group, Team: m.Team, Role: m.Role, User: email, Action: ActionAdd})
+		if dryRun {
+			continue
+		}
+		if err = user.AddRole(m.Role, m.Team); err != nil {
+			multiErr.Add(err)
+			continue
+		}
+		members = append(members, email)
+	}
+	for _, email := range m.Members {
+		if _, ok := inGroup[email]; ok {
+			continue
+		}
+		result.Changes = append(result.Changes, Change{Group: m.Group, Team: m.Team, Role: m.Role, User: email, Action: ActionRemove})
+		if dryRun {
+			continue
+		}
+		if err = removeRole(email, m.Role, m.Team); err != nil {
+			multiErr.Add(err)
+			members = append(members, email)
+		}
+	}
+	if dryRun {
+		return multiErr.ToError()
+	}
+	update := bson.M{"members": members, "lastsync": time.Now().UTC(), "lasterror": ""}
+	if multiErr.Len() > 0 {
+		update["lasterror"] = multiErr.Error()
+	}
+	if err = updateMapping(m.ID, bson.M{"$set": update}); err != nil {
+		multiErr.Add(err)
+	}
+	return multiErr.ToError()
+}
+
+// SyncUser applies the mappings to a single user, usually on login, so that
+// memberships are up to date without waiting for the next sync. It does
+// nothing when directory-sync:dry-run is enabled.
+func SyncUser(ctx context.Context, email string) error {
+	if dryRun, _ := config.GetBool("directory-sync:dry-run"); dryRun {
+		return nil
+	}
+	mappings, err := List()
+	if err != nil || len(mappings) == 0 {
+		return err
+	}
+	dir, err := NewDirectory()
+	if err != nil {
+		return err
+	}
+	groups, err := dir.UserGroups(ctx, email)
+	if err != nil {
+		return err
+	}
+	user, err := auth.GetUserByEmail(email)
+	if err != nil {
+		return err
+	}
+	email = strings.ToLower(email)
+	multiErr := tsuruErrors.NewMultiError()
+	for _, m := range mappings {
+		inGroup := containsFold(groups, m.Group)
+		synced := containsFold(m.Members, email)
+		switch {
+		case inGroup && !hasRole(user, m.Role, m.Team):
+			err = user.AddRole(m.Role, m.Team)
+			if err == nil {
+				err = updateMapping(m.ID, bson.M{"$addToSet": bson.M{"members": email}})
+			}
+		case !inGroup && synced:
+			err = user.RemoveRole(m.Role, m.Team)
+			if err == nil {
+				err = updateMapping(m.ID, bson.M{"$pull": bson.M{"members": email}})
+			}
+		default:
+			err = nil
+		}
+		if err != nil {
+			multiErr.Add(err)
+		}
+	}
+	return multiErr.ToError()
+}
+
+func hasRole(user *auth.User, role, team string) bool {
+	for _, r := range user.Roles {
+		if r.Name == role && r.ContextValue == team {
+			return true
+		}
+	}
+	return false
+}
+
+func removeRole(email, role, team string) error {
+	user, err := auth.GetUserByEmail(email)
+	if err != nil {
+		if err == authTypes.ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+	return user.RemoveRole(role, team)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func updateMapping(id bson.ObjectId, update bson.M) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	coll, err := mappingsCollection(conn)
+	if err != nil {
+		return err
+	}
+	return coll.UpdateId(id, update)
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dirsync
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/permission"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage     *db.Storage
+	mockService servicemock.MockService
+	dir         *fakeDirectory
+}
+
+var _ = check.Suite(&S{})
+
+type fakeDirectory struct {
+	groups map[string][]string
+}
+
+func (d *fakeDirectory) GroupMembers(ctx context.Context, group string) ([]string, error) {
+	return d.groups[group], nil
+}
+
+func (d *fakeDirectory) UserGroups(ctx context.Context, email string) ([]string, error) {
+	var groups []string
+	for group, members := range d.groups {
+		if containsFold(members, email) {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "tsuru_dirsync_tests")
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	err := dbtest.ClearAllCollections(s.storage.Apps().Database)
+	c.Assert(err, check.IsNil)
+	servicemock.SetMockService(&s.mockService)
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		if name != "myteam" {
+			return nil, authTypes.ErrTeamNotFound
+		}
+		return &authTypes.Team{Name: name}, nil
+	}
+	_, err = permission.NewRole("team-member", "team", "")
+	c.Assert(err, check.IsNil)
+	_, err = permission.NewRole("global-admin", "global", "")
+	c.Assert(err, check.IsNil)
+	s.dir = &fakeDirectory{groups: map[string][]string{}}
+	NewDirectory = func() (Directory, error) { return s.dir, nil }
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	NewDirectory = newLDAPDirectory
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+	s.storage.Close()
+}
+
+func (s *S) createUser(c *check.C, email string) *auth.User {
+	u := &auth.User{Email: email, Password: "123456"}
+	err := u.Create()
+	c.Assert(err, check.IsNil)
+	return u
+}
+
+func (s *S) userHasRole(c *check.C, email string) bool {
+	u, err := auth.GetUserByEmail(email)
+	c.Assert(err, check.IsNil)
+	return hasRole(u, "team-member", "myteam")
+}
+
+func (s *S) TestCreate(c *check.C) {
+	m := Mapping{Group: "devs", Team: "myteam", Role: "team-member"}
+	err := Create(context.TODO(), &m)
+	c.Assert(err, check.IsNil)
+	c.Assert(m.ID.Valid(), check.Equals, true)
+	mappings, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(mappings, check.HasLen, 1)
+	c.Assert(mappings[0].Group, check.Equals, "devs")
+	err = Create(context.TODO(), &Mapping{Group: "devs", Team: "myteam", Role: "team-member"})
+	c.Assert(err, check.Equals, ErrMappingAlreadyExists)
+}
+
+func (s *S) TestCreateInvalid(c *check.C) {
+	tests := []struct {
+		mapping Mapping
+		msg     string
+	}{
+		{Mapping{Team: "myteam", Role: "team-member"}, "group, team and role are required"},
+		{Mapping{Group: "devs", Team: "myteam", Role: "unknown"}, "role not found"},
+		{Mapping{Group: "devs", Team: "myteam", Role: "global-admin"}, "role global-admin must have the team context"},
+		{Mapping{Group: "devs", Team: "otherteam", Role: "team-member"}, authTypes.ErrTeamNotFound.Error()},
+	}
+	for _, tt := range tests {
+		err := Create(context.TODO(), &tt.mapping)
+		c.Check(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+		c.Check(err, check.ErrorMatches, tt.msg)
+	}
+}
+
+func (s *S) TestRemove(c *check.C) {
+	m := Mapping{Group: "devs", Team: "myteam", Role: "team-member"}
+	err := Create(context.TODO(), &m)
+	c.Assert(err, check.IsNil)
+	err = Remove(m.ID.Hex())
+	c.Assert(err, check.IsNil)
+	err = Remove(m.ID.Hex())
+	c.Assert(err, check.Equals, ErrMappingNotFound)
+	err = Remove("invalid")
+	c.Assert(err, check.Equals, ErrMappingNotFound)
+}
+
+func (s *S) TestSync(c *check.C) {
+	s.createUser(c, "alice@example.com")
+	s.createUser(c, "bob@example.com")
+	manual := s.createUser(c, "carol@example.com")
+	err := manual.AddRole("team-member", "myteam")
+	c.Assert(err, check.IsNil)
+	err = Create(context.TODO(), &Mapping{Group: "devs", Team: "myteam", Role: "team-member"})
+	c.Assert(err, check.IsNil)
+	s.dir.groups["devs"] = []string{"Alice@example.com", "bob@example.com", "carol@example.com", "dave@example.com"}
+	result, err := Sync(context.TODO(), false)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Errors, check.HasLen, 0)
+	c.Assert(result.Changes, check.DeepEquals, []Change{
+		{Group: "devs", Team: "myteam", Role: "team-member", User: "alice@example.com", Action: ActionAdd},
+		{Group: "devs", Team: "myteam", Role: "team-member", User: "bob@example.com", Action: ActionAdd},
+	})
+	c.Assert(s.userHasRole(c, "alice@example.com"), check.Equals, true)
+	c.Assert(s.userHasRole(c, "bob@example.com"), check.Equals, true)
+	s.dir.groups["devs"] = []string{"alice@example.com"}
+	result, err = Sync(context.TODO(), false)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Changes, check.DeepEquals, []Change{
+		{Group: "devs", Team: "myteam", Role: "team-member", User: "bob@example.com", Action: ActionRemove},
+	})
+	c.Assert(s.userHasRole(c, "alice@example.com"), check.Equals, true)
+	c.Assert(s.userHasRole(c, "bob@example.com"), check.Equals, false)
+	c.Assert(s.userHasRole(c, "carol@example.com"), check.Equals, true)
+	mappings, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(mappings[0].Members, check.DeepEquals, []string{"alice@example.com"})
+	c.Assert(mappings[0].LastSync.IsZero(), check.Equals, false)
+}
+
+func (s *S) TestSyncDryRun(c *check.C) {
+	s.createUser(c, "alice@example.com")
+	err := Create(context.TODO(), &Mapping{Group: "devs", Team: "myteam", Role: "team-member"})
+	c.Assert(err, check.IsNil)
+	s.dir.groups["devs"] = []string{"alice@example.com"}
+	result, err := Sync(context.TODO(), true)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.DryRun, check.Equals, true)
+	c.Assert(result.Changes, check.HasLen, 1)
+	c.Assert(s.userHasRole(c, "alice@example.com"), check.Equals, false)
+	mappings, err := List()
+	c.Assert(err, check.IsNil)
+	c.Assert(mappings[0].Members, check.HasLen, 0)
+}
+
+func (s *S) TestSyncNotConfigured(c *check.C) {
+	NewDirectory = newLDAPDirectory
+	defer func() { NewDirectory = func() (Directory, error) { return s.dir, nil } }()
+	_, err := Sync(context.TODO(), false)
+	c.Assert(err, check.Equals, ErrNotConfigured)
+}
+
+func (s *S) TestSyncUser(c *check.C) {
+	s.createUser(c, "alice@example.com")
+	err := Create(context.TODO(), &Mapping{Group: "devs", Team: "myteam", Role: "team-member"})
+	c.Assert(err, check.IsNil)
+	s.dir.groups["devs"] = []string{"alice@example.com"}
+	err = SyncUser(context.TODO(), "alice@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(s.userHasRole(c, "alice@example.com"), check.Equals, true)
+	s.dir.groups["devs"] = nil
+	err = SyncUser(context.TODO(), "alice@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(s.userHasRole(c, "alice@example.com"), check.Equals, false)
+}
+
+func (s *S) TestSyncUserDryRun(c *check.C) {
+	config.Set("directory-sync:dry-run", true)
+	defer config.Unset("directory-sync:dry-run")
+	s.createUser(c, "alice@example.com")
+	err := Create(context.TODO(), &Mapping{Group: "devs", Team: "myteam", Role: "team-member"})
+	c.Assert(err, check.IsNil)
+	s.dir.groups["devs"] = []string{"alice@example.com"}
+	err = SyncUser(context.TODO(), "alice@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(s.userHasRole(c, "alice@example.com"), check.Equals, false)
+}
+
+func (s *S) TestContainsFold(c *check.C) {
+	c.Assert(containsFold([]string{"Devs"}, "devs"), check.Equals, true)
+	c.Assert(containsFold([]string{"ops"}, strings.ToUpper("devs")), check.Equals, false)
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dirsync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+// Directory is the source of the groups synchronized to teams.
+type Directory interface {
+	// GroupMembers returns the emails of the members of the group.
+	GroupMembers(ctx context.Context, group string) ([]string, error)
+	// UserGroups returns the names of the groups of the user.
+	UserGroups(ctx context.Context, email string) ([]string, error)
+}
+
+// NewDirectory returns the directory used by the sync, it's replaced in
+// tests.
+var NewDirectory = newLDAPDirectory
+
+type ldapDirectory struct {
+	url                string
+	bindDN             string
+	bindPassword       string
+	baseDN             string
+	groupFilter        string
+	memberAttribute    string
+	memberFilter       string
+	groupNameAttribute string
+	userFilter         string
+	mailAttribute      string
+}
+
+func configString(key, defaultValue string) string {
+	value, _ := config.GetString("directory-sync:ldap:" + key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func newLDAPDirectory() (Directory, error) {
+	url := configString("url", "")
+	if url == "" {
+		return nil, ErrNotConfigured
+	}
+	return &ldapDirectory{
+		url:                url,
+		bindDN:             configString("bind-dn", ""),
+		bindPassword:       configString("bind-password", ""),
+		baseDN:             configString("base-dn", ""),
+		groupFilter:        configString("group-filter", "(cn=%s)"),
+		memberAttribute:    configString("member-attribute", "member"),
+		memberFilter:       configString("member-filter", "(member=%s)"),
+		groupNameAttribute: configString("group-name-attribute", "cn"),
+		userFilter:         configString("user-filter", "(mail=%s)"),
+		mailAttribute:      configString("mail-attribute", "mail"),
+	}, nil
+}
+
+func (d *ldapDirectory) connect() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(d.url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to connect to %s", d.url)
+	}
+	if d.bindDN != "" {
+		err = conn.Bind(d.bindDN, d.bindPassword)
+		if err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "unable to bind as %s", d.bindDN)
+		}
+	}
+	return conn, nil
+}
+
+func (d *ldapDirectory) search(conn *ldap.Conn, baseDN string, scope int, filter string, attrs ...string) ([]*ldap.Entry, error) {
+	req := ldap.NewSearchRequest(baseDN, scope, ldap.NeverDerefAliases, 0, 0, false, filter, attrs, nil)
+	result, err := conn.Search(req)
+	if err != nil {
+		return nil, err
+	}
+	return result.Entries, nil
+}
+
+func (d *ldapDirectory) GroupMembers(ctx context.Context, group string) ([]string, error) {
+	conn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	entries, err := d.search(conn, d.baseDN, ldap.ScopeWholeSubtree, fmt.Sprintf(d.groupFilter, ldap.EscapeFilter(group)), d.memberAttribute)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to search group %q", group)
+	}
+	if len(entries) == 0 {
+		return nil, errors.Errorf("group %q not found in directory", group)
+	}
+	var emails []string
+	for _, memberDN := range entries[0].GetAttributeValues(d.memberAttribute) {
+		members, err := d.search(conn, memberDN, ldap.ScopeBaseObject, "(objectClass=*)", d.mailAttribute)
+		if err != nil {
+			if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "unable to read member %q of group %q", memberDN, group)
+		}
+		if len(members) == 0 {
+			continue
+		}
+		if mail := members[0].GetAttributeValue(d.mailAttribute); mail != "" {
+			emails = append(emails, strings.ToLower(mail))
+		}
+	}
+	return emails, nil
+}
+
+func (d *ldapDirectory) UserGroups(ctx context.Context, email string) ([]string, error) {
+	conn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	users, err := d.search(conn, d.baseDN, ldap.ScopeWholeSubtree, fmt.Sprintf(d.userFilter, ldap.EscapeFilter(email)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to search user %q", email)
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	groups, err := d.search(conn, d.baseDN, ldap.ScopeWholeSubtree, fmt.Sprintf(d.memberFilter, ldap.EscapeFilter(users[0].DN)), d.groupNameAttribute)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to search groups of user %q", email)
+	}
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		if name := g.GetAttributeValue(d.groupNameAttribute); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -7,6 +7,8 @@ package native
 import (
 	"crypto"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/tsuru/tsuru/db"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/readcache"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	"github.com/tsuru/tsuru/validation"
 	"golang.org/x/crypto/bcrypt"
@@ -148,7 +151,7 @@ func removeOldTokens(userEmail string) error {
 	}
 	var tokens []map[string]interface{}
 	err = conn.Tokens().Find(bson.M{"useremail": userEmail}).
-		Select(bson.M{"_id": 1}).Sort("creation").Limit(diff).All(&tokens)
+		Select(bson.M{"_id": 1, "token": 1}).Sort("creation").Limit(diff).All(&tokens)
 	if err != nil {
 		return nil
 	}
@@ -157,6 +160,7 @@ func removeOldTokens(userEmail string) error {
 		ids = append(ids, token["_id"])
 	}
 	_, err = conn.Tokens().RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
+	invalidateTokens(tokens)
 	return err
 }
 
@@ -192,17 +196,19 @@ func createToken(u *auth.User, password string) (*Token, error) {
 }
 
 func getToken(header string) (*Token, error) {
-	conn, err := db.Conn()
-	if err != nil {
-		return nil, err
-	}
-	defer conn.Close()
 	var t Token
 	token, err := auth.ParseToken(header)
 	if err != nil {
 		return nil, err
 	}
-	err = conn.Tokens().Find(bson.M{"token": token}).One(&t)
+	err = readcache.Fetch(readcache.Tokens, tokenCacheKey(token), &t, func() error {
+		conn, err := db.Conn()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return conn.Tokens().Find(bson.M{"token": token}).One(&t)
+	})
 	if err != nil {
 		if err == mgo.ErrNotFound {
 			return nil, auth.ErrInvalidToken
@@ -221,6 +227,7 @@ func deleteToken(token string) error {
 		return err
 	}
 	defer conn.Close()
+	defer readcache.Invalidate(readcache.Tokens, tokenCacheKey(token))
 	return conn.Tokens().Remove(bson.M{"token": token})
 }
 
@@ -230,10 +237,33 @@ func deleteAllTokens(email string) error {
 		return err
 	}
 	defer conn.Close()
+	var tokens []map[string]interface{}
+	err = conn.Tokens().Find(bson.M{"useremail": email}).Select(bson.M{"token": 1}).All(&tokens)
+	if err != nil {
+		return err
+	}
 	_, err = conn.Tokens().RemoveAll(bson.M{"useremail": email})
+	invalidateTokens(tokens)
 	return err
 }
 
+// tokenCacheKey hashes the token, so tokens aren't stored as keys in the read
+// cache.
+func tokenCacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func invalidateTokens(tokens []map[string]interface{}) {
+	keys := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if value, ok := token["token"].(string); ok {
+			keys = append(keys, tokenCacheKey(value))
+		}
+	}
+	readcache.Invalidate(readcache.Tokens, keys...)
+}
+
 func createApplicationToken(appName string) (*Token, error) {
 	conn, err := db.Conn()
 	if err != nil {
@@ -5,20 +5,20 @@
 package auth
 
 import (
+	"context"
 	"crypto"
 	"crypto/rand"
 	_ "crypto/sha256"
 	"fmt"
 	"time"
 
-	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
-	"github.com/tsuru/tsuru/db"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
 	authTypes "github.com/tsuru/tsuru/types/auth"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	"github.com/tsuru/tsuru/types/quota"
@@ -35,27 +35,48 @@ type User struct {
 	FromToken bool                     `bson:",omitempty"`
 }
 
-func listUsers(filter bson.M) ([]User, error) {
-	conn, err := db.Conn()
+func userStorage() (authTypes.UserStorage, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
 	if err != nil {
-		return nil, err
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
 	}
-	defer conn.Close()
-	var users []User
-	err = conn.Users().Find(filter).All(&users)
-	if err != nil {
-		return nil, err
+	return dbDriver.UserStorage, nil
+}
+
+func convertUsers(users []authTypes.User) []User {
+	result := make([]User, len(users))
+	for i, u := range users {
+		result[i] = User(u)
 	}
-	return users, nil
+	return result
 }
 
 // ListUsers list all users registred in tsuru
 func ListUsers() ([]User, error) {
-	return listUsers(nil)
+	s, err := userStorage()
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.FindAll(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return convertUsers(users), nil
 }
 
 func ListUsersWithRole(role string) ([]User, error) {
-	return listUsers(bson.M{"roles.name": role})
+	s, err := userStorage()
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.FindByRole(context.TODO(), role)
+	if err != nil {
+		return nil, err
+	}
+	return convertUsers(users), nil
 }
 
 func ListUsersWithPermissions(wantedPerms ...permission.Permission) ([]User, error) {
@@ -85,26 +106,22 @@ func GetUserByEmail(email string) (*User, error) {
 	if !validation.ValidateEmail(email) {
 		return nil, &tsuruErrors.ValidationError{Message: "invalid email"}
 	}
-	var u User
-	conn, err := db.Conn()
+	s, err := userStorage()
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
-	err = conn.Users().Find(bson.M{"email": email}).One(&u)
+	u, err := s.FindByEmail(context.TODO(), email)
 	if err != nil {
 		return nil, authTypes.ErrUserNotFound
 	}
-	return &u, nil
+	return ConvertNewUser(u, nil)
 }
 
 func (u *User) Create() error {
-	conn, err := db.Conn()
+	s, err := userStorage()
 	if err != nil {
-		addr, _ := db.DbConfig("")
-		return errors.New(fmt.Sprintf("Failed to connect to MongoDB %q - %s.", addr, err.Error()))
+		return err
 	}
-	defer conn.Close()
 	if u.Quota.Limit == 0 {
 		u.Quota = quota.UnlimitedQuota
 		var limit int
@@ -112,7 +129,7 @@ func (u *User) Create() error {
 			u.Quota.Limit = limit
 		}
 	}
-	err = conn.Users().Insert(u)
+	err = s.Insert(context.TODO(), authTypes.User(*u))
 	if err != nil {
 		return err
 	}
@@ -124,12 +141,11 @@ func (u *User) Create() error {
 }
 
 func (u *User) Delete() error {
-	conn, err := db.Conn()
+	s, err := userStorage()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	err = conn.Users().Remove(bson.M{"email": u.Email})
+	err = s.Delete(context.TODO(), u.Email)
 	if err != nil {
 		log.Errorf("failed to remove user %q from the database: %s", u.Email, err)
 	}
@@ -138,12 +154,11 @@ func (u *User) Delete() error {
 }
 
 func (u *User) Update() error {
-	conn, err := db.Conn()
+	s, err := userStorage()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	return conn.Users().Update(bson.M{"email": u.Email}, u)
+	return s.Update(context.TODO(), authTypes.User(*u))
 }
 
 func (u *User) ShowAPIKey() (string, error) {
@@ -174,12 +189,16 @@ func (u *User) RegenerateAPIKey() (string, error) {
 }
 
 func (u *User) Reload() error {
-	conn, err := db.Conn()
+	s, err := userStorage()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	return conn.Users().Find(bson.M{"email": u.Email}).One(u)
+	reloaded, err := s.FindByEmail(context.TODO(), u.Email)
+	if err != nil {
+		return err
+	}
+	*u = User(*reloaded)
+	return nil
 }
 
 func expandRolePermissions(roleInstances []authTypes.RoleInstance) ([]permission.Permission, error) {
@@ -221,6 +240,11 @@ func (u *User) Permissions() ([]permission.Permission, error) {
 	for _, group := range groups {
 		allRoles = append(allRoles, group.Roles...)
 	}
+	grantRoles, err := accessGrantRoles(u.Email)
+	if err != nil {
+		return nil, err
+	}
+	allRoles = append(allRoles, grantRoles...)
 	permissions, err := expandRolePermissions(allRoles)
 	if err != nil {
 		return nil, err
@@ -236,21 +260,11 @@ func (u *User) AddRole(roleName string, contextValue string) error {
 	if err != nil {
 		return err
 	}
-	conn, err := db.Conn()
+	s, err := userStorage()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	err = conn.Users().Update(bson.M{"email": u.Email}, bson.M{
-		"$addToSet": bson.M{
-			// Order matters in $addToSet, that's why bson.D is used instead
-			// of bson.M.
-			"roles": bson.D([]bson.DocElem{
-				{Name: "name", Value: roleName},
-				{Name: "contextvalue", Value: contextValue},
-			}),
-		},
-	})
+	err = s.AddRole(context.TODO(), u.Email, authTypes.RoleInstance{Name: roleName, ContextValue: contextValue})
 	if err != nil {
 		return err
 	}
@@ -309,33 +323,19 @@ func UpdateRoleFromAllUsers(roleName, newRoleName, ctx, desc string) error {
 }
 
 func RemoveRoleFromAllUsers(roleName string) error {
-	conn, err := db.Conn()
+	s, err := userStorage()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	_, err = conn.Users().UpdateAll(bson.M{"roles.name": roleName}, bson.M{
-		"$pull": bson.M{
-			"roles": bson.M{"name": roleName},
-		},
-	})
-	return err
+	return s.RemoveRoleFromAll(context.TODO(), roleName)
 }
 
 func (u *User) RemoveRole(roleName string, contextValue string) error {
-	conn, err := db.Conn()
+	s, err := userStorage()
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
-	err = conn.Users().Update(bson.M{"email": u.Email}, bson.M{
-		"$pull": bson.M{
-			"roles": bson.D([]bson.DocElem{
-				{Name: "name", Value: roleName},
-				{Name: "contextvalue", Value: contextValue},
-			}),
-		},
-	})
+	err = s.RemoveRole(context.TODO(), u.Email, authTypes.RoleInstance{Name: roleName, ContextValue: contextValue})
 	if err != nil {
 		return err
 	}
@@ -8,6 +8,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -35,19 +37,28 @@ const (
 	lockWaitTimeout = 30 * time.Second
 )
 
-var globalConfig *Config
+var (
+	// globalConfigMut guards globalConfig and the running flag of the
+	// configs, which are read by Trigger from request handlers while the
+	// run loop is started or shut down.
+	globalConfigMut sync.RWMutex
+	globalConfig    *Config
+)
 
 type Config struct {
 	WaitTimeNewMachine  time.Duration
 	RunInterval         time.Duration
 	TotalMemoryMetadata string
 	done                chan bool
+	trigger             chan struct{}
 	writer              io.Writer
 	running             bool
 	Enabled             bool
 }
 
 func CurrentConfig() (*Config, error) {
+	globalConfigMut.RLock()
+	defer globalConfigMut.RUnlock()
 	if globalConfig == nil {
 		return nil, errors.New("autoscale not initialized")
 	}
@@ -55,13 +66,33 @@ func CurrentConfig() (*Config, error) {
 }
 
 func Initialize() error {
-	globalConfig = newConfig()
-	shutdown.Register(globalConfig)
-	globalConfig.running = true
-	go globalConfig.run()
+	conf := newConfig()
+	conf.running = true
+	globalConfigMut.Lock()
+	globalConfig = conf
+	globalConfigMut.Unlock()
+	shutdown.Register(conf)
+	go conf.run()
 	return nil
 }
 
+// Trigger requests an immediate run of the autoscaler, without waiting for
+// the run interval. It's called when units can't be scheduled in a pool due
+// to lack of capacity. Requests made while a run is pending are coalesced.
+func Trigger(pool string) {
+	globalConfigMut.RLock()
+	defer globalConfigMut.RUnlock()
+	conf := globalConfig
+	if conf == nil || !conf.running {
+		return
+	}
+	select {
+	case conf.trigger <- struct{}{}:
+		conf.logDebug("run triggered by lack of capacity in pool %q", pool)
+	default:
+	}
+}
+
 func RunOnce(w io.Writer) error {
 	conf := newConfig()
 	conf.writer = w
@@ -78,6 +109,7 @@ func newConfig() *Config {
 		RunInterval:         time.Duration(runInterval) * time.Second,
 		Enabled:             true,
 		done:                make(chan bool),
+		trigger:             make(chan struct{}, 1),
 	}
 	if c.RunInterval == 0 {
 		c.RunInterval = time.Hour
@@ -125,6 +157,7 @@ func (a *Config) run() error {
 		case <-a.done:
 			return err
 		case <-time.After(a.RunInterval):
+		case <-a.trigger:
 		}
 	}
 }
@@ -148,11 +181,14 @@ func (a *Config) runOnce() error {
 }
 
 func (a *Config) Shutdown(ctx context.Context) error {
-	if !a.running {
+	globalConfigMut.Lock()
+	running := a.running
+	a.running = false
+	globalConfigMut.Unlock()
+	if !running {
 		return nil
 	}
 	a.done <- true
-	a.running = false
 	return nil
 }
 
@@ -440,10 +476,26 @@ func (a *Config) removeMultipleNodes(evt *event.Event, prov provision.NodeProvis
 	return nil
 }
 
+// chooseNodeForRemoval returns up to toRemoveCount nodes that can be removed
+// without breaking metadata restrictions. Idle nodes are preferred, as they
+// have no units to be drained before the removal.
 func chooseNodeForRemoval(nodes []provision.Node, toRemoveCount int) []provision.Node {
+	unitCount := make(map[string]int, len(nodes))
+	for _, n := range nodes {
+		units, err := n.Units()
+		if err != nil {
+			unitCount[n.Address()] = math.MaxInt32
+			continue
+		}
+		unitCount[n.Address()] = len(units)
+	}
+	candidates := append([]provision.Node{}, nodes...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return unitCount[candidates[i].Address()] < unitCount[candidates[j].Address()]
+	})
 	var chosenNodes []provision.Node
-	remainingNodes := nodes[:]
-	for _, node := range nodes {
+	remainingNodes := append([]provision.Node{}, nodes...)
+	for _, node := range candidates {
 		canRemove, _ := canRemoveNode(node, remainingNodes)
 		if canRemove {
 			for i := range remainingNodes {
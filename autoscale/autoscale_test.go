@@ -1232,3 +1232,38 @@ func (s *S) TestChooseMetadataFromNodes(c *check.C) {
 	_, err = chooseMetadataFromNodes(nodes)
 	c.Assert(err, check.ErrorMatches, "unbalanced metadata for node group:.*")
 }
+
+func (s *S) TestChooseNodeForRemovalPrefersIdleNodes(c *check.C) {
+	err := s.p.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address: "http://n2:2",
+		Pool:    "pool1",
+		Metadata: map[string]string{
+			"iaas": "my-scale-iaas",
+		},
+	})
+	c.Assert(err, check.IsNil)
+	_, err = s.p.AddUnitsToNode(s.appInstance, 2, "web", nil, "n1:1", nil)
+	c.Assert(err, check.IsNil)
+	nodes, err := s.p.ListNodes(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.HasLen, 2)
+	chosen := chooseNodeForRemoval(nodes, 1)
+	c.Assert(chosen, check.HasLen, 1)
+	c.Assert(chosen[0].Address(), check.Equals, "http://n2:2")
+	c.Assert(nodes[0].Address(), check.Equals, "http://n1:1")
+	c.Assert(nodes[1].Address(), check.Equals, "http://n2:2")
+}
+
+func (s *S) TestTriggerCoalescesRequests(c *check.C) {
+	oldConfig := globalConfig
+	defer func() { globalConfig = oldConfig }()
+	globalConfig = &Config{running: true, trigger: make(chan struct{}, 1), done: make(chan bool, 1)}
+	Trigger("pool1")
+	Trigger("pool1")
+	c.Assert(globalConfig.trigger, check.HasLen, 1)
+	err := globalConfig.Shutdown(context.TODO())
+	c.Assert(err, check.IsNil)
+	<-globalConfig.trigger
+	Trigger("pool1")
+	c.Assert(globalConfig.trigger, check.HasLen, 0)
+}
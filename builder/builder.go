@@ -38,6 +38,24 @@ type Builder interface {
 	Build(ctx context.Context, p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *BuildOpts) (appTypes.AppVersion, error)
 }
 
+// Artifact is the content used to build an app version, Name and
+// ContentType describe how it should be served.
+type Artifact struct {
+	io.ReadCloser
+	Name        string
+	ContentType string
+}
+
+// ArtifactBuilder is a builder able to retrieve the artifact of an app
+// version previously built by it.
+type ArtifactBuilder interface {
+	Artifact(ctx context.Context, p provision.BuilderDeploy, app provision.App, version appTypes.AppVersion) (*Artifact, error)
+}
+
+// ErrArtifactNotFound is returned by ArtifactBuilder when the artifact of a
+// version is no longer available.
+var ErrArtifactNotFound = errors.New("artifact not found")
+
 var builders = make(map[string]Builder)
 
 // PlatformBuilder is a builder where administrators can manage
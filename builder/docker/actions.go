@@ -96,7 +96,7 @@ var createContainer = action.Action{
 	Backward: func(ctx action.BWContext) {
 		c := ctx.FWResult.(container.Container)
 		args := ctx.Params[0].(runContainerActionsArgs)
-		err := args.client.RemoveContainer(docker.RemoveContainerOptions{ID: c.ID})
+		err := args.client.RemoveContainer(docker.RemoveContainerOptions{ID: c.ID, Force: true})
 		if err != nil {
 			log.Errorf("Failed to remove the container %q: %s", c.ID, err)
 		}
@@ -115,12 +115,15 @@ var uploadToContainer = action.Action{
 		uploadOpts := docker.UploadToContainerOptions{
 			InputStream: args.tarFile,
 			Path:        archiveDirPath,
+			Context:     ctx.Context,
 		}
+		done := args.event.StartStage(provision.DeployStageUpload)
 		err := args.client.UploadToContainer(c.ID, uploadOpts)
 		if err != nil {
 			log.Errorf("error on upload tarfile to container %s - %s", c.ID, err)
-			return nil, err
+			return nil, canceledError(ctx.Context, err)
 		}
+		done()
 		return c, nil
 	},
 	Backward: func(ctx action.BWContext) {
@@ -136,6 +139,7 @@ var startContainer = action.Action{
 		}
 		c := ctx.Previous.(container.Container)
 		log.Debugf("starting container %s", c.ID)
+		done := args.event.StartStage(provision.DeployStageBuild)
 		err := c.Start(&container.StartArgs{
 			Client:  args.client,
 			Limiter: limiter(),
@@ -144,6 +148,7 @@ var startContainer = action.Action{
 			log.Errorf("error on start container %s - %s", c.ID, err)
 			return nil, err
 		}
+		done()
 		return c, nil
 	},
 	Backward: func(ctx action.BWContext) {
@@ -167,6 +172,7 @@ var followLogsAndCommit = action.Action{
 		if !ok {
 			return nil, errors.New("Previous result must be a container.")
 		}
+		doneBuild := args.event.StartStage(provision.DeployStageBuild)
 		type logsResult struct {
 			status int
 			err    error
@@ -174,6 +180,10 @@ var followLogsAndCommit = action.Action{
 		doneCh := make(chan bool)
 		canceledCh := make(chan error)
 		resultCh := make(chan logsResult)
+		var ctxDone <-chan struct{}
+		if ctx.Context != nil {
+			ctxDone = ctx.Context.Done()
+		}
 		go func() {
 			for {
 				err := checkCanceled(args.event)
@@ -201,6 +211,9 @@ var followLogsAndCommit = action.Action{
 		select {
 		case err := <-canceledCh:
 			return nil, err
+		case <-ctxDone:
+			close(doneCh)
+			return nil, canceledError(ctx.Context, ctx.Context.Err())
 		case result := <-resultCh:
 			doneCh <- true
 			if result.err != nil {
@@ -211,15 +224,18 @@ var followLogsAndCommit = action.Action{
 				return nil, errors.Errorf("Exit status %d", result.status)
 			}
 		}
+		doneBuild()
 		if err := checkCanceled(args.event); err != nil {
 			return nil, err
 		}
 		fmt.Fprintf(args.writer, "\n---- Building image ----\n")
-		imageID, err := c.Commit(args.client, limiter(), args.writer, false)
+		donePush := args.event.StartStage(provision.DeployStagePush)
+		imageID, err := c.Commit(args.client, limiter(), args.app.GetPool(), args.writer, false)
 		if err != nil {
 			log.Errorf("error on commit container %s - %s", c.ID, err)
 			return nil, err
 		}
+		donePush()
 		fmt.Fprintf(args.writer, " ---> Cleaning up\n")
 		c.Remove(args.client, limiter())
 		return imageID, nil
@@ -253,7 +253,7 @@ func (s *S) TestUpdateAppBuilderImageForward(c *check.C) {
 	})
 	c.Assert(err, check.IsNil)
 	buf := safe.NewBuffer(nil)
-	imgID, err := cont.Commit(builderClient(client), limiter(), buf, false)
+	imgID, err := cont.Commit(builderClient(client), limiter(), "", buf, false)
 	c.Assert(err, check.IsNil)
 	c.Assert(imgID, check.Equals, "tsuru/app-mightyapp:v1-builder")
 	c.Assert(buf.String(), check.Not(check.Equals), "")
@@ -321,3 +321,31 @@ func newVersionForApp(c *check.C, client *docker.Client, a provision.App, custom
 	}, docker.AuthConfiguration{})
 	return version
 }
+
+func (s *S) TestFollowLogsAndCommitForwardCanceled(c *check.C) {
+	client, err := docker.NewClient(s.server.URL())
+	c.Assert(err, check.IsNil)
+	app := provisiontest.NewFakeApp("myapp", "python", 1)
+	cont := container.Container{Container: types.Container{AppName: "mightyapp"}}
+	err = cont.Create(&container.CreateArgs{
+		App:      app,
+		ImageID:  "tsuru/python",
+		Commands: []string{"foo"},
+		Client:   builderClient(client),
+	})
+	c.Assert(err, check.IsNil)
+	err = cont.Start(&container.StartArgs{
+		Client:  builderClient(client),
+		Limiter: limiter(),
+	})
+	c.Assert(err, check.IsNil)
+	defer cont.Remove(builderClient(client), limiter())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf := safe.NewBuffer(nil)
+	args := runContainerActionsArgs{writer: buf, provisioner: s.provisioner, client: builderClient(client)}
+	fwCtx := action.FWContext{Context: ctx, Params: []interface{}{args}, Previous: cont}
+	imageID, err := followLogsAndCommit.Forward(fwCtx)
+	c.Assert(err, check.Equals, ErrDeployCanceled)
+	c.Assert(imageID, check.IsNil)
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/builder"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+var _ builder.ArtifactBuilder = &dockerBuilder{}
+
+type containerExporter interface {
+	ExportContainer(docker.ExportContainerOptions) error
+}
+
+type artifactReader struct {
+	io.Reader
+	closeFn func()
+}
+
+func (r *artifactReader) Close() error {
+	r.closeFn()
+	return nil
+}
+
+// Artifact returns the archive uploaded when the version was deployed,
+// which is kept inside the version image. Versions deployed without an
+// archive, like image deploys, return the exported file system of the
+// version image.
+func (b *dockerBuilder) Artifact(ctx context.Context, prov provision.BuilderDeploy, app provision.App, version appTypes.AppVersion) (*builder.Artifact, error) {
+	p, ok := prov.(provision.BuilderDeployDockerClient)
+	if !ok {
+		return nil, errors.New("provisioner not supported: doesn't implement docker builder")
+	}
+	versionInfo := version.VersionInfo()
+	imageID := versionInfo.DeployImage
+	if imageID == "" {
+		imageID = versionInfo.BuildImage
+	}
+	if imageID == "" {
+		return nil, builder.ErrArtifactNotFound
+	}
+	client, err := p.GetClient(app)
+	if err != nil {
+		return nil, err
+	}
+	cont, _, err := client.PullAndCreateContainer(docker.CreateContainerOptions{
+		Config: &docker.Config{
+			AttachStdout: true,
+			AttachStderr: true,
+			Image:        imageID,
+		},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	removeCont := func() {
+		client.RemoveContainer(docker.RemoveContainerOptions{ID: cont.ID, Force: true})
+	}
+	archiveFile, err := dockercommon.DownloadFromContainer(client, cont.ID, fmt.Sprintf("%s/%s", defaultArchivePath, defaultArchiveName))
+	if err != nil {
+		removeCont()
+		return nil, err
+	}
+	tarReader := tar.NewReader(archiveFile)
+	if _, err = tarReader.Next(); err == nil {
+		return &builder.Artifact{
+			ReadCloser: &artifactReader{Reader: tarReader, closeFn: func() {
+				archiveFile.Close()
+				removeCont()
+			}},
+			Name:        defaultArchiveName,
+			ContentType: "application/gzip",
+		}, nil
+	}
+	archiveFile.Close()
+	exporter, ok := client.(containerExporter)
+	if !ok {
+		removeCont()
+		return nil, builder.ErrArtifactNotFound
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(exporter.ExportContainer(docker.ExportContainerOptions{
+			ID:           cont.ID,
+			OutputStream: writer,
+			Context:      ctx,
+		}))
+	}()
+	return &builder.Artifact{
+		ReadCloser: &artifactReader{Reader: reader, closeFn: func() {
+			reader.Close()
+			removeCont()
+		}},
+		Name:        fmt.Sprintf("%s-v%d.tar", app.GetName(), version.Version()),
+		ContentType: "application/x-tar",
+	}, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) newArtifactVersion(c *check.C) (*app.App, appTypes.AppVersion) {
+	err := s.provisioner.AddNode(context.TODO(), provision.AddNodeOptions{Address: s.server.URL()})
+	c.Assert(err, check.IsNil)
+	a := &app.App{Name: "myapp", Platform: "whitespace", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version, err := servicemanager.AppVersion.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{App: a})
+	c.Assert(err, check.IsNil)
+	err = version.CommitBuildImage()
+	c.Assert(err, check.IsNil)
+	err = version.CommitBaseImage()
+	c.Assert(err, check.IsNil)
+	err = version.CommitSuccessful()
+	c.Assert(err, check.IsNil)
+	return a, version
+}
+
+func (s *S) TestBuilderArtifact(c *check.C) {
+	a, version := s.newArtifactVersion(c)
+	s.server.CustomHandler("/containers/[^/]+/archive$", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Assert(r.URL.Query().Get("path"), check.Equals, "/home/application/archive.tar.gz")
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		tw.WriteHeader(&tar.Header{Name: "archive.tar.gz", Mode: 0644, Size: int64(len("my upload data"))})
+		tw.Write([]byte("my upload data"))
+		tw.Close()
+		w.Header().Set("Content-Type", "application/x-tar")
+		w.Write(buf.Bytes())
+	}))
+	artifact, err := s.b.Artifact(context.TODO(), s.provisioner, a, version)
+	c.Assert(err, check.IsNil)
+	c.Assert(artifact.Name, check.Equals, "archive.tar.gz")
+	c.Assert(artifact.ContentType, check.Equals, "application/gzip")
+	data, err := ioutil.ReadAll(artifact)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "my upload data")
+	artifact.Close()
+}
+
+func (s *S) TestBuilderArtifactExportsImage(c *check.C) {
+	a, version := s.newArtifactVersion(c)
+	s.server.CustomHandler("/containers/[^/]+/export$", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-tar")
+		w.Write([]byte("exported fs"))
+	}))
+	artifact, err := s.b.Artifact(context.TODO(), s.provisioner, a, version)
+	c.Assert(err, check.IsNil)
+	c.Assert(artifact.Name, check.Equals, "myapp-v1.tar")
+	c.Assert(artifact.ContentType, check.Equals, "application/x-tar")
+	data, err := ioutil.ReadAll(artifact)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "exported fs")
+	artifact.Close()
+}
@@ -88,6 +88,8 @@ func (b *dockerBuilder) Build(ctx context.Context, prov provision.BuilderDeploy,
 	if err != nil {
 		return nil, err
 	}
+	ctx, cancel := evt.CancelableContext(ctx)
+	defer cancel()
 	var tarFile io.ReadCloser
 	if opts.ArchiveFile != nil && opts.ArchiveSize != 0 {
 		tarFile = dockercommon.AddDeployTarFile(opts.ArchiveFile, opts.ArchiveSize, defaultArchiveName)
@@ -122,11 +124,13 @@ func imageBuild(ctx context.Context, client provision.BuilderDockerClient, app p
 	fmt.Fprintln(evt, "---- Getting process from image ----")
 	cmd := generateCatCommand([]string{procfileFileName}, dirPaths)
 	var procfileBuf bytes.Buffer
-	containerID, err := runCommandInContainer(client, evt, imageID, cmd, app, &procfileBuf, nil)
+	donePull := evt.StartStage(provision.DeployStagePull)
+	containerID, err := runCommandInContainer(ctx, client, evt, imageID, cmd, app, &procfileBuf, nil)
 	defer removeContainer(client, containerID)
 	if err != nil {
 		return nil, err
 	}
+	donePull()
 	fmt.Fprintf(evt, "---- Inspecting image %q ----\n", imageID)
 	imageInspect, err := client.InspectImage(imageID)
 	if err != nil {
@@ -147,12 +151,12 @@ func imageBuild(ctx context.Context, client provision.BuilderDockerClient, app p
 		fmt.Fprintf(evt, "  ---> Process %q found with commands: %q\n", k, v)
 	}
 	fmt.Fprintln(evt, "---- Getting tsuru.yaml from image ----")
-	yaml, containerID, err := loadTsuruYaml(client, app, imageID, evt)
+	yaml, containerID, err := loadTsuruYaml(ctx, client, app, imageID, evt)
 	defer removeContainer(client, containerID)
 	if err != nil {
 		return nil, err
 	}
-	containerID, err = runBuildHooks(client, app, imageID, evt, yaml)
+	containerID, err = runBuildHooks(ctx, client, app, imageID, evt, yaml)
 	defer removeContainer(client, containerID)
 	if err != nil {
 		return nil, err
@@ -172,6 +176,9 @@ func imageBuild(ctx context.Context, client provision.BuilderDockerClient, app p
 		versionData.ExposedPorts[i] = string(k)
 		i++
 	}
+	if imageInspect.Architecture != "" {
+		versionData.Architectures = []string{imageInspect.Architecture}
+	}
 	err = newVersion.AddData(versionData)
 	if err != nil {
 		return nil, err
@@ -209,11 +216,14 @@ func pushImageToRegistry(ctx context.Context, client provision.BuilderDockerClie
 		OutputStream:      &tsuruIo.DockerErrorCheckWriter{W: evt},
 		InactivityTimeout: net.StreamInactivityTimeout,
 		RawJSONStream:     true,
+		Context:           ctx,
 	}
-	err = client.PushImage(pushOpts, dockercommon.RegistryAuthConfig(newBaseImage))
+	donePush := evt.StartStage(provision.DeployStagePush)
+	err = client.PushImage(pushOpts, dockercommon.RegistryAuthConfig(app.GetPool(), newBaseImage))
 	if err != nil {
-		return nil, err
+		return nil, canceledError(ctx, err)
 	}
+	donePush()
 	err = newVersion.CommitBaseImage()
 	if err != nil {
 		return nil, err
@@ -221,10 +231,10 @@ func pushImageToRegistry(ctx context.Context, client provision.BuilderDockerClie
 	return newVersion, nil
 }
 
-func loadTsuruYaml(client provision.BuilderDockerClient, app provision.App, imageID string, evt *event.Event) (*provTypes.TsuruYamlData, string, error) {
+func loadTsuruYaml(ctx context.Context, client provision.BuilderDockerClient, app provision.App, imageID string, evt *event.Event) (*provTypes.TsuruYamlData, string, error) {
 	cmd := generateCatCommand(tsuruYamlFiles, dirPaths)
 	var buf bytes.Buffer
-	containerID, err := runCommandInContainer(client, evt, imageID, cmd, app, &buf, nil)
+	containerID, err := runCommandInContainer(ctx, client, evt, imageID, cmd, app, &buf, nil)
 	if err != nil {
 		return nil, containerID, err
 	}
@@ -247,14 +257,14 @@ func tsuruYamlToCustomData(yaml *provTypes.TsuruYamlData) map[string]interface{}
 	}
 }
 
-func runBuildHooks(client provision.BuilderDockerClient, app provision.App, imageID string, evt *event.Event, tsuruYamlData *provTypes.TsuruYamlData) (string, error) {
+func runBuildHooks(ctx context.Context, client provision.BuilderDockerClient, app provision.App, imageID string, evt *event.Event, tsuruYamlData *provTypes.TsuruYamlData) (string, error) {
 	if tsuruYamlData == nil || tsuruYamlData.Hooks == nil || len(tsuruYamlData.Hooks.Build) == 0 {
 		return "", nil
 	}
 	cmd := strings.Join(tsuruYamlData.Hooks.Build, " && ")
 	fmt.Fprintln(evt, "---- Running build hooks ----")
 	fmt.Fprintf(evt, " ---> Running %q\n", cmd)
-	containerID, err := runCommandInContainer(client, evt, imageID, cmd, app, evt, evt)
+	containerID, err := runCommandInContainer(ctx, client, evt, imageID, cmd, app, evt, evt)
 	if err != nil {
 		return containerID, err
 	}
@@ -263,15 +273,16 @@ func runBuildHooks(client provision.BuilderDockerClient, app provision.App, imag
 		Container:  containerID,
 		Repository: repo,
 		Tag:        tag,
+		Context:    ctx,
 	}
 	newImage, err := client.CommitContainer(opts)
 	if err != nil {
-		return containerID, err
+		return containerID, canceledError(ctx, err)
 	}
 	return newImage.ID, nil
 }
 
-func runCommandInContainer(client provision.BuilderDockerClient, evt *event.Event, imageID string, command string, app provision.App, stdout, stderr io.Writer) (string, error) {
+func runCommandInContainer(ctx context.Context, client provision.BuilderDockerClient, evt *event.Event, imageID string, command string, app provision.App, stdout, stderr io.Writer) (string, error) {
 	createOptions := docker.CreateContainerOptions{
 		Config: &docker.Config{
 			AttachStdout: true,
@@ -280,10 +291,11 @@ func runCommandInContainer(client provision.BuilderDockerClient, evt *event.Even
 			Entrypoint:   []string{"/bin/sh", "-c"},
 			Cmd:          []string{command},
 		},
+		Context: ctx,
 	}
 	cont, _, err := client.PullAndCreateContainer(createOptions, evt)
 	if err != nil {
-		return "", err
+		return "", canceledError(ctx, err)
 	}
 	attachOptions := docker.AttachToContainerOptions{
 		Container:    cont.ID,
@@ -304,21 +316,42 @@ func runCommandInContainer(client provision.BuilderDockerClient, evt *event.Even
 	if err != nil {
 		return cont.ID, err
 	}
-	waiter.Wait()
+	waitCh := make(chan struct{})
+	go func() {
+		waiter.Wait()
+		close(waitCh)
+	}()
+	select {
+	case <-waitCh:
+	case <-ctx.Done():
+		waiter.Close()
+		return cont.ID, canceledError(ctx, ctx.Err())
+	}
 	return cont.ID, nil
 }
 
+// removeContainer forcibly removes the container, as it may still be running
+// when the build is aborted.
 func removeContainer(client provision.BuilderDockerClient, containerID string) error {
 	if containerID == "" {
 		return nil
 	}
 	opts := docker.RemoveContainerOptions{
 		ID:    containerID,
-		Force: false,
+		Force: true,
 	}
 	return client.RemoveContainer(opts)
 }
 
+// canceledError returns ErrDeployCanceled when err was caused by ctx being
+// canceled, which happens when the deploy event is canceled.
+func canceledError(ctx context.Context, err error) error {
+	if err != nil && ctx != nil && ctx.Err() == context.Canceled {
+		return ErrDeployCanceled
+	}
+	return err
+}
+
 func downloadFromContainer(ctx context.Context, client provision.BuilderDockerClient, app provision.App, filePath string) (io.ReadCloser, *docker.Container, error) {
 	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, app)
 	if err != nil {
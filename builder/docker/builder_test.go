@@ -25,6 +25,7 @@ import (
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/provisiontest"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	check "gopkg.in/check.v1"
 )
@@ -712,3 +713,14 @@ func (s *S) TestBuilderImageBuilded(c *check.C) {
 	c.Assert(version.VersionInfo().DeployImage, check.Equals, testBaseImage)
 	c.Assert(bopts.IsTsuruBuilderImage, check.Equals, true)
 }
+
+func (s *S) TestRunCommandInContainerCanceled(c *check.C) {
+	client, err := docker.NewClient(s.server.URL())
+	c.Assert(err, check.IsNil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a := provisiontest.NewFakeApp("myapp", "python", 1)
+	containerID, err := runCommandInContainer(ctx, builderClient(client), nil, "tsuru/python", "ls", a, ioutil.Discard, nil)
+	c.Assert(err, check.Equals, ErrDeployCanceled)
+	c.Assert(containerID, check.Equals, "")
+}
@@ -118,6 +118,9 @@ func imageBuild(ctx context.Context, client provision.BuilderKubeClient, a provi
 		versionData.ExposedPorts[i] = string(k)
 		i++
 	}
+	if inspectData.Image.Architecture != "" {
+		versionData.Architectures = []string{inspectData.Image.Architecture}
+	}
 	err = newVersion.AddData(versionData)
 	if err != nil {
 		return nil, err
@@ -14,11 +14,13 @@ import (
 
 var _ Builder = &MockBuilder{}
 var _ PlatformBuilder = &MockBuilder{}
+var _ ArtifactBuilder = &MockBuilder{}
 
 type MockBuilder struct {
 	OnBuild          func(provision.BuilderDeploy, provision.App, *event.Event, *BuildOpts) (appTypes.AppVersion, error)
 	OnPlatformBuild  func(appTypes.PlatformOptions) ([]string, error)
 	OnPlatformRemove func(string) error
+	OnArtifact       func(provision.BuilderDeploy, provision.App, appTypes.AppVersion) (*Artifact, error)
 }
 
 func (b *MockBuilder) Build(ctx context.Context, p provision.BuilderDeploy, app provision.App, evt *event.Event, opts *BuildOpts) (appTypes.AppVersion, error) {
@@ -41,3 +43,10 @@ func (b *MockBuilder) PlatformRemove(ctx context.Context, name string) error {
 	}
 	return b.OnPlatformRemove(name)
 }
+
+func (b *MockBuilder) Artifact(ctx context.Context, p provision.BuilderDeploy, app provision.App, version appTypes.AppVersion) (*Artifact, error) {
+	if b.OnArtifact == nil {
+		return nil, ErrArtifactNotFound
+	}
+	return b.OnArtifact(p, app, version)
+}
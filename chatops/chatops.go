@@ -0,0 +1,200 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package chatops implements the building blocks for the slash command
+// endpoint used by chat platforms like Slack and Mattermost: request
+// verification, command parsing, the mapping between chat users and tsuru
+// users and the confirmations required by destructive commands.
+package chatops
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+const (
+	slackSignatureHeader = "X-Slack-Signature"
+	slackTimestampHeader = "X-Slack-Request-Timestamp"
+	slackSignaturePrefix = "v0"
+
+	maxRequestAge = 5 * time.Minute
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid chatops request signature")
+	ErrNotConfigured    = errors.New("chatops is not configured")
+	ErrUnknownCommand   = errors.New("unknown command")
+)
+
+var now = time.Now
+
+// VerifyRequest checks whether a chat request is authentic. Requests carrying
+// Slack signature headers are validated against chatops:signing-secret,
+// other requests must send the token configured in chatops:token in the
+// "token" form field, as done by Mattermost.
+func VerifyRequest(header http.Header, body []byte, formToken string) error {
+	if header.Get(slackSignatureHeader) != "" {
+		secret, _ := config.GetString("chatops:signing-secret")
+		if secret == "" {
+			return ErrNotConfigured
+		}
+		return verifySlackSignature(secret, header, body)
+	}
+	token, _ := config.GetString("chatops:token")
+	if token == "" {
+		return ErrNotConfigured
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(formToken)) != 1 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func verifySlackSignature(secret string, header http.Header, body []byte) error {
+	timestamp := header.Get(slackTimestampHeader)
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	age := now().Sub(time.Unix(ts, 0))
+	if age > maxRequestAge || age < -maxRequestAge {
+		return ErrInvalidSignature
+	}
+	expected := SlackSignature(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(slackSignatureHeader))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SlackSignature returns the signature Slack sends for a request with the
+// given timestamp and body.
+func SlackSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s:%s:", slackSignaturePrefix, timestamp)
+	mac.Write(body)
+	return slackSignaturePrefix + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type CommandName string
+
+const (
+	CommandHelp     CommandName = "help"
+	CommandLink     CommandName = "link"
+	CommandStatus   CommandName = "status"
+	CommandDeploy   CommandName = "deploy"
+	CommandRollback CommandName = "rollback"
+	CommandScale    CommandName = "scale"
+	CommandConfirm  CommandName = "confirm"
+	CommandCancel   CommandName = "cancel"
+)
+
+// Command is a parsed chat command.
+type Command struct {
+	Name    CommandName
+	App     string
+	Image   string
+	Version string
+	Process string
+	Units   int
+	Code    string
+}
+
+var usages = map[CommandName]string{
+	CommandStatus:   "status <app>",
+	CommandDeploy:   "deploy <app> <image>",
+	CommandRollback: "rollback <app> <version or image>",
+	CommandScale:    "scale <app> <process> <units>",
+	CommandConfirm:  "confirm <code>",
+	CommandCancel:   "cancel <code>",
+	CommandLink:     "link",
+}
+
+// Usage returns the help text listing the available commands.
+func Usage() string {
+	return strings.Join([]string{
+		"Available commands:",
+		"  " + usages[CommandStatus],
+		"  " + usages[CommandDeploy],
+		"  " + usages[CommandRollback],
+		"  " + usages[CommandScale],
+		"  " + usages[CommandLink],
+	}, "\n")
+}
+
+func usageError(name CommandName) error {
+	return errors.Errorf("usage: %s", usages[name])
+}
+
+// ParseCommand parses the text sent by the chat platform.
+func ParseCommand(text string) (*Command, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return &Command{Name: CommandHelp}, nil
+	}
+	cmd := &Command{Name: CommandName(strings.ToLower(fields[0]))}
+	args := fields[1:]
+	switch cmd.Name {
+	case CommandHelp, CommandLink:
+	case CommandStatus:
+		if len(args) != 1 {
+			return nil, usageError(cmd.Name)
+		}
+		cmd.App = args[0]
+	case CommandDeploy:
+		if len(args) != 2 {
+			return nil, usageError(cmd.Name)
+		}
+		cmd.App, cmd.Image = args[0], args[1]
+	case CommandRollback:
+		if len(args) != 2 {
+			return nil, usageError(cmd.Name)
+		}
+		cmd.App, cmd.Version = args[0], args[1]
+	case CommandScale:
+		if len(args) != 3 {
+			return nil, usageError(cmd.Name)
+		}
+		units, err := strconv.Atoi(args[2])
+		if err != nil || units < 0 {
+			return nil, usageError(cmd.Name)
+		}
+		cmd.App, cmd.Process, cmd.Units = args[0], args[1], units
+	case CommandConfirm, CommandCancel:
+		if len(args) != 1 {
+			return nil, usageError(cmd.Name)
+		}
+		cmd.Code = args[0]
+	default:
+		return nil, ErrUnknownCommand
+	}
+	return cmd, nil
+}
+
+// String returns the command in the format accepted by ParseCommand.
+func (c *Command) String() string {
+	switch c.Name {
+	case CommandStatus:
+		return fmt.Sprintf("%s %s", c.Name, c.App)
+	case CommandDeploy:
+		return fmt.Sprintf("%s %s %s", c.Name, c.App, c.Image)
+	case CommandRollback:
+		return fmt.Sprintf("%s %s %s", c.Name, c.App, c.Version)
+	case CommandScale:
+		return fmt.Sprintf("%s %s %s %d", c.Name, c.App, c.Process, c.Units)
+	case CommandConfirm, CommandCancel:
+		return fmt.Sprintf("%s %s", c.Name, c.Code)
+	}
+	return string(c.Name)
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatops
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("chatops")
+	now = time.Now
+}
+
+func (s *S) TestParseCommand(c *check.C) {
+	tests := []struct {
+		text     string
+		expected *Command
+		err      string
+	}{
+		{text: "", expected: &Command{Name: CommandHelp}},
+		{text: "help", expected: &Command{Name: CommandHelp}},
+		{text: "link", expected: &Command{Name: CommandLink}},
+		{text: "status myapp", expected: &Command{Name: CommandStatus, App: "myapp"}},
+		{text: "Deploy myapp tsuru/python:latest", expected: &Command{Name: CommandDeploy, App: "myapp", Image: "tsuru/python:latest"}},
+		{text: "rollback myapp v3", expected: &Command{Name: CommandRollback, App: "myapp", Version: "v3"}},
+		{text: "scale  myapp web 3", expected: &Command{Name: CommandScale, App: "myapp", Process: "web", Units: 3}},
+		{text: "confirm abc", expected: &Command{Name: CommandConfirm, Code: "abc"}},
+		{text: "cancel abc", expected: &Command{Name: CommandCancel, Code: "abc"}},
+		{text: "status", err: `usage: status <app>`},
+		{text: "scale myapp web -1", err: `usage: scale <app> <process> <units>`},
+		{text: "scale myapp web x", err: `usage: scale <app> <process> <units>`},
+		{text: "destroy myapp", err: `unknown command`},
+	}
+	for _, tt := range tests {
+		cmd, err := ParseCommand(tt.text)
+		if tt.err != "" {
+			c.Assert(err, check.ErrorMatches, tt.err, check.Commentf("text: %q", tt.text))
+			continue
+		}
+		c.Assert(err, check.IsNil, check.Commentf("text: %q", tt.text))
+		c.Assert(cmd, check.DeepEquals, tt.expected, check.Commentf("text: %q", tt.text))
+	}
+}
+
+func (s *S) TestCommandStringRoundTrip(c *check.C) {
+	cmds := []*Command{
+		{Name: CommandStatus, App: "myapp"},
+		{Name: CommandDeploy, App: "myapp", Image: "img:v1"},
+		{Name: CommandRollback, App: "myapp", Version: "2"},
+		{Name: CommandScale, App: "myapp", Process: "worker", Units: 0},
+	}
+	for _, cmd := range cmds {
+		parsed, err := ParseCommand(cmd.String())
+		c.Assert(err, check.IsNil)
+		c.Assert(parsed, check.DeepEquals, cmd)
+	}
+}
+
+func (s *S) TestVerifyRequestSlackSignature(c *check.C) {
+	config.Set("chatops:signing-secret", "s3cr3t")
+	body := []byte("team_id=T1&user_id=U1&text=status+myapp")
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", ts)
+	header.Set("X-Slack-Signature", SlackSignature("s3cr3t", ts, body))
+	c.Assert(VerifyRequest(header, body, ""), check.IsNil)
+	header.Set("X-Slack-Signature", SlackSignature("other", ts, body))
+	c.Assert(VerifyRequest(header, body, ""), check.Equals, ErrInvalidSignature)
+}
+
+func (s *S) TestVerifyRequestSlackSignatureExpired(c *check.C) {
+	config.Set("chatops:signing-secret", "s3cr3t")
+	body := []byte("text=status+myapp")
+	ts := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", ts)
+	header.Set("X-Slack-Signature", SlackSignature("s3cr3t", ts, body))
+	c.Assert(VerifyRequest(header, body, ""), check.Equals, ErrInvalidSignature)
+}
+
+func (s *S) TestVerifyRequestToken(c *check.C) {
+	config.Set("chatops:token", "mytoken")
+	c.Assert(VerifyRequest(http.Header{}, nil, "mytoken"), check.IsNil)
+	c.Assert(VerifyRequest(http.Header{}, nil, "other"), check.Equals, ErrInvalidSignature)
+}
+
+func (s *S) TestVerifyRequestNotConfigured(c *check.C) {
+	c.Assert(VerifyRequest(http.Header{}, nil, "mytoken"), check.Equals, ErrNotConfigured)
+	header := http.Header{}
+	header.Set("X-Slack-Signature", "v0=abc")
+	c.Assert(VerifyRequest(header, nil, ""), check.Equals, ErrNotConfigured)
+}
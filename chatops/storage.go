@@ -0,0 +1,207 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatops
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	dbStorage "github.com/tsuru/tsuru/db/storage"
+)
+
+const (
+	linkCodeTTL     = 15 * time.Minute
+	confirmationTTL = 5 * time.Minute
+)
+
+var (
+	ErrUserNotLinked        = errors.New("chat user is not linked to a tsuru user")
+	ErrInvalidLinkCode      = errors.New("invalid or expired link code")
+	ErrConfirmationNotFound = errors.New("confirmation not found or expired")
+)
+
+// ChatUser identifies a user in a chat platform.
+type ChatUser struct {
+	TeamID string `bson:"teamid"`
+	UserID string `bson:"userid"`
+}
+
+func (u ChatUser) key() string {
+	return u.TeamID + "/" + u.UserID
+}
+
+type userLink struct {
+	ID    string `bson:"_id"`
+	Email string `bson:"email"`
+}
+
+type linkCode struct {
+	Code     string    `bson:"_id"`
+	User     ChatUser  `bson:"user"`
+	ExpireAt time.Time `bson:"expireat"`
+}
+
+// Confirmation is a destructive command waiting for the user confirmation.
+type Confirmation struct {
+	Code     string    `bson:"_id"`
+	User     ChatUser  `bson:"user"`
+	Command  string    `bson:"command"`
+	ExpireAt time.Time `bson:"expireat"`
+}
+
+func expiringCollection(conn *db.Storage, name string) *dbStorage.Collection {
+	coll := conn.Collection(name)
+	coll.EnsureIndex(mgo.Index{Key: []string{"expireat"}, ExpireAfter: time.Second})
+	return coll
+}
+
+func usersCollection(conn *db.Storage) *dbStorage.Collection {
+	return conn.Collection("chatops_users")
+}
+
+func linkCodesCollection(conn *db.Storage) *dbStorage.Collection {
+	return expiringCollection(conn, "chatops_link_codes")
+}
+
+func confirmationsCollection(conn *db.Storage) *dbStorage.Collection {
+	return expiringCollection(conn, "chatops_confirmations")
+}
+
+func generateCode() (string, error) {
+	data := make([]byte, 8)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// UserEmail returns the email of the tsuru user linked to the chat user.
+func UserEmail(user ChatUser) (string, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	var link userLink
+	err = usersCollection(conn).FindId(user.key()).One(&link)
+	if err == mgo.ErrNotFound {
+		return "", ErrUserNotLinked
+	}
+	return link.Email, err
+}
+
+// NewLinkCode creates a short lived code that must be sent to the tsuru API
+// by an authenticated user in order to link it to the chat user.
+func NewLinkCode(user ChatUser) (string, error) {
+	code, err := generateCode()
+	if err != nil {
+		return "", err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	err = linkCodesCollection(conn).Insert(linkCode{
+		Code:     code,
+		User:     user,
+		ExpireAt: now().Add(linkCodeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// Link associates the chat user that requested the code with the given tsuru
+// user email. The code can only be used once.
+func Link(code, email string) (*ChatUser, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var lc linkCode
+	_, err = linkCodesCollection(conn).Find(bson.M{
+		"_id":      code,
+		"expireat": bson.M{"$gt": now()},
+	}).Apply(mgo.Change{Remove: true}, &lc)
+	if err == mgo.ErrNotFound {
+		return nil, ErrInvalidLinkCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = usersCollection(conn).UpsertId(lc.User.key(), userLink{ID: lc.User.key(), Email: email})
+	if err != nil {
+		return nil, err
+	}
+	return &lc.User, nil
+}
+
+// Unlink removes all chat users linked to the tsuru user email.
+func Unlink(email string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = usersCollection(conn).RemoveAll(bson.M{"email": email})
+	return err
+}
+
+// NewConfirmation stores a command that will only be executed after the chat
+// user confirms it.
+func NewConfirmation(user ChatUser, cmd *Command) (*Confirmation, error) {
+	code, err := generateCode()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	confirmation := Confirmation{
+		Code:     code,
+		User:     user,
+		Command:  cmd.String(),
+		ExpireAt: now().Add(confirmationTTL),
+	}
+	err = confirmationsCollection(conn).Insert(confirmation)
+	if err != nil {
+		return nil, err
+	}
+	return &confirmation, nil
+}
+
+// PopConfirmation removes and returns the command waiting for confirmation.
+// Only the chat user that issued the command may confirm it.
+func PopConfirmation(user ChatUser, code string) (*Command, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var confirmation Confirmation
+	_, err = confirmationsCollection(conn).Find(bson.M{
+		"_id":      code,
+		"user":     user,
+		"expireat": bson.M{"$gt": now()},
+	}).Apply(mgo.Change{Remove: true}, &confirmation)
+	if err == mgo.ErrNotFound {
+		return nil, ErrConfirmationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseCommand(confirmation.Command)
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tsuru/gnuflag"
+	"github.com/tsuru/tsuru/api"
+	"github.com/tsuru/tsuru/cmd"
+	"github.com/tsuru/tsuru/db/backup"
+	"github.com/tsuru/tsuru/migration"
+)
+
+type exportCmd struct {
+	fs          *gnuflag.FlagSet
+	output      string
+	credentials bool
+}
+
+func (*exportCmd) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "export",
+		Usage: "export [-o/--output file] [--credentials]",
+		Desc: `Exports the state of tsuru, stored in MongoDB, to a gzipped tarball, written
+to the standard output by default. Writes to MongoDB are blocked with fsyncLock
+during the export, for a consistent snapshot. Event logs are not exported.
+Credentials, like user passwords, sessions and service secrets, are left out
+unless the --credentials flag is informed.`,
+	}
+}
+
+func (c *exportCmd) Run(context *cmd.Context, client *cmd.Client) error {
+	var w io.Writer = context.Stdout
+	if c.output != "" {
+		f, err := os.OpenFile(c.output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	manifest, err := backup.Export(w, backup.ExportOptions{
+		TsuruVersion: api.Version,
+		Databases:    backup.Databases(),
+		Credentials:  c.credentials,
+	})
+	if err != nil {
+		return err
+	}
+	for _, database := range manifest.Databases {
+		fmt.Fprintf(context.Stderr, "Exported %d collections from %s.\n", len(database.Collections), database.Name)
+	}
+	return nil
+}
+
+func (c *exportCmd) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("export", gnuflag.ExitOnError)
+		outputMsg := "The file to write the archive to, instead of the standard output"
+		c.fs.StringVar(&c.output, "output", "", outputMsg)
+		c.fs.StringVar(&c.output, "o", "", outputMsg)
+		c.fs.BoolVar(&c.credentials, "credentials", false, "Include credentials, like user passwords and service secrets, in the archive")
+	}
+	return c.fs
+}
+
+type restoreCmd struct {
+	fs        *gnuflag.FlagSet
+	dry       bool
+	overwrite bool
+}
+
+func (*restoreCmd) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "restore",
+		Usage: "restore <file> [-n/--dry] [--overwrite]",
+		Desc: `Restores an archive created by tsurud export in a fresh installation. The
+archive is validated before restoring it, archives created by newer versions of
+tsuru are refused. Restoring collections that are not empty fails unless the
+--overwrite flag is informed. Run tsurud migrate after restoring an archive
+created by an older version of tsuru.`,
+		MinArgs: 1,
+		MaxArgs: 1,
+	}
+}
+
+func (c *restoreCmd) Run(context *cmd.Context, client *cmd.Client) error {
+	f, err := os.Open(context.Args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	migrations, err := migration.List()
+	if err != nil {
+		return err
+	}
+	known := make([]string, len(migrations))
+	for i, m := range migrations {
+		known[i] = m.Name
+	}
+	_, err = backup.Restore(f, backup.RestoreOptions{
+		TsuruVersion:    api.Version,
+		KnownMigrations: known,
+		Databases:       backup.Databases(),
+		DryRun:          c.dry,
+		Overwrite:       c.overwrite,
+		Writer:          context.Stdout,
+	})
+	return err
+}
+
+func (c *restoreCmd) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("restore", gnuflag.ExitOnError)
+		dryMsg := "Do not restore the archive, just validate it and print what would be restored"
+		c.fs.BoolVar(&c.dry, "dry", false, dryMsg)
+		c.fs.BoolVar(&c.dry, "n", false, dryMsg)
+		c.fs.BoolVar(&c.overwrite, "overwrite", false, "Replace the collections that are not empty")
+	}
+	return c.fs
+}
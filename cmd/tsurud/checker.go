@@ -17,8 +17,24 @@ func checkBasicConfig() error {
 }
 
 func checkDatabase() error {
-	if value, _ := config.GetString("database:driver"); value != "mongodb" && value != "" {
-		return errors.Errorf("Config error: mongodb is the only database driver currently supported")
+	driver, _ := config.GetString("database:driver")
+	switch driver {
+	case "", "mongodb":
+	case "postgres":
+		// The postgres driver only stores teams and users, apps, pools,
+		// events and the remaining data are still stored in MongoDB.
+		err := checkConfigPresent([]string{
+			"database:postgres:url",
+		}, "Config error: you should have %q key set in your config file")
+		if err != nil {
+			return err
+		}
+		return checkConfigPresent([]string{
+			"database:url",
+			"database:name",
+		}, "Config error: the postgres database driver only stores teams and users, you should also have %q key set in your config file for the remaining data, stored in mongodb")
+	default:
+		return errors.Errorf("Config error: unknown database driver %q, supported drivers are mongodb and postgres", driver)
 	}
 	return checkConfigPresent([]string{
 		"database:url",
@@ -70,9 +70,28 @@ func (s *CheckerSuite) TestCheckDatabaseConfigError(c *check.C) {
 }
 
 func (s *CheckerSuite) TestCheckDatabaseConfigDriverError(c *check.C) {
+	config.Set("database:driver", "mysql")
+	err := checkDatabase()
+	c.Assert(err, check.ErrorMatches, `Config error: unknown database driver "mysql", supported drivers are mongodb and postgres`)
+}
+
+func (s *CheckerSuite) TestCheckDatabaseConfigPostgres(c *check.C) {
 	config.Set("database:driver", "postgres")
 	err := checkDatabase()
-	c.Assert(err, check.NotNil)
+	c.Assert(err, check.ErrorMatches, `Config error: you should have "database:postgres:url" key set in your config file`)
+	config.Set("database:postgres:url", "postgres://127.0.0.1:5432/tsuru")
+	defer config.Unset("database:postgres")
+	err = checkDatabase()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *CheckerSuite) TestCheckDatabaseConfigPostgresOnly(c *check.C) {
+	config.Set("database:driver", "postgres")
+	config.Set("database:postgres:url", "postgres://127.0.0.1:5432/tsuru")
+	defer config.Unset("database:postgres")
+	config.Unset("database:url")
+	err := checkDatabase()
+	c.Assert(err, check.ErrorMatches, `Config error: the postgres database driver only stores teams and users, you should also have "database:url" key set .*`)
 }
 
 func (s *CheckerSuite) TestCheckDockerJustCheckIfProvisionerIsDocker(c *check.C) {
@@ -19,6 +19,7 @@ import (
 	_ "github.com/tsuru/tsuru/provision/docker"
 	_ "github.com/tsuru/tsuru/provision/kubernetes"
 	_ "github.com/tsuru/tsuru/storage/mongodb"
+	_ "github.com/tsuru/tsuru/storage/postgres"
 )
 
 const defaultConfigPath = "/etc/tsuru/tsuru.conf"
@@ -32,6 +33,8 @@ func buildManager() *cmd.Manager {
 	m.Register(&tsurudCommand{Command: &migrateCmd{}})
 	m.Register(&tsurudCommand{Command: createRootUserCmd{}})
 	m.Register(&tsurudCommand{Command: &migrationListCmd{}})
+	m.Register(&tsurudCommand{Command: &exportCmd{}})
+	m.Register(&tsurudCommand{Command: &restoreCmd{}})
 	return m
 }
 
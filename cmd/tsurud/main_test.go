@@ -56,3 +56,21 @@ func (s *S) TestMigrateCmdIsRegistered(c *check.C) {
 	c.Assert(ok, check.Equals, true)
 	c.Assert(migrate.Command, check.FitsTypeOf, &migrateCmd{})
 }
+
+func (s *S) TestExportCmdIsRegistered(c *check.C) {
+	manager := buildManager()
+	cmd, ok := manager.Commands["export"]
+	c.Assert(ok, check.Equals, true)
+	export, ok := cmd.(*tsurudCommand)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(export.Command, check.FitsTypeOf, &exportCmd{})
+}
+
+func (s *S) TestRestoreCmdIsRegistered(c *check.C) {
+	manager := buildManager()
+	cmd, ok := manager.Commands["restore"]
+	c.Assert(ok, check.Equals, true)
+	restore, ok := cmd.(*tsurudCommand)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(restore.Command, check.FitsTypeOf, &restoreCmd{})
+}
@@ -0,0 +1,359 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package backup exports the state of the tsuru control plane, stored in
+// MongoDB, to an archive and restores it in a fresh installation.
+//
+// The archive is a gzipped tarball with a manifest.json file and a file for
+// each collection, named <database>/<collection>.bson, holding its documents
+// concatenated in BSON, as done by mongodump.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	// FormatVersion is the version of the archive format, increased on
+	// incompatible changes.
+	FormatVersion = 1
+
+	manifestName = "manifest.json"
+
+	eventsCollectionName     = "events"
+	migrationsCollectionName = "migrations"
+)
+
+var (
+	// credentialCollections hold only credentials, like user sessions, and
+	// are not exported unless credentials are requested.
+	credentialCollections = map[string]bool{
+		"tokens":          true,
+		"password_tokens": true,
+		"saml_requests":   true,
+		"acme_challenges": true,
+	}
+
+	// credentialFields are the names of the fields, in any collection and at
+	// any level of the documents, holding credentials.
+	credentialFields = map[string]bool{
+		"password":      true,
+		"apikey":        true,
+		"token":         true,
+		"secret":        true,
+		"privatekey":    true,
+		"sshprivatekey": true,
+		"caprivatekey":  true,
+		"clientkey":     true,
+		"serverkey":     true,
+	}
+)
+
+// Manifest describes the content of an archive.
+type Manifest struct {
+	FormatVersion int                `json:"formatVersion"`
+	TsuruVersion  string             `json:"tsuruVersion"`
+	CreatedAt     time.Time          `json:"createdAt"`
+	Migrations    []string           `json:"migrations"`
+	Databases     []DatabaseManifest `json:"databases"`
+	// Redacted is set when credentials were left out of the archive.
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// DatabaseManifest lists the collections of a database in the archive.
+type DatabaseManifest struct {
+	Name        string               `json:"name"`
+	Collections []CollectionManifest `json:"collections"`
+}
+
+// CollectionManifest describes the file holding the documents of a
+// collection in the archive.
+type CollectionManifest struct {
+	Name      string      `json:"name"`
+	Documents int         `json:"documents"`
+	SHA256    string      `json:"sha256"`
+	Indexes   []mgo.Index `json:"indexes,omitempty"`
+}
+
+// Database is a MongoDB database holding tsuru state. Name identifies the
+// database in the archive, so it can be restored in a database with a
+// different address.
+type Database struct {
+	Name     string
+	URL      string
+	Database string
+}
+
+// Databases returns the databases holding tsuru state: the main database
+// and, when it's a different one, the database of the docker cluster nodes.
+func Databases() []Database {
+	url, name := db.DbConfig("")
+	dbs := []Database{{Name: "tsuru", URL: url, Database: name}}
+	clusterURL, _ := config.GetString("docker:cluster:mongo-url")
+	clusterDB, _ := config.GetString("docker:cluster:mongo-database")
+	if clusterURL != "" && clusterDB != "" && (clusterURL != url || clusterDB != name) {
+		dbs = append(dbs, Database{Name: "docker-cluster", URL: clusterURL, Database: clusterDB})
+	}
+	return dbs
+}
+
+// ExportOptions are the options used to export the archive.
+type ExportOptions struct {
+	// TsuruVersion is the version of tsuru exporting the archive.
+	TsuruVersion string
+	Databases    []Database
+	// Credentials includes user passwords and API keys, sessions, registry
+	// passwords, service secrets and other credentials in the archive. They
+	// are left out by default.
+	Credentials bool
+}
+
+// Export writes an archive with all collections of the databases to w.
+// Writes to the database servers are blocked with fsyncLock during the
+// export, so that the archive is a consistent snapshot of every collection.
+// Event logs are not exported.
+func Export(w io.Writer, opts ExportOptions) (*Manifest, error) {
+	manifest := Manifest{
+		FormatVersion: FormatVersion,
+		TsuruVersion:  opts.TsuruVersion,
+		CreatedAt:     time.Now().UTC(),
+		Migrations:    []string{},
+		Redacted:      !opts.Credentials,
+	}
+	storages := make([]*storage.Storage, len(opts.Databases))
+	for i, database := range opts.Databases {
+		strg, err := storage.Open(database.URL, database.Database)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to connect to database %q", database.Name)
+		}
+		defer strg.Close()
+		session := strg.DefaultDatabase().Session
+		err = session.FsyncLock()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to lock writes to database %q for a consistent export", database.Name)
+		}
+		defer func(name string) {
+			if unlockErr := session.FsyncUnlock(); unlockErr != nil {
+				log.Errorf("[backup] unable to unlock writes to database %q: %v", name, unlockErr)
+			}
+		}(database.Name)
+		storages[i] = strg
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for i, database := range opts.Databases {
+		dbManifest, migrations, err := exportDatabase(tw, database.Name, storages[i], opts.Credentials)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to export database %q", database.Name)
+		}
+		manifest.Databases = append(manifest.Databases, *dbManifest)
+		manifest.Migrations = append(manifest.Migrations, migrations...)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:    manifestName,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: manifest.CreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = tw.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = tw.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = gz.Close()
+	if err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+func exportDatabase(tw *tar.Writer, dbName string, strg *storage.Storage, credentials bool) (*DatabaseManifest, []string, error) {
+	mongoDB := strg.DefaultDatabase()
+	names, err := mongoDB.CollectionNames()
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(names)
+	dbManifest := DatabaseManifest{Name: dbName, Collections: []CollectionManifest{}}
+	var migrations []string
+	for _, name := range names {
+		if strings.HasPrefix(name, "system.") || (!credentials && credentialCollections[name]) {
+			continue
+		}
+		collManifest, err := exportCollection(tw, dbName, mongoDB.C(name), credentials)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "unable to export collection %q", name)
+		}
+		dbManifest.Collections = append(dbManifest.Collections, *collManifest)
+		if name == migrationsCollectionName && dbName == "tsuru" {
+			migrations, err = ranMigrations(mongoDB.C(name))
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return &dbManifest, migrations, nil
+}
+
+func ranMigrations(coll *mgo.Collection) ([]string, error) {
+	var result []struct{ Name string }
+	err := coll.Find(bson.M{"ran": true}).Select(bson.M{"name": 1}).All(&result)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(result))
+	for i, m := range result {
+		names[i] = m.Name
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// exportCollection writes the documents of the collection to a temporary
+// file, as the tar header requires the size of the file, and then copies it
+// to the archive.
+func exportCollection(tw *tar.Writer, dbName string, coll *mgo.Collection, credentials bool) (*CollectionManifest, error) {
+	tmp, err := ioutil.TempFile("", "tsuru-backup-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	query := coll.Find(nil).Snapshot()
+	if coll.Name == eventsCollectionName {
+		query = query.Select(bson.M{"log": 0, "structuredlog": 0})
+	}
+	hash := sha256.New()
+	out := io.MultiWriter(tmp, hash)
+	manifest := CollectionManifest{Name: coll.Name}
+	iter := query.Iter()
+	var doc bson.Raw
+	for iter.Next(&doc) {
+		data := doc.Data
+		if !credentials {
+			data, err = redactDocument(doc)
+			if err != nil {
+				iter.Close()
+				return nil, err
+			}
+		}
+		_, err = out.Write(data)
+		if err != nil {
+			iter.Close()
+			return nil, err
+		}
+		manifest.Documents++
+	}
+	err = iter.Close()
+	if err != nil {
+		return nil, err
+	}
+	manifest.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	indexes, err := coll.Indexes()
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		if index.Name != "_id_" {
+			manifest.Indexes = append(manifest.Indexes, index)
+		}
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:    collectionFileName(dbName, coll.Name),
+		Mode:    0600,
+		Size:    size,
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(tw, tmp)
+	if err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// redactDocument removes the credential fields of the document, and the
+// values of private env vars, at any level.
+func redactDocument(doc bson.Raw) ([]byte, error) {
+	var parsed bson.D
+	err := doc.Unmarshal(&parsed)
+	if err != nil {
+		return nil, err
+	}
+	return bson.Marshal(redactFields(parsed))
+}
+
+func redactFields(doc bson.D) bson.D {
+	private := false
+	for _, elem := range doc {
+		if strings.ToLower(elem.Name) == "public" && elem.Value == false {
+			private = true
+		}
+	}
+	result := make(bson.D, 0, len(doc))
+	for _, elem := range doc {
+		name := strings.ToLower(elem.Name)
+		if credentialFields[name] || (private && name == "value") {
+			continue
+		}
+		elem.Value = redactValue(elem.Value)
+		result = append(result, elem)
+	}
+	return result
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		return redactFields(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+		return v
+	}
+	return value
+}
+
+func collectionFileName(dbName, collName string) string {
+	return dbName + "/" + collName + ".bson"
+}
@@ -0,0 +1,232 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package backup
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db/dbtest"
+	"github.com/tsuru/tsuru/db/storage"
+	check "gopkg.in/check.v1"
+)
+
+const (
+	dbURL        = "127.0.0.1:27017"
+	sourceDBName = "tsuru_backup_test_source"
+	targetDBName = "tsuru_backup_test_target"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	source *storage.Storage
+	target *storage.Storage
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	var err error
+	s.source, err = storage.Open(dbURL, sourceDBName)
+	c.Assert(err, check.IsNil)
+	s.target, err = storage.Open(dbURL, targetDBName)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	s.source.DefaultDatabase().DropDatabase()
+	s.target.DefaultDatabase().DropDatabase()
+	s.source.Close()
+	s.target.Close()
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	dbtest.ClearAllCollections(s.source.DefaultDatabase())
+	dbtest.ClearAllCollections(s.target.DefaultDatabase())
+	src := s.source.DefaultDatabase()
+	err := src.C("apps").Insert(bson.M{"name": "myapp", "pool": "pool1"}, bson.M{"name": "otherapp", "pool": "pool2"})
+	c.Assert(err, check.IsNil)
+	err = src.C("apps").EnsureIndex(mgo.Index{Key: []string{"name"}, Unique: true})
+	c.Assert(err, check.IsNil)
+	err = src.C("events").Insert(bson.M{"kind": "app.create", "log": "some log", "structuredlog": []string{"a"}})
+	c.Assert(err, check.IsNil)
+	err = src.C("migrations").Insert(bson.M{"name": "m1", "ran": true}, bson.M{"name": "m2", "ran": false})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) databases(name string) []Database {
+	return []Database{{Name: "tsuru", URL: dbURL, Database: name}}
+}
+
+func (s *S) export(c *check.C) *bytes.Buffer {
+	var buf bytes.Buffer
+	_, err := Export(&buf, ExportOptions{TsuruVersion: "1.12.0", Databases: s.databases(sourceDBName)})
+	c.Assert(err, check.IsNil)
+	return &buf
+}
+
+func (s *S) TestExport(c *check.C) {
+	var buf bytes.Buffer
+	manifest, err := Export(&buf, ExportOptions{TsuruVersion: "1.12.0", Databases: s.databases(sourceDBName)})
+	c.Assert(err, check.IsNil)
+	c.Assert(manifest.FormatVersion, check.Equals, FormatVersion)
+	c.Assert(manifest.TsuruVersion, check.Equals, "1.12.0")
+	c.Assert(manifest.Migrations, check.DeepEquals, []string{"m1"})
+	c.Assert(manifest.Databases, check.HasLen, 1)
+	counts := map[string]int{}
+	for _, coll := range manifest.Databases[0].Collections {
+		counts[coll.Name] = coll.Documents
+	}
+	c.Assert(counts, check.DeepEquals, map[string]int{"apps": 2, "events": 1, "migrations": 2})
+}
+
+func (s *S) TestExportRedactsCredentials(c *check.C) {
+	src := s.source.DefaultDatabase()
+	err := src.C("users").Insert(bson.M{"email": "me@tsuru.io", "password": "hash", "apikey": "key"})
+	c.Assert(err, check.IsNil)
+	err = src.C("tokens").Insert(bson.M{"token": "abc"})
+	c.Assert(err, check.IsNil)
+	err = src.C("apps").Update(bson.M{"name": "myapp"}, bson.M{"$set": bson.M{"env": bson.M{
+		"PUBLIC":  bson.M{"name": "PUBLIC", "value": "1", "public": true},
+		"PRIVATE": bson.M{"name": "PRIVATE", "value": "s3cr3t", "public": false},
+	}}})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	manifest, err := Export(&buf, ExportOptions{TsuruVersion: "1.12.0", Databases: s.databases(sourceDBName)})
+	c.Assert(err, check.IsNil)
+	c.Assert(manifest.Redacted, check.Equals, true)
+	for _, coll := range manifest.Databases[0].Collections {
+		c.Assert(coll.Name, check.Not(check.Equals), "tokens")
+	}
+	_, err = Restore(&buf, RestoreOptions{
+		TsuruVersion:    "1.12.0",
+		KnownMigrations: []string{"m1", "m2"},
+		Databases:       s.databases(targetDBName),
+	})
+	c.Assert(err, check.IsNil)
+	target := s.target.DefaultDatabase()
+	var user bson.M
+	err = target.C("users").Find(nil).One(&user)
+	c.Assert(err, check.IsNil)
+	c.Assert(user, check.DeepEquals, bson.M{"_id": user["_id"], "email": "me@tsuru.io"})
+	var a struct{ Env map[string]bson.M }
+	err = target.C("apps").Find(bson.M{"name": "myapp"}).One(&a)
+	c.Assert(err, check.IsNil)
+	c.Assert(a.Env["PUBLIC"]["value"], check.Equals, "1")
+	c.Assert(a.Env["PRIVATE"], check.DeepEquals, bson.M{"name": "PRIVATE", "public": false})
+	n, err := target.C("tokens").Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 0)
+}
+
+func (s *S) TestExportWithCredentials(c *check.C) {
+	err := s.source.DefaultDatabase().C("users").Insert(bson.M{"email": "me@tsuru.io", "password": "hash"})
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	manifest, err := Export(&buf, ExportOptions{TsuruVersion: "1.12.0", Databases: s.databases(sourceDBName), Credentials: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(manifest.Redacted, check.Equals, false)
+	_, err = Restore(&buf, RestoreOptions{
+		TsuruVersion:    "1.12.0",
+		KnownMigrations: []string{"m1", "m2"},
+		Databases:       s.databases(targetDBName),
+	})
+	c.Assert(err, check.IsNil)
+	var user bson.M
+	err = s.target.DefaultDatabase().C("users").Find(nil).One(&user)
+	c.Assert(err, check.IsNil)
+	c.Assert(user["password"], check.Equals, "hash")
+}
+
+func (s *S) TestExportAndRestore(c *check.C) {
+	buf := s.export(c)
+	_, err := Restore(buf, RestoreOptions{
+		TsuruVersion:    "1.12.2",
+		KnownMigrations: []string{"m1", "m2"},
+		Databases:       s.databases(targetDBName),
+	})
+	c.Assert(err, check.IsNil)
+	target := s.target.DefaultDatabase()
+	var apps []struct{ Name, Pool string }
+	err = target.C("apps").Find(nil).Sort("name").All(&apps)
+	c.Assert(err, check.IsNil)
+	c.Assert(apps, check.DeepEquals, []struct{ Name, Pool string }{{"myapp", "pool1"}, {"otherapp", "pool2"}})
+	indexes, err := target.C("apps").Indexes()
+	c.Assert(err, check.IsNil)
+	c.Assert(indexes, check.HasLen, 2)
+	c.Assert(indexes[1].Key, check.DeepEquals, []string{"name"})
+	c.Assert(indexes[1].Unique, check.Equals, true)
+	var evt bson.M
+	err = target.C("events").Find(nil).One(&evt)
+	c.Assert(err, check.IsNil)
+	c.Assert(evt["kind"], check.Equals, "app.create")
+	c.Assert(evt["log"], check.IsNil)
+	c.Assert(evt["structuredlog"], check.IsNil)
+}
+
+func (s *S) TestRestoreDryRun(c *check.C) {
+	buf := s.export(c)
+	var out bytes.Buffer
+	_, err := Restore(buf, RestoreOptions{
+		TsuruVersion:    "1.12.0",
+		KnownMigrations: []string{"m1"},
+		Databases:       s.databases(targetDBName),
+		DryRun:          true,
+		Writer:          &out,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(out.String(), check.Matches, `(?s).*Would restore 2 documents in tsuru_backup_test_target.apps.*`)
+	n, err := s.target.DefaultDatabase().C("apps").Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 0)
+}
+
+func (s *S) TestRestoreTargetNotEmpty(c *check.C) {
+	err := s.target.DefaultDatabase().C("apps").Insert(bson.M{"name": "existing"})
+	c.Assert(err, check.IsNil)
+	buf := s.export(c)
+	opts := RestoreOptions{
+		TsuruVersion:    "1.12.0",
+		KnownMigrations: []string{"m1"},
+		Databases:       s.databases(targetDBName),
+	}
+	_, err = Restore(bytes.NewReader(buf.Bytes()), opts)
+	c.Assert(err, check.ErrorMatches, `.*apps: target collections are not empty.*`)
+	opts.Overwrite = true
+	_, err = Restore(bytes.NewReader(buf.Bytes()), opts)
+	c.Assert(err, check.IsNil)
+	n, err := s.target.DefaultDatabase().C("apps").Find(bson.M{"name": "existing"}).Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 0)
+}
+
+func (s *S) TestRestoreNewerVersion(c *check.C) {
+	buf := s.export(c)
+	_, err := Restore(buf, RestoreOptions{
+		TsuruVersion:    "1.11.0",
+		KnownMigrations: []string{"m1"},
+		Databases:       s.databases(targetDBName),
+	})
+	c.Assert(err, check.ErrorMatches, `archive exported by tsuru 1.12.0 can't be restored by tsuru 1.11.0`)
+}
+
+func (s *S) TestRestoreUnknownMigration(c *check.C) {
+	buf := s.export(c)
+	_, err := Restore(buf, RestoreOptions{
+		TsuruVersion: "1.12.0",
+		Databases:    s.databases(targetDBName),
+	})
+	c.Assert(err, check.ErrorMatches, `archive has migrations unknown to tsuru 1.12.0: m1`)
+}
+
+func (s *S) TestRestoreInvalidArchive(c *check.C) {
+	_, err := Restore(bytes.NewBufferString("not an archive"), RestoreOptions{Databases: s.databases(targetDBName)})
+	c.Assert(err, check.ErrorMatches, `invalid archive.*`)
+}
@@ -0,0 +1,321 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	goVersion "github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db/storage"
+)
+
+const maxDocumentSize = 16 * 1024 * 1024
+
+var (
+	ErrMissingManifest = errors.New("invalid archive: manifest.json not found")
+	ErrTargetNotEmpty  = errors.New("target collections are not empty, use overwrite to replace them")
+)
+
+// RestoreOptions are the options used to restore an archive.
+type RestoreOptions struct {
+	// TsuruVersion is the version of tsuru restoring the archive, archives
+	// exported by newer versions are refused.
+	TsuruVersion string
+	// KnownMigrations are the names of the migrations registered in the
+	// running version of tsuru. Archives with migrations not in the list
+	// are refused.
+	KnownMigrations []string
+	Databases       []Database
+	// DryRun validates the archive and the target databases, without
+	// writing to them.
+	DryRun bool
+	// Overwrite drops non empty target collections before restoring them.
+	Overwrite bool
+	// Writer receives the progress of the restore.
+	Writer io.Writer
+}
+
+// Restore validates the archive read from r and restores its collections in
+// the databases. Collections are restored in the database with the same name
+// in opts.Databases, and must be empty unless opts.Overwrite is set.
+func Restore(r io.Reader, opts RestoreOptions) (*Manifest, error) {
+	w := opts.Writer
+	if w == nil {
+		w = ioutil.Discard
+	}
+	dir, err := ioutil.TempDir("", "tsuru-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	manifest, err := extract(r, dir)
+	if err != nil {
+		return nil, err
+	}
+	err = checkCompatibility(manifest, opts)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w, "Archive created at %s by tsuru %s.\n", manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"), manifest.TsuruVersion)
+	if manifest.TsuruVersion != opts.TsuruVersion {
+		fmt.Fprintf(w, "Archive version differs from tsuru %s, run tsurud migrate after restoring it.\n", opts.TsuruVersion)
+	}
+	if manifest.Redacted {
+		fmt.Fprintf(w, "Archive was exported without credentials: users must reset their passwords and API keys, and credentials of services, registries and integrations must be set again.\n")
+	}
+	targets := make(map[string]Database, len(opts.Databases))
+	for _, database := range opts.Databases {
+		targets[database.Name] = database
+	}
+	for _, dbManifest := range manifest.Databases {
+		target, ok := targets[dbManifest.Name]
+		if !ok {
+			return nil, errors.Errorf("no target database for %q in the archive", dbManifest.Name)
+		}
+		err = restoreDatabase(dir, dbManifest, target, opts, w)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to restore database %q", dbManifest.Name)
+		}
+	}
+	return manifest, nil
+}
+
+// extract writes the files in the archive to dir, validating them against
+// the checksums in the manifest.
+func extract(r io.Reader, dir string) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid archive")
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	checksums := map[string]string{}
+	var manifest *Manifest
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid archive")
+		}
+		if header.Name == manifestName {
+			manifest = &Manifest{}
+			err = json.NewDecoder(tr).Decode(manifest)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid manifest")
+			}
+			continue
+		}
+		name := filepath.Clean(header.Name)
+		if header.Typeflag != tar.TypeReg || filepath.IsAbs(name) || strings.HasPrefix(name, "..") {
+			return nil, errors.Errorf("invalid archive: unexpected file %q", header.Name)
+		}
+		path := filepath.Join(dir, name)
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			return nil, err
+		}
+		checksums[name], err = writeFile(path, tr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if manifest == nil {
+		return nil, ErrMissingManifest
+	}
+	for _, dbManifest := range manifest.Databases {
+		for _, collManifest := range dbManifest.Collections {
+			name := filepath.Clean(collectionFileName(dbManifest.Name, collManifest.Name))
+			checksum, ok := checksums[name]
+			if !ok {
+				return nil, errors.Errorf("invalid archive: %s not found", name)
+			}
+			if checksum != collManifest.SHA256 {
+				return nil, errors.Errorf("invalid archive: checksum mismatch in %s", name)
+			}
+		}
+	}
+	return manifest, nil
+}
+
+func writeFile(path string, r io.Reader) (string, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hash), r)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// checkCompatibility refuses archives in an unknown format, exported by a
+// newer version of tsuru or with migrations unknown to this version.
+func checkCompatibility(manifest *Manifest, opts RestoreOptions) error {
+	if manifest.FormatVersion != FormatVersion {
+		return errors.Errorf("unsupported archive format version %d, expected %d", manifest.FormatVersion, FormatVersion)
+	}
+	if manifest.TsuruVersion != "" && opts.TsuruVersion != "" {
+		archiveVersion, err := goVersion.NewVersion(manifest.TsuruVersion)
+		if err != nil {
+			return errors.Wrapf(err, "invalid tsuru version %q in the archive", manifest.TsuruVersion)
+		}
+		currentVersion, err := goVersion.NewVersion(opts.TsuruVersion)
+		if err != nil {
+			return err
+		}
+		if archiveVersion.GreaterThan(currentVersion) {
+			return errors.Errorf("archive exported by tsuru %s can't be restored by tsuru %s", manifest.TsuruVersion, opts.TsuruVersion)
+		}
+	}
+	known := make(map[string]struct{}, len(opts.KnownMigrations))
+	for _, name := range opts.KnownMigrations {
+		known[name] = struct{}{}
+	}
+	var unknown []string
+	for _, name := range manifest.Migrations {
+		if _, ok := known[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return errors.Errorf("archive has migrations unknown to tsuru %s: %s", opts.TsuruVersion, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func restoreDatabase(dir string, dbManifest DatabaseManifest, target Database, opts RestoreOptions, w io.Writer) error {
+	strg, err := storage.Open(target.URL, target.Database)
+	if err != nil {
+		return err
+	}
+	defer strg.Close()
+	mongoDB := strg.DefaultDatabase()
+	var notEmpty []string
+	for _, collManifest := range dbManifest.Collections {
+		n, err := mongoDB.C(collManifest.Name).Count()
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			notEmpty = append(notEmpty, collManifest.Name)
+		}
+	}
+	if len(notEmpty) > 0 {
+		if !opts.Overwrite {
+			return errors.Wrapf(ErrTargetNotEmpty, "%s", strings.Join(notEmpty, ", "))
+		}
+		fmt.Fprintf(w, "Collections to be replaced in %s: %s.\n", target.Database, strings.Join(notEmpty, ", "))
+	}
+	for _, collManifest := range dbManifest.Collections {
+		if opts.DryRun {
+			fmt.Fprintf(w, "Would restore %d documents in %s.%s.\n", collManifest.Documents, target.Database, collManifest.Name)
+			continue
+		}
+		coll := mongoDB.C(collManifest.Name)
+		err = coll.DropCollection()
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		path := filepath.Join(dir, filepath.FromSlash(collectionFileName(dbManifest.Name, collManifest.Name)))
+		n, err := restoreCollection(coll, path)
+		if err != nil {
+			return errors.Wrapf(err, "unable to restore collection %q", collManifest.Name)
+		}
+		for _, index := range collManifest.Indexes {
+			err = coll.EnsureIndex(index)
+			if err != nil {
+				return errors.Wrapf(err, "unable to create index %q in %q", index.Name, collManifest.Name)
+			}
+		}
+		fmt.Fprintf(w, "Restored %d documents in %s.%s.\n", n, target.Database, collManifest.Name)
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "ns not found")
+}
+
+func restoreCollection(coll *mgo.Collection, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	const batchSize = 1000
+	batch := make([]interface{}, 0, batchSize)
+	var n int
+	for {
+		doc, err := readDocument(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		batch = append(batch, doc)
+		if len(batch) == batchSize {
+			err = coll.Insert(batch...)
+			if err != nil {
+				return n, err
+			}
+			n += len(batch)
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		err = coll.Insert(batch...)
+		if err != nil {
+			return n, err
+		}
+		n += len(batch)
+	}
+	return n, nil
+}
+
+// readDocument reads the next BSON document from r. Documents start with
+// their length, as a little endian int32, including the length itself.
+func readDocument(r io.Reader) (bson.Raw, error) {
+	var header [4]byte
+	_, err := io.ReadFull(r, header[:])
+	if err != nil {
+		return bson.Raw{}, err
+	}
+	size := int(binary.LittleEndian.Uint32(header[:]))
+	if size < 5 || size > maxDocumentSize {
+		return bson.Raw{}, errors.Errorf("invalid document size %d", size)
+	}
+	data := make([]byte, size)
+	copy(data, header[:])
+	_, err = io.ReadFull(r, data[4:])
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return bson.Raw{}, err
+	}
+	return bson.Raw{Kind: 0x03, Data: data}, nil
+}
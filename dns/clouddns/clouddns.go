@@ -0,0 +1,231 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package clouddns implements a DNS driver managing records in Google Cloud
+// DNS managed zones.
+package clouddns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/dns"
+	"github.com/tsuru/tsuru/net"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	defaultURL = "https://dns.googleapis.com/dns/v1"
+	dnsScope   = "https://www.googleapis.com/auth/ndev.clouddns.readwrite"
+)
+
+func init() {
+	dns.Register("clouddns", newProvider)
+}
+
+type provider struct {
+	url          string
+	project      string
+	source       oauth2.TokenSource
+	managedZones map[string]string
+	mu           sync.Mutex
+}
+
+type resourceRecordSet struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	TTL     int64    `json:"ttl"`
+	RRDatas []string `json:"rrdatas"`
+}
+
+type change struct {
+	Additions []resourceRecordSet `json:"additions,omitempty"`
+	Deletions []resourceRecordSet `json:"deletions,omitempty"`
+}
+
+func newProvider(name, configPrefix string) (dns.Provider, error) {
+	project, err := config.GetString(configPrefix + ":project")
+	if err != nil {
+		return nil, errors.Errorf("%s:project is required", configPrefix)
+	}
+	source, err := tokenSource(configPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get google cloud credentials")
+	}
+	u, _ := config.GetString(configPrefix + ":url")
+	if u == "" {
+		u = defaultURL
+	}
+	p := &provider{
+		url:          strings.TrimRight(u, "/"),
+		project:      project,
+		source:       source,
+		managedZones: map[string]string{},
+	}
+	zones, _ := config.Get(configPrefix + ":managed-zones")
+	if m, ok := zones.(map[interface{}]interface{}); ok {
+		for zone, managed := range m {
+			zoneName, _ := zone.(string)
+			managedName, _ := managed.(string)
+			p.managedZones[dns.Canonical(zoneName)] = managedName
+		}
+	}
+	return p, nil
+}
+
+func tokenSource(configPrefix string) (oauth2.TokenSource, error) {
+	ctx := context.Background()
+	if token, _ := config.GetString(configPrefix + ":token"); token != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), nil
+	}
+	credentialsFile, _ := config.GetString(configPrefix + ":credentials-file")
+	if credentialsFile == "" {
+		return google.DefaultTokenSource(ctx, dnsScope)
+	}
+	data, err := ioutil.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := google.CredentialsFromJSON(ctx, data, dnsScope)
+	if err != nil {
+		return nil, err
+	}
+	return creds.TokenSource, nil
+}
+
+// managedZone returns the name of the managed zone serving the DNS zone,
+// either from the managed-zones config entry or looking it up by DNS name.
+func (p *provider) managedZone(ctx context.Context, zone string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if name, ok := p.managedZones[zone]; ok {
+		return name, nil
+	}
+	var result struct {
+		ManagedZones []struct {
+			Name string `json:"name"`
+		} `json:"managedZones"`
+	}
+	path := fmt.Sprintf("/projects/%s/managedZones?dnsName=%s", p.project, url.QueryEscape(zone+"."))
+	err := p.do(ctx, http.MethodGet, path, nil, &result)
+	if err != nil {
+		return "", err
+	}
+	if len(result.ManagedZones) == 0 {
+		return "", errors.Errorf("cloud dns managed zone for %q not found", zone)
+	}
+	p.managedZones[zone] = result.ManagedZones[0].Name
+	return result.ManagedZones[0].Name, nil
+}
+
+func (p *provider) current(ctx context.Context, managedZone string, record dns.Record) (*resourceRecordSet, error) {
+	var result struct {
+		RRSets []resourceRecordSet `json:"rrsets"`
+	}
+	path := fmt.Sprintf("/projects/%s/managedZones/%s/rrsets?name=%s&type=%s", p.project, managedZone, url.QueryEscape(record.Name+"."), record.Type)
+	err := p.do(ctx, http.MethodGet, path, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.RRSets) == 0 {
+		return nil, nil
+	}
+	return &result.RRSets[0], nil
+}
+
+func (p *provider) SetRecord(ctx context.Context, zone string, record dns.Record) error {
+	managedZone, err := p.managedZone(ctx, zone)
+	if err != nil {
+		return err
+	}
+	existing, err := p.current(ctx, managedZone, record)
+	if err != nil {
+		return err
+	}
+	rrset := resourceRecordSet{Name: record.Name + ".", Type: record.Type, TTL: record.TTL}
+	for _, value := range record.Values {
+		switch record.Type {
+		case dns.RecordTypeTXT:
+			value = strconv.Quote(value)
+		case dns.RecordTypeCNAME:
+			value = strings.TrimSuffix(value, ".") + "."
+		}
+		rrset.RRDatas = append(rrset.RRDatas, value)
+	}
+	c := change{Additions: []resourceRecordSet{rrset}}
+	if existing != nil {
+		c.Deletions = []resourceRecordSet{*existing}
+	}
+	return p.change(ctx, managedZone, c)
+}
+
+func (p *provider) RemoveRecord(ctx context.Context, zone string, record dns.Record) error {
+	managedZone, err := p.managedZone(ctx, zone)
+	if err != nil {
+		return err
+	}
+	existing, err := p.current(ctx, managedZone, record)
+	if err != nil || existing == nil {
+		return err
+	}
+	return p.change(ctx, managedZone, change{Deletions: []resourceRecordSet{*existing}})
+}
+
+func (p *provider) change(ctx context.Context, managedZone string, c change) error {
+	path := fmt.Sprintf("/projects/%s/managedZones/%s/changes", p.project, managedZone)
+	return p.do(ctx, http.MethodPost, path, c, nil)
+}
+
+func (p *provider) do(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&reqBody).Encode(body)
+		if err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, p.url+path, &reqBody)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	client := *net.Dial15Full60ClientNoKeepAlive
+	client.Transport = &oauth2.Transport{Source: p.source, Base: net.Dial15Full60ClientNoKeepAlive.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return errors.Errorf("cloud dns api error: %s", apiErr.Error.Message)
+		}
+		return errors.Errorf("cloud dns api error: status %d: %s", resp.StatusCode, data)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clouddns
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/dns"
+	check "gopkg.in/check.v1"
+)
+
+type S struct {
+	server  *httptest.Server
+	rrsets  []resourceRecordSet
+	changes []change
+}
+
+var _ = check.Suite(&S{})
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+func (s *S) SetUpTest(c *check.C) {
+	s.rrsets = nil
+	s.changes = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer my-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/proj/managedZones":
+			zones := []map[string]string{}
+			if r.URL.Query().Get("dnsName") == "example.com." {
+				zones = append(zones, map[string]string{"name": "example-zone"})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"managedZones": zones})
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/proj/managedZones/example-zone/rrsets":
+			c.Check(r.URL.Query().Get("name"), check.Equals, "www.example.com.")
+			json.NewEncoder(w).Encode(map[string]interface{}{"rrsets": s.rrsets})
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/proj/managedZones/example-zone/changes":
+			var ch change
+			json.NewDecoder(r.Body).Decode(&ch)
+			s.changes = append(s.changes, ch)
+			w.Write([]byte("{}"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"message": "not found"}}`))
+		}
+	}))
+	config.Set("dns:providers:gcp:project", "proj")
+	config.Set("dns:providers:gcp:token", "my-token")
+	config.Set("dns:providers:gcp:url", s.server.URL)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	s.server.Close()
+	config.Unset("dns")
+}
+
+func (s *S) TestSetRecord(c *check.C) {
+	p, err := newProvider("gcp", "dns:providers:gcp")
+	c.Assert(err, check.IsNil)
+	err = p.SetRecord(context.TODO(), "example.com", dns.Record{
+		Name:   "www.example.com",
+		Type:   dns.RecordTypeCNAME,
+		Values: []string{"myapp.router.io"},
+		TTL:    300,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.changes, check.DeepEquals, []change{{
+		Additions: []resourceRecordSet{{Name: "www.example.com.", Type: "CNAME", TTL: 300, RRDatas: []string{"myapp.router.io."}}},
+	}})
+}
+
+func (s *S) TestSetRecordReplacesExisting(c *check.C) {
+	existing := resourceRecordSet{Name: "www.example.com.", Type: "TXT", TTL: 60, RRDatas: []string{`"old"`}}
+	s.rrsets = []resourceRecordSet{existing}
+	config.Set("dns:providers:gcp:managed-zones", map[interface{}]interface{}{"example.com": "example-zone"})
+	p, err := newProvider("gcp", "dns:providers:gcp")
+	c.Assert(err, check.IsNil)
+	err = p.SetRecord(context.TODO(), "example.com", dns.Record{Name: "www.example.com", Type: dns.RecordTypeTXT, Values: []string{"new"}, TTL: 60})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.changes, check.DeepEquals, []change{{
+		Additions: []resourceRecordSet{{Name: "www.example.com.", Type: "TXT", TTL: 60, RRDatas: []string{`"new"`}}},
+		Deletions: []resourceRecordSet{existing},
+	}})
+}
+
+func (s *S) TestRemoveRecord(c *check.C) {
+	existing := resourceRecordSet{Name: "www.example.com.", Type: "CNAME", TTL: 300, RRDatas: []string{"myapp.router.io."}}
+	s.rrsets = []resourceRecordSet{existing}
+	p, err := newProvider("gcp", "dns:providers:gcp")
+	c.Assert(err, check.IsNil)
+	err = p.RemoveRecord(context.TODO(), "example.com", dns.Record{Name: "www.example.com", Type: dns.RecordTypeCNAME})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.changes, check.DeepEquals, []change{{Deletions: []resourceRecordSet{existing}}})
+}
+
+func (s *S) TestRemoveRecordNotFound(c *check.C) {
+	p, err := newProvider("gcp", "dns:providers:gcp")
+	c.Assert(err, check.IsNil)
+	err = p.RemoveRecord(context.TODO(), "example.com", dns.Record{Name: "www.example.com", Type: dns.RecordTypeCNAME})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.changes, check.HasLen, 0)
+}
+
+func (s *S) TestSetRecordManagedZoneNotFound(c *check.C) {
+	p, err := newProvider("gcp", "dns:providers:gcp")
+	c.Assert(err, check.IsNil)
+	err = p.SetRecord(context.TODO(), "example.org", dns.Record{Name: "www.example.org", Type: dns.RecordTypeCNAME})
+	c.Assert(err, check.ErrorMatches, `cloud dns managed zone for "example.org" not found`)
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dns provides an interface for managing DNS records in external
+// providers, used to point custom domains to tsuru apps.
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+const (
+	RecordTypeA     = "A"
+	RecordTypeCNAME = "CNAME"
+	RecordTypeTXT   = "TXT"
+
+	DefaultTTL = 300
+)
+
+// ErrNoProvider is returned when no configured provider manages the zone of
+// a domain.
+var ErrNoProvider = errors.New("no dns provider configured for domain")
+
+// Record is a DNS resource record set, identified by its name and type.
+type Record struct {
+	Name   string
+	Type   string
+	Values []string
+	TTL    int64
+}
+
+// Provider manages records in the zones served by a DNS provider.
+type Provider interface {
+	// SetRecord creates the record, replacing the values of an existing
+	// record with the same name and type.
+	SetRecord(ctx context.Context, zone string, record Record) error
+	// RemoveRecord removes the record, ignoring records that do not exist.
+	RemoveRecord(ctx context.Context, zone string, record Record) error
+}
+
+// ProviderFactory creates a provider reading its settings from the config
+// entries under configPrefix.
+type ProviderFactory func(name, configPrefix string) (Provider, error)
+
+var (
+	factories = map[string]ProviderFactory{}
+	providers = map[string]Provider{}
+	lock      sync.Mutex
+)
+
+// Register registers a new DNS driver, available to providers configured
+// with it in the dns:providers:<name>:driver config entry.
+func Register(driver string, factory ProviderFactory) {
+	lock.Lock()
+	defer lock.Unlock()
+	factories[driver] = factory
+}
+
+// Get returns the provider configured under dns:providers:<name>.
+func Get(name string) (Provider, error) {
+	lock.Lock()
+	defer lock.Unlock()
+	if p, ok := providers[name]; ok {
+		return p, nil
+	}
+	prefix := "dns:providers:" + name
+	driver, err := config.GetString(prefix + ":driver")
+	if err != nil {
+		return nil, errors.Errorf("dns provider %q not found", name)
+	}
+	factory, ok := factories[driver]
+	if !ok {
+		return nil, errors.Errorf("unknown dns driver %q for provider %q", driver, name)
+	}
+	p, err := factory(name, prefix)
+	if err != nil {
+		return nil, err
+	}
+	providers[name] = p
+	return p, nil
+}
+
+// Zone is a DNS zone managed by a configured provider.
+type Zone struct {
+	Name     string
+	Provider string
+}
+
+// ForDomain returns the zone of the configured provider responsible for the
+// domain, preferring the most specific zone when more than one matches.
+func ForDomain(domain string) (*Zone, error) {
+	zones, err := configuredZones()
+	if err != nil {
+		return nil, err
+	}
+	domain = Canonical(domain)
+	for _, zone := range zones {
+		if domain == zone.Name || strings.HasSuffix(domain, "."+zone.Name) {
+			return &zone, nil
+		}
+	}
+	return nil, ErrNoProvider
+}
+
+func configuredZones() ([]Zone, error) {
+	data, err := config.Get("dns:providers")
+	if err != nil {
+		return nil, nil
+	}
+	entries, ok := data.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("dns:providers must be a map")
+	}
+	var zones []Zone
+	for key := range entries {
+		name := fmt.Sprint(key)
+		names, err := config.GetList("dns:providers:" + name + ":zones")
+		if err != nil {
+			return nil, errors.Errorf("dns:providers:%s:zones is required", name)
+		}
+		for _, zone := range names {
+			zones = append(zones, Zone{Name: Canonical(zone), Provider: name})
+		}
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		if len(zones[i].Name) != len(zones[j].Name) {
+			return len(zones[i].Name) > len(zones[j].Name)
+		}
+		return zones[i].Name < zones[j].Name
+	})
+	return zones, nil
+}
+
+// Canonical returns the domain in lower case and without the trailing dot.
+func Canonical(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// Reset removes the providers created so far, forcing them to be created
+// again from the config on next use.
+func Reset() {
+	lock.Lock()
+	defer lock.Unlock()
+	providers = map[string]Provider{}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type nopProvider struct {
+	name string
+}
+
+func (p *nopProvider) SetRecord(ctx context.Context, zone string, record Record) error {
+	return nil
+}
+
+func (p *nopProvider) RemoveRecord(ctx context.Context, zone string, record Record) error {
+	return nil
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	Register("nop", func(name, configPrefix string) (Provider, error) {
+		return &nopProvider{name: name}, nil
+	})
+	config.Set("dns:providers", map[interface{}]interface{}{
+		"main": map[interface{}]interface{}{
+			"driver": "nop",
+			"zones":  []interface{}{"Example.com."},
+		},
+		"internal": map[interface{}]interface{}{
+			"driver": "nop",
+			"zones":  []interface{}{"internal.example.com", "example.org"},
+		},
+	})
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("dns")
+	Reset()
+}
+
+func (s *S) TestForDomain(c *check.C) {
+	tests := []struct {
+		domain string
+		zone   *Zone
+	}{
+		{"www.example.com", &Zone{Name: "example.com", Provider: "main"}},
+		{"Example.com.", &Zone{Name: "example.com", Provider: "main"}},
+		{"app.internal.example.com", &Zone{Name: "internal.example.com", Provider: "internal"}},
+		{"*.example.org", &Zone{Name: "example.org", Provider: "internal"}},
+		{"notexample.com", nil},
+		{"example.net", nil},
+	}
+	for _, tt := range tests {
+		zone, err := ForDomain(tt.domain)
+		if tt.zone == nil {
+			c.Check(err, check.Equals, ErrNoProvider, check.Commentf("domain %q", tt.domain))
+			continue
+		}
+		c.Check(err, check.IsNil)
+		c.Check(zone, check.DeepEquals, tt.zone, check.Commentf("domain %q", tt.domain))
+	}
+}
+
+func (s *S) TestForDomainNoProviders(c *check.C) {
+	config.Unset("dns")
+	_, err := ForDomain("www.example.com")
+	c.Assert(err, check.Equals, ErrNoProvider)
+}
+
+func (s *S) TestGet(c *check.C) {
+	p, err := Get("main")
+	c.Assert(err, check.IsNil)
+	c.Assert(p.(*nopProvider).name, check.Equals, "main")
+	other, err := Get("main")
+	c.Assert(err, check.IsNil)
+	c.Assert(other, check.Equals, p)
+}
+
+func (s *S) TestGetNotFound(c *check.C) {
+	_, err := Get("unknown")
+	c.Assert(err, check.ErrorMatches, `dns provider "unknown" not found`)
+}
+
+func (s *S) TestGetUnknownDriver(c *check.C) {
+	config.Set("dns:providers:main:driver", "invalid")
+	_, err := Get("main")
+	c.Assert(err, check.ErrorMatches, `unknown dns driver "invalid" for provider "main"`)
+}
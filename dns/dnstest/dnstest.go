@@ -0,0 +1,70 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dnstest provides a fake DNS driver, keeping records in memory.
+package dnstest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/dns"
+)
+
+// Provider is the fake provider shared by every provider configured with
+// the "fake" driver.
+var Provider = &FakeProvider{}
+
+func init() {
+	dns.Register("fake", func(name, configPrefix string) (dns.Provider, error) {
+		return Provider, nil
+	})
+}
+
+type FakeProvider struct {
+	mu      sync.Mutex
+	records map[string]dns.Record
+	// FailSet makes SetRecord fail when set.
+	FailSet bool
+}
+
+func recordKey(zone, name, rtype string) string {
+	return zone + "/" + name + "/" + rtype
+}
+
+func (p *FakeProvider) SetRecord(ctx context.Context, zone string, record dns.Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.FailSet {
+		return errors.New("fake provider failure")
+	}
+	if p.records == nil {
+		p.records = map[string]dns.Record{}
+	}
+	p.records[recordKey(zone, record.Name, record.Type)] = record
+	return nil
+}
+
+func (p *FakeProvider) RemoveRecord(ctx context.Context, zone string, record dns.Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.records, recordKey(zone, record.Name, record.Type))
+	return nil
+}
+
+// Record returns the record with the given name and type in the zone.
+func (p *FakeProvider) Record(zone, name, rtype string) (dns.Record, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r, ok := p.records[recordKey(zone, name, rtype)]
+	return r, ok
+}
+
+func (p *FakeProvider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.records = nil
+	p.FailSet = false
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rfc2136 implements a DNS driver managing records through dynamic
+// updates (RFC 2136), optionally signed with TSIG (RFC 8945).
+package rfc2136
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"hash"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/dns"
+)
+
+const (
+	opcodeUpdate = 5
+
+	typeA     = 1
+	typeCNAME = 5
+	typeSOA   = 6
+	typeTXT   = 16
+	typeTSIG  = 250
+
+	classIN  = 1
+	classANY = 255
+
+	tsigFudge      = 300
+	defaultTimeout = 10 * time.Second
+)
+
+var algorithms = map[string]struct {
+	name string
+	hash func() hash.Hash
+}{
+	"hmac-md5":    {name: "hmac-md5.sig-alg.reg.int", hash: md5.New},
+	"hmac-sha1":   {name: "hmac-sha1", hash: sha1.New},
+	"hmac-sha256": {name: "hmac-sha256", hash: sha256.New},
+	"hmac-sha512": {name: "hmac-sha512", hash: sha512.New},
+}
+
+var rcodes = []string{"NOERROR", "FORMERR", "SERVFAIL", "NXDOMAIN", "NOTIMP", "REFUSED", "YXDOMAIN", "YXRRSET", "NXRRSET", "NOTAUTH", "NOTZONE"}
+
+func init() {
+	dns.Register("rfc2136", newProvider)
+}
+
+type provider struct {
+	server    string
+	keyName   string
+	secret    []byte
+	algorithm string
+	timeout   time.Duration
+	now       func() time.Time
+}
+
+func newProvider(name, configPrefix string) (dns.Provider, error) {
+	server, err := config.GetString(configPrefix + ":server")
+	if err != nil {
+		return nil, errors.Errorf("%s:server is required", configPrefix)
+	}
+	if _, _, err = net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	p := &provider{server: server, timeout: defaultTimeout, now: time.Now}
+	if timeout, _ := config.GetDuration(configPrefix + ":timeout"); timeout > 0 {
+		p.timeout = timeout
+	}
+	p.keyName, _ = config.GetString(configPrefix + ":tsig:key-name")
+	if p.keyName == "" {
+		return p, nil
+	}
+	secret, _ := config.GetString(configPrefix + ":tsig:secret")
+	p.secret, err = base64.StdEncoding.DecodeString(secret)
+	if err != nil || len(p.secret) == 0 {
+		return nil, errors.Errorf("%s:tsig:secret must be a base64 encoded key", configPrefix)
+	}
+	p.algorithm, _ = config.GetString(configPrefix + ":tsig:algorithm")
+	if p.algorithm == "" {
+		p.algorithm = "hmac-sha256"
+	}
+	if _, ok := algorithms[p.algorithm]; !ok {
+		return nil, errors.Errorf("unsupported tsig algorithm %q", p.algorithm)
+	}
+	return p, nil
+}
+
+func (p *provider) SetRecord(ctx context.Context, zone string, record dns.Record) error {
+	rtype, err := recordType(record.Type)
+	if err != nil {
+		return err
+	}
+	msg := newUpdate(zone)
+	msg.deleteRRSet(record.Name, rtype)
+	for _, value := range record.Values {
+		var rdata []byte
+		rdata, err = encodeRData(rtype, value)
+		if err != nil {
+			return err
+		}
+		msg.addRR(record.Name, rtype, uint32(record.TTL), rdata)
+	}
+	return p.send(ctx, msg)
+}
+
+func (p *provider) RemoveRecord(ctx context.Context, zone string, record dns.Record) error {
+	rtype, err := recordType(record.Type)
+	if err != nil {
+		return err
+	}
+	msg := newUpdate(zone)
+	msg.deleteRRSet(record.Name, rtype)
+	return p.send(ctx, msg)
+}
+
+func recordType(name string) (uint16, error) {
+	switch name {
+	case dns.RecordTypeA:
+		return typeA, nil
+	case dns.RecordTypeCNAME:
+		return typeCNAME, nil
+	case dns.RecordTypeTXT:
+		return typeTXT, nil
+	}
+	return 0, errors.Errorf("unsupported record type %q", name)
+}
+
+// update is a DNS UPDATE message, holding a single zone and the update
+// section records already in wire format.
+type update struct {
+	id      uint16
+	zone    string
+	records []byte
+	count   uint16
+}
+
+func newUpdate(zone string) *update {
+	return &update{id: uint16(rand.Intn(1 << 16)), zone: zone}
+}
+
+func (u *update) deleteRRSet(name string, rtype uint16) {
+	u.appendRR(name, rtype, classANY, 0, nil)
+}
+
+func (u *update) addRR(name string, rtype uint16, ttl uint32, rdata []byte) {
+	u.appendRR(name, rtype, classIN, ttl, rdata)
+}
+
+func (u *update) appendRR(name string, rtype, class uint16, ttl uint32, rdata []byte) {
+	u.records = append(u.records, encodeName(name)...)
+	u.records = appendUint16(u.records, rtype, class)
+	u.records = appendUint32(u.records, ttl)
+	u.records = appendUint16(u.records, uint16(len(rdata)))
+	u.records = append(u.records, rdata...)
+	u.count++
+}
+
+func (u *update) pack(additional uint16) []byte {
+	msg := appendUint16(nil, u.id, opcodeUpdate<<11, 1, 0, u.count, additional)
+	msg = append(msg, encodeName(u.zone)...)
+	msg = appendUint16(msg, typeSOA, classIN)
+	return append(msg, u.records...)
+}
+
+// sign appends a TSIG record to the packed message, as described in RFC
+// 8945.
+func (p *provider) sign(msg []byte, id uint16) []byte {
+	alg := algorithms[p.algorithm]
+	keyName := encodeName(strings.ToLower(p.keyName))
+	algName := encodeName(alg.name)
+	signed := p.now().Unix()
+	timeFields := appendUint16(nil, uint16(signed>>32))
+	timeFields = appendUint32(timeFields, uint32(signed))
+	timeFields = appendUint16(timeFields, tsigFudge)
+	mac := hmac.New(alg.hash, p.secret)
+	mac.Write(msg)
+	mac.Write(keyName)
+	mac.Write(appendUint16(nil, classANY))
+	mac.Write(appendUint32(nil, 0))
+	mac.Write(algName)
+	mac.Write(timeFields)
+	mac.Write(appendUint16(nil, 0, 0))
+	sum := mac.Sum(nil)
+	rdata := append(algName, timeFields...)
+	rdata = appendUint16(rdata, uint16(len(sum)))
+	rdata = append(rdata, sum...)
+	rdata = appendUint16(rdata, id, 0, 0)
+	msg = append(msg, keyName...)
+	msg = appendUint16(msg, typeTSIG, classANY)
+	msg = appendUint32(msg, 0)
+	msg = appendUint16(msg, uint16(len(rdata)))
+	return append(msg, rdata...)
+}
+
+func (p *provider) send(ctx context.Context, u *update) error {
+	var msg []byte
+	if p.keyName == "" {
+		msg = u.pack(0)
+	} else {
+		msg = p.sign(u.pack(0), u.id)
+		binary.BigEndian.PutUint16(msg[10:], 1)
+	}
+	dialer := net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(p.timeout))
+	_, err = conn.Write(append(appendUint16(nil, uint16(len(msg))), msg...))
+	if err != nil {
+		return err
+	}
+	var size uint16
+	err = binary.Read(conn, binary.BigEndian, &size)
+	if err != nil {
+		return errors.Wrap(err, "unable to read dns update response")
+	}
+	rsp := make([]byte, size)
+	_, err = io.ReadFull(conn, rsp)
+	if err != nil {
+		return errors.Wrap(err, "unable to read dns update response")
+	}
+	if len(rsp) < 12 || binary.BigEndian.Uint16(rsp) != u.id {
+		return errors.New("invalid dns update response")
+	}
+	rcode := int(binary.BigEndian.Uint16(rsp[2:]) & 0xf)
+	if rcode != 0 {
+		name := "UNKNOWN"
+		if rcode < len(rcodes) {
+			name = rcodes[rcode]
+		}
+		return errors.Errorf("dns update for zone %q failed: %s", u.zone, name)
+	}
+	return nil
+}
+
+func encodeName(name string) []byte {
+	var result []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		result = append(result, byte(len(label)))
+		result = append(result, label...)
+	}
+	return append(result, 0)
+}
+
+func encodeRData(rtype uint16, value string) ([]byte, error) {
+	switch rtype {
+	case typeA:
+		ip := net.ParseIP(value).To4()
+		if ip == nil {
+			return nil, errors.Errorf("invalid ipv4 address %q", value)
+		}
+		return ip, nil
+	case typeCNAME:
+		return encodeName(value), nil
+	}
+	var rdata []byte
+	for len(value) > 255 {
+		rdata = append(rdata, 255)
+		rdata = append(rdata, value[:255]...)
+		value = value[255:]
+	}
+	rdata = append(rdata, byte(len(value)))
+	return append(rdata, value...), nil
+}
+
+func appendUint16(b []byte, values ...uint16) []byte {
+	for _, v := range values {
+		b = append(b, byte(v>>8), byte(v))
+	}
+	return b
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rfc2136
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/dns"
+	check "gopkg.in/check.v1"
+)
+
+type S struct {
+	listener net.Listener
+	received chan []byte
+	rcode    uint16
+}
+
+var _ = check.Suite(&S{})
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+func (s *S) SetUpTest(c *check.C) {
+	var err error
+	s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	s.received = make(chan []byte, 1)
+	s.rcode = 0
+	go s.serve()
+	config.Set("dns:providers:bind:server", s.listener.Addr().String())
+	config.Set("dns:providers:bind:tsig:key-name", "Tsuru-Key")
+	config.Set("dns:providers:bind:tsig:secret", "c2VjcmV0")
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	s.listener.Close()
+	config.Unset("dns")
+}
+
+func (s *S) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		var size uint16
+		binary.Read(conn, binary.BigEndian, &size)
+		msg := make([]byte, size)
+		io.ReadFull(conn, msg)
+		s.received <- msg
+		rsp := appendUint16(nil, binary.BigEndian.Uint16(msg), 1<<15|opcodeUpdate<<11|s.rcode, 0, 0, 0, 0)
+		conn.Write(append(appendUint16(nil, uint16(len(rsp))), rsp...))
+		conn.Close()
+	}
+}
+
+func (s *S) newProvider(c *check.C) *provider {
+	p, err := newProvider("bind", "dns:providers:bind")
+	c.Assert(err, check.IsNil)
+	prov := p.(*provider)
+	prov.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return prov
+}
+
+func (s *S) TestSetRecord(c *check.C) {
+	p := s.newProvider(c)
+	err := p.SetRecord(context.TODO(), "example.com", dns.Record{
+		Name:   "www.example.com",
+		Type:   dns.RecordTypeCNAME,
+		Values: []string{"myapp.router.io"},
+		TTL:    300,
+	})
+	c.Assert(err, check.IsNil)
+	msg := <-s.received
+	c.Assert(binary.BigEndian.Uint16(msg[2:]), check.Equals, uint16(opcodeUpdate<<11))
+	counts := []uint16{binary.BigEndian.Uint16(msg[4:]), binary.BigEndian.Uint16(msg[6:]), binary.BigEndian.Uint16(msg[8:]), binary.BigEndian.Uint16(msg[10:])}
+	c.Assert(counts, check.DeepEquals, []uint16{1, 0, 2, 1})
+	var expected []byte
+	expected = append(expected, "\x07example\x03com\x00\x00\x06\x00\x01"...)
+	expected = append(expected, "\x03www\x07example\x03com\x00\x00\x05\x00\xff\x00\x00\x00\x00\x00\x00"...)
+	expected = append(expected, "\x03www\x07example\x03com\x00\x00\x05\x00\x01\x00\x00\x01\x2c\x00\x11\x05myapp\x06router\x02io\x00"...)
+	c.Assert(msg[12:12+len(expected)], check.DeepEquals, expected)
+	unsigned := append([]byte{}, msg[:12+len(expected)]...)
+	unsigned[11] = 0
+	tsig := msg[12+len(expected):]
+	keyName := []byte("\x09tsuru-key\x00")
+	c.Assert(tsig[:len(keyName)], check.DeepEquals, keyName)
+	rdata := tsig[len(keyName)+10:]
+	algName := []byte("\x0bhmac-sha256\x00")
+	c.Assert(rdata[:len(algName)], check.DeepEquals, algName)
+	timeFields := rdata[len(algName) : len(algName)+8]
+	c.Assert(timeFields, check.DeepEquals, []byte{0, 0, 0x65, 0x53, 0xf1, 0x00, 0x01, 0x2c})
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(unsigned)
+	mac.Write(keyName)
+	mac.Write([]byte{0, 0xff, 0, 0, 0, 0})
+	mac.Write(algName)
+	mac.Write(timeFields)
+	mac.Write([]byte{0, 0, 0, 0})
+	macSize := binary.BigEndian.Uint16(rdata[len(algName)+8:])
+	c.Assert(int(macSize), check.Equals, sha256.Size)
+	signature := rdata[len(algName)+10 : len(algName)+10+sha256.Size]
+	c.Assert(bytes.Equal(signature, mac.Sum(nil)), check.Equals, true)
+}
+
+func (s *S) TestRemoveRecordWithoutTSIG(c *check.C) {
+	config.Unset("dns:providers:bind:tsig")
+	p := s.newProvider(c)
+	err := p.RemoveRecord(context.TODO(), "example.com", dns.Record{Name: "www.example.com", Type: dns.RecordTypeTXT})
+	c.Assert(err, check.IsNil)
+	msg := <-s.received
+	c.Assert(binary.BigEndian.Uint16(msg[8:]), check.Equals, uint16(1))
+	c.Assert(binary.BigEndian.Uint16(msg[10:]), check.Equals, uint16(0))
+	c.Assert(msg[12:], check.DeepEquals, []byte("\x07example\x03com\x00\x00\x06\x00\x01\x03www\x07example\x03com\x00\x00\x10\x00\xff\x00\x00\x00\x00\x00\x00"))
+}
+
+func (s *S) TestSetRecordRefused(c *check.C) {
+	s.rcode = 5
+	p := s.newProvider(c)
+	err := p.SetRecord(context.TODO(), "example.com", dns.Record{Name: "www.example.com", Type: dns.RecordTypeA, Values: []string{"10.0.0.1"}, TTL: 60})
+	c.Assert(err, check.ErrorMatches, `dns update for zone "example.com" failed: REFUSED`)
+}
+
+func (s *S) TestSetRecordUnsupportedType(c *check.C) {
+	p := s.newProvider(c)
+	err := p.SetRecord(context.TODO(), "example.com", dns.Record{Name: "www.example.com", Type: "MX"})
+	c.Assert(err, check.ErrorMatches, `unsupported record type "MX"`)
+}
+
+func (s *S) TestNewProviderInvalidAlgorithm(c *check.C) {
+	config.Set("dns:providers:bind:tsig:algorithm", "hmac-sha3")
+	_, err := newProvider("bind", "dns:providers:bind")
+	c.Assert(err, check.ErrorMatches, `unsupported tsig algorithm "hmac-sha3"`)
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package route53 implements a DNS driver managing records in Amazon Route
+// 53 hosted zones.
+package route53
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/dns"
+	tsuruNet "github.com/tsuru/tsuru/net"
+)
+
+const defaultRegion = "us-east-1"
+
+func init() {
+	dns.Register("route53", newProvider)
+}
+
+type provider struct {
+	client  *route53.Route53
+	zoneIDs map[string]string
+	mu      sync.Mutex
+}
+
+func newProvider(name, configPrefix string) (dns.Provider, error) {
+	region, _ := config.GetString(configPrefix + ":region")
+	if region == "" {
+		region = defaultRegion
+	}
+	awsConfig := aws.Config{
+		Region:     aws.String(region),
+		HTTPClient: tsuruNet.Dial15Full60ClientNoKeepAlive,
+	}
+	if endpoint, _ := config.GetString(configPrefix + ":endpoint"); endpoint != "" {
+		awsConfig.Endpoint = aws.String(endpoint)
+	}
+	keyID, _ := config.GetString(configPrefix + ":key-id")
+	secretKey, _ := config.GetString(configPrefix + ":secret-key")
+	if keyID != "" || secretKey != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(keyID, secretKey, "")
+	}
+	sess, err := session.NewSession(&awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	p := &provider{client: route53.New(sess), zoneIDs: map[string]string{}}
+	zoneIDs, _ := config.Get(configPrefix + ":hosted-zone-ids")
+	if m, ok := zoneIDs.(map[interface{}]interface{}); ok {
+		for zone, id := range m {
+			zoneName, _ := zone.(string)
+			zoneID, _ := id.(string)
+			p.zoneIDs[dns.Canonical(zoneName)] = zoneID
+		}
+	}
+	return p, nil
+}
+
+// hostedZoneID returns the id of the hosted zone, either from the
+// hosted-zone-ids config entry or looking it up by name.
+func (p *provider) hostedZoneID(ctx context.Context, zone string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if id, ok := p.zoneIDs[zone]; ok {
+		return id, nil
+	}
+	out, err := p.client.ListHostedZonesByNameWithContext(ctx, &route53.ListHostedZonesByNameInput{
+		DNSName:  aws.String(zone + "."),
+		MaxItems: aws.String("1"),
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(out.HostedZones) == 0 || dns.Canonical(aws.StringValue(out.HostedZones[0].Name)) != zone {
+		return "", errors.Errorf("route53 hosted zone %q not found", zone)
+	}
+	id := strings.TrimPrefix(aws.StringValue(out.HostedZones[0].Id), "/hostedzone/")
+	p.zoneIDs[zone] = id
+	return id, nil
+}
+
+func (p *provider) SetRecord(ctx context.Context, zone string, record dns.Record) error {
+	zoneID, err := p.hostedZoneID(ctx, zone)
+	if err != nil {
+		return err
+	}
+	rrset := &route53.ResourceRecordSet{
+		Name: aws.String(record.Name + "."),
+		Type: aws.String(record.Type),
+		TTL:  aws.Int64(record.TTL),
+	}
+	for _, value := range record.Values {
+		if record.Type == dns.RecordTypeTXT {
+			value = strconv.Quote(value)
+		}
+		rrset.ResourceRecords = append(rrset.ResourceRecords, &route53.ResourceRecord{Value: aws.String(value)})
+	}
+	return p.change(ctx, zoneID, route53.ChangeActionUpsert, rrset)
+}
+
+func (p *provider) RemoveRecord(ctx context.Context, zone string, record dns.Record) error {
+	zoneID, err := p.hostedZoneID(ctx, zone)
+	if err != nil {
+		return err
+	}
+	// Route 53 only deletes record sets matching exactly the current values,
+	// which are fetched before the removal.
+	out, err := p.client.ListResourceRecordSetsWithContext(ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(zoneID),
+		StartRecordName: aws.String(record.Name + "."),
+		StartRecordType: aws.String(record.Type),
+		MaxItems:        aws.String("1"),
+	})
+	if err != nil {
+		return err
+	}
+	if len(out.ResourceRecordSets) == 0 {
+		return nil
+	}
+	rrset := out.ResourceRecordSets[0]
+	if dns.Canonical(aws.StringValue(rrset.Name)) != record.Name || aws.StringValue(rrset.Type) != record.Type {
+		return nil
+	}
+	return p.change(ctx, zoneID, route53.ChangeActionDelete, rrset)
+}
+
+func (p *provider) change(ctx context.Context, zoneID, action string, rrset *route53.ResourceRecordSet) error {
+	_, err := p.client.ChangeResourceRecordSetsWithContext(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{{Action: aws.String(action), ResourceRecordSet: rrset}},
+		},
+	})
+	return errors.Wrapf(err, "unable to %s route53 record %q", strings.ToLower(action), aws.StringValue(rrset.Name))
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package route53
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/dns"
+	check "gopkg.in/check.v1"
+)
+
+type S struct {
+	server   *httptest.Server
+	changes  []string
+	existing string
+}
+
+var _ = check.Suite(&S{})
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+const changeResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<ChangeResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+<ChangeInfo><Id>/change/C1</Id><Status>PENDING</Status><SubmittedAt>2026-01-01T00:00:00Z</SubmittedAt></ChangeInfo>
+</ChangeResourceRecordSetsResponse>`
+
+func (s *S) SetUpTest(c *check.C) {
+	s.changes = nil
+	s.existing = ""
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/hostedzonesbyname":
+			w.Write([]byte(`<ListHostedZonesByNameResponse><HostedZones><HostedZone><Id>/hostedzone/Z123</Id><Name>example.com.</Name><CallerReference>x</CallerReference></HostedZone></HostedZones><IsTruncated>false</IsTruncated><MaxItems>1</MaxItems></ListHostedZonesByNameResponse>`))
+		case r.Method == http.MethodGet && r.URL.Path == "/2013-04-01/hostedzone/Z123/rrset":
+			w.Write([]byte(`<ListResourceRecordSetsResponse><ResourceRecordSets>` + s.existing + `</ResourceRecordSets><IsTruncated>false</IsTruncated><MaxItems>1</MaxItems></ListResourceRecordSetsResponse>`))
+		case r.Method == http.MethodPost && r.URL.Path == "/2013-04-01/hostedzone/Z123/rrset/":
+			data, _ := ioutil.ReadAll(r.Body)
+			s.changes = append(s.changes, string(data))
+			w.Write([]byte(changeResponse))
+		default:
+			c.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	config.Set("dns:providers:aws:endpoint", s.server.URL)
+	config.Set("dns:providers:aws:key-id", "key")
+	config.Set("dns:providers:aws:secret-key", "secret")
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	s.server.Close()
+	config.Unset("dns")
+}
+
+func (s *S) TestSetRecord(c *check.C) {
+	p, err := newProvider("aws", "dns:providers:aws")
+	c.Assert(err, check.IsNil)
+	err = p.SetRecord(context.TODO(), "example.com", dns.Record{
+		Name:   "www.example.com",
+		Type:   dns.RecordTypeTXT,
+		Values: []string{"token"},
+		TTL:    300,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.changes, check.HasLen, 1)
+	c.Assert(s.changes[0], check.Matches, `.*<Action>UPSERT</Action>.*`)
+	c.Assert(s.changes[0], check.Matches, `.*<Name>www.example.com.</Name>.*`)
+	c.Assert(s.changes[0], check.Matches, `.*<ResourceRecord><Value>&#34;token&#34;</Value></ResourceRecord>.*`)
+	c.Assert(s.changes[0], check.Matches, `.*<TTL>300</TTL><Type>TXT</Type>.*`)
+}
+
+func (s *S) TestRemoveRecord(c *check.C) {
+	s.existing = `<ResourceRecordSet><Name>www.example.com.</Name><Type>CNAME</Type><TTL>300</TTL><ResourceRecords><ResourceRecord><Value>myapp.router.io</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>`
+	config.Set("dns:providers:aws:hosted-zone-ids", map[interface{}]interface{}{"example.com": "Z123"})
+	p, err := newProvider("aws", "dns:providers:aws")
+	c.Assert(err, check.IsNil)
+	err = p.RemoveRecord(context.TODO(), "example.com", dns.Record{Name: "www.example.com", Type: dns.RecordTypeCNAME})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.changes, check.HasLen, 1)
+	c.Assert(s.changes[0], check.Matches, `.*<Action>DELETE</Action>.*<Value>myapp.router.io</Value>.*`)
+}
+
+func (s *S) TestRemoveRecordNotFound(c *check.C) {
+	s.existing = `<ResourceRecordSet><Name>zzz.example.com.</Name><Type>CNAME</Type><TTL>300</TTL><ResourceRecords><ResourceRecord><Value>other</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>`
+	p, err := newProvider("aws", "dns:providers:aws")
+	c.Assert(err, check.IsNil)
+	err = p.RemoveRecord(context.TODO(), "example.com", dns.Record{Name: "www.example.com", Type: dns.RecordTypeCNAME})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.changes, check.HasLen, 0)
+}
@@ -0,0 +1,270 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package audit exports a summary of every completed event to external
+// sinks, like syslog servers, HTTPS collectors and Kafka, so security teams
+// can ingest tsuru activity without access to its database.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/log"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+const (
+	defaultBufferSize    = 10000
+	defaultBatchSize     = 100
+	defaultMaxRetries    = 5
+	defaultRetryInterval = time.Second
+	maxRetryInterval     = time.Minute
+)
+
+var (
+	_ eventTypes.AuditService = &auditService{}
+
+	recordsExported = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_audit_records_exported_total",
+		Help: "The total number of audit records exported to each sink",
+	}, []string{"sink"})
+	recordsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_audit_records_dropped_total",
+		Help: "The total number of audit records dropped because the sink buffer was full or retries were exhausted",
+	}, []string{"sink"})
+	sendErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_audit_send_errors_total",
+		Help: "The total number of failed attempts to send audit records to each sink",
+	}, []string{"sink"})
+)
+
+func init() {
+	prometheus.MustRegister(recordsExported, recordsDropped, sendErrors)
+}
+
+// sink is a destination for audit records.
+type sink interface {
+	Send(ctx context.Context, records []eventTypes.AuditRecord) error
+	Close() error
+}
+
+// queue buffers records for a single sink, so a slow or unavailable sink
+// doesn't delay the others.
+type queue struct {
+	name          string
+	sink          sink
+	ch            chan eventTypes.AuditRecord
+	batchSize     int
+	maxRetries    int
+	retryInterval time.Duration
+}
+
+type auditService struct {
+	queues []*queue
+	quitCh chan struct{}
+	doneCh chan struct{}
+}
+
+// AuditService returns the service exporting events to the sinks configured
+// under audit:sinks, or nil when no sink is configured.
+func AuditService() (eventTypes.AuditService, error) {
+	sinksConfig, err := config.Get("audit:sinks")
+	if err != nil {
+		return nil, nil
+	}
+	sinksMap, ok := sinksConfig.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.Errorf("invalid audit sinks configuration: %v", sinksConfig)
+	}
+	names := make([]string, 0, len(sinksMap))
+	for name := range sinksMap {
+		names = append(names, fmt.Sprint(name))
+	}
+	sort.Strings(names)
+	queues := make([]*queue, 0, len(names))
+	for _, name := range names {
+		q, err := queueFromConfig(name)
+		if err != nil {
+			return nil, err
+		}
+		queues = append(queues, q)
+	}
+	s := newAuditService(queues)
+	shutdown.Register(s)
+	return s, nil
+}
+
+func queueFromConfig(name string) (*queue, error) {
+	prefix := "audit:sinks:" + name
+	sinkType, _ := config.GetString(prefix + ":type")
+	var (
+		snk sink
+		err error
+	)
+	switch sinkType {
+	case "syslog":
+		snk, err = newSyslogSink(prefix)
+	case "http":
+		snk, err = newHTTPSink(prefix)
+	case "kafka":
+		snk, err = newKafkaSink(prefix)
+	default:
+		return nil, errors.Errorf("invalid type %q for audit sink %q, must be syslog, http or kafka", sinkType, name)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid audit sink %q", name)
+	}
+	q := &queue{
+		name:          name,
+		sink:          snk,
+		batchSize:     defaultBatchSize,
+		maxRetries:    defaultMaxRetries,
+		retryInterval: defaultRetryInterval,
+	}
+	bufferSize := defaultBufferSize
+	if value, errConf := config.GetInt(prefix + ":buffer-size"); errConf == nil && value > 0 {
+		bufferSize = value
+	}
+	q.ch = make(chan eventTypes.AuditRecord, bufferSize)
+	if value, errConf := config.GetInt(prefix + ":batch-size"); errConf == nil && value > 0 {
+		q.batchSize = value
+	}
+	if value, errConf := config.GetInt(prefix + ":max-retries"); errConf == nil && value >= 0 {
+		q.maxRetries = value
+	}
+	if value, errConf := config.GetDuration(prefix + ":retry-interval"); errConf == nil && value > 0 {
+		q.retryInterval = value
+	}
+	return q, nil
+}
+
+func newAuditService(queues []*queue) *auditService {
+	s := &auditService{
+		queues: queues,
+		quitCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Export queues the record to every sink. It never blocks: records are
+// dropped when a sink buffer is full.
+func (s *auditService) Export(record eventTypes.AuditRecord) {
+	for _, q := range s.queues {
+		select {
+		case q.ch <- record:
+		default:
+			recordsDropped.WithLabelValues(q.name).Inc()
+			log.Errorf("[audit] buffer full for sink %q, dropping record for event %s", q.name, record.ID)
+		}
+	}
+}
+
+func (s *auditService) Shutdown(ctx context.Context) error {
+	close(s.quitCh)
+	select {
+	case <-s.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *auditService) run() {
+	defer close(s.doneCh)
+	done := make(chan struct{}, len(s.queues))
+	for _, q := range s.queues {
+		go func(q *queue) {
+			q.run(s.quitCh)
+			done <- struct{}{}
+		}(q)
+	}
+	for range s.queues {
+		<-done
+	}
+}
+
+func (q *queue) run(quitCh chan struct{}) {
+	defer q.sink.Close()
+	for {
+		select {
+		case record := <-q.ch:
+			q.send(q.batch(record), quitCh)
+		case <-quitCh:
+			q.flush()
+			return
+		}
+	}
+}
+
+// batch returns the given record along with any other records already
+// buffered, up to the batch size.
+func (q *queue) batch(first eventTypes.AuditRecord) []eventTypes.AuditRecord {
+	records := []eventTypes.AuditRecord{first}
+	for len(records) < q.batchSize {
+		select {
+		case record := <-q.ch:
+			records = append(records, record)
+		default:
+			return records
+		}
+	}
+	return records
+}
+
+// send sends the records to the sink, retrying with exponential backoff.
+func (q *queue) send(records []eventTypes.AuditRecord, quitCh chan struct{}) {
+	interval := q.retryInterval
+	for attempt := 0; ; attempt++ {
+		err := q.sink.Send(context.Background(), records)
+		if err == nil {
+			recordsExported.WithLabelValues(q.name).Add(float64(len(records)))
+			return
+		}
+		sendErrors.WithLabelValues(q.name).Inc()
+		if attempt >= q.maxRetries {
+			recordsDropped.WithLabelValues(q.name).Add(float64(len(records)))
+			log.Errorf("[audit] unable to send %d records to sink %q after %d attempts, dropping them: %v", len(records), q.name, attempt+1, err)
+			return
+		}
+		log.Errorf("[audit] unable to send %d records to sink %q, retrying in %s: %v", len(records), q.name, interval, err)
+		select {
+		case <-time.After(interval):
+		case <-quitCh:
+			recordsDropped.WithLabelValues(q.name).Add(float64(len(records)))
+			return
+		}
+		interval *= 2
+		if interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
+
+// flush makes a single attempt to send the records still buffered when the
+// service is shutting down.
+func (q *queue) flush() {
+	for {
+		select {
+		case record := <-q.ch:
+			records := q.batch(record)
+			if err := q.sink.Send(context.Background(), records); err != nil {
+				recordsDropped.WithLabelValues(q.name).Add(float64(len(records)))
+				log.Errorf("[audit] unable to flush %d records to sink %q: %v", len(records), q.name, err)
+				continue
+			}
+			recordsExported.WithLabelValues(q.name).Add(float64(len(records)))
+		default:
+			return
+		}
+	}
+}
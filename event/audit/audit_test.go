@@ -0,0 +1,247 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("audit")
+}
+
+type fakeSink struct {
+	sync.Mutex
+	failures int
+	batches  [][]eventTypes.AuditRecord
+	closed   bool
+}
+
+func (f *fakeSink) Send(ctx context.Context, records []eventTypes.AuditRecord) error {
+	f.Lock()
+	defer f.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("sink unavailable")
+	}
+	f.batches = append(f.batches, records)
+	return nil
+}
+
+func (f *fakeSink) Close() error {
+	f.Lock()
+	defer f.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeSink) records() []string {
+	f.Lock()
+	defer f.Unlock()
+	var ids []string
+	for _, batch := range f.batches {
+		for _, r := range batch {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids
+}
+
+func newTestQueue(snk sink, size int) *queue {
+	return &queue{
+		name:          "test",
+		sink:          snk,
+		ch:            make(chan eventTypes.AuditRecord, size),
+		batchSize:     10,
+		maxRetries:    3,
+		retryInterval: time.Millisecond,
+	}
+}
+
+func waitRecords(c *check.C, snk *fakeSink, n int) []string {
+	timeout := time.After(5 * time.Second)
+	for {
+		if ids := snk.records(); len(ids) >= n {
+			return ids
+		}
+		select {
+		case <-timeout:
+			c.Fatalf("timeout waiting for %d records, got %v", n, snk.records())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (s *S) TestExportRetries(c *check.C) {
+	snk := &fakeSink{failures: 2}
+	svc := newAuditService([]*queue{newTestQueue(snk, 10)})
+	svc.Export(eventTypes.AuditRecord{ID: "1"})
+	svc.Export(eventTypes.AuditRecord{ID: "2"})
+	c.Assert(waitRecords(c, snk, 2), check.DeepEquals, []string{"1", "2"})
+	err := svc.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Assert(snk.closed, check.Equals, true)
+}
+
+func (s *S) TestExportDropsAfterMaxRetries(c *check.C) {
+	snk := &fakeSink{failures: 4}
+	svc := newAuditService([]*queue{newTestQueue(snk, 10)})
+	svc.Export(eventTypes.AuditRecord{ID: "1"})
+	time.Sleep(100 * time.Millisecond)
+	svc.Export(eventTypes.AuditRecord{ID: "2"})
+	c.Assert(waitRecords(c, snk, 1), check.DeepEquals, []string{"2"})
+	err := svc.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestExportFullBufferDoesNotBlock(c *check.C) {
+	snk := &fakeSink{}
+	q := newTestQueue(snk, 1)
+	svc := &auditService{queues: []*queue{q}}
+	svc.Export(eventTypes.AuditRecord{ID: "1"})
+	svc.Export(eventTypes.AuditRecord{ID: "2"})
+	c.Assert(q.ch, check.HasLen, 1)
+}
+
+func (s *S) TestShutdownFlushesBuffer(c *check.C) {
+	snk := &fakeSink{}
+	q := newTestQueue(snk, 10)
+	q.ch <- eventTypes.AuditRecord{ID: "1"}
+	q.ch <- eventTypes.AuditRecord{ID: "2"}
+	q.flush()
+	c.Assert(snk.records(), check.DeepEquals, []string{"1", "2"})
+	c.Assert(snk.batches, check.HasLen, 1)
+}
+
+func (s *S) TestAuditServiceNotConfigured(c *check.C) {
+	svc, err := AuditService()
+	c.Assert(err, check.IsNil)
+	c.Assert(svc, check.IsNil)
+}
+
+func (s *S) TestAuditServiceInvalidType(c *check.C) {
+	config.Set("audit:sinks:siem:type", "smoke-signals")
+	_, err := AuditService()
+	c.Assert(err, check.ErrorMatches, `invalid type "smoke-signals" for audit sink "siem".*`)
+}
+
+func (s *S) TestHTTPSink(c *check.C) {
+	var received []eventTypes.AuditRecord
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &received)
+	}))
+	defer srv.Close()
+	config.Set("audit:sinks:collector:type", "http")
+	config.Set("audit:sinks:collector:url", srv.URL)
+	config.Set("audit:sinks:collector:headers", map[interface{}]interface{}{"Authorization": "Splunk abc"})
+	q, err := queueFromConfig("collector")
+	c.Assert(err, check.IsNil)
+	now := time.Now().UTC().Truncate(time.Second)
+	record := eventTypes.AuditRecord{ID: "1", Kind: "app.deploy", Owner: "me@example.com", StartTime: now, EndTime: now, Success: true}
+	err = q.sink.Send(context.Background(), []eventTypes.AuditRecord{record})
+	c.Assert(err, check.IsNil)
+	c.Assert(received, check.DeepEquals, []eventTypes.AuditRecord{record})
+	c.Assert(header.Get("Authorization"), check.Equals, "Splunk abc")
+	c.Assert(header.Get("Content-Type"), check.Equals, "application/json")
+}
+
+func (s *S) TestHTTPSinkError(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	config.Set("audit:sinks:collector:type", "http")
+	config.Set("audit:sinks:collector:url", srv.URL)
+	q, err := queueFromConfig("collector")
+	c.Assert(err, check.IsNil)
+	err = q.sink.Send(context.Background(), []eventTypes.AuditRecord{{ID: "1"}})
+	c.Assert(err, check.ErrorMatches, "invalid status code 503: overloaded\n")
+}
+
+func (s *S) TestKafkaSink(c *check.C) {
+	var path, contentType string
+	var body map[string][]kafkaMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+	}))
+	defer srv.Close()
+	config.Set("audit:sinks:kafka:type", "kafka")
+	config.Set("audit:sinks:kafka:url", srv.URL+"/")
+	config.Set("audit:sinks:kafka:topic", "tsuru-audit")
+	q, err := queueFromConfig("kafka")
+	c.Assert(err, check.IsNil)
+	err = q.sink.Send(context.Background(), []eventTypes.AuditRecord{{ID: "1", TargetType: "app", TargetValue: "myapp"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(path, check.Equals, "/topics/tsuru-audit")
+	c.Assert(contentType, check.Equals, "application/vnd.kafka.json.v2+json")
+	c.Assert(body["records"], check.HasLen, 1)
+	c.Assert(body["records"][0].Key, check.Equals, "app/myapp")
+	c.Assert(body["records"][0].Value.ID, check.Equals, "1")
+}
+
+func (s *S) TestSyslogSinkCEF(c *check.C) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+	config.Set("audit:sinks:siem:type", "syslog")
+	config.Set("audit:sinks:siem:address", "udp://"+conn.LocalAddr().String())
+	config.Set("audit:sinks:siem:format", "cef")
+	q, err := queueFromConfig("siem")
+	c.Assert(err, check.IsNil)
+	defer q.sink.Close()
+	err = q.sink.Send(context.Background(), []eventTypes.AuditRecord{{ID: "1", Kind: "app.deploy", Owner: "me@example.com", Success: true}})
+	c.Assert(err, check.IsNil)
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(buf[:n]), check.Matches, `(?s)<38>.*tsuru\[\d+\]: CEF:0\|tsuru\|tsuru\|1.0\|app.deploy\|app.deploy\|3\|.*suser=me@example.com.*outcome=success.*`)
+}
+
+func (s *S) TestFormatCEFRecord(c *check.C) {
+	start := time.Date(2026, time.October, 17, 10, 0, 0, 0, time.UTC)
+	record := eventTypes.AuditRecord{
+		ID:          "abc",
+		Kind:        "app.update|weird",
+		Owner:       "me@example.com",
+		OwnerType:   "user",
+		TargetType:  "app",
+		TargetValue: "myapp",
+		SourceIP:    "10.0.0.1",
+		StartTime:   start,
+		EndTime:     start.Add(1500 * time.Millisecond),
+		Duration:    1.5,
+		Error:       "a=b\nfailed",
+	}
+	c.Assert(formatCEFRecord(record), check.Equals, `CEF:0|tsuru|tsuru|1.0|app.update\|weird|app.update\|weird|7|`+
+		`rt=1792231201500 start=1792231200000 externalId=abc suser=me@example.com cs1Label=ownerType cs1=user `+
+		`cs2Label=targetType cs2=app cs3Label=targetValue cs3=myapp cn1Label=durationMs cn1=1500 outcome=failure `+
+		`src=10.0.0.1 msg=a\=b\nfailed`)
+}
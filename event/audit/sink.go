@@ -0,0 +1,256 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/syslog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+const (
+	formatJSON = "json"
+	formatCEF  = "cef"
+
+	userAgent = "tsuru-audit-client/1.0"
+)
+
+// syslogSink writes one message per record to a syslog server, either as
+// JSON or in the ArcSight Common Event Format.
+type syslogSink struct {
+	network string
+	address string
+	tag     string
+	format  string
+	writer  *syslog.Writer
+}
+
+func newSyslogSink(prefix string) (*syslogSink, error) {
+	s := &syslogSink{tag: "tsuru", format: formatJSON}
+	address, err := config.GetString(prefix + ":address")
+	if err != nil {
+		return nil, errors.New("syslog address is required")
+	}
+	s.network, s.address = "udp", address
+	if u, errParse := url.Parse(address); errParse == nil && u.Host != "" {
+		s.network, s.address = u.Scheme, u.Host
+	}
+	if tag, _ := config.GetString(prefix + ":tag"); tag != "" {
+		s.tag = tag
+	}
+	if format, _ := config.GetString(prefix + ":format"); format != "" {
+		s.format = format
+	}
+	if s.format != formatJSON && s.format != formatCEF {
+		return nil, errors.Errorf("invalid syslog format %q, must be json or cef", s.format)
+	}
+	return s, nil
+}
+
+func (s *syslogSink) Send(ctx context.Context, records []eventTypes.AuditRecord) error {
+	if s.writer == nil {
+		writer, err := syslog.Dial(s.network, s.address, syslog.LOG_INFO|syslog.LOG_AUTH, s.tag)
+		if err != nil {
+			return err
+		}
+		s.writer = writer
+	}
+	for i, record := range records {
+		msg, err := s.message(record)
+		if err != nil {
+			return err
+		}
+		if err = s.writer.Info(msg); err != nil {
+			return errors.Wrapf(err, "%d of %d records sent", i, len(records))
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) message(record eventTypes.AuditRecord) (string, error) {
+	if s.format == formatCEF {
+		return formatCEFRecord(record), nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (s *syslogSink) Close() error {
+	if s.writer == nil {
+		return nil
+	}
+	return s.writer.Close()
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+)
+
+// formatCEFRecord formats the record in the Common Event Format, understood
+// by most SIEM systems.
+func formatCEFRecord(record eventTypes.AuditRecord) string {
+	severity, outcome := 3, "success"
+	if !record.Success {
+		severity, outcome = 7, "failure"
+	}
+	extensions := []string{
+		"rt=" + fmt.Sprint(record.EndTime.UnixNano()/1e6),
+		"start=" + fmt.Sprint(record.StartTime.UnixNano()/1e6),
+		"externalId=" + cefExtensionEscaper.Replace(record.ID),
+		"suser=" + cefExtensionEscaper.Replace(record.Owner),
+		"cs1Label=ownerType",
+		"cs1=" + cefExtensionEscaper.Replace(record.OwnerType),
+		"cs2Label=targetType",
+		"cs2=" + cefExtensionEscaper.Replace(record.TargetType),
+		"cs3Label=targetValue",
+		"cs3=" + cefExtensionEscaper.Replace(record.TargetValue),
+		"cn1Label=durationMs",
+		"cn1=" + fmt.Sprint(int64(record.Duration*1000)),
+		"outcome=" + outcome,
+	}
+	if record.SourceIP != "" {
+		extensions = append(extensions, "src="+cefExtensionEscaper.Replace(record.SourceIP))
+	}
+	if record.Error != "" {
+		extensions = append(extensions, "msg="+cefExtensionEscaper.Replace(record.Error))
+	}
+	kind := cefHeaderEscaper.Replace(record.Kind)
+	return fmt.Sprintf("CEF:0|tsuru|tsuru|1.0|%s|%s|%d|%s", kind, kind, severity, strings.Join(extensions, " "))
+}
+
+// httpSink posts records as a JSON array to a collector.
+type httpSink struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+func newHTTPSink(prefix string) (*httpSink, error) {
+	u, err := config.GetString(prefix + ":url")
+	if err != nil {
+		return nil, errors.New("http url is required")
+	}
+	s := &httpSink{url: u, headers: http.Header{}}
+	if headers, _ := config.Get(prefix + ":headers"); headers != nil {
+		h, ok := headers.(map[interface{}]interface{})
+		if !ok {
+			return nil, errors.Errorf("invalid headers configuration: %v", headers)
+		}
+		for k, v := range h {
+			s.headers.Set(fmt.Sprint(k), fmt.Sprint(v))
+		}
+	}
+	s.client = tsuruNet.Dial15Full60ClientNoKeepAlive
+	if insecure, _ := config.GetBool(prefix + ":insecure"); insecure {
+		s.client = tsuruNet.Dial15Full60ClientNoKeepAliveInsecure
+	}
+	s.client, err = tsuruNet.WithProxyFromConfig(*s.client, u)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *httpSink) Send(ctx context.Context, records []eventTypes.AuditRecord) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.client, s.url, "application/json", s.headers, data)
+}
+
+func (s *httpSink) Close() error {
+	return nil
+}
+
+// kafkaSink produces records to a Kafka topic through a Kafka REST proxy,
+// one message per record keyed by the target.
+type kafkaSink struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+func newKafkaSink(prefix string) (*kafkaSink, error) {
+	proxyURL, err := config.GetString(prefix + ":url")
+	if err != nil {
+		return nil, errors.New("kafka rest proxy url is required")
+	}
+	topic, err := config.GetString(prefix + ":topic")
+	if err != nil {
+		return nil, errors.New("kafka topic is required")
+	}
+	s := &kafkaSink{
+		url:     strings.TrimSuffix(proxyURL, "/") + "/topics/" + url.PathEscape(topic),
+		headers: http.Header{"Accept": []string{"application/vnd.kafka.v2+json"}},
+	}
+	if token, _ := config.GetString(prefix + ":token"); token != "" {
+		s.headers.Set("Authorization", "Bearer "+token)
+	}
+	s.client, err = tsuruNet.WithProxyFromConfig(*tsuruNet.Dial15Full60ClientNoKeepAlive, proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+type kafkaMessage struct {
+	Key   string                 `json:"key"`
+	Value eventTypes.AuditRecord `json:"value"`
+}
+
+func (s *kafkaSink) Send(ctx context.Context, records []eventTypes.AuditRecord) error {
+	messages := make([]kafkaMessage, len(records))
+	for i, record := range records {
+		messages[i] = kafkaMessage{Key: record.TargetType + "/" + record.TargetValue, Value: record}
+	}
+	data, err := json.Marshal(map[string]interface{}{"records": messages})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.client, s.url, "application/vnd.kafka.json.v2+json", s.headers, data)
+}
+
+func (s *kafkaSink) Close() error {
+	return nil
+}
+
+func post(ctx context.Context, client *http.Client, u, contentType string, headers http.Header, data []byte) error {
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", userAgent)
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(rsp.Body)
+		return errors.Errorf("invalid status code %d: %s", rsp.StatusCode, string(body))
+	}
+	return nil
+}
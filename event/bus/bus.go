@@ -0,0 +1,200 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bus publishes event lifecycle transitions to a message broker, so
+// external systems can react to them without polling the events API.
+package bus
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/log"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+const (
+	defaultBufferSize    = 10000
+	maxBatchSize         = 100
+	defaultMaxRetries    = 5
+	defaultRetryInterval = time.Second
+	maxRetryInterval     = time.Minute
+	defaultTopic         = "tsuru.events"
+)
+
+var (
+	_ eventTypes.EventPublisher = &publisherService{}
+
+	transitionsPublished = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_event_bus_published_total",
+		Help: "The total number of event transitions published to the event bus",
+	})
+	transitionsDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_event_bus_dropped_total",
+		Help: "The total number of event transitions dropped because the buffer was full or retries were exhausted",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(transitionsPublished, transitionsDropped)
+}
+
+// publisher sends transitions to a broker.
+type publisher interface {
+	Publish(ctx context.Context, transitions []eventTypes.Transition) error
+	Close() error
+}
+
+type publisherService struct {
+	publisher     publisher
+	ch            chan eventTypes.Transition
+	maxRetries    int
+	retryInterval time.Duration
+	quitCh        chan struct{}
+	doneCh        chan struct{}
+}
+
+// PublisherService returns the service publishing event transitions to the
+// broker configured in event-bus, or nil when no broker is configured.
+func PublisherService() (eventTypes.EventPublisher, error) {
+	busType, err := config.GetString("event-bus:type")
+	if err != nil {
+		return nil, nil
+	}
+	topic, _ := config.GetString("event-bus:topic")
+	if topic == "" {
+		topic = defaultTopic
+	}
+	var p publisher
+	switch busType {
+	case "kafka":
+		p, err = newKafkaPublisher(topic)
+	case "nats":
+		p, err = newNATSPublisher(topic)
+	default:
+		return nil, errors.Errorf("invalid event bus type %q, must be kafka or nats", busType)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid event bus configuration")
+	}
+	bufferSize := defaultBufferSize
+	if value, errConf := config.GetInt("event-bus:buffer-size"); errConf == nil && value > 0 {
+		bufferSize = value
+	}
+	s := newPublisherService(p, bufferSize)
+	if value, errConf := config.GetInt("event-bus:max-retries"); errConf == nil && value >= 0 {
+		s.maxRetries = value
+	}
+	if value, errConf := config.GetDuration("event-bus:retry-interval"); errConf == nil && value > 0 {
+		s.retryInterval = value
+	}
+	go s.run()
+	shutdown.Register(s)
+	return s, nil
+}
+
+func newPublisherService(p publisher, bufferSize int) *publisherService {
+	return &publisherService{
+		publisher:     p,
+		ch:            make(chan eventTypes.Transition, bufferSize),
+		maxRetries:    defaultMaxRetries,
+		retryInterval: defaultRetryInterval,
+		quitCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+}
+
+// Publish queues the transition. It never blocks: transitions are dropped
+// when the buffer is full.
+func (s *publisherService) Publish(t eventTypes.Transition) {
+	select {
+	case s.ch <- t:
+	default:
+		transitionsDropped.Inc()
+		log.Errorf("[event bus] buffer full, dropping %s transition for event %s", t.Transition, t.ID)
+	}
+}
+
+func (s *publisherService) Shutdown(ctx context.Context) error {
+	close(s.quitCh)
+	select {
+	case <-s.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *publisherService) run() {
+	defer close(s.doneCh)
+	defer s.publisher.Close()
+	for {
+		select {
+		case t := <-s.ch:
+			s.send(s.batch(t))
+		case <-s.quitCh:
+			for {
+				select {
+				case t := <-s.ch:
+					batch := s.batch(t)
+					if err := s.publisher.Publish(context.Background(), batch); err != nil {
+						transitionsDropped.Add(float64(len(batch)))
+						log.Errorf("[event bus] unable to flush %d transitions: %v", len(batch), err)
+						continue
+					}
+					transitionsPublished.Add(float64(len(batch)))
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// batch returns the given transition along with any other transitions
+// already buffered, preserving their order.
+func (s *publisherService) batch(first eventTypes.Transition) []eventTypes.Transition {
+	batch := []eventTypes.Transition{first}
+	for len(batch) < maxBatchSize {
+		select {
+		case t := <-s.ch:
+			batch = append(batch, t)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// send publishes the transitions, retrying with exponential backoff.
+func (s *publisherService) send(batch []eventTypes.Transition) {
+	interval := s.retryInterval
+	for attempt := 0; ; attempt++ {
+		err := s.publisher.Publish(context.Background(), batch)
+		if err == nil {
+			transitionsPublished.Add(float64(len(batch)))
+			return
+		}
+		if attempt >= s.maxRetries {
+			transitionsDropped.Add(float64(len(batch)))
+			log.Errorf("[event bus] unable to publish %d transitions after %d attempts, dropping them: %v", len(batch), attempt+1, err)
+			return
+		}
+		log.Errorf("[event bus] unable to publish %d transitions, retrying in %s: %v", len(batch), interval, err)
+		select {
+		case <-time.After(interval):
+		case <-s.quitCh:
+			transitionsDropped.Add(float64(len(batch)))
+			return
+		}
+		interval *= 2
+		if interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
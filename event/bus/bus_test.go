@@ -0,0 +1,310 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("event-bus")
+}
+
+type fakePublisher struct {
+	sync.Mutex
+	failures int
+	batches  [][]eventTypes.Transition
+	closed   bool
+}
+
+func (f *fakePublisher) Publish(ctx context.Context, transitions []eventTypes.Transition) error {
+	f.Lock()
+	defer f.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("broker unavailable")
+	}
+	f.batches = append(f.batches, transitions)
+	return nil
+}
+
+func (f *fakePublisher) Close() error {
+	f.Lock()
+	defer f.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakePublisher) ids() []string {
+	f.Lock()
+	defer f.Unlock()
+	var ids []string
+	for _, batch := range f.batches {
+		for _, t := range batch {
+			ids = append(ids, t.ID)
+		}
+	}
+	return ids
+}
+
+func newTestService(p publisher, size int) *publisherService {
+	s := newPublisherService(p, size)
+	s.maxRetries = 3
+	s.retryInterval = time.Millisecond
+	return s
+}
+
+func waitTransitions(c *check.C, p *fakePublisher, n int) []string {
+	timeout := time.After(5 * time.Second)
+	for {
+		if ids := p.ids(); len(ids) >= n {
+			return ids
+		}
+		select {
+		case <-timeout:
+			c.Fatalf("timeout waiting for %d transitions, got %v", n, p.ids())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (s *S) TestPublishRetries(c *check.C) {
+	p := &fakePublisher{failures: 2}
+	svc := newTestService(p, 10)
+	go svc.run()
+	svc.Publish(eventTypes.Transition{ID: "1"})
+	svc.Publish(eventTypes.Transition{ID: "2"})
+	c.Assert(waitTransitions(c, p, 2), check.DeepEquals, []string{"1", "2"})
+	err := svc.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Assert(p.closed, check.Equals, true)
+}
+
+func (s *S) TestPublishDropsAfterMaxRetries(c *check.C) {
+	p := &fakePublisher{failures: 4}
+	svc := newTestService(p, 10)
+	go svc.run()
+	svc.Publish(eventTypes.Transition{ID: "1"})
+	time.Sleep(100 * time.Millisecond)
+	svc.Publish(eventTypes.Transition{ID: "2"})
+	c.Assert(waitTransitions(c, p, 1), check.DeepEquals, []string{"2"})
+	err := svc.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestPublishFullBufferDoesNotBlock(c *check.C) {
+	svc := newTestService(&fakePublisher{}, 1)
+	svc.Publish(eventTypes.Transition{ID: "1"})
+	svc.Publish(eventTypes.Transition{ID: "2"})
+	c.Assert(svc.ch, check.HasLen, 1)
+}
+
+func (s *S) TestShutdownFlushesBuffer(c *check.C) {
+	p := &fakePublisher{}
+	svc := newTestService(p, 10)
+	svc.Publish(eventTypes.Transition{ID: "1"})
+	svc.Publish(eventTypes.Transition{ID: "2"})
+	close(svc.quitCh)
+	svc.run()
+	c.Assert(p.ids(), check.DeepEquals, []string{"1", "2"})
+	c.Assert(p.batches, check.HasLen, 1)
+	c.Assert(p.closed, check.Equals, true)
+}
+
+func (s *S) TestPublisherServiceNotConfigured(c *check.C) {
+	svc, err := PublisherService()
+	c.Assert(err, check.IsNil)
+	c.Assert(svc, check.IsNil)
+}
+
+func (s *S) TestPublisherServiceInvalidType(c *check.C) {
+	config.Set("event-bus:type", "pigeon")
+	_, err := PublisherService()
+	c.Assert(err, check.ErrorMatches, `invalid event bus type "pigeon", must be kafka or nats`)
+}
+
+func (s *S) TestPublisherServiceMissingURL(c *check.C) {
+	config.Set("event-bus:type", "nats")
+	_, err := PublisherService()
+	c.Assert(err, check.ErrorMatches, `invalid event bus configuration: nats url is required`)
+}
+
+func (s *S) TestKafkaPublisher(c *check.C) {
+	var path, contentType, auth string
+	var body map[string][]kafkaRecord
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		auth = r.Header.Get("Authorization")
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+	}))
+	defer srv.Close()
+	config.Set("event-bus:url", srv.URL+"/")
+	config.Set("event-bus:token", "secret")
+	p, err := newKafkaPublisher("tsuru.events")
+	c.Assert(err, check.IsNil)
+	err = p.Publish(context.Background(), []eventTypes.Transition{
+		{ID: "1", Transition: eventTypes.TransitionCreated, Target: eventTypes.TransitionTarget{Type: "app", Value: "myapp"}},
+		{ID: "1", Transition: eventTypes.TransitionDone, Target: eventTypes.TransitionTarget{Type: "app", Value: "myapp"}},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(path, check.Equals, "/topics/tsuru.events")
+	c.Assert(contentType, check.Equals, "application/vnd.kafka.json.v2+json")
+	c.Assert(auth, check.Equals, "Bearer secret")
+	c.Assert(body["records"], check.HasLen, 2)
+	c.Assert(body["records"][0].Key, check.Equals, "1")
+	c.Assert(body["records"][0].Value.Transition, check.Equals, eventTypes.TransitionCreated)
+	c.Assert(body["records"][1].Value.Transition, check.Equals, eventTypes.TransitionDone)
+}
+
+func (s *S) TestKafkaPublisherError(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unknown topic", http.StatusNotFound)
+	}))
+	defer srv.Close()
+	config.Set("event-bus:url", srv.URL)
+	p, err := newKafkaPublisher("tsuru.events")
+	c.Assert(err, check.IsNil)
+	err = p.Publish(context.Background(), []eventTypes.Transition{{ID: "1"}})
+	c.Assert(err, check.ErrorMatches, "invalid status code 404: unknown topic\n")
+}
+
+type natsMessage struct {
+	subject string
+	payload []byte
+}
+
+// fakeNATSServer speaks enough of the NATS protocol to accept publishes.
+type fakeNATSServer struct {
+	listener net.Listener
+	connect  chan string
+	messages chan natsMessage
+	reject   bool
+}
+
+func newFakeNATSServer(c *check.C) *fakeNATSServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	srv := &fakeNATSServer{listener: l, connect: make(chan string, 10), messages: make(chan natsMessage, 10)}
+	go srv.serve()
+	return srv
+}
+
+func (f *fakeNATSServer) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeNATSServer) handle(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprint(conn, "INFO {\"server_id\":\"fake\"}\r\n")
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			f.connect <- strings.TrimPrefix(line, "CONNECT ")
+			if f.reject {
+				fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case strings.HasPrefix(line, "PUB "):
+			parts := strings.Fields(line)
+			size, _ := strconv.Atoi(parts[2])
+			payload := make([]byte, size+2)
+			if _, err = io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			f.messages <- natsMessage{subject: parts[1], payload: payload[:size]}
+		case line == "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		}
+	}
+}
+
+func (s *S) TestNATSPublisher(c *check.C) {
+	srv := newFakeNATSServer(c)
+	defer srv.listener.Close()
+	config.Set("event-bus:url", "nats://tsuru:pass@"+srv.listener.Addr().String())
+	p, err := newNATSPublisher("tsuru.events")
+	c.Assert(err, check.IsNil)
+	defer p.Close()
+	err = p.Publish(context.Background(), []eventTypes.Transition{
+		{ID: "1", Transition: eventTypes.TransitionCreated, Target: eventTypes.TransitionTarget{Type: "app", Value: "myapp"}},
+		{ID: "2", Transition: eventTypes.TransitionDone, Target: eventTypes.TransitionTarget{Type: "node", Value: "10.0.0.1"}},
+	})
+	c.Assert(err, check.IsNil)
+	var opts map[string]interface{}
+	err = json.Unmarshal([]byte(<-srv.connect), &opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(opts["user"], check.Equals, "tsuru")
+	c.Assert(opts["pass"], check.Equals, "pass")
+	c.Assert(opts["verbose"], check.Equals, false)
+	msg := <-srv.messages
+	c.Assert(msg.subject, check.Equals, "tsuru.events.app.created")
+	var t eventTypes.Transition
+	err = json.Unmarshal(msg.payload, &t)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.ID, check.Equals, "1")
+	msg = <-srv.messages
+	c.Assert(msg.subject, check.Equals, "tsuru.events.node.done")
+}
+
+func (s *S) TestNATSPublisherServerError(c *check.C) {
+	srv := newFakeNATSServer(c)
+	srv.reject = true
+	defer srv.listener.Close()
+	config.Set("event-bus:url", "nats://"+srv.listener.Addr().String())
+	config.Set("event-bus:token", "wrong")
+	p, err := newNATSPublisher("tsuru.events")
+	c.Assert(err, check.IsNil)
+	err = p.Publish(context.Background(), []eventTypes.Transition{{ID: "1", Transition: eventTypes.TransitionCreated}})
+	c.Assert(err, check.ErrorMatches, "nats error: 'Authorization Violation'")
+	var opts map[string]interface{}
+	err = json.Unmarshal([]byte(<-srv.connect), &opts)
+	c.Assert(err, check.IsNil)
+	c.Assert(opts["auth_token"], check.Equals, "wrong")
+	c.Assert(p.conn, check.IsNil)
+}
+
+func (s *S) TestNATSPublisherInvalidURL(c *check.C) {
+	config.Set("event-bus:url", "http://localhost:4222")
+	_, err := newNATSPublisher("tsuru.events")
+	c.Assert(err, check.ErrorMatches, `invalid nats url "http://localhost:4222", expected nats://host:port`)
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+// kafkaPublisher produces transitions to a topic through a Kafka REST proxy.
+// Messages are keyed by the event ID, so all transitions of an event land in
+// the same partition, in order.
+type kafkaPublisher struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type kafkaRecord struct {
+	Key   string                `json:"key"`
+	Value eventTypes.Transition `json:"value"`
+}
+
+func newKafkaPublisher(topic string) (*kafkaPublisher, error) {
+	proxyURL, err := config.GetString("event-bus:url")
+	if err != nil {
+		return nil, errors.New("kafka rest proxy url is required")
+	}
+	p := &kafkaPublisher{
+		url: strings.TrimSuffix(proxyURL, "/") + "/topics/" + url.PathEscape(topic),
+	}
+	p.token, _ = config.GetString("event-bus:token")
+	p.client, err = tsuruNet.WithProxyFromConfig(*tsuruNet.Dial15Full60ClientNoKeepAlive, proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, transitions []eventTypes.Transition) error {
+	records := make([]kafkaRecord, len(transitions))
+	for i, t := range transitions {
+		records[i] = kafkaRecord{Key: t.ID, Value: t}
+	}
+	data, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	rsp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(rsp.Body)
+		return errors.Errorf("invalid status code %d: %s", rsp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (p *kafkaPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+const natsTimeout = 10 * time.Second
+
+// natsPublisher publishes transitions to NATS subjects named after the
+// target type and transition, like tsuru.events.app.done, using the plain
+// text NATS protocol. Each batch is followed by a PING, so it's only
+// considered published after the server acknowledges it with a PONG.
+type natsPublisher struct {
+	address string
+	subject string
+	user    string
+	pass    string
+	token   string
+
+	mu    sync.Mutex
+	conn  net.Conn
+	pongs chan struct{}
+	errCh chan error
+}
+
+func newNATSPublisher(subject string) (*natsPublisher, error) {
+	rawURL, err := config.GetString("event-bus:url")
+	if err != nil {
+		return nil, errors.New("nats url is required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "nats" || u.Host == "" {
+		return nil, errors.Errorf("invalid nats url %q, expected nats://host:port", rawURL)
+	}
+	p := &natsPublisher{address: u.Host, subject: subject}
+	if u.User != nil {
+		p.user = u.User.Username()
+		p.pass, _ = u.User.Password()
+	}
+	p.token, _ = config.GetString("event-bus:token")
+	return p, nil
+}
+
+func (p *natsPublisher) subjectFor(t eventTypes.Transition) string {
+	targetType := strings.NewReplacer(".", "_", " ", "_").Replace(t.Target.Type)
+	return fmt.Sprintf("%s.%s.%s", p.subject, targetType, t.Transition)
+}
+
+func (p *natsPublisher) connect() error {
+	conn, err := net.DialTimeout("tcp", p.address, natsTimeout)
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(natsTimeout))
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return errors.Errorf("unexpected nats greeting: %q", strings.TrimSpace(line))
+	}
+	conn.SetReadDeadline(time.Time{})
+	opts := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "tsuru",
+		"lang":     "go",
+	}
+	if p.user != "" {
+		opts["user"], opts["pass"] = p.user, p.pass
+	}
+	if p.token != "" {
+		opts["auth_token"] = p.token
+	}
+	data, err := json.Marshal(opts)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if _, err = fmt.Fprintf(conn, "CONNECT %s\r\n", data); err != nil {
+		conn.Close()
+		return err
+	}
+	p.conn = conn
+	p.pongs = make(chan struct{}, 1)
+	p.errCh = make(chan error, 1)
+	go p.read(conn, reader, p.pongs, p.errCh)
+	return nil
+}
+
+// read handles messages sent by the server: PINGs must be answered to keep
+// the connection alive, PONGs acknowledge published batches and errors
+// invalidate the connection.
+func (p *natsPublisher) read(conn net.Conn, reader *bufio.Reader, pongs chan struct{}, errCh chan error) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			errCh <- err
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PING":
+			p.mu.Lock()
+			_, err = conn.Write([]byte("PONG\r\n"))
+			p.mu.Unlock()
+			if err != nil {
+				errCh <- err
+				return
+			}
+		case line == "PONG":
+			select {
+			case pongs <- struct{}{}:
+			default:
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			errCh <- errors.Errorf("nats error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+			return
+		}
+	}
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, transitions []eventTypes.Transition) error {
+	if p.conn == nil {
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+	var buf strings.Builder
+	for _, t := range transitions {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&buf, "PUB %s %d\r\n%s\r\n", p.subjectFor(t), len(data), data)
+	}
+	buf.WriteString("PING\r\n")
+	p.mu.Lock()
+	_, err := p.conn.Write([]byte(buf.String()))
+	p.mu.Unlock()
+	if err != nil {
+		p.Close()
+		return err
+	}
+	select {
+	case <-p.pongs:
+		return nil
+	case err = <-p.errCh:
+	case <-time.After(natsTimeout):
+		err = errors.New("timeout waiting for nats acknowledgement")
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	p.Close()
+	return err
+}
+
+func (p *natsPublisher) Close() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
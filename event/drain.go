@@ -0,0 +1,114 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/log"
+)
+
+var (
+	ErrDraining = errors.New("tsuru is shutting down, try again later")
+
+	draining     int32
+	drainCheck   = 500 * time.Millisecond
+	cancelGrace  = 30 * time.Second
+	localRunning = runningEvents{events: map[bson.ObjectId]*Event{}}
+)
+
+const drainOwner = "tsuru"
+
+// runningEvents holds the events started by this instance which are still
+// running.
+type runningEvents struct {
+	sync.Mutex
+	events map[bson.ObjectId]*Event
+}
+
+func (r *runningEvents) add(e *Event) {
+	r.Lock()
+	defer r.Unlock()
+	r.events[e.UniqueID] = e
+}
+
+func (r *runningEvents) remove(e *Event) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.events, e.UniqueID)
+}
+
+func (r *runningEvents) list() []*Event {
+	r.Lock()
+	defer r.Unlock()
+	events := make([]*Event, 0, len(r.events))
+	for _, e := range r.events {
+		events = append(events, e)
+	}
+	return events
+}
+
+func (r *runningEvents) wait(ctx context.Context) bool {
+	for {
+		if len(r.list()) == 0 {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(drainCheck):
+		}
+	}
+}
+
+func isDraining() bool {
+	return atomic.LoadInt32(&draining) == 1
+}
+
+// Drain stops accepting new events, failing them with ErrDraining, and waits
+// for the events started by this instance to finish until ctx is done. Then
+// the cancelable events still running, like deploys, are canceled with the
+// given reason and waited for a little longer. Events which don't finish in
+// time are marked as done with the reason as error, so they aren't left
+// running until expired by the event cleaner.
+func Drain(ctx context.Context, reason string) error {
+	atomic.StoreInt32(&draining, 1)
+	if localRunning.wait(ctx) {
+		return nil
+	}
+	var canceled int
+	for _, e := range localRunning.list() {
+		if !e.Cancelable {
+			continue
+		}
+		err := e.TryCancel(reason, drainOwner)
+		if err != nil && err != ErrCancelAlreadyRequested {
+			log.Errorf("[events] unable to cancel event %s: %v", e.UniqueID.Hex(), err)
+			continue
+		}
+		canceled++
+	}
+	if canceled > 0 {
+		graceCtx, cancel := context.WithTimeout(context.Background(), cancelGrace)
+		finished := localRunning.wait(graceCtx)
+		cancel()
+		if finished {
+			return nil
+		}
+	}
+	pending := localRunning.list()
+	for _, e := range pending {
+		err := e.Done(errors.New(reason))
+		if err != nil {
+			log.Errorf("[events] unable to mark event %s as done: %v", e.UniqueID.Hex(), err)
+		}
+	}
+	return errors.Errorf("%d events still running after draining", len(pending))
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) resetDrain() func() {
+	localRunning = runningEvents{events: map[bson.ObjectId]*Event{}}
+	oldDrainCheck, oldCancelGrace := drainCheck, cancelGrace
+	drainCheck = 10 * time.Millisecond
+	cancelGrace = 200 * time.Millisecond
+	return func() {
+		atomic.StoreInt32(&draining, 0)
+		drainCheck, cancelGrace = oldDrainCheck, oldCancelGrace
+	}
+}
+
+func (s *S) newDrainEvent(c *check.C, value string, cancelable bool) *Event {
+	opts := &Opts{
+		Target:     Target{Type: "app", Value: value},
+		Kind:       permission.PermAppDeploy,
+		Owner:      s.token,
+		Cancelable: cancelable,
+		Allowed:    Allowed(permission.PermAppReadEvents),
+	}
+	if cancelable {
+		opts.AllowedCancel = Allowed(permission.PermAppReadEvents)
+	}
+	evt, err := New(opts)
+	c.Assert(err, check.IsNil)
+	return evt
+}
+
+func (s *S) TestDrainRejectsNewEvents(c *check.C) {
+	defer s.resetDrain()()
+	err := Drain(context.Background(), "shutting down")
+	c.Assert(err, check.IsNil)
+	_, err = New(&Opts{
+		Target:  Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.Equals, ErrDraining)
+}
+
+func (s *S) TestDrainWaitsForRunningEvents(c *check.C) {
+	defer s.resetDrain()()
+	evt := s.newDrainEvent(c, "myapp", true)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		evt.Done(nil)
+	}()
+	err := Drain(context.Background(), "shutting down")
+	c.Assert(err, check.IsNil)
+	evts, err := All()
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Running, check.Equals, false)
+	c.Assert(evts[0].Error, check.Equals, "")
+	c.Assert(evts[0].CancelInfo.Asked, check.Equals, false)
+}
+
+func (s *S) TestDrainCancelsCancelableEvents(c *check.C) {
+	defer s.resetDrain()()
+	evt := s.newDrainEvent(c, "myapp", true)
+	ctx, cancel := evt.CancelableContext(context.Background())
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		evt.Done(ctx.Err())
+	}()
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer drainCancel()
+	cancelGrace = 5 * time.Second
+	err := Drain(drainCtx, "shutting down")
+	c.Assert(err, check.IsNil)
+	evts, err := All()
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].Running, check.Equals, false)
+	c.Assert(evts[0].CancelInfo.Canceled, check.Equals, true)
+	c.Assert(evts[0].CancelInfo.Reason, check.Equals, "shutting down")
+	c.Assert(evts[0].CancelInfo.Owner, check.Equals, "tsuru")
+}
+
+func (s *S) TestDrainFinishesPendingEvents(c *check.C) {
+	defer s.resetDrain()()
+	s.newDrainEvent(c, "myapp", true)
+	s.newDrainEvent(c, "otherapp", false)
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer drainCancel()
+	err := Drain(drainCtx, "shutting down")
+	c.Assert(err, check.ErrorMatches, "2 events still running after draining")
+	evts, err := All()
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 2)
+	for _, evt := range evts {
+		c.Assert(evt.Running, check.Equals, false)
+		c.Assert(evt.Error, check.Equals, "shutting down")
+	}
+	c.Assert(localRunning.list(), check.HasLen, 0)
+}
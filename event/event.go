@@ -27,8 +27,10 @@ import (
 	"github.com/tsuru/tsuru/db/storage"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/readcache"
 	"github.com/tsuru/tsuru/servicemanager"
 	authTypes "github.com/tsuru/tsuru/types/auth"
+	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	"github.com/tsuru/tsuru/types/tracker"
 )
@@ -62,6 +64,7 @@ const (
 	rejectLocked    = "locked"
 	rejectBlocked   = "blocked"
 	rejectThrottled = "throttled"
+	rejectDraining  = "draining"
 
 	timeFormat = "2006-01-02 15:04:05 -0700"
 )
@@ -114,6 +117,16 @@ var (
 	TargetTypeWebhook         = TargetType("webhook")
 	TargetTypeGC              = TargetType("gc")
 	TargetTypeRouter          = TargetType("router")
+	TargetTypeAppTemplate     = TargetType("app-template")
+	TargetTypeProject         = TargetType("project")
+	TargetTypeEnvGroup        = TargetType("env-group")
+	TargetTypeNotification    = TargetType("notification-channel")
+	TargetTypeIntegration     = TargetType("integration")
+	TargetTypeGitOps          = TargetType("gitops")
+	TargetTypeACMECertificate = TargetType("acme-certificate")
+	TargetTypePipelineHook    = TargetType("pipeline-hook")
+	TargetTypeDirectorySync   = TargetType("directory-sync")
+	TargetTypePermTemplate    = TargetType("permission-template")
 )
 
 const (
@@ -219,6 +232,9 @@ type eventData struct {
 	Allowed         AllowedPermission
 	AllowedCancel   AllowedPermission
 	Instance        tracker.TrackedInstance
+	// AccessGrants are the IDs of the access grants active for the owner
+	// when the event started, flagging actions done with elevated access.
+	AccessGrants []string `bson:",omitempty"`
 }
 
 type LogEntry struct {
@@ -283,6 +299,26 @@ func GetTargetType(t string) (TargetType, error) {
 		return TargetTypeWebhook, nil
 	case "router":
 		return TargetTypeRouter, nil
+	case "app-template":
+		return TargetTypeAppTemplate, nil
+	case "project":
+		return TargetTypeProject, nil
+	case "env-group":
+		return TargetTypeEnvGroup, nil
+	case "notification-channel":
+		return TargetTypeNotification, nil
+	case "integration":
+		return TargetTypeIntegration, nil
+	case "gitops":
+		return TargetTypeGitOps, nil
+	case "acme-certificate":
+		return TargetTypeACMECertificate, nil
+	case "pipeline-hook":
+		return TargetTypePipelineHook, nil
+	case "directory-sync":
+		return TargetTypeDirectorySync, nil
+	case "permission-template":
+		return TargetTypePermTemplate, nil
 	}
 	return TargetType(""), ErrInvalidTargetType
 }
@@ -383,6 +419,8 @@ type Event struct {
 	eventData
 	logMu     sync.Mutex
 	logWriter io.Writer
+	stageMu   sync.Mutex
+	stages    map[string]*stageSpan
 }
 
 type ExtraTarget struct {
@@ -457,6 +495,7 @@ type Filter struct {
 	Until          time.Time
 	Running        *bool
 	ErrorOnly      bool
+	AccessGrant    bool
 	Raw            bson.M
 	AllowedTargets []TargetFilter
 	Permissions    []permission.Permission
@@ -580,6 +619,9 @@ func (f *Filter) toQuery() (bson.M, error) {
 	if f.ErrorOnly {
 		query["error"] = bson.M{"$ne": ""}
 	}
+	if f.AccessGrant {
+		query["accessgrants.0"] = bson.M{"$exists": true}
+	}
 	if f.Raw != nil {
 		for k, v := range f.Raw {
 			query[k] = v
@@ -832,6 +874,15 @@ func checkThrottling(coll *storage.Collection, target *Target, kind *Kind, allTa
 }
 
 func newEvt(opts *Opts) (evt *Event, err error) {
+	defer func() {
+		if err == nil {
+			evt.holdCache()
+			evt.publish(eventTypes.TransitionCreated)
+			if servicemanager.Notification != nil {
+				servicemanager.Notification.Notify(evt.UniqueID.Hex(), eventTypes.TransitionCreated)
+			}
+		}
+	}()
 	if opts.RetryTimeout == 0 {
 		return newEvtOnce(opts)
 	}
@@ -867,6 +918,9 @@ func newEvtOnce(opts *Opts) (evt *Event, err error) {
 			case ErrThrottled:
 				reason = rejectThrottled
 			}
+			if err == ErrDraining {
+				reason = rejectDraining
+			}
 			if !(reason == rejectBlocked) {
 				eventCurrent.WithLabelValues(k.Name).Dec()
 			}
@@ -875,6 +929,9 @@ func newEvtOnce(opts *Opts) (evt *Event, err error) {
 		}
 	}()
 	updater.start()
+	if isDraining() {
+		return nil, ErrDraining
+	}
 	if opts == nil {
 		return nil, ErrNoOpts
 	}
@@ -916,6 +973,16 @@ func newEvtOnce(opts *Opts) (evt *Event, err error) {
 			o.Name = opts.Owner.GetUserName()
 		}
 	}
+	var accessGrants []string
+	if o.Type == OwnerTypeUser {
+		grants, grantsErr := auth.ActiveAccessGrants(o.Name)
+		if grantsErr != nil {
+			return nil, grantsErr
+		}
+		for _, g := range grants {
+			accessGrants = append(accessGrants, g.ID.Hex())
+		}
+	}
 	conn, err := db.Conn()
 	if err != nil {
 		return nil, err
@@ -968,6 +1035,7 @@ func newEvtOnce(opts *Opts) (evt *Event, err error) {
 		Allowed:         opts.Allowed,
 		AllowedCancel:   opts.AllowedCancel,
 		Instance:        instance,
+		AccessGrants:    accessGrants,
 	}}
 	maxRetries := 1
 	for i := 0; i < maxRetries+1; i++ {
@@ -984,6 +1052,7 @@ func newEvtOnce(opts *Opts) (evt *Event, err error) {
 				return nil, err
 			}
 			updater.add(id)
+			localRunning.add(evt)
 			return evt, nil
 		}
 		if mgo.IsDup(err) {
@@ -1098,9 +1167,13 @@ func (e *Event) SetOtherCustomData(data interface{}) error {
 	}
 	defer conn.Close()
 	coll := conn.Events()
-	return coll.UpdateId(e.ID, bson.M{
+	err = coll.UpdateId(e.ID, bson.M{
 		"$set": bson.M{"othercustomdata": data},
 	})
+	if err == nil {
+		e.publish(eventTypes.TransitionUpdated)
+	}
+	return err
 }
 
 func (e *Event) Logf(format string, params ...interface{}) {
@@ -1185,6 +1258,9 @@ func (e *Event) TryCancel(reason, owner string) error {
 		}
 		err = ErrCancelAlreadyRequested
 	}
+	if err == nil {
+		e.publish(eventTypes.TransitionUpdated)
+	}
 	return err
 }
 
@@ -1211,7 +1287,11 @@ func (e *Event) AckCancel() (bool, error) {
 	if err == mgo.ErrNotFound {
 		return false, nil
 	}
-	return err == nil, err
+	if err != nil {
+		return false, err
+	}
+	e.publish(eventTypes.TransitionCanceled)
+	return true, nil
 }
 
 func (e *Event) StartData(value interface{}) error {
@@ -1235,10 +1315,47 @@ func (e *Event) OtherData(value interface{}) error {
 	return e.OtherCustomData.Unmarshal(value)
 }
 
+// cacheKeys returns the read cache entries for the targets of the event.
+func (e *Event) cacheKeys() map[string][]string {
+	keys := map[string][]string{}
+	targets := []Target{e.Target}
+	for _, extra := range e.ExtraTargets {
+		targets = append(targets, extra.Target)
+	}
+	for _, target := range targets {
+		switch target.Type {
+		case TargetTypeApp:
+			keys[readcache.Apps] = append(keys[readcache.Apps], target.Value)
+		case TargetTypePool:
+			keys[readcache.Pools] = append(keys[readcache.Pools], target.Value)
+		}
+	}
+	return keys
+}
+
+// holdCache bypasses the read cache for the targets of the event while it's
+// running, as their documents are likely to be updated by it.
+func (e *Event) holdCache() {
+	for collection, keys := range e.cacheKeys() {
+		for _, key := range keys {
+			readcache.Hold(collection, key)
+		}
+	}
+}
+
+func (e *Event) releaseCache() {
+	for collection, keys := range e.cacheKeys() {
+		for _, key := range keys {
+			readcache.Release(collection, key)
+		}
+	}
+}
+
 func (e *Event) done(evtErr error, customData interface{}, abort bool) (err error) {
 	// Done will be usually called in a defer block ignoring errors. This is
 	// why we log error messages here.
 	defer func() {
+		e.releaseCache()
 		e.fillLegacyLog()
 		eventDuration.WithLabelValues(e.Kind.Name).Observe(time.Since(e.StartTime).Seconds())
 		eventCurrent.WithLabelValues(e.Kind.Name).Dec()
@@ -1248,9 +1365,19 @@ func (e *Event) done(evtErr error, customData interface{}, abort bool) (err erro
 			if !abort && servicemanager.Webhook != nil {
 				servicemanager.Webhook.Notify(e.UniqueID.Hex())
 			}
+			if !abort && servicemanager.Notification != nil {
+				servicemanager.Notification.Notify(e.UniqueID.Hex(), eventTypes.TransitionDone)
+			}
+			if !abort && servicemanager.Audit != nil {
+				servicemanager.Audit.Export(e.auditRecord())
+			}
+			if !abort {
+				e.publish(eventTypes.TransitionDone)
+			}
 		}
 	}()
 	updater.remove(e.ID)
+	localRunning.remove(e)
 	conn, err := db.Conn()
 	if err != nil {
 		return err
@@ -1296,6 +1423,56 @@ func (e *Event) done(evtErr error, customData interface{}, abort bool) (err erro
 	return coll.Insert(e.eventData)
 }
 
+func (e *Event) auditRecord() eventTypes.AuditRecord {
+	return eventTypes.AuditRecord{
+		ID:          e.UniqueID.Hex(),
+		Kind:        e.Kind.Name,
+		KindType:    string(e.Kind.Type),
+		Owner:       e.Owner.Name,
+		OwnerType:   string(e.Owner.Type),
+		TargetType:  string(e.Target.Type),
+		TargetValue: e.Target.Value,
+		SourceIP:    e.SourceIP,
+		StartTime:   e.StartTime,
+		EndTime:     e.EndTime,
+		Duration:    e.EndTime.Sub(e.StartTime).Seconds(),
+		Success:     e.Error == "",
+		Error:       e.Error,
+	}
+}
+
+// publish sends the lifecycle transition of the event to the event bus, if
+// one is configured.
+func (e *Event) publish(transition string) {
+	if servicemanager.EventPublisher == nil {
+		return
+	}
+	t := eventTypes.Transition{
+		Transition: transition,
+		Timestamp:  time.Now().UTC(),
+		ID:         e.UniqueID.Hex(),
+		Kind:       e.Kind.Name,
+		KindType:   string(e.Kind.Type),
+		Owner:      e.Owner.Name,
+		OwnerType:  string(e.Owner.Type),
+		Target:     eventTypes.TransitionTarget{Type: string(e.Target.Type), Value: e.Target.Value},
+		StartTime:  e.StartTime,
+		Running:    e.Running,
+		Error:      e.Error,
+	}
+	for _, et := range e.ExtraTargets {
+		t.ExtraTargets = append(t.ExtraTargets, eventTypes.TransitionTarget{Type: string(et.Target.Type), Value: et.Target.Value})
+	}
+	if !e.EndTime.IsZero() {
+		endTime := e.EndTime
+		t.EndTime = &endTime
+	}
+	if e.CancelInfo.Asked {
+		t.CancelReason = e.CancelInfo.Reason
+	}
+	servicemanager.EventPublisher.Publish(t)
+}
+
 func (e *Event) Log() string {
 	if len(e.StructuredLog) == 0 {
 		return e.eventData.Log
@@ -27,7 +27,9 @@ import (
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/safe"
+	"github.com/tsuru/tsuru/servicemanager"
 	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	eventTypes "github.com/tsuru/tsuru/types/event"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 	trackerTypes "github.com/tsuru/tsuru/types/tracker"
 	"golang.org/x/crypto/bcrypt"
@@ -66,6 +68,132 @@ func (s *S) SetUpTest(c *check.C) {
 	servicemock.SetMockService(&servicemock.MockService{})
 }
 
+type fakeAuditService struct {
+	records []eventTypes.AuditRecord
+}
+
+func (f *fakeAuditService) Export(record eventTypes.AuditRecord) {
+	f.records = append(f.records, record)
+}
+
+func (s *S) TestDoneExportsAuditRecord(c *check.C) {
+	audit := &fakeAuditService{}
+	servicemanager.Audit = audit
+	defer func() { servicemanager.Audit = nil }()
+	evt, err := New(&Opts{
+		Target:     Target{Type: "app", Value: "myapp"},
+		Kind:       permission.PermAppUpdateEnvSet,
+		Owner:      s.token,
+		RemoteAddr: "10.0.0.1:52314",
+		Allowed:    Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(errors.New("env not set"))
+	c.Assert(err, check.IsNil)
+	c.Assert(audit.records, check.HasLen, 1)
+	record := audit.records[0]
+	c.Assert(record.Duration >= 0, check.Equals, true)
+	record.Duration = 0
+	c.Assert(record, check.DeepEquals, eventTypes.AuditRecord{
+		ID:          evt.UniqueID.Hex(),
+		Kind:        "app.update.env.set",
+		KindType:    "permission",
+		Owner:       s.token.GetUserName(),
+		OwnerType:   "user",
+		TargetType:  "app",
+		TargetValue: "myapp",
+		SourceIP:    "10.0.0.1",
+		StartTime:   evt.StartTime,
+		EndTime:     evt.EndTime,
+		Success:     false,
+		Error:       "env not set",
+	})
+}
+
+type fakeEventPublisher struct {
+	transitions []eventTypes.Transition
+}
+
+func (f *fakeEventPublisher) Publish(t eventTypes.Transition) {
+	f.transitions = append(f.transitions, t)
+}
+
+func (s *S) TestEventPublishesTransitions(c *check.C) {
+	publisher := &fakeEventPublisher{}
+	servicemanager.EventPublisher = publisher
+	defer func() { servicemanager.EventPublisher = nil }()
+	evt, err := New(&Opts{
+		Target:        Target{Type: "app", Value: "myapp"},
+		ExtraTargets:  []ExtraTarget{{Target: Target{Type: "pool", Value: "pool1"}}},
+		Kind:          permission.PermAppUpdateEnvSet,
+		Owner:         s.token,
+		Cancelable:    true,
+		Allowed:       Allowed(permission.PermAppReadEvents),
+		AllowedCancel: Allowed(permission.PermAppUpdateEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.TryCancel("because", "admin@example.com")
+	c.Assert(err, check.IsNil)
+	err = evt.Done(errors.New("env not set"))
+	c.Assert(err, check.IsNil)
+	c.Assert(publisher.transitions, check.HasLen, 3)
+	var transitions []string
+	for _, t := range publisher.transitions {
+		c.Assert(t.ID, check.Equals, evt.UniqueID.Hex())
+		c.Assert(t.Kind, check.Equals, "app.update.env.set")
+		c.Assert(t.Owner, check.Equals, s.token.GetUserName())
+		c.Assert(t.Target, check.Equals, eventTypes.TransitionTarget{Type: "app", Value: "myapp"})
+		c.Assert(t.ExtraTargets, check.DeepEquals, []eventTypes.TransitionTarget{{Type: "pool", Value: "pool1"}})
+		transitions = append(transitions, t.Transition)
+	}
+	c.Assert(transitions, check.DeepEquals, []string{
+		eventTypes.TransitionCreated,
+		eventTypes.TransitionUpdated,
+		eventTypes.TransitionDone,
+	})
+	c.Assert(publisher.transitions[0].Running, check.Equals, true)
+	c.Assert(publisher.transitions[0].EndTime, check.IsNil)
+	c.Assert(publisher.transitions[1].CancelReason, check.Equals, "because")
+	done := publisher.transitions[2]
+	c.Assert(done.Running, check.Equals, false)
+	c.Assert(done.Error, check.Equals, "env not set")
+	c.Assert(done.EndTime, check.NotNil)
+}
+
+func (s *S) TestNewWithAccessGrant(c *check.C) {
+	_, err := permission.NewRole("app-admin", "app", "")
+	c.Assert(err, check.IsNil)
+	grant := auth.AccessGrant{User: s.token.GetUserName(), Role: "app-admin", ContextValue: "myapp", Reason: "incident"}
+	err = auth.GrantAccess(&grant, time.Hour)
+	c.Assert(err, check.IsNil)
+	evt, err := New(&Opts{
+		Target:  Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.AccessGrants, check.DeepEquals, []string{grant.ID.Hex()})
+	err = evt.Done(nil)
+	c.Assert(err, check.IsNil)
+	evts, err := List(&Filter{AccessGrant: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(evts, check.HasLen, 1)
+	c.Assert(evts[0].UniqueID, check.Equals, evt.UniqueID)
+	err = auth.RevokeAccessGrant(grant.ID.Hex(), "admin@example.com")
+	c.Assert(err, check.IsNil)
+	evt, err = New(&Opts{
+		Target:  Target{Type: "app", Value: "myapp"},
+		Kind:    permission.PermAppUpdateEnvSet,
+		Owner:   s.token,
+		Allowed: Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(evt.AccessGrants, check.IsNil)
+	err = evt.Done(nil)
+	c.Assert(err, check.IsNil)
+}
+
 func (s *S) TestNewDone(c *check.C) {
 	evt, err := New(&Opts{
 		Target:  Target{Type: "app", Value: "myapp"},
@@ -0,0 +1,167 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+)
+
+const userAgent = "tsuru-notification-client/1.0"
+
+type driverFunc func(ch eventTypes.NotificationChannel, msg message) (*http.Request, error)
+
+var drivers = map[string]driverFunc{
+	eventTypes.NotificationDriverSlack:  slackRequest,
+	eventTypes.NotificationDriverTeams:  teamsRequest,
+	eventTypes.NotificationDriverMatrix: matrixRequest,
+}
+
+func send(ch eventTypes.NotificationChannel, msg message) error {
+	driver, ok := drivers[ch.Driver]
+	if !ok {
+		return errors.Errorf("unknown notification driver %q", ch.Driver)
+	}
+	req, err := driver(ch, msg)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	client, err := tsuruNet.WithProxyFromConfig(*tsuruNet.Dial15Full60ClientNoKeepAlive, req.URL.String())
+	if err != nil {
+		return err
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		data, _ := ioutil.ReadAll(rsp.Body)
+		return errors.Errorf("invalid status code sending notification: %d: %s", rsp.StatusCode, string(data))
+	}
+	return nil
+}
+
+func jsonRequest(method, u string, body interface{}) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// slackRequest posts the message to a Slack incoming webhook as an
+// attachment colored by the outcome.
+func slackRequest(ch eventTypes.NotificationChannel, msg message) (*http.Request, error) {
+	type field struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+		Short bool   `json:"short"`
+	}
+	attachment := map[string]interface{}{
+		"fallback": msg.text(),
+		"color":    msg.color(),
+		"title":    msg.Title,
+	}
+	if msg.Link != "" {
+		attachment["title_link"] = msg.Link
+	}
+	var fields []field
+	for _, f := range msg.Fields {
+		fields = append(fields, field{Title: f[0], Value: f[1], Short: true})
+	}
+	attachment["fields"] = fields
+	if msg.Error != "" {
+		attachment["text"] = "```" + msg.Error + "```"
+	}
+	return jsonRequest(http.MethodPost, ch.URL, map[string]interface{}{
+		"text":        msg.Title,
+		"attachments": []interface{}{attachment},
+	})
+}
+
+// teamsRequest posts the message to a Microsoft Teams incoming webhook as a
+// message card.
+func teamsRequest(ch eventTypes.NotificationChannel, msg message) (*http.Request, error) {
+	type fact struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	var facts []fact
+	for _, f := range msg.Fields {
+		facts = append(facts, fact{Name: f[0], Value: f[1]})
+	}
+	section := map[string]interface{}{
+		"activityTitle": msg.Title,
+		"facts":         facts,
+	}
+	if msg.Error != "" {
+		section["text"] = msg.Error
+	}
+	card := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "http://schema.org/extensions",
+		"summary":    msg.Title,
+		"themeColor": strings.TrimPrefix(msg.color(), "#"),
+		"sections":   []interface{}{section},
+	}
+	if msg.Link != "" {
+		card["potentialAction"] = []interface{}{map[string]interface{}{
+			"@type":   "OpenUri",
+			"name":    "View event",
+			"targets": []interface{}{map[string]string{"os": "default", "uri": msg.Link}},
+		}}
+	}
+	return jsonRequest(http.MethodPost, ch.URL, card)
+}
+
+// matrixRequest sends the message to a Matrix room using the client-server
+// API. The transaction ID is derived from the event and notification, so
+// retries don't duplicate messages.
+func matrixRequest(ch eventTypes.NotificationChannel, msg message) (*http.Request, error) {
+	var formatted strings.Builder
+	fmt.Fprintf(&formatted, "<strong>%s</strong>", html.EscapeString(msg.Title))
+	for _, f := range msg.Fields {
+		fmt.Fprintf(&formatted, "<br>%s: %s", html.EscapeString(f[0]), html.EscapeString(f[1]))
+	}
+	if msg.Error != "" {
+		fmt.Fprintf(&formatted, "<br><pre>%s</pre>", html.EscapeString(msg.Error))
+	}
+	if msg.Link != "" {
+		fmt.Fprintf(&formatted, `<br><a href="%s">View event</a>`, html.EscapeString(msg.Link))
+	}
+	u := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(ch.URL, "/"),
+		url.PathEscape(ch.Room),
+		url.PathEscape(msg.ID+"-"+msg.Event),
+	)
+	req, err := jsonRequest(http.MethodPut, u, map[string]string{
+		"msgtype":        "m.text",
+		"body":           msg.text(),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+ch.Token)
+	return req, nil
+}
@@ -0,0 +1,381 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package notification posts messages about deploys, healings and autoscale
+// runs to chat channels subscribed by teams to apps and pools.
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	"github.com/tsuru/tsuru/storage"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	"github.com/tsuru/tsuru/validation"
+)
+
+var (
+	_ eventTypes.NotificationService = &notificationService{}
+
+	chanBufferSize = 1000
+
+	notificationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_notifications_sent_total",
+		Help: "The total number of chat notifications sent",
+	}, []string{"driver"})
+	notificationsError = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_notifications_sent_error",
+		Help: "The total number of chat notifications that failed to be sent",
+	}, []string{"driver"})
+)
+
+func init() {
+	prometheus.MustRegister(notificationsTotal, notificationsError)
+}
+
+type notifyRequest struct {
+	evtID      string
+	transition string
+}
+
+type notificationService struct {
+	storage eventTypes.NotificationStorage
+	ch      chan notifyRequest
+	quitCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+func NotificationService() (eventTypes.NotificationService, error) {
+	dbDriver, err := storage.GetCurrentDbDriver()
+	if err != nil {
+		dbDriver, err = storage.GetDefaultDbDriver()
+		if err != nil {
+			return nil, err
+		}
+	}
+	s := &notificationService{
+		storage: dbDriver.NotificationStorage,
+		ch:      make(chan notifyRequest, chanBufferSize),
+		quitCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	go s.run()
+	shutdown.Register(s)
+	return s, nil
+}
+
+func (s *notificationService) Shutdown(ctx context.Context) error {
+	close(s.quitCh)
+	select {
+	case <-s.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Notify queues the event transition to be checked against the subscribed
+// channels. Only created and done transitions may trigger notifications.
+func (s *notificationService) Notify(evtID, transition string) {
+	if transition != eventTypes.TransitionCreated && transition != eventTypes.TransitionDone {
+		return
+	}
+	select {
+	case s.ch <- notifyRequest{evtID: evtID, transition: transition}:
+	case <-s.quitCh:
+	}
+}
+
+func (s *notificationService) run() {
+	defer close(s.doneCh)
+	for {
+		select {
+		case req := <-s.ch:
+			err := s.handleEvent(req.evtID, req.transition)
+			if err != nil {
+				log.Errorf("[notifications] error handling notifications for event %q: %v", req.evtID, err)
+			}
+		case <-s.quitCh:
+			return
+		}
+	}
+}
+
+func isDeployKind(kind event.Kind) bool {
+	if kind.Type != event.KindTypePermission {
+		return false
+	}
+	return kind.Name == permission.PermAppDeploy.FullName() ||
+		kind.Name == permission.PermAppUpdateDeployRollback.FullName()
+}
+
+// notificationEvent returns which notification event, if any, the
+// transition of the event represents.
+func notificationEvent(evt *event.Event, transition string) string {
+	if isDeployKind(evt.Kind) {
+		switch {
+		case transition == eventTypes.TransitionCreated:
+			return eventTypes.NotificationDeployStart
+		case evt.Error != "":
+			return eventTypes.NotificationDeployFailure
+		default:
+			return eventTypes.NotificationDeploySuccess
+		}
+	}
+	if transition != eventTypes.TransitionDone || evt.Kind.Type != event.KindTypeInternal {
+		return ""
+	}
+	switch evt.Kind.Name {
+	case "healer":
+		return eventTypes.NotificationHealing
+	case "autoscale":
+		return eventTypes.NotificationAutoscale
+	}
+	return ""
+}
+
+// subscriptionTargets returns the apps and pools related to the event,
+// including the pools of its apps.
+func subscriptionTargets(ctx context.Context, evt *event.Event) (apps, pools []string) {
+	targets := []event.Target{evt.Target}
+	for _, et := range evt.ExtraTargets {
+		targets = append(targets, et.Target)
+	}
+	for _, t := range targets {
+		switch t.Type {
+		case event.TargetTypeApp:
+			apps = append(apps, t.Value)
+			if servicemanager.App == nil {
+				continue
+			}
+			a, err := servicemanager.App.GetByName(ctx, t.Value)
+			if err != nil {
+				log.Errorf("[notifications] unable to get pool for app %q: %v", t.Value, err)
+				continue
+			}
+			pools = append(pools, a.GetPool())
+		case event.TargetTypePool:
+			pools = append(pools, t.Value)
+		}
+	}
+	return apps, pools
+}
+
+func (s *notificationService) handleEvent(evtID, transition string) error {
+	evt, err := event.GetByHexID(evtID)
+	if err != nil {
+		return err
+	}
+	notification := notificationEvent(evt, transition)
+	if notification == "" {
+		return nil
+	}
+	apps, pools := subscriptionTargets(context.Background(), evt)
+	channels, err := s.storage.FindBySubscription(apps, pools)
+	if err != nil {
+		return err
+	}
+	msg := newMessage(evt, notification)
+	for _, ch := range channels {
+		if !ch.Wants(notification) {
+			continue
+		}
+		err = send(ch, msg)
+		notificationsTotal.WithLabelValues(ch.Driver).Inc()
+		if err != nil {
+			notificationsError.WithLabelValues(ch.Driver).Inc()
+			log.Errorf("[notifications] error notifying channel %q for event %q: %v", ch.Name, evtID, err)
+		}
+	}
+	return nil
+}
+
+// eventURL returns the link to the event, built from the
+// notifications:event-url template, where {id} is replaced by the event ID,
+// or from the API host.
+func eventURL(evtID string) string {
+	tpl, _ := config.GetString("notifications:event-url")
+	if tpl == "" {
+		host, _ := config.GetString("host")
+		if host == "" {
+			return ""
+		}
+		tpl = strings.TrimSuffix(host, "/") + "/events/{id}"
+	}
+	return strings.Replace(tpl, "{id}", evtID, -1)
+}
+
+func validateChannel(ch eventTypes.NotificationChannel) error {
+	if !validDriver(ch.Driver) {
+		return &tsuruErrors.ValidationError{
+			Message: fmt.Sprintf("invalid notification driver %q, must be one of: %s", ch.Driver, strings.Join(eventTypes.NotificationDrivers, ", ")),
+		}
+	}
+	if ch.URL == "" {
+		return &tsuruErrors.ValidationError{Message: "notification channel url must not be empty"}
+	}
+	u, err := url.Parse(ch.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("notification channel url is not valid: %q", ch.URL)}
+	}
+	if ch.Driver == eventTypes.NotificationDriverMatrix && (ch.Room == "" || ch.Token == "") {
+		return &tsuruErrors.ValidationError{Message: "matrix notification channels require room and token"}
+	}
+	if len(ch.Apps) == 0 && len(ch.Pools) == 0 {
+		return &tsuruErrors.ValidationError{Message: "notification channel must be subscribed to at least one app or pool"}
+	}
+	for _, e := range ch.Events {
+		if !validEvent(e) {
+			return &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("invalid notification event %q, must be one of: %s", e, strings.Join(eventTypes.NotificationEvents, ", ")),
+			}
+		}
+	}
+	return nil
+}
+
+func validDriver(driver string) bool {
+	for _, d := range eventTypes.NotificationDrivers {
+		if d == driver {
+			return true
+		}
+	}
+	return false
+}
+
+func validEvent(e string) bool {
+	for _, valid := range eventTypes.NotificationEvents {
+		if valid == e {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *notificationService) Create(ch eventTypes.NotificationChannel) error {
+	if ch.Name == "" {
+		return &tsuruErrors.ValidationError{Message: "notification channel name must not be empty"}
+	}
+	if !validation.ValidateName(ch.Name) {
+		return &tsuruErrors.ValidationError{Message: "Invalid notification channel name, name should have at most 40 " +
+			"characters, containing only lower case letters, numbers or dashes, " +
+			"starting with a letter."}
+	}
+	err := validateChannel(ch)
+	if err != nil {
+		return err
+	}
+	return s.storage.Insert(ch)
+}
+
+func (s *notificationService) Update(ch eventTypes.NotificationChannel) error {
+	err := validateChannel(ch)
+	if err != nil {
+		return err
+	}
+	return s.storage.Update(ch)
+}
+
+func (s *notificationService) Delete(name string) error {
+	return s.storage.Delete(name)
+}
+
+func (s *notificationService) Find(name string) (eventTypes.NotificationChannel, error) {
+	ch, err := s.storage.FindByName(name)
+	if err != nil {
+		return eventTypes.NotificationChannel{}, err
+	}
+	return *ch, nil
+}
+
+func (s *notificationService) List(teams []string) ([]eventTypes.NotificationChannel, error) {
+	return s.storage.FindAllByTeams(teams)
+}
+
+// message is the driver independent content of a notification.
+type message struct {
+	ID      string
+	Event   string
+	Title   string
+	Fields  [][2]string
+	Error   string
+	Link    string
+	Success bool
+}
+
+func newMessage(evt *event.Event, notification string) message {
+	target := fmt.Sprintf("%s %s", evt.Target.Type, evt.Target.Value)
+	msg := message{
+		ID:      evt.UniqueID.Hex(),
+		Event:   notification,
+		Error:   evt.Error,
+		Link:    eventURL(evt.UniqueID.Hex()),
+		Success: evt.Error == "",
+	}
+	switch notification {
+	case eventTypes.NotificationDeployStart:
+		msg.Title = fmt.Sprintf("Deploy of %s started", target)
+	case eventTypes.NotificationDeploySuccess:
+		msg.Title = fmt.Sprintf("Deploy of %s succeeded", target)
+	case eventTypes.NotificationDeployFailure:
+		msg.Title = fmt.Sprintf("Deploy of %s failed", target)
+	case eventTypes.NotificationHealing:
+		msg.Title = fmt.Sprintf("Healing of %s %s", target, outcome(evt))
+	case eventTypes.NotificationAutoscale:
+		msg.Title = fmt.Sprintf("Autoscale of %s %s", target, outcome(evt))
+	}
+	msg.Fields = append(msg.Fields, [2]string{"Kind", evt.Kind.Name})
+	if evt.Owner.Name != "" {
+		msg.Fields = append(msg.Fields, [2]string{"Owner", evt.Owner.Name})
+	}
+	if !evt.EndTime.IsZero() {
+		msg.Fields = append(msg.Fields, [2]string{"Duration", evt.EndTime.Sub(evt.StartTime).Round(time.Second).String()})
+	}
+	return msg
+}
+
+func outcome(evt *event.Event) string {
+	if evt.Error != "" {
+		return "failed"
+	}
+	return "finished"
+}
+
+// text returns the message as plain text, used as fallback by drivers.
+func (m message) text() string {
+	parts := []string{m.Title}
+	for _, f := range m.Fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", f[0], f[1]))
+	}
+	if m.Error != "" {
+		parts = append(parts, "Error: "+m.Error)
+	}
+	if m.Link != "" {
+		parts = append(parts, m.Link)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// color returns the hex color of the message: blue for deploy starts, green
+// for successes and red for failures.
+func (m message) color() string {
+	switch {
+	case m.Event == eventTypes.NotificationDeployStart:
+		return "#439FE0"
+	case m.Success:
+		return "#2EB886"
+	}
+	return "#D50200"
+}
@@ -0,0 +1,324 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/servicemanager"
+	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("host")
+	config.Unset("notifications")
+	servicemanager.App = nil
+}
+
+type fakeApp struct {
+	appTypes.App
+	name, pool string
+}
+
+func (a *fakeApp) GetName() string { return a.name }
+func (a *fakeApp) GetPool() string { return a.pool }
+
+type fakeAppService struct {
+	appTypes.AppService
+	apps map[string]string
+}
+
+func (f *fakeAppService) GetByName(ctx context.Context, name string) (appTypes.App, error) {
+	pool, ok := f.apps[name]
+	if !ok {
+		return nil, appTypes.ErrAppNotFound
+	}
+	return &fakeApp{name: name, pool: pool}, nil
+}
+
+func newTestEvent(kind event.Kind, errMsg string) *event.Event {
+	evt := &event.Event{}
+	evt.UniqueID = bson.ObjectIdHex("5f0a1b2c3d4e5f6a7b8c9d0e")
+	evt.Target = event.Target{Type: event.TargetTypeApp, Value: "myapp"}
+	evt.Kind = kind
+	evt.Owner = event.Owner{Type: "user", Name: "me@example.com"}
+	evt.StartTime = time.Date(2026, time.October, 17, 10, 0, 0, 0, time.UTC)
+	evt.EndTime = evt.StartTime.Add(90 * time.Second)
+	evt.Error = errMsg
+	return evt
+}
+
+func (s *S) TestNotificationEvent(c *check.C) {
+	deploy := event.Kind{Type: event.KindTypePermission, Name: "app.deploy"}
+	rollback := event.Kind{Type: event.KindTypePermission, Name: "app.update.deploy.rollback"}
+	healer := event.Kind{Type: event.KindTypeInternal, Name: "healer"}
+	autoscale := event.Kind{Type: event.KindTypeInternal, Name: "autoscale"}
+	envSet := event.Kind{Type: event.KindTypePermission, Name: "app.update.env.set"}
+	tests := []struct {
+		kind       event.Kind
+		err        string
+		transition string
+		expected   string
+	}{
+		{deploy, "", eventTypes.TransitionCreated, eventTypes.NotificationDeployStart},
+		{deploy, "", eventTypes.TransitionDone, eventTypes.NotificationDeploySuccess},
+		{deploy, "build failed", eventTypes.TransitionDone, eventTypes.NotificationDeployFailure},
+		{rollback, "", eventTypes.TransitionDone, eventTypes.NotificationDeploySuccess},
+		{healer, "", eventTypes.TransitionCreated, ""},
+		{healer, "", eventTypes.TransitionDone, eventTypes.NotificationHealing},
+		{autoscale, "", eventTypes.TransitionDone, eventTypes.NotificationAutoscale},
+		{envSet, "", eventTypes.TransitionDone, ""},
+		{event.Kind{Type: event.KindTypeInternal, Name: "app.deploy"}, "", eventTypes.TransitionDone, ""},
+	}
+	for i, tt := range tests {
+		evt := newTestEvent(tt.kind, tt.err)
+		c.Assert(notificationEvent(evt, tt.transition), check.Equals, tt.expected, check.Commentf("failed test %d", i))
+	}
+}
+
+func (s *S) TestSubscriptionTargets(c *check.C) {
+	servicemanager.App = &fakeAppService{apps: map[string]string{"myapp": "pool1"}}
+	evt := newTestEvent(event.Kind{Type: event.KindTypeInternal, Name: "healer"}, "")
+	evt.Target = event.Target{Type: event.TargetTypeContainer, Value: "abc"}
+	evt.ExtraTargets = []event.ExtraTarget{
+		{Target: event.Target{Type: event.TargetTypeApp, Value: "myapp"}},
+		{Target: event.Target{Type: event.TargetTypeApp, Value: "unknown"}},
+		{Target: event.Target{Type: event.TargetTypePool, Value: "pool2"}},
+	}
+	apps, pools := subscriptionTargets(context.Background(), evt)
+	c.Assert(apps, check.DeepEquals, []string{"myapp", "unknown"})
+	c.Assert(pools, check.DeepEquals, []string{"pool1", "pool2"})
+}
+
+func (s *S) TestNewMessage(c *check.C) {
+	config.Set("host", "https://tsuru.example.com/")
+	evt := newTestEvent(event.Kind{Type: event.KindTypePermission, Name: "app.deploy"}, "build failed")
+	msg := newMessage(evt, eventTypes.NotificationDeployFailure)
+	c.Assert(msg, check.DeepEquals, message{
+		ID:    "5f0a1b2c3d4e5f6a7b8c9d0e",
+		Event: eventTypes.NotificationDeployFailure,
+		Title: "Deploy of app myapp failed",
+		Fields: [][2]string{
+			{"Kind", "app.deploy"},
+			{"Owner", "me@example.com"},
+			{"Duration", "1m30s"},
+		},
+		Error: "build failed",
+		Link:  "https://tsuru.example.com/events/5f0a1b2c3d4e5f6a7b8c9d0e",
+	})
+	c.Assert(msg.color(), check.Equals, "#D50200")
+	c.Assert(msg.text(), check.Equals, "Deploy of app myapp failed\nKind: app.deploy\nOwner: me@example.com\n"+
+		"Duration: 1m30s\nError: build failed\nhttps://tsuru.example.com/events/5f0a1b2c3d4e5f6a7b8c9d0e")
+}
+
+func (s *S) TestEventURL(c *check.C) {
+	c.Assert(eventURL("abc"), check.Equals, "")
+	config.Set("host", "https://tsuru.example.com")
+	c.Assert(eventURL("abc"), check.Equals, "https://tsuru.example.com/events/abc")
+	config.Set("notifications:event-url", "https://dashboard.example.com/events/{id}/details")
+	c.Assert(eventURL("abc"), check.Equals, "https://dashboard.example.com/events/abc/details")
+}
+
+func (s *S) TestValidateChannel(c *check.C) {
+	valid := eventTypes.NotificationChannel{Driver: "slack", URL: "https://hooks.slack.com/x", Apps: []string{"myapp"}}
+	c.Assert(validateChannel(valid), check.IsNil)
+	tests := []struct {
+		change   func(*eventTypes.NotificationChannel)
+		expected string
+	}{
+		{func(ch *eventTypes.NotificationChannel) { ch.Driver = "irc" }, `invalid notification driver "irc", must be one of: slack, teams, matrix`},
+		{func(ch *eventTypes.NotificationChannel) { ch.URL = "" }, `notification channel url must not be empty`},
+		{func(ch *eventTypes.NotificationChannel) { ch.URL = "ftp://x" }, `notification channel url is not valid: "ftp://x"`},
+		{func(ch *eventTypes.NotificationChannel) { ch.Driver = "matrix" }, `matrix notification channels require room and token`},
+		{func(ch *eventTypes.NotificationChannel) { ch.Apps = nil }, `notification channel must be subscribed to at least one app or pool`},
+		{func(ch *eventTypes.NotificationChannel) { ch.Events = []string{"deploy"} }, `invalid notification event "deploy", must be one of: .*`},
+	}
+	for i, tt := range tests {
+		ch := valid
+		tt.change(&ch)
+		c.Assert(validateChannel(ch), check.ErrorMatches, tt.expected, check.Commentf("failed test %d", i))
+	}
+}
+
+func (s *S) TestChannelWants(c *check.C) {
+	ch := eventTypes.NotificationChannel{}
+	c.Assert(ch.Wants(eventTypes.NotificationHealing), check.Equals, true)
+	ch.Events = []string{eventTypes.NotificationDeployFailure}
+	c.Assert(ch.Wants(eventTypes.NotificationHealing), check.Equals, false)
+	c.Assert(ch.Wants(eventTypes.NotificationDeployFailure), check.Equals, true)
+}
+
+type receivedRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   map[string]interface{}
+}
+
+func newTestServer() (*httptest.Server, chan receivedRequest) {
+	received := make(chan receivedRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		var body map[string]interface{}
+		json.Unmarshal(data, &body)
+		received <- receivedRequest{method: r.Method, path: r.URL.EscapedPath(), header: r.Header, body: body}
+	}))
+	return srv, received
+}
+
+func testMessage() message {
+	return message{
+		ID:      "abc",
+		Event:   eventTypes.NotificationDeploySuccess,
+		Title:   "Deploy of app myapp succeeded",
+		Fields:  [][2]string{{"Owner", "me@example.com"}},
+		Link:    "https://tsuru.example.com/events/abc",
+		Success: true,
+	}
+}
+
+func (s *S) TestSendSlack(c *check.C) {
+	srv, received := newTestServer()
+	defer srv.Close()
+	err := send(eventTypes.NotificationChannel{Driver: "slack", URL: srv.URL + "/services/x"}, testMessage())
+	c.Assert(err, check.IsNil)
+	req := <-received
+	c.Assert(req.method, check.Equals, http.MethodPost)
+	c.Assert(req.path, check.Equals, "/services/x")
+	c.Assert(req.header.Get("User-Agent"), check.Equals, "tsuru-notification-client/1.0")
+	c.Assert(req.body["text"], check.Equals, "Deploy of app myapp succeeded")
+	attachment := req.body["attachments"].([]interface{})[0].(map[string]interface{})
+	c.Assert(attachment["color"], check.Equals, "#2EB886")
+	c.Assert(attachment["title_link"], check.Equals, "https://tsuru.example.com/events/abc")
+	c.Assert(attachment["fields"], check.DeepEquals, []interface{}{
+		map[string]interface{}{"title": "Owner", "value": "me@example.com", "short": true},
+	})
+}
+
+func (s *S) TestSendTeams(c *check.C) {
+	srv, received := newTestServer()
+	defer srv.Close()
+	err := send(eventTypes.NotificationChannel{Driver: "teams", URL: srv.URL}, testMessage())
+	c.Assert(err, check.IsNil)
+	req := <-received
+	c.Assert(req.method, check.Equals, http.MethodPost)
+	c.Assert(req.body["@type"], check.Equals, "MessageCard")
+	c.Assert(req.body["themeColor"], check.Equals, "2EB886")
+	c.Assert(req.body["summary"], check.Equals, "Deploy of app myapp succeeded")
+	action := req.body["potentialAction"].([]interface{})[0].(map[string]interface{})
+	c.Assert(action["targets"], check.DeepEquals, []interface{}{
+		map[string]interface{}{"os": "default", "uri": "https://tsuru.example.com/events/abc"},
+	})
+}
+
+func (s *S) TestSendMatrix(c *check.C) {
+	srv, received := newTestServer()
+	defer srv.Close()
+	msg := testMessage()
+	msg.Error = "<oops>"
+	err := send(eventTypes.NotificationChannel{Driver: "matrix", URL: srv.URL + "/", Room: "!room:example.com", Token: "secret"}, msg)
+	c.Assert(err, check.IsNil)
+	req := <-received
+	c.Assert(req.method, check.Equals, http.MethodPut)
+	c.Assert(req.path, check.Equals, "/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/abc-deploy-success")
+	c.Assert(req.header.Get("Authorization"), check.Equals, "Bearer secret")
+	c.Assert(req.body["msgtype"], check.Equals, "m.text")
+	c.Assert(req.body["formatted_body"], check.Equals, "<strong>Deploy of app myapp succeeded</strong><br>Owner: me@example.com"+
+		`<br><pre>&lt;oops&gt;</pre><br><a href="https://tsuru.example.com/events/abc">View event</a>`)
+}
+
+func (s *S) TestSendError(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer srv.Close()
+	err := send(eventTypes.NotificationChannel{Driver: "slack", URL: srv.URL}, testMessage())
+	c.Assert(err, check.ErrorMatches, "invalid status code sending notification: 403: invalid_token\n")
+}
+
+type ServiceSuite struct {
+	service *notificationService
+}
+
+var _ = check.Suite(&ServiceSuite{})
+
+func (s *ServiceSuite) SetUpTest(c *check.C) {
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=150")
+	config.Set("database:name", "tsuru_event_notification_tests")
+	conn, err := db.Conn()
+	c.Assert(err, check.IsNil)
+	defer conn.Close()
+	err = dbtest.ClearAllCollections(conn.Events().Database)
+	c.Assert(err, check.IsNil)
+	svc, err := NotificationService()
+	c.Assert(err, check.IsNil)
+	s.service = svc.(*notificationService)
+	servicemock.SetMockService(&servicemock.MockService{})
+	servicemanager.App = &fakeAppService{apps: map[string]string{"myapp": "pool1"}}
+}
+
+func (s *ServiceSuite) TearDownTest(c *check.C) {
+	servicemanager.App = nil
+	err := s.service.Shutdown(context.Background())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *ServiceSuite) TestNotifyDeployFailure(c *check.C) {
+	srv, received := newTestServer()
+	defer srv.Close()
+	for _, ch := range []eventTypes.NotificationChannel{
+		{Name: "by-pool", Driver: "slack", URL: srv.URL + "/pool", Pools: []string{"pool1"}, Events: []string{eventTypes.NotificationDeployFailure}},
+		{Name: "other-events", Driver: "slack", URL: srv.URL + "/other", Apps: []string{"myapp"}, Events: []string{eventTypes.NotificationHealing}},
+		{Name: "other-app", Driver: "slack", URL: srv.URL + "/other", Apps: []string{"otherapp"}},
+	} {
+		err := s.service.storage.Insert(ch)
+		c.Assert(err, check.IsNil)
+	}
+	evt, err := event.New(&event.Opts{
+		Target:   event.Target{Type: event.TargetTypeApp, Value: "myapp"},
+		RawOwner: event.Owner{Type: "user", Name: "me@example.com"},
+		Kind:     permission.PermAppDeploy,
+		Allowed:  event.Allowed(permission.PermAppReadEvents, permission.Context(permTypes.CtxApp, "myapp")),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.Done(errors.New("build failed"))
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(evt.UniqueID.Hex(), eventTypes.TransitionCreated)
+	c.Assert(err, check.IsNil)
+	err = s.service.handleEvent(evt.UniqueID.Hex(), eventTypes.TransitionDone)
+	c.Assert(err, check.IsNil)
+	req := <-received
+	c.Assert(req.path, check.Equals, "/pool")
+	c.Assert(req.body["text"], check.Equals, "Deploy of app myapp failed")
+	select {
+	case req = <-received:
+		c.Fatalf("unexpected notification to %s", req.path)
+	default:
+	}
+}
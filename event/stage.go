@@ -0,0 +1,64 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import "time"
+
+type stageSpan struct {
+	start time.Time
+	end   time.Time
+}
+
+// StartStage marks the beginning of a stage of the operation tracked by the
+// event and returns a function marking its end. Stages that run more than
+// once, like the creation of multiple units, span from their first start to
+// their last end. It's safe to call StartStage on a nil event.
+func (e *Event) StartStage(name string) func() {
+	if e == nil {
+		return func() {}
+	}
+	now := time.Now()
+	e.stageMu.Lock()
+	if e.stages == nil {
+		e.stages = map[string]*stageSpan{}
+	}
+	span, ok := e.stages[name]
+	if !ok {
+		span = &stageSpan{start: now}
+		e.stages[name] = span
+	} else if now.Before(span.start) {
+		span.start = now
+	}
+	e.stageMu.Unlock()
+	return func() {
+		end := time.Now()
+		e.stageMu.Lock()
+		defer e.stageMu.Unlock()
+		if end.After(span.end) {
+			span.end = end
+		}
+	}
+}
+
+// StageDurations returns the duration of each finished stage recorded with
+// StartStage.
+func (e *Event) StageDurations() map[string]time.Duration {
+	if e == nil {
+		return nil
+	}
+	e.stageMu.Lock()
+	defer e.stageMu.Unlock()
+	if len(e.stages) == 0 {
+		return nil
+	}
+	durations := make(map[string]time.Duration, len(e.stages))
+	for name, span := range e.stages {
+		if span.end.IsZero() {
+			continue
+		}
+		durations[name] = span.end.Sub(span.start)
+	}
+	return durations
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import (
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestEventStages(c *check.C) {
+	evt := &Event{}
+	c.Assert(evt.StageDurations(), check.IsNil)
+	doneBuild := evt.StartStage("build")
+	time.Sleep(10 * time.Millisecond)
+	doneBuild()
+	doneStart1 := evt.StartStage("start")
+	doneStart2 := evt.StartStage("start")
+	doneStart1()
+	time.Sleep(10 * time.Millisecond)
+	doneStart2()
+	evt.StartStage("unfinished")
+	durations := evt.StageDurations()
+	c.Assert(durations, check.HasLen, 2)
+	c.Assert(durations["build"] >= 10*time.Millisecond, check.Equals, true)
+	c.Assert(durations["start"] >= 10*time.Millisecond, check.Equals, true)
+}
+
+func (s *S) TestEventStagesNilEvent(c *check.C) {
+	var evt *Event
+	evt.StartStage("build")()
+	c.Assert(evt.StageDurations(), check.IsNil)
+}
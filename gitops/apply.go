@@ -0,0 +1,160 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/reconcile"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/service"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// Apply converges tsuru to the bundle, applying the changes in the plan.
+// The user owns the apps created by the plan. All the progress is written
+// to the event.
+func Apply(ctx context.Context, b *Bundle, plan *Plan, user *auth.User, evt *event.Event) error {
+	pools := map[string]PoolSpec{}
+	for _, spec := range b.Pools {
+		pools[spec.Name] = spec
+	}
+	for _, change := range plan.Pools {
+		fmt.Fprintf(evt, "---- %s pool %s ----\n", change.Kind, change.Name)
+		err := applyPool(ctx, pools[change.Name], change)
+		if err != nil {
+			return errors.Wrapf(err, "unable to %s pool %q", change.Kind, change.Name)
+		}
+	}
+	instances := map[string]ServiceInstanceSpec{}
+	for _, spec := range b.ServiceInstances {
+		instances[spec.String()] = spec
+	}
+	for _, change := range plan.ServiceInstances {
+		fmt.Fprintf(evt, "---- %s service instance %s ----\n", change.Kind, change.Name)
+		err := applyServiceInstance(ctx, instances[change.Name], change, evt)
+		if err != nil {
+			return errors.Wrapf(err, "unable to %s service instance %q", change.Kind, change.Name)
+		}
+	}
+	manifests := map[string]manifest.Manifest{}
+	for _, m := range b.Apps {
+		manifests[m.Name] = m
+	}
+	for _, appChanges := range plan.Apps {
+		m := manifests[appChanges.App]
+		err := applyApp(ctx, &m, appChanges.Changes, user, evt)
+		if err != nil {
+			return errors.Wrapf(err, "unable to converge app %q", m.Name)
+		}
+	}
+	return nil
+}
+
+func applyPool(ctx context.Context, spec PoolSpec, change ResourceChange) error {
+	if change.Kind == manifest.ChangeCreate {
+		return pool.AddPool(ctx, pool.AddPoolOptions{
+			Name:        spec.Name,
+			Default:     spec.Default,
+			Force:       true,
+			Provisioner: spec.Provisioner,
+			Labels:      spec.Labels,
+		})
+	}
+	var opts pool.UpdatePoolOptions
+	for _, field := range change.Fields {
+		switch field {
+		case "default":
+			opts.Default = &spec.Default
+			opts.Force = true
+		case "labels":
+			opts.Labels = spec.Labels
+		}
+	}
+	return pool.PoolUpdate(ctx, spec.Name, opts)
+}
+
+func applyServiceInstance(ctx context.Context, spec ServiceInstanceSpec, change ResourceChange, evt *event.Event) error {
+	srv, err := service.Get(ctx, spec.Service)
+	if err != nil {
+		return err
+	}
+	if change.Kind == manifest.ChangeCreate {
+		return service.CreateServiceInstance(ctx, service.ServiceInstance{
+			Name:        spec.Name,
+			ServiceName: spec.Service,
+			PlanName:    spec.Plan,
+			TeamOwner:   spec.TeamOwner,
+			Description: spec.Description,
+			Pool:        spec.Pool,
+			Tags:        spec.Tags,
+			Parameters:  spec.Parameters,
+		}, &srv, evt, "")
+	}
+	si, err := service.GetServiceInstance(ctx, spec.Service, spec.Name)
+	if err != nil {
+		return err
+	}
+	updateData := service.ServiceInstance{
+		Description: si.Description,
+		PlanName:    si.PlanName,
+		TeamOwner:   si.TeamOwner,
+		Tags:        si.Tags,
+		Parameters:  si.Parameters,
+	}
+	for _, field := range change.Fields {
+		switch field {
+		case "description":
+			updateData.Description = spec.Description
+		case "plan":
+			updateData.PlanName = spec.Plan
+		case "team_owner":
+			updateData.TeamOwner = spec.TeamOwner
+		case "tags":
+			updateData.Tags = spec.Tags
+		}
+	}
+	return si.Update(srv, updateData, evt, "")
+}
+
+func applyApp(ctx context.Context, m *manifest.Manifest, changes []manifest.Change, user *auth.User, evt *event.Event) error {
+	for _, change := range changes {
+		fmt.Fprintf(evt, "---- app %s: %s ----\n", m.Name, change)
+		if change.Kind == manifest.ChangeCreate {
+			if user == nil {
+				return errors.New("gitops:user must be set to create apps")
+			}
+			a := app.App{
+				Name:        m.Name,
+				Description: m.Description,
+				Platform:    m.Platform,
+				Pool:        m.Pool,
+				Plan:        appTypes.Plan{Name: m.Plan},
+				TeamOwner:   m.TeamOwner,
+				Tags:        m.Tags,
+			}
+			err := app.CreateApp(ctx, &a, user)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		a, err := app.GetByName(ctx, m.Name)
+		if err != nil {
+			return err
+		}
+		err = reconcile.ApplyChange(ctx, a, m, change, true, evt)
+		if err != nil {
+			return errors.Wrapf(err, "unable to %s", change)
+		}
+	}
+	return nil
+}
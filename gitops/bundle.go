@@ -0,0 +1,243 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitops
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app/manifest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+const (
+	KindApp             = "app"
+	KindPool            = "pool"
+	KindServiceInstance = "service-instance"
+
+	maxBundleSize = 10 * 1024 * 1024
+)
+
+// PoolSpec is the declarative description of a pool.
+type PoolSpec struct {
+	Name        string            `json:"name"`
+	Default     bool              `json:"default,omitempty"`
+	Provisioner string            `json:"provisioner,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// ServiceInstanceSpec is the declarative description of a service instance.
+type ServiceInstanceSpec struct {
+	Service     string                 `json:"service"`
+	Name        string                 `json:"name"`
+	Plan        string                 `json:"plan,omitempty"`
+	TeamOwner   string                 `json:"team_owner,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Pool        string                 `json:"pool,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+func (s ServiceInstanceSpec) String() string {
+	return s.Service + "/" + s.Name
+}
+
+// Bundle is the set of specs tsuru is converged to. Resources not present
+// in the bundle are never touched.
+type Bundle struct {
+	Revision         string                `json:"revision"`
+	Pools            []PoolSpec            `json:"pools,omitempty"`
+	ServiceInstances []ServiceInstanceSpec `json:"serviceInstances,omitempty"`
+	Apps             []manifest.Manifest   `json:"apps,omitempty"`
+}
+
+// LoadDir reads a bundle from the YAML and JSON files found under dir.
+func LoadDir(dir string) (*Bundle, error) {
+	files := map[string][]byte{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isSpecFile(path) {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files[rel] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseFiles(files)
+}
+
+// ReadArchive reads a bundle from a gzipped tarball with the same layout of
+// the directories read by LoadDir. The revision of the bundle is the digest
+// of the archive.
+func ReadArchive(r io.Reader) (*Bundle, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxBundleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBundleSize {
+		return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("bundle is larger than %d bytes", maxBundleSize)}
+	}
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, &tsuruErrors.ValidationError{Message: "invalid bundle: " + err.Error()}
+	}
+	defer gzr.Close()
+	files := map[string][]byte{}
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, &tsuruErrors.ValidationError{Message: "invalid bundle: " + err.Error()}
+		}
+		if header.Typeflag != tar.TypeReg || !isSpecFile(header.Name) {
+			continue
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[header.Name] = content
+	}
+	b, err := parseFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	b.Revision = hex.EncodeToString(sum[:])[:12]
+	return b, nil
+}
+
+func isSpecFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+func parseFiles(files map[string][]byte) (*Bundle, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b Bundle
+	for _, name := range names {
+		for i, doc := range splitDocuments(files[name]) {
+			err := b.add(doc)
+			if err != nil {
+				return nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("%s (document %d): %v", name, i+1, err)}
+			}
+		}
+	}
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func splitDocuments(data []byte) [][]byte {
+	var docs [][]byte
+	for _, doc := range bytes.Split(append([]byte("\n"), data...), []byte("\n---")) {
+		if len(bytes.TrimSpace(doc)) > 0 {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+func (b *Bundle) add(doc []byte) error {
+	var header struct {
+		Kind string `json:"kind"`
+	}
+	err := yaml.Unmarshal(doc, &header)
+	if err != nil {
+		return errors.Wrap(err, "invalid spec")
+	}
+	switch header.Kind {
+	case KindApp:
+		m, err := manifest.Parse(doc)
+		if err != nil {
+			return err
+		}
+		b.Apps = append(b.Apps, *m)
+	case KindPool:
+		var p PoolSpec
+		if err = yaml.Unmarshal(doc, &p); err != nil {
+			return errors.Wrap(err, "invalid pool spec")
+		}
+		b.Pools = append(b.Pools, p)
+	case KindServiceInstance:
+		var si ServiceInstanceSpec
+		if err = yaml.Unmarshal(doc, &si); err != nil {
+			return errors.Wrap(err, "invalid service instance spec")
+		}
+		b.ServiceInstances = append(b.ServiceInstances, si)
+	default:
+		return errors.Errorf("unknown kind %q, expected one of %s, %s or %s", header.Kind, KindApp, KindPool, KindServiceInstance)
+	}
+	return nil
+}
+
+func (b *Bundle) validate() error {
+	seen := map[string]bool{}
+	check := func(kind, name string) error {
+		if name == "" || strings.HasSuffix(name, "/") || strings.HasPrefix(name, "/") {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("%s spec without name", kind)}
+		}
+		key := kind + ":" + name
+		if seen[key] {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("duplicated %s %q", kind, name)}
+		}
+		seen[key] = true
+		return nil
+	}
+	for _, p := range b.Pools {
+		if err := check(KindPool, p.Name); err != nil {
+			return err
+		}
+	}
+	for _, si := range b.ServiceInstances {
+		if err := check(KindServiceInstance, si.String()); err != nil {
+			return err
+		}
+	}
+	for _, m := range b.Apps {
+		if err := check(KindApp, m.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gitops keeps pools, service instances and apps converged to a
+// bundle of declarative specs, read from a git repository or uploaded to
+// the API. Each sync computes the plan of changes between the bundle and
+// the current state, recording it as a drift report, and applies it unless
+// running in dry-run mode.
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/api/shutdown"
+	"github.com/tsuru/tsuru/api/tracker"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/exec"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+)
+
+const (
+	internalKind = "gitops sync"
+
+	SourceUpload = "upload"
+
+	bundleID = "bundle"
+	statusID = "status"
+)
+
+var (
+	ErrNoSource = errors.New("no gitops source: set gitops:repository or upload a bundle")
+
+	executor exec.Executor = exec.OsExecutor{}
+
+	driftsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_gitops_drifts_total",
+		Help: "The total number of gitops syncs that found drift from the bundle.",
+	})
+
+	syncErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tsuru_gitops_sync_errors_total",
+		Help: "The total number of errors syncing the gitops bundle.",
+	})
+)
+
+// Status is the result of the last sync. Drift holds the changes found
+// between the bundle and the state of tsuru, which were applied unless
+// DryRun is true.
+type Status struct {
+	Source   string    `json:"source"`
+	Revision string    `json:"revision"`
+	LastSync time.Time `json:"lastSync"`
+	DryRun   bool      `json:"dryRun"`
+	Drift    *Plan     `json:"drift,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// SyncOptions holds the parameters of a sync. User owns the apps created by
+// the sync and Event receives its output, when nil an internal event is
+// created if there are changes to apply.
+type SyncOptions struct {
+	DryRun bool
+	User   *auth.User
+	Event  *event.Event
+}
+
+func gitopsCollection(conn *db.Storage) *storage.Collection {
+	return conn.Collection("gitops")
+}
+
+// Target is the event target of syncs.
+func Target() event.Target {
+	return event.Target{Type: event.TargetTypeGitOps, Value: Source()}
+}
+
+// Source returns where the bundle is read from, either the git repository
+// set by gitops:repository or SourceUpload.
+func Source() string {
+	repository, _ := config.GetString("gitops:repository")
+	if repository == "" {
+		return SourceUpload
+	}
+	return repository
+}
+
+// Prune returns whether syncs remove the env vars, bindings and cnames of
+// apps which are not in their manifests, as set by gitops:prune.
+func Prune() bool {
+	prune, _ := config.GetBool("gitops:prune")
+	return prune
+}
+
+// Fetch reads the current bundle from the configured source.
+func Fetch(ctx context.Context) (*Bundle, error) {
+	repository, _ := config.GetString("gitops:repository")
+	if repository == "" {
+		return uploadedBundle()
+	}
+	dir, err := ioutil.TempDir("", "tsuru-gitops")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	args := []string{"clone", "--depth", "1"}
+	if branch, _ := config.GetString("gitops:branch"); branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	_, err = git("", append(args, repository, dir)...)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	subdir, _ := config.GetString("gitops:path")
+	bundleDir := filepath.Join(dir, filepath.Clean("/"+subdir))
+	b, err := LoadDir(bundleDir)
+	if err != nil {
+		return nil, err
+	}
+	b.Revision = revision
+	return b, nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := executor.Execute(exec.ExecuteOptions{Cmd: "git", Args: args, Dir: dir, Stdout: &stdout, Stderr: &stderr})
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to run git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// SaveBundle stores an uploaded bundle, used as the source when no git
+// repository is configured.
+func SaveBundle(b *Bundle) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = gitopsCollection(conn).UpsertId(bundleID, b)
+	return err
+}
+
+func uploadedBundle() (*Bundle, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var b Bundle
+	err = gitopsCollection(conn).FindId(bundleID).One(&b)
+	if err == mgo.ErrNotFound {
+		return nil, ErrNoSource
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetStatus returns the status of the last sync, or nil if there was none.
+func GetStatus() (*Status, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var st Status
+	err = gitopsCollection(conn).FindId(statusID).One(&st)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func saveStatus(st *Status) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = gitopsCollection(conn).UpsertId(statusID, st)
+	return err
+}
+
+// Sync fetches the bundle, records the drift found and, unless running in
+// dry-run mode, applies the changes needed to converge tsuru to it.
+func Sync(ctx context.Context, opts SyncOptions) (st *Status, err error) {
+	st = &Status{Source: Source(), LastSync: time.Now().UTC(), DryRun: opts.DryRun}
+	defer func() {
+		if err != nil {
+			syncErrors.Inc()
+			st.Error = err.Error()
+		}
+		if saveErr := saveStatus(st); saveErr != nil {
+			log.Errorf("[gitops] unable to save status: %v", saveErr)
+		}
+	}()
+	b, err := Fetch(ctx)
+	if err != nil {
+		return st, err
+	}
+	st.Revision = b.Revision
+	plan, err := NewPlan(ctx, b, Prune())
+	if err != nil {
+		return st, err
+	}
+	if plan.Empty() {
+		return st, nil
+	}
+	st.Drift = plan
+	driftsTotal.Inc()
+	if opts.DryRun {
+		return st, nil
+	}
+	evt := opts.Event
+	if evt == nil {
+		evt, err = event.NewInternal(&event.Opts{
+			Target:       Target(),
+			InternalKind: internalKind,
+			CustomData:   plan,
+			Allowed:      event.Allowed(permission.PermGitopsRead),
+		})
+		if err != nil {
+			if _, ok := err.(event.ErrEventLocked); ok {
+				log.Debugf("[gitops] skipping sync: event locked")
+				return st, nil
+			}
+			return st, err
+		}
+		defer func() { evt.Done(err) }()
+	}
+	fmt.Fprintf(evt, "---- Syncing revision %s from %s ----\n", b.Revision, st.Source)
+	return st, Apply(ctx, b, plan, opts.User, evt)
+}
+
+// Initialize starts the gitops controller when enabled by the
+// gitops:enabled config entry.
+func Initialize() error {
+	enabled, _ := config.GetBool("gitops:enabled")
+	if !enabled {
+		return nil
+	}
+	interval, _ := config.GetDuration("gitops:interval")
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	dryRun, _ := config.GetBool("gitops:dry-run")
+	c := &controller{interval: interval, dryRun: dryRun}
+	c.start()
+	shutdown.Register(c)
+	return nil
+}
+
+type controller struct {
+	interval time.Duration
+	dryRun   bool
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+func (c *controller) start() {
+	c.shutdown = make(chan struct{})
+	c.done = make(chan struct{})
+	log.Debugf("[gitops] starting. Running every %s.", c.interval)
+	go func() {
+		defer close(c.done)
+		for {
+			select {
+			case <-time.After(c.interval):
+				c.run()
+			case <-c.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (c *controller) run() {
+	if !tracker.IsLeader("gitops") {
+		return
+	}
+	opts := SyncOptions{DryRun: c.dryRun}
+	if email, _ := config.GetString("gitops:user"); email != "" {
+		user, err := auth.GetUserByEmail(email)
+		if err != nil {
+			log.Errorf("[gitops] unable to find user %q: %v", email, err)
+			return
+		}
+		opts.User = user
+	}
+	_, err := Sync(context.Background(), opts)
+	if err != nil && err != ErrNoSource {
+		log.Errorf("[gitops] unable to sync: %v", err)
+	}
+}
+
+// Shutdown stops the controller, waiting for the current sync to finish.
+func (c *controller) Shutdown(ctx context.Context) error {
+	close(c.shutdown)
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitops
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/manifest"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/exec"
+	"github.com/tsuru/tsuru/exec/exectest"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TearDownTest(c *check.C) {
+	executor = exec.OsExecutor{}
+	config.Unset("gitops")
+}
+
+func archive(c *check.C, files map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		c.Assert(err, check.IsNil)
+		_, err = tw.Write([]byte(content))
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(tw.Close(), check.IsNil)
+	c.Assert(gzw.Close(), check.IsNil)
+	return &buf
+}
+
+var expectedBundle = Bundle{
+	Pools: []PoolSpec{{Name: "prod", Default: true, Labels: map[string]string{"region": "us-east-1"}}},
+	ServiceInstances: []ServiceInstanceSpec{
+		{Service: "mysql", Name: "prod-db", Plan: "large", TeamOwner: "admin"},
+	},
+	Apps: []manifest.Manifest{{
+		Name:      "myapp",
+		Platform:  "python",
+		Pool:      "prod",
+		TeamOwner: "admin",
+		Env:       map[string]string{"LOG_LEVEL": "info"},
+		Bindings:  []manifest.Binding{{Service: "mysql", Instance: "prod-db"}},
+	}},
+}
+
+func (s *S) TestLoadDir(c *check.C) {
+	b, err := LoadDir("testdata/bundle")
+	c.Assert(err, check.IsNil)
+	c.Assert(*b, check.DeepEquals, expectedBundle)
+}
+
+func (s *S) TestReadArchive(c *check.C) {
+	buf := archive(c, map[string]string{
+		"specs/pool.yaml": "kind: pool\nname: prod\n",
+		"specs/app.json":  `{"kind": "app", "name": "myapp", "platform": "go"}`,
+		"README":          "ignored",
+	})
+	b, err := ReadArchive(buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(b.Revision, check.HasLen, 12)
+	c.Assert(b.Pools, check.DeepEquals, []PoolSpec{{Name: "prod"}})
+	c.Assert(b.Apps, check.DeepEquals, []manifest.Manifest{{Name: "myapp", Platform: "go"}})
+}
+
+func (s *S) TestReadArchiveInvalid(c *check.C) {
+	_, err := ReadArchive(bytes.NewBufferString("not gzip"))
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	_, err = ReadArchive(archive(c, map[string]string{"a.yaml": "kind: node\nname: n1\n"}))
+	c.Assert(err, check.ErrorMatches, `a.yaml \(document 1\): unknown kind "node".*`)
+	_, err = ReadArchive(archive(c, map[string]string{"a.yaml": "kind: pool\nname: p1\n---\nkind: pool\nname: p1\n"}))
+	c.Assert(err, check.ErrorMatches, `duplicated pool "p1"`)
+	_, err = ReadArchive(archive(c, map[string]string{"a.yaml": "kind: service-instance\nservice: mysql\n"}))
+	c.Assert(err, check.ErrorMatches, `service-instance spec without name`)
+}
+
+func (s *S) TestFetchRepository(c *check.C) {
+	config.Set("gitops:repository", "https://git.example.com/infra.git")
+	config.Set("gitops:branch", "main")
+	fexec := &exectest.FakeExecutor{Output: map[string][][]byte{"rev-parse HEAD": {[]byte("abc123\n")}}}
+	executor = fexec
+	b, err := Fetch(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(b.Revision, check.Equals, "abc123")
+	cmds := fexec.GetCommands("git")
+	c.Assert(cmds, check.HasLen, 2)
+	args := cmds[0].GetArgs()
+	c.Assert(args[:6], check.DeepEquals, []string{"clone", "--depth", "1", "--branch", "main", "https://git.example.com/infra.git"})
+	c.Assert(cmds[1].GetDir(), check.Equals, args[6])
+	c.Assert(Source(), check.Equals, "https://git.example.com/infra.git")
+}
+
+func (s *S) TestFetchRepositoryCloneError(c *check.C) {
+	config.Set("gitops:repository", "https://git.example.com/infra.git")
+	executor = &exectest.ErrorExecutor{}
+	_, err := Fetch(context.TODO())
+	c.Assert(err, check.ErrorMatches, "unable to run git clone.*")
+}
+
+func (s *S) TestPlanEmpty(c *check.C) {
+	c.Assert((&Plan{Revision: "abc"}).Empty(), check.Equals, true)
+	c.Assert((&Plan{Pools: []ResourceChange{{Kind: manifest.ChangeCreate, Name: "p1"}}}).Empty(), check.Equals, false)
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitops
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/app/manifest"
+	"github.com/tsuru/tsuru/app/reconcile"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/service"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// ResourceChange is a change needed to converge a pool or a service
+// instance to its spec.
+type ResourceChange struct {
+	Kind   manifest.ChangeKind `json:"kind"`
+	Name   string              `json:"name"`
+	Fields []string            `json:"fields,omitempty"`
+}
+
+// AppChanges are the changes needed to converge an app to its manifest.
+type AppChanges struct {
+	App     string            `json:"app"`
+	Changes []manifest.Change `json:"changes"`
+}
+
+// Plan is the set of changes needed to converge tsuru to a bundle. Pools
+// are applied first, then service instances and finally apps.
+type Plan struct {
+	Revision         string           `json:"revision"`
+	Pools            []ResourceChange `json:"pools,omitempty"`
+	ServiceInstances []ResourceChange `json:"serviceInstances,omitempty"`
+	Apps             []AppChanges     `json:"apps,omitempty"`
+}
+
+// Empty returns whether tsuru is already converged to the bundle.
+func (p *Plan) Empty() bool {
+	return len(p.Pools) == 0 && len(p.ServiceInstances) == 0 && len(p.Apps) == 0
+}
+
+// NewPlan compares the bundle with the current state of pools, service
+// instances and apps. When prune is true, env vars, bindings and cnames of
+// the apps in the bundle which are not in their manifests are removed.
+func NewPlan(ctx context.Context, b *Bundle, prune bool) (*Plan, error) {
+	plan := Plan{Revision: b.Revision}
+	for _, spec := range b.Pools {
+		p, err := pool.GetPoolByName(ctx, spec.Name)
+		if err == pool.ErrPoolNotFound {
+			plan.Pools = append(plan.Pools, ResourceChange{Kind: manifest.ChangeCreate, Name: spec.Name})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if fields := poolDiff(p, spec); len(fields) > 0 {
+			plan.Pools = append(plan.Pools, ResourceChange{Kind: manifest.ChangeUpdate, Name: spec.Name, Fields: fields})
+		}
+	}
+	for _, spec := range b.ServiceInstances {
+		si, err := service.GetServiceInstance(ctx, spec.Service, spec.Name)
+		if err == service.ErrServiceInstanceNotFound {
+			plan.ServiceInstances = append(plan.ServiceInstances, ResourceChange{Kind: manifest.ChangeCreate, Name: spec.String()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if fields := serviceInstanceDiff(si, spec); len(fields) > 0 {
+			plan.ServiceInstances = append(plan.ServiceInstances, ResourceChange{Kind: manifest.ChangeUpdate, Name: spec.String(), Fields: fields})
+		}
+	}
+	for _, m := range b.Apps {
+		var current *manifest.Manifest
+		a, err := app.GetByName(ctx, m.Name)
+		switch err {
+		case nil:
+			current, err = reconcile.State(a)
+			if err != nil {
+				return nil, err
+			}
+		case appTypes.ErrAppNotFound:
+		default:
+			return nil, err
+		}
+		var changes []manifest.Change
+		for _, change := range manifest.Diff(current, m, prune) {
+			if (change.Kind == manifest.ChangeUnitsAdd || change.Kind == manifest.ChangeUnitsRemove) && (a == nil || a.Deploys == 0) {
+				continue
+			}
+			changes = append(changes, change)
+		}
+		if len(changes) > 0 {
+			plan.Apps = append(plan.Apps, AppChanges{App: m.Name, Changes: changes})
+		}
+	}
+	return &plan, nil
+}
+
+func poolDiff(p *pool.Pool, spec PoolSpec) []string {
+	var fields []string
+	if p.Default != spec.Default {
+		fields = append(fields, "default")
+	}
+	if spec.Labels != nil && !reflect.DeepEqual(p.Labels, spec.Labels) {
+		fields = append(fields, "labels")
+	}
+	return fields
+}
+
+func serviceInstanceDiff(si *service.ServiceInstance, spec ServiceInstanceSpec) []string {
+	var fields []string
+	if spec.Description != "" && spec.Description != si.Description {
+		fields = append(fields, "description")
+	}
+	if spec.Plan != "" && spec.Plan != si.PlanName {
+		fields = append(fields, "plan")
+	}
+	if spec.TeamOwner != "" && spec.TeamOwner != si.TeamOwner {
+		fields = append(fields, "team_owner")
+	}
+	if spec.Tags != nil && !reflect.DeepEqual(spec.Tags, si.Tags) {
+		fields = append(fields, "tags")
+	}
+	return fields
+}
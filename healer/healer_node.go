@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/api/tracker"
 	"github.com/tsuru/tsuru/db"
 	"github.com/tsuru/tsuru/db/storage"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
@@ -47,13 +49,20 @@ type nodeHealerArgs struct {
 	FailuresBeforeHealing int
 }
 
+// NodeHealerConfig is the healing policy of a pool. ReplaceNode defaults to
+// true, meaning failing nodes are replaced by new machines created in the
+// same IaaS. When it's false, units are moved from failing nodes to healthy
+// nodes in the pool and failing nodes are removed, including nodes not
+// created by an IaaS.
 type NodeHealerConfig struct {
 	Enabled                      *bool
 	MaxTimeSinceSuccess          *int
 	MaxUnresponsiveTime          *int
+	ReplaceNode                  *bool
 	EnabledInherited             bool
 	MaxTimeSinceSuccessInherited bool
 	MaxUnresponsiveTimeInherited bool
+	ReplaceNodeInherited         bool
 }
 
 type NodeStatusData struct {
@@ -200,9 +209,40 @@ func (h *NodeHealer) healNode(ctx context.Context, node provision.Node) (*provis
 	return &nodeSpec, multiErr.ToError()
 }
 
+func (h *NodeHealer) evacuateNode(ctx context.Context, node provision.Node, w io.Writer) error {
+	failingHost := net.URLToHost(node.Address())
+	err := node.Provisioner().RemoveNode(ctx, provision.RemoveNodeOptions{
+		Address:   node.Address(),
+		Rebalance: true,
+		Writer:    w,
+	})
+	if err != nil && err != provision.ErrNodeNotFound {
+		return errors.Wrapf(err, "Can't auto-heal node %s: error moving units to healthy nodes", failingHost)
+	}
+	err = h.RemoveNode(node)
+	if err != nil {
+		log.Errorf("Unable to remove node %s status from healer: %s", node.Address(), err)
+	}
+	log.Debugf("Done auto-healing node %q, units moved to healthy nodes.", failingHost)
+	return nil
+}
+
+func shouldReplaceNode(pool string) (bool, error) {
+	var configEntry NodeHealerConfig
+	err := healerConfig().Load(pool, &configEntry)
+	if err != nil {
+		return false, err
+	}
+	return configEntry.ReplaceNode == nil || *configEntry.ReplaceNode, nil
+}
+
 func (h *NodeHealer) tryHealingNode(ctx context.Context, node provision.Node, reason string, lastCheck *NodeChecks) error {
+	replace, err := shouldReplaceNode(node.Pool())
+	if err != nil {
+		return errors.Wrapf(err, "unable to load healing policy for node %q", node.Address())
+	}
 	_, hasIaas := node.MetadataNoPrefix()[provision.IaaSMetadataName]
-	if !hasIaas {
+	if replace && !hasIaas {
 		log.Debugf("node %q doesn't have IaaS information, healing (%s) won't run on it.", node.Address(), reason)
 		return nil
 	}
@@ -228,6 +268,7 @@ func (h *NodeHealer) tryHealingNode(ctx context.Context, node provision.Node, re
 		return errors.Wrapf(err, "Error trying to insert node healing event for node %q, healing aborted", node.Address())
 	}
 	var createdNode *provision.NodeSpec
+	var evacuated bool
 	var evtErr error
 	defer func() {
 		if createdNode != nil {
@@ -235,7 +276,7 @@ func (h *NodeHealer) tryHealingNode(ctx context.Context, node provision.Node, re
 				event.ExtraTarget{Target: event.Target{Type: event.TargetTypeNode, Value: createdNode.Address}})
 		}
 		var updateErr error
-		if evtErr == nil && createdNode == nil {
+		if evtErr == nil && createdNode == nil && !evacuated {
 			updateErr = evt.Abort()
 		} else {
 			updateErr = evt.DoneCustomData(evtErr, createdNode)
@@ -261,6 +302,11 @@ func (h *NodeHealer) tryHealingNode(ctx context.Context, node provision.Node, re
 		return nil
 	}
 	log.Errorf("initiating healing process for node %q due to: %s", node.Address(), reason)
+	if !replace {
+		evtErr = h.evacuateNode(ctx, node, evt)
+		evacuated = evtErr == nil
+		return evtErr
+	}
 	createdNode, evtErr = h.healNode(ctx, node)
 	return evtErr
 }
@@ -544,6 +590,9 @@ func (h *NodeHealer) findNodesForHealing(ctx context.Context) ([]NodeStatusData,
 }
 
 func (h *NodeHealer) runActiveHealing(ctx context.Context) {
+	if !tracker.IsLeader("node-healer") {
+		return
+	}
 	nodesStatus, nodesAddrMap, err := h.findNodesForHealing(ctx)
 	if err != nil {
 		log.Errorf("[node healer active] %s", err)
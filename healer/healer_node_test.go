@@ -490,6 +490,57 @@ func (s *S) TestHealerHandleError(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *S) TestHealerHandleErrorEvacuateNode(c *check.C) {
+	p := provisiontest.ProvisionerInstance
+	err := p.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address: "http://addr1:1",
+		Pool:    "p1",
+	})
+	c.Assert(err, check.IsNil)
+	err = p.AddNode(context.TODO(), provision.AddNodeOptions{
+		Address: "http://addr2:2",
+		Pool:    "p1",
+	})
+	c.Assert(err, check.IsNil)
+	healer := newNodeHealer(context.TODO(), nodeHealerArgs{
+		FailuresBeforeHealing: 1,
+		WaitTimeNewMachine:    time.Minute,
+	})
+	healer.Shutdown(context.Background())
+	healer.started = time.Now().Add(-3 * time.Second)
+	conf := healerConfig()
+	err = conf.SaveBase(NodeHealerConfig{Enabled: boolPtr(true), MaxUnresponsiveTime: intPtr(1)})
+	c.Assert(err, check.IsNil)
+	err = conf.Save("p1", NodeHealerConfig{ReplaceNode: boolPtr(false)})
+	c.Assert(err, check.IsNil)
+	node, err := p.GetNode(context.TODO(), "http://addr1:1")
+	c.Assert(err, check.IsNil)
+	err = healer.UpdateNodeData([]string{node.Address()}, []provision.NodeCheckResult{})
+	c.Assert(err, check.IsNil)
+	time.Sleep(1200 * time.Millisecond)
+	node.(*provisiontest.FakeNode).SetHealth(2, true)
+	waitTime := healer.HandleError(context.TODO(), node.(provision.NodeHealthChecker))
+	c.Assert(waitTime, check.Equals, time.Duration(0))
+	nodes, err := p.ListNodes(context.TODO(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.HasLen, 1)
+	c.Assert(nodes[0].Address(), check.Equals, "http://addr2:2")
+	_, err = healer.GetNodeStatusData(node)
+	c.Assert(err, check.Equals, provision.ErrNodeNotFound)
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: "node", Value: "http://addr1:1"},
+		ExtraTargets: []event.ExtraTarget{
+			{Target: event.Target{Type: "pool", Value: "p1"}},
+		},
+		Kind: "healer",
+		StartCustomData: map[string]interface{}{
+			"reason":   "2 consecutive failures",
+			"node._id": "http://addr1:1",
+		},
+		LogMatches: []string{"rebalancing..."},
+	}, eventtest.HasEvent)
+}
+
 func (s *S) TestHealerHandleErrorFailureEvent(c *check.C) {
 	factory, iaasInst := iaasTesting.NewHealerIaaSConstructorWithInst("addr1")
 	iaas.RegisterIaasProvider("my-healer-iaas", factory)
@@ -1197,6 +1248,7 @@ func (s *S) TestUpdateConfigIgnoresEmpty(c *check.C) {
 		EnabledInherited:             true,
 		MaxUnresponsiveTimeInherited: true,
 		MaxTimeSinceSuccessInherited: true,
+		ReplaceNodeInherited:         true,
 	})
 	err = UpdateConfig("p1", NodeHealerConfig{
 		MaxTimeSinceSuccess: intPtr(2),
@@ -1212,6 +1264,7 @@ func (s *S) TestUpdateConfigIgnoresEmpty(c *check.C) {
 		EnabledInherited:             true,
 		MaxUnresponsiveTimeInherited: true,
 		MaxTimeSinceSuccessInherited: false,
+		ReplaceNodeInherited:         true,
 	})
 	err = UpdateConfig("p1", NodeHealerConfig{
 		MaxTimeSinceSuccess: intPtr(2),
@@ -1228,6 +1281,7 @@ func (s *S) TestUpdateConfigIgnoresEmpty(c *check.C) {
 		EnabledInherited:             true,
 		MaxUnresponsiveTimeInherited: false,
 		MaxTimeSinceSuccessInherited: false,
+		ReplaceNodeInherited:         true,
 	})
 }
 
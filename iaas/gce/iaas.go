@@ -0,0 +1,256 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/iaas"
+	"github.com/tsuru/tsuru/net"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	defaultURL   = "https://compute.googleapis.com/compute/v1"
+	computeScope = "https://www.googleapis.com/auth/compute"
+)
+
+func init() {
+	iaas.RegisterIaasProvider("gce", newGCEIaas)
+}
+
+type gceIaas struct {
+	base iaas.UserDataIaaS
+}
+
+type instance struct {
+	Name              string `json:"name"`
+	Status            string `json:"status"`
+	NetworkInterfaces []struct {
+		NetworkIP     string `json:"networkIP"`
+		AccessConfigs []struct {
+			NatIP string `json:"natIP"`
+		} `json:"accessConfigs"`
+	} `json:"networkInterfaces"`
+}
+
+func newGCEIaas(name string) iaas.IaaS {
+	baseIaas := iaas.UserDataIaaS{NamedIaaS: iaas.NamedIaaS{BaseIaaSName: "gce", IaaSName: name}}
+	return &gceIaas{base: baseIaas}
+}
+
+func (i *gceIaas) tokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	if token, _ := i.base.GetConfigString("token"); token != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), nil
+	}
+	credentialsFile, _ := i.base.GetConfigString("credentials-file")
+	if credentialsFile == "" {
+		return google.DefaultTokenSource(ctx, computeScope)
+	}
+	data, err := ioutil.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := google.CredentialsFromJSON(ctx, data, computeScope)
+	if err != nil {
+		return nil, err
+	}
+	return creds.TokenSource, nil
+}
+
+func (i *gceIaas) param(params map[string]string, name string) (string, error) {
+	if v := params[name]; v != "" {
+		return v, nil
+	}
+	v, _ := i.base.GetConfigString(name)
+	if v == "" {
+		return "", errors.Errorf("%s param is required", name)
+	}
+	return v, nil
+}
+
+func (i *gceIaas) do(method, path string, body interface{}, result interface{}) error {
+	ctx := context.Background()
+	source, err := i.tokenSource(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to get gce credentials")
+	}
+	u, _ := i.base.GetConfigString("url")
+	if u == "" {
+		u = defaultURL
+	}
+	var reqBody bytes.Buffer
+	if body != nil {
+		err = json.NewEncoder(&reqBody).Encode(body)
+		if err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimRight(u, "/")+path, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := *net.Dial15Full300Client
+	client.Transport = &oauth2.Transport{Source: source, Base: net.Dial15Full300Client.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return errors.Errorf("gce api error: %s", apiErr.Error.Message)
+		}
+		return errors.Errorf("gce api error: status %d: %s", resp.StatusCode, data)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (i *gceIaas) CreateMachine(params map[string]string) (*iaas.Machine, error) {
+	project, err := i.param(params, "project")
+	if err != nil {
+		return nil, err
+	}
+	zone, err := i.param(params, "zone")
+	if err != nil {
+		return nil, err
+	}
+	name := params["name"]
+	if name == "" {
+		return nil, errors.New("name param is required")
+	}
+	userData, err := i.base.ReadUserData(params)
+	if err != nil {
+		return nil, err
+	}
+	network := params["network"]
+	if network == "" {
+		network = "global/networks/default"
+	}
+	networkInterface := map[string]interface{}{"network": network}
+	if subnetwork := params["subnetwork"]; subnetwork != "" {
+		networkInterface["subnetwork"] = subnetwork
+	}
+	private, _ := strconv.ParseBool(params["private-networking"])
+	if !private {
+		networkInterface["accessConfigs"] = []map[string]string{{"type": "ONE_TO_ONE_NAT", "name": "External NAT"}}
+	}
+	createRequest := map[string]interface{}{
+		"name":        name,
+		"machineType": fmt.Sprintf("zones/%s/machineTypes/%s", zone, params["machine-type"]),
+		"disks": []map[string]interface{}{{
+			"boot":       true,
+			"autoDelete": true,
+			"initializeParams": map[string]string{
+				"sourceImage": params["image"],
+			},
+		}},
+		"networkInterfaces": []map[string]interface{}{networkInterface},
+		"metadata": map[string]interface{}{
+			"items": []map[string]string{{"key": "startup-script", "value": userData}},
+		},
+	}
+	if rawTags := params["tags"]; rawTags != "" {
+		createRequest["tags"] = map[string][]string{"items": strings.Split(rawTags, ",")}
+	}
+	instancesPath := fmt.Sprintf("/projects/%s/zones/%s/instances", project, zone)
+	err = i.do(http.MethodPost, instancesPath, createRequest, nil)
+	if err != nil {
+		return nil, err
+	}
+	inst, err := i.waitRunning(instancesPath + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	var address string
+	if len(inst.NetworkInterfaces) > 0 {
+		nic := inst.NetworkInterfaces[0]
+		address = nic.NetworkIP
+		if !private && len(nic.AccessConfigs) > 0 {
+			address = nic.AccessConfigs[0].NatIP
+		}
+	}
+	return &iaas.Machine{
+		Address: address,
+		Id:      name,
+		Status:  inst.Status,
+	}, nil
+}
+
+func (i *gceIaas) waitRunning(path string) (*instance, error) {
+	rawTimeout, _ := i.base.GetConfigString("wait-timeout")
+	timeout, _ := strconv.Atoi(rawTimeout)
+	if timeout == 0 {
+		timeout = 300
+	}
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	for {
+		var inst instance
+		err := i.do(http.MethodGet, path, nil, &inst)
+		if err != nil {
+			return nil, err
+		}
+		if inst.Status == "RUNNING" {
+			return &inst, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("timed out waiting for instance %s to be running", inst.Name)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func (i *gceIaas) DeleteMachine(m *iaas.Machine) error {
+	project, err := i.param(m.CreationParams, "project")
+	if err != nil {
+		return err
+	}
+	zone, err := i.param(m.CreationParams, "zone")
+	if err != nil {
+		return err
+	}
+	return i.do(http.MethodDelete, fmt.Sprintf("/projects/%s/zones/%s/instances/%s", project, zone, m.Id), nil, nil)
+}
+
+func (i *gceIaas) Describe() string {
+	return `Google Compute Engine IaaS required params:
+  name=<name>                Name of the instance
+  machine-type=<type>        Machine type (e.g.: n1-standard-2)
+  image=<image>              Source image (e.g.: projects/ubuntu-os-cloud/global/images/family/ubuntu-2004-lts)
+
+There are also some optional parameters, project and zone default to the
+values in the IaaS configuration:
+
+  project=<project>          Project where the instance is created
+  zone=<zone>                Zone of the instance (e.g.: us-central1-a)
+  network=<network>          Network URL, defaults to global/networks/default
+  subnetwork=<subnetwork>    Subnetwork URL
+  tags=<tags>                Comma separated list of network tags
+  private-networking=1/0     Whether to create the instance without an external IP
+`
+}
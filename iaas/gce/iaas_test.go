@@ -0,0 +1,111 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gce
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/iaas"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type gceSuite struct{}
+
+var _ = check.Suite(&gceSuite{})
+
+func (s *gceSuite) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("iaas:gce:token", "test-token")
+	config.Set("iaas:gce:project", "myproject")
+}
+
+func (s *gceSuite) TestCreateMachine(c *check.C) {
+	var createRequest map[string]interface{}
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Header.Get("Authorization"), check.Equals, "Bearer test-token")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/myproject/zones/us-central1-a/instances":
+			err := json.NewDecoder(r.Body).Decode(&createRequest)
+			c.Assert(err, check.IsNil)
+			fmt.Fprintln(w, `{"kind": "compute#operation", "status": "PENDING"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/projects/myproject/zones/us-central1-a/instances/node1":
+			fmt.Fprintln(w, `{"name": "node1", "status": "RUNNING", "networkInterfaces": [{"networkIP": "10.0.0.2", "accessConfigs": [{"natIP": "35.1.2.3"}]}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer fakeServer.Close()
+	config.Set("iaas:gce:url", fakeServer.URL)
+	defer config.Unset("iaas:gce:url")
+	g := newGCEIaas("gce")
+	m, err := g.CreateMachine(map[string]string{
+		"name":         "node1",
+		"zone":         "us-central1-a",
+		"machine-type": "n1-standard-2",
+		"image":        "projects/ubuntu-os-cloud/global/images/family/ubuntu-2004-lts",
+		"tags":         "docker,tsuru",
+		"user-data":    "#!/bin/sh",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(m, check.DeepEquals, &iaas.Machine{Id: "node1", Address: "35.1.2.3", Status: "RUNNING"})
+	c.Assert(createRequest["machineType"], check.Equals, "zones/us-central1-a/machineTypes/n1-standard-2")
+	c.Assert(createRequest["tags"], check.DeepEquals, map[string]interface{}{"items": []interface{}{"docker", "tsuru"}})
+	c.Assert(createRequest["metadata"], check.DeepEquals, map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"key": "startup-script", "value": "#!/bin/sh"}},
+	})
+}
+
+func (s *gceSuite) TestCreateMachinePrivateNetworking(c *check.C) {
+	var createRequest map[string]interface{}
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			json.NewDecoder(r.Body).Decode(&createRequest)
+		}
+		fmt.Fprintln(w, `{"name": "node1", "status": "RUNNING", "networkInterfaces": [{"networkIP": "10.0.0.2"}]}`)
+	}))
+	defer fakeServer.Close()
+	config.Set("iaas:gce:url", fakeServer.URL)
+	defer config.Unset("iaas:gce:url")
+	g := newGCEIaas("gce")
+	m, err := g.CreateMachine(map[string]string{
+		"name":               "node1",
+		"zone":               "us-central1-a",
+		"private-networking": "true",
+		"user-data":          "",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(m.Address, check.Equals, "10.0.0.2")
+	nics := createRequest["networkInterfaces"].([]interface{})
+	c.Assert(nics[0], check.DeepEquals, map[string]interface{}{"network": "global/networks/default"})
+}
+
+func (s *gceSuite) TestCreateMachineWithoutZone(c *check.C) {
+	g := newGCEIaas("gce")
+	_, err := g.CreateMachine(map[string]string{"name": "node1"})
+	c.Assert(err, check.ErrorMatches, "zone param is required")
+}
+
+func (s *gceSuite) TestDeleteMachine(c *check.C) {
+	var path, method string
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, method = r.URL.Path, r.Method
+		fmt.Fprintln(w, `{"kind": "compute#operation"}`)
+	}))
+	defer fakeServer.Close()
+	config.Set("iaas:gce:url", fakeServer.URL)
+	defer config.Unset("iaas:gce:url")
+	g := newGCEIaas("gce")
+	err := g.DeleteMachine(&iaas.Machine{Id: "node1", CreationParams: map[string]string{"zone": "us-central1-a"}})
+	c.Assert(err, check.IsNil)
+	c.Assert(method, check.Equals, http.MethodDelete)
+	c.Assert(path, check.Equals, "/projects/myproject/zones/us-central1-a/instances/node1")
+}
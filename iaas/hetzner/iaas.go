@@ -0,0 +1,194 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hetzner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/iaas"
+	"github.com/tsuru/tsuru/net"
+)
+
+const defaultURL = "https://api.hetzner.cloud/v1"
+
+func init() {
+	iaas.RegisterIaasProvider("hetzner", newHetznerIaas)
+}
+
+type hetznerIaas struct {
+	base iaas.UserDataIaaS
+}
+
+type server struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	PublicNet struct {
+		IPv4 struct {
+			IP string `json:"ip"`
+		} `json:"ipv4"`
+	} `json:"public_net"`
+	PrivateNet []struct {
+		IP string `json:"ip"`
+	} `json:"private_net"`
+}
+
+type serverResponse struct {
+	Server server `json:"server"`
+}
+
+type apiError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func newHetznerIaas(name string) iaas.IaaS {
+	baseIaas := iaas.UserDataIaaS{NamedIaaS: iaas.NamedIaaS{BaseIaaSName: "hetzner", IaaSName: name}}
+	return &hetznerIaas{base: baseIaas}
+}
+
+func (i *hetznerIaas) do(method, path string, body interface{}, result interface{}) error {
+	token, err := i.base.GetConfigString("token")
+	if err != nil {
+		return err
+	}
+	u, _ := i.base.GetConfigString("url")
+	if u == "" {
+		u = defaultURL
+	}
+	var reqBody bytes.Buffer
+	if body != nil {
+		err = json.NewEncoder(&reqBody).Encode(body)
+		if err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimRight(u, "/")+path, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := net.Dial15Full300Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr apiError
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return errors.Errorf("hetzner api error (%s): %s", apiErr.Error.Code, apiErr.Error.Message)
+		}
+		return errors.Errorf("hetzner api error: status %d: %s", resp.StatusCode, data)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (i *hetznerIaas) CreateMachine(params map[string]string) (*iaas.Machine, error) {
+	userData, err := i.base.ReadUserData(params)
+	if err != nil {
+		return nil, err
+	}
+	createRequest := map[string]interface{}{
+		"name":        params["name"],
+		"server_type": params["server-type"],
+		"image":       params["image"],
+		"user_data":   userData,
+	}
+	if location := params["location"]; location != "" {
+		createRequest["location"] = location
+	}
+	if rawSSHKeys := params["ssh-keys"]; rawSSHKeys != "" {
+		createRequest["ssh_keys"] = strings.Split(rawSSHKeys, ",")
+	}
+	if rawNetworks := params["networks"]; rawNetworks != "" {
+		var networks []int
+		for _, n := range strings.Split(rawNetworks, ",") {
+			id, atoiErr := strconv.Atoi(n)
+			if atoiErr != nil {
+				return nil, errors.Errorf("invalid network id %q", n)
+			}
+			networks = append(networks, id)
+		}
+		createRequest["networks"] = networks
+	}
+	var created serverResponse
+	err = i.do(http.MethodPost, "/servers", createRequest, &created)
+	if err != nil {
+		return nil, err
+	}
+	srv, err := i.waitRunning(created.Server.ID)
+	if err != nil {
+		return nil, err
+	}
+	address := srv.PublicNet.IPv4.IP
+	if private, _ := strconv.ParseBool(params["private-networking"]); private && len(srv.PrivateNet) > 0 {
+		address = srv.PrivateNet[0].IP
+	}
+	return &iaas.Machine{
+		Address: address,
+		Id:      strconv.Itoa(srv.ID),
+		Status:  srv.Status,
+	}, nil
+}
+
+func (i *hetznerIaas) waitRunning(id int) (*server, error) {
+	rawTimeout, _ := i.base.GetConfigString("wait-timeout")
+	timeout, _ := strconv.Atoi(rawTimeout)
+	if timeout == 0 {
+		timeout = 300
+	}
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	for {
+		var resp serverResponse
+		err := i.do(http.MethodGet, fmt.Sprintf("/servers/%d", id), nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Server.Status == "running" && resp.Server.PublicNet.IPv4.IP != "" {
+			return &resp.Server, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("timed out waiting for server %d to be running", id)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func (i *hetznerIaas) DeleteMachine(m *iaas.Machine) error {
+	return i.do(http.MethodDelete, "/servers/"+m.Id, nil, nil)
+}
+
+func (i *hetznerIaas) Describe() string {
+	return `Hetzner Cloud IaaS required params:
+  name=<name>                Name of the server
+  server-type=<type>         Server type (e.g.: cx21)
+  image=<image>              Name or ID of the image (e.g.: ubuntu-20.04)
+
+There are also some optional parameters:
+
+  location=<location>        Location of the server (e.g.: fsn1)
+  ssh-keys=<keys>            Comma separated list of SSH key names or IDs
+  networks=<ids>             Comma separated list of private network IDs
+  private-networking=1/0     Whether to register the node using its private IP
+`
+}
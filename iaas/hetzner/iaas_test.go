@@ -0,0 +1,123 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hetzner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/iaas"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type hetznerSuite struct{}
+
+var _ = check.Suite(&hetznerSuite{})
+
+func (s *hetznerSuite) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("iaas:hetzner:token", "test-token")
+}
+
+func (s *hetznerSuite) TestCreateMachine(c *check.C) {
+	var createRequest map[string]interface{}
+	var calls int
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Header.Get("Authorization"), check.Equals, "Bearer test-token")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/servers":
+			err := json.NewDecoder(r.Body).Decode(&createRequest)
+			c.Assert(err, check.IsNil)
+			fmt.Fprintln(w, `{"server": {"id": 42, "status": "initializing", "public_net": {"ipv4": {"ip": ""}}}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/servers/42":
+			calls++
+			if calls == 1 {
+				fmt.Fprintln(w, `{"server": {"id": 42, "status": "initializing", "public_net": {"ipv4": {"ip": ""}}}}`)
+				return
+			}
+			fmt.Fprintln(w, `{"server": {"id": 42, "status": "running", "public_net": {"ipv4": {"ip": "1.2.3.4"}}, "private_net": [{"ip": "10.0.0.2"}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer fakeServer.Close()
+	config.Set("iaas:hetzner:url", fakeServer.URL)
+	defer config.Unset("iaas:hetzner:url")
+	h := newHetznerIaas("hetzner")
+	m, err := h.CreateMachine(map[string]string{
+		"name":        "node1",
+		"server-type": "cx21",
+		"image":       "ubuntu-20.04",
+		"location":    "fsn1",
+		"ssh-keys":    "key1,key2",
+		"networks":    "10",
+		"user-data":   "#!/bin/sh",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(m, check.DeepEquals, &iaas.Machine{Id: "42", Address: "1.2.3.4", Status: "running"})
+	c.Assert(createRequest, check.DeepEquals, map[string]interface{}{
+		"name":        "node1",
+		"server_type": "cx21",
+		"image":       "ubuntu-20.04",
+		"location":    "fsn1",
+		"ssh_keys":    []interface{}{"key1", "key2"},
+		"networks":    []interface{}{float64(10)},
+		"user_data":   "#!/bin/sh",
+	})
+}
+
+func (s *hetznerSuite) TestCreateMachinePrivateNetworking(c *check.C) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"server": {"id": 42, "status": "running", "public_net": {"ipv4": {"ip": "1.2.3.4"}}, "private_net": [{"ip": "10.0.0.2"}]}}`)
+	}))
+	defer fakeServer.Close()
+	config.Set("iaas:hetzner:url", fakeServer.URL)
+	defer config.Unset("iaas:hetzner:url")
+	h := newHetznerIaas("hetzner")
+	m, err := h.CreateMachine(map[string]string{
+		"name":               "node1",
+		"server-type":        "cx21",
+		"image":              "ubuntu-20.04",
+		"private-networking": "true",
+		"user-data":          "",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(m.Address, check.Equals, "10.0.0.2")
+}
+
+func (s *hetznerSuite) TestCreateMachineAPIError(c *check.C) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintln(w, `{"error": {"code": "invalid_input", "message": "invalid server type"}}`)
+	}))
+	defer fakeServer.Close()
+	config.Set("iaas:hetzner:url", fakeServer.URL)
+	defer config.Unset("iaas:hetzner:url")
+	h := newHetznerIaas("hetzner")
+	_, err := h.CreateMachine(map[string]string{"name": "node1", "user-data": ""})
+	c.Assert(err, check.ErrorMatches, `hetzner api error \(invalid_input\): invalid server type`)
+}
+
+func (s *hetznerSuite) TestDeleteMachine(c *check.C) {
+	var path, method string
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, method = r.URL.Path, r.Method
+		fmt.Fprintln(w, `{"action": {"id": 1}}`)
+	}))
+	defer fakeServer.Close()
+	config.Set("iaas:hetzner:url", fakeServer.URL)
+	defer config.Unset("iaas:hetzner:url")
+	h := newHetznerIaas("hetzner")
+	err := h.DeleteMachine(&iaas.Machine{Id: "42"})
+	c.Assert(err, check.IsNil)
+	c.Assert(method, check.Equals, http.MethodDelete)
+	c.Assert(path, check.Equals, "/servers/42")
+}
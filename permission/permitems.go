@@ -1,7 +1,7 @@
 // AUTOMATICALLY GENERATED FILE - DO NOT EDIT!
 // Please run 'go generate' to update this file.
 //
-// Copyright 2022 tsuru authors. All rights reserved.
+// Copyright 2026 tsuru authors. All rights reserved.
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
@@ -9,23 +9,42 @@ package permission
 
 var (
 	PermAll                              = PermissionRegistry.get("")                                    // [global]
+	PermAccessGrant                      = PermissionRegistry.get("access-grant")                        // [global]
+	PermAccessGrantCreate                = PermissionRegistry.get("access-grant.create")                 // [global]
+	PermAccessGrantDelete                = PermissionRegistry.get("access-grant.delete")                 // [global]
+	PermAccessGrantRead                  = PermissionRegistry.get("access-grant.read")                   // [global]
 	PermApp                              = PermissionRegistry.get("app")                                 // [global app team pool]
+	PermAppTemplate                      = PermissionRegistry.get("app-template")                        // [global]
+	PermAppTemplateCreate                = PermissionRegistry.get("app-template.create")                 // [global]
+	PermAppTemplateDelete                = PermissionRegistry.get("app-template.delete")                 // [global]
+	PermAppTemplateRead                  = PermissionRegistry.get("app-template.read")                   // [global]
+	PermAppTemplateReadEvents            = PermissionRegistry.get("app-template.read.events")            // [global]
+	PermAppTemplateUpdate                = PermissionRegistry.get("app-template.update")                 // [global]
+	PermAppTemplateUpdateSync            = PermissionRegistry.get("app-template.update.sync")            // [global]
 	PermAppAdmin                         = PermissionRegistry.get("app.admin")                           // [global app team pool]
+	PermAppAdminDeployApproval           = PermissionRegistry.get("app.admin.deploy-approval")           // [global app team pool]
+	PermAppAdminDeployGateOverride       = PermissionRegistry.get("app.admin.deploy-gate-override")      // [global app team pool]
+	PermAppAdminFreezeOverride           = PermissionRegistry.get("app.admin.freeze-override")           // [global app team pool]
 	PermAppAdminQuota                    = PermissionRegistry.get("app.admin.quota")                     // [global app team pool]
 	PermAppAdminRoutes                   = PermissionRegistry.get("app.admin.routes")                    // [global app team pool]
 	PermAppBuild                         = PermissionRegistry.get("app.build")                           // [global app team pool]
 	PermAppCreate                        = PermissionRegistry.get("app.create")                          // [global team]
 	PermAppDelete                        = PermissionRegistry.get("app.delete")                          // [global app team pool]
 	PermAppDeploy                        = PermissionRegistry.get("app.deploy")                          // [global app team pool]
+	PermAppDeployApprove                 = PermissionRegistry.get("app.deploy.approve")                  // [global app team pool]
 	PermAppDeployArchiveUrl              = PermissionRegistry.get("app.deploy.archive-url")              // [global app team pool]
 	PermAppDeployBuild                   = PermissionRegistry.get("app.deploy.build")                    // [global app team pool]
 	PermAppDeployGit                     = PermissionRegistry.get("app.deploy.git")                      // [global app team pool]
 	PermAppDeployImage                   = PermissionRegistry.get("app.deploy.image")                    // [global app team pool]
 	PermAppDeployRollback                = PermissionRegistry.get("app.deploy.rollback")                 // [global app team pool]
 	PermAppDeployUpload                  = PermissionRegistry.get("app.deploy.upload")                   // [global app team pool]
+	PermAppPreview                       = PermissionRegistry.get("app.preview")                         // [global app team pool]
+	PermAppPreviewCreate                 = PermissionRegistry.get("app.preview.create")                  // [global app team pool]
+	PermAppPreviewDelete                 = PermissionRegistry.get("app.preview.delete")                  // [global app team pool]
 	PermAppRead                          = PermissionRegistry.get("app.read")                            // [global app team pool]
 	PermAppReadCertificate               = PermissionRegistry.get("app.read.certificate")                // [global app team pool]
 	PermAppReadDeploy                    = PermissionRegistry.get("app.read.deploy")                     // [global app team pool]
+	PermAppReadDeployArtifact            = PermissionRegistry.get("app.read.deploy.artifact")            // [global app team pool]
 	PermAppReadEnv                       = PermissionRegistry.get("app.read.env")                        // [global app team pool]
 	PermAppReadEvents                    = PermissionRegistry.get("app.read.events")                     // [global app team pool]
 	PermAppReadInfo                      = PermissionRegistry.get("app.read.info")                       // [global app team pool]
@@ -35,6 +54,7 @@ var (
 	PermAppRun                           = PermissionRegistry.get("app.run")                             // [global app team pool]
 	PermAppRunShell                      = PermissionRegistry.get("app.run.shell")                       // [global app team pool]
 	PermAppUpdate                        = PermissionRegistry.get("app.update")                          // [global app team pool]
+	PermAppUpdateArchive                 = PermissionRegistry.get("app.update.archive")                  // [global app team pool]
 	PermAppUpdateBind                    = PermissionRegistry.get("app.update.bind")                     // [global app team pool]
 	PermAppUpdateBindVolume              = PermissionRegistry.get("app.update.bind-volume")              // [global app team pool]
 	PermAppUpdateCertificate             = PermissionRegistry.get("app.update.certificate")              // [global app team pool]
@@ -43,22 +63,34 @@ var (
 	PermAppUpdateCname                   = PermissionRegistry.get("app.update.cname")                    // [global app team pool]
 	PermAppUpdateCnameAdd                = PermissionRegistry.get("app.update.cname.add")                // [global app team pool]
 	PermAppUpdateCnameRemove             = PermissionRegistry.get("app.update.cname.remove")             // [global app team pool]
+	PermAppUpdateDependency              = PermissionRegistry.get("app.update.dependency")               // [global app team pool]
 	PermAppUpdateDeploy                  = PermissionRegistry.get("app.update.deploy")                   // [global app team pool]
+	PermAppUpdateDeployGates             = PermissionRegistry.get("app.update.deploy-gates")             // [global app team pool]
+	PermAppUpdateDeployHook              = PermissionRegistry.get("app.update.deploy-hook")              // [global app team pool]
+	PermAppUpdateDeployAutoRollback      = PermissionRegistry.get("app.update.deploy.auto-rollback")     // [global app team pool]
 	PermAppUpdateDeployRollback          = PermissionRegistry.get("app.update.deploy.rollback")          // [global app team pool]
 	PermAppUpdateDescription             = PermissionRegistry.get("app.update.description")              // [global app team pool]
+	PermAppUpdateDisruption              = PermissionRegistry.get("app.update.disruption")               // [global app team pool]
 	PermAppUpdateEnv                     = PermissionRegistry.get("app.update.env")                      // [global app team pool]
+	PermAppUpdateEnvGroup                = PermissionRegistry.get("app.update.env-group")                // [global app team pool]
+	PermAppUpdateEnvRollback             = PermissionRegistry.get("app.update.env.rollback")             // [global app team pool]
 	PermAppUpdateEnvSet                  = PermissionRegistry.get("app.update.env.set")                  // [global app team pool]
 	PermAppUpdateEnvUnset                = PermissionRegistry.get("app.update.env.unset")                // [global app team pool]
 	PermAppUpdateEvents                  = PermissionRegistry.get("app.update.events")                   // [global app team pool]
 	PermAppUpdateGrant                   = PermissionRegistry.get("app.update.grant")                    // [global app team pool]
 	PermAppUpdateImageReset              = PermissionRegistry.get("app.update.image-reset")              // [global app team pool]
 	PermAppUpdateLog                     = PermissionRegistry.get("app.update.log")                      // [global app team pool]
+	PermAppUpdateMaintenance             = PermissionRegistry.get("app.update.maintenance")              // [global app team pool]
 	PermAppUpdateMetadata                = PermissionRegistry.get("app.update.metadata")                 // [global app team pool]
+	PermAppUpdatePlacement               = PermissionRegistry.get("app.update.placement")                // [global app team pool]
 	PermAppUpdatePlan                    = PermissionRegistry.get("app.update.plan")                     // [global app team pool]
 	PermAppUpdatePlanoverride            = PermissionRegistry.get("app.update.planoverride")             // [global app team pool]
 	PermAppUpdatePlatform                = PermissionRegistry.get("app.update.platform")                 // [global app team pool]
 	PermAppUpdatePool                    = PermissionRegistry.get("app.update.pool")                     // [global app team pool]
+	PermAppUpdateProject                 = PermissionRegistry.get("app.update.project")                  // [global app team pool]
+	PermAppUpdateReconcile               = PermissionRegistry.get("app.update.reconcile")                // [global app team pool]
 	PermAppUpdateRestart                 = PermissionRegistry.get("app.update.restart")                  // [global app team pool]
+	PermAppUpdateRestore                 = PermissionRegistry.get("app.update.restore")                  // [global app team pool]
 	PermAppUpdateRevoke                  = PermissionRegistry.get("app.update.revoke")                   // [global app team pool]
 	PermAppUpdateRoutable                = PermissionRegistry.get("app.update.routable")                 // [global app team pool]
 	PermAppUpdateRouter                  = PermissionRegistry.get("app.update.router")                   // [global app team pool]
@@ -71,6 +103,7 @@ var (
 	PermAppUpdateSwap                    = PermissionRegistry.get("app.update.swap")                     // [global app team pool]
 	PermAppUpdateTags                    = PermissionRegistry.get("app.update.tags")                     // [global app team pool]
 	PermAppUpdateTeamowner               = PermissionRegistry.get("app.update.teamowner")                // [global app team pool]
+	PermAppUpdateUnarchive               = PermissionRegistry.get("app.update.unarchive")                // [global app team pool]
 	PermAppUpdateUnbind                  = PermissionRegistry.get("app.update.unbind")                   // [global app team pool]
 	PermAppUpdateUnbindVolume            = PermissionRegistry.get("app.update.unbind-volume")            // [global app team pool]
 	PermAppUpdateUnit                    = PermissionRegistry.get("app.update.unit")                     // [global app team pool]
@@ -78,10 +111,14 @@ var (
 	PermAppUpdateUnitAutoscale           = PermissionRegistry.get("app.update.unit.autoscale")           // [global app team pool]
 	PermAppUpdateUnitAutoscaleAdd        = PermissionRegistry.get("app.update.unit.autoscale.add")       // [global app team pool]
 	PermAppUpdateUnitAutoscaleRemove     = PermissionRegistry.get("app.update.unit.autoscale.remove")    // [global app team pool]
+	PermAppUpdateUnitKill                = PermissionRegistry.get("app.update.unit.kill")                // [global app team pool]
 	PermAppUpdateUnitRegister            = PermissionRegistry.get("app.update.unit.register")            // [global app team pool]
 	PermAppUpdateUnitRemove              = PermissionRegistry.get("app.update.unit.remove")              // [global app team pool]
-	PermAppUpdateUnitKill                = PermissionRegistry.get("app.update.unit.kill")                // [global app team pool]
+	PermAppUpdateUnitRestart             = PermissionRegistry.get("app.update.unit.restart")             // [global app team pool]
 	PermAppUpdateUnitStatus              = PermissionRegistry.get("app.update.unit.status")              // [global app team pool]
+	PermBackup                           = PermissionRegistry.get("backup")                              // [global]
+	PermBackupRead                       = PermissionRegistry.get("backup.read")                         // [global]
+	PermBackupReadCredentials            = PermissionRegistry.get("backup.read.credentials")             // [global]
 	PermCluster                          = PermissionRegistry.get("cluster")                             // [global]
 	PermClusterAdmin                     = PermissionRegistry.get("cluster.admin")                       // [global]
 	PermClusterCreate                    = PermissionRegistry.get("cluster.create")                      // [global]
@@ -90,17 +127,37 @@ var (
 	PermClusterReadEvents                = PermissionRegistry.get("cluster.read.events")                 // [global]
 	PermClusterUpdate                    = PermissionRegistry.get("cluster.update")                      // [global]
 	PermDebug                            = PermissionRegistry.get("debug")                               // [global]
+	PermDirectorySync                    = PermissionRegistry.get("directory-sync")                      // [global]
+	PermDirectorySyncCreate              = PermissionRegistry.get("directory-sync.create")               // [global]
+	PermDirectorySyncDelete              = PermissionRegistry.get("directory-sync.delete")               // [global]
+	PermDirectorySyncRead                = PermissionRegistry.get("directory-sync.read")                 // [global]
+	PermDirectorySyncRun                 = PermissionRegistry.get("directory-sync.run")                  // [global]
+	PermEnvGroup                         = PermissionRegistry.get("env-group")                           // [global]
+	PermEnvGroupCreate                   = PermissionRegistry.get("env-group.create")                    // [global]
+	PermEnvGroupDelete                   = PermissionRegistry.get("env-group.delete")                    // [global]
+	PermEnvGroupRead                     = PermissionRegistry.get("env-group.read")                      // [global]
+	PermEnvGroupReadEvents               = PermissionRegistry.get("env-group.read.events")               // [global]
+	PermEnvGroupUpdate                   = PermissionRegistry.get("env-group.update")                    // [global]
 	PermEventBlock                       = PermissionRegistry.get("event-block")                         // [global]
 	PermEventBlockAdd                    = PermissionRegistry.get("event-block.add")                     // [global]
 	PermEventBlockRead                   = PermissionRegistry.get("event-block.read")                    // [global]
 	PermEventBlockReadEvents             = PermissionRegistry.get("event-block.read.events")             // [global]
 	PermEventBlockRemove                 = PermissionRegistry.get("event-block.remove")                  // [global]
+	PermGitops                           = PermissionRegistry.get("gitops")                              // [global]
+	PermGitopsRead                       = PermissionRegistry.get("gitops.read")                         // [global]
+	PermGitopsUpdate                     = PermissionRegistry.get("gitops.update")                       // [global]
 	PermHealing                          = PermissionRegistry.get("healing")                             // [global pool]
 	PermHealingDelete                    = PermissionRegistry.get("healing.delete")                      // [global pool]
 	PermHealingRead                      = PermissionRegistry.get("healing.read")                        // [global pool]
 	PermHealingUpdate                    = PermissionRegistry.get("healing.update")                      // [global pool]
 	PermInstall                          = PermissionRegistry.get("install")                             // [global]
 	PermInstallManage                    = PermissionRegistry.get("install.manage")                      // [global]
+	PermIntegration                      = PermissionRegistry.get("integration")                         // [global team]
+	PermIntegrationCreate                = PermissionRegistry.get("integration.create")                  // [global team]
+	PermIntegrationDelete                = PermissionRegistry.get("integration.delete")                  // [global team]
+	PermIntegrationRead                  = PermissionRegistry.get("integration.read")                    // [global team]
+	PermIntegrationReadEvents            = PermissionRegistry.get("integration.read.events")             // [global team]
+	PermIntegrationUpdate                = PermissionRegistry.get("integration.update")                  // [global team]
 	PermMachine                          = PermissionRegistry.get("machine")                             // [global iaas]
 	PermMachineDelete                    = PermissionRegistry.get("machine.delete")                      // [global iaas]
 	PermMachineRead                      = PermissionRegistry.get("machine.read")                        // [global iaas]
@@ -120,6 +177,8 @@ var (
 	PermNodeDelete                       = PermissionRegistry.get("node.delete")                         // [global pool]
 	PermNodeRead                         = PermissionRegistry.get("node.read")                           // [global pool]
 	PermNodeUpdate                       = PermissionRegistry.get("node.update")                         // [global pool]
+	PermNodeUpdateCerts                  = PermissionRegistry.get("node.update.certs")                   // [global pool]
+	PermNodeUpdateEviction               = PermissionRegistry.get("node.update.eviction")                // [global pool]
 	PermNodeUpdateMove                   = PermissionRegistry.get("node.update.move")                    // [global pool]
 	PermNodeUpdateMoveContainer          = PermissionRegistry.get("node.update.move.container")          // [global pool]
 	PermNodeUpdateMoveContainers         = PermissionRegistry.get("node.update.move.containers")         // [global pool]
@@ -130,11 +189,29 @@ var (
 	PermNodecontainerRead                = PermissionRegistry.get("nodecontainer.read")                  // [global pool]
 	PermNodecontainerUpdate              = PermissionRegistry.get("nodecontainer.update")                // [global pool]
 	PermNodecontainerUpdateUpgrade       = PermissionRegistry.get("nodecontainer.update.upgrade")        // [global pool]
+	PermNotification                     = PermissionRegistry.get("notification")                        // [global team]
+	PermNotificationCreate               = PermissionRegistry.get("notification.create")                 // [global team]
+	PermNotificationDelete               = PermissionRegistry.get("notification.delete")                 // [global team]
+	PermNotificationRead                 = PermissionRegistry.get("notification.read")                   // [global team]
+	PermNotificationReadEvents           = PermissionRegistry.get("notification.read.events")            // [global team]
+	PermNotificationUpdate               = PermissionRegistry.get("notification.update")                 // [global team]
+	PermPermissionTemplate               = PermissionRegistry.get("permission-template")                 // [global]
+	PermPermissionTemplateCreate         = PermissionRegistry.get("permission-template.create")          // [global]
+	PermPermissionTemplateDelete         = PermissionRegistry.get("permission-template.delete")          // [global]
+	PermPermissionTemplateRead           = PermissionRegistry.get("permission-template.read")            // [global]
+	PermPermissionTemplateUpdate         = PermissionRegistry.get("permission-template.update")          // [global]
+	PermPipelineHook                     = PermissionRegistry.get("pipeline-hook")                       // [global]
+	PermPipelineHookCreate               = PermissionRegistry.get("pipeline-hook.create")                // [global]
+	PermPipelineHookDelete               = PermissionRegistry.get("pipeline-hook.delete")                // [global]
+	PermPipelineHookRead                 = PermissionRegistry.get("pipeline-hook.read")                  // [global]
+	PermPipelineHookReadEvents           = PermissionRegistry.get("pipeline-hook.read.events")           // [global]
+	PermPipelineHookUpdate               = PermissionRegistry.get("pipeline-hook.update")                // [global]
 	PermPlan                             = PermissionRegistry.get("plan")                                // [global]
 	PermPlanCreate                       = PermissionRegistry.get("plan.create")                         // [global]
 	PermPlanDelete                       = PermissionRegistry.get("plan.delete")                         // [global]
 	PermPlanRead                         = PermissionRegistry.get("plan.read")                           // [global]
 	PermPlanReadEvents                   = PermissionRegistry.get("plan.read.events")                    // [global]
+	PermPlanUpdate                       = PermissionRegistry.get("plan.update")                         // [global]
 	PermPlatform                         = PermissionRegistry.get("platform")                            // [global]
 	PermPlatformCreate                   = PermissionRegistry.get("platform.create")                     // [global]
 	PermPlatformDelete                   = PermissionRegistry.get("platform.delete")                     // [global]
@@ -148,19 +225,39 @@ var (
 	PermPoolRead                         = PermissionRegistry.get("pool.read")                           // [global pool]
 	PermPoolReadConstraints              = PermissionRegistry.get("pool.read.constraints")               // [global pool]
 	PermPoolReadEvents                   = PermissionRegistry.get("pool.read.events")                    // [global pool]
+	PermPoolReadFreeze                   = PermissionRegistry.get("pool.read.freeze")                    // [global pool]
+	PermPoolReadRegistry                 = PermissionRegistry.get("pool.read.registry")                  // [global pool]
+	PermPoolReadTrustedKeys              = PermissionRegistry.get("pool.read.trusted-keys")              // [global pool]
 	PermPoolUpdate                       = PermissionRegistry.get("pool.update")                         // [global pool]
 	PermPoolUpdateConstraints            = PermissionRegistry.get("pool.update.constraints")             // [global pool]
 	PermPoolUpdateConstraintsSet         = PermissionRegistry.get("pool.update.constraints.set")         // [global pool]
+	PermPoolUpdateFreeze                 = PermissionRegistry.get("pool.update.freeze")                  // [global pool]
+	PermPoolUpdateFreezeRemove           = PermissionRegistry.get("pool.update.freeze.remove")           // [global pool]
+	PermPoolUpdateFreezeSet              = PermissionRegistry.get("pool.update.freeze.set")              // [global pool]
 	PermPoolUpdateLogs                   = PermissionRegistry.get("pool.update.logs")                    // [global pool]
+	PermPoolUpdateRegistry               = PermissionRegistry.get("pool.update.registry")                // [global pool]
+	PermPoolUpdateRegistryRemove         = PermissionRegistry.get("pool.update.registry.remove")         // [global pool]
+	PermPoolUpdateRegistrySet            = PermissionRegistry.get("pool.update.registry.set")            // [global pool]
 	PermPoolUpdateTeam                   = PermissionRegistry.get("pool.update.team")                    // [global pool]
 	PermPoolUpdateTeamAdd                = PermissionRegistry.get("pool.update.team.add")                // [global pool]
 	PermPoolUpdateTeamRemove             = PermissionRegistry.get("pool.update.team.remove")             // [global pool]
+	PermPoolUpdateTrustedKeys            = PermissionRegistry.get("pool.update.trusted-keys")            // [global pool]
+	PermPoolUpdateTrustedKeysAdd         = PermissionRegistry.get("pool.update.trusted-keys.add")        // [global pool]
+	PermPoolUpdateTrustedKeysRemove      = PermissionRegistry.get("pool.update.trusted-keys.remove")     // [global pool]
+	PermProject                          = PermissionRegistry.get("project")                             // [global]
+	PermProjectCreate                    = PermissionRegistry.get("project.create")                      // [global]
+	PermProjectDelete                    = PermissionRegistry.get("project.delete")                      // [global]
+	PermProjectRead                      = PermissionRegistry.get("project.read")                        // [global]
+	PermProjectReadEvents                = PermissionRegistry.get("project.read.events")                 // [global]
+	PermProjectUpdate                    = PermissionRegistry.get("project.update")                      // [global]
 	PermRole                             = PermissionRegistry.get("role")                                // [global]
 	PermRoleCreate                       = PermissionRegistry.get("role.create")                         // [global]
 	PermRoleDefault                      = PermissionRegistry.get("role.default")                        // [global]
 	PermRoleDefaultCreate                = PermissionRegistry.get("role.default.create")                 // [global]
 	PermRoleDefaultDelete                = PermissionRegistry.get("role.default.delete")                 // [global]
 	PermRoleDelete                       = PermissionRegistry.get("role.delete")                         // [global]
+	PermRoleExport                       = PermissionRegistry.get("role.export")                         // [global]
+	PermRoleImport                       = PermissionRegistry.get("role.import")                         // [global]
 	PermRoleRead                         = PermissionRegistry.get("role.read")                           // [global]
 	PermRoleReadEvents                   = PermissionRegistry.get("role.read.events")                    // [global]
 	PermRoleUpdate                       = PermissionRegistry.get("role.update")                         // [global]
@@ -173,6 +270,9 @@ var (
 	PermRoleUpdatePermission             = PermissionRegistry.get("role.update.permission")              // [global]
 	PermRoleUpdatePermissionAdd          = PermissionRegistry.get("role.update.permission.add")          // [global]
 	PermRoleUpdatePermissionRemove       = PermissionRegistry.get("role.update.permission.remove")       // [global]
+	PermRoleUpdateTemplate               = PermissionRegistry.get("role.update.template")                // [global]
+	PermRoleUpdateTemplateAdd            = PermissionRegistry.get("role.update.template.add")            // [global]
+	PermRoleUpdateTemplateRemove         = PermissionRegistry.get("role.update.template.remove")         // [global]
 	PermRouter                           = PermissionRegistry.get("router")                              // [global router]
 	PermRouterCreate                     = PermissionRegistry.get("router.create")                       // [global]
 	PermRouterDelete                     = PermissionRegistry.get("router.delete")                       // [global router]
@@ -210,6 +310,7 @@ var (
 	PermServiceReadEvents                = PermissionRegistry.get("service.read.events")                 // [global service team]
 	PermServiceReadPlans                 = PermissionRegistry.get("service.read.plans")                  // [global service team]
 	PermServiceUpdate                    = PermissionRegistry.get("service.update")                      // [global service team]
+	PermServiceUpdateCredentials         = PermissionRegistry.get("service.update.credentials")          // [global service team]
 	PermServiceUpdateDoc                 = PermissionRegistry.get("service.update.doc")                  // [global service team]
 	PermServiceUpdateGrantAccess         = PermissionRegistry.get("service.update.grant-access")         // [global service team]
 	PermServiceUpdateProxy               = PermissionRegistry.get("service.update.proxy")                // [global service team]
@@ -226,6 +327,7 @@ var (
 	PermTeamTokenRead                    = PermissionRegistry.get("team.token.read")                     // [global team]
 	PermTeamTokenUpdate                  = PermissionRegistry.get("team.token.update")                   // [global team]
 	PermTeamUpdate                       = PermissionRegistry.get("team.update")                         // [global team]
+	PermTeamUpdateAppProtection          = PermissionRegistry.get("team.update.app-protection")          // [global team]
 	PermTeamUpdateQuota                  = PermissionRegistry.get("team.update.quota")                   // [global team]
 	PermUser                             = PermissionRegistry.get("user")                                // [global user]
 	PermUserCreate                       = PermissionRegistry.get("user.create")                         // [global]
@@ -22,12 +22,14 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.update.unit.add",
 	"app.update.unit.remove",
 	"app.update.unit.kill",
+	"app.update.unit.restart",
 	"app.update.unit.register",
 	"app.update.unit.status",
 	"app.update.unit.autoscale.add",
 	"app.update.unit.autoscale.remove",
 	"app.update.env.set",
 	"app.update.env.unset",
+	"app.update.env.rollback",
 	"app.update.restart",
 	"app.update.sleep",
 	"app.update.start",
@@ -50,12 +52,28 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.update.certificate.set",
 	"app.update.certificate.unset",
 	"app.update.deploy.rollback",
+	"app.update.deploy.auto-rollback",
 	"app.update.router.add",
 	"app.update.router.update",
 	"app.update.router.remove",
 	"app.update.routable",
 	"app.update.metadata",
+	"app.update.reconcile",
+	"app.update.maintenance",
+	"app.update.restore",
+	"app.update.dependency",
+	"app.update.deploy-hook",
+	"app.update.deploy-gates",
+	"app.update.disruption",
+	"app.update.placement",
+	"app.update.project",
+	"app.update.env-group",
+	"app.update.archive",
+	"app.update.unarchive",
+	"app.preview.create",
+	"app.preview.delete",
 	"app.deploy",
+	"app.deploy.approve",
 	"app.deploy.archive-url",
 	"app.deploy.build",
 	"app.deploy.git",
@@ -64,6 +82,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.deploy.upload",
 	"app.read",
 	"app.read.deploy",
+	"app.read.deploy.artifact",
 	"app.read.router",
 	"app.read.env",
 	"app.read.events",
@@ -76,6 +95,9 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"app.run.shell",
 	"app.admin.routes",
 	"app.admin.quota",
+	"app.admin.deploy-approval",
+	"app.admin.freeze-override",
+	"app.admin.deploy-gate-override",
 	"app.build",
 ).addWithCtx(
 	"node", []permTypes.ContextType{permTypes.CtxPool},
@@ -85,6 +107,8 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"node.update.move.container",
 	"node.update.move.containers",
 	"node.update.rebalance",
+	"node.update.eviction",
+	"node.update.certs",
 	"node.delete",
 ).addWithCtx(
 	"node.autoscale", []permTypes.ContextType{},
@@ -117,6 +141,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"team.token.update",
 	"team.read.quota",
 	"team.update.quota",
+	"team.update.app-protection",
 ).addWithCtx(
 	"user", []permTypes.ContextType{permTypes.CtxUser},
 ).addWithCtx(
@@ -141,6 +166,7 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"service.update.revoke-access",
 	"service.update.grant-access",
 	"service.update.doc",
+	"service.update.credentials",
 	"service.delete",
 	"service-broker.read",
 	"service-broker.read.events",
@@ -176,8 +202,21 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"role.update.context.type",
 	"role.update.permission.add",
 	"role.update.permission.remove",
+	"role.update.template.add",
+	"role.update.template.remove",
 	"role.default.create",
 	"role.default.delete",
+	"role.export",
+	"role.import",
+).add(
+	"access-grant.create",
+	"access-grant.read",
+	"access-grant.delete",
+).add(
+	"permission-template.read",
+	"permission-template.create",
+	"permission-template.update",
+	"permission-template.delete",
 ).add(
 	"platform.create",
 	"platform.delete",
@@ -185,8 +224,30 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"platform.read.events",
 ).add(
 	"plan.create",
+	"plan.update",
 	"plan.delete",
 	"plan.read.events",
+).add(
+	"app-template.create",
+	"app-template.update",
+	"app-template.update.sync",
+	"app-template.delete",
+	"app-template.read.events",
+).add(
+	"project.create",
+	"project.update",
+	"project.delete",
+	"project.read.events",
+).add(
+	"env-group.create",
+	"env-group.update",
+	"env-group.delete",
+	"env-group.read.events",
+).add(
+	"directory-sync.read",
+	"directory-sync.create",
+	"directory-sync.delete",
+	"directory-sync.run",
 ).addWithCtx(
 	"pool", []permTypes.ContextType{permTypes.CtxPool},
 ).addWithCtx(
@@ -198,6 +259,15 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"pool.update.constraints.set",
 	"pool.read.constraints",
 	"pool.update.logs",
+	"pool.read.trusted-keys",
+	"pool.update.trusted-keys.add",
+	"pool.update.trusted-keys.remove",
+	"pool.read.registry",
+	"pool.update.registry.set",
+	"pool.update.registry.remove",
+	"pool.read.freeze",
+	"pool.update.freeze.set",
+	"pool.update.freeze.remove",
 	"pool.delete",
 ).add(
 	"debug",
@@ -247,6 +317,40 @@ var PermissionRegistry = (&registry{}).addWithCtx(
 	"webhook.create",
 	"webhook.update",
 	"webhook.delete",
+).addWithCtx(
+	"notification", []permTypes.ContextType{permTypes.CtxTeam},
+).add(
+	"notification.read",
+	"notification.read.events",
+	"notification.create",
+	"notification.update",
+	"notification.delete",
+).addWithCtx(
+	"integration", []permTypes.ContextType{permTypes.CtxTeam},
+).add(
+	"integration.read",
+	"integration.read.events",
+	"integration.create",
+	"integration.update",
+	"integration.delete",
+).addWithCtx(
+	"gitops", []permTypes.ContextType{},
+).add(
+	"gitops.read",
+	"gitops.update",
+).addWithCtx(
+	"backup", []permTypes.ContextType{},
+).add(
+	"backup.read",
+	"backup.read.credentials",
+).addWithCtx(
+	"pipeline-hook", []permTypes.ContextType{},
+).add(
+	"pipeline-hook.read",
+	"pipeline-hook.read.events",
+	"pipeline-hook.create",
+	"pipeline-hook.update",
+	"pipeline-hook.delete",
 ).addWithCtx(
 	"router", []permTypes.ContextType{permTypes.CtxRouter},
 ).addWithCtx(
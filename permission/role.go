@@ -22,6 +22,11 @@ type Role struct {
 	Description string
 	SchemeNames []string `json:"scheme_names,omitempty"`
 	Events      []string `json:"events,omitempty"`
+	// Templates are the names of the permission templates whose permissions
+	// are granted by the role, in addition to SchemeNames.
+	Templates []string `bson:",omitempty" json:"templates,omitempty"`
+
+	templatePermissions []string
 }
 
 func NewRole(name string, ctx string, description string) (Role, error) {
@@ -60,6 +65,10 @@ func ListRoles() ([]Role, error) {
 	for i := range roles {
 		roles[i].filterValidSchemes()
 	}
+	err = loadTemplatePermissions(roles)
+	if err != nil {
+		return nil, err
+	}
 	return roles, nil
 }
 
@@ -77,6 +86,10 @@ func ListRolesWithEvents() ([]Role, error) {
 	for i := range roles {
 		roles[i].filterValidSchemes()
 	}
+	err = loadTemplatePermissions(roles)
+	if err != nil {
+		return nil, err
+	}
 	return roles, nil
 }
 
@@ -97,6 +110,10 @@ func ListRolesForEvent(evt *permTypes.RoleEvent) ([]Role, error) {
 	for i := range roles {
 		roles[i].filterValidSchemes()
 	}
+	err = loadTemplatePermissions(roles)
+	if err != nil {
+		return nil, err
+	}
 	return roles, nil
 }
 
@@ -115,7 +132,12 @@ func FindRole(name string) (Role, error) {
 		return role, err
 	}
 	role.filterValidSchemes()
-	return role, nil
+	roles := []Role{role}
+	err = loadTemplatePermissions(roles)
+	if err != nil {
+		return role, err
+	}
+	return roles[0], nil
 }
 
 func DestroyRole(name string) error {
@@ -132,6 +154,30 @@ func DestroyRole(name string) error {
 }
 
 func (r *Role) AddPermissions(permNames ...string) error {
+	err := validatePermissions(r.ContextType, permNames)
+	if err != nil {
+		return err
+	}
+	coll, err := rolesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.UpdateId(r.Name, bson.M{"$addToSet": bson.M{"schemenames": bson.M{"$each": permNames}}})
+	if err != nil {
+		return err
+	}
+	dbRole, err := FindRole(r.Name)
+	if err != nil {
+		return err
+	}
+	r.SchemeNames = dbRole.SchemeNames
+	return nil
+}
+
+// validatePermissions checks that the permissions exist and, when ctxType
+// isn't empty, that they are allowed in contexts of this type.
+func validatePermissions(ctxType permTypes.ContextType, permNames []string) error {
 	for _, permName := range permNames {
 		if permName == "" {
 			return permTypes.ErrInvalidPermissionName
@@ -143,9 +189,12 @@ func (r *Role) AddPermissions(permNames ...string) error {
 		if reg == nil {
 			return &permTypes.ErrPermissionNotFound{Permission: permName}
 		}
+		if ctxType == "" {
+			continue
+		}
 		var found bool
-		for _, ctxType := range reg.AllowedContexts() {
-			if ctxType == r.ContextType {
+		for _, allowed := range reg.AllowedContexts() {
+			if allowed == ctxType {
 				found = true
 				break
 			}
@@ -153,24 +202,10 @@ func (r *Role) AddPermissions(permNames ...string) error {
 		if !found {
 			return &permTypes.ErrPermissionNotAllowed{
 				Permission:  permName,
-				ContextType: r.ContextType,
+				ContextType: ctxType,
 			}
 		}
 	}
-	coll, err := rolesCollection()
-	if err != nil {
-		return err
-	}
-	defer coll.Close()
-	err = coll.UpdateId(r.Name, bson.M{"$addToSet": bson.M{"schemenames": bson.M{"$each": permNames}}})
-	if err != nil {
-		return err
-	}
-	dbRole, err := FindRole(r.Name)
-	if err != nil {
-		return err
-	}
-	r.SchemeNames = dbRole.SchemeNames
 	return nil
 }
 
@@ -215,6 +250,7 @@ func (r *Role) filterValidSchemes() PermissionSchemeList {
 
 func (r *Role) PermissionsFor(contextValue string) []Permission {
 	schemes := r.filterValidSchemes()
+	schemes = append(schemes, r.templateSchemes(schemes)...)
 	permissions := make([]Permission, len(schemes))
 	for i, scheme := range schemes {
 		permissions[i] = Permission{
@@ -298,7 +334,7 @@ func (r *Role) Add() error {
 		return err
 	}
 	defer coll.Close()
-	insertRole := Role{Name: name, ContextType: r.ContextType, Description: r.Description, SchemeNames: r.SchemeNames, Events: r.Events}
+	insertRole := Role{Name: name, ContextType: r.ContextType, Description: r.Description, SchemeNames: r.SchemeNames, Events: r.Events, Templates: r.Templates}
 	err = coll.Insert(insertRole)
 	if mgo.IsDup(err) {
 		return permTypes.ErrRoleAlreadyExists
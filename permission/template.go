@@ -0,0 +1,353 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package permission
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+// Template is a named set of permissions, like "deployer" or "observer",
+// shared by roles. Changes to a template apply to every role using it.
+// Permissions of a template not allowed in the context of a role are ignored
+// for that role.
+type Template struct {
+	Name        string   `bson:"_id" json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+func (t *Template) validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return permTypes.ErrInvalidTemplateName
+	}
+	return validatePermissions("", t.Permissions)
+}
+
+func templatesCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection("permission_templates"), nil
+}
+
+func AddTemplate(t *Template) error {
+	err := t.validate()
+	if err != nil {
+		return err
+	}
+	coll, err := templatesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.Insert(t)
+	if mgo.IsDup(err) {
+		return permTypes.ErrTemplateAlreadyExists
+	}
+	return err
+}
+
+func UpdateTemplate(t *Template) error {
+	err := t.validate()
+	if err != nil {
+		return err
+	}
+	coll, err := templatesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.UpdateId(t.Name, bson.M{"$set": bson.M{"description": t.Description, "permissions": t.Permissions}})
+	if err == mgo.ErrNotFound {
+		return permTypes.ErrTemplateNotFound
+	}
+	return err
+}
+
+func ListTemplates() ([]Template, error) {
+	coll, err := templatesCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var templates []Template
+	err = coll.Find(nil).Sort("_id").All(&templates)
+	return templates, err
+}
+
+func FindTemplate(name string) (*Template, error) {
+	coll, err := templatesCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var t Template
+	err = coll.FindId(name).One(&t)
+	if err == mgo.ErrNotFound {
+		return nil, permTypes.ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DestroyTemplate removes a template, failing when it's still used by roles.
+func DestroyTemplate(name string) error {
+	roles, err := rolesCollection()
+	if err != nil {
+		return err
+	}
+	defer roles.Close()
+	n, err := roles.Find(bson.M{"templates": name}).Count()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return permTypes.ErrTemplateInUse
+	}
+	coll, err := templatesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.RemoveId(name)
+	if err == mgo.ErrNotFound {
+		return permTypes.ErrTemplateNotFound
+	}
+	return err
+}
+
+// AddTemplates grants the permissions of the templates to the role. All
+// permissions of the templates must be allowed in the context of the role.
+func (r *Role) AddTemplates(names ...string) error {
+	for _, name := range names {
+		t, err := FindTemplate(name)
+		if err != nil {
+			return err
+		}
+		err = validatePermissions(r.ContextType, t.Permissions)
+		if err != nil {
+			return err
+		}
+	}
+	coll, err := rolesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.UpdateId(r.Name, bson.M{"$addToSet": bson.M{"templates": bson.M{"$each": names}}})
+	if err != nil {
+		return err
+	}
+	return r.reload()
+}
+
+func (r *Role) RemoveTemplates(names ...string) error {
+	coll, err := rolesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	err = coll.UpdateId(r.Name, bson.M{"$pullAll": bson.M{"templates": names}})
+	if err != nil {
+		return err
+	}
+	return r.reload()
+}
+
+func (r *Role) reload() error {
+	dbRole, err := FindRole(r.Name)
+	if err != nil {
+		return err
+	}
+	r.Templates = dbRole.Templates
+	r.templatePermissions = dbRole.templatePermissions
+	return nil
+}
+
+// templateSchemes returns the schemes granted by the templates of the role
+// which are allowed in its context and not already in schemes.
+func (r *Role) templateSchemes(schemes PermissionSchemeList) PermissionSchemeList {
+	seen := make(map[string]struct{}, len(schemes))
+	for _, s := range schemes {
+		seen[s.FullName()] = struct{}{}
+	}
+	var result PermissionSchemeList
+	for _, name := range r.templatePermissions {
+		if validatePermissions(r.ContextType, []string{name}) != nil {
+			continue
+		}
+		permName := name
+		if permName == "*" {
+			permName = ""
+		}
+		if _, ok := seen[permName]; ok {
+			continue
+		}
+		seen[permName] = struct{}{}
+		result = append(result, &PermissionRegistry.getSubRegistry(permName).PermissionScheme)
+	}
+	return result
+}
+
+// loadTemplatePermissions fills the permissions granted by the templates of
+// the roles. Missing templates are ignored.
+func loadTemplatePermissions(roles []Role) error {
+	var names []string
+	for _, r := range roles {
+		names = append(names, r.Templates...)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	coll, err := templatesCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	var templates []Template
+	err = coll.Find(bson.M{"_id": bson.M{"$in": names}}).All(&templates)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]Template, len(templates))
+	for _, t := range templates {
+		byName[t.Name] = t
+	}
+	for i := range roles {
+		roles[i].templatePermissions = nil
+		for _, name := range roles[i].Templates {
+			roles[i].templatePermissions = append(roles[i].templatePermissions, byName[name].Permissions...)
+		}
+		sort.Strings(roles[i].templatePermissions)
+	}
+	return nil
+}
+
+// Bundle holds the roles and templates of an installation, exported as JSON to
+// be imported in other installations.
+type Bundle struct {
+	Templates []Template `json:"templates"`
+	Roles     []Role     `json:"roles"`
+}
+
+func Export() (*Bundle, error) {
+	templates, err := ListTemplates()
+	if err != nil {
+		return nil, err
+	}
+	roles, err := ListRoles()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return &Bundle{Templates: templates, Roles: roles}, nil
+}
+
+// validate checks the bundle, templates referenced by its roles must be in
+// the bundle or in existing.
+func (b *Bundle) validate(existing []Template) error {
+	templates := map[string]Template{}
+	for _, t := range existing {
+		templates[t.Name] = t
+	}
+	for i := range b.Templates {
+		err := b.Templates[i].validate()
+		if err != nil {
+			return err
+		}
+		templates[b.Templates[i].Name] = b.Templates[i]
+	}
+	for i := range b.Roles {
+		r := &b.Roles[i]
+		r.Name = strings.TrimSpace(r.Name)
+		if r.Name == "" {
+			return permTypes.ErrInvalidRoleName
+		}
+		ctxType, err := parseContext(string(r.ContextType))
+		if err != nil {
+			return err
+		}
+		r.ContextType = ctxType
+		err = validatePermissions(r.ContextType, r.SchemeNames)
+		if err != nil {
+			return err
+		}
+		for _, evtName := range r.Events {
+			roleEvent := permTypes.RoleEventMap[evtName]
+			if roleEvent == nil {
+				return permTypes.ErrRoleEventNotFound
+			}
+			if roleEvent.Context != r.ContextType {
+				return permTypes.ErrRoleEventWrongContext{Expected: string(roleEvent.Context), Role: string(r.ContextType)}
+			}
+		}
+		for _, name := range r.Templates {
+			t, ok := templates[name]
+			if !ok {
+				return permTypes.ErrTemplateNotFound
+			}
+			err = validatePermissions(r.ContextType, t.Permissions)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Import creates or replaces the templates and roles in the bundle. The whole
+// bundle is validated before any change is made. Users assigned to replaced
+// roles are kept.
+func Import(b *Bundle) error {
+	existing, err := ListTemplates()
+	if err != nil {
+		return err
+	}
+	err = b.validate(existing)
+	if err != nil {
+		return &tsuruErrors.ValidationError{Message: err.Error()}
+	}
+	tColl, err := templatesCollection()
+	if err != nil {
+		return err
+	}
+	defer tColl.Close()
+	for _, t := range b.Templates {
+		_, err = tColl.UpsertId(t.Name, bson.M{"$set": bson.M{"description": t.Description, "permissions": t.Permissions}})
+		if err != nil {
+			return err
+		}
+	}
+	rColl, err := rolesCollection()
+	if err != nil {
+		return err
+	}
+	defer rColl.Close()
+	for _, r := range b.Roles {
+		_, err = rColl.UpsertId(r.Name, bson.M{"$set": bson.M{
+			"contexttype": r.ContextType,
+			"description": r.Description,
+			"schemenames": r.SchemeNames,
+			"events":      r.Events,
+			"templates":   r.Templates,
+		}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package permission
+
+import (
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAddTemplate(c *check.C) {
+	err := AddTemplate(&Template{Name: "deployer", Permissions: []string{"app.deploy", "app.read"}})
+	c.Assert(err, check.IsNil)
+	t, err := FindTemplate("deployer")
+	c.Assert(err, check.IsNil)
+	c.Assert(t.Permissions, check.DeepEquals, []string{"app.deploy", "app.read"})
+	err = AddTemplate(&Template{Name: "deployer"})
+	c.Assert(err, check.Equals, permTypes.ErrTemplateAlreadyExists)
+	err = AddTemplate(&Template{Name: " "})
+	c.Assert(err, check.Equals, permTypes.ErrInvalidTemplateName)
+	err = AddTemplate(&Template{Name: "other", Permissions: []string{"app.invalid"}})
+	c.Assert(err, check.ErrorMatches, `permission named "app.invalid" not found`)
+}
+
+func (s *S) TestUpdateTemplate(c *check.C) {
+	err := AddTemplate(&Template{Name: "observer", Permissions: []string{"app.read"}})
+	c.Assert(err, check.IsNil)
+	err = UpdateTemplate(&Template{Name: "observer", Description: "read only", Permissions: []string{"app.read", "pool.read.events"}})
+	c.Assert(err, check.IsNil)
+	t, err := FindTemplate("observer")
+	c.Assert(err, check.IsNil)
+	c.Assert(t.Description, check.Equals, "read only")
+	c.Assert(t.Permissions, check.DeepEquals, []string{"app.read", "pool.read.events"})
+	err = UpdateTemplate(&Template{Name: "unknown"})
+	c.Assert(err, check.Equals, permTypes.ErrTemplateNotFound)
+}
+
+func (s *S) TestDestroyTemplate(c *check.C) {
+	err := AddTemplate(&Template{Name: "deployer", Permissions: []string{"app.deploy"}})
+	c.Assert(err, check.IsNil)
+	r, err := NewRole("myrole", "team", "")
+	c.Assert(err, check.IsNil)
+	err = r.AddTemplates("deployer")
+	c.Assert(err, check.IsNil)
+	err = DestroyTemplate("deployer")
+	c.Assert(err, check.Equals, permTypes.ErrTemplateInUse)
+	err = r.RemoveTemplates("deployer")
+	c.Assert(err, check.IsNil)
+	err = DestroyTemplate("deployer")
+	c.Assert(err, check.IsNil)
+	err = DestroyTemplate("deployer")
+	c.Assert(err, check.Equals, permTypes.ErrTemplateNotFound)
+}
+
+func (s *S) TestRoleAddTemplatesNotAllowed(c *check.C) {
+	err := AddTemplate(&Template{Name: "admin", Permissions: []string{"pool.create"}})
+	c.Assert(err, check.IsNil)
+	r, err := NewRole("myrole", "team", "")
+	c.Assert(err, check.IsNil)
+	err = r.AddTemplates("admin")
+	c.Assert(err, check.ErrorMatches, `permission "pool.create" not allowed with context of type "team"`)
+	err = r.AddTemplates("unknown")
+	c.Assert(err, check.Equals, permTypes.ErrTemplateNotFound)
+}
+
+func (s *S) TestRolePermissionsForWithTemplates(c *check.C) {
+	err := AddTemplate(&Template{Name: "deployer", Permissions: []string{"app.deploy", "app.read"}})
+	c.Assert(err, check.IsNil)
+	r, err := NewRole("myrole", "team", "")
+	c.Assert(err, check.IsNil)
+	err = r.AddPermissions("app.read")
+	c.Assert(err, check.IsNil)
+	err = r.AddTemplates("deployer")
+	c.Assert(err, check.IsNil)
+	r, err = FindRole("myrole")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Templates, check.DeepEquals, []string{"deployer"})
+	perms := r.PermissionsFor("team1")
+	c.Assert(perms, check.HasLen, 2)
+	c.Assert(perms[0].Scheme, check.Equals, PermAppRead)
+	c.Assert(perms[1].Scheme, check.Equals, PermAppDeploy)
+	c.Assert(perms[1].Context, check.Equals, permTypes.PermissionContext{CtxType: permTypes.CtxTeam, Value: "team1"})
+	err = UpdateTemplate(&Template{Name: "deployer", Permissions: []string{"app.deploy", "pool.create"}})
+	c.Assert(err, check.IsNil)
+	r, err = FindRole("myrole")
+	c.Assert(err, check.IsNil)
+	perms = r.PermissionsFor("team1")
+	c.Assert(perms, check.HasLen, 2)
+	c.Assert(perms[1].Scheme, check.Equals, PermAppDeploy)
+}
+
+func (s *S) TestExportImport(c *check.C) {
+	err := AddTemplate(&Template{Name: "deployer", Permissions: []string{"app.deploy"}})
+	c.Assert(err, check.IsNil)
+	r, err := NewRole("myrole", "team", "desc")
+	c.Assert(err, check.IsNil)
+	err = r.AddPermissions("app.read")
+	c.Assert(err, check.IsNil)
+	err = r.AddTemplates("deployer")
+	c.Assert(err, check.IsNil)
+	bundle, err := Export()
+	c.Assert(err, check.IsNil)
+	c.Assert(bundle.Templates, check.HasLen, 1)
+	c.Assert(bundle.Roles, check.HasLen, 1)
+	err = DestroyRole("myrole")
+	c.Assert(err, check.IsNil)
+	bundle.Templates[0].Permissions = []string{"app.deploy", "app.read"}
+	err = Import(bundle)
+	c.Assert(err, check.IsNil)
+	r, err = FindRole("myrole")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.ContextType, check.Equals, permTypes.CtxTeam)
+	c.Assert(r.Description, check.Equals, "desc")
+	c.Assert(r.SchemeNames, check.DeepEquals, []string{"app.read"})
+	c.Assert(r.Templates, check.DeepEquals, []string{"deployer"})
+	t, err := FindTemplate("deployer")
+	c.Assert(err, check.IsNil)
+	c.Assert(t.Permissions, check.DeepEquals, []string{"app.deploy", "app.read"})
+}
+
+func (s *S) TestImportInvalid(c *check.C) {
+	err := Import(&Bundle{Roles: []Role{{Name: "r1", ContextType: "team", Templates: []string{"unknown"}}}})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, "permission template not found")
+	err = Import(&Bundle{
+		Templates: []Template{{Name: "admin", Permissions: []string{"pool.create"}}},
+		Roles:     []Role{{Name: "r1", ContextType: "team", Templates: []string{"admin"}}},
+	})
+	c.Assert(err, check.ErrorMatches, `permission "pool.create" not allowed with context of type "team"`)
+	err = Import(&Bundle{Roles: []Role{{Name: "r1", ContextType: "invalid"}}})
+	c.Assert(err, check.ErrorMatches, `invalid context type "invalid"`)
+	roles, err := ListRoles()
+	c.Assert(err, check.IsNil)
+	c.Assert(roles, check.HasLen, 0)
+	templates, err := ListTemplates()
+	c.Assert(err, check.IsNil)
+	c.Assert(templates, check.HasLen, 0)
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package discovery keeps a registry of the addresses of app units, allowing
+// apps to find each other without going through the public routers. Each
+// process of an app is registered under <app>.<process>.<domain>, where the
+// domain defaults to tsuru.internal.
+package discovery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+)
+
+const (
+	collectionName = "service_discovery"
+	DefaultDomain  = "tsuru.internal"
+)
+
+var ErrEntryNotFound = errors.New("discovery entry not found")
+
+// Entry holds the current addresses of the units of an app process.
+type Entry struct {
+	Name      string    `bson:"_id" json:"name"`
+	App       string    `json:"app"`
+	Process   string    `json:"process"`
+	Addresses []string  `json:"addresses"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Domain returns the domain of the registered names, read from the
+// discovery:domain config entry.
+func Domain() string {
+	domain, _ := config.GetString("discovery:domain")
+	domain = canonical(domain)
+	if domain == "" {
+		return DefaultDomain
+	}
+	return domain
+}
+
+// Name returns the name under which the process of the app is registered.
+func Name(appName, process string) string {
+	return fmt.Sprintf("%s.%s.%s", appName, process, Domain())
+}
+
+func canonical(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+func collection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection(collectionName)
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+// Update replaces the entries of the app with the given addresses, keyed by
+// process name. Entries of processes missing from addrs are removed.
+func Update(appName string, addrs map[string][]string) error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	now := time.Now().UTC()
+	names := make([]string, 0, len(addrs))
+	for process, processAddrs := range addrs {
+		sorted := append([]string{}, processAddrs...)
+		sort.Strings(sorted)
+		entry := Entry{
+			Name:      Name(appName, process),
+			App:       appName,
+			Process:   process,
+			Addresses: sorted,
+			UpdatedAt: now,
+		}
+		_, err = coll.UpsertId(entry.Name, entry)
+		if err != nil {
+			return err
+		}
+		names = append(names, entry.Name)
+	}
+	_, err = coll.RemoveAll(bson.M{"app": appName, "_id": bson.M{"$nin": names}})
+	return err
+}
+
+// Resolve returns the entry registered under name.
+func Resolve(name string) (*Entry, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var entry Entry
+	err = coll.FindId(canonical(name)).One(&entry)
+	if err == mgo.ErrNotFound {
+		return nil, ErrEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List returns the entries of the app, sorted by process.
+func List(appName string) ([]Entry, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var entries []Entry
+	err = coll.Find(bson.M{"app": appName}).Sort("process").All(&entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Remove removes all entries of the app.
+func Remove(appName string) error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.RemoveAll(bson.M{"app": appName})
+	return err
+}
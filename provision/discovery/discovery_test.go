@@ -0,0 +1,83 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage *db.Storage
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "provision_discovery_tests")
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	s.storage.Close()
+}
+
+func (s *S) TestName(c *check.C) {
+	c.Assert(Name("myapp", "web"), check.Equals, "myapp.web.tsuru.internal")
+	config.Set("discovery:domain", "Apps.Local.")
+	defer config.Unset("discovery:domain")
+	c.Assert(Name("myapp", "web"), check.Equals, "myapp.web.apps.local")
+}
+
+func (s *S) TestUpdateAndResolve(c *check.C) {
+	err := Update("myapp", map[string][]string{
+		"web":    {"10.0.0.2:8888", "10.0.0.1:8888"},
+		"worker": {"10.0.0.3:8888"},
+	})
+	c.Assert(err, check.IsNil)
+	entry, err := Resolve("MyApp.web.tsuru.internal.")
+	c.Assert(err, check.IsNil)
+	c.Assert(entry.App, check.Equals, "myapp")
+	c.Assert(entry.Process, check.Equals, "web")
+	c.Assert(entry.Addresses, check.DeepEquals, []string{"10.0.0.1:8888", "10.0.0.2:8888"})
+	err = Update("myapp", map[string][]string{"web": {"10.0.0.4:8888"}})
+	c.Assert(err, check.IsNil)
+	entries, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(entries[0].Name, check.Equals, "myapp.web.tsuru.internal")
+	c.Assert(entries[0].Addresses, check.DeepEquals, []string{"10.0.0.4:8888"})
+	_, err = Resolve("myapp.worker.tsuru.internal")
+	c.Assert(err, check.Equals, ErrEntryNotFound)
+}
+
+func (s *S) TestRemove(c *check.C) {
+	err := Update("myapp", map[string][]string{"web": {"10.0.0.1:8888"}})
+	c.Assert(err, check.IsNil)
+	err = Update("otherapp", map[string][]string{"web": {"10.0.0.2:8888"}})
+	c.Assert(err, check.IsNil)
+	err = Remove("myapp")
+	c.Assert(err, check.IsNil)
+	entries, err := List("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 0)
+	_, err = Resolve("otherapp.web.tsuru.internal")
+	c.Assert(err, check.IsNil)
+}
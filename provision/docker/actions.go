@@ -17,6 +17,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/action"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/event"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/provision"
@@ -41,6 +42,30 @@ type runContainerActionsArgs struct {
 	exposedPort      string
 	event            *event.Event
 	version          appTypes.AppVersion
+	// stickyVolume is the sticky storage volume mounted by the unit and
+	// stickySource is the unit previously using it, whose data is migrated
+	// when it runs in another node.
+	stickyVolume string
+	stickySource *container.Container
+	// publishedPorts are the ports exposed by the unit process with the
+	// node ports allocated to them.
+	publishedPorts []types.PublishedPort
+	// namedPorts are the named ports of the unit process, the first one is
+	// also used as the unit main port.
+	namedPorts []types.NamedPort
+	// stages is the deploy event where the durations of the unit creation
+	// stages are recorded. Unlike event, it's not checked for cancelation.
+	stages *event.Event
+	// initSteps are the init steps with a custom image, run in their own
+	// containers before the unit is started.
+	initSteps []provTypes.TsuruYamlInitStep
+}
+
+func (args *runContainerActionsArgs) startStage(name string) func() {
+	if args.buildingImage != "" {
+		return args.event.StartStage(provision.DeployStageBuild)
+	}
+	return args.stages.StartStage(name)
 }
 
 type containersToAdd struct {
@@ -64,6 +89,60 @@ type callbackFunc func(*container.Container, chan *container.Container) error
 
 type rollbackFunc func(*container.Container)
 
+// runInContainersByNode calls callback for every container, grouping them by
+// node. Up to docker:limit:parallel-nodes nodes are handled at the same time
+// and containers in the same node are handled together, relying on the action
+// limiter to protect the node. Unlike runInContainers it doesn't stop on
+// failures, errors from all containers are aggregated in the returned error.
+func runInContainersByNode(containers []container.Container, callback func(*container.Container) error) error {
+	if len(containers) == 0 {
+		return nil
+	}
+	var nodes []string
+	nodeContainers := make(map[string][]*container.Container)
+	for i := range containers {
+		host := containers[i].HostAddr
+		if _, ok := nodeContainers[host]; !ok {
+			nodes = append(nodes, host)
+		}
+		nodeContainers[host] = append(nodeContainers[host], &containers[i])
+	}
+	maxNodes, _ := config.GetInt("docker:limit:parallel-nodes")
+	if maxNodes <= 0 {
+		maxNodes = len(nodes)
+	}
+	nodeLimit := make(chan struct{}, maxNodes)
+	multiErr := tsuruErrors.NewMultiError()
+	var errMtx sync.Mutex
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		nodeLimit <- struct{}{}
+		wg.Add(1)
+		go func(conts []*container.Container) {
+			defer func() {
+				<-nodeLimit
+				wg.Done()
+			}()
+			var nodeWg sync.WaitGroup
+			for _, c := range conts {
+				nodeWg.Add(1)
+				go func(c *container.Container) {
+					defer nodeWg.Done()
+					err := callback(c)
+					if err != nil {
+						errMtx.Lock()
+						multiErr.Add(err)
+						errMtx.Unlock()
+					}
+				}(c)
+			}
+			nodeWg.Wait()
+		}(nodeContainers[node])
+	}
+	wg.Wait()
+	return multiErr.ToError()
+}
+
 func runInContainers(containers []container.Container, callback callbackFunc, rollback rollbackFunc, parallel bool) error {
 	if len(containers) == 0 {
 		return nil
@@ -151,14 +230,17 @@ var insertEmptyContainerInDB = action.Action{
 		}
 		cont := container.Container{
 			Container: types.Container{
-				AppName:       args.app.GetName(),
-				ProcessName:   args.processName,
-				Type:          args.app.GetPlatform(),
-				Name:          generateContainerName(args.app.GetName()),
-				Status:        initialStatus.String(),
-				Image:         args.imageID,
-				BuildingImage: args.buildingImage,
-				ExposedPort:   args.exposedPort,
+				AppName:        args.app.GetName(),
+				ProcessName:    args.processName,
+				Type:           args.app.GetPlatform(),
+				Name:           generateContainerName(args.app.GetName()),
+				Status:         initialStatus.String(),
+				Image:          args.imageID,
+				BuildingImage:  args.buildingImage,
+				ExposedPort:    args.exposedPort,
+				StickyVolume:   args.stickyVolume,
+				PublishedPorts: args.publishedPorts,
+				NamedPorts:     args.namedPorts,
 			},
 		}
 		return &cont, nil
@@ -195,12 +277,13 @@ var createContainer = action.Action{
 		}
 		cont := ctx.Previous.(*container.Container)
 		log.Debugf("create container for app %s, based on image %s", args.app.GetName(), args.imageID)
+		done := args.startStage(provision.DeployStageCreate)
 		err := cont.Create(&container.CreateArgs{
 			ImageID:          args.imageID,
 			Commands:         args.commands,
 			App:              args.app,
 			Deploy:           args.isDeploy,
-			Client:           args.provisioner.ClusterClient(),
+			Client:           args.provisioner.poolClusterClient(args.app.GetPool()),
 			DestinationHosts: args.destinationHosts,
 			ProcessName:      args.processName,
 			Building:         args.buildingImage != "",
@@ -211,6 +294,7 @@ var createContainer = action.Action{
 			log.Errorf("error on create container for app %s - %s", args.app.GetName(), err)
 			return nil, err
 		}
+		done()
 		return cont, nil
 	},
 	Backward: func(ctx action.BWContext) {
@@ -244,6 +328,41 @@ var setContainerID = action.Action{
 	},
 }
 
+var migrateStickyVolume = action.Action{
+	Name: "migrate-sticky-volume",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		args := ctx.Params[0].(runContainerActionsArgs)
+		cont := ctx.Previous.(*container.Container)
+		source := args.stickySource
+		if source == nil || source.HostAddr == cont.HostAddr {
+			return cont, nil
+		}
+		mountPoint := container.StickyStorageMountPoint(args.app)
+		if mountPoint == "" {
+			return cont, nil
+		}
+		if err := checkCanceled(args.event); err != nil {
+			return nil, err
+		}
+		log.Debugf("migrating sticky volume %s of app %s from %s to %s", cont.StickyVolume, args.app.GetName(), source.HostAddr, cont.HostAddr)
+		err := container.MigrateStickyVolume(args.provisioner.ClusterClient(), source, cont, mountPoint)
+		if err != nil {
+			log.Errorf("error migrating sticky volume %s from %s - %s", cont.StickyVolume, source.ID, err)
+			return nil, err
+		}
+		return cont, nil
+	},
+	Backward: func(ctx action.BWContext) {
+		args := ctx.Params[0].(runContainerActionsArgs)
+		cont := ctx.FWResult.(*container.Container)
+		source := args.stickySource
+		if source == nil || source.HostAddr == cont.HostAddr || container.StickyStorageMountPoint(args.app) == "" {
+			return
+		}
+		container.RestartStickySource(args.provisioner.ClusterClient(), source)
+	},
+}
+
 var stopContainer = action.Action{
 	Name: "stop-container",
 	Forward: func(ctx action.FWContext) (action.Result, error) {
@@ -273,6 +392,7 @@ var setNetworkInfo = action.Action{
 		}
 		c.IP = info.IP
 		c.HostPort = info.HTTPHostPort
+		c.UpdateNamedPorts(info)
 		return c, nil
 	},
 }
@@ -286,6 +406,7 @@ var startContainer = action.Action{
 		}
 		c := ctx.Previous.(*container.Container)
 		log.Debugf("starting container %s", c.ID)
+		done := args.startStage(provision.DeployStageStart)
 		err := c.Start(&container.StartArgs{
 			Client:  args.provisioner.ClusterClient(),
 			Limiter: args.provisioner.ActionLimiter(),
@@ -296,6 +417,7 @@ var startContainer = action.Action{
 			log.Errorf("error on start container %s - %s", c.ID, err)
 			return nil, err
 		}
+		done()
 		return c, nil
 	},
 	Backward: func(ctx action.BWContext) {
@@ -346,6 +468,13 @@ var provisionAddUnitsToHost = action.Action{
 			fmt.Fprintf(w, " ---> Destroyed unit %s [%s]\n", cont.ShortID(), cont.ProcessName)
 			return nil
 		}, nil, true)
+		var released []container.Container
+		for _, c := range args.toRemove {
+			if len(c.PublishedPorts) > 0 && c.Status != provision.StatusStopped.String() {
+				released = append(released, c)
+			}
+		}
+		restartReleasedUnits(&args, released)
 	},
 	OnError:   rollbackNotice,
 	MinParams: 1,
@@ -387,10 +516,12 @@ var bindAndHealthcheck = action.Action{
 			}
 			toRollback <- c
 			if doHealthcheck && c.ProcessName == webProcessName {
+				done := args.event.StartStage(provision.DeployStageHealthcheck)
 				err = runHealthcheck(c, yamlData, writer)
 				if err != nil {
 					return err
 				}
+				done()
 			}
 			err = args.provisioner.runRestartAfterHooks(c, yamlData, writer)
 			if err != nil {
@@ -490,6 +621,11 @@ var addNewRoutes = action.Action{
 		if len(routesToAdd) == 0 {
 			return newContainers, nil
 		}
+		if maintApp, ok := args.app.(provision.MaintenanceApp); ok && maintApp.UnderMaintenance() {
+			fmt.Fprintf(writer, " ---> App is under maintenance, routes will be added when it ends\n")
+			return newContainers, nil
+		}
+		done := args.event.StartStage(provision.DeployStageRouteSwap)
 		err = runInRouters(ctx.Context, args.app, func(r router.Router) error {
 			if _, isRouterV2 := r.(router.RouterV2); isRouterV2 {
 				return nil
@@ -504,6 +640,7 @@ var addNewRoutes = action.Action{
 		if err != nil {
 			return nil, err
 		}
+		done()
 		for _, c := range newContainers {
 			if c.Routable {
 				fmt.Fprintf(writer, " ---> Added route to unit %s [%s]\n", c.ShortID(), c.ProcessName)
@@ -676,6 +813,7 @@ var removeOldRoutes = action.Action{
 		if len(routesToRemove) == 0 {
 			return result, nil
 		}
+		done := args.event.StartStage(provision.DeployStageRouteSwap)
 		err = runInRouters(ctx.Context, args.app, func(r router.Router) error {
 			if _, isRouterV2 := r.(router.RouterV2); isRouterV2 {
 				return nil
@@ -693,6 +831,7 @@ var removeOldRoutes = action.Action{
 		if err != nil {
 			return nil, err
 		}
+		done()
 		for _, c := range args.toRemove {
 			if c.Routable {
 				fmt.Fprintf(writer, " ---> Removed route from unit %s [%s]\n", c.ShortID(), c.ProcessName)
@@ -706,6 +845,9 @@ var removeOldRoutes = action.Action{
 		if w == nil {
 			w = ioutil.Discard
 		}
+		if maintApp, ok := args.app.(provision.MaintenanceApp); ok && maintApp.UnderMaintenance() {
+			return
+		}
 		fmt.Fprintf(w, "\n---- Adding back routes to old units ----\n")
 		var routesToAdd []*url.URL
 		for _, c := range args.toRemove {
@@ -744,6 +886,7 @@ var provisionRemoveOldUnits = action.Action{
 		if writer == nil {
 			writer = ioutil.Discard
 		}
+		added, _ := ctx.Previous.([]container.Container)
 		total := len(args.toRemove)
 		fmt.Fprintf(writer, "\n---- Removing %d old %s ----\n", total, pluralize("unit", total))
 		runInContainers(args.toRemove, func(c *container.Container, toRollback chan *container.Container) error {
@@ -751,6 +894,7 @@ var provisionRemoveOldUnits = action.Action{
 			if err != nil {
 				log.Errorf("Ignored error trying to remove old container %q: %s", c.ID, err)
 			}
+			args.provisioner.removeMovedStickyVolume(*c, added)
 			fmt.Fprintf(writer, " ---> Removed old unit %s [%s]\n", c.ShortID(), c.ProcessName)
 			return nil
 		}, nil, true)
@@ -799,6 +943,7 @@ var followLogsAndCommit = action.Action{
 		if !ok {
 			return nil, errors.New("Previous result must be a container.")
 		}
+		doneBuild := args.event.StartStage(provision.DeployStageBuild)
 		type logsResult struct {
 			status int
 			err    error
@@ -843,12 +988,15 @@ var followLogsAndCommit = action.Action{
 				return nil, errors.Errorf("Exit status %d", result.status)
 			}
 		}
+		doneBuild()
 		fmt.Fprintf(args.writer, "\n---- Deploying application image ----\n")
-		imageID, err := c.Commit(args.provisioner.ClusterClient(), args.provisioner.ActionLimiter(), args.writer, true)
+		donePush := args.event.StartStage(provision.DeployStagePush)
+		imageID, err := c.Commit(args.provisioner.ClusterClient(), args.provisioner.ActionLimiter(), args.app.GetPool(), args.writer, true)
 		if err != nil {
 			log.Errorf("error on commit container %s - %s", c.ID, err)
 			return nil, err
 		}
+		donePush()
 		err = args.version.CommitBaseImage()
 		if err != nil {
 			log.Errorf("error on commit image for container %s - %s", c.ID, err)
@@ -15,12 +15,15 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	docker "github.com/fsouza/go-dockerclient"
 	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
 	"github.com/tsuru/config"
 	"github.com/tsuru/tsuru/action"
 	"github.com/tsuru/tsuru/app"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/container"
 	"github.com/tsuru/tsuru/provision/docker/types"
@@ -72,6 +75,47 @@ func (s *S) TestRunInContainersOddMaxWorkers(c *check.C) {
 	c.Assert(called, check.DeepEquals, []string{"1", "2", "3", "4"})
 }
 
+func (s *S) TestRunInContainersByNode(c *check.C) {
+	config.Set("docker:limit:parallel-nodes", 1)
+	defer config.Unset("docker:limit:parallel-nodes")
+	conts := []container.Container{
+		{Container: types.Container{ID: "1", HostAddr: "h1"}}, {Container: types.Container{ID: "2", HostAddr: "h2"}},
+		{Container: types.Container{ID: "3", HostAddr: "h1"}}, {Container: types.Container{ID: "4", HostAddr: "h3"}},
+	}
+	var called []string
+	var mtx sync.Mutex
+	runningHosts := map[string]int{}
+	var maxRunningHosts int
+	runFunc := func(cont *container.Container) error {
+		mtx.Lock()
+		runningHosts[cont.HostAddr]++
+		if len(runningHosts) > maxRunningHosts {
+			maxRunningHosts = len(runningHosts)
+		}
+		called = append(called, cont.ID)
+		mtx.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mtx.Lock()
+		defer mtx.Unlock()
+		runningHosts[cont.HostAddr]--
+		if runningHosts[cont.HostAddr] == 0 {
+			delete(runningHosts, cont.HostAddr)
+		}
+		if cont.ID == "1" || cont.ID == "4" {
+			return errors.Errorf("error in %s", cont.ID)
+		}
+		return nil
+	}
+	err := runInContainersByNode(conts, runFunc)
+	c.Assert(err, check.NotNil)
+	multiErr, ok := err.(*tsuruErrors.MultiError)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(multiErr.Len(), check.Equals, 2)
+	sort.Strings(called)
+	c.Assert(called, check.DeepEquals, []string{"1", "2", "3", "4"})
+	c.Assert(maxRunningHosts, check.Equals, 1)
+}
+
 func (s *S) TestInsertEmptyContainerInDBName(c *check.C) {
 	c.Assert(insertEmptyContainerInDB.Name, check.Equals, "insert-empty-container")
 }
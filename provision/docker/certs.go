@@ -0,0 +1,373 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/api/tracker"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	nodeCertsCollectionName = "docker_node_certs"
+	certRotationEventKind   = "node-cert-rotation"
+
+	defaultCertRotationInterval = time.Hour
+	defaultCertValidity         = 90 * 24 * time.Hour
+
+	certExpiryMetadata = "tls-cert-expiry"
+	certStatusMetadata = "tls-cert-status"
+
+	CertStatusValid    = "valid"
+	CertStatusExpiring = "expiring"
+	CertStatusExpired  = "expired"
+)
+
+// NodeCerts holds the server certificate issued to a node by the internal
+// CA. The client certificate used to reach the node is kept in the cluster
+// storage, along with the node.
+type NodeCerts struct {
+	Node       string    `bson:"_id" json:"node"`
+	CaCert     []byte    `json:"caCert"`
+	ServerCert []byte    `json:"serverCert"`
+	ServerKey  []byte    `json:"serverKey"`
+	IssuedAt   time.Time `json:"issuedAt"`
+	NotAfter   time.Time `json:"notAfter"`
+}
+
+func nodeCertsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection(nodeCertsCollectionName), nil
+}
+
+// GetNodeCerts returns the last certificates issued to the node.
+func GetNodeCerts(address string) (*NodeCerts, error) {
+	coll, err := nodeCertsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var certs NodeCerts
+	err = coll.FindId(address).One(&certs)
+	if err != nil {
+		return nil, err
+	}
+	return &certs, nil
+}
+
+// certValidity returns how long the issued certificates are valid, set in
+// docker:tls:cert-rotation:validity, and how long before they expire they're
+// rotated, set in docker:tls:cert-rotation:renew-before, defaulting to a
+// third of the validity.
+func certValidity() (time.Duration, time.Duration) {
+	validity, _ := config.GetDuration("docker:tls:cert-rotation:validity")
+	if validity <= 0 {
+		validity = defaultCertValidity
+	}
+	renewBefore, _ := config.GetDuration("docker:tls:cert-rotation:renew-before")
+	if renewBefore <= 0 || renewBefore >= validity {
+		renewBefore = validity / 3
+	}
+	return validity, renewBefore
+}
+
+// nodeCertStatus returns the expiry of the client certificate of the node
+// and whether it's valid, expiring or expired.
+func nodeCertStatus(node cluster.Node, now time.Time) (time.Time, string, bool) {
+	leaf, err := parseCertPEM(node.ClientCert)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	_, renewBefore := certValidity()
+	switch {
+	case now.After(leaf.NotAfter):
+		return leaf.NotAfter, CertStatusExpired, true
+	case leaf.NotAfter.Sub(now) < renewBefore:
+		return leaf.NotAfter, CertStatusExpiring, true
+	}
+	return leaf.NotAfter, CertStatusValid, true
+}
+
+func nodeCertMetadata(node cluster.Node) map[string]string {
+	expiry, status, ok := nodeCertStatus(node, time.Now())
+	if !ok {
+		return nil
+	}
+	return map[string]string{
+		certExpiryMetadata: expiry.UTC().Format(time.RFC3339),
+		certStatusMetadata: status,
+	}
+}
+
+func parseCertPEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// certAuthority is the internal CA issuing node certificates.
+type certAuthority struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     interface{}
+}
+
+// loadCertAuthority reads the internal CA from docker:tls:ca-cert and
+// docker:tls:ca-key, defaulting to ca.pem and ca-key.pem in
+// docker:tls:root-path.
+func loadCertAuthority() (*certAuthority, error) {
+	rootPath, _ := config.GetString("docker:tls:root-path")
+	certPath, err := config.GetString("docker:tls:ca-cert")
+	if err != nil {
+		certPath = filepath.Join(rootPath, "ca.pem")
+	}
+	keyPath, err := config.GetString("docker:tls:ca-key")
+	if err != nil {
+		keyPath = filepath.Join(rootPath, "ca-key.pem")
+	}
+	certPEM, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read CA certificate")
+	}
+	keyPEM, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read CA key")
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid CA key pair")
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA {
+		return nil, errors.New("CA certificate is not a certificate authority")
+	}
+	return &certAuthority{cert: cert, certPEM: certPEM, key: pair.PrivateKey}, nil
+}
+
+// manages returns whether the node certificates are issued by the CA. Nodes
+// using certificates from another CA are left untouched, the daemon wouldn't
+// trust certificates issued by this one.
+func (ca *certAuthority) manages(node cluster.Node) bool {
+	u, err := url.Parse(node.Address)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	if len(node.CaCert) == 0 {
+		return true
+	}
+	return bytes.Equal(bytes.TrimSpace(node.CaCert), bytes.TrimSpace(ca.certPEM))
+}
+
+// issue creates a certificate for the usage, with the node host as subject
+// alternative name for server certificates.
+func (ca *certAuthority) issue(host string, usage x509.ExtKeyUsage, now time.Time, validity time.Duration) ([]byte, []byte, time.Time, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host, Organization: []string{"tsuru"}},
+		NotBefore:    now.Add(-5 * time.Minute),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	if usage == x509.ExtKeyUsageServerAuth {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = []net.IP{ip}
+		} else {
+			template.DNSNames = []string{host}
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, template.NotAfter, nil
+}
+
+// rotateNodeCerts issues new client and server certificates for the node,
+// storing the client certificate in the cluster storage, used in every
+// further connection to the node, and the server certificate to be installed
+// in the node. The daemon trusts any client certificate issued by the CA, so
+// the new client certificate is used right away.
+func (p *dockerProvisioner) rotateNodeCerts(node cluster.Node, ca *certAuthority) (*NodeCerts, error) {
+	u, err := url.Parse(node.Address)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Hostname()
+	validity, _ := certValidity()
+	now := time.Now().UTC()
+	serverCert, serverKey, notAfter, err := ca.issue(host, x509.ExtKeyUsageServerAuth, now, validity)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to issue server certificate")
+	}
+	clientCert, clientKey, _, err := ca.issue(host, x509.ExtKeyUsageClientAuth, now, validity)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to issue client certificate")
+	}
+	certs := NodeCerts{
+		Node:       node.Address,
+		CaCert:     ca.certPEM,
+		ServerCert: serverCert,
+		ServerKey:  serverKey,
+		IssuedAt:   now,
+		NotAfter:   notAfter,
+	}
+	coll, err := nodeCertsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	_, err = coll.UpsertId(node.Address, certs)
+	if err != nil {
+		return nil, err
+	}
+	dbNode, err := p.storage.RetrieveNode(node.Address)
+	if err != nil {
+		return nil, err
+	}
+	dbNode.CaCert = ca.certPEM
+	dbNode.ClientCert = clientCert
+	dbNode.ClientKey = clientKey
+	err = p.storage.UpdateNode(dbNode)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to update node in cluster storage")
+	}
+	return &certs, nil
+}
+
+func (p *dockerProvisioner) rotateNodeCertsWithEvent(node cluster.Node, ca *certAuthority) (*NodeCerts, error) {
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeNode, Value: node.Address},
+		InternalKind: certRotationEventKind,
+		Allowed:      event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, node.Metadata[provision.PoolMetadataName])),
+	})
+	if err != nil {
+		return nil, err
+	}
+	certs, err := p.rotateNodeCerts(node, ca)
+	var notAfter time.Time
+	if certs != nil {
+		notAfter = certs.NotAfter
+	}
+	if doneErr := evt.DoneCustomData(err, map[string]interface{}{"notAfter": notAfter}); doneErr != nil {
+		log.Errorf("[cert manager] unable to finish event for node %s: %s", node.Address, doneErr)
+	}
+	return certs, err
+}
+
+type nodeCertManager struct {
+	p        *dockerProvisioner
+	interval time.Duration
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+func (m *nodeCertManager) start() {
+	m.shutdown = make(chan struct{})
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		for {
+			m.run()
+			select {
+			case <-time.After(m.interval):
+			case <-m.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (m *nodeCertManager) Shutdown(ctx context.Context) error {
+	close(m.shutdown)
+	select {
+	case <-m.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
+
+func (m *nodeCertManager) run() {
+	if !tracker.IsLeader("docker-cert-manager") {
+		return
+	}
+	ca, err := loadCertAuthority()
+	if err != nil {
+		log.Errorf("[cert manager] unable to load CA: %s", err)
+		return
+	}
+	nodes, err := m.p.Cluster().UnfilteredNodes()
+	if err != nil {
+		log.Errorf("[cert manager] unable to list nodes: %s", err)
+		return
+	}
+	now := time.Now()
+	for _, node := range nodes {
+		select {
+		case <-m.shutdown:
+			return
+		default:
+		}
+		if !ca.manages(node) {
+			continue
+		}
+		if _, status, ok := nodeCertStatus(node, now); ok && status == CertStatusValid {
+			continue
+		}
+		_, err = m.p.rotateNodeCertsWithEvent(node, ca)
+		if err != nil {
+			if _, ok := err.(event.ErrEventLocked); ok {
+				continue
+			}
+			log.Errorf("[cert manager] unable to rotate certificates of node %s: %s", node.Address, err)
+		}
+	}
+}
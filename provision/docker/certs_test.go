@@ -0,0 +1,139 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) writeTestCA(c *check.C) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "tsuru-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	c.Assert(err, check.IsNil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, check.IsNil)
+	dir := c.MkDir()
+	err = ioutil.WriteFile(filepath.Join(dir, "ca.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	c.Assert(err, check.IsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, "ca-key.pem"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	c.Assert(err, check.IsNil)
+	return dir
+}
+
+func (s *S) TestCertAuthorityManages(c *check.C) {
+	config.Set("docker:tls:root-path", s.writeTestCA(c))
+	defer config.Unset("docker:tls:root-path")
+	ca, err := loadCertAuthority()
+	c.Assert(err, check.IsNil)
+	c.Assert(ca.manages(cluster.Node{Address: "https://10.0.0.1:2376"}), check.Equals, true)
+	c.Assert(ca.manages(cluster.Node{Address: "https://10.0.0.1:2376", CaCert: ca.certPEM}), check.Equals, true)
+	c.Assert(ca.manages(cluster.Node{Address: "https://10.0.0.1:2376", CaCert: []byte("other ca")}), check.Equals, false)
+	c.Assert(ca.manages(cluster.Node{Address: "http://10.0.0.1:2375"}), check.Equals, false)
+}
+
+func (s *S) TestRotateNodeCerts(c *check.C) {
+	config.Set("docker:tls:root-path", s.writeTestCA(c))
+	defer config.Unset("docker:tls:root-path")
+	ca, err := loadCertAuthority()
+	c.Assert(err, check.IsNil)
+	p := &dockerProvisioner{storage: &cluster.MapStorage{}}
+	p.cluster, err = cluster.New(nil, p.storage, "", cluster.Node{Address: "https://10.0.0.1:2376", Metadata: map[string]string{"pool": "pool1"}})
+	c.Assert(err, check.IsNil)
+	node, err := p.Cluster().GetNode("https://10.0.0.1:2376")
+	c.Assert(err, check.IsNil)
+	certs, err := p.rotateNodeCerts(node, ca)
+	c.Assert(err, check.IsNil)
+	server, err := parseCertPEM(certs.ServerCert)
+	c.Assert(err, check.IsNil)
+	c.Assert(server.IPAddresses[0].String(), check.Equals, "10.0.0.1")
+	c.Assert(server.ExtKeyUsage, check.DeepEquals, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth})
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	_, err = server.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+	c.Assert(err, check.IsNil)
+	stored, err := GetNodeCerts("https://10.0.0.1:2376")
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.ServerCert, check.DeepEquals, certs.ServerCert)
+	node, err = p.Cluster().GetNode("https://10.0.0.1:2376")
+	c.Assert(err, check.IsNil)
+	c.Assert(node.CaCert, check.DeepEquals, ca.certPEM)
+	client, err := parseCertPEM(node.ClientCert)
+	c.Assert(err, check.IsNil)
+	_, err = client.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	c.Assert(err, check.IsNil)
+	wrapper := &clusterNodeWrapper{Node: &node, prov: p}
+	extra := wrapper.ExtraData()
+	c.Assert(extra[certStatusMetadata], check.Equals, CertStatusValid)
+	c.Assert(extra[certExpiryMetadata], check.Equals, client.NotAfter.UTC().Format(time.RFC3339))
+}
+
+func (s *S) TestNodeCertStatus(c *check.C) {
+	config.Set("docker:tls:root-path", s.writeTestCA(c))
+	defer config.Unset("docker:tls:root-path")
+	ca, err := loadCertAuthority()
+	c.Assert(err, check.IsNil)
+	now := time.Now()
+	clientCert, _, notAfter, err := ca.issue("10.0.0.1", x509.ExtKeyUsageClientAuth, now, 30*24*time.Hour)
+	c.Assert(err, check.IsNil)
+	node := cluster.Node{Address: "https://10.0.0.1:2376", ClientCert: clientCert}
+	_, status, ok := nodeCertStatus(node, now)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(status, check.Equals, CertStatusValid)
+	_, status, _ = nodeCertStatus(node, notAfter.Add(-24*time.Hour))
+	c.Assert(status, check.Equals, CertStatusExpiring)
+	_, status, _ = nodeCertStatus(node, notAfter.Add(time.Hour))
+	c.Assert(status, check.Equals, CertStatusExpired)
+	_, _, ok = nodeCertStatus(cluster.Node{Address: "http://10.0.0.1:2375"}, now)
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *S) TestNodeCertManagerRotatesExpiringCerts(c *check.C) {
+	config.Set("docker:tls:root-path", s.writeTestCA(c))
+	defer config.Unset("docker:tls:root-path")
+	ca, err := loadCertAuthority()
+	c.Assert(err, check.IsNil)
+	expiring, expiringKey, _, err := ca.issue("10.0.0.1", x509.ExtKeyUsageClientAuth, time.Now().Add(-89*24*time.Hour), 90*24*time.Hour)
+	c.Assert(err, check.IsNil)
+	p := &dockerProvisioner{storage: &cluster.MapStorage{}}
+	p.cluster, err = cluster.New(nil, p.storage, "",
+		cluster.Node{Address: "https://10.0.0.1:2376", CaCert: ca.certPEM, ClientCert: expiring, ClientKey: expiringKey},
+		cluster.Node{Address: "https://10.0.0.2:2376", CaCert: []byte("other ca")},
+	)
+	c.Assert(err, check.IsNil)
+	manager := &nodeCertManager{p: p, shutdown: make(chan struct{})}
+	manager.run()
+	node, err := p.Cluster().GetNode("https://10.0.0.1:2376")
+	c.Assert(err, check.IsNil)
+	_, status, _ := nodeCertStatus(node, time.Now())
+	c.Assert(status, check.Equals, CertStatusValid)
+	node, err = p.Cluster().GetNode("https://10.0.0.2:2376")
+	c.Assert(err, check.IsNil)
+	c.Assert(node.ClientCert, check.IsNil)
+	_, err = GetNodeCerts("https://10.0.0.2:2376")
+	c.Assert(err, check.NotNil)
+}
@@ -22,11 +22,14 @@ import (
 	"github.com/tsuru/tsuru/provision/dockercommon"
 )
 
+// ClusterClient creates containers in the cluster, pulling images with the
+// credentials of the registry of Pool when they're stored there.
 type ClusterClient struct {
 	*cluster.Cluster
 	Collection    func() *storage.Collection
 	Limiter       provision.ActionLimiter
 	PossibleNodes []string
+	Pool          string
 }
 
 var (
@@ -61,7 +64,7 @@ func (c *ClusterClient) PullAndCreateContainer(opts docker.CreateContainerOption
 		hostAddr, cont, err = c.Cluster.CreateContainerPullOptsSchedulerOpts(
 			opts,
 			pullOpts,
-			dockercommon.RegistryAuthConfig(opts.Config.Image),
+			dockercommon.RegistryAuthConfig(c.Pool, opts.Config.Image),
 			schedulerOpts,
 		)
 		hostAddr = net.URLToHost(hostAddr)
@@ -113,11 +116,12 @@ func (c *ClusterClient) PullAndCreateContainer(opts docker.CreateContainerOption
 			return nil, "", err
 		}
 		nodes = []string{node.Address}
+		dockercommon.PrePullImageFromMirror(c.Cluster, node.Address, opts.Config.Image)
 	}
 	addr, cont, err = c.Cluster.CreateContainerPullOptsSchedulerOpts(
 		opts,
 		pullOpts,
-		dockercommon.RegistryAuthConfig(opts.Config.Image),
+		dockercommon.RegistryAuthConfig(c.Pool, opts.Config.Image),
 		schedulerOpts,
 		nodes...,
 	)
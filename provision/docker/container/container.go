@@ -11,6 +11,7 @@ import (
 	"io"
 	"math/rand"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/types"
 	"github.com/tsuru/tsuru/provision/dockercommon"
+	"github.com/tsuru/tsuru/provision/unithistory"
 	appTypes "github.com/tsuru/tsuru/types/app"
 )
 
@@ -144,6 +146,12 @@ func (c *Container) Create(args *CreateArgs) error {
 		exposedPorts = map[docker.Port]struct{}{
 			docker.Port(c.ExposedPort): {},
 		}
+		for _, p := range c.PublishedPorts {
+			exposedPorts[p.DockerPort()] = struct{}{}
+		}
+		for _, p := range c.NamedPorts {
+			exposedPorts[p.DockerPort()] = struct{}{}
+		}
 	}
 	var user string
 	if args.Building {
@@ -215,6 +223,9 @@ func (c *Container) addEnvsToConfig(args *CreateArgs, port string, cfg *docker.C
 type NetworkInfo struct {
 	HTTPHostPort string
 	IP           string
+	// NamedHostPorts maps the name of each named port of the container to
+	// the port bound in the node.
+	NamedHostPorts map[string]string
 }
 
 func (c *Container) NetworkInfo(client provision.BuilderDockerClient) (NetworkInfo, error) {
@@ -226,16 +237,56 @@ func (c *Container) NetworkInfo(client provision.BuilderDockerClient) (NetworkIn
 	if dockerContainer.NetworkSettings != nil {
 		netInfo.IP = dockerContainer.NetworkSettings.IPAddress
 		httpPort := docker.Port(c.ExposedPort)
-		for _, port := range dockerContainer.NetworkSettings.Ports[httpPort] {
-			if port.HostPort != "" && port.HostIP != "" {
-				netInfo.HTTPHostPort = port.HostPort
-				break
+		netInfo.HTTPHostPort = boundHostPort(dockerContainer.NetworkSettings.Ports[httpPort])
+		for _, p := range c.NamedPorts {
+			if netInfo.NamedHostPorts == nil {
+				netInfo.NamedHostPorts = make(map[string]string, len(c.NamedPorts))
 			}
+			netInfo.NamedHostPorts[p.Name] = boundHostPort(dockerContainer.NetworkSettings.Ports[p.DockerPort()])
 		}
 	}
 	return netInfo, err
 }
 
+func boundHostPort(bindings []docker.PortBinding) string {
+	for _, port := range bindings {
+		if port.HostPort != "" && port.HostIP != "" {
+			return port.HostPort
+		}
+	}
+	return ""
+}
+
+// UpdateNamedPorts sets the node ports bound to the named ports of the
+// container, returning whether any of them changed.
+func (c *Container) UpdateNamedPorts(info NetworkInfo) bool {
+	changed := false
+	for i, p := range c.NamedPorts {
+		hostPort := info.NamedHostPorts[p.Name]
+		if hostPort != p.HostPort {
+			c.NamedPorts[i].HostPort = hostPort
+			changed = true
+		}
+	}
+	return changed
+}
+
+// NamedAddresses returns the addresses of the named ports of the container
+// bound to a port in the node.
+func (c *Container) NamedAddresses() map[string]*url.URL {
+	addrs := make(map[string]*url.URL, len(c.NamedPorts))
+	for _, p := range c.NamedPorts {
+		if c.HostAddr == "" || p.HostPort == "" || p.HostPort == "0" {
+			continue
+		}
+		addrs[p.Name] = &url.URL{
+			Scheme: "http",
+			Host:   fmt.Sprintf("%s:%s", c.HostAddr, p.HostPort),
+		}
+	}
+	return addrs
+}
+
 func (c *Container) ExpectedStatus() provision.Status {
 	if c.StatusBeforeError != "" {
 		return provision.Status(c.StatusBeforeError)
@@ -244,6 +295,7 @@ func (c *Container) ExpectedStatus() provision.Status {
 }
 
 func (c *Container) SetStatus(client provision.BuilderDockerClient, status provision.Status, triggerCallback bool) error {
+	previousStatus := c.Status
 	c.Status = status.String()
 	c.LastStatusUpdate = time.Now().In(time.UTC)
 	if c.Status != provision.StatusError.String() {
@@ -257,7 +309,24 @@ func (c *Container) SetStatus(client provision.BuilderDockerClient, status provi
 	if !triggerCallback {
 		return nil
 	}
-	return c.setState(client, ContainerStateNewStatus)
+	err := c.setState(client, ContainerStateNewStatus)
+	if err != nil {
+		return err
+	}
+	if c.ID != "" && previousStatus != c.Status {
+		err = unithistory.Record(unithistory.Transition{
+			App:     c.AppName,
+			Unit:    c.ID,
+			Process: c.ProcessName,
+			From:    previousStatus,
+			Status:  c.Status,
+			Time:    c.LastStatusUpdate,
+		})
+		if err != nil {
+			log.Errorf("unable to record status transition of unit %s: %s", c.ID, err)
+		}
+	}
+	return nil
 }
 
 func (c *Container) setState(client provision.BuilderDockerClient, s ContainerState) error {
@@ -289,6 +358,16 @@ func (c *Container) Remove(client provision.BuilderDockerClient, limiter provisi
 	if err != nil {
 		log.Errorf("Failed to set new container state: %s", err)
 	}
+	err = unithistory.Record(unithistory.Transition{
+		App:     c.AppName,
+		Unit:    c.ID,
+		Process: c.ProcessName,
+		From:    c.Status,
+		Status:  unithistory.StatusRemoved,
+	})
+	if err != nil {
+		log.Errorf("unable to record removal of unit %s: %s", c.ID, err)
+	}
 	return nil
 }
 
@@ -356,8 +435,9 @@ func (e *execErr) Error() string {
 }
 
 // Commits commits the container, creating an image in Docker. It then returns
-// the image identifier for usage in future container creation.
-func (c *Container) Commit(client provision.BuilderDockerClient, limiter provision.ActionLimiter, writer io.Writer, isDeploy bool) (string, error) {
+// the image identifier for usage in future container creation. The image is
+// pushed with the credentials of the registry of pool, when stored there.
+func (c *Container) Commit(client provision.BuilderDockerClient, limiter provision.ActionLimiter, pool string, writer io.Writer, isDeploy bool) (string, error) {
 	log.Debugf("committing container %s", c.ID)
 	repository, tag := image.SplitImageName(c.BuildingImage)
 	opts := docker.CommitContainerOptions{Container: c.ID, Repository: repository, Tag: tag}
@@ -396,7 +476,7 @@ func (c *Container) Commit(client provision.BuilderDockerClient, limiter provisi
 			maxTry = 3
 		}
 		for i := 0; i < maxTry; i++ {
-			err = dockercommon.PushImage(client, repository, tag, dockercommon.RegistryAuthConfig(repository))
+			err = dockercommon.PushImage(client, repository, tag, dockercommon.RegistryAuthConfig(pool, repository))
 			if err != nil {
 				fmt.Fprintf(writer, "Could not send image, trying again. Original error: %s\n", err.Error())
 				log.Errorf("error in push image %s: %s", c.BuildingImage, err)
@@ -452,7 +532,13 @@ func (c *Container) hostConfig(app provision.App, isDeploy bool) (*docker.HostCo
 	sharedIsolation, _ := config.GetBool("docker:sharedfs:app-isolation")
 	sharedSalt, _ := config.GetString("docker:sharedfs:salt")
 	hostConfig := docker.HostConfig{
-		CPUShares: int64(app.GetCpuShare()),
+		CPUShares:   int64(app.GetCpuShare()),
+		CPUQuota:    app.GetCPUQuota(),
+		CPUPeriod:   app.GetCPUPeriod(),
+		BlkioWeight: app.GetBlkioWeight(),
+	}
+	if pidsLimit := app.GetPidsLimit(); pidsLimit > 0 {
+		hostConfig.PidsLimit = &pidsLimit
 	}
 
 	if !isDeploy {
@@ -462,6 +548,12 @@ func (c *Container) hostConfig(app provision.App, isDeploy bool) (*docker.HostCo
 		hostConfig.PortBindings = map[docker.Port][]docker.PortBinding{
 			docker.Port(c.ExposedPort): {{HostIP: "", HostPort: ""}},
 		}
+		for _, p := range c.PublishedPorts {
+			hostConfig.PortBindings[p.DockerPort()] = []docker.PortBinding{{HostIP: "", HostPort: strconv.Itoa(p.HostPort)}}
+		}
+		for _, p := range c.NamedPorts {
+			hostConfig.PortBindings[p.DockerPort()] = []docker.PortBinding{{HostIP: "", HostPort: ""}}
+		}
 		pool := app.GetPool()
 		driver, opts, logErr := LogOpts(pool)
 		if logErr != nil {
@@ -495,6 +587,12 @@ func (c *Container) hostConfig(app provision.App, isDeploy bool) (*docker.HostCo
 		}
 	}
 
+	if c.StickyVolume != "" && !isDeploy {
+		if mountPoint := StickyStorageMountPoint(app); mountPoint != "" {
+			hostConfig.Binds = append(hostConfig.Binds, fmt.Sprintf("%s:%s:rw", c.StickyVolume, mountPoint))
+		}
+	}
+
 	pidsLimit, _ := config.GetInt("docker:pids-limit")
 	if pidsLimit > 0 {
 		limit := int64(pidsLimit)
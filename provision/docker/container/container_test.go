@@ -332,6 +332,47 @@ func (s *S) TestContainerCreateForDeploy(c *check.C) {
 	c.Assert(dockerContainer.HostConfig.OomScoreAdj, check.Equals, 1000)
 }
 
+func (s *S) TestContainerCreatePlanLimits(c *check.C) {
+	s.server.CustomHandler("/images/.*/json", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := docker.Image{
+			Config: &docker.Config{
+				ExposedPorts: map[docker.Port]struct{}{},
+			},
+		}
+		j, _ := json.Marshal(response)
+		w.Write(j)
+	}))
+	app := provisiontest.NewFakeApp("app-name", "brainfuck", 1)
+	app.CPUQuota = 50000
+	app.CPUPeriod = 100000
+	app.BlkioWeight = 300
+	app.PidsLimit = 256
+	img := "tsuru/brainfuck:latest"
+	s.cli.PullImage(docker.PullImageOptions{Repository: img}, docker.AuthConfiguration{})
+	cont := Container{Container: types.Container{
+		Name:    "myName",
+		AppName: app.GetName(),
+		Type:    app.GetPlatform(),
+		Status:  "created",
+	}}
+	err := cont.Create(&CreateArgs{
+		App:      app,
+		ImageID:  img,
+		Commands: []string{"docker", "run"},
+		Client:   s.cli,
+	})
+	c.Assert(err, check.IsNil)
+	defer s.removeTestContainer(&cont)
+	client, err := docker.NewClient(s.server.URL())
+	c.Assert(err, check.IsNil)
+	dockerContainer, err := client.InspectContainerWithOptions(docker.InspectContainerOptions{ID: cont.ID})
+	c.Assert(err, check.IsNil)
+	c.Assert(dockerContainer.HostConfig.CPUQuota, check.Equals, int64(50000))
+	c.Assert(dockerContainer.HostConfig.CPUPeriod, check.Equals, int64(100000))
+	c.Assert(dockerContainer.HostConfig.BlkioWeight, check.Equals, int64(300))
+	c.Assert(*dockerContainer.HostConfig.PidsLimit, check.Equals, int64(256))
+}
+
 func (s *S) TestContainerCreateDoesNotSetEnvs(c *check.C) {
 	s.server.CustomHandler("/images/.*/json", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		response := docker.Image{
@@ -716,7 +757,7 @@ func (s *S) TestContainerCommit(c *check.C) {
 	defer s.removeTestContainer(cont)
 	cont.BuildingImage = "tsuru/app-myapp:v1"
 	var buf bytes.Buffer
-	imageID, err := cont.Commit(s.cli, s.limiter, &buf, false)
+	imageID, err := cont.Commit(s.cli, s.limiter, "", &buf, false)
 	c.Assert(err, check.IsNil)
 	repoNamespace, _ := config.GetString("docker:repository-namespace")
 	repository := repoNamespace + "/app-" + cont.AppName + ":v1"
@@ -741,7 +782,7 @@ func (s *S) TestContainerCommitWithRegistryBuild(c *check.C) {
 	defer s.removeTestContainer(cont)
 	cont.BuildingImage = "localhost:3030/tsuru/app-myapp:v1"
 	var buf bytes.Buffer
-	imageID, err := cont.Commit(s.cli, s.limiter, &buf, false)
+	imageID, err := cont.Commit(s.cli, s.limiter, "", &buf, false)
 	c.Assert(err, check.IsNil)
 	repoNamespace, _ := config.GetString("docker:repository-namespace")
 	repository := "localhost:3030/" + repoNamespace + "/app-" + cont.AppName + ":v1"
@@ -767,7 +808,7 @@ func (s *S) TestContainerCommitWithRegistryDeploy(c *check.C) {
 	defer s.removeTestContainer(cont)
 	cont.BuildingImage = "localhost:3030/tsuru/app-myapp:v1"
 	var buf bytes.Buffer
-	imageID, err := cont.Commit(s.cli, s.limiter, &buf, true)
+	imageID, err := cont.Commit(s.cli, s.limiter, "", &buf, true)
 	c.Assert(err, check.IsNil)
 	repoNamespace, _ := config.GetString("docker:repository-namespace")
 	repository := "localhost:3030/" + repoNamespace + "/app-" + cont.AppName + ":v1"
@@ -796,7 +837,7 @@ func (s *S) TestContainerCommitErrorInPush(c *check.C) {
 	defer s.removeTestContainer(cont)
 	cont.BuildingImage = cont.Image
 	var buf bytes.Buffer
-	_, err = cont.Commit(s.cli, s.limiter, &buf, false)
+	_, err = cont.Commit(s.cli, s.limiter, "", &buf, false)
 	c.Assert(err, check.ErrorMatches, ".*third failure$")
 }
 
@@ -824,7 +865,7 @@ func (s *S) TestContainerCommitRetryPush(c *check.C) {
 	defer s.removeTestContainer(cont)
 	cont.BuildingImage = cont.Image
 	var buf bytes.Buffer
-	_, err = cont.Commit(s.cli, s.limiter, &buf, false)
+	_, err = cont.Commit(s.cli, s.limiter, "", &buf, false)
 	c.Assert(err, check.IsNil)
 	expectedPush := "tsuru/python:latest"
 	c.Assert(pushes, check.DeepEquals, []string{expectedPush, expectedPush, expectedPush})
@@ -0,0 +1,78 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package container
+
+import (
+	"path"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+)
+
+// AnnotationStickyStorage is the app annotation that enables sticky storage,
+// its value is the path where each unit mounts its own named volume.
+const AnnotationStickyStorage = "app.tsuru.io/docker-sticky-storage"
+
+// StickyStorageMountPoint returns the path where the units of the app mount
+// their sticky volume or an empty string if sticky storage is not enabled
+// for the app.
+func StickyStorageMountPoint(app provision.App) string {
+	mountPoint, _ := app.GetMetadata().Annotation(AnnotationStickyStorage)
+	mountPoint = path.Clean(strings.TrimSpace(mountPoint))
+	if !path.IsAbs(mountPoint) || mountPoint == "/" {
+		return ""
+	}
+	return mountPoint
+}
+
+// MigrateStickyVolume copies the content of the sticky volume mounted in from
+// to the sticky volume of to. The destination container must be already
+// created, so that its volume exists, and should not be started yet. The
+// source container is stopped before the copy, so that its files don't
+// change while they're copied, and started again if the copy fails.
+func MigrateStickyVolume(client provision.BuilderDockerClient, from, to *Container, mountPoint string) error {
+	err := client.StopContainer(from.ID, 10)
+	if err != nil {
+		if _, notRunning := err.(*docker.ContainerNotRunning); !notRunning {
+			return errors.Wrapf(err, "unable to stop unit %s in node %s to copy its sticky volume %s, the node may be unreachable", from.ShortID(), from.HostAddr, from.StickyVolume)
+		}
+	}
+	err = copyStickyVolume(client, from, to, mountPoint)
+	if err != nil {
+		RestartStickySource(client, from)
+		return errors.Wrapf(err, "unable to copy sticky volume %s from unit %s in node %s", from.StickyVolume, from.ShortID(), from.HostAddr)
+	}
+	return nil
+}
+
+func copyStickyVolume(client provision.BuilderDockerClient, from, to *Container, mountPoint string) error {
+	archive, err := dockercommon.DownloadFromContainer(client, from.ID, mountPoint)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	return client.UploadToContainer(to.ID, docker.UploadToContainerOptions{
+		InputStream: archive,
+		Path:        path.Dir(mountPoint),
+	})
+}
+
+// RestartStickySource starts again the source of a sticky volume migration
+// that didn't complete, unless it was expected to be stopped.
+func RestartStickySource(client provision.BuilderDockerClient, from *Container) {
+	if from.ExpectedStatus() == provision.StatusStopped {
+		return
+	}
+	err := client.StartContainer(from.ID, nil)
+	if err != nil {
+		if _, running := err.(*docker.ContainerAlreadyRunning); !running {
+			log.Errorf("unable to start unit %s again after failing to migrate its sticky volume: %s", from.ID, err)
+		}
+	}
+}
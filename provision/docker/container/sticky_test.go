@@ -0,0 +1,59 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package container
+
+import (
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestStickyStorageMountPoint(c *check.C) {
+	app := provisiontest.NewFakeApp("myapp", "python", 1)
+	c.Assert(StickyStorageMountPoint(app), check.Equals, "")
+	tests := map[string]string{
+		"/data":     "/data",
+		" /data/ ":  "/data",
+		"/var/lib/": "/var/lib",
+		"data":      "",
+		"/":         "",
+		"":          "",
+	}
+	for value, expected := range tests {
+		app.Metadata = appTypes.Metadata{Annotations: []appTypes.MetadataItem{{Name: AnnotationStickyStorage, Value: value}}}
+		c.Check(StickyStorageMountPoint(app), check.Equals, expected, check.Commentf("value %q", value))
+	}
+}
+
+func (s *S) TestContainerCreateStickyVolume(c *check.C) {
+	app := provisiontest.NewFakeApp("myapp", "python", 1)
+	app.Metadata = appTypes.Metadata{Annotations: []appTypes.MetadataItem{{Name: AnnotationStickyStorage, Value: "/data"}}}
+	img := "tsuru/python:latest"
+	s.cli.PullImage(docker.PullImageOptions{Repository: img}, docker.AuthConfiguration{})
+	cont := Container{Container: types.Container{
+		Name:         "myName",
+		AppName:      app.GetName(),
+		Type:         app.GetPlatform(),
+		Status:       "created",
+		ProcessName:  "web",
+		ExposedPort:  "8888/tcp",
+		StickyVolume: "myapp-web-abc",
+	}}
+	err := cont.Create(&CreateArgs{
+		App:      app,
+		ImageID:  img,
+		Commands: []string{"docker", "run"},
+		Client:   s.cli,
+	})
+	c.Assert(err, check.IsNil)
+	defer s.removeTestContainer(&cont)
+	client, err := docker.NewClient(s.server.URL())
+	c.Assert(err, check.IsNil)
+	dockerContainer, err := client.InspectContainerWithOptions(docker.InspectContainerOptions{ID: cont.ID})
+	c.Assert(err, check.IsNil)
+	c.Assert(dockerContainer.HostConfig.Binds, check.DeepEquals, []string{"myapp-web-abc:/data:rw"})
+}
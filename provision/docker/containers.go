@@ -141,6 +141,7 @@ func (p *dockerProvisioner) runReplaceUnitsPipeline(ctx context.Context, w io.Wr
 	if err != nil {
 		return nil, err
 	}
+	p.updateDiscovery(a.GetName())
 	return pipeline.Result().([]container.Container), nil
 }
 
@@ -168,6 +169,7 @@ func (p *dockerProvisioner) runCreateUnitsPipeline(ctx context.Context, w io.Wri
 	if err != nil {
 		return nil, err
 	}
+	p.updateDiscovery(a.GetName())
 	return pipeline.Result().([]container.Container), nil
 }
 
@@ -248,6 +250,29 @@ func (p *dockerProvisioner) moveContainer(ctx context.Context, contId string, to
 	return createdContainer, p.HandleMoveErrors(moveErrors, writer)
 }
 
+// RestartUnit replaces the container of the unit with a new one, created in
+// the same node unless the node is unable to run it.
+func (p *dockerProvisioner) RestartUnit(ctx context.Context, a provision.App, unitID string, w io.Writer) error {
+	cont, err := p.GetContainer(unitID)
+	if err != nil {
+		return err
+	}
+	if cont.AppName != a.GetName() {
+		return &provision.UnitNotFoundError{ID: unitID}
+	}
+	_, err = p.moveContainer(ctx, cont.ID, cont.HostAddr, w)
+	if err == nil {
+		return nil
+	}
+	if _, getErr := p.GetContainer(cont.ID); getErr != nil {
+		// the unit is gone, there is nothing left to recreate elsewhere.
+		return err
+	}
+	fmt.Fprintf(w, "Unable to restart unit %s in %s, trying other nodes: %s\n", cont.ID, cont.HostAddr, err)
+	_, err = p.moveContainer(ctx, cont.ID, "", w)
+	return err
+}
+
 func (p *dockerProvisioner) moveContainerList(ctx context.Context, containers []container.Container, toHost string, writer io.Writer) error {
 	locker := &appLocker{}
 	moveErrors := make(chan error, len(containers))
@@ -270,10 +295,25 @@ func (p *dockerProvisioner) MoveContainers(ctx context.Context, fromHost, toHost
 	}
 	if len(containers) == 0 {
 		fmt.Fprintf(writer, "No units to move in %s\n", fromHost)
+		// units left by an interrupted move may have been moved elsewhere.
+		if plan, planErr := GetMovePlan(fromHost); planErr == nil && !plan.Finished() {
+			return plan.finish(ctx)
+		}
 		return nil
 	}
+	plan, err := startMovePlan(fromHost, toHost, containers)
+	if err != nil {
+		return err
+	}
+	if plan.Resumed > 0 {
+		fmt.Fprintf(writer, "Resuming interrupted move, %d of %d units already moved\n", len(plan.Moved), plan.Total)
+	}
 	fmt.Fprintf(writer, "Moving %d units...\n", len(containers))
-	return p.moveContainerList(ctx, containers, toHost, writer)
+	err = p.moveContainersWithPlan(ctx, containers, toHost, writer, plan)
+	if finishErr := plan.finish(ctx); finishErr != nil {
+		log.Errorf("[move plan] unable to finish move plan from %s: %s", fromHost, finishErr)
+	}
+	return err
 }
 
 func (p *dockerProvisioner) rebalanceContainersByFilter(ctx context.Context, writer io.Writer, appFilter []string, metadataFilter map[string]string, dryRun bool) (*dockerProvisioner, error) {
@@ -380,7 +420,7 @@ func (p *dockerProvisioner) runCommandInContainer(ctx context.Context, version a
 	addr, cont, err := cluster.CreateContainerPullOptsSchedulerOpts(
 		createOptions,
 		pullOpts,
-		dockercommon.RegistryAuthConfig(createOptions.Config.Image),
+		dockercommon.RegistryAuthConfig(app.GetPool(), createOptions.Config.Image),
 		schedOpts,
 	)
 	hostAddr := net.URLToHost(addr)
@@ -459,6 +499,47 @@ func (p *dockerProvisioner) runningContainersByNode(nodes []*cluster.Node) (map[
 	return result, nil
 }
 
+// containerSkewInDomains returns the highest difference, among all app
+// processes, between the number of units in the failure domains with the
+// most and the fewest units of the process. Domains are identified by the
+// value of spreadKey in the nodes metadata.
+func (p *dockerProvisioner) containerSkewInDomains(nodes []*cluster.Node, spreadKey string) (int, error) {
+	containersMap, err := p.runningContainersByNode(nodes)
+	if err != nil {
+		return 0, err
+	}
+	domains := map[string]struct{}{}
+	processCounts := map[string]map[string]int{}
+	for _, n := range nodes {
+		domain := n.Metadata[spreadKey]
+		domains[domain] = struct{}{}
+		for _, c := range containersMap[n.Address] {
+			process := c.AppName + "/" + c.ProcessName
+			if processCounts[process] == nil {
+				processCounts[process] = map[string]int{}
+			}
+			processCounts[process][domain]++
+		}
+	}
+	var skew int
+	for _, counts := range processCounts {
+		minCount, maxCount := -1, 0
+		for domain := range domains {
+			count := counts[domain]
+			if count > maxCount {
+				maxCount = count
+			}
+			if minCount == -1 || count < minCount {
+				minCount = count
+			}
+		}
+		if maxCount-minCount > skew {
+			skew = maxCount - minCount
+		}
+	}
+	return skew, nil
+}
+
 func (p *dockerProvisioner) containerGapInNodes(nodes []*cluster.Node) (int, int, error) {
 	maxCount := 0
 	minCount := -1
@@ -212,6 +212,62 @@ func (s *S) TestMoveContainerStopped(c *check.C) {
 	c.Assert(containers[0].Status, check.Equals, provision.StatusStopped.String())
 }
 
+func (s *S) TestRestartUnitKeepsHost(c *check.C) {
+	ctx := context.TODO()
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	defer p.Destroy(ctx, appInstance)
+	p.Provision(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(p, appInstance, nil)
+	c.Assert(err, check.IsNil)
+	addedConts, err := addContainersWithHost(context.TODO(), &changeUnitsPipelineArgs{
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 2}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	appStruct := s.newAppFromFake(appInstance)
+	err = s.conn.Apps().Insert(appStruct)
+	c.Assert(err, check.IsNil)
+	buf := safe.NewBuffer(nil)
+	err = p.RestartUnit(context.TODO(), appInstance, addedConts[0].ID, buf)
+	c.Assert(err, check.IsNil)
+	containers, err := p.listContainersByHost("localhost")
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 2)
+	ids := []string{containers[0].ID, containers[1].ID}
+	c.Assert(ids, check.Not(check.DeepEquals), []string{addedConts[0].ID, addedConts[1].ID})
+	_, err = p.GetContainer(addedConts[0].ID)
+	c.Assert(err, check.FitsTypeOf, &provision.UnitNotFoundError{})
+	_, err = p.GetContainer(addedConts[1].ID)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestRestartUnitOtherApp(c *check.C) {
+	ctx := context.TODO()
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	defer p.Destroy(ctx, appInstance)
+	p.Provision(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(p, appInstance, nil)
+	c.Assert(err, check.IsNil)
+	addedConts, err := addContainersWithHost(context.TODO(), &changeUnitsPipelineArgs{
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 1}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	otherApp := provisiontest.NewFakeApp("otherapp", "python", 0)
+	err = p.RestartUnit(context.TODO(), otherApp, addedConts[0].ID, safe.NewBuffer(nil))
+	c.Assert(err, check.FitsTypeOf, &provision.UnitNotFoundError{})
+}
+
 func (s *S) TestMoveContainerErrorStopped(c *check.C) {
 	p, err := s.startMultipleServersCluster()
 	c.Assert(err, check.IsNil)
@@ -0,0 +1,141 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	"github.com/tsuru/tsuru/servicemanager"
+)
+
+type plannedContainer struct {
+	process  string
+	replaces string
+}
+
+// PlanDeploy computes where the units of a deploy of image would be
+// created, using a provisioner in dry mode so that nothing is changed in the
+// nodes, units or routers.
+func (p *dockerProvisioner) PlanDeploy(ctx context.Context, a provision.App, image string) (*provision.DeployPlan, error) {
+	containers, err := p.listContainersByApp(a.GetName())
+	if err != nil {
+		return nil, err
+	}
+	toPlan, err := containersToPlan(ctx, a, containers)
+	if err != nil {
+		return nil, err
+	}
+	dryProv, err := p.dryMode(containers)
+	if err != nil {
+		return nil, err
+	}
+	defer dryProv.stopDryMode()
+	nodes, err := dryProv.Nodes(a)
+	if err != nil {
+		return nil, err
+	}
+	var dbApp *app.App
+	if appObj, ok := a.(*app.App); ok {
+		dbApp = appObj
+	} else {
+		dbApp, err = app.GetByName(ctx, a.GetName())
+		if err != nil {
+			return nil, err
+		}
+	}
+	nodes, err = dryProv.scheduler.filterBySchedulingHints(a, nodes)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := schedulerStrategyForPool(a.GetPool())
+	if err != nil {
+		return nil, err
+	}
+	plan := &provision.DeployPlan{App: a.GetName(), Image: image}
+	pulled := map[string]struct{}{}
+	coll := dryProv.Collection()
+	defer coll.Close()
+	for _, planned := range toPlan {
+		name := generateContainerName(a.GetName())
+		err = coll.Insert(container.Container{Container: types.Container{
+			ID:          name,
+			Name:        name,
+			AppName:     a.GetName(),
+			ProcessName: planned.process,
+			Image:       image,
+		}})
+		if err != nil {
+			return nil, err
+		}
+		// Memory usage is filtered for each unit, as in the scheduler, so
+		// that the units already planned are taken into account.
+		candidates, err := dryProv.scheduler.filterByMemoryUsage(dbApp, nodes, dryProv.scheduler.maxMemoryRatioForPool(a.GetPool()), dryProv.scheduler.TotalMemoryMetadata)
+		if err != nil {
+			return nil, err
+		}
+		unit := SchedulerUnit{
+			App:      a.GetName(),
+			Process:  planned.process,
+			Image:    image,
+			Memory:   a.GetMemory(),
+			CPUMilli: a.GetMilliCPU(),
+		}
+		node, err := dryProv.scheduler.chooseNodeToAddWithStrategy(strategy, candidates, name, unit)
+		if err != nil {
+			return nil, err
+		}
+		host := net.URLToHost(node)
+		plan.Units = append(plan.Units, provision.PlannedUnit{Process: planned.process, Node: host, Replaces: planned.replaces})
+		plan.RoutesAdded = append(plan.RoutesAdded, host)
+		if _, ok := pulled[host]; !ok {
+			pulled[host] = struct{}{}
+			plan.Pulls = append(plan.Pulls, provision.PlannedPull{Node: host, Image: image})
+		}
+	}
+	for _, c := range containers {
+		if c.HostAddr != "" && c.HostPort != "" {
+			plan.RoutesRemoved = append(plan.RoutesRemoved, c.Address().String())
+		}
+	}
+	return plan, nil
+}
+
+// containersToPlan returns the units a deploy would create: one replacing
+// each existing container or, for apps without units, one for each process
+// of the latest version.
+func containersToPlan(ctx context.Context, a provision.App, containers []container.Container) ([]plannedContainer, error) {
+	var planned []plannedContainer
+	for _, c := range containers {
+		planned = append(planned, plannedContainer{process: c.ProcessName, replaces: c.ID})
+	}
+	if len(planned) > 0 {
+		return planned, nil
+	}
+	var processes []string
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(ctx, a)
+	if err == nil {
+		processMap, err := version.Processes()
+		if err != nil {
+			return nil, err
+		}
+		for name := range processMap {
+			processes = append(processes, name)
+		}
+	}
+	if len(processes) == 0 {
+		processes = []string{provision.WebProcessName}
+	}
+	sort.Strings(processes)
+	for _, name := range processes {
+		planned = append(planned, plannedContainer{process: name})
+	}
+	return planned, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPlanDeploy(c *check.C) {
+	a := &app.App{Name: "myapp", Platform: "python", Pool: "test-default"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	coll := s.p.Collection()
+	defer coll.Close()
+	err = coll.Insert(
+		container.Container{Container: types.Container{ID: "c1", AppName: "myapp", ProcessName: "web", HostAddr: "127.0.0.1", HostPort: "30001"}},
+		container.Container{Container: types.Container{ID: "c2", AppName: "myapp", ProcessName: "worker", HostAddr: "127.0.0.1", HostPort: "30002"}},
+	)
+	c.Assert(err, check.IsNil)
+	plan, err := s.p.PlanDeploy(context.TODO(), a, "tsuru/app-myapp:v2")
+	c.Assert(err, check.IsNil)
+	c.Assert(plan, check.DeepEquals, &provision.DeployPlan{
+		App:   "myapp",
+		Image: "tsuru/app-myapp:v2",
+		Units: []provision.PlannedUnit{
+			{Process: "web", Node: "127.0.0.1", Replaces: "c1"},
+			{Process: "worker", Node: "127.0.0.1", Replaces: "c2"},
+		},
+		Pulls:         []provision.PlannedPull{{Node: "127.0.0.1", Image: "tsuru/app-myapp:v2"}},
+		RoutesAdded:   []string{"127.0.0.1", "127.0.0.1"},
+		RoutesRemoved: []string{"http://127.0.0.1:30001", "http://127.0.0.1:30002"},
+	})
+	conts, err := s.p.listContainersByApp("myapp")
+	c.Assert(err, check.IsNil)
+	c.Assert(conts, check.HasLen, 2)
+}
+
+func (s *S) TestPlanDeployWithoutUnits(c *check.C) {
+	a := &app.App{Name: "myapp", Platform: "python", Pool: "test-default"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	plan, err := s.p.PlanDeploy(context.TODO(), a, "tsuru/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Units, check.DeepEquals, []provision.PlannedUnit{{Process: "web", Node: "127.0.0.1"}})
+	c.Assert(plan.RoutesRemoved, check.IsNil)
+}
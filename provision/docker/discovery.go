@@ -0,0 +1,42 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"net"
+
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/discovery"
+)
+
+// updateDiscovery registers the addresses of the running units of the app in
+// the internal discovery registry. It's called after every change in the app
+// units, failures are only logged as the registry is fixed on the next one.
+func (p *dockerProvisioner) updateDiscovery(appName string) {
+	if p.isDryMode {
+		return
+	}
+	containers, err := p.listContainersByApp(appName)
+	if err != nil {
+		log.Errorf("[discovery] unable to list units of app %q: %s", appName, err)
+		return
+	}
+	addrs := map[string][]string{}
+	for _, c := range containers {
+		if !c.ValidAddr() {
+			continue
+		}
+		status := provision.Status(c.Status)
+		if status != provision.StatusStarted && status != provision.StatusStarting {
+			continue
+		}
+		addrs[c.ProcessName] = append(addrs[c.ProcessName], net.JoinHostPort(c.HostAddr, c.HostPort))
+	}
+	err = discovery.Update(appName, addrs)
+	if err != nil {
+		log.Errorf("[discovery] unable to update entries of app %q: %s", appName, err)
+	}
+}
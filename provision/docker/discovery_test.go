@@ -0,0 +1,57 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"net"
+
+	"github.com/tsuru/tsuru/provision/discovery"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAddUnitsUpdatesDiscovery(c *check.C) {
+	ctx := context.TODO()
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	s.p.Provision(ctx, appInstance)
+	defer s.p.Destroy(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(s.p, appInstance, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web":    "python myapp.py",
+			"worker": "python worker.py",
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = s.p.AddUnits(ctx, appInstance, 2, "web", version, nil)
+	c.Assert(err, check.IsNil)
+	err = s.p.AddUnits(ctx, appInstance, 1, "worker", version, nil)
+	c.Assert(err, check.IsNil)
+	containers, err := s.p.listContainersByApp(appInstance.GetName())
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 3)
+	entries, err := discovery.List(appInstance.GetName())
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 2)
+	c.Assert(entries[0].Name, check.Equals, "myapp.web.tsuru.internal")
+	c.Assert(entries[0].Addresses, check.HasLen, 2)
+	c.Assert(entries[1].Name, check.Equals, "myapp.worker.tsuru.internal")
+	for _, cont := range containers {
+		if cont.ProcessName == "worker" {
+			c.Assert(entries[1].Addresses, check.DeepEquals, []string{net.JoinHostPort(cont.HostAddr, cont.HostPort)})
+		}
+	}
+	err = s.p.RemoveUnits(ctx, appInstance, 1, "worker", version, nil)
+	c.Assert(err, check.IsNil)
+	entries, err = discovery.List(appInstance.GetName())
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(entries[0].Process, check.Equals, "web")
+	err = s.p.Stop(ctx, appInstance, "", version, nil)
+	c.Assert(err, check.IsNil)
+	entries, err = discovery.List(appInstance.GetName())
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 0)
+}
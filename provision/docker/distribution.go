@@ -0,0 +1,103 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/image"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// distributeImage pulls the image of the version on every node of the app
+// pool before its units are replaced, so new units don't wait for the image
+// to be pulled on their first start. Pulls run in parallel, bounded by the
+// action limiter of each node. Failures are only reported, as the image is
+// still pulled when the units are created. In pools using P2P distribution
+// the image is seeded by a single node before being pulled by the others.
+func (p *dockerProvisioner) distributeImage(ctx context.Context, a provision.App, version appTypes.AppVersion, evt *event.Event) {
+	if disabled, _ := config.GetBool("docker:image-distribution:disable"); disabled {
+		return
+	}
+	if registry, _ := config.GetString("docker:registry"); registry == "" {
+		return
+	}
+	imageName := version.VersionInfo().DeployImage
+	nodes, err := p.Cluster().NodesForMetadata(map[string]string{provision.PoolMetadataName: a.GetPool()})
+	if err != nil {
+		log.Errorf("[image-distribution] unable to list nodes for pool %q: %v", a.GetPool(), err)
+		return
+	}
+	if len(nodes) == 0 {
+		return
+	}
+	var w io.Writer = ioutil.Discard
+	if evt != nil {
+		w = evt
+	}
+	var writeMu sync.Mutex
+	report := func(format string, args ...interface{}) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		fmt.Fprintf(w, format, args...)
+	}
+	report("\n---- Distributing image %q to %d nodes ----\n", imageName, len(nodes))
+	done := evt.StartStage(provision.DeployStagePull)
+	defer done()
+	repo, tag := image.SplitImageName(imageName)
+	total := len(nodes)
+	var pulled int32
+	pull := func(nodeAddr string) {
+		host := net.URLToHost(nodeAddr)
+		limiterDone := p.ActionLimiter().Start(host)
+		defer limiterDone()
+		if ctx.Err() != nil {
+			return
+		}
+		dockercommon.PrePullImageFromMirror(p.Cluster(), nodeAddr, imageName)
+		pullOpts := docker.PullImageOptions{
+			Repository:        repo,
+			Tag:               tag,
+			InactivityTimeout: net.StreamInactivityTimeout,
+			Context:           ctx,
+		}
+		err := p.Cluster().PullImage(pullOpts, dockercommon.RegistryAuthConfig(a.GetPool(), imageName), nodeAddr)
+		if err != nil {
+			report(" ---> Unable to pull image on node %s, it will be pulled when units are created: %v\n", host, err)
+			return
+		}
+		count := atomic.AddInt32(&pulled, 1)
+		report(" ---> Image pulled on node %s [%d/%d]\n", host, count, total)
+	}
+	if dockercommon.P2PEnabled(a.GetPool()) && len(nodes) > 1 {
+		// The first node seeds the image in the P2P network, so the
+		// remaining nodes fetch its layers from their peers instead of
+		// the registry.
+		report(" ---> Seeding image through node %s\n", net.URLToHost(nodes[0].Address))
+		pull(nodes[0].Address)
+		nodes = nodes[1:]
+	}
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(nodeAddr string) {
+			defer wg.Done()
+			pull(nodeAddr)
+		}(node.Address)
+	}
+	wg.Wait()
+}
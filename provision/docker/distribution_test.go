@@ -0,0 +1,49 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestDistributeImage(c *check.C) {
+	config.Set("docker:registry", "localhost:3030")
+	defer config.Unset("docker:registry")
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	a := provisiontest.NewFakeApp("myapp", "python", 0)
+	version, err := newSuccessfulVersionForApp(p, a, nil)
+	c.Assert(err, check.IsNil)
+	imageName := version.VersionInfo().DeployImage
+	evt, err := event.New(&event.Opts{
+		Target:  event.Target{Type: "app", Value: a.GetName()},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermApp),
+	})
+	c.Assert(err, check.IsNil)
+	p.distributeImage(context.TODO(), a, version, evt)
+	img, err := p.storage.RetrieveImage(imageName)
+	c.Assert(err, check.IsNil)
+	c.Assert(img.History, check.HasLen, 2)
+	c.Assert(evt.Log(), check.Matches, `(?s).*Distributing image ".*" to 2 nodes.*Image pulled on node .* \[1/2\].*Image pulled on node .* \[2/2\].*`)
+}
+
+func (s *S) TestDistributeImageWithoutRegistry(c *check.C) {
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	a := provisiontest.NewFakeApp("myapp", "python", 0)
+	version, err := newSuccessfulVersionForApp(p, a, nil)
+	c.Assert(err, check.IsNil)
+	p.distributeImage(context.TODO(), a, version, nil)
+	_, err = p.storage.RetrieveImage(version.VersionInfo().DeployImage)
+	c.Assert(err, check.NotNil)
+}
@@ -85,8 +85,24 @@ func (p *dockerProvisioner) deployPipeline(ctx context.Context, app provision.Ap
 	return version.VersionInfo().DeployImage, nil
 }
 
-func (p *dockerProvisioner) start(ctx context.Context, oldContainer *container.Container, app provision.App, cmdData dockercommon.ContainerCmdsData, version appTypes.AppVersion, w io.Writer, destinationHosts ...string) (*container.Container, error) {
-	commands, processName, err := dockercommon.LeanContainerCmds(oldContainer.ProcessName, cmdData, app)
+func (p *dockerProvisioner) start(ctx context.Context, oldContainer *container.Container, app provision.App, cmdData dockercommon.ContainerCmdsData, version appTypes.AppVersion, w io.Writer, evt *event.Event, destinationHosts ...string) (*container.Container, error) {
+	yamlData, err := version.TsuruYamlData()
+	if err != nil {
+		return nil, err
+	}
+	err = yamlData.ValidateInit()
+	if err != nil {
+		return nil, err
+	}
+	err = yamlData.ValidateRoutableProcesses()
+	if err != nil {
+		return nil, err
+	}
+	err = yamlData.ValidateStartup()
+	if err != nil {
+		return nil, err
+	}
+	commands, processName, err := dockercommon.LeanContainerCmdsWithExtra(oldContainer.ProcessName, cmdData, app, dockercommon.InitStepCmds(cmdData))
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +112,7 @@ func (p *dockerProvisioner) start(ctx context.Context, oldContainer *container.C
 			&insertEmptyContainerInDB,
 			&createContainer,
 			&setContainerID,
+			&migrateStickyVolume,
 			&stopContainer,
 			&updateContainerInDB,
 			&setNetworkInfo,
@@ -105,6 +122,8 @@ func (p *dockerProvisioner) start(ctx context.Context, oldContainer *container.C
 			&insertEmptyContainerInDB,
 			&createContainer,
 			&setContainerID,
+			&migrateStickyVolume,
+			&runInitSteps,
 			&startContainer,
 			&updateContainerInDB,
 			&setNetworkInfo,
@@ -116,6 +135,19 @@ func (p *dockerProvisioner) start(ctx context.Context, oldContainer *container.C
 	if len(exposedPorts) > 0 {
 		exposedPort = exposedPorts[0]
 	}
+	publishedPorts, err := allocateExposedPorts(app.GetName(), processName, yamlData.ExposedPortsForProcess(processName))
+	if err != nil {
+		return nil, err
+	}
+	namedPorts, err := namedPortsForProcess(yamlData, processName)
+	if err != nil {
+		return nil, err
+	}
+	if len(namedPorts) > 0 {
+		exposedPort = string(namedPorts[0].DockerPort())
+	} else if rp := yamlData.RoutableProcess(processName); rp != nil && rp.Port > 0 {
+		exposedPort = fmt.Sprintf("%d/tcp", rp.Port)
+	}
 	deployImageID := version.VersionInfo().DeployImage
 	args := runContainerActionsArgs{
 		app:              app,
@@ -125,7 +157,24 @@ func (p *dockerProvisioner) start(ctx context.Context, oldContainer *container.C
 		destinationHosts: destinationHosts,
 		provisioner:      p,
 		exposedPort:      exposedPort,
+		publishedPorts:   publishedPorts,
+		namedPorts:       namedPorts,
 		version:          version,
+		stages:           evt,
+	}
+	for _, step := range yamlData.Init {
+		if step.Image != "" {
+			args.initSteps = append(args.initSteps, step)
+		}
+	}
+	if oldContainer.StickyVolume != "" {
+		args.stickyVolume = oldContainer.StickyVolume
+		if !p.isDryMode {
+			args.stickySource, err = p.containerWithStickyVolume(app.GetName(), oldContainer.StickyVolume)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 	err = container.RunPipelineWithRetry(ctx, pipeline, args)
 	if err != nil {
@@ -140,20 +189,24 @@ func (p *dockerProvisioner) start(ctx context.Context, oldContainer *container.C
 }
 
 func (p *dockerProvisioner) ClusterClient() provision.BuilderDockerClient {
+	return p.poolClusterClient("")
+}
+
+// poolClusterClient returns a cluster client pulling images with the
+// credentials of the registry of the pool.
+func (p *dockerProvisioner) poolClusterClient(pool string) *clusterclient.ClusterClient {
 	return &clusterclient.ClusterClient{
 		Cluster:    p.Cluster(),
 		Collection: p.Collection,
 		Limiter:    p.ActionLimiter(),
+		Pool:       pool,
 	}
 }
 
 func (p *dockerProvisioner) GetClient(app provision.App) (provision.BuilderDockerClient, error) {
-	cli := &clusterclient.ClusterClient{
-		Cluster:    p.Cluster(),
-		Collection: p.Collection,
-		Limiter:    p.ActionLimiter(),
-	}
+	cli := p.poolClusterClient("")
 	if app != nil {
+		cli.Pool = app.GetPool()
 		appNodes, err := p.Nodes(app)
 		if err != nil {
 			return nil, err
@@ -240,7 +240,7 @@ func (s *S) TestStart(c *check.C) {
 	var buf bytes.Buffer
 	cmdData, err := dockercommon.ContainerCmdsDataFromVersion(version)
 	c.Assert(err, check.IsNil)
-	cont, err := s.p.start(context.TODO(), &container.Container{Container: types.Container{ProcessName: "web"}}, app, cmdData, version, &buf, "")
+	cont, err := s.p.start(context.TODO(), &container.Container{Container: types.Container{ProcessName: "web"}}, app, cmdData, version, &buf, nil, "")
 	c.Assert(err, check.IsNil)
 	c.Assert(cont.ID, check.Not(check.Equals), "")
 	cont2, err := s.p.GetContainer(cont.ID)
@@ -264,7 +264,7 @@ func (s *S) TestStartStoppedContainer(c *check.C) {
 	cmdData, err := dockercommon.ContainerCmdsDataFromVersion(version)
 	c.Assert(err, check.IsNil)
 	var buf bytes.Buffer
-	cont, err = s.p.start(context.TODO(), cont, app, cmdData, version, &buf, "")
+	cont, err = s.p.start(context.TODO(), cont, app, cmdData, version, &buf, nil, "")
 	c.Assert(err, check.IsNil)
 	c.Assert(cont.ID, check.Not(check.Equals), "")
 	cont2, err := s.p.GetContainer(cont.ID)
@@ -275,6 +275,61 @@ func (s *S) TestStartStoppedContainer(c *check.C) {
 	c.Assert(cont2.Status, check.Equals, provision.StatusStopped.String())
 }
 
+func (s *S) TestStartWithInitSteps(c *check.C) {
+	app := provisiontest.NewFakeApp("myapp", "python", 1)
+	version, err := newVersionForApp(s.p, app, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+		"init": []map[string]interface{}{
+			{"name": "migrate", "command": "python migrate.py"},
+			{"name": "fetch-config", "image": "busybox", "command": "wget http://config"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = version.CommitBaseImage()
+	c.Assert(err, check.IsNil)
+	routertest.FakeRouter.AddBackend(context.TODO(), app)
+	cmdData, err := dockercommon.ContainerCmdsDataFromVersion(version)
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	cont, err := s.p.start(context.TODO(), &container.Container{Container: types.Container{ProcessName: "web"}}, app, cmdData, version, &buf, nil, "")
+	c.Assert(err, check.IsNil)
+	dockerCont, err := s.p.Cluster().InspectContainer(cont.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dockerCont.Config.Cmd, check.DeepEquals, []string{
+		"/bin/sh", "-lc",
+		"[ -d /home/application/current ] && cd /home/application/current; echo '---- Running init step migrate ----' && (python migrate.py) && exec python myapp.py",
+	})
+	containers, err := s.p.Cluster().ListContainers(docker.ListContainersOptions{All: true})
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 1)
+	logs, err := servicemanager.AppLog.List(context.TODO(), appTypes.ListLogArgs{AppName: "myapp", Source: "init"})
+	c.Assert(err, check.IsNil)
+	c.Assert(len(logs) > 0, check.Equals, true)
+	c.Assert(logs[0].Message, check.Equals, "---- Running init step fetch-config ----")
+	c.Assert(logs[0].Unit, check.Equals, cont.ShortID())
+}
+
+func (s *S) TestStartWithInvalidInitSteps(c *check.C) {
+	app := provisiontest.NewFakeApp("myapp", "python", 1)
+	version, err := newVersionForApp(s.p, app, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+		"init": []map[string]interface{}{
+			{"name": "migrate"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = version.CommitBaseImage()
+	c.Assert(err, check.IsNil)
+	cmdData, err := dockercommon.ContainerCmdsDataFromVersion(version)
+	c.Assert(err, check.IsNil)
+	_, err = s.p.start(context.TODO(), &container.Container{Container: types.Container{ProcessName: "web"}}, app, cmdData, version, nil, nil, "")
+	c.Assert(err, check.ErrorMatches, `init step "migrate" requires a command or an image`)
+}
+
 func (s *S) TestProvisionerGetCluster(c *check.C) {
 	config.Set("docker:cluster:redis-server", "127.0.0.1:6379")
 	defer config.Unset("docker:cluster:redis-server")
@@ -65,7 +65,7 @@ func (s *S) TestNewFakeDockerProvisioner(c *check.C) {
 	_, err = p.storage.RetrieveNode(server.URL())
 	c.Assert(err, check.IsNil)
 	opts := docker.PullImageOptions{Repository: "tsuru/bs"}
-	err = p.Cluster().PullImage(opts, dockercommon.RegistryAuthConfig(opts.Repository))
+	err = p.Cluster().PullImage(opts, dockercommon.RegistryAuthConfig("", opts.Repository))
 	c.Assert(err, check.IsNil)
 	client, err := docker.NewClient(server.URL())
 	c.Assert(err, check.IsNil)
@@ -77,7 +77,7 @@ func (s *S) TestStartMultipleServersCluster(c *check.C) {
 	p, err := StartMultipleServersCluster()
 	c.Assert(err, check.IsNil)
 	opts := docker.PullImageOptions{Repository: "tsuru/bs"}
-	err = p.Cluster().PullImage(opts, dockercommon.RegistryAuthConfig(opts.Repository))
+	err = p.Cluster().PullImage(opts, dockercommon.RegistryAuthConfig("", opts.Repository))
 	c.Assert(err, check.IsNil)
 	nodes, err := p.Cluster().Nodes()
 	c.Assert(err, check.IsNil)
@@ -90,7 +90,7 @@ func (s *S) TestDestroy(c *check.C) {
 	p.Destroy()
 	c.Assert(p.servers, check.IsNil)
 	opts := docker.PullImageOptions{Repository: "tsuru/bs"}
-	err = p.Cluster().PullImage(opts, dockercommon.RegistryAuthConfig(opts.Repository))
+	err = p.Cluster().PullImage(opts, dockercommon.RegistryAuthConfig("", opts.Repository))
 	c.Assert(err, check.NotNil)
 	e, ok := err.(cluster.DockerNodeError)
 	c.Assert(ok, check.Equals, true)
@@ -17,7 +17,8 @@ func (p *dockerProvisioner) checkContainer(container *container.Container) error
 		if err != nil {
 			return err
 		}
-		if info.HTTPHostPort != container.HostPort || info.IP != container.IP {
+		namedPortsChanged := container.UpdateNamedPorts(info)
+		if info.HTTPHostPort != container.HostPort || info.IP != container.IP || namedPortsChanged {
 			err = p.fixContainer(container, info)
 			if err != nil {
 				log.Errorf("error on fix container hostport for [container %s]", container.ID)
@@ -36,9 +37,12 @@ func (p *dockerProvisioner) fixContainer(container *container.Container, info co
 	container.HostPort = info.HTTPHostPort
 	coll := p.Collection()
 	defer coll.Close()
-	err := coll.Update(bson.M{"id": container.ID}, bson.M{
-		"$set": bson.M{"hostport": container.HostPort, "ip": container.IP},
-	})
+	update := bson.M{"hostport": container.HostPort, "ip": container.IP}
+	if len(container.NamedPorts) > 0 {
+		update["namedports"] = container.NamedPorts
+	}
+	err := coll.Update(bson.M{"id": container.ID}, bson.M{"$set": update})
 	rebuild.LockedRoutesRebuildOrEnqueue(container.AppName)
+	p.updateDiscovery(container.AppName)
 	return err
 }
@@ -16,7 +16,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/globalsign/mgo"
 	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	clusterStorage "github.com/tsuru/docker-cluster/storage"
 	"github.com/tsuru/tsuru/api"
 	"github.com/tsuru/tsuru/app"
 	"github.com/tsuru/tsuru/auth"
@@ -26,22 +30,35 @@ import (
 	_ "github.com/tsuru/tsuru/iaas/digitalocean"
 	_ "github.com/tsuru/tsuru/iaas/dockermachine"
 	_ "github.com/tsuru/tsuru/iaas/ec2"
+	_ "github.com/tsuru/tsuru/iaas/gce"
+	_ "github.com/tsuru/tsuru/iaas/hetzner"
 	tsuruIo "github.com/tsuru/tsuru/io"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/container"
 	"github.com/tsuru/tsuru/provision/dockercommon"
+	"github.com/tsuru/tsuru/provision/pool"
 	permTypes "github.com/tsuru/tsuru/types/permission"
 )
 
 func init() {
 	api.RegisterHandler("/docker/container/{id}/move", "POST", api.AuthorizationRequiredHandler(moveContainerHandler))
 	api.RegisterHandler("/docker/containers/move", "POST", api.AuthorizationRequiredHandler(moveContainersHandler))
+	api.RegisterHandler("/docker/containers/move/{from}", "GET", api.AuthorizationRequiredHandler(moveContainersProgressHandler))
 	api.RegisterHandler("/docker/bs/upgrade", "POST", api.AuthorizationRequiredHandler(bsUpgradeHandler))
 	api.RegisterHandler("/docker/bs/env", "POST", api.AuthorizationRequiredHandler(bsEnvSetHandler))
 	api.RegisterHandler("/docker/bs", "GET", api.AuthorizationRequiredHandler(bsConfigGetHandler))
 	api.RegisterHandler("/docker/logs", "GET", api.AuthorizationRequiredHandler(logsConfigGetHandler))
 	api.RegisterHandler("/docker/logs", "POST", api.AuthorizationRequiredHandler(logsConfigSetHandler))
+	api.RegisterHandler("/docker/node/{address:.*}/registry-mirror", "GET", api.AuthorizationRequiredHandler(registryMirrorInfoHandler))
+	api.RegisterHandler("/docker/node/{address:.*}/registry-mirror", "PUT", api.AuthorizationRequiredHandler(registryMirrorSetHandler))
+	api.RegisterHandler("/docker/node/{address:.*}/registry-mirror", "DELETE", api.AuthorizationRequiredHandler(registryMirrorRemoveHandler))
+	api.RegisterHandler("/docker/node/{address:.*}/certs", "GET", api.AuthorizationRequiredHandler(nodeCertsInfoHandler))
+	api.RegisterHandler("/docker/node/{address:.*}/certs", "POST", api.AuthorizationRequiredHandler(nodeCertsRotateHandler))
+	api.RegisterHandler("/pools/{name}/capacity", "GET", api.AuthorizationRequiredHandler(poolCapacityHandler))
+	api.RegisterHandler("/pools/{name}/heterogeneity", "GET", api.AuthorizationRequiredHandler(poolHeterogeneityHandler))
+	api.RegisterHandler("/pools/{name}/status", "GET", api.AuthorizationRequiredHandler(poolStatusHandler))
+	api.RegisterHandler("/pools/{name}/janitor", "GET", api.AuthorizationRequiredHandler(poolJanitorReportsHandler))
 }
 
 // title: move container
@@ -155,6 +172,34 @@ func moveContainersHandler(w http.ResponseWriter, r *http.Request, t auth.Token)
 	return nil
 }
 
+// title: move containers progress
+// path: /docker/containers/move/{from}
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Not found
+func moveContainersProgressHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	from := r.URL.Query().Get(":from")
+	node, err := dockercommon.GetNodeByHost(mainDockerProvisioner.Cluster(), from)
+	if err != nil {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if !permission.Check(t, permission.PermNodeRead, permission.Context(permTypes.CtxPool, node.Metadata[provision.PoolMetadataName])) {
+		return permission.ErrUnauthorized
+	}
+	plan, err := GetMovePlan(from)
+	if err == mgo.ErrNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("no units moved from %s", from)}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(plan)
+}
+
 func moveContainersPermissionContexts(from, to string) ([]permTypes.PermissionContext, error) {
 	originHost, err := dockercommon.GetNodeByHost(mainDockerProvisioner.Cluster(), from)
 	if err != nil {
@@ -306,3 +351,342 @@ func tryRestartAppsByFilter(filter *app.Filter, writer io.Writer) error {
 	wg.Wait()
 	return nil
 }
+
+type registryMirrorInfo struct {
+	Address string `json:"address"`
+	Mirror  string `json:"mirror"`
+	Image   string `json:"image,omitempty"`
+	Digest  string `json:"digest,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// title: node certificates info
+// path: /docker/node/{address}/certs
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Not found
+func nodeCertsInfoHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	node, err := requestNode(r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermNodeUpdateCerts, permission.Context(permTypes.CtxPool, node.Metadata[provision.PoolMetadataName])) {
+		return permission.ErrUnauthorized
+	}
+	certs, err := GetNodeCerts(node.Address)
+	if err == mgo.ErrNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("no certificates issued to node %s", node.Address)}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(certs)
+}
+
+// title: node certificates rotate
+// path: /docker/node/{address}/certs
+// method: POST
+// produce: application/json
+// responses:
+//   200: Ok
+//   400: Node certificates not managed by tsuru
+//   401: Unauthorized
+//   404: Not found
+func nodeCertsRotateHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	node, err := requestNode(r)
+	if err != nil {
+		return err
+	}
+	poolContext := permission.Context(permTypes.CtxPool, node.Metadata[provision.PoolMetadataName])
+	if !permission.Check(t, permission.PermNodeUpdateCerts, poolContext) {
+		return permission.ErrUnauthorized
+	}
+	ca, err := loadCertAuthority()
+	if err != nil {
+		return err
+	}
+	if !ca.manages(node) {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: fmt.Sprintf("certificates of node %s are not issued by the internal CA", node.Address)}
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeNode, Value: node.Address},
+		Kind:       permission.PermNodeUpdateCerts,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(api.InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, poolContext),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	certs, err := mainDockerProvisioner.rotateNodeCerts(node, ca)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]interface{}{"node": certs.Node, "notAfter": certs.NotAfter})
+}
+
+func requestNode(r *http.Request) (cluster.Node, error) {
+	address := r.URL.Query().Get(":address")
+	node, err := mainDockerProvisioner.Cluster().GetNode(address)
+	if err != nil {
+		if err == clusterStorage.ErrNoSuchNode {
+			return cluster.Node{}, &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: provision.ErrNodeNotFound.Error()}
+		}
+		return cluster.Node{}, err
+	}
+	return node, nil
+}
+
+func registryMirrorProbeImage(r *http.Request) string {
+	probe := api.InputValue(r, "image")
+	if probe == "" {
+		probe, _ = config.GetString("docker:registry-mirror:probe-image")
+	}
+	if probe == "" {
+		probe = "busybox:latest"
+	}
+	return probe
+}
+
+// title: node registry mirror info
+// path: /docker/node/{address}/registry-mirror
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Not found
+func registryMirrorInfoHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	node, err := requestNode(r)
+	if err != nil {
+		return err
+	}
+	if !permission.Check(t, permission.PermNodeRead, permission.Context(permTypes.CtxPool, node.Metadata[provision.PoolMetadataName])) {
+		return permission.ErrUnauthorized
+	}
+	info := registryMirrorInfo{Address: node.Address, Mirror: dockercommon.NodeRegistryMirror(node)}
+	if verify, _ := strconv.ParseBool(r.URL.Query().Get("verify")); verify && info.Mirror != "" {
+		info.Image = registryMirrorProbeImage(r)
+		info.Digest, err = dockercommon.VerifyRegistryMirror(node, info.Image)
+		if err != nil {
+			info.Error = err.Error()
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(info)
+}
+
+// title: node registry mirror set
+// path: /docker/node/{address}/registry-mirror
+// method: PUT
+// consume: application/x-www-form-urlencoded
+// produce: application/json
+// responses:
+//   200: Ok
+//   400: Invalid data or mirror unreachable
+//   401: Unauthorized
+//   404: Not found
+func registryMirrorSetHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	mirror := api.InputValue(r, "mirror")
+	if mirror == "" {
+		return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: "mirror is required"}
+	}
+	skipVerify, _ := strconv.ParseBool(api.InputValue(r, "skip-verify"))
+	node, err := requestNode(r)
+	if err != nil {
+		return err
+	}
+	pool := node.Metadata[provision.PoolMetadataName]
+	if !permission.Check(t, permission.PermNodeUpdate, permission.Context(permTypes.CtxPool, pool)) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeNode, Value: node.Address},
+		Kind:       permission.PermNodeUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		CustomData: event.FormToCustomData(api.InputFields(r)),
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, pool)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	info := registryMirrorInfo{Address: node.Address, Mirror: mirror}
+	if !skipVerify {
+		candidate := node
+		candidate.Metadata = map[string]string{dockercommon.RegistryMirrorMetadata: mirror}
+		info.Image = registryMirrorProbeImage(r)
+		info.Digest, err = dockercommon.VerifyRegistryMirror(candidate, info.Image)
+		if err != nil {
+			return &tsuruErrors.HTTP{Code: http.StatusBadRequest, Message: err.Error()}
+		}
+	}
+	_, err = mainDockerProvisioner.Cluster().UpdateNode(cluster.Node{
+		Address:  node.Address,
+		Metadata: map[string]string{dockercommon.RegistryMirrorMetadata: mirror},
+	})
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(info)
+}
+
+// title: node registry mirror remove
+// path: /docker/node/{address}/registry-mirror
+// method: DELETE
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Not found
+func registryMirrorRemoveHandler(w http.ResponseWriter, r *http.Request, t auth.Token) (err error) {
+	node, err := requestNode(r)
+	if err != nil {
+		return err
+	}
+	pool := node.Metadata[provision.PoolMetadataName]
+	if !permission.Check(t, permission.PermNodeUpdate, permission.Context(permTypes.CtxPool, pool)) {
+		return permission.ErrUnauthorized
+	}
+	evt, err := event.New(&event.Opts{
+		Target:     event.Target{Type: event.TargetTypeNode, Value: node.Address},
+		Kind:       permission.PermNodeUpdate,
+		Owner:      t,
+		RemoteAddr: r.RemoteAddr,
+		Allowed:    event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, pool)),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { evt.Done(err) }()
+	_, err = mainDockerProvisioner.Cluster().UpdateNode(cluster.Node{
+		Address:  node.Address,
+		Metadata: map[string]string{dockercommon.RegistryMirrorMetadata: ""},
+	})
+	return err
+}
+
+// title: pool capacity
+// path: /pools/{name}/capacity
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Pool not found
+func poolCapacityHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(t, permission.PermPoolRead, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	_, err := pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	capacity, err := mainDockerProvisioner.PoolCapacity(poolName)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(capacity)
+}
+
+// title: pool status
+// path: /pools/{name}/status
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Pool not found
+func poolStatusHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(t, permission.PermPoolRead, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	_, err := pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	status, err := mainDockerProvisioner.PoolStatus(poolName)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(status)
+}
+
+// title: pool janitor reports
+// path: /pools/{name}/janitor
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   204: No content
+//   401: Unauthorized
+//   404: Pool not found
+func poolJanitorReportsHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(t, permission.PermPoolRead, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	_, err := pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	reports, err := JanitorReports(poolName)
+	if err != nil {
+		return err
+	}
+	if len(reports) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(reports)
+}
+
+// title: pool heterogeneity
+// path: /pools/{name}/heterogeneity
+// method: GET
+// produce: application/json
+// responses:
+//   200: Ok
+//   401: Unauthorized
+//   404: Pool not found
+func poolHeterogeneityHandler(w http.ResponseWriter, r *http.Request, t auth.Token) error {
+	poolName := r.URL.Query().Get(":name")
+	if !permission.Check(t, permission.PermPoolRead, permission.Context(permTypes.CtxPool, poolName)) {
+		return permission.ErrUnauthorized
+	}
+	_, err := pool.GetPoolByName(r.Context(), poolName)
+	if err == pool.ErrPoolNotFound {
+		return &tsuruErrors.HTTP{Code: http.StatusNotFound, Message: err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	report, err := mainDockerProvisioner.PoolHeterogeneity(poolName)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(report)
+}
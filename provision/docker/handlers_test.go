@@ -204,6 +204,50 @@ func (s *HandlersSuite) TestMoveContainersHandler(c *check.C) {
 	}, eventtest.HasEvent)
 }
 
+func (s *HandlersSuite) TestMoveContainersProgressHandler(c *check.C) {
+	mainDockerProvisioner.Cluster().Register(cluster.Node{Address: "http://localhost:2375"})
+	coll, err := movePlansCollection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	err = coll.Insert(MovePlan{
+		From:    "localhost",
+		To:      "127.0.0.1",
+		Total:   2,
+		Pending: []string{"c2"},
+		Moved:   []MovedUnit{{Unit: "c1", NewUnit: "c3", App: "myapp", To: "127.0.0.1"}},
+		Failed:  []FailedMove{},
+	})
+	c.Assert(err, check.IsNil)
+	defer coll.RemoveId("localhost")
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/docker/containers/move/localhost", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := api.RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var plan MovePlan
+	err = json.Unmarshal(recorder.Body.Bytes(), &plan)
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Total, check.Equals, 2)
+	c.Assert(plan.Pending, check.DeepEquals, []string{"c2"})
+	c.Assert(plan.Moved, check.DeepEquals, []MovedUnit{{Unit: "c1", NewUnit: "c3", App: "myapp", To: "127.0.0.1"}})
+	c.Assert(plan.Finished(), check.Equals, false)
+}
+
+func (s *HandlersSuite) TestMoveContainersProgressHandlerNoPlan(c *check.C) {
+	mainDockerProvisioner.Cluster().Register(cluster.Node{Address: "http://localhost:2375"})
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/docker/containers/move/localhost", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	server := api.RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+	c.Assert(recorder.Body.String(), check.Equals, "no units moved from localhost\n")
+}
+
 func (s *HandlersSuite) TestMoveContainerNotFound(c *check.C) {
 	recorder := httptest.NewRecorder()
 	mainDockerProvisioner.Cluster().Register(cluster.Node{Address: "http://127.0.0.1:2375"})
@@ -389,3 +433,70 @@ func (s *HandlersSuite) TestDockerLogsInfoHandler(c *check.C) {
 		"p1": {DockerLogConfig: types.DockerLogConfig{Driver: "syslog", LogOpts: map[string]string{}}},
 	})
 }
+
+func (s *HandlersSuite) TestRegistryMirrorSetHandlerSkipVerify(c *check.C) {
+	body := strings.NewReader("mirror=mirror.local:5000&skip-verify=true")
+	path := fmt.Sprintf("/docker/node/%s/registry-mirror", s.server.URL())
+	request, err := http.NewRequest(http.MethodPut, path, body)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	server := api.RunServer(true)
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	node, err := s.p.Cluster().GetNode(s.server.URL())
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Metadata, check.DeepEquals, map[string]string{
+		"pool":            "test-default",
+		"registry-mirror": "mirror.local:5000",
+	})
+	request, err = http.NewRequest(http.MethodGet, path, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	var info registryMirrorInfo
+	err = json.Unmarshal(recorder.Body.Bytes(), &info)
+	c.Assert(err, check.IsNil)
+	c.Assert(info, check.DeepEquals, registryMirrorInfo{Address: s.server.URL(), Mirror: "mirror.local:5000"})
+}
+
+func (s *HandlersSuite) TestRegistryMirrorSetHandlerWithoutMirror(c *check.C) {
+	path := fmt.Sprintf("/docker/node/%s/registry-mirror", s.server.URL())
+	request, err := http.NewRequest(http.MethodPut, path, strings.NewReader(""))
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	api.RunServer(true).ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusBadRequest)
+}
+
+func (s *HandlersSuite) TestRegistryMirrorRemoveHandler(c *check.C) {
+	_, err := s.p.Cluster().UpdateNode(cluster.Node{
+		Address:  s.server.URL(),
+		Metadata: map[string]string{"registry-mirror": "mirror.local:5000"},
+	})
+	c.Assert(err, check.IsNil)
+	path := fmt.Sprintf("/docker/node/%s/registry-mirror", s.server.URL())
+	request, err := http.NewRequest(http.MethodDelete, path, nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	api.RunServer(true).ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK)
+	node, err := s.p.Cluster().GetNode(s.server.URL())
+	c.Assert(err, check.IsNil)
+	c.Assert(node.Metadata, check.DeepEquals, map[string]string{"pool": "test-default"})
+}
+
+func (s *HandlersSuite) TestRegistryMirrorInfoHandlerNodeNotFound(c *check.C) {
+	request, err := http.NewRequest(http.MethodGet, "/docker/node/http://unknown:2375/registry-mirror", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	api.RunServer(true).ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
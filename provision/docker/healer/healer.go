@@ -8,6 +8,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/globalsign/mgo"
@@ -56,6 +57,12 @@ func toHealingEvt(evt *event.Event) (types.HealingEvent, error) {
 		if err != nil {
 			return healingEvt, err
 		}
+		var data containerHealingData
+		err = evt.StartData(&data)
+		if err != nil {
+			return healingEvt, err
+		}
+		healingEvt.Reason = data.Reason
 		err = evt.EndData(&healingEvt.CreatedContainer)
 		if err != nil {
 			return healingEvt, err
@@ -107,6 +114,45 @@ func ListHealingHistory(filter string) ([]types.HealingEvent, error) {
 	return healingEvts, nil
 }
 
+// UnitHealingHistory returns the healing events of a unit and of the units it
+// replaced through previous healings, most recent first.
+func UnitHealingHistory(unitID string) ([]types.HealingEvent, error) {
+	var history []types.HealingEvent
+	seen := map[interface{}]bool{}
+	visited := map[string]bool{}
+	for unitID != "" && !visited[unitID] {
+		visited[unitID] = true
+		evts, err := event.List(&event.Filter{
+			KindNames: []string{"healer"},
+			KindType:  event.KindTypeInternal,
+			Target:    event.Target{Type: event.TargetTypeContainer, Value: unitID},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var previousID string
+		for _, evt := range evts {
+			if evt.Target.Type != event.TargetTypeContainer || seen[evt.UniqueID] {
+				continue
+			}
+			seen[evt.UniqueID] = true
+			if evt.Target.Value != unitID {
+				previousID = evt.Target.Value
+			}
+			healingEvt, err := toHealingEvt(evt)
+			if err != nil {
+				return nil, err
+			}
+			history = append(history, healingEvt)
+		}
+		unitID = previousID
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].StartTime.After(history[j].StartTime)
+	})
+	return history, nil
+}
+
 func oldHealingCollection() (*storage.Collection, error) {
 	name, _ := config.GetString("docker:healing:events_collection")
 	if name == "" {
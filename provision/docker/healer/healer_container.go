@@ -21,9 +21,17 @@ import (
 	permTypes "github.com/tsuru/tsuru/types/permission"
 )
 
+const (
+	healingReasonCrashLoop = "crash-loop"
+	healingReasonLiveness  = "liveness"
+)
+
 type ContainerHealer struct {
 	provisioner         DockerProvisioner
 	maxUnresponsiveTime time.Duration
+	crashLoop           CrashLoopConfig
+	liveness            LivenessChecker
+	startup             StartupChecker
 	done                chan bool
 	locker              AppLocker
 }
@@ -31,14 +39,56 @@ type ContainerHealer struct {
 type ContainerHealerArgs struct {
 	Provisioner         DockerProvisioner
 	MaxUnresponsiveTime time.Duration
+	CrashLoop           CrashLoopConfig
+	Liveness            LivenessChecker
+	Startup             StartupChecker
 	Done                chan bool
 	Locker              AppLocker
 }
 
+// LivenessChecker checks if a container is alive according to the liveness
+// check declared by its app, containers failing it are healed. Liveness
+// healing is disabled when the checker is nil.
+type LivenessChecker interface {
+	CheckLiveness(cont container.Container) error
+}
+
+// CrashLoopConfig controls the healing of containers that keep exiting or
+// stay in error state. Crash-loop healing is disabled when Restarts is zero.
+type CrashLoopConfig struct {
+	// Restarts is the number of restarts after which a container in error
+	// state is considered to be crash-looping.
+	Restarts int
+	// ErrorTimeout is how long a stopped container may stay in error state
+	// before being considered crash-looping, regardless of its restarts.
+	ErrorTimeout time.Duration
+	// MaxHealings is the number of times a unit is recreated because it was
+	// crash-looping before tsuru gives up healing it.
+	MaxHealings int
+	// Backoff is the time to wait between the first and the second healing
+	// of a unit, doubled for each subsequent healing.
+	Backoff time.Duration
+}
+
+// StartupChecker returns how long a container may take to boot, containers
+// aren't healed while booting. Every container is healed right away when
+// the checker is nil.
+type StartupChecker interface {
+	MaxBootTime(cont container.Container) time.Duration
+}
+
+type containerHealingData struct {
+	container.Container `bson:",inline"`
+	Reason              string `bson:"reason,omitempty"`
+}
+
 func NewContainerHealer(args ContainerHealerArgs) *ContainerHealer {
 	return &ContainerHealer{
 		provisioner:         args.Provisioner,
 		maxUnresponsiveTime: args.MaxUnresponsiveTime,
+		crashLoop:           args.CrashLoop,
+		liveness:            args.Liveness,
+		startup:             args.Startup,
 		done:                args.Done,
 		locker:              args.Locker,
 	}
@@ -91,7 +141,20 @@ func (h *ContainerHealer) isAsExpected(cont container.Container) (bool, error) {
 	return isRunning, nil
 }
 
+// isBooting returns whether the container is still within the max boot time
+// of its process.
+func (h *ContainerHealer) isBooting(cont container.Container) bool {
+	if h.startup == nil || !cont.MongoID.Valid() {
+		return false
+	}
+	maxBoot := h.startup.MaxBootTime(cont)
+	return maxBoot > 0 && time.Since(cont.MongoID.Time()) < maxBoot
+}
+
 func (h *ContainerHealer) healContainerIfNeeded(cont container.Container) error {
+	if h.isBooting(cont) {
+		return nil
+	}
 	if cont.LastSuccessStatusUpdate.IsZero() {
 		if !cont.MongoID.Time().Before(time.Now().Add(-h.maxUnresponsiveTime)) {
 			return nil
@@ -105,13 +168,77 @@ func (h *ContainerHealer) healContainerIfNeeded(cont container.Container) error
 		cont.SetStatus(h.provisioner.ClusterClient(), cont.ExpectedStatus(), true)
 		return nil
 	}
+	return h.healContainerWithEvent(cont, "")
+}
+
+func (h *ContainerHealer) isCrashLooping(cont container.Container) (bool, error) {
+	if cont.ExpectedStatus() == provision.StatusStopped {
+		return false, nil
+	}
+	dockerCont, err := h.provisioner.Cluster().InspectContainer(cont.ID)
+	if err != nil {
+		return false, err
+	}
+	if dockerCont.RestartCount >= h.crashLoop.Restarts {
+		return true, nil
+	}
+	if dockerCont.State.Running && !dockerCont.State.Restarting {
+		return false, nil
+	}
+	if h.crashLoop.ErrorTimeout <= 0 {
+		return false, nil
+	}
+	return cont.LastStatusUpdate.Before(time.Now().Add(-h.crashLoop.ErrorTimeout)), nil
+}
+
+func (h *ContainerHealer) healCrashLoopingContainerIfNeeded(cont container.Container) error {
+	if h.isBooting(cont) {
+		return nil
+	}
+	crashLooping, err := h.isCrashLooping(cont)
+	if err != nil {
+		return errors.Wrapf(err, "Containers healing: couldn't verify if container %q is crash-looping", cont.ID)
+	}
+	if !crashLooping {
+		return nil
+	}
+	history, err := UnitHealingHistory(cont.ID)
+	if err != nil {
+		return errors.Wrapf(err, "Containers healing: unable to heal %q couldn't get its healing history", cont.ID)
+	}
+	var healings int
+	var lastHealing time.Time
+	for _, evt := range history {
+		if evt.Reason != healingReasonCrashLoop {
+			continue
+		}
+		healings++
+		healingTime := evt.EndTime
+		if healingTime.IsZero() {
+			healingTime = evt.StartTime
+		}
+		if healingTime.After(lastHealing) {
+			lastHealing = healingTime
+		}
+	}
+	if healings >= h.crashLoop.MaxHealings {
+		log.Debugf("Containers healing: container %q is crash-looping but was already healed %d times, giving up.", cont.ID, healings)
+		return nil
+	}
+	if healings > 0 && time.Since(lastHealing) < h.crashLoop.Backoff<<uint(healings-1) {
+		return nil
+	}
+	return h.healContainerWithEvent(cont, healingReasonCrashLoop)
+}
+
+func (h *ContainerHealer) healContainerWithEvent(cont container.Container, reason string) error {
 	locked := h.locker.Lock(cont.AppName)
 	if !locked {
 		return errors.Errorf("Containers healing: unable to heal %q couldn't lock app %s", cont.ID, cont.AppName)
 	}
 	defer h.locker.Unlock(cont.AppName)
 	// Sanity check, now we have a lock, let's find out if the container still exists
-	_, err = h.provisioner.GetContainer(cont.ID)
+	_, err := h.provisioner.GetContainer(cont.ID)
 	if err != nil {
 		if _, isNotFound := err.(*provision.UnitNotFoundError); isNotFound {
 			return nil
@@ -122,14 +249,21 @@ func (h *ContainerHealer) healContainerIfNeeded(cont container.Container) error
 	if err != nil {
 		return errors.Wrapf(err, "Containers healing: unable to heal %q couldn't get app %q", cont.ID, cont.AppName)
 	}
-	log.Errorf("Initiating healing process for container %q, unresponsive since %s.", cont.ID, cont.LastSuccessStatusUpdate)
+	switch reason {
+	case healingReasonCrashLoop:
+		log.Errorf("Initiating healing process for container %q, crash-looping since %s.", cont.ID, cont.LastStatusUpdate)
+	case healingReasonLiveness:
+		log.Errorf("Initiating healing process for container %q, failing its liveness check.", cont.ID)
+	default:
+		log.Errorf("Initiating healing process for container %q, unresponsive since %s.", cont.ID, cont.LastSuccessStatusUpdate)
+	}
 	evt, err := event.NewInternal(&event.Opts{
 		Target: event.Target{Type: event.TargetTypeContainer, Value: cont.ID},
 		ExtraTargets: []event.ExtraTarget{
 			{Target: event.Target{Type: event.TargetTypeApp, Value: cont.AppName}},
 		},
 		InternalKind: "healer",
-		CustomData:   cont,
+		CustomData:   containerHealingData{Container: cont, Reason: reason},
 		Allowed: event.Allowed(permission.PermAppReadEvents, append(permission.Contexts(permTypes.CtxTeam, a.Teams),
 			permission.Context(permTypes.CtxApp, a.Name),
 			permission.Context(permTypes.CtxPool, a.Pool),
@@ -153,6 +287,15 @@ func (h *ContainerHealer) healContainerIfNeeded(cont container.Container) error
 }
 
 func (h *ContainerHealer) runContainerHealerOnce() {
+	if h.crashLoop.Restarts > 0 {
+		h.runCrashLoopHealerOnce()
+	}
+	if h.liveness != nil {
+		h.runLivenessHealerOnce()
+	}
+	if h.maxUnresponsiveTime <= 0 {
+		return
+	}
 	containers, err := listUnresponsiveContainers(h.provisioner, h.maxUnresponsiveTime)
 	if err != nil {
 		log.Errorf("Containers Healing: couldn't list unresponsive containers: %s", err)
@@ -165,6 +308,51 @@ func (h *ContainerHealer) runContainerHealerOnce() {
 	}
 }
 
+func (h *ContainerHealer) runCrashLoopHealerOnce() {
+	containers, err := h.provisioner.ListContainers(bson.M{
+		"id":      bson.M{"$ne": ""},
+		"appname": bson.M{"$ne": ""},
+		"status":  provision.StatusError.String(),
+	})
+	if err != nil {
+		log.Errorf("Containers Healing: couldn't list containers in error state: %s", err)
+		return
+	}
+	for _, cont := range containers {
+		err := h.healCrashLoopingContainerIfNeeded(cont)
+		if err != nil {
+			log.Errorf("Containers Healing: couldn't heal crash-looping container: %s", err)
+		}
+	}
+}
+
+func (h *ContainerHealer) runLivenessHealerOnce() {
+	containers, err := h.provisioner.ListContainers(bson.M{
+		"id":       bson.M{"$ne": ""},
+		"appname":  bson.M{"$ne": ""},
+		"hostport": bson.M{"$ne": ""},
+		"status":   provision.StatusStarted.String(),
+	})
+	if err != nil {
+		log.Errorf("Containers Healing: couldn't list started containers: %s", err)
+		return
+	}
+	for _, cont := range containers {
+		if h.isBooting(cont) {
+			continue
+		}
+		checkErr := h.liveness.CheckLiveness(cont)
+		if checkErr == nil {
+			continue
+		}
+		log.Errorf("Containers Healing: container %q failed its liveness check: %s", cont.ID, checkErr)
+		err = h.healContainerWithEvent(cont, healingReasonLiveness)
+		if err != nil {
+			log.Errorf("Containers Healing: couldn't heal container failing its liveness check: %s", err)
+		}
+	}
+}
+
 var localSkip uint64
 
 func listUnresponsiveContainers(p DockerProvisioner, maxUnresponsiveTime time.Duration) ([]container.Container, error) {
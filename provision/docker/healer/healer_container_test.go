@@ -801,3 +801,204 @@ func (s *S) TestListUnresponsiveContainersRotateResults(c *check.C) {
 	c.Assert(result1[0], check.DeepEquals, result2[1])
 	c.Assert(result1[1], check.DeepEquals, result2[0])
 }
+
+func (s *S) TestRunContainerHealerCrashLoopingContainer(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	app := newFakeAppInDB("myapp", "python", 2)
+	node1 := p.Servers()[0]
+	containers, err := p.StartContainers(dockertest.StartContainersArgs{
+		Endpoint:  node1.URL(),
+		App:       app,
+		Amount:    map[string]int{"web": 2},
+		Image:     "tsuru/python",
+		PullImage: true,
+	})
+	c.Assert(err, check.IsNil)
+	node1.MutateContainer(containers[1].ID, docker.State{Running: false, Restarting: true})
+	toMoveCont := containers[1]
+	err = toMoveCont.SetStatus(p.ClusterClient(), provision.StatusStarted, false)
+	c.Assert(err, check.IsNil)
+	err = toMoveCont.SetStatus(p.ClusterClient(), provision.StatusError, false)
+	c.Assert(err, check.IsNil)
+	toMoveCont.LastStatusUpdate = time.Now().UTC().Add(-5 * time.Minute)
+	p.PrepareListResult([]container.Container{containers[0], toMoveCont}, nil)
+	healer := NewContainerHealer(ContainerHealerArgs{
+		Provisioner: p,
+		CrashLoop: CrashLoopConfig{
+			Restarts:     5,
+			ErrorTimeout: time.Minute,
+			MaxHealings:  3,
+			Backoff:      time.Minute,
+		},
+		Locker: dockertest.NewFakeLocker(),
+	})
+	healer.runContainerHealerOnce()
+	c.Assert(p.Movings(), check.DeepEquals, []dockertest.ContainerMoving{
+		{ContainerID: toMoveCont.ID, HostFrom: toMoveCont.HostAddr, HostTo: ""},
+	})
+	queries := p.Queries()
+	c.Assert(queries, check.DeepEquals, []bson.M{{
+		"id":      bson.M{"$ne": ""},
+		"appname": bson.M{"$ne": ""},
+		"status":  provision.StatusError.String(),
+	}})
+	c.Assert(eventtest.EventDesc{
+		Target: event.Target{Type: "container", Value: toMoveCont.ID},
+		ExtraTargets: []event.ExtraTarget{
+			{Target: event.Target{Type: "app", Value: "myapp"}},
+			{Target: event.Target{Type: "container", Value: toMoveCont.ID + "-recreated"}},
+		},
+		Kind: "healer",
+		StartCustomData: map[string]interface{}{
+			"id":     toMoveCont.ID,
+			"reason": "crash-loop",
+		},
+		EndCustomData: map[string]interface{}{
+			"id": bson.M{"$ne": ""},
+		},
+	}, eventtest.HasEvent)
+	history, err := UnitHealingHistory(toMoveCont.ID + "-recreated")
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 1)
+	c.Assert(history[0].Reason, check.Equals, "crash-loop")
+}
+
+func (s *S) TestRunContainerHealerCrashLoopingContainerBackoff(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	app := newFakeAppInDB("myapp", "python", 1)
+	node1 := p.Servers()[0]
+	containers, err := p.StartContainers(dockertest.StartContainersArgs{
+		Endpoint:  node1.URL(),
+		App:       app,
+		Amount:    map[string]int{"web": 1},
+		Image:     "tsuru/python",
+		PullImage: true,
+	})
+	c.Assert(err, check.IsNil)
+	node1.MutateContainer(containers[0].ID, docker.State{Running: false})
+	cont := containers[0]
+	err = cont.SetStatus(p.ClusterClient(), provision.StatusStarted, false)
+	c.Assert(err, check.IsNil)
+	err = cont.SetStatus(p.ClusterClient(), provision.StatusError, false)
+	c.Assert(err, check.IsNil)
+	cont.LastStatusUpdate = time.Now().UTC().Add(-5 * time.Minute)
+	p.PrepareListResult([]container.Container{cont}, nil)
+	evt, err := event.NewInternal(&event.Opts{
+		Target: event.Target{Type: "container", Value: "previous-cont"},
+		ExtraTargets: []event.ExtraTarget{
+			{Target: event.Target{Type: "container", Value: cont.ID}},
+		},
+		InternalKind: "healer",
+		CustomData:   containerHealingData{Container: container.Container{Container: types.Container{ID: "previous-cont", AppName: "myapp"}}, Reason: "crash-loop"},
+		Allowed:      event.Allowed(permission.PermAppReadEvents),
+	})
+	c.Assert(err, check.IsNil)
+	err = evt.DoneCustomData(nil, cont)
+	c.Assert(err, check.IsNil)
+	healer := NewContainerHealer(ContainerHealerArgs{
+		Provisioner: p,
+		CrashLoop: CrashLoopConfig{
+			Restarts:     5,
+			ErrorTimeout: time.Minute,
+			MaxHealings:  3,
+			Backoff:      time.Minute,
+		},
+		Locker: dockertest.NewFakeLocker(),
+	})
+	healer.runContainerHealerOnce()
+	c.Assert(p.Movings(), check.IsNil)
+	healer.crashLoop.Backoff = 0
+	healer.crashLoop.MaxHealings = 1
+	healer.runContainerHealerOnce()
+	c.Assert(p.Movings(), check.IsNil)
+	healer.crashLoop.MaxHealings = 2
+	healer.runContainerHealerOnce()
+	c.Assert(p.Movings(), check.HasLen, 1)
+}
+
+type fakeLivenessChecker struct {
+	dead map[string]bool
+}
+
+func (f *fakeLivenessChecker) CheckLiveness(cont container.Container) error {
+	if f.dead[cont.ID] {
+		return errors.New("healthcheck fail: wrong status code")
+	}
+	return nil
+}
+
+func (s *S) TestRunContainerHealerLivenessFailingContainer(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	app := newFakeAppInDB("myapp", "python", 2)
+	node1 := p.Servers()[0]
+	containers, err := p.StartContainers(dockertest.StartContainersArgs{
+		Endpoint:  node1.URL(),
+		App:       app,
+		Amount:    map[string]int{"web": 2},
+		Image:     "tsuru/python",
+		PullImage: true,
+	})
+	c.Assert(err, check.IsNil)
+	toMoveCont := containers[1]
+	p.PrepareListResult([]container.Container{containers[0], toMoveCont}, nil)
+	healer := NewContainerHealer(ContainerHealerArgs{
+		Provisioner: p,
+		Liveness:    &fakeLivenessChecker{dead: map[string]bool{toMoveCont.ID: true}},
+		Locker:      dockertest.NewFakeLocker(),
+	})
+	healer.runContainerHealerOnce()
+	c.Assert(p.Movings(), check.DeepEquals, []dockertest.ContainerMoving{
+		{ContainerID: toMoveCont.ID, HostFrom: toMoveCont.HostAddr, HostTo: ""},
+	})
+	c.Assert(p.Queries(), check.DeepEquals, []bson.M{{
+		"id":       bson.M{"$ne": ""},
+		"appname":  bson.M{"$ne": ""},
+		"hostport": bson.M{"$ne": ""},
+		"status":   provision.StatusStarted.String(),
+	}})
+	history, err := UnitHealingHistory(toMoveCont.ID + "-recreated")
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 1)
+	c.Assert(history[0].Reason, check.Equals, "liveness")
+}
+
+type fakeStartupChecker struct {
+	maxBoot time.Duration
+}
+
+func (f *fakeStartupChecker) MaxBootTime(cont container.Container) time.Duration {
+	return f.maxBoot
+}
+
+func (s *S) TestRunContainerHealerLivenessBootingContainer(c *check.C) {
+	p, err := dockertest.StartMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	defer p.Destroy()
+	app := newFakeAppInDB("myapp", "python", 1)
+	node1 := p.Servers()[0]
+	containers, err := p.StartContainers(dockertest.StartContainersArgs{
+		Endpoint:  node1.URL(),
+		App:       app,
+		Amount:    map[string]int{"web": 1},
+		Image:     "tsuru/python",
+		PullImage: true,
+	})
+	c.Assert(err, check.IsNil)
+	cont := containers[0]
+	cont.MongoID = bson.NewObjectId()
+	p.PrepareListResult([]container.Container{cont}, nil)
+	healer := NewContainerHealer(ContainerHealerArgs{
+		Provisioner: p,
+		Liveness:    &fakeLivenessChecker{dead: map[string]bool{cont.ID: true}},
+		Startup:     &fakeStartupChecker{maxBoot: time.Minute},
+		Locker:      dockertest.NewFakeLocker(),
+	})
+	healer.runContainerHealerOnce()
+	c.Assert(p.Movings(), check.IsNil)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/tsuru/tsuru/healer"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
 	"github.com/tsuru/tsuru/provision/docker/types"
 	check "gopkg.in/check.v1"
 )
@@ -105,3 +106,38 @@ func (s *S) TestMigrateHealingToEvents(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(dbEvts, check.DeepEquals, []types.HealingEvent{evts[1], evts[0]})
 }
+
+func (s *S) TestUnitHealingHistory(c *check.C) {
+	var evts []*event.Event
+	for _, ids := range [][2]string{{"cont1", "cont2"}, {"cont2", "cont3"}, {"other1", "other2"}} {
+		evt, err := event.NewInternal(&event.Opts{
+			Target: event.Target{Type: "container", Value: ids[0]},
+			ExtraTargets: []event.ExtraTarget{
+				{Target: event.Target{Type: "container", Value: ids[1]}},
+			},
+			InternalKind: "healer",
+			CustomData:   containerHealingData{Container: container.Container{Container: types.Container{ID: ids[0]}}, Reason: "crash-loop"},
+			Allowed:      event.Allowed(permission.PermAppReadEvents),
+		})
+		c.Assert(err, check.IsNil)
+		err = evt.DoneCustomData(nil, container.Container{Container: types.Container{ID: ids[1]}})
+		c.Assert(err, check.IsNil)
+		evts = append(evts, evt)
+		time.Sleep(10 * time.Millisecond)
+	}
+	history, err := UnitHealingHistory("cont3")
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 2)
+	c.Assert(history[0].ID, check.Equals, evts[1].UniqueID)
+	c.Assert(history[0].Reason, check.Equals, "crash-loop")
+	c.Assert(history[0].FailingContainer.ID, check.Equals, "cont2")
+	c.Assert(history[0].CreatedContainer.ID, check.Equals, "cont3")
+	c.Assert(history[1].ID, check.Equals, evts[0].UniqueID)
+	c.Assert(history[1].FailingContainer.ID, check.Equals, "cont1")
+	history, err = UnitHealingHistory("cont2")
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 2)
+	history, err = UnitHealingHistory("unknown")
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 0)
+}
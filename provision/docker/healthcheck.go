@@ -15,97 +15,45 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/container"
 	"github.com/tsuru/tsuru/provision/dockercommon"
+	"github.com/tsuru/tsuru/servicemanager"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 )
 
 func runHealthcheck(cont *container.Container, yamlData provTypes.TsuruYamlData, w io.Writer) error {
-	if yamlData.Healthcheck == nil {
+	hc := yamlData.ReadinessCheck()
+	if hc == nil || hc.Path == "" {
 		return nil
 	}
-	path := yamlData.Healthcheck.Path
-	method := yamlData.Healthcheck.Method
-	match := yamlData.Healthcheck.Match
-	status := yamlData.Healthcheck.Status
-	scheme := yamlData.Healthcheck.Scheme
-	headers := yamlData.Healthcheck.Headers
-	if scheme == "" {
-		scheme = provision.DefaultHealthcheckScheme
-	}
-	allowedFailures := yamlData.Healthcheck.AllowedFailures
-	if path == "" {
-		return nil
-	}
-	path = strings.TrimSpace(strings.TrimLeft(path, "/"))
-	if method == "" {
-		method = "get"
-	}
-	method = strings.ToUpper(method)
-	if status == 0 && match == "" {
-		status = 200
-	}
-	var matchRE *regexp.Regexp
-	var err error
-	if match != "" {
-		match = "(?s)" + match
-		matchRE, err = regexp.Compile(match)
-		if err != nil {
-			return err
-		}
+	check, err := newContainerCheck(hc)
+	if err != nil {
+		return err
 	}
+	allowedFailures := hc.AllowedFailures
+	startup := yamlData.StartupForProcess(cont.ProcessName)
 	maxWaitTime := dockercommon.DeployHealthcheckTimeout(yamlData)
+	if maxBoot := startup.MaxBoot(); maxBoot > maxWaitTime {
+		maxWaitTime = maxBoot
+	}
 	sleepTime := 3 * time.Second
 	startedTime := time.Now()
-	url := fmt.Sprintf("%s://%s:%s/%s", scheme, cont.HostAddr, cont.HostPort, path)
 	for {
-		var lastError error = nil
-		req, err := http.NewRequest(method, url, nil)
-		for header, value := range headers {
-			if header == "Host" {
-				req.Host = value
-			} else {
-				req.Header.Set(header, value)
-			}
-		}
-		if err != nil {
-			return err
-		}
-		if yamlData.Healthcheck.TimeoutSeconds > 0 {
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(yamlData.Healthcheck.TimeoutSeconds))
-			defer cancel()
-			req = req.WithContext(ctx)
-		}
-		rsp, err := net.Dial15Full60ClientNoKeepAliveNoRedirectInsecure.Do(req)
-		if err != nil {
-			lastError = errors.Wrapf(err, "healthcheck fail(%s)", cont.ShortID())
-		} else {
-			defer rsp.Body.Close()
-			if status != 0 && rsp.StatusCode != status {
-				lastError = errors.Errorf("healthcheck fail(%s): wrong status code, expected %d, got: %d", cont.ShortID(), status, rsp.StatusCode)
-			} else if matchRE != nil {
-				result, err := ioutil.ReadAll(rsp.Body)
-
-				if err != nil {
-					lastError = err
-				}
-				if !matchRE.Match(result) {
-					lastError = errors.Errorf("healthcheck fail(%s): unexpected result, expected %q, got: %s", cont.ShortID(), match, string(result))
-				}
-			}
-			if lastError != nil {
-				if allowedFailures == 0 {
-					return lastError
-				}
-				allowedFailures--
-			}
-		}
+		responded, lastError := check.run(cont)
 		if lastError == nil {
 			fmt.Fprintf(w, " ---> healthcheck successful(%s)\n", cont.ShortID())
 			return nil
 		}
+		if responded && time.Since(startedTime) >= startup.GracePeriod() {
+			if allowedFailures == 0 {
+				return lastError
+			}
+			allowedFailures--
+		}
 		if time.Since(startedTime) > maxWaitTime {
 			return lastError
 		}
@@ -113,3 +61,156 @@ func runHealthcheck(cont *container.Container, yamlData provTypes.TsuruYamlData,
 		time.Sleep(sleepTime)
 	}
 }
+
+// CheckLiveness probes the liveness check declared by the app of the
+// container, failing when the check fails the allowed failures in a row,
+// waiting the check interval between the probes. Containers without exposed
+// ports and apps without a liveness check path are considered alive.
+func (p *dockerProvisioner) CheckLiveness(cont container.Container) error {
+	if cont.HostPort == "" {
+		return nil
+	}
+	yamlData, err := containerYamlData(cont)
+	if err != nil {
+		return err
+	}
+	hc := yamlData.LivenessCheck()
+	if hc == nil || hc.Path == "" {
+		return nil
+	}
+	check, err := newContainerCheck(hc)
+	if err != nil {
+		return err
+	}
+	allowedFailures := hc.AllowedFailures
+	if allowedFailures <= 0 {
+		allowedFailures = 3
+	}
+	interval := time.Duration(hc.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	for i := 0; ; i++ {
+		_, err = check.run(&cont)
+		if err == nil || i+1 >= allowedFailures {
+			return err
+		}
+		time.Sleep(interval)
+	}
+}
+
+// MaxBootTime returns how long the container may take to boot, according to
+// the startup settings of its process.
+func (p *dockerProvisioner) MaxBootTime(cont container.Container) time.Duration {
+	return containerStartup(cont).MaxBoot()
+}
+
+// containerBooting returns whether the container is still in the grace
+// period of its process, when its failures are ignored.
+func containerBooting(cont *container.Container) bool {
+	if !cont.MongoID.Valid() {
+		return false
+	}
+	return time.Since(cont.MongoID.Time()) < containerStartup(*cont).GracePeriod()
+}
+
+func containerStartup(cont container.Container) provTypes.TsuruYamlStartup {
+	yamlData, err := containerYamlData(cont)
+	if err != nil {
+		log.Errorf("unable to get startup settings of container %s: %s", cont.ShortID(), err)
+		return provTypes.TsuruYamlStartup{}
+	}
+	return yamlData.StartupForProcess(cont.ProcessName)
+}
+
+func containerYamlData(cont container.Container) (provTypes.TsuruYamlData, error) {
+	a, err := app.GetByName(context.TODO(), cont.AppName)
+	if err != nil {
+		return provTypes.TsuruYamlData{}, err
+	}
+	version, err := servicemanager.AppVersion.VersionByImageOrVersion(context.TODO(), a, cont.Image)
+	if err != nil {
+		return provTypes.TsuruYamlData{}, err
+	}
+	return version.TsuruYamlData()
+}
+
+type containerCheck struct {
+	path    string
+	method  string
+	scheme  string
+	status  int
+	headers map[string]string
+	match   string
+	matchRE *regexp.Regexp
+	timeout time.Duration
+}
+
+func newContainerCheck(hc *provTypes.TsuruYamlHealthcheck) (*containerCheck, error) {
+	check := containerCheck{
+		path:    strings.TrimSpace(strings.TrimLeft(hc.Path, "/")),
+		method:  strings.ToUpper(hc.Method),
+		scheme:  hc.Scheme,
+		status:  hc.Status,
+		headers: hc.Headers,
+		timeout: time.Duration(hc.TimeoutSeconds) * time.Second,
+	}
+	if check.scheme == "" {
+		check.scheme = provision.DefaultHealthcheckScheme
+	}
+	if check.method == "" {
+		check.method = http.MethodGet
+	}
+	if check.status == 0 && hc.Match == "" {
+		check.status = http.StatusOK
+	}
+	if hc.Match != "" {
+		check.match = "(?s)" + hc.Match
+		var err error
+		check.matchRE, err = regexp.Compile(check.match)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &check, nil
+}
+
+// run requests the check path in the container, responded is false when the
+// request failed before getting a response.
+func (c *containerCheck) run(cont *container.Container) (responded bool, err error) {
+	url := fmt.Sprintf("%s://%s:%s/%s", c.scheme, cont.HostAddr, cont.HostPort, c.path)
+	req, err := http.NewRequest(c.method, url, nil)
+	if err != nil {
+		return false, err
+	}
+	for header, value := range c.headers {
+		if header == "Host" {
+			req.Host = value
+		} else {
+			req.Header.Set(header, value)
+		}
+	}
+	if c.timeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	rsp, err := net.Dial15Full60ClientNoKeepAliveNoRedirectInsecure.Do(req)
+	if err != nil {
+		return false, errors.Wrapf(err, "healthcheck fail(%s)", cont.ShortID())
+	}
+	defer rsp.Body.Close()
+	if c.status != 0 && rsp.StatusCode != c.status {
+		return true, errors.Errorf("healthcheck fail(%s): wrong status code, expected %d, got: %d", cont.ShortID(), c.status, rsp.StatusCode)
+	}
+	if c.matchRE != nil {
+		result, err := ioutil.ReadAll(rsp.Body)
+		if err != nil {
+			return true, err
+		}
+		if !c.matchRE.Match(result) {
+			return true, errors.Errorf("healthcheck fail(%s): unexpected result, expected %q, got: %s", cont.ShortID(), c.match, string(result))
+		}
+	}
+	return true, nil
+}
@@ -423,3 +423,71 @@ func (s *S) TestHealthcheckSuccessfulWithAllowedFailures(c *check.C) {
 	c.Assert(requests[2].Method, check.Equals, "GET")
 	c.Assert(requests[2].URL.Path, check.Equals, "/x/y")
 }
+
+func (s *S) TestHealthcheckUsesReadiness(c *check.C) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	a := app.App{Name: "myapp1"}
+	customData := map[string]interface{}{
+		"healthcheck": map[string]interface{}{
+			"path": "/hc",
+		},
+		"readiness": map[string]interface{}{
+			"path": "/ready",
+		},
+	}
+	version, err := newVersionForApp(s.p, &a, customData)
+	c.Assert(err, check.IsNil)
+	url, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(url.Host)
+	cont := container.Container{Container: types.Container{AppName: a.Name, HostAddr: host, HostPort: port}}
+	yamlData, err := version.TsuruYamlData()
+	c.Assert(err, check.IsNil)
+	err = runHealthcheck(&cont, yamlData, &bytes.Buffer{})
+	c.Assert(err, check.IsNil)
+	c.Assert(requests, check.HasLen, 1)
+	c.Assert(requests[0].URL.Path, check.Equals, "/ready")
+}
+
+func (s *S) TestCheckLiveness(c *check.C) {
+	var requests []*http.Request
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	a := app.App{Name: "myapp1"}
+	customData := map[string]interface{}{
+		"liveness": map[string]interface{}{
+			"path":             "/alive",
+			"allowed_failures": 2,
+			"interval_seconds": 1,
+		},
+	}
+	version, err := newVersionForApp(s.p, &a, customData)
+	c.Assert(err, check.IsNil)
+	err = s.conn.Apps().Insert(a)
+	c.Assert(err, check.IsNil)
+	url, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(url.Host)
+	testBaseImage, err := version.BaseImageName()
+	c.Assert(err, check.IsNil)
+	cont := container.Container{Container: types.Container{AppName: a.Name, HostAddr: host, HostPort: port, Image: testBaseImage}}
+	err = s.p.CheckLiveness(cont)
+	c.Assert(err, check.IsNil)
+	c.Assert(requests, check.HasLen, 1)
+	c.Assert(requests[0].URL.Path, check.Equals, "/alive")
+	status = http.StatusInternalServerError
+	err = s.p.CheckLiveness(cont)
+	c.Assert(err, check.ErrorMatches, "healthcheck fail.*wrong status code, expected 200, got: 500")
+	c.Assert(requests, check.HasLen, 3)
+	cont.HostPort = ""
+	err = s.p.CheckLiveness(cont)
+	c.Assert(err, check.IsNil)
+	c.Assert(requests, check.HasLen, 3)
+}
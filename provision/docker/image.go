@@ -55,7 +55,7 @@ func MigrateImages() error {
 				Name:              newImage,
 				InactivityTimeout: net.StreamInactivityTimeout,
 			}
-			err = dcluster.PushImage(pushOpts, dockercommon.RegistryAuthConfig(newImage))
+			err = dcluster.PushImage(pushOpts, dockercommon.RegistryAuthConfig(app.GetPool(), newImage))
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,136 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/action"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+	"github.com/tsuru/tsuru/servicemanager"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+const initStepLogSource = "init"
+
+// runInitSteps runs the init steps with a custom image, in order, on the
+// node of the unit before it's started. Steps without an image are run by
+// the unit container itself, before the app process.
+var runInitSteps = action.Action{
+	Name: "run-init-steps",
+	Forward: func(ctx action.FWContext) (action.Result, error) {
+		args := ctx.Params[0].(runContainerActionsArgs)
+		c := ctx.Previous.(*container.Container)
+		for _, step := range args.initSteps {
+			if err := checkCanceled(args.event); err != nil {
+				return nil, err
+			}
+			err := args.provisioner.runInitStep(ctx.Context, args, c, step)
+			if err != nil {
+				log.Errorf("error running init step %q for container %s - %s", step.Name, c.ID, err)
+				return nil, err
+			}
+		}
+		return c, nil
+	},
+	Backward: func(ctx action.BWContext) {
+	},
+}
+
+func (p *dockerProvisioner) runInitStep(ctx context.Context, args runContainerActionsArgs, c *container.Container, step provTypes.TsuruYamlInitStep) error {
+	node, err := dockercommon.GetNodeByHost(p.Cluster(), c.HostAddr)
+	if err != nil {
+		return err
+	}
+	var envs []string
+	for _, e := range provision.EnvsForApp(args.app, args.processName, false, args.version) {
+		envs = append(envs, fmt.Sprintf("%s=%s", e.Name, e.Value))
+	}
+	createOptions := docker.CreateContainerOptions{
+		Config: &docker.Config{
+			AttachStdout: true,
+			AttachStderr: true,
+			Image:        step.Image,
+			Env:          envs,
+		},
+		Context: ctx,
+	}
+	if step.Command != "" {
+		createOptions.Config.Cmd = []string{"/bin/sh", "-c", step.Command}
+	}
+	pullOpts := docker.PullImageOptions{
+		Repository:        step.Image,
+		InactivityTimeout: net.StreamInactivityTimeout,
+		Context:           ctx,
+	}
+	cluster := p.Cluster()
+	_, cont, err := cluster.CreateContainerPullOptsSchedulerOpts(
+		createOptions,
+		pullOpts,
+		dockercommon.RegistryAuthConfig(args.app.GetPool(), step.Image),
+		nil,
+		node.Address,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create container for init step %q", step.Name)
+	}
+	defer func() {
+		done := p.ActionLimiter().Start(c.HostAddr)
+		cluster.RemoveContainer(docker.RemoveContainerOptions{ID: cont.ID, Force: true})
+		done()
+	}()
+	err = cluster.StartContainer(cont.ID, nil)
+	if err != nil {
+		return errors.Wrapf(err, "unable to start container for init step %q", step.Name)
+	}
+	status, err := cluster.WaitContainer(cont.ID)
+	if err != nil {
+		return errors.Wrapf(err, "unable to wait for init step %q", step.Name)
+	}
+	var output bytes.Buffer
+	err = cluster.Logs(docker.LogsOptions{
+		Context:      ctx,
+		Container:    cont.ID,
+		OutputStream: &output,
+		ErrorStream:  &output,
+		Stdout:       true,
+		Stderr:       true,
+	})
+	if err != nil {
+		log.Errorf("unable to read logs of init step %q for container %s: %s", step.Name, c.ID, err)
+	}
+	logInitStepOutput(args.app.GetName(), c.ShortID(), step.Name, &output, args.writer)
+	if status != 0 {
+		return errors.Errorf("init step %q exited with status %d", step.Name, status)
+	}
+	return nil
+}
+
+// logInitStepOutput attaches the output of an init step to the logs of the
+// unit, also copying it to w when it's not nil.
+func logInitStepOutput(appName, unit, stepName string, output io.Reader, w io.Writer) {
+	header := fmt.Sprintf("---- Running init step %s ----", stepName)
+	lines := []string{header}
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	for _, line := range lines {
+		if w != nil {
+			fmt.Fprintln(w, line)
+		}
+		servicemanager.AppLog.Add(appName, line, initStepLogSource, unit)
+	}
+}
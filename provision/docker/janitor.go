@@ -0,0 +1,307 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/api/tracker"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/event"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/permission"
+	"github.com/tsuru/tsuru/provision"
+	permTypes "github.com/tsuru/tsuru/types/permission"
+)
+
+const (
+	janitorReportsCollectionName = "docker_janitor_reports"
+	janitorEventKind             = "node-janitor"
+
+	defaultJanitorInterval = 6 * time.Hour
+	defaultJanitorMaxAge   = 7
+)
+
+// JanitorReport is the result of the last cleanup of a node.
+type JanitorReport struct {
+	Node              string    `bson:"_id" json:"node"`
+	Pool              string    `json:"pool"`
+	Time              time.Time `json:"time"`
+	MaxAgeDays        int       `json:"maxAgeDays"`
+	ContainersRemoved int       `json:"containersRemoved"`
+	ImagesRemoved     int       `json:"imagesRemoved"`
+	BuildCacheRemoved int       `json:"buildCacheRemoved"`
+	SpaceReclaimed    int64     `json:"spaceReclaimed"`
+	Error             string    `json:"error,omitempty"`
+}
+
+// janitorMaxAgeForPool returns the age, in days, of the exited containers,
+// dangling images and builder cache removed from the nodes of the pool, set
+// in docker:janitor:pool-max-age:<pool>, falling back to
+// docker:janitor:max-age. Zero disables the cleanup in the pool.
+func janitorMaxAgeForPool(pool string) int {
+	if pool != "" {
+		days, err := config.GetInt("docker:janitor:pool-max-age:" + pool)
+		if err == nil {
+			return days
+		}
+	}
+	days, err := config.GetInt("docker:janitor:max-age")
+	if err != nil {
+		return defaultJanitorMaxAge
+	}
+	return days
+}
+
+func janitorReportsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection(janitorReportsCollectionName), nil
+}
+
+// JanitorReports returns the last cleanup report of each node in the pool.
+func JanitorReports(pool string) ([]JanitorReport, error) {
+	coll, err := janitorReportsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var reports []JanitorReport
+	err = coll.Find(bson.M{"pool": pool}).Sort("_id").All(&reports)
+	return reports, err
+}
+
+type nodeJanitor struct {
+	p        *dockerProvisioner
+	interval time.Duration
+	shutdown chan struct{}
+	done     chan struct{}
+}
+
+func (j *nodeJanitor) start() {
+	j.shutdown = make(chan struct{})
+	j.done = make(chan struct{})
+	go func() {
+		defer close(j.done)
+		for {
+			j.run()
+			select {
+			case <-time.After(j.interval):
+			case <-j.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (j *nodeJanitor) Shutdown(ctx context.Context) error {
+	close(j.shutdown)
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
+
+func (j *nodeJanitor) run() {
+	if !tracker.IsLeader("docker-janitor") {
+		return
+	}
+	nodes, err := j.p.Cluster().Nodes()
+	if err != nil {
+		log.Errorf("[janitor] unable to list nodes: %s", err)
+		return
+	}
+	for _, node := range nodes {
+		select {
+		case <-j.shutdown:
+			return
+		default:
+		}
+		pool := node.Metadata[provision.PoolMetadataName]
+		maxAge := janitorMaxAgeForPool(pool)
+		if maxAge <= 0 {
+			continue
+		}
+		err = j.p.cleanupNode(node, maxAge, j.interval)
+		if err != nil {
+			log.Errorf("[janitor] unable to clean up node %s: %s", node.Address, err)
+		}
+	}
+}
+
+// cleanupNode removes the exited tsuru containers not tracked as units, the
+// dangling images and the builder cache older than maxAge days from the
+// node. The cleanup holds a lock on the node and is skipped when the node
+// was cleaned up less than interval ago, possibly by another API instance.
+func (p *dockerProvisioner) cleanupNode(node cluster.Node, maxAge int, interval time.Duration) error {
+	pool := node.Metadata[provision.PoolMetadataName]
+	coll, err := janitorReportsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	var last JanitorReport
+	err = coll.FindId(node.Address).One(&last)
+	if err == nil && time.Since(last.Time) < interval {
+		return nil
+	}
+	evt, err := event.NewInternal(&event.Opts{
+		Target:       event.Target{Type: event.TargetTypeNode, Value: node.Address},
+		InternalKind: janitorEventKind,
+		Allowed:      event.Allowed(permission.PermPoolReadEvents, permission.Context(permTypes.CtxPool, pool)),
+	})
+	if err != nil {
+		if _, ok := err.(event.ErrEventLocked); ok {
+			return nil
+		}
+		return err
+	}
+	report := JanitorReport{Node: node.Address, Pool: pool, MaxAgeDays: maxAge}
+	cleanupErr := p.pruneNode(node, maxAge, &report)
+	report.Time = time.Now().UTC()
+	if cleanupErr != nil {
+		report.Error = cleanupErr.Error()
+	}
+	_, err = coll.UpsertId(node.Address, report)
+	if err != nil {
+		log.Errorf("[janitor] unable to store report of node %s: %s", node.Address, err)
+	}
+	return evt.DoneCustomData(cleanupErr, report)
+}
+
+func (p *dockerProvisioner) pruneNode(node cluster.Node, maxAge int, report *JanitorReport) error {
+	client, err := node.Client()
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-time.Duration(maxAge) * 24 * time.Hour)
+	err = p.pruneContainers(client, cutoff, report)
+	if err != nil {
+		return errors.Wrap(err, "unable to remove exited containers")
+	}
+	until := strconv.Itoa(maxAge*24) + "h"
+	images, err := client.PruneImages(docker.PruneImagesOptions{
+		Filters: map[string][]string{"dangling": {"true"}, "until": {until}},
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to remove dangling images")
+	}
+	report.ImagesRemoved = len(images.ImagesDeleted)
+	report.SpaceReclaimed += images.SpaceReclaimed
+	err = pruneBuildCache(client, until, report)
+	if err != nil {
+		return errors.Wrap(err, "unable to remove builder cache")
+	}
+	return nil
+}
+
+// pruneContainers removes the exited containers labeled as tsuru containers
+// created before cutoff. Containers of stopped units are exited too, so
+// containers tracked as units are kept, as well as node containers.
+func (p *dockerProvisioner) pruneContainers(client *docker.Client, cutoff time.Time, report *JanitorReport) error {
+	containers, err := client.ListContainers(docker.ListContainersOptions{
+		All:  true,
+		Size: true,
+		Filters: map[string][]string{
+			"status": {"exited", "dead"},
+			"label":  {"is-tsuru=true"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	var candidates []docker.APIContainers
+	var ids []string
+	for _, c := range containers {
+		if c.State != "exited" && c.State != "dead" {
+			continue
+		}
+		if c.Labels["is-node-container"] == "true" || time.Unix(c.Created, 0).After(cutoff) {
+			continue
+		}
+		candidates = append(candidates, c)
+		ids = append(ids, c.ID)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	units, err := p.ListContainers(bson.M{"id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	tracked := make(map[string]struct{}, len(units))
+	for _, u := range units {
+		tracked[u.ID] = struct{}{}
+	}
+	for _, c := range candidates {
+		if _, ok := tracked[c.ID]; ok {
+			continue
+		}
+		err = client.RemoveContainer(docker.RemoveContainerOptions{ID: c.ID, RemoveVolumes: true})
+		if err != nil {
+			log.Errorf("[janitor] unable to remove container %s: %s", c.ID, err)
+			continue
+		}
+		report.ContainersRemoved++
+		report.SpaceReclaimed += c.SizeRw
+	}
+	return nil
+}
+
+// pruneBuildCache removes the builder cache not used in the given period.
+// The docker client has no support for it, so the API is called directly.
+// Daemons without the endpoint are ignored.
+func pruneBuildCache(client *docker.Client, until string, report *JanitorReport) error {
+	filters, err := json.Marshal(map[string][]string{"until": {until}})
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/build/prune?filters=%s", client.Endpoint(), url.QueryEscape(string(filters)))
+	req, err := http.NewRequest(http.MethodPost, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	var result struct {
+		CachesDeleted  []string
+		SpaceReclaimed int64
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return err
+	}
+	report.BuildCacheRemoved = len(result.CachesDeleted)
+	report.SpaceReclaimed += result.SpaceReclaimed
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) createJanitorContainer(c *check.C, client *docker.Client, name string, labels map[string]string, stop bool) string {
+	cont, err := client.CreateContainer(docker.CreateContainerOptions{
+		Name:   name,
+		Config: &docker.Config{Image: "tsuru/python", Labels: labels},
+	})
+	c.Assert(err, check.IsNil)
+	err = client.StartContainer(cont.ID, nil)
+	c.Assert(err, check.IsNil)
+	if stop {
+		err = client.StopContainer(cont.ID, 10)
+		c.Assert(err, check.IsNil)
+	}
+	return cont.ID
+}
+
+func (s *S) TestJanitorMaxAgeForPool(c *check.C) {
+	c.Assert(janitorMaxAgeForPool("mypool"), check.Equals, defaultJanitorMaxAge)
+	config.Set("docker:janitor:max-age", 3)
+	config.Set("docker:janitor:pool-max-age:mypool", 0)
+	defer config.Unset("docker:janitor")
+	c.Assert(janitorMaxAgeForPool("mypool"), check.Equals, 0)
+	c.Assert(janitorMaxAgeForPool("otherpool"), check.Equals, 3)
+}
+
+func (s *S) TestCleanupNode(c *check.C) {
+	client, err := docker.NewClient(s.server.URL())
+	c.Assert(err, check.IsNil)
+	err = client.PullImage(docker.PullImageOptions{Repository: "tsuru/python"}, docker.AuthConfiguration{})
+	c.Assert(err, check.IsNil)
+	tsuruLabels := map[string]string{"is-tsuru": "true"}
+	exited := s.createJanitorContainer(c, client, "exited", tsuruLabels, true)
+	stoppedUnit := s.createJanitorContainer(c, client, "stopped-unit", tsuruLabels, true)
+	running := s.createJanitorContainer(c, client, "running", tsuruLabels, false)
+	notTsuru := s.createJanitorContainer(c, client, "not-tsuru", nil, true)
+	coll := s.p.Collection()
+	defer coll.Close()
+	err = coll.Insert(container.Container{Container: types.Container{ID: stoppedUnit, AppName: "myapp"}})
+	c.Assert(err, check.IsNil)
+	var calls int32
+	s.server.CustomHandler("/images/prune", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ImagesDeleted": [{"Deleted": "sha256:abc"}], "SpaceReclaimed": 100}`))
+	}))
+	s.server.CustomHandler("/build/prune", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"CachesDeleted": ["a", "b"], "SpaceReclaimed": 50}`))
+	}))
+	node := cluster.Node{Address: s.server.URL(), Metadata: map[string]string{"pool": "test-default"}}
+	err = s.p.cleanupNode(node, 0, time.Hour)
+	c.Assert(err, check.IsNil)
+	_, err = client.InspectContainer(exited)
+	c.Assert(err, check.FitsTypeOf, &docker.NoSuchContainer{})
+	for _, id := range []string{stoppedUnit, running, notTsuru} {
+		_, err = client.InspectContainer(id)
+		c.Assert(err, check.IsNil)
+	}
+	reports, err := JanitorReports("test-default")
+	c.Assert(err, check.IsNil)
+	c.Assert(reports, check.HasLen, 1)
+	c.Assert(reports[0].Node, check.Equals, s.server.URL())
+	c.Assert(reports[0].ContainersRemoved, check.Equals, 1)
+	c.Assert(reports[0].ImagesRemoved, check.Equals, 1)
+	c.Assert(reports[0].BuildCacheRemoved, check.Equals, 2)
+	c.Assert(reports[0].SpaceReclaimed, check.Equals, int64(150))
+	c.Assert(reports[0].Error, check.Equals, "")
+	err = s.p.cleanupNode(node, 0, time.Hour)
+	c.Assert(err, check.IsNil)
+	c.Assert(atomic.LoadInt32(&calls), check.Equals, int32(1))
+}
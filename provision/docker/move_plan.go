@@ -0,0 +1,228 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision/docker/container"
+)
+
+const (
+	movePlansCollectionName = "docker_move_plans"
+
+	defaultParallelMoves = 10
+)
+
+// MovedUnit is a unit moved away from the origin node of a move plan.
+type MovedUnit struct {
+	Unit    string `json:"unit"`
+	NewUnit string `json:"newUnit"`
+	App     string `json:"app"`
+	To      string `json:"to"`
+}
+
+// FailedMove is a unit that couldn't be moved, it stays in the origin node
+// and is moved again by the next move from the node.
+type FailedMove struct {
+	Unit  string `json:"unit"`
+	Error string `json:"error"`
+}
+
+// MovePlan tracks the units being moved away from a node. Units are removed
+// from Pending as they're moved, so an interrupted move can be resumed by
+// moving the containers again, keeping the progress made so far.
+type MovePlan struct {
+	From       string       `bson:"_id" json:"from"`
+	To         string       `json:"to,omitempty"`
+	StartedAt  time.Time    `json:"startedAt"`
+	UpdatedAt  time.Time    `json:"updatedAt"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	Total      int          `json:"total"`
+	Pending    []string     `json:"pending"`
+	Moved      []MovedUnit  `json:"moved"`
+	Failed     []FailedMove `json:"failed"`
+	Resumed    int          `json:"resumed"`
+}
+
+// Finished returns whether every unit in the plan was moved or the move
+// ended with errors.
+func (p *MovePlan) Finished() bool {
+	return !p.FinishedAt.IsZero()
+}
+
+func movePlansCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Collection(movePlansCollectionName), nil
+}
+
+// GetMovePlan returns the plan of the last move of units from the host.
+func GetMovePlan(fromHost string) (*MovePlan, error) {
+	coll, err := movePlansCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var plan MovePlan
+	err = coll.FindId(fromHost).One(&plan)
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// startMovePlan creates the plan for moving the containers, resuming the
+// unfinished plan from the same host, if there's one.
+func startMovePlan(fromHost, toHost string, containers []container.Container) (*MovePlan, error) {
+	coll, err := movePlansCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	pending := make([]string, len(containers))
+	for i, c := range containers {
+		pending[i] = c.ID
+	}
+	now := time.Now().UTC()
+	var plan MovePlan
+	err = coll.FindId(fromHost).One(&plan)
+	if err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	if err == nil && !plan.Finished() {
+		plan.Resumed++
+	} else {
+		plan = MovePlan{From: fromHost, StartedAt: now, Moved: []MovedUnit{}}
+	}
+	plan.To = toHost
+	plan.UpdatedAt = now
+	plan.Pending = pending
+	plan.Failed = []FailedMove{}
+	plan.Total = len(plan.Moved) + len(pending)
+	_, err = coll.UpsertId(fromHost, plan)
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (p *MovePlan) recordMove(old, added container.Container) error {
+	return p.update(bson.M{
+		"$pull": bson.M{"pending": old.ID},
+		"$push": bson.M{"moved": MovedUnit{Unit: old.ID, NewUnit: added.ID, App: old.AppName, To: added.HostAddr}},
+		"$set":  bson.M{"updatedat": time.Now().UTC()},
+	})
+}
+
+func (p *MovePlan) recordFailure(c container.Container, moveErr error) error {
+	return p.update(bson.M{
+		"$pull": bson.M{"pending": c.ID},
+		"$push": bson.M{"failed": FailedMove{Unit: c.ID, Error: moveErr.Error()}},
+		"$set":  bson.M{"updatedat": time.Now().UTC()},
+	})
+}
+
+// finish marks the plan as finished, unless the move was interrupted, in
+// which case the plan is kept to be resumed.
+func (p *MovePlan) finish(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	return p.update(bson.M{"$set": bson.M{"updatedat": now, "finishedat": now}})
+}
+
+func (p *MovePlan) update(update bson.M) error {
+	coll, err := movePlansCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	return coll.UpdateId(p.From, update)
+}
+
+func parallelMovesLimit() int {
+	limit, err := config.GetInt("docker:limit:parallel-moves")
+	if err != nil || limit <= 0 {
+		return defaultParallelMoves
+	}
+	return limit
+}
+
+// moveLimiterAction is the action used to limit the moves to a host. It
+// differs from the host address, used to limit the container creations
+// themselves, so that a move holding a slot never waits for itself.
+func moveLimiterAction(host string) string {
+	return "move-to:" + host
+}
+
+// moveContainersWithPlan moves the containers with bounded parallelism,
+// recording each move in the plan. When toHost is set, moves to the host are
+// also bounded by the action limiter. No new moves are started once ctx is
+// done, leaving the remaining units pending in the plan.
+func (p *dockerProvisioner) moveContainersWithPlan(ctx context.Context, containers []container.Container, toHost string, writer io.Writer, plan *MovePlan) error {
+	locker := &appLocker{}
+	moveErrors := make(chan error, len(containers)+1)
+	sem := make(chan struct{}, parallelMovesLimit())
+	wg := sync.WaitGroup{}
+	for _, c := range containers {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(c container.Container) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if toHost != "" {
+				done := p.ActionLimiter().Start(moveLimiterAction(toHost))
+				defer done()
+			}
+			errCh := make(chan error, 1)
+			added := p.MoveOneContainer(ctx, c, toHost, errCh, nil, writer, locker)
+			close(errCh)
+			var recordErr error
+			if moveErr := <-errCh; moveErr != nil {
+				moveErrors <- moveErr
+				recordErr = plan.recordFailure(c, moveErr)
+			} else {
+				recordErr = plan.recordMove(c, added)
+			}
+			if recordErr != nil {
+				log.Errorf("[move plan] unable to record move of unit %s: %s", c.ID, recordErr)
+			}
+		}(c)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		moveErrors <- fmt.Errorf("move interrupted, %d units left to move: %w", plan.remaining(), ctx.Err())
+	}
+	close(moveErrors)
+	return p.HandleMoveErrors(moveErrors, writer)
+}
+
+func (p *MovePlan) remaining() int {
+	plan, err := GetMovePlan(p.From)
+	if err != nil {
+		return len(p.Pending)
+	}
+	return len(plan.Pending)
+}
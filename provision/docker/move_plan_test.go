@@ -0,0 +1,132 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/safe"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestMoveContainersRecordsPlan(c *check.C) {
+	config.Set("docker:limit:parallel-moves", 1)
+	defer config.Unset("docker:limit:parallel-moves")
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	defer p.Destroy(context.TODO(), appInstance)
+	p.Provision(context.TODO(), appInstance)
+	version, err := newSuccessfulVersionForApp(p, appInstance, nil)
+	c.Assert(err, check.IsNil)
+	added, err := addContainersWithHost(context.TODO(), &changeUnitsPipelineArgs{
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 3}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	err = s.conn.Apps().Insert(s.newAppFromFake(appInstance))
+	c.Assert(err, check.IsNil)
+	err = p.MoveContainers(context.TODO(), "localhost", "127.0.0.1", safe.NewBuffer(nil))
+	c.Assert(err, check.IsNil)
+	plan, err := GetMovePlan("localhost")
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Finished(), check.Equals, true)
+	c.Assert(plan.To, check.Equals, "127.0.0.1")
+	c.Assert(plan.Total, check.Equals, 3)
+	c.Assert(plan.Pending, check.HasLen, 0)
+	c.Assert(plan.Failed, check.HasLen, 0)
+	c.Assert(plan.Moved, check.HasLen, 3)
+	moved := map[string]bool{}
+	for _, m := range plan.Moved {
+		c.Assert(m.App, check.Equals, "myapp")
+		c.Assert(m.To, check.Equals, "127.0.0.1")
+		moved[m.Unit] = true
+	}
+	for _, cont := range added {
+		c.Assert(moved[cont.ID], check.Equals, true)
+	}
+}
+
+func (s *S) TestMoveContainersResumesInterruptedPlan(c *check.C) {
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	defer p.Destroy(context.TODO(), appInstance)
+	p.Provision(context.TODO(), appInstance)
+	version, err := newSuccessfulVersionForApp(p, appInstance, nil)
+	c.Assert(err, check.IsNil)
+	_, err = addContainersWithHost(context.TODO(), &changeUnitsPipelineArgs{
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 2}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	err = s.conn.Apps().Insert(s.newAppFromFake(appInstance))
+	c.Assert(err, check.IsNil)
+	startedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Millisecond)
+	coll, err := movePlansCollection()
+	c.Assert(err, check.IsNil)
+	defer coll.Close()
+	err = coll.Insert(MovePlan{
+		From:      "localhost",
+		To:        "127.0.0.1",
+		StartedAt: startedAt,
+		Total:     3,
+		Pending:   []string{"a", "b"},
+		Moved:     []MovedUnit{{Unit: "old", NewUnit: "new", App: "myapp", To: "127.0.0.1"}},
+	})
+	c.Assert(err, check.IsNil)
+	buf := safe.NewBuffer(nil)
+	err = p.MoveContainers(context.TODO(), "localhost", "127.0.0.1", buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, "(?s)Resuming interrupted move, 1 of 3 units already moved.*Moving 2 units.*")
+	plan, err := GetMovePlan("localhost")
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Finished(), check.Equals, true)
+	c.Assert(plan.Resumed, check.Equals, 1)
+	c.Assert(plan.StartedAt.Equal(startedAt), check.Equals, true)
+	c.Assert(plan.Total, check.Equals, 3)
+	c.Assert(plan.Pending, check.HasLen, 0)
+	c.Assert(plan.Moved, check.HasLen, 3)
+}
+
+func (s *S) TestMoveContainersInterruptedKeepsPlan(c *check.C) {
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	defer p.Destroy(context.TODO(), appInstance)
+	p.Provision(context.TODO(), appInstance)
+	version, err := newSuccessfulVersionForApp(p, appInstance, nil)
+	c.Assert(err, check.IsNil)
+	_, err = addContainersWithHost(context.TODO(), &changeUnitsPipelineArgs{
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 2}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	err = s.conn.Apps().Insert(s.newAppFromFake(appInstance))
+	c.Assert(err, check.IsNil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = p.MoveContainers(ctx, "localhost", "127.0.0.1", safe.NewBuffer(nil))
+	c.Assert(err, check.ErrorMatches, "(?s).*move interrupted, 2 units left to move.*")
+	plan, err := GetMovePlan("localhost")
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Finished(), check.Equals, false)
+	c.Assert(plan.Pending, check.HasLen, 2)
+	containers, err := p.listContainersByHost("localhost")
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 2)
+}
@@ -128,7 +128,7 @@ func ensureContainersStarted(p DockerProvisioner, w io.Writer, relaunch bool, na
 
 func pullImage(c *nodecontainer.NodeContainerConfig, client *docker.Client, p DockerProvisioner, pool string) (string, error) {
 	image := c.Image()
-	output, err := pullWithRetry(client, p, image, 3)
+	output, err := pullWithRetry(client, p, pool, image, 3)
 	if err != nil {
 		return "", err
 	}
@@ -198,11 +198,11 @@ func tryRemovingOld(client *docker.Client, id string) error {
 	return err
 }
 
-func pullWithRetry(client *docker.Client, p DockerProvisioner, image string, maxTries int) (string, error) {
+func pullWithRetry(client *docker.Client, p DockerProvisioner, pool, image string, maxTries int) (string, error) {
 	var buf bytes.Buffer
 	var err error
 	pullOpts := docker.PullImageOptions{Repository: image, OutputStream: &buf, InactivityTimeout: net.StreamInactivityTimeout}
-	registryAuth := dockercommon.RegistryAuthConfig(image)
+	registryAuth := dockercommon.RegistryAuthConfig(pool, image)
 	for ; maxTries > 0; maxTries-- {
 		err = client.PullImage(pullOpts, registryAuth)
 		if err == nil {
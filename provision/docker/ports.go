@@ -0,0 +1,293 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+)
+
+const (
+	exposedPortsCollectionName = "docker_exposed_ports"
+	defaultExposedPortsMin     = 30000
+	defaultExposedPortsMax     = 32767
+)
+
+var (
+	_ provision.ExposedPortsProvisioner = &dockerProvisioner{}
+
+	portNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// exposedPort is the node port allocated to a port exposed by an app
+// process. Allocations are kept until the app is removed, so the port stays
+// the same across deploys and unit moves.
+type exposedPort struct {
+	ID       string `bson:"_id"`
+	App      string
+	Process  string
+	Port     int
+	Protocol string
+	HostPort int
+}
+
+func exposedPortID(appName, process string, port provTypes.TsuruYamlExposedPort) string {
+	return fmt.Sprintf("%s/%s/%d/%s", appName, process, port.Port, port.Protocol)
+}
+
+func exposedPortsCollection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection(exposedPortsCollectionName)
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"hostport"}, Unique: true})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+func exposedPortsRange() (int, int, error) {
+	min, err := config.GetInt("docker:exposed-ports:min-port")
+	if err != nil {
+		min = defaultExposedPortsMin
+	}
+	max, err := config.GetInt("docker:exposed-ports:max-port")
+	if err != nil {
+		max = defaultExposedPortsMax
+	}
+	if min <= 0 || max > 65535 || min > max {
+		return 0, 0, errors.Errorf("invalid exposed ports range %d-%d", min, max)
+	}
+	return min, max, nil
+}
+
+func validateExposedPort(port provTypes.TsuruYamlExposedPort) error {
+	if port.Port <= 0 || port.Port > 65535 {
+		return errors.Errorf("invalid exposed port %d for process %q", port.Port, port.Process)
+	}
+	if port.Protocol != "tcp" && port.Protocol != "udp" {
+		return errors.Errorf("invalid protocol %q for exposed port %d of process %q, must be tcp or udp", port.Protocol, port.Port, port.Process)
+	}
+	return nil
+}
+
+// namedPortsForProcess returns the named ports declared for the process in
+// tsuru.yaml. Their names are used in the route prefixes of each port, so
+// they must be valid DNS labels.
+func namedPortsForProcess(yamlData provTypes.TsuruYamlData, process string) ([]types.NamedPort, error) {
+	ports := yamlData.Ports[process]
+	if len(ports) == 0 {
+		return nil, nil
+	}
+	names := make(map[string]struct{}, len(ports))
+	namedPorts := make([]types.NamedPort, 0, len(ports))
+	for _, port := range ports {
+		if !portNameRegexp.MatchString(port.Name) {
+			return nil, errors.Errorf("invalid name %q for port %d of process %q, must be a valid DNS label", port.Name, port.Port, process)
+		}
+		if _, ok := names[port.Name]; ok {
+			return nil, errors.Errorf("duplicated port name %q in process %q", port.Name, process)
+		}
+		names[port.Name] = struct{}{}
+		if port.Port <= 0 || port.Port > 65535 {
+			return nil, errors.Errorf("invalid port %d for process %q", port.Port, process)
+		}
+		namedPorts = append(namedPorts, types.NamedPort{Name: port.Name, Port: port.Port})
+	}
+	return namedPorts, nil
+}
+
+// allocateExposedPorts returns the node ports for the ports exposed by the
+// process, allocating the ones still missing from the configured range.
+func allocateExposedPorts(appName, process string, ports []provTypes.TsuruYamlExposedPort) ([]types.PublishedPort, error) {
+	if len(ports) == 0 {
+		return nil, nil
+	}
+	coll, err := exposedPortsCollection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	published := make([]types.PublishedPort, 0, len(ports))
+	for _, port := range ports {
+		err = validateExposedPort(port)
+		if err != nil {
+			return nil, err
+		}
+		var allocated exposedPort
+		allocated, err = allocateExposedPort(coll, appName, process, port)
+		if err != nil {
+			return nil, err
+		}
+		published = append(published, types.PublishedPort{
+			Port:     port.Port,
+			Protocol: port.Protocol,
+			HostPort: allocated.HostPort,
+		})
+	}
+	return published, nil
+}
+
+func allocateExposedPort(coll *storage.Collection, appName, process string, port provTypes.TsuruYamlExposedPort) (exposedPort, error) {
+	id := exposedPortID(appName, process, port)
+	min, max, err := exposedPortsRange()
+	if err != nil {
+		return exposedPort{}, err
+	}
+	for {
+		var allocated exposedPort
+		err = coll.FindId(id).One(&allocated)
+		if err == nil {
+			return allocated, nil
+		}
+		if err != mgo.ErrNotFound {
+			return exposedPort{}, err
+		}
+		var used []exposedPort
+		err = coll.Find(bson.M{"hostport": bson.M{"$gte": min, "$lte": max}}).Select(bson.M{"hostport": 1}).All(&used)
+		if err != nil {
+			return exposedPort{}, err
+		}
+		usedMap := make(map[int]struct{}, len(used))
+		for _, u := range used {
+			usedMap[u.HostPort] = struct{}{}
+		}
+		hostPort := 0
+		for p := min; p <= max; p++ {
+			if _, ok := usedMap[p]; !ok {
+				hostPort = p
+				break
+			}
+		}
+		if hostPort == 0 {
+			return exposedPort{}, errors.Errorf("no free port left in exposed ports range %d-%d", min, max)
+		}
+		allocated = exposedPort{
+			ID:       id,
+			App:      appName,
+			Process:  process,
+			Port:     port.Port,
+			Protocol: port.Protocol,
+			HostPort: hostPort,
+		}
+		err = coll.Insert(allocated)
+		if err == nil {
+			return allocated, nil
+		}
+		// Either the port was allocated to this process or the host port was
+		// taken by another one in the meantime, both are solved by retrying.
+		if !mgo.IsDup(err) {
+			return exposedPort{}, err
+		}
+	}
+}
+
+func releaseExposedPorts(appName string) error {
+	coll, err := exposedPortsCollection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.RemoveAll(bson.M{"app": appName})
+	return err
+}
+
+// releasePublishedPorts stops the units being replaced which publish ports,
+// as the node ports they hold are needed by the new units. Units exposing
+// ports are therefore replaced without overlap. The stopped units are
+// returned so they can be restarted if the new ones fail.
+func releasePublishedPorts(w io.Writer, args *changeUnitsPipelineArgs) []container.Container {
+	if args.provisioner.isDryMode {
+		return nil
+	}
+	var released []container.Container
+	for _, c := range args.toRemove {
+		if len(c.PublishedPorts) == 0 || c.Status == provision.StatusStopped.String() {
+			continue
+		}
+		err := c.Stop(args.provisioner.ClusterClient(), args.provisioner.ActionLimiter())
+		if err != nil {
+			log.Errorf("unable to stop unit %s to release its exposed ports: %s", c.ID, err)
+			continue
+		}
+		fmt.Fprintf(w, " ---> Stopped unit %s [%s] to release its exposed ports\n", c.ShortID(), c.ProcessName)
+		released = append(released, c)
+	}
+	return released
+}
+
+func restartReleasedUnits(args *changeUnitsPipelineArgs, released []container.Container) {
+	for _, c := range released {
+		err := c.Start(&container.StartArgs{
+			Client:  args.provisioner.ClusterClient(),
+			Limiter: args.provisioner.ActionLimiter(),
+			App:     args.app,
+		})
+		if err != nil {
+			log.Errorf("unable to restart unit %s after failing to replace it: %s", c.ID, err)
+		}
+	}
+}
+
+// ExposedEndpoints returns the addresses where the ports exposed by the app
+// processes are reachable. When docker:exposed-ports:address is set, which
+// is usually a load balancer in front of the nodes, a single endpoint is
+// returned for each port, otherwise each unit is listed with its node.
+func (p *dockerProvisioner) ExposedEndpoints(ctx context.Context, a provision.App) ([]provision.AppExposedEndpoint, error) {
+	containers, err := p.listContainersByApp(a.GetName())
+	if err != nil {
+		return nil, err
+	}
+	lbAddress, _ := config.GetString("docker:exposed-ports:address")
+	var endpoints []provision.AppExposedEndpoint
+	seen := map[string]struct{}{}
+	for _, c := range containers {
+		for _, port := range c.PublishedPorts {
+			endpoint := provision.AppExposedEndpoint{
+				Process:  c.ProcessName,
+				Protocol: port.Protocol,
+				Port:     port.Port,
+			}
+			if lbAddress != "" {
+				endpoint.Address = net.JoinHostPort(lbAddress, strconv.Itoa(port.HostPort))
+				if _, ok := seen[endpoint.Address+"/"+port.Protocol]; ok {
+					continue
+				}
+				seen[endpoint.Address+"/"+port.Protocol] = struct{}{}
+			} else {
+				endpoint.Address = net.JoinHostPort(c.HostAddr, strconv.Itoa(port.HostPort))
+				endpoint.Unit = c.ID
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if endpoints[i].Process != endpoints[j].Process {
+			return endpoints[i].Process < endpoints[j].Process
+		}
+		return endpoints[i].Port < endpoints[j].Port
+	})
+	return endpoints, nil
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"sort"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	provTypes "github.com/tsuru/tsuru/types/provision"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestAllocateExposedPorts(c *check.C) {
+	config.Set("docker:exposed-ports:min-port", 31000)
+	config.Set("docker:exposed-ports:max-port", 31001)
+	defer config.Unset("docker:exposed-ports")
+	ports := []provTypes.TsuruYamlExposedPort{{Process: "db", Port: 5432, Protocol: "tcp"}}
+	published, err := allocateExposedPorts("app1", "db", ports)
+	c.Assert(err, check.IsNil)
+	c.Assert(published, check.DeepEquals, []types.PublishedPort{{Port: 5432, Protocol: "tcp", HostPort: 31000}})
+	published, err = allocateExposedPorts("app1", "db", ports)
+	c.Assert(err, check.IsNil)
+	c.Assert(published, check.DeepEquals, []types.PublishedPort{{Port: 5432, Protocol: "tcp", HostPort: 31000}})
+	published, err = allocateExposedPorts("app2", "db", ports)
+	c.Assert(err, check.IsNil)
+	c.Assert(published, check.DeepEquals, []types.PublishedPort{{Port: 5432, Protocol: "tcp", HostPort: 31001}})
+	_, err = allocateExposedPorts("app3", "db", ports)
+	c.Assert(err, check.ErrorMatches, "no free port left in exposed ports range 31000-31001")
+	err = releaseExposedPorts("app1")
+	c.Assert(err, check.IsNil)
+	published, err = allocateExposedPorts("app3", "db", ports)
+	c.Assert(err, check.IsNil)
+	c.Assert(published, check.DeepEquals, []types.PublishedPort{{Port: 5432, Protocol: "tcp", HostPort: 31000}})
+}
+
+func (s *S) TestAllocateExposedPortsInvalid(c *check.C) {
+	_, err := allocateExposedPorts("app1", "db", []provTypes.TsuruYamlExposedPort{{Process: "db", Port: 70000, Protocol: "tcp"}})
+	c.Assert(err, check.ErrorMatches, `invalid exposed port 70000 for process "db"`)
+	_, err = allocateExposedPorts("app1", "db", []provTypes.TsuruYamlExposedPort{{Process: "db", Port: 5432, Protocol: "sctp"}})
+	c.Assert(err, check.ErrorMatches, `invalid protocol "sctp" for exposed port 5432 of process "db", must be tcp or udp`)
+}
+
+func (s *S) TestAddContainersWithExposedPorts(c *check.C) {
+	ctx := context.TODO()
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	defer p.Destroy(ctx, appInstance)
+	p.Provision(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(p, appInstance, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+		"exposed_ports": []map[string]interface{}{
+			{"process": "web", "port": 53, "protocol": "UDP"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	coll := p.Collection()
+	defer coll.Close()
+	defer coll.RemoveAll(bson.M{"appname": appInstance.GetName()})
+	added, err := addContainersWithHost(ctx, &changeUnitsPipelineArgs{
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 2}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(added, check.HasLen, 2)
+	c.Assert(added[0].PublishedPorts, check.DeepEquals, []types.PublishedPort{{Port: 53, Protocol: "udp", HostPort: 30000}})
+	c.Assert(added[1].PublishedPorts, check.DeepEquals, added[0].PublishedPorts)
+	c.Assert(added[0].HostAddr, check.Not(check.Equals), added[1].HostAddr)
+	_, err = addContainersWithHost(ctx, &changeUnitsPipelineArgs{
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 1}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.ErrorMatches, `(?s).*no nodes available for unit of process "web" exposing ports.*`)
+	endpoints, err := p.ExposedEndpoints(ctx, appInstance)
+	c.Assert(err, check.IsNil)
+	c.Assert(endpoints, check.HasLen, 2)
+	var addrs []string
+	for _, e := range endpoints {
+		c.Assert(e.Process, check.Equals, "web")
+		c.Assert(e.Protocol, check.Equals, "udp")
+		c.Assert(e.Port, check.Equals, 53)
+		addrs = append(addrs, e.Address)
+	}
+	sort.Strings(addrs)
+	c.Assert(addrs, check.DeepEquals, []string{"127.0.0.1:30000", "localhost:30000"})
+	config.Set("docker:exposed-ports:address", "lb.tsuru.io")
+	defer config.Unset("docker:exposed-ports")
+	endpoints, err = p.ExposedEndpoints(ctx, appInstance)
+	c.Assert(err, check.IsNil)
+	c.Assert(endpoints, check.DeepEquals, []provision.AppExposedEndpoint{
+		{Process: "web", Protocol: "udp", Port: 53, Address: "lb.tsuru.io:30000"},
+	})
+}
+
+func (s *S) TestContainerCreatePublishedPorts(c *check.C) {
+	ctx := context.TODO()
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	s.p.Provision(ctx, appInstance)
+	defer s.p.Destroy(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(s.p, appInstance, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"worker": "python worker.py",
+		},
+		"exposed_ports": []map[string]interface{}{
+			{"process": "worker", "port": 5432},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	added, err := addContainersWithHost(ctx, &changeUnitsPipelineArgs{
+		toAdd:       map[string]*containersToAdd{"worker": {Quantity: 1}},
+		app:         appInstance,
+		version:     version,
+		provisioner: s.p,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(added, check.HasLen, 1)
+	dockerContainer, err := s.p.Cluster().InspectContainer(added[0].ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(dockerContainer.HostConfig.PortBindings["5432/tcp"], check.HasLen, 1)
+	c.Assert(dockerContainer.HostConfig.PortBindings["5432/tcp"][0].HostPort, check.Equals, "30000")
+	_, ok := dockerContainer.Config.ExposedPorts["5432/tcp"]
+	c.Assert(ok, check.Equals, true)
+}
+
+func (s *S) TestNamedPortsForProcess(c *check.C) {
+	yamlData := provTypes.TsuruYamlData{
+		Ports: map[string][]provTypes.TsuruYamlProcessPort{
+			"web": {{Name: "http", Port: 8888}, {Name: "grpc", Port: 9000}},
+		},
+	}
+	ports, err := namedPortsForProcess(yamlData, "web")
+	c.Assert(err, check.IsNil)
+	c.Assert(ports, check.DeepEquals, []types.NamedPort{{Name: "http", Port: 8888}, {Name: "grpc", Port: 9000}})
+	ports, err = namedPortsForProcess(yamlData, "worker")
+	c.Assert(err, check.IsNil)
+	c.Assert(ports, check.IsNil)
+	tests := []struct {
+		ports []provTypes.TsuruYamlProcessPort
+		err   string
+	}{
+		{ports: []provTypes.TsuruYamlProcessPort{{Name: "Http", Port: 8888}}, err: `invalid name "Http" for port 8888 of process "web", must be a valid DNS label`},
+		{ports: []provTypes.TsuruYamlProcessPort{{Port: 8888}}, err: `invalid name "" for port 8888 of process "web", must be a valid DNS label`},
+		{ports: []provTypes.TsuruYamlProcessPort{{Name: "http", Port: 8888}, {Name: "http", Port: 9000}}, err: `duplicated port name "http" in process "web"`},
+		{ports: []provTypes.TsuruYamlProcessPort{{Name: "http", Port: 0}}, err: `invalid port 0 for process "web"`},
+	}
+	for _, tt := range tests {
+		_, err = namedPortsForProcess(provTypes.TsuruYamlData{Ports: map[string][]provTypes.TsuruYamlProcessPort{"web": tt.ports}}, "web")
+		c.Assert(err, check.ErrorMatches, tt.err)
+	}
+}
+
+func (s *S) TestRoutableAddressesNamedPorts(c *check.C) {
+	ctx := context.TODO()
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	s.p.Provision(ctx, appInstance)
+	defer s.p.Destroy(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(s.p, appInstance, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+		"ports": map[string]interface{}{
+			"web": []map[string]interface{}{
+				{"name": "http", "port": 8888},
+				{"name": "grpc", "port": 9000},
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	added, err := addContainersWithHost(ctx, &changeUnitsPipelineArgs{
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 2}},
+		app:         appInstance,
+		version:     version,
+		provisioner: s.p,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(added, check.HasLen, 2)
+	for _, cont := range added {
+		c.Assert(cont.ExposedPort, check.Equals, "8888/tcp")
+		c.Assert(cont.NamedPorts, check.HasLen, 2)
+		c.Assert(cont.NamedPorts[0].HostPort, check.Equals, cont.HostPort)
+		c.Assert(cont.NamedPorts[1].HostPort, check.Not(check.Equals), "")
+		dockerContainer, inspectErr := s.p.Cluster().InspectContainer(cont.ID)
+		c.Assert(inspectErr, check.IsNil)
+		c.Assert(dockerContainer.HostConfig.PortBindings["9000/tcp"], check.HasLen, 1)
+	}
+	addrs, err := s.p.RoutableAddresses(ctx, appInstance)
+	c.Assert(err, check.IsNil)
+	c.Assert(addrs, check.HasLen, 3)
+	c.Assert(addrs[0].Prefix, check.Equals, "")
+	c.Assert(addrs[0].Addresses, check.HasLen, 2)
+	c.Assert(addrs[1].Prefix, check.Equals, "grpc.port")
+	c.Assert(addrs[1].Port, check.Equals, "grpc")
+	c.Assert(addrs[2].Prefix, check.Equals, "http.port")
+	c.Assert(addrs[2].Port, check.Equals, "http")
+	var grpcAddrs []string
+	for _, cont := range added {
+		grpcAddrs = append(grpcAddrs, "http://"+cont.HostAddr+":"+cont.NamedPorts[1].HostPort)
+	}
+	var routedAddrs []string
+	for _, u := range addrs[1].Addresses {
+		routedAddrs = append(routedAddrs, u.String())
+	}
+	sort.Strings(grpcAddrs)
+	sort.Strings(routedAddrs)
+	c.Assert(routedAddrs, check.DeepEquals, grpcAddrs)
+	c.Assert(addrs[2].Addresses, check.DeepEquals, addrs[0].Addresses)
+}
+
+func (s *S) TestValidateRoutableProcesses(c *check.C) {
+	yamlData := provTypes.TsuruYamlData{
+		RoutableProcesses: []provTypes.TsuruYamlRoutableProcess{
+			{Process: "api", Hostnames: []string{"api.example.com"}, Port: 9000},
+		},
+	}
+	c.Assert(yamlData.ValidateRoutableProcesses(), check.IsNil)
+	c.Assert(yamlData.RoutableProcess("api"), check.DeepEquals, &yamlData.RoutableProcesses[0])
+	c.Assert(yamlData.RoutableProcess("worker"), check.IsNil)
+	tests := []struct {
+		processes []provTypes.TsuruYamlRoutableProcess
+		err       string
+	}{
+		{processes: []provTypes.TsuruYamlRoutableProcess{{Process: "Api"}}, err: `invalid routable process "Api": .*`},
+		{processes: []provTypes.TsuruYamlRoutableProcess{{Process: "api"}, {Process: "api"}}, err: `duplicated routable process "api"`},
+		{processes: []provTypes.TsuruYamlRoutableProcess{{Process: "api", Port: 70000}}, err: `invalid port 70000 for routable process "api"`},
+		{processes: []provTypes.TsuruYamlRoutableProcess{{Process: "api", Hostnames: []string{"not_valid"}}}, err: `invalid hostname "not_valid" for routable process "api": .*`},
+	}
+	for _, tt := range tests {
+		err := provTypes.TsuruYamlData{RoutableProcesses: tt.processes}.ValidateRoutableProcesses()
+		c.Assert(err, check.ErrorMatches, tt.err)
+	}
+}
+
+func (s *S) TestRoutableAddressesRoutableProcesses(c *check.C) {
+	ctx := context.TODO()
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	s.p.Provision(ctx, appInstance)
+	defer s.p.Destroy(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(s.p, appInstance, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web":    "python myapp.py",
+			"api":    "python api.py",
+			"worker": "python worker.py",
+		},
+		"routable_processes": []map[string]interface{}{
+			{"process": "api", "hostnames": []string{"api.example.com"}, "port": 9000},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	added, err := addContainersWithHost(ctx, &changeUnitsPipelineArgs{
+		toAdd: map[string]*containersToAdd{
+			"web":    {Quantity: 1},
+			"api":    {Quantity: 2},
+			"worker": {Quantity: 1},
+		},
+		app:         appInstance,
+		version:     version,
+		provisioner: s.p,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(added, check.HasLen, 4)
+	for _, cont := range added {
+		if cont.ProcessName == "api" {
+			c.Assert(cont.ExposedPort, check.Equals, "9000/tcp")
+		}
+	}
+	addrs, err := s.p.RoutableAddresses(ctx, appInstance)
+	c.Assert(err, check.IsNil)
+	c.Assert(addrs, check.HasLen, 2)
+	c.Assert(addrs[0].Prefix, check.Equals, "")
+	c.Assert(addrs[0].Addresses, check.HasLen, 1)
+	c.Assert(addrs[1].Prefix, check.Equals, "api.process")
+	c.Assert(addrs[1].Hostnames, check.DeepEquals, []string{"api.example.com"})
+	c.Assert(addrs[1].Addresses, check.HasLen, 2)
+}
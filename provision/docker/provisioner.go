@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"math"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +32,7 @@ import (
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/discovery"
 	"github.com/tsuru/tsuru/provision/docker/container"
 	"github.com/tsuru/tsuru/provision/docker/healer"
 	internalNodeContainer "github.com/tsuru/tsuru/provision/docker/nodecontainer"
@@ -78,20 +80,24 @@ type dockerProvisioner struct {
 }
 
 var (
-	_ provision.Provisioner               = &dockerProvisioner{}
-	_ provision.ExecutableProvisioner     = &dockerProvisioner{}
-	_ provision.SleepableProvisioner      = &dockerProvisioner{}
-	_ provision.MessageProvisioner        = &dockerProvisioner{}
-	_ provision.InitializableProvisioner  = &dockerProvisioner{}
-	_ provision.OptionalLogsProvisioner   = &dockerProvisioner{}
-	_ provision.UnitStatusProvisioner     = &dockerProvisioner{}
-	_ provision.NodeProvisioner           = &dockerProvisioner{}
-	_ provision.NodeRebalanceProvisioner  = &dockerProvisioner{}
-	_ provision.NodeContainerProvisioner  = &dockerProvisioner{}
-	_ provision.UnitFinderProvisioner     = &dockerProvisioner{}
-	_ provision.AppFilterProvisioner      = &dockerProvisioner{}
-	_ provision.BuilderDeploy             = &dockerProvisioner{}
-	_ provision.BuilderDeployDockerClient = &dockerProvisioner{}
+	_ provision.Provisioner                = &dockerProvisioner{}
+	_ provision.ExecutableProvisioner      = &dockerProvisioner{}
+	_ provision.SleepableProvisioner       = &dockerProvisioner{}
+	_ provision.MessageProvisioner         = &dockerProvisioner{}
+	_ provision.InitializableProvisioner   = &dockerProvisioner{}
+	_ provision.OptionalLogsProvisioner    = &dockerProvisioner{}
+	_ provision.UnitStatusProvisioner      = &dockerProvisioner{}
+	_ provision.NodeProvisioner            = &dockerProvisioner{}
+	_ provision.NodeRebalanceProvisioner   = &dockerProvisioner{}
+	_ provision.NodeEvictionProvisioner    = &dockerProvisioner{}
+	_ provision.NodeContainerProvisioner   = &dockerProvisioner{}
+	_ provision.UnitFinderProvisioner      = &dockerProvisioner{}
+	_ provision.AppFilterProvisioner       = &dockerProvisioner{}
+	_ provision.BuilderDeploy              = &dockerProvisioner{}
+	_ provision.BuilderDeployDockerClient  = &dockerProvisioner{}
+	_ provision.RollingRestarter           = &dockerProvisioner{}
+	_ provision.RestartUnitProvisioner     = &dockerProvisioner{}
+	_ provision.RemoveUnitsByIDProvisioner = &dockerProvisioner{}
 )
 
 type hookHealer struct {
@@ -126,10 +132,12 @@ func (p *dockerProvisioner) initDockerCluster() error {
 	}
 	var nodes []cluster.Node
 	TotalMemoryMetadata, _ := config.GetString("docker:scheduler:total-memory-metadata")
+	TotalCPUMetadata, _ := config.GetString("docker:scheduler:total-cpu-metadata")
 	maxUsedMemory, _ := config.GetFloat("docker:scheduler:max-used-memory")
 	p.scheduler = &segregatedScheduler{
 		maxMemoryRatio:      float32(maxUsedMemory),
 		TotalMemoryMetadata: TotalMemoryMetadata,
+		TotalCPUMetadata:    TotalCPUMetadata,
 		provisioner:         p,
 	}
 	caPath, _ := config.GetString("docker:tls:root-path")
@@ -143,16 +151,62 @@ func (p *dockerProvisioner) initDockerCluster() error {
 		p.cluster.Healer = healer
 	}
 	healContainersSeconds, _ := config.GetInt("docker:healing:heal-containers-timeout")
-	if healContainersSeconds > 0 {
+	crashLoopRestarts, _ := config.GetInt("docker:healing:crash-loop-restarts")
+	livenessChecks, _ := config.GetBool("docker:healing:liveness-checks")
+	if healContainersSeconds > 0 || crashLoopRestarts > 0 || livenessChecks {
+		var liveness healer.LivenessChecker
+		if livenessChecks {
+			liveness = p
+		}
+		crashLoopErrorSeconds, err := config.GetInt("docker:healing:crash-loop-error-timeout")
+		if err != nil {
+			crashLoopErrorSeconds = 60
+		}
+		crashLoopMaxHealings, err := config.GetInt("docker:healing:crash-loop-max-healings")
+		if err != nil {
+			crashLoopMaxHealings = 3
+		}
+		crashLoopBackoffSeconds, err := config.GetInt("docker:healing:crash-loop-backoff")
+		if err != nil {
+			crashLoopBackoffSeconds = 60
+		}
 		contHealerInst := healer.NewContainerHealer(healer.ContainerHealerArgs{
 			Provisioner:         p,
 			MaxUnresponsiveTime: time.Duration(healContainersSeconds) * time.Second,
-			Done:                make(chan bool),
-			Locker:              &appLocker{},
+			CrashLoop: healer.CrashLoopConfig{
+				Restarts:     crashLoopRestarts,
+				ErrorTimeout: time.Duration(crashLoopErrorSeconds) * time.Second,
+				MaxHealings:  crashLoopMaxHealings,
+				Backoff:      time.Duration(crashLoopBackoffSeconds) * time.Second,
+			},
+			Liveness: liveness,
+			Startup:  p,
+			Done:     make(chan bool),
+			Locker:   &appLocker{},
 		})
 		shutdown.Register(contHealerInst)
 		go contHealerInst.RunContainerHealer()
 	}
+	janitorEnabled, _ := config.GetBool("docker:janitor:enabled")
+	if janitorEnabled {
+		janitorInterval, _ := config.GetDuration("docker:janitor:interval")
+		if janitorInterval <= 0 {
+			janitorInterval = defaultJanitorInterval
+		}
+		janitor := &nodeJanitor{p: p, interval: janitorInterval}
+		janitor.start()
+		shutdown.Register(janitor)
+	}
+	certRotationEnabled, _ := config.GetBool("docker:tls:cert-rotation:enabled")
+	if certRotationEnabled {
+		certRotationInterval, _ := config.GetDuration("docker:tls:cert-rotation:interval")
+		if certRotationInterval <= 0 {
+			certRotationInterval = defaultCertRotationInterval
+		}
+		certManager := &nodeCertManager{p: p, interval: certRotationInterval}
+		certManager.start()
+		shutdown.Register(certManager)
+	}
 	activeMonitoring, _ := config.GetInt("docker:healing:active-monitoring-interval")
 	if activeMonitoring > 0 {
 		p.cluster.StartActiveMonitoring(time.Duration(activeMonitoring) * time.Second)
@@ -184,6 +238,7 @@ func (p *dockerProvisioner) cloneProvisioner(ignoredContainers []container.Conta
 	overridenProvisioner.scheduler = &segregatedScheduler{
 		maxMemoryRatio:      p.scheduler.maxMemoryRatio,
 		TotalMemoryMetadata: p.scheduler.TotalMemoryMetadata,
+		TotalCPUMetadata:    p.scheduler.TotalCPUMetadata,
 		provisioner:         &overridenProvisioner,
 		ignoredContainers:   containerIds,
 	}
@@ -219,6 +274,7 @@ func (p *dockerProvisioner) dryMode(ignoredContainers []container.Container) (*d
 	overridenProvisioner.scheduler = &segregatedScheduler{
 		maxMemoryRatio:      p.scheduler.maxMemoryRatio,
 		TotalMemoryMetadata: p.scheduler.TotalMemoryMetadata,
+		TotalCPUMetadata:    p.scheduler.TotalCPUMetadata,
 		provisioner:         overridenProvisioner,
 		ignoredContainers:   containerIds,
 	}
@@ -301,12 +357,47 @@ func (p *dockerProvisioner) Restart(ctx context.Context, a provision.App, proces
 	return err
 }
 
+func (p *dockerProvisioner) RollingRestart(ctx context.Context, a provision.App, version appTypes.AppVersion, batchSize int, w io.Writer) error {
+	containers, err := p.listContainersByProcess(a.GetName(), "")
+	if err != nil {
+		return err
+	}
+	if w == nil {
+		w = ioutil.Discard
+	}
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	batches := (len(containers) + batchSize - 1) / batchSize
+	for i := 0; i < batches; i++ {
+		end := (i + 1) * batchSize
+		if end > len(containers) {
+			end = len(containers)
+		}
+		batch := containers[i*batchSize : end]
+		fmt.Fprintf(w, "\n---- Replacing batch %d of %d (%d units) ----\n", i+1, batches, len(batch))
+		toAdd := make(map[string]*containersToAdd)
+		for _, c := range batch {
+			if _, ok := toAdd[c.ProcessName]; !ok {
+				toAdd[c.ProcessName] = &containersToAdd{Quantity: 0}
+			}
+			toAdd[c.ProcessName].Quantity++
+			toAdd[c.ProcessName].Status = provision.StatusStarted
+		}
+		_, err = p.runReplaceUnitsPipeline(ctx, w, a, toAdd, batch, version)
+		if err != nil {
+			return errors.Wrapf(err, "error replacing batch %d of %d", i+1, batches)
+		}
+	}
+	return nil
+}
+
 func (p *dockerProvisioner) Start(ctx context.Context, app provision.App, process string, _ appTypes.AppVersion, w io.Writer) error {
 	containers, err := p.listContainersByProcess(app.GetName(), process)
 	if err != nil {
 		return errors.New(fmt.Sprintf("Got error while getting app containers: %s", err))
 	}
-	err = runInContainers(containers, func(c *container.Container, _ chan *container.Container) error {
+	err = runInContainersByNode(containers, func(c *container.Container) error {
 		startErr := c.Start(&container.StartArgs{
 			Client:  p.ClusterClient(),
 			Limiter: p.ActionLimiter(),
@@ -317,10 +408,12 @@ func (p *dockerProvisioner) Start(ctx context.Context, app provision.App, proces
 		}
 		c.SetStatus(p.ClusterClient(), provision.StatusStarting, true)
 		if info, infoErr := c.NetworkInfo(p.ClusterClient()); infoErr == nil {
+			c.UpdateNamedPorts(info)
 			p.fixContainer(c, info)
 		}
 		return nil
-	}, nil, true)
+	})
+	p.updateDiscovery(app.GetName())
 	return err
 }
 
@@ -330,13 +423,15 @@ func (p *dockerProvisioner) Stop(ctx context.Context, app provision.App, process
 		log.Errorf("Got error while getting app containers: %s", err)
 		return nil
 	}
-	return runInContainers(containers, func(c *container.Container, _ chan *container.Container) error {
+	err = runInContainersByNode(containers, func(c *container.Container) error {
 		err := c.Stop(p.ClusterClient(), p.ActionLimiter())
 		if err != nil {
 			log.Errorf("Failed to stop %q: %s", app.GetName(), err)
 		}
 		return err
-	}, nil, true)
+	})
+	p.updateDiscovery(app.GetName())
+	return err
 }
 
 func (p *dockerProvisioner) Sleep(ctx context.Context, app provision.App, process string, _ appTypes.AppVersion) error {
@@ -345,13 +440,15 @@ func (p *dockerProvisioner) Sleep(ctx context.Context, app provision.App, proces
 		log.Errorf("Got error while getting app containers: %s", err)
 		return nil
 	}
-	return runInContainers(containers, func(c *container.Container, _ chan *container.Container) error {
+	err = runInContainersByNode(containers, func(c *container.Container) error {
 		err := c.Sleep(p.ClusterClient(), p.ActionLimiter())
 		if err != nil {
 			log.Errorf("Failed to sleep %q: %s", app.GetName(), err)
 		}
 		return err
-	}, nil, true)
+	})
+	p.updateDiscovery(app.GetName())
+	return err
 }
 
 func (p *dockerProvisioner) Deploy(ctx context.Context, args provision.DeployArgs) (string, error) {
@@ -399,9 +496,11 @@ func (p *dockerProvisioner) deploy(ctx context.Context, a provision.App, version
 			}
 			toAdd[processName].Quantity++
 		}
+		p.distributeImage(ctx, a, version, evt)
 		_, err = p.runCreateUnitsPipeline(ctx, evt, a, toAdd, version)
 	} else {
 		toAdd := getContainersToAdd(processes, containers)
+		p.distributeImage(ctx, a, version, evt)
 		_, err = p.runReplaceUnitsPipeline(ctx, evt, a, toAdd, containers, version)
 	}
 	if err != nil {
@@ -453,7 +552,15 @@ func (p *dockerProvisioner) Destroy(ctx context.Context, app provision.App) erro
 		&provisionRemoveOldUnits,
 		&provisionUnbindOldUnits,
 	)
-	return pipeline.Execute(ctx, args)
+	err = pipeline.Execute(ctx, args)
+	if err != nil {
+		return err
+	}
+	err = discovery.Remove(app.GetName())
+	if err != nil {
+		return err
+	}
+	return releaseExposedPorts(app.GetName())
 }
 
 func (p *dockerProvisioner) DestroyVersion(ctx context.Context, app provision.App, version appTypes.AppVersion) error {
@@ -498,13 +605,20 @@ func addContainersWithHost(ctx context.Context, args *changeUnitsPipelineArgs) (
 		w = ioutil.Discard
 	}
 	fmt.Fprintf(w, "\n---- Starting %d new %s %s ----\n", units, pluralize("unit", units), strings.Join(processMsg, " "))
+	released := releasePublishedPorts(w, args)
+	volumes := newStickyVolumes(a, args.toRemove)
 	oldContainers := make([]container.Container, 0, units)
 	for processName, cont := range args.toAdd {
+		volumeProcess := processName
+		if volumeProcess == "" {
+			_, volumeProcess, _ = dockercommon.ProcessCmdForVersion(processName, cmdData)
+		}
 		for i := 0; i < cont.Quantity; i++ {
 			oldContainers = append(oldContainers, container.Container{
 				Container: types.Container{
-					ProcessName: processName,
-					Status:      cont.Status.String(),
+					ProcessName:  processName,
+					Status:       cont.Status.String(),
+					StickyVolume: volumes.next(volumeProcess),
 				},
 			})
 		}
@@ -521,7 +635,7 @@ func addContainersWithHost(ctx context.Context, args *changeUnitsPipelineArgs) (
 		m                 sync.Mutex
 	)
 	err = runInContainers(oldContainers, func(c *container.Container, toRollback chan *container.Container) error {
-		c, startErr := args.provisioner.start(ctx, c, a, cmdData, args.version, w, destinationHost...)
+		c, startErr := args.provisioner.start(ctx, c, a, cmdData, args.version, w, args.event, destinationHost...)
 		if startErr != nil {
 			return startErr
 		}
@@ -533,6 +647,7 @@ func addContainersWithHost(ctx context.Context, args *changeUnitsPipelineArgs) (
 		return nil
 	}, rollbackCallback, true)
 	if err != nil {
+		restartReleasedUnits(args, released)
 		return nil, err
 	}
 	result := make([]container.Container, len(createdContainers))
@@ -554,7 +669,11 @@ func (p *dockerProvisioner) AddUnits(ctx context.Context, a provision.App, units
 	if w == nil {
 		w = ioutil.Discard
 	}
-	_, err := p.runCreateUnitsPipeline(ctx, w, a, map[string]*containersToAdd{process: {Quantity: int(units)}}, version)
+	err := p.checkMemoryCapacity(a, int(units))
+	if err != nil {
+		return err
+	}
+	_, err = p.runCreateUnitsPipeline(ctx, w, a, map[string]*containersToAdd{process: {Quantity: int(units)}}, version)
 	return err
 }
 
@@ -585,7 +704,6 @@ func (p *dockerProvisioner) RemoveUnits(ctx context.Context, a provision.App, un
 	if len(containers) < int(units) {
 		return errors.Errorf("cannot remove %d units from process %q, only %d available", units, processName, len(containers))
 	}
-	fmt.Fprintf(w, "\n---- Removing %d %s ----\n", units, pluralize("unit", int(units)))
 	p, err = p.cloneProvisioner(nil)
 	if err != nil {
 		return err
@@ -607,6 +725,33 @@ func (p *dockerProvisioner) RemoveUnits(ctx context.Context, a provision.App, un
 		p.scheduler.ignoredContainers = append(p.scheduler.ignoredContainers, cont.ID)
 		toRemove = append(toRemove, *cont)
 	}
+	return p.removeContainers(ctx, a, toRemove, w)
+}
+
+// RemoveUnitsByID removes the containers of the app with the given IDs.
+func (p *dockerProvisioner) RemoveUnitsByID(ctx context.Context, a provision.App, unitIDs []string, w io.Writer) error {
+	if len(unitIDs) == 0 {
+		return errors.New("cannot remove zero units")
+	}
+	if w == nil {
+		w = ioutil.Discard
+	}
+	toRemove := make([]container.Container, 0, len(unitIDs))
+	for _, id := range unitIDs {
+		cont, err := p.GetContainer(id)
+		if err != nil {
+			return err
+		}
+		if cont.AppName != a.GetName() {
+			return &provision.UnitNotFoundError{ID: id}
+		}
+		toRemove = append(toRemove, *cont)
+	}
+	return p.removeContainers(ctx, a, toRemove, w)
+}
+
+func (p *dockerProvisioner) removeContainers(ctx context.Context, a provision.App, toRemove []container.Container, w io.Writer) error {
+	fmt.Fprintf(w, "\n---- Removing %d %s ----\n", len(toRemove), pluralize("unit", len(toRemove)))
 	args := changeUnitsPipelineArgs{
 		app:         a,
 		toRemove:    toRemove,
@@ -618,10 +763,11 @@ func (p *dockerProvisioner) RemoveUnits(ctx context.Context, a provision.App, un
 		&provisionRemoveOldUnits,
 		&provisionUnbindOldUnits,
 	)
-	err = pipeline.Execute(ctx, args)
+	err := pipeline.Execute(ctx, args)
 	if err != nil {
 		return errors.Wrap(err, "error removing routes, units weren't removed")
 	}
+	p.updateDiscovery(a.GetName())
 	return nil
 }
 
@@ -651,11 +797,22 @@ func (p *dockerProvisioner) SetUnitStatus(unit provision.Unit, status provision.
 	if unit.AppName != "" && cont.AppName != unit.AppName {
 		return errors.New("wrong app name")
 	}
+	if status == provision.StatusError && containerBooting(cont) {
+		status = provision.StatusStarting
+	}
+	statusChanged := cont.Status != status.String()
 	err = cont.SetStatus(p.ClusterClient(), status, true)
 	if err != nil {
 		return err
 	}
-	return p.checkContainer(cont)
+	err = p.checkContainer(cont)
+	if err != nil {
+		return err
+	}
+	if statusChanged {
+		p.updateDiscovery(cont.AppName)
+	}
+	return nil
 }
 
 func (p *dockerProvisioner) ExecuteCommand(ctx context.Context, opts provision.ExecOptions) error {
@@ -735,23 +892,74 @@ func (p *dockerProvisioner) RoutableAddresses(ctx context.Context, app provision
 		return nil, err
 	}
 	var webProcessName string
+	var yamlData provTypes.TsuruYamlData
 	if version != nil {
 		webProcessName, err = version.WebProcess()
 		if err != nil {
 			return nil, err
 		}
+		yamlData, err = version.TsuruYamlData()
+		if err != nil {
+			return nil, err
+		}
 	}
 	containers, err := p.listContainersByApp(app.GetName())
 	if err != nil {
 		return nil, err
 	}
 	addrs := make([]*url.URL, 0, len(containers))
+	portAddrs := map[string][]*url.URL{}
+	processAddrs := map[string][]*url.URL{}
+	for _, rp := range yamlData.RoutableProcesses {
+		if rp.Process != webProcessName {
+			processAddrs[rp.Process] = []*url.URL{}
+		}
+	}
 	for _, container := range containers {
-		if container.ProcessName == webProcessName && container.ValidAddr() {
+		if container.ProcessName != webProcessName {
+			if _, ok := processAddrs[container.ProcessName]; ok && container.ValidAddr() {
+				processAddrs[container.ProcessName] = append(processAddrs[container.ProcessName], container.Address())
+			}
+			continue
+		}
+		if container.ValidAddr() {
 			addrs = append(addrs, container.Address())
 		}
+		for _, port := range container.NamedPorts {
+			if _, ok := portAddrs[port.Name]; !ok {
+				portAddrs[port.Name] = []*url.URL{}
+			}
+		}
+		for name, addr := range container.NamedAddresses() {
+			portAddrs[name] = append(portAddrs[name], addr)
+		}
+	}
+	result := []appTypes.RoutableAddresses{{Addresses: addrs}}
+	names := make([]string, 0, len(portAddrs))
+	for name := range portAddrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, appTypes.RoutableAddresses{
+			Prefix:    name + ".port",
+			Port:      name,
+			Addresses: portAddrs[name],
+		})
+	}
+	processes := make([]string, 0, len(processAddrs))
+	for process := range processAddrs {
+		processes = append(processes, process)
 	}
-	return []appTypes.RoutableAddresses{{Addresses: addrs}}, nil
+	sort.Strings(processes)
+	for _, process := range processes {
+		result = append(result, appTypes.RoutableAddresses{
+			Prefix:    process + ".process",
+			Addresses: processAddrs[process],
+			Hostnames: yamlData.RoutableProcess(process).Hostnames,
+		})
+	}
+	return result, nil
 }
 
 func (p *dockerProvisioner) RegisterUnit(ctx context.Context, a provision.App, unitId string, customData map[string]interface{}) error {
@@ -904,7 +1112,11 @@ func (n *clusterNodeWrapper) MetadataNoPrefix() map[string]string {
 }
 
 func (n *clusterNodeWrapper) ExtraData() map[string]string {
-	return n.Node.ExtraMetadata()
+	extra := n.Node.ExtraMetadata()
+	for k, v := range nodeCertMetadata(*n.Node) {
+		extra[k] = v
+	}
+	return extra
 }
 
 func (n *clusterNodeWrapper) Units() ([]provision.Unit, error) {
@@ -1105,6 +1317,26 @@ func (p *dockerProvisioner) RemoveNode(ctx context.Context, opts provision.Remov
 	return p.Cluster().Unregister(opts.Address)
 }
 
+// EvictNode disables the node, preventing new units from being scheduled
+// to it, and moves its units to other nodes before the node is terminated.
+func (p *dockerProvisioner) EvictNode(ctx context.Context, opts provision.EvictNodeOptions) error {
+	node, err := p.Cluster().GetNode(opts.Address)
+	if err != nil {
+		if err == clusterStorage.ErrNoSuchNode {
+			return provision.ErrNodeNotFound
+		}
+		return err
+	}
+	if node.CreationStatus != cluster.NodeCreationStatusDisabled {
+		node.CreationStatus = cluster.NodeCreationStatusDisabled
+		_, err = p.Cluster().UpdateNode(node)
+		if err != nil {
+			return err
+		}
+	}
+	return p.rebalanceContainersByHost(ctx, net.URLToHost(opts.Address), opts.Writer)
+}
+
 func (p *dockerProvisioner) UpgradeNodeContainer(ctx context.Context, name string, pool string, writer io.Writer) error {
 	return internalNodeContainer.RecreateNamedContainers(p, writer, name, pool)
 }
@@ -1138,6 +1370,14 @@ func (p *dockerProvisioner) RebalanceNodes(ctx context.Context, opts provision.R
 	if err != nil {
 		return false, errors.Wrapf(err, "unable to obtain container gap in nodes")
 	}
+	var skew int
+	spreadKey := poolSpreadKey(nodes)
+	if spreadKey != "" {
+		skew, err = p.containerSkewInDomains(ptrNodes, spreadKey)
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to obtain container skew in domains")
+		}
+	}
 	buf := safe.NewBuffer(nil)
 	dryProvisioner, err := p.rebalanceContainersByFilter(context.TODO(), buf, nil, opts.MetadataFilter, true)
 	if err != nil {
@@ -1155,5 +1395,16 @@ func (p *dockerProvisioner) RebalanceNodes(ctx context.Context, opts provision.R
 		_, err := p.rebalanceContainersByFilter(context.TODO(), opts.Event, nil, opts.MetadataFilter, opts.Dry)
 		return true, err
 	}
+	if spreadKey != "" {
+		skewAfter, err := dryProvisioner.containerSkewInDomains(ptrNodes, spreadKey)
+		if err != nil {
+			return false, errors.Wrap(err, "couldn't find containers from rebalanced nodes")
+		}
+		if skewAfter < skew {
+			fmt.Fprintf(opts.Event, "Rebalancing as skew among %q domains is %d, after rebalance skew will be %d\n", spreadKey, skew, skewAfter)
+			_, err := p.rebalanceContainersByFilter(context.TODO(), opts.Event, nil, opts.MetadataFilter, opts.Dry)
+			return true, err
+		}
+	}
 	return false, nil
 }
@@ -120,6 +120,46 @@ func (s *S) TestProvisionerRestart(c *check.C) {
 	c.Assert(dbConts[0].HostPort, check.Equals, expectedPort)
 }
 
+func (s *S) TestProvisionerRollingRestart(c *check.C) {
+	app := provisiontest.NewFakeApp("almah", "static", 1)
+	customData := map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web":    "python web.py",
+			"worker": "python worker.py",
+		},
+	}
+	var oldIDs []string
+	for _, process := range []string{"web", "web", "worker"} {
+		cont, err := s.newContainer(&newContainerOpts{
+			AppName:         app.GetName(),
+			ProcessName:     process,
+			ImageCustomData: customData,
+		}, nil)
+		c.Assert(err, check.IsNil)
+		defer s.removeTestContainer(cont)
+		oldIDs = append(oldIDs, cont.ID)
+	}
+	err := s.p.Start(context.TODO(), app, "", nil, nil)
+	c.Assert(err, check.IsNil)
+	version, err := servicemanager.AppVersion.LatestSuccessfulVersion(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	var buf bytes.Buffer
+	err = s.p.RollingRestart(context.TODO(), app, version, 2, &buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s).*---- Replacing batch 1 of 2 \(2 units\) ----.*---- Replacing batch 2 of 2 \(1 units\) ----.*`)
+	dbConts, err := s.p.listAllContainers()
+	c.Assert(err, check.IsNil)
+	c.Assert(dbConts, check.HasLen, 3)
+	processes := map[string]int{}
+	for _, cont := range dbConts {
+		for _, id := range oldIDs {
+			c.Assert(cont.ID, check.Not(check.Equals), id)
+		}
+		processes[cont.ProcessName]++
+	}
+	c.Assert(processes, check.DeepEquals, map[string]int{"web": 2, "worker": 1})
+}
+
 func (s *S) TestProvisionerRestartStoppedContainer(c *check.C) {
 	app := provisiontest.NewFakeApp("almah", "static", 1)
 	customData := map[string]interface{}{
@@ -592,7 +632,7 @@ func (s *S) TestDeployImageID(c *check.C) {
 		Repository: "tsuru/app-" + a.Name,
 		Tag:        "v1",
 	}
-	err = s.p.Cluster().PullImage(pullOpts, dockercommon.RegistryAuthConfig(pullOpts.Repository))
+	err = s.p.Cluster().PullImage(pullOpts, dockercommon.RegistryAuthConfig("", pullOpts.Repository))
 	c.Assert(err, check.IsNil)
 	_, err = s.p.Deploy(context.TODO(), provision.DeployArgs{App: &a, Version: version, Event: evt})
 	c.Assert(err, check.IsNil)
@@ -964,6 +1004,56 @@ func (s *S) TestProvisionerRemoveUnitsZeroUnits(c *check.C) {
 	c.Assert(err.Error(), check.Equals, "cannot remove zero units")
 }
 
+func (s *S) TestProvisionerRemoveUnitsByID(c *check.C) {
+	ctx := context.TODO()
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	defer p.Destroy(ctx, appInstance)
+	p.Provision(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(p, appInstance, nil)
+	c.Assert(err, check.IsNil)
+	addedConts, err := addContainersWithHost(ctx, &changeUnitsPipelineArgs{
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 3}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	err = p.RemoveUnitsByID(ctx, appInstance, []string{addedConts[0].ID, addedConts[2].ID}, nil)
+	c.Assert(err, check.IsNil)
+	containers, err := p.listContainersByApp(appInstance.GetName())
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 1)
+	c.Assert(containers[0].ID, check.Equals, addedConts[1].ID)
+}
+
+func (s *S) TestProvisionerRemoveUnitsByIDOtherApp(c *check.C) {
+	ctx := context.TODO()
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	defer p.Destroy(ctx, appInstance)
+	p.Provision(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(p, appInstance, nil)
+	c.Assert(err, check.IsNil)
+	addedConts, err := addContainersWithHost(ctx, &changeUnitsPipelineArgs{
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 1}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	otherApp := provisiontest.NewFakeApp("otherapp", "python", 0)
+	err = p.RemoveUnitsByID(ctx, otherApp, []string{addedConts[0].ID}, nil)
+	c.Assert(err, check.FitsTypeOf, &provision.UnitNotFoundError{})
+	containers, err := p.listContainersByApp(appInstance.GetName())
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 1)
+}
+
 func (s *S) TestProvisionerRemoveUnitsTooManyUnits(c *check.C) {
 	a1 := app.App{Name: "impius", Teams: []string{"tsuruteam", "nodockerforme"}, Pool: "pool1"}
 	cont1 := container.Container{Container: types.Container{ID: "1", Name: "impius1", AppName: a1.Name, ProcessName: "web"}}
@@ -2521,6 +2611,52 @@ func (s *S) TestRemoveNodeRebalanceWithUnits(c *check.C) {
 	c.Assert(containerList, check.HasLen, 5)
 }
 
+func (s *S) TestEvictNode(c *check.C) {
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	mainDockerProvisioner = p
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	p.Provision(context.TODO(), appInstance)
+	version, err := newSuccessfulVersionForApp(s.p, appInstance, nil)
+	c.Assert(err, check.IsNil)
+	_, err = addContainersWithHost(context.TODO(), &changeUnitsPipelineArgs{
+		toHost:      "127.0.0.1",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 2}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	appStruct := s.newAppFromFake(appInstance)
+	err = s.conn.Apps().Insert(appStruct)
+	c.Assert(err, check.IsNil)
+	nodes, err := p.Cluster().Nodes()
+	c.Assert(err, check.IsNil)
+	c.Assert(nodes, check.HasLen, 2)
+	c.Assert(net.URLToHost(nodes[0].Address), check.Equals, "127.0.0.1")
+	buf := safe.NewBuffer(nil)
+	err = p.EvictNode(context.TODO(), provision.EvictNodeOptions{
+		Address: nodes[0].Address,
+		Writer:  buf,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(buf.String(), check.Matches, `(?s)Moving unit .+? for "myapp" from 127\.0\.0\.1\.\.\..*`)
+	node, err := p.Cluster().GetNode(nodes[0].Address)
+	c.Assert(err, check.IsNil)
+	c.Assert(node.CreationStatus, check.Equals, cluster.NodeCreationStatusDisabled)
+	containerList, err := p.listContainersByHost("127.0.0.1")
+	c.Assert(err, check.IsNil)
+	c.Assert(containerList, check.HasLen, 0)
+	containerList, err = p.listContainersByHost("localhost")
+	c.Assert(err, check.IsNil)
+	c.Assert(containerList, check.HasLen, 2)
+}
+
+func (s *S) TestEvictNodeNotFound(c *check.C) {
+	err := s.p.EvictNode(context.TODO(), provision.EvictNodeOptions{Address: "http://notfound:2375"})
+	c.Assert(err, check.Equals, provision.ErrNodeNotFound)
+}
+
 func (s *S) TestRemoveNodeNoAddress(c *check.C) {
 	var buf bytes.Buffer
 	opts := provision.RemoveNodeOptions{
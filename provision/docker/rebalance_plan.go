@@ -0,0 +1,215 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+)
+
+const defaultUnitMoveDuration = 30 * time.Second
+
+var _ provision.NodeRebalancePlanProvisioner = &dockerProvisioner{}
+
+// RebalancePlan runs the rebalance of the containers matching opts in dry
+// mode and reports the moves it would make. The plan always reflects a
+// forced rebalance, RebalanceNodes may still skip it when the gap among the
+// nodes of a pool doesn't justify moving units.
+func (p *dockerProvisioner) RebalancePlan(ctx context.Context, opts provision.RebalanceNodesOptions) (*provision.RebalancePlan, error) {
+	metadataFilter := map[string]string{}
+	for k, v := range opts.MetadataFilter {
+		metadataFilter[k] = v
+	}
+	if opts.Pool != "" {
+		metadataFilter[provision.PoolMetadataName] = opts.Pool
+	}
+	var nodes []cluster.Node
+	var err error
+	if len(metadataFilter) > 0 {
+		nodes, err = p.Cluster().UnfilteredNodesForMetadata(metadataFilter)
+	} else {
+		nodes, err = p.Cluster().UnfilteredNodes()
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan := &provision.RebalancePlan{Moves: []provision.RebalanceMove{}, Nodes: []provision.RebalanceNodePlan{}}
+	if len(nodes) == 0 {
+		return plan, nil
+	}
+	hosts := make([]string, len(nodes))
+	for i, n := range nodes {
+		hosts[i] = net.URLToHost(n.Address)
+	}
+	before, err := p.listContainersByAppAndHost(nil, hosts)
+	if err != nil {
+		return nil, err
+	}
+	toMove, err := p.listContainersByAppAndHost(opts.AppFilter, hosts)
+	if err != nil {
+		return nil, err
+	}
+	after := before
+	if len(toMove) > 0 {
+		after, err = p.dryRebalance(ctx, toMove, hosts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	memory := map[string]int64{}
+	for _, c := range append(before, after...) {
+		if _, ok := memory[c.AppName]; ok {
+			continue
+		}
+		a, err := app.GetByName(ctx, c.AppName)
+		if err != nil {
+			return nil, err
+		}
+		memory[c.AppName] = a.Plan.Memory
+	}
+	for _, n := range nodes {
+		host := net.URLToHost(n.Address)
+		nodePlan := provision.RebalanceNodePlan{Address: n.Address}
+		for _, c := range before {
+			if c.HostAddr == host {
+				nodePlan.UnitsBefore++
+				nodePlan.MemoryBefore += memory[c.AppName]
+			}
+		}
+		for _, c := range after {
+			if c.HostAddr == host {
+				nodePlan.UnitsAfter++
+				nodePlan.MemoryAfter += memory[c.AppName]
+			}
+		}
+		plan.Nodes = append(plan.Nodes, nodePlan)
+	}
+	plan.Moves = rebalanceMoves(toMove, after)
+	plan.EstimatedDuration = p.estimateRebalanceDuration(toMove, after)
+	return plan, nil
+}
+
+// dryRebalance moves the containers in a dry mode provisioner, returning
+// every container in hosts once the moves are done.
+func (p *dockerProvisioner) dryRebalance(ctx context.Context, containers []container.Container, hosts []string) ([]container.Container, error) {
+	dryProvisioner, err := p.dryMode(containers)
+	if err != nil {
+		return nil, err
+	}
+	defer dryProvisioner.stopDryMode()
+	err = dryProvisioner.moveContainerList(ctx, containers, "", ioutil.Discard)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to run dry rebalance")
+	}
+	return dryProvisioner.listContainersByAppAndHost(nil, hosts)
+}
+
+// rebalanceMoves pairs the containers removed by the rebalance with the ones
+// created in their place, by app and process. Containers recreated in the
+// same host are not reported as moves.
+func rebalanceMoves(moved, after []container.Container) []provision.RebalanceMove {
+	type processKey struct{ app, process string }
+	existing := map[string]struct{}{}
+	for _, c := range moved {
+		existing[c.ID] = struct{}{}
+	}
+	removed := map[processKey][]container.Container{}
+	for _, c := range moved {
+		key := processKey{app: c.AppName, process: c.ProcessName}
+		removed[key] = append(removed[key], c)
+	}
+	created := map[processKey][]string{}
+	for _, c := range after {
+		if _, ok := existing[c.ID]; ok {
+			continue
+		}
+		key := processKey{app: c.AppName, process: c.ProcessName}
+		created[key] = append(created[key], c.HostAddr)
+	}
+	moves := []provision.RebalanceMove{}
+	for key, olds := range removed {
+		hosts := created[key]
+		var pending []container.Container
+		for _, c := range olds {
+			idx := indexOf(hosts, c.HostAddr)
+			if idx == -1 {
+				pending = append(pending, c)
+				continue
+			}
+			hosts = append(hosts[:idx], hosts[idx+1:]...)
+		}
+		for i, c := range pending {
+			if i >= len(hosts) {
+				break
+			}
+			moves = append(moves, provision.RebalanceMove{
+				App:     c.AppName,
+				Process: c.ProcessName,
+				Unit:    c.ID,
+				From:    c.HostAddr,
+				To:      hosts[i],
+			})
+		}
+	}
+	sort.Slice(moves, func(i, j int) bool {
+		if moves[i].App == moves[j].App {
+			return moves[i].Unit < moves[j].Unit
+		}
+		return moves[i].App < moves[j].App
+	})
+	return moves
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// estimateRebalanceDuration estimates how long the rebalance takes. Every
+// moved container is recreated, and creations run in parallel up to the
+// actions limit of each host, so the busiest host dictates the duration.
+func (p *dockerProvisioner) estimateRebalanceDuration(moved, after []container.Container) time.Duration {
+	if len(moved) == 0 {
+		return 0
+	}
+	existing := map[string]struct{}{}
+	for _, c := range moved {
+		existing[c.ID] = struct{}{}
+	}
+	perHost := map[string]int{}
+	var busiest int
+	for _, c := range after {
+		if _, ok := existing[c.ID]; ok {
+			continue
+		}
+		perHost[c.HostAddr]++
+		if perHost[c.HostAddr] > busiest {
+			busiest = perHost[c.HostAddr]
+		}
+	}
+	moveDuration := defaultUnitMoveDuration
+	if seconds, err := config.GetInt("docker:rebalance:unit-move-duration"); err == nil && seconds > 0 {
+		moveDuration = time.Duration(seconds) * time.Second
+	}
+	rounds := 1
+	if limit, _ := config.GetInt("docker:limit:actions-per-host"); limit > 0 {
+		rounds = (busiest + limit - 1) / limit
+	}
+	return time.Duration(rounds) * moveDuration
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestRebalancePlan(c *check.C) {
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	mainDockerProvisioner = p
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	p.Provision(context.TODO(), appInstance)
+	version, err := newSuccessfulVersionForApp(s.p, appInstance, nil)
+	c.Assert(err, check.IsNil)
+	_, err = addContainersWithHost(context.TODO(), &changeUnitsPipelineArgs{
+		toHost:      "127.0.0.1",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 4}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	appStruct := s.newAppFromFake(appInstance)
+	appStruct.Pool = "test-default"
+	appStruct.Plan.Memory = 1024
+	err = s.conn.Apps().Insert(appStruct)
+	c.Assert(err, check.IsNil)
+	plan, err := p.RebalancePlan(context.TODO(), provision.RebalanceNodesOptions{Pool: "test-default"})
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Moves, check.HasLen, 2)
+	for _, m := range plan.Moves {
+		c.Assert(m.App, check.Equals, "myapp")
+		c.Assert(m.Process, check.Equals, "web")
+		c.Assert(m.From, check.Equals, "127.0.0.1")
+		c.Assert(m.To, check.Equals, "localhost")
+	}
+	c.Assert(plan.Nodes, check.HasLen, 2)
+	for _, n := range plan.Nodes {
+		if n.Address == s.server.URL() {
+			c.Assert(n, check.DeepEquals, provision.RebalanceNodePlan{Address: n.Address, UnitsBefore: 4, UnitsAfter: 2, MemoryBefore: 4096, MemoryAfter: 2048})
+		} else {
+			c.Assert(n, check.DeepEquals, provision.RebalanceNodePlan{Address: n.Address, UnitsAfter: 2, MemoryAfter: 2048})
+		}
+	}
+	c.Assert(plan.EstimatedDuration, check.Equals, defaultUnitMoveDuration)
+	containers, err := p.listContainersByHost("127.0.0.1")
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 4)
+}
+
+func (s *S) TestRebalanceMovesIgnoresSameHost(c *check.C) {
+	newCont := func(id, host string) container.Container {
+		return container.Container{Container: types.Container{ID: id, AppName: "myapp", ProcessName: "web", HostAddr: host}}
+	}
+	moved := []container.Container{newCont("c1", "h1"), newCont("c2", "h1"), newCont("c3", "h2")}
+	after := []container.Container{newCont("n1", "h2"), newCont("n2", "h1"), newCont("n3", "h3")}
+	moves := rebalanceMoves(moved, after)
+	c.Assert(moves, check.DeepEquals, []provision.RebalanceMove{
+		{App: "myapp", Process: "web", Unit: "c2", From: "h1", To: "h3"},
+	})
+}
@@ -7,6 +7,7 @@ package docker
 import (
 	"fmt"
 
+	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 	"github.com/tsuru/docker-cluster/cluster"
 	"github.com/tsuru/tsuru/log"
@@ -168,6 +169,22 @@ func (p *dockerProvisioner) getOneContainerByAppName(appName string) (*container
 	return &c, nil
 }
 
+// containerWithStickyVolume returns the unit of the app currently using the
+// sticky volume, or nil if there's none.
+func (p *dockerProvisioner) containerWithStickyVolume(appName, volume string) (*container.Container, error) {
+	var c container.Container
+	coll := p.Collection()
+	defer coll.Close()
+	err := coll.Find(bson.M{"appname": appName, "stickyvolume": volume, "id": bson.M{"$ne": ""}}).One(&c)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
 func (p *dockerProvisioner) getContainerCountForAppName(appName string) (int, error) {
 	coll := p.Collection()
 	defer coll.Close()
@@ -20,14 +20,18 @@ import (
 	"github.com/tsuru/tsuru/autoscale"
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
 	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/dockercommon"
 	"github.com/tsuru/tsuru/provision/node"
+	"github.com/tsuru/tsuru/provision/pool"
 )
 
 type segregatedScheduler struct {
 	hostMutex           sync.Mutex
 	maxMemoryRatio      float32
 	TotalMemoryMetadata string
+	TotalCPUMetadata    string
 	provisioner         *dockerProvisioner
 	// ignored containers is only set in provisioner returned by
 	// cloneProvisioner which will set this field to exclude some container
@@ -51,7 +55,11 @@ func (s *segregatedScheduler) Schedule(c *cluster.Cluster, opts *docker.CreateCo
 		}
 	}
 	if appName == "" {
-		return s.scheduleAnyNode(c, filterNodesMap)
+		node, err := s.scheduleAnyNode(c, filterNodesMap)
+		if err == nil {
+			s.prePullImage(c, opts, node.Address)
+		}
+		return node, err
 	}
 	a, _ := app.GetByName(context.TODO(), schedOpts.AppName)
 	nodes, err := s.provisioner.Nodes(a)
@@ -59,11 +67,49 @@ func (s *segregatedScheduler) Schedule(c *cluster.Cluster, opts *docker.CreateCo
 		return cluster.Node{}, &container.SchedulerError{Base: err}
 	}
 	nodes = filterNodes(nodes, filterNodesMap)
-	nodes, err = s.filterByMemoryUsage(a, nodes, s.maxMemoryRatio, s.TotalMemoryMetadata)
+	maxMemoryRatio := s.maxMemoryRatio
+	if a != nil {
+		maxMemoryRatio = s.maxMemoryRatioForPool(a.Pool)
+		nodes, err = s.filterBySchedulingHints(a, nodes)
+		if err != nil {
+			return cluster.Node{}, &container.SchedulerError{Base: err}
+		}
+		nodes, err = filterByPlacement(a, nodes)
+		if err != nil {
+			return cluster.Node{}, &container.SchedulerError{Base: err}
+		}
+		nodes, err = s.filterBySpotPolicy(a, nodes, schedOpts.ProcessName)
+		if err != nil {
+			return cluster.Node{}, &container.SchedulerError{Base: err}
+		}
+		if opts.Config != nil {
+			nodes, err = filterByArchitecture(nodes, imageArchitectures(a, opts.Config.Image))
+			if err != nil {
+				return cluster.Node{}, &container.SchedulerError{Base: err}
+			}
+		}
+	}
+	nodes, err = s.filterByMemoryUsage(a, nodes, maxMemoryRatio, s.TotalMemoryMetadata)
 	if err != nil {
 		return cluster.Node{}, &container.SchedulerError{Base: err}
 	}
-	node, err := s.chooseNodeToAdd(nodes, opts.Name, schedOpts.AppName, schedOpts.ProcessName)
+	unit := SchedulerUnit{App: schedOpts.AppName, Process: schedOpts.ProcessName}
+	if opts.Config != nil {
+		unit.Image = opts.Config.Image
+	}
+	if opts.HostConfig != nil {
+		unit.PublishesPorts = publishesNodePorts(opts.HostConfig)
+	}
+	var strategy SchedulerStrategy
+	if a != nil {
+		unit.Memory = a.GetMemory()
+		unit.CPUMilli = a.GetMilliCPU()
+		strategy, err = schedulerStrategyForPool(a.Pool)
+		if err != nil {
+			return cluster.Node{}, &container.SchedulerError{Base: err}
+		}
+	}
+	node, err := s.chooseNodeToAddWithStrategy(strategy, nodes, opts.Name, unit)
 	if err != nil {
 		return cluster.Node{}, &container.SchedulerError{Base: err}
 	}
@@ -76,9 +122,19 @@ func (s *segregatedScheduler) Schedule(c *cluster.Cluster, opts *docker.CreateCo
 			return cluster.Node{}, &container.SchedulerError{Base: err}
 		}
 	}
+	s.prePullImage(c, opts, node)
 	return cluster.Node{Address: node}, nil
 }
 
+// prePullImage warms the image through the registry mirror of the chosen
+// node, if any, before the cluster pulls it from the primary registry.
+func (s *segregatedScheduler) prePullImage(c *cluster.Cluster, opts *docker.CreateContainerOptions, nodeAddr string) {
+	if opts == nil || opts.Config == nil || opts.Config.Image == "" {
+		return
+	}
+	dockercommon.PrePullImageFromMirror(c, nodeAddr, opts.Config.Image)
+}
+
 func (s *segregatedScheduler) scheduleAnyNode(c *cluster.Cluster, filter map[string]struct{}) (cluster.Node, error) {
 	nodes, err := c.Nodes()
 	if err != nil {
@@ -113,7 +169,6 @@ func (s *segregatedScheduler) updateContainerName(opts *docker.CreateContainerOp
 }
 
 func (s *segregatedScheduler) filterByMemoryUsage(a *app.App, nodes []cluster.Node, maxMemoryRatio float32, TotalMemoryMetadata string) ([]cluster.Node, error) {
-	ctx := context.TODO()
 	if maxMemoryRatio == 0 || TotalMemoryMetadata == "" {
 		return nodes, nil
 	}
@@ -121,18 +176,10 @@ func (s *segregatedScheduler) filterByMemoryUsage(a *app.App, nodes []cluster.No
 	for i := range nodes {
 		hosts[i] = net.URLToHost(nodes[i].Address)
 	}
-	containers, err := s.provisioner.ListContainers(bson.M{"hostaddr": bson.M{"$in": hosts}, "id": bson.M{"$nin": s.ignoredContainers}})
+	hostReserved, _, err := s.reservedMemoryByHost(hosts)
 	if err != nil {
 		return nil, err
 	}
-	hostReserved := make(map[string]int64)
-	for _, cont := range containers {
-		contApp, err := app.GetByName(ctx, cont.AppName)
-		if err != nil {
-			return nil, err
-		}
-		hostReserved[cont.HostAddr] += contApp.Plan.Memory
-	}
 	megabyte := float64(1024 * 1024)
 	nodeList := make([]cluster.Node, 0, len(nodes))
 	for _, node := range nodes {
@@ -168,6 +215,7 @@ func (s *segregatedScheduler) filterByMemoryUsage(a *app.App, nodes []cluster.No
 			// Allow going over quota temporarily because auto-scale will be
 			// able to detect this and automatically add a new nodes.
 			log.Errorf("WARNING: %s. Will ignore memory restrictions.", errMsg)
+			autoscale.Trigger(a.Pool)
 			return nodes, nil
 		}
 		return nil, errors.New(errMsg)
@@ -284,10 +332,33 @@ func (s *segregatedScheduler) nodesToHosts(nodes []cluster.Node) ([]string, map[
 // chooseNodeToAdd finds which is the node with the minimum number of containers
 // and returns it
 func (s *segregatedScheduler) chooseNodeToAdd(nodes []cluster.Node, contName string, appName, process string) (string, error) {
+	return s.chooseNodeToAddWithStrategy(nil, nodes, contName, SchedulerUnit{App: appName, Process: process})
+}
+
+// chooseNodeToAddWithStrategy finds the node with the lowest score in the
+// strategy, falling back to the node with the minimum number of containers
+// when strategy is nil, and returns it
+func (s *segregatedScheduler) chooseNodeToAddWithStrategy(strategy SchedulerStrategy, nodes []cluster.Node, contName string, unit SchedulerUnit) (string, error) {
 	log.Debugf("[scheduler] Possible nodes for container %s: %#v", contName, nodes)
 	s.hostMutex.Lock()
 	defer s.hostMutex.Unlock()
-	chosenNode, _, err := s.minMaxNodes(nodes, appName, process)
+	var chosenNode string
+	var err error
+	if unit.PublishesPorts {
+		nodes, err = s.filterByPublishedPorts(nodes, contName, unit)
+		if err != nil {
+			return "", err
+		}
+	}
+	if strategy == nil {
+		chosenNode, _, err = s.minMaxNodes(nodes, unit.App, unit.Process)
+	} else {
+		nodes, err = s.filterBySpreadDomain(nodes, unit.App, unit.Process)
+		if err != nil {
+			return "", err
+		}
+		chosenNode, err = s.chooseNodeByStrategy(strategy, nodes, unit)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -300,6 +371,50 @@ func (s *segregatedScheduler) chooseNodeToAdd(nodes []cluster.Node, contName str
 	return chosenNode, err
 }
 
+func publishesNodePorts(hostConfig *docker.HostConfig) bool {
+	for _, bindings := range hostConfig.PortBindings {
+		for _, b := range bindings {
+			if b.HostPort != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterByPublishedPorts removes the nodes already running a unit of the
+// same process binding its exposed ports, as their node ports would clash.
+func (s *segregatedScheduler) filterByPublishedPorts(nodes []cluster.Node, contName string, unit SchedulerUnit) ([]cluster.Node, error) {
+	coll := s.provisioner.Collection()
+	defer coll.Close()
+	var hosts []string
+	err := coll.Find(bson.M{
+		"appname":          unit.App,
+		"processname":      unit.Process,
+		"name":             bson.M{"$ne": contName},
+		"id":               bson.M{"$nin": s.ignoredContainers},
+		"status":           bson.M{"$ne": provision.StatusStopped.String()},
+		"publishedports.0": bson.M{"$exists": true},
+	}).Distinct("hostaddr", &hosts)
+	if err != nil {
+		return nil, err
+	}
+	usedHosts := make(map[string]struct{}, len(hosts))
+	for _, h := range hosts {
+		usedHosts[h] = struct{}{}
+	}
+	result := make([]cluster.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if _, ok := usedHosts[net.URLToHost(n.Address)]; !ok {
+			result = append(result, n)
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.Errorf("no nodes available for unit of process %q exposing ports: every node already runs one of its units", unit.Process)
+	}
+	return result, nil
+}
+
 // chooseContainerToRemove finds a container from the the node with maximum
 // number of containers and returns it
 func (s *segregatedScheduler) chooseContainerToRemove(nodes []cluster.Node, appName, process string) (string, error) {
@@ -335,14 +450,25 @@ func (s *segregatedScheduler) minMaxNodes(nodes []cluster.Node, appName, process
 	for i := range nodes {
 		nodesList[i] = &clusterNodeWrapper{Node: &nodes[i], prov: s.provisioner}
 	}
-	metaFreqList, _, err := nodesList.SplitMetadata()
-	if err != nil {
-		log.Debugf("[scheduler] ignoring metadata diff when selecting node: %s", err)
-	}
 	hostGroupMap := map[string]int{}
-	for i, m := range metaFreqList {
-		for _, n := range m.Nodes {
-			hostGroupMap[net.URLToHost(n.Address())] = i
+	if spreadKey := poolSpreadKey(nodes); spreadKey != "" {
+		domains := map[string]int{}
+		for _, n := range nodes {
+			domain := n.Metadata[spreadKey]
+			if _, ok := domains[domain]; !ok {
+				domains[domain] = len(domains)
+			}
+			hostGroupMap[net.URLToHost(n.Address)] = domains[domain]
+		}
+	} else {
+		metaFreqList, _, err := nodesList.SplitMetadata()
+		if err != nil {
+			log.Debugf("[scheduler] ignoring metadata diff when selecting node: %s", err)
+		}
+		for i, m := range metaFreqList {
+			for _, n := range m.Nodes {
+				hostGroupMap[net.URLToHost(n.Address())] = i
+			}
 		}
 	}
 	hosts, hostsMap := s.nodesToHosts(nodes)
@@ -375,6 +501,61 @@ func (s *segregatedScheduler) minMaxNodes(nodes []cluster.Node, appName, process
 	return hostsMap[minHost], hostsMap[maxHost], nil
 }
 
+// filterBySpreadDomain keeps only the nodes in the spread domains, the
+// values of the pool spread key, with the fewest units of the app process,
+// so that strategies only choose among nodes that keep the units balanced
+// across the domains.
+func (s *segregatedScheduler) filterBySpreadDomain(nodes []cluster.Node, appName, process string) ([]cluster.Node, error) {
+	spreadKey := poolSpreadKey(nodes)
+	if spreadKey == "" {
+		return nodes, nil
+	}
+	hosts, _ := s.nodesToHosts(nodes)
+	appCountMap, err := s.aggregateContainersByHostAppProcess(hosts, appName, process)
+	if err != nil {
+		return nil, err
+	}
+	domainCount := map[string]int{}
+	for i, n := range nodes {
+		domainCount[n.Metadata[spreadKey]] += appCountMap[hosts[i]]
+	}
+	minCount := -1
+	for _, count := range domainCount {
+		if minCount == -1 || count < minCount {
+			minCount = count
+		}
+	}
+	var result []cluster.Node
+	for _, n := range nodes {
+		if domainCount[n.Metadata[spreadKey]] == minCount {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+// poolSpreadKey returns the spread key of the pool of the nodes, if they all
+// belong to the same pool.
+func poolSpreadKey(nodes []cluster.Node) string {
+	var poolName string
+	for i, n := range nodes {
+		nodePool := n.Metadata[provision.PoolMetadataName]
+		if i > 0 && nodePool != poolName {
+			return ""
+		}
+		poolName = nodePool
+	}
+	if poolName == "" {
+		return ""
+	}
+	p, err := pool.GetPoolByName(context.TODO(), poolName)
+	if err != nil {
+		log.Debugf("[scheduler] ignoring spread key, unable to get pool %q: %s", poolName, err)
+		return ""
+	}
+	return p.GetSpreadKey()
+}
+
 func filterNodes(nodes []cluster.Node, filter map[string]struct{}) []cluster.Node {
 	if len(filter) == 0 {
 		return nodes
@@ -0,0 +1,58 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
+)
+
+// archMetadataName is the node metadata advertising the CPU architecture of
+// the node, like amd64 or arm64.
+const archMetadataName = "arch"
+
+// imageArchitectures returns the architectures the app version using the
+// image was built for, empty when they're unknown.
+func imageArchitectures(a provision.App, img string) []string {
+	if img == "" {
+		return nil
+	}
+	version, err := servicemanager.AppVersion.VersionByImageOrVersion(context.TODO(), a, img)
+	if err != nil {
+		log.Debugf("[scheduler] ignoring image architectures, unable to get version for image %q: %s", img, err)
+		return nil
+	}
+	return version.VersionInfo().Architectures
+}
+
+// filterByArchitecture removes the nodes advertising an architecture the
+// image wasn't built for. Nodes without the arch metadata are kept, as their
+// architecture is unknown.
+func filterByArchitecture(nodes []cluster.Node, archs []string) ([]cluster.Node, error) {
+	if len(archs) == 0 {
+		return nodes, nil
+	}
+	supported := make(map[string]struct{}, len(archs))
+	for _, arch := range archs {
+		supported[arch] = struct{}{}
+	}
+	result := make([]cluster.Node, 0, len(nodes))
+	for _, n := range nodes {
+		nodeArch := n.Metadata[archMetadataName]
+		if _, ok := supported[nodeArch]; ok || nodeArch == "" {
+			result = append(result, n)
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.Errorf("no nodes available for image architectures %s", strings.Join(archs, ", "))
+	}
+	return result, nil
+}
@@ -0,0 +1,318 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/autoscale"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+)
+
+// NodeCapacity reports the memory reserved by the plans of the containers
+// in a node against the memory the scheduler allows to be reserved in it.
+type NodeCapacity struct {
+	Address string `json:"address"`
+	// TotalMemory is read from the node metadata set in
+	// docker:scheduler:total-memory-metadata, it's zero when unknown.
+	TotalMemory int64 `json:"totalMemory"`
+	// MaxMemory is TotalMemory multiplied by the overcommit factor.
+	MaxMemory      int64 `json:"maxMemory"`
+	ReservedMemory int64 `json:"reservedMemory"`
+	Headroom       int64 `json:"headroom"`
+	Containers     int   `json:"containers"`
+}
+
+// PoolCapacity reports the memory capacity of the nodes in a pool.
+type PoolCapacity struct {
+	Pool string `json:"pool"`
+	// MaxUsedMemory is the overcommit factor applied to the pool nodes, zero
+	// means memory is not taken into account when scheduling units.
+	MaxUsedMemory  float64        `json:"maxUsedMemory"`
+	TotalMemory    int64          `json:"totalMemory"`
+	MaxMemory      int64          `json:"maxMemory"`
+	ReservedMemory int64          `json:"reservedMemory"`
+	Headroom       int64          `json:"headroom"`
+	Nodes          []NodeCapacity `json:"nodes"`
+}
+
+// PoolStatus reports the capacity and fragmentation of the nodes in a pool,
+// along with warnings about its health.
+type PoolStatus struct {
+	PoolCapacity
+	// ReservedMemoryByApp is the memory reserved by the plans of the
+	// containers of each app in the pool nodes.
+	ReservedMemoryByApp map[string]int64 `json:"reservedMemoryByApp"`
+	// Fragmentation is the fraction of the headroom scattered in chunks
+	// smaller than the largest plan used in the pool, which can't be used by
+	// units of that plan.
+	Fragmentation float64  `json:"fragmentation"`
+	Warnings      []string `json:"warnings"`
+}
+
+const defaultMinFreeMemoryRatio = 0.1
+
+// maxMemoryRatioForPool returns the overcommit factor of the pool, set in
+// docker:scheduler:pool-max-used-memory:<pool>, falling back to
+// docker:scheduler:max-used-memory.
+func (s *segregatedScheduler) maxMemoryRatioForPool(pool string) float32 {
+	if pool != "" {
+		ratio, err := config.GetFloat("docker:scheduler:pool-max-used-memory:" + pool)
+		if err == nil {
+			return float32(ratio)
+		}
+	}
+	return s.maxMemoryRatio
+}
+
+// reservedMemoryByHost returns the sum of the plan memory of the containers
+// in each host.
+func (s *segregatedScheduler) reservedMemoryByHost(hosts []string) (map[string]int64, map[string]int, error) {
+	containers, err := s.provisioner.ListContainers(bson.M{"hostaddr": bson.M{"$in": hosts}, "id": bson.M{"$nin": s.ignoredContainers}})
+	if err != nil {
+		return nil, nil, err
+	}
+	reserved := make(map[string]int64)
+	count := make(map[string]int)
+	apps := make(map[string]*app.App)
+	for _, cont := range containers {
+		contApp, ok := apps[cont.AppName]
+		if !ok {
+			contApp, err = app.GetByName(context.TODO(), cont.AppName)
+			if err != nil {
+				return nil, nil, err
+			}
+			apps[cont.AppName] = contApp
+		}
+		reserved[cont.HostAddr] += contApp.Plan.Memory
+		count[cont.HostAddr]++
+	}
+	return reserved, count, nil
+}
+
+// reservedMemoryByApp returns the sum of the plan memory of the containers
+// in the hosts by app, along with the largest plan memory among them.
+func (s *segregatedScheduler) reservedMemoryByApp(hosts []string) (map[string]int64, int64, error) {
+	containers, err := s.provisioner.ListContainers(bson.M{"hostaddr": bson.M{"$in": hosts}, "id": bson.M{"$nin": s.ignoredContainers}})
+	if err != nil {
+		return nil, 0, err
+	}
+	reserved := make(map[string]int64)
+	apps := make(map[string]*app.App)
+	var largest int64
+	for _, cont := range containers {
+		contApp, ok := apps[cont.AppName]
+		if !ok {
+			contApp, err = app.GetByName(context.TODO(), cont.AppName)
+			if err != nil {
+				return nil, 0, err
+			}
+			apps[cont.AppName] = contApp
+		}
+		reserved[cont.AppName] += contApp.Plan.Memory
+		if contApp.Plan.Memory > largest {
+			largest = contApp.Plan.Memory
+		}
+	}
+	return reserved, largest, nil
+}
+
+func (s *segregatedScheduler) nodesCapacity(nodes []cluster.Node, maxMemoryRatio float32) ([]NodeCapacity, error) {
+	hosts := make([]string, len(nodes))
+	for i := range nodes {
+		hosts[i] = net.URLToHost(nodes[i].Address)
+	}
+	reserved, count, err := s.reservedMemoryByHost(hosts)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]NodeCapacity, len(nodes))
+	for i, n := range nodes {
+		result[i] = NodeCapacity{
+			Address:        n.Address,
+			ReservedMemory: reserved[hosts[i]],
+			Containers:     count[hosts[i]],
+		}
+		if s.TotalMemoryMetadata == "" {
+			continue
+		}
+		totalMemory, _ := strconv.ParseFloat(n.Metadata[s.TotalMemoryMetadata], 64)
+		result[i].TotalMemory = int64(totalMemory)
+		if maxMemoryRatio > 0 {
+			result[i].MaxMemory = int64(totalMemory * float64(maxMemoryRatio))
+			result[i].Headroom = result[i].MaxMemory - result[i].ReservedMemory
+		}
+	}
+	return result, nil
+}
+
+// PoolCapacity returns the memory capacity of the nodes in the pool.
+func (p *dockerProvisioner) PoolCapacity(pool string) (*PoolCapacity, error) {
+	nodes, err := p.Cluster().NodesForMetadata(map[string]string{provision.PoolMetadataName: pool})
+	if err != nil {
+		return nil, err
+	}
+	ratio := p.scheduler.maxMemoryRatioForPool(pool)
+	nodesCapacity, err := p.scheduler.nodesCapacity(nodes, ratio)
+	if err != nil {
+		return nil, err
+	}
+	capacity := PoolCapacity{Pool: pool, MaxUsedMemory: float64(ratio), Nodes: nodesCapacity}
+	for _, n := range nodesCapacity {
+		capacity.TotalMemory += n.TotalMemory
+		capacity.MaxMemory += n.MaxMemory
+		capacity.ReservedMemory += n.ReservedMemory
+		if n.Headroom > 0 {
+			capacity.Headroom += n.Headroom
+		}
+	}
+	return &capacity, nil
+}
+
+// minFreeMemoryRatioForPool returns the fraction of the schedulable memory
+// of the pool that should be kept free, set in
+// docker:scheduler:pool-min-free-memory:<pool>, falling back to
+// docker:scheduler:min-free-memory.
+func minFreeMemoryRatioForPool(pool string) float64 {
+	ratio, err := config.GetFloat("docker:scheduler:pool-min-free-memory:" + pool)
+	if err == nil {
+		return ratio
+	}
+	ratio, err = config.GetFloat("docker:scheduler:min-free-memory")
+	if err == nil {
+		return ratio
+	}
+	return defaultMinFreeMemoryRatio
+}
+
+// PoolStatus returns the capacity of the pool, the memory reserved by each
+// app in it, how fragmented its headroom is and warnings when it's running
+// out of capacity.
+func (p *dockerProvisioner) PoolStatus(pool string) (*PoolStatus, error) {
+	capacity, err := p.PoolCapacity(pool)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, len(capacity.Nodes))
+	for i, n := range capacity.Nodes {
+		hosts[i] = net.URLToHost(n.Address)
+	}
+	byApp, largestPlan, err := p.scheduler.reservedMemoryByApp(hosts)
+	if err != nil {
+		return nil, err
+	}
+	status := PoolStatus{
+		PoolCapacity:        *capacity,
+		ReservedMemoryByApp: byApp,
+		Warnings:            []string{},
+	}
+	status.Fragmentation = fragmentation(capacity.Nodes, largestPlan)
+	if len(capacity.Nodes) == 0 {
+		status.Warnings = append(status.Warnings, "pool has no nodes")
+		return &status, nil
+	}
+	for _, n := range capacity.Nodes {
+		if p.scheduler.TotalMemoryMetadata != "" && n.TotalMemory == 0 {
+			status.Warnings = append(status.Warnings, fmt.Sprintf("node %s has no total memory metadata, its capacity is unknown", n.Address))
+		}
+		if n.Headroom < 0 {
+			status.Warnings = append(status.Warnings, fmt.Sprintf("node %s is overcommitted by %d bytes", n.Address, -n.Headroom))
+		}
+	}
+	if capacity.MaxMemory > 0 {
+		minFree := minFreeMemoryRatioForPool(pool)
+		free := float64(capacity.Headroom) / float64(capacity.MaxMemory)
+		if free < minFree {
+			status.Warnings = append(status.Warnings, fmt.Sprintf("free schedulable memory is %0.1f%%, below the threshold of %0.1f%%", free*100, minFree*100))
+		}
+		if largestPlan > 0 && largestHeadroom(capacity.Nodes) < largestPlan {
+			status.Warnings = append(status.Warnings, fmt.Sprintf("no node has room for another unit of the largest plan in use, with %d bytes", largestPlan))
+		}
+	}
+	return &status, nil
+}
+
+// fragmentation returns the fraction of the nodes headroom which can't be
+// used by units with the given memory, as it's left in chunks smaller than
+// it.
+func fragmentation(nodes []NodeCapacity, unitMemory int64) float64 {
+	if unitMemory <= 0 {
+		return 0
+	}
+	var free, wasted int64
+	for _, n := range nodes {
+		if n.Headroom <= 0 {
+			continue
+		}
+		free += n.Headroom
+		wasted += n.Headroom % unitMemory
+	}
+	if free == 0 {
+		return 0
+	}
+	return float64(wasted) / float64(free)
+}
+
+func largestHeadroom(nodes []NodeCapacity) int64 {
+	var largest int64
+	for _, n := range nodes {
+		if n.Headroom > largest {
+			largest = n.Headroom
+		}
+	}
+	return largest
+}
+
+// checkMemoryCapacity rejects adding units to the app when the nodes of its
+// pool don't have enough memory headroom to receive all of them without
+// being overcommitted. Pools with auto scale enabled are not checked, the
+// scheduler triggers a scale up in that case.
+func (p *dockerProvisioner) checkMemoryCapacity(a provision.App, units int) error {
+	memory := a.GetMemory()
+	ratio := p.scheduler.maxMemoryRatioForPool(a.GetPool())
+	if memory <= 0 || ratio == 0 || p.scheduler.TotalMemoryMetadata == "" {
+		return nil
+	}
+	rule, _ := autoscale.AutoScaleRuleForMetadata(a.GetPool())
+	if rule != nil && rule.Enabled {
+		return nil
+	}
+	nodes, err := p.Nodes(a)
+	if err != nil {
+		return err
+	}
+	nodesCapacity, err := p.scheduler.nodesCapacity(nodes, ratio)
+	if err != nil {
+		return err
+	}
+	var fit int64
+	for _, n := range nodesCapacity {
+		if n.TotalMemory == 0 {
+			// Nodes without total memory metadata are not limited by the
+			// scheduler.
+			return nil
+		}
+		if n.Headroom > 0 {
+			fit += n.Headroom / memory
+		}
+	}
+	if fit >= int64(units) {
+		return nil
+	}
+	megabyte := float64(1024 * 1024)
+	msg := fmt.Sprintf("not enough memory in pool %q to add %d units of %q: %0.4fMB needed per unit, room for %d units",
+		a.GetPool(), units, a.GetName(), float64(memory)/megabyte, fit)
+	log.Errorf("[scheduler] %s", msg)
+	return errors.New(msg)
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) setupCapacityCluster(c *check.C) {
+	sched := &segregatedScheduler{
+		maxMemoryRatio:      0.8,
+		TotalMemoryMetadata: "totalMemory",
+		provisioner:         s.p,
+	}
+	var err error
+	s.p.scheduler = sched
+	s.p.cluster, err = cluster.New(sched, &cluster.MapStorage{}, "",
+		cluster.Node{Address: "http://server1:2375", Metadata: map[string]string{"totalMemory": "100000", "pool": "mypool"}},
+		cluster.Node{Address: "http://server2:2375", Metadata: map[string]string{"totalMemory": "50000", "pool": "mypool"}},
+		cluster.Node{Address: "http://server3:2375", Metadata: map[string]string{"totalMemory": "50000", "pool": "otherpool"}},
+	)
+	c.Assert(err, check.IsNil)
+	err = s.conn.Apps().Insert(
+		app.App{Name: "skyrim", Plan: appTypes.Plan{Memory: 20000}, Pool: "mypool"},
+		app.App{Name: "oblivion", Plan: appTypes.Plan{Memory: 10000}, Pool: "mypool"},
+	)
+	c.Assert(err, check.IsNil)
+	coll := s.p.Collection()
+	defer coll.Close()
+	err = coll.Insert(
+		container.Container{Container: types.Container{ID: "c1", AppName: "skyrim", HostAddr: "server1"}},
+		container.Container{Container: types.Container{ID: "c2", AppName: "skyrim", HostAddr: "server1"}},
+		container.Container{Container: types.Container{ID: "c3", AppName: "oblivion", HostAddr: "server2"}},
+	)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestMaxMemoryRatioForPool(c *check.C) {
+	config.Set("docker:scheduler:pool-max-used-memory:mypool", 1.5)
+	defer config.Unset("docker:scheduler:pool-max-used-memory")
+	sched := segregatedScheduler{maxMemoryRatio: 0.8}
+	c.Assert(sched.maxMemoryRatioForPool("mypool"), check.Equals, float32(1.5))
+	c.Assert(sched.maxMemoryRatioForPool("otherpool"), check.Equals, float32(0.8))
+	c.Assert(sched.maxMemoryRatioForPool(""), check.Equals, float32(0.8))
+}
+
+func (s *S) TestPoolCapacity(c *check.C) {
+	s.setupCapacityCluster(c)
+	capacity, err := s.p.PoolCapacity("mypool")
+	c.Assert(err, check.IsNil)
+	c.Assert(capacity, check.DeepEquals, &PoolCapacity{
+		Pool:           "mypool",
+		MaxUsedMemory:  float64(float32(0.8)),
+		TotalMemory:    150000,
+		MaxMemory:      120000,
+		ReservedMemory: 50000,
+		Headroom:       70000,
+		Nodes: []NodeCapacity{
+			{Address: "http://server1:2375", TotalMemory: 100000, MaxMemory: 80000, ReservedMemory: 40000, Headroom: 40000, Containers: 2},
+			{Address: "http://server2:2375", TotalMemory: 50000, MaxMemory: 40000, ReservedMemory: 10000, Headroom: 30000, Containers: 1},
+		},
+	})
+}
+
+func (s *S) TestPoolStatus(c *check.C) {
+	s.setupCapacityCluster(c)
+	status, err := s.p.PoolStatus("mypool")
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Nodes, check.HasLen, 2)
+	c.Assert(status.ReservedMemory, check.Equals, int64(50000))
+	c.Assert(status.ReservedMemoryByApp, check.DeepEquals, map[string]int64{"skyrim": 40000, "oblivion": 10000})
+	c.Assert(status.Fragmentation, check.Equals, float64(10000)/70000)
+	c.Assert(status.Warnings, check.DeepEquals, []string{})
+	config.Set("docker:scheduler:pool-min-free-memory:mypool", 0.6)
+	defer config.Unset("docker:scheduler:pool-min-free-memory")
+	status, err = s.p.PoolStatus("mypool")
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Warnings, check.DeepEquals, []string{"free schedulable memory is 58.3%, below the threshold of 60.0%"})
+}
+
+func (s *S) TestPoolStatusWithoutNodes(c *check.C) {
+	s.setupCapacityCluster(c)
+	status, err := s.p.PoolStatus("emptypool")
+	c.Assert(err, check.IsNil)
+	c.Assert(status.Fragmentation, check.Equals, float64(0))
+	c.Assert(status.Warnings, check.DeepEquals, []string{"pool has no nodes"})
+}
+
+func (s *S) TestFragmentation(c *check.C) {
+	nodes := []NodeCapacity{{Headroom: 25000}, {Headroom: 15000}, {Headroom: -5000}}
+	c.Assert(fragmentation(nodes, 10000), check.Equals, float64(10000)/40000)
+	c.Assert(fragmentation(nodes, 5000), check.Equals, float64(0))
+	c.Assert(fragmentation(nodes, 0), check.Equals, float64(0))
+	c.Assert(fragmentation(nil, 10000), check.Equals, float64(0))
+}
+
+func (s *S) TestCheckMemoryCapacity(c *check.C) {
+	s.setupCapacityCluster(c)
+	a := &app.App{Name: "skyrim", Plan: appTypes.Plan{Memory: 20000}, Pool: "mypool"}
+	c.Assert(s.p.checkMemoryCapacity(a, 3), check.IsNil)
+	err := s.p.checkMemoryCapacity(a, 4)
+	c.Assert(err, check.ErrorMatches, `not enough memory in pool "mypool" to add 4 units of "skyrim": 0.0191MB needed per unit, room for 3 units`)
+	config.Set("docker:scheduler:pool-max-used-memory:mypool", 1.2)
+	defer config.Unset("docker:scheduler:pool-max-used-memory")
+	c.Assert(s.p.checkMemoryCapacity(a, 4), check.IsNil)
+}
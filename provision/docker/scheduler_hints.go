@@ -0,0 +1,283 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+)
+
+// AnnotationSchedulingHints is the app annotation with the node attributes
+// required by the units of the app, as a comma separated list of
+// "attribute=value" or "attribute!=value" hints.
+const AnnotationSchedulingHints = "app.tsuru.io/docker-scheduling-hints"
+
+// Node attributes read from the docker daemon, all other attributes come
+// from the node metadata.
+const (
+	NodeAttrDockerVersion   = "docker-version"
+	NodeAttrKernelVersion   = "kernel-version"
+	NodeAttrArchitecture    = "architecture"
+	NodeAttrOperatingSystem = "operating-system"
+)
+
+var daemonAttributes = []string{NodeAttrDockerVersion, NodeAttrKernelVersion, NodeAttrArchitecture, NodeAttrOperatingSystem}
+
+const nodeInfoTTL = 5 * time.Minute
+
+type nodeInfoEntry struct {
+	attrs   map[string]string
+	expires time.Time
+}
+
+var nodeInfoCache = struct {
+	sync.Mutex
+	entries map[string]nodeInfoEntry
+}{entries: make(map[string]nodeInfoEntry)}
+
+// nodeDaemonAttributes returns the attributes reported by the docker daemon
+// of the node, they're cached for a few minutes as they're read while
+// scheduling units.
+func nodeDaemonAttributes(n cluster.Node) (map[string]string, error) {
+	nodeInfoCache.Lock()
+	entry, ok := nodeInfoCache.entries[n.Address]
+	nodeInfoCache.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.attrs, nil
+	}
+	client, err := n.Client()
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.Info()
+	if err != nil {
+		return nil, err
+	}
+	attrs := map[string]string{
+		NodeAttrDockerVersion:   info.ServerVersion,
+		NodeAttrKernelVersion:   info.KernelVersion,
+		NodeAttrArchitecture:    info.Architecture,
+		NodeAttrOperatingSystem: info.OperatingSystem,
+	}
+	nodeInfoCache.Lock()
+	nodeInfoCache.entries[n.Address] = nodeInfoEntry{attrs: attrs, expires: time.Now().Add(nodeInfoTTL)}
+	nodeInfoCache.Unlock()
+	return attrs, nil
+}
+
+// nodeAttributes returns the metadata of the node, except for the pool,
+// along with the daemon attributes if withDaemon is set.
+func nodeAttributes(n cluster.Node, withDaemon bool) (map[string]string, error) {
+	attrs := n.CleanMetadata()
+	delete(attrs, provision.PoolMetadataName)
+	if !withDaemon {
+		return attrs, nil
+	}
+	daemonAttrs, err := nodeDaemonAttributes(n)
+	if err != nil {
+		return attrs, err
+	}
+	for k, v := range daemonAttrs {
+		attrs[k] = v
+	}
+	return attrs, nil
+}
+
+// SchedulingHint is a requirement of an app on a node attribute. Attributes
+// may hold a comma separated list of values, like the cpu flags of a node,
+// in which case the hint matches when the value is in the list.
+type SchedulingHint struct {
+	Attribute string
+	Value     string
+	Negate    bool
+}
+
+func (h SchedulingHint) String() string {
+	op := "="
+	if h.Negate {
+		op = "!="
+	}
+	return h.Attribute + op + h.Value
+}
+
+func (h SchedulingHint) matches(attrs map[string]string) bool {
+	var found bool
+	for _, v := range strings.Split(attrs[h.Attribute], ",") {
+		if strings.TrimSpace(v) == h.Value {
+			found = true
+			break
+		}
+	}
+	return found != h.Negate
+}
+
+func (h SchedulingHint) needsDaemon() bool {
+	for _, attr := range daemonAttributes {
+		if h.Attribute == attr {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSchedulingHints(raw string) ([]SchedulingHint, error) {
+	var hints []SchedulingHint
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var hint SchedulingHint
+		sep := "="
+		if strings.Contains(part, "!=") {
+			sep = "!="
+			hint.Negate = true
+		}
+		parts := strings.SplitN(part, sep, 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, errors.Errorf("invalid scheduling hint %q, expected attribute=value or attribute!=value", part)
+		}
+		hint.Attribute = strings.TrimSpace(parts[0])
+		hint.Value = strings.TrimSpace(parts[1])
+		hints = append(hints, hint)
+	}
+	return hints, nil
+}
+
+func schedulingHintsForApp(a provision.App) ([]SchedulingHint, error) {
+	raw, _ := a.GetMetadata().Annotation(AnnotationSchedulingHints)
+	return parseSchedulingHints(raw)
+}
+
+// UnschedulableError is returned when no node available for an app
+// satisfies all of its scheduling hints.
+type UnschedulableError struct {
+	App   string
+	Pool  string
+	Nodes int
+	Hints []SchedulingHint
+	// Matches is the number of nodes matching each hint.
+	Matches map[string]int
+}
+
+func (e *UnschedulableError) Error() string {
+	details := make([]string, len(e.Hints))
+	for i, h := range e.Hints {
+		details[i] = fmt.Sprintf("%s matched by %d of %d nodes", h, e.Matches[h.String()], e.Nodes)
+	}
+	return fmt.Sprintf("app %q is unschedulable in pool %q, no node satisfies all its scheduling hints: %s",
+		e.App, e.Pool, strings.Join(details, ", "))
+}
+
+// filterBySchedulingHints returns the nodes satisfying all the scheduling
+// hints of the app.
+func (s *segregatedScheduler) filterBySchedulingHints(a provision.App, nodes []cluster.Node) ([]cluster.Node, error) {
+	hints, err := schedulingHintsForApp(a)
+	if err != nil || len(hints) == 0 {
+		return nodes, err
+	}
+	var withDaemon bool
+	for _, h := range hints {
+		withDaemon = withDaemon || h.needsDaemon()
+	}
+	matches := make(map[string]int)
+	var nodeList []cluster.Node
+	for _, n := range nodes {
+		attrs, err := nodeAttributes(n, withDaemon)
+		if err != nil {
+			log.Errorf("[scheduler] unable to read attributes of node %q: %s", n.Address, err)
+		}
+		matchesAll := true
+		for _, h := range hints {
+			if h.matches(attrs) {
+				matches[h.String()]++
+			} else {
+				matchesAll = false
+			}
+		}
+		if matchesAll {
+			nodeList = append(nodeList, n)
+		}
+	}
+	if len(nodeList) == 0 {
+		return nil, &UnschedulableError{
+			App:     a.GetName(),
+			Pool:    a.GetPool(),
+			Nodes:   len(nodes),
+			Hints:   hints,
+			Matches: matches,
+		}
+	}
+	return nodeList, nil
+}
+
+// NodeHeterogeneity holds the attributes of a node in a heterogeneity
+// report.
+type NodeHeterogeneity struct {
+	Address    string            `json:"address"`
+	Attributes map[string]string `json:"attributes"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// PoolHeterogeneity summarizes the differences between the nodes of a pool.
+type PoolHeterogeneity struct {
+	Pool  string              `json:"pool"`
+	Nodes []NodeHeterogeneity `json:"nodes"`
+	// Attributes maps each attribute value to the nodes with it.
+	Attributes map[string]map[string][]string `json:"attributes"`
+	// Heterogeneous lists the attributes that differ among the nodes.
+	Heterogeneous []string `json:"heterogeneous"`
+}
+
+// PoolHeterogeneity returns the attributes of the nodes in the pool and
+// which of them differ among the nodes.
+func (p *dockerProvisioner) PoolHeterogeneity(pool string) (*PoolHeterogeneity, error) {
+	nodes, err := p.Cluster().NodesForMetadata(map[string]string{provision.PoolMetadataName: pool})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Address < nodes[j].Address })
+	result := PoolHeterogeneity{
+		Pool:          pool,
+		Nodes:         make([]NodeHeterogeneity, len(nodes)),
+		Attributes:    make(map[string]map[string][]string),
+		Heterogeneous: []string{},
+	}
+	for i, n := range nodes {
+		attrs, err := nodeAttributes(n, true)
+		result.Nodes[i] = NodeHeterogeneity{Address: n.Address, Attributes: attrs}
+		if err != nil {
+			result.Nodes[i].Error = err.Error()
+		}
+	}
+	var names []string
+	for _, n := range result.Nodes {
+		for name := range n.Attributes {
+			if _, ok := result.Attributes[name]; !ok {
+				result.Attributes[name] = make(map[string][]string)
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range names {
+		for _, n := range result.Nodes {
+			value := n.Attributes[name]
+			result.Attributes[name][value] = append(result.Attributes[name][value], n.Address)
+		}
+		if len(result.Attributes[name]) > 1 {
+			result.Heterogeneous = append(result.Heterogeneous, name)
+		}
+	}
+	sort.Strings(result.Heterogeneous)
+	return &result, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/api"
+	"github.com/tsuru/tsuru/provision/pool"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestParseSchedulingHints(c *check.C) {
+	hints, err := parseSchedulingHints(" cpu-flags=avx2, disk-class!=hdd ,")
+	c.Assert(err, check.IsNil)
+	c.Assert(hints, check.DeepEquals, []SchedulingHint{
+		{Attribute: "cpu-flags", Value: "avx2"},
+		{Attribute: "disk-class", Value: "hdd", Negate: true},
+	})
+	c.Assert(hints[1].String(), check.Equals, "disk-class!=hdd")
+	hints, err = parseSchedulingHints("")
+	c.Assert(err, check.IsNil)
+	c.Assert(hints, check.IsNil)
+	_, err = parseSchedulingHints("cpu-flags")
+	c.Assert(err, check.ErrorMatches, `invalid scheduling hint "cpu-flags", expected attribute=value or attribute!=value`)
+	_, err = parseSchedulingHints("=ssd")
+	c.Assert(err, check.NotNil)
+}
+
+func (s *S) TestSchedulingHintMatches(c *check.C) {
+	attrs := map[string]string{"cpu-flags": "sse4, avx2", "disk-class": "ssd"}
+	c.Assert(SchedulingHint{Attribute: "cpu-flags", Value: "avx2"}.matches(attrs), check.Equals, true)
+	c.Assert(SchedulingHint{Attribute: "cpu-flags", Value: "avx512"}.matches(attrs), check.Equals, false)
+	c.Assert(SchedulingHint{Attribute: "disk-class", Value: "hdd", Negate: true}.matches(attrs), check.Equals, true)
+	c.Assert(SchedulingHint{Attribute: "disk-class", Value: "ssd", Negate: true}.matches(attrs), check.Equals, false)
+	c.Assert(SchedulingHint{Attribute: "gpu", Value: "true"}.matches(attrs), check.Equals, false)
+}
+
+func (s *S) TestFilterBySchedulingHints(c *check.C) {
+	nodes := []cluster.Node{
+		{Address: "http://server1:2375", Metadata: map[string]string{"pool": "mypool", "cpu-flags": "sse4,avx2", "disk-class": "ssd"}},
+		{Address: "http://server2:2375", Metadata: map[string]string{"pool": "mypool", "cpu-flags": "sse4", "disk-class": "ssd"}},
+		{Address: "http://server3:2375", Metadata: map[string]string{"pool": "mypool", "cpu-flags": "sse4,avx2", "disk-class": "hdd"}},
+	}
+	sched := segregatedScheduler{}
+	a := provisiontest.NewFakeApp("myapp", "python", 0)
+	a.Pool = "mypool"
+	filtered, err := sched.filterBySchedulingHints(a, nodes)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes)
+	a.Metadata = appTypes.Metadata{Annotations: []appTypes.MetadataItem{{Name: AnnotationSchedulingHints, Value: "cpu-flags=avx2,disk-class=ssd"}}}
+	filtered, err = sched.filterBySchedulingHints(a, nodes)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[:1])
+	a.Metadata = appTypes.Metadata{Annotations: []appTypes.MetadataItem{{Name: AnnotationSchedulingHints, Value: "cpu-flags=avx512,disk-class=ssd"}}}
+	_, err = sched.filterBySchedulingHints(a, nodes)
+	c.Assert(err, check.FitsTypeOf, &UnschedulableError{})
+	c.Assert(err, check.ErrorMatches, `app "myapp" is unschedulable in pool "mypool", no node satisfies all its scheduling hints: cpu-flags=avx512 matched by 0 of 3 nodes, disk-class=ssd matched by 2 of 3 nodes`)
+}
+
+func (s *S) TestPoolHeterogeneity(c *check.C) {
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	report, err := p.PoolHeterogeneity("test-default")
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Pool, check.Equals, "test-default")
+	c.Assert(report.Nodes, check.HasLen, 2)
+	for _, n := range report.Nodes {
+		c.Assert(n.Error, check.Equals, "")
+		c.Assert(n.Attributes[NodeAttrOperatingSystem], check.Not(check.Equals), "")
+	}
+	c.Assert(report.Attributes["m1"], check.DeepEquals, map[string][]string{
+		"v1": {report.Nodes[0].Address},
+		"":   {report.Nodes[1].Address},
+	})
+	c.Assert(report.Heterogeneous, check.DeepEquals, []string{"m1"})
+}
+
+func (s *HandlersSuite) TestPoolHeterogeneityHandler(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{Name: "test-default"})
+	c.Assert(err, check.IsNil)
+	request, err := http.NewRequest(http.MethodGet, "/pools/test-default/heterogeneity", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	api.RunServer(true).ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusOK, check.Commentf("body: %s", recorder.Body.String()))
+	c.Assert(recorder.Header().Get("Content-Type"), check.Equals, "application/json")
+	var report PoolHeterogeneity
+	err = json.Unmarshal(recorder.Body.Bytes(), &report)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Nodes, check.HasLen, 1)
+	c.Assert(report.Nodes[0].Address, check.Equals, s.server.URL())
+	c.Assert(report.Heterogeneous, check.DeepEquals, []string{})
+}
+
+func (s *HandlersSuite) TestPoolHeterogeneityHandlerPoolNotFound(c *check.C) {
+	request, err := http.NewRequest(http.MethodGet, "/pools/unknown/heterogeneity", nil)
+	c.Assert(err, check.IsNil)
+	request.Header.Set("Authorization", "bearer "+s.token.GetValue())
+	recorder := httptest.NewRecorder()
+	api.RunServer(true).ServeHTTP(recorder, request)
+	c.Assert(recorder.Code, check.Equals, http.StatusNotFound)
+}
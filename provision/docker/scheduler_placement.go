@@ -0,0 +1,56 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/provision"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// PlacementError is returned when no node available for an app satisfies
+// its placement constraints.
+type PlacementError struct {
+	App         string
+	Pool        string
+	Nodes       int
+	Constraints []appTypes.PlacementConstraint
+}
+
+func (e *PlacementError) Error() string {
+	constraints := make([]string, len(e.Constraints))
+	for i, c := range e.Constraints {
+		constraints[i] = c.String()
+	}
+	return fmt.Sprintf("app %q is unschedulable in pool %q, none of the %d nodes satisfies its placement constraints: %s",
+		e.App, e.Pool, e.Nodes, strings.Join(constraints, ", "))
+}
+
+// filterByPlacement returns the nodes satisfying the placement constraints
+// of the app.
+func filterByPlacement(a provision.App, nodes []cluster.Node) ([]cluster.Node, error) {
+	constraints := a.GetPlacementConstraints()
+	if len(constraints) == 0 {
+		return nodes, nil
+	}
+	var nodeList []cluster.Node
+	for _, n := range nodes {
+		if appTypes.PlacementMatches(constraints, n.CleanMetadata()) {
+			nodeList = append(nodeList, n)
+		}
+	}
+	if len(nodeList) == 0 {
+		return nil, &PlacementError{
+			App:         a.GetName(),
+			Pool:        a.GetPool(),
+			Nodes:       len(nodes),
+			Constraints: constraints,
+		}
+	}
+	return nodeList, nil
+}
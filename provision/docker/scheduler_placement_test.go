@@ -0,0 +1,38 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestFilterByPlacement(c *check.C) {
+	nodes := []cluster.Node{
+		{Address: "http://server1:2375", Metadata: map[string]string{"pool": "mypool", "disk": "ssd", "zone": "a"}},
+		{Address: "http://server2:2375", Metadata: map[string]string{"pool": "mypool", "disk": "hdd", "zone": "b"}},
+		{Address: "http://server3:2375", Metadata: map[string]string{"pool": "mypool", "disk": "ssd", "zone": "c"}},
+	}
+	a := provisiontest.NewFakeApp("myapp", "python", 0)
+	a.Pool = "mypool"
+	filtered, err := filterByPlacement(a, nodes)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes)
+	a.Placement = []appTypes.PlacementConstraint{
+		{Key: "disk", Operator: appTypes.PlacementOpIn, Values: []string{"ssd"}},
+		{Key: "zone", Operator: appTypes.PlacementOpIn, Values: []string{"a", "b"}},
+	}
+	filtered, err = filterByPlacement(a, nodes)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[:1])
+	a.Placement = []appTypes.PlacementConstraint{
+		{Key: "gpu", Operator: appTypes.PlacementOpExists},
+	}
+	_, err = filterByPlacement(a, nodes)
+	c.Assert(err, check.FitsTypeOf, &PlacementError{})
+	c.Assert(err, check.ErrorMatches, `app "myapp" is unschedulable in pool "mypool", none of the 3 nodes satisfies its placement constraints: gpu`)
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/pool"
+)
+
+// filterBySpotPolicy restricts the nodes to the non-spot ones while the app
+// process has fewer units on them than the minimum configured in the pool,
+// so that spot evictions never take down every unit of the process.
+func (s *segregatedScheduler) filterBySpotPolicy(a provision.App, nodes []cluster.Node, process string) ([]cluster.Node, error) {
+	p, err := pool.GetPoolByName(context.TODO(), a.GetPool())
+	if err != nil {
+		log.Debugf("[scheduler] ignoring spot policy, unable to get pool %q: %s", a.GetPool(), err)
+		return nodes, nil
+	}
+	spotKey, spotValue := p.GetSpotNodeMetadata()
+	minOnDemand := p.GetMinOnDemandUnits()
+	if spotKey == "" || minOnDemand == 0 {
+		return nodes, nil
+	}
+	var onDemandNodes []cluster.Node
+	for _, n := range nodes {
+		if n.Metadata[spotKey] != spotValue {
+			onDemandNodes = append(onDemandNodes, n)
+		}
+	}
+	if len(onDemandNodes) == len(nodes) {
+		return nodes, nil
+	}
+	if len(onDemandNodes) == 0 {
+		log.Debugf("[scheduler] no on-demand nodes available for app %q, using spot nodes", a.GetName())
+		return nodes, nil
+	}
+	hosts, _ := s.nodesToHosts(onDemandNodes)
+	countMap, err := s.aggregateContainersByHostAppProcess(hosts, a.GetName(), process)
+	if err != nil {
+		return nil, err
+	}
+	var onDemandUnits int
+	for _, count := range countMap {
+		onDemandUnits += count
+	}
+	if onDemandUnits < minOnDemand {
+		return onDemandNodes, nil
+	}
+	return nodes, nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/log"
+)
+
+const defaultSchedulerStrategy = "count"
+
+// SchedulerNode holds what is known about a candidate node when scheduling a
+// new unit.
+type SchedulerNode struct {
+	Address string
+	// Containers is the number of containers in the node.
+	Containers int
+	// AppContainers is the number of containers of the same app and process
+	// of the new unit in the node.
+	AppContainers int
+	// ReservedMemory is the memory, in bytes, reserved by the plans of the
+	// containers in the node. TotalMemory is zero when the node doesn't have
+	// the metadata set in docker:scheduler:total-memory-metadata.
+	ReservedMemory int64
+	TotalMemory    int64
+	// ReservedCPUMilli is the CPU, in thousandths of a CPU, reserved by the
+	// plans of the containers in the node. TotalCPUMilli is zero when the
+	// node doesn't have the metadata set in
+	// docker:scheduler:total-cpu-metadata.
+	ReservedCPUMilli int
+	TotalCPUMilli    int
+	// HasImage is true when a container in the node already uses the image
+	// of the new unit.
+	HasImage bool
+}
+
+// SchedulerUnit describes the unit being scheduled.
+type SchedulerUnit struct {
+	App      string
+	Process  string
+	Image    string
+	Memory   int64
+	CPUMilli int
+	// PublishesPorts is set when the unit binds exposed ports to stable node
+	// ports, so it can't share a node with other units of its process.
+	PublishesPorts bool
+}
+
+// SchedulerStrategy scores the nodes able to receive a new unit. The node
+// with the lowest score is chosen, ties are broken by the order of the nodes.
+type SchedulerStrategy interface {
+	Score(node SchedulerNode, unit SchedulerUnit) float64
+}
+
+var schedulerStrategies = map[string]SchedulerStrategy{
+	"binpack": binpackStrategy{},
+	"spread":  spreadStrategy{},
+}
+
+// RegisterSchedulerStrategy makes a strategy available to pools under the
+// given name.
+func RegisterSchedulerStrategy(name string, strategy SchedulerStrategy) {
+	schedulerStrategies[name] = strategy
+}
+
+// schedulerStrategyForPool returns the strategy configured for the pool. It
+// returns nil when the pool uses the default count based balancing.
+func schedulerStrategyForPool(pool string) (SchedulerStrategy, error) {
+	var name string
+	if pool != "" {
+		name, _ = config.GetString("docker:scheduler:pool-strategies:" + pool)
+	}
+	if name == "" {
+		name, _ = config.GetString("docker:scheduler:strategy")
+	}
+	if name == "" || name == defaultSchedulerStrategy {
+		return nil, nil
+	}
+	strategy, ok := schedulerStrategies[name]
+	if !ok {
+		return nil, errors.Errorf("unknown scheduler strategy %q", name)
+	}
+	return strategy, nil
+}
+
+// nodeUsage returns the fraction of the node memory or CPU, whichever is
+// higher, that would be reserved after placing the unit in it. The returned
+// bool is false when the node totals are unknown.
+func nodeUsage(node SchedulerNode, unit SchedulerUnit) (float64, bool) {
+	var usage float64
+	var known bool
+	if node.TotalMemory > 0 {
+		usage = float64(node.ReservedMemory+unit.Memory) / float64(node.TotalMemory)
+		known = true
+	}
+	if node.TotalCPUMilli > 0 {
+		usage = math.Max(usage, float64(node.ReservedCPUMilli+unit.CPUMilli)/float64(node.TotalCPUMilli))
+		known = true
+	}
+	return usage, known
+}
+
+// spreadStrategy prefers nodes with fewer units of the same app and process,
+// then the least used nodes.
+type spreadStrategy struct{}
+
+func (spreadStrategy) Score(node SchedulerNode, unit SchedulerUnit) float64 {
+	score := float64(node.AppContainers) * 1000
+	if usage, ok := nodeUsage(node, unit); ok {
+		score += usage * 100
+	} else {
+		score += float64(node.Containers)
+	}
+	if node.HasImage {
+		score -= 0.5
+	}
+	return score
+}
+
+// binpackStrategy prefers the most used nodes that still fit the unit,
+// leaving the least used ones free to be removed.
+type binpackStrategy struct{}
+
+func (binpackStrategy) Score(node SchedulerNode, unit SchedulerUnit) float64 {
+	var score float64
+	if usage, ok := nodeUsage(node, unit); ok {
+		if usage > 1 {
+			return usage * 100
+		}
+		score = -usage * 100
+	} else {
+		score = -float64(node.Containers)
+	}
+	if node.HasImage {
+		score -= 0.5
+	}
+	return score
+}
+
+func (s *segregatedScheduler) schedulerNodes(nodes []cluster.Node, unit SchedulerUnit) ([]SchedulerNode, error) {
+	hosts, _ := s.nodesToHosts(nodes)
+	containers, err := s.provisioner.ListContainers(bson.M{"hostaddr": bson.M{"$in": hosts}, "id": bson.M{"$nin": s.ignoredContainers}})
+	if err != nil {
+		return nil, err
+	}
+	schedNodes := make([]SchedulerNode, len(nodes))
+	hostIndex := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		schedNodes[i].Address = n.Address
+		hostIndex[hosts[i]] = i
+		if s.TotalMemoryMetadata != "" {
+			totalMemory, _ := strconv.ParseFloat(n.Metadata[s.TotalMemoryMetadata], 64)
+			schedNodes[i].TotalMemory = int64(totalMemory)
+		}
+		if s.TotalCPUMetadata != "" {
+			totalCPU, _ := strconv.ParseFloat(n.Metadata[s.TotalCPUMetadata], 64)
+			schedNodes[i].TotalCPUMilli = int(totalCPU * 1000)
+		}
+	}
+	apps := map[string]*app.App{}
+	for _, cont := range containers {
+		i, ok := hostIndex[cont.HostAddr]
+		if !ok {
+			continue
+		}
+		schedNodes[i].Containers++
+		if cont.AppName == unit.App && cont.ProcessName == unit.Process {
+			schedNodes[i].AppContainers++
+		}
+		if unit.Image != "" && cont.Image == unit.Image {
+			schedNodes[i].HasImage = true
+		}
+		contApp, ok := apps[cont.AppName]
+		if !ok {
+			contApp, err = app.GetByName(context.TODO(), cont.AppName)
+			if err != nil {
+				log.Debugf("[scheduler] ignoring resources of container %q: %s", cont.ID, err)
+			}
+			apps[cont.AppName] = contApp
+		}
+		if contApp != nil {
+			schedNodes[i].ReservedMemory += contApp.GetMemory()
+			schedNodes[i].ReservedCPUMilli += contApp.GetMilliCPU()
+		}
+	}
+	return schedNodes, nil
+}
+
+func (s *segregatedScheduler) chooseNodeByStrategy(strategy SchedulerStrategy, nodes []cluster.Node, unit SchedulerUnit) (string, error) {
+	schedNodes, err := s.schedulerNodes(nodes, unit)
+	if err != nil {
+		return "", err
+	}
+	if len(schedNodes) == 0 {
+		return "", errors.New("no nodes available")
+	}
+	var chosen string
+	minScore := math.Inf(1)
+	for _, n := range schedNodes {
+		score := strategy.Score(n, unit)
+		log.Debugf("[scheduler] Node %q score for unit of %q: %f", n.Address, unit.App, score)
+		if chosen == "" || score < minScore {
+			minScore = score
+			chosen = n.Address
+		}
+	}
+	return chosen, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	"github.com/tsuru/tsuru/provision/pool"
+	check "gopkg.in/check.v1"
+)
+
+type fixedStrategy map[string]float64
+
+func (s fixedStrategy) Score(node SchedulerNode, unit SchedulerUnit) float64 {
+	return s[node.Address]
+}
+
+func (s *S) TestSchedulerStrategyForPool(c *check.C) {
+	defer config.Unset("docker:scheduler:strategy")
+	defer config.Unset("docker:scheduler:pool-strategies")
+	strategy, err := schedulerStrategyForPool("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(strategy, check.IsNil)
+	config.Set("docker:scheduler:strategy", "spread")
+	config.Set("docker:scheduler:pool-strategies:pool1", "binpack")
+	config.Set("docker:scheduler:pool-strategies:pool2", "count")
+	config.Set("docker:scheduler:pool-strategies:pool3", "unknown")
+	strategy, err = schedulerStrategyForPool("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(strategy, check.Equals, binpackStrategy{})
+	strategy, err = schedulerStrategyForPool("pool2")
+	c.Assert(err, check.IsNil)
+	c.Assert(strategy, check.IsNil)
+	strategy, err = schedulerStrategyForPool("other")
+	c.Assert(err, check.IsNil)
+	c.Assert(strategy, check.Equals, spreadStrategy{})
+	_, err = schedulerStrategyForPool("pool3")
+	c.Assert(err, check.ErrorMatches, `unknown scheduler strategy "unknown"`)
+}
+
+func (s *S) TestSpreadStrategyScore(c *check.C) {
+	unit := SchedulerUnit{App: "myapp", Process: "web", Memory: 100}
+	strategy := spreadStrategy{}
+	withAppUnit := strategy.Score(SchedulerNode{AppContainers: 1, ReservedMemory: 0, TotalMemory: 1000}, unit)
+	busy := strategy.Score(SchedulerNode{ReservedMemory: 800, TotalMemory: 1000}, unit)
+	idle := strategy.Score(SchedulerNode{ReservedMemory: 100, TotalMemory: 1000}, unit)
+	idleWithImage := strategy.Score(SchedulerNode{ReservedMemory: 100, TotalMemory: 1000, HasImage: true}, unit)
+	c.Assert(idleWithImage < idle, check.Equals, true)
+	c.Assert(idle < busy, check.Equals, true)
+	c.Assert(busy < withAppUnit, check.Equals, true)
+	fewContainers := strategy.Score(SchedulerNode{Containers: 1}, unit)
+	manyContainers := strategy.Score(SchedulerNode{Containers: 5}, unit)
+	c.Assert(fewContainers < manyContainers, check.Equals, true)
+}
+
+func (s *S) TestBinpackStrategyScore(c *check.C) {
+	unit := SchedulerUnit{App: "myapp", Process: "web", Memory: 100, CPUMilli: 500}
+	strategy := binpackStrategy{}
+	busy := strategy.Score(SchedulerNode{ReservedMemory: 800, TotalMemory: 1000}, unit)
+	idle := strategy.Score(SchedulerNode{ReservedMemory: 100, TotalMemory: 1000}, unit)
+	busyCPU := strategy.Score(SchedulerNode{ReservedMemory: 100, TotalMemory: 1000, ReservedCPUMilli: 1000, TotalCPUMilli: 2000}, unit)
+	full := strategy.Score(SchedulerNode{ReservedMemory: 950, TotalMemory: 1000}, unit)
+	c.Assert(busy < busyCPU, check.Equals, true)
+	c.Assert(busyCPU < idle, check.Equals, true)
+	c.Assert(idle < full, check.Equals, true)
+	fewContainers := strategy.Score(SchedulerNode{Containers: 1}, unit)
+	manyContainers := strategy.Score(SchedulerNode{Containers: 5}, unit)
+	c.Assert(manyContainers < fewContainers, check.Equals, true)
+}
+
+func (s *S) TestChooseNodeByStrategy(c *check.C) {
+	sched := segregatedScheduler{provisioner: s.p, TotalMemoryMetadata: "totalMemory"}
+	coll := s.p.Collection()
+	defer coll.Close()
+	err := coll.Insert(
+		container.Container{Container: types.Container{ID: "c1", AppName: "myapp", ProcessName: "web", HostAddr: "server1", Image: "tsuru/app-myapp:v1"}},
+		container.Container{Container: types.Container{ID: "c2", AppName: "other", HostAddr: "server1"}},
+		container.Container{Container: types.Container{ID: "c3", AppName: "other", HostAddr: "server2"}},
+	)
+	c.Assert(err, check.IsNil)
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234", Metadata: map[string]string{"totalMemory": "1000"}},
+		{Address: "http://server2:1234", Metadata: map[string]string{"totalMemory": "2000"}},
+		{Address: "http://server3:1234"},
+	}
+	unit := SchedulerUnit{App: "myapp", Process: "web", Image: "tsuru/app-myapp:v1"}
+	schedNodes, err := sched.schedulerNodes(nodes, unit)
+	c.Assert(err, check.IsNil)
+	c.Assert(schedNodes, check.DeepEquals, []SchedulerNode{
+		{Address: "http://server1:1234", Containers: 2, AppContainers: 1, TotalMemory: 1000, HasImage: true},
+		{Address: "http://server2:1234", Containers: 1, TotalMemory: 2000},
+		{Address: "http://server3:1234"},
+	})
+	node, err := sched.chooseNodeByStrategy(fixedStrategy{"http://server1:1234": 2, "http://server2:1234": 1, "http://server3:1234": 1}, nodes, unit)
+	c.Assert(err, check.IsNil)
+	c.Assert(node, check.Equals, "http://server2:1234")
+}
+
+func (s *S) TestChooseNodeByStrategyConsideringPoolSpreadKey(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{
+		Name:   "pool-zones",
+		Labels: map[string]string{"spread-key": "zone"},
+	})
+	c.Assert(err, check.IsNil)
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234", Metadata: map[string]string{"pool": "pool-zones", "zone": "a"}},
+		{Address: "http://server2:1234", Metadata: map[string]string{"pool": "pool-zones", "zone": "a"}},
+		{Address: "http://server3:1234", Metadata: map[string]string{"pool": "pool-zones", "zone": "b"}},
+	}
+	coll := s.p.Collection()
+	defer coll.Close()
+	err = coll.Insert(container.Container{Container: types.Container{ID: "c1", AppName: "myapp", ProcessName: "web", HostAddr: "server1"}})
+	c.Assert(err, check.IsNil)
+	sched := segregatedScheduler{provisioner: s.p}
+	strategy := fixedStrategy{"http://server1:1234": 1, "http://server2:1234": 1, "http://server3:1234": 5}
+	node, err := sched.chooseNodeToAddWithStrategy(strategy, nodes, "", SchedulerUnit{App: "myapp", Process: "web"})
+	c.Assert(err, check.IsNil)
+	c.Assert(node, check.Equals, "http://server3:1234")
+	node, err = sched.chooseNodeToAddWithStrategy(strategy, nodes, "", SchedulerUnit{App: "myapp", Process: "worker"})
+	c.Assert(err, check.IsNil)
+	c.Assert(node, check.Equals, "http://server1:1234")
+}
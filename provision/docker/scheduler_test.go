@@ -751,6 +751,99 @@ func (s *S) TestChooseNodeDistributesNodesConsideringMetadata(c *check.C) {
 	c.Assert(n3, check.Equals, 1)
 }
 
+func (s *S) TestChooseNodeDistributesNodesConsideringPoolSpreadKey(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{
+		Name:   "pool-zones",
+		Labels: map[string]string{"spread-key": "zone"},
+	})
+	c.Assert(err, check.IsNil)
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234", Metadata: map[string]string{
+			"pool": "pool-zones", "zone": "a", "hostname": "server1",
+		}},
+		{Address: "http://server2:1234", Metadata: map[string]string{
+			"pool": "pool-zones", "zone": "a", "hostname": "server2",
+		}},
+		{Address: "http://server3:1234", Metadata: map[string]string{
+			"pool": "pool-zones", "zone": "b", "hostname": "server3",
+		}},
+	}
+	c.Assert(poolSpreadKey(nodes), check.Equals, "zone")
+	sched := segregatedScheduler{provisioner: s.p}
+	contColl := s.p.Collection()
+	defer contColl.Close()
+	for i := 0; i < 2; i++ {
+		cont := container.Container{Container: types.Container{Name: fmt.Sprintf("unit%d", i), AppName: "anomander", ProcessName: "rake"}}
+		err = contColl.Insert(cont)
+		c.Assert(err, check.IsNil)
+		_, err = sched.chooseNodeToAdd(nodes, cont.Name, "anomander", "rake")
+		c.Assert(err, check.IsNil)
+	}
+	n3, err := contColl.Find(bson.M{"hostaddr": "server3"}).Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(n3, check.Equals, 1)
+	skew, err := s.p.containerSkewInDomains([]*cluster.Node{&nodes[0], &nodes[1], &nodes[2]}, "zone")
+	c.Assert(err, check.IsNil)
+	c.Assert(skew, check.Equals, 0)
+	err = contColl.Insert(container.Container{Container: types.Container{Name: "unit-extra", AppName: "anomander", ProcessName: "rake", HostAddr: "server1"}})
+	c.Assert(err, check.IsNil)
+	skew, err = s.p.containerSkewInDomains([]*cluster.Node{&nodes[0], &nodes[1], &nodes[2]}, "zone")
+	c.Assert(err, check.IsNil)
+	c.Assert(skew, check.Equals, 1)
+}
+
+func (s *S) TestFilterBySpotPolicy(c *check.C) {
+	err := pool.AddPool(context.TODO(), pool.AddPoolOptions{
+		Name:   "pool-spot",
+		Labels: map[string]string{"spot-node-metadata": "lifecycle=spot", "spot-min-on-demand-units": "2"},
+	})
+	c.Assert(err, check.IsNil)
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234", Metadata: map[string]string{"pool": "pool-spot"}},
+		{Address: "http://server2:1234", Metadata: map[string]string{"pool": "pool-spot", "lifecycle": "spot"}},
+		{Address: "http://server3:1234", Metadata: map[string]string{"pool": "pool-spot", "lifecycle": "spot"}},
+	}
+	a := &app.App{Name: "anomander", Pool: "pool-spot"}
+	sched := segregatedScheduler{provisioner: s.p}
+	filtered, err := sched.filterBySpotPolicy(a, nodes, "web")
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[:1])
+	contColl := s.p.Collection()
+	defer contColl.Close()
+	for i := 0; i < 2; i++ {
+		err = contColl.Insert(container.Container{Container: types.Container{Name: fmt.Sprintf("unit%d", i), AppName: "anomander", ProcessName: "web", HostAddr: "server1"}})
+		c.Assert(err, check.IsNil)
+	}
+	filtered, err = sched.filterBySpotPolicy(a, nodes, "web")
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes)
+	filtered, err = sched.filterBySpotPolicy(a, nodes, "worker")
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[:1])
+	filtered, err = sched.filterBySpotPolicy(a, nodes[1:], "worker")
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[1:])
+}
+
+func (s *S) TestFilterByArchitecture(c *check.C) {
+	nodes := []cluster.Node{
+		{Address: "http://server1:1234", Metadata: map[string]string{"arch": "amd64"}},
+		{Address: "http://server2:1234", Metadata: map[string]string{"arch": "arm64"}},
+		{Address: "http://server3:1234", Metadata: map[string]string{}},
+	}
+	filtered, err := filterByArchitecture(nodes, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes)
+	filtered, err = filterByArchitecture(nodes, []string{"arm64"})
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes[1:])
+	filtered, err = filterByArchitecture(nodes, []string{"amd64", "arm64"})
+	c.Assert(err, check.IsNil)
+	c.Assert(filtered, check.DeepEquals, nodes)
+	_, err = filterByArchitecture(nodes[:2], []string{"ppc64le"})
+	c.Assert(err, check.ErrorMatches, "no nodes available for image architectures ppc64le")
+}
+
 func (s *S) TestChooseContainerToBeRemoved(c *check.C) {
 	nodes := []cluster.Node{
 		{Address: "http://server1:1234"},
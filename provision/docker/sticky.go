@@ -0,0 +1,73 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"fmt"
+
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/docker/container"
+)
+
+// stickyVolumes assigns sticky storage volumes to new units, reusing the
+// volumes of the units they replace so that their data is preserved.
+type stickyVolumes struct {
+	enabled bool
+	appName string
+	free    map[string][]string
+}
+
+func newStickyVolumes(a provision.App, toRemove []container.Container) *stickyVolumes {
+	volumes := &stickyVolumes{
+		enabled: container.StickyStorageMountPoint(a) != "",
+		appName: a.GetName(),
+		free:    make(map[string][]string),
+	}
+	for _, c := range toRemove {
+		if c.StickyVolume != "" {
+			volumes.free[c.ProcessName] = append(volumes.free[c.ProcessName], c.StickyVolume)
+		}
+	}
+	return volumes
+}
+
+// next returns the volume for a new unit of the process, it's empty when the
+// app doesn't use sticky storage.
+func (v *stickyVolumes) next(process string) string {
+	if !v.enabled {
+		return ""
+	}
+	if free := v.free[process]; len(free) > 0 {
+		v.free[process] = free[1:]
+		return free[0]
+	}
+	return fmt.Sprintf("%s-%s-%s", v.appName, process, randomString())
+}
+
+// removeMovedStickyVolume removes the sticky volume of a removed unit from
+// its node when the volume was migrated to a unit in another node.
+func (p *dockerProvisioner) removeMovedStickyVolume(old container.Container, added []container.Container) {
+	if old.StickyVolume == "" {
+		return
+	}
+	moved := false
+	for _, c := range added {
+		if c.StickyVolume == old.StickyVolume && c.HostAddr != old.HostAddr {
+			moved = true
+			break
+		}
+	}
+	if !moved {
+		return
+	}
+	client, err := p.hostClient(old.HostAddr)
+	if err == nil {
+		err = client.RemoveVolume(old.StickyVolume)
+	}
+	if err != nil {
+		log.Errorf("unable to remove sticky volume %s from node %s after moving it: %s", old.StickyVolume, old.HostAddr, err)
+	}
+}
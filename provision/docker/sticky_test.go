@@ -0,0 +1,123 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync/atomic"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/provision/docker/container"
+	"github.com/tsuru/tsuru/provision/docker/types"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	"github.com/tsuru/tsuru/safe"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestStickyVolumesNext(c *check.C) {
+	a := provisiontest.NewFakeApp("myapp", "python", 0)
+	volumes := newStickyVolumes(a, []container.Container{
+		{Container: types.Container{ProcessName: "web", StickyVolume: "myapp-web-1"}},
+	})
+	c.Assert(volumes.next("web"), check.Equals, "")
+	a.Metadata = appTypes.Metadata{Annotations: []appTypes.MetadataItem{{Name: container.AnnotationStickyStorage, Value: "/data"}}}
+	volumes = newStickyVolumes(a, []container.Container{
+		{Container: types.Container{ProcessName: "web", StickyVolume: "myapp-web-1"}},
+		{Container: types.Container{ProcessName: "worker"}},
+	})
+	c.Assert(volumes.next("web"), check.Equals, "myapp-web-1")
+	c.Assert(volumes.next("web"), check.Matches, "myapp-web-[0-9a-f]{20}")
+	c.Assert(volumes.next("worker"), check.Matches, "myapp-worker-[0-9a-f]{20}")
+}
+
+func (s *S) TestMoveContainersStickyStorage(c *check.C) {
+	ctx := context.TODO()
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	appInstance.Metadata = appTypes.Metadata{Annotations: []appTypes.MetadataItem{{Name: container.AnnotationStickyStorage, Value: "/data"}}}
+	defer p.Destroy(ctx, appInstance)
+	p.Provision(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(p, appInstance, nil)
+	c.Assert(err, check.IsNil)
+	coll := p.Collection()
+	defer coll.Close()
+	defer coll.RemoveAll(bson.M{"appname": appInstance.GetName()})
+	added, err := addContainersWithHost(context.TODO(), &changeUnitsPipelineArgs{
+		toHost:      "localhost",
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 2}},
+		app:         appInstance,
+		version:     version,
+		provisioner: p,
+	})
+	c.Assert(err, check.IsNil)
+	var volumes []string
+	for _, cont := range added {
+		c.Assert(cont.StickyVolume, check.Matches, "myapp-web-.+")
+		volumes = append(volumes, cont.StickyVolume)
+	}
+	sort.Strings(volumes)
+	var stops, removedVolumes int32
+	defaultHandler := s.extraServer.DefaultHandler()
+	s.extraServer.CustomHandler("/containers/.*/stop", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&stops, 1)
+		defaultHandler.ServeHTTP(w, r)
+	}))
+	s.extraServer.CustomHandler("/volumes/.*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Assert(r.Method, check.Equals, http.MethodDelete)
+		c.Assert(r.URL.Path, check.Matches, "/volumes/myapp-web-.+")
+		atomic.AddInt32(&removedVolumes, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	s.extraServer.CustomHandler("/containers/.*/archive", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Assert(r.Method, check.Equals, http.MethodGet)
+		c.Assert(atomic.LoadInt32(&stops) > 0, check.Equals, true)
+		c.Assert(r.URL.Query().Get("path"), check.Equals, "/data")
+		w.Header().Set("Content-Type", "application/x-tar")
+		w.Write([]byte("volume data"))
+	}))
+	var uploads int32
+	s.server.CustomHandler("/containers/.*/archive", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Assert(r.Method, check.Equals, http.MethodPut)
+		c.Assert(r.URL.Query().Get("path"), check.Equals, "/")
+		data, _ := ioutil.ReadAll(r.Body)
+		c.Assert(string(data), check.Equals, "volume data")
+		atomic.AddInt32(&uploads, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	appStruct := s.newAppFromFake(appInstance)
+	appStruct.Metadata = appInstance.Metadata
+	err = s.conn.Apps().Insert(appStruct)
+	c.Assert(err, check.IsNil)
+	err = p.MoveContainers(context.TODO(), "localhost", "127.0.0.1", safe.NewBuffer(nil))
+	c.Assert(err, check.IsNil)
+	c.Assert(atomic.LoadInt32(&uploads), check.Equals, int32(2))
+	c.Assert(atomic.LoadInt32(&removedVolumes), check.Equals, int32(2))
+	containers, err := p.listContainersByHost("127.0.0.1")
+	c.Assert(err, check.IsNil)
+	c.Assert(containers, check.HasLen, 2)
+	var movedVolumes []string
+	for _, cont := range containers {
+		movedVolumes = append(movedVolumes, cont.StickyVolume)
+	}
+	sort.Strings(movedVolumes)
+	c.Assert(movedVolumes, check.DeepEquals, volumes)
+}
+
+func (s *S) TestMigrateStickyVolumeSourceUnreachable(c *check.C) {
+	p, err := s.startMultipleServersCluster()
+	c.Assert(err, check.IsNil)
+	s.extraServer.CustomHandler("/containers/.*/stop", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	from := &container.Container{Container: types.Container{ID: "source", HostAddr: "localhost", StickyVolume: "myapp-web-1"}}
+	to := &container.Container{Container: types.Container{ID: "dest", HostAddr: "127.0.0.1", StickyVolume: "myapp-web-1"}}
+	err = container.MigrateStickyVolume(p.ClusterClient(), from, to, "/data")
+	c.Assert(err, check.ErrorMatches, `unable to stop unit source in node localhost to copy its sticky volume myapp-web-1, the node may be unreachable: .*`)
+}
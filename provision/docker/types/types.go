@@ -5,8 +5,10 @@
 package types
 
 import (
+	"fmt"
 	"time"
 
+	docker "github.com/fsouza/go-dockerclient"
 	"github.com/globalsign/mgo/bson"
 	"github.com/tsuru/tsuru/provision"
 )
@@ -33,6 +35,33 @@ type Container struct {
 	LockedUntil             time.Time
 	Routable                bool `bson:"-"`
 	ExposedPort             string
+	StickyVolume            string
+	PublishedPorts          []PublishedPort `bson:",omitempty"`
+	NamedPorts              []NamedPort     `bson:",omitempty"`
+}
+
+// NamedPort is a named port of the unit process, bound to a port in the
+// node chosen by docker, just like the main port.
+type NamedPort struct {
+	Name     string
+	Port     int
+	HostPort string
+}
+
+func (p NamedPort) DockerPort() docker.Port {
+	return docker.Port(fmt.Sprintf("%d/tcp", p.Port))
+}
+
+// PublishedPort is a port exposed by the unit, bound to a stable port in
+// the node running it.
+type PublishedPort struct {
+	Port     int
+	Protocol string
+	HostPort int
+}
+
+func (p PublishedPort) DockerPort() docker.Port {
+	return docker.Port(fmt.Sprintf("%d/%s", p.Port, p.Protocol))
 }
 
 type DockerLogConfig struct {
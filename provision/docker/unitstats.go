@@ -0,0 +1,226 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+)
+
+const (
+	defaultUnitStatsInterval = 10 * time.Second
+	defaultUnitStatsSamples  = 30
+
+	// unitStatsWatchTimeout is how long the stats of an unit are still
+	// collected after they were last requested.
+	unitStatsWatchTimeout = 5 * time.Minute
+)
+
+var (
+	_ provision.UnitStatsProvisioner = &dockerProvisioner{}
+
+	unitStatsCollectors = statsCollectors{nodes: map[string]*nodeStatsCollector{}}
+)
+
+// statsCollectors holds the stats collector of each node.
+type statsCollectors struct {
+	sync.Mutex
+	nodes map[string]*nodeStatsCollector
+}
+
+// nodeStatsCollector periodically samples the docker stats of the units in a
+// node whose stats were recently requested, keeping the last samples of each
+// unit in memory. It stops once no unit is watched anymore.
+type nodeStatsCollector struct {
+	sync.Mutex
+	client   *docker.Client
+	interval time.Duration
+	samples  int
+	running  bool
+	units    map[string]*unitStatsBuffer
+}
+
+// unitStatsBuffer is a ring buffer with the last stats samples of an unit.
+type unitStatsBuffer struct {
+	samples  []provision.UnitStats
+	next     int
+	lastRead time.Time
+}
+
+func newUnitStatsBuffer(size int) *unitStatsBuffer {
+	return &unitStatsBuffer{samples: make([]provision.UnitStats, 0, size)}
+}
+
+func (b *unitStatsBuffer) add(stats provision.UnitStats) {
+	if len(b.samples) < cap(b.samples) {
+		b.samples = append(b.samples, stats)
+	} else {
+		b.samples[b.next] = stats
+	}
+	b.next = (b.next + 1) % cap(b.samples)
+}
+
+// list returns the samples in the buffer, from the oldest to the newest.
+func (b *unitStatsBuffer) list() []provision.UnitStats {
+	result := make([]provision.UnitStats, 0, len(b.samples))
+	if len(b.samples) == cap(b.samples) {
+		result = append(result, b.samples[b.next:]...)
+		return append(result, b.samples[:b.next]...)
+	}
+	return append(result, b.samples...)
+}
+
+// UnitStats returns the last stats samples of the unit, collected from the
+// docker stats API every docker:unit-stats:interval. Units are only sampled
+// while their stats are being requested, so the first request returns a
+// single sample.
+func (p *dockerProvisioner) UnitStats(ctx context.Context, a provision.App, unitID string) ([]provision.UnitStats, error) {
+	cont, err := p.GetContainer(unitID)
+	if err != nil {
+		return nil, err
+	}
+	if cont.AppName != a.GetName() {
+		return nil, &provision.UnitNotFoundError{ID: unitID}
+	}
+	collector, err := p.statsCollector(cont.HostAddr)
+	if err != nil {
+		return nil, err
+	}
+	return collector.watch(ctx, cont.ID)
+}
+
+func (p *dockerProvisioner) statsCollector(host string) (*nodeStatsCollector, error) {
+	node, err := dockercommon.GetNodeByHost(p.Cluster(), host)
+	if err != nil {
+		return nil, err
+	}
+	unitStatsCollectors.Lock()
+	defer unitStatsCollectors.Unlock()
+	if collector, ok := unitStatsCollectors.nodes[node.Address]; ok {
+		return collector, nil
+	}
+	client, err := node.Client()
+	if err != nil {
+		return nil, err
+	}
+	interval, _ := config.GetDuration("docker:unit-stats:interval")
+	if interval <= 0 {
+		interval = defaultUnitStatsInterval
+	}
+	samples, _ := config.GetInt("docker:unit-stats:samples")
+	if samples <= 0 {
+		samples = defaultUnitStatsSamples
+	}
+	collector := &nodeStatsCollector{
+		client:   client,
+		interval: interval,
+		samples:  samples,
+		units:    map[string]*unitStatsBuffer{},
+	}
+	unitStatsCollectors.nodes[node.Address] = collector
+	return collector, nil
+}
+
+func (c *nodeStatsCollector) watch(ctx context.Context, id string) ([]provision.UnitStats, error) {
+	c.Lock()
+	buffer, ok := c.units[id]
+	c.Unlock()
+	if !ok {
+		stats, err := containerStats(ctx, c.client, id)
+		if err != nil {
+			return nil, err
+		}
+		c.Lock()
+		buffer, ok = c.units[id]
+		if !ok {
+			buffer = newUnitStatsBuffer(c.samples)
+			buffer.add(unitStatsFromDocker(stats))
+			c.units[id] = buffer
+		}
+		c.Unlock()
+	}
+	c.Lock()
+	defer c.Unlock()
+	buffer.lastRead = time.Now()
+	if !c.running {
+		c.running = true
+		go c.run()
+	}
+	return buffer.list(), nil
+}
+
+func (c *nodeStatsCollector) run() {
+	for {
+		time.Sleep(c.interval)
+		c.Lock()
+		var ids []string
+		for id, buffer := range c.units {
+			if time.Since(buffer.lastRead) > unitStatsWatchTimeout {
+				delete(c.units, id)
+				continue
+			}
+			ids = append(ids, id)
+		}
+		if len(ids) == 0 {
+			c.running = false
+			c.Unlock()
+			return
+		}
+		c.Unlock()
+		for _, id := range ids {
+			c.collect(id)
+		}
+	}
+}
+
+func (c *nodeStatsCollector) collect(id string) {
+	stats, err := containerStats(context.Background(), c.client, id)
+	c.Lock()
+	defer c.Unlock()
+	if err != nil {
+		if _, ok := err.(*docker.NoSuchContainer); ok {
+			delete(c.units, id)
+			return
+		}
+		log.Errorf("[unit-stats] unable to get stats of unit %s: %s", id, err)
+		return
+	}
+	if buffer, ok := c.units[id]; ok {
+		buffer.add(unitStatsFromDocker(stats))
+	}
+}
+
+func unitStatsFromDocker(stats *docker.Stats) provision.UnitStats {
+	unitStats := provision.UnitStats{
+		Time:        stats.Read,
+		CPU:         cpuUsage(stats),
+		Memory:      memoryUsage(stats),
+		MemoryLimit: int64(stats.MemoryStats.Limit),
+	}
+	if unitStats.Time.IsZero() {
+		unitStats.Time = time.Now()
+	}
+	for _, network := range stats.Networks {
+		unitStats.NetworkRxBytes += network.RxBytes
+		unitStats.NetworkTxBytes += network.TxBytes
+	}
+	for _, entry := range stats.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			unitStats.BlockReadBytes += entry.Value
+		case "write":
+			unitStats.BlockWriteBytes += entry.Value
+		}
+	}
+	return unitStats
+}
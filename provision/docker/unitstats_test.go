@@ -0,0 +1,88 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestUnitStatsBuffer(c *check.C) {
+	buffer := newUnitStatsBuffer(3)
+	c.Assert(buffer.list(), check.HasLen, 0)
+	for i := 1; i <= 2; i++ {
+		buffer.add(provision.UnitStats{Memory: int64(i)})
+	}
+	c.Assert(buffer.list(), check.DeepEquals, []provision.UnitStats{{Memory: 1}, {Memory: 2}})
+	for i := 3; i <= 5; i++ {
+		buffer.add(provision.UnitStats{Memory: int64(i)})
+	}
+	c.Assert(buffer.list(), check.DeepEquals, []provision.UnitStats{{Memory: 3}, {Memory: 4}, {Memory: 5}})
+}
+
+func (s *S) TestUnitStats(c *check.C) {
+	ctx := context.TODO()
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	s.p.Provision(ctx, appInstance)
+	defer s.p.Destroy(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(s.p, appInstance, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+	})
+	c.Assert(err, check.IsNil)
+	added, err := addContainersWithHost(ctx, &changeUnitsPipelineArgs{
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 1}},
+		app:         appInstance,
+		version:     version,
+		provisioner: s.p,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(added, check.HasLen, 1)
+	read := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	s.server.PrepareStats(added[0].ID, func(string) docker.Stats {
+		var stats docker.Stats
+		stats.Read = read
+		stats.CPUStats.CPUUsage.TotalUsage = 3000
+		stats.CPUStats.SystemCPUUsage = 20000
+		stats.CPUStats.OnlineCPUs = 4
+		stats.PreCPUStats.CPUUsage.TotalUsage = 1000
+		stats.PreCPUStats.SystemCPUUsage = 10000
+		stats.MemoryStats.Usage = 1500
+		stats.MemoryStats.Stats.Cache = 500
+		stats.MemoryStats.Limit = 4000
+		stats.Networks = map[string]docker.NetworkStats{
+			"eth0": {RxBytes: 100, TxBytes: 200},
+		}
+		stats.BlkioStats.IOServiceBytesRecursive = []docker.BlkioStatsEntry{
+			{Op: "Read", Value: 10},
+			{Op: "Write", Value: 20},
+			{Op: "Total", Value: 30},
+		}
+		return stats
+	})
+	stats, err := s.p.UnitStats(ctx, appInstance, added[0].ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(stats, check.DeepEquals, []provision.UnitStats{{
+		Time:            read,
+		CPU:             0.8,
+		Memory:          1000,
+		MemoryLimit:     4000,
+		NetworkRxBytes:  100,
+		NetworkTxBytes:  200,
+		BlockReadBytes:  10,
+		BlockWriteBytes: 20,
+	}})
+	otherApp := provisiontest.NewFakeApp("otherapp", "python", 0)
+	_, err = s.p.UnitStats(ctx, otherApp, added[0].ID)
+	c.Assert(err, check.FitsTypeOf, &provision.UnitNotFoundError{})
+	_, err = s.p.UnitStats(ctx, appInstance, "unknown")
+	c.Assert(err, check.FitsTypeOf, &provision.UnitNotFoundError{})
+}
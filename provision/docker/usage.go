@@ -0,0 +1,124 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/log"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/dockercommon"
+)
+
+const statsTimeout = 10 * time.Second
+
+var _ provision.UsageProvisioner = &dockerProvisioner{}
+
+// UnitsUsage samples the resources used by the running units of the app from
+// the docker stats of their containers. Units whose stats are unavailable are
+// skipped.
+func (p *dockerProvisioner) UnitsUsage(ctx context.Context, a provision.App) ([]provision.UnitUsage, error) {
+	containers, err := p.listContainersByApp(a.GetName())
+	if err != nil {
+		return nil, err
+	}
+	clients := map[string]*docker.Client{}
+	var usage []provision.UnitUsage
+	for _, c := range containers {
+		status := provision.Status(c.Status)
+		if status != provision.StatusStarted && status != provision.StatusStarting {
+			continue
+		}
+		client, ok := clients[c.HostAddr]
+		if !ok {
+			client, err = p.hostClient(c.HostAddr)
+			if err != nil {
+				return nil, err
+			}
+			clients[c.HostAddr] = client
+		}
+		stats, err := containerStats(ctx, client, c.ID)
+		if err != nil {
+			log.Errorf("[usage] unable to get stats of unit %s: %s", c.ID, err)
+			continue
+		}
+		unitUsage := provision.UnitUsage{
+			ID:      c.ID,
+			Process: c.ProcessName,
+			CPU:     cpuUsage(stats),
+			Memory:  memoryUsage(stats),
+		}
+		for _, network := range stats.Networks {
+			unitUsage.NetworkRxBytes += network.RxBytes
+			unitUsage.NetworkTxBytes += network.TxBytes
+		}
+		usage = append(usage, unitUsage)
+	}
+	return usage, nil
+}
+
+func (p *dockerProvisioner) hostClient(host string) (*docker.Client, error) {
+	node, err := dockercommon.GetNodeByHost(p.Cluster(), host)
+	if err != nil {
+		return nil, err
+	}
+	return node.Client()
+}
+
+func containerStats(ctx context.Context, client *docker.Client, id string) (*docker.Stats, error) {
+	statsCh := make(chan *docker.Stats)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- client.Stats(docker.StatsOptions{
+			ID:      id,
+			Stats:   statsCh,
+			Stream:  false,
+			Timeout: statsTimeout,
+			Context: ctx,
+		})
+	}()
+	var stats *docker.Stats
+	for s := range statsCh {
+		if stats == nil {
+			stats = s
+		}
+	}
+	err := <-errCh
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return nil, errors.Errorf("no stats returned for container %s", id)
+	}
+	return stats, nil
+}
+
+// cpuUsage returns the number of cores used by the container between the
+// two last reads of its stats, as done by docker stats.
+func cpuUsage(stats *docker.Stats) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemCPUUsage) - float64(stats.PreCPUStats.SystemCPUUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+	cpus := float64(stats.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	return cpuDelta / systemDelta * cpus
+}
+
+// memoryUsage returns the memory used by the container, not counting the
+// page cache, as done by docker stats.
+func memoryUsage(stats *docker.Stats) int64 {
+	usage := stats.MemoryStats.Usage
+	if cache := stats.MemoryStats.Stats.Cache; cache < usage {
+		usage -= cache
+	}
+	return int64(usage)
+}
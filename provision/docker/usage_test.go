@@ -0,0 +1,55 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package docker
+
+import (
+	"context"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/provisiontest"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestUnitsUsage(c *check.C) {
+	ctx := context.TODO()
+	appInstance := provisiontest.NewFakeApp("myapp", "python", 0)
+	s.p.Provision(ctx, appInstance)
+	defer s.p.Destroy(ctx, appInstance)
+	version, err := newSuccessfulVersionForApp(s.p, appInstance, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+	})
+	c.Assert(err, check.IsNil)
+	added, err := addContainersWithHost(ctx, &changeUnitsPipelineArgs{
+		toAdd:       map[string]*containersToAdd{"web": {Quantity: 1}},
+		app:         appInstance,
+		version:     version,
+		provisioner: s.p,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(added, check.HasLen, 1)
+	s.server.PrepareStats(added[0].ID, func(string) docker.Stats {
+		var stats docker.Stats
+		stats.CPUStats.CPUUsage.TotalUsage = 3000
+		stats.CPUStats.SystemCPUUsage = 20000
+		stats.CPUStats.OnlineCPUs = 4
+		stats.PreCPUStats.CPUUsage.TotalUsage = 1000
+		stats.PreCPUStats.SystemCPUUsage = 10000
+		stats.MemoryStats.Usage = 1500
+		stats.MemoryStats.Stats.Cache = 500
+		stats.Networks = map[string]docker.NetworkStats{
+			"eth0": {RxBytes: 100, TxBytes: 200},
+			"eth1": {RxBytes: 10, TxBytes: 20},
+		}
+		return stats
+	})
+	usage, err := s.p.UnitsUsage(ctx, appInstance)
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.DeepEquals, []provision.UnitUsage{
+		{ID: added[0].ID, Process: "web", CPU: 0.8, Memory: 1000, NetworkRxBytes: 110, NetworkTxBytes: 220},
+	})
+}
@@ -99,6 +99,20 @@ func ProcessCmdForVersion(processName string, cmdData ContainerCmdsData) ([]stri
 	return processCmd, processName, nil
 }
 
+// InitStepCmds returns the shell commands running the init steps without
+// an image, which run in the unit container before the app process. The
+// steps with an image run in their own containers.
+func InitStepCmds(cmdData ContainerCmdsData) []string {
+	var cmds []string
+	for _, step := range cmdData.yamlData.Init {
+		if step.Image != "" {
+			continue
+		}
+		cmds = append(cmds, fmt.Sprintf("echo '---- Running init step %s ----' && (%s)", step.Name, step.Command))
+	}
+	return cmds
+}
+
 func LeanContainerCmds(processName string, cmdData ContainerCmdsData, app provision.App) ([]string, string, error) {
 	return LeanContainerCmdsWithExtra(processName, cmdData, app, nil)
 }
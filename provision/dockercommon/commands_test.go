@@ -241,3 +241,31 @@ func (s *S) TestLeanContainerCmdsManyCmds(c *check.C) {
 	expected := []string{"/bin/sh", "-lc", "[ -d /home/application/current ] && cd /home/application/current; exec $0 \"$@\"", "python", "web.py"}
 	c.Assert(cmds, check.DeepEquals, expected)
 }
+
+func (s *S) TestInitStepCmds(c *check.C) {
+	customData := map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python web.py",
+		},
+		"init": []map[string]interface{}{
+			{"name": "migrate", "command": "python manage.py migrate"},
+			{"name": "fetch", "image": "busybox", "command": "wget http://config"},
+			{"name": "warmup", "command": "./warmup.sh || true"},
+		},
+	}
+	fakeApp := provisiontest.NewFakeApp("sample", "python", 0)
+	version := newVersion(c, fakeApp, customData)
+	cmdData, err := dockercommon.ContainerCmdsDataFromVersion(version)
+	c.Assert(err, check.IsNil)
+	initCmds := dockercommon.InitStepCmds(cmdData)
+	c.Assert(initCmds, check.DeepEquals, []string{
+		"echo '---- Running init step migrate ----' && (python manage.py migrate)",
+		"echo '---- Running init step warmup ----' && (./warmup.sh || true)",
+	})
+	cmds, _, err := dockercommon.LeanContainerCmdsWithExtra("web", cmdData, nil, initCmds)
+	c.Assert(err, check.IsNil)
+	c.Assert(cmds, check.DeepEquals, []string{
+		"/bin/sh", "-lc",
+		"[ -d /home/application/current ] && cd /home/application/current; echo '---- Running init step migrate ----' && (python manage.py migrate) && echo '---- Running init step warmup ----' && (./warmup.sh || true) && exec python web.py",
+	})
+}
@@ -81,8 +81,8 @@ func DeployHealthcheckTimeout(tsuruYamlData provTypes.TsuruYamlData) time.Durati
 	}
 
 	var waitTime int
-	if tsuruYamlData.Healthcheck != nil {
-		waitTime = tsuruYamlData.Healthcheck.DeployTimeoutSeconds
+	if hc := tsuruYamlData.ReadinessCheck(); hc != nil {
+		waitTime = hc.DeployTimeoutSeconds
 	}
 	if waitTime < minWaitSeconds {
 		waitTime = minWaitSeconds
@@ -18,6 +18,7 @@ import (
 	"github.com/tsuru/tsuru/log"
 	tsuruNet "github.com/tsuru/tsuru/net"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/registry"
 	"github.com/tsuru/tsuru/safe"
 )
 
@@ -25,8 +26,11 @@ const (
 	JsonFileLogDriver = "json-file"
 )
 
+// PullAndCreateClient pulls images using the credentials of the registry of
+// Pool, when the image is stored there.
 type PullAndCreateClient struct {
 	*docker.Client
+	Pool string
 }
 
 var _ provision.BuilderDockerClient = &PullAndCreateClient{}
@@ -46,7 +50,7 @@ func (c *PullAndCreateClient) PullAndCreateContainer(opts docker.CreateContainer
 		InactivityTimeout: tsuruNet.StreamInactivityTimeout,
 		RawJSONStream:     true,
 	}
-	err := c.Client.PullImage(pullOpts, RegistryAuthConfig(opts.Config.Image))
+	err := c.Client.PullImage(pullOpts, RegistryAuthConfig(c.Pool, opts.Config.Image))
 	if err != nil {
 		return nil, "", err
 	}
@@ -117,7 +121,11 @@ func WaitDocker(client *docker.Client) error {
 }
 
 func PushImage(client Client, name, tag string, authconfig docker.AuthConfiguration) error {
-	if _, err := config.GetString("docker:registry"); err == nil {
+	if authconfig == (docker.AuthConfiguration{}) {
+		authconfig = RegistryAuthConfig("", name)
+	}
+	_, err := config.GetString("docker:registry")
+	if err == nil || authconfig.ServerAddress != "" {
 		var buf safe.Buffer
 		pushOpts := docker.PushImageOptions{
 			Name:              name,
@@ -125,9 +133,6 @@ func PushImage(client Client, name, tag string, authconfig docker.AuthConfigurat
 			OutputStream:      &buf,
 			InactivityTimeout: tsuruNet.StreamInactivityTimeout,
 		}
-		if authconfig == (docker.AuthConfiguration{}) {
-			authconfig = RegistryAuthConfig(name)
-		}
 		err = client.PushImage(pushOpts, authconfig)
 		if err != nil {
 			log.Errorf("[docker] Failed to push image %q (%s): %s", name, err, buf.String())
@@ -137,8 +142,18 @@ func PushImage(client Client, name, tag string, authconfig docker.AuthConfigurat
 	return nil
 }
 
-func RegistryAuthConfig(image string) docker.AuthConfiguration {
+// RegistryAuthConfig returns the credentials for the registry where the image
+// is stored. Images stored in the registry configured for the pool use the
+// pool credentials, falling back to the global docker:registry-auth settings.
+func RegistryAuthConfig(pool, image string) docker.AuthConfiguration {
 	var authConfig docker.AuthConfiguration
+	reg, err := registry.PoolRegistryForImage(pool, image)
+	if err != nil {
+		log.Errorf("[docker] unable to get registry of pool %q for image %q: %v", pool, image, err)
+	}
+	if reg != nil {
+		return reg.AuthConfig()
+	}
 	addr, _ := config.GetString("docker:registry")
 	if !strings.HasPrefix(image, addr) {
 		return authConfig
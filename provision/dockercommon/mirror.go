@@ -0,0 +1,159 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockercommon
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/app/image"
+	tsuruIo "github.com/tsuru/tsuru/io"
+	"github.com/tsuru/tsuru/log"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/registry"
+)
+
+// RegistryMirrorMetadata is the node metadata holding the address of the
+// pull-through cache used by the node when pulling images.
+const RegistryMirrorMetadata = "registry-mirror"
+
+var ErrNoRegistryMirror = errors.New("node has no registry mirror configured")
+
+// MirrorImageName returns the name of image in the given registry mirror,
+// replacing the registry part of the image by the mirror address.
+func MirrorImageName(mirror, imageName string) string {
+	mirror = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(mirror, "https://"), "http://"), "/")
+	_, img, tag := image.ParseImageParts(imageName)
+	if !strings.Contains(img, "/") {
+		img = "library/" + img
+	}
+	if tag != "" {
+		img += ":" + tag
+	}
+	return mirror + "/" + img
+}
+
+// NodeRegistryMirror returns the registry mirror used by the node: its P2P
+// registry proxy when enabled for the node pool, the mirror configured in the
+// node metadata otherwise, or an empty string if the node pulls from the
+// registries directly.
+func NodeRegistryMirror(node cluster.Node) string {
+	if proxy := NodeP2PProxy(node); proxy != "" {
+		return proxy
+	}
+	return node.Metadata[RegistryMirrorMetadata]
+}
+
+// ImageRegistryMirror returns the mirror used by the node to pull the image,
+// along with the credentials used to pull from it. Node mirrors and P2P
+// proxies cache the global registry, so images stored in the registry of the
+// node pool are only pulled through the mirror configured for it, if any.
+func ImageRegistryMirror(node cluster.Node, imageName string) (string, docker.AuthConfiguration, error) {
+	reg, err := registry.PoolRegistryForImage(node.Metadata[provision.PoolMetadataName], imageName)
+	if err != nil {
+		return "", docker.AuthConfiguration{}, err
+	}
+	if reg != nil {
+		return reg.Mirror, reg.MirrorAuthConfig(), nil
+	}
+	return NodeRegistryMirror(node), docker.AuthConfiguration{}, nil
+}
+
+// PullImageFromMirror pulls the image through the registry mirror used by
+// the node for the image, tagging the result with the original image name so that the
+// following pull from the primary registry only needs to check the
+// manifest. Errors are returned to the caller, which must fall back to
+// pulling from the primary registry.
+func PullImageFromMirror(node cluster.Node, imageName string, w io.Writer) error {
+	mirror, authConfig, err := ImageRegistryMirror(node, imageName)
+	if err != nil {
+		return err
+	}
+	if mirror == "" {
+		return ErrNoRegistryMirror
+	}
+	return pullImageThrough(node, mirror, authConfig, imageName, w)
+}
+
+// pullImageThrough pulls the image on the node through the given mirror,
+// tagging the result with the original image name.
+func pullImageThrough(node cluster.Node, mirror string, authConfig docker.AuthConfiguration, imageName string, w io.Writer) error {
+	client, err := node.Client()
+	if err != nil {
+		return err
+	}
+	if w == nil {
+		w = ioutil.Discard
+	}
+	mirrorImage := MirrorImageName(mirror, imageName)
+	mirrorRepo, mirrorTag := image.SplitImageName(mirrorImage)
+	err = client.PullImage(docker.PullImageOptions{
+		Repository:        mirrorRepo,
+		Tag:               mirrorTag,
+		OutputStream:      &tsuruIo.DockerErrorCheckWriter{W: w},
+		RawJSONStream:     true,
+		InactivityTimeout: tsuruNet.StreamInactivityTimeout,
+	}, authConfig)
+	if err != nil {
+		return errors.Wrapf(err, "unable to pull %q from mirror %q", imageName, mirror)
+	}
+	repo, tag := image.SplitImageName(imageName)
+	err = client.TagImage(mirrorImage, docker.TagImageOptions{Repo: repo, Tag: tag, Force: true})
+	if err != nil {
+		return errors.Wrapf(err, "unable to tag %q as %q", mirrorImage, imageName)
+	}
+	return nil
+}
+
+// PrePullImageFromMirror tries to pull the image from a peer of the node
+// with the given address which already has it, when P2P distribution is
+// enabled for the node pool, or through the registry mirror used for the
+// image by the node. Failures are only logged, as the image is always pulled
+// from the primary registry afterwards.
+func PrePullImageFromMirror(c *cluster.Cluster, nodeAddr, imageName string) {
+	node, err := c.GetNode(nodeAddr)
+	if err != nil {
+		log.Errorf("[registry-mirror] unable to get node %q: %v", nodeAddr, err)
+		return
+	}
+	err = PullImageFromPeer(c, node, imageName, nil)
+	if err == nil {
+		return
+	}
+	if err != ErrNoImagePeer {
+		log.Errorf("[p2p] falling back to registry mirror on node %q: %v", nodeAddr, err)
+	}
+	err = PullImageFromMirror(node, imageName, nil)
+	if err == ErrNoRegistryMirror {
+		return
+	}
+	if err != nil {
+		log.Errorf("[registry-mirror] falling back to primary registry on node %q: %v", nodeAddr, err)
+	}
+}
+
+// VerifyRegistryMirror checks that the node is able to reach its registry
+// mirror, by inspecting the distribution of the given image through it.
+// The digest of the image in the mirror is returned.
+func VerifyRegistryMirror(node cluster.Node, imageName string) (string, error) {
+	mirror := NodeRegistryMirror(node)
+	if mirror == "" {
+		return "", ErrNoRegistryMirror
+	}
+	client, err := node.Client()
+	if err != nil {
+		return "", err
+	}
+	info, err := client.InspectDistribution(MirrorImageName(mirror, imageName))
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to reach mirror %q from node %q", mirror, node.Address)
+	}
+	return string(info.Descriptor.Digest), nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockercommon
+
+import (
+	"net/http"
+	"sync"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/fsouza/go-dockerclient/testing"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	"github.com/tsuru/tsuru/registry"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestMirrorImageName(c *check.C) {
+	tests := []struct {
+		mirror, image, expected string
+	}{
+		{"mirror:5000", "python", "mirror:5000/library/python"},
+		{"mirror:5000", "python:3.9", "mirror:5000/library/python:3.9"},
+		{"mirror:5000", "tsuru/python", "mirror:5000/tsuru/python"},
+		{"http://mirror:5000/", "registry.example.com/tsuru/app-myapp:v1", "mirror:5000/tsuru/app-myapp:v1"},
+		{"https://mirror", "localhost:5000/app-myapp", "mirror/library/app-myapp"},
+	}
+	for _, tt := range tests {
+		c.Check(MirrorImageName(tt.mirror, tt.image), check.Equals, tt.expected, check.Commentf("image %q", tt.image))
+	}
+}
+
+func (s *S) TestPullImageFromMirrorWithoutMirror(c *check.C) {
+	err := PullImageFromMirror(cluster.Node{Address: "http://localhost:2375"}, "python", nil)
+	c.Assert(err, check.Equals, ErrNoRegistryMirror)
+	_, err = VerifyRegistryMirror(cluster.Node{Address: "http://localhost:2375"}, "python")
+	c.Assert(err, check.Equals, ErrNoRegistryMirror)
+}
+
+func (s *S) TestNodeRegistryMirrorP2P(c *check.C) {
+	config.Set("docker:p2p:pools", []string{"pool1"})
+	defer config.Unset("docker:p2p")
+	node := cluster.Node{Address: "http://localhost:2375", Metadata: map[string]string{"pool": "pool1", RegistryMirrorMetadata: "mirror:5000"}}
+	c.Assert(NodeRegistryMirror(node), check.Equals, "127.0.0.1:65001")
+	config.Set("docker:p2p:port", 7000)
+	c.Assert(NodeRegistryMirror(node), check.Equals, "127.0.0.1:7000")
+	node.Metadata["pool"] = "pool2"
+	c.Assert(NodeRegistryMirror(node), check.Equals, "mirror:5000")
+	c.Assert(P2PEnabled("pool1"), check.Equals, true)
+	c.Assert(P2PEnabled("pool2"), check.Equals, false)
+}
+
+func (s *S) TestImageRegistryMirrorPoolRegistry(c *check.C) {
+	err := registry.SetPoolRegistry("pool1", registry.PoolRegistry{Address: "registry.example.com", Namespace: "team-a", Username: "user", Password: "pass"})
+	c.Assert(err, check.IsNil)
+	node := cluster.Node{Address: "http://localhost:2375", Metadata: map[string]string{"pool": "pool1", RegistryMirrorMetadata: "mirror:5000"}}
+	mirror, authConfig, err := ImageRegistryMirror(node, "registry.example.com/team-a/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	c.Assert(mirror, check.Equals, "")
+	c.Assert(authConfig, check.DeepEquals, docker.AuthConfiguration{Username: "user", Password: "pass"})
+	err = PullImageFromMirror(node, "registry.example.com/team-a/app-myapp:v1", nil)
+	c.Assert(err, check.Equals, ErrNoRegistryMirror)
+	err = registry.SetPoolRegistry("pool1", registry.PoolRegistry{Address: "registry.example.com", Namespace: "team-a", Username: "user", Mirror: "pool-mirror:5000/"})
+	c.Assert(err, check.IsNil)
+	mirror, authConfig, err = ImageRegistryMirror(node, "registry.example.com/team-a/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	c.Assert(mirror, check.Equals, "pool-mirror:5000")
+	c.Assert(authConfig, check.DeepEquals, docker.AuthConfiguration{ServerAddress: "pool-mirror:5000", Username: "user", Password: "pass"})
+	mirror, authConfig, err = ImageRegistryMirror(node, "python:3.9")
+	c.Assert(err, check.IsNil)
+	c.Assert(mirror, check.Equals, "mirror:5000")
+	c.Assert(authConfig, check.DeepEquals, docker.AuthConfiguration{})
+}
+
+func (s *S) TestImageRegistryMirrorOtherPoolRegistry(c *check.C) {
+	err := registry.SetPoolRegistry("pool1", registry.PoolRegistry{Address: "registry.example.com", Username: "user", Password: "pass"})
+	c.Assert(err, check.IsNil)
+	node := cluster.Node{Address: "http://localhost:2375", Metadata: map[string]string{"pool": "pool2", RegistryMirrorMetadata: "mirror:5000"}}
+	mirror, authConfig, err := ImageRegistryMirror(node, "registry.example.com/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	c.Assert(mirror, check.Equals, "mirror:5000")
+	c.Assert(authConfig, check.DeepEquals, docker.AuthConfiguration{})
+	c.Assert(RegistryAuthConfig("pool2", "registry.example.com/app-myapp:v1"), check.DeepEquals, docker.AuthConfiguration{})
+	c.Assert(RegistryAuthConfig("pool1", "registry.example.com/app-myapp:v1"), check.DeepEquals, docker.AuthConfiguration{ServerAddress: "registry.example.com", Username: "user", Password: "pass"})
+}
+
+func (s *S) TestPullImageFromPeer(c *check.C) {
+	config.Set("docker:p2p:pools", []string{"pool1"})
+	defer config.Unset("docker:p2p")
+	var mu sync.Mutex
+	var pulls []string
+	target, err := testing.NewServer("127.0.0.1:0", nil, func(r *http.Request) {
+		if r.URL.Path == "/images/create" {
+			mu.Lock()
+			pulls = append(pulls, r.URL.Query().Get("fromImage"))
+			mu.Unlock()
+		}
+	})
+	c.Assert(err, check.IsNil)
+	defer target.Stop()
+	peer, err := testing.NewServer("127.0.0.1:0", nil, nil)
+	c.Assert(err, check.IsNil)
+	defer peer.Stop()
+	metadata := map[string]string{"pool": "pool1"}
+	cl, err := cluster.New(nil, &cluster.MapStorage{}, "",
+		cluster.Node{Address: target.URL(), Metadata: metadata},
+		cluster.Node{Address: peer.URL(), Metadata: metadata},
+	)
+	c.Assert(err, check.IsNil)
+	targetNode, err := cl.GetNode(target.URL())
+	c.Assert(err, check.IsNil)
+	_, err = FindImagePeer(cl, targetNode, "registry.example.com/tsuru/app-myapp:v1")
+	c.Assert(err, check.Equals, ErrNoImagePeer)
+	peerClient, err := docker.NewClient(peer.URL())
+	c.Assert(err, check.IsNil)
+	err = peerClient.PullImage(docker.PullImageOptions{Repository: "registry.example.com/tsuru/app-myapp", Tag: "v1"}, docker.AuthConfiguration{})
+	c.Assert(err, check.IsNil)
+	found, err := FindImagePeer(cl, targetNode, "registry.example.com/tsuru/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	c.Assert(found.Address, check.Equals, peer.URL())
+	err = PullImageFromPeer(cl, targetNode, "registry.example.com/tsuru/app-myapp:v1", nil)
+	c.Assert(err, check.IsNil)
+	mu.Lock()
+	c.Assert(pulls, check.DeepEquals, []string{"127.0.0.1:65001/tsuru/app-myapp"})
+	mu.Unlock()
+	targetClient, err := docker.NewClient(target.URL())
+	c.Assert(err, check.IsNil)
+	_, err = targetClient.InspectImage("registry.example.com/tsuru/app-myapp:v1")
+	c.Assert(err, check.IsNil)
+	targetNode.Metadata = map[string]string{"pool": "pool2"}
+	_, err = FindImagePeer(cl, targetNode, "registry.example.com/tsuru/app-myapp:v1")
+	c.Assert(err, check.Equals, ErrNoImagePeer)
+}
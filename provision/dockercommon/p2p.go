@@ -0,0 +1,116 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dockercommon
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/docker-cluster/cluster"
+	tsuruNet "github.com/tsuru/tsuru/net"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/registry"
+)
+
+const defaultP2PProxyPort = 65001
+
+var ErrNoImagePeer = errors.New("no peer node has the image")
+
+// P2PEnabled returns whether nodes of the pool share images with each
+// other, pulling them from peer nodes which already have them through the
+// registry proxy running on every node of the pool, instead of fetching them
+// all from the registry.
+func P2PEnabled(pool string) bool {
+	pools, _ := config.GetList("docker:p2p:pools")
+	for _, p := range pools {
+		if p == pool {
+			return true
+		}
+	}
+	return false
+}
+
+func p2pProxyPort() int {
+	port, _ := config.GetInt("docker:p2p:port")
+	if port == 0 {
+		port = defaultP2PProxyPort
+	}
+	return port
+}
+
+// NodeP2PProxy returns the address where the node reaches its own registry
+// proxy, or an empty string when P2P distribution isn't enabled for the node
+// pool. The node uses the loopback interface, which docker allows as an
+// insecure registry by default.
+func NodeP2PProxy(node cluster.Node) string {
+	if !P2PEnabled(node.Metadata[provision.PoolMetadataName]) {
+		return ""
+	}
+	return fmt.Sprintf("127.0.0.1:%d", p2pProxyPort())
+}
+
+// peerP2PProxy returns the address where peers reach the registry proxy of
+// the node.
+func peerP2PProxy(node cluster.Node) string {
+	return fmt.Sprintf("%s:%d", tsuruNet.URLToHost(node.Address), p2pProxyPort())
+}
+
+// FindImagePeer returns a node in the same pool as node which already has the
+// image. Peers are checked in random order, so that pulls of the image are
+// spread among the nodes that have it. It returns ErrNoImagePeer when P2P
+// distribution isn't enabled for the node pool, when the image is stored in a
+// pool registry, which the proxies don't cache, or when no peer has the image.
+func FindImagePeer(c *cluster.Cluster, node cluster.Node, imageName string) (*cluster.Node, error) {
+	pool := node.Metadata[provision.PoolMetadataName]
+	if !P2PEnabled(pool) {
+		return nil, ErrNoImagePeer
+	}
+	reg, err := registry.PoolRegistryForImage(pool, imageName)
+	if err != nil {
+		return nil, err
+	}
+	if reg != nil {
+		return nil, ErrNoImagePeer
+	}
+	nodes, err := c.NodesForMetadata(map[string]string{provision.PoolMetadataName: pool})
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range rand.Perm(len(nodes)) {
+		peer := nodes[i]
+		if peer.Address == node.Address {
+			continue
+		}
+		client, err := peer.Client()
+		if err != nil {
+			continue
+		}
+		_, err = client.InspectImage(imageName)
+		if err == nil {
+			return &peer, nil
+		}
+	}
+	return nil, ErrNoImagePeer
+}
+
+// PullImageFromPeer pulls the image on the node through the registry proxy
+// of a peer which already has the image, tagging the result with the
+// original image name. The proxy of the peer serves the layers from the
+// peer, only fetching from the registry the layers it doesn't have.
+func PullImageFromPeer(c *cluster.Cluster, node cluster.Node, imageName string, w io.Writer) error {
+	peer, err := FindImagePeer(c, node, imageName)
+	if err != nil {
+		return err
+	}
+	err = pullImageThrough(node, peerP2PProxy(*peer), docker.AuthConfiguration{}, imageName, w)
+	if err != nil {
+		return errors.Wrapf(err, "unable to pull from peer %q", tsuruNet.URLToHost(peer.Address))
+	}
+	return nil
+}
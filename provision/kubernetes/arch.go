@@ -0,0 +1,69 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// platformArchitectures returns the CPU architectures of the image
+// platforms, like arm64 for linux/arm64/v8.
+func platformArchitectures(platforms []string) []string {
+	var archs []string
+	for _, p := range platforms {
+		parts := strings.Split(p, "/")
+		if len(parts) < 2 || parts[1] == "" {
+			continue
+		}
+		archs = append(archs, parts[1])
+	}
+	return archs
+}
+
+// withArchitectureAffinity returns a copy of affinity requiring the pods to
+// run on nodes of one of the architectures the image was built for. Nodes
+// advertise their architecture in the well known kubernetes.io/arch label.
+func withArchitectureAffinity(affinity *apiv1.Affinity, archs []string) *apiv1.Affinity {
+	if len(archs) == 0 {
+		return affinity
+	}
+	return withRequiredNodeAffinity(affinity, apiv1.NodeSelectorRequirement{
+		Key:      apiv1.LabelArchStable,
+		Operator: apiv1.NodeSelectorOpIn,
+		Values:   archs,
+	})
+}
+
+// withRequiredNodeAffinity returns a copy of affinity requiring the pods to
+// run on nodes satisfying all the requirements.
+func withRequiredNodeAffinity(affinity *apiv1.Affinity, requirements ...apiv1.NodeSelectorRequirement) *apiv1.Affinity {
+	if len(requirements) == 0 {
+		return affinity
+	}
+	if affinity == nil {
+		affinity = &apiv1.Affinity{}
+	} else {
+		affinity = affinity.DeepCopy()
+	}
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &apiv1.NodeAffinity{}
+	}
+	required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &apiv1.NodeSelector{
+			NodeSelectorTerms: []apiv1.NodeSelectorTerm{{
+				MatchExpressions: requirements,
+			}},
+		}
+		return affinity
+	}
+	// Terms are ORed, the requirements must be present in each one of them.
+	for i := range required.NodeSelectorTerms {
+		required.NodeSelectorTerms[i].MatchExpressions = append(required.NodeSelectorTerms[i].MatchExpressions, requirements...)
+	}
+	return affinity
+}
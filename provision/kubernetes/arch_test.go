@@ -0,0 +1,57 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func (s *S) TestBuildPlatforms(c *check.C) {
+	c.Assert(s.clusterClient.BuildPlatforms(), check.IsNil)
+	s.clusterClient.CustomData[buildPlatformsKey] = "linux/amd64, linux/arm64,"
+	c.Assert(s.clusterClient.BuildPlatforms(), check.DeepEquals, []string{"linux/amd64", "linux/arm64"})
+	c.Assert(platformArchitectures(s.clusterClient.BuildPlatforms()), check.DeepEquals, []string{"amd64", "arm64"})
+	c.Assert(platformArchitectures([]string{"linux/arm64/v8", "invalid"}), check.DeepEquals, []string{"arm64"})
+}
+
+func (s *S) TestWithArchitectureAffinity(c *check.C) {
+	archRequirement := apiv1.NodeSelectorRequirement{
+		Key:      "kubernetes.io/arch",
+		Operator: apiv1.NodeSelectorOpIn,
+		Values:   []string{"amd64", "arm64"},
+	}
+	c.Assert(withArchitectureAffinity(nil, nil), check.IsNil)
+	c.Assert(withArchitectureAffinity(nil, []string{"amd64", "arm64"}), check.DeepEquals, &apiv1.Affinity{
+		NodeAffinity: &apiv1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &apiv1.NodeSelector{
+				NodeSelectorTerms: []apiv1.NodeSelectorTerm{{
+					MatchExpressions: []apiv1.NodeSelectorRequirement{archRequirement},
+				}},
+			},
+		},
+	})
+	poolRequirement := apiv1.NodeSelectorRequirement{
+		Key:      "tsuru.io/pool",
+		Operator: apiv1.NodeSelectorOpIn,
+		Values:   []string{"pool1"},
+	}
+	poolAffinity := &apiv1.Affinity{
+		NodeAffinity: &apiv1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &apiv1.NodeSelector{
+				NodeSelectorTerms: []apiv1.NodeSelectorTerm{
+					{MatchExpressions: []apiv1.NodeSelectorRequirement{poolRequirement}},
+					{MatchExpressions: []apiv1.NodeSelectorRequirement{poolRequirement}},
+				},
+			},
+		},
+	}
+	affinity := withArchitectureAffinity(poolAffinity, []string{"amd64", "arm64"})
+	c.Assert(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, check.DeepEquals, []apiv1.NodeSelectorTerm{
+		{MatchExpressions: []apiv1.NodeSelectorRequirement{poolRequirement, archRequirement}},
+		{MatchExpressions: []apiv1.NodeSelectorRequirement{poolRequirement, archRequirement}},
+	})
+	c.Assert(poolAffinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions, check.HasLen, 1)
+}
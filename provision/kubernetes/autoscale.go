@@ -156,6 +156,24 @@ func (p *kubernetesProvisioner) GetAutoScale(ctx context.Context, a provision.Ap
 	return specs, nil
 }
 
+func (p *kubernetesProvisioner) GetAutoScaleStatus(ctx context.Context, a provision.App) ([]provision.AutoScaleSpec, error) {
+	client, err := clusterForPool(ctx, a.GetPool())
+	if err != nil {
+		return nil, err
+	}
+	hpas, err := getHPAs(ctx, client, a, "")
+	if err != nil {
+		return nil, err
+	}
+	var specs []provision.AutoScaleSpec
+	for _, hpa := range hpas {
+		spec := hpaToSpec(hpa)
+		spec.Status = hpaToStatus(hpa)
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
 func hpaToSpec(hpa autoscalingv2.HorizontalPodAutoscaler) provision.AutoScaleSpec {
 	ls := labelSetFromMeta(&hpa.ObjectMeta)
 	spec := provision.AutoScaleSpec{
@@ -168,12 +186,19 @@ func hpaToSpec(hpa autoscalingv2.HorizontalPodAutoscaler) provision.AutoScaleSpe
 	}
 
 	cpuValue := int64(0)
-	if len(hpa.Spec.Metrics) > 0 && hpa.Spec.Metrics[0].Resource != nil {
-		if hpa.Spec.Metrics[0].Resource.Target.AverageUtilization != nil {
-			cpuValue = int64(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
-			cpuValue = cpuValue * 10
-		} else if hpa.Spec.Metrics[0].Resource.Target.AverageValue != nil {
-			cpuValue = hpa.Spec.Metrics[0].Resource.Target.AverageValue.MilliValue()
+	for _, m := range hpa.Spec.Metrics {
+		switch {
+		case m.Resource != nil:
+			if m.Resource.Target.AverageUtilization != nil {
+				cpuValue = int64(*m.Resource.Target.AverageUtilization)
+				cpuValue = cpuValue * 10
+			} else if m.Resource.Target.AverageValue != nil {
+				cpuValue = m.Resource.Target.AverageValue.MilliValue()
+			}
+		case m.Pods != nil:
+			spec.Metrics = append(spec.Metrics, specMetric(provision.AutoScaleMetricPods, m.Pods.Metric, m.Pods.Target))
+		case m.External != nil:
+			spec.Metrics = append(spec.Metrics, specMetric(provision.AutoScaleMetricExternal, m.External.Metric, m.External.Target))
 		}
 	}
 
@@ -184,6 +209,73 @@ func hpaToSpec(hpa autoscalingv2.HorizontalPodAutoscaler) provision.AutoScaleSpe
 	return spec
 }
 
+func specMetric(metricType string, metric autoscalingv2.MetricIdentifier, target autoscalingv2.MetricTarget) provision.AutoScaleMetric {
+	m := provision.AutoScaleMetric{
+		Type: metricType,
+		Name: metric.Name,
+	}
+	if metric.Selector != nil {
+		m.Selector = metric.Selector.MatchLabels
+	}
+	if target.AverageValue != nil {
+		m.Target = target.AverageValue.String()
+	} else if target.Value != nil {
+		m.Target = target.Value.String()
+	}
+	return m
+}
+
+// hpaToStatus returns the current state of the HPA, as reported by the
+// kubernetes controller.
+func hpaToStatus(hpa autoscalingv2.HorizontalPodAutoscaler) *provision.AutoScaleStatus {
+	status := &provision.AutoScaleStatus{
+		CurrentUnits: uint(hpa.Status.CurrentReplicas),
+		DesiredUnits: uint(hpa.Status.DesiredReplicas),
+	}
+	if hpa.Status.LastScaleTime != nil {
+		lastScale := hpa.Status.LastScaleTime.Time
+		status.LastScaleTime = &lastScale
+	}
+	for _, m := range hpa.Status.CurrentMetrics {
+		switch {
+		case m.Resource != nil:
+			value := provision.AutoScaleMetricValue{Type: "resource", Name: string(m.Resource.Name)}
+			if m.Resource.Current.AverageUtilization != nil {
+				value.Value = fmt.Sprintf("%d%%", *m.Resource.Current.AverageUtilization)
+			} else if m.Resource.Current.AverageValue != nil {
+				value.Value = m.Resource.Current.AverageValue.String()
+			}
+			status.CurrentMetrics = append(status.CurrentMetrics, value)
+		case m.Pods != nil:
+			status.CurrentMetrics = append(status.CurrentMetrics, metricValue(provision.AutoScaleMetricPods, m.Pods.Metric, m.Pods.Current))
+		case m.External != nil:
+			status.CurrentMetrics = append(status.CurrentMetrics, metricValue(provision.AutoScaleMetricExternal, m.External.Metric, m.External.Current))
+		}
+	}
+	for _, cond := range hpa.Status.Conditions {
+		status.Conditions = append(status.Conditions, provision.AutoScaleCondition{
+			Type:    string(cond.Type),
+			Status:  string(cond.Status),
+			Reason:  cond.Reason,
+			Message: cond.Message,
+		})
+	}
+	return status
+}
+
+func metricValue(metricType string, metric autoscalingv2.MetricIdentifier, current autoscalingv2.MetricValueStatus) provision.AutoScaleMetricValue {
+	value := provision.AutoScaleMetricValue{
+		Type: metricType,
+		Name: metric.Name,
+	}
+	if current.AverageValue != nil {
+		value.Value = current.AverageValue.String()
+	} else if current.Value != nil {
+		value.Value = current.Value.String()
+	}
+	return value
+}
+
 func (p *kubernetesProvisioner) deleteAllAutoScale(ctx context.Context, a provision.App) error {
 	scaleSpecs, err := p.GetAutoScale(ctx, a)
 	if err != nil {
@@ -266,21 +358,9 @@ func setAutoScale(ctx context.Context, client *ClusterClient, a provision.App, s
 
 	hpaName := hpaNameForApp(a, depInfo.process)
 
-	cpuValue, err := spec.ToCPUValue(a)
+	metrics, err := hpaMetrics(a, spec)
 	if err != nil {
-		return errors.WithStack(err)
-	}
-
-	target := autoscalingv2.MetricTarget{}
-	if a.GetMilliCPU() > 0 {
-		target.Type = autoscalingv2.UtilizationMetricType
-		val := int32(cpuValue)
-		target.AverageUtilization = &val
-	} else {
-		target.Type = autoscalingv2.AverageValueMetricType
-		target.AverageValue = resource.NewMilliQuantity(int64(cpuValue), resource.DecimalSI)
-		// Fill string value for easier tests
-		_ = target.AverageValue.String()
+		return err
 	}
 
 	policyMin := autoscalingv2.MinPolicySelect
@@ -318,15 +398,7 @@ func setAutoScale(ctx context.Context, client *ClusterClient, a provision.App, s
 					},
 				},
 			},
-			Metrics: []autoscalingv2.MetricSpec{
-				{
-					Type: autoscalingv2.ResourceMetricSourceType,
-					Resource: &autoscalingv2.ResourceMetricSource{
-						Name:   "cpu",
-						Target: target,
-					},
-				},
-			},
+			Metrics: metrics,
 		},
 	}
 
@@ -353,6 +425,65 @@ func setAutoScale(ctx context.Context, client *ClusterClient, a provision.App, s
 	return nil
 }
 
+// hpaMetrics returns the metrics of the HPA for the autoscale spec, the cpu
+// usage of the units, when set, followed by the custom and external metrics.
+func hpaMetrics(a provision.App, spec provision.AutoScaleSpec) ([]autoscalingv2.MetricSpec, error) {
+	var metrics []autoscalingv2.MetricSpec
+	if spec.AverageCPU != "" {
+		cpuValue, err := spec.ToCPUValue(a)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		target := autoscalingv2.MetricTarget{}
+		if a.GetMilliCPU() > 0 {
+			target.Type = autoscalingv2.UtilizationMetricType
+			val := int32(cpuValue)
+			target.AverageUtilization = &val
+		} else {
+			target.Type = autoscalingv2.AverageValueMetricType
+			target.AverageValue = resource.NewMilliQuantity(int64(cpuValue), resource.DecimalSI)
+			// Fill string value for easier tests
+			_ = target.AverageValue.String()
+		}
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name:   "cpu",
+				Target: target,
+			},
+		})
+	}
+	for _, m := range spec.Metrics {
+		targetValue, err := resource.ParseQuantity(m.Target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid target for autoscale metric %q", m.Name)
+		}
+		metric := autoscalingv2.MetricIdentifier{Name: m.Name}
+		if len(m.Selector) > 0 {
+			metric.Selector = &metav1.LabelSelector{MatchLabels: m.Selector}
+		}
+		target := autoscalingv2.MetricTarget{
+			Type:         autoscalingv2.AverageValueMetricType,
+			AverageValue: &targetValue,
+		}
+		switch m.Type {
+		case provision.AutoScaleMetricPods:
+			metrics = append(metrics, autoscalingv2.MetricSpec{
+				Type: autoscalingv2.PodsMetricSourceType,
+				Pods: &autoscalingv2.PodsMetricSource{Metric: metric, Target: target},
+			})
+		case provision.AutoScaleMetricExternal:
+			metrics = append(metrics, autoscalingv2.MetricSpec{
+				Type:     autoscalingv2.ExternalMetricSourceType,
+				External: &autoscalingv2.ExternalMetricSource{Metric: metric, Target: target},
+			})
+		default:
+			return nil, errors.Errorf("invalid autoscale metric type %q", m.Type)
+		}
+	}
+	return metrics, nil
+}
+
 func minimumAutoScaleVersion(ctx context.Context, client *ClusterClient, a provision.App, process string) (*deploymentInfo, error) {
 	depGroups, err := deploymentsDataForApp(ctx, client, a)
 	if err != nil {
@@ -539,6 +670,18 @@ func ensureHPA(ctx context.Context, client *ClusterClient, a provision.App, proc
 }
 
 func getAutoScale(ctx context.Context, client *ClusterClient, a provision.App, process string) ([]provision.AutoScaleSpec, error) {
+	hpas, err := getHPAs(ctx, client, a, process)
+	if err != nil {
+		return nil, err
+	}
+	var specs []provision.AutoScaleSpec
+	for _, hpa := range hpas {
+		specs = append(specs, hpaToSpec(hpa))
+	}
+	return specs, nil
+}
+
+func getHPAs(ctx context.Context, client *ClusterClient, a provision.App, process string) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
 	ns, err := client.AppNamespace(ctx, a)
 	if err != nil {
 		return nil, err
@@ -562,12 +705,7 @@ func getAutoScale(ctx context.Context, client *ClusterClient, a provision.App, p
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-
-	var specs []provision.AutoScaleSpec
-	for _, hpa := range hpas.Items {
-		specs = append(specs, hpaToSpec(hpa))
-	}
-	return specs, nil
+	return hpas.Items, nil
 }
 
 func allVPAsForApp(ctx context.Context, clusterClient *ClusterClient, vpaClient vpaclientset.Interface, a provision.App) (*vpav1.VerticalPodAutoscalerList, error) {
@@ -465,6 +465,85 @@ func (s *S) TestProvisionerGetAutoScale(c *check.C) {
 	})
 }
 
+func (s *S) TestProvisionerSetAutoScaleWithMetrics(c *check.C) {
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+	version := newSuccessfulVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "python myapp.py",
+		},
+	})
+	err := s.p.AddUnits(context.TODO(), a, 1, "web", version, nil)
+	c.Assert(err, check.IsNil)
+	wait()
+	spec := provision.AutoScaleSpec{
+		MinUnits: 1,
+		MaxUnits: 10,
+		Metrics: []provision.AutoScaleMetric{
+			{Type: provision.AutoScaleMetricExternal, Name: "queue_depth", Selector: map[string]string{"queue": "jobs"}, Target: "30"},
+			{Type: provision.AutoScaleMetricPods, Name: "requests_per_second", Target: "500m"},
+		},
+	}
+	err = s.p.SetAutoScale(context.TODO(), a, spec)
+	c.Assert(err, check.IsNil)
+	hpa, err := s.client.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	queueTarget := resource.MustParse("30")
+	rpsTarget := resource.MustParse("500m")
+	c.Assert(hpa.Spec.Metrics, check.DeepEquals, []autoscalingv2.MetricSpec{
+		{
+			Type: autoscalingv2.ExternalMetricSourceType,
+			External: &autoscalingv2.ExternalMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{
+					Name:     "queue_depth",
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"queue": "jobs"}},
+				},
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &queueTarget},
+			},
+		},
+		{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{Name: "requests_per_second"},
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &rpsTarget},
+			},
+		},
+	})
+	hpa.Status = autoscalingv2.HorizontalPodAutoscalerStatus{
+		CurrentReplicas: 2,
+		DesiredReplicas: 3,
+		CurrentMetrics: []autoscalingv2.MetricStatus{
+			{
+				Type: autoscalingv2.ExternalMetricSourceType,
+				External: &autoscalingv2.ExternalMetricStatus{
+					Metric:  autoscalingv2.MetricIdentifier{Name: "queue_depth"},
+					Current: autoscalingv2.MetricValueStatus{AverageValue: resource.NewQuantity(45, resource.DecimalSI)},
+				},
+			},
+		},
+		Conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+			{Type: autoscalingv2.ScalingActive, Status: corev1.ConditionTrue, Reason: "ValidMetricFound"},
+		},
+	}
+	_, err = s.client.AutoscalingV2beta2().HorizontalPodAutoscalers("default").Update(context.TODO(), hpa, metav1.UpdateOptions{})
+	c.Assert(err, check.IsNil)
+	specs, err := s.p.GetAutoScaleStatus(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	spec.Version = 1
+	spec.Process = "web"
+	spec.Status = &provision.AutoScaleStatus{
+		CurrentUnits: 2,
+		DesiredUnits: 3,
+		CurrentMetrics: []provision.AutoScaleMetricValue{
+			{Type: provision.AutoScaleMetricExternal, Name: "queue_depth", Value: "45"},
+		},
+		Conditions: []provision.AutoScaleCondition{
+			{Type: "ScalingActive", Status: "True", Reason: "ValidMetricFound"},
+		},
+	}
+	c.Assert(specs, check.DeepEquals, []provision.AutoScaleSpec{spec})
+}
+
 func (s *S) TestEnsureVPAIfEnabled(c *check.C) {
 	a, wait, rollback := s.mock.DefaultReactions(c)
 	defer rollback()
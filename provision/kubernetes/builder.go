@@ -72,7 +72,15 @@ func (c *KubeClient) BuildPod(ctx context.Context, a provision.App, evt *event.E
 		quota:             quota,
 		cmds:              dockercommon.ArchiveBuildCmds(a, "file://"+inputFile),
 	}
-	return createPod(ctx, params)
+	err = createPod(ctx, params)
+	if err != nil {
+		return err
+	}
+	archs := platformArchitectures(client.BuildPlatforms())
+	if len(archs) == 0 {
+		return nil
+	}
+	return version.AddData(appTypes.AddVersionDataArgs{Architectures: archs})
 }
 
 func (c *KubeClient) ImageTagPushAndInspect(ctx context.Context, a provision.App, evt *event.Event, oldImage string, version appTypes.AppVersion) (provision.InspectData, error) {
@@ -72,6 +72,7 @@ const (
 	sidecarImageKey               = "sidecar-image"
 	buildServiceAccountKey        = "build-service-account"
 	disablePlatformBuildKey       = "disable-platform-build"
+	buildPlatformsKey             = "build-platforms"
 	disablePDBKey                 = "disable-pdb"
 	defaultLogsFromAPIServer      = false
 	versionedServices             = "enable-versioned-services"
@@ -107,6 +108,7 @@ var (
 		registryKey:                   "Allow a custom registry to be used on this cluster.",
 		buildServiceAccountKey:        "Custom service account used in build containers.",
 		disablePlatformBuildKey:       "Disable platform image build in cluster.",
+		buildPlatformsKey:             "Comma separated list of platforms, like linux/amd64,linux/arm64, images built in the cluster are built for, producing multi-arch manifests. Defaults to the platform of the build node.",
 		sidecarImageKey:               "Override for deploy sidecar image.",
 		versionedServices:             "Allow the creation of multiple services for each pair of {process, version} from the app. The default behavior creates versioned services only in a multi versioned deploy scenario.",
 		dockerConfigJSONKey:           "Custom Docker config (~/.docker/config.json) to be mounted on deploy-agent container",
@@ -610,6 +612,22 @@ func (c *ClusterClient) DisablePlatformBuild() bool {
 	return d
 }
 
+// BuildPlatforms returns the platforms images built in the cluster are built
+// for, empty when only the platform of the build node is used.
+func (c *ClusterClient) BuildPlatforms() []string {
+	if c.CustomData == nil {
+		return nil
+	}
+	var platforms []string
+	for _, p := range strings.Split(c.CustomData[buildPlatformsKey], ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			platforms = append(platforms, p)
+		}
+	}
+	return platforms
+}
+
 func (c *ClusterClient) sideCarImage(imgName string) string {
 	if c.CustomData == nil {
 		return imgName
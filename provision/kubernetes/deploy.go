@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
@@ -209,6 +210,7 @@ func createImageBuildPod(ctx context.Context, params createPodParams) error {
 		destinationImages: params.destinationImages,
 		inputFile:         params.inputFile,
 		dockerfileBuild:   true,
+		platforms:         params.client.BuildPlatforms(),
 	})
 	if err != nil {
 		return err
@@ -302,6 +304,7 @@ func createPod(ctx context.Context, params createPodParams) error {
 			cmd:               fmt.Sprintf("mkdir -p $(dirname %[1]s) && cat >%[1]s && %[2]s", params.inputFile, strings.Join(params.cmds[2:], " ")),
 			destinationImages: params.destinationImages,
 			inputFile:         params.inputFile,
+			platforms:         params.client.BuildPlatforms(),
 		})
 		if err != nil {
 			return err
@@ -344,6 +347,52 @@ func createPod(ctx context.Context, params createPodParams) error {
 	return waitForPod(tctx, params.client, params.pod, ns, false)
 }
 
+// resourceMetadata returns the extra labels and annotations declared by the
+// pool of the app and by the tsuru.yaml of the version, merged onto the
+// deployments, pods and services of the app. Values declared in tsuru.yaml
+// take precedence over the pool ones.
+func resourceMetadata(ctx context.Context, a provision.App, version appTypes.AppVersion) (map[string]string, map[string]string, error) {
+	labels := map[string]string{}
+	annotations := map[string]string{}
+	p, err := pool.GetPoolByName(ctx, a.GetPool())
+	if err != nil && err != pool.ErrPoolNotFound {
+		return nil, nil, err
+	}
+	if p != nil {
+		poolMeta, err := p.GetResourceMetadata()
+		if err != nil {
+			return nil, nil, errors.WithMessagef(err, "invalid resource metadata in pool %q", p.Name)
+		}
+		for _, l := range poolMeta.Labels {
+			labels[l.Name] = l.Value
+		}
+		for _, annotation := range poolMeta.Annotations {
+			annotations[annotation.Name] = annotation.Value
+		}
+	}
+	if version == nil {
+		return labels, annotations, nil
+	}
+	yamlData, err := version.TsuruYamlData()
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if yamlData.Kubernetes == nil || yamlData.Kubernetes.Metadata == nil {
+		return labels, annotations, nil
+	}
+	yamlMeta := appTypes.MetadataFromMaps(yamlData.Kubernetes.Metadata.Labels, yamlData.Kubernetes.Metadata.Annotations)
+	if err = yamlMeta.Validate(); err != nil {
+		return nil, nil, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid kubernetes metadata in tsuru.yaml: %v", err)}
+	}
+	for _, l := range yamlMeta.Labels {
+		labels[l.Name] = l.Value
+	}
+	for _, annotation := range yamlMeta.Annotations {
+		annotations[annotation.Name] = annotation.Value
+	}
+	return labels, annotations, nil
+}
+
 func applyAppMetadata(pod *apiv1.Pod, app provision.App) {
 	if app == nil {
 		return
@@ -426,6 +475,7 @@ func logPodEvents(ctx context.Context, client *ClusterClient, initialResourceVer
 type hcResult struct {
 	liveness  *apiv1.Probe
 	readiness *apiv1.Probe
+	startup   *apiv1.Probe
 }
 
 func ensureHealthCheckDefaults(hc *provTypes.TsuruYamlHealthcheck) error {
@@ -452,13 +502,52 @@ func ensureHealthCheckDefaults(hc *provTypes.TsuruYamlHealthcheck) error {
 	return nil
 }
 
-func probesFromHC(hc *provTypes.TsuruYamlHealthcheck, client *ClusterClient, port int) (hcResult, error) {
+// probesFromYaml maps the readiness check of the app to the readiness probe,
+// keeping pods out of the service endpoints until ready, and the liveness
+// check to the liveness probe, restarting the pods when failing. Processes
+// with a max boot time get a startup probe, holding the other probes until
+// the pod boots.
+func probesFromYaml(yamlData provTypes.TsuruYamlData, process string, port int) (hcResult, error) {
 	var result hcResult
-	if hc == nil || (hc.Path == "" && len(hc.Command) == 0) {
+	var err error
+	result.readiness, err = probeFromHC(yamlData.ReadinessCheck(), port)
+	if err != nil {
+		return result, err
+	}
+	result.liveness, err = probeFromHC(yamlData.LivenessCheck(), port)
+	if err != nil {
+		return result, err
+	}
+	startup := yamlData.StartupForProcess(process)
+	grace := int32(startup.GracePeriodSeconds)
+	base := result.liveness
+	if base == nil {
+		base = result.readiness
+	}
+	if startup.MaxBootSeconds > 0 && base != nil {
+		probe := *base
+		probe.InitialDelaySeconds = grace
+		probe.FailureThreshold = int32(math.Ceil(float64(startup.MaxBootSeconds-startup.GracePeriodSeconds) / float64(probe.PeriodSeconds)))
+		if probe.FailureThreshold < 1 {
+			probe.FailureThreshold = 1
+		}
+		result.startup = &probe
 		return result, nil
 	}
+	for _, probe := range []*apiv1.Probe{result.readiness, result.liveness} {
+		if probe != nil {
+			probe.InitialDelaySeconds = grace
+		}
+	}
+	return result, nil
+}
+
+func probeFromHC(hc *provTypes.TsuruYamlHealthcheck, port int) (*apiv1.Probe, error) {
+	if hc == nil || (hc.Path == "" && len(hc.Command) == 0) {
+		return nil, nil
+	}
 	if err := ensureHealthCheckDefaults(hc); err != nil {
-		return result, err
+		return nil, err
 	}
 	headers := []apiv1.HTTPHeader{}
 	for header, value := range hc.Headers {
@@ -484,11 +573,7 @@ func probesFromHC(hc *provTypes.TsuruYamlHealthcheck, client *ClusterClient, por
 			Command: hc.Command,
 		}
 	}
-	result.readiness = probe
-	if hc.ForceRestart {
-		result.liveness = probe
-	}
-	return result, nil
+	return probe, nil
 }
 
 func ensureNamespaceForApp(ctx context.Context, client *ClusterClient, app provision.App) error {
@@ -649,6 +734,18 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 	if err != nil {
 		return nil, nil, errors.WithStack(err)
 	}
+	err = yamlData.ValidateInit()
+	if err != nil {
+		return nil, nil, err
+	}
+	err = yamlData.ValidateRoutableProcesses()
+	if err != nil {
+		return nil, nil, err
+	}
+	err = yamlData.ValidateStartup()
+	if err != nil {
+		return nil, nil, err
+	}
 	processPorts, err := getProcessPortsForVersion(version, process)
 	if err != nil {
 		return nil, nil, errors.WithStack(err)
@@ -656,7 +753,7 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 	var hcData hcResult
 	if process == webProcessName && len(processPorts) > 0 {
 		//TODO: add support to multiple HCs
-		hcData, err = probesFromHC(yamlData.Healthcheck, client, processPorts[0].TargetPort)
+		hcData, err = probesFromYaml(yamlData, process, processPorts[0].TargetPort)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -689,13 +786,15 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 			},
 		}
 	}
-	maxSurge := client.maxSurge(a.GetPool())
-	maxUnavailable := client.maxUnavailable(a.GetPool())
+	maxSurge := appMaxSurge(client, a)
+	maxUnavailable := appMaxUnavailable(client, a)
 	dnsConfig := dnsConfigNdots(client, a)
 	nodeSelector, affinity, err := defineSelectorAndAffinity(ctx, a, client)
 	if err != nil {
 		return nil, nil, err
 	}
+	affinity = withArchitectureAffinity(affinity, version.VersionInfo().Architectures)
+	affinity = withPlacementAffinity(affinity, a.GetPlacementConstraints())
 
 	_, uid := dockercommon.UserForContainer()
 	overCommit, err := client.OvercommitFactor(a.GetPool())
@@ -737,12 +836,19 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 		return nil, nil, err
 	}
 
+	extraLabels, annotations, err := resourceMetadata(ctx, a, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range extraLabels {
+		labels.RawLabels[k] = v
+	}
+
 	metadata := a.GetMetadata()
 	for _, l := range metadata.Labels {
 		labels.RawLabels[l.Name] = l.Value
 	}
 
-	annotations := map[string]string{}
 	for _, annotation := range metadata.Annotations {
 		annotations[annotation.Name] = annotation.Value
 	}
@@ -781,6 +887,7 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 		}
 	}
 
+	envs := appEnvs(a, process, version, false)
 	deployment := appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        depName,
@@ -821,14 +928,16 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 					Subdomain:      headlessServiceName(a, process),
 					ReadinessGates: readinessGates,
 					DNSConfig:      dnsConfig,
+					InitContainers: initContainers(yamlData.Init, deployImage, envs, mounts, resourceRequirements),
 					Containers: []apiv1.Container{
 						{
 							Name:           depName,
 							Image:          deployImage,
 							Command:        cmds,
-							Env:            appEnvs(a, process, version, false),
+							Env:            envs,
 							ReadinessProbe: hcData.readiness,
 							LivenessProbe:  hcData.liveness,
+							StartupProbe:   hcData.startup,
 							Resources:      resourceRequirements,
 							VolumeMounts:   mounts,
 							Ports:          containerPorts,
@@ -848,6 +957,30 @@ func createAppDeployment(ctx context.Context, client *ClusterClient, depName str
 	return newDep, labels, errors.WithStack(err)
 }
 
+// initContainers returns the init containers running the init steps of the
+// app, in order, before the app container. Steps without an image run in the
+// app image, from the app directory.
+func initContainers(steps []provTypes.TsuruYamlInitStep, deployImage string, envs []apiv1.EnvVar, mounts []apiv1.VolumeMount, resources apiv1.ResourceRequirements) []apiv1.Container {
+	var containers []apiv1.Container
+	for _, step := range steps {
+		cont := apiv1.Container{
+			Name:         step.Name,
+			Image:        step.Image,
+			Env:          envs,
+			VolumeMounts: mounts,
+			Resources:    resources,
+		}
+		if step.Image == "" {
+			cont.Image = deployImage
+			cont.Command = []string{"/bin/sh", "-lc", "[ -d /home/application/current ] && cd /home/application/current; " + step.Command}
+		} else if step.Command != "" {
+			cont.Command = []string{"/bin/sh", "-c", step.Command}
+		}
+		containers = append(containers, cont)
+	}
+	return containers
+}
+
 func appEnvs(a provision.App, process string, version appTypes.AppVersion, isDeploy bool) []apiv1.EnvVar {
 	appEnvs := EnvsForApp(a, process, version, isDeploy)
 	envs := make([]apiv1.EnvVar, len(appEnvs))
@@ -1121,8 +1254,17 @@ func monitorDeployment(ctx context.Context, client *ClusterClient, dep *appsv1.D
 	}()
 
 	fmt.Fprintf(w, "\n---- Updating units [%s] [version %d] ----\n", processName, version.Version())
+	tsuruYamlData, err := version.TsuruYamlData()
+	if err != nil {
+		return revision, errors.WithStack(err)
+	}
+	maxBoot := tsuruYamlData.StartupForProcess(processName).MaxBoot()
 	kubeConf := getKubeConfig()
-	timer := time.NewTimer(kubeConf.DeploymentProgressTimeout)
+	progressTimeout := kubeConf.DeploymentProgressTimeout
+	if maxBoot > progressTimeout {
+		progressTimeout = maxBoot
+	}
+	timer := time.NewTimer(progressTimeout)
 	for dep.Status.ObservedGeneration < dep.Generation {
 		dep, err = client.AppsV1().Deployments(ns).Get(ctx, dep.Name, metav1.GetOptions{})
 		if err != nil {
@@ -1141,11 +1283,10 @@ func monitorDeployment(ctx context.Context, client *ClusterClient, dep *appsv1.D
 	oldUpdatedReplicas := int32(-1)
 	oldReadyUnits := int32(-1)
 	oldPendingTermination := int32(-1)
-	tsuruYamlData, err := version.TsuruYamlData()
-	if err != nil {
-		return revision, errors.WithStack(err)
-	}
 	maxWaitTimeDuration := dockercommon.DeployHealthcheckTimeout(tsuruYamlData)
+	if maxBoot > maxWaitTimeDuration {
+		maxWaitTimeDuration = maxBoot
+	}
 	var healthcheckTimeout <-chan time.Time
 	t0 := time.Now()
 	largestReady := int32(0)
@@ -1180,7 +1321,7 @@ func monitorDeployment(ctx context.Context, client *ClusterClient, dep *appsv1.D
 			if !timer.Stop() {
 				<-timer.C
 			}
-			timer.Reset(kubeConf.DeploymentProgressTimeout)
+			timer.Reset(progressTimeout)
 		}
 		pendingTermination := dep.Status.Replicas - dep.Status.UpdatedReplicas
 		if oldPendingTermination != pendingTermination && pendingTermination > 0 {
@@ -1612,7 +1753,22 @@ func (m *serviceManager) ensureServices(ctx context.Context, a provision.App, pr
 	if err != nil {
 		return errors.WithMessage(err, "could not to parse all services annotations")
 	}
+	extraLabels, extraAnnotations, err := resourceMetadata(ctx, a, currentVersion)
+	if err != nil {
+		return err
+	}
 	for _, svcData := range svcsToCreate {
+		for k, v := range extraLabels {
+			svcData.labels[k] = v
+		}
+		if len(extraAnnotations) > 0 {
+			if svcData.annotations == nil {
+				svcData.annotations = map[string]string{}
+			}
+			for k, v := range extraAnnotations {
+				svcData.annotations[k] = v
+			}
+		}
 		if addAllServicesAnnotations != nil {
 			if svcData.annotations == nil {
 				svcData.annotations = addAllServicesAnnotations
@@ -1826,6 +1982,10 @@ func getProcessPortsForVersion(version appTypes.AppVersion, process string) ([]p
 	}
 
 	defaultPort := defaultKubernetesPodPortConfig()
+	if rp := tsuruYamlData.RoutableProcess(process); rp != nil && rp.Port > 0 {
+		defaultPort.TargetPort = rp.Port
+		return []provTypes.TsuruYamlKubernetesProcessPortConfig{defaultPort}, nil
+	}
 	targetPorts := getTargetPortsForVersion(version)
 	ports = make([]provTypes.TsuruYamlKubernetesProcessPortConfig, len(targetPorts))
 	for i := range ports {
@@ -1957,6 +2117,7 @@ type deployAgentConfig struct {
 	registryAuth      registryAuthConfig
 	runAsUser         string
 	dockerfileBuild   bool
+	platforms         []string
 }
 
 func newDeployAgentPod(ctx context.Context, params createPodParams, conf deployAgentConfig) (apiv1.Pod, error) {
@@ -2155,7 +2316,7 @@ func newDeployAgentImageBuildPod(ctx context.Context, client *ClusterClient, sou
 }
 
 func (c deployAgentConfig) asEnvs() []apiv1.EnvVar {
-	return []apiv1.EnvVar{
+	envs := []apiv1.EnvVar{
 		{Name: "DEPLOYAGENT_RUN_AS_SIDECAR", Value: "true"},
 		{Name: "DEPLOYAGENT_DESTINATION_IMAGES", Value: strings.Join(c.destinationImages, ",")},
 		{Name: "DEPLOYAGENT_SOURCE_IMAGE", Value: c.sourceImage},
@@ -2169,6 +2330,10 @@ func (c deployAgentConfig) asEnvs() []apiv1.EnvVar {
 		{Name: "BUILDKITD_FLAGS", Value: "--oci-worker-no-process-sandbox"},
 		{Name: "BUILDCTL_CONNECT_RETRIES_MAX", Value: "50"},
 	}
+	if len(c.platforms) > 0 {
+		envs = append(envs, apiv1.EnvVar{Name: "DEPLOYAGENT_PLATFORMS", Value: strings.Join(c.platforms, ",")})
+	}
+	return envs
 }
 
 func newDeployAgentContainer(conf deployAgentConfig, pullSecrets []apiv1.LocalObjectReference, quota apiv1.ResourceRequirements) apiv1.Container {
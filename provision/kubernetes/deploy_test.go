@@ -510,6 +510,156 @@ func (s *S) TestServiceManagerDeployServiceWithCustomAnnotationsFromDeployment(c
 	c.Assert(svc2.Annotations, check.DeepEquals, map[string]string{"a1": "v1", "a2": "v2"})
 }
 
+func (s *S) TestServiceManagerDeployServiceWithResourceMetadata(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	err := pool.PoolUpdate(context.TODO(), "test-default", pool.UpdatePoolOptions{Labels: map[string]string{
+		"resource-labels":      `{"cost-center": "platform", "team": "pool"}`,
+		"resource-annotations": `{"sidecar.istio.io/inject": "true"}`,
+	}})
+	c.Assert(err, check.IsNil)
+	a := &app.App{Name: "myapp", TeamOwner: s.team.Name}
+	err = app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+		},
+		"kubernetes": provTypes.TsuruYamlKubernetesConfig{
+			Metadata: &provTypes.TsuruYamlKubernetesMetadata{
+				Labels:      map[string]string{"team": "payments"},
+				Annotations: map[string]string{"prometheus.io/scrape": "true"},
+			},
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	nsName, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.AppsV1().Deployments(nsName).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Labels["cost-center"], check.Equals, "platform")
+	c.Assert(dep.Labels["team"], check.Equals, "payments")
+	c.Assert(dep.Spec.Template.Labels["team"], check.Equals, "payments")
+	c.Assert(dep.Spec.Template.Annotations, check.DeepEquals, map[string]string{
+		"sidecar.istio.io/inject": "true",
+		"prometheus.io/scrape":    "true",
+	})
+	c.Assert(dep.Spec.Selector.MatchLabels["team"], check.Equals, "")
+	svc, err := s.client.CoreV1().Services(nsName).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(svc.Labels["cost-center"], check.Equals, "platform")
+	c.Assert(svc.Labels["team"], check.Equals, "payments")
+	c.Assert(svc.Annotations, check.DeepEquals, map[string]string{
+		"sidecar.istio.io/inject": "true",
+		"prometheus.io/scrape":    "true",
+	})
+	c.Assert(svc.Spec.Selector["team"], check.Equals, "")
+}
+
+func (s *S) TestServiceManagerDeployServiceWithReservedResourceMetadata(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &app.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+		},
+		"kubernetes": provTypes.TsuruYamlKubernetesConfig{
+			Metadata: &provTypes.TsuruYamlKubernetesMetadata{
+				Labels: map[string]string{"tsuru.io/app-name": "otherapp"},
+			},
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.ErrorMatches, `(?s).*invalid kubernetes metadata in tsuru.yaml: .*prefix tsuru.io/ is private.*`)
+}
+
+func (s *S) TestServiceManagerDeployServiceWithInitSteps(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &app.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+		},
+		"init": []provTypes.TsuruYamlInitStep{
+			{Name: "migrate", Command: "python migrate.py"},
+			{Name: "fetch-config", Image: "busybox", Command: "wget http://config"},
+			{Name: "warmup", Image: "myorg/warmup"},
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	nsName, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.AppsV1().Deployments(nsName).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	appContainer := dep.Spec.Template.Spec.Containers[0]
+	initContainers := dep.Spec.Template.Spec.InitContainers
+	c.Assert(initContainers, check.HasLen, 3)
+	c.Assert(initContainers[0].Name, check.Equals, "migrate")
+	c.Assert(initContainers[0].Image, check.Equals, appContainer.Image)
+	c.Assert(initContainers[0].Command, check.DeepEquals, []string{
+		"/bin/sh", "-lc", "[ -d /home/application/current ] && cd /home/application/current; python migrate.py",
+	})
+	c.Assert(initContainers[0].Env, check.DeepEquals, appContainer.Env)
+	c.Assert(initContainers[1].Name, check.Equals, "fetch-config")
+	c.Assert(initContainers[1].Image, check.Equals, "busybox")
+	c.Assert(initContainers[1].Command, check.DeepEquals, []string{"/bin/sh", "-c", "wget http://config"})
+	c.Assert(initContainers[2].Name, check.Equals, "warmup")
+	c.Assert(initContainers[2].Image, check.Equals, "myorg/warmup")
+	c.Assert(initContainers[2].Command, check.IsNil)
+}
+
+func (s *S) TestServiceManagerDeployServiceWithInvalidInitSteps(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &app.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+		},
+		"init": []provTypes.TsuruYamlInitStep{
+			{Name: "Migrate_DB", Command: "python migrate.py"},
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.ErrorMatches, `(?s).*invalid init step name "Migrate_DB".*`)
+}
+
 func (s *S) TestServiceManagerDeployServiceWithNodeAffinity(c *check.C) {
 	waitDep := s.mock.DeploymentReactions(c)
 	defer waitDep()
@@ -1316,6 +1466,135 @@ func (s *S) TestServiceManagerDeployServiceWithHC(c *check.C) {
 	}
 }
 
+func (s *S) TestServiceManagerDeployServiceWithReadinessAndLiveness(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &app.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "cm1",
+		},
+		"healthcheck": provTypes.TsuruYamlHealthcheck{
+			Path:         "/hc",
+			ForceRestart: true,
+		},
+		"readiness": provTypes.TsuruYamlHealthcheck{
+			Path:            "/ready",
+			AllowedFailures: 1,
+		},
+		"liveness": provTypes.TsuruYamlHealthcheck{
+			Command:         []string{"cat", "/tmp/alive"},
+			IntervalSeconds: 30,
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"web": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	nsName, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.Clientset.AppsV1().Deployments(nsName).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.Containers[0].ReadinessProbe, check.DeepEquals, &apiv1.Probe{
+		PeriodSeconds:    10,
+		FailureThreshold: 1,
+		TimeoutSeconds:   60,
+		Handler: apiv1.Handler{
+			HTTPGet: &apiv1.HTTPGetAction{
+				Path:        "/ready",
+				Port:        intstr.FromInt(8888),
+				Scheme:      apiv1.URISchemeHTTP,
+				HTTPHeaders: []apiv1.HTTPHeader{},
+			},
+		},
+	})
+	c.Assert(dep.Spec.Template.Spec.Containers[0].LivenessProbe, check.DeepEquals, &apiv1.Probe{
+		PeriodSeconds:    30,
+		FailureThreshold: 3,
+		TimeoutSeconds:   60,
+		Handler: apiv1.Handler{
+			Exec: &apiv1.ExecAction{
+				Command: []string{"cat", "/tmp/alive"},
+			},
+		},
+	})
+}
+
+func (s *S) TestServiceManagerDeployServiceWithStartup(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &app.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "cm1",
+		},
+		"readiness": provTypes.TsuruYamlHealthcheck{
+			Path: "/ready",
+		},
+		"liveness": provTypes.TsuruYamlHealthcheck{
+			Path: "/alive",
+		},
+		"startup": map[string]provTypes.TsuruYamlStartup{
+			"web": {GracePeriodSeconds: 30, MaxBootSeconds: 300},
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"web": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	nsName, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.Clientset.AppsV1().Deployments(nsName).Get(context.TODO(), "myapp-web", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(dep.Spec.Template.Spec.Containers[0].ReadinessProbe.InitialDelaySeconds, check.Equals, int32(0))
+	c.Assert(dep.Spec.Template.Spec.Containers[0].LivenessProbe.InitialDelaySeconds, check.Equals, int32(0))
+	c.Assert(dep.Spec.Template.Spec.Containers[0].StartupProbe, check.DeepEquals, &apiv1.Probe{
+		InitialDelaySeconds: 30,
+		PeriodSeconds:       10,
+		FailureThreshold:    27,
+		TimeoutSeconds:      60,
+		Handler: apiv1.Handler{
+			HTTPGet: &apiv1.HTTPGetAction{
+				Path:        "/alive",
+				Port:        intstr.FromInt(8888),
+				Scheme:      apiv1.URISchemeHTTP,
+				HTTPHeaders: []apiv1.HTTPHeader{},
+			},
+		},
+	})
+}
+
+func (s *S) TestProbesFromYamlGracePeriod(c *check.C) {
+	yamlData := provTypes.TsuruYamlData{
+		Readiness: &provTypes.TsuruYamlHealthcheck{Path: "/ready"},
+		Startup: map[string]provTypes.TsuruYamlStartup{
+			"web": {GracePeriodSeconds: 45},
+		},
+	}
+	result, err := probesFromYaml(yamlData, "web", 8888)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.startup, check.IsNil)
+	c.Assert(result.liveness, check.IsNil)
+	c.Assert(result.readiness.InitialDelaySeconds, check.Equals, int32(45))
+	result, err = probesFromYaml(yamlData, "worker", 8888)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.readiness.InitialDelaySeconds, check.Equals, int32(0))
+}
+
 func (s *S) TestEnsureBackendConfigIfEnabled(c *check.C) {
 	waitDep := s.mock.DeploymentReactions(c)
 	defer waitDep()
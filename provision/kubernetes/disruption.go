@@ -0,0 +1,65 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/provision"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var defaultPDBMaxUnavailable = intstr.FromString("10%")
+
+// appMaxSurge returns the max surge of the app deployments, either set in
+// the app disruption settings or in the cluster.
+func appMaxSurge(client *ClusterClient, a provision.App) intstr.IntOrString {
+	if maxSurge := a.GetDisruptionSettings().MaxSurge; maxSurge != "" {
+		return intstr.Parse(maxSurge)
+	}
+	return client.maxSurge(a.GetPool())
+}
+
+// appMaxUnavailable returns the max unavailable of the app deployments,
+// either set in the app disruption settings or in the cluster.
+func appMaxUnavailable(client *ClusterClient, a provision.App) intstr.IntOrString {
+	if maxUnavailable := a.GetDisruptionSettings().MaxUnavailable; maxUnavailable != "" {
+		return intstr.Parse(maxUnavailable)
+	}
+	return client.maxUnavailable(a.GetPool())
+}
+
+// pdbBudget returns the budget of the app PDBs, the min available units set
+// in the app disruption settings or else 10% of max unavailable units.
+func pdbBudget(a provision.App) (minAvailable, maxUnavailable *intstr.IntOrString) {
+	if min := a.GetDisruptionSettings().PDBMinAvailable; min != "" {
+		return intOrStringPtr(intstr.Parse(min)), nil
+	}
+	return nil, intOrStringPtr(defaultPDBMaxUnavailable)
+}
+
+func (p *kubernetesProvisioner) DisruptionSettings(ctx context.Context, a provision.App) (provision.EffectiveDisruptionSettings, error) {
+	client, err := clusterForPool(ctx, a.GetPool())
+	if err != nil {
+		return provision.EffectiveDisruptionSettings{}, err
+	}
+	maxSurge := appMaxSurge(client, a)
+	maxUnavailable := appMaxUnavailable(client, a)
+	settings := provision.EffectiveDisruptionSettings{
+		MaxSurge:       maxSurge.String(),
+		MaxUnavailable: maxUnavailable.String(),
+		PDBEnabled:     !client.disablePDB(a.GetPool()),
+	}
+	if settings.PDBEnabled {
+		minAvailable, pdbMaxUnavailable := pdbBudget(a)
+		if minAvailable != nil {
+			settings.PDBMinAvailable = minAvailable.String()
+		}
+		if pdbMaxUnavailable != nil {
+			settings.PDBMaxUnavailable = pdbMaxUnavailable.String()
+		}
+	}
+	return settings, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/tsuru/tsuru/app"
+	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/servicecommon"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func (s *S) TestDisruptionSettings(c *check.C) {
+	a := &app.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	settings, err := s.p.DisruptionSettings(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(settings, check.DeepEquals, provision.EffectiveDisruptionSettings{
+		MaxSurge:          "100%",
+		MaxUnavailable:    "0",
+		PDBEnabled:        true,
+		PDBMaxUnavailable: "10%",
+	})
+	s.clusterClient.CustomData[maxSurgeKey] = "30%"
+	defer delete(s.clusterClient.CustomData, maxSurgeKey)
+	a.Disruption = appTypes.DisruptionSettings{MaxUnavailable: "1", PDBMinAvailable: "50%"}
+	settings, err = s.p.DisruptionSettings(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(settings, check.DeepEquals, provision.EffectiveDisruptionSettings{
+		MaxSurge:        "30%",
+		MaxUnavailable:  "1",
+		PDBEnabled:      true,
+		PDBMinAvailable: "50%",
+	})
+	s.clusterClient.CustomData[disablePDBKey] = "true"
+	defer delete(s.clusterClient.CustomData, disablePDBKey)
+	settings, err = s.p.DisruptionSettings(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(settings, check.DeepEquals, provision.EffectiveDisruptionSettings{
+		MaxSurge:       "30%",
+		MaxUnavailable: "1",
+	})
+}
+
+func (s *S) TestServiceManagerDeployServiceWithAppDisruptionSettings(c *check.C) {
+	waitDep := s.mock.DeploymentReactions(c)
+	defer waitDep()
+	m := serviceManager{client: s.clusterClient}
+	a := &app.App{Name: "myapp", TeamOwner: s.team.Name}
+	err := app.CreateApp(context.TODO(), a, s.user)
+	c.Assert(err, check.IsNil)
+	err = a.SetDisruptionSettings(appTypes.DisruptionSettings{MaxSurge: "1", MaxUnavailable: "25%", PDBMinAvailable: "2"})
+	c.Assert(err, check.IsNil)
+	version := newCommittedVersion(c, a, map[string]interface{}{
+		"processes": map[string]interface{}{
+			"p1": "cm1",
+		},
+	})
+	err = servicecommon.RunServicePipeline(context.TODO(), &m, 0, provision.DeployArgs{
+		App:     a,
+		Version: version,
+	}, servicecommon.ProcessSpec{
+		"p1": servicecommon.ProcessState{Start: true},
+	})
+	c.Assert(err, check.IsNil)
+	waitDep()
+	nsName, err := s.client.AppNamespace(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	dep, err := s.client.AppsV1().Deployments(nsName).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(*dep.Spec.Strategy.RollingUpdate.MaxSurge, check.Equals, intstr.FromInt(1))
+	c.Assert(*dep.Spec.Strategy.RollingUpdate.MaxUnavailable, check.Equals, intstr.FromString("25%"))
+	pdb, err := s.client.PolicyV1beta1().PodDisruptionBudgets(nsName).Get(context.TODO(), "myapp-p1", metav1.GetOptions{})
+	c.Assert(err, check.IsNil)
+	c.Assert(pdb.Spec.MinAvailable, check.DeepEquals, intOrStringPtr(intstr.FromInt(2)))
+	c.Assert(pdb.Spec.MaxUnavailable, check.IsNil)
+}
@@ -144,8 +144,8 @@ func ensureBackendConfig(ctx context.Context, args backendConfigArgs) (bool, err
 	}
 
 	var hc provTypes.TsuruYamlHealthcheck
-	if yamlData.Healthcheck != nil {
-		hc = *yamlData.Healthcheck
+	if readiness := yamlData.ReadinessCheck(); readiness != nil {
+		hc = *readiness
 	}
 	backendConfig, err := backendConfigFromHC(ctx, args.app, args.process, hc)
 	if err != nil {
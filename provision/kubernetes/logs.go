@@ -129,6 +129,50 @@ func (p *kubernetesProvisioner) WatchLogs(ctx context.Context, app appTypes.App,
 	return watcher, nil
 }
 
+// initLogSource is the log source of the output of init containers, which is
+// attached to the logs of their units.
+const initLogSource = "init"
+
+// readPodContainerLogs reads the logs of a container of the pod, or of its
+// app container when containerName is empty.
+func readPodContainerLogs(ctx context.Context, clusterClient *ClusterClient, ns string, pod *apiv1.Pod, containerName, source string, tailLimit *int64) ([]appTypes.Applog, error) {
+	request := clusterClient.CoreV1().Pods(ns).GetLogs(pod.ObjectMeta.Name, &apiv1.PodLogOptions{
+		Container:  containerName,
+		TailLines:  tailLimit,
+		Timestamps: true,
+	})
+	stream, err := request.Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	appName := pod.ObjectMeta.Labels[tsuruLabelAppName]
+	reader := bufio.NewReader(stream)
+	tsuruLogs := make([]appTypes.Applog, 0)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if !knet.IsProbableEOF(err) {
+				return tsuruLogs, err
+			}
+			break
+		}
+
+		if len(line) == 0 {
+			continue
+		}
+
+		tsuruLog := parsek8sLogLine(strings.TrimSpace(string(line)))
+		tsuruLog.Unit = pod.ObjectMeta.Name
+		tsuruLog.AppName = appName
+		tsuruLog.Source = source
+		tsuruLogs = append(tsuruLogs, tsuruLog)
+	}
+	return tsuruLogs, nil
+}
+
 func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns string, pods []*apiv1.Pod, args appTypes.ListLogArgs) ([]appTypes.Applog, error) {
 	var wg sync.WaitGroup
 
@@ -147,43 +191,21 @@ func listLogsFromPods(ctx context.Context, clusterClient *ClusterClient, ns stri
 		go func(index int, pod *apiv1.Pod) {
 			defer wg.Done()
 
-			request := clusterClient.CoreV1().Pods(ns).GetLogs(pod.ObjectMeta.Name, &apiv1.PodLogOptions{
-				TailLines:  tailLimit,
-				Timestamps: true,
-			})
-			stream, err := request.Stream(ctx)
-			if err != nil {
-				errs[index] = err
-				return
-			}
-
-			appName := pod.ObjectMeta.Labels[tsuruLabelAppName]
 			appProcess := pod.ObjectMeta.Labels[tsuruLabelAppProcess]
-
-			reader := bufio.NewReader(stream)
-			tsuruLogs := make([]appTypes.Applog, 0)
-
-			for {
-				line, err := reader.ReadBytes('\n')
+			var tsuruLogs []appTypes.Applog
+			for _, initContainer := range pod.Spec.InitContainers {
+				initLogs, err := readPodContainerLogs(ctx, clusterClient, ns, pod, initContainer.Name, initLogSource, tailLimit)
 				if err != nil {
-					if !knet.IsProbableEOF(err) {
-						errs[index] = err
-					}
-					break
+					errs[index] = err
+					return
 				}
-
-				if len(line) == 0 {
-					continue
-				}
-
-				tsuruLog := parsek8sLogLine(strings.TrimSpace(string(line)))
-				tsuruLog.Unit = pod.ObjectMeta.Name
-				tsuruLog.AppName = appName
-				tsuruLog.Source = appProcess
-				tsuruLogs = append(tsuruLogs, tsuruLog)
+				tsuruLogs = append(tsuruLogs, initLogs...)
 			}
-
-			logs[index] = tsuruLogs
+			podLogs, err := readPodContainerLogs(ctx, clusterClient, ns, pod, "", appProcess, tailLimit)
+			if err != nil {
+				errs[index] = err
+			}
+			logs[index] = append(tsuruLogs, podLogs...)
 		}(index, pod)
 	}
 
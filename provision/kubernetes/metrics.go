@@ -11,36 +11,11 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
 
 func (p *kubernetesProvisioner) UnitsMetrics(ctx context.Context, a provision.App) ([]provision.UnitMetric, error) {
-	clusterClient, err := clusterForPool(ctx, a.GetPool())
-	if err != nil {
-		return nil, err
-	}
-	ns, err := clusterClient.AppNamespace(ctx, a)
-	if err != nil {
-		return nil, err
-	}
-
-	metricsClient, err := MetricsClientForConfig(clusterClient.restConfig)
-	if err != nil {
-		return nil, err
-	}
-	l, err := provision.ServiceLabels(ctx, provision.ServiceLabelsOpts{
-		App: a,
-		ServiceLabelExtendedOpts: provision.ServiceLabelExtendedOpts{
-			Prefix:      tsuruLabelPrefix,
-			Provisioner: provisionerName,
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-	labelSelector := labels.SelectorFromSet(l.ToAppSelector())
-	metricList, err := metricsClient.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{
-		LabelSelector: labelSelector.String(),
-	})
+	metricList, err := podMetricsForApp(ctx, a)
 	if err != nil {
 		return nil, err
 	}
@@ -75,3 +50,59 @@ func (p *kubernetesProvisioner) UnitsMetrics(ctx context.Context, a provision.Ap
 
 	return unitMetrics, nil
 }
+
+// UnitsUsage samples the resources used by the app units from the metrics
+// API. Network usage is not available in the metrics API and is not sampled.
+func (p *kubernetesProvisioner) UnitsUsage(ctx context.Context, a provision.App) ([]provision.UnitUsage, error) {
+	metricList, err := podMetricsForApp(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	usage := []provision.UnitUsage{}
+	for _, metric := range metricList.Items {
+		unitUsage := provision.UnitUsage{
+			ID:      metric.ObjectMeta.Name,
+			Process: labelSetFromMeta(&metric.ObjectMeta).AppProcess(),
+		}
+		for _, container := range metric.Containers {
+			if cpuUsage, ok := container.Usage["cpu"]; ok {
+				unitUsage.CPU += cpuUsage.AsApproximateFloat64()
+			}
+			if memoryUsage, ok := container.Usage["memory"]; ok {
+				unitUsage.Memory += memoryUsage.Value()
+			}
+		}
+		usage = append(usage, unitUsage)
+	}
+	return usage, nil
+}
+
+func podMetricsForApp(ctx context.Context, a provision.App) (*metricsv1beta1.PodMetricsList, error) {
+	clusterClient, err := clusterForPool(ctx, a.GetPool())
+	if err != nil {
+		return nil, err
+	}
+	ns, err := clusterClient.AppNamespace(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+
+	metricsClient, err := MetricsClientForConfig(clusterClient.restConfig)
+	if err != nil {
+		return nil, err
+	}
+	l, err := provision.ServiceLabels(ctx, provision.ServiceLabelsOpts{
+		App: a,
+		ServiceLabelExtendedOpts: provision.ServiceLabelExtendedOpts{
+			Prefix:      tsuruLabelPrefix,
+			Provisioner: provisionerName,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	labelSelector := labels.SelectorFromSet(l.ToAppSelector())
+	return metricsClient.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector.String(),
+	})
+}
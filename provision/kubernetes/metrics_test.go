@@ -87,3 +87,69 @@ func (s *S) Test_MetricsProvisioner_UnitsMetrics(c *check.C) {
 	})
 
 }
+
+func (s *S) Test_UsageProvisioner_UnitsUsage(c *check.C) {
+	a, wait, rollback := s.mock.DefaultReactions(c)
+	defer rollback()
+
+	evt, err := event.New(&event.Opts{
+		Target:  event.Target{Type: event.TargetTypeApp, Value: a.GetName()},
+		Kind:    permission.PermAppDeploy,
+		Owner:   s.token,
+		Allowed: event.Allowed(permission.PermAppDeploy),
+	})
+	c.Assert(err, check.IsNil)
+	customData := map[string]interface{}{
+		"processes": map[string]interface{}{
+			"web": "run mycmd arg1",
+		},
+	}
+	version := newCommittedVersion(c, a, customData)
+	_, err = s.p.Deploy(context.TODO(), provision.DeployArgs{App: a, Version: version, Event: evt})
+	c.Assert(err, check.IsNil)
+	wait()
+
+	s.client.MetricsClientset.PrependReactor("list", "pods", func(action ktesting.Action) (handled bool, ret runtime.Object, err error) {
+		return true, &metricsv1beta1.PodMetricsList{
+			Items: []metricsv1beta1.PodMetrics{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      a.GetName() + "-123",
+						Namespace: "default",
+						Labels: map[string]string{
+							"tsuru.io/app-name":    a.GetName(),
+							"tsuru.io/app-process": "web",
+						},
+					},
+					Containers: []metricsv1beta1.ContainerMetrics{
+						{
+							Name: a.GetName() + "-123-web",
+							Usage: corev1.ResourceList{
+								"cpu":    resource.MustParse("1500m"),
+								"memory": resource.MustParse("100Mi"),
+							},
+						},
+						{
+							Name: a.GetName() + "-123-sidecar",
+							Usage: corev1.ResourceList{
+								"cpu":    resource.MustParse("500m"),
+								"memory": resource.MustParse("28Mi"),
+							},
+						},
+					},
+				},
+			},
+		}, nil
+	})
+
+	usage, err := s.p.UnitsUsage(context.TODO(), a)
+	c.Assert(err, check.IsNil)
+	c.Assert(usage, check.DeepEquals, []provision.UnitUsage{
+		{
+			ID:      "myapp-123",
+			Process: "web",
+			CPU:     2,
+			Memory:  128 * 1024 * 1024,
+		},
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/tsuru/tsuru/log"
 	"github.com/tsuru/tsuru/permission"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/provision/unithistory"
 	"github.com/tsuru/tsuru/router/rebuild"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -157,6 +158,9 @@ func (c *clusterController) start() (v1informers.PodInformer, error) {
 			if err != nil {
 				log.Errorf("[router-update-controller] error on add pod event: %v", err)
 			}
+			if pod, ok := obj.(*apiv1.Pod); ok {
+				recordPodStatus(nil, pod)
+			}
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			if !c.isLeader() {
@@ -166,6 +170,10 @@ func (c *clusterController) start() (v1informers.PodInformer, error) {
 			if err != nil {
 				log.Errorf("[router-update-controller] error on update pod event: %v", err)
 			}
+			oldPod, _ := oldObj.(*apiv1.Pod)
+			if newPod, ok := newObj.(*apiv1.Pod); ok {
+				recordPodStatus(oldPod, newPod)
+			}
 		},
 		DeleteFunc: func(obj interface{}) {
 			if !c.isLeader() {
@@ -175,6 +183,9 @@ func (c *clusterController) start() (v1informers.PodInformer, error) {
 			if err != nil {
 				log.Errorf("[router-update-controller] error on delete pod event: %v", err)
 			}
+			if pod, ok := obj.(*apiv1.Pod); ok {
+				recordPodStatus(pod, nil)
+			}
 		},
 	})
 
@@ -199,6 +210,39 @@ func (c *clusterController) start() (v1informers.PodInformer, error) {
 	return informer, nil
 }
 
+// recordPodStatus stores the status transition of app units, it's only
+// called by the leader so each transition is recorded once. A nil newPod
+// records the removal of the unit.
+func recordPodStatus(oldPod, newPod *apiv1.Pod) {
+	pod := newPod
+	if pod == nil {
+		pod = oldPod
+	}
+	labelSet := labelSetFromMeta(&pod.ObjectMeta)
+	if labelSet.AppName() == "" || labelSet.AppProcess() == "" || labelSet.IsDeploy() {
+		return
+	}
+	transition := unithistory.Transition{
+		App:     labelSet.AppName(),
+		Unit:    pod.Name,
+		Process: labelSet.AppProcess(),
+		Status:  unithistory.StatusRemoved,
+	}
+	if oldPod != nil {
+		oldStatus, _ := podStatus(oldPod)
+		transition.From = oldStatus.String()
+	}
+	if newPod != nil {
+		status, reason := podStatus(newPod)
+		transition.Status = status.String()
+		transition.Reason = reason
+	}
+	err := unithistory.Record(transition)
+	if err != nil {
+		log.Errorf("[unit-history] unable to record status transition of unit %s: %v", pod.Name, err)
+	}
+}
+
 func (c *clusterController) onAdd(obj interface{}) error {
 	// Pods are never ready on add, ignore and do nothing
 	return nil
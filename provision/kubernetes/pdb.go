@@ -85,8 +85,7 @@ func newPDB(ctx context.Context, client *ClusterClient, app provision.App, proce
 	if client.disablePDB(app.GetPool()) {
 		return nil, nil
 	}
-	maxUnavailableByProcess := intstr.FromString("10%")
-
+	minAvailable, maxUnavailable := pdbBudget(app)
 	ns, err := client.AppNamespace(ctx, app)
 	if err != nil {
 		return nil, err
@@ -101,7 +100,8 @@ func newPDB(ctx context.Context, client *ClusterClient, app provision.App, proce
 			Labels:    pdbLabels(app, process).ToLabels(),
 		},
 		Spec: policyv1beta1.PodDisruptionBudgetSpec{
-			MaxUnavailable: &maxUnavailableByProcess,
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
 			Selector:       &metav1.LabelSelector{MatchLabels: routableLabels.ToRoutableSelector()},
 		},
 	}, nil
@@ -8,6 +8,7 @@ import (
 	"context"
 
 	"github.com/tsuru/tsuru/app"
+	appTypes "github.com/tsuru/tsuru/types/app"
 	check "gopkg.in/check.v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -47,6 +48,37 @@ func (s *S) TestNewPDB(c *check.C) {
 				},
 			},
 		},
+		"with app min available": {
+			setup: func() (teardown func()) {
+				a.Disruption = appTypes.DisruptionSettings{PDBMinAvailable: "2"}
+				return func() {
+					a.Disruption = appTypes.DisruptionSettings{}
+				}
+			},
+			expected: &policyv1beta1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "myapp-p1",
+					Namespace: "default",
+					Labels: map[string]string{
+						"tsuru.io/is-tsuru":    "true",
+						"tsuru.io/app-name":    "myapp",
+						"tsuru.io/app-process": "p1",
+						"tsuru.io/app-team":    "admin",
+						"tsuru.io/provisioner": "kubernetes",
+					},
+				},
+				Spec: policyv1beta1.PodDisruptionBudgetSpec{
+					MinAvailable: intOrStringPtr(intstr.FromInt(2)),
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"tsuru.io/app-name":    "myapp",
+							"tsuru.io/app-process": "p1",
+							"tsuru.io/is-routable": "true",
+						},
+					},
+				},
+			},
+		},
 		"when disable PDB for cluster/pool": {
 			setup: func() (teardown func()) {
 				s.clusterClient.CustomData["test-default:disable-pdb"] = "true"
@@ -0,0 +1,32 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	appTypes "github.com/tsuru/tsuru/types/app"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+var placementOperators = map[string]apiv1.NodeSelectorOperator{
+	appTypes.PlacementOpIn:           apiv1.NodeSelectorOpIn,
+	appTypes.PlacementOpNotIn:        apiv1.NodeSelectorOpNotIn,
+	appTypes.PlacementOpExists:       apiv1.NodeSelectorOpExists,
+	appTypes.PlacementOpDoesNotExist: apiv1.NodeSelectorOpDoesNotExist,
+}
+
+// withPlacementAffinity returns a copy of affinity requiring the pods to run
+// on nodes satisfying the placement constraints of the app. Constraints refer
+// to node metadata, stored as node labels prefixed by tsuru.
+func withPlacementAffinity(affinity *apiv1.Affinity, constraints []appTypes.PlacementConstraint) *apiv1.Affinity {
+	requirements := make([]apiv1.NodeSelectorRequirement, 0, len(constraints))
+	for _, c := range constraints {
+		requirements = append(requirements, apiv1.NodeSelectorRequirement{
+			Key:      tsuruLabelPrefix + c.Key,
+			Operator: placementOperators[c.Operator],
+			Values:   c.Values,
+		})
+	}
+	return withRequiredNodeAffinity(affinity, requirements...)
+}
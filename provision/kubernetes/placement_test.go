@@ -0,0 +1,33 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kubernetes
+
+import (
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func (s *S) TestWithPlacementAffinity(c *check.C) {
+	c.Assert(withPlacementAffinity(nil, nil), check.IsNil)
+	affinity := withPlacementAffinity(nil, []appTypes.PlacementConstraint{
+		{Key: "disk", Operator: appTypes.PlacementOpIn, Values: []string{"ssd"}},
+		{Key: "zone", Operator: appTypes.PlacementOpNotIn, Values: []string{"a", "b"}},
+		{Key: "gpu", Operator: appTypes.PlacementOpDoesNotExist},
+	})
+	c.Assert(affinity, check.DeepEquals, &apiv1.Affinity{
+		NodeAffinity: &apiv1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &apiv1.NodeSelector{
+				NodeSelectorTerms: []apiv1.NodeSelectorTerm{{
+					MatchExpressions: []apiv1.NodeSelectorRequirement{
+						{Key: "tsuru.io/disk", Operator: apiv1.NodeSelectorOpIn, Values: []string{"ssd"}},
+						{Key: "tsuru.io/zone", Operator: apiv1.NodeSelectorOpNotIn, Values: []string{"a", "b"}},
+						{Key: "tsuru.io/gpu", Operator: apiv1.NodeSelectorOpDoesNotExist},
+					},
+				}},
+			},
+		},
+	})
+}
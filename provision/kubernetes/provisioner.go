@@ -85,25 +85,30 @@ type kubernetesProvisioner struct {
 }
 
 var (
-	_ provision.Provisioner              = &kubernetesProvisioner{}
-	_ provision.NodeProvisioner          = &kubernetesProvisioner{}
-	_ provision.NodeContainerProvisioner = &kubernetesProvisioner{}
-	_ provision.MessageProvisioner       = &kubernetesProvisioner{}
-	_ provision.SleepableProvisioner     = &kubernetesProvisioner{}
-	_ provision.VolumeProvisioner        = &kubernetesProvisioner{}
-	_ provision.BuilderDeploy            = &kubernetesProvisioner{}
-	_ provision.BuilderDeployKubeClient  = &kubernetesProvisioner{}
-	_ provision.InitializableProvisioner = &kubernetesProvisioner{}
-	_ provision.InterAppProvisioner      = &kubernetesProvisioner{}
-	_ provision.HCProvisioner            = &kubernetesProvisioner{}
-	_ provision.VersionsProvisioner      = &kubernetesProvisioner{}
-	_ provision.LogsProvisioner          = &kubernetesProvisioner{}
-	_ provision.MetricsProvisioner       = &kubernetesProvisioner{}
-	_ provision.AutoScaleProvisioner     = &kubernetesProvisioner{}
-	_ cluster.ClusteredProvisioner       = &kubernetesProvisioner{}
-	_ provision.UpdatableProvisioner     = &kubernetesProvisioner{}
-	_ provision.MultiRegistryProvisioner = &kubernetesProvisioner{}
-	_ provision.KillUnitProvisioner      = &kubernetesProvisioner{}
+	_ provision.Provisioner                = &kubernetesProvisioner{}
+	_ provision.NodeProvisioner            = &kubernetesProvisioner{}
+	_ provision.NodeContainerProvisioner   = &kubernetesProvisioner{}
+	_ provision.MessageProvisioner         = &kubernetesProvisioner{}
+	_ provision.SleepableProvisioner       = &kubernetesProvisioner{}
+	_ provision.VolumeProvisioner          = &kubernetesProvisioner{}
+	_ provision.BuilderDeploy              = &kubernetesProvisioner{}
+	_ provision.BuilderDeployKubeClient    = &kubernetesProvisioner{}
+	_ provision.InitializableProvisioner   = &kubernetesProvisioner{}
+	_ provision.InterAppProvisioner        = &kubernetesProvisioner{}
+	_ provision.HCProvisioner              = &kubernetesProvisioner{}
+	_ provision.VersionsProvisioner        = &kubernetesProvisioner{}
+	_ provision.LogsProvisioner            = &kubernetesProvisioner{}
+	_ provision.MetricsProvisioner         = &kubernetesProvisioner{}
+	_ provision.UsageProvisioner           = &kubernetesProvisioner{}
+	_ provision.AutoScaleProvisioner       = &kubernetesProvisioner{}
+	_ cluster.ClusteredProvisioner         = &kubernetesProvisioner{}
+	_ provision.UpdatableProvisioner       = &kubernetesProvisioner{}
+	_ provision.MultiRegistryProvisioner   = &kubernetesProvisioner{}
+	_ provision.KillUnitProvisioner        = &kubernetesProvisioner{}
+	_ provision.RestartUnitProvisioner     = &kubernetesProvisioner{}
+	_ provision.RemoveUnitsByIDProvisioner = &kubernetesProvisioner{}
+	_ provision.DisruptionProvisioner      = &kubernetesProvisioner{}
+	_ provision.AutoScaleStatusProvisioner = &kubernetesProvisioner{}
 
 	mainKubernetesProvisioner *kubernetesProvisioner
 )
@@ -782,15 +787,7 @@ func (p *kubernetesProvisioner) podsToUnitsMultiple(ctx context.Context, client
 			}
 		}
 
-		var status provision.Status
-		var reason string
-		if pod.Status.Phase == apiv1.PodRunning {
-			status, reason = extractStatusFromContainerStatuses(pod.Status.ContainerStatuses)
-		} else {
-			status = stateMap[pod.Status.Phase]
-			reason = pod.Status.Reason
-		}
-
+		status, reason := podStatus(&pod)
 		createdAt := pod.CreationTimestamp.Time.In(time.UTC)
 		units = append(units, provision.Unit{
 			ID:           pod.Name,
@@ -813,6 +810,13 @@ func (p *kubernetesProvisioner) podsToUnitsMultiple(ctx context.Context, client
 	return units, nil
 }
 
+func podStatus(pod *apiv1.Pod) (provision.Status, string) {
+	if pod.Status.Phase == apiv1.PodRunning {
+		return extractStatusFromContainerStatuses(pod.Status.ContainerStatuses)
+	}
+	return stateMap[pod.Status.Phase], pod.Status.Reason
+}
+
 func containersRestarts(containersStatus []apiv1.ContainerStatus) *int32 {
 	restarts := int32(0)
 	for _, containerStatus := range containersStatus {
@@ -963,6 +967,7 @@ func (p *kubernetesProvisioner) RoutableAddresses(ctx context.Context, a provisi
 	webProcessName := provision.MainAppProcess(processSet.ToList())
 
 	var allAddrs []appTypes.RoutableAddresses
+	yamlByVersion := map[int]*provTypes.TsuruYamlData{}
 	for _, svc := range svcs {
 		ls := labelOnlySetFromMeta(&svc.ObjectMeta)
 
@@ -997,12 +1002,52 @@ func (p *kubernetesProvisioner) RoutableAddresses(ctx context.Context, a provisi
 		if err != nil {
 			return nil, err
 		}
+		if processName != webProcessName {
+			rAddr.Hostnames, err = routableProcessHostnames(ctx, a, version, processName, yamlByVersion)
+			if err != nil {
+				return nil, err
+			}
+		}
 		allAddrs = append(allAddrs, rAddr)
 
 	}
 	return allAddrs, nil
 }
 
+// routableProcessHostnames returns the hostnames set in tsuru.yaml for the
+// process, when marked as routable. Services without a version label belong
+// to the latest version.
+func routableProcessHostnames(ctx context.Context, a provision.App, version int, process string, cache map[int]*provTypes.TsuruYamlData) ([]string, error) {
+	yamlData, ok := cache[version]
+	if !ok {
+		var appVersion appTypes.AppVersion
+		var err error
+		if version == 0 {
+			appVersion, err = servicemanager.AppVersion.LatestSuccessfulVersion(ctx, a)
+		} else {
+			appVersion, err = servicemanager.AppVersion.VersionByImageOrVersion(ctx, a, strconv.Itoa(version))
+		}
+		if err != nil && err != appTypes.ErrNoVersionsAvailable && !appTypes.IsInvalidVersionError(err) {
+			return nil, err
+		}
+		if appVersion != nil {
+			data, err := appVersion.TsuruYamlData()
+			if err != nil {
+				return nil, err
+			}
+			yamlData = &data
+		}
+		cache[version] = yamlData
+	}
+	if yamlData == nil {
+		return nil, nil
+	}
+	if rp := yamlData.RoutableProcess(process); rp != nil {
+		return rp.Hostnames, nil
+	}
+	return nil, nil
+}
+
 func (p *kubernetesProvisioner) routableAddrForProcess(ctx context.Context, client *ClusterClient, a provision.App, processName, prefix string, version int, svc apiv1.Service) (appTypes.RoutableAddresses, error) {
 	routableAddrs := appTypes.RoutableAddresses{
 		Prefix: prefix,
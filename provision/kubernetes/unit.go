@@ -6,14 +6,27 @@ package kubernetes
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"strconv"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/servicemanager"
 	policyV1Beta1 "k8s.io/api/policy/v1beta1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+// RestartUnit evicts the pod of the unit, letting its deployment create a
+// replacement, honoring the disruption budget of the app.
+func (p *kubernetesProvisioner) RestartUnit(ctx context.Context, app provision.App, unitName string, w io.Writer) error {
+	return p.KillUnit(ctx, app, unitName, false)
+}
+
 func (p *kubernetesProvisioner) KillUnit(ctx context.Context, app provision.App, unitName string, force bool) error {
 	clusterClient, err := clusterForPool(ctx, app.GetPool())
 	if err != nil {
@@ -54,3 +67,69 @@ func (p *kubernetesProvisioner) KillUnit(ctx context.Context, app provision.App,
 	}
 	return nil
 }
+
+// podDeletionCostAnnotation ranks the pods of a replica set being scaled
+// down, pods with the lowest cost are removed first.
+const podDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
+
+// RemoveUnitsByID scales down the deployments running the units, marking
+// their pods with the lowest deletion cost so they are the ones removed.
+func (p *kubernetesProvisioner) RemoveUnitsByID(ctx context.Context, app provision.App, unitNames []string, w io.Writer) error {
+	clusterClient, err := clusterForPool(ctx, app.GetPool())
+	if err != nil {
+		return err
+	}
+	ns, err := clusterClient.AppNamespace(ctx, app)
+	if err != nil {
+		return err
+	}
+	type processVersion struct {
+		process string
+		version int
+	}
+	var toScale []processVersion
+	counts := map[processVersion]int{}
+	for _, unitName := range unitNames {
+		pod, err := clusterClient.CoreV1().Pods(ns).Get(ctx, unitName, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			return &provision.UnitNotFoundError{ID: unitName}
+		}
+		if err != nil {
+			return errors.Wrap(err, "Unable to find pod")
+		}
+		labelSet := labelSetFromMeta(&pod.ObjectMeta)
+		if labelSet.AppName() != app.GetName() {
+			return &provision.UnitNotFoundError{ID: unitName}
+		}
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]string{
+					podDeletionCostAnnotation: strconv.Itoa(math.MinInt32),
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = clusterClient.CoreV1().Pods(ns).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return errors.Wrap(err, "Unable to update pod deletion cost")
+		}
+		key := processVersion{process: labelSet.AppProcess(), version: labelSet.AppVersion()}
+		if counts[key] == 0 {
+			toScale = append(toScale, key)
+		}
+		counts[key]++
+	}
+	for _, key := range toScale {
+		version, err := servicemanager.AppVersion.VersionByImageOrVersion(ctx, app, strconv.Itoa(key.version))
+		if err != nil {
+			return err
+		}
+		err = changeUnits(ctx, app, -counts[key], key.process, version, w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
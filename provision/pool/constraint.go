@@ -15,11 +15,12 @@ import (
 	"github.com/globalsign/mgo/bson"
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
 )
 
 var (
 	ErrInvalidConstraintType = errors.Errorf("invalid constraint type. Valid types are: %s", validConstraintTypes)
-	validConstraintTypes     = []poolConstraintType{ConstraintTypeTeam, ConstraintTypeService, ConstraintTypeRouter, ConstraintTypePlan, ConstraintTypeVolumePlan}
+	validConstraintTypes     = []poolConstraintType{ConstraintTypeTeam, ConstraintTypeService, ConstraintTypeRouter, ConstraintTypePlan, ConstraintTypeVolumePlan, ConstraintTypeExpression}
 )
 
 type poolConstraintType string
@@ -30,6 +31,7 @@ const (
 	ConstraintTypeService    = poolConstraintType("service")
 	ConstraintTypePlan       = poolConstraintType("plan")
 	ConstraintTypeVolumePlan = poolConstraintType("volume-plan")
+	ConstraintTypeExpression = poolConstraintType("expression")
 )
 
 type regexpCache struct {
@@ -120,6 +122,10 @@ func SetPoolConstraint(c *PoolConstraint) error {
 	if !isValid {
 		return ErrInvalidConstraintType
 	}
+	err = validateConstraintValues(c)
+	if err != nil {
+		return err
+	}
 	if len(c.Values) == 0 || (len(c.Values) == 1 && c.Values[0] == "") {
 		errRem := conn.PoolsConstraints().Remove(bson.M{"poolexpr": c.PoolExpr, "field": c.Field})
 		if errRem != mgo.ErrNotFound {
@@ -136,6 +142,10 @@ func AppendPoolConstraint(c *PoolConstraint) error {
 	if !isValid {
 		return ErrInvalidConstraintType
 	}
+	err := validateConstraintValues(c)
+	if err != nil {
+		return err
+	}
 	return appendPoolConstraint(c.PoolExpr, c.Field, c.Values...)
 }
 
@@ -148,6 +158,25 @@ func validateConstraintType(c poolConstraintType) bool {
 	return false
 }
 
+func validateConstraintValues(c *PoolConstraint) error {
+	if c.Field != ConstraintTypeExpression {
+		return nil
+	}
+	if c.Blacklist {
+		return &tsuruErrors.ValidationError{Message: "expression constraints can't be blacklists, negate the expression instead"}
+	}
+	for _, v := range c.Values {
+		if v == "" {
+			continue
+		}
+		_, err := ParseConstraintExpression(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func appendPoolConstraint(poolExpr string, field poolConstraintType, values ...string) error {
 	conn, err := db.Conn()
 	if err != nil {
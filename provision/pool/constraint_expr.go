@@ -0,0 +1,478 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+// Fields that can be referenced in constraint expressions.
+const (
+	ExprFieldApp      = "app"
+	ExprFieldTeam     = "team"
+	ExprFieldPlan     = "plan"
+	ExprFieldPlatform = "platform"
+	ExprFieldTag      = "tag"
+	ExprFieldRouter   = "router"
+	ExprFieldService  = "service"
+	ExprFieldPool     = "pool"
+)
+
+var exprFields = []string{ExprFieldApp, ExprFieldTeam, ExprFieldPlan, ExprFieldPlatform, ExprFieldTag, ExprFieldRouter, ExprFieldService, ExprFieldPool}
+
+// ConstraintSpec is the description of an app evaluated by the expression
+// constraints of a pool.
+type ConstraintSpec struct {
+	Name     string   `json:"name,omitempty"`
+	Team     string   `json:"team,omitempty"`
+	Plan     string   `json:"plan,omitempty"`
+	Platform string   `json:"platform,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Routers  []string `json:"routers,omitempty"`
+	Services []string `json:"services,omitempty"`
+}
+
+func (s ConstraintSpec) fieldValues(pool string) map[string][]string {
+	values := map[string][]string{
+		ExprFieldTag:     s.Tags,
+		ExprFieldRouter:  s.Routers,
+		ExprFieldService: s.Services,
+	}
+	single := map[string]string{
+		ExprFieldApp:      s.Name,
+		ExprFieldTeam:     s.Team,
+		ExprFieldPlan:     s.Plan,
+		ExprFieldPlatform: s.Platform,
+		ExprFieldPool:     pool,
+	}
+	for k, v := range single {
+		if v != "" {
+			values[k] = []string{v}
+		}
+	}
+	return values
+}
+
+// ConstraintExpression is a boolean expression over the fields of an app,
+// e.g. "team in (payments, checkout) and plan != huge". Values may use "*"
+// as a wildcard. Comparisons on fields holding several values, like router
+// and service, must hold for every value, so they're satisfied by apps
+// without any value for the field.
+type ConstraintExpression struct {
+	raw  string
+	root exprNode
+}
+
+// ParseConstraintExpression parses the expression, returning a validation
+// error if it's malformed.
+func ParseConstraintExpression(expr string) (*ConstraintExpression, error) {
+	tokens, err := tokenizeExpr(expr)
+	if err != nil {
+		return nil, invalidExprError(expr, err)
+	}
+	p := exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].value)
+	}
+	if err != nil {
+		return nil, invalidExprError(expr, err)
+	}
+	return &ConstraintExpression{raw: strings.TrimSpace(expr), root: root}, nil
+}
+
+func invalidExprError(expr string, err error) error {
+	return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid constraint expression %q: %s", expr, err)}
+}
+
+func (e *ConstraintExpression) String() string {
+	return e.raw
+}
+
+// Eval returns whether the app described by spec satisfies the expression
+// in the given pool.
+func (e *ConstraintExpression) Eval(spec ConstraintSpec, pool string) bool {
+	return e.root.eval(spec.fieldValues(pool))
+}
+
+type exprNode interface {
+	eval(values map[string][]string) bool
+}
+
+type andNode struct{ left, right exprNode }
+
+func (n andNode) eval(values map[string][]string) bool {
+	return n.left.eval(values) && n.right.eval(values)
+}
+
+type orNode struct{ left, right exprNode }
+
+func (n orNode) eval(values map[string][]string) bool {
+	return n.left.eval(values) || n.right.eval(values)
+}
+
+type notNode struct{ expr exprNode }
+
+func (n notNode) eval(values map[string][]string) bool {
+	return !n.expr.eval(values)
+}
+
+type compareNode struct {
+	field  string
+	values []string
+	negate bool
+}
+
+func (n compareNode) eval(values map[string][]string) bool {
+	for _, v := range values[n.field] {
+		if n.matches(v) == n.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (n compareNode) matches(v string) bool {
+	for _, expected := range n.values {
+		if match, _ := rCache.MatchString(exprAsGlobPattern(expected), v); match {
+			return true
+		}
+	}
+	return false
+}
+
+type exprTokenKind int
+
+const (
+	tokenWord exprTokenKind = iota
+	tokenString
+	tokenSymbol
+)
+
+type exprToken struct {
+	kind  exprTokenKind
+	value string
+}
+
+func (t exprToken) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.value, keyword)
+}
+
+func (t exprToken) isSymbol(symbol string) bool {
+	return t.kind == tokenSymbol && t.value == symbol
+}
+
+func tokenizeExpr(expr string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, exprToken{kind: tokenSymbol, value: string(r)})
+			i++
+		case r == '=' || r == '!':
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, exprToken{kind: tokenSymbol, value: string(r) + "="})
+				i += 2
+			} else if r == '=' {
+				tokens = append(tokens, exprToken{kind: tokenSymbol, value: "=="})
+				i++
+			} else {
+				return nil, fmt.Errorf("unexpected %q", r)
+			}
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, exprToken{kind: tokenString, value: string(runes[i+1 : end])})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`(),=!"'`, runes[end]) {
+				end++
+			}
+			tokens = append(tokens, exprToken{kind: tokenWord, value: string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek() *exprToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *exprParser) next() (exprToken, error) {
+	t := p.peek()
+	if t == nil {
+		return exprToken{}, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	return *t, nil
+}
+
+func (p *exprParser) expectSymbol(symbol string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if !t.isSymbol(symbol) {
+		return fmt.Errorf("expected %q, got %q", symbol, t.value)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.isKeyword("or"); t = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t != nil && t.isKeyword("and"); t = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	t := p.peek()
+	if t != nil && t.isKeyword("not") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{expr: expr}, nil
+	}
+	if t != nil && t.isSymbol("(") {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expectSymbol(")")
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	field := strings.ToLower(t.value)
+	if t.kind != tokenWord || !isExprField(field) {
+		return nil, fmt.Errorf("unknown field %q, valid fields are: %s", t.value, strings.Join(exprFields, ", "))
+	}
+	node := compareNode{field: field}
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case op.isSymbol("==") || op.isSymbol("!="):
+		node.negate = op.value == "!="
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.values = []string{value}
+		return node, nil
+	case op.isKeyword("not"):
+		node.negate = true
+		op, err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !op.isKeyword("in") {
+			return nil, fmt.Errorf("expected \"in\", got %q", op.value)
+		}
+	case !op.isKeyword("in"):
+		return nil, fmt.Errorf("expected an operator after %q, got %q", t.value, op.value)
+	}
+	node.values, err = p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (p *exprParser) parseList() ([]string, error) {
+	err := p.expectSymbol("(")
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.isSymbol(")") {
+			return values, nil
+		}
+		if !t.isSymbol(",") {
+			return nil, fmt.Errorf("expected \",\" or \")\", got %q", t.value)
+		}
+	}
+}
+
+func (p *exprParser) parseValue() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if t.kind == tokenSymbol {
+		return "", fmt.Errorf("expected a value, got %q", t.value)
+	}
+	if t.value == "" {
+		return "", fmt.Errorf("values can't be empty")
+	}
+	return t.value, nil
+}
+
+func isExprField(field string) bool {
+	for _, f := range exprFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ConstraintResult is the outcome of checking a value or an expression of
+// an app spec against the constraints of a pool.
+type ConstraintResult struct {
+	Field      poolConstraintType `json:"field"`
+	Value      string             `json:"value,omitempty"`
+	Expression string             `json:"expression,omitempty"`
+	Satisfied  bool               `json:"satisfied"`
+}
+
+// CheckConstraintExpressions returns a validation error if the app described
+// by spec doesn't satisfy every expression constraint of the pool.
+func (p *Pool) CheckConstraintExpressions(spec ConstraintSpec) error {
+	exprs, err := p.constraintExpressions()
+	if err != nil {
+		return err
+	}
+	for _, e := range exprs {
+		if !e.Eval(spec, p.Name) {
+			return &tsuruErrors.ValidationError{
+				Message: fmt.Sprintf("app doesn't satisfy the constraint %q of pool %q", e, p.Name),
+			}
+		}
+	}
+	return nil
+}
+
+// TestConstraints checks the app described by spec against both the allowed
+// values and the expression constraints of the pool, without changing
+// anything.
+func (p *Pool) TestConstraints(spec ConstraintSpec) ([]ConstraintResult, error) {
+	allowed, err := p.allowedValues()
+	if err != nil {
+		return nil, err
+	}
+	results := []ConstraintResult{}
+	checkValues := func(field poolConstraintType, values ...string) {
+		for _, v := range values {
+			if v == "" {
+				continue
+			}
+			result := ConstraintResult{Field: field, Value: v}
+			for _, a := range allowed[field] {
+				if a == v {
+					result.Satisfied = true
+					break
+				}
+			}
+			results = append(results, result)
+		}
+	}
+	checkValues(ConstraintTypeTeam, spec.Team)
+	checkValues(ConstraintTypePlan, spec.Plan)
+	checkValues(ConstraintTypeRouter, spec.Routers...)
+	checkValues(ConstraintTypeService, spec.Services...)
+	exprs, err := p.constraintExpressions()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range exprs {
+		results = append(results, ConstraintResult{
+			Field:      ConstraintTypeExpression,
+			Expression: e.String(),
+			Satisfied:  e.Eval(spec, p.Name),
+		})
+	}
+	return results, nil
+}
+
+func (p *Pool) constraintExpressions() ([]*ConstraintExpression, error) {
+	constraints, err := getConstraintsForPool(p.Name, ConstraintTypeExpression)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := constraints[ConstraintTypeExpression]
+	if !ok {
+		return nil, nil
+	}
+	var exprs []*ConstraintExpression
+	for _, v := range c.Values {
+		if v == "" {
+			continue
+		}
+		e, err := ParseConstraintExpression(v)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+	return exprs, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"github.com/globalsign/mgo/bson"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestParseConstraintExpression(c *check.C) {
+	spec := ConstraintSpec{Name: "myapp", Team: "payments", Plan: "small", Routers: []string{"router1", "router2"}}
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{"team in (payments, checkout) and plan != huge", true},
+		{"team in (checkout)", false},
+		{"TEAM NOT IN (checkout)", true},
+		{"not team == payments or pool == prod", true},
+		{"(team == checkout or team == payments) and not plan in (huge, 'x large')", true},
+		{"plan = small", true},
+		{"router in (router*)", true},
+		{"router != router2", false},
+		{"service == mysql", true},
+		{"pool == dev", false},
+	}
+	for _, tt := range tests {
+		e, err := ParseConstraintExpression(tt.expr)
+		c.Assert(err, check.IsNil, check.Commentf("expr: %q", tt.expr))
+		c.Check(e.Eval(spec, "prod"), check.Equals, tt.expected, check.Commentf("expr: %q", tt.expr))
+	}
+}
+
+func (s *S) TestParseConstraintExpressionInvalid(c *check.C) {
+	tests := []struct {
+		expr string
+		msg  string
+	}{
+		{"", `invalid constraint expression "": unexpected end of expression`},
+		{"team ==", `invalid constraint expression "team ==": unexpected end of expression`},
+		{"owner == me", `invalid constraint expression "owner == me": unknown field "owner", valid fields are: app, team, plan, platform, tag, router, service, pool`},
+		{"team in ()", `invalid constraint expression "team in \(\)": expected a value, got "\)"`},
+		{"team == a)", `invalid constraint expression "team == a\)": unexpected "\)"`},
+		{"team == 'a", `invalid constraint expression "team == 'a": unterminated string`},
+	}
+	for _, tt := range tests {
+		_, err := ParseConstraintExpression(tt.expr)
+		c.Check(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+		c.Check(err, check.ErrorMatches, tt.msg, check.Commentf("expr: %q", tt.expr))
+	}
+}
+
+func (s *S) TestSetPoolConstraintInvalidExpression(c *check.C) {
+	err := SetPoolConstraint(&PoolConstraint{PoolExpr: "*", Field: ConstraintTypeExpression, Values: []string{"team =="}})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	err = AppendPoolConstraint(&PoolConstraint{PoolExpr: "*", Field: ConstraintTypeExpression, Values: []string{"team == a"}, Blacklist: true})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	n, err := s.storage.PoolsConstraints().Find(bson.M{"field": ConstraintTypeExpression}).Count()
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, 0)
+}
+
+func (s *S) TestCheckConstraintExpressions(c *check.C) {
+	err := SetPoolConstraint(&PoolConstraint{PoolExpr: "prod*", Field: ConstraintTypeExpression, Values: []string{
+		"team in (payments, checkout)",
+		"plan != huge",
+	}})
+	c.Assert(err, check.IsNil)
+	pool := Pool{Name: "prod1"}
+	err = pool.CheckConstraintExpressions(ConstraintSpec{Team: "payments", Plan: "small"})
+	c.Assert(err, check.IsNil)
+	err = pool.CheckConstraintExpressions(ConstraintSpec{Team: "payments", Plan: "huge"})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `app doesn't satisfy the constraint "plan != huge" of pool "prod1"`)
+	otherPool := Pool{Name: "dev"}
+	err = otherPool.CheckConstraintExpressions(ConstraintSpec{Team: "other", Plan: "huge"})
+	c.Assert(err, check.IsNil)
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/db"
+	dbStorage "github.com/tsuru/tsuru/db/storage"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+)
+
+const minutesPerWeek = 7 * 24 * 60
+
+var ErrPoolFreezeNotFound = errors.New("pool has no freeze windows")
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// FreezeWindow is a weekly recurring period, like "Fri 18:00-Mon 06:00",
+// during which deploys, restarts and unit removals are rejected in the pool.
+type FreezeWindow string
+
+// PoolFreeze holds the freeze windows of a pool. Windows are evaluated in the
+// configured timezone, UTC by default.
+type PoolFreeze struct {
+	Pool     string         `json:"pool" bson:"_id"`
+	Windows  []FreezeWindow `json:"windows"`
+	Timezone string         `json:"timezone,omitempty"`
+	Reason   string         `json:"reason,omitempty"`
+}
+
+// FreezeStatus describes whether a pool is frozen at a given time.
+type FreezeStatus struct {
+	Pool   string     `json:"pool"`
+	Frozen bool       `json:"frozen"`
+	Window string     `json:"window,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// ErrPoolFrozen is returned when an operation is attempted during a freeze
+// window of the pool.
+type ErrPoolFrozen struct {
+	FreezeStatus
+}
+
+func (e ErrPoolFrozen) Error() string {
+	msg := fmt.Sprintf("pool %q is frozen until %s", e.Pool, e.Until.Format(time.RFC3339))
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+func poolFreezesCollection(conn *db.Storage) *dbStorage.Collection {
+	return conn.Collection("pool_freezes")
+}
+
+func parseWeekTime(value string) (int, error) {
+	parts := strings.Fields(value)
+	if len(parts) != 2 {
+		return 0, errors.Errorf("invalid time %q, expected a weekday and a time, like Fri 18:00", value)
+	}
+	weekday, ok := weekdays[strings.ToLower(parts[0])]
+	if !ok {
+		return 0, errors.Errorf("invalid weekday %q", parts[0])
+	}
+	clock, err := time.Parse("15:04", parts[1])
+	if err != nil {
+		return 0, errors.Errorf("invalid time %q, expected HH:MM", parts[1])
+	}
+	return int(weekday)*24*60 + clock.Hour()*60 + clock.Minute(), nil
+}
+
+func (w FreezeWindow) bounds() (int, int, error) {
+	parts := strings.Split(string(w), "-")
+	if len(parts) != 2 {
+		return 0, 0, errors.Errorf("invalid freeze window %q, expected a start and an end, like Fri 18:00-Mon 06:00", w)
+	}
+	start, err := parseWeekTime(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseWeekTime(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, errors.Errorf("invalid freeze window %q, start and end must differ", w)
+	}
+	return start, end, nil
+}
+
+// remaining returns how many minutes are left in the window at the given
+// minute of the week, or zero if the window is not active.
+func (w FreezeWindow) remaining(now int) int {
+	start, end, err := w.bounds()
+	if err != nil {
+		return 0
+	}
+	if start < end && (now < start || now >= end) {
+		return 0
+	}
+	if start > end && now < start && now >= end {
+		return 0
+	}
+	return (end - now + minutesPerWeek) % minutesPerWeek
+}
+
+func (f *PoolFreeze) location() (*time.Location, error) {
+	if f.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(f.Timezone)
+}
+
+func (f *PoolFreeze) validate() error {
+	if len(f.Windows) == 0 {
+		return &tsuruErrors.ValidationError{Message: "at least one freeze window is required"}
+	}
+	for _, w := range f.Windows {
+		if _, _, err := w.bounds(); err != nil {
+			return &tsuruErrors.ValidationError{Message: err.Error()}
+		}
+	}
+	if _, err := f.location(); err != nil {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid timezone %q", f.Timezone)}
+	}
+	return nil
+}
+
+// Status returns whether the pool is frozen at the given time and, if so,
+// when the freeze ends. Adjacent or overlapping windows are merged, up to a
+// week ahead.
+func (f *PoolFreeze) Status(now time.Time) FreezeStatus {
+	status := FreezeStatus{Pool: f.Pool}
+	loc, err := f.location()
+	if err != nil {
+		return status
+	}
+	now = now.In(loc).Truncate(time.Minute)
+	until := now
+	limit := now.Add(7 * 24 * time.Hour)
+	for changed := true; changed && until.Before(limit); {
+		changed = false
+		minute := int(until.Weekday())*24*60 + until.Hour()*60 + until.Minute()
+		for _, w := range f.Windows {
+			if left := w.remaining(minute); left > 0 {
+				until = until.Add(time.Duration(left) * time.Minute)
+				if !status.Frozen {
+					status.Window = string(w)
+				}
+				status.Frozen, changed = true, true
+				break
+			}
+		}
+	}
+	if status.Frozen {
+		status.Until = &until
+		status.Reason = f.Reason
+	}
+	return status
+}
+
+// SetPoolFreeze replaces the freeze windows of the pool.
+func SetPoolFreeze(freeze PoolFreeze) error {
+	if err := freeze.validate(); err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = poolFreezesCollection(conn).UpsertId(freeze.Pool, freeze)
+	return err
+}
+
+// GetPoolFreeze returns the freeze windows of the pool.
+func GetPoolFreeze(poolName string) (*PoolFreeze, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var freeze PoolFreeze
+	err = poolFreezesCollection(conn).FindId(poolName).One(&freeze)
+	if err == mgo.ErrNotFound {
+		return nil, ErrPoolFreezeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &freeze, nil
+}
+
+// RemovePoolFreeze removes all freeze windows of the pool.
+func RemovePoolFreeze(poolName string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = poolFreezesCollection(conn).RemoveId(poolName)
+	if err == mgo.ErrNotFound {
+		return ErrPoolFreezeNotFound
+	}
+	return err
+}
+
+// CheckFreeze returns ErrPoolFrozen if the pool is inside one of its freeze
+// windows at the given time.
+func CheckFreeze(poolName string, now time.Time) error {
+	freeze, err := GetPoolFreeze(poolName)
+	if err == ErrPoolFreezeNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	status := freeze.Status(now)
+	if status.Frozen {
+		return ErrPoolFrozen{FreezeStatus: status}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPoolFreezeStatus(c *check.C) {
+	freeze := PoolFreeze{Pool: "pool1", Windows: []FreezeWindow{"Fri 18:00-Mon 06:00"}, Reason: "weekend"}
+	friday := time.Date(2026, time.October, 16, 19, 30, 20, 0, time.UTC)
+	status := freeze.Status(friday)
+	c.Assert(status.Frozen, check.Equals, true)
+	c.Assert(status.Window, check.Equals, "Fri 18:00-Mon 06:00")
+	c.Assert(status.Reason, check.Equals, "weekend")
+	c.Assert(*status.Until, check.DeepEquals, time.Date(2026, time.October, 19, 6, 0, 0, 0, time.UTC))
+	sunday := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	c.Assert(freeze.Status(sunday).Frozen, check.Equals, true)
+	monday := time.Date(2026, time.October, 19, 6, 0, 0, 0, time.UTC)
+	c.Assert(freeze.Status(monday), check.DeepEquals, FreezeStatus{Pool: "pool1"})
+	wednesday := time.Date(2026, time.October, 14, 18, 0, 0, 0, time.UTC)
+	c.Assert(freeze.Status(wednesday).Frozen, check.Equals, false)
+}
+
+func (s *S) TestPoolFreezeStatusMergesWindows(c *check.C) {
+	freeze := PoolFreeze{Pool: "pool1", Windows: []FreezeWindow{"Mon 06:00-Mon 09:00", "Fri 18:00-Mon 06:00"}}
+	friday := time.Date(2026, time.October, 16, 19, 0, 0, 0, time.UTC)
+	status := freeze.Status(friday)
+	c.Assert(status.Frozen, check.Equals, true)
+	c.Assert(*status.Until, check.DeepEquals, time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC))
+}
+
+func (s *S) TestPoolFreezeStatusTimezone(c *check.C) {
+	freeze := PoolFreeze{Pool: "pool1", Windows: []FreezeWindow{"Fri 18:00-Mon 06:00"}, Timezone: "America/Sao_Paulo"}
+	c.Assert(freeze.Status(time.Date(2026, time.October, 16, 19, 0, 0, 0, time.UTC)).Frozen, check.Equals, false)
+	c.Assert(freeze.Status(time.Date(2026, time.October, 16, 22, 0, 0, 0, time.UTC)).Frozen, check.Equals, true)
+}
+
+func (s *S) TestSetPoolFreezeInvalid(c *check.C) {
+	err := SetPoolFreeze(PoolFreeze{Pool: "pool1"})
+	c.Assert(err, check.ErrorMatches, "at least one freeze window is required")
+	err = SetPoolFreeze(PoolFreeze{Pool: "pool1", Windows: []FreezeWindow{"Fri 18:00"}})
+	c.Assert(err, check.ErrorMatches, `invalid freeze window "Fri 18:00".*`)
+	err = SetPoolFreeze(PoolFreeze{Pool: "pool1", Windows: []FreezeWindow{"Fry 18:00-Mon 06:00"}})
+	c.Assert(err, check.ErrorMatches, `invalid weekday "Fry"`)
+	err = SetPoolFreeze(PoolFreeze{Pool: "pool1", Windows: []FreezeWindow{"Fri 18:00-Mon 06:00"}, Timezone: "Nowhere/City"})
+	c.Assert(err, check.ErrorMatches, `invalid timezone "Nowhere/City"`)
+}
+
+func (s *S) TestCheckFreeze(c *check.C) {
+	friday := time.Date(2026, time.October, 16, 19, 0, 0, 0, time.UTC)
+	err := CheckFreeze("pool1", friday)
+	c.Assert(err, check.IsNil)
+	err = SetPoolFreeze(PoolFreeze{Pool: "pool1", Windows: []FreezeWindow{"Fri 18:00-Mon 06:00"}, Reason: "weekend"})
+	c.Assert(err, check.IsNil)
+	err = CheckFreeze("pool1", friday)
+	c.Assert(err, check.ErrorMatches, `pool "pool1" is frozen until 2026-10-19T06:00:00Z: weekend`)
+	_, ok := err.(ErrPoolFrozen)
+	c.Assert(ok, check.Equals, true)
+	err = RemovePoolFreeze("pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(CheckFreeze("pool1", friday), check.IsNil)
+}
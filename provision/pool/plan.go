@@ -0,0 +1,125 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	"github.com/tsuru/tsuru/servicemanager"
+	appTypes "github.com/tsuru/tsuru/types/app"
+)
+
+// PoolPlans holds the plans apps in a pool may use and the plan assigned to
+// new apps which don't choose one. An empty list of plans allows every plan.
+type PoolPlans struct {
+	Pool    string   `json:"pool"`
+	Plans   []string `json:"plans"`
+	Default string   `json:"default"`
+}
+
+// GetPoolPlans returns the plans allowed in the pool and its default plan.
+func GetPoolPlans(ctx context.Context, name string) (*PoolPlans, error) {
+	p, err := GetPoolByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	result := PoolPlans{Pool: p.Name}
+	result.Plans, err = p.GetPlans()
+	if err != nil && err != ErrPoolHasNoPlan {
+		return nil, err
+	}
+	plan, err := p.GetDefaultPlan()
+	if err != nil && err != appTypes.ErrPlanDefaultNotFound {
+		return nil, err
+	}
+	if plan != nil {
+		result.Default = plan.Name
+	}
+	return &result, nil
+}
+
+// SetPoolPlans restricts the plans available in the pool, replacing the
+// plan constraint of the pool, and sets its default plan. The default plan
+// must be one of the allowed plans.
+func SetPoolPlans(ctx context.Context, name string, plans []string, defaultPlan string) error {
+	_, err := GetPoolByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, planName := range plans {
+		if err = checkPlanExists(ctx, planName); err != nil {
+			return err
+		}
+	}
+	if defaultPlan != "" {
+		if err = checkPlanExists(ctx, defaultPlan); err != nil {
+			return err
+		}
+		if len(plans) > 0 && !containsString(plans, defaultPlan) {
+			msg := fmt.Sprintf("default plan %q must be one of the allowed plans: %s", defaultPlan, strings.Join(plans, ", "))
+			return &tsuruErrors.ValidationError{Message: msg}
+		}
+	}
+	err = SetPoolConstraint(&PoolConstraint{PoolExpr: name, Field: ConstraintTypePlan, Values: plans})
+	if err != nil {
+		return err
+	}
+	return setPoolDefaultPlan(name, defaultPlan)
+}
+
+// RemovePoolPlans removes the plan restrictions and the default plan of the
+// pool, allowing every plan again.
+func RemovePoolPlans(ctx context.Context, name string) error {
+	_, err := GetPoolByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	err = SetPoolConstraint(&PoolConstraint{PoolExpr: name, Field: ConstraintTypePlan})
+	if err != nil {
+		return err
+	}
+	return setPoolDefaultPlan(name, "")
+}
+
+func setPoolDefaultPlan(name, plan string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	update := bson.M{"$set": bson.M{"defaultplan": plan}}
+	if plan == "" {
+		update = bson.M{"$unset": bson.M{"defaultplan": ""}}
+	}
+	err = conn.Pools().UpdateId(name, update)
+	if err == mgo.ErrNotFound {
+		return ErrPoolNotFound
+	}
+	invalidatePools(name)
+	return err
+}
+
+func checkPlanExists(ctx context.Context, name string) error {
+	_, err := servicemanager.Plan.FindByName(ctx, name)
+	if err == appTypes.ErrPlanNotFound {
+		return &tsuruErrors.ValidationError{Message: fmt.Sprintf("plan %q not found", name)}
+	}
+	return err
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
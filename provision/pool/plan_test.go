@@ -0,0 +1,71 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pool
+
+import (
+	"context"
+
+	tsuruErrors "github.com/tsuru/tsuru/errors"
+	appTypes "github.com/tsuru/tsuru/types/app"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) setupPlanLookup() {
+	s.plans = append(s.plans, appTypes.Plan{Name: "plan3"}, appTypes.Plan{Name: "default", Default: true})
+	s.mockPlanService.OnFindByName = func(name string) (*appTypes.Plan, error) {
+		for _, p := range s.plans {
+			if p.Name == name {
+				return &p, nil
+			}
+		}
+		return nil, appTypes.ErrPlanNotFound
+	}
+	s.mockPlanService.OnDefaultPlan = func() (*appTypes.Plan, error) {
+		return &appTypes.Plan{Name: "default", Default: true}, nil
+	}
+}
+
+func (s *S) TestSetPoolPlans(c *check.C) {
+	s.setupPlanLookup()
+	err := AddPool(context.TODO(), AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = SetPoolPlans(context.TODO(), "pool1", []string{"plan1", "plan3"}, "plan3")
+	c.Assert(err, check.IsNil)
+	plans, err := GetPoolPlans(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(*plans, check.DeepEquals, PoolPlans{Pool: "pool1", Plans: []string{"plan1", "plan3"}, Default: "plan3"})
+	p, err := GetPoolByName(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	defaultPlan, err := p.GetDefaultPlan()
+	c.Assert(err, check.IsNil)
+	c.Assert(defaultPlan.Name, check.Equals, "plan3")
+}
+
+func (s *S) TestSetPoolPlansInvalid(c *check.C) {
+	s.setupPlanLookup()
+	err := AddPool(context.TODO(), AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = SetPoolPlans(context.TODO(), "pool1", []string{"plan1", "unknown"}, "")
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+	c.Assert(err, check.ErrorMatches, `plan "unknown" not found`)
+	err = SetPoolPlans(context.TODO(), "pool1", []string{"plan1"}, "plan2")
+	c.Assert(err, check.ErrorMatches, `default plan "plan2" must be one of the allowed plans: plan1`)
+	err = SetPoolPlans(context.TODO(), "unknown", nil, "")
+	c.Assert(err, check.Equals, ErrPoolNotFound)
+}
+
+func (s *S) TestRemovePoolPlans(c *check.C) {
+	s.setupPlanLookup()
+	err := AddPool(context.TODO(), AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = SetPoolPlans(context.TODO(), "pool1", []string{"plan1"}, "plan1")
+	c.Assert(err, check.IsNil)
+	err = RemovePoolPlans(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	plans, err := GetPoolPlans(context.TODO(), "pool1")
+	c.Assert(err, check.IsNil)
+	c.Assert(plans.Plans, check.HasLen, len(s.plans))
+	c.Assert(plans.Default, check.Equals, "default")
+}
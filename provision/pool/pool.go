@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/ghodss/yaml"
@@ -18,6 +19,7 @@ import (
 	"github.com/tsuru/tsuru/db"
 	tsuruErrors "github.com/tsuru/tsuru/errors"
 	"github.com/tsuru/tsuru/provision"
+	"github.com/tsuru/tsuru/readcache"
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/service"
 	"github.com/tsuru/tsuru/servicemanager"
@@ -45,12 +47,23 @@ const (
 	affinityKey         = "affinity"
 	buildPlanKey        = "build-plan"
 	buildPlanSideCarKey = "build-plan-sidecar"
+	spreadKeyKey        = "spread-key"
+	deployApprovalKey   = "deploy-approval"
+
+	spotNodeMetadataKey     = "spot-node-metadata"
+	spotMinOnDemandUnitsKey = "spot-min-on-demand-units"
+
+	resourceLabelsKey      = "resource-labels"
+	resourceAnnotationsKey = "resource-annotations"
 )
 
 type Pool struct {
 	Name        string `bson:"_id"`
 	Default     bool
 	Provisioner string
+	// DefaultPlan is the plan assigned to new apps in the pool which don't
+	// choose one, when empty the first allowed plan is used.
+	DefaultPlan string `bson:",omitempty"`
 
 	Labels map[string]string
 
@@ -100,6 +113,69 @@ func (p *Pool) GetBuildPlan() map[string]string {
 	return plans
 }
 
+// GetSpreadKey returns the node metadata key identifying the failure domain
+// of nodes in the pool, units of the same app and process are spread among
+// the domains.
+func (p *Pool) GetSpreadKey() string {
+	return p.Labels[spreadKeyKey]
+}
+
+// GetSpotNodeMetadata returns the node metadata key and value identifying
+// spot (or preemptible) nodes in the pool, declared as key=value in the
+// spot-node-metadata label. Empty values mean the pool has no spot nodes.
+func (p *Pool) GetSpotNodeMetadata() (string, string) {
+	key, value, _ := parseSpotNodeMetadata(p.Labels[spotNodeMetadataKey])
+	return key, value
+}
+
+// GetMinOnDemandUnits returns the minimum number of units of each app
+// process kept on non-spot nodes of the pool.
+func (p *Pool) GetMinOnDemandUnits() int {
+	min, _ := strconv.Atoi(p.Labels[spotMinOnDemandUnitsKey])
+	return min
+}
+
+func parseSpotNodeMetadata(value string) (string, string, bool) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// RequiresDeployApproval returns whether deploys to apps in the pool must be
+// approved by a second user before running.
+func (p *Pool) RequiresDeployApproval() bool {
+	required, _ := strconv.ParseBool(p.Labels[deployApprovalKey])
+	return required
+}
+
+// GetResourceMetadata returns the extra labels and annotations merged onto
+// the kubernetes resources of apps in the pool, declared as JSON objects in
+// the resource-labels and resource-annotations labels of the pool.
+func (p *Pool) GetResourceMetadata() (appTypes.Metadata, error) {
+	return resourceMetadata(p.Labels)
+}
+
+func resourceMetadata(labels map[string]string) (appTypes.Metadata, error) {
+	var resLabels, resAnnotations map[string]string
+	if raw, ok := labels[resourceLabelsKey]; ok {
+		if err := json.Unmarshal([]byte(raw), &resLabels); err != nil {
+			return appTypes.Metadata{}, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid %s: %v", resourceLabelsKey, err)}
+		}
+	}
+	if raw, ok := labels[resourceAnnotationsKey]; ok {
+		if err := json.Unmarshal([]byte(raw), &resAnnotations); err != nil {
+			return appTypes.Metadata{}, &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid %s: %v", resourceAnnotationsKey, err)}
+		}
+	}
+	metadata := appTypes.MetadataFromMaps(resLabels, resAnnotations)
+	if err := metadata.Validate(); err != nil {
+		return appTypes.Metadata{}, &tsuruErrors.ValidationError{Message: err.Error()}
+	}
+	return metadata, nil
+}
+
 func (p *Pool) GetProvisioner() (provision.Provisioner, error) {
 	if p.Provisioner != "" {
 		return provision.Get(p.Provisioner)
@@ -154,6 +230,17 @@ func (p *Pool) GetPlans() ([]string, error) {
 }
 
 func (p *Pool) GetDefaultPlan() (*appTypes.Plan, error) {
+	if p.DefaultPlan != "" {
+		plans, err := p.GetPlans()
+		if err != nil && err != ErrPoolHasNoPlan {
+			return nil, err
+		}
+		for _, name := range plans {
+			if name == p.DefaultPlan {
+				return servicemanager.Plan.FindByName(p.ctx, name)
+			}
+		}
+	}
 	constraints, err := getConstraintsForPool(p.Name, ConstraintTypePlan)
 	if err != nil {
 		return nil, err
@@ -365,6 +452,9 @@ func (p *Pool) MarshalJSON() ([]byte, error) {
 	result["public"] = teams.AllowsAll()
 	result["default"] = p.Default
 	result["provisioner"] = p.Provisioner
+	if p.DefaultPlan != "" {
+		result["defaultPlan"] = p.DefaultPlan
+	}
 	result["teams"] = resolvedConstraints[ConstraintTypeTeam]
 	result["allowed"] = resolvedConstraints
 	return json.Marshal(&result)
@@ -377,7 +467,19 @@ func validateLabels(labels map[string]string) error {
 			return err
 		}
 	}
-
+	if _, err := resourceMetadata(labels); err != nil {
+		return err
+	}
+	if spotMetadata, ok := labels[spotNodeMetadataKey]; ok {
+		if _, _, valid := parseSpotNodeMetadata(spotMetadata); !valid {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid %s %q, must be in the key=value format", spotNodeMetadataKey, spotMetadata)}
+		}
+	}
+	if minUnits, ok := labels[spotMinOnDemandUnitsKey]; ok {
+		if n, err := strconv.Atoi(minUnits); err != nil || n < 0 {
+			return &tsuruErrors.ValidationError{Message: fmt.Sprintf("invalid %s %q, must be a non negative number", spotMinOnDemandUnitsKey, minUnits)}
+		}
+	}
 	return nil
 }
 
@@ -421,6 +523,7 @@ func AddPool(ctx context.Context, opts AddPoolOptions) error {
 		}
 		return err
 	}
+	invalidatePools(opts.Name)
 	if opts.Public || opts.Default {
 		return SetPoolConstraint(&PoolConstraint{PoolExpr: opts.Name, Field: ConstraintTypeTeam, Values: []string{"*"}})
 	}
@@ -458,6 +561,7 @@ func changeDefaultPool(ctx context.Context, force bool) error {
 		if !force {
 			return ErrDefaultPoolAlreadyExists
 		}
+		defer invalidatePools(p[0].Name)
 		return conn.Pools().UpdateId(p[0].Name, bson.M{"$set": bson.M{"default": false}})
 	}
 	return nil
@@ -473,6 +577,7 @@ func RemovePool(poolName string) error {
 	if err == mgo.ErrNotFound {
 		return ErrPoolNotFound
 	}
+	invalidatePools(poolName)
 	return err
 }
 
@@ -537,7 +642,16 @@ func ListPools(ctx context.Context, names ...string) ([]Pool, error) {
 }
 
 func ListAllPools(ctx context.Context) ([]Pool, error) {
-	return listPools(ctx, nil)
+	var pools []Pool
+	err := readcache.Fetch(readcache.Pools, allPoolsKey, &pools, func() error {
+		var err error
+		pools, err = listPools(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pools, nil
 }
 
 func ListPublicPools(ctx context.Context) ([]Pool, error) {
@@ -588,13 +702,15 @@ func GetProvisionerForPool(ctx context.Context, name string) (provision.Provisio
 
 // GetPoolByName finds a pool by name
 func GetPoolByName(ctx context.Context, name string) (*Pool, error) {
-	conn, err := db.Conn()
-	if err != nil {
-		return nil, err
-	}
-	defer conn.Close()
 	var p Pool
-	err = conn.Pools().FindId(name).One(&p)
+	err := readcache.Fetch(readcache.Pools, name, &p, func() error {
+		conn, err := db.Conn()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return conn.Pools().FindId(name).One(&p)
+	})
 	if err != nil {
 		if err == mgo.ErrNotFound {
 			return nil, ErrPoolNotFound
@@ -670,9 +786,19 @@ func PoolUpdate(ctx context.Context, name string, opts UpdatePoolOptions) error
 	if err == mgo.ErrNotFound {
 		return ErrPoolNotFound
 	}
+	invalidatePools(name)
 	return err
 }
 
+// allPoolsKey is the read cache key of the list of all pools.
+const allPoolsKey = "*"
+
+// invalidatePools removes the pools from the read cache, along with the list
+// of all pools.
+func invalidatePools(names ...string) {
+	readcache.Invalidate(readcache.Pools, append(names, allPoolsKey)...)
+}
+
 func exprAsGlobPattern(expr string) string {
 	parts := strings.Split(expr, "*")
 	for i := range parts {
@@ -279,6 +279,22 @@ func (s *S) TestAddPoolValidateLabels(c *check.C) {
 			},
 			expectedErr: "invalid character 'i' looking for beginning of value",
 		},
+		{
+			testName: "spot node metadata without value",
+			opts: AddPoolOptions{
+				Name:   "pool2",
+				Labels: map[string]string{spotNodeMetadataKey: "lifecycle"},
+			},
+			expectedErr: `invalid spot-node-metadata "lifecycle", must be in the key=value format`,
+		},
+		{
+			testName: "negative spot min on demand units",
+			opts: AddPoolOptions{
+				Name:   "pool2",
+				Labels: map[string]string{spotMinOnDemandUnitsKey: "-1"},
+			},
+			expectedErr: `invalid spot-min-on-demand-units "-1", must be a non negative number`,
+		},
 	}
 
 	for _, t := range tt {
@@ -928,3 +944,67 @@ func (s *S) TestGetAffinity(c *check.C) {
 		t.assertion(t.testName, c, affinity, err)
 	}
 }
+
+func (s *S) TestGetSpreadKey(c *check.C) {
+	p := Pool{Name: "pool1"}
+	c.Assert(p.GetSpreadKey(), check.Equals, "")
+	p.Labels = map[string]string{"spread-key": "zone"}
+	c.Assert(p.GetSpreadKey(), check.Equals, "zone")
+}
+
+func (s *S) TestGetSpotNodeMetadata(c *check.C) {
+	p := Pool{Name: "pool1"}
+	key, value := p.GetSpotNodeMetadata()
+	c.Assert(key, check.Equals, "")
+	c.Assert(value, check.Equals, "")
+	c.Assert(p.GetMinOnDemandUnits(), check.Equals, 0)
+	p.Labels = map[string]string{"spot-node-metadata": "lifecycle=spot", "spot-min-on-demand-units": "2"}
+	key, value = p.GetSpotNodeMetadata()
+	c.Assert(key, check.Equals, "lifecycle")
+	c.Assert(value, check.Equals, "spot")
+	c.Assert(p.GetMinOnDemandUnits(), check.Equals, 2)
+}
+
+func (s *S) TestGetResourceMetadata(c *check.C) {
+	p := Pool{Name: "pool1"}
+	metadata, err := p.GetResourceMetadata()
+	c.Assert(err, check.IsNil)
+	c.Assert(metadata, check.DeepEquals, appTypes.Metadata{})
+	p.Labels = map[string]string{
+		"resource-labels":      `{"team": "payments", "cost-center": "platform"}`,
+		"resource-annotations": `{"sidecar.istio.io/inject": "true"}`,
+	}
+	metadata, err = p.GetResourceMetadata()
+	c.Assert(err, check.IsNil)
+	c.Assert(metadata, check.DeepEquals, appTypes.Metadata{
+		Labels: []appTypes.MetadataItem{
+			{Name: "cost-center", Value: "platform"},
+			{Name: "team", Value: "payments"},
+		},
+		Annotations: []appTypes.MetadataItem{
+			{Name: "sidecar.istio.io/inject", Value: "true"},
+		},
+	})
+	p.Labels = map[string]string{"resource-labels": `{"tsuru.io/pool": "other"}`}
+	_, err = p.GetResourceMetadata()
+	c.Assert(err, check.ErrorMatches, `(?s).*prefix tsuru.io/ is private.*`)
+	p.Labels = map[string]string{"resource-annotations": `invalid`}
+	_, err = p.GetResourceMetadata()
+	c.Assert(err, check.ErrorMatches, `invalid resource-annotations: .*`)
+}
+
+func (s *S) TestPoolUpdateInvalidResourceMetadata(c *check.C) {
+	err := AddPool(context.TODO(), AddPoolOptions{Name: "pool1"})
+	c.Assert(err, check.IsNil)
+	err = PoolUpdate(context.TODO(), "pool1", UpdatePoolOptions{Labels: map[string]string{"resource-labels": `{"tsuru.io/pool": "other"}`}})
+	c.Assert(err, check.FitsTypeOf, &tsuruErrors.ValidationError{})
+}
+
+func (s *S) TestRequiresDeployApproval(c *check.C) {
+	p := Pool{Name: "pool1"}
+	c.Assert(p.RequiresDeployApproval(), check.Equals, false)
+	p.Labels = map[string]string{"deploy-approval": "true"}
+	c.Assert(p.RequiresDeployApproval(), check.Equals, true)
+	p.Labels = map[string]string{"deploy-approval": "no"}
+	c.Assert(p.RequiresDeployApproval(), check.Equals, false)
+}
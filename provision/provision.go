@@ -26,6 +26,7 @@ import (
 	imgTypes "github.com/tsuru/tsuru/types/app/image"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	volumeTypes "github.com/tsuru/tsuru/types/volume"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
@@ -38,6 +39,18 @@ const (
 	WebProcessName     = "web"
 )
 
+// Deploy stages recorded in deploy events, see event.Event.StartStage.
+const (
+	DeployStageUpload      = "upload"
+	DeployStageBuild       = "build"
+	DeployStagePush        = "push"
+	DeployStagePull        = "pull"
+	DeployStageCreate      = "create"
+	DeployStageStart       = "start"
+	DeployStageHealthcheck = "healthcheck"
+	DeployStageRouteSwap   = "route-swap"
+)
+
 var (
 	ErrInvalidStatus = errors.New("invalid status")
 	ErrEmptyApp      = errors.New("no units for this app")
@@ -248,6 +261,10 @@ type App interface {
 	GetMilliCPU() int
 	GetSwap() int64
 	GetCpuShare() int
+	GetCPUQuota() int64
+	GetCPUPeriod() int64
+	GetBlkioWeight() int64
+	GetPidsLimit() int64
 
 	GetUpdatePlatform() bool
 
@@ -263,6 +280,14 @@ type App interface {
 	GetMetadata() appTypes.Metadata
 
 	GetRegistry() (imgTypes.ImageRegistry, error)
+
+	// GetDisruptionSettings returns the rollout and disruption budget
+	// settings of the app, empty values meaning provisioner defaults.
+	GetDisruptionSettings() appTypes.DisruptionSettings
+
+	// GetPlacementConstraints returns the requirements on the metadata of
+	// the nodes running the units of the app.
+	GetPlacementConstraints() []appTypes.PlacementConstraint
 }
 
 type BuilderDockerClient interface {
@@ -321,6 +346,39 @@ type BuilderDeploy interface {
 	Deploy(context.Context, DeployArgs) (string, error)
 }
 
+// DeployPlan describes the changes a deploy would make to the units and
+// routes of an app.
+type DeployPlan struct {
+	App   string        `json:"app"`
+	Image string        `json:"image"`
+	Units []PlannedUnit `json:"units"`
+	Pulls []PlannedPull `json:"pulls"`
+	// RoutesAdded holds the hosts that would receive routes to the new
+	// units, the ports are only known after the units are created.
+	RoutesAdded   []string `json:"routesAdded"`
+	RoutesRemoved []string `json:"routesRemoved"`
+}
+
+// PlannedUnit is a unit that would be created by a deploy, replacing the
+// unit with id Replaces, if any.
+type PlannedUnit struct {
+	Process  string `json:"process"`
+	Node     string `json:"node"`
+	Replaces string `json:"replaces,omitempty"`
+}
+
+// PlannedPull is an image that would be pulled in a node by a deploy.
+type PlannedPull struct {
+	Node  string `json:"node"`
+	Image string `json:"image"`
+}
+
+// DeployPlanner is a provisioner able to compute the plan of a deploy
+// without side effects.
+type DeployPlanner interface {
+	PlanDeploy(ctx context.Context, app App, image string) (*DeployPlan, error)
+}
+
 type BuilderDeployDockerClient interface {
 	BuilderDeploy
 	GetClient(App) (BuilderDockerClient, error)
@@ -416,6 +474,45 @@ type MetricsProvisioner interface {
 	UnitsMetrics(ctx context.Context, a App) ([]UnitMetric, error)
 }
 
+// UsageProvisioner is a provisioner able to sample the resources used by the
+// app units, used to meter apps for chargeback.
+type UsageProvisioner interface {
+	UnitsUsage(ctx context.Context, a App) ([]UnitUsage, error)
+}
+
+// UnitUsage is a sample of the resources used by an unit. CPU is the number
+// of cores in use and Memory the bytes in use at sampling time, while the
+// network counters are cumulative since the unit started, being zero when
+// unavailable.
+type UnitUsage struct {
+	ID             string
+	Process        string
+	CPU            float64
+	Memory         int64
+	NetworkRxBytes uint64
+	NetworkTxBytes uint64
+}
+
+// UnitStatsProvisioner is a provisioner able to report the resources
+// recently used by an unit, without an external monitoring stack.
+type UnitStatsProvisioner interface {
+	UnitStats(ctx context.Context, a App, unitID string) ([]UnitStats, error)
+}
+
+// UnitStats is a sample of the resources used by an unit, CPU is the number
+// of cores in use. Network and block IO counters are cumulative since the
+// unit started.
+type UnitStats struct {
+	Time            time.Time
+	CPU             float64
+	Memory          int64
+	MemoryLimit     int64
+	NetworkRxBytes  uint64
+	NetworkTxBytes  uint64
+	BlockReadBytes  uint64
+	BlockWriteBytes uint64
+}
+
 // SleepableProvisioner is a provisioner that allows putting applications to
 // sleep.
 type SleepableProvisioner interface {
@@ -425,6 +522,15 @@ type SleepableProvisioner interface {
 	Sleep(context.Context, App, string, appTypes.AppVersion) error
 }
 
+// RollingRestarter is a provisioner able to restart the units of an app in
+// batches, replacing a few units at a time instead of all of them at once.
+type RollingRestarter interface {
+	// RollingRestart replaces the units of all processes of the app, at most
+	// batchSize units at a time, waiting for each batch to be started before
+	// moving on to the next one.
+	RollingRestart(ctx context.Context, app App, version appTypes.AppVersion, batchSize int, w io.Writer) error
+}
+
 // UpdatableProvisioner is a provisioner that stores data about applications
 // and must be notified when they are updated
 type UpdatableProvisioner interface {
@@ -437,6 +543,23 @@ type InterAppProvisioner interface {
 	InternalAddresses(ctx context.Context, a App) ([]AppInternalAddress, error)
 }
 
+// DisruptionProvisioner is a provisioner honoring the disruption settings of
+// apps, able to report the settings in effect for the app units.
+type DisruptionProvisioner interface {
+	DisruptionSettings(ctx context.Context, a App) (EffectiveDisruptionSettings, error)
+}
+
+// EffectiveDisruptionSettings are the rollout and disruption budget settings
+// in effect for the units of an app, merging the app settings with the
+// provisioner defaults.
+type EffectiveDisruptionSettings struct {
+	MaxSurge          string `json:"maxSurge"`
+	MaxUnavailable    string `json:"maxUnavailable"`
+	PDBEnabled        bool   `json:"pdbEnabled"`
+	PDBMinAvailable   string `json:"pdbMinAvailable,omitempty"`
+	PDBMaxUnavailable string `json:"pdbMaxUnavailable,omitempty"`
+}
+
 type AppInternalAddress struct {
 	Domain   string
 	Protocol string
@@ -445,6 +568,20 @@ type AppInternalAddress struct {
 	Process  string
 }
 
+// ExposedPortsProvisioner is a provisioner able to expose raw TCP and UDP
+// ports declared by app processes, bypassing the app routers.
+type ExposedPortsProvisioner interface {
+	ExposedEndpoints(ctx context.Context, a App) ([]AppExposedEndpoint, error)
+}
+
+type AppExposedEndpoint struct {
+	Process  string `json:"process"`
+	Protocol string `json:"protocol"`
+	Port     int    `json:"port"`
+	Address  string `json:"address"`
+	Unit     string `json:"unit,omitempty"`
+}
+
 // MessageProvisioner is a provisioner that provides a welcome message for
 // logging.
 type MessageProvisioner interface {
@@ -475,6 +612,27 @@ type KillUnitProvisioner interface {
 	KillUnit(ctx context.Context, app App, unit string, force bool) error
 }
 
+// RemoveUnitsByIDProvisioner is a provisioner able to remove specific units
+// of an app, instead of choosing which units to remove.
+type RemoveUnitsByIDProvisioner interface {
+	RemoveUnitsByID(ctx context.Context, app App, units []string, w io.Writer) error
+}
+
+// RestartUnitProvisioner is a provisioner able to replace a single unit of an
+// app, keeping the other units running.
+type RestartUnitProvisioner interface {
+	// RestartUnit replaces the unit with a new one running the same process,
+	// preferably in the same node.
+	RestartUnit(ctx context.Context, app App, unit string, w io.Writer) error
+}
+
+// MaintenanceApp is an app whose routes may point to a maintenance backend.
+// Provisioners must not route traffic to new units of an app under
+// maintenance, the routes are rebuilt when the maintenance ends.
+type MaintenanceApp interface {
+	UnderMaintenance() bool
+}
+
 // HCProvisioner is a provisioner that may handle loadbalancing healthchecks.
 type HCProvisioner interface {
 	// HandlesHC returns true if the provisioner will handle healthchecking
@@ -546,6 +704,52 @@ type NodeRebalanceProvisioner interface {
 	RebalanceNodes(context.Context, RebalanceNodesOptions) (bool, error)
 }
 
+// RebalanceMove is a unit that would be moved from a node to another one by
+// a rebalance.
+type RebalanceMove struct {
+	App     string `json:"app"`
+	Process string `json:"process"`
+	Unit    string `json:"unit"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// RebalanceNodePlan holds the units and reserved memory of a node before
+// and after a rebalance.
+type RebalanceNodePlan struct {
+	Address      string `json:"address"`
+	UnitsBefore  int    `json:"unitsBefore"`
+	UnitsAfter   int    `json:"unitsAfter"`
+	MemoryBefore int64  `json:"memoryBefore"`
+	MemoryAfter  int64  `json:"memoryAfter"`
+}
+
+// RebalancePlan is the outcome of a rebalance computed without moving any
+// unit, allowing it to be reviewed before the rebalance is triggered.
+type RebalancePlan struct {
+	Moves             []RebalanceMove     `json:"moves"`
+	Nodes             []RebalanceNodePlan `json:"nodes"`
+	EstimatedDuration time.Duration       `json:"estimatedDuration"`
+}
+
+// NodeRebalancePlanProvisioner is a provisioner able to compute the moves
+// of a rebalance without executing them.
+type NodeRebalancePlanProvisioner interface {
+	RebalancePlan(context.Context, RebalanceNodesOptions) (*RebalancePlan, error)
+}
+
+type EvictNodeOptions struct {
+	Address string
+	Writer  io.Writer
+}
+
+// NodeEvictionProvisioner is a provisioner able to react to eviction notices
+// of nodes, like the termination of spot instances, moving the units away
+// from the node before it's gone.
+type NodeEvictionProvisioner interface {
+	EvictNode(context.Context, EvictNodeOptions) error
+}
+
 type NodeContainerProvisioner interface {
 	UpgradeNodeContainer(ctx context.Context, name string, pool string, writer io.Writer) error
 	RemoveNodeContainer(ctx context.Context, name string, pool string, writer io.Writer) error
@@ -582,6 +786,56 @@ type AutoScaleSpec struct {
 	MaxUnits   uint   `json:"maxUnits"`
 	AverageCPU string `json:"averageCPU"`
 	Version    int    `json:"version"`
+	// Metrics are custom or external metrics used to scale the units,
+	// along with the cpu usage when AverageCPU is set.
+	Metrics []AutoScaleMetric `json:"metrics,omitempty"`
+	// Status is the current state of the autoscaler, only filled by
+	// AutoScaleStatusProvisioner.
+	Status *AutoScaleStatus `json:"status,omitempty"`
+}
+
+const (
+	// AutoScaleMetricPods is a custom metric reported by the units, like
+	// requests per second, averaged across them.
+	AutoScaleMetricPods = "pods"
+	// AutoScaleMetricExternal is a metric from outside the cluster, like
+	// the depth of a queue exposed by a metrics adapter.
+	AutoScaleMetricExternal = "external"
+)
+
+// AutoScaleMetric is a custom or external metric used to scale the units of
+// the app. Target is the value of the metric expected for each unit, as a
+// quantity like "10" or "500m".
+type AutoScaleMetric struct {
+	Type     string            `json:"type"`
+	Name     string            `json:"name"`
+	Selector map[string]string `json:"selector,omitempty"`
+	Target   string            `json:"target"`
+}
+
+// AutoScaleStatus is the current state of an autoscaler.
+type AutoScaleStatus struct {
+	CurrentUnits   uint                   `json:"currentUnits"`
+	DesiredUnits   uint                   `json:"desiredUnits"`
+	LastScaleTime  *time.Time             `json:"lastScaleTime,omitempty"`
+	CurrentMetrics []AutoScaleMetricValue `json:"currentMetrics,omitempty"`
+	Conditions     []AutoScaleCondition   `json:"conditions,omitempty"`
+}
+
+// AutoScaleMetricValue is the current value of a metric used by an
+// autoscaler, averaged across the units when the metric has a per unit
+// target.
+type AutoScaleMetricValue struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type AutoScaleCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 type RecommendedResources struct {
@@ -635,9 +889,33 @@ func (s AutoScaleSpec) Validate(quotaLimit int, a App) error {
 	if quotaLimit > 0 && s.MaxUnits > uint(quotaLimit) {
 		return errors.New("maximum units cannot be greater than quota limit")
 	}
-	_, err := s.ToCPUValue(a)
-	if err != nil {
-		return err
+	if s.AverageCPU == "" && len(s.Metrics) == 0 {
+		return errors.New("autoscale requires a cpu value or at least one metric")
+	}
+	if s.AverageCPU != "" {
+		_, err := s.ToCPUValue(a)
+		if err != nil {
+			return err
+		}
+	}
+	for _, m := range s.Metrics {
+		err := m.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m AutoScaleMetric) Validate() error {
+	if m.Type != AutoScaleMetricPods && m.Type != AutoScaleMetricExternal {
+		return errors.Errorf("invalid autoscale metric type %q, must be %s or %s", m.Type, AutoScaleMetricPods, AutoScaleMetricExternal)
+	}
+	if m.Name == "" {
+		return errors.New("autoscale metric name is required")
+	}
+	if _, err := resource.ParseQuantity(m.Target); err != nil {
+		return errors.Errorf("invalid target %q for autoscale metric %q", m.Target, m.Name)
 	}
 	return nil
 }
@@ -649,6 +927,12 @@ type AutoScaleProvisioner interface {
 	RemoveAutoScale(ctx context.Context, a App, process string) error
 }
 
+// AutoScaleStatusProvisioner is an autoscale provisioner able to report the
+// current state of the app autoscalers.
+type AutoScaleStatusProvisioner interface {
+	GetAutoScaleStatus(ctx context.Context, a App) ([]AutoScaleSpec, error)
+}
+
 type Node interface {
 	Pool() string
 	IaaSID() string
@@ -228,6 +228,37 @@ func (ProvisionSuite) TestValidate(c *check.C) {
 			},
 			"maximum units cannot be greater than quota limit",
 		},
+		{
+			AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 5,
+			},
+			"autoscale requires a cpu value or at least one metric",
+		},
+		{
+			AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 5,
+				Metrics:  []AutoScaleMetric{{Type: "object", Name: "rps", Target: "10"}},
+			},
+			`invalid autoscale metric type "object", must be pods or external`,
+		},
+		{
+			AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 5,
+				Metrics:  []AutoScaleMetric{{Type: AutoScaleMetricExternal, Target: "10"}},
+			},
+			"autoscale metric name is required",
+		},
+		{
+			AutoScaleSpec{
+				MinUnits: 1,
+				MaxUnits: 5,
+				Metrics:  []AutoScaleMetric{{Type: AutoScaleMetricExternal, Name: "queue_depth", Target: "many"}},
+			},
+			`invalid target "many" for autoscale metric "queue_depth"`,
+		},
 	}
 
 	for _, test := range tests {
@@ -235,3 +266,15 @@ func (ProvisionSuite) TestValidate(c *check.C) {
 		c.Check(err, check.ErrorMatches, test.expected)
 	}
 }
+
+func (ProvisionSuite) TestValidateWithMetrics(c *check.C) {
+	spec := AutoScaleSpec{
+		MinUnits: 1,
+		MaxUnits: 5,
+		Metrics: []AutoScaleMetric{
+			{Type: AutoScaleMetricExternal, Name: "queue_depth", Selector: map[string]string{"queue": "jobs"}, Target: "30"},
+			{Type: AutoScaleMetricPods, Name: "requests_per_second", Target: "500m"},
+		},
+	}
+	c.Assert(spec.Validate(10, nil), check.IsNil)
+}
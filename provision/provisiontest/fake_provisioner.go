@@ -37,21 +37,28 @@ var (
 	errNotProvisioned         = &provision.Error{Reason: "App is not provisioned."}
 	uniqueIpCounter     int32 = 0
 
-	_ provision.Provisioner              = &FakeProvisioner{}
-	_ provision.NodeProvisioner          = &FakeProvisioner{}
-	_ provision.NodeContainerProvisioner = &FakeProvisioner{}
-	_ provision.InterAppProvisioner      = &FakeProvisioner{}
-	_ provision.UpdatableProvisioner     = &FakeProvisioner{}
-	_ provision.Provisioner              = &FakeProvisioner{}
-	_ provision.LogsProvisioner          = &FakeProvisioner{}
-	_ provision.MetricsProvisioner       = &FakeProvisioner{}
-	_ provision.VolumeProvisioner        = &FakeProvisioner{}
-	_ provision.SleepableProvisioner     = &FakeProvisioner{}
-	_ provision.AppFilterProvisioner     = &FakeProvisioner{}
-	_ provision.ExecutableProvisioner    = &FakeProvisioner{}
-	_ provision.NodeRebalanceProvisioner = &FakeProvisioner{}
-	_ provision.App                      = &FakeApp{}
-	_ bind.App                           = &FakeApp{}
+	_ provision.Provisioner                = &FakeProvisioner{}
+	_ provision.NodeProvisioner            = &FakeProvisioner{}
+	_ provision.NodeContainerProvisioner   = &FakeProvisioner{}
+	_ provision.InterAppProvisioner        = &FakeProvisioner{}
+	_ provision.UpdatableProvisioner       = &FakeProvisioner{}
+	_ provision.Provisioner                = &FakeProvisioner{}
+	_ provision.LogsProvisioner            = &FakeProvisioner{}
+	_ provision.MetricsProvisioner         = &FakeProvisioner{}
+	_ provision.VolumeProvisioner          = &FakeProvisioner{}
+	_ provision.SleepableProvisioner       = &FakeProvisioner{}
+	_ provision.AppFilterProvisioner       = &FakeProvisioner{}
+	_ provision.ExecutableProvisioner      = &FakeProvisioner{}
+	_ provision.NodeRebalanceProvisioner   = &FakeProvisioner{}
+	_ provision.NodeEvictionProvisioner    = &FakeProvisioner{}
+	_ provision.RollingRestarter           = &FakeProvisioner{}
+	_ provision.ExposedPortsProvisioner    = &FakeProvisioner{}
+	_ provision.UnitStatsProvisioner       = &FakeProvisioner{}
+	_ provision.DisruptionProvisioner      = &FakeProvisioner{}
+	_ provision.RestartUnitProvisioner     = &FakeProvisioner{}
+	_ provision.RemoveUnitsByIDProvisioner = &FakeProvisioner{}
+	_ provision.App                        = &FakeApp{}
+	_ bind.App                             = &FakeApp{}
 )
 
 func init() {
@@ -77,6 +84,10 @@ type FakeApp struct {
 	Swap              int64
 	CpuShare          int
 	MilliCPU          int
+	CPUQuota          int64
+	CPUPeriod         int64
+	BlkioWeight       int64
+	PidsLimit         int64
 	commMut           sync.Mutex
 	Deploys           uint
 	env               map[string]bind.EnvVar
@@ -91,6 +102,8 @@ type FakeApp struct {
 	Tags              []string
 	Metadata          appTypes.Metadata
 	InternalAddresses []provision.AppInternalAddress
+	Disruption        appTypes.DisruptionSettings
+	Placement         []appTypes.PlacementConstraint
 }
 
 func NewFakeApp(name, platform string, units int) *FakeApp {
@@ -139,6 +152,22 @@ func (a *FakeApp) GetCpuShare() int {
 	return a.CpuShare
 }
 
+func (a *FakeApp) GetCPUQuota() int64 {
+	return a.CPUQuota
+}
+
+func (a *FakeApp) GetCPUPeriod() int64 {
+	return a.CPUPeriod
+}
+
+func (a *FakeApp) GetBlkioWeight() int64 {
+	return a.BlkioWeight
+}
+
+func (a *FakeApp) GetPidsLimit() int64 {
+	return a.PidsLimit
+}
+
 func (a *FakeApp) GetTeamsName() []string {
 	return a.Teams
 }
@@ -372,6 +401,14 @@ func (app *FakeApp) GetMetadata() appTypes.Metadata {
 	return app.Metadata
 }
 
+func (app *FakeApp) GetDisruptionSettings() appTypes.DisruptionSettings {
+	return app.Disruption
+}
+
+func (app *FakeApp) GetPlacementConstraints() []appTypes.PlacementConstraint {
+	return app.Placement
+}
+
 func (app *FakeApp) GetRegistry() (imgTypes.ImageRegistry, error) {
 	return "", nil
 }
@@ -586,6 +623,24 @@ func (p *FakeProvisioner) UpdateNode(ctx context.Context, opts provision.UpdateN
 	return nil
 }
 
+func (p *FakeProvisioner) EvictNode(ctx context.Context, opts provision.EvictNodeOptions) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	if err := p.getError("EvictNode"); err != nil {
+		return err
+	}
+	n, ok := p.nodes[opts.Address]
+	if !ok {
+		return provision.ErrNodeNotFound
+	}
+	n.status = "disabled"
+	p.nodes[opts.Address] = n
+	if opts.Writer != nil {
+		opts.Writer.Write([]byte("evicting..."))
+	}
+	return nil
+}
+
 type nodeList []provision.Node
 
 func (l nodeList) Len() int           { return len(l) }
@@ -673,6 +728,35 @@ func (p *FakeProvisioner) RebalanceNodes(ctx context.Context, opts provision.Reb
 	return p.rebalanceNodesLocked(opts)
 }
 
+// RebalancePlan reports the units in each node without planning any move,
+// the fake rebalance doesn't move units among nodes.
+func (p *FakeProvisioner) RebalancePlan(ctx context.Context, opts provision.RebalanceNodesOptions) (*provision.RebalancePlan, error) {
+	if err := p.getError("RebalancePlan"); err != nil {
+		return nil, err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	plan := &provision.RebalancePlan{Moves: []provision.RebalanceMove{}, Nodes: []provision.RebalanceNodePlan{}}
+	for _, n := range p.nodes {
+		if opts.Pool != "" && n.Pool() != opts.Pool {
+			continue
+		}
+		units, err := n.unitsLocked()
+		if err != nil {
+			return nil, err
+		}
+		plan.Nodes = append(plan.Nodes, provision.RebalanceNodePlan{
+			Address:     n.Address(),
+			UnitsBefore: len(units),
+			UnitsAfter:  len(units),
+		})
+	}
+	sort.Slice(plan.Nodes, func(i, j int) bool {
+		return plan.Nodes[i].Address < plan.Nodes[j].Address
+	})
+	return plan, nil
+}
+
 func (p *FakeProvisioner) rebalanceNodesLocked(opts provision.RebalanceNodesOptions) (bool, error) {
 	if err := p.getError("RebalanceNodes"); err != nil {
 		return true, err
@@ -752,6 +836,22 @@ func (p *FakeProvisioner) Restarts(a provision.App, process string) int {
 	return p.apps[a.GetName()].restarts[process]
 }
 
+// RollingRestarts returns the batch sizes used in each rolling restart of the
+// given app.
+func (p *FakeProvisioner) RollingRestarts(a provision.App) []int {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.apps[a.GetName()].rollings
+}
+
+// UnitRestarts returns the IDs of the units restarted individually in the
+// given app.
+func (p *FakeProvisioner) UnitRestarts(a provision.App) []string {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+	return p.apps[a.GetName()].unitRestarts
+}
+
 // Starts returns the number of starts for a given app.
 func (p *FakeProvisioner) Starts(app provision.App, process string) int {
 	p.mut.RLock()
@@ -913,7 +1013,11 @@ func (p *FakeProvisioner) GetClient(app provision.App) (provision.BuilderDockerC
 		if err != nil {
 			return nil, err
 		}
-		return &dockercommon.PullAndCreateClient{Client: client}, nil
+		pullClient := &dockercommon.PullAndCreateClient{Client: client}
+		if app != nil {
+			pullClient.Pool = app.GetPool()
+		}
+		return pullClient, nil
 	}
 	return nil, errors.New("No node found")
 
@@ -970,6 +1074,47 @@ func (p *FakeProvisioner) Restart(ctx context.Context, app provision.App, proces
 	return nil
 }
 
+func (p *FakeProvisioner) RestartUnit(ctx context.Context, app provision.App, unitID string, w io.Writer) error {
+	if err := p.getError("RestartUnit"); err != nil {
+		return err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	pApp, ok := p.apps[app.GetName()]
+	if !ok {
+		return errNotProvisioned
+	}
+	for _, unit := range pApp.units {
+		if unit.ID == unitID {
+			pApp.unitRestarts = append(pApp.unitRestarts, unitID)
+			p.apps[app.GetName()] = pApp
+			if w != nil {
+				fmt.Fprintf(w, "restarting unit %s", unitID)
+			}
+			return nil
+		}
+	}
+	return &provision.UnitNotFoundError{ID: unitID}
+}
+
+func (p *FakeProvisioner) RollingRestart(ctx context.Context, app provision.App, version appTypes.AppVersion, batchSize int, w io.Writer) error {
+	if err := p.getError("RollingRestart"); err != nil {
+		return err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	pApp, ok := p.apps[app.GetName()]
+	if !ok {
+		return errNotProvisioned
+	}
+	pApp.rollings = append(pApp.rollings, batchSize)
+	p.apps[app.GetName()] = pApp
+	if w != nil {
+		fmt.Fprintf(w, "rolling restart app")
+	}
+	return nil
+}
+
 func (p *FakeProvisioner) Start(ctx context.Context, app provision.App, process string, version appTypes.AppVersion, w io.Writer) error {
 	p.mut.Lock()
 	defer p.mut.Unlock()
@@ -1118,6 +1263,46 @@ func (p *FakeProvisioner) RemoveUnits(ctx context.Context, app provision.App, n
 	return nil
 }
 
+func (p *FakeProvisioner) RemoveUnitsByID(ctx context.Context, app provision.App, unitIDs []string, w io.Writer) error {
+	if err := p.getError("RemoveUnitsByID"); err != nil {
+		return err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	pApp, ok := p.apps[app.GetName()]
+	if !ok {
+		return errNotProvisioned
+	}
+	toRemove := map[string]bool{}
+	for _, id := range unitIDs {
+		toRemove[id] = true
+	}
+	var newUnits []provision.Unit
+	var addresses []*url.URL
+	for _, u := range pApp.units {
+		if toRemove[u.ID] {
+			delete(toRemove, u.ID)
+			addresses = append(addresses, u.Address)
+			continue
+		}
+		newUnits = append(newUnits, u)
+	}
+	for id := range toRemove {
+		return &provision.UnitNotFoundError{ID: id}
+	}
+	err := routertest.FakeRouter.RemoveRoutes(ctx, app, addresses)
+	if err != nil {
+		return err
+	}
+	if w != nil {
+		fmt.Fprintf(w, "removing %d units", len(unitIDs))
+	}
+	pApp.units = newUnits
+	pApp.unitLen = len(newUnits)
+	p.apps[app.GetName()] = pApp
+	return nil
+}
+
 func (p *FakeProvisioner) AddUnit(app provision.App, unit provision.Unit) {
 	p.mut.Lock()
 	defer p.mut.Unlock()
@@ -1157,6 +1342,49 @@ func (p *FakeProvisioner) UnitsMetrics(ctx context.Context, a provision.App) ([]
 	return unitsMetrics, nil
 }
 
+func (p *FakeProvisioner) UnitsUsage(ctx context.Context, a provision.App) ([]provision.UnitUsage, error) {
+	if err := p.getError("UnitsUsage"); err != nil {
+		return nil, err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	var usage []provision.UnitUsage
+	for _, unit := range p.apps[a.GetName()].units {
+		usage = append(usage, provision.UnitUsage{
+			ID:             unit.ID,
+			Process:        unit.ProcessName,
+			CPU:            0.5,
+			Memory:         1000000000,
+			NetworkRxBytes: 1000,
+			NetworkTxBytes: 2000,
+		})
+	}
+	return usage, nil
+}
+
+func (p *FakeProvisioner) UnitStats(ctx context.Context, a provision.App, unitID string) ([]provision.UnitStats, error) {
+	if err := p.getError("UnitStats"); err != nil {
+		return nil, err
+	}
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	for _, unit := range p.apps[a.GetName()].units {
+		if unit.ID == unitID {
+			return []provision.UnitStats{{
+				Time:            time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				CPU:             0.5,
+				Memory:          1000000000,
+				MemoryLimit:     2000000000,
+				NetworkRxBytes:  1000,
+				NetworkTxBytes:  2000,
+				BlockReadBytes:  3000,
+				BlockWriteBytes: 4000,
+			}}, nil
+		}
+	}
+	return nil, &provision.UnitNotFoundError{ID: unitID}
+}
+
 func (p *FakeProvisioner) MockRoutableAddresses(app provision.App, addrs []appTypes.RoutableAddresses) {
 	p.mut.Lock()
 	defer p.mut.Unlock()
@@ -1411,6 +1639,37 @@ func (p *FakeProvisioner) UpdateApp(ctx context.Context, old, new provision.App,
 	return nil
 }
 
+func (p *FakeProvisioner) ExposedEndpoints(ctx context.Context, a provision.App) ([]provision.AppExposedEndpoint, error) {
+	return []provision.AppExposedEndpoint{
+		{
+			Process:  "db",
+			Protocol: "tcp",
+			Port:     5432,
+			Address:  fmt.Sprintf("%s-db.fake-cluster.local:30000", a.GetName()),
+		},
+	}, nil
+}
+
+func (p *FakeProvisioner) DisruptionSettings(ctx context.Context, a provision.App) (provision.EffectiveDisruptionSettings, error) {
+	settings := a.GetDisruptionSettings()
+	effective := provision.EffectiveDisruptionSettings{
+		MaxSurge:        "100%",
+		MaxUnavailable:  "0",
+		PDBEnabled:      true,
+		PDBMinAvailable: settings.PDBMinAvailable,
+	}
+	if settings.MaxSurge != "" {
+		effective.MaxSurge = settings.MaxSurge
+	}
+	if settings.MaxUnavailable != "" {
+		effective.MaxUnavailable = settings.MaxUnavailable
+	}
+	if effective.PDBMinAvailable == "" {
+		effective.PDBMaxUnavailable = "10%"
+	}
+	return effective, nil
+}
+
 func (p *FakeProvisioner) InternalAddresses(ctx context.Context, a provision.App) ([]provision.AppInternalAddress, error) {
 	return []provision.AppInternalAddress{
 		{
@@ -1515,17 +1774,19 @@ func (p *PipelineErrorFakeProvisioner) DeployPipeline() *action.Pipeline {
 }
 
 type provisionedApp struct {
-	units     []provision.Unit
-	app       provision.App
-	restarts  map[string]int
-	starts    map[string]int
-	stops     map[string]int
-	sleeps    map[string]int
-	cnames    []string
-	unitLen   int
-	lastData  map[string]interface{}
-	image     string
-	mockAddrs []appTypes.RoutableAddresses
+	units        []provision.Unit
+	app          provision.App
+	restarts     map[string]int
+	rollings     []int
+	unitRestarts []string
+	starts       map[string]int
+	stops        map[string]int
+	sleeps       map[string]int
+	cnames       []string
+	unitLen      int
+	lastData     map[string]interface{}
+	image        string
+	mockAddrs    []appTypes.RoutableAddresses
 }
 
 type AutoScaleProvisioner struct {
@@ -1533,7 +1794,10 @@ type AutoScaleProvisioner struct {
 	autoscales map[string][]provision.AutoScaleSpec
 }
 
-var _ provision.AutoScaleProvisioner = &AutoScaleProvisioner{}
+var (
+	_ provision.AutoScaleProvisioner       = &AutoScaleProvisioner{}
+	_ provision.AutoScaleStatusProvisioner = &AutoScaleProvisioner{}
+)
 
 func (p *AutoScaleProvisioner) GetAutoScale(ctx context.Context, app provision.App) ([]provision.AutoScaleSpec, error) {
 	if p.autoscales == nil {
@@ -1542,6 +1806,21 @@ func (p *AutoScaleProvisioner) GetAutoScale(ctx context.Context, app provision.A
 	return p.autoscales[app.GetName()], nil
 }
 
+func (p *AutoScaleProvisioner) GetAutoScaleStatus(ctx context.Context, app provision.App) ([]provision.AutoScaleSpec, error) {
+	if p.autoscales == nil {
+		return nil, nil
+	}
+	var specs []provision.AutoScaleSpec
+	for _, spec := range p.autoscales[app.GetName()] {
+		spec.Status = &provision.AutoScaleStatus{
+			CurrentUnits: spec.MinUnits,
+			DesiredUnits: spec.MinUnits,
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
 func (p *AutoScaleProvisioner) GetVerticalAutoScaleRecommendations(ctx context.Context, app provision.App) ([]provision.RecommendedResources, error) {
 	if p.autoscales == nil {
 		return nil, nil
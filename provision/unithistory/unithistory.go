@@ -0,0 +1,344 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package unithistory stores the status transitions of app units, used to
+// show the history of each unit and the uptime of apps with flapping units.
+package unithistory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/provision"
+)
+
+const (
+	collectionName = "unit_status_history"
+
+	// Retention is how long transitions are kept.
+	Retention = 30 * 24 * time.Hour
+
+	// StatusRemoved is recorded when a unit is removed, ending its history.
+	StatusRemoved = "removed"
+)
+
+// Transition is a change in the status of a unit.
+type Transition struct {
+	ID      bson.ObjectId `bson:"_id" json:"-"`
+	App     string        `json:"app"`
+	Unit    string        `json:"unit"`
+	Process string        `json:"process"`
+	From    string        `json:"from,omitempty"`
+	Status  string        `json:"status"`
+	Reason  string        `json:"reason,omitempty"`
+	Time    time.Time     `json:"time"`
+}
+
+// UnitUptime summarizes the transitions of a unit in a period.
+type UnitUptime struct {
+	Unit     string  `json:"unit"`
+	Process  string  `json:"process"`
+	Status   string  `json:"status"`
+	Uptime   float64 `json:"uptime"`
+	Restarts int     `json:"restarts"`
+}
+
+// AppUptime summarizes the transitions of the units of an app in a period.
+// Uptime is the percentage of time the units spent started, averaged among
+// them, and Restarts is the number of times they were started again after
+// stopping or failing.
+type AppUptime struct {
+	App      string       `json:"app"`
+	Since    time.Time    `json:"since"`
+	Uptime   float64      `json:"uptime"`
+	Restarts int          `json:"restarts"`
+	Units    []UnitUptime `json:"units"`
+}
+
+func collection() (*storage.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	coll := conn.Collection(collectionName)
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"app", "unit", "time"}})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	err = coll.EnsureIndex(mgo.Index{Key: []string{"time"}, ExpireAfter: Retention})
+	if err != nil {
+		coll.Close()
+		return nil, err
+	}
+	return coll, nil
+}
+
+// Record stores the transition, ignoring the ones not changing the status.
+func Record(t Transition) error {
+	if t.From == t.Status {
+		return nil
+	}
+	if t.Time.IsZero() {
+		t.Time = time.Now().UTC()
+	}
+	t.ID = bson.NewObjectId()
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	return coll.Insert(t)
+}
+
+// UnitHistory returns the transitions of the unit, oldest first.
+func UnitHistory(appName, unit string) ([]Transition, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var transitions []Transition
+	err = coll.Find(bson.M{"app": appName, "unit": unit}).Sort("time", "_id").All(&transitions)
+	if err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// Uptime summarizes the transitions of the app units since the given time,
+// until now.
+func Uptime(appName string, since time.Time) (*AppUptime, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var transitions []Transition
+	err = coll.Find(bson.M{"app": appName, "time": bson.M{"$gte": since}}).Sort("time", "_id").All(&transitions)
+	if err != nil {
+		return nil, err
+	}
+	return summarize(appName, since, time.Now().UTC(), transitions), nil
+}
+
+func summarize(appName string, since, until time.Time, transitions []Transition) *AppUptime {
+	byUnit := map[string][]Transition{}
+	for _, t := range transitions {
+		byUnit[t.Unit] = append(byUnit[t.Unit], t)
+	}
+	result := &AppUptime{App: appName, Since: since, Units: []UnitUptime{}}
+	for unit, unitTransitions := range byUnit {
+		result.Units = append(result.Units, unitUptime(unit, since, until, unitTransitions))
+	}
+	sort.Slice(result.Units, func(i, j int) bool {
+		if result.Units[i].Process != result.Units[j].Process {
+			return result.Units[i].Process < result.Units[j].Process
+		}
+		return result.Units[i].Unit < result.Units[j].Unit
+	})
+	for _, u := range result.Units {
+		result.Uptime += u.Uptime
+		result.Restarts += u.Restarts
+	}
+	if len(result.Units) > 0 {
+		result.Uptime /= float64(len(result.Units))
+	}
+	return result
+}
+
+// unitUptime walks the transitions of a unit, which must be sorted by time.
+// Units already existing before since are considered to be in the status
+// they left in the first transition since then, new units are only accounted
+// from their first transition on.
+func unitUptime(unit string, since, until time.Time, transitions []Transition) UnitUptime {
+	first := transitions[0]
+	result := UnitUptime{Unit: unit, Process: first.Process}
+	start := first.Time
+	status := first.Status
+	if first.From != "" {
+		start = since
+		status = first.From
+	}
+	last := start
+	var total, up time.Duration
+	for i, t := range transitions {
+		if i > 0 || first.From != "" {
+			d := t.Time.Sub(last)
+			total += d
+			if status == provision.StatusStarted.String() {
+				up += d
+			}
+			last = t.Time
+			status = t.Status
+		}
+		if isRestart(t) {
+			result.Restarts++
+		}
+	}
+	if until.After(last) && status != StatusRemoved {
+		d := until.Sub(last)
+		total += d
+		if status == provision.StatusStarted.String() {
+			up += d
+		}
+	}
+	result.Status = status
+	if total > 0 {
+		result.Uptime = float64(up) / float64(total) * 100
+	} else if status == provision.StatusStarted.String() {
+		result.Uptime = 100
+	}
+	return result
+}
+
+func isRestart(t Transition) bool {
+	from := provision.Status(t.From)
+	to := provision.Status(t.Status)
+	return (from == provision.StatusStopped || from == provision.StatusError) &&
+		(to == provision.StatusStarting || to == provision.StatusStarted)
+}
+
+// RemoveApp removes the transitions of all units of the app.
+func RemoveApp(appName string) error {
+	coll, err := collection()
+	if err != nil {
+		return err
+	}
+	defer coll.Close()
+	_, err = coll.RemoveAll(bson.M{"app": appName})
+	return err
+}
+
+// ProcessAvailability summarizes the availability of an app process in a
+// period. A process is available while at least one of its units is started
+// and unavailable while none is started and some unit failed. Periods without
+// units or with all units stopped on purpose are not accounted. An incident
+// is each time the process becomes unavailable, and MTTR is the mean time
+// to recover from the incidents ended in the period.
+type ProcessAvailability struct {
+	Process      string        `json:"process"`
+	Availability float64       `json:"availability"`
+	Downtime     time.Duration `json:"downtime"`
+	Incidents    int           `json:"incidents"`
+	MTTR         time.Duration `json:"mttr"`
+}
+
+// Availability returns the availability of each process of the app between
+// since and until, sorted by process.
+func Availability(appName string, since, until time.Time) ([]ProcessAvailability, error) {
+	coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer coll.Close()
+	var transitions []Transition
+	query := bson.M{"app": appName, "time": bson.M{"$gte": since, "$lte": until}}
+	err = coll.Find(query).Sort("time", "_id").All(&transitions)
+	if err != nil {
+		return nil, err
+	}
+	byProcess := map[string][]Transition{}
+	for _, t := range transitions {
+		byProcess[t.Process] = append(byProcess[t.Process], t)
+	}
+	result := make([]ProcessAvailability, 0, len(byProcess))
+	for process, processTransitions := range byProcess {
+		result = append(result, processAvailability(process, since, until, processTransitions))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Process < result[j].Process
+	})
+	return result, nil
+}
+
+type processState int
+
+const (
+	stateIgnored processState = iota
+	stateUp
+	stateDown
+)
+
+func currentProcessState(statuses map[string]string) processState {
+	failed := false
+	for _, status := range statuses {
+		switch provision.Status(status) {
+		case provision.StatusStarted:
+			return stateUp
+		case provision.StatusError:
+			failed = true
+		}
+	}
+	if failed {
+		return stateDown
+	}
+	return stateIgnored
+}
+
+// processAvailability sweeps the transitions of the units of a process, which
+// must be sorted by time.
+func processAvailability(process string, since, until time.Time, transitions []Transition) ProcessAvailability {
+	result := ProcessAvailability{Process: process}
+	statuses := map[string]string{}
+	seen := map[string]struct{}{}
+	for _, t := range transitions {
+		if _, ok := seen[t.Unit]; ok {
+			continue
+		}
+		seen[t.Unit] = struct{}{}
+		if t.From != "" && t.From != StatusRemoved {
+			statuses[t.Unit] = t.From
+		}
+	}
+	var up, down, recovery time.Duration
+	var recovered int
+	var incidentStart time.Time
+	state := currentProcessState(statuses)
+	last := since
+	account := func(to time.Time) {
+		switch state {
+		case stateUp:
+			up += to.Sub(last)
+		case stateDown:
+			down += to.Sub(last)
+		}
+		last = to
+	}
+	for _, t := range transitions {
+		account(t.Time)
+		if t.Status == StatusRemoved {
+			delete(statuses, t.Unit)
+		} else {
+			statuses[t.Unit] = t.Status
+		}
+		newState := currentProcessState(statuses)
+		if newState == stateDown && state != stateDown {
+			result.Incidents++
+			incidentStart = t.Time
+		}
+		if state == stateDown && newState != stateDown && !incidentStart.IsZero() {
+			recovered++
+			recovery += t.Time.Sub(incidentStart)
+			incidentStart = time.Time{}
+		}
+		state = newState
+	}
+	if until.After(last) {
+		account(until)
+	}
+	result.Downtime = down
+	result.Availability = 100
+	if up+down > 0 {
+		result.Availability = float64(up) / float64(up+down) * 100
+	}
+	if recovered > 0 {
+		result.MTTR = recovery / time.Duration(recovered)
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unithistory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/db/dbtest"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	storage *db.Storage
+}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpSuite(c *check.C) {
+	config.Set("log:disable-syslog", true)
+	config.Set("database:url", "127.0.0.1:27017?maxPoolSize=100")
+	config.Set("database:name", "provision_unithistory_tests")
+	var err error
+	s.storage, err = db.Conn()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	dbtest.ClearAllCollections(s.storage.Apps().Database)
+}
+
+func (s *S) TearDownSuite(c *check.C) {
+	s.storage.Close()
+}
+
+func (s *S) TestRecordAndUnitHistory(c *check.C) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	transitions := []Transition{
+		{App: "myapp", Unit: "u1", Process: "web", Status: "starting", Time: now.Add(-3 * time.Minute)},
+		{App: "myapp", Unit: "u1", Process: "web", From: "starting", Status: "started", Time: now.Add(-2 * time.Minute)},
+		{App: "myapp", Unit: "u1", Process: "web", From: "started", Status: "started", Time: now.Add(-time.Minute)},
+		{App: "myapp", Unit: "u2", Process: "web", Status: "starting", Time: now},
+	}
+	for _, t := range transitions {
+		err := Record(t)
+		c.Assert(err, check.IsNil)
+	}
+	history, err := UnitHistory("myapp", "u1")
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 2)
+	c.Assert(history[0].Status, check.Equals, "starting")
+	c.Assert(history[1].From, check.Equals, "starting")
+	c.Assert(history[1].Status, check.Equals, "started")
+	c.Assert(history[1].Time.Equal(now.Add(-2*time.Minute)), check.Equals, true)
+	err = RemoveApp("myapp")
+	c.Assert(err, check.IsNil)
+	history, err = UnitHistory("myapp", "u2")
+	c.Assert(err, check.IsNil)
+	c.Assert(history, check.HasLen, 0)
+}
+
+func (s *S) TestSummarize(c *check.C) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(10 * time.Hour)
+	transitions := []Transition{
+		{Unit: "u1", Process: "web", From: "started", Status: "error", Time: since.Add(2 * time.Hour)},
+		{Unit: "u2", Process: "web", Status: "starting", Time: since.Add(5 * time.Hour)},
+		{Unit: "u1", Process: "web", From: "error", Status: "starting", Time: since.Add(3 * time.Hour)},
+		{Unit: "u2", Process: "web", From: "starting", Status: "started", Time: since.Add(6 * time.Hour)},
+		{Unit: "u1", Process: "web", From: "starting", Status: "started", Time: since.Add(4 * time.Hour)},
+	}
+	sortedTransitions := []Transition{transitions[0], transitions[2], transitions[4], transitions[1], transitions[3]}
+	result := summarize("myapp", since, until, sortedTransitions)
+	c.Assert(result.Units, check.DeepEquals, []UnitUptime{
+		{Unit: "u1", Process: "web", Status: "started", Uptime: 80, Restarts: 1},
+		{Unit: "u2", Process: "web", Status: "started", Uptime: 80, Restarts: 0},
+	})
+	c.Assert(result.Uptime, check.Equals, float64(80))
+	c.Assert(result.Restarts, check.Equals, 1)
+}
+
+func (s *S) TestSummarizeNoTransitions(c *check.C) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := summarize("myapp", since, since.Add(time.Hour), nil)
+	c.Assert(result.Units, check.DeepEquals, []UnitUptime{})
+	c.Assert(result.Uptime, check.Equals, float64(0))
+}
+
+func (s *S) TestSummarizeRemovedUnit(c *check.C) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	transitions := []Transition{
+		{Unit: "u1", Process: "web", From: "started", Status: "stopped", Time: since.Add(3 * time.Hour)},
+		{Unit: "u1", Process: "web", From: "stopped", Status: StatusRemoved, Time: since.Add(4 * time.Hour)},
+	}
+	result := summarize("myapp", since, since.Add(10*time.Hour), transitions)
+	c.Assert(result.Units, check.DeepEquals, []UnitUptime{
+		{Unit: "u1", Process: "web", Status: StatusRemoved, Uptime: 75},
+	})
+}
+
+func (s *S) TestProcessAvailability(c *check.C) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(20 * time.Hour)
+	transitions := []Transition{
+		{Unit: "u1", Process: "web", From: "started", Status: "error", Time: since.Add(2 * time.Hour)},
+		{Unit: "u1", Process: "web", From: "error", Status: "started", Time: since.Add(3 * time.Hour)},
+		{Unit: "u2", Process: "web", Status: "starting", Time: since.Add(5 * time.Hour)},
+		{Unit: "u2", Process: "web", From: "starting", Status: "started", Time: since.Add(6 * time.Hour)},
+		{Unit: "u1", Process: "web", From: "started", Status: "error", Time: since.Add(7 * time.Hour)},
+		{Unit: "u2", Process: "web", From: "started", Status: "error", Time: since.Add(8 * time.Hour)},
+		{Unit: "u2", Process: "web", From: "error", Status: "started", Time: since.Add(11 * time.Hour)},
+		{Unit: "u1", Process: "web", From: "error", Status: StatusRemoved, Time: since.Add(12 * time.Hour)},
+		{Unit: "u2", Process: "web", From: "started", Status: "stopped", Time: since.Add(16 * time.Hour)},
+	}
+	result := processAvailability("web", since, until, transitions)
+	c.Assert(result, check.DeepEquals, ProcessAvailability{
+		Process:      "web",
+		Availability: 75,
+		Downtime:     4 * time.Hour,
+		Incidents:    2,
+		MTTR:         2 * time.Hour,
+	})
+}
+
+func (s *S) TestProcessAvailabilityNoDowntime(c *check.C) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	transitions := []Transition{
+		{Unit: "u1", Process: "worker", Status: "starting", Time: since.Add(time.Hour)},
+		{Unit: "u1", Process: "worker", From: "starting", Status: "started", Time: since.Add(2 * time.Hour)},
+	}
+	result := processAvailability("worker", since, since.Add(4*time.Hour), transitions)
+	c.Assert(result, check.DeepEquals, ProcessAvailability{Process: "worker", Availability: 100})
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readcache
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultMaxEntries = 10000
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+type memoryHold struct {
+	count   int
+	expires time.Time
+}
+
+type memoryBackend struct {
+	sync.Mutex
+	maxEntries int
+	entries    map[string]memoryEntry
+	holds      map[string]memoryHold
+}
+
+func newMemoryBackend(maxEntries int) *memoryBackend {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	return &memoryBackend{
+		maxEntries: maxEntries,
+		entries:    map[string]memoryEntry{},
+		holds:      map[string]memoryHold{},
+	}
+}
+
+func (b *memoryBackend) get(key string) ([]byte, bool, error) {
+	b.Lock()
+	defer b.Unlock()
+	entry, ok := b.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(b.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (b *memoryBackend) set(key string, value []byte, ttl time.Duration) error {
+	b.Lock()
+	defer b.Unlock()
+	if _, ok := b.entries[key]; !ok && len(b.entries) >= b.maxEntries {
+		b.evict()
+	}
+	b.entries[key] = memoryEntry{value: value, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// evict removes the expired entries or, when none is expired, an arbitrary
+// entry. It must be called with the lock held.
+func (b *memoryBackend) evict() {
+	now := time.Now()
+	for key, entry := range b.entries {
+		if now.After(entry.expires) {
+			delete(b.entries, key)
+		}
+	}
+	if len(b.entries) < b.maxEntries {
+		return
+	}
+	for key := range b.entries {
+		delete(b.entries, key)
+		return
+	}
+}
+
+func (b *memoryBackend) del(keys ...string) error {
+	b.Lock()
+	defer b.Unlock()
+	for _, key := range keys {
+		delete(b.entries, key)
+	}
+	return nil
+}
+
+func (b *memoryBackend) hold(key string) error {
+	b.Lock()
+	defer b.Unlock()
+	h := b.holds[key]
+	h.count++
+	h.expires = time.Now().Add(holdTTL)
+	b.holds[key] = h
+	return nil
+}
+
+func (b *memoryBackend) release(key string) error {
+	b.Lock()
+	defer b.Unlock()
+	h, ok := b.holds[key]
+	if !ok {
+		return nil
+	}
+	h.count--
+	if h.count <= 0 {
+		delete(b.holds, key)
+		return nil
+	}
+	b.holds[key] = h
+	return nil
+}
+
+func (b *memoryBackend) held(key string) (bool, error) {
+	b.Lock()
+	defer b.Unlock()
+	h, ok := b.holds[key]
+	if !ok {
+		return false, nil
+	}
+	if time.Now().After(h.expires) {
+		delete(b.holds, key)
+		return false, nil
+	}
+	return true, nil
+}
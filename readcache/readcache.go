@@ -0,0 +1,260 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package readcache caches documents read from the database on every API
+// request, like apps, pools and auth tokens, in memory or in redis.
+//
+// Cached documents are invalidated when written by tsuru and bypassed while
+// an event targeting them is running, as handlers usually read and write the
+// target of the event. Writes made by other API instances are only seen by
+// the memory backend when the cached entry expires, so the redis backend
+// should be used when running multiple instances.
+package readcache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/log"
+)
+
+const (
+	Apps           = "apps"
+	Pools          = "pools"
+	PoolRegistries = "pool_registries"
+	Tokens         = "tokens"
+
+	defaultTTL = 30 * time.Second
+	// holdTTL limits how long an entry is bypassed when the event holding
+	// it never finishes.
+	holdTTL = time.Hour
+)
+
+var (
+	cacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_cache_hits_total",
+		Help: "The total number of reads served by the read cache",
+	}, []string{"collection"})
+
+	cacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_cache_misses_total",
+		Help: "The total number of reads not served by the read cache",
+	}, []string{"collection"})
+
+	cacheErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tsuru_cache_errors_total",
+		Help: "The total number of errors accessing the read cache backend",
+	}, []string{"collection"})
+)
+
+func init() {
+	prometheus.MustRegister(cacheHits, cacheMisses, cacheErrors)
+}
+
+type backend interface {
+	get(key string) ([]byte, bool, error)
+	set(key string, value []byte, ttl time.Duration) error
+	del(keys ...string) error
+	// hold increments the number of holders of key, entries with holders
+	// are neither read from nor written to the cache.
+	hold(key string) error
+	// release decrements the number of holders of key.
+	release(key string) error
+	held(key string) (bool, error)
+}
+
+type settings struct {
+	disabled bool
+	ttl      time.Duration
+}
+
+var (
+	mu       sync.RWMutex
+	current  backend
+	defaults = settings{ttl: defaultTTL}
+	byColl   = map[string]settings{}
+)
+
+// Initialize sets up the backend in cache:backend, "memory" or "redis". The
+// cache is disabled when no backend is set.
+func Initialize() error {
+	name, _ := config.GetString("cache:backend")
+	var b backend
+	switch name {
+	case "":
+	case "memory":
+		maxEntries, err := config.GetInt("cache:memory-max-entries")
+		if err != nil {
+			maxEntries = defaultMaxEntries
+		}
+		b = newMemoryBackend(maxEntries)
+	case "redis":
+		var err error
+		b, err = newRedisBackend()
+		if err != nil {
+			return errors.Wrap(err, "unable to connect to the cache redis")
+		}
+	default:
+		return errors.Errorf("invalid cache backend %q, must be memory or redis", name)
+	}
+	ttl := defaultTTL
+	if value, err := config.GetDuration("cache:ttl"); err == nil && value > 0 {
+		ttl = value
+	}
+	collections := map[string]settings{}
+	for _, coll := range []string{Apps, Pools, PoolRegistries, Tokens} {
+		s := settings{ttl: ttl}
+		s.disabled, _ = config.GetBool("cache:" + coll + ":disabled")
+		if value, err := config.GetDuration("cache:" + coll + ":ttl"); err == nil && value > 0 {
+			s.ttl = value
+		}
+		collections[coll] = s
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	current = b
+	defaults = settings{ttl: ttl}
+	byColl = collections
+	return nil
+}
+
+func backendFor(collection string) (backend, settings) {
+	mu.RLock()
+	defer mu.RUnlock()
+	s, ok := byColl[collection]
+	if !ok {
+		s = defaults
+	}
+	if current == nil || s.disabled {
+		return nil, s
+	}
+	return current, s
+}
+
+func cacheKey(collection, key string) string {
+	return collection + ":" + key
+}
+
+// Fetch reads the document cached for key in the collection into dest, a
+// pointer. On misses, or when the cache is disabled for the collection, load
+// is called to read the document from the database into dest, which is then
+// cached. Errors accessing the cache are logged and handled as misses.
+func Fetch(collection, key string, dest interface{}, load func() error) error {
+	b, s := backendFor(collection)
+	if b == nil {
+		return load()
+	}
+	k := cacheKey(collection, key)
+	held, err := b.held(k)
+	if err != nil {
+		logError(collection, "check", err)
+		held = true
+	}
+	if !held {
+		data, ok, err := b.get(k)
+		if err != nil {
+			logError(collection, "read", err)
+		} else if ok {
+			err = decode(data, dest)
+			if err == nil {
+				cacheHits.WithLabelValues(collection).Inc()
+				return nil
+			}
+			logError(collection, "decode", err)
+		}
+	}
+	cacheMisses.WithLabelValues(collection).Inc()
+	err = load()
+	if err != nil || held {
+		return err
+	}
+	data, err := encode(dest)
+	if err != nil {
+		logError(collection, "encode", err)
+		return nil
+	}
+	err = b.set(k, data, s.ttl)
+	if err != nil {
+		logError(collection, "write", err)
+	}
+	return nil
+}
+
+// Invalidate removes the documents cached for the keys in the collection.
+func Invalidate(collection string, keys ...string) {
+	b, _ := backendFor(collection)
+	if b == nil || len(keys) == 0 {
+		return
+	}
+	cacheKeys := make([]string, len(keys))
+	for i, key := range keys {
+		cacheKeys[i] = cacheKey(collection, key)
+	}
+	err := b.del(cacheKeys...)
+	if err != nil {
+		logError(collection, "invalidate", err)
+	}
+}
+
+// Hold invalidates the document cached for key in the collection and
+// bypasses the cache for it until Release is called.
+func Hold(collection, key string) {
+	b, _ := backendFor(collection)
+	if b == nil {
+		return
+	}
+	k := cacheKey(collection, key)
+	err := b.hold(k)
+	if err != nil {
+		logError(collection, "hold", err)
+	}
+	err = b.del(k)
+	if err != nil {
+		logError(collection, "invalidate", err)
+	}
+}
+
+// Release undoes a previous call to Hold, invalidating the document cached
+// for key in the collection.
+func Release(collection, key string) {
+	b, _ := backendFor(collection)
+	if b == nil {
+		return
+	}
+	k := cacheKey(collection, key)
+	err := b.release(k)
+	if err != nil {
+		logError(collection, "release", err)
+	}
+	err = b.del(k)
+	if err != nil {
+		logError(collection, "invalidate", err)
+	}
+}
+
+func logError(collection, op string, err error) {
+	cacheErrors.WithLabelValues(collection).Inc()
+	log.Errorf("[read cache] unable to %s %s entry: %s", op, collection, err)
+}
+
+// encode wraps the value in a document, as bson requires documents at the
+// top level and slices are cached as well.
+func encode(value interface{}) ([]byte, error) {
+	return bson.Marshal(bson.M{"v": value})
+}
+
+func decode(data []byte, dest interface{}) error {
+	var doc struct {
+		V bson.Raw `bson:"v"`
+	}
+	err := bson.Unmarshal(data, &doc)
+	if err != nil {
+		return err
+	}
+	return doc.V.Unmarshal(dest)
+}
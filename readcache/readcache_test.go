@@ -0,0 +1,195 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+type doc struct {
+	Name  string
+	Count int
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	config.Set("cache:backend", "memory")
+	err := Initialize()
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("cache")
+	err := Initialize()
+	c.Assert(err, check.IsNil)
+}
+
+func loader(calls *int, value doc, dest *doc) func() error {
+	return func() error {
+		*calls++
+		*dest = value
+		return nil
+	}
+}
+
+func (s *S) TestFetchCachesLoadedDocument(c *check.C) {
+	hits := testutil.ToFloat64(cacheHits.WithLabelValues(Apps))
+	misses := testutil.ToFloat64(cacheMisses.WithLabelValues(Apps))
+	var calls int
+	var d doc
+	err := Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp", Count: 1}, &d))
+	c.Assert(err, check.IsNil)
+	c.Assert(d, check.DeepEquals, doc{Name: "myapp", Count: 1})
+	var cached doc
+	err = Fetch(Apps, "myapp", &cached, loader(&calls, doc{Name: "myapp", Count: 2}, &cached))
+	c.Assert(err, check.IsNil)
+	c.Assert(cached, check.DeepEquals, doc{Name: "myapp", Count: 1})
+	c.Assert(calls, check.Equals, 1)
+	c.Assert(testutil.ToFloat64(cacheHits.WithLabelValues(Apps))-hits, check.Equals, float64(1))
+	c.Assert(testutil.ToFloat64(cacheMisses.WithLabelValues(Apps))-misses, check.Equals, float64(1))
+}
+
+func (s *S) TestFetchCachesSlices(c *check.C) {
+	var calls int
+	load := func(dest *[]doc) func() error {
+		return func() error {
+			calls++
+			*dest = []doc{{Name: "p1"}, {Name: "p2"}}
+			return nil
+		}
+	}
+	var pools []doc
+	err := Fetch(Pools, "*", &pools, load(&pools))
+	c.Assert(err, check.IsNil)
+	var cached []doc
+	err = Fetch(Pools, "*", &cached, load(&cached))
+	c.Assert(err, check.IsNil)
+	c.Assert(cached, check.DeepEquals, []doc{{Name: "p1"}, {Name: "p2"}})
+	c.Assert(calls, check.Equals, 1)
+}
+
+func (s *S) TestFetchDoesNotCacheErrors(c *check.C) {
+	var calls int
+	notFound := errors.New("not found")
+	var d doc
+	for i := 0; i < 2; i++ {
+		err := Fetch(Apps, "myapp", &d, func() error {
+			calls++
+			return notFound
+		})
+		c.Assert(err, check.Equals, notFound)
+	}
+	c.Assert(calls, check.Equals, 2)
+}
+
+func (s *S) TestFetchDisabledBackend(c *check.C) {
+	config.Unset("cache")
+	err := Initialize()
+	c.Assert(err, check.IsNil)
+	var calls int
+	var d doc
+	for i := 0; i < 2; i++ {
+		err = Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp"}, &d))
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(calls, check.Equals, 2)
+}
+
+func (s *S) TestFetchDisabledCollection(c *check.C) {
+	config.Set("cache:tokens:disabled", true)
+	err := Initialize()
+	c.Assert(err, check.IsNil)
+	var tokenCalls, appCalls int
+	var d doc
+	for i := 0; i < 2; i++ {
+		err = Fetch(Tokens, "abc", &d, loader(&tokenCalls, doc{Name: "abc"}, &d))
+		c.Assert(err, check.IsNil)
+		err = Fetch(Apps, "myapp", &d, loader(&appCalls, doc{Name: "myapp"}, &d))
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(tokenCalls, check.Equals, 2)
+	c.Assert(appCalls, check.Equals, 1)
+}
+
+func (s *S) TestFetchExpiredEntry(c *check.C) {
+	config.Set("cache:apps:ttl", "10ms")
+	err := Initialize()
+	c.Assert(err, check.IsNil)
+	var calls int
+	var d doc
+	err = Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp"}, &d))
+	c.Assert(err, check.IsNil)
+	time.Sleep(20 * time.Millisecond)
+	err = Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp"}, &d))
+	c.Assert(err, check.IsNil)
+	c.Assert(calls, check.Equals, 2)
+}
+
+func (s *S) TestInvalidate(c *check.C) {
+	var calls int
+	var d doc
+	err := Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp", Count: 1}, &d))
+	c.Assert(err, check.IsNil)
+	Invalidate(Apps, "myapp")
+	err = Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp", Count: 2}, &d))
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Count, check.Equals, 2)
+	c.Assert(calls, check.Equals, 2)
+}
+
+func (s *S) TestHoldBypassesCache(c *check.C) {
+	var calls int
+	var d doc
+	err := Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp", Count: 1}, &d))
+	c.Assert(err, check.IsNil)
+	Hold(Apps, "myapp")
+	Hold(Apps, "myapp")
+	for i := 0; i < 2; i++ {
+		err = Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp", Count: 2}, &d))
+		c.Assert(err, check.IsNil)
+		c.Assert(d.Count, check.Equals, 2)
+	}
+	c.Assert(calls, check.Equals, 3)
+	Release(Apps, "myapp")
+	err = Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp", Count: 3}, &d))
+	c.Assert(err, check.IsNil)
+	c.Assert(calls, check.Equals, 4)
+	Release(Apps, "myapp")
+	err = Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp", Count: 4}, &d))
+	c.Assert(err, check.IsNil)
+	err = Fetch(Apps, "myapp", &d, loader(&calls, doc{Name: "myapp", Count: 5}, &d))
+	c.Assert(err, check.IsNil)
+	c.Assert(d.Count, check.Equals, 4)
+	c.Assert(calls, check.Equals, 5)
+}
+
+func (s *S) TestInitializeInvalidBackend(c *check.C) {
+	config.Set("cache:backend", "memcached")
+	err := Initialize()
+	c.Assert(err, check.ErrorMatches, `invalid cache backend "memcached", must be memory or redis`)
+}
+
+func (s *S) TestMemoryBackendEvicts(c *check.C) {
+	b := newMemoryBackend(2)
+	for _, key := range []string{"a", "b", "c"} {
+		err := b.set(key, []byte(key), time.Minute)
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(b.entries, check.HasLen, 2)
+	_, ok, err := b.get("c")
+	c.Assert(err, check.IsNil)
+	c.Assert(ok, check.Equals, true)
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readcache
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	tsuruRedis "github.com/tsuru/tsuru/redis"
+	"github.com/tsuru/tsuru/router"
+	redis "gopkg.in/redis.v3"
+)
+
+const (
+	redisKeyPrefix  = "tsuru:cache:"
+	redisHoldPrefix = "tsuru:cache-hold:"
+)
+
+type redisClient interface {
+	Get(key string) *redis.StringCmd
+	Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(keys ...string) *redis.IntCmd
+	Incr(key string) *redis.IntCmd
+	Decr(key string) *redis.IntCmd
+	Expire(key string, expiration time.Duration) *redis.BoolCmd
+}
+
+type redisBackend struct {
+	client redisClient
+}
+
+// newRedisBackend connects to the redis server configured with the cache
+// prefix, like cache:redis-server, as done by the other redis clients.
+func newRedisBackend() (*redisBackend, error) {
+	client, err := tsuruRedis.NewRedisDefaultConfig("cache", router.ConfigGetterFromPrefix("cache"), nil)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := client.(redisClient)
+	if !ok {
+		return nil, errors.New("unsupported redis client")
+	}
+	return &redisBackend{client: c}, nil
+}
+
+func (b *redisBackend) get(key string) ([]byte, bool, error) {
+	data, err := b.client.Get(redisKeyPrefix + key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (b *redisBackend) set(key string, value []byte, ttl time.Duration) error {
+	return b.client.Set(redisKeyPrefix+key, value, ttl).Err()
+}
+
+func (b *redisBackend) del(keys ...string) error {
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = redisKeyPrefix + key
+	}
+	return b.client.Del(redisKeys...).Err()
+}
+
+func (b *redisBackend) hold(key string) error {
+	err := b.client.Incr(redisHoldPrefix + key).Err()
+	if err != nil {
+		return err
+	}
+	return b.client.Expire(redisHoldPrefix+key, holdTTL).Err()
+}
+
+func (b *redisBackend) release(key string) error {
+	count, err := b.client.Decr(redisHoldPrefix + key).Result()
+	if err != nil {
+		return err
+	}
+	if count <= 0 {
+		return b.client.Del(redisHoldPrefix + key).Err()
+	}
+	return nil
+}
+
+func (b *redisBackend) held(key string) (bool, error) {
+	count, err := b.client.Get(redisHoldPrefix + key).Int64()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
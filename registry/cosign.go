@@ -0,0 +1,230 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/app/image"
+)
+
+const (
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	cosignSignatureType       = "cosign container image signature"
+
+	manifestAcceptHeader = "application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.index.v1+json, application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+var (
+	ErrSignatureNotFound     = errors.New("no cosign signature found for image")
+	ErrNoValidSignature      = errors.New("no valid cosign signature from a trusted key found for image")
+	ErrUnsupportedPublicKey  = errors.New("unsupported public key type, only ECDSA, RSA and Ed25519 keys are supported")
+	ErrInvalidPublicKeyBlock = errors.New("invalid public key, expected a PEM encoded PUBLIC KEY block")
+)
+
+type signatureManifest struct {
+	Layers []struct {
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"layers"`
+}
+
+type simpleSigningPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// ParsePublicKey parses a PEM encoded public key, as generated by
+// "cosign generate-key-pair".
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, ErrInvalidPublicKeyBlock
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse public key")
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	}
+	return nil, ErrUnsupportedPublicKey
+}
+
+func verifySignature(key crypto.PublicKey, payload, signature []byte) bool {
+	digest := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], signature)
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil {
+			return true
+		}
+		return rsa.VerifyPSS(k, crypto.SHA256, digest[:], signature, nil) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, signature)
+	}
+	return false
+}
+
+// VerifyCosignSignature checks whether the image has at least one cosign
+// signature, stored in the same repository as the image, that is valid for
+// one of the given public keys. It returns the digest of the verified
+// manifest, which must be used to reference the image from then on, as the
+// tag may be moved to another manifest after the verification. Images stored
+// in the registry of the pool are read with the pool registry credentials.
+func VerifyCosignSignature(ctx context.Context, pool, imageName string, keys []crypto.PublicKey) (string, error) {
+	reg, err := PoolRegistryForImage(pool, imageName)
+	if err != nil {
+		return "", err
+	}
+	return verifyCosignSignature(ctx, reg, imageName, keys)
+}
+
+func verifyCosignSignature(ctx context.Context, poolRegistry *PoolRegistry, imageName string, keys []crypto.PublicKey) (string, error) {
+	registry, repository, tag := image.ParseImageParts(imageName)
+	if idx := strings.Index(repository, "@"); idx != -1 {
+		tag = repository[idx+1:] + ":" + tag
+		repository = repository[:idx]
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+	if registry == "" {
+		registry, _ = config.GetString("docker:registry")
+	}
+	if registry == "" {
+		registry = "registry-1.docker.io"
+		if !strings.Contains(repository, "/") {
+			repository = "library/" + repository
+		}
+	}
+	r := &dockerRegistry{server: registry}
+	if poolRegistry != nil {
+		authConfig := poolRegistry.AuthConfig()
+		r.authConfig = &authConfig
+	}
+	digest := tag
+	if !strings.HasPrefix(tag, "sha256:") {
+		var err error
+		digest, err = r.getManifestDigest(ctx, repository, tag)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to get digest for image %s", imageName)
+		}
+	}
+	signatureTag := strings.Replace(digest, ":", "-", 1) + ".sig"
+	var manifest signatureManifest
+	err := r.getJSON(ctx, fmt.Sprintf("/v2/%s/manifests/%s", repository, signatureTag), manifestAcceptHeader, &manifest)
+	if err != nil {
+		if err == ErrImageNotFound {
+			return "", ErrSignatureNotFound
+		}
+		return "", errors.Wrapf(err, "unable to get signatures for image %s", imageName)
+	}
+	for _, layer := range manifest.Layers {
+		encodedSignature, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+		if err != nil {
+			continue
+		}
+		payload, err := r.getBlob(ctx, repository, layer.Digest)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to get signature payload for image %s", imageName)
+		}
+		var signed simpleSigningPayload
+		err = json.Unmarshal(payload, &signed)
+		if err != nil || signed.Critical.Type != cosignSignatureType || signed.Critical.Image.DockerManifestDigest != digest {
+			continue
+		}
+		for _, key := range keys {
+			if verifySignature(key, payload, signature) {
+				return digest, nil
+			}
+		}
+	}
+	return "", ErrNoValidSignature
+}
+
+func (r *dockerRegistry) getManifestDigest(ctx context.Context, repository, reference string) (string, error) {
+	path := fmt.Sprintf("/v2/%s/manifests/%s", repository, reference)
+	resp, err := r.doRequest(ctx, http.MethodHead, path, map[string]string{"Accept": manifestAcceptHeader})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrImageNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", errors.Errorf("invalid status reading manifest for %v:%v: %v", repository, reference, resp.StatusCode)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", ErrDigestNotFound
+	}
+	return digest, nil
+}
+
+func (r *dockerRegistry) getJSON(ctx context.Context, path, accept string, result interface{}) error {
+	resp, err := r.doRequest(ctx, http.MethodGet, path, map[string]string{"Accept": accept})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrImageNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("invalid status code requesting %s: %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (r *dockerRegistry) getBlob(ctx context.Context, repository, digest string) ([]byte, error) {
+	resp, err := r.doRequest(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/blobs/%s", repository, digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Errorf("invalid status code reading blob %s: %d", digest, resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if "sha256:"+hex.EncodeToString(sum[:]) != digest {
+		return nil, errors.Errorf("blob content does not match digest %s", digest)
+	}
+	return data, nil
+}
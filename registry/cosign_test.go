@@ -0,0 +1,151 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+const fakeImageDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func signedRegistryServer(c *check.C, key *ecdsa.PrivateKey, manifestDigest string) *httptest.Server {
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"tsuru/app"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, manifestDigest))
+	sum := sha256.Sum256(payload)
+	payloadDigest := "sha256:" + hex.EncodeToString(sum[:])
+	signature, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	c.Assert(err, check.IsNil)
+	sigTag := strings.Replace(fakeImageDigest, ":", "-", 1) + ".sig"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/tsuru/app/manifests/v1":
+			w.Header().Set("Docker-Content-Digest", fakeImageDigest)
+		case "/v2/tsuru/app/manifests/" + sigTag:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"layers": []map[string]interface{}{{
+					"digest":      payloadDigest,
+					"annotations": map[string]string{"dev.cosignproject.cosign/signature": base64.StdEncoding.EncodeToString(signature)},
+				}},
+			})
+		case "/v2/tsuru/app/blobs/" + payloadDigest:
+			w.Write(payload)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func generateECDSAKey(c *check.C) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	c.Assert(err, check.IsNil)
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func (s *S) TestParsePublicKey(c *check.C) {
+	_, pemData := generateECDSAKey(c)
+	key, err := ParsePublicKey(pemData)
+	c.Assert(err, check.IsNil)
+	c.Assert(key, check.FitsTypeOf, &ecdsa.PublicKey{})
+	_, err = ParsePublicKey([]byte("not a key"))
+	c.Assert(err, check.Equals, ErrInvalidPublicKeyBlock)
+}
+
+func (s *S) TestVerifyCosignSignature(c *check.C) {
+	key, pemData := generateECDSAKey(c)
+	srv := signedRegistryServer(c, key, fakeImageDigest)
+	defer srv.Close()
+	pubKey, err := ParsePublicKey(pemData)
+	c.Assert(err, check.IsNil)
+	imageName := strings.TrimPrefix(srv.URL, "http://") + "/tsuru/app:v1"
+	digest, err := VerifyCosignSignature(context.TODO(), "", imageName, []crypto.PublicKey{pubKey})
+	c.Assert(err, check.IsNil)
+	c.Assert(digest, check.Equals, fakeImageDigest)
+}
+
+func (s *S) TestVerifyCosignSignaturePoolRegistry(c *check.C) {
+	key, pemData := generateECDSAKey(c)
+	srv := signedRegistryServer(c, key, fakeImageDigest)
+	defer srv.Close()
+	authSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "pool-user" || pass != "pool-pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer authSrv.Close()
+	config.Set("docker:registry-auth:username", "global-user")
+	config.Set("docker:registry-auth:password", "global-pass")
+	defer config.Unset("docker:registry-auth")
+	pubKey, err := ParsePublicKey(pemData)
+	c.Assert(err, check.IsNil)
+	address := strings.TrimPrefix(authSrv.URL, "http://")
+	imageName := address + "/tsuru/app:v1"
+	reg := &PoolRegistry{Pool: "pool1", Address: address, Username: "pool-user", Password: "pool-pass"}
+	digest, err := verifyCosignSignature(context.TODO(), reg, imageName, []crypto.PublicKey{pubKey})
+	c.Assert(err, check.IsNil)
+	c.Assert(digest, check.Equals, fakeImageDigest)
+	_, err = verifyCosignSignature(context.TODO(), nil, imageName, []crypto.PublicKey{pubKey})
+	c.Assert(err, check.ErrorMatches, "unable to get digest for image .*: invalid status reading manifest .*: 401")
+}
+
+func (s *S) TestVerifyCosignSignatureUntrustedKey(c *check.C) {
+	key, _ := generateECDSAKey(c)
+	srv := signedRegistryServer(c, key, fakeImageDigest)
+	defer srv.Close()
+	_, otherPEM := generateECDSAKey(c)
+	otherKey, err := ParsePublicKey(otherPEM)
+	c.Assert(err, check.IsNil)
+	imageName := strings.TrimPrefix(srv.URL, "http://") + "/tsuru/app:v1"
+	_, err = VerifyCosignSignature(context.TODO(), "", imageName, []crypto.PublicKey{otherKey})
+	c.Assert(err, check.Equals, ErrNoValidSignature)
+}
+
+func (s *S) TestVerifyCosignSignatureDigestMismatch(c *check.C) {
+	key, pemData := generateECDSAKey(c)
+	srv := signedRegistryServer(c, key, "sha256:ffff")
+	defer srv.Close()
+	pubKey, err := ParsePublicKey(pemData)
+	c.Assert(err, check.IsNil)
+	imageName := strings.TrimPrefix(srv.URL, "http://") + "/tsuru/app:v1"
+	_, err = VerifyCosignSignature(context.TODO(), "", imageName, []crypto.PublicKey{pubKey})
+	c.Assert(err, check.Equals, ErrNoValidSignature)
+}
+
+func (s *S) TestVerifyCosignSignatureNotSigned(c *check.C) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/tsuru/app/manifests/v1" {
+			w.Header().Set("Docker-Content-Digest", fakeImageDigest)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	_, pemData := generateECDSAKey(c)
+	pubKey, err := ParsePublicKey(pemData)
+	c.Assert(err, check.IsNil)
+	imageName := strings.TrimPrefix(srv.URL, "http://") + "/tsuru/app:v1"
+	_, err = VerifyCosignSignature(context.TODO(), "", imageName, []crypto.PublicKey{pubKey})
+	c.Assert(err, check.Equals, ErrSignatureNotFound)
+}
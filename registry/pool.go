@@ -0,0 +1,296 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/globalsign/mgo"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/db"
+	dbStorage "github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/readcache"
+	"github.com/tsuru/tsuru/secret"
+	imgTypes "github.com/tsuru/tsuru/types/app/image"
+)
+
+var (
+	ErrPoolRegistryNotFound       = errors.New("pool has no registry configured")
+	ErrPoolRegistryAddressMissing = errors.New("registry address is required")
+)
+
+// PoolRegistryConflictError is returned when the images of a pool registry
+// would be named like the images of another registry.
+type PoolRegistryConflictError struct {
+	Registry string
+}
+
+func (e *PoolRegistryConflictError) Error() string {
+	return fmt.Sprintf("registry address and namespace overlap with the images of %s", e.Registry)
+}
+
+// PoolRegistry is the docker registry used to store the images of apps deployed
+// in a pool, overriding the global docker:registry setting. Its password is
+// never exposed by the API and is stored encrypted when secrets:encryption-key
+// is set.
+type PoolRegistry struct {
+	Pool              string `json:"pool" bson:"_id" form:"-"`
+	Address           string `json:"address" form:"address"`
+	Namespace         string `json:"namespace,omitempty" form:"namespace"`
+	Username          string `json:"username,omitempty" form:"username"`
+	Password          string `json:"-" bson:",omitempty" form:"password"`
+	EncryptedPassword []byte `json:"-" bson:",omitempty" form:"-"`
+	Email             string `json:"email,omitempty" form:"email"`
+	Mirror            string `json:"mirror,omitempty" form:"mirror"`
+}
+
+// ImageRegistry returns the prefix used when naming images pushed to this
+// registry.
+func (r *PoolRegistry) ImageRegistry() imgTypes.ImageRegistry {
+	if r.Namespace == "" {
+		return imgTypes.ImageRegistry(r.Address)
+	}
+	return imgTypes.ImageRegistry(r.Address + "/" + r.Namespace)
+}
+
+// AuthConfig returns the credentials used to push and pull images from this
+// registry.
+func (r *PoolRegistry) AuthConfig() docker.AuthConfiguration {
+	return docker.AuthConfiguration{
+		ServerAddress: r.Address,
+		Username:      r.Username,
+		Password:      r.Password,
+		Email:         r.Email,
+	}
+}
+
+// MirrorAuthConfig returns the credentials used to pull images from the
+// mirror of this registry, which proxies the registry with the same
+// credentials.
+func (r *PoolRegistry) MirrorAuthConfig() docker.AuthConfiguration {
+	authConfig := r.AuthConfig()
+	authConfig.ServerAddress = r.Mirror
+	return authConfig
+}
+
+func (r *PoolRegistry) matchesImage(image string) bool {
+	return strings.HasPrefix(image, string(r.ImageRegistry())+"/")
+}
+
+// overlaps reports whether the images stored under one of the prefixes may
+// also be named under the other, so that their credentials would be mixed.
+func overlaps(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// globalImageRegistry returns the prefix of the images stored in the global
+// registry, as named by the provisioners.
+func globalImageRegistry() string {
+	addr, _ := config.GetString("docker:registry")
+	if addr == "" {
+		return ""
+	}
+	namespace, _ := config.GetString("docker:repository-namespace")
+	if namespace == "" {
+		namespace = "tsuru"
+	}
+	return strings.TrimSuffix(addr, "/") + "/" + strings.Trim(namespace, "/")
+}
+
+// checkConflicts ensures that the images of the registry can't be mistaken
+// for images of the global registry or of the registry of another pool.
+func (r *PoolRegistry) checkConflicts() error {
+	prefix := string(r.ImageRegistry())
+	if overlaps(prefix, globalImageRegistry()) {
+		return &PoolRegistryConflictError{Registry: "the global registry"}
+	}
+	regs, err := storedPoolRegistries()
+	if err != nil {
+		return err
+	}
+	for _, other := range regs {
+		if other.Pool != r.Pool && overlaps(prefix, string(other.ImageRegistry())) {
+			return &PoolRegistryConflictError{Registry: fmt.Sprintf("the registry of pool %q", other.Pool)}
+		}
+	}
+	return nil
+}
+
+// protectPassword moves the password to EncryptedPassword, when an
+// encryption key is configured.
+func (r *PoolRegistry) protectPassword() error {
+	r.EncryptedPassword = nil
+	if r.Password == "" || !secret.Enabled() {
+		return nil
+	}
+	encrypted, err := secret.Encrypt([]byte(r.Password))
+	if err != nil {
+		return errors.Wrapf(err, "unable to encrypt the registry password of pool %q", r.Pool)
+	}
+	r.Password = ""
+	r.EncryptedPassword = encrypted
+	return nil
+}
+
+// revealPassword reverts protectPassword.
+func (r *PoolRegistry) revealPassword() error {
+	if len(r.EncryptedPassword) == 0 {
+		return nil
+	}
+	password, err := secret.Decrypt(r.EncryptedPassword)
+	if err != nil {
+		return errors.Wrapf(err, "unable to decrypt the registry password of pool %q", r.Pool)
+	}
+	r.Password = string(password)
+	r.EncryptedPassword = nil
+	return nil
+}
+
+func poolRegistriesCollection(conn *db.Storage) *dbStorage.Collection {
+	return conn.Collection("pool_registries")
+}
+
+// allRegistriesKey is the read cache key of the list of all pool registries.
+const allRegistriesKey = "*"
+
+// storedPoolRegistries returns the registries as stored in the database, with
+// their passwords still encrypted. They're cached, as the registries are read
+// on every image pull and push.
+func storedPoolRegistries() ([]PoolRegistry, error) {
+	var regs []PoolRegistry
+	err := readcache.Fetch(readcache.PoolRegistries, allRegistriesKey, &regs, func() error {
+		conn, err := db.Conn()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return poolRegistriesCollection(conn).Find(nil).All(&regs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return regs, nil
+}
+
+// SetPoolRegistry configures the registry used by the pool, replacing any
+// previous configuration. An empty password keeps the stored one, so
+// non-secret fields may be updated without sending the credentials again.
+func SetPoolRegistry(poolName string, reg PoolRegistry) error {
+	reg.Pool = poolName
+	reg.Address = strings.TrimSuffix(reg.Address, "/")
+	reg.Namespace = strings.Trim(reg.Namespace, "/")
+	reg.Mirror = strings.TrimSuffix(reg.Mirror, "/")
+	if reg.Address == "" {
+		return ErrPoolRegistryAddressMissing
+	}
+	err := reg.checkConflicts()
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if reg.Password == "" {
+		var current PoolRegistry
+		err = poolRegistriesCollection(conn).FindId(poolName).One(&current)
+		if err != nil && err != mgo.ErrNotFound {
+			return err
+		}
+		if current.Address == reg.Address && current.Username == reg.Username {
+			err = current.revealPassword()
+			if err != nil {
+				return err
+			}
+			reg.Password = current.Password
+		}
+	}
+	err = reg.protectPassword()
+	if err != nil {
+		return err
+	}
+	defer readcache.Invalidate(readcache.PoolRegistries, allRegistriesKey)
+	_, err = poolRegistriesCollection(conn).UpsertId(poolName, reg)
+	return err
+}
+
+// GetPoolRegistry returns the registry configured for the pool.
+func GetPoolRegistry(poolName string) (*PoolRegistry, error) {
+	regs, err := storedPoolRegistries()
+	if err != nil {
+		return nil, err
+	}
+	for i := range regs {
+		if regs[i].Pool != poolName {
+			continue
+		}
+		err = regs[i].revealPassword()
+		if err != nil {
+			return nil, err
+		}
+		return &regs[i], nil
+	}
+	return nil, ErrPoolRegistryNotFound
+}
+
+// ListPoolRegistries returns the registries configured for all pools.
+func ListPoolRegistries() ([]PoolRegistry, error) {
+	regs, err := storedPoolRegistries()
+	if err != nil {
+		return nil, err
+	}
+	for i := range regs {
+		err = regs[i].revealPassword()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return regs, nil
+}
+
+// RemovePoolRegistry removes the registry configured for the pool, making it
+// fall back to the global registry.
+func RemovePoolRegistry(poolName string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	defer readcache.Invalidate(readcache.PoolRegistries, allRegistriesKey)
+	err = poolRegistriesCollection(conn).RemoveId(poolName)
+	if err == mgo.ErrNotFound {
+		return ErrPoolRegistryNotFound
+	}
+	return err
+}
+
+// PoolRegistryForImage returns the registry configured for the pool when the
+// image is stored in it. It returns nil when the pool has no registry or the
+// image belongs to another registry, in which case the global registry
+// settings apply. Registries of other pools are never considered, so the
+// credentials of a pool are only used for images of that pool.
+func PoolRegistryForImage(poolName, image string) (*PoolRegistry, error) {
+	if poolName == "" {
+		return nil, nil
+	}
+	reg, err := GetPoolRegistry(poolName)
+	if err == ErrPoolRegistryNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !reg.matchesImage(image) {
+		return nil, nil
+	}
+	return reg, nil
+}
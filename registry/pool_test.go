@@ -0,0 +1,92 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package registry
+
+import (
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/tsuru/config"
+	imgTypes "github.com/tsuru/tsuru/types/app/image"
+	check "gopkg.in/check.v1"
+)
+
+func (s *S) TestPoolRegistryImageRegistry(c *check.C) {
+	reg := PoolRegistry{Address: "registry.example.com"}
+	c.Assert(reg.ImageRegistry(), check.Equals, imgTypes.ImageRegistry("registry.example.com"))
+	reg.Namespace = "team-a"
+	c.Assert(reg.ImageRegistry(), check.Equals, imgTypes.ImageRegistry("registry.example.com/team-a"))
+}
+
+func (s *S) TestPoolRegistryAuthConfig(c *check.C) {
+	reg := PoolRegistry{Address: "registry.example.com", Namespace: "ns", Username: "user", Password: "pass", Email: "user@example.com"}
+	c.Assert(reg.AuthConfig(), check.DeepEquals, docker.AuthConfiguration{
+		ServerAddress: "registry.example.com",
+		Username:      "user",
+		Password:      "pass",
+		Email:         "user@example.com",
+	})
+}
+
+func (s *S) TestPoolRegistryMatchesImage(c *check.C) {
+	reg := PoolRegistry{Address: "registry.example.com", Namespace: "team-a"}
+	c.Assert(reg.matchesImage("registry.example.com/team-a/app-myapp:v1"), check.Equals, true)
+	c.Assert(reg.matchesImage("registry.example.com/team-ab/app-myapp:v1"), check.Equals, false)
+	c.Assert(reg.matchesImage("registry.example.com/app-myapp:v1"), check.Equals, false)
+	c.Assert(reg.matchesImage("other.example.com/team-a/app-myapp:v1"), check.Equals, false)
+}
+
+func (s *S) TestPoolRegistryOverlaps(c *check.C) {
+	c.Assert(overlaps("registry.example.com", "registry.example.com/tsuru"), check.Equals, true)
+	c.Assert(overlaps("registry.example.com/tsuru", "registry.example.com"), check.Equals, true)
+	c.Assert(overlaps("registry.example.com/team-a", "registry.example.com/team-a"), check.Equals, true)
+	c.Assert(overlaps("registry.example.com/team-a", "registry.example.com/team-ab"), check.Equals, false)
+	c.Assert(overlaps("registry.example.com/team-a", "registry.example.com/tsuru"), check.Equals, false)
+	c.Assert(overlaps("registry.example.com", ""), check.Equals, false)
+}
+
+func (s *S) TestSetPoolRegistryConflictsWithGlobalRegistry(c *check.C) {
+	config.Set("docker:registry", "registry.example.com")
+	defer config.Unset("docker:registry")
+	err := SetPoolRegistry("pool1", PoolRegistry{Address: "registry.example.com", Username: "user", Password: "pass"})
+	c.Assert(err, check.FitsTypeOf, &PoolRegistryConflictError{})
+	c.Assert(err, check.ErrorMatches, "registry address and namespace overlap with the images of the global registry")
+	err = SetPoolRegistry("pool1", PoolRegistry{Address: "registry.example.com", Namespace: "tsuru"})
+	c.Assert(err, check.FitsTypeOf, &PoolRegistryConflictError{})
+}
+
+func (s *S) TestPoolRegistryProtectPassword(c *check.C) {
+	config.Set("secrets:encryption-key", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	defer config.Unset("secrets:encryption-key")
+	reg := PoolRegistry{Pool: "pool1", Address: "registry.example.com", Username: "user", Password: "pass"}
+	err := reg.protectPassword()
+	c.Assert(err, check.IsNil)
+	c.Assert(reg.Password, check.Equals, "")
+	c.Assert(reg.EncryptedPassword, check.Not(check.HasLen), 0)
+	c.Assert(string(reg.EncryptedPassword), check.Not(check.Matches), ".*pass.*")
+	err = reg.revealPassword()
+	c.Assert(err, check.IsNil)
+	c.Assert(reg.Password, check.Equals, "pass")
+	c.Assert(reg.EncryptedPassword, check.IsNil)
+}
+
+func (s *S) TestPoolRegistryProtectPasswordWithoutKey(c *check.C) {
+	reg := PoolRegistry{Pool: "pool1", Address: "registry.example.com", Username: "user", Password: "pass"}
+	err := reg.protectPassword()
+	c.Assert(err, check.IsNil)
+	c.Assert(reg.Password, check.Equals, "pass")
+	c.Assert(reg.EncryptedPassword, check.IsNil)
+	err = reg.revealPassword()
+	c.Assert(err, check.IsNil)
+	c.Assert(reg.Password, check.Equals, "pass")
+}
+
+func (s *S) TestPoolRegistryRevealPasswordWithoutKey(c *check.C) {
+	config.Set("secrets:encryption-key", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	reg := PoolRegistry{Pool: "pool1", Address: "registry.example.com", Password: "pass"}
+	err := reg.protectPassword()
+	config.Unset("secrets:encryption-key")
+	c.Assert(err, check.IsNil)
+	err = reg.revealPassword()
+	c.Assert(err, check.ErrorMatches, `unable to decrypt the registry password of pool "pool1": secrets:encryption-key is not set`)
+}
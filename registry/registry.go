@@ -24,8 +24,9 @@ import (
 )
 
 type dockerRegistry struct {
-	server string
-	client *http.Client
+	server     string
+	client     *http.Client
+	authConfig *docker.AuthConfiguration
 }
 
 var (
@@ -184,7 +185,12 @@ func (r *dockerRegistry) doRequest(ctx context.Context, method, path string, hea
 	if u != nil && u.Host != "" {
 		server = u.Host
 	}
-	authHeaders := registryAuth(server)
+	var authHeaders http.Header
+	if r.authConfig != nil {
+		authHeaders = authConfigHeaders(*r.authConfig)
+	} else {
+		authHeaders = registryAuth(server)
+	}
 	if r.client == nil {
 		r.client, err = tsuruNet.WithProxyFromConfig(*tsuruNet.Dial15Full300ClientNoKeepAlive, server)
 		if err != nil {
@@ -238,9 +244,12 @@ func registryAuth(registry string) http.Header {
 			Password: password,
 		}
 	}
+	return authConfigHeaders(*authConfig)
+}
 
+func authConfigHeaders(authConfig docker.AuthConfiguration) http.Header {
 	headers := http.Header{}
-	if *authConfig == (docker.AuthConfiguration{}) {
+	if authConfig == (docker.AuthConfiguration{}) {
 		return headers
 	}
 
@@ -56,10 +56,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 		{{ range $element -}}
 			{{ index (index $capMap (index $caps .)) 0 }}
 		{{ end -}}
 		}{
+			base,
 			base,
 			base,
 			{{ range $element -}}
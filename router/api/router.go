@@ -15,6 +15,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/log"
@@ -41,6 +42,12 @@ var (
 	_ router.InfoRouter              = &apiRouterWithInfo{}
 	_ router.StatusRouter            = &apiRouterWithStatus{}
 	_ router.PrefixRouter            = &apiRouterWithPrefix{}
+	_ router.RouteRulesRouter        = &apiRouterWithRules{}
+	_ router.PolicyRouter            = &apiRouterWithPolicy{}
+	_ router.ShadowRouter            = &apiRouterWithShadow{}
+	_ router.TrafficRouter           = &apiRouterWithTraffic{}
+	_ router.VisibilityRouter        = &apiRouter{}
+	_ router.OptionalFeaturesRouter  = &apiRouter{}
 )
 
 type apiRouter struct {
@@ -49,9 +56,16 @@ type apiRouter struct {
 	headers    http.Header
 	client     *http.Client
 	supIface   router.Router
+	// features are the optional features supported by the router, which
+	// are not combined in supIface.
+	features []interface{}
 
 	debug        bool
 	multiCluster bool
+	// requestIDHeader is sent to the router in the backend options so it
+	// can inject a request id in requests missing it and propagate it to
+	// the app units.
+	requestIDHeader string
 }
 
 type apiRouterV2 struct{ *apiRouter }
@@ -68,10 +82,20 @@ type apiRouterWithStatus struct{ *apiRouter }
 
 type apiRouterWithPrefix struct{ *apiRouter }
 
+type apiRouterWithRules struct{ *apiRouter }
+
+type apiRouterWithPolicy struct{ *apiRouter }
+
+type apiRouterWithShadow struct{ *apiRouter }
+
+type apiRouterWithTraffic struct{ *apiRouter }
+
 type routesReq struct {
 	Prefix    string            `json:"prefix"`
 	Addresses []string          `json:"addresses"`
 	ExtraData map[string]string `json:"extraData"`
+	Port      string            `json:"port,omitempty"`
+	Hostnames []string          `json:"hostnames,omitempty"`
 }
 
 type routesPrefixReq struct {
@@ -79,6 +103,10 @@ type routesPrefixReq struct {
 	AddressesWithPrefix []routesReq `json:"addressesWithPrefix"`
 }
 
+type routeRulesReq struct {
+	Rules []routerTypes.RouteRule `json:"rules"`
+}
+
 type swapReq struct {
 	Target    string `json:"target"`
 	CnameOnly bool   `json:"cnameOnly"`
@@ -93,6 +121,10 @@ type certData struct {
 	Key         string `json:"key"`
 }
 
+type trafficResp struct {
+	Requests int64 `json:"requests"`
+}
+
 type backendResp struct {
 	Address   string   `json:"address"`
 	Addresses []string `json:"addresses"`
@@ -108,8 +140,12 @@ var (
 	capStatus      = capability("status")
 	capPrefix      = capability("prefix")
 	capV2          = capability("v2")
+	capRules       = capability("rules")
+	capPolicy      = capability("policy")
+	capShadow      = capability("shadow")
+	capTraffic     = capability("traffic")
 
-	allCaps = []capability{capCName, capTLS, capHealthcheck, capInfo, capStatus, capPrefix, capV2}
+	allCaps = []capability{capCName, capTLS, capHealthcheck, capInfo, capStatus, capPrefix, capV2, capRules, capPolicy, capShadow, capTraffic}
 )
 
 func init() {
@@ -123,6 +159,7 @@ func createRouter(routerName string, config router.ConfigGetter) (router.Router,
 	}
 	debug, _ := config.GetBool("debug")
 	multiCluster, _ := config.GetBool("multi-cluster")
+	requestIDHeader, _ := config.GetString("request-id-header")
 	headers, err := headersFromConfig(config)
 	if err != nil {
 		return nil, err
@@ -134,12 +171,40 @@ func createRouter(routerName string, config router.ConfigGetter) (router.Router,
 		debug:      debug,
 		headers:    headers,
 
-		multiCluster: multiCluster,
+		multiCluster:    multiCluster,
+		requestIDHeader: requestIDHeader,
 	}
-	baseRouter.supIface = toSupportedInterface(baseRouter, baseRouter.checkAllCapabilities(context.Background()))
+	supports := baseRouter.checkAllCapabilities(context.Background())
+	baseRouter.features = optionalFeatures(baseRouter, supports)
+	baseRouter.supIface = toSupportedInterface(baseRouter, supports)
 	return baseRouter.supIface, nil
 }
 
+// optionalFeatures returns the implementations of the optional features
+// supported by the router. Unlike the capabilities in toSupportedInterface,
+// they're looked up through router.OptionalFeaturesRouter, so adding a
+// feature doesn't double the generated combinations.
+func optionalFeatures(base *apiRouter, supports map[capability]bool) []interface{} {
+	var features []interface{}
+	if supports[capRules] {
+		features = append(features, &apiRouterWithRules{base})
+	}
+	if supports[capPolicy] {
+		features = append(features, &apiRouterWithPolicy{base})
+	}
+	if supports[capShadow] {
+		features = append(features, &apiRouterWithShadow{base})
+	}
+	if supports[capTraffic] {
+		features = append(features, &apiRouterWithTraffic{base})
+	}
+	return features
+}
+
+func (r *apiRouter) OptionalFeatures() []interface{} {
+	return r.features
+}
+
 func (r *apiRouter) GetName() string {
 	return r.routerName
 }
@@ -166,7 +231,7 @@ func (r *apiRouter) UpdateBackendOpts(ctx context.Context, app router.App, opts
 
 func (r *apiRouter) doBackendOpts(ctx context.Context, app router.App, method string, opts map[string]string) error {
 	path := fmt.Sprintf("backend/%s", app.GetName())
-	b, err := json.Marshal(addDefaultOpts(app, mapStringToMapInterface(opts)))
+	b, err := json.Marshal(r.addDefaultOpts(app, mapStringToMapInterface(opts)))
 	if err != nil {
 		return err
 	}
@@ -348,6 +413,13 @@ func (r *apiRouter) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+func (r *apiRouter) SupportsVisibility(ctx context.Context, visibility string) (bool, error) {
+	if visibility == routerTypes.VisibilityPublic {
+		return true, nil
+	}
+	return r.checkSupports(ctx, "visibility")
+}
+
 func (r *apiRouter) checkSupports(ctx context.Context, feature string) (bool, error) {
 	path := fmt.Sprintf("support/%s", feature)
 	data, statusCode, err := r.do(ctx, http.MethodGet, path, nil, nil)
@@ -415,7 +487,7 @@ func (r *apiRouter) do(ctx context.Context, method, path string, headers http.He
 func (r *apiRouterV2) EnsureBackend(ctx context.Context, app router.App, o router.EnsureBackendOpts) error {
 	path := fmt.Sprintf("backend/%s", app.GetName())
 
-	o.Opts = addDefaultOpts(app, o.Opts)
+	o.Opts = r.addDefaultOpts(app, o.Opts)
 
 	var buf bytes.Buffer
 	err := json.NewEncoder(&buf).Encode(o)
@@ -665,6 +737,8 @@ func (r *apiRouterWithPrefix) RoutesPrefix(ctx context.Context, app router.App)
 			Prefix:    addrData.Prefix,
 			Addresses: urls,
 			ExtraData: addrData.ExtraData,
+			Port:      addrData.Port,
+			Hostnames: addrData.Hostnames,
 		})
 	}
 	return result, nil
@@ -682,6 +756,8 @@ func (r *apiRouter) doRoutesPrefix(ctx context.Context, app router.App, addresse
 	req := &routesReq{
 		Prefix:    addresses.Prefix,
 		ExtraData: addresses.ExtraData,
+		Port:      addresses.Port,
+		Hostnames: addresses.Hostnames,
 	}
 	req.Addresses = make([]string, len(addresses.Addresses))
 	for i := range addresses.Addresses {
@@ -690,7 +766,139 @@ func (r *apiRouter) doRoutesPrefix(ctx context.Context, app router.App, addresse
 	return r.doRoutesReq(ctx, app, req, suffix)
 }
 
-func addDefaultOpts(app router.App, opts map[string]interface{}) map[string]interface{} {
+func (r *apiRouterWithRules) SetRouteRules(ctx context.Context, app router.App, rules []routerTypes.RouteRule) error {
+	backendName, err := router.Retrieve(app.GetName())
+	if err != nil {
+		return err
+	}
+	if rules == nil {
+		rules = []routerTypes.RouteRule{}
+	}
+	b, err := json.Marshal(routeRulesReq{Rules: rules})
+	if err != nil {
+		return err
+	}
+	headers, err := r.getExtraHeadersFromApp(ctx, app)
+	if err != nil {
+		return err
+	}
+	_, code, err := r.do(ctx, http.MethodPut, fmt.Sprintf("backend/%s/rules", backendName), headers, bytes.NewReader(b))
+	if code == http.StatusNotFound {
+		return router.ErrBackendNotFound
+	}
+	return err
+}
+
+func (r *apiRouterWithPolicy) SetPolicy(ctx context.Context, app router.App, policy *routerTypes.Policy) error {
+	backendName, err := router.Retrieve(app.GetName())
+	if err != nil {
+		return err
+	}
+	headers, err := r.getExtraHeadersFromApp(ctx, app)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("backend/%s/policy", backendName)
+	var code int
+	if policy == nil {
+		_, code, err = r.do(ctx, http.MethodDelete, path, headers, nil)
+	} else {
+		var b []byte
+		b, err = json.Marshal(policy)
+		if err != nil {
+			return err
+		}
+		_, code, err = r.do(ctx, http.MethodPut, path, headers, bytes.NewReader(b))
+	}
+	if code == http.StatusNotFound {
+		if policy == nil {
+			return nil
+		}
+		return router.ErrBackendNotFound
+	}
+	return err
+}
+
+func (r *apiRouterWithShadow) SetShadow(ctx context.Context, app router.App, shadow *routerTypes.Shadow) error {
+	backendName, err := router.Retrieve(app.GetName())
+	if err != nil {
+		return err
+	}
+	headers, err := r.getExtraHeadersFromApp(ctx, app)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("backend/%s/shadow", backendName)
+	var code int
+	if shadow == nil {
+		_, code, err = r.do(ctx, http.MethodDelete, path, headers, nil)
+	} else {
+		var b []byte
+		b, err = json.Marshal(shadow)
+		if err != nil {
+			return err
+		}
+		_, code, err = r.do(ctx, http.MethodPut, path, headers, bytes.NewReader(b))
+	}
+	if code == http.StatusNotFound {
+		if shadow == nil {
+			return nil
+		}
+		return router.ErrBackendNotFound
+	}
+	return err
+}
+
+func (r *apiRouterWithShadow) ShadowReport(ctx context.Context, app router.App) (*routerTypes.ShadowReport, error) {
+	backendName, err := router.Retrieve(app.GetName())
+	if err != nil {
+		return nil, err
+	}
+	headers, err := r.getExtraHeadersFromApp(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	data, code, err := r.do(ctx, http.MethodGet, fmt.Sprintf("backend/%s/shadow/report", backendName), headers, nil)
+	if code == http.StatusNotFound {
+		return nil, router.ErrBackendNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report routerTypes.ShadowReport
+	err = json.Unmarshal(data, &report)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse shadow report: %q", string(data))
+	}
+	return &report, nil
+}
+
+func (r *apiRouterWithTraffic) RequestCount(ctx context.Context, app router.App, since time.Time) (int64, error) {
+	backendName, err := router.Retrieve(app.GetName())
+	if err != nil {
+		return 0, err
+	}
+	headers, err := r.getExtraHeadersFromApp(ctx, app)
+	if err != nil {
+		return 0, err
+	}
+	path := fmt.Sprintf("backend/%s/traffic?since=%s", backendName, url.QueryEscape(since.UTC().Format(time.RFC3339)))
+	data, code, err := r.do(ctx, http.MethodGet, path, headers, nil)
+	if code == http.StatusNotFound {
+		return 0, router.ErrBackendNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	var resp trafficResp
+	err = json.Unmarshal(data, &resp)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to parse traffic response: %q", string(data))
+	}
+	return resp.Requests, nil
+}
+
+func (r *apiRouter) addDefaultOpts(app router.App, opts map[string]interface{}) map[string]interface{} {
 	mergedOpts := make(map[string]interface{})
 	for k, v := range opts {
 		mergedOpts[k] = v
@@ -699,6 +907,12 @@ func addDefaultOpts(app router.App, opts map[string]interface{}) map[string]inte
 	mergedOpts[prefix+"app-pool"] = app.GetPool()
 	mergedOpts[prefix+"app-teamowner"] = app.GetTeamOwner()
 	mergedOpts[prefix+"app-teams"] = app.GetTeamsName()
+	if visibility := router.AppVisibility(app); visibility != routerTypes.VisibilityPublic {
+		mergedOpts[prefix+"app-visibility"] = visibility
+	}
+	if r.requestIDHeader != "" {
+		mergedOpts[prefix+"request-id-header"] = r.requestIDHeader
+	}
 	return mergedOpts
 }
 
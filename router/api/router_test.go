@@ -15,6 +15,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/tsuru/config"
@@ -24,6 +25,7 @@ import (
 	"github.com/tsuru/tsuru/router"
 	"github.com/tsuru/tsuru/router/routertest"
 	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	appTypes "github.com/tsuru/tsuru/types/app"
 	provTypes "github.com/tsuru/tsuru/types/provision"
 	routerTypes "github.com/tsuru/tsuru/types/router"
 	check "gopkg.in/check.v1"
@@ -132,6 +134,43 @@ func (s *S) TestAddBackendOpts(c *check.C) {
 	})
 }
 
+func (s *S) TestAddBackendOptsRequestIDHeader(c *check.C) {
+	s.testRouter.requestIDHeader = "X-Request-ID"
+	app := routertest.FakeApp{Name: "new-backend", Pool: "mypool", TeamOwner: "owner"}
+	err := s.testRouter.AddBackendOpts(context.TODO(), app, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["new-backend"].opts["tsuru.io/request-id-header"], check.Equals, "X-Request-ID")
+}
+
+func (s *S) TestAddBackendOptsVisibility(c *check.C) {
+	app := routertest.FakeApp{Name: "new-backend", Pool: "mypool", TeamOwner: "owner", Visibility: routerTypes.VisibilityInternal}
+	err := s.testRouter.AddBackendOpts(context.TODO(), app, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["new-backend"].opts["tsuru.io/app-visibility"], check.Equals, routerTypes.VisibilityInternal)
+	err = s.testRouter.UpdateBackendOpts(context.TODO(), routertest.FakeApp{Name: "new-backend"}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["new-backend"].opts["tsuru.io/app-visibility"], check.IsNil)
+}
+
+func (s *S) TestSupportsVisibility(c *check.C) {
+	supported := map[string]bool{"visibility": true}
+	s.apiRouter.router.HandleFunc("/support/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if !supported[mux.Vars(r)["name"]] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	result, err := s.testRouter.SupportsVisibility(context.TODO(), routerTypes.VisibilityInternal)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.Equals, true)
+	supported = map[string]bool{}
+	result, err = s.testRouter.SupportsVisibility(context.TODO(), routerTypes.VisibilityNone)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.Equals, false)
+	result, err = s.testRouter.SupportsVisibility(context.TODO(), routerTypes.VisibilityPublic)
+	c.Assert(err, check.IsNil)
+	c.Assert(result, check.Equals, true)
+}
+
 func (s *S) TestAddBackendOptsMultiCluster(c *check.C) {
 	s.mockService.ResetCluster()
 	s.mockService.Pool.OnFindByName = func(name string) (*provTypes.Pool, error) {
@@ -457,6 +496,132 @@ func (s *S) TestHealcheckBackendNotFound(c *check.C) {
 	c.Assert(err, check.DeepEquals, router.ErrBackendNotFound)
 }
 
+func (s *S) TestSetRouteRules(c *check.C) {
+	rulesRouter := &apiRouterWithRules{s.testRouter}
+	rules := []routerTypes.RouteRule{
+		{
+			Name:  "canary",
+			Match: routerTypes.RouteMatch{Headers: map[string]string{"X-Canary": "1"}},
+			Backends: []routerTypes.WeightedBackend{
+				{Version: 1, Weight: 90},
+				{Version: 2, Weight: 10},
+			},
+		},
+	}
+	err := rulesRouter.SetRouteRules(context.TODO(), routertest.FakeApp{Name: "mybackend"}, rules)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["mybackend"].rules, check.DeepEquals, rules)
+}
+
+func (s *S) TestSetRouteRulesBackendNotFound(c *check.C) {
+	rulesRouter := &apiRouterWithRules{s.testRouter}
+	err := rulesRouter.SetRouteRules(context.TODO(), routertest.FakeApp{Name: "invalid"}, nil)
+	c.Assert(err, check.DeepEquals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestSetPolicy(c *check.C) {
+	policyRouter := &apiRouterWithPolicy{s.testRouter}
+	policy := &routerTypes.Policy{
+		RateLimit:   &routerTypes.RateLimit{RequestsPerSecond: 10, Burst: 5},
+		DenyCIDRs:   []string{"10.0.0.0/8"},
+		MaxBodySize: 1024,
+	}
+	err := policyRouter.SetPolicy(context.TODO(), routertest.FakeApp{Name: "mybackend"}, policy)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["mybackend"].policy, check.DeepEquals, policy)
+	err = policyRouter.SetPolicy(context.TODO(), routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["mybackend"].policy, check.IsNil)
+}
+
+func (s *S) TestSetPolicyBackendNotFound(c *check.C) {
+	policyRouter := &apiRouterWithPolicy{s.testRouter}
+	err := policyRouter.SetPolicy(context.TODO(), routertest.FakeApp{Name: "invalid"}, &routerTypes.Policy{MaxBodySize: 10})
+	c.Assert(err, check.DeepEquals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestSetShadow(c *check.C) {
+	shadowRouter := &apiRouterWithShadow{s.testRouter}
+	shadow := &routerTypes.Shadow{Version: 2, Percent: 50}
+	err := shadowRouter.SetShadow(context.TODO(), routertest.FakeApp{Name: "mybackend"}, shadow)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["mybackend"].shadow, check.DeepEquals, shadow)
+	err = shadowRouter.SetShadow(context.TODO(), routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["mybackend"].shadow, check.IsNil)
+	err = shadowRouter.SetShadow(context.TODO(), routertest.FakeApp{Name: "invalid"}, shadow)
+	c.Assert(err, check.DeepEquals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestShadowReport(c *check.C) {
+	shadowRouter := &apiRouterWithShadow{s.testRouter}
+	report, err := shadowRouter.ShadowReport(context.TODO(), routertest.FakeApp{Name: "mybackend"})
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.DeepEquals, &routerTypes.ShadowReport{
+		Requests: 10,
+		Primary:  routerTypes.ShadowBackendStats{StatusCodes: map[int]int64{200: 10}, LatencyP50: 12.5, LatencyP99: 40},
+		Shadow:   routerTypes.ShadowBackendStats{StatusCodes: map[int]int64{200: 9, 500: 1}, LatencyP50: 15, LatencyP99: 80},
+	})
+	_, err = shadowRouter.ShadowReport(context.TODO(), routertest.FakeApp{Name: "invalid"})
+	c.Assert(err, check.DeepEquals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestRequestCount(c *check.C) {
+	trafficRouter := &apiRouterWithTraffic{s.testRouter}
+	since := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	count, err := trafficRouter.RequestCount(context.TODO(), routertest.FakeApp{Name: "mybackend"}, since)
+	c.Assert(err, check.IsNil)
+	c.Assert(count, check.Equals, int64(42))
+	c.Assert(s.apiRouter.trafficSince, check.Equals, "2026-05-01T10:00:00Z")
+	_, err = trafficRouter.RequestCount(context.TODO(), routertest.FakeApp{Name: "invalid"}, since)
+	c.Assert(err, check.DeepEquals, router.ErrBackendNotFound)
+}
+
+func (s *S) TestAddRoutesPrefixWithPort(c *check.C) {
+	prefixRouter := &apiRouterWithPrefix{s.testRouter}
+	addr, _ := url.Parse("http://10.0.0.1:32001")
+	err := prefixRouter.AddRoutesPrefix(context.TODO(), routertest.FakeApp{Name: "mybackend"}, appTypes.RoutableAddresses{
+		Prefix:    "grpc.port",
+		Port:      "grpc",
+		Addresses: []*url.URL{addr},
+	}, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(s.apiRouter.backends["mybackend"].prefixAddrs["grpc.port"].Port, check.Equals, "grpc")
+	routes, err := prefixRouter.RoutesPrefix(context.TODO(), routertest.FakeApp{Name: "mybackend"})
+	c.Assert(err, check.IsNil)
+	var found bool
+	for _, r := range routes {
+		if r.Prefix == "grpc.port" {
+			found = true
+			c.Assert(r.Port, check.Equals, "grpc")
+			c.Assert(r.Addresses, check.DeepEquals, []*url.URL{addr})
+		}
+	}
+	c.Assert(found, check.Equals, true)
+}
+
+func (s *S) TestAddRoutesPrefixWithHostnames(c *check.C) {
+	prefixRouter := &apiRouterWithPrefix{s.testRouter}
+	addr, _ := url.Parse("http://10.0.0.1:32002")
+	err := prefixRouter.AddRoutesPrefix(context.TODO(), routertest.FakeApp{Name: "mybackend"}, appTypes.RoutableAddresses{
+		Prefix:    "api.process",
+		Hostnames: []string{"api.example.com"},
+		Addresses: []*url.URL{addr},
+	}, true)
+	c.Assert(err, check.IsNil)
+	routes, err := prefixRouter.RoutesPrefix(context.TODO(), routertest.FakeApp{Name: "mybackend"})
+	c.Assert(err, check.IsNil)
+	var found bool
+	for _, r := range routes {
+		if r.Prefix == "api.process" {
+			found = true
+			c.Assert(r.Hostnames, check.DeepEquals, []string{"api.example.com"})
+			c.Assert(r.Addresses, check.DeepEquals, []*url.URL{addr})
+		}
+	}
+	c.Assert(found, check.Equals, true)
+}
+
 // Router V2 exclusive APIs
 func (s *S) TestEnsureBackend(c *check.C) {
 	routerV2 := &apiRouterV2{s.testRouter}
@@ -546,6 +711,47 @@ func (s *S) TestCreateRouterSupport(c *check.C) {
 	}
 }
 
+func (s *S) TestCreateRouterOptionalFeatures(c *check.C) {
+	supported := map[string]bool{}
+	s.apiRouter.router.HandleFunc("/support/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if !supported[mux.Vars(r)["name"]] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	r, err := createRouter("myrouter", router.ConfigGetterFromPrefix("routers:apirouter"))
+	c.Assert(err, check.IsNil)
+	_, ok := router.AsRouteRulesRouter(r)
+	c.Assert(ok, check.Equals, false)
+	supported["rules"] = true
+	r, err = createRouter("myrouter", router.ConfigGetterFromPrefix("routers:apirouter"))
+	c.Assert(err, check.IsNil)
+	rulesRouter, ok := router.AsRouteRulesRouter(r)
+	c.Assert(ok, check.Equals, true)
+	err = rulesRouter.SetRouteRules(context.TODO(), routertest.FakeApp{Name: "mybackend"}, nil)
+	c.Assert(err, check.IsNil)
+	_, ok = router.AsPolicyRouter(r)
+	c.Assert(ok, check.Equals, false)
+	supported["policy"] = true
+	r, err = createRouter("myrouter", router.ConfigGetterFromPrefix("routers:apirouter"))
+	c.Assert(err, check.IsNil)
+	_, ok = router.AsPolicyRouter(r)
+	c.Assert(ok, check.Equals, true)
+	_, ok = router.AsShadowRouter(r)
+	c.Assert(ok, check.Equals, false)
+	supported["shadow"] = true
+	r, err = createRouter("myrouter", router.ConfigGetterFromPrefix("routers:apirouter"))
+	c.Assert(err, check.IsNil)
+	_, ok = router.AsShadowRouter(r)
+	c.Assert(ok, check.Equals, true)
+	_, ok = router.AsTrafficRouter(r)
+	c.Assert(ok, check.Equals, false)
+	supported["traffic"] = true
+	r, err = createRouter("myrouter", router.ConfigGetterFromPrefix("routers:apirouter"))
+	c.Assert(err, check.IsNil)
+	_, ok = router.AsTrafficRouter(r)
+	c.Assert(ok, check.Equals, true)
+}
+
 func (s *S) TestCreateCustomHeaders(c *check.C) {
 	s.apiRouter.router.HandleFunc("/custom", func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("X-CUSTOM") != "HI" || r.Header.Get("X-CUSTOM-ENV") != "XYZ" {
@@ -562,6 +768,7 @@ func (s *S) TestCreateCustomHeaders(c *check.C) {
 	_, code, err := r.(*struct {
 		router.Router
 		router.OptsRouter
+		router.OptionalFeaturesRouter
 	}).Router.(*apiRouter).do(context.TODO(), http.MethodGet, "/custom", nil, nil)
 	c.Assert(code, check.DeepEquals, http.StatusOK)
 	c.Assert(err, check.IsNil)
@@ -590,6 +797,7 @@ func (s *S) TestCreateDuplicatedCustomHeaders(c *check.C) {
 	_, code, err := r.(*struct {
 		router.Router
 		router.OptsRouter
+		router.OptionalFeaturesRouter
 	}).Router.(*apiRouter).do(context.TODO(), http.MethodGet, "/custom", nil, nil)
 	c.Assert(code, check.DeepEquals, http.StatusOK)
 	c.Assert(err, check.IsNil)
@@ -610,6 +818,13 @@ func newFakeRouter(c *check.C) *fakeRouterAPI {
 	r.HandleFunc("/backend/{name}/cname/{cname}", api.setCname).Methods(http.MethodPost)
 	r.HandleFunc("/backend/{name}/cname/{cname}", api.unsetCname).Methods(http.MethodDelete)
 	r.HandleFunc("/backend/{name}/healthcheck", api.setHealthcheck).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/rules", api.setRules).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/policy", api.setPolicy).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/policy", api.removePolicy).Methods(http.MethodDelete)
+	r.HandleFunc("/backend/{name}/shadow", api.setShadow).Methods(http.MethodPut)
+	r.HandleFunc("/backend/{name}/shadow", api.removeShadow).Methods(http.MethodDelete)
+	r.HandleFunc("/backend/{name}/shadow/report", api.shadowReport).Methods(http.MethodGet)
+	r.HandleFunc("/backend/{name}/traffic", api.traffic).Methods(http.MethodGet)
 	r.HandleFunc("/backend/{name}/certificate/{cname}", api.getCertificate).Methods(http.MethodGet)
 	r.HandleFunc("/backend/{name}/certificate/{cname}", api.addCertificate).Methods(http.MethodPut)
 	r.HandleFunc("/backend/{name}/certificate/{cname}", api.removeCertificate).Methods(http.MethodDelete)
@@ -637,6 +852,9 @@ type backend struct {
 	swapWith    string
 	cnameOnly   bool
 	healthcheck routerTypes.HealthcheckData
+	rules       []routerTypes.RouteRule
+	policy      *routerTypes.Policy
+	shadow      *routerTypes.Shadow
 	opts        map[string]interface{}
 	prefixAddrs map[string]routesReq
 }
@@ -648,6 +866,7 @@ type fakeRouterAPI struct {
 	endpoint     string
 	router       *mux.Router
 	interceptor  func(r *http.Request)
+	trafficSince string
 }
 
 func (f *fakeRouterAPI) getInfo(w http.ResponseWriter, r *http.Request) {
@@ -811,6 +1030,12 @@ func (f *fakeRouterAPI) addRoutes(w http.ResponseWriter, r *http.Request) {
 	if req.ExtraData != nil {
 		prefixData.ExtraData = req.ExtraData
 	}
+	if req.Port != "" {
+		prefixData.Port = req.Port
+	}
+	if req.Hostnames != nil {
+		prefixData.Hostnames = req.Hostnames
+	}
 	for _, a := range prefixData.Addresses {
 		rMap[addressToKey(a)] = struct{}{}
 	}
@@ -1030,6 +1255,78 @@ func (f *fakeRouterAPI) setHealthcheck(w http.ResponseWriter, r *http.Request) {
 	b.healthcheck = hc
 }
 
+func (f *fakeRouterAPI) setRules(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["name"]
+	b, ok := f.backends[name]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req routeRulesReq
+	json.NewDecoder(r.Body).Decode(&req)
+	b.rules = req.Rules
+}
+
+func (f *fakeRouterAPI) setPolicy(w http.ResponseWriter, r *http.Request) {
+	b, ok := f.backends[mux.Vars(r)["name"]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var policy routerTypes.Policy
+	json.NewDecoder(r.Body).Decode(&policy)
+	b.policy = &policy
+}
+
+func (f *fakeRouterAPI) removePolicy(w http.ResponseWriter, r *http.Request) {
+	b, ok := f.backends[mux.Vars(r)["name"]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	b.policy = nil
+}
+
+func (f *fakeRouterAPI) setShadow(w http.ResponseWriter, r *http.Request) {
+	b, ok := f.backends[mux.Vars(r)["name"]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var shadow routerTypes.Shadow
+	json.NewDecoder(r.Body).Decode(&shadow)
+	b.shadow = &shadow
+}
+
+func (f *fakeRouterAPI) removeShadow(w http.ResponseWriter, r *http.Request) {
+	b, ok := f.backends[mux.Vars(r)["name"]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	b.shadow = nil
+}
+
+func (f *fakeRouterAPI) shadowReport(w http.ResponseWriter, r *http.Request) {
+	if _, ok := f.backends[mux.Vars(r)["name"]]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"requests": 10, "primary": {"statusCodes": {"200": 10}, "latencyP50": 12.5, "latencyP99": 40}, "shadow": {"statusCodes": {"200": 9, "500": 1}, "latencyP50": 15, "latencyP99": 80}}`))
+}
+
+func (f *fakeRouterAPI) traffic(w http.ResponseWriter, r *http.Request) {
+	if _, ok := f.backends[mux.Vars(r)["name"]]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	f.trafficSince = r.URL.Query().Get("since")
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"requests": 42}`))
+}
+
 func (f *fakeRouterAPI) stop() {
 	f.listener.Close()
 }
@@ -1,7 +1,7 @@
 // AUTOMATICALLY GENERATED FILE - DO NOT EDIT!
 // Please run 'go generate' to update this file.
 //
-// Copyright 2022 tsuru authors. All rights reserved.
+// Copyright 2026 tsuru authors. All rights reserved.
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
@@ -24,17 +24,21 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 		}{
 			base,
 			base,
+			base,
 		}
 	}
 	if supports["cname"] && !supports["healthcheck"] && !supports["info"] && !supports["prefix"] && !supports["status"] && !supports["tls"] && !supports["v2"] {
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -44,8 +48,10 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -55,9 +61,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -68,8 +76,10 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -79,9 +89,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -92,9 +104,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -105,10 +119,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -120,8 +136,10 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.PrefixRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithPrefixInst,
@@ -131,9 +149,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.PrefixRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -144,9 +164,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -157,10 +179,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -172,9 +196,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.PrefixRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -185,10 +211,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.PrefixRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -200,10 +228,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -215,11 +245,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -232,8 +264,10 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithStatusInst,
@@ -243,9 +277,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -256,9 +292,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -269,10 +307,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -284,9 +324,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -297,10 +339,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -312,10 +356,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -327,11 +373,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -344,9 +392,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.PrefixRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithPrefixInst,
@@ -357,10 +407,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.PrefixRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -372,10 +424,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -387,11 +441,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -404,10 +460,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -419,11 +477,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -436,11 +496,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -453,12 +515,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -472,8 +536,10 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithTLSSupportInst,
@@ -483,9 +549,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -496,9 +564,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -509,10 +579,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -524,9 +596,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -537,10 +611,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -552,10 +628,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -567,11 +645,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -584,9 +664,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.PrefixRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithPrefixInst,
@@ -597,10 +679,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.PrefixRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -612,10 +696,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -627,11 +713,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -644,10 +732,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -659,11 +749,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -676,11 +768,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -693,12 +787,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -712,9 +808,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithStatusInst,
@@ -725,10 +823,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -740,10 +840,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -755,11 +857,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -772,10 +876,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -787,11 +893,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -804,11 +912,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -821,12 +931,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -840,10 +952,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithPrefixInst,
@@ -855,11 +969,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -872,11 +988,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -889,12 +1007,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -908,11 +1028,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -925,12 +1047,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -944,12 +1068,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -963,6 +1089,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
@@ -970,6 +1097,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 			router.StatusRouter
 			router.TLSRouter
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -984,8 +1112,10 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterV2Inst,
@@ -995,9 +1125,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1008,9 +1140,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1021,10 +1155,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1036,9 +1172,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -1049,10 +1187,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1064,10 +1204,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1079,11 +1221,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1096,9 +1240,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.PrefixRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithPrefixInst,
@@ -1109,10 +1255,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.PrefixRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1124,10 +1272,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1139,11 +1289,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1156,10 +1308,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -1171,11 +1325,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1188,11 +1344,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1205,12 +1363,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1224,9 +1384,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithStatusInst,
@@ -1237,10 +1399,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1252,10 +1416,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1267,11 +1433,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1284,10 +1452,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -1299,11 +1469,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1316,11 +1488,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1333,12 +1507,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1352,10 +1528,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithPrefixInst,
@@ -1367,11 +1545,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1384,11 +1564,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1401,12 +1583,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1420,11 +1604,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -1437,12 +1623,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1456,12 +1644,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1475,6 +1665,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
@@ -1482,6 +1673,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 			router.StatusRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1496,9 +1688,11 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithTLSSupportInst,
@@ -1509,10 +1703,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1524,10 +1720,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1539,11 +1737,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1556,10 +1756,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -1571,11 +1773,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1588,11 +1792,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1605,12 +1811,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1624,10 +1832,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.PrefixRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithPrefixInst,
@@ -1639,11 +1849,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.PrefixRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1656,11 +1868,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1673,12 +1887,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1692,11 +1908,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -1709,12 +1927,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1728,12 +1948,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1747,6 +1969,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
@@ -1754,6 +1977,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1768,10 +1992,12 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithStatusInst,
@@ -1783,11 +2009,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1800,11 +2028,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1817,12 +2047,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1836,11 +2068,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -1853,12 +2087,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1872,12 +2108,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1891,6 +2129,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
@@ -1898,6 +2137,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1912,11 +2152,13 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithPrefixInst,
@@ -1929,12 +2171,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1948,12 +2192,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -1967,6 +2213,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.PrefixRouter
@@ -1974,6 +2221,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -1988,12 +2236,14 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.InfoRouter
 			router.PrefixRouter
 			router.StatusRouter
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithInfoInst,
@@ -2007,6 +2257,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.InfoRouter
 			router.PrefixRouter
@@ -2014,6 +2265,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
@@ -2028,6 +2280,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
 			router.PrefixRouter
@@ -2035,6 +2288,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithHealthcheckSupportInst,
@@ -2049,6 +2303,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 		return &struct {
 			router.Router
 			router.OptsRouter
+			router.OptionalFeaturesRouter
 			router.CNameRouter
 			router.CustomHealthcheckRouter
 			router.InfoRouter
@@ -2057,6 +2312,7 @@ func toSupportedInterface(base *apiRouter, supports map[capability]bool) router.
 			router.TLSRouter
 			router.RouterV2
 		}{
+			base,
 			base,
 			base,
 			apiRouterWithCnameSupportInst,
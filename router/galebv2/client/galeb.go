@@ -240,7 +240,9 @@ func (c *GalebClient) fillDefaultPoolValues(params *Pool) {
 }
 
 func (c *GalebClient) fillDefaultRuleValues(params *Rule) {
-	params.Matching = "/"
+	if params.Matching == "" {
+		params.Matching = "/"
+	}
 	if params.Project == "" {
 		params.Project = c.Project
 	}
@@ -250,7 +252,6 @@ func (c *GalebClient) fillDefaultRuleOrderedValues(params *RuleOrdered) {
 	if params.Environment == "" {
 		params.Environment = c.Environment
 	}
-	params.Order = 1
 }
 
 func (c *GalebClient) fillDefaultVirtualHostValues(params *VirtualHost) {
@@ -423,6 +424,20 @@ func (c *GalebClient) AddRuleToPool(ctx context.Context, name, poolName string)
 	return c.addRuleToID(ctx, name, id)
 }
 
+// AddRuleToPoolMatching creates a rule sending requests whose path starts
+// with matching to the given pool.
+func (c *GalebClient) AddRuleToPoolMatching(ctx context.Context, name, poolName, matching string) (string, error) {
+	id, err := c.findItemByName(ctx, "pool", poolName)
+	if err != nil {
+		return "", err
+	}
+	params := Rule{Matching: matching}
+	c.fillDefaultRuleValues(&params)
+	params.Name = name
+	params.BackendPool = []string{id}
+	return c.doCreateResource(ctx, "/rule", &params)
+}
+
 func (c *GalebClient) addRuleToID(ctx context.Context, name, poolID string) (string, error) {
 	var params Rule
 	c.fillDefaultRuleValues(&params)
@@ -432,12 +447,16 @@ func (c *GalebClient) addRuleToID(ctx context.Context, name, poolID string) (str
 }
 
 func (c *GalebClient) setRuleVirtualHostIDs(ctx context.Context, ruleID, virtualHostID string, wait bool) error {
+	return c.setRuleVirtualHostIDsOrder(ctx, ruleID, virtualHostID, 1, wait)
+}
+
+func (c *GalebClient) setRuleVirtualHostIDsOrder(ctx context.Context, ruleID, virtualHostID string, order int, wait bool) error {
 	virtualHostGroupId, err := c.FindVirtualHostGroupByVirtualHostId(ctx, virtualHostID)
 	if err != nil {
 		return err
 	}
 
-	var params RuleOrdered
+	params := RuleOrdered{Order: order}
 	c.fillDefaultRuleOrderedValues(&params)
 	params.Rule = ruleID
 	params.VirtualHostGroup = fmt.Sprintf("%s/virtualhostgroup/%d", c.ApiURL, virtualHostGroupId)
@@ -464,6 +483,35 @@ func (c *GalebClient) SetRuleVirtualHost(ctx context.Context, ruleName, virtualH
 	return c.setRuleVirtualHostIDs(ctx, ruleID, virtualHostID, wait)
 }
 
+// SetRuleVirtualHostOrder adds the rule to the virtual host with the given
+// order. Rules with lower orders are evaluated first.
+func (c *GalebClient) SetRuleVirtualHostOrder(ctx context.Context, ruleName, virtualHostName string, order int, wait bool) error {
+	ruleID, err := c.findItemByName(ctx, "rule", ruleName)
+	if err != nil {
+		return err
+	}
+	virtualHostID, err := c.findItemByName(ctx, "virtualhost", virtualHostName)
+	if err != nil {
+		return err
+	}
+	return c.setRuleVirtualHostIDsOrder(ctx, ruleID, virtualHostID, order, wait)
+}
+
+// FindRuleNamesByPrefix returns the names of the rules starting with prefix.
+func (c *GalebClient) FindRuleNamesByPrefix(ctx context.Context, prefix string) ([]string, error) {
+	rules, err := c.findItemIDsByNameContaining(ctx, "rule", prefix)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, rule := range rules {
+		if strings.HasPrefix(rule.Name, prefix) {
+			names = append(names, rule.Name)
+		}
+	}
+	return names, nil
+}
+
 func (c *GalebClient) RemoveResourceByID(ctx context.Context, resourceID string) error {
 	resource, err := c.removeResource(ctx, resourceID)
 	if err != nil {
@@ -424,6 +424,38 @@ func (s *S) TestGalebAddRuleToPool(c *check.C) {
 	c.Assert(fullId, check.Equals, "http://galeb.somewhere/api/rule/8")
 }
 
+func (s *S) TestGalebAddRuleToPoolMatching(c *check.C) {
+	s.handler.RspHeader.Set("Location", "http://galeb.somewhere/api/rule/8")
+	s.handler.RspCode = http.StatusCreated
+	s.handler.ConditionalContent["/api/pool/search/findByName?name=mypool"] = []string{
+		"200", `{
+		"_embedded": {
+			"pool": [
+				{
+					"_links": {
+						"self": {
+							"href": "http://galeb.somewhere/api/pool/9"
+						}
+					}
+				}
+			]
+		}
+	}`}
+	expected := Rule{
+		commonPostResponse: commonPostResponse{ID: 0, Name: "myrule"},
+		BackendPool:        []string{"http://galeb.somewhere/api/pool/9"},
+		Matching:           "/beta",
+		Project:            "proj1",
+	}
+	fullId, err := s.client.AddRuleToPoolMatching(context.TODO(), "myrule", "mypool", "/beta")
+	c.Assert(err, check.IsNil)
+	var parsedParams Rule
+	err = json.Unmarshal(s.handler.Body[1], &parsedParams)
+	c.Assert(err, check.IsNil)
+	c.Assert(parsedParams, check.DeepEquals, expected)
+	c.Assert(fullId, check.Equals, "http://galeb.somewhere/api/rule/8")
+}
+
 func (s *S) TestGalebRemoveBackendByID(c *check.C) {
 	s.handler.ConditionalContent["GET /api/target/mybackendID"] = []string{
 		"200", `{"_status": "OK"}`,
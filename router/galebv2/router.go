@@ -33,6 +33,7 @@ var (
 	_ router.CNameMoveRouter         = &galebRouter{}
 	_ router.CNameRouter             = &galebRouter{}
 	_ router.PrefixRouter            = &galebRouter{}
+	_ router.RouteRulesRouter        = &galebRouter{}
 )
 
 var clientCache struct {
@@ -141,6 +142,14 @@ func (r *galebRouter) ruleName(prefix, base string) string {
 	return fmt.Sprintf("tsuru-rootrule-%s-%s%s", r.routerName, base, prefix)
 }
 
+func (r *galebRouter) customRuleName(name, base string) string {
+	return r.customRulePrefix(base) + name
+}
+
+func (r *galebRouter) customRulePrefix(base string) string {
+	return fmt.Sprintf("tsuru-customrule-%s-%s%s", r.routerName, base, galebClient.RoutePrefixSeparator)
+}
+
 func (r *galebRouter) virtualHostName(prefix, base string) string {
 	if prefix != "" {
 		prefix = prefix + "."
@@ -440,6 +449,10 @@ func (r *galebRouter) RemoveBackend(ctx context.Context, app router.App) (err er
 	if backendName != app.GetName() {
 		return router.ErrBackendSwapped
 	}
+	err = r.removeRouteRules(ctx, backendName)
+	if err != nil {
+		return err
+	}
 	poolTargets, err := r.client.FindAllTargetsByPoolPrefix(ctx, r.poolName("", backendName))
 	if err != nil {
 		return err
@@ -567,6 +580,65 @@ func (r *galebRouter) SetHealthcheck(ctx context.Context, app router.App, data r
 	return r.client.UpdatePoolProperties(ctx, poolName, poolHealthCheck)
 }
 
+// SetRouteRules only supports path prefix rules sending all their traffic
+// to a single app version, as galeb has no header, cookie or weighted
+// matching. Custom rules are ordered before the root rule of the app.
+func (r *galebRouter) SetRouteRules(ctx context.Context, app router.App, rules []routerTypes.RouteRule) (err error) {
+	done := router.InstrumentRequest(r.routerName)
+	defer func() {
+		done(err)
+	}()
+	for _, rule := range rules {
+		if len(rule.Match.Headers) > 0 || len(rule.Match.Cookies) > 0 {
+			return errors.Errorf("rule %q: header and cookie matching are not supported by galeb routers", rule.Name)
+		}
+		if len(rule.Backends) != 1 {
+			return errors.Errorf("rule %q: weighted backends are not supported by galeb routers", rule.Name)
+		}
+	}
+	backendName, err := router.Retrieve(app.GetName())
+	if err != nil {
+		return err
+	}
+	err = r.removeRouteRules(ctx, backendName)
+	if err != nil {
+		return err
+	}
+	vhName := r.virtualHostName("", backendName)
+	for i, rule := range rules {
+		ruleName := r.customRuleName(rule.Name, backendName)
+		_, err = r.client.AddRuleToPoolMatching(ctx, ruleName, r.poolName(rule.Backends[0].Prefix(), backendName), rule.Match.PathPrefix)
+		if err != nil {
+			return err
+		}
+		// The root rule has order 1, custom rules take the orders right
+		// before it keeping the order they were given.
+		err = r.client.SetRuleVirtualHostOrder(ctx, ruleName, vhName, i-len(rules)+1, true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *galebRouter) removeRouteRules(ctx context.Context, backendName string) error {
+	ruleNames, err := r.client.FindRuleNamesByPrefix(ctx, r.customRulePrefix(backendName))
+	if err != nil {
+		return err
+	}
+	for _, ruleName := range ruleNames {
+		err = r.client.RemoveRulesOrderedByRule(ctx, ruleName)
+		if err != nil {
+			return err
+		}
+		err = r.client.RemoveRule(ctx, ruleName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *galebRouter) RoutesPrefix(ctx context.Context, app router.App) (addrs []appTypes.RoutableAddresses, err error) {
 	done := router.InstrumentRequest(r.routerName)
 	defer func() {
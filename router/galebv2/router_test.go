@@ -26,6 +26,7 @@ import (
 	galebClient "github.com/tsuru/tsuru/router/galebv2/client"
 	"github.com/tsuru/tsuru/router/routertest"
 	servicemock "github.com/tsuru/tsuru/servicemanager/mock"
+	appTypes "github.com/tsuru/tsuru/types/app"
 	routerTypes "github.com/tsuru/tsuru/types/router"
 	check "gopkg.in/check.v1"
 )
@@ -603,3 +604,67 @@ func (s *S) TestSetHealthcheckTCPOnly(c *check.C) {
 	c.Check(fakeServer.pools["1"].(*galebClient.Pool).HcTCPOnly, check.Equals, true)
 	c.Check(fakeServer.pools["1"].(*galebClient.Pool).HcPath, check.Equals, "")
 }
+
+func (s *S) TestSetRouteRules(c *check.C) {
+	fakeServer, err := NewFakeGalebServer()
+	c.Assert(err, check.IsNil)
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+	config.Set("routers:galeb:api-url", server.URL+"/api")
+	gRouter, err := createRouter("galeb", router.ConfigGetterFromPrefix("routers:galeb"))
+	c.Assert(err, check.IsNil)
+	app := routertest.FakeApp{Name: "backend1"}
+	err = gRouter.AddBackend(context.TODO(), app)
+	c.Assert(err, check.IsNil)
+	addr, _ := url.Parse("http://10.0.0.2:8080")
+	err = gRouter.(router.PrefixRouter).AddRoutesPrefix(context.TODO(), app, appTypes.RoutableAddresses{
+		Prefix:    "v2.version",
+		Addresses: []*url.URL{addr},
+	}, true)
+	c.Assert(err, check.IsNil)
+	rules := []routerTypes.RouteRule{{
+		Name:     "beta",
+		Match:    routerTypes.RouteMatch{PathPrefix: "/beta"},
+		Backends: []routerTypes.WeightedBackend{{Version: 2, Weight: 100}},
+	}}
+	err = gRouter.(router.RouteRulesRouter).SetRouteRules(context.TODO(), app, rules)
+	c.Assert(err, check.IsNil)
+	found := fakeServer.findItemByName("rule", "tsuru-customrule-galeb-backend1.beta")
+	c.Assert(found, check.HasLen, 1)
+	rule := found[0].(*galebClient.Rule)
+	c.Assert(rule.Matching, check.Equals, "/beta")
+	poolID := fakeServer.findItemByName("pool", "tsuru-backendpool-galeb-backend1.v2.version")[0].(*galebClient.Pool).FullId()
+	c.Assert(rule.BackendPool, check.DeepEquals, []string{poolID})
+	var orders []int
+	for _, ro := range fakeServer.ruleordered {
+		if strings.HasSuffix(ro.(*galebClient.RuleOrdered).Rule, fmt.Sprintf("/%d", rule.ID)) {
+			orders = append(orders, ro.(*galebClient.RuleOrdered).Order)
+		}
+	}
+	c.Assert(orders, check.DeepEquals, []int{0})
+	err = gRouter.(router.RouteRulesRouter).SetRouteRules(context.TODO(), app, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(fakeServer.findItemByName("rule", "tsuru-customrule-galeb-backend1.beta"), check.HasLen, 0)
+}
+
+func (s *S) TestSetRouteRulesUnsupportedMatch(c *check.C) {
+	fakeServer, err := NewFakeGalebServer()
+	c.Assert(err, check.IsNil)
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+	config.Set("routers:galeb:api-url", server.URL+"/api")
+	gRouter, err := createRouter("galeb", router.ConfigGetterFromPrefix("routers:galeb"))
+	c.Assert(err, check.IsNil)
+	err = gRouter.(router.RouteRulesRouter).SetRouteRules(context.TODO(), routertest.FakeApp{Name: "backend1"}, []routerTypes.RouteRule{{
+		Name:     "canary",
+		Match:    routerTypes.RouteMatch{Headers: map[string]string{"X-Canary": "1"}},
+		Backends: []routerTypes.WeightedBackend{{Version: 2, Weight: 100}},
+	}})
+	c.Assert(err, check.ErrorMatches, `rule "canary": header and cookie matching are not supported by galeb routers`)
+	err = gRouter.(router.RouteRulesRouter).SetRouteRules(context.TODO(), routertest.FakeApp{Name: "backend1"}, []routerTypes.RouteRule{{
+		Name:     "split",
+		Match:    routerTypes.RouteMatch{PathPrefix: "/"},
+		Backends: []routerTypes.WeightedBackend{{Version: 1, Weight: 50}, {Version: 2, Weight: 50}},
+	}})
+	c.Assert(err, check.ErrorMatches, `rule "split": weighted backends are not supported by galeb routers`)
+}
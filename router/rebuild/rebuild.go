@@ -37,6 +37,8 @@ type RebuildApp interface {
 	GetCname() []string
 	GetRouters() []appTypes.AppRouter
 	GetHealthcheckData() (routerTypes.HealthcheckData, error)
+	GetRouterPolicy() (*routerTypes.Policy, error)
+	GetShadow() *routerTypes.Shadow
 	RoutableAddresses(context.Context) ([]appTypes.RoutableAddresses, error)
 }
 
@@ -105,11 +107,17 @@ func RebuildRoutesInRouter(ctx context.Context, appRouter appTypes.AppRouter, o
 		if errHc != nil {
 			return nil, errHc
 		}
+		policy, errPolicy := o.App.GetRouterPolicy()
+		if errPolicy != nil {
+			return nil, errPolicy
+		}
 		opts := router.EnsureBackendOpts{
 			Opts:        map[string]interface{}{},
 			Prefixes:    []router.BackendPrefix{},
 			CNames:      o.App.GetCname(),
 			Healthcheck: hcData,
+			Policy:      policy,
+			Shadow:      o.App.GetShadow(),
 
 			PreserveOldCNames: o.PreserveOldCNames,
 		}
@@ -119,8 +127,9 @@ func RebuildRoutesInRouter(ctx context.Context, appRouter appTypes.AppRouter, o
 		var resultRouterV2 RebuildRoutesResult
 		for _, route := range routes {
 			opts.Prefixes = append(opts.Prefixes, router.BackendPrefix{
-				Prefix: route.Prefix,
-				Target: route.ExtraData,
+				Prefix:    route.Prefix,
+				Target:    route.ExtraData,
+				Hostnames: route.Hostnames,
 			})
 			resultRouterV2.PrefixResults = append(resultRouterV2.PrefixResults, RebuildPrefixResult{
 				Prefix: route.Prefix,
@@ -192,6 +201,29 @@ func RebuildRoutesInRouter(ctx context.Context, appRouter appTypes.AppRouter, o
 			return nil, errHc
 		}
 	}
+	if policyRouter, ok := router.AsPolicyRouter(r); ok {
+		policy, errPolicy := o.App.GetRouterPolicy()
+		if errPolicy != nil {
+			return nil, errPolicy
+		}
+		if policy != nil {
+			fmt.Fprintf(o.Writer, " ---> Setting router policy\n")
+		}
+		errPolicy = policyRouter.SetPolicy(ctx, o.App, policy)
+		if errPolicy != nil {
+			return nil, errPolicy
+		}
+	}
+	if shadowRouter, ok := router.AsShadowRouter(r); ok {
+		shadow := o.App.GetShadow()
+		if shadow != nil {
+			fmt.Fprintf(o.Writer, " ---> Mirroring %d%% of the requests to version %d\n", shadow.Percent, shadow.Version)
+		}
+		errShadow := shadowRouter.SetShadow(ctx, o.App, shadow)
+		if errShadow != nil {
+			return nil, errShadow
+		}
+	}
 
 	prefixRouter, isPrefixRouter := r.(router.PrefixRouter)
 	var oldRoutes []appTypes.RoutableAddresses
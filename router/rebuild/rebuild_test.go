@@ -339,6 +339,65 @@ func (s *S) TestRebuildRoutesSetsHealthcheck(c *check.C) {
 	c.Assert(routertest.FakeRouter.GetHealthcheck("my-test-app"), check.DeepEquals, expected)
 }
 
+func (s *S) TestRebuildRoutesSetsPolicy(c *check.C) {
+	defer routertest.PolicyRouter.Reset()
+	a := app.App{Name: "my-test-app", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-policy"}}}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	version, err := servicemanager.AppVersion.NewAppVersion(context.TODO(), appTypes.NewVersionArgs{
+		App: &a,
+	})
+	c.Assert(err, check.IsNil)
+	err = version.AddData(appTypes.AddVersionDataArgs{
+		CustomData: map[string]interface{}{
+			"router_policy": map[string]interface{}{
+				"allow_cidrs":   []string{"10.0.0.0/8"},
+				"max_body_size": 2048,
+			},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	err = version.CommitSuccessful()
+	c.Assert(err, check.IsNil)
+	_, err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{
+		App:  &a,
+		Wait: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.PolicyRouter.Policies["my-test-app"], check.DeepEquals, &routerTypes.Policy{
+		AllowCIDRs:  []string{"10.0.0.0/8"},
+		MaxBodySize: 2048,
+	})
+	a.RouterPolicy = &routerTypes.Policy{RateLimit: &routerTypes.RateLimit{RequestsPerSecond: 5}}
+	_, err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{
+		App:  &a,
+		Wait: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.PolicyRouter.Policies["my-test-app"], check.DeepEquals, a.RouterPolicy)
+}
+
+func (s *S) TestRebuildRoutesSetsShadow(c *check.C) {
+	defer routertest.ShadowRouter.Reset()
+	a := app.App{Name: "my-test-app", TeamOwner: s.team.Name, Routers: []appTypes.AppRouter{{Name: "fake-shadow"}}}
+	err := app.CreateApp(context.TODO(), &a, s.user)
+	c.Assert(err, check.IsNil)
+	a.Shadow = &routerTypes.Shadow{Version: 2, Percent: 25}
+	_, err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{
+		App:  &a,
+		Wait: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.ShadowRouter.Shadows["my-test-app"], check.DeepEquals, a.Shadow)
+	a.Shadow = nil
+	_, err = rebuild.RebuildRoutes(context.TODO(), rebuild.RebuildRoutesOpts{
+		App:  &a,
+		Wait: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(routertest.ShadowRouter.Shadows["my-test-app"], check.IsNil)
+}
+
 func (s *S) TestRebuildRoutesMultiplePrefixes(c *check.C) {
 	a := app.App{Name: "my-test-app", TeamOwner: s.team.Name}
 	a.Routers = []appTypes.AppRouter{{Name: "fake"}, {Name: "fake-prefix"}}
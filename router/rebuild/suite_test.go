@@ -49,6 +49,8 @@ func (s *S) SetUpSuite(c *check.C) {
 	config.Set("routers:fake:default", true)
 	config.Set("routers:fake-hc:type", "fake-hc")
 	config.Set("routers:fake-prefix:type", "fake-prefix")
+	config.Set("routers:fake-policy:type", "fake-policy")
+	config.Set("routers:fake-shadow:type", "fake-shadow")
 	config.Set("docker:router", "fake")
 	config.Set("auth:hash-cost", bcrypt.MinCost)
 	provision.DefaultProvisioner = "fake"
@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
@@ -145,6 +146,24 @@ type App interface {
 	GetTeamsName() []string
 }
 
+// VisibilityApp is an App with a visibility setting, restricting where
+// routers expose it.
+type VisibilityApp interface {
+	App
+	GetVisibility() string
+}
+
+// AppVisibility returns the visibility of the app, apps without a visibility
+// setting are public.
+func AppVisibility(app App) string {
+	if visibilityApp, ok := app.(VisibilityApp); ok {
+		if visibility := visibilityApp.GetVisibility(); visibility != "" {
+			return visibility
+		}
+	}
+	return router.VisibilityPublic
+}
+
 // Router is the basic interface of this package. It provides methods for
 // managing backends and routes. Each backend can have multiple routes.
 type Router interface {
@@ -240,6 +259,103 @@ type StatusRouter interface {
 	GetBackendStatus(ctx context.Context, app App, path string) (status RouterBackendStatus, err error)
 }
 
+// TrafficRouter is a router able to report how many requests the backend of
+// an app received since a given time.
+type TrafficRouter interface {
+	RequestCount(ctx context.Context, app App, since time.Time) (int64, error)
+}
+
+// AsTrafficRouter returns the TrafficRouter of r, if it's able to report the
+// traffic of apps.
+func AsTrafficRouter(r Router) (TrafficRouter, bool) {
+	for _, f := range optionalFeatures(r) {
+		if trafficRouter, ok := f.(TrafficRouter); ok {
+			return trafficRouter, true
+		}
+	}
+	return nil, false
+}
+
+// OptionalFeaturesRouter is implemented by routers that only know at runtime
+// which optional features they support, like the routers backed by a remote
+// API. The features are not part of the router method set, they're returned
+// by OptionalFeatures and looked up with the As* functions below.
+type OptionalFeaturesRouter interface {
+	OptionalFeatures() []interface{}
+}
+
+// optionalFeatures returns the router itself followed by its optional
+// features, in the order they must be checked.
+func optionalFeatures(r Router) []interface{} {
+	features := []interface{}{r}
+	if featuresRouter, ok := r.(OptionalFeaturesRouter); ok {
+		features = append(features, featuresRouter.OptionalFeatures()...)
+	}
+	return features
+}
+
+// RouteRulesRouter is a router able to route requests matching headers,
+// cookies or path prefixes to weighted sets of app versions. SetRouteRules
+// replaces every rule previously set for the app.
+type RouteRulesRouter interface {
+	SetRouteRules(ctx context.Context, app App, rules []router.RouteRule) error
+}
+
+// AsRouteRulesRouter returns the RouteRulesRouter of r, if it supports route
+// rules.
+func AsRouteRulesRouter(r Router) (RouteRulesRouter, bool) {
+	for _, f := range optionalFeatures(r) {
+		if rulesRouter, ok := f.(RouteRulesRouter); ok {
+			return rulesRouter, true
+		}
+	}
+	return nil, false
+}
+
+// PolicyRouter is a router able to apply rate limits, address allow and deny
+// lists and body size limits to the requests of an app. A nil policy removes
+// any policy previously set.
+type PolicyRouter interface {
+	SetPolicy(ctx context.Context, app App, policy *router.Policy) error
+}
+
+// AsPolicyRouter returns the PolicyRouter of r, if it supports policies.
+func AsPolicyRouter(r Router) (PolicyRouter, bool) {
+	for _, f := range optionalFeatures(r) {
+		if policyRouter, ok := f.(PolicyRouter); ok {
+			return policyRouter, true
+		}
+	}
+	return nil, false
+}
+
+// ShadowRouter is a router able to mirror the requests of an app to the
+// units of a version, discarding their responses, and to report how the
+// mirrored requests were answered. A nil shadow stops the mirroring.
+type ShadowRouter interface {
+	SetShadow(ctx context.Context, app App, shadow *router.Shadow) error
+	ShadowReport(ctx context.Context, app App) (*router.ShadowReport, error)
+}
+
+// AsShadowRouter returns the ShadowRouter of r, if it supports mirroring
+// requests.
+func AsShadowRouter(r Router) (ShadowRouter, bool) {
+	for _, f := range optionalFeatures(r) {
+		if shadowRouter, ok := f.(ShadowRouter); ok {
+			return shadowRouter, true
+		}
+	}
+	return nil, false
+}
+
+// VisibilityRouter is a router able to expose apps according to their
+// visibility, only on internal VIPs or ingress classes for internal apps and
+// not at all for apps with visibility none. Routers read the visibility from
+// the app when adding or updating its backend.
+type VisibilityRouter interface {
+	SupportsVisibility(ctx context.Context, visibility string) (bool, error)
+}
+
 type RouterError struct {
 	Op  string
 	Err error
@@ -21,10 +21,11 @@ import (
 )
 
 type FakeApp struct {
-	Name      string
-	Pool      string
-	Teams     []string
-	TeamOwner string
+	Name       string
+	Pool       string
+	Teams      []string
+	TeamOwner  string
+	Visibility string
 }
 
 func (r FakeApp) GetName() string {
@@ -43,6 +44,10 @@ func (r FakeApp) GetTeamsName() []string {
 	return r.Teams
 }
 
+func (r FakeApp) GetVisibility() string {
+	return r.Visibility
+}
+
 var (
 	testBackend1 = FakeApp{Name: "backend1"}
 	testBackend2 = FakeApp{Name: "backend2"}
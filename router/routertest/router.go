@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tsuru/tsuru/router"
@@ -38,12 +39,38 @@ var StatusRouter = statusRouter{
 	},
 }
 
+var TrafficRouter = trafficRouter{
+	fakeRouter: newFakeRouter(),
+	Requests:   make(map[string]int64),
+}
+
 var TLSRouter = tlsRouter{
 	fakeRouter: newFakeRouter(),
 	Certs:      make(map[string]string),
 	Keys:       make(map[string]string),
 }
 
+var RulesRouter = rulesRouter{
+	fakeRouter: newFakeRouter(),
+	Rules:      make(map[string][]routerTypes.RouteRule),
+}
+
+var PolicyRouter = policyRouter{
+	fakeRouter: newFakeRouter(),
+	Policies:   make(map[string]*routerTypes.Policy),
+}
+
+var ShadowRouter = shadowRouter{
+	fakeRouter: newFakeRouter(),
+	Shadows:    make(map[string]*routerTypes.Shadow),
+	Reports:    make(map[string]*routerTypes.ShadowReport),
+}
+
+var VisibilityRouter = visibilityRouter{
+	optsRouter:   optsRouter{fakeRouter: newFakeRouter(), Opts: make(map[string]map[string]string)},
+	Visibilities: make(map[string]string),
+}
+
 var PrefixRouter = prefixRouter{
 	fakeRouter:   newFakeRouter(),
 	prefixRoutes: make(map[string][]appTypes.RoutableAddresses),
@@ -64,6 +91,11 @@ func init() {
 	router.Register("fake-info", createInfoRouter)
 	router.Register("fake-status", createStatusRouter)
 	router.Register("fake-prefix", createPrefixRouter)
+	router.Register("fake-traffic", createTrafficRouter)
+	router.Register("fake-rules", createRulesRouter)
+	router.Register("fake-policy", createPolicyRouter)
+	router.Register("fake-shadow", createShadowRouter)
+	router.Register("fake-visibility", createVisibilityRouter)
 }
 
 func createRouter(name string, config router.ConfigGetter) (router.Router, error) {
@@ -98,6 +130,26 @@ func createPrefixRouter(name string, config router.ConfigGetter) (router.Router,
 	return &PrefixRouter, nil
 }
 
+func createTrafficRouter(name string, config router.ConfigGetter) (router.Router, error) {
+	return &TrafficRouter, nil
+}
+
+func createRulesRouter(name string, config router.ConfigGetter) (router.Router, error) {
+	return &RulesRouter, nil
+}
+
+func createPolicyRouter(name string, config router.ConfigGetter) (router.Router, error) {
+	return &PolicyRouter, nil
+}
+
+func createShadowRouter(name string, config router.ConfigGetter) (router.Router, error) {
+	return &ShadowRouter, nil
+}
+
+func createVisibilityRouter(name string, config router.ConfigGetter) (router.Router, error) {
+	return &VisibilityRouter, nil
+}
+
 func newFakeRouter() fakeRouter {
 	return fakeRouter{cnames: make(map[string]string), backends: make(map[string][]string), failuresByIp: make(map[string]bool), healthcheck: make(map[string]routerTypes.HealthcheckData), mutex: &sync.Mutex{}}
 }
@@ -570,6 +622,153 @@ func (r *statusRouter) Reset() {
 	}
 }
 
+type trafficRouter struct {
+	fakeRouter
+	Requests map[string]int64
+}
+
+var _ router.TrafficRouter = &trafficRouter{}
+
+func (r *trafficRouter) RequestCount(ctx context.Context, app router.App, since time.Time) (int64, error) {
+	return r.Requests[app.GetName()], nil
+}
+
+func (r *trafficRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.Requests = make(map[string]int64)
+}
+
+type rulesRouter struct {
+	fakeRouter
+	Rules map[string][]routerTypes.RouteRule
+}
+
+var _ router.RouteRulesRouter = &rulesRouter{}
+
+func (r *rulesRouter) SetRouteRules(ctx context.Context, app router.App, rules []routerTypes.RouteRule) error {
+	backendName, err := router.Retrieve(app.GetName())
+	if err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.backends[backendName]; !ok {
+		return router.ErrBackendNotFound
+	}
+	r.Rules[backendName] = rules
+	return nil
+}
+
+func (r *rulesRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.Rules = make(map[string][]routerTypes.RouteRule)
+}
+
+type policyRouter struct {
+	fakeRouter
+	Policies map[string]*routerTypes.Policy
+}
+
+var _ router.PolicyRouter = &policyRouter{}
+
+func (r *policyRouter) SetPolicy(ctx context.Context, app router.App, policy *routerTypes.Policy) error {
+	backendName, err := router.Retrieve(app.GetName())
+	if err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.backends[backendName]; !ok {
+		return router.ErrBackendNotFound
+	}
+	if policy == nil {
+		delete(r.Policies, backendName)
+		return nil
+	}
+	r.Policies[backendName] = policy
+	return nil
+}
+
+func (r *policyRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.Policies = make(map[string]*routerTypes.Policy)
+}
+
+type shadowRouter struct {
+	fakeRouter
+	Shadows map[string]*routerTypes.Shadow
+	Reports map[string]*routerTypes.ShadowReport
+}
+
+var _ router.ShadowRouter = &shadowRouter{}
+
+func (r *shadowRouter) SetShadow(ctx context.Context, app router.App, shadow *routerTypes.Shadow) error {
+	backendName, err := router.Retrieve(app.GetName())
+	if err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.backends[backendName]; !ok {
+		return router.ErrBackendNotFound
+	}
+	if shadow == nil {
+		delete(r.Shadows, backendName)
+		return nil
+	}
+	r.Shadows[backendName] = shadow
+	return nil
+}
+
+func (r *shadowRouter) ShadowReport(ctx context.Context, app router.App) (*routerTypes.ShadowReport, error) {
+	backendName, err := router.Retrieve(app.GetName())
+	if err != nil {
+		return nil, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.backends[backendName]; !ok {
+		return nil, router.ErrBackendNotFound
+	}
+	if report, ok := r.Reports[backendName]; ok {
+		return report, nil
+	}
+	return &routerTypes.ShadowReport{}, nil
+}
+
+func (r *shadowRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.Shadows = make(map[string]*routerTypes.Shadow)
+	r.Reports = make(map[string]*routerTypes.ShadowReport)
+}
+
+type visibilityRouter struct {
+	optsRouter
+	Visibilities map[string]string
+}
+
+var _ router.VisibilityRouter = &visibilityRouter{}
+
+func (r *visibilityRouter) SupportsVisibility(ctx context.Context, visibility string) (bool, error) {
+	return true, nil
+}
+
+func (r *visibilityRouter) AddBackendOpts(ctx context.Context, app router.App, opts map[string]string) error {
+	r.Visibilities[app.GetName()] = router.AppVisibility(app)
+	return r.optsRouter.AddBackendOpts(ctx, app, opts)
+}
+
+func (r *visibilityRouter) UpdateBackendOpts(ctx context.Context, app router.App, opts map[string]string) error {
+	r.Visibilities[app.GetName()] = router.AppVisibility(app)
+	return r.optsRouter.UpdateBackendOpts(ctx, app, opts)
+}
+
+func (r *visibilityRouter) Reset() {
+	r.fakeRouter.Reset()
+	r.Opts = make(map[string]map[string]string)
+	r.Visibilities = make(map[string]string)
+}
+
 type prefixRouter struct {
 	fakeRouter
 	prefixRoutes map[string][]appTypes.RoutableAddresses
@@ -14,8 +14,9 @@ import (
 )
 
 type BackendPrefix struct {
-	Prefix string            `json:"prefix"`
-	Target map[string]string `json:"target"` // in kubernetes cluster be like {serviceName: "", namespace: ""}
+	Prefix    string            `json:"prefix"`
+	Target    map[string]string `json:"target"` // in kubernetes cluster be like {serviceName: "", namespace: ""}
+	Hostnames []string          `json:"hostnames,omitempty"`
 }
 
 type EnsureBackendOpts struct {
@@ -23,6 +24,8 @@ type EnsureBackendOpts struct {
 	CNames      []string               `json:"cnames"`
 	Prefixes    []BackendPrefix        `json:"prefixes"`
 	Healthcheck router.HealthcheckData `json:"healthcheck"`
+	Policy      *router.Policy         `json:"policy,omitempty"`
+	Shadow      *router.Shadow         `json:"shadow,omitempty"`
 
 	PreserveOldCNames bool `json:"preserveOldCNames,omitempty"`
 }
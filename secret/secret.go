@@ -0,0 +1,89 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package secret encrypts sensitive values stored in the database with the
+// key set in the secrets:encryption-key config entry.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+)
+
+var (
+	ErrNoKey = errors.New("secrets:encryption-key is not set")
+
+	errInvalidCiphertext = errors.New("invalid encrypted data")
+)
+
+// Enabled returns whether an encryption key is configured.
+func Enabled() bool {
+	encoded, _ := config.GetString("secrets:encryption-key")
+	return encoded != ""
+}
+
+func encryptionKey() ([]byte, error) {
+	encoded, _ := config.GetString("secrets:encryption-key")
+	if encoded == "" {
+		return nil, ErrNoKey
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid secrets:encryption-key")
+	}
+	if len(key) != 32 {
+		return nil, errors.Errorf("secrets:encryption-key must have 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	key, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts data with AES-256-GCM, prefixing the result with the
+// random nonce used.
+func Encrypt(data []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// Decrypt reverts Encrypt.
+func Decrypt(data []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errInvalidCiphertext
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errInvalidCiphertext
+	}
+	return plain, nil
+}
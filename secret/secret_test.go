@@ -0,0 +1,54 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"testing"
+
+	"github.com/tsuru/config"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	check.TestingT(t)
+}
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) SetUpTest(c *check.C) {
+	config.Set("secrets:encryption-key", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+}
+
+func (s *S) TearDownTest(c *check.C) {
+	config.Unset("secrets")
+}
+
+func (s *S) TestEncryptDecrypt(c *check.C) {
+	c.Assert(Enabled(), check.Equals, true)
+	encrypted, err := Encrypt([]byte("my password"))
+	c.Assert(err, check.IsNil)
+	c.Assert(string(encrypted), check.Not(check.Matches), ".*my password.*")
+	other, err := Encrypt([]byte("my password"))
+	c.Assert(err, check.IsNil)
+	c.Assert(other, check.Not(check.DeepEquals), encrypted)
+	plain, err := Decrypt(encrypted)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(plain), check.Equals, "my password")
+	encrypted[len(encrypted)-1]++
+	_, err = Decrypt(encrypted)
+	c.Assert(err, check.Equals, errInvalidCiphertext)
+}
+
+func (s *S) TestEncryptInvalidKey(c *check.C) {
+	config.Unset("secrets")
+	c.Assert(Enabled(), check.Equals, false)
+	_, err := Encrypt([]byte("data"))
+	c.Assert(err, check.Equals, ErrNoKey)
+	config.Set("secrets:encryption-key", "c2hvcnQ=")
+	_, err = Encrypt([]byte("data"))
+	c.Assert(err, check.ErrorMatches, "secrets:encryption-key must have 32 bytes, got 5")
+}
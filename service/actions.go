@@ -114,9 +114,13 @@ var createServiceInstance = action.Action{
 }
 
 // updateServiceInstance is an action that updates an instance in the database.
+// The instance is only updated if it wasn't changed since it was read,
+// failing with ErrServiceInstanceChanged otherwise.
 //
 // The second argument in the context must be a Service Instance with the current attributes.
 // The third argument in the context must be a Service Instance with the updated attributes.
+// The teams of the instance are replaced when the updated attributes have
+// teams, otherwise the team owner is added to them.
 var updateServiceInstance = action.Action{
 	Name: "update-service-instance",
 	Forward: func(ctx action.FWContext) (action.Result, error) {
@@ -133,21 +137,31 @@ var updateServiceInstance = action.Action{
 			return nil, err
 		}
 		defer conn.Close()
-		return nil, conn.ServiceInstances().Update(
-			bson.M{"name": instance.Name, "service_name": instance.ServiceName},
-			bson.M{
-				"$set": bson.M{
-					"description": updateData.Description,
-					"tags":        updateData.Tags,
-					"teamowner":   updateData.TeamOwner,
-					"plan_name":   updateData.PlanName,
-					"parameters":  updateData.Parameters,
-				},
-				"$addToSet": bson.M{
-					"teams": updateData.TeamOwner,
-				},
-			},
-		)
+		set := bson.M{
+			"description": updateData.Description,
+			"tags":        updateData.Tags,
+			"teamowner":   updateData.TeamOwner,
+			"plan_name":   updateData.PlanName,
+			"parameters":  updateData.Parameters,
+		}
+		update := bson.M{
+			"$set": set,
+			"$inc": bson.M{"generation": 1},
+		}
+		if updateData.Teams != nil {
+			set["teams"] = teamsWithOwner(updateData.Teams, updateData.TeamOwner)
+		} else {
+			update["$addToSet"] = bson.M{"teams": updateData.TeamOwner}
+		}
+		query := bson.M{"name": instance.Name, "service_name": instance.ServiceName, "generation": instance.Generation}
+		if instance.Generation == 0 {
+			query["generation"] = bson.M{"$in": []interface{}{0, nil}}
+		}
+		err = conn.ServiceInstances().Update(query, update)
+		if err == mgo.ErrNotFound {
+			return nil, ErrServiceInstanceChanged
+		}
+		return nil, err
 	},
 	Backward: func(ctx action.BWContext) {
 		instance, ok := ctx.Params[1].(ServiceInstance)
@@ -169,12 +183,23 @@ var updateServiceInstance = action.Action{
 					"teams":       instance.Teams,
 					"plan_name":   instance.PlanName,
 				},
+				"$inc": bson.M{"generation": 1},
 			},
 		)
 	},
 	MinParams: 3,
 }
 
+func teamsWithOwner(teams []string, owner string) []string {
+	result := append([]string{}, teams...)
+	for _, team := range teams {
+		if team == owner {
+			return result
+		}
+	}
+	return append(result, owner)
+}
+
 // notifyUpdateServiceInstance is an action that calls the service endpoint
 // to update a service instance.
 //
@@ -8,7 +8,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/globalsign/mgo/bson"
 	uuid "github.com/nu7hatch/gouuid"
@@ -32,6 +35,12 @@ var ClientFactory = func(config *osb.ClientConfiguration) (osb.Client, error) {
 	return osb.NewClient(config)
 }
 
+const defaultBrokerOperationTimeout = 5 * time.Minute
+
+// brokerOperationPollInterval is the interval between each poll of an
+// asynchronous binding operation.
+var brokerOperationPollInterval = 2 * time.Second
+
 // brokerClient implements the Open Service Broker API for stored
 // Brokers
 type brokerClient struct {
@@ -50,6 +59,7 @@ func newClient(b serviceTypes.Broker, service string) (*brokerClient, error) {
 	config := osb.DefaultClientConfiguration()
 	config.URL = b.URL
 	config.Insecure = b.Config.Insecure
+	config.EnableAlphaFeatures = b.Config.AsyncBindings
 	var authConfig *osb.AuthConfig
 	if b.Config.AuthConfig != nil {
 		authConfig = &osb.AuthConfig{}
@@ -165,6 +175,9 @@ func (b *brokerClient) Create(ctx context.Context, instance *ServiceInstance, ev
 	}
 	resp, err := b.client.ProvisionInstance(&req)
 	if err != nil {
+		if needsOrphanMitigation(err) {
+			b.deprovisionOrphan(instance)
+		}
 		return err
 	}
 	if resp != nil && resp.OperationKey != nil {
@@ -294,14 +307,35 @@ func (b *brokerClient) BindApp(ctx context.Context, instance *ServiceInstance, a
 		resp, err = b.client.Bind(&req)
 	}
 	if err != nil {
+		if needsOrphanMitigation(err) {
+			b.unbindOrphan(instance, bind.UUID, id)
+		}
 		return nil, err
 	}
 	if resp.OperationKey != nil {
 		bind.OperationKey = string(*resp.OperationKey)
 		instance.BrokerData.LastOperationKey = string(*resp.OperationKey)
 	}
+	credentials := resp.Credentials
+	if resp.Async {
+		err = b.waitBindingOperation(ctx, instance, bind.UUID, resp.OperationKey, id)
+		if err != nil {
+			b.unbindOrphan(instance, bind.UUID, id)
+			return nil, err
+		}
+		var bindResp *osb.GetBindingResponse
+		bindResp, err = b.client.GetBinding(&osb.GetBindingRequest{
+			InstanceID: instance.BrokerData.UUID,
+			BindingID:  bind.UUID,
+		})
+		if err != nil {
+			b.unbindOrphan(instance, bind.UUID, id)
+			return nil, err
+		}
+		credentials = bindResp.Credentials
+	}
 	envs := make(map[string]string)
-	for k, v := range resp.Credentials {
+	for k, v := range credentials {
 		switch s := v.(type) {
 		case string:
 			envs[k] = s
@@ -340,6 +374,12 @@ func (b *brokerClient) UnbindApp(ctx context.Context, instance *ServiceInstance,
 	if err != nil {
 		return err
 	}
+	if resp != nil && resp.Async {
+		err = b.waitBindingOperation(ctx, instance, req.BindingID, resp.OperationKey, id)
+		if err != nil && !osb.IsGoneError(err) {
+			return err
+		}
+	}
 	delete(instance.BrokerData.Binds, app.GetName())
 	if resp != nil && resp.OperationKey != nil {
 		instance.BrokerData.LastOperationKey = string(*resp.OperationKey)
@@ -348,6 +388,90 @@ func (b *brokerClient) UnbindApp(ctx context.Context, instance *ServiceInstance,
 	return err
 }
 
+// waitBindingOperation polls the broker until the asynchronous operation on
+// the binding finishes, failing if it takes longer than the operation timeout
+// of the broker.
+func (b *brokerClient) waitBindingOperation(ctx context.Context, instance *ServiceInstance, bindingID string, opKey *osb.OperationKey, id *osb.OriginatingIdentity) error {
+	timeout := defaultBrokerOperationTimeout
+	if b.broker.Config.OperationTimeoutSeconds > 0 {
+		timeout = time.Duration(b.broker.Config.OperationTimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req := osb.BindingLastOperationRequest{
+		InstanceID:          instance.BrokerData.UUID,
+		BindingID:           bindingID,
+		ServiceID:           &instance.BrokerData.ServiceID,
+		PlanID:              &instance.BrokerData.PlanID,
+		OperationKey:        opKey,
+		OriginatingIdentity: id,
+	}
+	for {
+		op, err := b.client.PollBindingLastOperation(&req)
+		if err != nil {
+			return err
+		}
+		switch op.State {
+		case osb.StateSucceeded:
+			return nil
+		case osb.StateFailed:
+			msg := fmt.Sprintf("binding operation failed in broker %q", b.broker.Name)
+			if op.Description != nil {
+				msg += ": " + *op.Description
+			}
+			return errors.New(msg)
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "binding operation did not finish in broker %q", b.broker.Name)
+		case <-time.After(brokerOperationPollInterval):
+		}
+	}
+}
+
+// needsOrphanMitigation returns whether the broker may have created a
+// resource even though the request failed, as described by the orphan
+// mitigation section of the Open Service Broker API.
+func needsOrphanMitigation(err error) bool {
+	if httpErr, ok := osb.IsHTTPError(err); ok {
+		return httpErr.StatusCode >= http.StatusInternalServerError ||
+			(httpErr.StatusCode >= http.StatusOK && httpErr.StatusCode < http.StatusMultipleChoices)
+	}
+	_, ok := errors.Cause(err).(net.Error)
+	return ok
+}
+
+func (b *brokerClient) deprovisionOrphan(instance *ServiceInstance) {
+	_, err := b.client.DeprovisionInstance(&osb.DeprovisionRequest{
+		InstanceID:        instance.BrokerData.UUID,
+		ServiceID:         instance.BrokerData.ServiceID,
+		PlanID:            instance.BrokerData.PlanID,
+		AcceptsIncomplete: true,
+	})
+	if err != nil && !osb.IsGoneError(err) {
+		log.Errorf("[broker %s] unable to deprovision orphan instance %q: %v", b.broker.Name, instance.BrokerData.UUID, err)
+	}
+}
+
+func (b *brokerClient) unbindOrphan(instance *ServiceInstance, bindingID string, id *osb.OriginatingIdentity) {
+	req := osb.UnbindRequest{
+		InstanceID:          instance.BrokerData.UUID,
+		BindingID:           bindingID,
+		ServiceID:           instance.BrokerData.ServiceID,
+		PlanID:              instance.BrokerData.PlanID,
+		OriginatingIdentity: id,
+		AcceptsIncomplete:   true,
+	}
+	_, err := b.client.Unbind(&req)
+	if osb.IsAsyncBindingOperationsNotAllowedError(err) {
+		req.AcceptsIncomplete = false
+		_, err = b.client.Unbind(&req)
+	}
+	if err != nil && !osb.IsGoneError(err) {
+		log.Errorf("[broker %s] unable to unbind orphan binding %q: %v", b.broker.Name, bindingID, err)
+	}
+}
+
 func (b *brokerClient) Status(ctx context.Context, instance *ServiceInstance, requestID string) (string, error) {
 	if instance.BrokerData == nil {
 		return "", ErrInvalidBrokerData
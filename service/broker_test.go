@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"sort"
+	"time"
 
 	osb "github.com/pmorie/go-open-service-broker-client/v2"
 	osbfake "github.com/pmorie/go-open-service-broker-client/v2/fake"
@@ -358,6 +360,194 @@ func (s *S) TestBrokerClientUnbindApp(c *check.C) {
 	})
 }
 
+func (s *S) TestBrokerClientCreateOrphanMitigation(c *check.C) {
+	ev := createEvt(c)
+	var deprovisioned *osb.DeprovisionRequest
+	config := osbfake.FakeClientConfiguration{
+		ProvisionReaction: &osbfake.ProvisionReaction{
+			Error: osb.HTTPStatusCodeError{StatusCode: http.StatusInternalServerError},
+		},
+		DeprovisionReaction: osbfake.DynamicDeprovisionReaction(func(req *osb.DeprovisionRequest) (*osb.DeprovisionResponse, error) {
+			deprovisioned = req
+			return &osb.DeprovisionResponse{}, nil
+		}),
+		CatalogReaction: &osbfake.CatalogReaction{
+			Response: &osb.CatalogResponse{
+				Services: []osb.Service{
+					{Name: "service", ID: "serviceid", Plans: []osb.Plan{{Name: "plan1", ID: "planid"}}},
+				},
+			},
+		},
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	client, err := newClient(serviceTypes.Broker{Name: "broker"}, "service")
+	c.Assert(err, check.IsNil)
+	instance := createTestInstance()
+	err = client.Create(context.TODO(), &instance, ev, "request-id")
+	c.Assert(err, check.NotNil)
+	c.Assert(deprovisioned, check.NotNil)
+	c.Assert(deprovisioned.InstanceID, check.Equals, instance.BrokerData.UUID)
+	c.Assert(deprovisioned.ServiceID, check.Equals, "serviceid")
+	c.Assert(deprovisioned.PlanID, check.Equals, "planid")
+}
+
+func (s *S) TestBrokerClientCreateNoOrphanMitigationOnClientError(c *check.C) {
+	ev := createEvt(c)
+	config := osbfake.FakeClientConfiguration{
+		ProvisionReaction: &osbfake.ProvisionReaction{
+			Error: osb.HTTPStatusCodeError{StatusCode: http.StatusBadRequest},
+		},
+		CatalogReaction: &osbfake.CatalogReaction{
+			Response: &osb.CatalogResponse{
+				Services: []osb.Service{
+					{Name: "service", ID: "serviceid", Plans: []osb.Plan{{Name: "plan1", ID: "planid"}}},
+				},
+			},
+		},
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	client, err := newClient(serviceTypes.Broker{Name: "broker"}, "service")
+	c.Assert(err, check.IsNil)
+	instance := createTestInstance()
+	err = client.Create(context.TODO(), &instance, ev, "request-id")
+	c.Assert(err, check.NotNil)
+}
+
+func (s *S) TestBrokerClientBindAppAsync(c *check.C) {
+	defer func(interval time.Duration) { brokerOperationPollInterval = interval }(brokerOperationPollInterval)
+	brokerOperationPollInterval = time.Millisecond
+	ev := createEvt(c)
+	a := provisiontest.NewFakeApp("theapp", "python", 1)
+	var bindID string
+	var polls int
+	opKey := osb.OperationKey("Binding")
+	config := osbfake.FakeClientConfiguration{
+		BindReaction: osbfake.DynamicBindReaction(func(req *osb.BindRequest) (*osb.BindResponse, error) {
+			bindID = req.BindingID
+			return &osb.BindResponse{Async: true, OperationKey: &opKey}, nil
+		}),
+		PollBindingLastOperationReaction: osbfake.DynamicPollBindingLastOperationReaction(func(req *osb.BindingLastOperationRequest) (*osb.LastOperationResponse, error) {
+			c.Assert(req.BindingID, check.Equals, bindID)
+			c.Assert(req.OperationKey, check.DeepEquals, &opKey)
+			polls++
+			if polls < 3 {
+				return &osb.LastOperationResponse{State: osb.StateInProgress}, nil
+			}
+			return &osb.LastOperationResponse{State: osb.StateSucceeded}, nil
+		}),
+		GetBindingReaction: &osbfake.GetBindingReaction{Response: &osb.GetBindingResponse{
+			Credentials: map[string]interface{}{"env1": "val1"},
+		}},
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	client, err := newClient(serviceTypes.Broker{
+		Name:   "broker",
+		Config: serviceTypes.BrokerConfig{AsyncBindings: true},
+	}, "service")
+	c.Assert(err, check.IsNil)
+	instance := createTestInstance()
+	err = s.conn.ServiceInstances().Insert(&instance)
+	c.Assert(err, check.IsNil)
+	envs, err := client.BindApp(context.TODO(), &instance, a, nil, ev, "request-id")
+	c.Assert(err, check.IsNil)
+	c.Assert(polls, check.Equals, 3)
+	c.Assert(envs, check.DeepEquals, map[string]string{"env1": "val1"})
+	c.Assert(instance.BrokerData.Binds["theapp"].UUID, check.Equals, bindID)
+}
+
+func (s *S) TestBrokerClientBindAppAsyncFailed(c *check.C) {
+	defer func(interval time.Duration) { brokerOperationPollInterval = interval }(brokerOperationPollInterval)
+	brokerOperationPollInterval = time.Millisecond
+	ev := createEvt(c)
+	a := provisiontest.NewFakeApp("theapp", "python", 1)
+	var bindID, unbindID string
+	description := "no capacity"
+	config := osbfake.FakeClientConfiguration{
+		BindReaction: osbfake.DynamicBindReaction(func(req *osb.BindRequest) (*osb.BindResponse, error) {
+			bindID = req.BindingID
+			return &osb.BindResponse{Async: true}, nil
+		}),
+		PollBindingLastOperationReaction: &osbfake.PollBindingLastOperationReaction{
+			Response: &osb.LastOperationResponse{State: osb.StateFailed, Description: &description},
+		},
+		UnbindReaction: osbfake.DynamicUnbindReaction(func(req *osb.UnbindRequest) (*osb.UnbindResponse, error) {
+			unbindID = req.BindingID
+			return &osb.UnbindResponse{}, nil
+		}),
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	client, err := newClient(serviceTypes.Broker{
+		Name:   "broker",
+		Config: serviceTypes.BrokerConfig{AsyncBindings: true},
+	}, "service")
+	c.Assert(err, check.IsNil)
+	instance := createTestInstance()
+	_, err = client.BindApp(context.TODO(), &instance, a, nil, ev, "request-id")
+	c.Assert(err, check.ErrorMatches, `binding operation failed in broker "broker": no capacity`)
+	c.Assert(unbindID, check.Equals, bindID)
+	c.Assert(instance.BrokerData.Binds, check.IsNil)
+}
+
+func (s *S) TestBrokerClientBindAppAsyncTimeout(c *check.C) {
+	defer func(interval time.Duration) { brokerOperationPollInterval = interval }(brokerOperationPollInterval)
+	brokerOperationPollInterval = time.Millisecond
+	ev := createEvt(c)
+	a := provisiontest.NewFakeApp("theapp", "python", 1)
+	var unbound bool
+	config := osbfake.FakeClientConfiguration{
+		BindReaction: &osbfake.BindReaction{Response: &osb.BindResponse{Async: true}},
+		PollBindingLastOperationReaction: &osbfake.PollBindingLastOperationReaction{
+			Response: &osb.LastOperationResponse{State: osb.StateInProgress},
+		},
+		UnbindReaction: osbfake.DynamicUnbindReaction(func(req *osb.UnbindRequest) (*osb.UnbindResponse, error) {
+			unbound = true
+			return &osb.UnbindResponse{}, nil
+		}),
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	client, err := newClient(serviceTypes.Broker{
+		Name:   "broker",
+		Config: serviceTypes.BrokerConfig{AsyncBindings: true},
+	}, "service")
+	c.Assert(err, check.IsNil)
+	instance := createTestInstance()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.BindApp(ctx, &instance, a, nil, ev, "request-id")
+	c.Assert(err, check.ErrorMatches, `binding operation did not finish in broker "broker": context deadline exceeded`)
+	c.Assert(unbound, check.Equals, true)
+}
+
+func (s *S) TestBrokerClientUnbindAppAsync(c *check.C) {
+	defer func(interval time.Duration) { brokerOperationPollInterval = interval }(brokerOperationPollInterval)
+	brokerOperationPollInterval = time.Millisecond
+	ev := createEvt(c)
+	var polled bool
+	config := osbfake.FakeClientConfiguration{
+		UnbindReaction: &osbfake.UnbindReaction{Response: &osb.UnbindResponse{Async: true}},
+		PollBindingLastOperationReaction: osbfake.DynamicPollBindingLastOperationReaction(func(req *osb.BindingLastOperationRequest) (*osb.LastOperationResponse, error) {
+			c.Assert(req.BindingID, check.Equals, "xxxx-xxxx")
+			polled = true
+			return nil, osb.HTTPStatusCodeError{StatusCode: http.StatusGone}
+		}),
+	}
+	ClientFactory = osbfake.NewFakeClientFunc(config)
+	client, err := newClient(serviceTypes.Broker{
+		Name:   "broker",
+		Config: serviceTypes.BrokerConfig{AsyncBindings: true},
+	}, "service")
+	c.Assert(err, check.IsNil)
+	instance := createTestInstance()
+	instance.BrokerData.Binds = map[string]BrokerInstanceBind{
+		"theapp": {UUID: "xxxx-xxxx"},
+	}
+	a := provisiontest.NewFakeApp("theapp", "python", 1)
+	err = client.UnbindApp(context.TODO(), &instance, a, ev, "request-id")
+	c.Assert(err, check.IsNil)
+	c.Assert(polled, check.Equals, true)
+	c.Assert(instance.BrokerData.Binds, check.DeepEquals, map[string]BrokerInstanceBind{})
+}
+
 func (s *S) TestBrokerClientUpdate(c *check.C) {
 	ev := createEvt(c)
 	planID := "planid"
@@ -41,6 +41,7 @@ var (
 	ErrMultiClusterPoolDoesNotMatch             = errors.New("pools between app and multi-cluster service instance does not match")
 	ErrRegularServiceInstanceCannotBelongToPool = errors.New("regular (non-multi-cluster) service instance cannot belong to a pool")
 	ErrRevokeInstanceTeamOwnerAccess            = errors.New("cannot revoke the instance's team owner access")
+	ErrServiceInstanceChanged                   = errors.New("service instance was changed by another request")
 	instanceNameRegexp                          = regexp.MustCompile(`^[A-Za-z][-a-zA-Z0-9_]+$`)
 )
 
@@ -66,6 +67,10 @@ type ServiceInstance struct {
 	// BrokerData stores data used by Instances provisioned by Brokers
 	BrokerData *BrokerInstanceData `json:"broker_data,omitempty" bson:"broker_data"`
 
+	// Generation is incremented whenever the instance attributes change, so
+	// that updates can be applied only to the state they were based on.
+	Generation int64 `json:"-"`
+
 	// ForceRemove indicates whether service instance should be removed even the
 	// related call to service API fails.
 	ForceRemove bool `bson:"-" json:"-"`
@@ -411,7 +416,7 @@ func (si *ServiceInstance) Grant(teamName string) error {
 	if err != nil {
 		return err
 	}
-	return si.updateData(bson.M{"$push": bson.M{"teams": team.Name}})
+	return si.updateData(bson.M{"$push": bson.M{"teams": team.Name}, "$inc": bson.M{"generation": 1}})
 }
 
 func (si *ServiceInstance) Revoke(teamName string) error {
@@ -422,7 +427,7 @@ func (si *ServiceInstance) Revoke(teamName string) error {
 	if err != nil {
 		return err
 	}
-	return si.updateData(bson.M{"$pull": bson.M{"teams": team.Name}})
+	return si.updateData(bson.M{"$pull": bson.M{"teams": team.Name}, "$inc": bson.M{"generation": 1}})
 }
 
 func genericServiceInstancesFilter(services interface{}, teams []string) bson.M {
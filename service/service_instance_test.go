@@ -836,6 +836,45 @@ func (s *InstanceSuite) TestUpdateServiceInstance(c *check.C) {
 	c.Assert(si.Teams, check.DeepEquals, []string{s.team.Name, newTeam.Name})
 }
 
+func (s *InstanceSuite) TestUpdateServiceInstanceChangedConcurrently(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	srv := Service{Name: "mongodb", Endpoint: map[string]string{"production": ts.URL}, Password: "s3cr3t"}
+	err := s.conn.Services().Insert(&srv)
+	c.Assert(err, check.IsNil)
+	instance := ServiceInstance{Name: "instance", ServiceName: "mongodb", PlanName: "small", TeamOwner: s.team.Name, Teams: []string{s.team.Name}}
+	err = s.conn.ServiceInstances().Insert(instance)
+	c.Assert(err, check.IsNil)
+	si, err := GetServiceInstance(context.TODO(), "mongodb", "instance")
+	c.Assert(err, check.IsNil)
+	other, err := GetServiceInstance(context.TODO(), "mongodb", "instance")
+	c.Assert(err, check.IsNil)
+	s.mockService.Team.OnFindByName = func(name string) (*authTypes.Team, error) {
+		return &authTypes.Team{Name: name}, nil
+	}
+	err = other.Grant("other-team")
+	c.Assert(err, check.IsNil)
+	updateData := *si
+	updateData.Description = "desc"
+	err = si.Update(srv, updateData, createEvt(c), "")
+	c.Assert(err, check.Equals, ErrServiceInstanceChanged)
+	dbInstance, err := GetServiceInstance(context.TODO(), "mongodb", "instance")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.Description, check.Equals, "")
+	c.Assert(dbInstance.Teams, check.DeepEquals, []string{s.team.Name, "other-team"})
+	c.Assert(dbInstance.Generation, check.Equals, int64(1))
+	updateData = *dbInstance
+	updateData.Description = "desc"
+	err = dbInstance.Update(srv, updateData, createEvt(c), "")
+	c.Assert(err, check.IsNil)
+	dbInstance, err = GetServiceInstance(context.TODO(), "mongodb", "instance")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbInstance.Description, check.Equals, "desc")
+	c.Assert(dbInstance.Generation, check.Equals, int64(2))
+}
+
 func (s *InstanceSuite) TestUpdateServiceInstanceValidatesTeamOwner(c *check.C) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
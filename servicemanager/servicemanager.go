@@ -27,6 +27,9 @@ var (
 	Team                      auth.TeamService
 	TeamToken                 auth.TeamTokenService
 	Webhook                   event.WebhookService
+	Notification              event.NotificationService
+	Audit                     event.AuditService
+	EventPublisher            event.EventPublisher
 	AppQuota                  quota.QuotaService
 	UserQuota                 quota.QuotaService
 	TeamQuota                 quota.QuotaService
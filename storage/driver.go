@@ -24,6 +24,7 @@ import (
 
 type DbDriver struct {
 	TeamStorage                      auth.TeamStorage
+	UserStorage                      auth.UserStorage
 	PlatformStorage                  app.PlatformStorage
 	PlanStorage                      app.PlanStorage
 	AppCacheStorage                  cache.CacheStorage
@@ -32,11 +33,13 @@ type DbDriver struct {
 	AppQuotaStorage                  quota.QuotaStorage
 	TeamQuotaStorage                 quota.QuotaStorage
 	WebhookStorage                   event.WebhookStorage
+	NotificationStorage              event.NotificationStorage
 	ClusterStorage                   provision.ClusterStorage
 	ServiceBrokerStorage             service.ServiceBrokerStorage
 	ServiceBrokerCatalogCacheStorage cache.CacheStorage
 	PlatformImageStorage             image.PlatformImageStorage
 	InstanceTrackerStorage           tracker.InstanceStorage
+	LeaseStorage                     tracker.LeaseStorage
 	AppVersionStorage                app.AppVersionStorage
 	DynamicRouterStorage             router.DynamicRouterStorage
 	AuthGroupStorage                 auth.GroupStorage
@@ -0,0 +1,89 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	dbStorage "github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/types/tracker"
+)
+
+const leaseCollectionName = "leases"
+
+type leaseStorage struct{}
+
+func leaseCollection(conn *db.Storage) *dbStorage.Collection {
+	return conn.Collection(leaseCollectionName)
+}
+
+type lease struct {
+	Name      string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	RenewedAt time.Time `bson:"renewedat"`
+	ExpiresAt time.Time `bson:"expiresat"`
+}
+
+// Acquire upserts the lease only when it's expired or already owned by
+// owner. When held by another owner the upsert tries to insert a new
+// document with the same id, failing with a duplicate key error.
+func (s *leaseStorage) Acquire(ctx context.Context, name, owner string, duration time.Duration) error {
+	now := time.Now().UTC()
+	query := bson.M{
+		"_id": name,
+		"$or": []bson.M{
+			{"owner": owner},
+			{"expiresat": bson.M{"$lt": now}},
+		},
+	}
+
+	span := newMongoDBSpan(ctx, mongoSpanUpsert, leaseCollectionName)
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	conn, err := db.Conn()
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	defer conn.Close()
+	_, err = leaseCollection(conn).Upsert(query, bson.M{"$set": bson.M{
+		"owner":     owner,
+		"renewedat": now,
+		"expiresat": now.Add(duration),
+	}})
+	if mgo.IsDup(err) {
+		return tracker.ErrLeaseHeld
+	}
+	span.SetError(err)
+	return err
+}
+
+func (s *leaseStorage) List(ctx context.Context) ([]tracker.Lease, error) {
+	span := newMongoDBSpan(ctx, mongoSpanFind, leaseCollectionName)
+	defer span.Finish()
+
+	conn, err := db.Conn()
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	defer conn.Close()
+	var leases []lease
+	err = leaseCollection(conn).Find(nil).Sort("_id").All(&leases)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	results := make([]tracker.Lease, len(leases))
+	for i := range leases {
+		results[i] = tracker.Lease(leases[i])
+	}
+	return results, nil
+}
@@ -11,6 +11,7 @@ import (
 func init() {
 	mongodbDriver := storage.DbDriver{
 		TeamStorage:                      &TeamStorage{},
+		UserStorage:                      &UserStorage{},
 		PlatformStorage:                  &PlatformStorage{},
 		PlatformImageStorage:             &PlatformImageStorage{},
 		PlanStorage:                      &PlanStorage{},
@@ -20,10 +21,12 @@ func init() {
 		AppQuotaStorage:                  appQuotaStorage(),
 		TeamQuotaStorage:                 teamQuotaStorage(),
 		WebhookStorage:                   &webhookStorage{},
+		NotificationStorage:              &notificationStorage{},
 		ClusterStorage:                   &clusterStorage{},
 		ServiceBrokerStorage:             &serviceBrokerStorage{},
 		ServiceBrokerCatalogCacheStorage: serviceBrokerCatalogCacheStorage(),
 		InstanceTrackerStorage:           &instanceTrackerStorage{},
+		LeaseStorage:                     &leaseStorage{},
 		AppVersionStorage:                &appVersionStorage{},
 		DynamicRouterStorage:             &dynamicRouterStorage{},
 		AuthGroupStorage:                 &authGroupStorage{},
@@ -0,0 +1,113 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	mgo "github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	dbStorage "github.com/tsuru/tsuru/db/storage"
+	"github.com/tsuru/tsuru/types/event"
+)
+
+type notificationStorage struct{}
+
+func notificationCollection(conn *db.Storage) *dbStorage.Collection {
+	coll := conn.Collection("notification_channels")
+	coll.EnsureIndex(mgo.Index{
+		Key:    []string{"name"},
+		Unique: true,
+	})
+	coll.EnsureIndex(mgo.Index{Key: []string{"apps"}})
+	coll.EnsureIndex(mgo.Index{Key: []string{"pools"}})
+	return coll
+}
+
+var _ event.NotificationStorage = &notificationStorage{}
+
+func (s *notificationStorage) Insert(c event.NotificationChannel) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = notificationCollection(conn).Insert(c)
+	if err != nil && mgo.IsDup(err) {
+		err = event.ErrNotificationChannelAlreadyExists
+	}
+	return err
+}
+
+func (s *notificationStorage) Update(c event.NotificationChannel) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = notificationCollection(conn).Update(bson.M{"name": c.Name}, c)
+	if err == mgo.ErrNotFound {
+		err = event.ErrNotificationChannelNotFound
+	}
+	return err
+}
+
+func (s *notificationStorage) findQuery(query bson.M) ([]event.NotificationChannel, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var channels []event.NotificationChannel
+	err = notificationCollection(conn).Find(query).All(&channels)
+	return channels, err
+}
+
+func (s *notificationStorage) FindAllByTeams(teams []string) ([]event.NotificationChannel, error) {
+	var query bson.M
+	if teams != nil {
+		query = bson.M{"teamowner": bson.M{"$in": teams}}
+	}
+	return s.findQuery(query)
+}
+
+func (s *notificationStorage) FindBySubscription(apps, pools []string) ([]event.NotificationChannel, error) {
+	if len(apps) == 0 && len(pools) == 0 {
+		return nil, nil
+	}
+	return s.findQuery(bson.M{"$or": []bson.M{
+		{"apps": bson.M{"$in": apps}},
+		{"pools": bson.M{"$in": pools}},
+	}})
+}
+
+func (s *notificationStorage) FindByName(name string) (*event.NotificationChannel, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var result event.NotificationChannel
+	err = notificationCollection(conn).Find(bson.M{"name": name}).One(&result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			err = event.ErrNotificationChannelNotFound
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *notificationStorage) Delete(name string) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	err = notificationCollection(conn).Remove(bson.M{"name": name})
+	if err == mgo.ErrNotFound {
+		err = event.ErrNotificationChannelNotFound
+	}
+	return err
+}
@@ -21,13 +21,17 @@ var _ app.PlanStorage = &PlanStorage{}
 type PlanStorage struct{}
 
 type plan struct {
-	Name     string `bson:"_id"`
-	Memory   int64
-	Swap     int64
-	CpuShare int
-	CPUMilli int
-	Default  bool
-	Override app.PlanOverride `bson:"-"`
+	Name        string `bson:"_id"`
+	Memory      int64
+	Swap        int64
+	CpuShare    int
+	CPUMilli    int
+	CPUQuota    int64
+	CPUPeriod   int64
+	BlkioWeight int64
+	PidsLimit   int64
+	Default     bool
+	Override    app.PlanOverride `bson:"-"`
 }
 
 func plansCollection(conn *db.Storage) *dbStorage.Collection {
@@ -64,6 +68,37 @@ func (s *PlanStorage) Insert(ctx context.Context, p app.Plan) error {
 	return err
 }
 
+func (s *PlanStorage) Update(ctx context.Context, p app.Plan) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if p.Default {
+		query := bson.M{"default": true, "_id": bson.M{"$ne": p.Name}}
+		span := newMongoDBSpan(ctx, mongoSpanUpdateAll, plansCollectionName)
+		span.SetQueryStatement(query)
+		defer span.Finish()
+
+		_, err = plansCollection(conn).UpdateAll(query, bson.M{"$unset": bson.M{"default": false}})
+		if err != nil {
+			span.SetError(err)
+			return err
+		}
+	}
+
+	span := newMongoDBSpan(ctx, mongoSpanUpdate, plansCollectionName)
+	span.SetMongoID(p.Name)
+	defer span.Finish()
+
+	err = plansCollection(conn).UpdateId(p.Name, plan(p))
+	if err == mgo.ErrNotFound {
+		return app.ErrPlanNotFound
+	}
+	span.SetError(err)
+	return err
+}
+
 func (s *PlanStorage) FindAll(ctx context.Context) ([]app.Plan, error) {
 	return s.findByQuery(ctx, nil)
 }
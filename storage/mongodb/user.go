@@ -0,0 +1,209 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mongodb
+
+import (
+	"context"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	"github.com/tsuru/tsuru/db"
+	"github.com/tsuru/tsuru/types/auth"
+	"github.com/tsuru/tsuru/types/quota"
+)
+
+const usersCollectionName = "users"
+
+var _ auth.UserStorage = &UserStorage{}
+
+type UserStorage struct{}
+
+type user struct {
+	Quota     quota.Quota
+	Email     string
+	Password  string
+	APIKey    string
+	Roles     []auth.RoleInstance `bson:",omitempty"`
+	Groups    []string            `bson:",omitempty"`
+	FromToken bool                `bson:",omitempty"`
+}
+
+func (s *UserStorage) Insert(ctx context.Context, u auth.User) error {
+	span := newMongoDBSpan(ctx, mongoSpanInsert, usersCollectionName)
+	defer span.Finish()
+
+	conn, err := db.Conn()
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	defer conn.Close()
+	err = conn.Users().Insert(user(u))
+	if mgo.IsDup(err) {
+		err = auth.ErrUserAlreadyExists
+	}
+	span.SetError(err)
+	return err
+}
+
+func (s *UserStorage) Update(ctx context.Context, u auth.User) error {
+	query := bson.M{"email": u.Email}
+	span := newMongoDBSpan(ctx, mongoSpanUpdate, usersCollectionName)
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	conn, err := db.Conn()
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	defer conn.Close()
+	err = conn.Users().Update(query, user(u))
+	if err == mgo.ErrNotFound {
+		err = auth.ErrUserNotFound
+	}
+	span.SetError(err)
+	return err
+}
+
+func (s *UserStorage) FindAll(ctx context.Context) ([]auth.User, error) {
+	return s.findByQuery(ctx, nil)
+}
+
+func (s *UserStorage) FindByEmail(ctx context.Context, email string) (*auth.User, error) {
+	return s.findOneByQuery(ctx, bson.M{"email": email})
+}
+
+func (s *UserStorage) FindByAPIKey(ctx context.Context, key string) (*auth.User, error) {
+	return s.findOneByQuery(ctx, bson.M{"apikey": key})
+}
+
+func (s *UserStorage) FindByRole(ctx context.Context, role string) ([]auth.User, error) {
+	return s.findByQuery(ctx, bson.M{"roles.name": role})
+}
+
+func (s *UserStorage) findOneByQuery(ctx context.Context, query bson.M) (*auth.User, error) {
+	span := newMongoDBSpan(ctx, mongoSpanFindOne, usersCollectionName)
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	conn, err := db.Conn()
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	defer conn.Close()
+	var u user
+	err = conn.Users().Find(query).One(&u)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, auth.ErrUserNotFound
+		}
+		span.SetError(err)
+		return nil, err
+	}
+	result := auth.User(u)
+	return &result, nil
+}
+
+func (s *UserStorage) findByQuery(ctx context.Context, query bson.M) ([]auth.User, error) {
+	span := newMongoDBSpan(ctx, mongoSpanFind, usersCollectionName)
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	conn, err := db.Conn()
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	defer conn.Close()
+	var users []user
+	err = conn.Users().Find(query).All(&users)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	authUsers := make([]auth.User, len(users))
+	for i, u := range users {
+		authUsers[i] = auth.User(u)
+	}
+	return authUsers, nil
+}
+
+func (s *UserStorage) Delete(ctx context.Context, email string) error {
+	query := bson.M{"email": email}
+	span := newMongoDBSpan(ctx, mongoSpanDelete, usersCollectionName)
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	conn, err := db.Conn()
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	defer conn.Close()
+	err = conn.Users().Remove(query)
+	if err == mgo.ErrNotFound {
+		err = auth.ErrUserNotFound
+	}
+	span.SetError(err)
+	return err
+}
+
+// roleDocument keeps the order of the fields of the role, as order matters
+// when matching embedded documents in $addToSet and $pull.
+func roleDocument(role auth.RoleInstance) bson.D {
+	return bson.D{
+		{Name: "name", Value: role.Name},
+		{Name: "contextvalue", Value: role.ContextValue},
+	}
+}
+
+func (s *UserStorage) AddRole(ctx context.Context, email string, role auth.RoleInstance) error {
+	return s.updateRoles(ctx, email, bson.M{"$addToSet": bson.M{"roles": roleDocument(role)}})
+}
+
+func (s *UserStorage) RemoveRole(ctx context.Context, email string, role auth.RoleInstance) error {
+	return s.updateRoles(ctx, email, bson.M{"$pull": bson.M{"roles": roleDocument(role)}})
+}
+
+func (s *UserStorage) updateRoles(ctx context.Context, email string, update bson.M) error {
+	query := bson.M{"email": email}
+	span := newMongoDBSpan(ctx, mongoSpanUpdate, usersCollectionName)
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	conn, err := db.Conn()
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	defer conn.Close()
+	err = conn.Users().Update(query, update)
+	if err == mgo.ErrNotFound {
+		err = auth.ErrUserNotFound
+	}
+	span.SetError(err)
+	return err
+}
+
+func (s *UserStorage) RemoveRoleFromAll(ctx context.Context, roleName string) error {
+	query := bson.M{"roles.name": roleName}
+	span := newMongoDBSpan(ctx, mongoSpanUpdateAll, usersCollectionName)
+	span.SetQueryStatement(query)
+	defer span.Finish()
+
+	conn, err := db.Conn()
+	if err != nil {
+		span.SetError(err)
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Users().UpdateAll(query, bson.M{
+		"$pull": bson.M{"roles": bson.M{"name": roleName}},
+	})
+	span.SetError(err)
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package postgres implements a database driver storing teams and users, with
+// their quotas, in PostgreSQL. Storages not yet supported by the driver are
+// delegated to the mongodb driver, and apps, pools and events, read and
+// written directly through the db package, are always stored in MongoDB, so
+// the driver can't be used without a MongoDB database.
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sync"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/storage"
+	_ "github.com/tsuru/tsuru/storage/mongodb"
+)
+
+const (
+	DriverName = "postgres"
+
+	uniqueViolation = "23505"
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS teams (
+		name text PRIMARY KEY,
+		creating_user text NOT NULL DEFAULT '',
+		tags jsonb,
+		quota_limit integer NOT NULL DEFAULT 0,
+		quota_in_use integer NOT NULL DEFAULT 0
+	)`,
+	`CREATE TABLE IF NOT EXISTS users (
+		email text PRIMARY KEY,
+		password text NOT NULL DEFAULT '',
+		api_key text NOT NULL DEFAULT '',
+		roles jsonb,
+		groups jsonb,
+		from_token boolean NOT NULL DEFAULT false,
+		quota_limit integer NOT NULL DEFAULT 0,
+		quota_in_use integer NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS users_api_key_idx ON users (api_key)`,
+	`CREATE INDEX IF NOT EXISTS users_roles_idx ON users USING gin (roles)`,
+}
+
+var (
+	dbLock sync.Mutex
+	dbConn *sql.DB
+)
+
+func init() {
+	base, err := storage.GetDbDriver("mongodb")
+	if err != nil {
+		panic(err)
+	}
+	driver := *base
+	driver.TeamStorage = &teamStorage{}
+	driver.TeamQuotaStorage = &quotaStorage{table: "teams", key: "name"}
+	driver.UserStorage = &userStorage{}
+	driver.UserQuotaStorage = &quotaStorage{table: "users", key: "email"}
+	storage.RegisterDbDriver(DriverName, driver)
+}
+
+// conn returns the connection pool to the database set in
+// database:postgres:url, creating the tables on the first call.
+func conn() (*sql.DB, error) {
+	dbLock.Lock()
+	defer dbLock.Unlock()
+	if dbConn != nil {
+		return dbConn, nil
+	}
+	url, err := config.GetString("database:postgres:url")
+	if err != nil {
+		return nil, errors.Wrap(err, "database:postgres:url is required by the postgres database driver")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range schema {
+		_, err = db.Exec(stmt)
+		if err != nil {
+			db.Close()
+			return nil, errors.Wrap(err, "unable to create postgres schema")
+		}
+	}
+	dbConn = db
+	return dbConn, nil
+}
+
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == uniqueViolation
+}
+
+// jsonValue encodes v to be stored in a jsonb column. Nil slices are stored
+// as NULL.
+func jsonValue(v interface{}, isNil bool) (interface{}, error) {
+	if isNil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+func notFoundIfNoRows(result sql.Result, notFound error) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/tsuru/tsuru/types/quota"
+)
+
+var _ quota.QuotaStorage = &quotaStorage{}
+
+// quotaStorage stores the quota in the quota_limit and quota_in_use columns
+// of the row identified by the key column in table.
+type quotaStorage struct {
+	table string
+	key   string
+}
+
+func (s *quotaStorage) SetLimit(ctx context.Context, name string, limit int) error {
+	return s.update(ctx, "quota_limit", name, limit)
+}
+
+func (s *quotaStorage) Set(ctx context.Context, name string, inUse int) error {
+	return s.update(ctx, "quota_in_use", name, inUse)
+}
+
+func (s *quotaStorage) update(ctx context.Context, column, name string, value int) error {
+	db, err := conn()
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`UPDATE %s SET %s = $2 WHERE %s = $1`, s.table, column, s.key)
+	result, err := db.ExecContext(ctx, query, name, value)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result, quota.ErrQuotaNotFound)
+}
+
+func (s *quotaStorage) Get(ctx context.Context, name string) (*quota.Quota, error) {
+	db, err := conn()
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`SELECT quota_limit, quota_in_use FROM %s WHERE %s = $1`, s.table, s.key)
+	var q quota.Quota
+	err = db.QueryRowContext(ctx, query, name).Scan(&q.Limit, &q.InUse)
+	if err == sql.ErrNoRows {
+		return nil, quota.ErrQuotaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
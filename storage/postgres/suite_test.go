@@ -0,0 +1,74 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/tsuru/config"
+	"github.com/tsuru/tsuru/auth"
+	"github.com/tsuru/tsuru/storage/storagetest"
+	authTypes "github.com/tsuru/tsuru/types/auth"
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type postgresBaseTest struct{}
+
+func (t *postgresBaseTest) SetUpSuite(c *check.C) {
+	url := os.Getenv("TSURU_POSTGRES_URL")
+	if url == "" {
+		url = "postgres://postgres@127.0.0.1:5432/tsuru_storage_postgres_test?sslmode=disable"
+	}
+	config.Set("database:postgres:url", url)
+	_, err := conn()
+	if err != nil {
+		c.Skip("postgres not available: " + err.Error())
+	}
+}
+
+func (t *postgresBaseTest) SetUpTest(c *check.C) {
+	db, err := conn()
+	c.Assert(err, check.IsNil)
+	_, err = db.Exec(`TRUNCATE teams, users`)
+	c.Assert(err, check.IsNil)
+}
+
+func (t *postgresBaseTest) TearDownTest(c *check.C) {
+}
+
+func (t *postgresBaseTest) TearDownSuite(c *check.C) {
+}
+
+var _ = check.Suite(&storagetest.TeamSuite{
+	TeamStorage: &teamStorage{},
+	SuiteHooks:  &postgresBaseTest{},
+})
+
+var _ = check.Suite(&storagetest.UserSuite{
+	UserStorage: &userStorage{},
+	SuiteHooks:  &postgresBaseTest{},
+})
+
+type quotaUserStorage struct {
+	userStorage
+}
+
+func (s *quotaUserStorage) Create(u *auth.User) error {
+	return s.Insert(context.TODO(), authTypes.User(*u))
+}
+
+func (s *quotaUserStorage) Remove(u *auth.User) error {
+	return s.Delete(context.TODO(), u.Email)
+}
+
+var _ = check.Suite(&storagetest.UserQuotaSuite{
+	UserStorage:      &quotaUserStorage{},
+	UserQuotaStorage: &quotaStorage{table: "users", key: "email"},
+	SuiteHooks:       &postgresBaseTest{},
+})
@@ -0,0 +1,123 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/tsuru/tsuru/types/auth"
+)
+
+const teamColumns = "name, creating_user, tags, quota_limit, quota_in_use"
+
+var _ auth.TeamStorage = &teamStorage{}
+
+type teamStorage struct{}
+
+func (s *teamStorage) Insert(ctx context.Context, t auth.Team) error {
+	db, err := conn()
+	if err != nil {
+		return err
+	}
+	tags, err := jsonValue(t.Tags, t.Tags == nil)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO teams (`+teamColumns+`) VALUES ($1, $2, $3, $4, $5)`,
+		t.Name, t.CreatingUser, tags, t.Quota.Limit, t.Quota.InUse,
+	)
+	if isUniqueViolation(err) {
+		return auth.ErrTeamAlreadyExists
+	}
+	return err
+}
+
+func (s *teamStorage) Update(ctx context.Context, t auth.Team) error {
+	db, err := conn()
+	if err != nil {
+		return err
+	}
+	tags, err := jsonValue(t.Tags, t.Tags == nil)
+	if err != nil {
+		return err
+	}
+	result, err := db.ExecContext(ctx,
+		`UPDATE teams SET creating_user = $2, tags = $3, quota_limit = $4, quota_in_use = $5 WHERE name = $1`,
+		t.Name, t.CreatingUser, tags, t.Quota.Limit, t.Quota.InUse,
+	)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result, auth.ErrTeamNotFound)
+}
+
+func (s *teamStorage) FindAll(ctx context.Context) ([]auth.Team, error) {
+	return s.findByQuery(ctx, `SELECT `+teamColumns+` FROM teams ORDER BY name`)
+}
+
+func (s *teamStorage) FindByName(ctx context.Context, name string) (*auth.Team, error) {
+	teams, err := s.findByQuery(ctx, `SELECT `+teamColumns+` FROM teams WHERE name = $1`, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(teams) == 0 {
+		return nil, auth.ErrTeamNotFound
+	}
+	return &teams[0], nil
+}
+
+func (s *teamStorage) FindByNames(ctx context.Context, names []string) ([]auth.Team, error) {
+	return s.findByQuery(ctx, `SELECT `+teamColumns+` FROM teams WHERE name = ANY($1) ORDER BY name`, pq.Array(names))
+}
+
+func (s *teamStorage) findByQuery(ctx context.Context, query string, args ...interface{}) ([]auth.Team, error) {
+	db, err := conn()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	teams := []auth.Team{}
+	for rows.Next() {
+		t, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, *t)
+	}
+	return teams, rows.Err()
+}
+
+func scanTeam(rows *sql.Rows) (*auth.Team, error) {
+	var t auth.Team
+	var tags []byte
+	err := rows.Scan(&t.Name, &t.CreatingUser, &tags, &t.Quota.Limit, &t.Quota.InUse)
+	if err != nil {
+		return nil, err
+	}
+	err = decodeJSON(tags, &t.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *teamStorage) Delete(ctx context.Context, t auth.Team) error {
+	db, err := conn()
+	if err != nil {
+		return err
+	}
+	result, err := db.ExecContext(ctx, `DELETE FROM teams WHERE name = $1`, t.Name)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result, auth.ErrTeamNotFound)
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/tsuru/tsuru/types/auth"
+)
+
+const userColumns = "email, password, api_key, roles, groups, from_token, quota_limit, quota_in_use"
+
+var _ auth.UserStorage = &userStorage{}
+
+type userStorage struct{}
+
+func (s *userStorage) Insert(ctx context.Context, u auth.User) error {
+	db, err := conn()
+	if err != nil {
+		return err
+	}
+	roles, groups, err := userJSONColumns(u)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO users (`+userColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		u.Email, u.Password, u.APIKey, roles, groups, u.FromToken, u.Quota.Limit, u.Quota.InUse,
+	)
+	if isUniqueViolation(err) {
+		return auth.ErrUserAlreadyExists
+	}
+	return err
+}
+
+func (s *userStorage) Update(ctx context.Context, u auth.User) error {
+	db, err := conn()
+	if err != nil {
+		return err
+	}
+	roles, groups, err := userJSONColumns(u)
+	if err != nil {
+		return err
+	}
+	result, err := db.ExecContext(ctx,
+		`UPDATE users SET password = $2, api_key = $3, roles = $4, groups = $5, from_token = $6,
+		quota_limit = $7, quota_in_use = $8 WHERE email = $1`,
+		u.Email, u.Password, u.APIKey, roles, groups, u.FromToken, u.Quota.Limit, u.Quota.InUse,
+	)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result, auth.ErrUserNotFound)
+}
+
+func userJSONColumns(u auth.User) (interface{}, interface{}, error) {
+	roles, err := jsonValue(u.Roles, u.Roles == nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	groups, err := jsonValue(u.Groups, u.Groups == nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return roles, groups, nil
+}
+
+func (s *userStorage) FindAll(ctx context.Context) ([]auth.User, error) {
+	return s.findByQuery(ctx, `SELECT `+userColumns+` FROM users ORDER BY email`)
+}
+
+func (s *userStorage) FindByEmail(ctx context.Context, email string) (*auth.User, error) {
+	return s.findOneByQuery(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email)
+}
+
+func (s *userStorage) FindByAPIKey(ctx context.Context, key string) (*auth.User, error) {
+	return s.findOneByQuery(ctx, `SELECT `+userColumns+` FROM users WHERE api_key = $1 LIMIT 1`, key)
+}
+
+func (s *userStorage) FindByRole(ctx context.Context, role string) ([]auth.User, error) {
+	filter, err := jsonValue([]map[string]string{{"Name": role}}, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.findByQuery(ctx, `SELECT `+userColumns+` FROM users WHERE roles @> $1::jsonb ORDER BY email`, filter)
+}
+
+func (s *userStorage) findOneByQuery(ctx context.Context, query string, args ...interface{}) (*auth.User, error) {
+	users, err := s.findByQuery(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, auth.ErrUserNotFound
+	}
+	return &users[0], nil
+}
+
+func (s *userStorage) findByQuery(ctx context.Context, query string, args ...interface{}) ([]auth.User, error) {
+	db, err := conn()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	users := []auth.User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, rows.Err()
+}
+
+func scanUser(rows *sql.Rows) (*auth.User, error) {
+	var u auth.User
+	var roles, groups []byte
+	err := rows.Scan(&u.Email, &u.Password, &u.APIKey, &roles, &groups, &u.FromToken, &u.Quota.Limit, &u.Quota.InUse)
+	if err != nil {
+		return nil, err
+	}
+	err = decodeJSON(roles, &u.Roles)
+	if err != nil {
+		return nil, err
+	}
+	err = decodeJSON(groups, &u.Groups)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (s *userStorage) Delete(ctx context.Context, email string) error {
+	db, err := conn()
+	if err != nil {
+		return err
+	}
+	result, err := db.ExecContext(ctx, `DELETE FROM users WHERE email = $1`, email)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result, auth.ErrUserNotFound)
+}
+
+// AddRole appends the role to the user roles, unless the user already has
+// it, as done by $addToSet in the mongodb driver.
+func (s *userStorage) AddRole(ctx context.Context, email string, role auth.RoleInstance) error {
+	db, err := conn()
+	if err != nil {
+		return err
+	}
+	value, err := jsonValue([]auth.RoleInstance{role}, false)
+	if err != nil {
+		return err
+	}
+	result, err := db.ExecContext(ctx, `UPDATE users SET roles = CASE
+		WHEN COALESCE(roles, '[]'::jsonb) @> $2::jsonb THEN roles
+		ELSE COALESCE(roles, '[]'::jsonb) || $2::jsonb
+	END WHERE email = $1`, email, value)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result, auth.ErrUserNotFound)
+}
+
+func (s *userStorage) RemoveRole(ctx context.Context, email string, role auth.RoleInstance) error {
+	db, err := conn()
+	if err != nil {
+		return err
+	}
+	value, err := jsonValue(role, false)
+	if err != nil {
+		return err
+	}
+	result, err := db.ExecContext(ctx, `UPDATE users SET roles = (
+		SELECT COALESCE(jsonb_agg(r ORDER BY i), '[]'::jsonb)
+		FROM jsonb_array_elements(COALESCE(roles, '[]'::jsonb)) WITH ORDINALITY AS e(r, i)
+		WHERE r <> $2::jsonb
+	) WHERE email = $1`, email, value)
+	if err != nil {
+		return err
+	}
+	return notFoundIfNoRows(result, auth.ErrUserNotFound)
+}
+
+func (s *userStorage) RemoveRoleFromAll(ctx context.Context, roleName string) error {
+	db, err := conn()
+	if err != nil {
+		return err
+	}
+	filter, err := jsonValue([]map[string]string{{"Name": roleName}}, false)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `UPDATE users SET roles = (
+		SELECT COALESCE(jsonb_agg(r ORDER BY i), '[]'::jsonb)
+		FROM jsonb_array_elements(roles) WITH ORDINALITY AS e(r, i)
+		WHERE r->>'Name' <> $1
+	) WHERE roles @> $2::jsonb`, roleName, filter)
+	return err
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsuru/tsuru/types/tracker"
+	check "gopkg.in/check.v1"
+)
+
+type LeaseSuite struct {
+	SuiteHooks
+	LeaseStorage tracker.LeaseStorage
+}
+
+func (s *LeaseSuite) Test_Acquire(c *check.C) {
+	t0 := time.Now().Truncate(time.Millisecond)
+	err := s.LeaseStorage.Acquire(context.TODO(), "gc", "host1", time.Minute)
+	c.Assert(err, check.IsNil)
+	leases, err := s.LeaseStorage.List(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(leases, check.HasLen, 1)
+	c.Assert(leases[0].Name, check.Equals, "gc")
+	c.Assert(leases[0].Owner, check.Equals, "host1")
+	c.Assert(leases[0].RenewedAt.Before(t0), check.Equals, false)
+	c.Assert(leases[0].ExpiresAt.Sub(leases[0].RenewedAt), check.Equals, time.Minute)
+}
+
+func (s *LeaseSuite) Test_Acquire_Renew(c *check.C) {
+	err := s.LeaseStorage.Acquire(context.TODO(), "gc", "host1", time.Minute)
+	c.Assert(err, check.IsNil)
+	leases, err := s.LeaseStorage.List(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(leases, check.HasLen, 1)
+	expires := leases[0].ExpiresAt
+	err = s.LeaseStorage.Acquire(context.TODO(), "gc", "host1", 2*time.Minute)
+	c.Assert(err, check.IsNil)
+	leases, err = s.LeaseStorage.List(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(leases, check.HasLen, 1)
+	c.Assert(leases[0].ExpiresAt.After(expires), check.Equals, true)
+}
+
+func (s *LeaseSuite) Test_Acquire_HeldByOther(c *check.C) {
+	err := s.LeaseStorage.Acquire(context.TODO(), "gc", "host1", time.Minute)
+	c.Assert(err, check.IsNil)
+	err = s.LeaseStorage.Acquire(context.TODO(), "gc", "host2", time.Minute)
+	c.Assert(err, check.Equals, tracker.ErrLeaseHeld)
+	err = s.LeaseStorage.Acquire(context.TODO(), "autoscale", "host2", time.Minute)
+	c.Assert(err, check.IsNil)
+	leases, err := s.LeaseStorage.List(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(leases, check.HasLen, 2)
+	c.Assert(leases[0].Name, check.Equals, "autoscale")
+	c.Assert(leases[0].Owner, check.Equals, "host2")
+	c.Assert(leases[1].Name, check.Equals, "gc")
+	c.Assert(leases[1].Owner, check.Equals, "host1")
+}
+
+func (s *LeaseSuite) Test_Acquire_Expired(c *check.C) {
+	err := s.LeaseStorage.Acquire(context.TODO(), "gc", "host1", 100*time.Millisecond)
+	c.Assert(err, check.IsNil)
+	time.Sleep(200 * time.Millisecond)
+	err = s.LeaseStorage.Acquire(context.TODO(), "gc", "host2", time.Minute)
+	c.Assert(err, check.IsNil)
+	leases, err := s.LeaseStorage.List(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(leases, check.HasLen, 1)
+	c.Assert(leases[0].Owner, check.Equals, "host2")
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"sort"
+
+	eventTypes "github.com/tsuru/tsuru/types/event"
+	check "gopkg.in/check.v1"
+)
+
+type NotificationSuite struct {
+	SuiteHooks
+	NotificationStorage eventTypes.NotificationStorage
+}
+
+func notificationChannelsNames(channels []eventTypes.NotificationChannel) []string {
+	var names []string
+	for _, ch := range channels {
+		names = append(names, ch.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *NotificationSuite) TestInsertNotificationChannel(c *check.C) {
+	ch := eventTypes.NotificationChannel{
+		Name:      "deploys",
+		TeamOwner: "team1",
+		Driver:    "slack",
+		URL:       "https://hooks.slack.com/services/T0/B0/abc",
+		Apps:      []string{"myapp"},
+		Pools:     []string{"pool1"},
+		Events:    []string{"deploy-failure"},
+	}
+	err := s.NotificationStorage.Insert(ch)
+	c.Assert(err, check.IsNil)
+	dbCh, err := s.NotificationStorage.FindByName(ch.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(dbCh, check.DeepEquals, &ch)
+}
+
+func (s *NotificationSuite) TestInsertDuplicateNotificationChannel(c *check.C) {
+	ch := eventTypes.NotificationChannel{Name: "deploys"}
+	err := s.NotificationStorage.Insert(ch)
+	c.Assert(err, check.IsNil)
+	err = s.NotificationStorage.Insert(ch)
+	c.Assert(err, check.Equals, eventTypes.ErrNotificationChannelAlreadyExists)
+}
+
+func (s *NotificationSuite) TestFindBySubscription(c *check.C) {
+	channels := []eventTypes.NotificationChannel{
+		{Name: "ch1", Apps: []string{"myapp"}},
+		{Name: "ch2", Apps: []string{"otherapp"}, Pools: []string{"pool1"}},
+		{Name: "ch3", Pools: []string{"pool2"}},
+		{Name: "ch4", Apps: []string{"myapp", "otherapp"}},
+	}
+	for _, ch := range channels {
+		err := s.NotificationStorage.Insert(ch)
+		c.Assert(err, check.IsNil)
+	}
+	tests := []struct {
+		apps, pools []string
+		expected    []string
+	}{
+		{apps: []string{"myapp"}, expected: []string{"ch1", "ch4"}},
+		{apps: []string{"myapp"}, pools: []string{"pool1"}, expected: []string{"ch1", "ch2", "ch4"}},
+		{pools: []string{"pool2"}, expected: []string{"ch3"}},
+		{apps: []string{"unknown"}, pools: []string{"unknown"}},
+		{},
+	}
+	for i, tt := range tests {
+		result, err := s.NotificationStorage.FindBySubscription(tt.apps, tt.pools)
+		c.Assert(err, check.IsNil)
+		c.Assert(notificationChannelsNames(result), check.DeepEquals, tt.expected, check.Commentf("failed test %d", i))
+	}
+}
+
+func (s *NotificationSuite) TestFindAllByTeams(c *check.C) {
+	err := s.NotificationStorage.Insert(eventTypes.NotificationChannel{Name: "ch1", TeamOwner: "t1"})
+	c.Assert(err, check.IsNil)
+	err = s.NotificationStorage.Insert(eventTypes.NotificationChannel{Name: "ch2", TeamOwner: "t2"})
+	c.Assert(err, check.IsNil)
+	channels, err := s.NotificationStorage.FindAllByTeams(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(notificationChannelsNames(channels), check.DeepEquals, []string{"ch1", "ch2"})
+	channels, err = s.NotificationStorage.FindAllByTeams([]string{})
+	c.Assert(err, check.IsNil)
+	c.Assert(notificationChannelsNames(channels), check.IsNil)
+	channels, err = s.NotificationStorage.FindAllByTeams([]string{"t1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(notificationChannelsNames(channels), check.DeepEquals, []string{"ch1"})
+}
+
+func (s *NotificationSuite) TestUpdate(c *check.C) {
+	ch := eventTypes.NotificationChannel{Name: "ch1", Driver: "slack", Apps: []string{"myapp"}}
+	err := s.NotificationStorage.Insert(ch)
+	c.Assert(err, check.IsNil)
+	ch.Driver = "teams"
+	ch.Pools = []string{"pool1"}
+	err = s.NotificationStorage.Update(ch)
+	c.Assert(err, check.IsNil)
+	dbCh, err := s.NotificationStorage.FindByName("ch1")
+	c.Assert(err, check.IsNil)
+	c.Assert(dbCh.Driver, check.Equals, "teams")
+	c.Assert(dbCh.Apps, check.DeepEquals, []string{"myapp"})
+	c.Assert(dbCh.Pools, check.DeepEquals, []string{"pool1"})
+}
+
+func (s *NotificationSuite) TestUpdateNotFound(c *check.C) {
+	err := s.NotificationStorage.Update(eventTypes.NotificationChannel{Name: "ch1"})
+	c.Assert(err, check.Equals, eventTypes.ErrNotificationChannelNotFound)
+}
+
+func (s *NotificationSuite) TestDelete(c *check.C) {
+	err := s.NotificationStorage.Insert(eventTypes.NotificationChannel{Name: "ch1"})
+	c.Assert(err, check.IsNil)
+	err = s.NotificationStorage.Delete("ch1")
+	c.Assert(err, check.IsNil)
+	err = s.NotificationStorage.Delete("ch1")
+	c.Assert(err, check.Equals, eventTypes.ErrNotificationChannelNotFound)
+	_, err = s.NotificationStorage.FindByName("ch1")
+	c.Assert(err, check.Equals, eventTypes.ErrNotificationChannelNotFound)
+}
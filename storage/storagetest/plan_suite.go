@@ -80,6 +80,27 @@ func (s *PlanSuite) TestInsertDefaultPlan(c *check.C) {
 	c.Assert(sortedPlans[2].Default, check.Equals, true)
 }
 
+func (s *PlanSuite) TestUpdatePlan(c *check.C) {
+	err := s.PlanStorage.Insert(context.TODO(), app.Plan{Name: "plan1", Default: true})
+	c.Assert(err, check.IsNil)
+	err = s.PlanStorage.Insert(context.TODO(), app.Plan{Name: "plan2"})
+	c.Assert(err, check.IsNil)
+	p := app.Plan{Name: "plan2", Memory: 4194304, CPUQuota: 50000, CPUPeriod: 100000, BlkioWeight: 300, PidsLimit: 512, Default: true}
+	err = s.PlanStorage.Update(context.TODO(), p)
+	c.Assert(err, check.IsNil)
+	plan, err := s.PlanStorage.FindByName(context.TODO(), "plan2")
+	c.Assert(err, check.IsNil)
+	c.Assert(*plan, check.DeepEquals, p)
+	plan, err = s.PlanStorage.FindDefault(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(plan.Name, check.Equals, "plan2")
+}
+
+func (s *PlanSuite) TestUpdatePlanNotFound(c *check.C) {
+	err := s.PlanStorage.Update(context.TODO(), app.Plan{Name: "unknown"})
+	c.Assert(err, check.Equals, app.ErrPlanNotFound)
+}
+
 func (s *PlanSuite) TestFindAllPlans(c *check.C) {
 	err := s.PlanStorage.Insert(context.TODO(), app.Plan{Name: "plan1"})
 	c.Assert(err, check.IsNil)
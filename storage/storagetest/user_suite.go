@@ -0,0 +1,166 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package storagetest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tsuru/tsuru/types/auth"
+	"github.com/tsuru/tsuru/types/quota"
+	check "gopkg.in/check.v1"
+)
+
+type UserSuite struct {
+	SuiteHooks
+	UserStorage auth.UserStorage
+}
+
+func (s *UserSuite) TestInsertUser(c *check.C) {
+	u := auth.User{
+		Email:    "me@example.com",
+		Password: "hashed",
+		APIKey:   "key",
+		Quota:    quota.Quota{Limit: 2, InUse: 1},
+		Roles:    []auth.RoleInstance{{Name: "role1", ContextValue: "team1"}},
+		Groups:   []string{"group1"},
+	}
+	err := s.UserStorage.Insert(context.TODO(), u)
+	c.Assert(err, check.IsNil)
+	user, err := s.UserStorage.FindByEmail(context.TODO(), u.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(*user, check.DeepEquals, u)
+}
+
+func (s *UserSuite) TestInsertDuplicateUser(c *check.C) {
+	u := auth.User{Email: "me@example.com"}
+	err := s.UserStorage.Insert(context.TODO(), u)
+	c.Assert(err, check.IsNil)
+	err = s.UserStorage.Insert(context.TODO(), u)
+	c.Assert(err, check.Equals, auth.ErrUserAlreadyExists)
+}
+
+func (s *UserSuite) TestUpdateUser(c *check.C) {
+	u := auth.User{Email: "me@example.com", Password: "hashed"}
+	err := s.UserStorage.Insert(context.TODO(), u)
+	c.Assert(err, check.IsNil)
+	u.Password = "otherhash"
+	u.Groups = []string{"group1"}
+	err = s.UserStorage.Update(context.TODO(), u)
+	c.Assert(err, check.IsNil)
+	user, err := s.UserStorage.FindByEmail(context.TODO(), u.Email)
+	c.Assert(err, check.IsNil)
+	c.Assert(user.Password, check.Equals, "otherhash")
+	c.Assert(user.Groups, check.DeepEquals, []string{"group1"})
+}
+
+func (s *UserSuite) TestUpdateUserNotFound(c *check.C) {
+	err := s.UserStorage.Update(context.TODO(), auth.User{Email: "me@example.com"})
+	c.Assert(err, check.Equals, auth.ErrUserNotFound)
+}
+
+func (s *UserSuite) TestFindAllUsers(c *check.C) {
+	err := s.UserStorage.Insert(context.TODO(), auth.User{Email: "paul@example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.UserStorage.Insert(context.TODO(), auth.User{Email: "leto@example.com"})
+	c.Assert(err, check.IsNil)
+	users, err := s.UserStorage.FindAll(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(users, check.HasLen, 2)
+	emails := []string{users[0].Email, users[1].Email}
+	sort.Strings(emails)
+	c.Assert(emails, check.DeepEquals, []string{"leto@example.com", "paul@example.com"})
+}
+
+func (s *UserSuite) TestFindUserByEmailNotFound(c *check.C) {
+	user, err := s.UserStorage.FindByEmail(context.TODO(), "me@example.com")
+	c.Assert(err, check.Equals, auth.ErrUserNotFound)
+	c.Assert(user, check.IsNil)
+}
+
+func (s *UserSuite) TestFindUserByAPIKey(c *check.C) {
+	err := s.UserStorage.Insert(context.TODO(), auth.User{Email: "me@example.com", APIKey: "key1"})
+	c.Assert(err, check.IsNil)
+	user, err := s.UserStorage.FindByAPIKey(context.TODO(), "key1")
+	c.Assert(err, check.IsNil)
+	c.Assert(user.Email, check.Equals, "me@example.com")
+	_, err = s.UserStorage.FindByAPIKey(context.TODO(), "key2")
+	c.Assert(err, check.Equals, auth.ErrUserNotFound)
+}
+
+func (s *UserSuite) TestFindUsersByRole(c *check.C) {
+	err := s.UserStorage.Insert(context.TODO(), auth.User{
+		Email: "paul@example.com",
+		Roles: []auth.RoleInstance{{Name: "admin"}},
+	})
+	c.Assert(err, check.IsNil)
+	err = s.UserStorage.Insert(context.TODO(), auth.User{
+		Email: "leto@example.com",
+		Roles: []auth.RoleInstance{{Name: "viewer", ContextValue: "team1"}},
+	})
+	c.Assert(err, check.IsNil)
+	users, err := s.UserStorage.FindByRole(context.TODO(), "viewer")
+	c.Assert(err, check.IsNil)
+	c.Assert(users, check.HasLen, 1)
+	c.Assert(users[0].Email, check.Equals, "leto@example.com")
+}
+
+func (s *UserSuite) TestDeleteUser(c *check.C) {
+	err := s.UserStorage.Insert(context.TODO(), auth.User{Email: "me@example.com"})
+	c.Assert(err, check.IsNil)
+	err = s.UserStorage.Delete(context.TODO(), "me@example.com")
+	c.Assert(err, check.IsNil)
+	_, err = s.UserStorage.FindByEmail(context.TODO(), "me@example.com")
+	c.Assert(err, check.Equals, auth.ErrUserNotFound)
+}
+
+func (s *UserSuite) TestDeleteUserNotFound(c *check.C) {
+	err := s.UserStorage.Delete(context.TODO(), "me@example.com")
+	c.Assert(err, check.Equals, auth.ErrUserNotFound)
+}
+
+func (s *UserSuite) TestAddAndRemoveRole(c *check.C) {
+	err := s.UserStorage.Insert(context.TODO(), auth.User{Email: "me@example.com"})
+	c.Assert(err, check.IsNil)
+	role1 := auth.RoleInstance{Name: "role1", ContextValue: "team1"}
+	role2 := auth.RoleInstance{Name: "role1", ContextValue: "team2"}
+	err = s.UserStorage.AddRole(context.TODO(), "me@example.com", role1)
+	c.Assert(err, check.IsNil)
+	err = s.UserStorage.AddRole(context.TODO(), "me@example.com", role1)
+	c.Assert(err, check.IsNil)
+	err = s.UserStorage.AddRole(context.TODO(), "me@example.com", role2)
+	c.Assert(err, check.IsNil)
+	user, err := s.UserStorage.FindByEmail(context.TODO(), "me@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(user.Roles, check.DeepEquals, []auth.RoleInstance{role1, role2})
+	err = s.UserStorage.RemoveRole(context.TODO(), "me@example.com", role1)
+	c.Assert(err, check.IsNil)
+	user, err = s.UserStorage.FindByEmail(context.TODO(), "me@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(user.Roles, check.DeepEquals, []auth.RoleInstance{role2})
+}
+
+func (s *UserSuite) TestAddRoleUserNotFound(c *check.C) {
+	err := s.UserStorage.AddRole(context.TODO(), "me@example.com", auth.RoleInstance{Name: "role1"})
+	c.Assert(err, check.Equals, auth.ErrUserNotFound)
+}
+
+func (s *UserSuite) TestRemoveRoleFromAll(c *check.C) {
+	for _, email := range []string{"paul@example.com", "leto@example.com"} {
+		err := s.UserStorage.Insert(context.TODO(), auth.User{
+			Email: email,
+			Roles: []auth.RoleInstance{{Name: "role1", ContextValue: "a"}, {Name: "role2"}, {Name: "role1", ContextValue: "b"}},
+		})
+		c.Assert(err, check.IsNil)
+	}
+	err := s.UserStorage.RemoveRoleFromAll(context.TODO(), "role1")
+	c.Assert(err, check.IsNil)
+	users, err := s.UserStorage.FindAll(context.TODO())
+	c.Assert(err, check.IsNil)
+	c.Assert(users, check.HasLen, 2)
+	for _, u := range users {
+		c.Assert(u.Roles, check.DeepEquals, []auth.RoleInstance{{Name: "role2"}})
+	}
+}
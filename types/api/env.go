@@ -12,6 +12,10 @@ type Envs struct {
 	NoRestart   bool
 	Private     bool
 	PruneUnused bool `json:"pruneUnused"`
+	// RollingRestart replaces the units in batches of BatchSize units instead
+	// of restarting all of them at once.
+	RollingRestart bool `json:"rollingRestart"`
+	BatchSize      int  `json:"batchSize"`
 }
 
 type Env struct {
@@ -0,0 +1,95 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+// Resource types are the canonical representations of tsuru objects used by
+// declarative clients, such as the Terraform provider. Slices are always
+// sorted and never null, so that two reads of an unchanged object are
+// byte-for-byte identical. The Revision field identifies the state of the
+// object and must be sent back in conditional updates.
+
+const (
+	ResourceTypeApp             = "app"
+	ResourceTypePool            = "pool"
+	ResourceTypeRole            = "role"
+	ResourceTypeServiceBinding  = "service-binding"
+	ResourceTypePlan            = "plan"
+	ResourceTypeServiceInstance = "service-instance"
+)
+
+type AppResource struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Platform    string   `json:"platform"`
+	Pool        string   `json:"pool"`
+	Plan        string   `json:"plan"`
+	TeamOwner   string   `json:"team_owner"`
+	Teams       []string `json:"teams"`
+	Tags        []string `json:"tags"`
+	CNames      []string `json:"cnames"`
+	Revision    string   `json:"revision,omitempty"`
+}
+
+type PoolResource struct {
+	Name        string            `json:"name"`
+	Provisioner string            `json:"provisioner"`
+	Default     bool              `json:"default"`
+	Public      bool              `json:"public"`
+	Labels      map[string]string `json:"labels"`
+	Teams       []string          `json:"teams"`
+	Revision    string            `json:"revision,omitempty"`
+}
+
+type RoleResource struct {
+	Name        string   `json:"name"`
+	Context     string   `json:"context"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	Events      []string `json:"events"`
+	Revision    string   `json:"revision,omitempty"`
+}
+
+type ServiceBindingResource struct {
+	Service  string   `json:"service"`
+	Instance string   `json:"instance"`
+	App      string   `json:"app"`
+	Envs     []string `json:"envs"`
+	Revision string   `json:"revision,omitempty"`
+}
+
+type PlanResource struct {
+	Name        string `json:"name"`
+	Memory      int64  `json:"memory"`
+	Swap        int64  `json:"swap"`
+	CPUMilli    int    `json:"cpumilli"`
+	CPUQuota    int64  `json:"cpuquota"`
+	CPUPeriod   int64  `json:"cpuperiod"`
+	BlkioWeight int64  `json:"blkioweight"`
+	PidsLimit   int64  `json:"pidslimit"`
+	Default     bool   `json:"default"`
+	Revision    string `json:"revision,omitempty"`
+}
+
+type ServiceInstanceResource struct {
+	Service     string                 `json:"service"`
+	Name        string                 `json:"name"`
+	Plan        string                 `json:"plan"`
+	TeamOwner   string                 `json:"team_owner"`
+	Teams       []string               `json:"teams"`
+	Description string                 `json:"description"`
+	Tags        []string               `json:"tags"`
+	Pool        string                 `json:"pool"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	Revision    string                 `json:"revision,omitempty"`
+}
+
+// ImportResource is returned by the import endpoint, wrapping the canonical
+// representation of any resource type.
+type ImportResource struct {
+	Type     string      `json:"type"`
+	ID       string      `json:"id"`
+	Revision string      `json:"revision"`
+	Resource interface{} `json:"resource"`
+}
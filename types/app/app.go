@@ -9,6 +9,7 @@ import (
 	"net/url"
 
 	"github.com/tsuru/tsuru/types/app/image"
+	routerTypes "github.com/tsuru/tsuru/types/router"
 )
 
 type App interface {
@@ -24,19 +25,26 @@ type App interface {
 }
 
 type AppRouter struct {
-	Name         string            `json:"name"`
-	Opts         map[string]string `json:"opts"`
-	Address      string            `json:"address" bson:"-"`
-	Addresses    []string          `json:"addresses" bson:"-"`
-	Type         string            `json:"type" bson:"-"`
-	Status       string            `json:"status,omitempty" bson:"-"`
-	StatusDetail string            `json:"status-detail,omitempty" bson:"-"`
+	Name         string                  `json:"name"`
+	Opts         map[string]string       `json:"opts"`
+	Rules        []routerTypes.RouteRule `json:"rules,omitempty" bson:",omitempty"`
+	Address      string                  `json:"address" bson:"-"`
+	Addresses    []string                `json:"addresses" bson:"-"`
+	Type         string                  `json:"type" bson:"-"`
+	Status       string                  `json:"status,omitempty" bson:"-"`
+	StatusDetail string                  `json:"status-detail,omitempty" bson:"-"`
 }
 
 type RoutableAddresses struct {
 	Prefix    string
 	Addresses []*url.URL
 	ExtraData map[string]string
+	// Port is the name of the process port the addresses point to, it's
+	// empty for the main port.
+	Port string
+	// Hostnames are the hostnames routed to the addresses, besides the
+	// ones derived from the prefix.
+	Hostnames []string
 }
 
 type Filter struct {
@@ -47,6 +55,7 @@ type Filter struct {
 	UserOwner   string
 	Pool        string
 	Pools       []string
+	Project     string
 	Statuses    []string
 	Locked      bool
 	Tags        []string
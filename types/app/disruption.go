@@ -0,0 +1,67 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tsuru/tsuru/errors"
+)
+
+// DisruptionSettings are the rollout and disruption budget settings of the
+// units of an app. Values are either a number of units or a percentage of
+// them, like "2" or "25%". Empty values use the provisioner defaults.
+type DisruptionSettings struct {
+	// MaxSurge is the number of units created above the desired number of
+	// units during rollouts.
+	MaxSurge string `json:"maxSurge,omitempty" bson:",omitempty"`
+	// MaxUnavailable is the number of units that may be unavailable during
+	// rollouts.
+	MaxUnavailable string `json:"maxUnavailable,omitempty" bson:",omitempty"`
+	// PDBMinAvailable is the number of units that must remain available
+	// during voluntary disruptions, like node drains.
+	PDBMinAvailable string `json:"pdbMinAvailable,omitempty" bson:",omitempty"`
+}
+
+func (s DisruptionSettings) IsEmpty() bool {
+	return s == DisruptionSettings{}
+}
+
+func (s DisruptionSettings) Validate() error {
+	values := []struct {
+		name, value string
+	}{
+		{"maxSurge", s.MaxSurge},
+		{"maxUnavailable", s.MaxUnavailable},
+		{"pdbMinAvailable", s.PDBMinAvailable},
+	}
+	for _, v := range values {
+		if v.value == "" {
+			continue
+		}
+		if !validUnitsValue(v.value) {
+			return &errors.ValidationError{Message: fmt.Sprintf("invalid %s %q, must be a number of units or a percentage", v.name, v.value)}
+		}
+	}
+	if isZeroUnits(s.MaxSurge) && isZeroUnits(s.MaxUnavailable) {
+		return &errors.ValidationError{Message: "maxSurge and maxUnavailable can't be both zero"}
+	}
+	return nil
+}
+
+func validUnitsValue(value string) bool {
+	percentage := strings.HasSuffix(value, "%")
+	n, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if err != nil || n < 0 {
+		return false
+	}
+	return !percentage || n <= 100
+}
+
+func isZeroUnits(value string) bool {
+	return value == "0" || value == "0%"
+}
@@ -0,0 +1,30 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import "gopkg.in/check.v1"
+
+func (s S) TestDisruptionSettingsValidate(c *check.C) {
+	tests := []struct {
+		settings DisruptionSettings
+		msg      string
+	}{
+		{DisruptionSettings{}, ""},
+		{DisruptionSettings{MaxSurge: "25%", MaxUnavailable: "1", PDBMinAvailable: "50%"}, ""},
+		{DisruptionSettings{MaxSurge: "0", MaxUnavailable: "10%"}, ""},
+		{DisruptionSettings{MaxSurge: "abc"}, `invalid maxSurge "abc", must be a number of units or a percentage`},
+		{DisruptionSettings{MaxUnavailable: "-1"}, `invalid maxUnavailable "-1", must be a number of units or a percentage`},
+		{DisruptionSettings{PDBMinAvailable: "120%"}, `invalid pdbMinAvailable "120%", must be a number of units or a percentage`},
+		{DisruptionSettings{MaxSurge: "0%", MaxUnavailable: "0"}, "maxSurge and maxUnavailable can't be both zero"},
+	}
+	for _, tt := range tests {
+		err := tt.settings.Validate()
+		if tt.msg == "" {
+			c.Check(err, check.IsNil)
+			continue
+		}
+		c.Check(err, check.ErrorMatches, tt.msg)
+	}
+}
@@ -35,6 +35,7 @@ type ListLogArgs struct {
 	Units        []string
 	Limit        int
 	InvertSource bool
+	RequestID    string
 	Token        auth.Token
 }
 
@@ -46,4 +47,7 @@ type Applog struct {
 	Source  string
 	AppName string
 	Unit    string
+	// RequestID is the request id found in the message, set when
+	// log:request-id-pattern is configured.
+	RequestID string `json:",omitempty"`
 }
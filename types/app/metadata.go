@@ -6,6 +6,7 @@ package app
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/tsuru/tsuru/errors"
@@ -42,6 +43,27 @@ func (m *Metadata) Validate() error {
 	return nil
 }
 
+// MetadataFromMaps returns the Metadata holding the given labels and
+// annotations, sorted by name.
+func MetadataFromMaps(labels, annotations map[string]string) Metadata {
+	return Metadata{
+		Labels:      itemsFromMap(labels),
+		Annotations: itemsFromMap(annotations),
+	}
+}
+
+func itemsFromMap(m map[string]string) []MetadataItem {
+	if len(m) == 0 {
+		return nil
+	}
+	items := make([]MetadataItem, 0, len(m))
+	for name, value := range m {
+		items = append(items, MetadataItem{Name: name, Value: value})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
 func (m Metadata) Annotation(v string) (string, bool) {
 	return getItem(m.Annotations, v)
 }
@@ -22,6 +22,13 @@ func (s S) TestMetadataUpdateList(c *check.C) {
 	c.Assert(result, check.DeepEquals, []MetadataItem{{Name: "found-item", Value: "new-value"}})
 }
 
+func (s S) TestMetadataFromMaps(c *check.C) {
+	m := MetadataFromMaps(map[string]string{"b": "2", "a": "1"}, nil)
+	c.Assert(m, check.DeepEquals, Metadata{Labels: []MetadataItem{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}})
+	m = MetadataFromMaps(nil, map[string]string{"tsuru.io/x": "1"})
+	c.Assert(m.Validate(), check.ErrorMatches, "(?s).*prefix tsuru.io/ is private.*")
+}
+
 func Test(t *testing.T) {
 	check.TestingT(t)
 }
@@ -0,0 +1,146 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tsuru/tsuru/errors"
+)
+
+// Operators of placement constraints, named after the kubernetes node
+// selector operators.
+const (
+	PlacementOpIn           = "In"
+	PlacementOpNotIn        = "NotIn"
+	PlacementOpExists       = "Exists"
+	PlacementOpDoesNotExist = "DoesNotExist"
+)
+
+var (
+	placementKeyRegexp  = regexp.MustCompile(`^[a-zA-Z0-9][-_./a-zA-Z0-9]*$`)
+	placementListRegexp = regexp.MustCompile(`^(\S+)\s+(?i:(in|notin))\s*[\[(](.*)[\])]$`)
+)
+
+// PlacementConstraint is a requirement of an app on the metadata of the
+// nodes running its units.
+type PlacementConstraint struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty" bson:",omitempty"`
+}
+
+// ParsePlacementConstraint parses a node selector expression. Supported
+// expressions are "key=value", "key!=value", "key in [a,b]",
+// "key notin [a,b]", "key" and "!key", the last two requiring the key to be
+// present or absent.
+func ParsePlacementConstraint(expr string) (PlacementConstraint, error) {
+	expr = strings.TrimSpace(expr)
+	var constraint PlacementConstraint
+	if parts := placementListRegexp.FindStringSubmatch(expr); parts != nil {
+		constraint.Key = parts[1]
+		constraint.Operator = PlacementOpIn
+		if strings.EqualFold(parts[2], "notin") {
+			constraint.Operator = PlacementOpNotIn
+		}
+		for _, v := range strings.Split(parts[3], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				constraint.Values = append(constraint.Values, v)
+			}
+		}
+	} else if parts := strings.SplitN(expr, "!=", 2); len(parts) == 2 {
+		constraint = PlacementConstraint{Key: strings.TrimSpace(parts[0]), Operator: PlacementOpNotIn, Values: []string{strings.TrimSpace(parts[1])}}
+	} else if parts := strings.SplitN(expr, "=", 2); len(parts) == 2 {
+		constraint = PlacementConstraint{Key: strings.TrimSpace(parts[0]), Operator: PlacementOpIn, Values: []string{strings.TrimSpace(parts[1])}}
+	} else if strings.HasPrefix(expr, "!") {
+		constraint = PlacementConstraint{Key: strings.TrimSpace(expr[1:]), Operator: PlacementOpDoesNotExist}
+	} else {
+		constraint = PlacementConstraint{Key: expr, Operator: PlacementOpExists}
+	}
+	err := constraint.Validate()
+	if err != nil {
+		return PlacementConstraint{}, &errors.ValidationError{Message: fmt.Sprintf("invalid placement constraint %q: %s", expr, err)}
+	}
+	return constraint, nil
+}
+
+func (c PlacementConstraint) Validate() error {
+	if !placementKeyRegexp.MatchString(c.Key) {
+		return &errors.ValidationError{Message: fmt.Sprintf("invalid key %q", c.Key)}
+	}
+	switch c.Operator {
+	case PlacementOpIn, PlacementOpNotIn:
+		if len(c.Values) == 0 {
+			return &errors.ValidationError{Message: fmt.Sprintf("operator %s requires at least one value", c.Operator)}
+		}
+		for _, v := range c.Values {
+			if v == "" {
+				return &errors.ValidationError{Message: "values can't be empty"}
+			}
+		}
+	case PlacementOpExists, PlacementOpDoesNotExist:
+		if len(c.Values) > 0 {
+			return &errors.ValidationError{Message: fmt.Sprintf("operator %s doesn't accept values", c.Operator)}
+		}
+	default:
+		return &errors.ValidationError{Message: fmt.Sprintf("unknown operator %q", c.Operator)}
+	}
+	return nil
+}
+
+func (c PlacementConstraint) String() string {
+	switch c.Operator {
+	case PlacementOpIn, PlacementOpNotIn:
+		if len(c.Values) == 1 {
+			op := "="
+			if c.Operator == PlacementOpNotIn {
+				op = "!="
+			}
+			return c.Key + op + c.Values[0]
+		}
+		return fmt.Sprintf("%s %s [%s]", c.Key, strings.ToLower(c.Operator), strings.Join(c.Values, ","))
+	case PlacementOpDoesNotExist:
+		return "!" + c.Key
+	}
+	return c.Key
+}
+
+// Matches returns whether a node with the given metadata satisfies the
+// constraint.
+func (c PlacementConstraint) Matches(metadata map[string]string) bool {
+	value, ok := metadata[c.Key]
+	switch c.Operator {
+	case PlacementOpExists:
+		return ok
+	case PlacementOpDoesNotExist:
+		return !ok
+	}
+	var found bool
+	if ok {
+		for _, v := range c.Values {
+			if v == value {
+				found = true
+				break
+			}
+		}
+	}
+	if c.Operator == PlacementOpNotIn {
+		return !found
+	}
+	return found
+}
+
+// PlacementMatches returns whether a node with the given metadata satisfies
+// all the constraints.
+func PlacementMatches(constraints []PlacementConstraint, metadata map[string]string) bool {
+	for _, c := range constraints {
+		if !c.Matches(metadata) {
+			return false
+		}
+	}
+	return true
+}
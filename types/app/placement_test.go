@@ -0,0 +1,57 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import "gopkg.in/check.v1"
+
+func (s S) TestParsePlacementConstraint(c *check.C) {
+	tests := []struct {
+		expr     string
+		expected PlacementConstraint
+		msg      string
+	}{
+		{"disk=ssd", PlacementConstraint{Key: "disk", Operator: PlacementOpIn, Values: []string{"ssd"}}, ""},
+		{" disk != hdd ", PlacementConstraint{Key: "disk", Operator: PlacementOpNotIn, Values: []string{"hdd"}}, ""},
+		{"zone in [a, b]", PlacementConstraint{Key: "zone", Operator: PlacementOpIn, Values: []string{"a", "b"}}, ""},
+		{"zone NotIn (c)", PlacementConstraint{Key: "zone", Operator: PlacementOpNotIn, Values: []string{"c"}}, ""},
+		{"gpu", PlacementConstraint{Key: "gpu", Operator: PlacementOpExists}, ""},
+		{"!gpu", PlacementConstraint{Key: "gpu", Operator: PlacementOpDoesNotExist}, ""},
+		{"disk=", PlacementConstraint{}, `invalid placement constraint "disk=": values can't be empty`},
+		{"zone in []", PlacementConstraint{}, `invalid placement constraint "zone in \[\]": operator In requires at least one value`},
+		{"=ssd", PlacementConstraint{}, `invalid placement constraint "=ssd": invalid key ""`},
+		{"disk type", PlacementConstraint{}, `invalid placement constraint "disk type": invalid key "disk type"`},
+	}
+	for _, tt := range tests {
+		constraint, err := ParsePlacementConstraint(tt.expr)
+		if tt.msg != "" {
+			c.Check(err, check.ErrorMatches, tt.msg, check.Commentf("expr: %q", tt.expr))
+			continue
+		}
+		c.Check(err, check.IsNil, check.Commentf("expr: %q", tt.expr))
+		c.Check(constraint, check.DeepEquals, tt.expected, check.Commentf("expr: %q", tt.expr))
+	}
+}
+
+func (s S) TestPlacementConstraintString(c *check.C) {
+	for _, expr := range []string{"disk=ssd", "disk!=hdd", "zone in [a,b]", "zone notin [a,b]", "gpu", "!gpu"} {
+		constraint, err := ParsePlacementConstraint(expr)
+		c.Assert(err, check.IsNil)
+		c.Check(constraint.String(), check.Equals, expr)
+	}
+}
+
+func (s S) TestPlacementMatches(c *check.C) {
+	constraints := []PlacementConstraint{
+		{Key: "disk", Operator: PlacementOpIn, Values: []string{"ssd"}},
+		{Key: "zone", Operator: PlacementOpNotIn, Values: []string{"c"}},
+		{Key: "gpu", Operator: PlacementOpDoesNotExist},
+	}
+	c.Check(PlacementMatches(constraints, map[string]string{"disk": "ssd", "zone": "a"}), check.Equals, true)
+	c.Check(PlacementMatches(constraints, map[string]string{"disk": "ssd"}), check.Equals, true)
+	c.Check(PlacementMatches(constraints, map[string]string{"disk": "hdd", "zone": "a"}), check.Equals, false)
+	c.Check(PlacementMatches(constraints, map[string]string{"disk": "ssd", "zone": "c"}), check.Equals, false)
+	c.Check(PlacementMatches(constraints, map[string]string{"disk": "ssd", "gpu": "true"}), check.Equals, false)
+	c.Check(PlacementMatches(nil, map[string]string{}), check.Equals, true)
+}
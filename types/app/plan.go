@@ -11,10 +11,18 @@ type Plan struct {
 	Memory int64  `json:"memory"`
 	Swap   int64  `json:"swap"`
 	// CpuShare is DEPRECATED, use CPUMilli instead
-	CpuShare int          `json:"cpushare"`
-	CPUMilli int          `json:"cpumilli"`
-	Default  bool         `json:"default,omitempty"`
-	Override PlanOverride `json:"override,omitempty"`
+	CpuShare int `json:"cpushare"`
+	CPUMilli int `json:"cpumilli"`
+	// CPUQuota and CPUPeriod, in microseconds, set a hard limit of CPU
+	// usage for each unit: CPUQuota of CPU time on every CPUPeriod.
+	CPUQuota  int64 `json:"cpuquota,omitempty"`
+	CPUPeriod int64 `json:"cpuperiod,omitempty"`
+	// BlkioWeight is the relative weight of block IO, between 10 and 1000.
+	BlkioWeight int64 `json:"blkioweight,omitempty"`
+	// PidsLimit is the maximum number of processes in each unit.
+	PidsLimit int64        `json:"pidslimit,omitempty"`
+	Default   bool         `json:"default,omitempty"`
+	Override  PlanOverride `json:"override,omitempty"`
 }
 
 type PlanOverride struct {
@@ -41,6 +49,7 @@ func (p *Plan) MergeOverride(po PlanOverride) {
 
 type PlanService interface {
 	Create(ctx context.Context, plan Plan) error
+	Update(ctx context.Context, plan Plan) error
 	List(context.Context) ([]Plan, error)
 	FindByName(ctx context.Context, name string) (*Plan, error)
 	DefaultPlan(context.Context) (*Plan, error)
@@ -49,6 +58,7 @@ type PlanService interface {
 
 type PlanStorage interface {
 	Insert(context.Context, Plan) error
+	Update(context.Context, Plan) error
 	FindAll(context.Context) ([]Plan, error)
 	FindDefault(context.Context) (*Plan, error)
 	FindByName(context.Context, string) (*Plan, error)
@@ -11,6 +11,7 @@ var _ PlanStorage = &MockPlanStorage{}
 // MockPlanStorage implements PlanStorage interface
 type MockPlanStorage struct {
 	OnInsert      func(Plan) error
+	OnUpdate      func(Plan) error
 	OnFindAll     func() ([]Plan, error)
 	OnFindDefault func() (*Plan, error)
 	OnFindByName  func(string) (*Plan, error)
@@ -21,6 +22,10 @@ func (m *MockPlanStorage) Insert(ctx context.Context, p Plan) error {
 	return m.OnInsert(p)
 }
 
+func (m *MockPlanStorage) Update(ctx context.Context, p Plan) error {
+	return m.OnUpdate(p)
+}
+
 func (m *MockPlanStorage) FindAll(ctx context.Context) ([]Plan, error) {
 	return m.OnFindAll()
 }
@@ -40,6 +45,7 @@ func (m *MockPlanStorage) Delete(ctx context.Context, p Plan) error {
 // MockPlanService implements PlanService interface
 type MockPlanService struct {
 	OnCreate      func(Plan) error
+	OnUpdate      func(Plan) error
 	OnList        func() ([]Plan, error)
 	OnFindByName  func(string) (*Plan, error)
 	OnDefaultPlan func() (*Plan, error)
@@ -53,6 +59,13 @@ func (m *MockPlanService) Create(ctx context.Context, plan Plan) error {
 	return m.OnCreate(plan)
 }
 
+func (m *MockPlanService) Update(ctx context.Context, plan Plan) error {
+	if m.OnUpdate == nil {
+		return nil
+	}
+	return m.OnUpdate(plan)
+}
+
 func (m *MockPlanService) List(ctx context.Context) ([]Plan, error) {
 	if m.OnList == nil {
 		return nil, nil
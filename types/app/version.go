@@ -54,9 +54,10 @@ type AppVersion interface {
 }
 
 type AddVersionDataArgs struct {
-	Processes    map[string][]string
-	CustomData   map[string]interface{}
-	ExposedPorts []string
+	Processes     map[string][]string
+	CustomData    map[string]interface{}
+	ExposedPorts  []string
+	Architectures []string
 }
 
 type AppVersions struct {
@@ -86,6 +87,9 @@ type AppVersionInfo struct {
 	DeploySuccessful bool                   `json:"deploySuccessful"`
 	MarkedToRemoval  bool                   `json:"markedToRemoval"`
 	PastUnits        map[string]int         `json:"pastUnits"`
+	// Architectures are the CPU architectures the version image was built
+	// for, like amd64 and arm64, empty when unknown.
+	Architectures []string `json:"architectures,omitempty"`
 }
 
 type NewVersionArgs struct {
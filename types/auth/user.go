@@ -5,6 +5,7 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -29,6 +30,19 @@ type RoleInstance struct {
 	ContextValue string
 }
 
+type UserStorage interface {
+	Insert(context.Context, User) error
+	Update(context.Context, User) error
+	FindAll(context.Context) ([]User, error)
+	FindByEmail(context.Context, string) (*User, error)
+	FindByAPIKey(context.Context, string) (*User, error)
+	FindByRole(context.Context, string) ([]User, error)
+	Delete(context.Context, string) error
+	AddRole(ctx context.Context, email string, role RoleInstance) error
+	RemoveRole(ctx context.Context, email string, role RoleInstance) error
+	RemoveRoleFromAll(ctx context.Context, roleName string) error
+}
+
 type ErrTeamStillUsed struct {
 	Apps             []string
 	ServiceInstances []string
@@ -36,6 +50,7 @@ type ErrTeamStillUsed struct {
 
 var (
 	ErrUserNotFound       = errors.New("user not found")
+	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrInvalidKey         = errors.New("invalid key")
 	ErrKeyDisabled        = errors.New("key management is disabled")
 	ErrEmailFromTeamToken = errors.New("email from team token")
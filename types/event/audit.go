@@ -0,0 +1,28 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import "time"
+
+// AuditRecord summarizes a completed event for external audit systems.
+type AuditRecord struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	KindType    string    `json:"kindType"`
+	Owner       string    `json:"owner"`
+	OwnerType   string    `json:"ownerType"`
+	TargetType  string    `json:"targetType"`
+	TargetValue string    `json:"targetValue"`
+	SourceIP    string    `json:"sourceIP,omitempty"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+	Duration    float64   `json:"durationSeconds"`
+	Success     bool      `json:"success"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type AuditService interface {
+	Export(AuditRecord)
+}
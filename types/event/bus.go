@@ -0,0 +1,42 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import "time"
+
+const (
+	TransitionCreated  = "created"
+	TransitionUpdated  = "updated"
+	TransitionCanceled = "canceled"
+	TransitionDone     = "done"
+)
+
+type TransitionTarget struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Transition is a change in the lifecycle of an event, published to the
+// event bus.
+type Transition struct {
+	Transition   string             `json:"transition"`
+	Timestamp    time.Time          `json:"timestamp"`
+	ID           string             `json:"id"`
+	Kind         string             `json:"kind"`
+	KindType     string             `json:"kindType"`
+	Owner        string             `json:"owner"`
+	OwnerType    string             `json:"ownerType"`
+	Target       TransitionTarget   `json:"target"`
+	ExtraTargets []TransitionTarget `json:"extraTargets,omitempty"`
+	StartTime    time.Time          `json:"startTime"`
+	EndTime      *time.Time         `json:"endTime,omitempty"`
+	Running      bool               `json:"running"`
+	Error        string             `json:"error,omitempty"`
+	CancelReason string             `json:"cancelReason,omitempty"`
+}
+
+type EventPublisher interface {
+	Publish(Transition)
+}
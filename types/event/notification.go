@@ -0,0 +1,86 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package event
+
+import "errors"
+
+var (
+	ErrNotificationChannelAlreadyExists = errors.New("notification channel already exists with the same name")
+	ErrNotificationChannelNotFound      = errors.New("notification channel not found")
+)
+
+const (
+	NotificationDriverSlack  = "slack"
+	NotificationDriverTeams  = "teams"
+	NotificationDriverMatrix = "matrix"
+)
+
+const (
+	NotificationDeployStart   = "deploy-start"
+	NotificationDeploySuccess = "deploy-success"
+	NotificationDeployFailure = "deploy-failure"
+	NotificationHealing       = "healing"
+	NotificationAutoscale     = "autoscale"
+)
+
+var (
+	NotificationDrivers = []string{NotificationDriverSlack, NotificationDriverTeams, NotificationDriverMatrix}
+	NotificationEvents  = []string{
+		NotificationDeployStart,
+		NotificationDeploySuccess,
+		NotificationDeployFailure,
+		NotificationHealing,
+		NotificationAutoscale,
+	}
+)
+
+// NotificationChannel is a chat channel owned by a team, subscribed to events
+// of apps and pools. For slack and teams drivers, URL is the incoming webhook
+// of the channel. For the matrix driver, URL is the homeserver, Room is the
+// room ID and Token is the access token of the user posting messages.
+type NotificationChannel struct {
+	Name        string   `json:"name" form:"name"`
+	Description string   `json:"description" form:"description"`
+	TeamOwner   string   `json:"team_owner" form:"team_owner"`
+	Driver      string   `json:"driver" form:"driver"`
+	URL         string   `json:"url" form:"url"`
+	Room        string   `json:"room,omitempty" form:"room"`
+	Token       string   `json:"token,omitempty" form:"token"`
+	Apps        []string `json:"apps" form:"apps"`
+	Pools       []string `json:"pools" form:"pools"`
+	Events      []string `json:"events" form:"events"`
+}
+
+// Wants returns whether the channel is subscribed to the notification event,
+// channels without events are subscribed to all of them.
+func (c *NotificationChannel) Wants(notificationEvent string) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, e := range c.Events {
+		if e == notificationEvent {
+			return true
+		}
+	}
+	return false
+}
+
+type NotificationService interface {
+	Notify(evtID, transition string)
+	Create(NotificationChannel) error
+	Update(NotificationChannel) error
+	Delete(string) error
+	Find(string) (NotificationChannel, error)
+	List([]string) ([]NotificationChannel, error)
+}
+
+type NotificationStorage interface {
+	Insert(NotificationChannel) error
+	Update(NotificationChannel) error
+	FindAllByTeams([]string) ([]NotificationChannel, error)
+	FindByName(string) (*NotificationChannel, error)
+	FindBySubscription(apps, pools []string) ([]NotificationChannel, error)
+	Delete(string) error
+}
@@ -16,6 +16,10 @@ var (
 	ErrInvalidRoleName       = errors.New("invalid role name")
 	ErrInvalidPermissionName = errors.New("invalid permission name")
 	ErrRemoveRoleWithUsers   = errors.New("role has users assigned. you must dissociate them before remove the role.")
+	ErrTemplateNotFound      = errors.New("permission template not found")
+	ErrTemplateAlreadyExists = errors.New("permission template already exists")
+	ErrInvalidTemplateName   = errors.New("invalid permission template name")
+	ErrTemplateInUse         = errors.New("permission template is used by roles. you must remove it from them before removing the template.")
 
 	RoleEventUserCreate = &RoleEvent{
 		Name:        "user-create",
@@ -4,17 +4,172 @@
 
 package provision
 
-import "github.com/tsuru/tsuru/types/router"
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tsuru/tsuru/types/router"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
 
 type TsuruYamlData struct {
 	Hooks       *TsuruYamlHooks            `json:"hooks,omitempty" bson:",omitempty"`
 	Healthcheck *TsuruYamlHealthcheck      `json:"healthcheck,omitempty" bson:",omitempty"`
+	Readiness   *TsuruYamlHealthcheck      `json:"readiness,omitempty" bson:",omitempty"`
+	Liveness    *TsuruYamlHealthcheck      `json:"liveness,omitempty" bson:",omitempty"`
 	Kubernetes  *TsuruYamlKubernetesConfig `json:"kubernetes,omitempty" bson:",omitempty"`
+
+	RouterPolicy *router.Policy         `json:"router_policy,omitempty" bson:"router_policy,omitempty"`
+	ExposedPorts []TsuruYamlExposedPort `json:"exposed_ports,omitempty" bson:"exposed_ports,omitempty"`
+
+	Ports map[string][]TsuruYamlProcessPort `json:"ports,omitempty" bson:",omitempty"`
+
+	Init []TsuruYamlInitStep `json:"init,omitempty" bson:",omitempty"`
+
+	RoutableProcesses []TsuruYamlRoutableProcess `json:"routable_processes,omitempty" bson:"routable_processes,omitempty"`
+
+	Startup map[string]TsuruYamlStartup `json:"startup,omitempty" bson:",omitempty"`
+}
+
+// TsuruYamlInitStep is a setup step that must complete before the app
+// process starts on each unit. The command runs in the app image, or in
+// Image when set, in which case the command is optional and defaults to the
+// image entrypoint.
+type TsuruYamlInitStep struct {
+	Name    string `json:"name"`
+	Command string `json:"command,omitempty" bson:",omitempty"`
+	Image   string `json:"image,omitempty" bson:",omitempty"`
+}
+
+// ValidateInit checks that init steps have unique names, valid as
+// container names, and a command or an image.
+func (y TsuruYamlData) ValidateInit() error {
+	names := map[string]struct{}{}
+	for _, step := range y.Init {
+		if msgs := validation.IsDNS1123Label(step.Name); len(msgs) > 0 {
+			return errors.Errorf("invalid init step name %q: %s", step.Name, strings.Join(msgs, ", "))
+		}
+		if _, ok := names[step.Name]; ok {
+			return errors.Errorf("duplicated init step %q", step.Name)
+		}
+		names[step.Name] = struct{}{}
+		if step.Command == "" && step.Image == "" {
+			return errors.Errorf("init step %q requires a command or an image", step.Name)
+		}
+	}
+	return nil
+}
+
+// TsuruYamlRoutableProcess marks a process, besides the web process, as
+// routable. Its units are registered in the app routers under the
+// <process>.process prefix, answering on Hostnames when set. Port is the
+// port the process listens on, defaulting to the port of the web process.
+type TsuruYamlRoutableProcess struct {
+	Process   string   `json:"process"`
+	Hostnames []string `json:"hostnames,omitempty" bson:",omitempty"`
+	Port      int      `json:"port,omitempty" bson:",omitempty"`
+}
+
+// RoutableProcess returns the routing settings of the process, or nil when
+// it's not marked as routable.
+func (y TsuruYamlData) RoutableProcess(process string) *TsuruYamlRoutableProcess {
+	for i := range y.RoutableProcesses {
+		if y.RoutableProcesses[i].Process == process {
+			return &y.RoutableProcesses[i]
+		}
+	}
+	return nil
+}
+
+// ValidateRoutableProcesses checks that routable processes are declared once,
+// with valid ports and hostnames. Process names are used in route prefixes,
+// so they must be valid DNS labels.
+func (y TsuruYamlData) ValidateRoutableProcesses() error {
+	processes := map[string]struct{}{}
+	for _, rp := range y.RoutableProcesses {
+		if msgs := validation.IsDNS1123Label(rp.Process); len(msgs) > 0 {
+			return errors.Errorf("invalid routable process %q: %s", rp.Process, strings.Join(msgs, ", "))
+		}
+		if _, ok := processes[rp.Process]; ok {
+			return errors.Errorf("duplicated routable process %q", rp.Process)
+		}
+		processes[rp.Process] = struct{}{}
+		if rp.Port < 0 || rp.Port > 65535 {
+			return errors.Errorf("invalid port %d for routable process %q", rp.Port, rp.Process)
+		}
+		for _, hostname := range rp.Hostnames {
+			if msgs := validation.IsDNS1123Subdomain(hostname); len(msgs) > 0 {
+				return errors.Errorf("invalid hostname %q for routable process %q: %s", hostname, rp.Process, strings.Join(msgs, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// TsuruYamlStartup gives the units of a process time to boot. Failures of
+// the unit and of its checks are ignored during the grace period after the
+// unit starts, and the unit may take up to the max boot time to become
+// ready before deploys and healers consider it failed.
+type TsuruYamlStartup struct {
+	GracePeriodSeconds int `json:"grace_period_seconds,omitempty" bson:"grace_period_seconds,omitempty"`
+	MaxBootSeconds     int `json:"max_boot_seconds,omitempty" bson:"max_boot_seconds,omitempty"`
+}
+
+// GracePeriod returns the time after the unit starts when its failures are
+// ignored.
+func (s TsuruYamlStartup) GracePeriod() time.Duration {
+	return time.Duration(s.GracePeriodSeconds) * time.Second
+}
+
+// MaxBoot returns the time the unit may take to become ready, never shorter
+// than the grace period.
+func (s TsuruYamlStartup) MaxBoot() time.Duration {
+	if s.MaxBootSeconds < s.GracePeriodSeconds {
+		return s.GracePeriod()
+	}
+	return time.Duration(s.MaxBootSeconds) * time.Second
+}
+
+// StartupForProcess returns the startup settings of the process, zero when
+// the process boots without a grace period.
+func (y TsuruYamlData) StartupForProcess(process string) TsuruYamlStartup {
+	return y.Startup[process]
+}
+
+// ValidateStartup checks that startup settings aren't negative and that the
+// max boot time isn't shorter than the grace period.
+func (y TsuruYamlData) ValidateStartup() error {
+	for process, startup := range y.Startup {
+		if startup.GracePeriodSeconds < 0 || startup.MaxBootSeconds < 0 {
+			return errors.Errorf("invalid startup for process %q: grace period and max boot time must not be negative", process)
+		}
+		if startup.MaxBootSeconds > 0 && startup.MaxBootSeconds < startup.GracePeriodSeconds {
+			return errors.Errorf("invalid startup for process %q: max boot time must not be shorter than the grace period", process)
+		}
+	}
+	return nil
+}
+
+// TsuruYamlProcessPort is a named container port of a process, routed
+// separately from the other ports of the same process.
+type TsuruYamlProcessPort struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+// TsuruYamlExposedPort is a raw TCP or UDP port of a process that must be
+// reachable from outside the cluster, bypassing the app routers.
+type TsuruYamlExposedPort struct {
+	Process  string `json:"process"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol,omitempty" bson:",omitempty"`
 }
 
 type TsuruYamlHooks struct {
 	Restart TsuruYamlRestartHooks `json:"restart" bson:",omitempty"`
 	Build   []string              `json:"build" bson:",omitempty"`
+	Reload  []string              `json:"reload" bson:",omitempty"`
 }
 
 type TsuruYamlRestartHooks struct {
@@ -40,10 +195,24 @@ type TsuruYamlHealthcheck struct {
 }
 
 type TsuruYamlKubernetesConfig struct {
-	Groups map[string]TsuruYamlKubernetesGroup `json:"groups,omitempty"`
+	Groups   map[string]TsuruYamlKubernetesGroup `json:"groups,omitempty"`
+	Metadata *TsuruYamlKubernetesMetadata        `json:"metadata,omitempty" bson:",omitempty"`
+}
+
+// TsuruYamlKubernetesMetadata holds extra labels and annotations merged onto
+// the pods, deployments and services of the app.
+type TsuruYamlKubernetesMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty" bson:",omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" bson:",omitempty"`
 }
 
 func (in *TsuruYamlKubernetesConfig) DeepCopyInto(out *TsuruYamlKubernetesConfig) {
+	if in.Metadata != nil {
+		out.Metadata = &TsuruYamlKubernetesMetadata{
+			Labels:      copyStringMap(in.Metadata.Labels),
+			Annotations: copyStringMap(in.Metadata.Annotations),
+		}
+	}
 	if in.Groups == nil {
 		return
 	}
@@ -55,6 +224,17 @@ func (in *TsuruYamlKubernetesConfig) DeepCopyInto(out *TsuruYamlKubernetesConfig
 	}
 }
 
+func copyStringMap(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
 func (in *TsuruYamlKubernetesConfig) DeepCopy() *TsuruYamlKubernetesConfig {
 	out := &TsuruYamlKubernetesConfig{}
 	in.DeepCopyInto(out)
@@ -74,8 +254,29 @@ type TsuruYamlKubernetesProcessPortConfig struct {
 	TargetPort int    `json:"target_port,omitempty"`
 }
 
+// ReadinessCheck returns the check a unit must pass before being registered
+// in the app routers, the readiness check falling back to the healthcheck.
+func (y TsuruYamlData) ReadinessCheck() *TsuruYamlHealthcheck {
+	if y.Readiness != nil {
+		return y.Readiness
+	}
+	return y.Healthcheck
+}
+
+// LivenessCheck returns the check restarting a unit when failing, the
+// liveness check falling back to the healthcheck with force_restart.
+func (y TsuruYamlData) LivenessCheck() *TsuruYamlHealthcheck {
+	if y.Liveness != nil {
+		return y.Liveness
+	}
+	if y.Healthcheck != nil && y.Healthcheck.ForceRestart {
+		return y.Healthcheck
+	}
+	return nil
+}
+
 func (y TsuruYamlData) ToRouterHC() router.HealthcheckData {
-	hc := y.Healthcheck
+	hc := y.ReadinessCheck()
 	if hc == nil || !hc.UseInRouter {
 		return router.HealthcheckData{
 			Path: "/",
@@ -88,6 +289,23 @@ func (y TsuruYamlData) ToRouterHC() router.HealthcheckData {
 	}
 }
 
+// ExposedPortsForProcess returns the ports exposed by the process, with the
+// protocol defaulting to tcp.
+func (y TsuruYamlData) ExposedPortsForProcess(process string) []TsuruYamlExposedPort {
+	var ports []TsuruYamlExposedPort
+	for _, p := range y.ExposedPorts {
+		if p.Process != process {
+			continue
+		}
+		if p.Protocol == "" {
+			p.Protocol = "tcp"
+		}
+		p.Protocol = strings.ToLower(p.Protocol)
+		ports = append(ports, p)
+	}
+	return ports
+}
+
 func (y *TsuruYamlKubernetesConfig) GetProcessConfigs(procName string) *TsuruYamlKubernetesProcessConfig {
 	for _, group := range y.Groups {
 		for p, proc := range group {
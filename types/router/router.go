@@ -7,6 +7,7 @@ package router
 import (
 	"context"
 	"fmt"
+	"net"
 
 	"github.com/pkg/errors"
 )
@@ -82,3 +83,191 @@ func (hc *HealthcheckData) String() string {
 	}
 	return fmt.Sprintf("path: %q%s%s", path, status, body)
 }
+
+// RouteRule sends requests matching Match to Backends, splitting traffic
+// between them by weight. Rules are evaluated before the app's default
+// route.
+type RouteRule struct {
+	Name     string            `json:"name"`
+	Match    RouteMatch        `json:"match"`
+	Backends []WeightedBackend `json:"backends"`
+}
+
+// RouteMatch holds the conditions a request must satisfy to be handled by a
+// rule. Every condition set must match.
+type RouteMatch struct {
+	Headers    map[string]string `json:"headers,omitempty"`
+	Cookies    map[string]string `json:"cookies,omitempty"`
+	PathPrefix string            `json:"pathPrefix,omitempty"`
+}
+
+// WeightedBackend is an app version receiving a share of the traffic of a
+// rule. Version 0 refers to the app's current routable backend.
+type WeightedBackend struct {
+	Version int `json:"version"`
+	Weight  int `json:"weight"`
+}
+
+// AppRouteRules groups the rules of an app in a single router.
+type AppRouteRules struct {
+	Router string      `json:"router"`
+	Rules  []RouteRule `json:"rules"`
+}
+
+func (m RouteMatch) IsEmpty() bool {
+	return len(m.Headers) == 0 && len(m.Cookies) == 0 && m.PathPrefix == ""
+}
+
+func (b WeightedBackend) Prefix() string {
+	if b.Version == 0 {
+		return ""
+	}
+	return fmt.Sprintf("v%d.version", b.Version)
+}
+
+func (r *RouteRule) Validate() error {
+	if r.Name == "" {
+		return errors.New("rule name is required")
+	}
+	if r.Match.IsEmpty() {
+		return errors.Errorf("rule %q must match at least a header, a cookie or a path prefix", r.Name)
+	}
+	if r.Match.PathPrefix != "" && r.Match.PathPrefix[0] != '/' {
+		return errors.Errorf("rule %q path prefix must start with /", r.Name)
+	}
+	if len(r.Backends) == 0 {
+		return errors.Errorf("rule %q must have at least one backend", r.Name)
+	}
+	total := 0
+	versions := map[int]struct{}{}
+	for _, b := range r.Backends {
+		if b.Version < 0 {
+			return errors.Errorf("rule %q has an invalid backend version %d", r.Name, b.Version)
+		}
+		if b.Weight < 0 || b.Weight > 100 {
+			return errors.Errorf("rule %q backend weights must be between 0 and 100", r.Name)
+		}
+		if _, ok := versions[b.Version]; ok {
+			return errors.Errorf("rule %q has duplicated backend version %d", r.Name, b.Version)
+		}
+		versions[b.Version] = struct{}{}
+		total += b.Weight
+	}
+	if total != 100 {
+		return errors.Errorf("rule %q backend weights must sum 100, got %d", r.Name, total)
+	}
+	return nil
+}
+
+// ValidateRouteRules validates each rule and ensures their names are unique.
+func ValidateRouteRules(rules []RouteRule) error {
+	names := map[string]struct{}{}
+	for i := range rules {
+		err := rules[i].Validate()
+		if err != nil {
+			return err
+		}
+		if _, ok := names[rules[i].Name]; ok {
+			return errors.Errorf("duplicated rule name %q", rules[i].Name)
+		}
+		names[rules[i].Name] = struct{}{}
+	}
+	return nil
+}
+
+// Policy holds the request policies applied by routers to the traffic of an
+// app.
+type Policy struct {
+	RateLimit   *RateLimit `json:"rateLimit,omitempty" bson:",omitempty"`
+	AllowCIDRs  []string   `json:"allowCIDRs,omitempty" bson:",omitempty"`
+	DenyCIDRs   []string   `json:"denyCIDRs,omitempty" bson:",omitempty"`
+	MaxBodySize int64      `json:"maxBodySize,omitempty" bson:",omitempty"`
+}
+
+// RateLimit limits the rate of requests of each client address, allowing
+// bursts of up to Burst requests above the limit.
+type RateLimit struct {
+	RequestsPerSecond int `json:"requestsPerSecond"`
+	Burst             int `json:"burst,omitempty"`
+}
+
+func (p *Policy) IsEmpty() bool {
+	return p.RateLimit == nil && len(p.AllowCIDRs) == 0 && len(p.DenyCIDRs) == 0 && p.MaxBodySize == 0
+}
+
+func (p *Policy) Validate() error {
+	if p.RateLimit != nil {
+		if p.RateLimit.RequestsPerSecond <= 0 {
+			return errors.New("rate limit requests per second must be greater than 0")
+		}
+		if p.RateLimit.Burst < 0 {
+			return errors.New("rate limit burst must not be negative")
+		}
+	}
+	if p.MaxBodySize < 0 {
+		return errors.New("max body size must not be negative")
+	}
+	for _, cidrs := range [][]string{p.AllowCIDRs, p.DenyCIDRs} {
+		for _, cidr := range cidrs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return errors.Errorf("invalid CIDR %q", cidr)
+			}
+		}
+	}
+	return nil
+}
+
+// Shadow mirrors Percent percent of the requests of an app to the units of
+// Version. Routers discard the responses of the mirrored requests, the
+// shadowed version never serves clients.
+type Shadow struct {
+	Version int `json:"version"`
+	Percent int `json:"percent"`
+}
+
+func (s *Shadow) Validate() error {
+	if s.Version <= 0 {
+		return errors.New("shadow version must be greater than 0")
+	}
+	if s.Percent <= 0 || s.Percent > 100 {
+		return errors.New("shadow percent must be between 1 and 100")
+	}
+	return nil
+}
+
+// ShadowReport holds the responses of the requests mirrored to a shadow
+// version, along with the responses of the same requests served by the
+// routable versions.
+type ShadowReport struct {
+	Requests int64              `json:"requests"`
+	Primary  ShadowBackendStats `json:"primary"`
+	Shadow   ShadowBackendStats `json:"shadow"`
+}
+
+// ShadowBackendStats holds the count of responses by status code and the
+// latency percentiles, in milliseconds, of a backend.
+type ShadowBackendStats struct {
+	StatusCodes map[int]int64 `json:"statusCodes"`
+	LatencyP50  float64       `json:"latencyP50"`
+	LatencyP99  float64       `json:"latencyP99"`
+}
+
+// Visibility values control where routers expose an app: public apps are
+// exposed as usual, internal apps only on internal VIPs or ingress classes
+// and apps with visibility none aren't exposed at all, being reachable only
+// through internal service discovery.
+const (
+	VisibilityPublic   = "public"
+	VisibilityInternal = "internal"
+	VisibilityNone     = "none"
+)
+
+// ValidateVisibility returns an error if visibility isn't one of the known
+// visibility values.
+func ValidateVisibility(visibility string) error {
+	switch visibility {
+	case VisibilityPublic, VisibilityInternal, VisibilityNone:
+		return nil
+	}
+	return errors.Errorf("invalid visibility %q, must be one of: %s, %s, %s", visibility, VisibilityPublic, VisibilityInternal, VisibilityNone)
+}
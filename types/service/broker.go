@@ -40,6 +40,13 @@ type BrokerConfig struct {
 	// CacheExpirationSeconds is a time duration in seconds that the Service
 	// Broker catalog is kept in cache
 	CacheExpirationSeconds int
+	// AsyncBindings enables asynchronous bind and unbind operations, which
+	// are still an alpha feature of the Open Service Broker API. tsuru polls
+	// the broker until these operations finish.
+	AsyncBindings bool
+	// OperationTimeoutSeconds is the maximum time in seconds tsuru waits for
+	// an asynchronous binding operation to finish, defaults to 300 seconds.
+	OperationTimeoutSeconds int
 }
 
 // AuthConfig is a union-type representing the possible auth configurations a
@@ -0,0 +1,48 @@
+// Copyright 2026 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tracker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrLeaseHeld = errors.New("lease held by another instance")
+
+// Lease grants the ownership of a background worker to a single API
+// instance until it expires.
+type Lease struct {
+	Name      string    `json:"name"`
+	Owner     string    `json:"owner"`
+	RenewedAt time.Time `json:"renewedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type LeaseStorage interface {
+	// Acquire takes or renews the lease for owner, returning ErrLeaseHeld
+	// when it's held by another owner and not expired.
+	Acquire(ctx context.Context, name, owner string, duration time.Duration) error
+	List(ctx context.Context) ([]Lease, error)
+}
+
+// NodeStatus describes the live API instances and the leases of the
+// background workers.
+type NodeStatus struct {
+	LeaderElection bool             `json:"leaderElection"`
+	Instances      []InstanceStatus `json:"instances"`
+	Leases         []Lease          `json:"leases"`
+}
+
+type InstanceStatus struct {
+	Name       string    `json:"name"`
+	Addresses  []string  `json:"addresses"`
+	Port       string    `json:"port"`
+	TLSPort    string    `json:"tlsPort"`
+	LastUpdate time.Time `json:"lastUpdate"`
+	Current    bool      `json:"current"`
+	Role       string    `json:"role"`
+	Workers    []string  `json:"workers"`
+}